@@ -115,6 +115,16 @@ func InitApi() {
 	utils.InitHTML()
 
 	app.InitEmailBatching()
+	app.InitScheduledPosts()
+	app.InitStatusExpiry()
+	app.InitCacheMetrics()
+	app.InitCacheSizing()
+	app.InitEmojiUsage()
+	app.InitPushProxyHealthCheck()
+	app.InitSearchEngine()
+	app.InitPostIntegrity()
+	app.InitLdapGroupSync()
+	app.InitInvitationExpiry()
 }
 
 func HandleEtag(etag string, routeName string, w http.ResponseWriter, r *http.Request) bool {
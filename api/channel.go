@@ -563,11 +563,10 @@ func getChannelStats(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if memberCount, err := app.GetChannelMemberCount(id); err != nil {
+	if stats, err := app.GetChannelStats(id); err != nil {
 		c.Err = err
 		return
 	} else {
-		stats := model.ChannelStats{ChannelId: channel.Id, MemberCount: memberCount}
 		w.Write([]byte(stats.ToJson()))
 	}
 }
@@ -194,6 +194,14 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.Path = "/" + strings.Join(splitURL[2:], "/")
 	}
 
+	if c.Err == nil && h.isApi {
+		c.Err = app.CheckEndpointRateLimit(w, r, &c.Session, c.IpAddress)
+	}
+
+	if c.Err == nil && h.isApi {
+		c.Err = app.CheckClientVersion(w, r, &c.Session)
+	}
+
 	if c.Err == nil && h.requireUser {
 		c.UserRequired()
 	}
@@ -202,6 +210,10 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.MfaRequired()
 	}
 
+	if c.Err == nil && c.Session.IsOAuth {
+		c.OAuthScopeRequired(r)
+	}
+
 	if c.Err == nil && h.requireSystemAdmin {
 		c.SystemAdminRequired()
 	}
@@ -258,8 +270,8 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 func (c *Context) LogAudit(extraInfo string) {
 	audit := &model.Audit{UserId: c.Session.UserId, IpAddress: c.IpAddress, Action: c.Path, ExtraInfo: extraInfo, SessionId: c.Session.Id}
-	if r := <-app.Srv.Store.Audit().Save(audit); r.Err != nil {
-		c.LogError(r.Err)
+	if err := app.SaveAudit(audit); err != nil {
+		c.LogError(err)
 	}
 }
 
@@ -270,8 +282,8 @@ func (c *Context) LogAuditWithUserId(userId, extraInfo string) {
 	}
 
 	audit := &model.Audit{UserId: userId, IpAddress: c.IpAddress, Action: c.Path, ExtraInfo: extraInfo, SessionId: c.Session.Id}
-	if r := <-app.Srv.Store.Audit().Save(audit); r.Err != nil {
-		c.LogError(r.Err)
+	if err := app.SaveAudit(audit); err != nil {
+		c.LogError(err)
 	}
 }
 
@@ -332,6 +344,18 @@ func (c *Context) MfaRequired() {
 	}
 }
 
+// OAuthScopeRequired rejects OAuth tokens that were granted a restricted scope, since scopes are
+// only enforced by APIv4. The userinfo endpoint checks the scope of the token itself.
+func (c *Context) OAuthScopeRequired(r *http.Request) {
+	if r.URL.Path == model.OPENID_USERINFO_PATH {
+		return
+	}
+
+	if c.Session.IsOAuthScopeRestricted() {
+		c.Err = model.NewAppError("OAuthScopeRequired", "api.context.oauth_scope.api_v3.app_error", nil, "scope="+c.Session.GetOAuthScope(), http.StatusForbidden)
+	}
+}
+
 func (c *Context) SystemAdminRequired() {
 	if len(c.Session.UserId) == 0 {
 		c.Err = model.NewLocAppError("", "api.context.session_expired.app_error", nil, "SystemAdminRequired")
@@ -38,6 +38,10 @@ func InitOAuth() {
 
 	BaseRoutes.Root.Handle("/oauth/authorize", AppHandlerTrustRequester(authorizeOAuth)).Methods("GET")
 	BaseRoutes.Root.Handle("/oauth/access_token", ApiAppHandlerTrustRequester(getAccessToken)).Methods("POST")
+	BaseRoutes.Root.Handle("/oauth/revoke", ApiAppHandlerTrustRequester(revokeOAuthToken)).Methods("POST")
+	BaseRoutes.Root.Handle(model.OPENID_USERINFO_PATH, ApiAppHandlerTrustRequester(getOpenIdUserInfo)).Methods("GET", "POST")
+	BaseRoutes.Root.Handle(model.OPENID_JWKS_PATH, ApiAppHandlerTrustRequester(getOpenIdJsonWebKeys)).Methods("GET")
+	BaseRoutes.Root.Handle(model.OPENID_CONFIGURATION_PATH, ApiAppHandlerTrustRequester(getOpenIdConfiguration)).Methods("GET")
 
 	// Handle all the old routes, to be later removed
 	BaseRoutes.Root.Handle("/{service:[A-Za-z0-9]+}/complete", AppHandlerIndependent(completeOAuth)).Methods("GET")
@@ -110,6 +114,12 @@ func getOAuthApps(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		apps := result.Data.([]*model.OAuthApp)
+
+		// The whole list is always returned
+		if includeTotalCount, _ := strconv.ParseBool(r.URL.Query().Get("include_total_count")); includeTotalCount {
+			model.NewPagination(0, len(apps), len(apps), int64(len(apps))).SetHeaders(w.Header())
+		}
+
 		w.Write([]byte(model.OAuthAppListToJson(apps)))
 	}
 }
@@ -171,10 +181,9 @@ func allowOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	scope := r.URL.Query().Get("scope")
 	state := r.URL.Query().Get("state")
-
-	if len(scope) == 0 {
-		scope = model.DEFAULT_SCOPE
-	}
+	codeChallenge := r.URL.Query().Get("code_challenge")
+	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
+	nonce := r.URL.Query().Get("nonce")
 
 	var oauthApp *model.OAuthApp
 	if result := <-app.Srv.Store.OAuth().GetApp(clientId); result.Err != nil {
@@ -184,6 +193,10 @@ func allowOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		oauthApp = result.Data.(*model.OAuthApp)
 	}
 
+	if len(scope) == 0 {
+		scope = oauthApp.GetScopes()
+	}
+
 	if !oauthApp.IsValidRedirectURL(redirectUri) {
 		c.LogAudit("fail - redirect_uri did not match registered callback")
 		c.Err = model.NewLocAppError("allowOAuth", "api.oauth.allow_oauth.redirect_callback.app_error", nil, "")
@@ -197,7 +210,31 @@ func allowOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authData := &model.AuthData{UserId: c.Session.UserId, ClientId: clientId, CreateAt: model.GetMillis(), RedirectUri: redirectUri, State: state, Scope: scope}
+	// Only S256 challenges are accepted since plain challenges offer no protection if the request is observed
+	if len(codeChallenge) > 0 && (codeChallengeMethod != model.PKCE_CHALLENGE_METHOD_S256 || !model.IsValidPKCEValue(codeChallenge)) {
+		responseData["redirect"] = redirectUri + "?error=invalid_request&state=" + state
+		w.Write([]byte(model.MapToJson(responseData)))
+		return
+	}
+
+	if len(nonce) > model.OPENID_NONCE_MAX_LENGTH {
+		responseData["redirect"] = redirectUri + "?error=invalid_request&state=" + state
+		w.Write([]byte(model.MapToJson(responseData)))
+		return
+	}
+
+	// An app can never be granted more than the scopes it was registered with
+	if !model.IsValidOAuthScope(scope) || !model.IsOAuthScopeWithin(scope, oauthApp.GetScopes()) {
+		responseData["redirect"] = redirectUri + "?error=invalid_scope&state=" + state
+		w.Write([]byte(model.MapToJson(responseData)))
+		return
+	}
+
+	authData := &model.AuthData{UserId: c.Session.UserId, ClientId: clientId, CreateAt: model.GetMillis(), RedirectUri: redirectUri, State: state, Scope: scope, Nonce: nonce}
+	if len(codeChallenge) > 0 {
+		authData.CodeChallenge = codeChallenge
+		authData.CodeChallengeMethod = codeChallengeMethod
+	}
 	authData.Code = model.HashPassword(fmt.Sprintf("%v:%v:%v:%v", clientId, redirectUri, authData.CreateAt, c.Session.UserId))
 
 	// this saves the OAuth2 app as authorized
@@ -339,10 +376,9 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	redirect := r.URL.Query().Get("redirect_uri")
 	scope := r.URL.Query().Get("scope")
 	state := r.URL.Query().Get("state")
-
-	if len(scope) == 0 {
-		scope = model.DEFAULT_SCOPE
-	}
+	codeChallenge := r.URL.Query().Get("code_challenge")
+	codeChallengeMethod := r.URL.Query().Get("code_challenge_method")
+	nonce := r.URL.Query().Get("nonce")
 
 	if len(responseType) == 0 || len(clientId) == 0 || len(redirect) == 0 {
 		c.Err = model.NewLocAppError("authorizeOAuth", "api.oauth.authorize_oauth.missing.app_error", nil, "")
@@ -357,6 +393,10 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 		oauthApp = result.Data.(*model.OAuthApp)
 	}
 
+	if len(scope) == 0 {
+		scope = oauthApp.GetScopes()
+	}
+
 	// here we should check if the user is logged in
 	if len(c.Session.UserId) == 0 {
 		http.Redirect(w, r, c.GetSiteURLHeader()+"/login?redirect_to="+url.QueryEscape(r.RequestURI), http.StatusFound)
@@ -365,8 +405,8 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	isAuthorized := false
 	if result := <-app.Srv.Store.Preference().Get(c.Session.UserId, model.PREFERENCE_CATEGORY_AUTHORIZED_OAUTH_APP, clientId); result.Err == nil {
-		// when we support scopes we should check if the scopes match
-		isAuthorized = true
+		// the user has to be asked again when the app requests more than was previously granted
+		isAuthorized = model.IsOAuthScopeWithin(scope, result.Data.(model.Preference).Value)
 	}
 
 	// Automatically allow if the app is trusted
@@ -378,9 +418,19 @@ func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		pkceParams := ""
+		if len(codeChallenge) > 0 {
+			pkceParams = "&code_challenge=" + url.QueryEscape(codeChallenge) + "&code_challenge_method=" + url.QueryEscape(codeChallengeMethod)
+		}
+
+		nonceParam := ""
+		if len(nonce) > 0 {
+			nonceParam = "&nonce=" + url.QueryEscape(nonce)
+		}
+
 		doAllow := func() (*http.Response, *model.AppError) {
 			HttpClient := &http.Client{}
-			url := c.GetSiteURLHeader() + "/api/v3/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + clientId + "&redirect_uri=" + url.QueryEscape(redirect) + "&scope=" + scope + "&state=" + url.QueryEscape(state)
+			url := c.GetSiteURLHeader() + "/api/v3/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + clientId + "&redirect_uri=" + url.QueryEscape(redirect) + "&scope=" + url.QueryEscape(scope) + "&state=" + url.QueryEscape(state) + pkceParams + nonceParam
 			rq, _ := http.NewRequest("GET", url, strings.NewReader(""))
 
 			rq.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+c.Session.Token)
@@ -452,8 +502,9 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Public clients using PKCE may leave out the secret when exchanging an authorization code
 	secret := r.FormValue("client_secret")
-	if len(secret) == 0 {
+	if len(secret) == 0 && grantType != model.ACCESS_TOKEN_GRANT_TYPE {
 		c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.bad_client_secret.app_error", nil, "")
 		return
 	}
@@ -467,7 +518,7 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 		oauthApp = result.Data.(*model.OAuthApp)
 	}
 
-	if oauthApp.ClientSecret != secret {
+	if len(secret) > 0 && oauthApp.ClientSecret != secret {
 		c.LogAudit("fail - invalid client credentials")
 		c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.credentials.app_error", nil, "")
 		return
@@ -505,6 +556,17 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if len(authData.CodeChallenge) > 0 {
+			if !authData.VerifyCodeChallenge(r.FormValue("code_verifier")) {
+				c.LogAudit("fail - code verifier did not match code challenge")
+				c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.code_verifier.app_error", nil, "")
+				return
+			}
+		} else if len(secret) == 0 {
+			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.bad_client_secret.app_error", nil, "")
+			return
+		}
+
 		uchan := app.Srv.Store.User().Get(authData.UserId)
 		if result := <-uchan; result.Err != nil {
 			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_user.app_error", nil, "")
@@ -519,7 +581,9 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		} else if result.Data != nil {
 			accessData := result.Data.(*model.AccessData)
-			if accessData.IsExpired() {
+			if accessData.IsExpired() || accessData.Scopes != authData.Scope {
+				// a grant with different scopes replaces the previous session so the new scopes take effect
+				accessData.Scopes = authData.Scope
 				if access, err := newSessionUpdateToken(oauthApp.Name, accessData, user); err != nil {
 					c.Err = err
 					return
@@ -532,19 +596,20 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 					AccessToken: accessData.Token,
 					TokenType:   model.ACCESS_TOKEN_TYPE,
 					ExpiresIn:   int32((accessData.ExpiresAt - model.GetMillis()) / 1000),
+					Scope:       accessData.Scopes,
 				}
 			}
 		} else {
 			// create a new session and return new access token
 			var session *model.Session
-			if result, err := newSession(oauthApp.Name, user); err != nil {
+			if result, err := newSession(oauthApp.Name, user, authData.Scope); err != nil {
 				c.Err = err
 				return
 			} else {
 				session = result
 			}
 
-			accessData = &model.AccessData{ClientId: clientId, UserId: user.Id, Token: session.Token, RefreshToken: model.NewId(), RedirectUri: redirectUri, ExpiresAt: session.ExpiresAt}
+			accessData = &model.AccessData{ClientId: clientId, UserId: user.Id, Token: session.Token, RefreshToken: model.NewId(), RedirectUri: redirectUri, ExpiresAt: session.ExpiresAt, Scopes: authData.Scope}
 
 			if result := <-app.Srv.Store.OAuth().SaveAccessData(accessData); result.Err != nil {
 				l4g.Error(result.Err)
@@ -557,10 +622,18 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 				TokenType:    model.ACCESS_TOKEN_TYPE,
 				RefreshToken: accessData.RefreshToken,
 				ExpiresIn:    int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
+				Scope:        accessData.Scopes,
 			}
 		}
 
 		<-app.Srv.Store.OAuth().RemoveAuthData(authData.Code)
+
+		if idToken, err := app.CreateOpenIdToken(c.GetSiteURLHeader(), clientId, user, accessRsp.Scope, authData.Nonce); err != nil {
+			c.Err = err
+			return
+		} else {
+			accessRsp.IdToken = idToken
+		}
 	} else {
 		// when grantType is refresh_token
 		if result := <-app.Srv.Store.OAuth().GetAccessDataByRefreshToken(refreshToken); result.Err != nil {
@@ -571,6 +644,13 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 			accessData = result.Data.(*model.AccessData)
 		}
 
+		// a refresh token can only be used by the client it was issued to
+		if accessData.ClientId != clientId {
+			c.LogAudit("fail - refresh token was issued to another client")
+			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.refresh_token.app_error", nil, "")
+			return
+		}
+
 		uchan := app.Srv.Store.User().Get(accessData.UserId)
 		if result := <-uchan; result.Err != nil {
 			c.Err = model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_user.app_error", nil, "")
@@ -585,6 +665,13 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 		} else {
 			accessRsp = access
 		}
+
+		if idToken, err := app.CreateOpenIdToken(c.GetSiteURLHeader(), clientId, user, accessRsp.Scope, ""); err != nil {
+			c.Err = err
+			return
+		} else {
+			accessRsp.IdToken = idToken
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -596,6 +683,111 @@ func getAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(accessRsp.ToJson()))
 }
 
+// revokeOAuthToken implements token revocation as described in RFC 7009. The client authenticates with its
+// id and secret, and revoking either the access token or the refresh token ends the whole grant.
+func revokeOAuthToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("revokeOAuthToken", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	r.ParseForm()
+
+	token := r.FormValue("token")
+	if len(token) == 0 {
+		c.Err = model.NewLocAppError("revokeOAuthToken", "api.oauth.revoke_token.missing_token.app_error", nil, "")
+		return
+	}
+
+	clientId := r.FormValue("client_id")
+	if len(clientId) != 26 {
+		c.Err = model.NewLocAppError("revokeOAuthToken", "api.oauth.get_access_token.bad_client_id.app_error", nil, "")
+		return
+	}
+
+	secret := r.FormValue("client_secret")
+	if len(secret) == 0 {
+		c.Err = model.NewLocAppError("revokeOAuthToken", "api.oauth.get_access_token.bad_client_secret.app_error", nil, "")
+		return
+	}
+
+	if result := <-app.Srv.Store.OAuth().GetApp(clientId); result.Err != nil || result.Data.(*model.OAuthApp).ClientSecret != secret {
+		c.LogAudit("fail - invalid client credentials")
+		c.Err = model.NewLocAppError("revokeOAuthToken", "api.oauth.get_access_token.credentials.app_error", nil, "")
+		c.Err.StatusCode = http.StatusUnauthorized
+		return
+	}
+
+	if err := app.RevokeOAuthToken(clientId, token, r.FormValue("token_type_hint")); err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+// getOpenIdUserInfo returns the claims about the user that an OAuth access token was granted for, as
+// described in OpenID Connect Core 1.0. The token must have been granted the openid scope.
+func getOpenIdUserInfo(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("getOpenIdUserInfo", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	if len(c.Session.UserId) == 0 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		c.Err = model.NewLocAppError("getOpenIdUserInfo", "api.context.session_expired.app_error", nil, "")
+		c.Err.StatusCode = http.StatusUnauthorized
+		return
+	}
+
+	claims, err := app.GetOpenIdUserInfo(&c.Session)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", "Bearer error=\"insufficient_scope\"")
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(claims.ToJson()))
+}
+
+func getOpenIdJsonWebKeys(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("getOpenIdJsonWebKeys", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	keys, err := app.GetOpenIdJsonWebKeys()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(keys.ToJson()))
+}
+
+// getOpenIdConfiguration serves the provider metadata for OpenID Connect discovery so that services
+// can be set up to log in with Mattermost from the site URL alone.
+func getOpenIdConfiguration(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
+		c.Err = model.NewLocAppError("getOpenIdConfiguration", "api.oauth.get_access_token.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	w.Write([]byte(model.NewOpenIdConfiguration(c.GetSiteURLHeader()).ToJson()))
+}
+
 func loginWithOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	service := params["service"]
@@ -857,13 +1049,14 @@ func regenerateOAuthSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func newSession(appName string, user *model.User) (*model.Session, *model.AppError) {
+func newSession(appName string, user *model.User, scope string) (*model.Session, *model.AppError) {
 	// set new token an session
 	session := &model.Session{UserId: user.Id, Roles: user.Roles, IsOAuth: true}
 	session.SetExpireInDays(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays)
 	session.AddProp(model.SESSION_PROP_PLATFORM, appName)
 	session.AddProp(model.SESSION_PROP_OS, "OAuth2")
 	session.AddProp(model.SESSION_PROP_BROWSER, "OAuth2")
+	session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, scope)
 
 	if result := <-app.Srv.Store.Session().Save(session); result.Err != nil {
 		return nil, model.NewLocAppError("getAccessToken", "api.oauth.get_access_token.internal_session.app_error", nil, "")
@@ -879,22 +1072,32 @@ func newSessionUpdateToken(appName string, accessData *model.AccessData, user *m
 	var session *model.Session
 	<-app.Srv.Store.Session().Remove(accessData.Token) //remove the previous session
 
-	if result, err := newSession(appName, user); err != nil {
+	if result, err := newSession(appName, user, accessData.Scopes); err != nil {
 		return nil, err
 	} else {
 		session = result
 	}
 
+	// Issue a new refresh token with every access token so that a leaked refresh token stops working once it is used
+	previousRefreshToken := accessData.RefreshToken
 	accessData.Token = session.Token
+	accessData.RefreshToken = model.NewId()
 	accessData.ExpiresAt = session.ExpiresAt
-	if result := <-app.Srv.Store.OAuth().UpdateAccessData(accessData); result.Err != nil {
+	if result := <-app.Srv.Store.OAuth().RotateRefreshToken(accessData, previousRefreshToken); result.Err != nil {
+		<-app.Srv.Store.Session().Remove(session.Token)
+		if result.Err.StatusCode == http.StatusBadRequest {
+			return nil, model.NewAppError("getAccessToken", "api.oauth.get_access_token.refresh_token.app_error", nil, "", http.StatusBadRequest)
+		}
+
 		l4g.Error(result.Err)
 		return nil, model.NewLocAppError("getAccessToken", "web.get_access_token.internal_saving.app_error", nil, "")
 	}
 	accessRsp := &model.AccessResponse{
-		AccessToken: session.Token,
-		TokenType:   model.ACCESS_TOKEN_TYPE,
-		ExpiresIn:   int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
+		AccessToken:  session.Token,
+		TokenType:    model.ACCESS_TOKEN_TYPE,
+		RefreshToken: accessData.RefreshToken,
+		ExpiresIn:    int32(*utils.Cfg.ServiceSettings.SessionLengthSSOInDays * 60 * 60 * 24),
+		Scope:        accessData.Scopes,
 	}
 
 	return accessRsp, nil
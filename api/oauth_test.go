@@ -522,6 +522,9 @@ func TestOAuthAccessToken(t *testing.T) {
 	oauthApp := &model.OAuthApp{Name: "TestApp5" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
 	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
 
+	otherApp := &model.OAuthApp{Name: "TestApp5" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	otherApp = Client.Must(Client.RegisterApp(otherApp)).Data.(*model.OAuthApp)
+
 	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = false
 	data := url.Values{"grant_type": []string{"junk"}, "client_id": []string{"12345678901234567890123456"}, "client_secret": []string{"12345678901234567890123456"}, "code": []string{"junk"}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
 
@@ -645,10 +648,56 @@ func TestOAuthAccessToken(t *testing.T) {
 	}
 
 	data.Set("refresh_token", refreshToken)
-	if _, err := Client.GetAccessToken(data); err != nil {
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else {
+		rsp := result.Data.(*model.AccessResponse)
+		if len(rsp.RefreshToken) == 0 || rsp.RefreshToken == refreshToken {
+			t.Fatal("refresh token should have been rotated")
+		}
+		token = rsp.AccessToken
+		refreshToken = rsp.RefreshToken
+	}
+
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - tried to reuse a rotated refresh token")
+	}
+
+	data.Set("client_id", otherApp.Id)
+	data.Set("client_secret", otherApp.ClientSecret)
+	data.Set("refresh_token", refreshToken)
+	if _, err := Client.GetAccessToken(data); err == nil || err.Id != "api.oauth.get_access_token.refresh_token.app_error" {
+		t.Fatal("should have failed - refresh token was issued to another client")
+	}
+	data.Set("client_id", oauthApp.Id)
+	data.Set("client_secret", oauthApp.ClientSecret)
+
+	revokeData := url.Values{"client_id": []string{oauthApp.Id}, "client_secret": []string{"junk"}, "token": []string{refreshToken}}
+	if _, err := Client.RevokeOAuthToken(revokeData); err == nil {
+		t.Fatal("should have failed - bad client secret")
+	}
+
+	revokeData.Set("client_secret", oauthApp.ClientSecret)
+	revokeData.Set("token", model.NewId())
+	if _, err := Client.RevokeOAuthToken(revokeData); err != nil {
+		t.Fatal("revoking an unknown token should succeed", err)
+	}
+
+	revokeData.Set("token", refreshToken)
+	revokeData.Set("token_type_hint", model.REFRESH_TOKEN_TYPE_HINT)
+	if _, err := Client.RevokeOAuthToken(revokeData); err != nil {
 		t.Fatal(err)
 	}
 
+	data.Set("refresh_token", refreshToken)
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - refresh token was revoked")
+	}
+
+	if _, err := Client.DoApiGet("/teams/"+th.BasicTeam.Id+"/users/0/100?access_token="+token, "", ""); err == nil {
+		t.Fatal("should have failed - access token was revoked with the refresh token")
+	}
+
 	authData := &model.AuthData{ClientId: oauthApp.Id, RedirectUri: oauthApp.CallbackUrls[0], UserId: th.BasicUser.Id, Code: model.NewId(), ExpiresIn: -1}
 	<-app.Srv.Store.OAuth().SaveAuthData(authData)
 
@@ -673,6 +722,190 @@ func TestOAuthAccessToken(t *testing.T) {
 	Client.ClearOAuthToken()
 }
 
+func TestOAuthAccessTokenPKCE(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	oauthApp := &model.OAuthApp{Name: "TestApp5" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	// Example values from RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	allow := "/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + oauthApp.Id + "&redirect_uri=" + url.QueryEscape(oauthApp.CallbackUrls[0]) + "&state=123&code_challenge=" + challenge
+
+	if result, err := Client.DoApiGet(allow+"&code_challenge_method=plain", "", ""); err != nil {
+		t.Fatal(err)
+	} else {
+		redirect := model.MapFromJson(result.Body)["redirect"]
+		if rurl, _ := url.Parse(redirect); rurl.Query().Get("error") != "invalid_request" {
+			t.Fatal("should have rejected a plain challenge")
+		}
+	}
+
+	result, err := Client.DoApiGet(allow+"&code_challenge_method="+model.PKCE_CHALLENGE_METHOD_S256, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rurl, _ := url.Parse(model.MapFromJson(result.Body)["redirect"])
+
+	Client.Logout()
+
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - missing code verifier")
+	}
+
+	data.Set("code_verifier", verifier[1:]+"a")
+	if _, err := Client.GetAccessToken(data); err == nil {
+		t.Fatal("should have failed - wrong code verifier")
+	}
+
+	data.Set("code_verifier", verifier)
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else if len(result.Data.(*model.AccessResponse).AccessToken) == 0 {
+		t.Fatal("access token not returned")
+	}
+}
+
+func TestOAuthScopes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	oauthApp := &model.OAuthApp{Name: "TestApp6" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}, Scopes: "read channel:" + th.BasicChannel.Id}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	allow := "/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + oauthApp.Id + "&redirect_uri=" + url.QueryEscape(oauthApp.CallbackUrls[0]) + "&state=123"
+
+	for _, scope := range []string{"user", "read", "read channel:" + model.NewId(), "junk"} {
+		if result, err := Client.DoApiGet(allow+"&scope="+url.QueryEscape(scope), "", ""); err != nil {
+			t.Fatal(err)
+		} else {
+			redirect := model.MapFromJson(result.Body)["redirect"]
+			if rurl, _ := url.Parse(redirect); rurl.Query().Get("error") != "invalid_scope" {
+				t.Fatal("should have rejected scope " + scope)
+			}
+		}
+	}
+
+	result, err := Client.DoApiGet(allow, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rurl, _ := url.Parse(model.MapFromJson(result.Body)["redirect"])
+
+	Client.Logout()
+
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+
+	if result, err := Client.GetAccessToken(data); err != nil {
+		t.Fatal(err)
+	} else {
+		rsp := result.Data.(*model.AccessResponse)
+		if rsp.Scope != oauthApp.Scopes {
+			t.Fatal("should have granted the scopes of the app")
+		}
+
+		if session, err := app.GetSession(rsp.AccessToken); err != nil {
+			t.Fatal(err)
+		} else if !session.IsOAuthReadOnly() || session.IsChannelAllowedByOAuthScope(model.NewId()) {
+			t.Fatal("session should be restricted by the granted scopes")
+		}
+
+		Client.SetOAuthToken(rsp.AccessToken)
+		if _, err := Client.GetMe(""); err == nil || err.StatusCode != http.StatusForbidden {
+			t.Fatal("tokens with a restricted scope should not be able to use APIv3")
+		}
+		Client.ClearOAuthToken()
+	}
+}
+
+func TestOAuthOpenId(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	utils.Cfg.ServiceSettings.EnableOAuthServiceProvider = true
+	oauthApp := &model.OAuthApp{Name: "TestApp7" + model.NewId(), Homepage: "https://nowhere.com", Description: "test", CallbackUrls: []string{"https://nowhere.com"}, Scopes: "read"}
+	oauthApp = Client.Must(Client.RegisterApp(oauthApp)).Data.(*model.OAuthApp)
+
+	config := Client.Must(Client.GetOpenIdConfiguration()).Data.(*model.OpenIdConfiguration)
+	if config.Issuer != Client.Url || config.UserInfoEndpoint != Client.Url+model.OPENID_USERINFO_PATH {
+		t.Fatal("should have described the provider", config)
+	}
+
+	allow := "/oauth/allow?response_type=" + model.AUTHCODE_RESPONSE_TYPE + "&client_id=" + oauthApp.Id + "&redirect_uri=" + url.QueryEscape(oauthApp.CallbackUrls[0]) + "&state=123&nonce=abc"
+
+	if result, err := Client.DoApiGet(allow+"&scope="+url.QueryEscape("profile email"), "", ""); err != nil {
+		t.Fatal(err)
+	} else if rurl, _ := url.Parse(model.MapFromJson(result.Body)["redirect"]); rurl.Query().Get("error") != "invalid_scope" {
+		t.Fatal("should have rejected a scope without openid or any access to the api")
+	}
+
+	result, err := Client.DoApiGet(allow+"&scope="+url.QueryEscape("openid email"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rurl, _ := url.Parse(model.MapFromJson(result.Body)["redirect"])
+
+	Client.Logout()
+
+	data := url.Values{"grant_type": []string{model.ACCESS_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "code": []string{rurl.Query().Get("code")}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+
+	rsp := Client.Must(Client.GetAccessToken(data)).Data.(*model.AccessResponse)
+	if len(rsp.IdToken) == 0 {
+		t.Fatal("should have issued an id token")
+	}
+
+	keys := Client.Must(Client.GetOpenIdJsonWebKeys()).Data.(*model.JsonWebKeySet)
+	if idToken, err := model.VerifyOpenIdToken(rsp.IdToken, keys); err != nil {
+		t.Fatal(err)
+	} else if idToken.Issuer != config.Issuer || idToken.Audience != oauthApp.Id || idToken.Subject != th.BasicUser.Id || idToken.Nonce != "abc" || idToken.Email != th.BasicUser.Email {
+		t.Fatal("should have issued an id token for the user", idToken)
+	}
+
+	claims := Client.Must(Client.GetOpenIdUserInfo(rsp.AccessToken)).Data.(*model.OpenIdClaims)
+	if claims.Subject != th.BasicUser.Id || claims.Email != th.BasicUser.Email || claims.PreferredUsername != "" {
+		t.Fatal("should have returned the claims allowed by the scope", claims)
+	}
+
+	if _, err := Client.GetOpenIdUserInfo(""); err == nil {
+		t.Fatal("should have required an access token")
+	}
+
+	Client.SetOAuthToken(rsp.AccessToken)
+	if _, err := Client.GetMe(""); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("a token with only openid scopes should not be able to use the api")
+	}
+	Client.ClearOAuthToken()
+
+	data = url.Values{"grant_type": []string{model.REFRESH_TOKEN_GRANT_TYPE}, "client_id": []string{oauthApp.Id}, "client_secret": []string{oauthApp.ClientSecret}, "refresh_token": []string{rsp.RefreshToken}, "redirect_uri": []string{oauthApp.CallbackUrls[0]}}
+	if refreshed := Client.Must(Client.GetAccessToken(data)).Data.(*model.AccessResponse); len(refreshed.IdToken) == 0 {
+		t.Fatal("should have issued a new id token when refreshing")
+	}
+
+	Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+	if _, err := Client.GetOpenIdUserInfo(Client.AuthToken); err == nil {
+		t.Fatal("should have required an oauth access token")
+	}
+}
+
 func TestOAuthComplete(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -16,7 +16,7 @@ import (
 
 const OPEN_GRAPH_METADATA_CACHE_SIZE = 10000
 
-var openGraphDataCache = utils.NewLru(OPEN_GRAPH_METADATA_CACHE_SIZE)
+var openGraphDataCache = utils.NewNamedLru("open_graph_metadata", OPEN_GRAPH_METADATA_CACHE_SIZE)
 
 func InitPost() {
 	l4g.Debug(utils.T("api.post.init.debug"))
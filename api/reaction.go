@@ -65,15 +65,19 @@ func saveReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result := <-app.Srv.Store.Reaction().Save(reaction); result.Err != nil {
-		c.Err = result.Err
+	var channel *model.Channel
+	if rchannel, err := app.GetChannel(channelId); err != nil {
+		c.Err = err
 		return
 	} else {
-		go sendReactionEvent(model.WEBSOCKET_EVENT_REACTION_ADDED, channelId, reaction, post)
-
-		reaction := result.Data.(*model.Reaction)
+		channel = rchannel
+	}
 
-		app.InvalidateCacheForReactions(reaction.PostId)
+	if reaction, err := app.SaveReactionForPost(reaction, channel); err != nil {
+		c.Err = err
+		return
+	} else {
+		go sendReactionEvent(model.WEBSOCKET_EVENT_REACTION_ADDED, channelId, reaction, post)
 
 		w.Write([]byte(reaction.ToJson()))
 	}
@@ -130,6 +134,7 @@ func deleteReaction(c *Context, w http.ResponseWriter, r *http.Request) {
 		go sendReactionEvent(model.WEBSOCKET_EVENT_REACTION_REMOVED, channelId, reaction, post)
 
 		app.InvalidateCacheForReactions(reaction.PostId)
+		go app.SyncReactionsForSharedChannel(channelId, reaction.PostId)
 
 		ReturnStatusOK(w)
 	}
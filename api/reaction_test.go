@@ -6,6 +6,7 @@ package api
 import (
 	"testing"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 )
 
@@ -138,6 +139,25 @@ func TestSaveReaction(t *testing.T) {
 	if _, err := Client.SaveReaction(channel.Id, reaction9); err == nil {
 		t.Fatal("should've failed to save reaction to a post that isn't in the given channel")
 	}
+
+	// saving a reaction that isn't allowed in the channel
+	if _, err := app.UpdateChannelAllowedReactions(channel, []string{"+1", "-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reaction10 := &model.Reaction{
+		UserId:    user.Id,
+		PostId:    post.Id,
+		EmojiName: "heart",
+	}
+	if _, err := Client.SaveReaction(channel.Id, reaction10); err == nil {
+		t.Fatal("should've failed to save a reaction that isn't allowed in the channel")
+	}
+
+	reaction10.EmojiName = "+1"
+	if _, err := Client.SaveReaction(channel.Id, reaction10); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestDeleteReaction(t *testing.T) {
@@ -339,6 +339,12 @@ func getInitialLoad(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 
 		il.TeamMembers = c.Session.TeamMembers
+
+		il.FeatureFlags, err = app.EvaluateFeatureFlagsForUser(c.Session.UserId, "")
+		if err != nil {
+			c.Err = err
+			return
+		}
 	}
 
 	if app.SessionCacheLength() == 0 {
@@ -1249,7 +1255,7 @@ func completeSaml(c *Context, w http.ResponseWriter, r *http.Request) {
 		relayProps = model.MapFromJson(strings.NewReader(stateStr))
 	}
 
-	if user, err := samlInterface.DoLogin(encodedXML, relayProps); err != nil {
+	if user, groups, err := samlInterface.DoLogin(encodedXML, relayProps); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusFound
 		return
@@ -1259,6 +1265,9 @@ func completeSaml(c *Context, w http.ResponseWriter, r *http.Request) {
 			c.Err.StatusCode = http.StatusFound
 			return
 		}
+
+		app.SyncSamlGroupMemberships(user, groups)
+
 		action := relayProps["action"]
 		switch action {
 		case model.OAUTH_ACTION_SIGNUP:
@@ -1747,6 +1747,12 @@ func TestEmailToLDAP(t *testing.T) {
 func TestMeInitialLoad(t *testing.T) {
 	th := Setup().InitBasic()
 
+	configFlags := utils.Cfg.FeatureFlagSettings.Flags
+	defer func() {
+		utils.Cfg.FeatureFlagSettings.Flags = configFlags
+	}()
+	utils.Cfg.FeatureFlagSettings.Flags = []*model.FeatureFlag{{Name: "initial_load", Enabled: true, TeamIds: model.StringArray{th.BasicTeam.Id}}}
+
 	if result, err := th.BasicClient.GetInitialLoad(); err != nil {
 		t.Fatal(err)
 	} else {
@@ -1775,6 +1781,10 @@ func TestMeInitialLoad(t *testing.T) {
 		if len(il.LicenseCfg) == 0 {
 			t.Fatal("should be valid")
 		}
+
+		if !il.FeatureFlags["initial_load"] {
+			t.Fatal("should include the feature flags for the user")
+		}
 	}
 
 	th.BasicClient.Logout()
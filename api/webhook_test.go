@@ -1097,3 +1097,49 @@ func TestIncomingWebhooks(t *testing.T) {
 		t.Fatal("should have failed - webhooks turned off")
 	}
 }
+
+func TestIncomingWebhookOverrideGrants(t *testing.T) {
+	th := Setup().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+	channel1 := th.CreateChannel(Client, team)
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	enableUsernameOverride := utils.Cfg.ServiceSettings.EnablePostUsernameOverride
+	enableIconOverride := utils.Cfg.ServiceSettings.EnablePostIconOverride
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+		utils.Cfg.ServiceSettings.EnablePostUsernameOverride = enableUsernameOverride
+		utils.Cfg.ServiceSettings.EnablePostIconOverride = enableIconOverride
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+	utils.Cfg.ServiceSettings.EnablePostUsernameOverride = true
+	utils.Cfg.ServiceSettings.EnablePostIconOverride = true
+
+	hook := &model.IncomingWebhook{ChannelId: channel1.Id, UsernamePattern: "alerts(-[a-z]+)?", FixedIconURL: "https://example.com/alerts.png"}
+	hook = Client.Must(Client.CreateIncomingWebhook(hook)).Data.(*model.IncomingWebhook)
+
+	url := "/hooks/" + hook.Id
+
+	if _, err := Client.DoPost(url, `{"text":"this is a test", "username":"admin"}`, "application/json"); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("should have failed - username doesn't match the pattern")
+	}
+
+	if _, err := Client.DoPost(url, `{"text":"this is a test", "username":"alerts-prod", "icon_url":"https://example.com/admin.png"}`, "application/json"); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := Client.Must(Client.GetPosts(channel1.Id, 0, 1, "")).Data.(*model.PostList)
+	post := posts.Posts[posts.Order[0]]
+	if post.Props["override_username"] != "alerts-prod" {
+		t.Fatal("should have used the username override")
+	}
+
+	if post.Props["override_icon_url"] != hook.FixedIconURL {
+		t.Fatal("should have used the hook's icon")
+	}
+
+	if _, err := Client.DoPost(url, `{"text":"this is a test"}`, "application/json"); err != nil {
+		t.Fatal("should allow the default username", err)
+	}
+}
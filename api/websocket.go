@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/websocket"
@@ -19,6 +20,13 @@ func InitWebSocket() {
 }
 
 func connect(c *Context, w http.ResponseWriter, r *http.Request) {
+	// Special-purpose clients can ask to only be sent some event types or the events of some channels
+	filterChannelIds, filterEvents, filterErr := app.ParseWebConnFilter(r.URL.Query())
+	if filterErr != nil {
+		c.Err = filterErr
+		return
+	}
+
 	originChecker := utils.GetOriginChecker(r)
 
 	upgrader := websocket.Upgrader{
@@ -36,6 +44,15 @@ func connect(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	wc := app.NewWebConn(ws, c.Session, c.T, c.Locale)
 
+	// Clients reconnecting after a short disconnect can ask for the events they missed
+	if connectionId := r.URL.Query().Get(model.WEBSOCKET_PARAM_CONNECTION_ID); len(connectionId) == 26 {
+		if lastSequence, err := strconv.ParseInt(r.URL.Query().Get(model.WEBSOCKET_PARAM_LAST_SEQUENCE), 10, 64); err == nil {
+			wc.RequestResume(connectionId, lastSequence)
+		}
+	}
+
+	wc.RestrictTo(filterChannelIds, filterEvents)
+
 	if len(c.Session.UserId) > 0 {
 		app.HubRegister(wc)
 	}
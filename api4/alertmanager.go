@@ -0,0 +1,228 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"io"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitAlertmanager() {
+	l4g.Debug(utils.T("api.alertmanager.init.debug"))
+
+	BaseRoutes.AlertmanagerHooks.Handle("", ApiSessionRequired(createAlertmanagerHook)).Methods("POST")
+	BaseRoutes.AlertmanagerHooks.Handle("", ApiSessionRequired(getAlertmanagerHooks)).Methods("GET")
+	BaseRoutes.AlertmanagerHook.Handle("", ApiSessionRequired(getAlertmanagerHook)).Methods("GET")
+	BaseRoutes.AlertmanagerHook.Handle("", ApiSessionRequired(updateAlertmanagerHook)).Methods("PUT")
+	BaseRoutes.AlertmanagerHook.Handle("", ApiSessionRequired(deleteAlertmanagerHook)).Methods("DELETE")
+
+	BaseRoutes.AlertmanagerHook.Handle("/alerts", ApiHandler(receiveAlertmanagerAlerts)).Methods("POST")
+}
+
+func createAlertmanagerHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	receiver := model.AlertmanagerReceiverFromJson(r.Body)
+	if receiver == nil {
+		c.SetInvalidParam("alertmanager_receiver")
+		return
+	}
+
+	channel, err := app.GetChannel(receiver.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionToTeam(c.Session, channel.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	if channel.Type != model.CHANNEL_OPEN && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_READ_CHANNEL) {
+		c.LogAudit("fail - bad channel permissions")
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	receiver.CreatorId = c.Session.UserId
+	receiver.TeamId = channel.TeamId
+
+	if rreceiver, err := app.CreateAlertmanagerReceiver(receiver); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		rreceiver.Sanitize()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rreceiver.ToJson()))
+	}
+}
+
+func getAlertmanagerHooks(c *Context, w http.ResponseWriter, r *http.Request) {
+	teamId := r.URL.Query().Get("team_id")
+	if len(teamId) != 26 {
+		c.SetInvalidParam("team_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	receivers, err := app.GetAlertmanagerReceiversForTeamPage(teamId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, receiver := range receivers {
+		receiver.Sanitize()
+	}
+
+	w.Write([]byte(model.AlertmanagerReceiverListToJson(receivers)))
+}
+
+func getAlertmanagerHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	receiver, err := app.GetAlertmanagerReceiver(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, receiver.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	receiver.Sanitize()
+	w.Write([]byte(receiver.ToJson()))
+}
+
+func updateAlertmanagerHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	updatedReceiver := model.AlertmanagerReceiverFromJson(r.Body)
+	if updatedReceiver == nil {
+		c.SetInvalidParam("alertmanager_receiver")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	oldReceiver, err := app.GetAlertmanagerReceiver(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, oldReceiver.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	if c.Session.UserId != oldReceiver.CreatorId && !app.SessionHasPermissionToTeam(c.Session, oldReceiver.TeamId, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)
+		return
+	}
+
+	channel, err := app.GetChannel(updatedReceiver.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if channel.TeamId != oldReceiver.TeamId {
+		c.Err = model.NewAppError("updateAlertmanagerHook", "api.webhook.team_mismatch.app_error", nil, "user_id="+c.Session.UserId, http.StatusBadRequest)
+		return
+	}
+
+	if channel.Type != model.CHANNEL_OPEN && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_READ_CHANNEL) {
+		c.LogAudit("fail - bad channel permissions")
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if rreceiver, err := app.UpdateAlertmanagerReceiver(oldReceiver, updatedReceiver); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		rreceiver.Sanitize()
+		w.Write([]byte(rreceiver.ToJson()))
+	}
+}
+
+func deleteAlertmanagerHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	receiver, err := app.GetAlertmanagerReceiver(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionToTeam(c.Session, receiver.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	if c.Session.UserId != receiver.CreatorId && !app.SessionHasPermissionToTeam(c.Session, receiver.TeamId, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)
+		return
+	}
+
+	if err := app.DeleteAlertmanagerReceiver(receiver.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+func receiveAlertmanagerAlerts(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	var payload io.Reader = r.Body
+	if c.Config.LogSettings.EnableWebhookDebugging {
+		var err error
+		payload, err = utils.DebugReader(payload, utils.T("api.webhook.incoming.debug"))
+		if err != nil {
+			c.Err = model.NewAppError("receiveAlertmanagerAlerts", "api.webhook.incoming.debug.error", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := app.HandleAlertmanagerMessage(c.Params.HookId, model.AlertmanagerMessageFromJson(payload)); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestAlertmanagerHooks(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	receiver := &model.AlertmanagerReceiver{
+		ChannelId:       th.BasicChannel.Id,
+		DisplayName:     "Prometheus",
+		AlertmanagerURL: "http://alertmanager:9093",
+		Credentials:     model.EncryptStringMap{"username": "admin", "password": "secret"},
+	}
+
+	rreceiver, resp := th.SystemAdminClient.CreateAlertmanagerHook(receiver)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rreceiver.TeamId != th.BasicTeam.Id || rreceiver.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("team and creator should have been set")
+	}
+
+	if rreceiver.Credentials["password"] != model.FAKE_SETTING {
+		t.Fatal("password should have been sanitized")
+	}
+
+	_, resp = Client.CreateAlertmanagerHook(receiver)
+	CheckForbiddenStatus(t, resp)
+
+	receivers, resp := th.SystemAdminClient.GetAlertmanagerHooksForTeam(th.BasicTeam.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if len(receivers) != 1 || receivers[0].Id != rreceiver.Id {
+		t.Fatal("should have returned the receiver")
+	}
+
+	rreceiver.DisplayName = "Renamed"
+	updated, resp := th.SystemAdminClient.UpdateAlertmanagerHook(rreceiver)
+	CheckNoError(t, resp)
+
+	if updated.DisplayName != "Renamed" {
+		t.Fatal("should have been updated")
+	}
+
+	msg := &model.AlertmanagerMessage{
+		GroupKey:     "{}:{alertname=\"HighLatency\"}",
+		Status:       model.ALERTMANAGER_STATUS_FIRING,
+		GroupLabels:  map[string]string{"alertname": "HighLatency"},
+		CommonLabels: map[string]string{"alertname": "HighLatency"},
+		Alerts:       []*model.AlertmanagerAlert{{Status: model.ALERTMANAGER_STATUS_FIRING, Labels: map[string]string{"alertname": "HighLatency"}}},
+	}
+
+	_, resp = Client.PostAlertmanagerMessage(rreceiver.Id, msg)
+	CheckNoError(t, resp)
+
+	_, resp = Client.PostAlertmanagerMessage(model.NewId(), msg)
+	CheckBadRequestStatus(t, resp)
+
+	msg.Status = "junk"
+	_, resp = Client.PostAlertmanagerMessage(rreceiver.Id, msg)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.DeleteAlertmanagerHook(rreceiver.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteAlertmanagerHook(rreceiver.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetAlertmanagerHook(rreceiver.Id)
+	CheckNotFoundStatus(t, resp)
+
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = false
+	_, resp = th.SystemAdminClient.CreateAlertmanagerHook(receiver)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestDoPostAction(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	silenced := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/silences" {
+			if username, password, ok := r.BasicAuth(); ok && username == "admin" && password == "secret" {
+				silenced = true
+			}
+		}
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer ts.Close()
+
+	receiver, resp := th.SystemAdminClient.CreateAlertmanagerHook(&model.AlertmanagerReceiver{
+		ChannelId:       th.BasicChannel.Id,
+		AlertmanagerURL: ts.URL,
+		Credentials:     model.EncryptStringMap{"username": "admin", "password": "secret"},
+	})
+	CheckNoError(t, resp)
+
+	msg := &model.AlertmanagerMessage{
+		GroupKey:    "{}:{alertname=\"DiskFull\"}",
+		Status:      model.ALERTMANAGER_STATUS_FIRING,
+		GroupLabels: map[string]string{"alertname": "DiskFull"},
+	}
+
+	_, resp = Client.PostAlertmanagerMessage(receiver.Id, msg)
+	CheckNoError(t, resp)
+
+	posts, resp := Client.GetPostsForChannel(th.BasicChannel.Id, 0, 1, "")
+	CheckNoError(t, resp)
+	post := posts.Posts[posts.Order[0]]
+
+	_, resp = Client.DoPostAction(post.Id, "junk", "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DoPostAction(post.Id, model.ALERTMANAGER_ACTION_SILENCE, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.DoPostAction(post.Id, model.ALERTMANAGER_ACTION_SILENCE, "2h")
+	CheckNoError(t, resp)
+
+	if !silenced {
+		t.Fatal("should have created a silence in alertmanager")
+	}
+
+	_, resp = Client.DoPostAction(post.Id, model.ALERTMANAGER_ACTION_SILENCE, "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DoPostAction(post.Id, model.ALERTMANAGER_ACTION_ACK, "")
+	CheckNoError(t, resp)
+
+	msg.Status = model.ALERTMANAGER_STATUS_RESOLVED
+	_, resp = Client.PostAlertmanagerMessage(receiver.Id, msg)
+	CheckNoError(t, resp)
+
+	rpost, resp := Client.GetPost(post.Id, "")
+	CheckNoError(t, resp)
+
+	if attachments := rpost.Attachments(); len(attachments) != 1 || attachments[0].Color != model.ALERTMANAGER_COLOR_RESOLVED {
+		t.Fatal("post should have been updated as resolved")
+	}
+
+	_, resp = th.SystemAdminClient.DoPostAction(post.Id, model.ALERTMANAGER_ACTION_ACK, "")
+	CheckNotFoundStatus(t, resp)
+}
@@ -33,6 +33,8 @@ type Routes struct {
 	TeamMembers        *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9_-]+}/members'
 	TeamMember         *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9_-]+}/members/{user_id:[A-Za-z0-9_-]+}'
 	TeamMembersForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/teams/members'
+	Invitations        *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9]+}/invitations'
+	Invitation         *mux.Router // 'api/v4/teams/{team_id:[A-Za-z0-9]+}/invitations/{invitation_id:[A-Za-z0-9]+}'
 
 	Channels                 *mux.Router // 'api/v4/channels'
 	Channel                  *mux.Router // 'api/v4/channels/{channel_id:[A-Za-z0-9]+}'
@@ -44,11 +46,16 @@ type Routes struct {
 	ChannelMember            *mux.Router // 'api/v4/channels/{channel_id:[A-Za-z0-9]+}/members/{user_id:[A-Za-z0-9]+}'
 	ChannelMembersForUser    *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/teams/{team_id:[A-Za-z0-9]+}/channels/members'
 
+	ChannelCategoriesForTeamForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/teams/{team_id:[A-Za-z0-9]+}/channels/categories'
+
 	Posts           *mux.Router // 'api/v4/posts'
 	Post            *mux.Router // 'api/v4/posts/{post_id:[A-Za-z0-9]+}'
 	PostsForChannel *mux.Router // 'api/v4/channels/{channel_id:[A-Za-z0-9]+}/posts'
 	PostsForUser    *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/posts'
 
+	ThreadForUser         *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/threads/{post_id:[A-Za-z0-9]+}'
+	ThreadsForTeamForUser *mux.Router // 'api/v4/users/{user_id:[A-Za-z0-9]+}/teams/{team_id:[A-Za-z0-9]+}/threads'
+
 	Files *mux.Router // 'api/v4/files'
 	File  *mux.Router // 'api/v4/files/{file_id:[A-Za-z0-9]+}'
 
@@ -64,6 +71,12 @@ type Routes struct {
 	OutgoingHooks *mux.Router // 'api/v4/hooks/outgoing'
 	OutgoingHook  *mux.Router // 'api/v4/hooks/outgoing/{hook_id:[A-Za-z0-9]+}'
 
+	AlertmanagerHooks *mux.Router // 'api/v4/hooks/alertmanager'
+	AlertmanagerHook  *mux.Router // 'api/v4/hooks/alertmanager/{hook_id:[A-Za-z0-9]+}'
+
+	PostEventHooks *mux.Router // 'api/v4/hooks/post_events'
+	PostEventHook  *mux.Router // 'api/v4/hooks/post_events/{hook_id:[A-Za-z0-9]+}'
+
 	Admin      *mux.Router // 'api/v4/admin'
 	OAuth      *mux.Router // 'api/v4/oauth'
 	SAML       *mux.Router // 'api/v4/saml'
@@ -76,6 +89,8 @@ type Routes struct {
 
 	System *mux.Router // 'api/v4/system'
 
+	Analytics *mux.Router // 'api/v4/analytics'
+
 	Preferences *mux.Router // 'api/v4/preferences'
 
 	License *mux.Router // 'api/v4/license'
@@ -85,7 +100,47 @@ type Routes struct {
 	Emojis *mux.Router // 'api/v4/emoji'
 	Emoji  *mux.Router // 'api/v4/emoji/{emoji_id:[A-Za-z0-9]+}'
 
+	Reactions *mux.Router // 'api/v4/reactions'
+
 	Webrtc *mux.Router // 'api/v4/webrtc'
+
+	Incidents *mux.Router // 'api/v4/incidents'
+	Incident  *mux.Router // 'api/v4/incidents/{incident_id:[A-Za-z0-9]+}'
+
+	FeatureFlags *mux.Router // 'api/v4/feature_flags'
+	FeatureFlag  *mux.Router // 'api/v4/feature_flags/{flag_name:[a-z0-9_\-]+}'
+
+	Experiments *mux.Router // 'api/v4/experiments'
+	Experiment  *mux.Router // 'api/v4/experiments/{experiment_name:[a-z0-9_\-]+}'
+
+	TeamTemplates *mux.Router // 'api/v4/team_templates'
+	TeamTemplate  *mux.Router // 'api/v4/team_templates/{template_id:[A-Za-z0-9]+}'
+	TeamCloneJobs *mux.Router // 'api/v4/team_clone_jobs'
+
+	Testing *mux.Router // 'api/v4/testing'
+
+	ScheduledPosts *mux.Router // 'api/v4/scheduled_posts'
+	ScheduledPost  *mux.Router // 'api/v4/scheduled_posts/{scheduled_post_id:[A-Za-z0-9]+}'
+
+	Bots *mux.Router // 'api/v4/bots'
+	Bot  *mux.Router // 'api/v4/bots/{bot_user_id:[A-Za-z0-9]+}'
+
+	ArchiveExports *mux.Router // 'api/v4/exports'
+	ArchiveExport  *mux.Router // 'api/v4/exports/{archive_export_id:[A-Za-z0-9]+}'
+
+	Reports *mux.Router // 'api/v4/reports'
+
+	BulkEmails *mux.Router // 'api/v4/bulk_emails'
+	BulkEmail  *mux.Router // 'api/v4/bulk_emails/{bulk_email_id:[A-Za-z0-9]+}'
+
+	RetentionPolicies *mux.Router // 'api/v4/retention_policies'
+	RetentionPolicy   *mux.Router // 'api/v4/retention_policies/{policy_id:[A-Za-z0-9]+}'
+
+	LegalHolds *mux.Router // 'api/v4/legal_holds'
+	LegalHold  *mux.Router // 'api/v4/legal_holds/{legal_hold_id:[A-Za-z0-9]+}'
+
+	RemoteClusters *mux.Router // 'api/v4/remotecluster'
+	RemoteCluster  *mux.Router // 'api/v4/remotecluster/{remote_id:[A-Za-z0-9]+}'
 }
 
 var BaseRoutes *Routes
@@ -113,6 +168,8 @@ func InitApi(full bool) {
 	BaseRoutes.TeamMembers = BaseRoutes.Team.PathPrefix("/members").Subrouter()
 	BaseRoutes.TeamMember = BaseRoutes.TeamMembers.PathPrefix("/{user_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.TeamMembersForUser = BaseRoutes.User.PathPrefix("/teams/members").Subrouter()
+	BaseRoutes.Invitations = BaseRoutes.Team.PathPrefix("/invitations").Subrouter()
+	BaseRoutes.Invitation = BaseRoutes.Invitations.PathPrefix("/{invitation_id:[A-Za-z0-9]+}").Subrouter()
 
 	BaseRoutes.Channels = BaseRoutes.ApiRoot.PathPrefix("/channels").Subrouter()
 	BaseRoutes.Channel = BaseRoutes.Channels.PathPrefix("/{channel_id:[A-Za-z0-9]+}").Subrouter()
@@ -123,12 +180,16 @@ func InitApi(full bool) {
 	BaseRoutes.ChannelMembers = BaseRoutes.Channel.PathPrefix("/members").Subrouter()
 	BaseRoutes.ChannelMember = BaseRoutes.ChannelMembers.PathPrefix("/{user_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.ChannelMembersForUser = BaseRoutes.User.PathPrefix("/teams/{team_id:[A-Za-z0-9]+}/channels/members").Subrouter()
+	BaseRoutes.ChannelCategoriesForTeamForUser = BaseRoutes.TeamForUser.PathPrefix("/channels/categories").Subrouter()
 
 	BaseRoutes.Posts = BaseRoutes.ApiRoot.PathPrefix("/posts").Subrouter()
 	BaseRoutes.Post = BaseRoutes.Posts.PathPrefix("/{post_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.PostsForChannel = BaseRoutes.Channel.PathPrefix("/posts").Subrouter()
 	BaseRoutes.PostsForUser = BaseRoutes.User.PathPrefix("/posts").Subrouter()
 
+	BaseRoutes.ThreadForUser = BaseRoutes.User.PathPrefix("/threads/{post_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.ThreadsForTeamForUser = BaseRoutes.TeamForUser.PathPrefix("/threads").Subrouter()
+
 	BaseRoutes.Files = BaseRoutes.ApiRoot.PathPrefix("/files").Subrouter()
 	BaseRoutes.File = BaseRoutes.Files.PathPrefix("/{file_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.PublicFile = BaseRoutes.Root.PathPrefix("/files/{file_id:[A-Za-z0-9]+}/public").Subrouter()
@@ -142,6 +203,10 @@ func InitApi(full bool) {
 	BaseRoutes.IncomingHook = BaseRoutes.IncomingHooks.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.OutgoingHooks = BaseRoutes.Hooks.PathPrefix("/outgoing").Subrouter()
 	BaseRoutes.OutgoingHook = BaseRoutes.OutgoingHooks.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.AlertmanagerHooks = BaseRoutes.Hooks.PathPrefix("/alertmanager").Subrouter()
+	BaseRoutes.AlertmanagerHook = BaseRoutes.AlertmanagerHooks.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.PostEventHooks = BaseRoutes.Hooks.PathPrefix("/post_events").Subrouter()
+	BaseRoutes.PostEventHook = BaseRoutes.PostEventHooks.PathPrefix("/{hook_id:[A-Za-z0-9]+}").Subrouter()
 
 	BaseRoutes.SAML = BaseRoutes.ApiRoot.PathPrefix("/saml").Subrouter()
 	BaseRoutes.OAuth = BaseRoutes.ApiRoot.PathPrefix("/oauth").Subrouter()
@@ -151,6 +216,7 @@ func InitApi(full bool) {
 	BaseRoutes.LDAP = BaseRoutes.ApiRoot.PathPrefix("/ldap").Subrouter()
 	BaseRoutes.Brand = BaseRoutes.ApiRoot.PathPrefix("/brand").Subrouter()
 	BaseRoutes.System = BaseRoutes.ApiRoot.PathPrefix("/system").Subrouter()
+	BaseRoutes.Analytics = BaseRoutes.ApiRoot.PathPrefix("/analytics").Subrouter()
 	BaseRoutes.Preferences = BaseRoutes.User.PathPrefix("/preferences").Subrouter()
 	BaseRoutes.License = BaseRoutes.ApiRoot.PathPrefix("/license").Subrouter()
 	BaseRoutes.Public = BaseRoutes.ApiRoot.PathPrefix("/public").Subrouter()
@@ -158,18 +224,67 @@ func InitApi(full bool) {
 	BaseRoutes.Emojis = BaseRoutes.ApiRoot.PathPrefix("/emoji").Subrouter()
 	BaseRoutes.Emoji = BaseRoutes.Emojis.PathPrefix("/{emoji_id:[A-Za-z0-9]+}").Subrouter()
 
+	BaseRoutes.Reactions = BaseRoutes.ApiRoot.PathPrefix("/reactions").Subrouter()
+
 	BaseRoutes.Webrtc = BaseRoutes.ApiRoot.PathPrefix("/webrtc").Subrouter()
 
+	BaseRoutes.Incidents = BaseRoutes.ApiRoot.PathPrefix("/incidents").Subrouter()
+	BaseRoutes.Incident = BaseRoutes.Incidents.PathPrefix("/{incident_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.FeatureFlags = BaseRoutes.ApiRoot.PathPrefix("/feature_flags").Subrouter()
+	BaseRoutes.FeatureFlag = BaseRoutes.FeatureFlags.PathPrefix("/{flag_name:[a-z0-9_\\-]+}").Subrouter()
+
+	BaseRoutes.Experiments = BaseRoutes.ApiRoot.PathPrefix("/experiments").Subrouter()
+	BaseRoutes.Experiment = BaseRoutes.Experiments.PathPrefix("/{experiment_name:[a-z0-9_\\-]+}").Subrouter()
+
+	BaseRoutes.TeamTemplates = BaseRoutes.ApiRoot.PathPrefix("/team_templates").Subrouter()
+	BaseRoutes.TeamTemplate = BaseRoutes.TeamTemplates.PathPrefix("/{template_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.TeamCloneJobs = BaseRoutes.ApiRoot.PathPrefix("/team_clone_jobs").Subrouter()
+
+	BaseRoutes.Testing = BaseRoutes.ApiRoot.PathPrefix("/testing").Subrouter()
+
+	BaseRoutes.ScheduledPosts = BaseRoutes.ApiRoot.PathPrefix("/scheduled_posts").Subrouter()
+	BaseRoutes.ScheduledPost = BaseRoutes.ScheduledPosts.PathPrefix("/{scheduled_post_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.Bots = BaseRoutes.ApiRoot.PathPrefix("/bots").Subrouter()
+	BaseRoutes.Bot = BaseRoutes.Bots.PathPrefix("/{bot_user_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.ArchiveExports = BaseRoutes.ApiRoot.PathPrefix("/exports").Subrouter()
+	BaseRoutes.ArchiveExport = BaseRoutes.ArchiveExports.PathPrefix("/{archive_export_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.Reports = BaseRoutes.ApiRoot.PathPrefix("/reports").Subrouter()
+
+	BaseRoutes.BulkEmails = BaseRoutes.ApiRoot.PathPrefix("/bulk_emails").Subrouter()
+	BaseRoutes.BulkEmail = BaseRoutes.BulkEmails.PathPrefix("/{bulk_email_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.RetentionPolicies = BaseRoutes.ApiRoot.PathPrefix("/retention_policies").Subrouter()
+	BaseRoutes.RetentionPolicy = BaseRoutes.RetentionPolicies.PathPrefix("/{policy_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.LegalHolds = BaseRoutes.ApiRoot.PathPrefix("/legal_holds").Subrouter()
+	BaseRoutes.LegalHold = BaseRoutes.LegalHolds.PathPrefix("/{legal_hold_id:[A-Za-z0-9]+}").Subrouter()
+
+	BaseRoutes.RemoteClusters = BaseRoutes.ApiRoot.PathPrefix("/remotecluster").Subrouter()
+	BaseRoutes.RemoteCluster = BaseRoutes.RemoteClusters.PathPrefix("/{remote_id:[A-Za-z0-9]+}").Subrouter()
+
 	InitUser()
 	InitTeam()
+	InitInvitation()
 	InitChannel()
+	InitChannelSuccessor()
 	InitPost()
 	InitFile()
 	InitSystem()
 	InitWebhook()
+	InitAlertmanager()
+	InitPostEventHook()
 	InitPreference()
 	InitSaml()
 	InitCompliance()
+	InitArchiveExport()
+	InitInactivityReport()
+	InitBulkEmail()
+	InitRetentionPolicy()
+	InitLegalHold()
 	InitCluster()
 	InitLdap()
 	InitBrand()
@@ -177,6 +292,22 @@ func InitApi(full bool) {
 	InitStatus()
 	InitWebSocket()
 	InitEmoji()
+	InitReaction()
+	InitIncident()
+	InitFeatureFlag()
+	InitExperiment()
+	InitTeamTemplate()
+	InitScheduledPost()
+	InitDraft()
+	InitDevice()
+	InitThread()
+	InitChannelCategory()
+	InitTeamQuota()
+	InitRemoteCluster()
+	InitChannelAutoArchive()
+	InitBot()
+	InitGraphQL()
+	InitTesting()
 
 	app.Srv.Router.Handle("/api/v4/{anything:.*}", http.HandlerFunc(Handle404))
 
@@ -185,6 +316,21 @@ func InitApi(full bool) {
 		utils.InitHTML()
 
 		app.InitEmailBatching()
+		app.InitScheduledPosts()
+		app.InitStatusExpiry()
+		app.InitCacheMetrics()
+		app.InitCacheSizing()
+		app.InitEmojiUsage()
+		app.InitAuditSinks()
+		app.InitPushProxyHealthCheck()
+		app.InitSearchEngine()
+		app.InitPostIntegrity()
+		app.InitLdapGroupSync()
+		app.InitInvitationExpiry()
+		app.InitDataRetention()
+		app.InitAutoResponders()
+		app.InitClusterHealthCheck()
+		app.InitChannelAutoArchiving()
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -38,9 +39,22 @@ type TestHelper struct {
 
 	SystemAdminClient *model.Client4
 	SystemAdminUser   *model.User
+
+	// Namespace is added to the names of the users and teams created by the helper so that tests
+	// running in parallel can tell their own apart.
+	Namespace string
+
+	createdLock    sync.Mutex
+	createdUserIds []string
+	createdTeamIds []string
 }
 
+var setupLock sync.Mutex
+
 func SetupEnterprise() *TestHelper {
+	setupLock.Lock()
+	defer setupLock.Unlock()
+
 	if app.Srv == nil {
 		utils.TranslationsPreInit()
 		utils.LoadConfig("config.json")
@@ -68,12 +82,16 @@ func SetupEnterprise() *TestHelper {
 	}
 
 	th := &TestHelper{}
+	th.Namespace = strings.ToLower(model.NewRandomString(6))
 	th.Client = th.CreateClient()
 	th.SystemAdminClient = th.CreateClient()
 	return th
 }
 
 func Setup() *TestHelper {
+	setupLock.Lock()
+	defer setupLock.Unlock()
+
 	if app.Srv == nil {
 		utils.TranslationsPreInit()
 		utils.LoadConfig("config.json")
@@ -99,14 +117,25 @@ func Setup() *TestHelper {
 	}
 
 	th := &TestHelper{}
+	th.Namespace = strings.ToLower(model.NewRandomString(6))
 	th.Client = th.CreateClient()
 	th.SystemAdminClient = th.CreateClient()
 	return th
 }
 
+// SetupParallel marks the test as parallel and returns a helper for it. Parallel tests only start
+// once the serial tests are done, so they must clean up with the helper's TearDown, which only
+// removes what the helper created, instead of the global TearDown.
+func SetupParallel(t *testing.T) *TestHelper {
+	t.Parallel()
+	return Setup()
+}
+
 func TearDown() {
 	utils.DisableDebugLogForTest()
 
+	app.Srv.SetConfigService(nil)
+
 	options := map[string]bool{}
 	options[store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME] = true
 	if result := <-app.Srv.Store.User().Search("", "fakeuser", options); result.Err != nil {
@@ -136,6 +165,46 @@ func TearDown() {
 	utils.EnableDebugLogForTest()
 }
 
+// UseStaticConfig makes the server run with a copy of the config that the test can change without
+// touching utils.Cfg. The server goes back to the loaded config when the test is torn down.
+func (me *TestHelper) UseStaticConfig() *model.Config {
+	cfg := utils.CloneConfig(utils.Cfg)
+	app.Srv.SetConfigService(utils.NewStaticConfigService(cfg))
+	return cfg
+}
+
+// TearDown permanently deletes the users and teams created by the helper.
+func (me *TestHelper) TearDown() {
+	utils.DisableDebugLogForTest()
+
+	app.Srv.SetConfigService(nil)
+
+	me.createdLock.Lock()
+	userIds := me.createdUserIds
+	teamIds := me.createdTeamIds
+	me.createdUserIds = nil
+	me.createdTeamIds = nil
+	me.createdLock.Unlock()
+
+	for _, userId := range userIds {
+		if result := <-app.Srv.Store.User().Get(userId); result.Err == nil {
+			if err := app.PermanentDeleteUser(result.Data.(*model.User)); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+
+	for _, teamId := range teamIds {
+		if result := <-app.Srv.Store.Team().Get(teamId); result.Err == nil {
+			if err := app.PermanentDeleteTeam(result.Data.(*model.Team)); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+
+	utils.EnableDebugLogForTest()
+}
+
 func (me *TestHelper) InitBasic() *TestHelper {
 	me.TeamAdminUser = me.CreateUser()
 	me.LoginTeamAdmin()
@@ -188,7 +257,7 @@ func (me *TestHelper) CreateTeamWithClient(client *model.Client4) *model.Team {
 	id := model.NewId()
 	team := &model.Team{
 		DisplayName: "dn_" + id,
-		Name:        GenerateTestTeamName(),
+		Name:        "faketeam" + me.Namespace + model.NewRandomString(6),
 		Email:       GenerateTestEmail(),
 		Type:        model.TEAM_OPEN,
 	}
@@ -196,6 +265,13 @@ func (me *TestHelper) CreateTeamWithClient(client *model.Client4) *model.Team {
 	utils.DisableDebugLogForTest()
 	rteam, _ := client.CreateTeam(team)
 	utils.EnableDebugLogForTest()
+
+	if rteam != nil {
+		me.createdLock.Lock()
+		me.createdTeamIds = append(me.createdTeamIds, rteam.Id)
+		me.createdLock.Unlock()
+	}
+
 	return rteam
 }
 
@@ -204,7 +280,7 @@ func (me *TestHelper) CreateUserWithClient(client *model.Client4) *model.User {
 
 	user := &model.User{
 		Email:     GenerateTestEmail(),
-		Username:  GenerateTestUsername(),
+		Username:  "fakeuser" + me.Namespace + model.NewRandomString(7),
 		Nickname:  "nn_" + id,
 		FirstName: "f_" + id,
 		LastName:  "l_" + id,
@@ -216,6 +292,11 @@ func (me *TestHelper) CreateUserWithClient(client *model.Client4) *model.User {
 	ruser.Password = "Password1"
 	VerifyUserEmail(ruser.Id)
 	utils.EnableDebugLogForTest()
+
+	me.createdLock.Lock()
+	me.createdUserIds = append(me.createdUserIds, ruser.Id)
+	me.createdLock.Unlock()
+
 	return ruser
 }
 
@@ -345,6 +426,23 @@ func (me *TestHelper) LoginSystemAdminWithClient(client *model.Client4) {
 	utils.EnableDebugLogForTest()
 }
 
+// LoginBasicWithOAuthScope logs the client in with an OAuth token for the basic user that was
+// granted the given scope.
+func (me *TestHelper) LoginBasicWithOAuthScope(client *model.Client4, scope string) {
+	session := &model.Session{UserId: me.BasicUser.Id, Roles: me.BasicUser.GetRawRoles(), IsOAuth: true}
+	session.SetExpireInDays(1)
+	session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, scope)
+
+	utils.DisableDebugLogForTest()
+	session, err := app.CreateSession(session)
+	if err != nil {
+		panic(err)
+	}
+	utils.EnableDebugLogForTest()
+
+	client.SetOAuthToken(session.Token)
+}
+
 func (me *TestHelper) UpdateActiveUser(user *model.User, active bool) {
 	utils.DisableDebugLogForTest()
 
@@ -0,0 +1,126 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"github.com/mssola/user_agent"
+)
+
+func InitArchiveExport() {
+	l4g.Debug(utils.T("api.archive_export.init.debug"))
+
+	BaseRoutes.ArchiveExports.Handle("", ApiSessionRequired(createArchiveExport)).Methods("POST")
+	BaseRoutes.ArchiveExports.Handle("", ApiSessionRequired(getArchiveExports)).Methods("GET")
+	BaseRoutes.ArchiveExport.Handle("", ApiSessionRequired(getArchiveExport)).Methods("GET")
+	BaseRoutes.ArchiveExport.Handle("/download", ApiSessionRequired(downloadArchiveExport)).Methods("GET")
+}
+
+func createArchiveExport(c *Context, w http.ResponseWriter, r *http.Request) {
+	export := model.ArchiveExportFromJson(r.Body)
+	if export == nil {
+		c.SetInvalidParam("export")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	export.UserId = c.Session.UserId
+
+	rexport, err := app.CreateArchiveExport(export)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + rexport.Id + " type=" + rexport.Type + " team_id=" + rexport.TeamId + " channel_id=" + rexport.ChannelId)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rexport.ToJson()))
+}
+
+func getArchiveExports(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	exports, err := app.GetArchiveExports(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ArchiveExportListToJson(exports)))
+}
+
+func getArchiveExport(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireArchiveExportId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	export, err := app.GetArchiveExport(c.Params.ArchiveExportId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(export.ToJson()))
+}
+
+func downloadArchiveExport(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireArchiveExportId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	export, err := app.GetArchiveExport(c.Params.ArchiveExportId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	data, err := app.GetArchiveExportFile(export)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("downloaded id=" + export.Id)
+
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Del("Content-Type") // Content-Type will be set automatically by the http writer
+
+	// attach extra headers to trigger a download on IE, Edge, and Safari
+	ua := user_agent.New(r.UserAgent())
+	bname, _ := ua.Browser()
+
+	w.Header().Set("Content-Disposition", "attachment;filename=\""+export.FileName()+"\"")
+
+	if bname == "Edge" || bname == "Internet Explorer" || bname == "Safari" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+
+	w.Write(data)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func waitForArchiveExport(t *testing.T, client *model.Client4, exportId string) *model.ArchiveExport {
+	for i := 0; i < 50; i++ {
+		export, resp := client.GetArchiveExport(exportId)
+		CheckNoError(t, resp)
+
+		if export.Status == model.ARCHIVE_EXPORT_STATUS_SUCCESS || export.Status == model.ARCHIVE_EXPORT_STATUS_FAILED {
+			return export
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatal("archive export did not finish")
+	return nil
+}
+
+func readArchiveExportFile(t *testing.T, data []byte, name string) string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name == name {
+			r, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return string(b)
+		}
+	}
+
+	t.Fatal("archive is missing " + name)
+	return ""
+}
+
+func TestArchiveExport(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.CreateArchiveExport(&model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_CHANNEL, ChannelId: th.BasicChannel.Id})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateArchiveExport(&model.ArchiveExport{Type: "junk", ChannelId: th.BasicChannel.Id})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateArchiveExport(&model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_CHANNEL, ChannelId: model.NewId()})
+	CheckNotFoundStatus(t, resp)
+
+	export, resp := th.SystemAdminClient.CreateArchiveExport(&model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_CHANNEL, ChannelId: th.BasicChannel.Id})
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if export.UserId != th.SystemAdminUser.Id || export.TeamId != th.BasicTeam.Id {
+		t.Fatal("should have saved who requested the export and the team of the channel")
+	}
+
+	export = waitForArchiveExport(t, th.SystemAdminClient, export.Id)
+	if export.Status != model.ARCHIVE_EXPORT_STATUS_SUCCESS || export.Progress != 100 || export.Size == 0 {
+		t.Fatal("export should have succeeded", export.Error)
+	}
+
+	data, resp := th.SystemAdminClient.DownloadArchiveExport(export.Id)
+	CheckNoError(t, resp)
+
+	if posts := readArchiveExportFile(t, data, "posts.jsonl"); !strings.Contains(posts, th.BasicPost.Id) {
+		t.Fatal("should have exported the posts of the channel")
+	}
+
+	if members := readArchiveExportFile(t, data, "members.jsonl"); !strings.Contains(members, th.BasicUser.Id) {
+		t.Fatal("should have exported the members of the channel")
+	}
+
+	if users := readArchiveExportFile(t, data, "users.jsonl"); !strings.Contains(users, th.BasicUser.Username) {
+		t.Fatal("should have exported the users of the channel")
+	}
+
+	team, resp := th.SystemAdminClient.CreateArchiveExport(&model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_TEAM, TeamId: th.BasicTeam.Id})
+	CheckNoError(t, resp)
+
+	team = waitForArchiveExport(t, th.SystemAdminClient, team.Id)
+	if team.Status != model.ARCHIVE_EXPORT_STATUS_SUCCESS {
+		t.Fatal("export should have succeeded", team.Error)
+	}
+
+	data, resp = th.SystemAdminClient.DownloadArchiveExport(team.Id)
+	CheckNoError(t, resp)
+
+	if channels := readArchiveExportFile(t, data, "channels.jsonl"); !strings.Contains(channels, th.BasicChannel.Id) || !strings.Contains(channels, th.BasicChannel2.Id) {
+		t.Fatal("should have exported the channels of the team")
+	}
+
+	exports, resp := th.SystemAdminClient.GetArchiveExports(0, 100)
+	CheckNoError(t, resp)
+
+	if len(exports) < 2 || exports[0].Id != team.Id {
+		t.Fatal("should have listed the most recent export first")
+	}
+
+	_, resp = Client.GetArchiveExports(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetArchiveExport(export.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DownloadArchiveExport(export.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetArchiveExport(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
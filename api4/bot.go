@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitBot() {
+	l4g.Debug(utils.T("api.bot.init.debug"))
+
+	BaseRoutes.Bots.Handle("", ApiSessionRequired(createBot)).Methods("POST")
+	BaseRoutes.Bots.Handle("", ApiSessionRequired(getBots)).Methods("GET")
+	BaseRoutes.Bot.Handle("", ApiSessionRequired(getBot)).Methods("GET")
+	BaseRoutes.Bot.Handle("", ApiSessionRequired(disableBot)).Methods("DELETE")
+
+	BaseRoutes.Bot.Handle("/tokens", ApiSessionRequired(createBotAccessToken)).Methods("POST")
+	BaseRoutes.Bot.Handle("/tokens", ApiSessionRequired(getBotAccessTokens)).Methods("GET")
+	BaseRoutes.Bot.Handle("/tokens/{token_id:[A-Za-z0-9]+}", ApiSessionRequired(revokeBotAccessToken)).Methods("DELETE")
+}
+
+func createBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := model.BotFromJson(r.Body)
+	if bot == nil {
+		c.SetInvalidParam("bot")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_CREATE_BOT) {
+		c.SetPermissionError(model.PERMISSION_CREATE_BOT)
+		return
+	}
+
+	bot.OwnerId = c.Session.UserId
+
+	if rbot, err := app.CreateBot(bot); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("user_id=" + rbot.UserId)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rbot.ToJson()))
+	}
+}
+
+func getBots(c *Context, w http.ResponseWriter, r *http.Request) {
+	// Only list the bots owned by the user unless they can manage all bots
+	ownerId := c.Session.UserId
+	if app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		ownerId = ""
+	}
+
+	if bots, err := app.GetBotsPage(ownerId, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.BotListToJson(bots)))
+	}
+}
+
+func getBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBotUserId()
+	if c.Err != nil {
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(bot.ToJson()))
+}
+
+func disableBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBotUserId()
+	if c.Err != nil {
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.DisableBot(bot); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("user_id=" + bot.UserId)
+	ReturnStatusOK(w)
+}
+
+func createBotAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBotUserId()
+	if c.Err != nil {
+		return
+	}
+
+	token := model.UserAccessTokenFromJson(r.Body)
+	if token == nil {
+		c.SetInvalidParam("token")
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	token.UserId = bot.UserId
+
+	if rtoken, err := app.CreateUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("token_id=" + rtoken.Id)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rtoken.ToJson()))
+	}
+}
+
+func getBotAccessTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBotUserId()
+	if c.Err != nil {
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if tokens, err := app.GetUserAccessTokensForUser(bot.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.UserAccessTokenListToJson(tokens)))
+	}
+}
+
+func revokeBotAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBotUserId().RequireTokenId()
+	if c.Err != nil {
+		return
+	}
+
+	bot := getBotForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	token, err := app.GetUserAccessToken(c.Params.TokenId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if token.UserId != bot.UserId {
+		c.SetInvalidUrlParam("token_id")
+		return
+	}
+
+	if err := app.RevokeUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("token_id=" + token.Id)
+	ReturnStatusOK(w)
+}
+
+// getBotForSession returns the bot from the request if it's owned by the session's user or if they
+// can manage all bots.
+func getBotForSession(c *Context) *model.Bot {
+	bot, err := app.GetBot(c.Params.BotUserId, false)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if bot.OwnerId != c.Session.UserId && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_BOTS)
+		return nil
+	}
+
+	return bot
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateBot(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableBotAccountCreation := *utils.Cfg.ServiceSettings.EnableBotAccountCreation
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableBotAccountCreation = enableBotAccountCreation
+	}()
+
+	bot := &model.Bot{Username: GenerateTestUsername(), DisplayName: "Test Bot", Description: "a bot"}
+
+	*utils.Cfg.ServiceSettings.EnableBotAccountCreation = false
+	_, resp := Client.CreateBot(bot)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableBotAccountCreation = true
+	rbot, resp := Client.CreateBot(bot)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rbot.Username != bot.Username || rbot.DisplayName != bot.DisplayName || rbot.OwnerId != th.BasicUser.Id {
+		t.Fatal("should have created the bot owned by the user")
+	}
+
+	if _, resp = Client.Login(bot.Username, "password"); resp.Error == nil {
+		t.Fatal("bots should not be able to log in")
+	}
+	CheckUnauthorizedStatus(t, resp)
+
+	th.LoginBasic()
+
+	_, resp = Client.CreateBot(&model.Bot{Username: bot.Username})
+	CheckBadRequestStatus(t, resp)
+
+	bots, resp := Client.GetBots(0, 60)
+	CheckNoError(t, resp)
+	if len(bots) != 1 || bots[0].UserId != rbot.UserId {
+		t.Fatal("should have returned the bots owned by the user")
+	}
+
+	_, resp = Client.GetBot(rbot.UserId)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetBot(rbot.UserId)
+	CheckNoError(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.GetBot(rbot.UserId)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DisableBot(rbot.UserId)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	_, resp = Client.DisableBot(rbot.UserId)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetBot(rbot.UserId)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestBotAccessTokens(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableBotAccountCreation := *utils.Cfg.ServiceSettings.EnableBotAccountCreation
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableBotAccountCreation = enableBotAccountCreation
+	}()
+	*utils.Cfg.ServiceSettings.EnableBotAccountCreation = true
+
+	bot, resp := Client.CreateBot(&model.Bot{Username: GenerateTestUsername()})
+	CheckNoError(t, resp)
+
+	token, resp := Client.CreateBotAccessToken(bot.UserId, "test token")
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if len(token.Token) == 0 || token.UserId != bot.UserId {
+		t.Fatal("should have returned the token")
+	}
+
+	BotClient := th.CreateClient()
+	BotClient.SetOAuthToken(token.Token)
+
+	if me, resp := BotClient.GetMe(""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if me.Id != bot.UserId {
+		t.Fatal("should have authenticated as the bot")
+	}
+
+	tokens, resp := Client.GetBotAccessTokens(bot.UserId)
+	CheckNoError(t, resp)
+	if len(tokens) != 1 || tokens[0].Id != token.Id || tokens[0].Token != "" {
+		t.Fatal("should have returned the tokens without their values")
+	}
+
+	th.LoginBasic2()
+	_, resp = Client.CreateBotAccessToken(bot.UserId, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RevokeBotAccessToken(bot.UserId, token.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	_, resp = Client.RevokeBotAccessToken(bot.UserId, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.RevokeBotAccessToken(bot.UserId, token.Id)
+	CheckNoError(t, resp)
+
+	_, resp = BotClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+}
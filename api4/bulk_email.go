@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitBulkEmail() {
+	l4g.Debug(utils.T("api.bulk_email.init.debug"))
+
+	BaseRoutes.BulkEmails.Handle("", ApiSessionRequired(createBulkEmail)).Methods("POST")
+	BaseRoutes.BulkEmails.Handle("", ApiSessionRequired(getBulkEmails)).Methods("GET")
+	BaseRoutes.BulkEmail.Handle("", ApiSessionRequired(getBulkEmail)).Methods("GET")
+	BaseRoutes.BulkEmail.Handle("/cancel", ApiSessionRequired(cancelBulkEmail)).Methods("POST")
+	BaseRoutes.BulkEmail.Handle("/recipients", ApiSessionRequired(getBulkEmailRecipients)).Methods("GET")
+}
+
+func createBulkEmail(c *Context, w http.ResponseWriter, r *http.Request) {
+	bulkEmail := model.BulkEmailFromJson(r.Body)
+	if bulkEmail == nil {
+		c.SetInvalidParam("bulk_email")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	bulkEmail.UserId = c.Session.UserId
+
+	rbulkEmail, err := app.CreateBulkEmail(bulkEmail)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + rbulkEmail.Id + " segment=" + rbulkEmail.Segment + " team_id=" + rbulkEmail.TeamId)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rbulkEmail.ToJson()))
+}
+
+func getBulkEmails(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	bulkEmails, err := app.GetBulkEmails(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.BulkEmailListToJson(bulkEmails)))
+}
+
+func getBulkEmail(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBulkEmailId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	bulkEmail, err := app.GetBulkEmail(c.Params.BulkEmailId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(bulkEmail.ToJson()))
+}
+
+func cancelBulkEmail(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBulkEmailId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	bulkEmail, err := app.GetBulkEmail(c.Params.BulkEmailId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if bulkEmail, err = app.CancelBulkEmail(bulkEmail); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + bulkEmail.Id)
+	w.Write([]byte(bulkEmail.ToJson()))
+}
+
+func getBulkEmailRecipients(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireBulkEmailId()
+	if c.Err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if len(status) > 0 && !model.IsValidBulkEmailRecipientStatus(status) {
+		c.SetInvalidParam("status")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	recipients, err := app.GetBulkEmailRecipients(c.Params.BulkEmailId, status, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.BulkEmailRecipientListToJson(recipients)))
+}
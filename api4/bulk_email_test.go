@@ -0,0 +1,113 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func waitForBulkEmail(t *testing.T, client *model.Client4, bulkEmailId string) *model.BulkEmail {
+	for i := 0; i < 50; i++ {
+		bulkEmail, resp := client.GetBulkEmail(bulkEmailId)
+		CheckNoError(t, resp)
+
+		if bulkEmail.IsFinished() {
+			return bulkEmail
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatal("bulk email did not finish")
+	return nil
+}
+
+func TestBulkEmail(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	maxPerMinute := *utils.Cfg.EmailSettings.BulkEmailMaxPerMinute
+	defer func() {
+		*utils.Cfg.EmailSettings.BulkEmailMaxPerMinute = maxPerMinute
+	}()
+	*utils.Cfg.EmailSettings.BulkEmailMaxPerMinute = 60000
+
+	<-app.Srv.Store.Preference().Save(&model.Preferences{{UserId: th.BasicUser2.Id, Category: model.PREFERENCE_CATEGORY_NOTIFICATIONS, Name: model.PREFERENCE_NAME_BULK_EMAIL, Value: "false"}})
+
+	bulkEmail := &model.BulkEmail{
+		Segment:           model.BULK_EMAIL_SEGMENT_TEAM,
+		TeamId:            th.BasicTeam.Id,
+		Message:           "Hello {{username}}",
+		SendDirectMessage: true,
+	}
+
+	_, resp := Client.CreateBulkEmail(bulkEmail)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateBulkEmail(&model.BulkEmail{Segment: "junk", Message: "Hello", SendDirectMessage: true})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateBulkEmail(&model.BulkEmail{Segment: model.BULK_EMAIL_SEGMENT_TEAM, TeamId: model.NewId(), Message: "Hello", SendDirectMessage: true})
+	CheckNotFoundStatus(t, resp)
+
+	rbulkEmail, resp := th.SystemAdminClient.CreateBulkEmail(bulkEmail)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rbulkEmail.UserId != th.SystemAdminUser.Id {
+		t.Fatal("should have saved who created the bulk email")
+	}
+
+	rbulkEmail = waitForBulkEmail(t, th.SystemAdminClient, rbulkEmail.Id)
+	if rbulkEmail.Status != model.BULK_EMAIL_STATUS_SUCCESS {
+		t.Fatal("should have succeeded", rbulkEmail.Status, rbulkEmail.Error)
+	} else if rbulkEmail.SentCount == 0 || rbulkEmail.SkippedCount != 1 || rbulkEmail.TotalRecipients != rbulkEmail.SentCount+rbulkEmail.SkippedCount+rbulkEmail.FailedCount {
+		t.Fatal("should have counted the recipients", rbulkEmail.TotalRecipients, rbulkEmail.SentCount, rbulkEmail.SkippedCount, rbulkEmail.FailedCount)
+	}
+
+	skipped, resp := th.SystemAdminClient.GetBulkEmailRecipients(rbulkEmail.Id, model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED, 0, 100)
+	CheckNoError(t, resp)
+
+	if len(skipped) != 1 || skipped[0].UserId != th.BasicUser2.Id || skipped[0].Error != model.BULK_EMAIL_SKIPPED_UNSUBSCRIBED {
+		t.Fatal("should have skipped the user that unsubscribed")
+	}
+
+	_, resp = th.SystemAdminClient.GetBulkEmailRecipients(rbulkEmail.Id, "junk", 0, 100)
+	CheckBadRequestStatus(t, resp)
+
+	channel, resp := Client.CreateDirectChannel(th.BasicUser.Id, th.SystemAdminUser.Id)
+	CheckNoError(t, resp)
+
+	posts, resp := Client.GetPostsForChannel(channel.Id, 0, 10, "")
+	CheckNoError(t, resp)
+
+	if len(posts.Order) != 1 || posts.Posts[posts.Order[0]].Message != "Hello "+th.BasicUser.Username {
+		t.Fatal("should have sent the message as a direct message from the admin")
+	}
+
+	list, resp := th.SystemAdminClient.GetBulkEmails(0, 1)
+	CheckNoError(t, resp)
+
+	if len(list) != 1 || list[0].Id != rbulkEmail.Id {
+		t.Fatal("should have returned the bulk email")
+	}
+
+	_, resp = th.SystemAdminClient.CancelBulkEmail(rbulkEmail.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetBulkEmail(rbulkEmail.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetBulkEmailRecipients(rbulkEmail.Id, "", 0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetBulkEmail(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
@@ -4,7 +4,10 @@
 package api4
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/app"
@@ -20,8 +23,8 @@ func InitChannel() {
 	BaseRoutes.Channels.Handle("/members/{user_id:[A-Za-z0-9]+}/view", ApiSessionRequired(viewChannel)).Methods("POST")
 
 	BaseRoutes.ChannelsForTeam.Handle("", ApiSessionRequired(getPublicChannelsForTeam)).Methods("GET")
-	BaseRoutes.ChannelsForTeam.Handle("/ids", ApiSessionRequired(getPublicChannelsByIdsForTeam)).Methods("POST")
-	BaseRoutes.ChannelsForTeam.Handle("/search", ApiSessionRequired(searchChannelsForTeam)).Methods("POST")
+	BaseRoutes.ChannelsForTeam.Handle("/ids", ApiSessionRequiredReadOnly(getPublicChannelsByIdsForTeam)).Methods("POST")
+	BaseRoutes.ChannelsForTeam.Handle("/search", ApiSessionRequiredReadOnly(searchChannelsForTeam)).Methods("POST")
 	BaseRoutes.User.Handle("/teams/{team_id:[A-Za-z0-9]+}/channels", ApiSessionRequired(getChannelsForTeamForUser)).Methods("GET")
 
 	BaseRoutes.Channel.Handle("", ApiSessionRequired(getChannel)).Methods("GET")
@@ -30,15 +33,30 @@ func InitChannel() {
 	BaseRoutes.Channel.Handle("", ApiSessionRequired(deleteChannel)).Methods("DELETE")
 	BaseRoutes.Channel.Handle("/stats", ApiSessionRequired(getChannelStats)).Methods("GET")
 	BaseRoutes.Channel.Handle("/pinned", ApiSessionRequired(getPinnedPosts)).Methods("GET")
+	BaseRoutes.Channel.Handle("/export", ApiSessionRequired(exportChannelSnapshot)).Methods("GET")
+	BaseRoutes.Channel.Handle("/integrity", ApiSessionRequired(verifyChannelPostIntegrity)).Methods("GET")
+	BaseRoutes.Channel.Handle("/reactions/allowed", ApiSessionRequired(updateChannelAllowedReactions)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/integration_overrides", ApiSessionRequired(updateChannelIntegrationOverrides)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/moderation", ApiSessionRequired(updateChannelModerationSettings)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/announcement", ApiSessionRequired(updateChannelAnnouncementSettings)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/announcement/posters", ApiSessionRequired(updateChannelAnnouncementPosters)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/convert", ApiSessionRequired(convertChannelToPrivate)).Methods("POST")
+	BaseRoutes.Channel.Handle("/convert_to_public", ApiSessionRequired(convertChannelToPublic)).Methods("POST")
+	BaseRoutes.Channel.Handle("/convert_to_channel", ApiSessionRequired(convertGroupMessageToChannel)).Methods("POST")
+	BaseRoutes.Channel.Handle("/participants", ApiSessionRequired(addGroupMessageParticipants)).Methods("POST")
+	BaseRoutes.Channel.Handle("/participants/{user_id:[A-Za-z0-9]+}", ApiSessionRequired(removeGroupMessageParticipant)).Methods("DELETE")
 
 	BaseRoutes.ChannelForUser.Handle("/unread", ApiSessionRequired(getChannelUnread)).Methods("GET")
+	BaseRoutes.ChannelForUser.Handle("/last_read", ApiSessionRequired(getChannelLastRead)).Methods("GET")
 
 	BaseRoutes.ChannelByName.Handle("", ApiSessionRequired(getChannelByName)).Methods("GET")
 	BaseRoutes.ChannelByNameForTeamName.Handle("", ApiSessionRequired(getChannelByNameForTeamName)).Methods("GET")
 
 	BaseRoutes.ChannelMembers.Handle("", ApiSessionRequired(getChannelMembers)).Methods("GET")
-	BaseRoutes.ChannelMembers.Handle("/ids", ApiSessionRequired(getChannelMembersByIds)).Methods("POST")
+	BaseRoutes.ChannelMembers.Handle("/ids", ApiSessionRequiredReadOnly(getChannelMembersByIds)).Methods("POST")
 	BaseRoutes.ChannelMembers.Handle("", ApiSessionRequired(addChannelMember)).Methods("POST")
+	BaseRoutes.ChannelMembers.Handle("/batch", ApiSessionRequired(addChannelMembers)).Methods("POST")
+	BaseRoutes.ChannelMembers.Handle("/batch/remove", ApiSessionRequired(removeChannelMembers)).Methods("POST")
 	BaseRoutes.ChannelMembersForUser.Handle("", ApiSessionRequired(getChannelMembersForUser)).Methods("GET")
 	BaseRoutes.ChannelMember.Handle("", ApiSessionRequired(getChannelMember)).Methods("GET")
 	BaseRoutes.ChannelMember.Handle("", ApiSessionRequired(removeChannelMember)).Methods("DELETE")
@@ -178,6 +196,398 @@ func patchChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func updateChannelAllowedReactions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	emojiNames := model.ArrayFromJson(r.Body)
+	if emojiNames == nil {
+		c.SetInvalidParam("allowed_reactions")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !CanManageChannel(c, channel) {
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("updateChannelAllowedReactions", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.UpdateChannelAllowedReactions(channel, emojiNames); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func updateChannelIntegrationOverrides(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.StringInterfaceFromJson(r.Body)
+	forbidden, ok := props["forbidden"].(bool)
+	if !ok {
+		c.SetInvalidParam("forbidden")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !CanManageChannel(c, channel) {
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("updateChannelIntegrationOverrides", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.UpdateChannelIntegrationOverrides(channel, forbidden); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " forbidden=" + strconv.FormatBool(forbidden))
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func updateChannelModerationSettings(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	settings := model.ChannelModerationSettingsFromJson(r.Body)
+	if settings == nil {
+		c.SetInvalidParam("moderation_settings")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	// Members could lift the restrictions on themselves, so only channel admins can moderate a channel
+	if !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_CHANNEL_ROLES) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_CHANNEL_ROLES)
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("updateChannelModerationSettings", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.UpdateChannelModerationSettings(channel, settings); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " settings=" + settings.ToJson())
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func updateChannelAnnouncementSettings(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.StringInterfaceFromJson(r.Body)
+	isAnnouncement, ok := props["is_announcement"].(bool)
+	if !ok {
+		c.SetInvalidParam("is_announcement")
+		return
+	}
+
+	autoAddTeamMembers, ok := props["auto_add_team_members"].(bool)
+	if !ok {
+		c.SetInvalidParam("auto_add_team_members")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_CHANNEL_ROLES) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_CHANNEL_ROLES)
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("updateChannelAnnouncementSettings", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.UpdateChannelAnnouncementSettings(channel, isAnnouncement, autoAddTeamMembers, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " is_announcement=" + strconv.FormatBool(isAnnouncement) + " auto_add_team_members=" + strconv.FormatBool(autoAddTeamMembers))
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func updateChannelAnnouncementPosters(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	userIds := model.ArrayFromJson(r.Body)
+	if userIds == nil {
+		c.SetInvalidParam("announcement_posters")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_CHANNEL_ROLES) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_CHANNEL_ROLES)
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("updateChannelAnnouncementPosters", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.UpdateChannelAnnouncementPosters(channel, userIds); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func convertChannelToPrivate(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, channel.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("convertChannelToPrivate", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.ConvertChannelToPrivate(channel, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func convertChannelToPublic(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	// Making a private channel public exposes its whole history to the team, so clients have to
+	// explicitly confirm it
+	props := model.StringInterfaceFromJson(r.Body)
+	if confirm, ok := props["confirm"].(bool); !ok || !confirm {
+		c.SetInvalidParam("confirm")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, channel.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if channel.DeleteAt > 0 {
+		c.Err = model.NewLocAppError("convertChannelToPublic", "api.channel.update_channel.deleted.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rchannel, err := app.ConvertChannelToPublic(channel, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func convertGroupMessageToChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	teamId := props["team_id"]
+	if len(teamId) != 26 {
+		c.SetInvalidParam("team_id")
+		return
+	}
+
+	name := props["name"]
+	if len(name) == 0 {
+		c.SetInvalidParam("name")
+		return
+	}
+
+	displayName := props["display_name"]
+	if len(displayName) == 0 {
+		c.SetInvalidParam("display_name")
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_CREATE_PRIVATE_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_CREATE_PRIVATE_CHANNEL)
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if rchannel, err := app.ConvertGroupMessageToChannel(channel, c.Session.UserId, teamId, name, displayName); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " team_id=" + teamId)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func addGroupMessageParticipants(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	userIds := model.ArrayFromJson(r.Body)
+	if len(userIds) == 0 {
+		c.SetInvalidParam("user_ids")
+		return
+	}
+
+	for _, userId := range userIds {
+		if len(userId) != 26 {
+			c.SetInvalidParam("user_id")
+			return
+		}
+	}
+
+	// Group messages have no admins, so any of their members can add others
+	if !app.SessionHasPermissionToChannel(c.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if rchannel, err := app.AddGroupMessageParticipants(channel, userIds, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " user_ids=" + model.ArrayToJson(userIds))
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
+func removeGroupMessageParticipant(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	// Members can leave a group message, but only system admins can remove other members from it
+	if c.Params.UserId != c.Session.UserId && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if rchannel, err := app.RemoveGroupMessageParticipant(channel, c.Params.UserId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rchannel.Name + " user_id=" + c.Params.UserId)
+		w.Write([]byte(rchannel.ToJson()))
+	}
+}
+
 func CanManageChannel(c *Context, channel *model.Channel) bool {
 	if channel.Type == model.CHANNEL_OPEN && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES) {
 		c.SetPermissionError(model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES)
@@ -283,6 +693,30 @@ func getChannelUnread(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(channelUnread.ToJson()))
 }
 
+func getChannelLastRead(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !app.SessionHasPermissionToChannel(c.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if lastRead, err := app.GetChannelLastRead(c.Params.ChannelId, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(lastRead.ToJson()))
+	}
+}
+
 func getChannelStats(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireChannelId()
 	if c.Err != nil {
@@ -294,14 +728,13 @@ func getChannelStats(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	memberCount, err := app.GetChannelMemberCount(c.Params.ChannelId)
+	stats, err := app.GetChannelStats(c.Params.ChannelId)
 
 	if err != nil {
 		c.Err = err
 		return
 	}
 
-	stats := model.ChannelStats{ChannelId: c.Params.ChannelId, MemberCount: memberCount}
 	w.Write([]byte(stats.ToJson()))
 }
 
@@ -338,10 +771,17 @@ func getPublicChannelsForTeam(c *Context, w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if channels, err := app.GetPublicChannelsForTeam(c.Params.TeamId, c.Params.Page, c.Params.PerPage); err != nil {
+	if channels, err := app.GetPublicChannelsForTeam(c.Params.TeamId, c.Params.Page*c.Params.PerPage, c.Params.PerPage); err != nil {
 		c.Err = err
 		return
 	} else {
+		channels = app.FilterChannelsByOAuthScope(c.Session, channels)
+		if pagination := c.GetPagination(len(*channels), func() (int64, *model.AppError) {
+			return app.GetPublicChannelCountForTeam(c.Params.TeamId)
+		}); pagination != nil {
+			pagination.SetHeaders(w.Header())
+		}
+
 		w.Write([]byte(channels.ToJson()))
 		return
 	}
@@ -375,7 +815,7 @@ func getPublicChannelsByIdsForTeam(c *Context, w http.ResponseWriter, r *http.Re
 		c.Err = err
 		return
 	} else {
-		w.Write([]byte(channels.ToJson()))
+		w.Write([]byte(app.FilterChannelsByOAuthScope(c.Session, channels).ToJson()))
 	}
 }
 
@@ -395,15 +835,19 @@ func getChannelsForTeamForUser(c *Context, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if channels, err := app.GetChannelsForUser(c.Params.TeamId, c.Params.UserId); err != nil {
+	channels, err := app.GetChannelsForUser(c.Params.TeamId, c.Params.UserId)
+	if err != nil {
 		c.Err = err
 		return
-	} else if HandleEtag(channels.Etag(), "Get Channels", w, r) {
+	}
+
+	channels = app.FilterChannelsByOAuthScope(c.Session, channels)
+	if HandleEtag(channels.Etag(), "Get Channels", w, r) {
 		return
-	} else {
-		w.Header().Set(model.HEADER_ETAG_SERVER, channels.Etag())
-		w.Write([]byte(channels.ToJson()))
 	}
+
+	w.Header().Set(model.HEADER_ETAG_SERVER, channels.Etag())
+	w.Write([]byte(channels.ToJson()))
 }
 
 func searchChannelsForTeam(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -432,7 +876,7 @@ func searchChannelsForTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = err
 		return
 	} else {
-		w.Write([]byte(channels.ToJson()))
+		w.Write([]byte(app.FilterChannelsByOAuthScope(c.Session, channels).ToJson()))
 	}
 }
 
@@ -465,7 +909,7 @@ func deleteChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c.LogAudit("name=" + channel.Name)
+	c.LogAuditEvent(model.AUDIT_TARGET_CHANNEL, channel.Id, model.StringInterface{"name": channel.Name, "team_id": channel.TeamId})
 
 	ReturnStatusOK(w)
 }
@@ -479,7 +923,7 @@ func getChannelByName(c *Context, w http.ResponseWriter, r *http.Request) {
 	var channel *model.Channel
 	var err *model.AppError
 
-	if channel, err = app.GetChannelByName(c.Params.ChannelName, c.Params.TeamId); err != nil {
+	if channel, err = app.ResolveChannelByName(c.Params.ChannelName, c.Params.TeamId); err != nil {
 		c.Err = err
 		return
 	}
@@ -496,6 +940,10 @@ func getChannelByName(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if channel.Name != c.Params.ChannelName {
+		w.Header().Set(model.HEADER_REDIRECTED_FROM, c.Params.ChannelName)
+	}
+
 	w.Write([]byte(channel.ToJson()))
 }
 
@@ -508,7 +956,7 @@ func getChannelByNameForTeamName(c *Context, w http.ResponseWriter, r *http.Requ
 	var channel *model.Channel
 	var err *model.AppError
 
-	if channel, err = app.GetChannelByNameForTeamName(c.Params.ChannelName, c.Params.TeamName); err != nil {
+	if channel, err = app.ResolveChannelByNameForTeamName(c.Params.ChannelName, c.Params.TeamName); err != nil {
 		c.Err = err
 		return
 	}
@@ -518,6 +966,10 @@ func getChannelByNameForTeamName(c *Context, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if channel.Name != c.Params.ChannelName {
+		w.Header().Set(model.HEADER_REDIRECTED_FROM, c.Params.ChannelName)
+	}
+
 	w.Write([]byte(channel.ToJson()))
 	return
 }
@@ -743,6 +1195,104 @@ func addChannelMember(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func addChannelMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	userIds := model.ArrayFromJson(r.Body)
+	if len(userIds) == 0 {
+		c.SetInvalidParam("user_ids")
+		return
+	}
+
+	var channel *model.Channel
+	var err *model.AppError
+	if channel, err = app.GetChannel(c.Params.ChannelId); err != nil {
+		c.Err = err
+		return
+	}
+
+	// Check join permission if only adding yourself, otherwise check manage permission
+	if channel.Type == model.CHANNEL_OPEN {
+		if isOnlySessionUser(c, userIds) {
+			if !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_JOIN_PUBLIC_CHANNELS) {
+				c.SetPermissionError(model.PERMISSION_JOIN_PUBLIC_CHANNELS)
+				return
+			}
+		} else {
+			if !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS) {
+				c.SetPermissionError(model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS)
+				return
+			}
+		}
+	}
+
+	if channel.Type == model.CHANNEL_PRIVATE && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS)
+		return
+	}
+
+	if batch, err := app.AddChannelMembers(userIds, channel, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + channel.Name + " added=" + strconv.Itoa(len(batch.UserIds)))
+		w.Write([]byte(batch.ToJson()))
+	}
+}
+
+func removeChannelMembers(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	userIds := model.ArrayFromJson(r.Body)
+	if len(userIds) == 0 {
+		c.SetInvalidParam("user_ids")
+		return
+	}
+
+	var channel *model.Channel
+	var err *model.AppError
+	if channel, err = app.GetChannel(c.Params.ChannelId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if !isOnlySessionUser(c, userIds) {
+		if channel.Type == model.CHANNEL_OPEN && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_PUBLIC_CHANNEL_MEMBERS)
+			return
+		}
+
+		if channel.Type == model.CHANNEL_PRIVATE && !app.SessionHasPermissionToChannel(c.Session, channel.Id, model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS) {
+			c.SetPermissionError(model.PERMISSION_MANAGE_PRIVATE_CHANNEL_MEMBERS)
+			return
+		}
+	}
+
+	if batch, err := app.RemoveChannelMembers(userIds, c.Session.UserId, channel); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + channel.Name + " removed=" + strconv.Itoa(len(batch.UserIds)))
+		w.Write([]byte(batch.ToJson()))
+	}
+}
+
+func isOnlySessionUser(c *Context, userIds []string) bool {
+	for _, userId := range userIds {
+		if userId != c.Session.UserId {
+			return false
+		}
+	}
+
+	return true
+}
+
 func removeChannelMember(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireChannelId().RequireUserId()
 	if c.Err != nil {
@@ -777,3 +1327,58 @@ func removeChannelMember(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ReturnStatusOK(w)
 }
+
+func exportChannelSnapshot(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	var asOf int64
+	if asOfString := r.URL.Query().Get("as_of"); len(asOfString) > 0 {
+		var err error
+		if asOf, err = strconv.ParseInt(asOfString, 10, 64); err != nil {
+			c.SetInvalidParam("as_of")
+			return
+		}
+	}
+
+	// The archive is built before responding so that a failure part way through can still be reported
+	var buf bytes.Buffer
+	manifest, err := app.ExportChannelSnapshot(c.Params.ChannelId, asOf, c.Session.UserId, &buf)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit(fmt.Sprintf("channel_id=%v as_of=%v", manifest.ChannelId, manifest.AsOf))
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=\"channel-%v-%v.zip\"", manifest.ChannelId, manifest.AsOf))
+	w.Write(buf.Bytes())
+}
+
+func verifyChannelPostIntegrity(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if verification, err := app.VerifyPostIntegrityChain(c.Params.ChannelId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit(fmt.Sprintf("channel_id=%v valid=%v", verification.ChannelId, verification.IsValid()))
+		w.Write([]byte(verification.ToJson()))
+	}
+}
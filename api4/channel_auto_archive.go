@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitChannelAutoArchive() {
+	l4g.Debug(utils.T("api.channel_auto_archive.init.debug"))
+
+	BaseRoutes.Channel.Handle("/auto_archive/exempt", ApiSessionRequired(exemptChannelFromAutoArchiving)).Methods("PUT")
+	BaseRoutes.Channel.Handle("/auto_archive/exempt", ApiSessionRequired(removeChannelAutoArchiveExemption)).Methods("DELETE")
+	BaseRoutes.Reports.Handle("/upcoming_channel_archivals", ApiSessionRequired(getUpcomingChannelArchivals)).Methods("GET")
+}
+
+func exemptChannelFromAutoArchiving(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !CanManageChannel(c, channel) {
+		return
+	}
+
+	archive, err := app.ExemptChannelFromAutoArchiving(channel, c.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + channel.Name)
+	w.Write([]byte(archive.ToJson()))
+}
+
+func removeChannelAutoArchiveExemption(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !CanManageChannel(c, channel) {
+		return
+	}
+
+	if err := app.RemoveChannelAutoArchiveExemption(channel.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + channel.Name)
+	ReturnStatusOK(w)
+}
+
+func getUpcomingChannelArchivals(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	archivals, err := app.GetUpcomingChannelArchivals(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(archivals), func() (int64, *model.AppError) {
+		return app.GetUpcomingChannelArchivalsCount()
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.UpcomingChannelArchivalListToJson(archivals)))
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestChannelAutoArchiveExemption(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	archive, resp := Client.ExemptChannelFromAutoArchiving(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+
+	if !archive.Exempt || archive.ExemptBy != th.BasicUser.Id {
+		t.Fatal("should have exempted the channel")
+	}
+
+	ok, resp := Client.RemoveChannelAutoArchiveExemption(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned ok")
+	}
+
+	_, resp = Client.RemoveChannelAutoArchiveExemption(th.BasicChannel.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.ExemptChannelFromAutoArchiving(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.ExemptChannelFromAutoArchiving(th.BasicChannel.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestChannelAutoArchiving(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	now := model.GetMillis()
+	if result := <-app.Srv.Store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: th.BasicChannel2.Id, WarnedAt: now, ArchiveAt: now}); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	_, resp := Client.GetUpcomingChannelArchivalsReport(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	archivals, resp := th.SystemAdminClient.GetUpcomingChannelArchivalsReport(0, 10000)
+	CheckNoError(t, resp)
+
+	found := false
+	for _, archival := range archivals {
+		if archival.Id == th.BasicChannel2.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have reported the upcoming archival")
+	}
+
+	app.RunChannelAutoArchiving()
+
+	if channel, err := app.GetChannel(th.BasicChannel2.Id); err != nil {
+		t.Fatal(err)
+	} else if channel.DeleteAt == 0 {
+		t.Fatal("should have archived the idle channel")
+	}
+
+	if _, err := app.GetChannelAutoArchive(th.BasicChannel2.Id); err == nil {
+		t.Fatal("should have forgotten the channel once it was archived")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitChannelCategory() {
+	l4g.Debug(utils.T("api.channel_category.init.debug"))
+
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("", ApiSessionRequired(getSidebarCategories)).Methods("GET")
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("", ApiSessionRequired(createSidebarCategory)).Methods("POST")
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("/order", ApiSessionRequired(updateSidebarCategoryOrder)).Methods("PUT")
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("/{category_id:[A-Za-z0-9]+}", ApiSessionRequired(getSidebarCategory)).Methods("GET")
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("/{category_id:[A-Za-z0-9]+}", ApiSessionRequired(updateSidebarCategory)).Methods("PUT")
+	BaseRoutes.ChannelCategoriesForTeamForUser.Handle("/{category_id:[A-Za-z0-9]+}", ApiSessionRequired(deleteSidebarCategory)).Methods("DELETE")
+}
+
+// checkSidebarCategoryPermissions makes sure that the session can change the user's sidebar and can
+// see the team.
+func checkSidebarCategoryPermissions(c *Context) bool {
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return false
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return false
+	}
+
+	return true
+}
+
+func getSidebarCategories(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	if categories, err := app.GetSidebarCategories(c.Params.UserId, c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.SidebarCategoryListToJson(categories)))
+	}
+}
+
+func createSidebarCategory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	category := model.SidebarCategoryFromJson(r.Body)
+	if category == nil {
+		c.SetInvalidParam("category")
+		return
+	}
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	if rcategory, err := app.CreateSidebarCategory(c.Params.UserId, c.Params.TeamId, category); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rcategory.ToJson()))
+	}
+}
+
+func getSidebarCategory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId().RequireCategoryId()
+	if c.Err != nil {
+		return
+	}
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	if category, err := app.GetSidebarCategory(c.Params.UserId, c.Params.TeamId, c.Params.CategoryId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(category.ToJson()))
+	}
+}
+
+func updateSidebarCategory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId().RequireCategoryId()
+	if c.Err != nil {
+		return
+	}
+
+	category := model.SidebarCategoryFromJson(r.Body)
+	if category == nil {
+		c.SetInvalidParam("category")
+		return
+	}
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	category.Id = c.Params.CategoryId
+
+	if rcategory, err := app.UpdateSidebarCategory(c.Params.UserId, c.Params.TeamId, category); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(rcategory.ToJson()))
+	}
+}
+
+func updateSidebarCategoryOrder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	categoryOrder := model.ArrayFromJson(r.Body)
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	if err := app.UpdateSidebarCategoryOrder(c.Params.UserId, c.Params.TeamId, categoryOrder); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func deleteSidebarCategory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId().RequireCategoryId()
+	if c.Err != nil {
+		return
+	}
+
+	if !checkSidebarCategoryPermissions(c) {
+		return
+	}
+
+	if err := app.DeleteSidebarCategory(c.Params.UserId, c.Params.TeamId, c.Params.CategoryId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
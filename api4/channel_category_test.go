@@ -0,0 +1,136 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestSidebarCategories(t *testing.T) {
+	th := SetupParallel(t).InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+	teamId := th.BasicTeam.Id
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+	time.Sleep(300 * time.Millisecond)
+
+	categories, resp := Client.GetSidebarCategoriesForTeamForUser(model.ME, teamId)
+	CheckNoError(t, resp)
+
+	if len(categories) != 0 {
+		t.Fatal("shouldn't have any categories yet")
+	}
+
+	category := &model.SidebarCategoryWithChannels{
+		SidebarCategory: model.SidebarCategory{DisplayName: "Projects"},
+		Channels:        []string{th.BasicChannel.Id, th.BasicPrivateChannel.Id},
+	}
+	c1, resp := Client.CreateSidebarCategoryForTeamForUser(model.ME, teamId, category)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if c1.UserId != th.BasicUser.Id || c1.TeamId != teamId || len(c1.Channels) != 2 {
+		t.Fatal("category did not match")
+	}
+
+	eventHit := false
+	timeout := time.After(2 * time.Second)
+	for !eventHit {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_SIDEBAR_CATEGORY_CREATED && event.Data["category_id"] == c1.Id {
+				eventHit = true
+			}
+		case <-timeout:
+			t.Fatal("should have sent a category created event")
+		}
+	}
+
+	c2, resp := Client.CreateSidebarCategoryForTeamForUser(th.BasicUser.Id, teamId, &model.SidebarCategoryWithChannels{
+		SidebarCategory: model.SidebarCategory{DisplayName: "Favorites"},
+		Channels:        []string{th.BasicChannel.Id},
+	})
+	CheckNoError(t, resp)
+
+	c1, resp = Client.GetSidebarCategoryForTeamForUser(model.ME, teamId, c1.Id)
+	CheckNoError(t, resp)
+
+	if len(c1.Channels) != 1 || c1.Channels[0] != th.BasicPrivateChannel.Id {
+		t.Fatal("the channel should have moved to the new category")
+	}
+
+	c1.DisplayName = "Renamed"
+	c1.Collapsed = true
+	c1.Channels = []string{th.BasicPrivateChannel.Id, th.BasicChannel.Id}
+	c1, resp = Client.UpdateSidebarCategoryForTeamForUser(model.ME, teamId, c1)
+	CheckNoError(t, resp)
+
+	if c1.DisplayName != "Renamed" || !c1.Collapsed || len(c1.Channels) != 2 {
+		t.Fatal("should have updated the category")
+	}
+
+	_, resp = Client.UpdateSidebarCategoryOrderForTeamForUser(model.ME, teamId, []string{c2.Id, c1.Id})
+	CheckNoError(t, resp)
+
+	categories, resp = Client.GetSidebarCategoriesForTeamForUser(model.ME, teamId)
+	CheckNoError(t, resp)
+
+	if len(categories) != 2 || categories[0].Id != c2.Id || categories[1].Id != c1.Id {
+		t.Fatal("should have reordered the categories")
+	}
+
+	if len(categories[0].Channels) != 0 {
+		t.Fatal("the channel should have moved out of the second category")
+	}
+
+	_, resp = Client.UpdateSidebarCategoryOrderForTeamForUser(model.ME, teamId, []string{c1.Id})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateSidebarCategoryOrderForTeamForUser(model.ME, teamId, []string{c1.Id, model.NewId()})
+	CheckBadRequestStatus(t, resp)
+
+	c1.Channels = []string{model.NewId()}
+	_, resp = Client.UpdateSidebarCategoryForTeamForUser(model.ME, teamId, c1)
+	CheckBadRequestStatus(t, resp)
+
+	c1.Channels = []string{}
+	c1.DisplayName = ""
+	_, resp = Client.UpdateSidebarCategoryForTeamForUser(model.ME, teamId, c1)
+	CheckBadRequestStatus(t, resp)
+
+	th.LoginBasic2()
+
+	_, resp = Client.GetSidebarCategoriesForTeamForUser(th.BasicUser.Id, teamId)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetSidebarCategoryForTeamForUser(model.ME, teamId, c1.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteSidebarCategoryForTeamForUser(model.ME, teamId, c1.Id)
+	CheckNotFoundStatus(t, resp)
+
+	th.LoginBasic()
+
+	_, resp = Client.DeleteSidebarCategoryForTeamForUser(model.ME, teamId, c1.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetSidebarCategoryForTeamForUser(model.ME, teamId, c1.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetSidebarCategoriesForTeamForUser(th.BasicUser.Id, teamId)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetSidebarCategoriesForTeamForUser(model.ME, teamId)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitChannelSuccessor() {
+	l4g.Debug(utils.T("api.channel_successor.init.debug"))
+
+	BaseRoutes.Channel.Handle("/admin", ApiSessionRequired(assignChannelAdmin)).Methods("POST")
+	BaseRoutes.Reports.Handle("/orphaned_channels", ApiSessionRequired(getOrphanedChannels)).Methods("GET")
+}
+
+// assignChannelAdmin makes the user in the request a channel admin, or the longest tenured member of
+// the channel if no user is given.
+func assignChannelAdmin(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	userId := props["user_id"]
+	if len(userId) > 0 && len(userId) != 26 {
+		c.SetInvalidParam("user_id")
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, channel.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	var member *model.ChannelMember
+	if len(userId) > 0 {
+		member, err = app.AssignChannelAdmin(channel, userId, c.Session.UserId)
+	} else {
+		member, err = app.PromoteChannelSuccessor(channel)
+	}
+
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + channel.Name + " user_id=" + member.UserId)
+	w.Write([]byte(member.ToJson()))
+}
+
+func getOrphanedChannels(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channels, err := app.GetOrphanedChannels(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(channels), func() (int64, *model.AppError) {
+		return app.GetOrphanedChannelsCount()
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.OrphanedChannelListToJson(channels)))
+}
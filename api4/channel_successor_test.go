@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestAssignChannelAdmin(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.AssignChannelAdmin(th.BasicChannel.Id, th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+
+	member, resp := Client.AssignChannelAdmin(th.BasicChannel.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if member.UserId != th.BasicUser2.Id || !member.IsChannelAdmin() {
+		t.Fatal("should have made the user a channel admin")
+	}
+
+	user := th.CreateUser()
+	LinkUserToTeam(user, th.BasicTeam)
+
+	member, resp = Client.AssignChannelAdmin(th.BasicChannel.Id, user.Id)
+	CheckNoError(t, resp)
+
+	if member.UserId != user.Id || !member.IsChannelAdmin() {
+		t.Fatal("should have added the user to the channel as a channel admin")
+	}
+
+	member, resp = Client.AssignChannelAdmin(th.BasicChannel.Id, "")
+	CheckNoError(t, resp)
+
+	if member.UserId != th.TeamAdminUser.Id || !member.IsChannelAdmin() {
+		t.Fatal("should have picked the longest tenured member")
+	}
+
+	_, resp = Client.AssignChannelAdmin(th.BasicChannel.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AssignChannelAdmin(model.NewId(), th.BasicUser2.Id)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.AssignChannelAdmin(th.BasicChannel.Id, th.BasicUser2.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestChannelAdminSuccession(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	succession := *utils.Cfg.TeamSettings.ChannelAdminSuccession
+	defer func() {
+		*utils.Cfg.TeamSettings.ChannelAdminSuccession = succession
+	}()
+	*utils.Cfg.TeamSettings.ChannelAdminSuccession = model.CHANNEL_ADMIN_SUCCESSION_LONGEST_TENURED
+
+	// The team admin created the channel, so they're its only channel admin
+	th.LoginTeamAdmin()
+	_, resp := Client.RemoveUserFromChannel(th.BasicPrivateChannel.Id, th.TeamAdminUser.Id)
+	CheckNoError(t, resp)
+
+	promoted := false
+	for i := 0; i < 50 && !promoted; i++ {
+		time.Sleep(100 * time.Millisecond)
+
+		member, resp := th.SystemAdminClient.GetChannelMember(th.BasicPrivateChannel.Id, th.BasicUser.Id, "")
+		CheckNoError(t, resp)
+		promoted = member.IsChannelAdmin()
+	}
+
+	if !promoted {
+		t.Fatal("should have made the longest tenured member a channel admin")
+	}
+
+	member, resp := th.SystemAdminClient.GetChannelMember(th.BasicPrivateChannel.Id, th.BasicUser2.Id, "")
+	CheckNoError(t, resp)
+
+	if member.IsChannelAdmin() {
+		t.Fatal("should only have made one member a channel admin")
+	}
+}
+
+func TestGetOrphanedChannelsReport(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetOrphanedChannelsReport(0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+	_, resp = Client.RemoveUserFromChannel(th.BasicChannel2.Id, th.TeamAdminUser.Id)
+	CheckNoError(t, resp)
+
+	channels, resp := th.SystemAdminClient.GetOrphanedChannelsReport(0, 10000)
+	CheckNoError(t, resp)
+
+	found := false
+	for _, channel := range channels {
+		if channel.Id == th.BasicChannel.Id {
+			t.Fatal("shouldn't have returned a channel with a channel admin")
+		} else if channel.Id == th.BasicChannel2.Id {
+			found = true
+
+			if channel.CreatorId != th.TeamAdminUser.Id {
+				t.Fatal("should have returned the creator of the channel")
+			}
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned the channel without a channel admin")
+	}
+
+	if r, err := th.SystemAdminClient.DoApiGet("/reports/orphaned_channels?page=0&per_page=1&include_total_count=true", ""); err != nil {
+		t.Fatal(err)
+	} else if pagination := model.PaginationFromHeaders(r.Header); pagination == nil {
+		t.Fatal("should have returned the pagination")
+	}
+
+	Client.Logout()
+	_, resp = Client.GetOrphanedChannelsReport(0, 60)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -4,11 +4,15 @@
 package api4
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
@@ -315,6 +319,431 @@ func TestPatchChannel(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestUpdateChannelAllowedReactions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	channel, resp := Client.UpdateChannelAllowedReactions(th.BasicChannel.Id, []string{":+1:", "-1", "+1"})
+	CheckNoError(t, resp)
+
+	if len(channel.AllowedReactions) != 2 || channel.AllowedReactions[0] != "+1" || channel.AllowedReactions[1] != "-1" {
+		t.Fatal("should have saved the normalized list of allowed reactions")
+	}
+
+	channel, resp = Client.GetChannel(th.BasicChannel.Id, "")
+	CheckNoError(t, resp)
+
+	if len(channel.AllowedReactions) != 2 {
+		t.Fatal("allowed reactions should be included with the channel")
+	}
+
+	tooMany := make([]string, 100)
+	for i := range tooMany {
+		tooMany[i] = model.NewId()
+	}
+	_, resp = Client.UpdateChannelAllowedReactions(th.BasicChannel.Id, tooMany)
+	CheckBadRequestStatus(t, resp)
+
+	channel, resp = Client.UpdateChannelAllowedReactions(th.BasicChannel.Id, []string{})
+	CheckNoError(t, resp)
+
+	if len(channel.AllowedReactions) != 0 {
+		t.Fatal("should have cleared the allowed reactions")
+	}
+
+	_, resp = Client.UpdateChannelAllowedReactions("junk", []string{"+1"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateChannelAllowedReactions(model.NewId(), []string{"+1"})
+	CheckNotFoundStatus(t, resp)
+
+	user := th.CreateUser()
+	Client.Login(user.Email, user.Password)
+	_, resp = Client.UpdateChannelAllowedReactions(th.BasicChannel.Id, []string{"+1"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelAllowedReactions(th.BasicPrivateChannel.Id, []string{"+1"})
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.UpdateChannelAllowedReactions(th.BasicChannel.Id, []string{"+1"})
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestUpdateChannelIntegrationOverrides(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	channel, resp := Client.UpdateChannelIntegrationOverrides(th.BasicChannel.Id, true)
+	CheckNoError(t, resp)
+
+	if !channel.ForbidIntegrationOverrides {
+		t.Fatal("should have forbidden overrides")
+	}
+
+	channel, resp = Client.GetChannel(th.BasicChannel.Id, "")
+	CheckNoError(t, resp)
+
+	if !channel.ForbidIntegrationOverrides {
+		t.Fatal("the setting should be included with the channel")
+	}
+
+	channel, resp = Client.UpdateChannelIntegrationOverrides(th.BasicChannel.Id, false)
+	CheckNoError(t, resp)
+
+	if channel.ForbidIntegrationOverrides {
+		t.Fatal("should have allowed overrides")
+	}
+
+	_, resp = Client.UpdateChannelIntegrationOverrides(model.NewId(), true)
+	CheckNotFoundStatus(t, resp)
+
+	user := th.CreateUser()
+	Client.Login(user.Email, user.Password)
+	_, resp = Client.UpdateChannelIntegrationOverrides(th.BasicChannel.Id, true)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.UpdateChannelIntegrationOverrides(th.BasicChannel.Id, true)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestUpdateChannelModerationSettings(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	settings := &model.ChannelModerationSettings{DisableMemberPosts: true}
+
+	_, resp := Client.UpdateChannelModerationSettings(th.BasicChannel.Id, settings)
+	CheckForbiddenStatus(t, resp)
+
+	channel, resp := th.SystemAdminClient.UpdateChannelModerationSettings(th.BasicChannel.Id, settings)
+	CheckNoError(t, resp)
+
+	if !channel.ModerationSettings.DisableMemberPosts {
+		t.Fatal("should have disabled posting for members")
+	}
+
+	channel, resp = Client.GetChannel(th.BasicChannel.Id, "")
+	CheckNoError(t, resp)
+
+	if channel.ModerationSettings != *settings {
+		t.Fatal("the settings should be included with the channel")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	settings = &model.ChannelModerationSettings{DisableMemberChannelMentions: true, DisableMemberReactions: true}
+	_, resp = th.SystemAdminClient.UpdateChannelModerationSettings(th.BasicChannel.Id, settings)
+	CheckNoError(t, resp)
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello @channel"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello @all!"})
+	CheckForbiddenStatus(t, resp)
+
+	post, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	channel, _ = app.GetChannel(th.BasicChannel.Id)
+	if _, err := app.SaveReactionForPost(&model.Reaction{UserId: th.BasicUser.Id, PostId: post.Id, EmojiName: "smile"}, channel); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("members shouldn't be able to react")
+	}
+
+	if _, err := app.SaveReactionForPost(&model.Reaction{UserId: th.SystemAdminUser.Id, PostId: post.Id, EmojiName: "smile"}, channel); err != nil {
+		t.Fatal(err)
+	}
+
+	dm, resp := Client.CreateDirectChannel(th.BasicUser.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelModerationSettings(dm.Id, settings)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelModerationSettings(model.NewId(), settings)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.UpdateChannelModerationSettings(th.BasicChannel.Id, settings)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestConvertChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	publicChannel := th.CreatePublicChannel()
+
+	_, resp := Client.ConvertChannelToPrivate(publicChannel.Id)
+	CheckForbiddenStatus(t, resp)
+
+	rchannel, resp := th.SystemAdminClient.ConvertChannelToPrivate(publicChannel.Id)
+	CheckNoError(t, resp)
+
+	if rchannel.Type != model.CHANNEL_PRIVATE {
+		t.Fatal("channel should have been converted to private")
+	}
+
+	_, resp = th.SystemAdminClient.ConvertChannelToPrivate(publicChannel.Id)
+	CheckBadRequestStatus(t, resp)
+
+	defaultChannel, _ := app.GetChannelByName(model.DEFAULT_CHANNEL, th.BasicTeam.Id)
+	_, resp = th.SystemAdminClient.ConvertChannelToPrivate(defaultChannel.Id)
+	CheckBadRequestStatus(t, resp)
+
+	allowConversion := *utils.Cfg.TeamSettings.AllowPrivateToPublicConversion
+	defer func() {
+		*utils.Cfg.TeamSettings.AllowPrivateToPublicConversion = allowConversion
+	}()
+
+	*utils.Cfg.TeamSettings.AllowPrivateToPublicConversion = false
+	_, resp = th.SystemAdminClient.ConvertChannelToPublic(publicChannel.Id)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.TeamSettings.AllowPrivateToPublicConversion = true
+	_, resp = Client.ConvertChannelToPublic(publicChannel.Id)
+	CheckForbiddenStatus(t, resp)
+
+	rchannel, resp = th.SystemAdminClient.ConvertChannelToPublic(publicChannel.Id)
+	CheckNoError(t, resp)
+
+	if rchannel.Type != model.CHANNEL_OPEN {
+		t.Fatal("channel should have been converted back to public")
+	}
+
+	_, resp = th.SystemAdminClient.ConvertChannelToPublic(publicChannel.Id)
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestConvertGroupMessageToChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	user3 := th.CreateUser()
+	LinkUserToTeam(user3, th.BasicTeam)
+
+	gm, err := app.CreateGroupChannel([]string{th.BasicUser.Id, th.BasicUser2.Id, user3.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, resp := Client.CreatePost(&model.Post{ChannelId: gm.Id, Message: "before the conversion"})
+	CheckNoError(t, resp)
+
+	name := GenerateTestChannelName()
+
+	_, resp = Client.ConvertGroupMessageToChannel(gm.Id, th.BasicTeam.Id, "", "Converted")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.ConvertGroupMessageToChannel(th.BasicChannel.Id, th.BasicTeam.Id, name, "Converted")
+	CheckBadRequestStatus(t, resp)
+
+	rchannel, resp := Client.ConvertGroupMessageToChannel(gm.Id, th.BasicTeam.Id, name, "Converted")
+	CheckNoError(t, resp)
+
+	if rchannel.Type != model.CHANNEL_PRIVATE || rchannel.TeamId != th.BasicTeam.Id || rchannel.Name != name {
+		t.Fatal("group message should have been converted to a private channel on the team")
+	}
+
+	if member, err := app.GetChannelMember(gm.Id, th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(member.Roles, model.ROLE_CHANNEL_ADMIN.Id) {
+		t.Fatal("the user converting the group message should be a channel admin")
+	}
+
+	if members, err := app.GetChannelMembersPage(gm.Id, 0, 100); err != nil {
+		t.Fatal(err)
+	} else if len(*members) != 3 {
+		t.Fatal("should have kept the members")
+	}
+
+	posts, resp := Client.GetPostsForChannel(gm.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	if _, ok := posts.Posts[post.Id]; !ok {
+		t.Fatal("should have kept the history")
+	}
+
+	// Every member has to be on the team
+	outsider := th.CreateUser()
+	gm2, err := app.CreateGroupChannel([]string{th.BasicUser.Id, th.BasicUser2.Id, outsider.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = Client.ConvertGroupMessageToChannel(gm2.Id, th.BasicTeam.Id, GenerateTestChannelName(), "Converted")
+	CheckBadRequestStatus(t, resp)
+
+	user4 := th.CreateUser()
+	LinkUserToTeam(user4, th.BasicTeam)
+	gm3, err := app.CreateGroupChannel([]string{th.BasicUser2.Id, user3.Id, user4.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = Client.ConvertGroupMessageToChannel(gm3.Id, th.BasicTeam.Id, GenerateTestChannelName(), "Converted")
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestGroupMessageParticipants(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	user3 := th.CreateUser()
+	user4 := th.CreateUser()
+
+	gm, err := app.CreateGroupChannel([]string{th.BasicUser.Id, th.BasicUser2.Id, user3.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp := Client.AddGroupMessageParticipants(gm.Id, []string{"junk"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddGroupMessageParticipants(th.BasicChannel.Id, []string{user4.Id})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddGroupMessageParticipants(gm.Id, []string{model.NewId()})
+	CheckBadRequestStatus(t, resp)
+
+	rchannel, resp := Client.AddGroupMessageParticipants(gm.Id, []string{user4.Id, user4.Id})
+	CheckNoError(t, resp)
+
+	if rchannel.Id != gm.Id || rchannel.Name != model.GetGroupNameFromUserIds([]string{th.BasicUser.Id, th.BasicUser2.Id, user3.Id, user4.Id}) {
+		t.Fatal("the group message should have been renamed after its new members")
+	}
+
+	if _, err := app.GetChannelMember(gm.Id, user4.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the new member would give the group message the same members as this one
+	if _, err := app.CreateGroupChannel([]string{th.BasicUser.Id, th.BasicUser2.Id, user3.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = th.SystemAdminClient.RemoveGroupMessageParticipant(gm.Id, user4.Id)
+	CheckBadRequestStatus(t, resp)
+
+	// Members can only remove themselves
+	_, resp = Client.RemoveGroupMessageParticipant(gm.Id, user4.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.RemoveGroupMessageParticipant(gm.Id, user4.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	Client.Login(user4.Email, user4.Password)
+
+	_, resp = Client.RemoveGroupMessageParticipant(gm.Id, th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RemoveGroupMessageParticipant(gm.Id, model.NewId())
+	CheckBadRequestStatus(t, resp)
+
+	Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+
+	rchannel, resp = Client.RemoveGroupMessageParticipant(gm.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if rchannel.Name != model.GetGroupNameFromUserIds([]string{th.BasicUser2.Id, user3.Id, user4.Id}) {
+		t.Fatal("the group message should have been renamed after its remaining members")
+	}
+
+	if _, err := app.GetChannelMember(gm.Id, th.BasicUser.Id); err == nil {
+		t.Fatal("the user should have been removed")
+	}
+
+	_, resp = th.SystemAdminClient.RemoveGroupMessageParticipant(gm.Id, user4.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddGroupMessageParticipants(gm.Id, []string{th.BasicUser.Id})
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestUpdateChannelAnnouncementSettings(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.UpdateChannelAnnouncementSettings(th.BasicChannel.Id, true, true)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelAnnouncementSettings(th.BasicChannel.Id, false, true)
+	CheckBadRequestStatus(t, resp)
+
+	notMember := th.CreateUser()
+	LinkUserToTeam(notMember, th.BasicTeam)
+
+	channel, resp := th.SystemAdminClient.UpdateChannelAnnouncementSettings(th.BasicChannel.Id, true, true)
+	CheckNoError(t, resp)
+
+	if !channel.IsAnnouncement || !channel.AutoAddTeamMembers {
+		t.Fatal("should have made an announcement channel")
+	}
+
+	if _, err := app.GetChannelMember(th.BasicChannel.Id, notMember.Id); err != nil {
+		t.Fatal("should have added the team members to the channel")
+	}
+
+	newMember := th.CreateUser()
+	LinkUserToTeam(newMember, th.BasicTeam)
+
+	if _, err := app.GetChannelMember(th.BasicChannel.Id, newMember.Id); err != nil {
+		t.Fatal("should have added the new team member to the channel")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RemoveUserFromChannel(th.BasicChannel.Id, th.BasicUser.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateChannelAnnouncementPosters(th.BasicChannel.Id, []string{th.BasicUser.Id})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelAnnouncementPosters(th.BasicChannel.Id, []string{model.NewId()})
+	CheckBadRequestStatus(t, resp)
+
+	channel, resp = th.SystemAdminClient.UpdateChannelAnnouncementPosters(th.BasicChannel.Id, []string{th.BasicUser.Id, th.BasicUser.Id})
+	CheckNoError(t, resp)
+
+	if len(channel.AnnouncementPosters) != 1 || channel.AnnouncementPosters[0] != th.BasicUser.Id {
+		t.Fatal("should have set the posters")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckForbiddenStatus(t, resp)
+
+	channel, resp = th.SystemAdminClient.UpdateChannelAnnouncementSettings(th.BasicChannel.Id, false, false)
+	CheckNoError(t, resp)
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateChannelAnnouncementSettings(model.NewId(), true, false)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.UpdateChannelAnnouncementSettings(th.BasicChannel.Id, true, false)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestCreateDirectChannel(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -606,6 +1035,34 @@ func TestGetChannelsForTeamForUser(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestGetChannelsForTeamForUserWithChannelScope(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	th.LoginBasicWithOAuthScope(Client, model.OAUTH_SCOPE_READ+" "+model.OAUTH_SCOPE_CHANNEL_PREFIX+th.BasicChannel.Id)
+
+	channels, resp := Client.GetChannelsForTeamForUser(th.BasicTeam.Id, th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+	if len(*channels) != 1 || (*channels)[0].Id != th.BasicChannel.Id {
+		t.Fatal("should only list the granted channel")
+	}
+
+	channels, resp = Client.SearchChannels(th.BasicTeam.Id, &model.ChannelSearch{Term: th.BasicChannel2.Name})
+	CheckNoError(t, resp)
+	if len(*channels) != 0 {
+		t.Fatal("should not find channels that weren't granted")
+	}
+
+	channels, resp = Client.GetPublicChannelsForTeam(th.BasicTeam.Id, 0, 100, "")
+	CheckNoError(t, resp)
+	for _, c := range *channels {
+		if c.Id != th.BasicChannel.Id {
+			t.Fatal("should only list the granted channel")
+		}
+	}
+}
+
 func TestSearchChannels(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -981,6 +1438,57 @@ func TestGetChannelByName(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestGetChannelByNameAfterRename(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	oldName := th.BasicChannel.Name
+
+	newName := GenerateTestChannelName()
+	_, resp := Client.PatchChannel(th.BasicChannel.Id, &model.ChannelPatch{Name: &newName})
+	CheckNoError(t, resp)
+
+	channel, resp := Client.GetChannelByName(oldName, th.BasicTeam.Id, "")
+	CheckNoError(t, resp)
+
+	if channel.Id != th.BasicChannel.Id || channel.Name != newName {
+		t.Fatal("the old name should resolve to the renamed channel")
+	}
+
+	if resp.RedirectedFrom != oldName {
+		t.Fatal("should have been told about the redirect")
+	}
+
+	channel, resp = Client.GetChannelByNameForTeamName(oldName, th.BasicTeam.Name, "")
+	CheckNoError(t, resp)
+
+	if channel.Id != th.BasicChannel.Id || resp.RedirectedFrom != oldName {
+		t.Fatal("the old name should resolve to the renamed channel")
+	}
+
+	_, resp = Client.GetChannelByName(newName, th.BasicTeam.Id, "")
+	CheckNoError(t, resp)
+
+	if resp.RedirectedFrom != "" {
+		t.Fatal("shouldn't have been redirected")
+	}
+
+	_, resp = Client.GetChannelByName(oldName, model.NewId(), "")
+	CheckNotFoundStatus(t, resp)
+
+	// Reusing the old name takes it over from the redirect
+	reused := th.CreatePublicChannel()
+	_, resp = Client.PatchChannel(reused.Id, &model.ChannelPatch{Name: &oldName})
+	CheckNoError(t, resp)
+
+	channel, resp = Client.GetChannelByName(oldName, th.BasicTeam.Id, "")
+	CheckNoError(t, resp)
+
+	if channel.Id != reused.Id || resp.RedirectedFrom != "" {
+		t.Fatal("should have found the channel that now has the name")
+	}
+}
+
 func TestGetChannelByNameForTeamName(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1313,6 +1821,65 @@ func TestGetChannelUnread(t *testing.T) {
 	CheckNotFoundStatus(t, resp)
 }
 
+func TestGetChannelLastRead(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	user := th.BasicUser
+	channel := th.BasicChannel
+
+	post := th.CreatePost()
+
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := app.CreatePost(&model.Post{ChannelId: channel.Id, UserId: th.BasicUser2.Id, Message: "unread"}, th.BasicTeam.Id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lastRead, resp := Client.GetChannelLastRead(channel.Id, user.Id)
+	CheckNoError(t, resp)
+
+	if lastRead.TeamId != th.BasicTeam.Id || lastRead.ChannelId != channel.Id || lastRead.UserId != user.Id {
+		t.Fatal("wrong channel returned")
+	}
+
+	if lastRead.LastReadPostId != post.Id {
+		t.Fatal("should have returned the last post the user read")
+	}
+
+	if lastRead.MsgCount != 2 {
+		t.Fatal("should have counted the unread posts")
+	}
+
+	_, resp = Client.GetChannelLastRead("junk", user.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetChannelLastRead(channel.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetChannelLastRead(channel.Id, model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetChannelLastRead(model.NewId(), user.Id)
+	CheckForbiddenStatus(t, resp)
+
+	newUser := th.CreateUser()
+	Client.Login(newUser.Email, newUser.Password)
+	_, resp = Client.GetChannelLastRead(channel.Id, user.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetChannelLastRead(channel.Id, user.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetChannelLastRead(channel.Id, user.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetChannelLastRead(channel.Id, model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
 func TestGetChannelStats(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1326,6 +1893,26 @@ func TestGetChannelStats(t *testing.T) {
 		t.Fatal("couldnt't get extra info")
 	} else if stats.MemberCount != 1 {
 		t.Fatal("got incorrect member count")
+	} else if stats.LargeChannel {
+		t.Fatal("shouldn't be a large channel")
+	}
+
+	largeChannelThreshold := *utils.Cfg.TeamSettings.LargeChannelThreshold
+	defer func() {
+		*utils.Cfg.TeamSettings.LargeChannelThreshold = largeChannelThreshold
+	}()
+	*utils.Cfg.TeamSettings.LargeChannelThreshold = 1
+
+	_, resp = Client.AddChannelMember(channel.Id, th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	stats, resp = Client.GetChannelStats(channel.Id, "")
+	CheckNoError(t, resp)
+
+	if stats.MemberCount != 2 {
+		t.Fatal("got incorrect member count")
+	} else if !stats.LargeChannel {
+		t.Fatal("should be a large channel")
 	}
 
 	_, resp = Client.GetChannelStats("junk", "")
@@ -1908,3 +2495,174 @@ func TestRemoveChannelMember(t *testing.T) {
 	_, resp = th.SystemAdminClient.RemoveUserFromChannel(privateChannel.Id, user2.Id)
 	CheckNoError(t, resp)
 }
+
+func TestAddAndRemoveChannelMembers(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	team := th.BasicTeam
+	publicChannel := th.CreatePublicChannel()
+	privateChannel := th.CreatePrivateChannel()
+
+	user3 := th.CreateUserWithClient(th.SystemAdminClient)
+	_, resp := th.SystemAdminClient.AddTeamMember(team.Id, user3.Id, "", "", team.InviteId)
+	CheckNoError(t, resp)
+
+	// not a member of the team
+	otherUser := th.CreateUser()
+
+	batch, resp := Client.AddChannelMembers(publicChannel.Id, []string{th.BasicUser2.Id, user3.Id, user3.Id, th.BasicUser.Id, otherUser.Id})
+	CheckNoError(t, resp)
+
+	if batch.ChannelId != publicChannel.Id {
+		t.Fatal("should have returned the channel")
+	}
+
+	if len(batch.UserIds) != 2 || len(batch.SkippedUserIds) != 2 {
+		t.Fatal("should have added the team members who weren't in the channel", batch.UserIds, batch.SkippedUserIds)
+	}
+
+	members, resp := Client.GetChannelMembersByIds(publicChannel.Id, []string{th.BasicUser2.Id, user3.Id, otherUser.Id})
+	CheckNoError(t, resp)
+
+	if len(*members) != 2 {
+		t.Fatal("should have added the members", len(*members))
+	}
+
+	_, resp = Client.AddChannelMembers(privateChannel.Id, []string{th.BasicUser2.Id, user3.Id})
+	CheckNoError(t, resp)
+
+	_, resp = Client.AddChannelMembers(publicChannel.Id, []string{"junk"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddChannelMembers(publicChannel.Id, []string{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddChannelMembers(model.NewId(), []string{th.BasicUser2.Id})
+	CheckNotFoundStatus(t, resp)
+
+	batch, resp = Client.RemoveChannelMembers(publicChannel.Id, []string{th.BasicUser2.Id, user3.Id, otherUser.Id})
+	CheckNoError(t, resp)
+
+	if len(batch.UserIds) != 2 || len(batch.SkippedUserIds) != 1 || batch.SkippedUserIds[0] != otherUser.Id {
+		t.Fatal("should have removed the channel members", batch.UserIds, batch.SkippedUserIds)
+	}
+
+	members, resp = Client.GetChannelMembersByIds(publicChannel.Id, []string{th.BasicUser2.Id, user3.Id})
+	CheckNoError(t, resp)
+
+	if len(*members) != 0 {
+		t.Fatal("should have removed the members", len(*members))
+	}
+
+	townSquare, resp := Client.GetChannelByName("town-square", team.Id, "")
+	CheckNoError(t, resp)
+
+	_, resp = Client.RemoveChannelMembers(townSquare.Id, []string{th.BasicUser2.Id})
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+
+	_, resp = Client.AddChannelMembers(publicChannel.Id, []string{th.BasicUser2.Id})
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.RemoveChannelMembers(privateChannel.Id, []string{th.BasicUser2.Id})
+	CheckUnauthorizedStatus(t, resp)
+
+	batch, resp = th.SystemAdminClient.RemoveChannelMembers(privateChannel.Id, []string{th.BasicUser2.Id, user3.Id})
+	CheckNoError(t, resp)
+
+	if len(batch.UserIds) != 2 {
+		t.Fatal("should have removed the private channel members", batch.UserIds)
+	}
+}
+
+func TestExportChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	channel := th.BasicChannel
+
+	_, resp := Client.ExportChannel(channel.Id, 0)
+	CheckForbiddenStatus(t, resp)
+
+	data, resp := th.SystemAdminClient.ExportChannel(channel.Id, 0)
+	CheckNoError(t, resp)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, file := range archive.File {
+		if file.Name == model.CHANNEL_EXPORT_SUMMARY_FILE {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have included the signed summary")
+	}
+
+	_, resp = th.SystemAdminClient.ExportChannel(channel.Id, model.GetMillis()+100000)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ExportChannel(model.NewId(), 0)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.ExportChannel(channel.Id, 0)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestVerifyChannelPostIntegrity(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enablePostIntegrityChain := *utils.Cfg.ComplianceSettings.EnablePostIntegrityChain
+	defer func() {
+		*utils.Cfg.ComplianceSettings.EnablePostIntegrityChain = enablePostIntegrityChain
+	}()
+
+	*utils.Cfg.ComplianceSettings.EnablePostIntegrityChain = false
+	_, resp := th.SystemAdminClient.VerifyChannelPostIntegrity(th.BasicChannel.Id)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ComplianceSettings.EnablePostIntegrityChain = true
+	channel := th.CreatePublicChannel()
+	post1 := th.CreatePostWithClient(Client, channel)
+	post2 := th.CreatePostWithClient(Client, channel)
+
+	_, resp = Client.VerifyChannelPostIntegrity(channel.Id)
+	CheckForbiddenStatus(t, resp)
+
+	verification, resp := th.SystemAdminClient.VerifyChannelPostIntegrity(channel.Id)
+	CheckNoError(t, resp)
+	if verification.RecordCount != 2 || !verification.IsValid() {
+		t.Fatal("should have verified the posts", verification.RecordCount, verification.Failures)
+	}
+
+	// Edits made through the server keep the chain valid
+	post1.Message = "edited"
+	_, resp = Client.UpdatePost(post1.Id, post1)
+	CheckNoError(t, resp)
+
+	verification, resp = th.SystemAdminClient.VerifyChannelPostIntegrity(channel.Id)
+	CheckNoError(t, resp)
+	if !verification.IsValid() {
+		t.Fatal("should not have reported an edit made through the server", verification.Failures)
+	}
+
+	// Edits made directly in the database are reported
+	if _, err := app.Srv.Store.(*store.SqlStore).GetMaster().Exec("UPDATE Posts SET Message = 'tampered' WHERE Id = :Id", map[string]interface{}{"Id": post2.Id}); err != nil {
+		t.Fatal(err)
+	}
+
+	verification, resp = th.SystemAdminClient.VerifyChannelPostIntegrity(channel.Id)
+	CheckNoError(t, resp)
+	if len(verification.Failures) != 1 || verification.Failures[0].PostId != post2.Id || verification.Failures[0].Reason != model.POST_INTEGRITY_FAILURE_MODIFIED_POST {
+		t.Fatal("should have reported the modified post", verification.Failures)
+	}
+}
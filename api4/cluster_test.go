@@ -5,6 +5,8 @@ package api4
 
 import (
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestGetClusterStatus(t *testing.T) {
@@ -19,4 +21,17 @@ func TestGetClusterStatus(t *testing.T) {
 	if infos == nil {
 		t.Fatal("should not be nil")
 	}
+
+	var me *model.ClusterInfo
+	for _, info := range infos {
+		if info.StartTime > 0 {
+			me = info
+		}
+	}
+
+	if me == nil {
+		t.Fatal("should have described this node")
+	} else if me.Version != model.CurrentVersion || me.SchemaVersion == "" || me.Uptime < 0 {
+		t.Fatal("should have reported this node's versions and uptime")
+	}
 }
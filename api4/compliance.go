@@ -21,6 +21,7 @@ func InitCompliance() {
 	BaseRoutes.Compliance.Handle("/reports", ApiSessionRequired(getComplianceReports)).Methods("GET")
 	BaseRoutes.Compliance.Handle("/reports/{report_id:[A-Za-z0-9]+}", ApiSessionRequired(getComplianceReport)).Methods("GET")
 	BaseRoutes.Compliance.Handle("/reports/{report_id:[A-Za-z0-9]+}/download", ApiSessionRequired(downloadComplianceReport)).Methods("GET")
+	BaseRoutes.Compliance.Handle("/formats", ApiSessionRequired(getComplianceFormats)).Methods("GET")
 }
 
 func createComplianceReport(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -125,3 +126,12 @@ func downloadComplianceReport(c *Context, w http.ResponseWriter, r *http.Request
 
 	w.Write(reportBytes)
 }
+
+func getComplianceFormats(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	w.Write([]byte(model.ArrayToJson(app.GetComplianceExportFormats())))
+}
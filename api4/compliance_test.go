@@ -0,0 +1,30 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetComplianceFormats(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetComplianceFormats()
+	CheckForbiddenStatus(t, resp)
+
+	formats, resp := th.SystemAdminClient.GetComplianceFormats()
+	CheckNoError(t, resp)
+
+	if len(formats) != 3 || formats[0] != model.COMPLIANCE_FORMAT_CSV || formats[1] != model.COMPLIANCE_FORMAT_ACTIANCE || formats[2] != model.COMPLIANCE_FORMAT_GLOBALRELAY {
+		t.Fatal("should have returned the compliance formats", formats)
+	}
+
+	Client.Logout()
+	_, resp = Client.GetComplianceFormats()
+	CheckUnauthorizedStatus(t, resp)
+}
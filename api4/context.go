@@ -26,7 +26,9 @@ type Context struct {
 	RequestId     string
 	IpAddress     string
 	Path          string
+	Config        *model.Config
 	siteURLHeader string
+	eventName     string
 }
 
 func ApiHandler(h func(*Context, http.ResponseWriter, *http.Request)) http.Handler {
@@ -74,11 +76,34 @@ func ApiSessionRequiredTrustRequester(h func(*Context, http.ResponseWriter, *htt
 	}
 }
 
+// ApiSessionRequiredReadOnly is for endpoints that take their query in a POST body but don't
+// change anything, so that OAuth tokens with the read scope can still use them.
+func ApiSessionRequiredReadOnly(h func(*Context, http.ResponseWriter, *http.Request)) http.Handler {
+	return &handler{
+		handleFunc:     h,
+		requireSession: true,
+		trustRequester: false,
+		requireMfa:     true,
+		isReadOnly:     true,
+	}
+}
+
+func ApiHandlerReadOnly(h func(*Context, http.ResponseWriter, *http.Request)) http.Handler {
+	return &handler{
+		handleFunc:     h,
+		requireSession: false,
+		trustRequester: false,
+		requireMfa:     false,
+		isReadOnly:     true,
+	}
+}
+
 type handler struct {
 	handleFunc     func(*Context, http.ResponseWriter, *http.Request)
 	requireSession bool
 	trustRequester bool
 	requireMfa     bool
+	isReadOnly     bool
 }
 
 func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -86,10 +111,14 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	l4g.Debug("%v - %v", r.Method, r.URL.Path)
 
 	c := &Context{}
+	// Changes to the config replace it wholesale, so each request keeps the config it started with
+	// even if it changes while the request is being handled
+	c.Config = app.Config()
 	c.T, _ = utils.GetTranslationsAndLocale(w, r)
 	c.RequestId = model.NewId()
 	c.IpAddress = utils.GetIpAddress(r)
 	c.Params = ApiParamsFromRequest(r)
+	c.eventName = app.GetAuditEventName(h.handleFunc)
 
 	token := ""
 	isTokenFromQueryString := false
@@ -139,6 +168,7 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Expires", "0")
 	}
 
+	userAccessTokenUsed := false
 	if len(token) != 0 {
 		session, err := app.GetSession(token)
 
@@ -154,11 +184,24 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			c.Err.StatusCode = http.StatusUnauthorized
 		} else {
 			c.Session = *session
+			userAccessTokenUsed = app.UpdateUserAccessTokenLastUsedIfNeeded(session)
 		}
 	}
 
 	c.Path = r.URL.Path
 
+	if userAccessTokenUsed {
+		c.LogAudit("user_access_token_id=" + c.Session.Props[model.SESSION_PROP_USER_ACCESS_TOKEN_ID])
+	}
+
+	if c.Err == nil {
+		c.Err = app.CheckEndpointRateLimit(w, r, &c.Session, c.IpAddress)
+	}
+
+	if c.Err == nil {
+		c.Err = app.CheckClientVersion(w, r, &c.Session)
+	}
+
 	if c.Err == nil && h.requireSession {
 		c.SessionRequired()
 	}
@@ -167,6 +210,10 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.MfaRequired()
 	}
 
+	if c.Err == nil && c.Session.IsOAuth {
+		c.OAuthScopeRequired(r, h.isReadOnly)
+	}
+
 	if c.Err == nil {
 		h.handleFunc(c, w, r)
 	}
@@ -179,7 +226,7 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		c.Err.Where = r.URL.Path
 
 		// Block out detailed error when not in developer mode
-		if !*utils.Cfg.ServiceSettings.EnableDeveloper {
+		if !*c.Config.ServiceSettings.EnableDeveloper {
 			c.Err.DetailedError = ""
 		}
 
@@ -201,23 +248,54 @@ func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (c *Context) LogAudit(extraInfo string) {
-	audit := &model.Audit{UserId: c.Session.UserId, IpAddress: c.IpAddress, Action: c.Path, ExtraInfo: extraInfo, SessionId: c.Session.Id}
-	if r := <-app.Srv.Store.Audit().Save(audit); r.Err != nil {
-		c.LogError(r.Err)
+func (c *Context) newAudit(userId string, extraInfo string) *model.Audit {
+	return &model.Audit{
+		UserId:    userId,
+		IpAddress: c.IpAddress,
+		Action:    c.Path,
+		ExtraInfo: extraInfo,
+		SessionId: c.Session.Id,
+		EventName: c.eventName,
+		ActorId:   c.Session.UserId,
+	}
+}
+
+func (c *Context) saveAudit(audit *model.Audit) {
+	if err := app.SaveAudit(audit); err != nil {
+		c.LogError(err)
 	}
 }
 
+func (c *Context) LogAudit(extraInfo string) {
+	c.saveAudit(c.newAudit(c.Session.UserId, extraInfo))
+}
+
 func (c *Context) LogAuditWithUserId(userId, extraInfo string) {
 
 	if len(c.Session.UserId) > 0 {
 		extraInfo = strings.TrimSpace(extraInfo + " session_user=" + c.Session.UserId)
 	}
 
-	audit := &model.Audit{UserId: userId, IpAddress: c.IpAddress, Action: c.Path, ExtraInfo: extraInfo, SessionId: c.Session.Id}
-	if r := <-app.Srv.Store.Audit().Save(audit); r.Err != nil {
-		c.LogError(r.Err)
+	audit := c.newAudit(userId, extraInfo)
+	audit.TargetType = model.AUDIT_TARGET_USER
+	audit.TargetId = userId
+
+	c.saveAudit(audit)
+}
+
+// LogAuditEvent records an audit of the object that the request acted on, with metadata that can be
+// read without parsing the extra info of the audit.
+func (c *Context) LogAuditEvent(targetType string, targetId string, metadata model.StringInterface) {
+	audit := c.newAudit(c.Session.UserId, "")
+	audit.TargetType = targetType
+	audit.TargetId = targetId
+	audit.Metadata = metadata
+
+	if targetType == model.AUDIT_TARGET_USER {
+		audit.UserId = targetId
 	}
+
+	c.saveAudit(audit)
 }
 
 func (c *Context) LogError(err *model.AppError) {
@@ -247,34 +325,19 @@ func (c *Context) SessionRequired() {
 	}
 }
 
-func (c *Context) MfaRequired() {
-	// Must be licensed for MFA and have it configured for enforcement
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
-		return
-	}
+// OAuthScopeRequired rejects requests that are not allowed by the scopes granted to an OAuth token.
+// Channels referenced in the request body are checked by the channel permission helpers instead.
+func (c *Context) OAuthScopeRequired(r *http.Request, isReadOnly bool) {
+	isWrite := !isReadOnly && r.Method != "GET" && r.Method != "HEAD"
 
-	// OAuth integrations are excepted
-	if c.Session.IsOAuth {
-		return
+	if err := app.CheckSessionOAuthScope(&c.Session, isWrite, c.Params.ChannelId); err != nil {
+		c.Err = err
 	}
+}
 
-	if user, err := app.GetUser(c.Session.UserId); err != nil {
-		c.Err = model.NewLocAppError("", "api.context.session_expired.app_error", nil, "MfaRequired")
-		c.Err.StatusCode = http.StatusUnauthorized
-		return
-	} else {
-		// Only required for email and ldap accounts
-		if user.AuthService != "" &&
-			user.AuthService != model.USER_AUTH_SERVICE_EMAIL &&
-			user.AuthService != model.USER_AUTH_SERVICE_LDAP {
-			return
-		}
-
-		if !user.MfaActive {
-			c.Err = model.NewLocAppError("", "api.context.mfa_required.app_error", nil, "MfaRequired")
-			c.Err.StatusCode = http.StatusUnauthorized
-			return
-		}
+func (c *Context) MfaRequired() {
+	if err := app.CheckSessionMfa(&c.Session); err != nil {
+		c.Err = err
 	}
 }
 
@@ -314,6 +377,26 @@ func (c *Context) SetPermissionError(permission *model.Permission) {
 	c.Err.StatusCode = http.StatusForbidden
 }
 
+// GetPagination returns where a page of count results sits in the full list if the client asked for
+// it with the include_total_count parameter, or nil if it didn't. countTotal may be nil for lists
+// that can't be counted, and a failure to count is logged instead of failing the request.
+func (c *Context) GetPagination(count int, countTotal func() (int64, *model.AppError)) *model.Pagination {
+	if !c.Params.IncludeTotalCount {
+		return nil
+	}
+
+	totalCount := int64(model.PAGINATION_TOTAL_COUNT_UNKNOWN)
+	if countTotal != nil {
+		if total, err := countTotal(); err != nil {
+			c.LogError(err)
+		} else {
+			totalCount = total
+		}
+	}
+
+	return model.NewPagination(c.Params.Page, c.Params.PerPage, count, totalCount)
+}
+
 func (c *Context) SetSiteURLHeader(url string) {
 	c.siteURLHeader = strings.TrimRight(url, "/")
 }
@@ -394,6 +477,30 @@ func (c *Context) RequireFileId() *Context {
 	return c
 }
 
+func (c *Context) RequireEmojiId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.EmojiId) != 26 {
+		c.SetInvalidUrlParam("emoji_id")
+	}
+
+	return c
+}
+
+func (c *Context) RequireEmojiName() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.EmojiName) == 0 || len(c.Params.EmojiName) > model.EMOJI_NAME_MAX_LENGTH {
+		c.SetInvalidUrlParam("emoji_name")
+	}
+
+	return c
+}
+
 func (c *Context) RequireReportId() *Context {
 	if c.Err != nil {
 		return c
@@ -477,6 +584,18 @@ func (c *Context) RequireHookId() *Context {
 	return c
 }
 
+func (c *Context) RequireActionId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ActionId) == 0 {
+		c.SetInvalidUrlParam("action_id")
+	}
+
+	return c
+}
+
 func (c *Context) RequireCommandId() *Context {
 	if c.Err != nil {
 		return c
@@ -487,3 +606,212 @@ func (c *Context) RequireCommandId() *Context {
 	}
 	return c
 }
+
+func (c *Context) RequireIncidentId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.IncidentId) != 26 {
+		c.SetInvalidUrlParam("incident_id")
+	}
+	return c
+}
+
+func (c *Context) RequireFlagName() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if !model.IsValidFeatureFlagName(c.Params.FlagName) {
+		c.SetInvalidUrlParam("flag_name")
+	}
+	return c
+}
+
+func (c *Context) RequireExperimentName() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if !model.IsValidExperimentName(c.Params.ExperimentName) {
+		c.SetInvalidUrlParam("experiment_name")
+	}
+	return c
+}
+
+func (c *Context) RequireTemplateId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TemplateId) != 26 {
+		c.SetInvalidUrlParam("template_id")
+	}
+	return c
+}
+
+func (c *Context) RequireJobId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.JobId) != 26 {
+		c.SetInvalidUrlParam("job_id")
+	}
+	return c
+}
+
+func (c *Context) RequireScheduledPostId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ScheduledPostId) != 26 {
+		c.SetInvalidUrlParam("scheduled_post_id")
+	}
+	return c
+}
+
+func (c *Context) RequireBotUserId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.BotUserId) != 26 {
+		c.SetInvalidUrlParam("bot_user_id")
+	}
+	return c
+}
+
+func (c *Context) RequireTokenId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.TokenId) != 26 {
+		c.SetInvalidUrlParam("token_id")
+	}
+	return c
+}
+
+func (c *Context) RequireCredentialId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.CredentialId) != 26 {
+		c.SetInvalidUrlParam("credential_id")
+	}
+	return c
+}
+
+func (c *Context) RequireDeviceId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.DeviceId) != 26 {
+		c.SetInvalidUrlParam("device_id")
+	}
+	return c
+}
+
+func (c *Context) RequireLdapGroupId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.LdapGroupId) != 26 {
+		c.SetInvalidUrlParam("ldap_group_id")
+	}
+	return c
+}
+
+func (c *Context) RequireInvitationId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.InvitationId) != 26 {
+		c.SetInvalidUrlParam("invitation_id")
+	}
+	return c
+}
+
+func (c *Context) RequireArchiveExportId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.ArchiveExportId) != 26 {
+		c.SetInvalidUrlParam("archive_export_id")
+	}
+	return c
+}
+
+func (c *Context) RequireBulkEmailId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.BulkEmailId) != 26 {
+		c.SetInvalidUrlParam("bulk_email_id")
+	}
+	return c
+}
+
+func (c *Context) RequirePolicyId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.PolicyId) != 26 {
+		c.SetInvalidUrlParam("policy_id")
+	}
+	return c
+}
+
+func (c *Context) RequireLegalHoldId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.LegalHoldId) != 26 {
+		c.SetInvalidUrlParam("legal_hold_id")
+	}
+	return c
+}
+
+func (c *Context) RequireCategoryId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.CategoryId) != 26 {
+		c.SetInvalidUrlParam("category_id")
+	}
+	return c
+}
+
+func (c *Context) RequireRemoteId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.RemoteId) != 26 {
+		c.SetInvalidUrlParam("remote_id")
+	}
+	return c
+}
+
+func (c *Context) RequireCacheName() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.CacheName) == 0 {
+		c.SetInvalidUrlParam("cache_name")
+	}
+	return c
+}
@@ -2,7 +2,10 @@ package api4
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestRequireHookId(t *testing.T) {
@@ -29,3 +32,62 @@ func TestRequireHookId(t *testing.T) {
 		}
 	})
 }
+
+func TestOAuthScopeRequired(t *testing.T) {
+	channelId := model.NewId()
+	session := model.Session{IsOAuth: true, Props: model.StringMap{model.SESSION_PROP_OAUTH_SCOPE: model.OAUTH_SCOPE_READ + " channel:" + channelId}}
+
+	t.Run("WhenReading", func(t *testing.T) {
+		c := &Context{Session: session, Params: &ApiParams{ChannelId: channelId}}
+		c.OAuthScopeRequired(httptest.NewRequest("GET", "/api/v4/channels/"+channelId, nil), false)
+
+		if c.Err != nil {
+			t.Fatal("should be allowed to read the channel")
+		}
+	})
+
+	t.Run("WhenWriting", func(t *testing.T) {
+		c := &Context{Session: session, Params: &ApiParams{ChannelId: channelId}}
+		c.OAuthScopeRequired(httptest.NewRequest("PUT", "/api/v4/channels/"+channelId, nil), false)
+
+		if c.Err == nil || c.Err.StatusCode != http.StatusForbidden {
+			t.Fatal("should not be allowed to write with a read scope")
+		}
+	})
+
+	t.Run("WhenReadingWithPost", func(t *testing.T) {
+		c := &Context{Session: session, Params: &ApiParams{}}
+		c.OAuthScopeRequired(httptest.NewRequest("POST", "/api/v4/users/ids", nil), true)
+
+		if c.Err != nil {
+			t.Fatal("should be allowed to use read only endpoints that take a POST body")
+		}
+	})
+
+	t.Run("WhenReadingAnotherChannel", func(t *testing.T) {
+		c := &Context{Session: session, Params: &ApiParams{ChannelId: model.NewId()}}
+		c.OAuthScopeRequired(httptest.NewRequest("GET", "/api/v4/channels/"+c.Params.ChannelId, nil), false)
+
+		if c.Err == nil || c.Err.StatusCode != http.StatusForbidden {
+			t.Fatal("should not be allowed to read another channel")
+		}
+	})
+
+	t.Run("WhenOnlyGrantedUserInfo", func(t *testing.T) {
+		c := &Context{Session: model.Session{IsOAuth: true, Props: model.StringMap{model.SESSION_PROP_OAUTH_SCOPE: "openid profile email"}}, Params: &ApiParams{}}
+		c.OAuthScopeRequired(httptest.NewRequest("GET", "/api/v4/users/me", nil), false)
+
+		if c.Err == nil || c.Err.StatusCode != http.StatusForbidden {
+			t.Fatal("should only be allowed to read the user info")
+		}
+	})
+
+	t.Run("WhenScopeIsMissing", func(t *testing.T) {
+		c := &Context{Session: model.Session{IsOAuth: true}, Params: &ApiParams{ChannelId: channelId}}
+		c.OAuthScopeRequired(httptest.NewRequest("POST", "/api/v4/channels/"+channelId, nil), false)
+
+		if c.Err != nil {
+			t.Fatal("tokens issued before scopes should keep full access")
+		}
+	})
+}
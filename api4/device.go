@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitDevice() {
+	l4g.Debug(utils.T("api.device.init.debug"))
+
+	BaseRoutes.User.Handle("/devices", ApiSessionRequired(getDevicesForUser)).Methods("GET")
+	BaseRoutes.User.Handle("/devices/{device_id:[A-Za-z0-9]+}", ApiSessionRequired(revokeDevice)).Methods("DELETE")
+
+	BaseRoutes.System.Handle("/devices/versions", ApiSessionRequired(getDeviceVersionCounts)).Methods("GET")
+}
+
+func getDevicesForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if devices, err := app.GetDevicesForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.DeviceListToJson(devices)))
+	}
+}
+
+func revokeDevice(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireDeviceId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.RevokeDevice(c.Params.UserId, c.Params.DeviceId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("device_id=" + c.Params.DeviceId)
+	ReturnStatusOK(w)
+}
+
+func getDeviceVersionCounts(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	var since int64
+	if sinceString := r.URL.Query().Get("since"); len(sinceString) > 0 {
+		var err error
+		if since, err = strconv.ParseInt(sinceString, 10, 64); err != nil || since < 0 {
+			c.SetInvalidParam("since")
+			return
+		}
+	}
+
+	if counts, err := app.GetDeviceVersionCounts(since); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.DeviceVersionCountListToJson(counts)))
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetDevicesForUser(t *testing.T) {
+	th := SetupParallel(t).InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+
+	MobileClient := th.CreateClient()
+	MobileClient.ClientVersion = "android/1.2.0"
+	_, resp := MobileClient.Login(th.BasicUser.Email, th.BasicUser.Password)
+	CheckNoError(t, resp)
+
+	_, resp = MobileClient.AttachDeviceId("apple:" + model.NewId())
+	CheckNoError(t, resp)
+
+	devices, resp := Client.GetDevicesForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	var mobileDevice *model.Device
+	for _, device := range devices {
+		if device.AppVersion == "1.2.0" {
+			mobileDevice = device
+		}
+
+		if device.PushToken != "" {
+			t.Fatal("should have removed the push token")
+		}
+	}
+
+	if len(devices) < 2 || mobileDevice == nil {
+		t.Fatal("should have returned a device for each session", devices)
+	} else if mobileDevice.Platform != model.DEVICE_PLATFORM_IOS {
+		t.Fatal("should have taken the platform from the push token", mobileDevice.Platform)
+	}
+
+	_, resp = Client.GetDevicesForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetDevicesForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	counts, resp := th.SystemAdminClient.GetDeviceVersionCounts(mobileDevice.LastSeenAt)
+	CheckNoError(t, resp)
+
+	found := false
+	for _, count := range counts {
+		if count.Platform == model.DEVICE_PLATFORM_IOS && count.AppVersion == "1.2.0" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have counted the device", counts)
+	}
+
+	_, resp = Client.GetDeviceVersionCounts(0)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RevokeDevice(th.BasicUser.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.RevokeDevice(th.BasicUser2.Id, mobileDevice.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RevokeDevice(th.BasicUser.Id, mobileDevice.Id)
+	CheckNoError(t, resp)
+
+	_, resp = MobileClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	remaining, resp := Client.GetDevicesForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(remaining) != len(devices)-1 {
+		t.Fatal("should have removed the revoked device", devices)
+	}
+
+	_, resp = Client.RevokeDevice(th.BasicUser.Id, mobileDevice.Id)
+	CheckNotFoundStatus(t, resp)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitDraft() {
+	l4g.Debug(utils.T("api.draft.init.debug"))
+
+	BaseRoutes.User.Handle("/drafts", ApiSessionRequired(getDraftsForUser)).Methods("GET")
+	BaseRoutes.User.Handle("/drafts", ApiSessionRequired(saveDraft)).Methods("PUT")
+	BaseRoutes.User.Handle("/drafts/{channel_id:[A-Za-z0-9]+}", ApiSessionRequired(deleteDraft)).Methods("DELETE")
+}
+
+func getDraftsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if drafts, err := app.GetDraftsForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.DraftListToJson(drafts)))
+	}
+}
+
+func saveDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	draft := model.DraftFromJson(r.Body)
+	if draft == nil {
+		c.SetInvalidParam("draft")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	draft.UserId = c.Params.UserId
+
+	if !app.SessionHasPermissionToChannel(c.Session, draft.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if rdraft, err := app.SaveDraft(draft); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(rdraft.ToJson()))
+	}
+}
+
+func deleteDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	rootId := r.URL.Query().Get("root_id")
+	if len(rootId) != 0 && len(rootId) != 26 {
+		c.SetInvalidParam("root_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.DeleteDraft(c.Params.UserId, c.Params.ChannelId, rootId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
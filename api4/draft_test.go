@@ -0,0 +1,140 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestSaveDraft(t *testing.T) {
+	th := SetupParallel(t).InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+	time.Sleep(300 * time.Millisecond)
+
+	draft := &model.Draft{ChannelId: th.BasicChannel.Id, Message: "hello"}
+	rdraft, resp := Client.SaveDraft(model.ME, draft)
+	CheckNoError(t, resp)
+
+	if rdraft.UserId != th.BasicUser.Id || rdraft.Message != draft.Message {
+		t.Fatal("draft did not match")
+	}
+
+	eventHit := false
+	timeout := time.After(2 * time.Second)
+	for !eventHit {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_DRAFT_UPDATED {
+				if wdraft := model.DraftFromJson(strings.NewReader(event.Data["draft"].(string))); wdraft.ChannelId == draft.ChannelId && wdraft.Message == draft.Message {
+					eventHit = true
+				}
+			}
+		case <-timeout:
+			t.Fatal("should have sent a draft updated event")
+		}
+	}
+
+	draft.Message = "hello again"
+	rdraft2, resp := Client.SaveDraft(th.BasicUser.Id, draft)
+	CheckNoError(t, resp)
+
+	if rdraft2.Message != draft.Message || rdraft2.CreateAt != rdraft.CreateAt {
+		t.Fatal("should have replaced the draft")
+	}
+
+	draft.RootId = "junk"
+	_, resp = Client.SaveDraft(model.ME, draft)
+	CheckBadRequestStatus(t, resp)
+
+	draft.RootId = ""
+	draft.ChannelId = th.BasicPrivateChannel.Id
+	th.LoginBasic2()
+	_, resp = Client.SaveDraft(model.ME, draft)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.SaveDraft(th.BasicUser.Id, draft)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.SaveDraft(model.ME, draft)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetDrafts(t *testing.T) {
+	th := SetupParallel(t).InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+
+	_, resp := Client.SaveDraft(model.ME, &model.Draft{ChannelId: th.BasicChannel.Id, Message: "channel draft"})
+	CheckNoError(t, resp)
+
+	post := th.CreatePost()
+	_, resp = Client.SaveDraft(model.ME, &model.Draft{ChannelId: th.BasicChannel.Id, RootId: post.Id, Message: "reply draft"})
+	CheckNoError(t, resp)
+
+	drafts, resp := Client.GetDrafts(model.ME)
+	CheckNoError(t, resp)
+
+	if len(drafts) != 2 {
+		t.Fatal("should have returned the channel and thread drafts")
+	}
+
+	_, resp = Client.GetDrafts(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetDrafts(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetDrafts(model.ME)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestDeleteDraft(t *testing.T) {
+	th := SetupParallel(t).InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+	_, resp := Client.SaveDraft(model.ME, &model.Draft{ChannelId: th.BasicChannel.Id, RootId: post.Id, Message: "reply draft"})
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeleteDraft(model.ME, th.BasicChannel.Id, "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteDraft(model.ME, th.BasicChannel.Id, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	pass, resp := Client.DeleteDraft(model.ME, th.BasicChannel.Id, post.Id)
+	CheckNoError(t, resp)
+
+	if !pass {
+		t.Fatal("should have passed")
+	}
+
+	if drafts, _ := Client.GetDrafts(model.ME); len(drafts) != 0 {
+		t.Fatal("should have deleted the draft")
+	}
+
+	Client.Logout()
+	_, resp = Client.DeleteDraft(model.ME, th.BasicChannel.Id, "")
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -5,6 +5,7 @@ package api4
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	l4g "github.com/alecthomas/log4go"
@@ -15,15 +16,28 @@ import (
 	"github.com/mattermost/platform/utils"
 )
 
+const (
+	FREQUENT_EMOJI_LIMIT_DEFAULT = 20
+	FREQUENT_EMOJI_LIMIT_MAXIMUM = 100
+)
+
 func InitEmoji() {
 	l4g.Debug(utils.T("api.emoji.init.debug"))
 
 	BaseRoutes.Emojis.Handle("", ApiSessionRequired(createEmoji)).Methods("POST")
 	BaseRoutes.Emojis.Handle("", ApiSessionRequired(getEmojiList)).Methods("GET")
+	BaseRoutes.Emojis.Handle("/search", ApiSessionRequiredReadOnly(searchEmoji)).Methods("POST")
+	BaseRoutes.Emojis.Handle("/autocomplete", ApiSessionRequired(autocompleteEmoji)).Methods("GET")
+	BaseRoutes.Emojis.Handle("/{emoji_name:[A-Za-z0-9_\\-\\+]+}/stats", ApiSessionRequired(getEmojiStats)).Methods("GET")
+	BaseRoutes.Emoji.Handle("/patch", ApiSessionRequired(patchEmoji)).Methods("PUT")
+
+	BaseRoutes.Analytics.Handle("/emoji", ApiSessionRequired(getEmojiAnalytics)).Methods("GET")
+
+	BaseRoutes.User.Handle("/emoji/frequent", ApiSessionRequired(getFrequentlyUsedEmoji)).Methods("GET")
 }
 
 func createEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !*utils.Cfg.ServiceSettings.EnableCustomEmoji {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
 		c.Err = model.NewAppError("createEmoji", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
 		return
 	}
@@ -34,7 +48,7 @@ func createEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(c.Config.FileSettings.DriverName) == 0 {
 		c.Err = model.NewAppError("createEmoji", "api.emoji.storage.app_error", nil, "", http.StatusNotImplemented)
 		return
 	}
@@ -68,7 +82,7 @@ func createEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func getEmojiList(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !*utils.Cfg.ServiceSettings.EnableCustomEmoji {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
 		c.Err = model.NewAppError("getEmoji", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
 		return
 	}
@@ -78,6 +92,174 @@ func getEmojiList(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = err
 		return
 	} else {
+		// The whole list is always returned
+		if pagination := c.GetPagination(len(listEmoji), func() (int64, *model.AppError) {
+			return int64(len(listEmoji)), nil
+		}); pagination != nil {
+			pagination.SetHeaders(w.Header())
+		}
+
 		w.Write([]byte(model.EmojiListToJson(listEmoji)))
 	}
 }
+
+func patchEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireEmojiId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
+		c.Err = model.NewAppError("patchEmoji", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	patch := model.EmojiPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("emoji")
+		return
+	}
+
+	emoji, err := app.GetEmoji(c.Params.EmojiId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if c.Session.UserId != emoji.CreatorId && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if patchedEmoji, err := app.PatchEmoji(emoji, patch); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + patchedEmoji.Name)
+		w.Write([]byte(patchedEmoji.ToJson()))
+	}
+}
+
+func searchEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
+		c.Err = model.NewAppError("searchEmoji", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	search := model.EmojiSearchFromJson(r.Body)
+	if search == nil {
+		c.SetInvalidParam("")
+		return
+	}
+
+	if len(search.Term) == 0 {
+		c.SetInvalidParam("term")
+		return
+	}
+
+	if emojis, err := app.SearchEmoji(search.Term, search.PrefixOnly, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.EmojiListToJson(emojis)))
+	}
+}
+
+// autocompleteEmoji returns the custom emoji starting with the name or one of its aliases so that
+// they can be suggested while typing along with the built-in emoji.
+func autocompleteEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
+		c.Err = model.NewAppError("autocompleteEmoji", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if len(name) == 0 {
+		c.SetInvalidUrlParam("name")
+		return
+	}
+
+	if emojis, err := app.SearchEmoji(name, true, app.EMOJI_AUTOCOMPLETE_LIMIT); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.EmojiListToJson(emojis)))
+	}
+}
+
+func getEmojiStats(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
+		c.Err = model.NewAppError("getEmojiStats", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	c.RequireEmojiName()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	emoji, err := app.GetEmojiByName(c.Params.EmojiName)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if stats, err := app.GetEmojiStats(emoji); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(stats.ToJson()))
+	}
+}
+
+func getEmojiAnalytics(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.Config.ServiceSettings.EnableCustomEmoji {
+		c.Err = model.NewAppError("getEmojiAnalytics", "api.emoji.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if stats, err := app.GetEmojiAnalytics(); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.EmojiStatsListToJson(stats)))
+	}
+}
+
+func getFrequentlyUsedEmoji(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	limit := FREQUENT_EMOJI_LIMIT_DEFAULT
+	if limitString := r.URL.Query().Get("limit"); len(limitString) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(limitString); err != nil || limit <= 0 || limit > FREQUENT_EMOJI_LIMIT_MAXIMUM {
+			c.SetInvalidParam("limit")
+			return
+		}
+	}
+
+	if counts, err := app.GetFrequentlyUsedEmoji(c.Params.UserId, limit); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.EmojiUsageCountListToJson(counts)))
+	}
+}
@@ -4,8 +4,13 @@
 package api4
 
 import (
+	"bytes"
+	"image/gif"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 )
@@ -15,11 +20,8 @@ func TestCreateEmoji(t *testing.T) {
 	defer TearDown()
 	Client := th.Client
 
-	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
-	defer func() {
-		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
-	}()
-	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+	cfg := th.UseStaticConfig()
+	*cfg.ServiceSettings.EnableCustomEmoji = false
 
 	emoji := &model.Emoji{
 		CreatorId: th.BasicUser.Id,
@@ -30,7 +32,7 @@ func TestCreateEmoji(t *testing.T) {
 	_, resp := Client.CreateEmoji(emoji, utils.CreateTestGif(t, 10, 10), "image.gif")
 	CheckNotImplementedStatus(t, resp)
 
-	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+	*cfg.ServiceSettings.EnableCustomEmoji = true
 	// try to create a valid gif emoji when they're enabled
 	newEmoji, resp := Client.CreateEmoji(emoji, utils.CreateTestGif(t, 10, 10), "image.gif")
 	CheckNoError(t, resp)
@@ -138,6 +140,65 @@ func TestCreateEmoji(t *testing.T) {
 	CheckForbiddenStatus(t, resp)
 }
 
+func TestCreateAnimatedEmoji(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	MaxEmojiFrames := *utils.Cfg.ServiceSettings.MaxEmojiFrames
+	MaxEmojiUploadWidth := *utils.Cfg.ServiceSettings.MaxEmojiUploadWidth
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+		*utils.Cfg.ServiceSettings.MaxEmojiFrames = MaxEmojiFrames
+		*utils.Cfg.ServiceSettings.MaxEmojiUploadWidth = MaxEmojiUploadWidth
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+	*utils.Cfg.ServiceSettings.MaxEmojiFrames = 10
+	*utils.Cfg.ServiceSettings.MaxEmojiUploadWidth = 500
+
+	// a large animated gif should be resized without losing its frames
+	newEmoji, resp := Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, utils.CreateTestAnimatedGif(t, 200, 200, 5), "image.gif")
+	CheckNoError(t, resp)
+
+	if data, err := app.ReadFile("emoji/" + newEmoji.Id + "/image"); err != nil {
+		t.Fatal(err)
+	} else if gifData, err := gif.DecodeAll(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	} else if len(gifData.Image) != 5 {
+		t.Fatal("should have kept every frame", len(gifData.Image))
+	} else if gifData.Config.Width > app.MaxEmojiWidth || gifData.Config.Height > app.MaxEmojiHeight {
+		t.Fatal("should have resized the gif")
+	}
+
+	_, resp = Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, utils.CreateTestAnimatedGif(t, 10, 10, 11), "image.gif")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.upload.frames.app_error")
+
+	_, resp = Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, utils.CreateTestGif(t, 501, 10), "image.gif")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.upload.dimensions.app_error")
+
+	// an animated png should be stored as it was uploaded
+	apng := utils.CreateTestAnimatedPng(t, 10, 10, 3)
+	newEmoji, resp = Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, apng, "image.png")
+	CheckNoError(t, resp)
+
+	if data, err := app.ReadFile("emoji/" + newEmoji.Id + "/image"); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(data, apng) {
+		t.Fatal("should have kept the animated png's frames")
+	}
+
+	_, resp = Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, utils.CreateTestAnimatedPng(t, 10, 10, 11), "image.png")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.upload.frames.app_error")
+
+	_, resp = Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: model.NewId()}, utils.CreateTestAnimatedPng(t, 200, 200, 2), "image.png")
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.upload.large_image.animated_png.app_error")
+}
+
 func TestGetEmojiList(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -188,3 +249,247 @@ func TestGetEmojiList(t *testing.T) {
 	// ADD delete test when create the delete endpoint
 
 }
+
+func TestGetFrequentlyUsedEmoji(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	app.RecordEmojiUsage(th.BasicUser.Id, []string{"+1"})
+	app.RecordEmojiUsage(th.BasicUser.Id, []string{"+1", "tada"})
+
+	_, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "ship it :+1: :smile: at 12:30:45"})
+	CheckNoError(t, resp)
+
+	time.Sleep(300 * time.Millisecond)
+
+	counts, resp := Client.GetFrequentlyUsedEmoji(th.BasicUser.Id, 10)
+	CheckNoError(t, resp)
+
+	if len(counts) != 3 {
+		t.Fatal("should have returned the emoji used in reactions and messages")
+	}
+
+	if counts[0].EmojiName != "+1" || counts[0].Count != 3 {
+		t.Fatal("the most used emoji should be first")
+	}
+
+	counts, resp = Client.GetFrequentlyUsedEmoji(th.BasicUser.Id, 1)
+	CheckNoError(t, resp)
+
+	if len(counts) != 1 {
+		t.Fatal("should have limited the results")
+	}
+
+	_, resp = Client.GetFrequentlyUsedEmoji(th.BasicUser.Id, 1000)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetFrequentlyUsedEmoji(th.BasicUser2.Id, 10)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetFrequentlyUsedEmoji(th.BasicUser.Id, 10)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetFrequentlyUsedEmoji(th.BasicUser.Id, 10)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestPatchEmoji(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	emoji := &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+		Category:  model.EMOJI_CATEGORY_PEOPLE,
+		Aliases:   model.StringArray{":alias" + model.NewId()[:10] + ":"},
+	}
+
+	emoji, resp := Client.CreateEmoji(emoji, utils.CreateTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+	if emoji.Category != model.EMOJI_CATEGORY_PEOPLE || len(emoji.Aliases) != 1 || strings.Contains(emoji.Aliases[0], ":") {
+		t.Fatal("should have created the emoji with its category and aliases", emoji)
+	}
+
+	category := model.EMOJI_CATEGORY_OBJECTS
+	aliases := model.StringArray{"first" + model.NewId()[:10], "second" + model.NewId()[:10]}
+
+	patched, resp := Client.PatchEmoji(emoji.Id, &model.EmojiPatch{Category: &category, Aliases: &aliases})
+	CheckNoError(t, resp)
+	if patched.Name != emoji.Name || patched.Category != category || len(patched.Aliases) != 2 || patched.Aliases[1] != aliases[1] {
+		t.Fatal("didn't patch the emoji correctly", patched)
+	}
+
+	category = "unknown"
+	_, resp = Client.PatchEmoji(emoji.Id, &model.EmojiPatch{Category: &category})
+	CheckBadRequestStatus(t, resp)
+
+	other := &model.Emoji{
+		CreatorId: th.BasicUser.Id,
+		Name:      model.NewId(),
+	}
+	other, resp = Client.CreateEmoji(other, utils.CreateTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	aliases = model.StringArray{other.Name}
+	_, resp = Client.PatchEmoji(emoji.Id, &model.EmojiPatch{Aliases: &aliases})
+	CheckBadRequestStatus(t, resp)
+	CheckErrorMessage(t, resp, "api.emoji.alias.duplicate.app_error")
+
+	_, resp = Client.PatchEmoji("junk", &model.EmojiPatch{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.PatchEmoji(model.NewId(), &model.EmojiPatch{})
+	CheckNotFoundStatus(t, resp)
+
+	Client.Login(th.BasicUser2.Email, th.BasicUser2.Password)
+	_, resp = Client.PatchEmoji(emoji.Id, &model.EmojiPatch{})
+	CheckForbiddenStatus(t, resp)
+
+	category = model.EMOJI_CATEGORY_SYMBOLS
+	_, resp = th.SystemAdminClient.PatchEmoji(emoji.Id, &model.EmojiPatch{Category: &category})
+	CheckNoError(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+	_, resp = th.SystemAdminClient.PatchEmoji(emoji.Id, &model.EmojiPatch{})
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestSearchEmoji(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	prefix := "search" + model.NewId()[:10]
+
+	emojis := []*model.Emoji{
+		{
+			CreatorId: th.BasicUser.Id,
+			Name:      prefix + "_name",
+		},
+		{
+			CreatorId: th.BasicUser.Id,
+			Name:      "other" + model.NewId()[:10],
+			Category:  model.EMOJI_CATEGORY_FOODS,
+			Aliases:   model.StringArray{prefix + "_alias"},
+		},
+		{
+			CreatorId: th.BasicUser.Id,
+			Name:      "contains_" + prefix,
+		},
+	}
+
+	for idx, emoji := range emojis {
+		emoji, resp := Client.CreateEmoji(emoji, utils.CreateTestGif(t, 10, 10), "image.gif")
+		CheckNoError(t, resp)
+		emojis[idx] = emoji
+	}
+
+	found, resp := Client.SearchEmoji(&model.EmojiSearch{Term: prefix}, 60)
+	CheckNoError(t, resp)
+	if len(found) != 3 {
+		t.Fatal("should have found every emoji containing the term", len(found))
+	}
+
+	found, resp = Client.SearchEmoji(&model.EmojiSearch{Term: ":" + prefix, PrefixOnly: true}, 60)
+	CheckNoError(t, resp)
+	if len(found) != 2 {
+		t.Fatal("should have found the emoji starting with the term", len(found))
+	}
+
+	_, resp = Client.SearchEmoji(&model.EmojiSearch{}, 60)
+	CheckBadRequestStatus(t, resp)
+
+	found, resp = Client.AutocompleteEmoji(prefix + "_al")
+	CheckNoError(t, resp)
+	if len(found) != 1 || found[0].Id != emojis[1].Id {
+		t.Fatal("should have autocompleted the emoji by its alias", found)
+	} else if found[0].Category != model.EMOJI_CATEGORY_FOODS || len(found[0].Aliases) != 1 {
+		t.Fatal("should have included the category and aliases", found[0])
+	}
+
+	_, resp = Client.AutocompleteEmoji("")
+	CheckBadRequestStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+	_, resp = Client.AutocompleteEmoji(prefix)
+	CheckNotImplementedStatus(t, resp)
+
+	_, resp = Client.SearchEmoji(&model.EmojiSearch{Term: prefix}, 60)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestGetEmojiStats(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	EnableCustomEmoji := *utils.Cfg.ServiceSettings.EnableCustomEmoji
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCustomEmoji = EnableCustomEmoji
+	}()
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = true
+
+	emoji, resp := Client.CreateEmoji(&model.Emoji{CreatorId: th.BasicUser.Id, Name: "stats" + model.NewId()[:10]}, utils.CreateTestGif(t, 10, 10), "image.gif")
+	CheckNoError(t, resp)
+
+	if result := <-app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: th.BasicPost.Id, EmojiName: emoji.Name}); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "nice :" + emoji.Name + ": :smile:"})
+	CheckNoError(t, resp)
+
+	time.Sleep(300 * time.Millisecond)
+
+	stats, resp := th.SystemAdminClient.GetEmojiStats(emoji.Name)
+	CheckNoError(t, resp)
+
+	if stats.EmojiId != emoji.Id || stats.ReactionCount != 1 || stats.ReactionUserCount != 1 || stats.MessageCount != 1 {
+		t.Fatal("should have counted the emoji's usage", stats)
+	}
+
+	_, resp = th.SystemAdminClient.GetEmojiStats("missing" + model.NewId()[:10])
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetEmojiStats(emoji.Name)
+	CheckForbiddenStatus(t, resp)
+
+	analytics, resp := th.SystemAdminClient.GetEmojiAnalytics()
+	CheckNoError(t, resp)
+
+	found := false
+	for _, stat := range analytics {
+		if stat.EmojiId == emoji.Id && stat.MessageCount == 1 {
+			found = true
+		} else if stat.EmojiName == "smile" {
+			t.Fatal("shouldn't have counted system emoji")
+		}
+	}
+
+	if !found {
+		t.Fatal("should have included the emoji")
+	}
+
+	_, resp = Client.GetEmojiAnalytics()
+	CheckForbiddenStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableCustomEmoji = false
+
+	_, resp = th.SystemAdminClient.GetEmojiAnalytics()
+	CheckNotImplementedStatus(t, resp)
+}
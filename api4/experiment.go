@@ -0,0 +1,169 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitExperiment() {
+	l4g.Debug(utils.T("api.experiment.init.debug"))
+
+	BaseRoutes.Experiments.Handle("", ApiSessionRequired(getExperiments)).Methods("GET")
+	BaseRoutes.Experiment.Handle("", ApiSessionRequired(getExperiment)).Methods("GET")
+	BaseRoutes.Experiment.Handle("", ApiSessionRequired(saveExperiment)).Methods("PUT")
+	BaseRoutes.Experiment.Handle("", ApiSessionRequired(deleteExperiment)).Methods("DELETE")
+	BaseRoutes.Experiment.Handle("/summary", ApiSessionRequired(getExperimentSummary)).Methods("GET")
+
+	BaseRoutes.User.Handle("/experiments", ApiSessionRequired(getExperimentVariantsForUser)).Methods("GET")
+	BaseRoutes.User.Handle("/experiments/{experiment_name:[a-z0-9_\\-]+}/exposure", ApiSessionRequired(logExperimentExposure)).Methods("POST")
+}
+
+func getExperiments(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if experiments, err := app.GetExperiments(); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ExperimentListToJson(experiments)))
+	}
+}
+
+func getExperiment(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireExperimentName()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if experiment, err := app.GetExperiment(c.Params.ExperimentName); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(experiment.ToJson()))
+	}
+}
+
+func saveExperiment(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireExperimentName()
+	if c.Err != nil {
+		return
+	}
+
+	experiment := model.ExperimentFromJson(r.Body)
+	if experiment == nil {
+		c.SetInvalidParam("experiment")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	experiment.Name = c.Params.ExperimentName
+
+	if rexperiment, err := app.SaveExperiment(experiment); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rexperiment.Name)
+		w.Write([]byte(rexperiment.ToJson()))
+	}
+}
+
+func deleteExperiment(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireExperimentName()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteExperiment(c.Params.ExperimentName); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + c.Params.ExperimentName)
+	ReturnStatusOK(w)
+}
+
+func getExperimentSummary(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireExperimentName()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if summary, err := app.GetExperimentSummary(c.Params.ExperimentName); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(summary.ToJson()))
+	}
+}
+
+func getExperimentVariantsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if variants, err := app.GetExperimentVariantsForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.MapToJson(variants)))
+	}
+}
+
+func logExperimentExposure(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireExperimentName()
+	if c.Err != nil {
+		return
+	}
+
+	// Exposures can only be logged by the user that saw the experiment
+	if c.Params.UserId != c.Session.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if exposure, err := app.LogExperimentExposure(c.Params.ExperimentName, c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(exposure.ToJson()))
+	}
+}
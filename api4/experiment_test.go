@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestExperiments(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	configFlags := utils.Cfg.FeatureFlagSettings.Flags
+	defer func() {
+		utils.Cfg.FeatureFlagSettings.Flags = configFlags
+	}()
+	utils.Cfg.FeatureFlagSettings.Flags = []*model.FeatureFlag{{Name: "onboarding", Enabled: true, UserIds: model.StringArray{th.BasicUser.Id}}}
+
+	experiment := &model.Experiment{Name: "new_onboarding", FlagName: "onboarding", Variants: model.StringArray{"control", "tour"}, Active: true}
+	defer th.SystemAdminClient.DeleteExperiment(experiment.Name)
+
+	_, resp := Client.SaveExperiment(experiment)
+	CheckForbiddenStatus(t, resp)
+
+	rexperiment, resp := th.SystemAdminClient.SaveExperiment(experiment)
+	CheckNoError(t, resp)
+
+	if rexperiment.Name != experiment.Name || rexperiment.CreateAt == 0 {
+		t.Fatal("should have saved the experiment")
+	}
+
+	_, resp = th.SystemAdminClient.SaveExperiment(&model.Experiment{Name: "bad_variants", Variants: model.StringArray{"control"}})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetExperiment(experiment.Name)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetExperiment(experiment.Name)
+	CheckForbiddenStatus(t, resp)
+
+	experiments, resp := th.SystemAdminClient.GetExperiments()
+	CheckNoError(t, resp)
+
+	found := false
+	for _, e := range experiments {
+		if e.Name == experiment.Name {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned the experiment")
+	}
+
+	_, resp = Client.GetExperiments()
+	CheckForbiddenStatus(t, resp)
+
+	variants, resp := Client.GetExperimentVariantsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	variant := variants[experiment.Name]
+	if variant != rexperiment.GetVariant(th.BasicUser.Id) {
+		t.Fatal("should have returned the assigned variant")
+	}
+
+	_, resp = Client.GetExperimentVariantsForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	th.LoginBasic2()
+
+	variants, resp = Client.GetExperimentVariantsForUser(th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if _, ok := variants[experiment.Name]; ok {
+		t.Fatal("user without the flag should not be in the experiment")
+	}
+
+	_, resp = Client.LogExperimentExposure(th.BasicUser2.Id, experiment.Name)
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	th.LoginBasic()
+
+	_, resp = Client.LogExperimentExposure(th.BasicUser2.Id, experiment.Name)
+	CheckForbiddenStatus(t, resp)
+
+	exposure, resp := Client.LogExperimentExposure(th.BasicUser.Id, experiment.Name)
+	CheckNoError(t, resp)
+
+	if exposure.Variant != variant {
+		t.Fatal("should have logged the assigned variant")
+	}
+
+	_, resp = Client.LogExperimentExposure(th.BasicUser.Id, experiment.Name)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetExperimentSummary(experiment.Name)
+	CheckForbiddenStatus(t, resp)
+
+	summary, resp := th.SystemAdminClient.GetExperimentSummary(experiment.Name)
+	CheckNoError(t, resp)
+
+	for _, v := range summary.Variants {
+		if v.Variant == variant && (v.Users != 1 || v.Exposures != 2) {
+			t.Fatal("wrong exposure counts", v)
+		}
+	}
+
+	_, resp = Client.DeleteExperiment(experiment.Name)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteExperiment(experiment.Name)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetExperiment(experiment.Name)
+	CheckNotFoundStatus(t, resp)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitFeatureFlag() {
+	l4g.Debug(utils.T("api.feature_flag.init.debug"))
+
+	BaseRoutes.FeatureFlags.Handle("", ApiSessionRequired(getFeatureFlags)).Methods("GET")
+	BaseRoutes.FeatureFlag.Handle("", ApiSessionRequired(saveFeatureFlag)).Methods("PUT")
+	BaseRoutes.FeatureFlag.Handle("", ApiSessionRequired(deleteFeatureFlag)).Methods("DELETE")
+
+	BaseRoutes.User.Handle("/feature_flags", ApiSessionRequired(getFeatureFlagsForUser)).Methods("GET")
+}
+
+func getFeatureFlags(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if flags, err := app.GetFeatureFlags(); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.FeatureFlagListToJson(flags)))
+	}
+}
+
+func saveFeatureFlag(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireFlagName()
+	if c.Err != nil {
+		return
+	}
+
+	flag := model.FeatureFlagFromJson(r.Body)
+	if flag == nil {
+		c.SetInvalidParam("feature_flag")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	flag.Name = c.Params.FlagName
+
+	if rflag, err := app.SaveFeatureFlag(flag); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("name=" + rflag.Name)
+		w.Write([]byte(rflag.ToJson()))
+	}
+}
+
+func deleteFeatureFlag(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireFlagName()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteFeatureFlag(c.Params.FlagName); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("name=" + c.Params.FlagName)
+	ReturnStatusOK(w)
+}
+
+func getFeatureFlagsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	teamId := r.URL.Query().Get("team_id")
+	if len(teamId) > 0 {
+		if len(teamId) != 26 {
+			c.SetInvalidParam("team_id")
+			return
+		}
+
+		if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_VIEW_TEAM) {
+			c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+			return
+		}
+	}
+
+	if flags, err := app.EvaluateFeatureFlagsForUser(c.Params.UserId, teamId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.MapBoolToJson(flags)))
+	}
+}
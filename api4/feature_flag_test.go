@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	configFlags := utils.Cfg.FeatureFlagSettings.Flags
+	defer func() {
+		utils.Cfg.FeatureFlagSettings.Flags = configFlags
+	}()
+	utils.Cfg.FeatureFlagSettings.Flags = []*model.FeatureFlag{{Name: "from_config", Enabled: true, RolloutPercentage: 100}}
+
+	flag := &model.FeatureFlag{Name: "team_rollout", Enabled: true, TeamIds: model.StringArray{th.BasicTeam.Id}}
+
+	_, resp := Client.SaveFeatureFlag(flag)
+	CheckForbiddenStatus(t, resp)
+
+	rflag, resp := th.SystemAdminClient.SaveFeatureFlag(flag)
+	CheckNoError(t, resp)
+
+	if rflag.Name != flag.Name || rflag.CreateAt == 0 {
+		t.Fatal("should have saved the flag")
+	}
+
+	_, resp = th.SystemAdminClient.SaveFeatureFlag(&model.FeatureFlag{Name: "bad_percentage", RolloutPercentage: 200})
+	CheckBadRequestStatus(t, resp)
+
+	flags, resp := th.SystemAdminClient.GetFeatureFlags()
+	CheckNoError(t, resp)
+
+	names := map[string]bool{}
+	for _, f := range flags {
+		names[f.Name] = true
+	}
+
+	if !names["from_config"] || !names["team_rollout"] {
+		t.Fatal("should have returned the config and stored flags")
+	}
+
+	_, resp = Client.GetFeatureFlags()
+	CheckForbiddenStatus(t, resp)
+
+	result, resp := Client.GetFeatureFlagsForUser(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	if !result["from_config"] || !result["team_rollout"] {
+		t.Fatal("both flags should be on for the user")
+	}
+
+	result, resp = Client.GetFeatureFlagsForUser(th.BasicUser.Id, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if !result["team_rollout"] {
+		t.Fatal("should be on for the allowed team")
+	}
+
+	_, resp = Client.GetFeatureFlagsForUser(th.BasicUser.Id, model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetFeatureFlagsForUser(th.BasicUser2.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeleteFeatureFlag(flag.Name)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteFeatureFlag(flag.Name)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteFeatureFlag(flag.Name)
+	CheckNotFoundStatus(t, resp)
+
+	result, resp = Client.GetFeatureFlagsForUser(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	if _, ok := result["team_rollout"]; ok {
+		t.Fatal("deleted flag should not be evaluated")
+	}
+}
@@ -470,3 +470,59 @@ func TestGetPublicFile(t *testing.T) {
 
 	cleanupTestFile(info)
 }
+
+func TestSearchFiles(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+	channel := th.BasicChannel
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	fileResp, resp := Client.UploadFile([]byte("minutes of the quarterly planning meeting"), channel.Id, "minutes.txt")
+	CheckNoError(t, resp)
+	fileId := fileResp.FileInfos[0].Id
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "attached", FileIds: []string{fileId}})
+	CheckNoError(t, resp)
+
+	infos, resp := Client.SearchFiles(th.BasicTeam.Id, "quarterly", false)
+	CheckNoError(t, resp)
+	if len(infos) != 1 || infos[0].Id != fileId {
+		t.Fatal("should've found the file by its content")
+	}
+
+	infos, resp = Client.SearchFiles(th.BasicTeam.Id, "quarterly budget", false)
+	CheckNoError(t, resp)
+	if len(infos) != 0 {
+		t.Fatal("shouldn't have found a file without all of the terms")
+	}
+
+	infos, resp = Client.SearchFiles(th.BasicTeam.Id, "quarterly budget", true)
+	CheckNoError(t, resp)
+	if len(infos) != 1 {
+		t.Fatal("should've found the file with one of the terms")
+	}
+
+	_, resp = Client.SearchFiles(th.BasicTeam.Id, "", false)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.SearchFiles(model.NewId(), "quarterly", false)
+	CheckForbiddenStatus(t, resp)
+
+	otherUser := th.CreateUser()
+	LinkUserToTeam(otherUser, th.BasicTeam)
+	Client.Login(otherUser.Email, otherUser.Password)
+
+	infos, resp = Client.SearchFiles(th.BasicTeam.Id, "quarterly", false)
+	CheckNoError(t, resp)
+	if len(infos) != 0 {
+		t.Fatal("shouldn't have found a file in a channel the user doesn't belong to")
+	}
+
+	Client.Logout()
+	_, resp = Client.SearchFiles(th.BasicTeam.Id, "quarterly", false)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -0,0 +1,514 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"errors"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/graphql"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+var graphqlSchema = newGraphQLSchema()
+
+func InitGraphQL() {
+	l4g.Debug(utils.T("api.graphql.init.debug"))
+
+	BaseRoutes.ApiRoot.Handle("/graphql", ApiSessionRequiredReadOnly(executeGraphQL)).Methods("POST")
+}
+
+func executeGraphQL(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.Config.ServiceSettings.EnableGraphQL {
+		c.Err = model.NewAppError("executeGraphQL", "api.graphql.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	request := graphql.RequestFromJson(r.Body)
+	if request == nil || len(request.Query) == 0 {
+		c.SetInvalidParam("query")
+		return
+	}
+
+	// Errors in the query are returned in the body along with whatever data could be resolved
+	response := graphql.Execute(graphqlSchema, request.Query, request.OperationName, request.Variables, &graphqlRequest{c: c, r: r})
+	w.Write([]byte(response.ToJson()))
+}
+
+// graphqlRequest is what the resolvers are given to load data and check permissions with.
+type graphqlRequest struct {
+	c *Context
+	r *http.Request
+}
+
+func (g *graphqlRequest) error(err *model.AppError) error {
+	err.Translate(g.c.T)
+	return errors.New(err.Message)
+}
+
+func (g *graphqlRequest) permissionError(permission *model.Permission) error {
+	return g.error(model.NewAppError("executeGraphQL", "api.context.permissions.app_error", nil, "userId="+g.c.Session.UserId+", permission="+permission.Id, http.StatusForbidden))
+}
+
+func (g *graphqlRequest) invalidParamError(name string) error {
+	return g.error(NewInvalidParamError(name))
+}
+
+func (g *graphqlRequest) canViewTeam(team *model.Team) bool {
+	return team.Type == model.TEAM_OPEN || app.SessionHasPermissionToTeam(g.c.Session, team.Id, model.PERMISSION_VIEW_TEAM)
+}
+
+func (g *graphqlRequest) canViewChannel(channel *model.Channel) bool {
+	if !g.c.Session.IsChannelAllowedByOAuthScope(channel.Id) {
+		return false
+	}
+
+	if channel.Type == model.CHANNEL_OPEN {
+		return app.SessionHasPermissionToTeam(g.c.Session, channel.TeamId, model.PERMISSION_READ_PUBLIC_CHANNEL)
+	}
+
+	return app.SessionHasPermissionToChannel(g.c.Session, channel.Id, model.PERMISSION_READ_CHANNEL)
+}
+
+// getUsers loads the given users with a single query, sanitized for the current user.
+func (g *graphqlRequest) getUsers(userIds []string) (map[string]*model.User, error) {
+	users, err := app.GetUsersByIds(userIds, g.c.IsSystemAdmin())
+	if err != nil {
+		return nil, g.error(err)
+	}
+
+	usersById := make(map[string]*model.User, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	return usersById, nil
+}
+
+// resolveUsers resolves a field holding the id of a user to that user, loading the users of
+// every source together.
+func resolveUsers(getUserId func(source interface{}) string) graphql.ResolveFunc {
+	return func(p *graphql.ResolveParams) ([]interface{}, error) {
+		g := p.Context.(*graphqlRequest)
+
+		var userIds []string
+		seen := map[string]bool{}
+		for _, source := range p.Sources {
+			if userId := getUserId(source); len(userId) > 0 && !seen[userId] {
+				seen[userId] = true
+				userIds = append(userIds, userId)
+			}
+		}
+
+		values := make([]interface{}, len(p.Sources))
+		if len(userIds) == 0 {
+			return values, nil
+		}
+
+		users, err := g.getUsers(userIds)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, source := range p.Sources {
+			values[i] = users[getUserId(source)]
+		}
+
+		return values, nil
+	}
+}
+
+// resolveOnce resolves a field holding an id by loading each distinct id once.
+func resolveOnce(getId func(source interface{}) string, load func(g *graphqlRequest, id string) (interface{}, error)) graphql.ResolveFunc {
+	return func(p *graphql.ResolveParams) ([]interface{}, error) {
+		g := p.Context.(*graphqlRequest)
+
+		loaded := map[string]interface{}{}
+
+		values := make([]interface{}, len(p.Sources))
+		for i, source := range p.Sources {
+			id := getId(source)
+			if len(id) == 0 {
+				continue
+			}
+
+			if value, ok := loaded[id]; ok {
+				values[i] = value
+				continue
+			}
+
+			if value, err := load(g, id); err != nil {
+				values[i] = err
+			} else {
+				values[i] = value
+			}
+			loaded[id] = values[i]
+		}
+
+		return values, nil
+	}
+}
+
+func property(get func(source interface{}) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+			return get(source), nil
+		}),
+	}
+}
+
+func loadTeam(g *graphqlRequest, teamId string) (interface{}, error) {
+	team, err := app.GetTeam(teamId)
+	if err != nil {
+		return nil, g.error(err)
+	}
+
+	if !g.canViewTeam(team) {
+		return nil, g.permissionError(model.PERMISSION_VIEW_TEAM)
+	}
+
+	return team, nil
+}
+
+func loadChannel(g *graphqlRequest, channelId string) (interface{}, error) {
+	channel, err := app.GetChannel(channelId)
+	if err != nil {
+		return nil, g.error(err)
+	}
+
+	if !g.canViewChannel(channel) {
+		return nil, g.permissionError(model.PERMISSION_READ_CHANNEL)
+	}
+
+	return channel, nil
+}
+
+func pageArguments() map[string]*graphql.ArgumentDefinition {
+	return map[string]*graphql.ArgumentDefinition{
+		"page":     {Type: graphql.INT, DefaultValue: PAGE_DEFAULT},
+		"per_page": {Type: graphql.INT, DefaultValue: PER_PAGE_DEFAULT},
+	}
+}
+
+func getPageArguments(arguments map[string]interface{}) (int, int) {
+	page := arguments["page"].(int)
+	if page < 0 {
+		page = PAGE_DEFAULT
+	}
+
+	perPage := arguments["per_page"].(int)
+	if perPage < 0 {
+		perPage = PER_PAGE_DEFAULT
+	} else if perPage > PER_PAGE_MAXIMUM {
+		perPage = PER_PAGE_MAXIMUM
+	}
+
+	return page, perPage
+}
+
+// newGraphQLSchema describes the users, teams, channels and posts that can be read through the
+// GraphQL endpoint. Fields use the same names as the JSON returned by the REST API and are only
+// resolved when the user has the same permissions that the REST API asks for.
+func newGraphQLSchema() *graphql.Schema {
+	userType := &graphql.Object{Name: "User"}
+	teamType := &graphql.Object{Name: "Team"}
+	channelType := &graphql.Object{Name: "Channel"}
+	postType := &graphql.Object{Name: "Post"}
+
+	userType.Fields = map[string]*graphql.Field{
+		"id":                  property(func(s interface{}) interface{} { return s.(*model.User).Id }),
+		"create_at":           property(func(s interface{}) interface{} { return s.(*model.User).CreateAt }),
+		"update_at":           property(func(s interface{}) interface{} { return s.(*model.User).UpdateAt }),
+		"delete_at":           property(func(s interface{}) interface{} { return s.(*model.User).DeleteAt }),
+		"username":            property(func(s interface{}) interface{} { return s.(*model.User).Username }),
+		"auth_service":        property(func(s interface{}) interface{} { return s.(*model.User).AuthService }),
+		"email":               property(func(s interface{}) interface{} { return s.(*model.User).Email }),
+		"nickname":            property(func(s interface{}) interface{} { return s.(*model.User).Nickname }),
+		"first_name":          property(func(s interface{}) interface{} { return s.(*model.User).FirstName }),
+		"last_name":           property(func(s interface{}) interface{} { return s.(*model.User).LastName }),
+		"position":            property(func(s interface{}) interface{} { return s.(*model.User).Position }),
+		"roles":               property(func(s interface{}) interface{} { return s.(*model.User).Roles }),
+		"locale":              property(func(s interface{}) interface{} { return s.(*model.User).Locale }),
+		"last_picture_update": property(func(s interface{}) interface{} { return s.(*model.User).LastPictureUpdate }),
+	}
+
+	teamType.Fields = map[string]*graphql.Field{
+		"id":                property(func(s interface{}) interface{} { return s.(*model.Team).Id }),
+		"create_at":         property(func(s interface{}) interface{} { return s.(*model.Team).CreateAt }),
+		"update_at":         property(func(s interface{}) interface{} { return s.(*model.Team).UpdateAt }),
+		"delete_at":         property(func(s interface{}) interface{} { return s.(*model.Team).DeleteAt }),
+		"display_name":      property(func(s interface{}) interface{} { return s.(*model.Team).DisplayName }),
+		"name":              property(func(s interface{}) interface{} { return s.(*model.Team).Name }),
+		"description":       property(func(s interface{}) interface{} { return s.(*model.Team).Description }),
+		"type":              property(func(s interface{}) interface{} { return s.(*model.Team).Type }),
+		"allow_open_invite": property(func(s interface{}) interface{} { return s.(*model.Team).AllowOpenInvite }),
+		"channels": {
+			Type: channelType,
+			List: true,
+			Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				g := request.(*graphqlRequest)
+
+				// Only the channels of the current user are listed
+				channels, err := app.GetChannelsForUser(source.(*model.Team).Id, g.c.Session.UserId)
+				if err != nil {
+					if err.Id == "store.sql_channel.get_channels.not_found.app_error" {
+						return []interface{}{}, nil
+					}
+
+					return nil, g.error(err)
+				}
+				channels = app.FilterChannelsByOAuthScope(g.c.Session, channels)
+
+				list := make([]interface{}, len(*channels))
+				for i, channel := range *channels {
+					list[i] = channel
+				}
+
+				return list, nil
+			}),
+		},
+	}
+
+	channelType.Fields = map[string]*graphql.Field{
+		"id":              property(func(s interface{}) interface{} { return s.(*model.Channel).Id }),
+		"create_at":       property(func(s interface{}) interface{} { return s.(*model.Channel).CreateAt }),
+		"update_at":       property(func(s interface{}) interface{} { return s.(*model.Channel).UpdateAt }),
+		"delete_at":       property(func(s interface{}) interface{} { return s.(*model.Channel).DeleteAt }),
+		"team_id":         property(func(s interface{}) interface{} { return s.(*model.Channel).TeamId }),
+		"type":            property(func(s interface{}) interface{} { return s.(*model.Channel).Type }),
+		"display_name":    property(func(s interface{}) interface{} { return s.(*model.Channel).DisplayName }),
+		"name":            property(func(s interface{}) interface{} { return s.(*model.Channel).Name }),
+		"header":          property(func(s interface{}) interface{} { return s.(*model.Channel).Header }),
+		"purpose":         property(func(s interface{}) interface{} { return s.(*model.Channel).Purpose }),
+		"last_post_at":    property(func(s interface{}) interface{} { return s.(*model.Channel).LastPostAt }),
+		"total_msg_count": property(func(s interface{}) interface{} { return s.(*model.Channel).TotalMsgCount }),
+		"creator_id":      property(func(s interface{}) interface{} { return s.(*model.Channel).CreatorId }),
+		"team": {
+			Type:    teamType,
+			Resolve: resolveOnce(func(s interface{}) string { return s.(*model.Channel).TeamId }, loadTeam),
+		},
+		"creator": {
+			Type:    userType,
+			Resolve: resolveUsers(func(s interface{}) string { return s.(*model.Channel).CreatorId }),
+		},
+		"posts": {
+			Type:      postType,
+			List:      true,
+			Arguments: pageArguments(),
+			Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				g := request.(*graphqlRequest)
+				channel := source.(*model.Channel)
+
+				if !app.SessionHasPermissionToChannel(g.c.Session, channel.Id, model.PERMISSION_READ_CHANNEL) {
+					return nil, g.permissionError(model.PERMISSION_READ_CHANNEL)
+				}
+
+				page, perPage := getPageArguments(arguments)
+
+				posts, err := app.GetPostsPageContext(g.r.Context(), channel.Id, page, perPage)
+				if err != nil {
+					return nil, g.error(err)
+				}
+
+				list := make([]interface{}, 0, len(posts.Order))
+				for _, postId := range posts.Order {
+					if post, ok := posts.Posts[postId]; ok {
+						list = append(list, post)
+					}
+				}
+
+				return list, nil
+			}),
+		},
+	}
+
+	postType.Fields = map[string]*graphql.Field{
+		"id":         property(func(s interface{}) interface{} { return s.(*model.Post).Id }),
+		"create_at":  property(func(s interface{}) interface{} { return s.(*model.Post).CreateAt }),
+		"update_at":  property(func(s interface{}) interface{} { return s.(*model.Post).UpdateAt }),
+		"edit_at":    property(func(s interface{}) interface{} { return s.(*model.Post).EditAt }),
+		"delete_at":  property(func(s interface{}) interface{} { return s.(*model.Post).DeleteAt }),
+		"is_pinned":  property(func(s interface{}) interface{} { return s.(*model.Post).IsPinned }),
+		"user_id":    property(func(s interface{}) interface{} { return s.(*model.Post).UserId }),
+		"channel_id": property(func(s interface{}) interface{} { return s.(*model.Post).ChannelId }),
+		"root_id":    property(func(s interface{}) interface{} { return s.(*model.Post).RootId }),
+		"parent_id":  property(func(s interface{}) interface{} { return s.(*model.Post).ParentId }),
+		"message":    property(func(s interface{}) interface{} { return s.(*model.Post).Message }),
+		"type":       property(func(s interface{}) interface{} { return s.(*model.Post).Type }),
+		"hashtags":   property(func(s interface{}) interface{} { return s.(*model.Post).Hashtags }),
+		"file_ids":   property(func(s interface{}) interface{} { return s.(*model.Post).FileIds }),
+		"user": {
+			Type:    userType,
+			Resolve: resolveUsers(func(s interface{}) string { return s.(*model.Post).UserId }),
+		},
+		"channel": {
+			Type:    channelType,
+			Resolve: resolveOnce(func(s interface{}) string { return s.(*model.Post).ChannelId }, loadChannel),
+		},
+	}
+
+	queryType := &graphql.Object{
+		Name: "Query",
+		Fields: map[string]*graphql.Field{
+			"me": {
+				Type: userType,
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+
+					if user, err := app.GetUser(g.c.Session.UserId); err != nil {
+						return nil, g.error(err)
+					} else {
+						return user, nil
+					}
+				}),
+			},
+			"user": {
+				Type: userType,
+				Arguments: map[string]*graphql.ArgumentDefinition{
+					"id":       {Type: graphql.ID},
+					"username": {Type: graphql.STRING},
+				},
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+
+					// No permission check required
+
+					var user *model.User
+					var err *model.AppError
+					if userId, ok := arguments["id"].(string); ok {
+						user, err = app.GetUser(userId)
+					} else if username, ok := arguments["username"].(string); ok {
+						user, err = app.GetUserByUsername(username)
+					} else {
+						return nil, g.invalidParamError("id")
+					}
+
+					if err != nil {
+						return nil, g.error(err)
+					}
+
+					app.SanitizeProfile(user, g.c.IsSystemAdmin())
+					return user, nil
+				}),
+			},
+			"users": {
+				Type: userType,
+				List: true,
+				Arguments: map[string]*graphql.ArgumentDefinition{
+					"ids": {Type: graphql.ID, List: true, Required: true},
+				},
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+
+					var userIds []string
+					for _, userId := range arguments["ids"].([]interface{}) {
+						userIds = append(userIds, userId.(string))
+					}
+
+					if len(userIds) == 0 {
+						return []interface{}{}, nil
+					}
+
+					users, err := g.getUsers(userIds)
+					if err != nil {
+						return nil, err
+					}
+
+					list := make([]interface{}, 0, len(userIds))
+					for _, userId := range userIds {
+						if user, ok := users[userId]; ok {
+							list = append(list, user)
+						}
+					}
+
+					return list, nil
+				}),
+			},
+			"team": {
+				Type: teamType,
+				Arguments: map[string]*graphql.ArgumentDefinition{
+					"id":   {Type: graphql.ID},
+					"name": {Type: graphql.STRING},
+				},
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+
+					if teamId, ok := arguments["id"].(string); ok {
+						return loadTeam(g, teamId)
+					}
+
+					name, ok := arguments["name"].(string)
+					if !ok {
+						return nil, g.invalidParamError("id")
+					}
+
+					team, err := app.GetTeamByName(name)
+					if err != nil {
+						return nil, g.error(err)
+					}
+
+					if !g.canViewTeam(team) {
+						return nil, g.permissionError(model.PERMISSION_VIEW_TEAM)
+					}
+
+					return team, nil
+				}),
+			},
+			"teams": {
+				Type: teamType,
+				List: true,
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+
+					// Only the teams of the current user are listed
+					teams, err := app.GetTeamsForUser(g.c.Session.UserId)
+					if err != nil {
+						return nil, g.error(err)
+					}
+
+					list := make([]interface{}, len(teams))
+					for i, team := range teams {
+						list[i] = team
+					}
+
+					return list, nil
+				}),
+			},
+			"channel": {
+				Type: channelType,
+				Arguments: map[string]*graphql.ArgumentDefinition{
+					"id": {Type: graphql.ID, Required: true},
+				},
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					return loadChannel(request.(*graphqlRequest), arguments["id"].(string))
+				}),
+			},
+			"post": {
+				Type: postType,
+				Arguments: map[string]*graphql.ArgumentDefinition{
+					"id": {Type: graphql.ID, Required: true},
+				},
+				Resolve: graphql.ResolveEach(func(request interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					g := request.(*graphqlRequest)
+					postId := arguments["id"].(string)
+
+					if !app.SessionHasPermissionToChannelByPost(g.c.Session, postId, model.PERMISSION_READ_CHANNEL) {
+						return nil, g.permissionError(model.PERMISSION_READ_CHANNEL)
+					}
+
+					if post, err := app.GetSinglePostContext(g.r.Context(), postId); err != nil {
+						return nil, g.error(err)
+					} else {
+						return post, nil
+					}
+				}),
+			},
+		},
+	}
+
+	return &graphql.Schema{Query: queryType}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/utils"
+)
+
+func TestExecuteGraphQL(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableGraphQL := *utils.Cfg.ServiceSettings.EnableGraphQL
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableGraphQL = enableGraphQL
+	}()
+
+	*utils.Cfg.ServiceSettings.EnableGraphQL = false
+
+	_, resp := Client.ExecuteGraphQL(`{ me { id } }`, nil)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableGraphQL = true
+
+	query := `query Channel($id: ID!) {
+		me { id username }
+		channel(id: $id) {
+			name
+			team { id }
+			posts(per_page: 5) {
+				id
+				user { id username }
+			}
+		}
+	}`
+
+	result, resp := Client.ExecuteGraphQL(query, map[string]interface{}{"id": th.BasicChannel.Id})
+	CheckNoError(t, resp)
+
+	if result["errors"] != nil {
+		t.Fatal("should not have returned errors", result["errors"])
+	}
+
+	data := result["data"].(map[string]interface{})
+
+	if me := data["me"].(map[string]interface{}); me["id"] != th.BasicUser.Id || me["username"] != th.BasicUser.Username {
+		t.Fatal("should have returned the current user", me)
+	}
+
+	channel := data["channel"].(map[string]interface{})
+	if channel["name"] != th.BasicChannel.Name {
+		t.Fatal("should have returned the channel", channel)
+	}
+
+	if team := channel["team"].(map[string]interface{}); team["id"] != th.BasicTeam.Id {
+		t.Fatal("should have returned the team of the channel", team)
+	}
+
+	posts := channel["posts"].([]interface{})
+	if len(posts) == 0 {
+		t.Fatal("should have returned the posts of the channel")
+	}
+
+	found := false
+	for _, p := range posts {
+		post := p.(map[string]interface{})
+		if post["id"] == th.BasicPost.Id {
+			found = true
+
+			if user := post["user"].(map[string]interface{}); user["id"] != th.BasicUser.Id {
+				t.Fatal("should have returned the author of the post", user)
+			}
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned the basic post")
+	}
+
+	// Fields that the user doesn't have permission to read are null with an error
+	privateChannel := th.CreatePrivateChannel()
+	th.LoginBasic2()
+
+	result, resp = Client.ExecuteGraphQL(`query Q($id: ID!) { me { id } channel(id: $id) { id } }`, map[string]interface{}{"id": privateChannel.Id})
+	CheckNoError(t, resp)
+
+	data = result["data"].(map[string]interface{})
+	if data["channel"] != nil {
+		t.Fatal("should not have returned a channel the user can't read")
+	} else if me := data["me"].(map[string]interface{}); me["id"] != th.BasicUser2.Id {
+		t.Fatal("should have returned the rest of the data")
+	}
+
+	if errors, ok := result["errors"].([]interface{}); !ok || len(errors) != 1 {
+		t.Fatal("should have returned a permission error", result["errors"])
+	}
+
+	// Invalid queries don't return any data
+	result, resp = Client.ExecuteGraphQL(`{ channel { id } }`, nil)
+	CheckNoError(t, resp)
+
+	if result["data"] != nil || result["errors"] == nil {
+		t.Fatal("should have failed to run the query", result)
+	}
+
+	Client.Logout()
+	_, resp = Client.ExecuteGraphQL(`{ me { id } }`, nil)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestExecuteGraphQLSanitization(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableGraphQL := *utils.Cfg.ServiceSettings.EnableGraphQL
+	showEmailAddress := utils.Cfg.PrivacySettings.ShowEmailAddress
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableGraphQL = enableGraphQL
+		utils.Cfg.PrivacySettings.ShowEmailAddress = showEmailAddress
+	}()
+	*utils.Cfg.ServiceSettings.EnableGraphQL = true
+	utils.Cfg.PrivacySettings.ShowEmailAddress = false
+
+	query := `query Users($ids: [ID]!) { users(ids: $ids) { id email } }`
+	variables := map[string]interface{}{"ids": []string{th.BasicUser2.Id}}
+
+	result, resp := Client.ExecuteGraphQL(query, variables)
+	CheckNoError(t, resp)
+
+	users := result["data"].(map[string]interface{})["users"].([]interface{})
+	if len(users) != 1 || users[0].(map[string]interface{})["email"] != "" {
+		t.Fatal("should have sanitized the email address", users)
+	}
+
+	result, resp = th.SystemAdminClient.ExecuteGraphQL(query, variables)
+	CheckNoError(t, resp)
+
+	users = result["data"].(map[string]interface{})["users"].([]interface{})
+	if len(users) != 1 || users[0].(map[string]interface{})["email"] != th.BasicUser2.Email {
+		t.Fatal("should have returned the email address to a system admin", users)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitInactivityReport() {
+	l4g.Debug(utils.T("api.inactivity_report.init.debug"))
+
+	BaseRoutes.Reports.Handle("/inactive_users", ApiSessionRequired(getInactiveUsers)).Methods("GET")
+	BaseRoutes.Reports.Handle("/inactive_users/download", ApiSessionRequired(downloadInactiveUsers)).Methods("GET")
+	BaseRoutes.Reports.Handle("/inactive_channels", ApiSessionRequired(getInactiveChannels)).Methods("GET")
+	BaseRoutes.Reports.Handle("/inactive_channels/download", ApiSessionRequired(downloadInactiveChannels)).Methods("GET")
+}
+
+// requireInactivityDays returns the number of days of the days query parameter, which must be set.
+func requireInactivityDays(c *Context, r *http.Request) int {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 || days > model.INACTIVITY_REPORT_MAX_DAYS {
+		c.SetInvalidParam("days")
+		return 0
+	}
+
+	return days
+}
+
+func getInactiveUsers(c *Context, w http.ResponseWriter, r *http.Request) {
+	days := requireInactivityDays(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	users, err := app.GetInactiveUsers(days, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(users), func() (int64, *model.AppError) {
+		return app.GetInactiveUsersCount(days)
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.InactiveUserListToJson(users)))
+}
+
+func downloadInactiveUsers(c *Context, w http.ResponseWriter, r *http.Request) {
+	days := requireInactivityDays(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	var report bytes.Buffer
+	if err := app.WriteInactiveUsersCsv(&report, days); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("days=" + strconv.Itoa(days))
+	writeInactivityReportCsv(w, "inactive_users_"+strconv.Itoa(days)+"_days.csv", report.Bytes())
+}
+
+func getInactiveChannels(c *Context, w http.ResponseWriter, r *http.Request) {
+	days := requireInactivityDays(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channels, err := app.GetInactiveChannels(days, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(channels), func() (int64, *model.AppError) {
+		return app.GetInactiveChannelsCount(days)
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.InactiveChannelListToJson(channels)))
+}
+
+func downloadInactiveChannels(c *Context, w http.ResponseWriter, r *http.Request) {
+	days := requireInactivityDays(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	var report bytes.Buffer
+	if err := app.WriteInactiveChannelsCsv(&report, days); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("days=" + strconv.Itoa(days))
+	writeInactivityReportCsv(w, "inactive_channels_"+strconv.Itoa(days)+"_days.csv", report.Bytes())
+}
+
+func writeInactivityReportCsv(w http.ResponseWriter, filename string, report []byte) {
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Content-Length", strconv.Itoa(len(report)))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment;filename=\""+filename+"\"")
+
+	w.Write(report)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetInactiveUsersReport(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetInactiveUsersReport(30, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetInactiveUsersReport(0, 0, 60)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetInactiveUsersReport(model.INACTIVITY_REPORT_MAX_DAYS+1, 0, 60)
+	CheckBadRequestStatus(t, resp)
+
+	users, resp := th.SystemAdminClient.GetInactiveUsersReport(1, 0, 200)
+	CheckNoError(t, resp)
+
+	for _, user := range users {
+		if user.Id == th.BasicUser.Id {
+			t.Fatal("should not have returned a user created today")
+		}
+	}
+
+	if r, err := th.SystemAdminClient.DoApiGet("/reports/inactive_users?days=1&page=0&per_page=1&include_total_count=true", ""); err != nil {
+		t.Fatal(err)
+	} else if pagination := model.PaginationFromHeaders(r.Header); pagination == nil {
+		t.Fatal("should have returned the pagination")
+	}
+
+	data, resp := th.SystemAdminClient.DownloadInactiveUsersReport(1)
+	CheckNoError(t, resp)
+
+	if !strings.HasPrefix(string(data), strings.Join(model.InactiveUserCsvHeader(), ",")+"\n") {
+		t.Fatal("should have started with the header", string(data))
+	} else if strings.Contains(string(data), th.BasicUser.Id) {
+		t.Fatal("should not have exported a user created today")
+	}
+
+	_, resp = Client.DownloadInactiveUsersReport(1)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetInactiveUsersReport(30, 0, 60)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetInactiveChannelsReport(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetInactiveChannelsReport(30, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetInactiveChannelsReport(-1, 0, 60)
+	CheckBadRequestStatus(t, resp)
+
+	channels, resp := th.SystemAdminClient.GetInactiveChannelsReport(1, 0, 200)
+	CheckNoError(t, resp)
+
+	for _, channel := range channels {
+		if channel.Id == th.BasicChannel.Id {
+			t.Fatal("should not have returned a channel created today")
+		}
+	}
+
+	data, resp := th.SystemAdminClient.DownloadInactiveChannelsReport(1)
+	CheckNoError(t, resp)
+
+	if !strings.HasPrefix(string(data), strings.Join(model.InactiveChannelCsvHeader(), ",")+"\n") {
+		t.Fatal("should have started with the header", string(data))
+	}
+
+	_, resp = Client.DownloadInactiveChannelsReport(1)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetInactiveChannelsReport(30, 0, 60)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitIncident() {
+	l4g.Debug(utils.T("api.incident.init.debug"))
+
+	BaseRoutes.Incidents.Handle("", ApiSessionRequired(createIncident)).Methods("POST")
+	BaseRoutes.Incidents.Handle("", ApiSessionRequired(getIncidents)).Methods("GET")
+	BaseRoutes.Incident.Handle("", ApiSessionRequired(getIncident)).Methods("GET")
+	BaseRoutes.Incident.Handle("/patch", ApiSessionRequired(patchIncident)).Methods("PUT")
+	BaseRoutes.Incident.Handle("/resolve", ApiSessionRequired(resolveIncident)).Methods("POST")
+
+	BaseRoutes.Public.Handle("/incidents", ApiHandler(getIncidentFeed)).Methods("GET")
+}
+
+func createIncident(c *Context, w http.ResponseWriter, r *http.Request) {
+	incident := model.IncidentFromJson(r.Body)
+	if incident == nil {
+		c.SetInvalidParam("incident")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	incident.CreatorId = c.Session.UserId
+
+	if rincident, err := app.CreateIncident(incident); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rincident.ToJson()))
+	}
+}
+
+func getIncidents(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if incidents, err := app.GetIncidentsPage(c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.IncidentListToJson(incidents)))
+	}
+}
+
+func getIncident(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireIncidentId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if incident, err := app.GetIncident(c.Params.IncidentId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(incident.ToJson()))
+	}
+}
+
+func patchIncident(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireIncidentId()
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.IncidentPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("incident")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if incident, err := app.PatchIncident(c.Params.IncidentId, patch, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(incident.ToJson()))
+	}
+}
+
+func resolveIncident(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireIncidentId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if incident, err := app.ResolveIncident(c.Params.IncidentId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(incident.ToJson()))
+	}
+}
+
+func getIncidentFeed(c *Context, w http.ResponseWriter, r *http.Request) {
+	if feed, err := app.GetIncidentFeed(); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Write([]byte(feed.ToJson()))
+	}
+}
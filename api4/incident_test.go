@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestIncidents(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enable := *utils.Cfg.IncidentSettings.Enable
+	enablePublicFeed := *utils.Cfg.IncidentSettings.EnablePublicFeed
+	announcementChannelId := *utils.Cfg.IncidentSettings.AnnouncementChannelId
+	defer func() {
+		*utils.Cfg.IncidentSettings.Enable = enable
+		*utils.Cfg.IncidentSettings.EnablePublicFeed = enablePublicFeed
+		*utils.Cfg.IncidentSettings.AnnouncementChannelId = announcementChannelId
+	}()
+	*utils.Cfg.IncidentSettings.Enable = true
+	*utils.Cfg.IncidentSettings.EnablePublicFeed = true
+	*utils.Cfg.IncidentSettings.AnnouncementChannelId = th.BasicChannel.Id
+
+	incident := &model.Incident{Title: "Search is slow", Severity: model.INCIDENT_SEVERITY_MAJOR}
+
+	_, resp := Client.CreateIncident(incident)
+	CheckForbiddenStatus(t, resp)
+
+	rincident, resp := th.SystemAdminClient.CreateIncident(incident)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rincident.CreatorId != th.SystemAdminUser.Id || rincident.Status != model.INCIDENT_STATUS_INVESTIGATING {
+		t.Fatal("creator and status should have been set")
+	}
+
+	if len(rincident.PostId) == 0 {
+		t.Fatal("should have been announced")
+	}
+
+	_, resp = Client.GetIncident(rincident.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetIncident(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	incidents, resp := th.SystemAdminClient.GetIncidents(0, 60)
+	CheckNoError(t, resp)
+
+	if len(incidents) == 0 || incidents[0].Id != rincident.Id {
+		t.Fatal("should have returned the incident")
+	}
+
+	status := model.INCIDENT_STATUS_IDENTIFIED
+	patched, resp := th.SystemAdminClient.PatchIncident(rincident.Id, &model.IncidentPatch{Status: &status})
+	CheckNoError(t, resp)
+
+	if patched.Status != status || patched.Title != rincident.Title {
+		t.Fatal("should have been patched")
+	}
+
+	status = "junk"
+	_, resp = th.SystemAdminClient.PatchIncident(rincident.Id, &model.IncidentPatch{Status: &status})
+	CheckBadRequestStatus(t, resp)
+
+	posts, resp := Client.GetPostThread(rincident.PostId, "")
+	CheckNoError(t, resp)
+
+	if len(posts.Order) != 2 {
+		t.Fatal("the update should have been announced in the thread")
+	}
+
+	feed, resp := Client.GetIncidentFeed()
+	CheckNoError(t, resp)
+
+	if feed.Status != model.INCIDENT_FEED_STATUS_DEGRADED || len(feed.Incidents) == 0 || len(feed.Incidents[0].CreatorId) != 0 {
+		t.Fatal("the feed should report the sanitized open incident")
+	}
+
+	_, resp = Client.ResolveIncident(rincident.Id)
+	CheckForbiddenStatus(t, resp)
+
+	resolved, resp := th.SystemAdminClient.ResolveIncident(rincident.Id)
+	CheckNoError(t, resp)
+
+	if !resolved.IsResolved() || resolved.ResolvedAt == 0 {
+		t.Fatal("should have been resolved")
+	}
+
+	*utils.Cfg.IncidentSettings.EnablePublicFeed = false
+	_, resp = Client.GetIncidentFeed()
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.IncidentSettings.Enable = false
+	_, resp = th.SystemAdminClient.CreateIncident(incident)
+	CheckNotImplementedStatus(t, resp)
+}
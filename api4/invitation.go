@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitInvitation() {
+	l4g.Debug(utils.T("api.invitation.init.debug"))
+
+	BaseRoutes.Invitations.Handle("", ApiSessionRequired(getPendingInvitations)).Methods("GET")
+	BaseRoutes.Invitation.Handle("/resend", ApiSessionRequired(resendInvitation)).Methods("POST")
+	BaseRoutes.Invitation.Handle("", ApiSessionRequired(revokeInvitation)).Methods("DELETE")
+}
+
+func getPendingInvitations(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if invitations, err := app.GetPendingInvitationsForTeam(c.Params.TeamId, c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.InvitationListToJson(invitations)))
+	}
+}
+
+// getInvitationForTeam returns the invitation in the URL after checking that it belongs to the team in the URL.
+func getInvitationForTeam(c *Context) *model.Invitation {
+	c.RequireTeamId().RequireInvitationId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return nil
+	}
+
+	invitation, err := app.GetInvitation(c.Params.InvitationId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if invitation.TeamId != c.Params.TeamId {
+		c.SetInvalidUrlParam("invitation_id")
+		return nil
+	}
+
+	return invitation
+}
+
+func resendInvitation(c *Context, w http.ResponseWriter, r *http.Request) {
+	invitation := getInvitationForTeam(c)
+	if c.Err != nil {
+		return
+	}
+
+	if rinvitation, err := app.ResendInvitation(invitation); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("email=" + rinvitation.Email)
+		w.Write([]byte(rinvitation.ToJson()))
+	}
+}
+
+func revokeInvitation(c *Context, w http.ResponseWriter, r *http.Request) {
+	invitation := getInvitationForTeam(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.RevokeInvitation(invitation.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("email=" + invitation.Email)
+	ReturnStatusOK(w)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+func invitationLinkData(invitation *model.Invitation) (string, string) {
+	props := map[string]string{
+		"email": invitation.Email,
+		"id":    invitation.TeamId,
+		"token": invitation.Token,
+		"time":  fmt.Sprintf("%v", invitation.UpdateAt),
+	}
+
+	data := model.MapToJson(props)
+	return data, model.HashPassword(fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt))
+}
+
+func TestGetPendingInvitations(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	email := GenerateTestEmail()
+	_, resp := th.SystemAdminClient.InviteUsersToTeam(th.BasicTeam.Id, []string{email})
+	CheckNoError(t, resp)
+
+	invitations, resp := th.SystemAdminClient.GetPendingInvitations(th.BasicTeam.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if len(invitations) != 1 || invitations[0].Email != email || invitations[0].InviterId != th.SystemAdminUser.Id {
+		t.Fatal("should have returned the invitation", invitations)
+	}
+
+	if len(invitations[0].Token) != 0 {
+		t.Fatal("should not have returned the token of the invitation")
+	}
+
+	invitations, resp = th.SystemAdminClient.GetPendingInvitations(th.BasicTeam.Id, 1, 60)
+	CheckNoError(t, resp)
+
+	if len(invitations) != 0 {
+		t.Fatal("should not have returned anything on the second page")
+	}
+
+	_, resp = th.Client.GetPendingInvitations(th.BasicTeam.Id, 0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPendingInvitations("junk", 0, 60)
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestResendInvitation(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	_, resp := th.SystemAdminClient.InviteUsersToTeam(th.BasicTeam.Id, []string{GenerateTestEmail()})
+	CheckNoError(t, resp)
+
+	invitations, resp := th.SystemAdminClient.GetPendingInvitations(th.BasicTeam.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	original := store.Must(app.Srv.Store.Invitation().Get(invitations[0].Id)).(*model.Invitation)
+
+	_, resp = th.Client.ResendInvitation(th.BasicTeam.Id, original.Id)
+	CheckForbiddenStatus(t, resp)
+
+	invitation, resp := th.SystemAdminClient.ResendInvitation(th.BasicTeam.Id, original.Id)
+	CheckNoError(t, resp)
+
+	if invitation.Id != original.Id || invitation.ExpireAt < original.ExpireAt {
+		t.Fatal("should have renewed the invitation")
+	}
+
+	resent := store.Must(app.Srv.Store.Invitation().Get(original.Id)).(*model.Invitation)
+	if resent.Token == original.Token {
+		t.Fatal("should have given the invitation a new token")
+	}
+
+	// The link that was sent first doesn't work anymore
+	user := th.CreateUser()
+	th.Client.Login(user.Email, user.Password)
+
+	data, hash := invitationLinkData(original)
+	_, resp = th.Client.AddTeamMember(th.BasicTeam.Id, "", hash, data, "")
+	CheckNotFoundStatus(t, resp)
+
+	data, hash = invitationLinkData(resent)
+	_, resp = th.Client.AddTeamMember(th.BasicTeam.Id, "", hash, data, "")
+	CheckNoError(t, resp)
+
+	if result := <-app.Srv.Store.Invitation().Get(original.Id); result.Err == nil {
+		t.Fatal("should have deleted the invitation once it was accepted")
+	}
+
+	_, resp = th.SystemAdminClient.ResendInvitation(th.BasicTeam.Id, original.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	_, resp := th.SystemAdminClient.InviteUsersToTeam(th.BasicTeam.Id, []string{GenerateTestEmail()})
+	CheckNoError(t, resp)
+
+	invitations, resp := th.SystemAdminClient.GetPendingInvitations(th.BasicTeam.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	invitation := store.Must(app.Srv.Store.Invitation().Get(invitations[0].Id)).(*model.Invitation)
+
+	_, resp = th.Client.RevokeInvitation(th.BasicTeam.Id, invitation.Id)
+	CheckForbiddenStatus(t, resp)
+
+	// The invitation has to belong to the team in the URL
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	_, resp = th.SystemAdminClient.RevokeInvitation(otherTeam.Id, invitation.Id)
+	CheckBadRequestStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.RevokeInvitation(th.BasicTeam.Id, invitation.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned ok")
+	}
+
+	invitations, resp = th.SystemAdminClient.GetPendingInvitations(th.BasicTeam.Id, 0, 60)
+	CheckNoError(t, resp)
+
+	if len(invitations) != 0 {
+		t.Fatal("should not have returned the revoked invitation")
+	}
+
+	user := th.CreateUser()
+	th.Client.Login(user.Email, user.Password)
+
+	data, hash := invitationLinkData(invitation)
+	_, resp = th.Client.AddTeamMember(th.BasicTeam.Id, "", hash, data, "")
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RevokeInvitation(th.BasicTeam.Id, invitation.Id)
+	CheckNotFoundStatus(t, resp)
+}
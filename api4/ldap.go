@@ -5,6 +5,7 @@ package api4
 
 import (
 	"net/http"
+	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/app"
@@ -17,6 +18,11 @@ func InitLdap() {
 
 	BaseRoutes.LDAP.Handle("/sync", ApiSessionRequired(syncLdap)).Methods("POST")
 	BaseRoutes.LDAP.Handle("/test", ApiSessionRequired(testLdap)).Methods("POST")
+
+	BaseRoutes.LDAP.Handle("/groups", ApiSessionRequired(linkLdapGroup)).Methods("POST")
+	BaseRoutes.LDAP.Handle("/groups", ApiSessionRequired(getLdapGroups)).Methods("GET")
+	BaseRoutes.LDAP.Handle("/groups/sync", ApiSessionRequired(syncLdapGroups)).Methods("POST")
+	BaseRoutes.LDAP.Handle("/groups/{ldap_group_id:[A-Za-z0-9]+}", ApiSessionRequired(unlinkLdapGroup)).Methods("DELETE")
 }
 
 func syncLdap(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -43,3 +49,93 @@ func testLdap(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ReturnStatusOK(w)
 }
+
+func linkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	group := model.LdapGroupFromJson(r.Body)
+	if group == nil {
+		c.SetInvalidParam("ldap_group")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	group.Id = ""
+	group.CreatorId = c.Session.UserId
+
+	if rgroup, err := app.LinkLdapGroup(group); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("remote_id=" + rgroup.RemoteId + " channel_id=" + rgroup.ChannelId)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rgroup.ToJson()))
+	}
+}
+
+func getLdapGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channelId := r.URL.Query().Get("channel_id")
+	if len(channelId) > 0 && len(channelId) != 26 {
+		c.SetInvalidParam("channel_id")
+		return
+	}
+
+	if groups, err := app.GetLdapGroups(channelId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.LdapGroupListToJson(groups)))
+	}
+}
+
+func unlinkLdapGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLdapGroupId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	group, err := app.GetLdapGroup(c.Params.LdapGroupId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := app.UnlinkLdapGroup(group.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("remote_id=" + group.RemoteId + " channel_id=" + group.ChannelId)
+	ReturnStatusOK(w)
+}
+
+func syncLdapGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	if result, err := app.SyncLdapGroups(dryRun); err != nil {
+		c.Err = err
+		return
+	} else {
+		if !dryRun {
+			c.LogAudit("changes=" + strconv.Itoa(len(result.Changes)))
+		}
+		w.Write([]byte(result.ToJson()))
+	}
+}
@@ -5,6 +5,10 @@ package api4
 
 import (
 	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
 )
 
 func TestLdapTest(t *testing.T) {
@@ -28,3 +32,98 @@ func TestLdapSync(t *testing.T) {
 	_, resp = th.Client.SyncLdap()
 	CheckForbiddenStatus(t, resp)
 }
+
+func TestLinkLdapGroup(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	group := &model.LdapGroup{RemoteId: "cn=developers,ou=groups,dc=example,dc=com", ChannelId: th.BasicChannel.Id}
+
+	_, resp := th.Client.LinkLdapGroup(group)
+	CheckForbiddenStatus(t, resp)
+
+	// Group sync needs an LDAP server, which isn't available without an enterprise build
+	_, resp = th.SystemAdminClient.LinkLdapGroup(group)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestGetLdapGroups(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	group := &model.LdapGroup{RemoteId: "cn=developers,ou=groups,dc=example,dc=com", ChannelId: th.BasicChannel.Id, CreatorId: th.SystemAdminUser.Id}
+	store.Must(app.Srv.Store.LdapGroup().Save(group))
+	defer app.UnlinkLdapGroup(group.Id)
+
+	groups, resp := th.SystemAdminClient.GetLdapGroups(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+
+	if len(groups) != 1 || groups[0].Id != group.Id || groups[0].RemoteId != group.RemoteId {
+		t.Fatal("should have returned the group linked to the channel")
+	}
+
+	groups, resp = th.SystemAdminClient.GetLdapGroups(th.BasicChannel2.Id)
+	CheckNoError(t, resp)
+
+	if len(groups) != 0 {
+		t.Fatal("should not have returned groups linked to other channels")
+	}
+
+	groups, resp = th.SystemAdminClient.GetLdapGroups("")
+	CheckNoError(t, resp)
+
+	found := false
+	for _, g := range groups {
+		if g.Id == group.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned every linked group")
+	}
+
+	_, resp = th.SystemAdminClient.GetLdapGroups("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.Client.GetLdapGroups(th.BasicChannel.Id)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestUnlinkLdapGroup(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	group := &model.LdapGroup{RemoteId: "cn=developers,ou=groups,dc=example,dc=com", ChannelId: th.BasicChannel.Id, CreatorId: th.SystemAdminUser.Id}
+	store.Must(app.Srv.Store.LdapGroup().Save(group))
+
+	_, resp := th.Client.UnlinkLdapGroup(group.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.UnlinkLdapGroup(group.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned ok")
+	}
+
+	_, resp = th.SystemAdminClient.UnlinkLdapGroup(group.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UnlinkLdapGroup("junk")
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestSyncLdapGroups(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	_, resp := th.Client.SyncLdapGroups(true)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.SyncLdapGroups(true)
+	CheckNotImplementedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.SyncLdapGroups(false)
+	CheckNotImplementedStatus(t, resp)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitLegalHold() {
+	l4g.Debug(utils.T("api.legal_hold.init.debug"))
+
+	BaseRoutes.LegalHolds.Handle("", ApiSessionRequired(createLegalHold)).Methods("POST")
+	BaseRoutes.LegalHolds.Handle("", ApiSessionRequired(getLegalHolds)).Methods("GET")
+	BaseRoutes.LegalHold.Handle("", ApiSessionRequired(getLegalHold)).Methods("GET")
+	BaseRoutes.LegalHold.Handle("", ApiSessionRequired(deleteLegalHold)).Methods("DELETE")
+	BaseRoutes.Reports.Handle("/legal_hold_export", ApiSessionRequired(downloadLegalHoldExport)).Methods("GET")
+}
+
+func createLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	hold := model.LegalHoldFromJson(r.Body)
+	if hold == nil {
+		c.SetInvalidParam("legal_hold")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	hold.CreatorId = c.Session.UserId
+
+	rhold, err := app.CreateLegalHold(hold)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + rhold.Id + " target_type=" + rhold.TargetType + " target_id=" + rhold.TargetId)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rhold.ToJson()))
+}
+
+func getLegalHolds(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	holds, err := app.GetLegalHolds(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(holds), func() (int64, *model.AppError) {
+		return app.GetLegalHoldsCount()
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.LegalHoldListToJson(holds)))
+}
+
+func getLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLegalHoldId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	hold, err := app.GetLegalHold(c.Params.LegalHoldId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(hold.ToJson()))
+}
+
+func deleteLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLegalHoldId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteLegalHold(c.Params.LegalHoldId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + c.Params.LegalHoldId)
+	ReturnStatusOK(w)
+}
+
+func downloadLegalHoldExport(c *Context, w http.ResponseWriter, r *http.Request) {
+	startTime, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+	if err != nil || startTime < 0 {
+		c.SetInvalidParam("start")
+		return
+	}
+
+	endTime, err := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+	if err != nil || endTime <= startTime {
+		c.SetInvalidParam("end")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	var export bytes.Buffer
+	if err := app.WriteLegalHoldExportCsv(&export, startTime, endTime); err != nil {
+		c.Err = err
+		return
+	}
+
+	startString := strconv.FormatInt(startTime, 10)
+	endString := strconv.FormatInt(endTime, 10)
+
+	c.LogAudit("start=" + startString + " end=" + endString)
+	writeInactivityReportCsv(w, "legal_hold_"+startString+"_"+endString+".csv", export.Bytes())
+}
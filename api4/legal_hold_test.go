@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLegalHolds(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	hold := &model.LegalHold{TargetType: model.LEGAL_HOLD_TARGET_CHANNEL, TargetId: th.BasicChannel.Id, Reason: "litigation"}
+
+	_, resp := Client.CreateLegalHold(hold)
+	CheckForbiddenStatus(t, resp)
+
+	rhold, resp := th.SystemAdminClient.CreateLegalHold(hold)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rhold.TargetId != th.BasicChannel.Id || rhold.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("should have created the hold")
+	}
+
+	_, resp = th.SystemAdminClient.CreateLegalHold(hold)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateLegalHold(&model.LegalHold{TargetType: model.LEGAL_HOLD_TARGET_USER, TargetId: model.NewId()})
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateLegalHold(&model.LegalHold{TargetType: "team", TargetId: th.BasicTeam.Id})
+	CheckBadRequestStatus(t, resp)
+
+	holds, resp := th.SystemAdminClient.GetLegalHolds(0, 100)
+	CheckNoError(t, resp)
+
+	if len(holds) != 1 || holds[0].Id != rhold.Id {
+		t.Fatal("should have returned the hold")
+	}
+
+	_, resp = Client.GetLegalHolds(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	fetched, resp := th.SystemAdminClient.GetLegalHold(rhold.Id)
+	CheckNoError(t, resp)
+
+	if fetched.Reason != "litigation" {
+		t.Fatal("should have returned the hold")
+	}
+
+	_, resp = th.SystemAdminClient.GetLegalHold("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetLegalHold(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteLegalHold(rhold.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.DeleteLegalHold(rhold.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have released the hold")
+	}
+
+	_, resp = th.SystemAdminClient.DeleteLegalHold(rhold.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestLegalHoldDeletes(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+
+	hold, resp := th.SystemAdminClient.CreateLegalHold(&model.LegalHold{TargetType: model.LEGAL_HOLD_TARGET_USER, TargetId: th.BasicUser.Id})
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeletePost(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeletePost(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	start := post.CreateAt - 1
+	end := model.GetMillis()
+
+	data, resp := th.SystemAdminClient.DownloadLegalHoldExport(start, end)
+	CheckNoError(t, resp)
+
+	if !strings.Contains(string(data), post.Id) {
+		t.Fatal("the export should contain the held post")
+	}
+
+	_, resp = Client.DownloadLegalHoldExport(start, end)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DownloadLegalHoldExport(end, start)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteLegalHold(hold.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeletePost(post.Id)
+	CheckNoError(t, resp)
+}
@@ -17,23 +17,45 @@ const (
 )
 
 type ApiParams struct {
-	UserId         string
-	TeamId         string
-	ChannelId      string
-	PostId         string
-	FileId         string
-	CommandId      string
-	HookId         string
-	ReportId       string
-	EmojiId        string
-	Email          string
-	Username       string
-	TeamName       string
-	ChannelName    string
-	PreferenceName string
-	Category       string
-	Page           int
-	PerPage        int
+	UserId            string
+	TeamId            string
+	ChannelId         string
+	PostId            string
+	ActionId          string
+	FileId            string
+	CommandId         string
+	HookId            string
+	ReportId          string
+	EmojiId           string
+	EmojiName         string
+	IncidentId        string
+	FlagName          string
+	ExperimentName    string
+	TemplateId        string
+	JobId             string
+	ScheduledPostId   string
+	BotUserId         string
+	TokenId           string
+	CredentialId      string
+	DeviceId          string
+	LdapGroupId       string
+	InvitationId      string
+	ArchiveExportId   string
+	BulkEmailId       string
+	PolicyId          string
+	LegalHoldId       string
+	CategoryId        string
+	RemoteId          string
+	CacheName         string
+	Email             string
+	Username          string
+	TeamName          string
+	ChannelName       string
+	PreferenceName    string
+	Category          string
+	Page              int
+	PerPage           int
+	IncludeTotalCount bool
 }
 
 func ApiParamsFromRequest(r *http.Request) *ApiParams {
@@ -57,6 +79,10 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.PostId = val
 	}
 
+	if val, ok := props["action_id"]; ok {
+		params.ActionId = val
+	}
+
 	if val, ok := props["file_id"]; ok {
 		params.FileId = val
 	}
@@ -77,6 +103,86 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.EmojiId = val
 	}
 
+	if val, ok := props["emoji_name"]; ok {
+		params.EmojiName = val
+	}
+
+	if val, ok := props["incident_id"]; ok {
+		params.IncidentId = val
+	}
+
+	if val, ok := props["flag_name"]; ok {
+		params.FlagName = val
+	}
+
+	if val, ok := props["experiment_name"]; ok {
+		params.ExperimentName = val
+	}
+
+	if val, ok := props["template_id"]; ok {
+		params.TemplateId = val
+	}
+
+	if val, ok := props["job_id"]; ok {
+		params.JobId = val
+	}
+
+	if val, ok := props["scheduled_post_id"]; ok {
+		params.ScheduledPostId = val
+	}
+
+	if val, ok := props["bot_user_id"]; ok {
+		params.BotUserId = val
+	}
+
+	if val, ok := props["token_id"]; ok {
+		params.TokenId = val
+	}
+
+	if val, ok := props["credential_id"]; ok {
+		params.CredentialId = val
+	}
+
+	if val, ok := props["device_id"]; ok {
+		params.DeviceId = val
+	}
+
+	if val, ok := props["ldap_group_id"]; ok {
+		params.LdapGroupId = val
+	}
+
+	if val, ok := props["invitation_id"]; ok {
+		params.InvitationId = val
+	}
+
+	if val, ok := props["archive_export_id"]; ok {
+		params.ArchiveExportId = val
+	}
+
+	if val, ok := props["bulk_email_id"]; ok {
+		params.BulkEmailId = val
+	}
+
+	if val, ok := props["policy_id"]; ok {
+		params.PolicyId = val
+	}
+
+	if val, ok := props["legal_hold_id"]; ok {
+		params.LegalHoldId = val
+	}
+
+	if val, ok := props["category_id"]; ok {
+		params.CategoryId = val
+	}
+
+	if val, ok := props["remote_id"]; ok {
+		params.RemoteId = val
+	}
+
+	if val, ok := props["cache_name"]; ok {
+		params.CacheName = val
+	}
+
 	if val, ok := props["email"]; ok {
 		params.Email = val
 	}
@@ -115,5 +221,9 @@ func ApiParamsFromRequest(r *http.Request) *ApiParams {
 		params.PerPage = val
 	}
 
+	if val, err := strconv.ParseBool(r.URL.Query().Get("include_total_count")); err == nil {
+		params.IncludeTotalCount = val
+	}
+
 	return params
 }
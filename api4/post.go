@@ -6,6 +6,7 @@ package api4
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/app"
@@ -17,18 +18,23 @@ func InitPost() {
 	l4g.Debug(utils.T("api.post.init.debug"))
 
 	BaseRoutes.Posts.Handle("", ApiSessionRequired(createPost)).Methods("POST")
+	BaseRoutes.Posts.Handle("/multi", ApiSessionRequired(createCrossPosts)).Methods("POST")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(getPost)).Methods("GET")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(deletePost)).Methods("DELETE")
+	BaseRoutes.Post.Handle("/restore", ApiSessionRequired(restorePost)).Methods("POST")
 	BaseRoutes.Post.Handle("/thread", ApiSessionRequired(getPostThread)).Methods("GET")
+	BaseRoutes.Post.Handle("/history", ApiSessionRequired(getPostHistory)).Methods("GET")
+	BaseRoutes.Post.Handle("/acks", ApiSessionRequired(getPostAcks)).Methods("GET")
 	BaseRoutes.Post.Handle("/files/info", ApiSessionRequired(getFileInfosForPost)).Methods("GET")
 	BaseRoutes.PostsForChannel.Handle("", ApiSessionRequired(getPostsForChannel)).Methods("GET")
 	BaseRoutes.PostsForUser.Handle("/flagged", ApiSessionRequired(getFlaggedPostsForUser)).Methods("GET")
 
-	BaseRoutes.Team.Handle("/posts/search", ApiSessionRequired(searchPosts)).Methods("POST")
+	BaseRoutes.Team.Handle("/posts/search", ApiSessionRequiredReadOnly(searchPosts)).Methods("POST")
 	BaseRoutes.Post.Handle("", ApiSessionRequired(updatePost)).Methods("PUT")
 	BaseRoutes.Post.Handle("/patch", ApiSessionRequired(patchPost)).Methods("PUT")
 	BaseRoutes.Post.Handle("/pin", ApiSessionRequired(pinPost)).Methods("POST")
 	BaseRoutes.Post.Handle("/unpin", ApiSessionRequired(unpinPost)).Methods("POST")
+	BaseRoutes.Post.Handle("/actions/{action_id:[A-Za-z0-9_]+}", ApiSessionRequired(doPostAction)).Methods("POST")
 }
 
 func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -49,7 +55,14 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		post.CreateAt = 0
 	}
 
-	rp, err := app.CreatePostAsUser(post)
+	var rp *model.Post
+	var err *model.AppError
+	if alsoSendToChannel, _ := strconv.ParseBool(r.URL.Query().Get("also_send_to_channel")); alsoSendToChannel {
+		rp, err = app.CreateReplyAlsoInChannel(post)
+	} else {
+		rp, err = app.CreatePostAsUser(post)
+	}
+
 	if err != nil {
 		c.Err = err
 		return
@@ -59,6 +72,42 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(rp.ToJson()))
 }
 
+func createCrossPosts(c *Context, w http.ResponseWriter, r *http.Request) {
+	request := model.CrossPostRequestFromJson(r.Body)
+	if request == nil || request.Post == nil {
+		c.SetInvalidParam("cross_post")
+		return
+	}
+
+	if len(request.ChannelIds) == 0 {
+		c.SetInvalidParam("channel_ids")
+		return
+	}
+
+	post := request.Post
+	post.UserId = c.Session.UserId
+
+	for _, channelId := range request.ChannelIds {
+		if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_CREATE_POST) {
+			c.SetPermissionError(model.PERMISSION_CREATE_POST)
+			return
+		}
+	}
+
+	if post.CreateAt != 0 && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		post.CreateAt = 0
+	}
+
+	list, err := app.CreateCrossPosts(post, request.ChannelIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(list.ToJson()))
+}
+
 func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireChannelId()
 	if c.Err != nil {
@@ -87,6 +136,7 @@ func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	var list *model.PostList
 	var err *model.AppError
+	var countTotal func() (int64, *model.AppError)
 	etag := ""
 
 	if since > 0 {
@@ -114,7 +164,16 @@ func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		list, err = app.GetPostsPage(c.Params.ChannelId, c.Params.Page, c.Params.PerPage)
+		list, err = app.GetPostsPageContext(r.Context(), c.Params.ChannelId, c.Params.Page, c.Params.PerPage)
+
+		// Counting the posts in a large channel is slow, so use the approximate count kept on the channel
+		countTotal = func() (int64, *model.AppError) {
+			if channel, err := app.GetChannel(c.Params.ChannelId); err != nil {
+				return 0, err
+			} else {
+				return channel.TotalMsgCount, nil
+			}
+		}
 	}
 
 	if err != nil {
@@ -125,6 +184,23 @@ func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 	if len(etag) > 0 {
 		w.Header().Set(model.HEADER_ETAG_SERVER, etag)
 	}
+
+	// Posts changed since a time aren't paged
+	if since == 0 {
+		if pagination := c.GetPagination(len(list.Order), countTotal); pagination != nil {
+			// The cursor is the id of the post to continue from with the before or after parameter, which
+			// unlike the page number keeps working when new posts are made in the meantime
+			if pagination.HasMore && len(list.Order) > 0 {
+				if len(afterPost) > 0 {
+					pagination.NextCursor = list.Order[0]
+				} else {
+					pagination.NextCursor = list.Order[len(list.Order)-1]
+				}
+			}
+
+			pagination.SetHeaders(w.Header())
+		}
+	}
 	w.Write([]byte(list.ToJson()))
 }
 
@@ -158,7 +234,7 @@ func getFlaggedPostsForUser(c *Context, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Write([]byte(posts.ToJson()))
+	w.Write([]byte(app.FilterPostsByOAuthScope(c.Session, posts).ToJson()))
 }
 
 func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -172,7 +248,7 @@ func getPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if post, err := app.GetSinglePost(c.Params.PostId); err != nil {
+	if post, err := app.GetSinglePostContext(r.Context(), c.Params.PostId); err != nil {
 		c.Err = err
 		return
 	} else if HandleEtag(post.Etag(), "Get Post", w, r) {
@@ -202,6 +278,27 @@ func deletePost(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func restorePost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	post, err := app.RestorePost(c.Params.PostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("post_id=" + post.Id)
+	w.Write([]byte(post.ToJson()))
+}
+
 func getPostThread(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequirePostId()
 	if c.Err != nil {
@@ -213,7 +310,7 @@ func getPostThread(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if list, err := app.GetPostThread(c.Params.PostId); err != nil {
+	if list, err := app.GetPostThreadContext(r.Context(), c.Params.PostId); err != nil {
 		c.Err = err
 		return
 	} else if HandleEtag(list.Etag(), "Get Post Thread", w, r) {
@@ -224,6 +321,37 @@ func getPostThread(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getPostHistory(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	// Users can see the previous versions of their own posts and system admins can see them for any post
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+			c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+			return
+		}
+
+		if post, err := app.GetSinglePostContext(r.Context(), c.Params.PostId); err != nil {
+			c.Err = err
+			return
+		} else if post.UserId != c.Session.UserId {
+			c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+			return
+		}
+	}
+
+	if history, err := app.GetPostHistory(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("post_id=" + c.Params.PostId)
+		w.Write([]byte(model.PostHistoryListToJson(history)))
+	}
+}
+
 func searchPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireTeamId()
 	if c.Err != nil {
@@ -254,6 +382,8 @@ func searchPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	posts = app.FilterPostsByOAuthScope(c.Session, posts)
+
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	w.Write([]byte(posts.ToJson()))
 }
@@ -320,6 +450,37 @@ func patchPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(patchedPost.ToJson()))
 }
 
+func doPostAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId().RequireActionId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	var duration time.Duration
+	if props := model.MapFromJson(r.Body); len(props["duration"]) > 0 {
+		var err error
+		if duration, err = time.ParseDuration(props["duration"]); err != nil || duration <= 0 {
+			c.SetInvalidParam("duration")
+			return
+		}
+	}
+
+	c.LogAudit("attempt")
+
+	if _, err := app.DoPostAction(c.Params.PostId, c.Params.ActionId, c.Session.UserId, duration); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
 func saveIsPinnedPost(c *Context, w http.ResponseWriter, r *http.Request, isPinned bool) {
 	c.RequirePostId()
 	if c.Err != nil {
@@ -352,6 +513,28 @@ func unpinPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	saveIsPinnedPost(c, w, r, false)
 }
 
+func getPostAcks(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if post, err := app.GetSinglePostContext(r.Context(), c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else if acks, err := app.GetPostAcks(post); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.PostAckListToJson(acks)))
+	}
+}
+
 func getFileInfosForPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequirePostId()
 	if c.Err != nil {
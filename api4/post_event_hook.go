@@ -0,0 +1,207 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPostEventHook() {
+	l4g.Debug(utils.T("api.post_event_hook.init.debug"))
+
+	BaseRoutes.PostEventHooks.Handle("", ApiSessionRequired(createPostEventHook)).Methods("POST")
+	BaseRoutes.PostEventHooks.Handle("", ApiSessionRequired(getPostEventHooks)).Methods("GET")
+	BaseRoutes.PostEventHook.Handle("", ApiSessionRequired(getPostEventHook)).Methods("GET")
+	BaseRoutes.PostEventHook.Handle("", ApiSessionRequired(updatePostEventHook)).Methods("PUT")
+	BaseRoutes.PostEventHook.Handle("", ApiSessionRequired(deletePostEventHook)).Methods("DELETE")
+	BaseRoutes.PostEventHook.Handle("/regen_secret", ApiSessionRequired(regenPostEventHookSecret)).Methods("POST")
+}
+
+// checkPostEventHookScope checks that the session can receive the events a hook subscribes to.
+// A hook on a channel needs the channel to be readable, while a hook on a whole team receives
+// the posts of its private channels too so it can only be made by a system admin.
+func checkPostEventHookScope(c *Context, hook *model.PostEventHook) bool {
+	if len(hook.ChannelId) > 0 {
+		if !app.SessionHasPermissionToChannel(c.Session, hook.ChannelId, model.PERMISSION_READ_CHANNEL) {
+			c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+			return false
+		}
+	} else if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return false
+	}
+
+	return true
+}
+
+// getPostEventHookForSession returns the hook in the URL if the session can manage it.
+func getPostEventHookForSession(c *Context) *model.PostEventHook {
+	hook, err := app.GetPostEventHook(c.Params.HookId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return nil
+	}
+
+	if c.Session.UserId != hook.CreatorId && !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.SetPermissionError(model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)
+		return nil
+	}
+
+	return hook
+}
+
+func createPostEventHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	hook := model.PostEventHookFromJson(r.Body)
+	if hook == nil {
+		c.SetInvalidParam("post_event_hook")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	hook.CreatorId = c.Session.UserId
+
+	if !app.SessionHasPermissionToTeam(c.Session, hook.TeamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	if !checkPostEventHookScope(c, hook) {
+		return
+	}
+
+	if rhook, err := app.CreatePostEventHook(hook); err != nil {
+		c.LogAudit("fail")
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rhook.ToJson()))
+	}
+}
+
+func getPostEventHooks(c *Context, w http.ResponseWriter, r *http.Request) {
+	teamId := r.URL.Query().Get("team_id")
+	if len(teamId) != 26 {
+		c.SetInvalidParam("team_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_WEBHOOKS)
+		return
+	}
+
+	hooks, err := app.GetPostEventHooksForTeamPage(teamId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PostEventHookListToJson(hooks)))
+}
+
+func getPostEventHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	hook := getPostEventHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	w.Write([]byte(hook.ToJson()))
+}
+
+func updatePostEventHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	toUpdateHook := model.PostEventHookFromJson(r.Body)
+	if toUpdateHook == nil {
+		c.SetInvalidParam("post_event_hook")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	oldHook := getPostEventHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if !checkPostEventHookScope(c, toUpdateHook) {
+		return
+	}
+
+	rhook, err := app.UpdatePostEventHook(oldHook, toUpdateHook)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	w.Write([]byte(rhook.ToJson()))
+}
+
+func regenPostEventHookSecret(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	hook := getPostEventHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if rhook, err := app.RegenPostEventHookSecret(hook); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rhook.ToJson()))
+	}
+}
+
+func deletePostEventHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireHookId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	hook := getPostEventHookForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.DeletePostEventHook(hook.Id); err != nil {
+		c.LogAudit("fail")
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
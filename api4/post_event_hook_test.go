@@ -0,0 +1,152 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type postEventHookDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+func waitForPostEventHookDelivery(t *testing.T, deliveries chan *postEventHookDelivery, event string) *postEventHookDelivery {
+	for {
+		select {
+		case delivery := <-deliveries:
+			if delivery.header.Get(model.HEADER_POST_EVENT_HOOK_EVENT) == event {
+				return delivery
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("didn't receive a delivery for " + event)
+			return nil
+		}
+	}
+}
+
+func TestPostEventHooks(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableOutgoingHooks := utils.Cfg.ServiceSettings.EnableOutgoingWebhooks
+	enableAdminOnlyHooks := *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = enableOutgoingHooks
+		*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableAdminOnlyHooks
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	deliveries := make(chan *postEventHookDelivery, 10)
+	failures := int32(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		// Fail the first delivery to check that it's retried
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		deliveries <- &postEventHookDelivery{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	hook := &model.PostEventHook{TeamId: th.BasicTeam.Id, ChannelId: th.BasicChannel.Id, CallbackURL: server.URL}
+
+	rhook, resp := Client.CreatePostEventHook(hook)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rhook.CreatorId != th.BasicUser.Id || len(rhook.Secret) != 26 || len(rhook.Events) != 3 {
+		t.Fatal("should have created the hook for every event")
+	}
+
+	_, resp = Client.CreatePostEventHook(&model.PostEventHook{TeamId: th.BasicTeam.Id, CallbackURL: server.URL})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreatePostEventHook(&model.PostEventHook{TeamId: th.BasicTeam.Id, ChannelId: th.BasicChannel.Id, CallbackURL: "nowhere"})
+	CheckBadRequestStatus(t, resp)
+
+	teamHook, resp := th.SystemAdminClient.CreatePostEventHook(&model.PostEventHook{TeamId: th.BasicTeam.Id, CallbackURL: "http://nowhere.com", Events: model.StringArray{model.POST_EVENT_HOOK_EVENT_POST_DELETED}})
+	CheckNoError(t, resp)
+
+	post, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	delivery := waitForPostEventHookDelivery(t, deliveries, model.POST_EVENT_HOOK_EVENT_POSTED)
+	if !model.VerifyPostEventHookSignature(rhook.Secret, delivery.body, delivery.header.Get(model.HEADER_POST_EVENT_HOOK_SIGNATURE)) {
+		t.Fatal("should have signed the delivery")
+	}
+
+	if payload := model.PostEventHookPayloadFromJson(bytes.NewReader(delivery.body)); payload.HookId != rhook.Id || payload.Post.Id != post.Id || payload.TeamId != th.BasicTeam.Id {
+		t.Fatal("should have delivered the post")
+	}
+
+	post.Message = "edited"
+	_, resp = Client.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	waitForPostEventHookDelivery(t, deliveries, model.POST_EVENT_HOOK_EVENT_POST_EDITED)
+
+	rhook.Events = model.StringArray{model.POST_EVENT_HOOK_EVENT_POST_DELETED}
+	updated, resp := Client.UpdatePostEventHook(rhook)
+	CheckNoError(t, resp)
+
+	if len(updated.Events) != 1 || updated.Secret != rhook.Secret {
+		t.Fatal("should have updated the events")
+	}
+
+	regen, resp := Client.RegenPostEventHookSecret(rhook.Id)
+	CheckNoError(t, resp)
+
+	if regen.Secret == rhook.Secret {
+		t.Fatal("should have replaced the secret")
+	}
+
+	_, resp = Client.DeletePost(post.Id)
+	CheckNoError(t, resp)
+
+	delivery = waitForPostEventHookDelivery(t, deliveries, model.POST_EVENT_HOOK_EVENT_POST_DELETED)
+	if !model.VerifyPostEventHookSignature(regen.Secret, delivery.body, delivery.header.Get(model.HEADER_POST_EVENT_HOOK_SIGNATURE)) {
+		t.Fatal("should have signed the delivery with the new secret")
+	}
+
+	hooks, resp := Client.GetPostEventHooksForTeam(th.BasicTeam.Id, 0, 100)
+	CheckNoError(t, resp)
+
+	if len(hooks) != 2 {
+		t.Fatal("should have listed the hooks of the team")
+	}
+
+	_, resp = Client.GetPostEventHook(teamHook.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPostEventHook(rhook.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.DeletePostEventHook(rhook.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetPostEventHook(rhook.Id)
+	CheckNotFoundStatus(t, resp)
+
+	utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = false
+
+	_, resp = th.SystemAdminClient.GetPostEventHook(teamHook.Id)
+	CheckNotImplementedStatus(t, resp)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,6 +102,163 @@ func TestCreatePost(t *testing.T) {
 	}
 }
 
+func TestCreateCrossPosts(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	channel := th.CreatePublicChannel()
+	otherChannel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_OPEN)
+	channelIds := []string{th.BasicChannel.Id, channel.Id, channel.Id}
+
+	post := &model.Post{Message: "#hashtag a" + model.NewId() + "a", Props: model.StringInterface{"attachment": "value"}}
+	list, resp := Client.CreateCrossPosts(post, channelIds)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if len(list.Order) != 2 {
+		t.Fatal("should have created one post per channel")
+	}
+
+	first := list.Posts[list.Order[0]]
+	second := list.Posts[list.Order[1]]
+	if first.ChannelId != th.BasicChannel.Id || second.ChannelId != channel.Id {
+		t.Fatal("should have posted to each channel")
+	}
+
+	if first.UserId != th.BasicUser.Id || first.Message != post.Message || first.Hashtags != "#hashtag" || first.Props["attachment"] != "value" {
+		t.Fatal("should have copied the post")
+	}
+
+	if len(first.GetCrossPostId()) != 26 || first.GetCrossPostId() != second.GetCrossPostId() {
+		t.Fatal("should have linked the posts")
+	}
+
+	// Nothing is posted unless the user can post to every channel.
+	_, resp = Client.CreateCrossPosts(&model.Post{Message: "forbidden"}, []string{channel.Id, otherChannel.Id})
+	CheckForbiddenStatus(t, resp)
+
+	if posts, err := app.GetPosts(channel.Id, 0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(posts.Order) != 1 {
+		t.Fatal("should not have created any post")
+	}
+
+	_, resp = Client.CreateCrossPosts(&model.Post{Message: "reply", RootId: first.Id}, channelIds)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateCrossPosts(&model.Post{Message: "nowhere"}, []string{})
+	CheckBadRequestStatus(t, resp)
+
+	tooManyIds := []string{}
+	for i := 0; i <= model.CROSS_POST_MAX_CHANNELS; i++ {
+		tooManyIds = append(tooManyIds, th.CreatePublicChannel().Id)
+	}
+
+	_, resp = Client.CreateCrossPosts(&model.Post{Message: "everywhere"}, tooManyIds)
+	CheckBadRequestStatus(t, resp)
+
+	// Edits are applied to every copy.
+	message := "edited"
+	_, resp = Client.PatchPost(first.Id, &model.PostPatch{Message: &message})
+	CheckNoError(t, resp)
+
+	if rpost, err := app.GetSinglePost(second.Id); err != nil {
+		t.Fatal(err)
+	} else if rpost.Message != message || rpost.EditAt == 0 {
+		t.Fatal("should have updated the other copies")
+	}
+
+	// Deletes are applied to every copy.
+	_, resp = Client.DeletePost(second.Id)
+	CheckNoError(t, resp)
+
+	if _, err := app.GetSinglePost(first.Id); err == nil {
+		t.Fatal("should have deleted the other copies")
+	}
+
+	Client.Logout()
+	_, resp = Client.CreateCrossPosts(post, channelIds)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestCreateReplyAlsoInChannel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	root := th.CreatePost()
+	post := &model.Post{ChannelId: th.BasicChannel.Id, RootId: root.Id, Message: "@" + th.BasicUser2.Username + " a" + model.NewId() + "a"}
+	reply, resp := Client.CreateReplyAlsoInChannel(post)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if reply.RootId != root.Id || reply.ParentId != root.Id || reply.Message != post.Message {
+		t.Fatal("should have created the reply")
+	}
+
+	var channelPost *model.Post
+	if posts, err := app.GetPosts(th.BasicChannel.Id, 0, 10); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, p := range posts.Posts {
+			if p.Id != reply.Id && p.GetCrossPostId() == reply.GetCrossPostId() {
+				channelPost = p
+			}
+		}
+	}
+
+	if channelPost == nil {
+		t.Fatal("should have created the channel copy")
+	} else if channelPost.RootId != "" || channelPost.Message != post.Message || channelPost.Props[model.POST_PROPS_THREAD_ROOT_ID] != root.Id {
+		t.Fatal("should have copied the reply to the channel")
+	}
+
+	if len(reply.GetCrossPostId()) != 26 || reply.GetCrossPostId() != channelPost.GetCrossPostId() {
+		t.Fatal("should have linked the posts")
+	}
+
+	if member, err := app.GetChannelMember(th.BasicChannel.Id, th.BasicUser2.Id); err != nil {
+		t.Fatal(err)
+	} else if member.MentionCount != 1 {
+		t.Fatal("should have notified the mentioned user once")
+	}
+
+	// Edits and deletes are applied to both posts.
+	message := "edited"
+	_, resp = Client.PatchPost(reply.Id, &model.PostPatch{Message: &message})
+	CheckNoError(t, resp)
+
+	if rpost, err := app.GetSinglePost(channelPost.Id); err != nil {
+		t.Fatal(err)
+	} else if rpost.Message != message {
+		t.Fatal("should have updated the channel copy")
+	}
+
+	_, resp = Client.DeletePost(channelPost.Id)
+	CheckNoError(t, resp)
+
+	if _, err := app.GetSinglePost(reply.Id); err == nil {
+		t.Fatal("should have deleted the reply")
+	}
+
+	_, resp = Client.CreateReplyAlsoInChannel(&model.Post{ChannelId: th.BasicChannel.Id, Message: "not a reply"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateReplyAlsoInChannel(&model.Post{ChannelId: th.BasicChannel.Id, RootId: model.NewId(), Message: "no root"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateReplyAlsoInChannel(&model.Post{ChannelId: th.BasicChannel2.Id, RootId: root.Id, Message: "wrong channel"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateReplyAlsoInChannel(&model.Post{ChannelId: th.BasicChannel.Id, RootId: root.Id, Message: "with files", FileIds: []string{model.NewId()}})
+	CheckBadRequestStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateReplyAlsoInChannel(post)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestUpdatePost(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -288,6 +446,14 @@ func TestPinPost(t *testing.T) {
 	defer TearDown()
 	Client := th.Client
 
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+	time.Sleep(300 * time.Millisecond)
+
 	post := th.BasicPost
 	pass, resp := Client.PinPost(post.Id)
 	CheckNoError(t, resp)
@@ -296,10 +462,31 @@ func TestPinPost(t *testing.T) {
 		t.Fatal("should have passed")
 	}
 
-	if rpost, err := app.GetSinglePost(post.Id); err != nil && rpost.IsPinned != true {
+	eventHit := false
+	timeout := time.After(2 * time.Second)
+	for !eventHit {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_POST_EDITED {
+				if rpost := model.PostFromJson(strings.NewReader(event.Data["post"].(string))); rpost.Id == post.Id && rpost.IsPinned {
+					eventHit = true
+				}
+			}
+		case <-timeout:
+			t.Fatal("should have sent an edited event for the pinned post")
+		}
+	}
+
+	if rpost, err := app.GetSinglePost(post.Id); err != nil || rpost.IsPinned != true {
 		t.Fatal("failed to pin post")
 	}
 
+	if posts, resp := Client.GetPinnedPosts(post.ChannelId, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if _, ok := posts.Posts[post.Id]; !ok {
+		t.Fatal("pinned post should be in the channel's pinned list")
+	}
+
 	pass, resp = Client.PinPost("junk")
 	CheckBadRequestStatus(t, resp)
 
@@ -331,8 +518,14 @@ func TestUnpinPost(t *testing.T) {
 		t.Fatal("should have passed")
 	}
 
-	if rpost, err := app.GetSinglePost(pinnedPost.Id); err != nil && rpost.IsPinned != false {
-		t.Fatal("failed to pin post")
+	if rpost, err := app.GetSinglePost(pinnedPost.Id); err != nil || rpost.IsPinned != false {
+		t.Fatal("failed to unpin post")
+	}
+
+	if posts, resp := Client.GetPinnedPosts(pinnedPost.ChannelId, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if _, ok := posts.Posts[pinnedPost.Id]; ok {
+		t.Fatal("unpinned post should not be in the channel's pinned list")
 	}
 
 	pass, resp = Client.UnpinPost("junk")
@@ -794,6 +987,45 @@ func TestDeletePost(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestRestorePost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+	reply, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "reply", RootId: post.Id})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RestorePost(post.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeletePost(post.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RestorePost(reply.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.RestorePost(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RestorePost(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	rpost, resp := th.SystemAdminClient.RestorePost(post.Id)
+	CheckNoError(t, resp)
+
+	if rpost.Id != post.Id || rpost.DeleteAt != 0 {
+		t.Fatal("should have restored the post")
+	}
+
+	_, resp = Client.GetPost(reply.Id, "")
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.RestorePost(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestGetPostThread(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -835,6 +1067,120 @@ func TestGetPostThread(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestGetPostAcks(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableReadReceipts := *utils.Cfg.TeamSettings.EnableReadReceipts
+	defer func() {
+		*utils.Cfg.TeamSettings.EnableReadReceipts = enableReadReceipts
+	}()
+	*utils.Cfg.TeamSettings.EnableReadReceipts = false
+
+	post := th.CreatePost()
+
+	_, resp := Client.GetPostAcks(post.Id)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.TeamSettings.EnableReadReceipts = true
+
+	acks, resp := Client.GetPostAcks(post.Id)
+	CheckNoError(t, resp)
+	if len(acks) != 0 {
+		t.Fatal("nobody should have read the post yet")
+	}
+
+	Client.ViewChannel(th.BasicUser.Id, &model.ChannelView{ChannelId: th.BasicChannel.Id})
+
+	acks, resp = Client.GetPostAcks(post.Id)
+	CheckNoError(t, resp)
+	if len(acks) != 0 {
+		t.Fatal("the author of the post shouldn't have a read receipt")
+	}
+
+	th.LoginBasic2()
+	_, resp = Client.ViewChannel(th.BasicUser2.Id, &model.ChannelView{ChannelId: th.BasicChannel.Id})
+	CheckNoError(t, resp)
+
+	acks, resp = Client.GetPostAcks(post.Id)
+	CheckNoError(t, resp)
+	if len(acks) != 1 || acks[0].UserId != th.BasicUser2.Id || acks[0].PostId != post.Id {
+		t.Fatal("should have returned the read receipt of the user who viewed the channel")
+	}
+
+	_, resp = Client.GetPostAcks("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetPostAcks(model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	privatePost := &model.Post{ChannelId: th.BasicPrivateChannel.Id, Message: "private"}
+	privatePost, _ = Client.CreatePost(privatePost)
+
+	Client.Logout()
+	_, resp = Client.GetPostAcks(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPostAcks(privatePost.Id)
+	CheckNoError(t, resp)
+}
+
+func TestGetPostHistory(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+	originalMessage := post.Message
+
+	history, resp := Client.GetPostHistory(post.Id)
+	CheckNoError(t, resp)
+
+	if len(history) != 0 {
+		t.Fatal("an unedited post shouldn't have any history")
+	}
+
+	post.Message = "edited " + model.NewId()
+	_, resp = Client.UpdatePost(post.Id, post)
+	CheckNoError(t, resp)
+
+	pinned := true
+	_, resp = Client.PatchPost(post.Id, &model.PostPatch{IsPinned: &pinned})
+	CheckNoError(t, resp)
+
+	history, resp = Client.GetPostHistory(post.Id)
+	CheckNoError(t, resp)
+
+	if len(history) != 1 {
+		t.Fatal("should only have saved the edit to the message")
+	} else if history[0].Message != originalMessage || history[0].PostId != post.Id {
+		t.Fatal("should have saved the original message")
+	}
+
+	_, resp = Client.GetPostHistory("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetPostHistory(model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.GetPostHistory(post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	history, resp = th.SystemAdminClient.GetPostHistory(post.Id)
+	CheckNoError(t, resp)
+
+	if len(history) != 1 {
+		t.Fatal("system admins should be able to see the history")
+	}
+
+	Client.Logout()
+	_, resp = Client.GetPostHistory(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestSearchPosts(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -896,6 +1242,30 @@ func TestSearchPosts(t *testing.T) {
 
 }
 
+func TestSearchPostsWithChannelScope(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	th.LoginBasic()
+	Client := th.Client
+
+	post1 := th.CreateMessagePost("scoped search in the first channel")
+	th.CreateMessagePostWithClient(Client, th.BasicChannel2, "scoped search in the second channel")
+
+	th.LoginBasicWithOAuthScope(Client, model.OAUTH_SCOPE_READ+" "+model.OAUTH_SCOPE_CHANNEL_PREFIX+th.BasicChannel.Id)
+
+	posts, resp := Client.SearchPosts(th.BasicTeam.Id, "scoped", false)
+	CheckNoError(t, resp)
+	if len(posts.Order) != 1 || posts.Order[0] != post1.Id {
+		t.Fatal("should only find the posts of the granted channel")
+	}
+
+	for _, post := range posts.Posts {
+		if post.ChannelId != th.BasicChannel.Id {
+			t.Fatal("should not return posts of other channels")
+		}
+	}
+}
+
 func TestSearchHashtagPosts(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -1111,3 +1481,27 @@ func TestGetFileInfosForPost(t *testing.T) {
 	_, resp = th.SystemAdminClient.GetFileInfosForPost(th.BasicPost.Id, "")
 	CheckNoError(t, resp)
 }
+
+func TestGetPostsForChannelPagination(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	th.CreatePost()
+	th.CreatePost()
+
+	route := "/channels/" + th.BasicChannel.Id + "/posts?page=0&per_page=1&include_total_count=true"
+	if r, err := Client.DoApiGet(route, ""); err != nil {
+		t.Fatal(err)
+	} else if list := model.PostListFromJson(r.Body); len(list.Order) != 1 {
+		t.Fatal("should have returned one post")
+	} else if pagination := model.PaginationFromHeaders(r.Header); pagination == nil {
+		t.Fatal("should have returned pagination")
+	} else if !pagination.HasMore || pagination.NextCursor != list.Order[0] {
+		t.Fatal("should continue from the returned post", pagination)
+	} else if r, err := Client.DoApiGet("/channels/"+th.BasicChannel.Id+"/posts?before="+pagination.NextCursor+"&per_page=1", ""); err != nil {
+		t.Fatal(err)
+	} else if next := model.PostListFromJson(r.Body); len(next.Order) != 1 || next.Order[0] == list.Order[0] {
+		t.Fatal("should have returned the next post")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	MAX_BULK_REACTION_POST_IDS = 200
+
+	TOP_REACTIONS_LIMIT_DEFAULT = 10
+	TOP_REACTIONS_LIMIT_MAXIMUM = 100
+)
+
+func InitReaction() {
+	l4g.Debug(utils.T("api.reaction.init.debug"))
+
+	BaseRoutes.Reactions.Handle("/ids", ApiSessionRequiredReadOnly(getBulkReactions)).Methods("POST")
+	BaseRoutes.Team.Handle("/reactions/top", ApiSessionRequired(getTopReactionsForTeam)).Methods("GET")
+}
+
+func getBulkReactions(c *Context, w http.ResponseWriter, r *http.Request) {
+	postIds := model.ArrayFromJson(r.Body)
+
+	if len(postIds) == 0 || len(postIds) > MAX_BULK_REACTION_POST_IDS {
+		c.SetInvalidParam("post_ids")
+		return
+	}
+
+	for _, postId := range postIds {
+		if len(postId) != 26 {
+			c.SetInvalidParam("post_ids")
+			return
+		}
+	}
+
+	if !app.SessionHasPermissionToChannelsByPosts(c.Session, postIds, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if reactions, err := app.GetReactionsForPostsContext(r.Context(), postIds); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ReactionsByPostToJson(reactions)))
+	}
+}
+
+func getTopReactionsForTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	var since int64
+	if sinceString := r.URL.Query().Get("since"); len(sinceString) > 0 {
+		var err error
+		if since, err = strconv.ParseInt(sinceString, 10, 64); err != nil || since < 0 {
+			c.SetInvalidParam("since")
+			return
+		}
+	}
+
+	limit := TOP_REACTIONS_LIMIT_DEFAULT
+	if limitString := r.URL.Query().Get("limit"); len(limitString) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(limitString); err != nil || limit <= 0 || limit > TOP_REACTIONS_LIMIT_MAXIMUM {
+			c.SetInvalidParam("limit")
+			return
+		}
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	if counts, err := app.GetTopReactionsForTeam(c.Params.TeamId, since, limit); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ReactionCountsToJson(counts)))
+	}
+}
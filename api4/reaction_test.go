@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+)
+
+func TestGetBulkReactions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post1 := th.CreatePost()
+	post2 := th.CreatePost()
+	post3 := th.CreatePost()
+
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: post1.Id, EmojiName: "smile"}))
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser2.Id, PostId: post1.Id, EmojiName: "smile"}))
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: post2.Id, EmojiName: "frowning"}))
+
+	reactions, resp := Client.GetBulkReactions([]string{post1.Id, post2.Id, post3.Id})
+	CheckNoError(t, resp)
+
+	if len(reactions) != 3 {
+		t.Fatal("should have returned an entry for every post")
+	}
+
+	if len(reactions[post1.Id]) != 2 || len(reactions[post2.Id]) != 1 || len(reactions[post3.Id]) != 0 {
+		t.Fatal("wrong reactions returned")
+	}
+
+	if reactions[post2.Id][0].EmojiName != "frowning" {
+		t.Fatal("wrong reaction returned")
+	}
+
+	_, resp = Client.GetBulkReactions([]string{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetBulkReactions([]string{"junk"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetBulkReactions([]string{post1.Id, model.NewId()})
+	CheckForbiddenStatus(t, resp)
+
+	privateChannel := th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE)
+	privatePost := th.CreatePostWithClient(th.SystemAdminClient, privateChannel)
+	_, resp = Client.GetBulkReactions([]string{post1.Id, privatePost.Id})
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetBulkReactions([]string{post1.Id})
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetTopReactionsForTeam(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	post1 := th.CreatePost()
+	post2 := th.CreatePost()
+
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: post1.Id, EmojiName: "smile"}))
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser2.Id, PostId: post1.Id, EmojiName: "smile"}))
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: post2.Id, EmojiName: "smile"}))
+	store.Must(app.Srv.Store.Reaction().Save(&model.Reaction{UserId: th.BasicUser.Id, PostId: post2.Id, EmojiName: "frowning"}))
+
+	_, resp := Client.GetTopReactionsForTeam(th.BasicTeam.Id, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginTeamAdmin()
+
+	counts, resp := Client.GetTopReactionsForTeam(th.BasicTeam.Id, 0, 10)
+	CheckNoError(t, resp)
+
+	if len(counts) != 2 {
+		t.Fatal("should have returned both emoji")
+	}
+
+	if counts[0].EmojiName != "smile" || counts[0].Count != 3 || counts[1].EmojiName != "frowning" || counts[1].Count != 1 {
+		t.Fatal("wrong counts returned")
+	}
+
+	counts, resp = Client.GetTopReactionsForTeam(th.BasicTeam.Id, 0, 1)
+	CheckNoError(t, resp)
+
+	if len(counts) != 1 {
+		t.Fatal("should have applied the limit")
+	}
+
+	counts, resp = Client.GetTopReactionsForTeam(th.BasicTeam.Id, model.GetMillis()+60*60*1000, 10)
+	CheckNoError(t, resp)
+
+	if len(counts) != 0 {
+		t.Fatal("should not have returned older reactions")
+	}
+
+	_, resp = Client.GetTopReactionsForTeam(th.BasicTeam.Id, 0, 1000)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetTopReactionsForTeam(th.BasicTeam.Id, -1, 10)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetTopReactionsForTeam(model.NewId(), 0, 10)
+	CheckForbiddenStatus(t, resp)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitRemoteCluster() {
+	l4g.Debug(utils.T("api.remote_cluster.init.debug"))
+
+	BaseRoutes.RemoteClusters.Handle("", ApiSessionRequired(createRemoteCluster)).Methods("POST")
+	BaseRoutes.RemoteClusters.Handle("", ApiSessionRequired(getRemoteClusters)).Methods("GET")
+	BaseRoutes.RemoteClusters.Handle("/accept_invite", ApiSessionRequired(acceptRemoteClusterInvite)).Methods("POST")
+	BaseRoutes.RemoteCluster.Handle("", ApiSessionRequired(deleteRemoteCluster)).Methods("DELETE")
+
+	// Called by remotes, which identify themselves with the id and token they were given
+	BaseRoutes.RemoteClusters.Handle("/confirm_invite", ApiHandler(confirmRemoteClusterInvite)).Methods("POST")
+	BaseRoutes.RemoteClusters.Handle("/channel_invite", ApiHandler(receiveSharedChannelInvite)).Methods("POST")
+	BaseRoutes.RemoteClusters.Handle("/msg", ApiHandler(receiveSharedChannelSync)).Methods("POST")
+
+	BaseRoutes.Channel.Handle("/remotes", ApiSessionRequired(getSharedChannelRemotes)).Methods("GET")
+	BaseRoutes.Channel.Handle("/remotes/{remote_id:[A-Za-z0-9]+}", ApiSessionRequired(shareChannelWithRemote)).Methods("POST")
+	BaseRoutes.Channel.Handle("/remotes/{remote_id:[A-Za-z0-9]+}", ApiSessionRequired(unshareChannelWithRemote)).Methods("DELETE")
+}
+
+func createRemoteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	rc := model.RemoteClusterFromJson(r.Body)
+	if rc == nil {
+		c.SetInvalidParam("remote_cluster")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	rc.CreatorId = c.Session.UserId
+
+	rrc, invite, err := app.CreateRemoteCluster(rc)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_REMOTE_CLUSTER, rrc.RemoteId, map[string]interface{}{"name": rrc.Name})
+
+	rrc.Sanitize()
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte((&model.RemoteClusterWithInvite{RemoteCluster: rrc, Invite: invite}).ToJson()))
+}
+
+func getRemoteClusters(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	remotes, err := app.GetRemoteClusters()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, rc := range remotes {
+		rc.Sanitize()
+	}
+
+	w.Write([]byte(model.RemoteClusterListToJson(remotes)))
+}
+
+func acceptRemoteClusterInvite(c *Context, w http.ResponseWriter, r *http.Request) {
+	props := model.MapFromJson(r.Body)
+
+	invite := props["invite"]
+	if len(invite) == 0 || len(invite) > model.REMOTE_CLUSTER_INVITE_MAX_ENCODED_SIZE {
+		c.SetInvalidParam("invite")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	rc := &model.RemoteCluster{
+		Name:          props["name"],
+		DisplayName:   props["display_name"],
+		DefaultTeamId: props["default_team_id"],
+		CreatorId:     c.Session.UserId,
+	}
+
+	rrc, err := app.AcceptRemoteClusterInvite(invite, rc)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_REMOTE_CLUSTER, rrc.RemoteId, map[string]interface{}{"name": rrc.Name, "site_url": rrc.SiteURL})
+
+	rrc.Sanitize()
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rrc.ToJson()))
+}
+
+func deleteRemoteCluster(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireRemoteId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteRemoteCluster(c.Params.RemoteId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_REMOTE_CLUSTER, c.Params.RemoteId, nil)
+	ReturnStatusOK(w)
+}
+
+// authenticateRemoteCluster returns the remote that made a request, or sets an error on the context if
+// the request didn't come from a remote.
+func authenticateRemoteCluster(c *Context, r *http.Request) *model.RemoteCluster {
+	rc, err := app.AuthenticateRemoteCluster(r.Header.Get(model.HEADER_REMOTE_ID), r.Header.Get(model.HEADER_REMOTE_TOKEN))
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	return rc
+}
+
+func confirmRemoteClusterInvite(c *Context, w http.ResponseWriter, r *http.Request) {
+	rc := authenticateRemoteCluster(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	confirmation := model.RemoteClusterConfirmationFromJson(r.Body)
+	if confirmation == nil {
+		c.SetInvalidParam("confirmation")
+		return
+	}
+
+	if _, err := app.ConfirmRemoteClusterInvite(rc, confirmation); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func receiveSharedChannelInvite(c *Context, w http.ResponseWriter, r *http.Request) {
+	rc := authenticateRemoteCluster(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !rc.IsConfirmed() {
+		c.Err = model.NewAppError("receiveSharedChannelInvite", "api.remote_cluster.not_confirmed.app_error", nil, "remote_id="+rc.RemoteId, http.StatusForbidden)
+		return
+	}
+
+	invite := model.SharedChannelInviteFromJson(r.Body)
+	if invite == nil {
+		c.SetInvalidParam("invite")
+		return
+	}
+
+	if _, err := app.ReceiveSharedChannelInvite(rc, invite); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func receiveSharedChannelSync(c *Context, w http.ResponseWriter, r *http.Request) {
+	rc := authenticateRemoteCluster(c, r)
+	if c.Err != nil {
+		return
+	}
+
+	if !rc.IsConfirmed() {
+		c.Err = model.NewAppError("receiveSharedChannelSync", "api.remote_cluster.not_confirmed.app_error", nil, "remote_id="+rc.RemoteId, http.StatusForbidden)
+		return
+	}
+
+	msg := model.SharedChannelSyncMsgFromJson(r.Body)
+	if msg == nil {
+		c.SetInvalidParam("msg")
+		return
+	}
+
+	if err := app.ReceiveSharedChannelSync(rc, msg); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getSharedChannelRemotes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	remotes, err := app.GetSharedChannelRemotes(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.SharedChannelRemoteListToJson(remotes)))
+}
+
+func shareChannelWithRemote(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireRemoteId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	channel, err := app.GetChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if _, err := app.ShareChannelWithRemote(channel, c.Params.RemoteId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_CHANNEL, channel.Id, map[string]interface{}{"remote_id": c.Params.RemoteId})
+	ReturnStatusOK(w)
+}
+
+func unshareChannelWithRemote(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId().RequireRemoteId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.UnshareChannelWithRemote(c.Params.ChannelId, c.Params.RemoteId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_CHANNEL, c.Params.ChannelId, map[string]interface{}{"remote_id": c.Params.RemoteId})
+	ReturnStatusOK(w)
+}
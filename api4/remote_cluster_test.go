@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestRemoteCluster(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableSharedChannels := *utils.Cfg.ServiceSettings.EnableSharedChannels
+	siteURL := *utils.Cfg.ServiceSettings.SiteURL
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableSharedChannels = enableSharedChannels
+		*utils.Cfg.ServiceSettings.SiteURL = siteURL
+	}()
+
+	// The server connects to itself, so each side of the connection is a remote on the same server
+	*utils.Cfg.ServiceSettings.SiteURL = "http://localhost" + utils.Cfg.ServiceSettings.ListenAddress
+
+	rc := &model.RemoteCluster{Name: "r" + model.NewId()[:10], DisplayName: "Remote"}
+
+	*utils.Cfg.ServiceSettings.EnableSharedChannels = false
+	_, resp := th.SystemAdminClient.CreateRemoteCluster(rc)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableSharedChannels = true
+	_, resp = Client.CreateRemoteCluster(rc)
+	CheckForbiddenStatus(t, resp)
+
+	created, resp := th.SystemAdminClient.CreateRemoteCluster(rc)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if created.RemoteCluster.Token != "" || created.Invite == "" {
+		t.Fatal("should have returned the invite without the remote's token")
+	}
+
+	_, resp = th.SystemAdminClient.AcceptRemoteClusterInvite("junk", "r"+model.NewId()[:10], "", th.BasicTeam.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AcceptRemoteClusterInvite(created.Invite, "r"+model.NewId()[:10], "", th.BasicTeam.Id)
+	CheckForbiddenStatus(t, resp)
+
+	accepted, resp := th.SystemAdminClient.AcceptRemoteClusterInvite(created.Invite, "r"+model.NewId()[:10], "", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if !accepted.IsConfirmed() || accepted.RemoteToken != "" {
+		t.Fatal("the remote should have been confirmed and sanitized")
+	}
+
+	if _, resp = th.SystemAdminClient.AcceptRemoteClusterInvite(created.Invite, "r"+model.NewId()[:10], "", th.BasicTeam.Id); resp.Error == nil {
+		t.Fatal("shouldn't accept an invite twice")
+	}
+
+	remotes, resp := th.SystemAdminClient.GetRemoteClusters()
+	CheckNoError(t, resp)
+
+	confirmed := false
+	for _, remote := range remotes {
+		if remote.RemoteId == created.RemoteCluster.RemoteId {
+			confirmed = len(remote.SiteURL) > 0
+		}
+	}
+
+	if !confirmed {
+		t.Fatal("the invite should have been confirmed")
+	}
+
+	_, resp = Client.GetRemoteClusters()
+	CheckForbiddenStatus(t, resp)
+
+	channel := th.BasicChannel
+
+	_, resp = Client.ShareChannelWithRemote(channel.Id, created.RemoteCluster.RemoteId)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ShareChannelWithRemote(th.BasicPrivateChannel.Id, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ShareChannelWithRemote(channel.Id, created.RemoteCluster.RemoteId)
+	CheckNoError(t, resp)
+
+	channelRemotes, resp := th.SystemAdminClient.GetSharedChannelRemotes(channel.Id)
+	CheckNoError(t, resp)
+
+	if len(channelRemotes) != 1 || channelRemotes[0].RemoteId != created.RemoteCluster.RemoteId {
+		t.Fatal("the channel should have been shared with the remote")
+	}
+
+	// The other side of the connection created its own copy of the channel in its default team
+	if _, err := app.GetChannelByName(channel.Name+"-"+accepted.Name, th.BasicTeam.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = th.SystemAdminClient.UnshareChannelWithRemote(channel.Id, created.RemoteCluster.RemoteId)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.UnshareChannelWithRemote(channel.Id, created.RemoteCluster.RemoteId)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteRemoteCluster(accepted.RemoteId)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteRemoteCluster(accepted.RemoteId)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteRemoteCluster(created.RemoteCluster.RemoteId)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteRemoteCluster(created.RemoteCluster.RemoteId)
+	CheckNotFoundStatus(t, resp)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitRetentionPolicy() {
+	l4g.Debug(utils.T("api.retention_policy.init.debug"))
+
+	BaseRoutes.RetentionPolicies.Handle("", ApiSessionRequired(createRetentionPolicy)).Methods("POST")
+	BaseRoutes.RetentionPolicies.Handle("", ApiSessionRequired(getRetentionPolicies)).Methods("GET")
+	BaseRoutes.RetentionPolicy.Handle("", ApiSessionRequired(getRetentionPolicy)).Methods("GET")
+	BaseRoutes.RetentionPolicy.Handle("", ApiSessionRequired(updateRetentionPolicy)).Methods("PUT")
+	BaseRoutes.RetentionPolicy.Handle("", ApiSessionRequired(deleteRetentionPolicy)).Methods("DELETE")
+	BaseRoutes.Reports.Handle("/retention_preview", ApiSessionRequired(getRetentionPreview)).Methods("GET")
+}
+
+func createRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	policy := model.RetentionPolicyFromJson(r.Body)
+	if policy == nil {
+		c.SetInvalidParam("retention_policy")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	rpolicy, err := app.CreateRetentionPolicy(policy)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + rpolicy.Id + " team_id=" + rpolicy.TeamId + " channel_id=" + rpolicy.ChannelId)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rpolicy.ToJson()))
+}
+
+func getRetentionPolicies(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policies, err := app.GetRetentionPolicies(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if pagination := c.GetPagination(len(policies), func() (int64, *model.AppError) {
+		return app.GetRetentionPoliciesCount()
+	}); pagination != nil {
+		pagination.SetHeaders(w.Header())
+	}
+
+	w.Write([]byte(model.RetentionPolicyListToJson(policies)))
+}
+
+func getRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePolicyId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policy, err := app.GetRetentionPolicy(c.Params.PolicyId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(policy.ToJson()))
+}
+
+func updateRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePolicyId()
+	if c.Err != nil {
+		return
+	}
+
+	policy := model.RetentionPolicyFromJson(r.Body)
+	if policy == nil {
+		c.SetInvalidParam("retention_policy")
+		return
+	}
+
+	if policy.Id != c.Params.PolicyId {
+		c.SetInvalidParam("id")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	rpolicy, err := app.UpdateRetentionPolicy(policy)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + rpolicy.Id)
+	w.Write([]byte(rpolicy.ToJson()))
+}
+
+func deleteRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePolicyId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteRetentionPolicy(c.Params.PolicyId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + c.Params.PolicyId)
+	ReturnStatusOK(w)
+}
+
+func getRetentionPreview(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	previews, err := app.GetRetentionPreview()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.RetentionPreviewListToJson(previews)))
+}
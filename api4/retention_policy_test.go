@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestRetentionPolicies(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	policy := &model.RetentionPolicy{TeamId: th.BasicTeam.Id, MessageRetentionDays: 30, FileRetentionDays: 7}
+
+	_, resp := Client.CreateRetentionPolicy(policy)
+	CheckForbiddenStatus(t, resp)
+
+	rpolicy, resp := th.SystemAdminClient.CreateRetentionPolicy(policy)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rpolicy.TeamId != th.BasicTeam.Id || rpolicy.MessageRetentionDays != 30 {
+		t.Fatal("should have created the policy")
+	}
+
+	_, resp = th.SystemAdminClient.CreateRetentionPolicy(&model.RetentionPolicy{TeamId: th.BasicTeam.Id})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateRetentionPolicy(&model.RetentionPolicy{ChannelId: model.NewId()})
+	CheckNotFoundStatus(t, resp)
+
+	channelPolicy, resp := th.SystemAdminClient.CreateRetentionPolicy(&model.RetentionPolicy{ChannelId: th.BasicChannel.Id, FileRetentionDays: 1})
+	CheckNoError(t, resp)
+
+	policies, resp := th.SystemAdminClient.GetRetentionPolicies(0, 100)
+	CheckNoError(t, resp)
+
+	if len(policies) != 2 || policies[0].Id != rpolicy.Id || policies[1].Id != channelPolicy.Id {
+		t.Fatal("should have returned the team policy followed by the channel policy")
+	}
+
+	_, resp = Client.GetRetentionPolicies(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	rpolicy.MessageRetentionDays = 60
+	rpolicy.TeamId = model.NewId()
+	updated, resp := th.SystemAdminClient.UpdateRetentionPolicy(rpolicy)
+	CheckNoError(t, resp)
+
+	if updated.MessageRetentionDays != 60 || updated.TeamId != th.BasicTeam.Id {
+		t.Fatal("should have only updated the days")
+	}
+
+	fetched, resp := th.SystemAdminClient.GetRetentionPolicy(rpolicy.Id)
+	CheckNoError(t, resp)
+
+	if fetched.MessageRetentionDays != 60 {
+		t.Fatal("should have saved the update")
+	}
+
+	_, resp = th.SystemAdminClient.GetRetentionPolicy("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetRetentionPolicy(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	previews, resp := th.SystemAdminClient.GetRetentionPreview()
+	CheckNoError(t, resp)
+
+	if len(previews) != 3 || len(previews[0].PolicyId) != 0 || previews[1].PolicyId != rpolicy.Id {
+		t.Fatal("should have previewed the global settings and each policy")
+	}
+
+	_, resp = Client.GetRetentionPreview()
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeleteRetentionPolicy(rpolicy.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.DeleteRetentionPolicy(rpolicy.Id)
+	CheckNoError(t, resp)
+
+	if !ok {
+		t.Fatal("should have returned ok")
+	}
+
+	_, resp = th.SystemAdminClient.DeleteRetentionPolicy(channelPolicy.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteRetentionPolicy(rpolicy.Id)
+	CheckNotFoundStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetRetentionPolicies(0, 100)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -42,7 +42,7 @@ func getSamlMetadata(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func parseSamlCertificateRequest(r *http.Request) (*multipart.FileHeader, *model.AppError) {
-	err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize)
+	err := r.ParseMultipartForm(*app.Config().FileSettings.MaxFileSize)
 	if err != nil {
 		return nil, model.NewAppError("addSamlCertificate", "api.admin.add_certificate.no_file.app_error", nil, err.Error(), http.StatusBadRequest)
 	}
@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitScheduledPost() {
+	l4g.Debug(utils.T("api.scheduled_post.init.debug"))
+
+	BaseRoutes.ScheduledPosts.Handle("", ApiSessionRequired(createScheduledPost)).Methods("POST")
+	BaseRoutes.ScheduledPost.Handle("", ApiSessionRequired(deleteScheduledPost)).Methods("DELETE")
+	BaseRoutes.PostsForUser.Handle("/scheduled", ApiSessionRequired(getScheduledPostsForUser)).Methods("GET")
+}
+
+func createScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost.Id = ""
+	scheduledPost.UserId = c.Session.UserId
+
+	if !app.SessionHasPermissionToChannel(c.Session, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	if rscheduledPost, err := app.CreateScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func getScheduledPostsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if scheduledPosts, err := app.GetScheduledPostsForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ScheduledPostListToJson(scheduledPosts)))
+	}
+}
+
+func deleteScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireScheduledPostId()
+	if c.Err != nil {
+		return
+	}
+
+	scheduledPost, err := app.GetScheduledPost(c.Params.ScheduledPostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, scheduledPost.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.CancelScheduledPost(scheduledPost.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
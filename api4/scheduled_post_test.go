@@ -0,0 +1,197 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestCreateScheduledPost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledPost := &model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "later", ScheduledAt: model.GetMillis() + 60000}
+	rscheduledPost, resp := Client.CreateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if rscheduledPost.UserId != th.BasicUser.Id || rscheduledPost.Message != scheduledPost.Message {
+		t.Fatal("scheduled post did not match")
+	}
+
+	scheduledPost.ScheduledAt = model.GetMillis() - 60000
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPost.ScheduledAt = model.GetMillis() + 60000
+	scheduledPost.Message = ""
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPost.Message = "later"
+	scheduledPost.ChannelId = model.NewId()
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckForbiddenStatus(t, resp)
+
+	scheduledPost.ChannelId = th.BasicPrivateChannel.Id
+	th.LoginBasic2()
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.CreateScheduledPost(scheduledPost)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetScheduledPostsForUser(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "later", ScheduledAt: model.GetMillis() + 60000})
+	CheckNoError(t, resp)
+
+	scheduledPosts, resp := Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if len(scheduledPosts) != 1 || scheduledPosts[0].Id != scheduledPost.Id {
+		t.Fatal("should have returned the scheduled post")
+	}
+
+	_, resp = Client.GetScheduledPostsForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetScheduledPostsForUser("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestDeleteScheduledPost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	scheduledPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "later", ScheduledAt: model.GetMillis() + 60000})
+	CheckNoError(t, resp)
+
+	th.LoginBasic2()
+	_, resp = Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic()
+	pass, resp := Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckNoError(t, resp)
+
+	if !pass {
+		t.Fatal("should have passed")
+	}
+
+	_, resp = Client.DeleteScheduledPost(scheduledPost.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.DeleteScheduledPost("junk")
+	CheckBadRequestStatus(t, resp)
+
+	if scheduledPosts, _ := Client.GetScheduledPostsForUser(th.BasicUser.Id); len(scheduledPosts) != 0 {
+		t.Fatal("should have cancelled the scheduled post")
+	}
+
+	Client.Logout()
+	_, resp = Client.DeleteScheduledPost(model.NewId())
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestPublishDueScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+	time.Sleep(300 * time.Millisecond)
+
+	scheduledPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "scheduled " + model.NewId(), ScheduledAt: model.GetMillis() + 100})
+	CheckNoError(t, resp)
+
+	time.Sleep(200 * time.Millisecond)
+	app.PublishDueScheduledPosts()
+
+	eventHit := false
+	timeout := time.After(2 * time.Second)
+	for !eventHit {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_POSTED {
+				if post := model.PostFromJson(strings.NewReader(event.Data["post"].(string))); post.Message == scheduledPost.Message && post.UserId == th.BasicUser.Id {
+					eventHit = true
+				}
+			}
+		case <-timeout:
+			t.Fatal("should have sent a posted event for the scheduled post")
+		}
+	}
+
+	if scheduledPosts, _ := Client.GetScheduledPostsForUser(th.BasicUser.Id); len(scheduledPosts) != 0 {
+		t.Fatal("should have removed the published scheduled post")
+	}
+
+	posts, resp := Client.GetPostsForChannel(th.BasicChannel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	found := false
+	for _, post := range posts.Posts {
+		if post.Message == scheduledPost.Message {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have created the post in the channel")
+	}
+
+	channel := th.CreatePrivateChannel()
+	droppedPost, resp := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: channel.Id, Message: "dropped " + model.NewId(), ScheduledAt: model.GetMillis() + 100})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RemoveUserFromChannel(channel.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	time.Sleep(200 * time.Millisecond)
+	app.PublishDueScheduledPosts()
+
+	eventHit = false
+	timeout = time.After(2 * time.Second)
+	for !eventHit {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_EPHEMERAL_MESSAGE {
+				if post := model.PostFromJson(strings.NewReader(event.Data["post"].(string))); strings.Contains(post.Message, droppedPost.Message) {
+					eventHit = true
+				}
+			}
+		case <-timeout:
+			t.Fatal("should have sent the message back to a user who can't post in the channel anymore")
+		}
+	}
+
+	if scheduledPosts, _ := Client.GetScheduledPostsForUser(th.BasicUser.Id); len(scheduledPosts) != 0 {
+		t.Fatal("should have removed the scheduled post that can't be published")
+	}
+}
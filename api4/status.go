@@ -17,8 +17,12 @@ func InitStatus() {
 	l4g.Debug(utils.T("api.status.init.debug"))
 
 	BaseRoutes.User.Handle("/status", ApiHandler(getUserStatus)).Methods("GET")
-	BaseRoutes.Users.Handle("/status/ids", ApiHandler(getUserStatusesByIds)).Methods("POST")
+	BaseRoutes.Users.Handle("/status/ids", ApiHandlerReadOnly(getUserStatusesByIds)).Methods("POST")
 	BaseRoutes.User.Handle("/status", ApiHandler(updateUserStatus)).Methods("PUT")
+	BaseRoutes.User.Handle("/status/custom", ApiHandler(updateUserCustomStatus)).Methods("PUT")
+	BaseRoutes.User.Handle("/status/custom", ApiHandler(removeUserCustomStatus)).Methods("DELETE")
+	BaseRoutes.User.Handle("/auto_responder", ApiSessionRequired(getUserAutoResponder)).Methods("GET")
+	BaseRoutes.User.Handle("/auto_responder", ApiSessionRequired(updateUserAutoResponder)).Methods("PUT")
 }
 
 func getUserStatus(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -84,6 +88,11 @@ func updateUserStatus(c *Context, w http.ResponseWriter, r *http.Request) {
 		app.SetStatusOffline(c.Params.UserId, true)
 	case "away":
 		app.SetStatusAwayIfNeeded(c.Params.UserId, true)
+	case "dnd":
+		if _, err := app.SetStatusDoNotDisturb(c.Params.UserId, status.DNDEndTime); err != nil {
+			c.Err = err
+			return
+		}
 	default:
 		c.SetInvalidParam("status")
 		return
@@ -91,3 +100,93 @@ func updateUserStatus(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	getUserStatus(c, w, r)
 }
+
+func updateUserCustomStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	customStatus := model.CustomStatusFromJson(r.Body)
+	if customStatus == nil {
+		c.SetInvalidParam("custom_status")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if status, err := app.SetCustomStatus(c.Params.UserId, customStatus); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(status.ToJson()))
+	}
+}
+
+func removeUserCustomStatus(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if _, err := app.ClearCustomStatus(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getUserAutoResponder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if responder, err := app.GetAutoResponder(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(responder.ToJson()))
+	}
+}
+
+func updateUserAutoResponder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	responder := model.AutoResponderFromJson(r.Body)
+	if responder == nil {
+		c.SetInvalidParam("auto_responder")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	responder.UserId = c.Params.UserId
+
+	if responder, err := app.SetAutoResponder(responder); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(responder.ToJson()))
+	}
+}
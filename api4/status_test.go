@@ -2,9 +2,11 @@ package api4
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
 )
 
 func TestGetUserStatus(t *testing.T) {
@@ -127,3 +129,177 @@ func TestUpdateUserStatus(t *testing.T) {
 		t.Fatal("Should return online status")
 	}
 }
+
+func TestUpdateUserStatusDoNotDisturb(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	endTime := model.GetMillis() + 60*60*1000
+	status, resp := Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{Status: model.STATUS_DND, DNDEndTime: endTime})
+	CheckNoError(t, resp)
+	if status.Status != model.STATUS_DND || !status.Manual || status.DNDEndTime != endTime {
+		t.Fatal("Should return dnd status")
+	}
+
+	// Activity doesn't end do not disturb
+	app.SetStatusOnline(th.BasicUser.Id, "", false)
+	if status, resp = Client.GetUserStatus(th.BasicUser.Id, ""); status.Status != model.STATUS_DND {
+		t.Fatal("Should have stayed in dnd")
+	}
+
+	_, resp = Client.UpdateUserStatus(th.BasicUser.Id, &model.Status{Status: model.STATUS_DND, DNDEndTime: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+
+	// Do not disturb ends once its end time has passed
+	expired := &model.Status{UserId: th.BasicUser.Id, Status: model.STATUS_DND, Manual: true, LastActivityAt: model.GetMillis(), DNDEndTime: model.GetMillis() - 1000}
+	app.AddStatusCache(expired)
+	store.Must(app.Srv.Store.Status().SaveOrUpdate(expired))
+
+	app.ClearExpiredStatuses()
+
+	status, resp = Client.GetUserStatus(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+	if status.Status != model.STATUS_ONLINE || status.Manual || status.DNDEndTime != 0 {
+		t.Fatal("Should have ended dnd")
+	}
+}
+
+func TestUpdateUserCustomStatus(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	customStatus := &model.CustomStatus{Emoji: "calendar", Text: "In a meeting", ExpiresAt: model.GetMillis() + 60*60*1000}
+	status, resp := Client.UpdateUserCustomStatus(model.ME, customStatus)
+	CheckNoError(t, resp)
+	if status.CustomStatusEmoji != customStatus.Emoji || status.CustomStatusText != customStatus.Text || status.CustomStatusExpiresAt != customStatus.ExpiresAt {
+		t.Fatal("Should return the custom status")
+	}
+
+	// The custom status is kept when the user goes offline
+	app.SetStatusOffline(th.BasicUser.Id, false)
+	if status, resp = Client.GetUserStatus(th.BasicUser.Id, ""); status.CustomStatusText != customStatus.Text {
+		t.Fatal("Should have kept the custom status")
+	}
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, &model.CustomStatus{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, &model.CustomStatus{Text: "expired", ExpiresAt: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser2.Id, customStatus)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateUserCustomStatus(th.BasicUser2.Id, customStatus)
+	CheckNoError(t, resp)
+
+	ok, resp := Client.RemoveUserCustomStatus(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("Should have returned ok")
+	}
+
+	if status, resp = Client.GetUserStatus(th.BasicUser.Id, ""); status.GetCustomStatus() != nil {
+		t.Fatal("Should have cleared the custom status")
+	}
+
+	_, resp = Client.RemoveUserCustomStatus(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	// Custom statuses are cleared once they expire
+	expired := &model.Status{UserId: th.BasicUser2.Id, Status: model.STATUS_ONLINE, CustomStatusText: "lunch", CustomStatusExpiresAt: model.GetMillis() - 1000}
+	app.AddStatusCache(expired)
+	store.Must(app.Srv.Store.Status().SaveOrUpdate(expired))
+
+	app.ClearExpiredStatuses()
+
+	if status, resp = th.SystemAdminClient.GetUserStatus(th.BasicUser2.Id, ""); status.GetCustomStatus() != nil || status.Status != model.STATUS_ONLINE {
+		t.Fatal("Should have cleared the expired custom status")
+	}
+
+	Client.Logout()
+	_, resp = Client.UpdateUserCustomStatus(th.BasicUser.Id, customStatus)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestUserAutoResponder(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	responder, resp := Client.GetUserAutoResponder(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	if responder.Active {
+		t.Fatal("should be turned off by default")
+	}
+
+	_, resp = Client.UpdateUserAutoResponder(th.BasicUser.Id, &model.AutoResponder{Active: true})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateUserAutoResponder(th.BasicUser2.Id, &model.AutoResponder{Active: true, Message: "I'm away"})
+	CheckForbiddenStatus(t, resp)
+
+	responder, resp = Client.UpdateUserAutoResponder(th.BasicUser.Id, &model.AutoResponder{Active: true, Message: "I'm away"})
+	CheckNoError(t, resp)
+
+	if !responder.Active || responder.UserId != th.BasicUser.Id {
+		t.Fatal("should have turned on the auto responder")
+	}
+
+	status, resp := Client.GetUserStatus(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	if status.Status != model.STATUS_OUT_OF_OFFICE {
+		t.Fatal("should be out of office")
+	}
+
+	th.LoginBasic2()
+
+	channel, resp := Client.CreateDirectChannel(th.BasicUser2.Id, th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	th.CreateMessagePostWithClient(Client, channel, "are you there?")
+	th.CreateMessagePostWithClient(Client, channel, "hello?")
+
+	// The auto response is sent in the background
+	time.Sleep(time.Second)
+
+	posts, resp := Client.GetPostsForChannel(channel.Id, 0, 60, "")
+	CheckNoError(t, resp)
+
+	responses := 0
+	for _, post := range posts.Posts {
+		if post.Type == model.POST_AUTO_RESPONDER {
+			if post.UserId != th.BasicUser.Id || post.Message != "I'm away" {
+				t.Fatal("bad auto response")
+			}
+			responses++
+		}
+	}
+
+	if responses != 1 {
+		t.Fatalf("should have replied once, got %v", responses)
+	}
+
+	th.LoginBasic()
+
+	_, resp = Client.UpdateUserAutoResponder(th.BasicUser.Id, &model.AutoResponder{Active: false})
+	CheckNoError(t, resp)
+
+	status, resp = Client.GetUserStatus(th.BasicUser.Id, "")
+	CheckNoError(t, resp)
+
+	if status.Status == model.STATUS_OUT_OF_OFFICE {
+		t.Fatal("shouldn't be out of office any more")
+	}
+
+	_, resp = th.SystemAdminClient.GetUserAutoResponder(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetUserAutoResponder(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
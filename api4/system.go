@@ -26,9 +26,17 @@ func InitSystem() {
 	BaseRoutes.ApiRoot.Handle("/audits", ApiSessionRequired(getAudits)).Methods("GET")
 	BaseRoutes.ApiRoot.Handle("/email/test", ApiSessionRequired(testEmail)).Methods("POST")
 	BaseRoutes.ApiRoot.Handle("/database/recycle", ApiSessionRequired(databaseRecycle)).Methods("POST")
+	BaseRoutes.ApiRoot.Handle("/database/slow_queries", ApiSessionRequired(getSlowQueries)).Methods("GET")
+	BaseRoutes.ApiRoot.Handle("/database/slow_queries", ApiSessionRequired(clearSlowQueries)).Methods("DELETE")
+	BaseRoutes.ApiRoot.Handle("/caches", ApiSessionRequired(getCacheStats)).Methods("GET")
 	BaseRoutes.ApiRoot.Handle("/caches/invalidate", ApiSessionRequired(invalidateCaches)).Methods("POST")
+	BaseRoutes.ApiRoot.Handle("/caches/{cache_name:[a-z_]+}/invalidate", ApiSessionRequired(invalidateCache)).Methods("POST")
 
 	BaseRoutes.ApiRoot.Handle("/logs", ApiSessionRequired(getLogs)).Methods("GET")
+
+	BaseRoutes.System.Handle("/outdated_clients", ApiSessionRequired(getOutdatedClients)).Methods("GET")
+
+	BaseRoutes.Analytics.Handle("/old", ApiSessionRequired(getAnalytics)).Methods("GET")
 }
 
 func getSystemPing(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -42,7 +50,7 @@ func testEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := app.TestEmail(c.Session.UserId, utils.Cfg)
+	err := app.TestEmail(c.Session.UserId, c.Config)
 	if err != nil {
 		c.Err = err
 		return
@@ -107,7 +115,13 @@ func getAudits(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	audits, err := app.GetAuditsPage("", c.Params.Page, c.Params.PerPage)
+	filter, invalid := model.AuditFilterFromQuery(r.URL.Query())
+	if len(invalid) > 0 {
+		c.SetInvalidParam(invalid)
+		return
+	}
+
+	audits, err := app.SearchAudits(filter, c.Params.Page, c.Params.PerPage)
 
 	if err != nil {
 		c.Err = err
@@ -129,6 +143,27 @@ func databaseRecycle(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func getSlowQueries(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	w.Write([]byte(model.SlowQueryListToJson(app.GetSlowQueries())))
+}
+
+func clearSlowQueries(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	app.ClearSlowQueries()
+
+	c.LogAudit("")
+	ReturnStatusOK(w)
+}
+
 func invalidateCaches(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
@@ -145,6 +180,71 @@ func invalidateCaches(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func getCacheStats(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	w.Write([]byte(model.CacheStatsListToJson(app.GetCacheStats())))
+}
+
+// getAnalytics returns the system analytics with the name in the request, for a team if one is given.
+func getAnalytics(c *Context, w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	teamId := r.URL.Query().Get("team_id")
+
+	if len(name) == 0 {
+		c.SetInvalidParam("name")
+		return
+	}
+
+	if len(teamId) > 0 && len(teamId) != 26 {
+		c.SetInvalidParam("team_id")
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	rows, err := app.GetAnalytics(name, teamId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if rows == nil {
+		c.SetInvalidParam("name")
+		return
+	}
+
+	w.Write([]byte(rows.ToJson()))
+}
+
+func invalidateCache(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireCacheName()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.InvalidateCache(c.Params.CacheName); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("cache_name=" + c.Params.CacheName)
+
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	ReturnStatusOK(w)
+}
+
 func getLogs(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
@@ -160,6 +260,15 @@ func getLogs(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(model.ArrayToJson(lines)))
 }
 
+func getOutdatedClients(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	w.Write([]byte(model.OutdatedClientListToJson(app.GetOutdatedClients())))
+}
+
 func getClientConfig(c *Context, w http.ResponseWriter, r *http.Request) {
 	format := r.URL.Query().Get("format")
 
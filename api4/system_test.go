@@ -219,6 +219,54 @@ func TestGetAudits(t *testing.T) {
 	CheckUnauthorizedStatus(t, resp)
 }
 
+func TestSearchAudits(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := th.SystemAdminClient.UpdateUserRoles(th.BasicUser.Id, model.ROLE_SYSTEM_USER.Id)
+	CheckNoError(t, resp)
+
+	filter := &model.AuditFilter{EventName: "updateUserRoles", TargetType: model.AUDIT_TARGET_USER, TargetId: th.BasicUser.Id}
+	audits, resp := th.SystemAdminClient.SearchAudits(filter, 0, 100)
+	CheckNoError(t, resp)
+
+	if len(audits) != 1 {
+		t.Fatal("should have returned the audit of the role update", len(audits))
+	} else if audits[0].ActorId != th.SystemAdminUser.Id || audits[0].UserId != th.BasicUser.Id || audits[0].Metadata["roles"] != model.ROLE_SYSTEM_USER.Id {
+		t.Fatal("should have recorded who updated the roles of whom", audits[0])
+	}
+
+	audits, resp = th.SystemAdminClient.SearchAudits(&model.AuditFilter{UserId: th.SystemAdminUser.Id, Since: audits[0].CreateAt}, 0, 100)
+	CheckNoError(t, resp)
+
+	if len(audits) == 0 {
+		t.Fatal("should have returned the audits of the admin")
+	}
+
+	for _, audit := range audits {
+		if audit.UserId != th.SystemAdminUser.Id && audit.ActorId != th.SystemAdminUser.Id {
+			t.Fatal("should only have returned the audits of the admin")
+		}
+	}
+
+	audits, resp = th.SystemAdminClient.SearchAudits(&model.AuditFilter{EventName: "updateUserRoles", TargetId: th.BasicUser.Id, Until: 1}, 0, 100)
+	CheckNoError(t, resp)
+
+	if len(audits) != 0 {
+		t.Fatal("should have filtered by end time")
+	}
+
+	if _, err := th.SystemAdminClient.DoApiGet("/audits?user_id=junk", ""); err == nil {
+		t.Fatal("should have failed with an invalid user id")
+	} else if err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have been a bad request", err.StatusCode)
+	}
+
+	_, resp = Client.SearchAudits(filter, 0, 100)
+	CheckForbiddenStatus(t, resp)
+}
+
 func TestEmailTest(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -278,6 +326,98 @@ func TestInvalidateCaches(t *testing.T) {
 	}
 }
 
+func TestGetCacheStats(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetCacheStats()
+	CheckForbiddenStatus(t, resp)
+
+	stats, resp := th.SystemAdminClient.GetCacheStats()
+	CheckNoError(t, resp)
+
+	found := false
+	for _, s := range stats {
+		if s.Name == "status" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned the status cache")
+	}
+}
+
+func TestGetAnalyticsOld(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetAnalyticsOld("standard", "")
+	CheckForbiddenStatus(t, resp)
+
+	rows, resp := th.SystemAdminClient.GetAnalyticsOld("standard", "")
+	CheckNoError(t, resp)
+
+	if len(rows) == 0 || rows[0].Name != "channel_open_count" {
+		t.Fatal("should have returned the standard analytics")
+	}
+
+	if _, err := app.SaveReactionForPost(&model.Reaction{UserId: th.BasicUser.Id, PostId: th.BasicPost.Id, EmojiName: "smile"}, th.BasicChannel); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, resp = th.SystemAdminClient.GetAnalyticsOld("reaction_counts", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(rows) != 3 || rows[0].Name != "reaction_count" || rows[0].Value != 1 || rows[1].Value != 1 {
+		t.Fatal("should have counted the reaction in the team")
+	} else if rows[2].Name != "post_with_reactions_ratio" || rows[2].Value <= 0 || rows[2].Value > 1 {
+		t.Fatal("should have returned the ratio of posts with reactions", rows[2].Value)
+	}
+
+	_, resp = th.SystemAdminClient.GetAnalyticsOld("reaction_counts_day", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	rows, resp = th.SystemAdminClient.GetAnalyticsOld("top_reactions", th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(rows) != 1 || rows[0].Name != "smile" || rows[0].Value != 1 {
+		t.Fatal("should have returned the top reactions in the team")
+	}
+
+	_, resp = th.SystemAdminClient.GetAnalyticsOld("top_reactions", "")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetAnalyticsOld("junk", "")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetAnalyticsOld("standard", "junk")
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestInvalidateCache(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	flag, resp := Client.InvalidateCache("status")
+	CheckForbiddenStatus(t, resp)
+	if flag {
+		t.Fatal("should not clean the cache due no permission.")
+	}
+
+	flag, resp = th.SystemAdminClient.InvalidateCache("status")
+	CheckNoError(t, resp)
+	if !flag {
+		t.Fatal("should clean the cache")
+	}
+
+	_, resp = th.SystemAdminClient.InvalidateCache("junk")
+	CheckNotFoundStatus(t, resp)
+}
+
 func TestGetLogs(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -317,3 +457,77 @@ func TestGetLogs(t *testing.T) {
 	_, resp = Client.GetLogs(0, 10)
 	CheckUnauthorizedStatus(t, resp)
 }
+
+func TestClientVersionEnforcement(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	minVersion := *utils.Cfg.ClientRequirementsSettings.DesktopMinVersion
+	defer func() {
+		*utils.Cfg.ClientRequirementsSettings.DesktopMinVersion = minVersion
+	}()
+	*utils.Cfg.ClientRequirementsSettings.DesktopMinVersion = "3.7.0"
+
+	Client.ClientVersion = "desktop/3.6.2"
+
+	_, resp := Client.GetMe("")
+	CheckErrorMessage(t, resp, "app.client_version.upgrade_required.app_error")
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Fatal("wrong status code", resp.StatusCode)
+	}
+
+	_, resp = Client.GetPing()
+	CheckNoError(t, resp)
+
+	Client.ClientVersion = "desktop/3.7.0"
+	_, resp = Client.GetMe("")
+	CheckNoError(t, resp)
+
+	Client.ClientVersion = "mobile/1.0.0"
+	_, resp = Client.GetMe("")
+	CheckNoError(t, resp)
+
+	outdated, resp := th.SystemAdminClient.GetOutdatedClients()
+	CheckNoError(t, resp)
+
+	found := false
+	for _, client := range outdated {
+		if client.Platform == model.CLIENT_PLATFORM_DESKTOP && client.Version == "3.6.2" {
+			found = true
+
+			if client.MinVersion != "3.7.0" || client.LastUserId != th.BasicUser.Id {
+				t.Fatal("recorded the wrong attempt", client)
+			}
+		}
+	}
+
+	if !found {
+		t.Fatal("should have recorded the outdated client")
+	}
+
+	Client.ClientVersion = ""
+	_, resp = Client.GetOutdatedClients()
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestSlowQueries(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetSlowQueries()
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.ClearSlowQueries()
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ClearSlowQueries()
+	CheckNoError(t, resp)
+
+	queries, resp := th.SystemAdminClient.GetSlowQueries()
+	CheckNoError(t, resp)
+	if queries == nil {
+		t.Fatal("should have returned the slow queries")
+	}
+}
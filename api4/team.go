@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/app"
@@ -24,7 +25,7 @@ func InitTeam() {
 
 	BaseRoutes.Teams.Handle("", ApiSessionRequired(createTeam)).Methods("POST")
 	BaseRoutes.Teams.Handle("", ApiSessionRequired(getAllTeams)).Methods("GET")
-	BaseRoutes.Teams.Handle("/search", ApiSessionRequired(searchTeams)).Methods("POST")
+	BaseRoutes.Teams.Handle("/search", ApiSessionRequiredReadOnly(searchTeams)).Methods("POST")
 	BaseRoutes.TeamsForUser.Handle("", ApiSessionRequired(getTeamsForUser)).Methods("GET")
 	BaseRoutes.TeamsForUser.Handle("/unread", ApiSessionRequired(getTeamsUnreadForUser)).Methods("GET")
 
@@ -32,12 +33,17 @@ func InitTeam() {
 	BaseRoutes.Team.Handle("", ApiSessionRequired(updateTeam)).Methods("PUT")
 	BaseRoutes.Team.Handle("", ApiSessionRequired(softDeleteTeam)).Methods("DELETE")
 	BaseRoutes.Team.Handle("/patch", ApiSessionRequired(patchTeam)).Methods("PUT")
+	BaseRoutes.Team.Handle("/name", ApiSessionRequired(updateTeamName)).Methods("PUT")
 	BaseRoutes.Team.Handle("/stats", ApiSessionRequired(getTeamStats)).Methods("GET")
 	BaseRoutes.TeamMembers.Handle("", ApiSessionRequired(getTeamMembers)).Methods("GET")
-	BaseRoutes.TeamMembers.Handle("/ids", ApiSessionRequired(getTeamMembersByIds)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/ids", ApiSessionRequiredReadOnly(getTeamMembersByIds)).Methods("POST")
 	BaseRoutes.TeamMembersForUser.Handle("", ApiSessionRequired(getTeamMembersForUser)).Methods("GET")
 	BaseRoutes.TeamMembers.Handle("", ApiSessionRequired(addTeamMember)).Methods("POST")
 	BaseRoutes.TeamMembers.Handle("/batch", ApiSessionRequired(addTeamMembers)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/batch/add", ApiSessionRequired(addTeamMembersBatch)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/batch/remove", ApiSessionRequired(removeTeamMembersBatch)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/batch/roles", ApiSessionRequired(updateTeamMembersRolesBatch)).Methods("POST")
+	BaseRoutes.TeamMembers.Handle("/batch/jobs/{job_id:[A-Za-z0-9]+}", ApiSessionRequired(getTeamMembersBatchJob)).Methods("GET")
 	BaseRoutes.TeamMember.Handle("", ApiSessionRequired(removeTeamMember)).Methods("DELETE")
 
 	BaseRoutes.TeamForUser.Handle("/unread", ApiSessionRequired(getTeamUnread)).Methods("GET")
@@ -49,6 +55,7 @@ func InitTeam() {
 
 	BaseRoutes.Team.Handle("/import", ApiSessionRequired(importTeam)).Methods("POST")
 	BaseRoutes.Team.Handle("/invite/email", ApiSessionRequired(inviteUsersToTeam)).Methods("POST")
+	BaseRoutes.Team.Handle("/invite-guests/email", ApiSessionRequired(inviteGuestsToTeam)).Methods("POST")
 }
 
 func createTeam(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -99,7 +106,7 @@ func getTeamByName(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if team, err := app.GetTeamByName(c.Params.TeamName); err != nil {
+	if team, err := app.ResolveTeamByName(c.Params.TeamName); err != nil {
 		c.Err = err
 		return
 	} else {
@@ -108,6 +115,10 @@ func getTeamByName(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if team.Name != c.Params.TeamName {
+			w.Header().Set(model.HEADER_REDIRECTED_FROM, c.Params.TeamName)
+		}
+
 		w.Write([]byte(team.ToJson()))
 		return
 	}
@@ -168,10 +179,39 @@ func patchTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c.LogAudit("")
+	c.LogAuditEvent(model.AUDIT_TARGET_TEAM, patchedTeam.Id, nil)
 	w.Write([]byte(patchedTeam.ToJson()))
 }
 
+func updateTeamName(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	name := props["name"]
+	if len(name) == 0 {
+		c.SetInvalidParam("name")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	team, err := app.UpdateTeamName(c.Params.TeamId, name)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_TEAM, team.Id, map[string]interface{}{"name": name})
+	w.Write([]byte(team.ToJson()))
+}
+
 func softDeleteTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireTeamId()
 	if c.Err != nil {
@@ -418,6 +458,130 @@ func addTeamMembers(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(model.TeamMembersToJson(members)))
 }
 
+func addTeamMembersBatch(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	batch := model.TeamMembersBatchFromJson(r.Body)
+	if batch == nil {
+		c.SetInvalidParam("team_members_batch")
+		return
+	}
+
+	if len(batch.Roles) > 0 && !model.IsValidUserRoles(batch.Roles) {
+		c.SetInvalidParam("team_member_roles")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_ADD_USER_TO_TEAM) {
+		c.SetPermissionError(model.PERMISSION_ADD_USER_TO_TEAM)
+		return
+	}
+
+	if len(batch.Roles) > 0 && !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM_ROLES) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM_ROLES)
+		return
+	}
+
+	startTeamMembersBatchJob(c, w, model.TEAM_MEMBERS_BATCH_ACTION_ADD, batch)
+}
+
+func removeTeamMembersBatch(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	batch := model.TeamMembersBatchFromJson(r.Body)
+	if batch == nil {
+		c.SetInvalidParam("team_members_batch")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_REMOVE_USER_FROM_TEAM) {
+		c.SetPermissionError(model.PERMISSION_REMOVE_USER_FROM_TEAM)
+		return
+	}
+
+	batch.Roles = ""
+	startTeamMembersBatchJob(c, w, model.TEAM_MEMBERS_BATCH_ACTION_REMOVE, batch)
+}
+
+func updateTeamMembersRolesBatch(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	batch := model.TeamMembersBatchFromJson(r.Body)
+	if batch == nil {
+		c.SetInvalidParam("team_members_batch")
+		return
+	}
+
+	if len(batch.Roles) == 0 || !model.IsValidUserRoles(batch.Roles) {
+		c.SetInvalidParam("team_member_roles")
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM_ROLES) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM_ROLES)
+		return
+	}
+
+	startTeamMembersBatchJob(c, w, model.TEAM_MEMBERS_BATCH_ACTION_UPDATE_ROLES, batch)
+}
+
+func startTeamMembersBatchJob(c *Context, w http.ResponseWriter, action string, batch *model.TeamMembersBatch) {
+	job := &model.TeamMembersBatchJob{
+		CreatorId: c.Session.UserId,
+		TeamId:    c.Params.TeamId,
+		Action:    action,
+		Roles:     batch.Roles,
+	}
+
+	job, err := app.StartTeamMembersBatchJob(job, batch.UserIds)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("action=" + action + " job_id=" + job.Id + " total=" + strconv.Itoa(job.Total))
+
+	// Large batches are still being processed and can be followed through the returned job
+	if !job.IsDone() {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	w.Write([]byte(job.ToJson()))
+}
+
+func getTeamMembersBatchJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId().RequireJobId()
+	if c.Err != nil {
+		return
+	}
+
+	job, err := app.GetTeamMembersBatchJob(c.Params.JobId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if job.TeamId != c.Params.TeamId {
+		c.Err = model.NewAppError("getTeamMembersBatchJob", "api.team.get_members_batch_job.team_id.app_error", nil, "job_id="+job.Id, http.StatusNotFound)
+		return
+	}
+
+	if job.CreatorId != c.Session.UserId && !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	w.Write([]byte(job.ToJson()))
+}
+
 func removeTeamMember(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireTeamId().RequireUserId()
 	if c.Err != nil {
@@ -681,3 +845,42 @@ func inviteUsersToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ReturnStatusOK(w)
 }
+
+func inviteGuestsToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_INVITE_GUEST) {
+		c.SetPermissionError(model.PERMISSION_INVITE_GUEST)
+		return
+	}
+
+	invite := model.GuestsInviteFromJson(r.Body)
+	if invite == nil {
+		c.SetInvalidParam("guests_invite")
+		return
+	}
+
+	if err := invite.IsValid(); err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, channelId := range invite.Channels {
+		if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_READ_CHANNEL) {
+			c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+			return
+		}
+	}
+
+	if err := app.InviteGuestsToChannels(c.Params.TeamId, invite, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("emails=" + strings.Join(invite.Emails, ","))
+
+	ReturnStatusOK(w)
+}
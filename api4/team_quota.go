@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitTeamQuota() {
+	l4g.Debug(utils.T("api.team_quota.init.debug"))
+
+	BaseRoutes.Team.Handle("/quota", ApiSessionRequired(getTeamQuota)).Methods("GET")
+	BaseRoutes.Team.Handle("/quota", ApiSessionRequired(updateTeamQuota)).Methods("PUT")
+	BaseRoutes.Team.Handle("/usage", ApiSessionRequired(getTeamUsage)).Methods("GET")
+}
+
+func getTeamQuota(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	quota, err := app.GetTeamQuota(c.Params.TeamId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(quota.ToJson()))
+}
+
+func updateTeamQuota(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	quota := model.TeamQuotaFromJson(r.Body)
+	if quota == nil {
+		c.SetInvalidParam("quota")
+		return
+	}
+
+	// Quotas are set by whoever hosts the server, so team admins can see them but not change them
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := app.GetTeam(c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	}
+
+	quota.TeamId = c.Params.TeamId
+
+	rquota, err := app.UpdateTeamQuota(quota)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAuditEvent(model.AUDIT_TARGET_TEAM, rquota.TeamId, map[string]interface{}{
+		"max_posts_per_month": rquota.MaxPostsPerMonth,
+		"max_storage_bytes":   rquota.MaxStorageBytes,
+		"max_integrations":    rquota.MaxIntegrations,
+	})
+	w.Write([]byte(rquota.ToJson()))
+}
+
+func getTeamUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_TEAM)
+		return
+	}
+
+	usage, err := app.GetTeamUsage(c.Params.TeamId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(usage.ToJson()))
+}
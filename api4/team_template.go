@@ -0,0 +1,191 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitTeamTemplate() {
+	l4g.Debug(utils.T("api.team_template.init.debug"))
+
+	BaseRoutes.Team.Handle("/template", ApiSessionRequired(saveTeamAsTemplate)).Methods("POST")
+	BaseRoutes.Team.Handle("/clone", ApiSessionRequired(cloneTeam)).Methods("POST")
+
+	BaseRoutes.TeamTemplates.Handle("", ApiSessionRequired(getTeamTemplates)).Methods("GET")
+	BaseRoutes.TeamTemplate.Handle("", ApiSessionRequired(getTeamTemplate)).Methods("GET")
+	BaseRoutes.TeamTemplate.Handle("", ApiSessionRequired(deleteTeamTemplate)).Methods("DELETE")
+	BaseRoutes.TeamTemplate.Handle("/clone", ApiSessionRequired(createTeamFromTemplate)).Methods("POST")
+
+	BaseRoutes.TeamCloneJobs.Handle("/{job_id:[A-Za-z0-9]+}", ApiSessionRequired(getTeamCloneJob)).Methods("GET")
+}
+
+func saveTeamAsTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	template := model.TeamTemplateFromJson(r.Body)
+	if template == nil {
+		c.SetInvalidParam("template")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	template.Id = ""
+	template.CreatorId = c.Session.UserId
+
+	if rtemplate, err := app.SaveTeamAsTemplate(c.Params.TeamId, template); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("template_id=" + rtemplate.Id)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rtemplate.ToJson()))
+	}
+}
+
+func cloneTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	team := model.TeamFromJson(r.Body)
+	if team == nil {
+		c.SetInvalidParam("team")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if job, err := app.CloneTeam(c.Params.TeamId, team, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("job_id=" + job.Id)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(job.ToJson()))
+	}
+}
+
+func getTeamTemplates(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if templates, err := app.GetTeamTemplatesPage(c.Params.Page, c.Params.PerPage); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.TeamTemplateListToJson(templates)))
+	}
+}
+
+func getTeamTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTemplateId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if template, err := app.GetTeamTemplate(c.Params.TemplateId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(template.ToJson()))
+	}
+}
+
+func deleteTeamTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTemplateId()
+	if c.Err != nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := app.DeleteTeamTemplate(c.Params.TemplateId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
+
+func createTeamFromTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTemplateId()
+	if c.Err != nil {
+		return
+	}
+
+	team := model.TeamFromJson(r.Body)
+	if team == nil {
+		c.SetInvalidParam("team")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if job, err := app.CreateTeamFromTemplate(c.Params.TemplateId, team, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("job_id=" + job.Id)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(job.ToJson()))
+	}
+}
+
+func getTeamCloneJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireJobId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if job, err := app.GetTeamCloneJob(c.Params.JobId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(job.ToJson()))
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func waitForTeamCloneJob(t *testing.T, client *model.Client4, jobId string) *model.TeamCloneJob {
+	for i := 0; i < 50; i++ {
+		job, resp := client.GetTeamCloneJob(jobId)
+		CheckNoError(t, resp)
+
+		if job.Status == model.TEAM_CLONE_JOB_STATUS_FINISHED || job.Status == model.TEAM_CLONE_JOB_STATUS_FAILED {
+			return job
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatal("clone job did not finish")
+	return nil
+}
+
+func TestCloneTeam(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableCommands = enableCommands
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	cmd, err := app.CreateCommand(&model.Command{CreatorId: th.TeamAdminUser.Id, TeamId: th.BasicTeam.Id, Trigger: "ticket", Method: model.COMMAND_METHOD_POST, URL: "http://example.com/ticket"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := app.CreateIncomingWebhookForChannel(th.TeamAdminUser.Id, th.BasicChannel, &model.IncomingWebhook{ChannelId: th.BasicChannel.Id, DisplayName: "alerts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	team := &model.Team{DisplayName: "Clone", Name: GenerateTestTeamName()}
+
+	_, resp := Client.CloneTeam(th.BasicTeam.Id, team)
+	CheckForbiddenStatus(t, resp)
+
+	job, resp := th.SystemAdminClient.CloneTeam(th.BasicTeam.Id, team)
+	CheckNoError(t, resp)
+
+	if job.SourceTeamId != th.BasicTeam.Id || len(job.TeamId) != 26 {
+		t.Fatal("should have created the team and the job")
+	}
+
+	if job = waitForTeamCloneJob(t, th.SystemAdminClient, job.Id); job.Status != model.TEAM_CLONE_JOB_STATUS_FINISHED {
+		t.Fatal("clone job failed: " + job.Error)
+	}
+
+	if channel, err := app.GetChannelByName(th.BasicPrivateChannel.Name, job.TeamId); err != nil {
+		t.Fatal(err)
+	} else if channel.Type != model.CHANNEL_PRIVATE || channel.DisplayName != th.BasicPrivateChannel.DisplayName {
+		t.Fatal("should have copied the channel")
+	}
+
+	if member, err := app.GetTeamMember(job.TeamId, th.TeamAdminUser.Id); err != nil {
+		t.Fatal(err)
+	} else if !model.IsInRole(member.Roles, model.ROLE_TEAM_ADMIN.Id) {
+		t.Fatal("should have copied the team admin role")
+	}
+
+	if _, err := app.GetTeamMember(job.TeamId, th.BasicUser.Id); err == nil {
+		t.Fatal("should not have copied regular members")
+	}
+
+	if cmds, err := app.ListTeamCommands(job.TeamId); err != nil {
+		t.Fatal(err)
+	} else if len(cmds) != 1 || cmds[0].Trigger != cmd.Trigger || cmds[0].Token == cmd.Token {
+		t.Fatal("should have copied the command with a new token")
+	}
+
+	if hooks, err := app.GetIncomingWebhooksForTeamPage(job.TeamId, 0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(hooks) != 1 || hooks[0].DisplayName != hook.DisplayName || hooks[0].Id == hook.Id {
+		t.Fatal("should have copied the incoming webhook with a new id")
+	}
+
+	_, resp = th.SystemAdminClient.CloneTeam(th.BasicTeam.Id, team)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CloneTeam(model.NewId(), &model.Team{DisplayName: "Clone", Name: GenerateTestTeamName()})
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetTeamCloneJob(job.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetTeamCloneJob(model.NewId())
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestTeamTemplates(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.SaveTeamAsTemplate(th.BasicTeam.Id, &model.TeamTemplate{Name: "Basic"})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.SaveTeamAsTemplate(th.BasicTeam.Id, &model.TeamTemplate{})
+	CheckBadRequestStatus(t, resp)
+
+	template, resp := th.SystemAdminClient.SaveTeamAsTemplate(th.BasicTeam.Id, &model.TeamTemplate{Name: "Basic", Description: "The basic team"})
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if template.SourceTeamId != th.BasicTeam.Id || template.CreatorId != th.SystemAdminUser.Id || len(template.Content.Channels) == 0 {
+		t.Fatal("should have captured the team structure")
+	}
+
+	templates, resp := th.SystemAdminClient.GetTeamTemplates(0, 1000)
+	CheckNoError(t, resp)
+
+	found := false
+	for _, tt := range templates {
+		if tt.Id == template.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have returned the template")
+	}
+
+	_, resp = Client.GetTeamTemplates(0, 60)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetTeamTemplate(template.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetTeamTemplate(template.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.CreateTeamFromTemplate(template.Id, &model.Team{DisplayName: "From Template", Name: GenerateTestTeamName()})
+	CheckForbiddenStatus(t, resp)
+
+	job, resp := th.SystemAdminClient.CreateTeamFromTemplate(template.Id, &model.Team{DisplayName: "From Template", Name: GenerateTestTeamName()})
+	CheckNoError(t, resp)
+
+	if job.TemplateId != template.Id {
+		t.Fatal("should have created the job from the template")
+	}
+
+	if job = waitForTeamCloneJob(t, th.SystemAdminClient, job.Id); job.Status != model.TEAM_CLONE_JOB_STATUS_FINISHED {
+		t.Fatal("clone job failed: " + job.Error)
+	}
+
+	if _, err := app.GetChannelByName(th.BasicChannel2.Name, job.TeamId); err != nil {
+		t.Fatal("should have created the template's channels")
+	}
+
+	_, resp = Client.DeleteTeamTemplate(template.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteTeamTemplate(template.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.GetTeamTemplate(template.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateTeamFromTemplate(template.Id, &model.Team{DisplayName: "From Template", Name: GenerateTestTeamName()})
+	CheckNotFoundStatus(t, resp)
+}
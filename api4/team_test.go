@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
@@ -448,6 +449,59 @@ func TestGetTeamByName(t *testing.T) {
 	CheckForbiddenStatus(t, resp)
 }
 
+func TestUpdateTeamName(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	team := th.BasicTeam
+	oldName := team.Name
+
+	newName := GenerateTestTeamName()
+
+	_, resp := Client.UpdateTeamName(team.Id, newName)
+	CheckForbiddenStatus(t, resp)
+
+	rteam, resp := th.SystemAdminClient.UpdateTeamName(team.Id, newName)
+	CheckNoError(t, resp)
+
+	if rteam.Name != newName {
+		t.Fatal("name should have been updated")
+	}
+
+	_, resp = th.SystemAdminClient.UpdateTeamName(team.Id, "junk name")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateTeamName(team.Id, "")
+	CheckBadRequestStatus(t, resp)
+
+	rteam, resp = Client.GetTeamByName(oldName, "")
+	CheckNoError(t, resp)
+
+	if rteam.Id != team.Id {
+		t.Fatal("the old name should resolve to the renamed team")
+	}
+
+	if resp.RedirectedFrom != oldName {
+		t.Fatal("should have been told about the redirect")
+	}
+
+	_, resp = Client.GetTeamByName(newName, "")
+	CheckNoError(t, resp)
+
+	if resp.RedirectedFrom != "" {
+		t.Fatal("shouldn't have been redirected")
+	}
+
+	gracePeriod := *utils.Cfg.TeamSettings.NameRedirectGracePeriodDays
+	defer func() {
+		*utils.Cfg.TeamSettings.NameRedirectGracePeriodDays = gracePeriod
+	}()
+	*utils.Cfg.TeamSettings.NameRedirectGracePeriodDays = 0
+
+	_, resp = Client.GetTeamByName(oldName, "")
+	CheckNotFoundStatus(t, resp)
+}
+
 func TestSearchAllTeams(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1079,6 +1133,119 @@ func TestAddTeamMembers(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestTeamMembersBatch(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	team := th.BasicTeam
+	user1 := th.CreateUser()
+	user2 := th.CreateUser()
+	missingId := GenerateTestId()
+	userIds := []string{user1.Id, user2.Id, missingId}
+
+	// Regular users can add members but can't assign roles.
+	_, resp := Client.AddTeamMembersBatch(team.Id, userIds, "team_user team_admin")
+	CheckForbiddenStatus(t, resp)
+
+	job, resp := Client.AddTeamMembersBatch(team.Id, userIds, "")
+	CheckNoError(t, resp)
+	CheckOKStatus(t, resp)
+
+	if job.Status != model.TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED || job.Total != 3 || job.Succeeded != 2 || job.FailedCount != 1 {
+		t.Fatal("should have processed the batch", job)
+	}
+
+	if _, ok := job.Failures[missingId]; !ok {
+		t.Fatal("should have reported the missing user")
+	}
+
+	if _, err := app.GetTeamMember(team.Id, user2.Id); err != nil {
+		t.Fatal("should have added the user")
+	}
+
+	_, resp = Client.AddTeamMembersBatch(team.Id, []string{}, "")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.AddTeamMembersBatch(team.Id, []string{"junk"}, "")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateTeamMembersRolesBatch(team.Id, userIds, "team_user team_admin")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.RemoveTeamMembersBatch(team.Id, userIds)
+	CheckForbiddenStatus(t, resp)
+
+	rjob, resp := Client.GetTeamMembersBatchJob(team.Id, job.Id)
+	CheckNoError(t, resp)
+
+	if rjob.Id != job.Id || rjob.Succeeded != 2 {
+		t.Fatal("should have returned the job")
+	}
+
+	_, resp = Client.GetTeamMembersBatchJob(team.Id, GenerateTestId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateTeamMembersRolesBatch(team.Id, userIds, "junk")
+	CheckBadRequestStatus(t, resp)
+
+	job, resp = th.SystemAdminClient.UpdateTeamMembersRolesBatch(team.Id, userIds, "team_user team_admin")
+	CheckNoError(t, resp)
+
+	if job.Succeeded != 2 || job.FailedCount != 1 {
+		t.Fatal("should have updated the roles", job)
+	}
+
+	if member, _ := app.GetTeamMember(team.Id, user1.Id); member.Roles != "team_user team_admin" {
+		t.Fatal("should have updated the roles")
+	}
+
+	otherTeam := th.CreateTeamWithClient(th.SystemAdminClient)
+	_, resp = th.SystemAdminClient.GetTeamMembersBatchJob(otherTeam.Id, job.Id)
+	CheckNotFoundStatus(t, resp)
+
+	job, resp = th.SystemAdminClient.RemoveTeamMembersBatch(team.Id, userIds)
+	CheckNoError(t, resp)
+
+	if job.Succeeded != 2 || job.FailedCount != 1 {
+		t.Fatal("should have removed the members", job)
+	}
+
+	if member, err := app.GetTeamMember(team.Id, user1.Id); err == nil && member.DeleteAt == 0 {
+		t.Fatal("should have removed the member")
+	}
+
+	// Large batches are processed in the background.
+	largeIds := []string{user1.Id}
+	for i := 0; i < app.TEAM_MEMBERS_BATCH_SYNC_MAX_SIZE; i++ {
+		largeIds = append(largeIds, GenerateTestId())
+	}
+
+	job, resp = th.SystemAdminClient.AddTeamMembersBatch(team.Id, largeIds, "")
+	CheckNoError(t, resp)
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatal("should have accepted the batch")
+	}
+
+	for i := 0; i < 50 && !job.IsDone(); i++ {
+		time.Sleep(100 * time.Millisecond)
+		job, resp = th.SystemAdminClient.GetTeamMembersBatchJob(team.Id, job.Id)
+		CheckNoError(t, resp)
+	}
+
+	if job.Status != model.TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED || job.Succeeded != 1 || job.FailedCount != app.TEAM_MEMBERS_BATCH_SYNC_MAX_SIZE {
+		t.Fatal("should have processed the batch in the background", job)
+	}
+
+	tooManyIds := []string{}
+	for i := 0; i <= app.TEAM_MEMBERS_BATCH_MAX_SIZE; i++ {
+		tooManyIds = append(tooManyIds, GenerateTestId())
+	}
+
+	_, resp = th.SystemAdminClient.RemoveTeamMembersBatch(team.Id, tooManyIds)
+	CheckBadRequestStatus(t, resp)
+}
+
 func TestRemoveTeamMember(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1420,3 +1587,109 @@ func TestInviteUsersToTeam(t *testing.T) {
 		}
 	}
 }
+
+func TestInviteGuestsToTeam(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+
+	enableGuestAccounts := *utils.Cfg.TeamSettings.EnableGuestAccounts
+	defer func() {
+		*utils.Cfg.TeamSettings.EnableGuestAccounts = enableGuestAccounts
+	}()
+
+	invite := &model.GuestsInvite{Emails: []string{GenerateTestEmail()}, Channels: []string{th.BasicChannel.Id}}
+
+	*utils.Cfg.TeamSettings.EnableGuestAccounts = false
+	_, resp := th.SystemAdminClient.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.TeamSettings.EnableGuestAccounts = true
+	_, resp = th.Client.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.InviteGuestsToTeam(th.BasicTeam.Id, invite)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should return true")
+	}
+
+	_, resp = th.SystemAdminClient.InviteGuestsToTeam(th.BasicTeam.Id, &model.GuestsInvite{Emails: invite.Emails})
+	CheckBadRequestStatus(t, resp)
+}
+
+func TestTeamQuota(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	team := th.BasicTeam
+
+	_, resp := Client.GetTeamQuota(team.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.GetTeamUsage(team.Id)
+	CheckForbiddenStatus(t, resp)
+
+	quota, resp := th.SystemAdminClient.GetTeamQuota(team.Id)
+	CheckNoError(t, resp)
+
+	if quota.MaxPostsPerMonth != 0 || quota.MaxStorageBytes != 0 || quota.MaxIntegrations != 0 {
+		t.Fatal("a team shouldn't have any limits by default")
+	}
+
+	// Posts are counted in the background
+	time.Sleep(100 * time.Millisecond)
+
+	usage, resp := th.SystemAdminClient.GetTeamUsage(team.Id)
+	CheckNoError(t, resp)
+
+	if usage.Month != model.CurrentUsageMonth() || usage.PostCount == 0 {
+		t.Fatal("the posts made by the test setup should have been counted")
+	}
+
+	_, resp = Client.UpdateTeamQuota(team.Id, &model.TeamQuota{MaxPostsPerMonth: usage.PostCount + 1})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateTeamQuota(team.Id, &model.TeamQuota{MaxPostsPerMonth: -1})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateTeamQuota(model.NewId(), &model.TeamQuota{MaxPostsPerMonth: 1})
+	CheckNotFoundStatus(t, resp)
+
+	quota, resp = th.SystemAdminClient.UpdateTeamQuota(team.Id, &model.TeamQuota{MaxPostsPerMonth: usage.PostCount + 1, MaxIntegrations: 1})
+	CheckNoError(t, resp)
+
+	if quota.TeamId != team.Id || quota.MaxPostsPerMonth != usage.PostCount+1 {
+		t.Fatal("quota wasn't saved")
+	}
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "under the limit"})
+	CheckNoError(t, resp)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "over the limit"})
+	CheckForbiddenStatus(t, resp)
+
+	if resp.Error.Id != "app.team_quota.posts_exceeded.app_error" {
+		t.Fatal("should have said that the post limit was reached")
+	}
+
+	enableIncomingHooks := utils.Cfg.ServiceSettings.EnableIncomingWebhooks
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableIncomingWebhooks = enableIncomingHooks
+	}()
+	utils.Cfg.ServiceSettings.EnableIncomingWebhooks = true
+
+	_, resp = th.SystemAdminClient.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: th.BasicChannel.Id})
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.CreateIncomingWebhook(&model.IncomingWebhook{ChannelId: th.BasicChannel.Id})
+	CheckForbiddenStatus(t, resp)
+
+	usage, resp = th.SystemAdminClient.GetTeamUsage(team.Id)
+	CheckNoError(t, resp)
+
+	if usage.IntegrationCount != 1 {
+		t.Fatal("should have counted the webhook")
+	}
+}
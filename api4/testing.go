@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitTesting() {
+	l4g.Debug(utils.T("api.testing.init.debug"))
+
+	BaseRoutes.Testing.Handle("/fixtures", ApiSessionRequired(createFixtures)).Methods("POST")
+}
+
+func createFixtures(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !c.Config.ServiceSettings.EnableTesting || !*c.Config.ServiceSettings.EnableDeveloper {
+		c.Err = model.NewAppError("createFixtures", "api.testing.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	request := model.FixtureRequestFromJson(r.Body)
+	if request == nil {
+		c.SetInvalidParam("fixture_request")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if result, err := app.CreateFixtures(request); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("prefix=" + request.Prefix)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(result.ToJson()))
+	}
+}
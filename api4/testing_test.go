@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateFixtures(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableTesting := utils.Cfg.ServiceSettings.EnableTesting
+	enableDeveloper := *utils.Cfg.ServiceSettings.EnableDeveloper
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableTesting = enableTesting
+		*utils.Cfg.ServiceSettings.EnableDeveloper = enableDeveloper
+	}()
+
+	prefix := "f" + model.NewRandomString(6)
+	request := &model.FixtureRequest{
+		Prefix:           prefix,
+		Password:         "passwd",
+		Teams:            1,
+		Users:            3,
+		ChannelsPerTeam:  2,
+		PostsPerChannel:  4,
+		ReactionsPerPost: 2,
+		StartAt:          1000000,
+		PostInterval:     1000,
+	}
+
+	utils.Cfg.ServiceSettings.EnableTesting = true
+	*utils.Cfg.ServiceSettings.EnableDeveloper = false
+
+	_, resp := th.SystemAdminClient.CreateFixtures(request)
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableDeveloper = true
+
+	_, resp = Client.CreateFixtures(request)
+	CheckForbiddenStatus(t, resp)
+
+	result, resp := th.SystemAdminClient.CreateFixtures(request)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if len(result.Teams) != 1 || len(result.Users) != 3 || len(result.Channels) != 2 {
+		t.Fatal("wrong number of teams, users or channels")
+	}
+
+	if result.Posts != 8 || result.Reactions != 16 {
+		t.Fatal("wrong number of posts or reactions")
+	}
+
+	if result.Users[0].Username != prefix+"-user-0" {
+		t.Fatal("user should have been named after the prefix")
+	}
+
+	posts, resp := th.SystemAdminClient.GetPostsForChannel(result.Channels[0].Id, 0, 10, "")
+	CheckNoError(t, resp)
+
+	for _, post := range posts.Posts {
+		if post.IsSystemMessage() {
+			continue
+		}
+
+		if post.CreateAt < request.StartAt || post.CreateAt >= request.StartAt+4*request.PostInterval {
+			t.Fatal("post should have been timestamped from the start time")
+		}
+	}
+
+	request.Users = 0
+	_, resp = th.SystemAdminClient.CreateFixtures(request)
+	CheckBadRequestStatus(t, resp)
+
+	utils.Cfg.ServiceSettings.EnableTesting = false
+	request.Users = 3
+	_, resp = th.SystemAdminClient.CreateFixtures(request)
+	CheckNotImplementedStatus(t, resp)
+}
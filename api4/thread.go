@@ -0,0 +1,108 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitThread() {
+	l4g.Debug(utils.T("api.thread.init.debug"))
+
+	BaseRoutes.ThreadForUser.Handle("/following", ApiSessionRequired(followThread)).Methods("PUT")
+	BaseRoutes.ThreadForUser.Handle("/following", ApiSessionRequired(unfollowThread)).Methods("DELETE")
+	BaseRoutes.ThreadForUser.Handle("/read", ApiSessionRequired(markThreadAsRead)).Methods("POST")
+	BaseRoutes.ThreadsForTeamForUser.Handle("/unread", ApiSessionRequired(getUnreadThreadsForUser)).Methods("GET")
+}
+
+func followThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !app.SessionHasPermissionToChannelByPost(c.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	if membership, err := app.FollowThread(c.Params.UserId, c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(membership.ToJson()))
+	}
+}
+
+func unfollowThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if membership, err := app.UnfollowThread(c.Params.UserId, c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(membership.ToJson()))
+	}
+}
+
+func markThreadAsRead(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.MarkThreadAsRead(c.Params.UserId, c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getUnreadThreadsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !app.SessionHasPermissionToTeam(c.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	if threads, err := app.GetUnreadThreadsForUser(c.Params.UserId, c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.ThreadUnreadListToJson(threads)))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestFollowThread(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+
+	membership, resp := Client.FollowThread(model.ME, post.Id)
+	CheckNoError(t, resp)
+
+	if !membership.Following || membership.PostId != post.Id || membership.UserId != th.BasicUser.Id {
+		t.Fatal("should be following the thread")
+	}
+
+	reply, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, RootId: post.Id, Message: "reply"})
+	CheckNoError(t, resp)
+
+	_, resp = Client.FollowThread(model.ME, reply.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.FollowThread(model.ME, model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.FollowThread(th.BasicUser2.Id, post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.FollowThread(th.BasicUser2.Id, post.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.FollowThread(model.ME, post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestUnfollowThread(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+
+	_, resp := Client.UnfollowThread(model.ME, post.Id)
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.FollowThread(model.ME, post.Id)
+	CheckNoError(t, resp)
+
+	membership, resp := Client.UnfollowThread(model.ME, post.Id)
+	CheckNoError(t, resp)
+
+	if membership.Following {
+		t.Fatal("should have stopped following the thread")
+	}
+
+	_, resp = Client.UnfollowThread(th.BasicUser2.Id, post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.UnfollowThread(model.ME, post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestGetUnreadThreadsForUser(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	post := th.CreatePost()
+
+	th.LoginBasic2()
+	_, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, RootId: post.Id, Message: "reply to @" + th.BasicUser.Username})
+	CheckNoError(t, resp)
+
+	threads, resp := Client.GetUnreadThreadsForUser(model.ME, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(threads) != 0 {
+		t.Fatal("replying should mark the thread as read")
+	}
+
+	th.LoginBasic()
+	threads, resp = Client.GetUnreadThreadsForUser(model.ME, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	if len(threads) != 1 {
+		t.Fatal("the root post author should be following the thread")
+	} else if threads[0].PostId != post.Id || threads[0].UnreadReplies != 1 || threads[0].MentionCount != 1 {
+		t.Fatal("unread counts are wrong")
+	}
+
+	if member, _ := Client.GetChannelMember(th.BasicChannel.Id, th.BasicUser.Id, ""); member.MentionCount != 0 {
+		t.Fatal("mentions in replies shouldn't count against the channel")
+	}
+
+	pass, resp := Client.MarkThreadAsRead(model.ME, post.Id)
+	CheckNoError(t, resp)
+
+	if !pass {
+		t.Fatal("should have passed")
+	}
+
+	threads, _ = Client.GetUnreadThreadsForUser(model.ME, th.BasicTeam.Id)
+	if len(threads) != 0 {
+		t.Fatal("should have marked the thread as read")
+	}
+
+	_, resp = Client.MarkThreadAsRead(model.ME, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = Client.GetUnreadThreadsForUser(th.BasicUser2.Id, th.BasicTeam.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetUnreadThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetUnreadThreadsForUser(model.ME, th.BasicTeam.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
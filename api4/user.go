@@ -21,8 +21,8 @@ func InitUser() {
 
 	BaseRoutes.Users.Handle("", ApiHandler(createUser)).Methods("POST")
 	BaseRoutes.Users.Handle("", ApiSessionRequired(getUsers)).Methods("GET")
-	BaseRoutes.Users.Handle("/ids", ApiSessionRequired(getUsersByIds)).Methods("POST")
-	BaseRoutes.Users.Handle("/search", ApiSessionRequired(searchUsers)).Methods("POST")
+	BaseRoutes.Users.Handle("/ids", ApiSessionRequiredReadOnly(getUsersByIds)).Methods("POST")
+	BaseRoutes.Users.Handle("/search", ApiSessionRequiredReadOnly(searchUsers)).Methods("POST")
 	BaseRoutes.Users.Handle("/autocomplete", ApiSessionRequired(autocompleteUsers)).Methods("GET")
 
 	BaseRoutes.User.Handle("", ApiSessionRequired(getUser)).Methods("GET")
@@ -32,6 +32,8 @@ func InitUser() {
 	BaseRoutes.User.Handle("/patch", ApiSessionRequired(patchUser)).Methods("PUT")
 	BaseRoutes.User.Handle("", ApiSessionRequired(deleteUser)).Methods("DELETE")
 	BaseRoutes.User.Handle("/roles", ApiSessionRequired(updateUserRoles)).Methods("PUT")
+	BaseRoutes.User.Handle("/promote", ApiSessionRequired(promoteGuestToUser)).Methods("POST")
+	BaseRoutes.User.Handle("/demote", ApiSessionRequired(demoteUserToGuest)).Methods("POST")
 	BaseRoutes.User.Handle("/password", ApiSessionRequired(updatePassword)).Methods("PUT")
 	BaseRoutes.Users.Handle("/password/reset", ApiHandler(resetPassword)).Methods("POST")
 	BaseRoutes.Users.Handle("/password/reset/send", ApiHandler(sendPasswordReset)).Methods("POST")
@@ -39,8 +41,16 @@ func InitUser() {
 	BaseRoutes.Users.Handle("/email/verify/send", ApiHandler(sendVerificationEmail)).Methods("POST")
 
 	BaseRoutes.Users.Handle("/mfa", ApiHandler(checkUserMfa)).Methods("POST")
-	BaseRoutes.User.Handle("/mfa", ApiSessionRequired(updateUserMfa)).Methods("PUT")
-	BaseRoutes.User.Handle("/mfa/generate", ApiSessionRequired(generateMfaSecret)).Methods("POST")
+	BaseRoutes.User.Handle("/mfa", ApiSessionRequiredMfa(updateUserMfa)).Methods("PUT")
+	BaseRoutes.User.Handle("/mfa/generate", ApiSessionRequiredMfa(generateMfaSecret)).Methods("POST")
+	BaseRoutes.User.Handle("/mfa/backup_codes", ApiSessionRequiredMfa(getMfaBackupCodeCount)).Methods("GET")
+	BaseRoutes.User.Handle("/mfa/backup_codes/regenerate", ApiSessionRequiredMfa(regenerateMfaBackupCodes)).Methods("POST")
+
+	BaseRoutes.User.Handle("/webauthn/register/begin", ApiSessionRequiredMfa(beginWebAuthnRegistration)).Methods("POST")
+	BaseRoutes.User.Handle("/webauthn/register/finish", ApiSessionRequiredMfa(finishWebAuthnRegistration)).Methods("POST")
+	BaseRoutes.User.Handle("/webauthn/credentials", ApiSessionRequiredMfa(getUserCredentials)).Methods("GET")
+	BaseRoutes.User.Handle("/webauthn/credentials/{credential_id:[A-Za-z0-9]+}", ApiSessionRequiredMfa(deleteUserCredential)).Methods("DELETE")
+	BaseRoutes.Users.Handle("/webauthn/login/begin", ApiHandler(beginWebAuthnLogin)).Methods("POST")
 
 	BaseRoutes.Users.Handle("/login", ApiHandler(login)).Methods("POST")
 	BaseRoutes.Users.Handle("/login/switch", ApiHandler(switchAccountType)).Methods("POST")
@@ -51,8 +61,16 @@ func InitUser() {
 
 	BaseRoutes.User.Handle("/sessions", ApiSessionRequired(getSessions)).Methods("GET")
 	BaseRoutes.User.Handle("/sessions/revoke", ApiSessionRequired(revokeSession)).Methods("POST")
+	BaseRoutes.User.Handle("/sessions/revoke/others", ApiSessionRequired(revokeOtherSessions)).Methods("POST")
+	BaseRoutes.User.Handle("/sessions/label", ApiSessionRequired(updateSessionLabel)).Methods("PUT")
 	BaseRoutes.Users.Handle("/sessions/device", ApiSessionRequired(attachDeviceId)).Methods("PUT")
 	BaseRoutes.User.Handle("/audits", ApiSessionRequired(getUserAudits)).Methods("GET")
+
+	BaseRoutes.User.Handle("/tokens", ApiSessionRequired(createUserAccessToken)).Methods("POST")
+	BaseRoutes.User.Handle("/tokens", ApiSessionRequired(getUserAccessTokens)).Methods("GET")
+	BaseRoutes.User.Handle("/tokens/{token_id:[A-Za-z0-9]+}/disable", ApiSessionRequired(disableUserAccessToken)).Methods("POST")
+	BaseRoutes.User.Handle("/tokens/{token_id:[A-Za-z0-9]+}/enable", ApiSessionRequired(enableUserAccessToken)).Methods("POST")
+	BaseRoutes.User.Handle("/tokens/{token_id:[A-Za-z0-9]+}", ApiSessionRequired(revokeUserAccessToken)).Methods("DELETE")
 }
 
 func createUser(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -104,7 +122,7 @@ func getUser(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	etag := user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress)
+	etag := user.Etag(c.Config.PrivacySettings.ShowFullName, c.Config.PrivacySettings.ShowEmailAddress)
 
 	if HandleEtag(etag, "Get User", w, r) {
 		return
@@ -132,7 +150,7 @@ func getUserByUsername(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	etag := user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress)
+	etag := user.Etag(c.Config.PrivacySettings.ShowFullName, c.Config.PrivacySettings.ShowEmailAddress)
 
 	if HandleEtag(etag, "Get User", w, r) {
 		return
@@ -160,7 +178,7 @@ func getUserByEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	etag := user.Etag(utils.Cfg.PrivacySettings.ShowFullName, utils.Cfg.PrivacySettings.ShowEmailAddress)
+	etag := user.Etag(c.Config.PrivacySettings.ShowFullName, c.Config.PrivacySettings.ShowEmailAddress)
 
 	if HandleEtag(etag, "Get User", w, r) {
 		return
@@ -222,19 +240,19 @@ func setProfileImage(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(c.Config.FileSettings.DriverName) == 0 {
 		c.Err = model.NewLocAppError("uploadProfileImage", "api.user.upload_profile_user.storage.app_error", nil, "")
 		c.Err.StatusCode = http.StatusNotImplemented
 		return
 	}
 
-	if r.ContentLength > *utils.Cfg.FileSettings.MaxFileSize {
+	if r.ContentLength > *c.Config.FileSettings.MaxFileSize {
 		c.Err = model.NewLocAppError("uploadProfileImage", "api.user.upload_profile_user.too_large.app_error", nil, "")
 		c.Err.StatusCode = http.StatusRequestEntityTooLarge
 		return
 	}
 
-	if err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize); err != nil {
+	if err := r.ParseMultipartForm(*c.Config.FileSettings.MaxFileSize); err != nil {
 		c.Err = model.NewLocAppError("uploadProfileImage", "api.user.upload_profile_user.parse.app_error", nil, "")
 		return
 	}
@@ -279,8 +297,12 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	var profiles []*model.User
 	var err *model.AppError
+	var countTotal func() (int64, *model.AppError)
 	etag := ""
 
+	// Guests can only list the users they share a channel with
+	canViewMembers := app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS)
+
 	if withoutTeamBool, err := strconv.ParseBool(withoutTeam); err == nil && withoutTeamBool {
 		// Use a special permission for now
 		if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_LIST_USERS_WITHOUT_TEAM) {
@@ -295,6 +317,11 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if !canViewMembers {
+			c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+			return
+		}
+
 		profiles, err = app.GetUsersNotInChannelPage(inTeamId, notInChannelId, c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
 	} else if len(notInTeamId) > 0 {
 		if !app.SessionHasPermissionToTeam(c.Session, notInTeamId, model.PERMISSION_VIEW_TEAM) {
@@ -302,6 +329,11 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if !canViewMembers {
+			c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+			return
+		}
+
 		etag = app.GetUsersNotInTeamEtag(inTeamId)
 		if HandleEtag(etag, "Get Users Not in Team", w, r) {
 			return
@@ -314,12 +346,17 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		etag = app.GetUsersInTeamEtag(inTeamId)
-		if HandleEtag(etag, "Get Users in Team", w, r) {
-			return
-		}
+		if !canViewMembers {
+			profiles, err = app.GetUsersSharingChannelsPage(c.Session.UserId, inTeamId, c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
+		} else {
+			etag = app.GetUsersInTeamEtag(inTeamId)
+			if HandleEtag(etag, "Get Users in Team", w, r) {
+				return
+			}
 
-		profiles, err = app.GetUsersInTeamPage(inTeamId, c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
+			profiles, err = app.GetUsersInTeamPage(inTeamId, c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
+			countTotal = func() (int64, *model.AppError) { return app.GetTeamMemberCount(inTeamId) }
+		}
 	} else if len(inChannelId) > 0 {
 		if !app.SessionHasPermissionToChannel(c.Session, inChannelId, model.PERMISSION_READ_CHANNEL) {
 			c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
@@ -327,6 +364,9 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 
 		profiles, err = app.GetUsersInChannelPage(inChannelId, c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
+		countTotal = func() (int64, *model.AppError) { return app.GetChannelMemberCount(inChannelId) }
+	} else if !canViewMembers {
+		profiles, err = app.GetUsersSharingChannelsPage(c.Session.UserId, "", c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
 	} else {
 		// No permission check required
 
@@ -335,6 +375,7 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		profiles, err = app.GetUsersPage(c.Params.Page, c.Params.PerPage, c.IsSystemAdmin())
+		countTotal = app.GetTotalUsersCount
 	}
 
 	if err != nil {
@@ -344,6 +385,9 @@ func getUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		if len(etag) > 0 {
 			w.Header().Set(model.HEADER_ETAG_SERVER, etag)
 		}
+		if pagination := c.GetPagination(len(profiles), countTotal); pagination != nil {
+			pagination.SetHeaders(w.Header())
+		}
 		w.Write([]byte(model.UserListToJson(profiles)))
 	}
 }
@@ -407,8 +451,8 @@ func searchUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 	searchOptions[store.USER_SEARCH_OPTION_ALLOW_INACTIVE] = props.AllowInactive
 
 	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
-		hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
-		hideEmail := !utils.Cfg.PrivacySettings.ShowEmailAddress
+		hideFullName := !c.Config.PrivacySettings.ShowFullName
+		hideEmail := !c.Config.PrivacySettings.ShowEmailAddress
 
 		if hideFullName && hideEmail {
 			searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME] = true
@@ -419,7 +463,21 @@ func searchUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if profiles, err := app.SearchUsers(props, searchOptions, c.IsSystemAdmin()); err != nil {
+	var profiles []*model.User
+	var err *model.AppError
+	if app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS) {
+		profiles, err = app.SearchUsers(props, searchOptions, c.IsSystemAdmin())
+	} else if props.WithoutTeam || props.NotInChannelId != "" || props.NotInTeamId != "" {
+		c.SetPermissionError(model.PERMISSION_VIEW_MEMBERS)
+		return
+	} else if props.InChannelId != "" {
+		profiles, err = app.SearchUsersInChannel(props.InChannelId, props.Term, searchOptions, c.IsSystemAdmin())
+	} else {
+		// Guests can only find the users they share a channel with
+		profiles, err = app.SearchUsersSharingChannels(c.Session.UserId, props.Term, searchOptions, c.IsSystemAdmin())
+	}
+
+	if err != nil {
 		c.Err = err
 		return
 	} else {
@@ -437,13 +495,16 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	searchOptions := map[string]bool{}
 
-	hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
+	hideFullName := !c.Config.PrivacySettings.ShowFullName
 	if hideFullName && !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
 		searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME] = true
 	} else {
 		searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY] = true
 	}
 
+	// Guests can only find the users they share a channel with
+	canViewMembers := app.SessionHasPermissionTo(c.Session, model.PERMISSION_VIEW_MEMBERS)
+
 	if len(teamId) > 0 {
 		if len(channelId) > 0 {
 			if !app.SessionHasPermissionToChannel(c.Session, channelId, model.PERMISSION_READ_CHANNEL) {
@@ -453,16 +514,24 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 
 			result, _ := app.AutocompleteUsersInChannel(teamId, channelId, name, searchOptions, c.IsSystemAdmin())
 			autocomplete.Users = result.InChannel
-			autocomplete.OutOfChannel = result.OutOfChannel
+			if canViewMembers {
+				autocomplete.OutOfChannel = result.OutOfChannel
+			}
 		} else {
 			if !app.SessionHasPermissionToTeam(c.Session, teamId, model.PERMISSION_VIEW_TEAM) {
 				c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
 				return
 			}
 
-			result, _ := app.AutocompleteUsersInTeam(teamId, name, searchOptions, c.IsSystemAdmin())
-			autocomplete.Users = result.InTeam
+			if canViewMembers {
+				result, _ := app.AutocompleteUsersInTeam(teamId, name, searchOptions, c.IsSystemAdmin())
+				autocomplete.Users = result.InTeam
+			} else {
+				autocomplete.Users, _ = app.SearchUsersSharingChannels(c.Session.UserId, name, searchOptions, c.IsSystemAdmin())
+			}
 		}
+	} else if !canViewMembers {
+		autocomplete.Users, _ = app.SearchUsersSharingChannels(c.Session.UserId, name, searchOptions, c.IsSystemAdmin())
 	} else {
 		// No permission check required
 		result, _ := app.SearchUsersInTeam("", name, searchOptions, c.IsSystemAdmin())
@@ -581,7 +650,49 @@ func updateUserRoles(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = err
 		return
 	} else {
-		c.LogAuditWithUserId(c.Params.UserId, "roles="+newRoles)
+		c.LogAuditEvent(model.AUDIT_TARGET_USER, c.Params.UserId, model.StringInterface{"roles": newRoles})
+	}
+
+	ReturnStatusOK(w)
+}
+
+func promoteGuestToUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := app.PromoteGuestToUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAuditWithUserId(c.Params.UserId, "")
+	}
+
+	ReturnStatusOK(w)
+}
+
+func demoteUserToGuest(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if _, err := app.DemoteUserToGuest(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAuditWithUserId(c.Params.UserId, "")
 	}
 
 	ReturnStatusOK(w)
@@ -598,14 +709,17 @@ func checkUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	resp := map[string]interface{}{}
 	resp["mfa_required"] = false
+	resp["security_key_available"] = false
 
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !utils.IsLicensed || !*utils.License.Features.MFA || !*c.Config.ServiceSettings.EnableMultifactorAuthentication {
 		w.Write([]byte(model.StringInterfaceToJson(resp)))
 		return
 	}
 
 	if user, err := app.GetUserForLogin(loginId, false); err == nil {
-		resp["mfa_required"] = user.MfaActive
+		hasCredentials, _ := app.HasUserCredentials(user.Id)
+		resp["mfa_required"] = user.MfaActive || hasCredentials
+		resp["security_key_available"] = hasCredentials
 	}
 
 	w.Write([]byte(model.StringInterfaceToJson(resp)))
@@ -673,6 +787,162 @@ func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(secret.ToJson()))
 }
 
+func getMfaBackupCodeCount(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if codes, err := app.GetMfaBackupCodeCount(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(codes.ToJson()))
+	}
+}
+
+func regenerateMfaBackupCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	// Backup codes get around MFA so only the user can see them, not even an admin
+	if c.Session.UserId != c.Params.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	codes, err := app.RegenerateMfaBackupCodes(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Write([]byte(codes.ToJson()))
+}
+
+func beginWebAuthnRegistration(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	// Only the user can register their own security keys since they need to be holding them
+	if c.Session.UserId != c.Params.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	options, err := app.BeginWebAuthnRegistration(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(options.ToJson()))
+}
+
+func finishWebAuthnRegistration(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.Session.UserId != c.Params.UserId {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	attestation := model.WebAuthnAttestationFromJson(r.Body)
+	if attestation == nil {
+		c.SetInvalidParam("attestation")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	credential, err := app.FinishWebAuthnRegistration(c.Params.UserId, attestation)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success - credential_id=" + credential.Id)
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(credential.ToJson()))
+}
+
+func getUserCredentials(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if credentials, err := app.GetUserCredentials(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.UserCredentialListToJson(credentials)))
+	}
+}
+
+func deleteUserCredential(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireCredentialId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.DeleteUserCredential(c.Params.UserId, c.Params.CredentialId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("credential_id=" + c.Params.CredentialId)
+	ReturnStatusOK(w)
+}
+
+func beginWebAuthnLogin(c *Context, w http.ResponseWriter, r *http.Request) {
+	props := model.MapFromJson(r.Body)
+
+	loginId := props["login_id"]
+	if len(loginId) == 0 {
+		c.SetInvalidParam("login_id")
+		return
+	}
+
+	options, err := app.BeginWebAuthnLogin(loginId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(options.ToJson()))
+}
+
 func updatePassword(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireUserId()
 	if c.Err != nil {
@@ -860,6 +1130,53 @@ func revokeSession(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func revokeOtherSessions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := app.RevokeOtherSessions(c.Params.UserId, c.Session.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("")
+	ReturnStatusOK(w)
+}
+
+func updateSessionLabel(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	sessionId := props["session_id"]
+	if len(sessionId) != 26 {
+		c.SetInvalidParam("session_id")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if session, err := app.UpdateSessionLabel(c.Params.UserId, sessionId, props["label"]); err != nil {
+		c.Err = err
+		return
+	} else {
+		session.Sanitize()
+		w.Write([]byte(session.ToJson()))
+	}
+}
+
 func attachDeviceId(c *Context, w http.ResponseWriter, r *http.Request) {
 	props := model.MapFromJson(r.Body)
 
@@ -876,9 +1193,9 @@ func attachDeviceId(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	app.ClearSessionCacheForUser(c.Session.UserId)
-	c.Session.SetExpireInDays(*utils.Cfg.ServiceSettings.SessionLengthMobileInDays)
+	c.Session.SetExpireInDays(*c.Config.ServiceSettings.SessionLengthMobileInDays)
 
-	maxAge := *utils.Cfg.ServiceSettings.SessionLengthMobileInDays * 60 * 60 * 24
+	maxAge := *c.Config.ServiceSettings.SessionLengthMobileInDays * 60 * 60 * 24
 
 	secure := false
 	if app.GetProtocol(r) == "https" {
@@ -903,6 +1220,9 @@ func attachDeviceId(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	c.Session.DeviceId = deviceId
+	app.RecordDevice(r, &c.Session)
+
 	c.LogAudit("")
 	ReturnStatusOK(w)
 }
@@ -943,7 +1263,7 @@ func verifyUserEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	hashed := model.HashPassword(hashedId)
-	if model.ComparePassword(hashed, userId+utils.Cfg.EmailSettings.InviteSalt) {
+	if model.ComparePassword(hashed, userId+c.Config.EmailSettings.InviteSalt) {
 		if c.Err = app.VerifyUserEmail(userId); c.Err != nil {
 			return
 		} else {
@@ -1019,3 +1339,123 @@ func switchAccountType(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.LogAudit("success")
 	w.Write([]byte(model.MapToJson(map[string]string{"follow_link": link})))
 }
+
+func createUserAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	token := model.UserAccessTokenFromJson(r.Body)
+	if token == nil {
+		c.SetInvalidParam("token")
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	token.UserId = c.Params.UserId
+
+	if rtoken, err := app.CreateUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("token_id=" + rtoken.Id)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(rtoken.ToJson()))
+	}
+}
+
+func getUserAccessTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if tokens, err := app.GetUserAccessTokensForUser(c.Params.UserId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.UserAccessTokenListToJson(tokens)))
+	}
+}
+
+func disableUserAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	token := getUserAccessTokenForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.DisableUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("token_id=" + token.Id)
+	ReturnStatusOK(w)
+}
+
+func enableUserAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	token := getUserAccessTokenForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.EnableUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("token_id=" + token.Id)
+	ReturnStatusOK(w)
+}
+
+func revokeUserAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	token := getUserAccessTokenForSession(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := app.RevokeUserAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("token_id=" + token.Id)
+	ReturnStatusOK(w)
+}
+
+// getUserAccessTokenForSession returns the access token from the request if it belongs to the user
+// from the request and the session's user can edit that user.
+func getUserAccessTokenForSession(c *Context) *model.UserAccessToken {
+	c.RequireUserId().RequireTokenId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !app.SessionHasPermissionToUser(c.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return nil
+	}
+
+	token, err := app.GetUserAccessToken(c.Params.TokenId)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if token.UserId != c.Params.UserId {
+		c.SetInvalidUrlParam("token_id")
+		return nil
+	}
+
+	return token
+}
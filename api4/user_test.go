@@ -850,6 +850,77 @@ func TestUpdateUserRoles(t *testing.T) {
 	CheckBadRequestStatus(t, resp)
 }
 
+func TestPromoteDemoteGuest(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+	SystemAdminClient := th.SystemAdminClient
+
+	_, resp := Client.DemoteUserToGuest(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = SystemAdminClient.DemoteUserToGuest(th.SystemAdminUser.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = SystemAdminClient.PromoteGuestToUser(th.BasicUser2.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = SystemAdminClient.DemoteUserToGuest(th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if user, resp := SystemAdminClient.GetUser(th.BasicUser2.Id, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if !user.IsGuest() {
+		t.Fatal("should have demoted the user")
+	}
+
+	if members, resp := SystemAdminClient.GetTeamMembersForUser(th.BasicUser2.Id, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if len(members) != 1 || members[0].Roles != model.ROLE_TEAM_GUEST.Id {
+		t.Fatal("should have demoted the user in their teams")
+	}
+
+	_, resp = SystemAdminClient.DemoteUserToGuest(th.BasicUser2.Id)
+	CheckBadRequestStatus(t, resp)
+
+	th.LoginBasic2()
+
+	otherUser := th.CreateUser()
+	LinkUserToTeam(otherUser, th.BasicTeam)
+
+	users, resp := Client.GetUsersInTeam(th.BasicTeam.Id, 0, 100, "")
+	CheckNoError(t, resp)
+	for _, user := range users {
+		if user.Id == otherUser.Id {
+			t.Fatal("guest should only see the users sharing a channel")
+		}
+	}
+
+	_, resp = Client.GetUsersNotInTeam(th.BasicTeam.Id, 0, 100, "")
+	CheckForbiddenStatus(t, resp)
+
+	users, resp = Client.SearchUsers(&model.UserSearch{Term: otherUser.Username})
+	CheckNoError(t, resp)
+	if len(users) != 0 {
+		t.Fatal("guest should only find the users sharing a channel")
+	}
+
+	_, resp = SystemAdminClient.PromoteGuestToUser(th.BasicUser2.Id)
+	CheckNoError(t, resp)
+
+	if user, resp := SystemAdminClient.GetUser(th.BasicUser2.Id, ""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if user.IsGuest() {
+		t.Fatal("should have promoted the user")
+	}
+
+	users, resp = Client.SearchUsers(&model.UserSearch{Term: otherUser.Username})
+	CheckNoError(t, resp)
+	if len(users) != 1 {
+		t.Fatal("should find all users once promoted")
+	}
+}
+
 func TestGetUsers(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -892,6 +963,32 @@ func TestGetUsers(t *testing.T) {
 	CheckUnauthorizedStatus(t, resp)
 }
 
+func TestGetUsersPagination(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	if r, err := Client.DoApiGet("/users?in_team="+th.BasicTeam.Id+"&page=0&per_page=1", ""); err != nil {
+		t.Fatal(err)
+	} else if model.PaginationFromHeaders(r.Header) != nil {
+		t.Fatal("shouldn't have returned pagination without being asked for it")
+	}
+
+	if r, err := Client.DoApiGet("/users?in_team="+th.BasicTeam.Id+"&page=0&per_page=1&include_total_count=true", ""); err != nil {
+		t.Fatal(err)
+	} else if pagination := model.PaginationFromHeaders(r.Header); pagination == nil {
+		t.Fatal("should have returned pagination")
+	} else if pagination.TotalCount < 2 || !pagination.HasMore || pagination.NextCursor != "1" {
+		t.Fatal("should have more users on the team", pagination)
+	}
+
+	if r, err := Client.DoApiGet("/users?in_team="+th.BasicTeam.Id+"&page=0&per_page=200&include_total_count=true", ""); err != nil {
+		t.Fatal(err)
+	} else if pagination := model.PaginationFromHeaders(r.Header); pagination == nil || pagination.HasMore || pagination.NextCursor != "" {
+		t.Fatal("should have returned every user on the team", pagination)
+	}
+}
+
 func TestGetUsersWithoutTeam(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1240,6 +1337,211 @@ func TestGenerateMfaSecret(t *testing.T) {
 	CheckNotImplementedStatus(t, resp)
 }
 
+func TestMfaBackupCodes(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.GetMfaBackupCodeCount(th.BasicUser.Id)
+	CheckNotImplementedStatus(t, resp)
+
+	isLicensed := utils.IsLicensed
+	license := utils.License
+	enableMfa := *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication
+	defer func() {
+		utils.IsLicensed = isLicensed
+		utils.License = license
+		*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = enableMfa
+	}()
+	utils.IsLicensed = true
+	utils.License = &model.License{Features: &model.Features{}}
+	utils.License.Features.SetDefaults()
+	*utils.License.Features.MFA = true
+	*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = true
+
+	_, resp = Client.RegenerateMfaBackupCodes(th.BasicUser.Id)
+	CheckBadRequestStatus(t, resp)
+
+	if result := <-app.Srv.Store.User().UpdateMfaActive(th.BasicUser.Id, true); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	codes, resp := Client.RegenerateMfaBackupCodes(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(codes.Codes) != model.MFA_BACKUP_CODE_COUNT || codes.Remaining != model.MFA_BACKUP_CODE_COUNT {
+		t.Fatal("should have generated the codes", codes)
+	}
+
+	count, resp := Client.GetMfaBackupCodeCount(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(count.Codes) != 0 || count.Remaining != model.MFA_BACKUP_CODE_COUNT {
+		t.Fatal("should only return the number of codes left", count)
+	}
+
+	_, resp = Client.GetMfaBackupCodeCount(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetMfaBackupCodeCount(th.BasicUser.Id)
+	CheckNoError(t, resp)
+
+	_, resp = th.SystemAdminClient.RegenerateMfaBackupCodes(th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.RegenerateMfaBackupCodes(th.BasicUser.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestWebAuthn(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	_, resp := Client.BeginWebAuthnRegistration(th.BasicUser.Id)
+	CheckNotImplementedStatus(t, resp)
+
+	isLicensed := utils.IsLicensed
+	license := utils.License
+	enableMfa := *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication
+	enableWebAuthn := *utils.Cfg.ServiceSettings.EnableWebAuthn
+	defer func() {
+		utils.IsLicensed = isLicensed
+		utils.License = license
+		*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = enableMfa
+		*utils.Cfg.ServiceSettings.EnableWebAuthn = enableWebAuthn
+	}()
+	utils.IsLicensed = true
+	utils.License = &model.License{Features: &model.Features{}}
+	utils.License.Features.SetDefaults()
+	*utils.License.Features.MFA = true
+	*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = true
+	*utils.Cfg.ServiceSettings.EnableWebAuthn = true
+
+	options, resp := Client.BeginWebAuthnRegistration(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(options.Challenge) == 0 || options.User.Name != th.BasicUser.Username || len(options.ExcludeCredentials) != 0 {
+		t.Fatal("should have returned the registration options", options)
+	}
+
+	_, resp = th.SystemAdminClient.BeginWebAuthnRegistration(th.BasicUser.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.FinishWebAuthnRegistration(th.BasicUser.Id, &model.WebAuthnAttestation{ClientDataJSON: "garbage", AttestationObject: "garbage"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.FinishWebAuthnRegistration(th.BasicUser.Id, &model.WebAuthnAttestation{})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.BeginWebAuthnLogin(th.BasicUser.Email)
+	CheckBadRequestStatus(t, resp)
+	noCredentialsErrorId := resp.Error.Id
+
+	_, resp = Client.BeginWebAuthnLogin("unknown" + model.NewId() + "@simulator.amazonses.com")
+	CheckBadRequestStatus(t, resp)
+	if resp.Error.Id != noCredentialsErrorId {
+		t.Fatal("should return the same error for unknown users as for users without security keys")
+	}
+
+	credential := &model.UserCredential{UserId: th.BasicUser.Id, Name: "key", CredentialId: model.NewId(), PublicKey: model.NewId()}
+	if result := <-app.Srv.Store.UserCredential().Save(credential); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	credentials, resp := Client.GetUserCredentials(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(credentials) != 1 || credentials[0].Id != credential.Id || credentials[0].PublicKey != "" {
+		t.Fatal("should have returned the credentials without their public keys", credentials)
+	}
+
+	_, resp = Client.GetUserCredentials(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	if required, resp := Client.CheckUserMfa(th.BasicUser.Email); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if !required {
+		t.Fatal("should require a second factor for a user with a security key")
+	}
+
+	loginOptions, resp := Client.BeginWebAuthnLogin(th.BasicUser.Email)
+	CheckNoError(t, resp)
+	if len(loginOptions.Challenge) == 0 || len(loginOptions.AllowCredentials) != 1 || loginOptions.AllowCredentials[0].Id != credential.CredentialId {
+		t.Fatal("should have returned the login options", loginOptions)
+	}
+
+	if otherOptions, resp := Client.BeginWebAuthnLogin(th.BasicUser.Email); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if otherOptions.Challenge == loginOptions.Challenge {
+		t.Fatal("should have returned a new challenge for each login")
+	}
+
+	Client.Logout()
+
+	_, resp = Client.Login(th.BasicUser.Email, th.BasicUser.Password)
+	CheckUnauthorizedStatus(t, resp)
+
+	assertion := &model.WebAuthnAssertion{Id: credential.CredentialId, ClientDataJSON: "garbage", AuthenticatorData: "garbage", Signature: "garbage"}
+	_, resp = Client.LoginWithMFA(th.BasicUser.Email, th.BasicUser.Password, assertion.ToJson())
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteUserCredential(th.BasicUser.Id, model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.DeleteUserCredential(th.BasicUser2.Id, credential.Id)
+	CheckBadRequestStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.DeleteUserCredential(th.BasicUser.Id, credential.Id)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have deleted the credential")
+	}
+}
+
+func TestEnforceMfaGracePeriod(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	isLicensed := utils.IsLicensed
+	license := utils.License
+	enableMfa := *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication
+	enforceMfa := *utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication
+	enforceMfaAfter := *utils.Cfg.ServiceSettings.EnforceMultifactorAuthenticationAfter
+	defer func() {
+		utils.IsLicensed = isLicensed
+		utils.License = license
+		*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = enableMfa
+		*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication = enforceMfa
+		*utils.Cfg.ServiceSettings.EnforceMultifactorAuthenticationAfter = enforceMfaAfter
+	}()
+	utils.IsLicensed = true
+	utils.License = &model.License{Features: &model.Features{}}
+	utils.License.Features.SetDefaults()
+	*utils.License.Features.MFA = true
+	*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = true
+	*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication = true
+
+	*utils.Cfg.ServiceSettings.EnforceMultifactorAuthenticationAfter = model.GetMillis() + 60*60*1000
+
+	_, resp := Client.GetMe("")
+	CheckNoError(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnforceMultifactorAuthenticationAfter = model.GetMillis() - 60*60*1000
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	// Setting up MFA is still allowed once the grace period is over
+	_, resp = Client.GetMfaBackupCodeCount(th.BasicUser.Id)
+	CheckBadRequestStatus(t, resp)
+
+	if result := <-app.Srv.Store.User().UpdateMfaActive(th.BasicUser.Id, true); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	_, resp = Client.GetMe("")
+	CheckNoError(t, resp)
+}
+
 func TestUpdateUserPassword(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer TearDown()
@@ -1508,6 +1810,92 @@ func TestRevokeSessions(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestRevokeOtherSessions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	user := th.BasicUser
+	OtherClient := th.CreateClient()
+	OtherClient.Login(user.Email, user.Password)
+
+	_, resp := Client.RevokeOtherSessions(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := Client.RevokeOtherSessions(user.Id)
+	CheckNoError(t, resp)
+	if !ok {
+		t.Fatal("should have revoked the other sessions")
+	}
+
+	_, resp = OtherClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.GetMe("")
+	CheckNoError(t, resp)
+
+	sessions, _ := Client.GetSessions(user.Id, "")
+	if len(sessions) != 1 {
+		t.Fatal("only the current session should be left", len(sessions))
+	}
+
+	_, resp = th.SystemAdminClient.RevokeOtherSessions(user.Id)
+	CheckNoError(t, resp)
+
+	_, resp = Client.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+}
+
+func TestUpdateSessionLabel(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	user := th.BasicUser
+	sessions, _ := Client.GetSessions(user.Id, "")
+	if len(sessions) == 0 {
+		t.Fatal("sessions should exist")
+	}
+	session := sessions[0]
+
+	labeled, resp := Client.UpdateSessionLabel(user.Id, session.Id, " Work laptop ")
+	CheckNoError(t, resp)
+	if labeled.Props[model.SESSION_PROP_LABEL] != "Work laptop" {
+		t.Fatal("session should have been named", labeled.Props)
+	}
+	if labeled.Token != "" {
+		t.Fatal("token should have been sanitized")
+	}
+
+	sessions, _ = Client.GetSessions(user.Id, "")
+	for _, s := range sessions {
+		if s.Id == session.Id && s.Props[model.SESSION_PROP_LABEL] != "Work laptop" {
+			t.Fatal("name should have been saved", s.Props)
+		}
+	}
+
+	_, resp = Client.UpdateSessionLabel(user.Id, session.Id, strings.Repeat("a", model.SESSION_LABEL_MAX_RUNES+1))
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateSessionLabel(user.Id, "junk", "Phone")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateSessionLabel(user.Id, model.NewId(), "Phone")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpdateSessionLabel(th.BasicUser2.Id, session.Id, "Phone")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.UpdateSessionLabel(th.SystemAdminUser.Id, session.Id, "Phone")
+	CheckBadRequestStatus(t, resp)
+
+	labeled, resp = th.SystemAdminClient.UpdateSessionLabel(user.Id, session.Id, "")
+	CheckNoError(t, resp)
+	if _, ok := labeled.Props[model.SESSION_PROP_LABEL]; ok {
+		t.Fatal("name should have been removed", labeled.Props)
+	}
+}
+
 func TestAttachDeviceId(t *testing.T) {
 	th := Setup().InitBasic()
 	defer TearDown()
@@ -1742,3 +2130,69 @@ func TestSwitchAccount(t *testing.T) {
 	_, resp = Client.SwitchAccountType(sr)
 	CheckUnauthorizedStatus(t, resp)
 }
+
+func TestUserAccessTokens(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer TearDown()
+	Client := th.Client
+
+	enableUserAccessTokens := *utils.Cfg.ServiceSettings.EnableUserAccessTokens
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableUserAccessTokens = enableUserAccessTokens
+	}()
+
+	*utils.Cfg.ServiceSettings.EnableUserAccessTokens = false
+	_, resp := Client.CreateUserAccessToken(th.BasicUser.Id, "test token")
+	CheckNotImplementedStatus(t, resp)
+
+	*utils.Cfg.ServiceSettings.EnableUserAccessTokens = true
+	token, resp := Client.CreateUserAccessToken(th.BasicUser.Id, "test token")
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+
+	if len(token.Token) == 0 || token.UserId != th.BasicUser.Id || !token.IsActive {
+		t.Fatal("should have returned the token")
+	}
+
+	_, resp = Client.CreateUserAccessToken(th.BasicUser2.Id, "")
+	CheckForbiddenStatus(t, resp)
+
+	TokenClient := th.CreateClient()
+	TokenClient.SetOAuthToken(token.Token)
+
+	if me, resp := TokenClient.GetMe(""); resp.Error != nil {
+		t.Fatal(resp.Error)
+	} else if me.Id != th.BasicUser.Id {
+		t.Fatal("should have authenticated as the user")
+	}
+
+	tokens, resp := Client.GetUserAccessTokens(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	if len(tokens) != 1 || tokens[0].Id != token.Id || tokens[0].Token != "" || tokens[0].LastUsedAt == 0 {
+		t.Fatal("should have returned the tokens without their values and when they were last used")
+	}
+
+	_, resp = Client.DisableUserAccessToken(th.BasicUser.Id, token.Id)
+	CheckNoError(t, resp)
+
+	_, resp = TokenClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.EnableUserAccessToken(th.BasicUser.Id, token.Id)
+	CheckNoError(t, resp)
+
+	_, resp = TokenClient.GetMe("")
+	CheckNoError(t, resp)
+
+	_, resp = Client.RevokeUserAccessToken(th.BasicUser2.Id, token.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.RevokeUserAccessToken(th.BasicUser2.Id, token.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.RevokeUserAccessToken(th.BasicUser.Id, token.Id)
+	CheckNoError(t, resp)
+
+	_, resp = TokenClient.GetMe("")
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -4,11 +4,15 @@
 package api4
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/websocket"
 	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 )
@@ -17,9 +21,20 @@ func InitWebSocket() {
 	l4g.Debug(utils.T("api.web_socket.init.debug"))
 
 	BaseRoutes.ApiRoot.Handle("/websocket", ApiHandlerTrustRequester(connectWebSocket)).Methods("GET")
+
+	// Browsers can't set headers on server-sent events requests, so the session cookie is trusted
+	BaseRoutes.ApiRoot.Handle("/events", ApiSessionRequiredTrustRequester(connectServerSentEvents)).Methods("GET")
+	BaseRoutes.ApiRoot.Handle("/events/poll", ApiSessionRequired(pollEvents)).Methods("GET")
 }
 
 func connectWebSocket(c *Context, w http.ResponseWriter, r *http.Request) {
+	// Special-purpose clients can ask to only be sent some event types or the events of some channels
+	filterChannelIds, filterEvents, filterErr := app.ParseWebConnFilter(r.URL.Query())
+	if filterErr != nil {
+		c.Err = filterErr
+		return
+	}
+
 	originChecker := utils.GetOriginChecker(r)
 
 	upgrader := websocket.Upgrader{
@@ -37,6 +52,15 @@ func connectWebSocket(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	wc := app.NewWebConn(ws, c.Session, c.T, "")
 
+	// Clients reconnecting after a short disconnect can ask for the events they missed
+	if connectionId := r.URL.Query().Get(model.WEBSOCKET_PARAM_CONNECTION_ID); len(connectionId) == 26 {
+		if lastSequence, err := strconv.ParseInt(r.URL.Query().Get(model.WEBSOCKET_PARAM_LAST_SEQUENCE), 10, 64); err == nil {
+			wc.RequestResume(connectionId, lastSequence)
+		}
+	}
+
+	wc.RestrictTo(filterChannelIds, filterEvents)
+
 	if len(c.Session.UserId) > 0 {
 		app.HubRegister(wc)
 	}
@@ -44,3 +68,106 @@ func connectWebSocket(c *Context, w http.ResponseWriter, r *http.Request) {
 	go wc.WritePump()
 	wc.ReadPump()
 }
+
+// connectServerSentEvents sends the events of a session as server-sent events, for clients that
+// can't open a websocket because a proxy in between breaks them. The id of each event is made of
+// the connection id and the event's sequence number so that clients resume the connection with
+// the Last-Event-ID header when they reconnect, which they also do when the server's write
+// timeout ends the response.
+func connectServerSentEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	filterChannelIds, filterEvents, filterErr := app.ParseWebConnFilter(r.URL.Query())
+	if filterErr != nil {
+		c.Err = filterErr
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.Err = model.NewAppError("connectServerSentEvents", "api.server_sent_events.connect.streaming.app_error", nil, "", http.StatusInternalServerError)
+		return
+	}
+
+	wc := app.NewServerSentEventsConn(c.Session, c.T, "")
+
+	// EventSource clients send the id of the last event they received when they reconnect and
+	// other clients can pass it in the query string instead
+	lastEventId := r.Header.Get(model.HEADER_LAST_EVENT_ID)
+	if len(lastEventId) == 0 {
+		lastEventId = r.URL.Query().Get(model.SERVER_SENT_EVENTS_PARAM_LAST_EVENT_ID)
+	}
+
+	if connectionId, lastSequence, ok := model.ParseServerSentEventId(lastEventId); ok {
+		wc.RequestResume(connectionId, lastSequence)
+	}
+
+	wc.RestrictTo(filterChannelIds, filterEvents)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	app.HubRegister(wc)
+	defer app.HubUnregister(wc)
+
+	ticker := time.NewTicker(app.PING_PERIOD)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-wc.Send:
+			if !ok {
+				return
+			}
+
+			event, ok := msg.(*model.WebSocketEvent)
+			if !ok {
+				continue
+			}
+
+			// The connection id changes when the connection is resumed, which happens before its
+			// first event is queued
+			if _, err := fmt.Fprintf(w, "id: %v\ndata: %v\n\n", model.ServerSentEventId(wc.ConnectionId, event.Sequence), event.ToJson()); err != nil {
+				l4g.Debug(fmt.Sprintf("server_sent_events.send: closing stream for userId=%v error=%v", wc.UserId, err.Error()))
+				return
+			}
+			flusher.Flush()
+
+			if event.Event == model.WEBSOCKET_EVENT_POSTED {
+				if einterfaces.GetMetricsInterface() != nil {
+					einterfaces.GetMetricsInterface().IncrementPostBroadcast()
+				}
+			}
+
+		case <-ticker.C:
+			// Comments are ignored by clients but keep proxies from closing an idle stream
+			if _, err := fmt.Fprint(w, ":\n\n"); err != nil {
+				l4g.Debug(fmt.Sprintf("server_sent_events.ticker: closing stream for userId=%v error=%v", wc.UserId, err.Error()))
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// pollEvents returns the events of the session that come after the since sequence number, waiting
+// for one if there aren't any yet, for clients in networks that block both websockets and
+// server-sent events. The events are kept in a queue for the session between polls.
+func pollEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	since := int64(-1)
+	if sinceString := r.URL.Query().Get("since"); len(sinceString) > 0 {
+		var err error
+		if since, err = strconv.ParseInt(sinceString, 10, 64); err != nil || since < -1 {
+			c.SetInvalidParam("since")
+			return
+		}
+	}
+
+	events := app.PollEvents(&c.Session, since, r.Context().Done())
+
+	w.Write([]byte(model.WebSocketEventListToJson(events)))
+}
@@ -4,10 +4,13 @@
 package api4
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 func TestWebSocket(t *testing.T) {
@@ -71,3 +74,326 @@ func TestWebSocket(t *testing.T) {
 		}
 	}
 }
+
+func TestWebSocketResume(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	nextEvent := func(client *model.WebSocketClient) *model.WebSocketEvent {
+		select {
+		case event := <-client.EventChannel:
+			return event
+		case <-time.After(2 * time.Second):
+			t.Fatal("should have received an event")
+		}
+		return nil
+	}
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	if event := nextEvent(WebSocketClient); event.Event != model.WEBSOCKET_EVENT_HELLO || event.Sequence != 0 {
+		t.Fatal("should have started with the hello event")
+	}
+
+	connectionId := WebSocketClient.ConnectionId
+	if len(connectionId) != 26 {
+		t.Fatal("should have been given a connection id")
+	}
+
+	// Events sent while the client is disconnected are replayed when it resumes
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	missed := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", th.BasicUser.Id, nil)
+	missed.Add("missed", "yes")
+	app.Publish(missed)
+	time.Sleep(300 * time.Millisecond)
+
+	if err := WebSocketClient.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	if event := nextEvent(WebSocketClient); event.Event != model.WEBSOCKET_EVENT_PREFERENCE_CHANGED || event.Sequence != 1 || event.Data["missed"] != "yes" {
+		t.Fatal("should have replayed the missed event", event)
+	}
+
+	if event := nextEvent(WebSocketClient); event.Event != model.WEBSOCKET_EVENT_HELLO || event.Sequence != 2 {
+		t.Fatal("should have continued the sequence", event)
+	}
+
+	if WebSocketClient.ConnectionId != connectionId {
+		t.Fatal("should have resumed the connection")
+	}
+
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	// Resuming an unknown connection starts a new one
+	WebSocketClient.ConnectionId = model.NewId()
+	if err := WebSocketClient.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+	defer WebSocketClient.Close()
+
+	if event := nextEvent(WebSocketClient); event.Event != model.WEBSOCKET_EVENT_HELLO || event.Sequence != 0 {
+		t.Fatal("should have started a new sequence", event)
+	}
+
+	if WebSocketClient.ConnectionId == connectionId {
+		t.Fatal("should have been given a new connection id")
+	}
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	nextEvent := func() *model.WebSocketEvent {
+		for {
+			select {
+			case event := <-WebSocketClient.EventChannel:
+				if event.Event != model.WEBSOCKET_EVENT_HELLO {
+					return event
+				}
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+	}
+
+	publish := func(event string, channelId string) {
+		app.Publish(model.NewWebSocketEvent(event, "", channelId, "", nil))
+	}
+
+	WebSocketClient.Unsubscribe([]string{th.BasicChannel.Id}, []string{model.WEBSOCKET_EVENT_TYPING})
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have unsubscribed", resp.Error)
+	}
+
+	publish(model.WEBSOCKET_EVENT_POSTED, th.BasicChannel.Id)
+	publish(model.WEBSOCKET_EVENT_TYPING, th.BasicChannel2.Id)
+	publish(model.WEBSOCKET_EVENT_POSTED, th.BasicChannel2.Id)
+
+	if event := nextEvent(); event == nil || event.Event != model.WEBSOCKET_EVENT_POSTED || event.Broadcast.ChannelId != th.BasicChannel2.Id {
+		t.Fatal("should only have received the events that were still subscribed to", event)
+	}
+
+	WebSocketClient.Subscribe([]string{th.BasicChannel.Id}, nil)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have subscribed", resp.Error)
+	}
+
+	publish(model.WEBSOCKET_EVENT_TYPING, th.BasicChannel.Id)
+	publish(model.WEBSOCKET_EVENT_POSTED, th.BasicChannel.Id)
+
+	if event := nextEvent(); event == nil || event.Event != model.WEBSOCKET_EVENT_POSTED || event.Broadcast.ChannelId != th.BasicChannel.Id {
+		t.Fatal("should have received the events of the channel again", event)
+	}
+
+	WebSocketClient.Unsubscribe(nil, nil)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Error == nil || resp.Error.Id != "api.websocket_handler.invalid_param.app_error" {
+		t.Fatal("should have been invalid param response")
+	}
+
+	WebSocketClient.Subscribe([]string{"junk"}, nil)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Error == nil || resp.Error.Id != "api.websocket_handler.invalid_param.app_error" {
+		t.Fatal("should have been invalid param response")
+	}
+}
+
+func TestWebSocketFilter(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	WebSocketClient, err := model.NewWebSocketClient4WithFilter("ws://localhost"+utils.Cfg.ServiceSettings.ListenAddress, th.Client.AuthToken, []string{th.BasicChannel.Id}, []string{model.WEBSOCKET_EVENT_POSTED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	app.Publish(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", th.BasicChannel.Id, "", nil))
+	app.Publish(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", th.BasicChannel2.Id, "", nil))
+	app.Publish(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", th.BasicChannel.Id, "", nil))
+
+	for {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_HELLO {
+				continue
+			}
+
+			if event.Event != model.WEBSOCKET_EVENT_POSTED || event.Broadcast.ChannelId != th.BasicChannel.Id {
+				t.Fatal("should only have received the requested events", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("should have received the requested event")
+		}
+
+		break
+	}
+
+	if _, err := model.NewWebSocketClient4WithFilter("ws://localhost"+utils.Cfg.ServiceSettings.ListenAddress, th.Client.AuthToken, []string{"junk"}, nil); err == nil {
+		t.Fatal("should have failed to connect with an invalid filter")
+	}
+}
+
+func TestServerSentEvents(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+
+	nextEvent := func(client *model.WebSocketClient) *model.WebSocketEvent {
+		select {
+		case event := <-client.EventChannel:
+			return event
+		case <-time.After(2 * time.Second):
+			t.Fatal("should have received an event")
+		}
+		return nil
+	}
+
+	url := "ws://localhost" + utils.Cfg.ServiceSettings.ListenAddress
+
+	client, err := model.NewServerSentEventsClient4(url, th.Client.AuthToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Listen()
+
+	if event := nextEvent(client); event.Event != model.WEBSOCKET_EVENT_HELLO || event.Sequence != 0 {
+		t.Fatal("should have started with the hello event")
+	}
+
+	connectionId := client.ConnectionId
+	if len(connectionId) != 26 {
+		t.Fatal("should have been given a connection id")
+	}
+
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", th.BasicUser.Id, nil)
+	event.Add("streamed", "yes")
+	app.Publish(event)
+
+	if event := nextEvent(client); event.Event != model.WEBSOCKET_EVENT_PREFERENCE_CHANGED || event.Sequence != 1 || event.Data["streamed"] != "yes" {
+		t.Fatal("should have streamed the event", event)
+	}
+
+	// Events sent while the client is disconnected are replayed when it resumes with the id of the
+	// last event it received
+	client.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	missed := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", th.BasicUser.Id, nil)
+	missed.Add("missed", "yes")
+	app.Publish(missed)
+	time.Sleep(300 * time.Millisecond)
+
+	if err := client.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	client.Listen()
+	defer client.Close()
+
+	if event := nextEvent(client); event.Event != model.WEBSOCKET_EVENT_PREFERENCE_CHANGED || event.Sequence != 2 || event.Data["missed"] != "yes" {
+		t.Fatal("should have replayed the missed event", event)
+	}
+
+	if event := nextEvent(client); event.Event != model.WEBSOCKET_EVENT_HELLO || event.Sequence != 3 {
+		t.Fatal("should have continued the sequence", event)
+	}
+
+	if client.ConnectionId != connectionId {
+		t.Fatal("should have resumed the connection")
+	}
+
+	// Messages can't be sent over the stream
+	client.UserTyping(th.BasicChannel.Id, "")
+
+	if _, err := model.NewServerSentEventsClient4(url, model.NewId()); err == nil {
+		t.Fatal("should have failed to connect without a valid session")
+	}
+}
+
+func TestPollEvents(t *testing.T) {
+	th := Setup().InitBasic()
+	defer TearDown()
+	Client := th.Client
+
+	events, resp := Client.PollEvents(-1)
+	CheckNoError(t, resp)
+
+	if len(events) != 1 || events[0].Event != model.WEBSOCKET_EVENT_HELLO || events[0].Sequence != 0 {
+		t.Fatal("should have started with the hello event", events)
+	}
+
+	connectionId := events[0].Data[model.WEBSOCKET_PARAM_CONNECTION_ID]
+
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", th.BasicUser.Id, nil)
+	event.Add("queued", "yes")
+	app.Publish(event)
+	time.Sleep(300 * time.Millisecond)
+
+	events, resp = Client.PollEvents(0)
+	CheckNoError(t, resp)
+
+	if len(events) != 1 || events[0].Event != model.WEBSOCKET_EVENT_PREFERENCE_CHANGED || events[0].Sequence != 1 || events[0].Data["queued"] != "yes" {
+		t.Fatal("should have returned the queued event", events)
+	}
+
+	// Polls wait for the next event
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+
+		event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", th.BasicUser.Id, nil)
+		event.Add("waited", "yes")
+		app.Publish(event)
+	}()
+
+	start := time.Now()
+	events, resp = Client.PollEvents(1)
+	CheckNoError(t, resp)
+
+	if len(events) != 1 || events[0].Sequence != 2 || events[0].Data["waited"] != "yes" {
+		t.Fatal("should have waited for the next event", events)
+	}
+
+	if time.Since(start) >= app.EVENT_POLL_TIMEOUT {
+		t.Fatal("should have returned as soon as the event was sent")
+	}
+
+	// Every event still queued is returned again if asked for
+	events, resp = Client.PollEvents(-1)
+	CheckNoError(t, resp)
+
+	if len(events) != 3 || events[0].Data[model.WEBSOCKET_PARAM_CONNECTION_ID] != connectionId {
+		t.Fatal("should have returned all of the queued events", events)
+	}
+
+	if _, err := Client.DoApiGet(Client.GetEventsRoute()+"/poll?since=junk", ""); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("should have failed with an invalid sequence number")
+	}
+
+	Client.Logout()
+	_, resp = Client.PollEvents(-1)
+	CheckUnauthorizedStatus(t, resp)
+}
@@ -5,8 +5,8 @@ package app
 
 import (
 	"bufio"
+	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"runtime/debug"
@@ -18,6 +18,13 @@ import (
 	"github.com/mattermost/platform/utils"
 )
 
+const (
+	CACHE_METRICS_TASK_NAME     = "Cache Metrics"
+	CACHE_METRICS_TASK_INTERVAL = 1 * time.Minute
+	CACHE_SIZING_TASK_NAME      = "Cache Sizing"
+	CACHE_SIZING_TASK_INTERVAL  = 5 * time.Minute
+)
+
 func GetLogs(page, perPage int) ([]string, *model.AppError) {
 	lines, err := GetLogsSkipSend(page, perPage)
 	if err != nil {
@@ -39,8 +46,8 @@ func GetLogs(page, perPage int) ([]string, *model.AppError) {
 func GetLogsSkipSend(page, perPage int) ([]string, *model.AppError) {
 	var lines []string
 
-	if utils.Cfg.LogSettings.EnableFile {
-		file, err := os.Open(utils.GetLogFileLocation(utils.Cfg.LogSettings.FileLocation))
+	if Config().LogSettings.EnableFile {
+		file, err := os.Open(utils.GetLogFileLocation(Config().LogSettings.FileLocation))
 		if err != nil {
 			return nil, model.NewLocAppError("getLogs", "api.admin.file_read_error", nil, err.Error())
 		}
@@ -69,6 +76,7 @@ func GetLogsSkipSend(page, perPage int) ([]string, *model.AppError) {
 	return lines, nil
 }
 
+// GetClusterStatus describes every node of the cluster, including this one.
 func GetClusterStatus() []*model.ClusterInfo {
 	infos := make([]*model.ClusterInfo, 0)
 
@@ -76,6 +84,25 @@ func GetClusterStatus() []*model.ClusterInfo {
 		infos = einterfaces.GetClusterInterface().GetClusterInfos()
 	}
 
+	me := GetMyClusterInfo()
+	found := false
+	for _, info := range infos {
+		if info.IdEqualTo(me.Id) {
+			found = true
+		}
+	}
+
+	if !found {
+		infos = append(infos, me)
+	}
+
+	now := model.GetMillis()
+	for _, info := range infos {
+		if info.StartTime > 0 {
+			info.Uptime = now - info.StartTime
+		}
+	}
+
 	return infos
 }
 
@@ -104,9 +131,74 @@ func InvalidateAllCachesSkipSend() {
 	LoadLicense()
 }
 
+// GetCacheStats returns the statistics of the in memory caches of this server.
+func GetCacheStats() []*model.CacheStats {
+	return utils.GetNamedCacheStats()
+}
+
+// InvalidateCache purges a single in memory cache on every server of the cluster.
+func InvalidateCache(cacheName string) *model.AppError {
+	if err := InvalidateCacheSkipSend(cacheName); err != nil {
+		return err
+	}
+
+	if einterfaces.GetClusterInterface() != nil {
+		if err := einterfaces.GetClusterInterface().InvalidateCache(cacheName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func InvalidateCacheSkipSend(cacheName string) *model.AppError {
+	l4g.Info(utils.T("api.context.invalidate_cache"), cacheName)
+
+	if !utils.PurgeNamedCache(cacheName) {
+		return model.NewAppError("InvalidateCache", "app.admin.invalidate_cache.not_found.app_error", map[string]interface{}{"Name": cacheName}, "", http.StatusNotFound)
+	}
+
+	return nil
+}
+
+// InitCacheMetrics periodically reports the statistics of the in memory caches through the
+// metrics interface.
+func InitCacheMetrics() {
+	if task := model.GetTaskByName(CACHE_METRICS_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	if einterfaces.GetMetricsInterface() != nil {
+		model.CreateRecurringTask(CACHE_METRICS_TASK_NAME, ReportCacheMetrics, CACHE_METRICS_TASK_INTERVAL)
+	}
+}
+
+func ReportCacheMetrics() {
+	if metrics := einterfaces.GetMetricsInterface(); metrics != nil {
+		for _, stats := range GetCacheStats() {
+			metrics.ObserveMemCacheStats(stats)
+		}
+	}
+}
+
+func InitCacheSizing() {
+	if task := model.GetTaskByName(CACHE_SIZING_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(CACHE_SIZING_TASK_NAME, ResizeCaches, CACHE_SIZING_TASK_INTERVAL)
+}
+
+// ResizeCaches scales the in memory caches to the configured memory budget as the size of their
+// entries changes. Without a budget the caches keep the sizes set when the config was loaded.
+func ResizeCaches() {
+	if *Config().CacheSettings.MemoryBudgetMB > 0 {
+		utils.ResizeNamedCaches(&Config().CacheSettings)
+	}
+}
+
 func GetConfig() *model.Config {
-	json := utils.Cfg.ToJson()
-	cfg := model.ConfigFromJson(strings.NewReader(json))
+	cfg := utils.CloneConfig(Config())
 	cfg.Sanitize()
 
 	return cfg
@@ -118,6 +210,9 @@ func ReloadConfig() {
 
 	// start/restart email batching job if necessary
 	InitEmailBatching()
+
+	// restart the audit sinks in case their settings changed
+	InitAuditSinks()
 }
 
 func SaveConfig(cfg *model.Config) *model.AppError {
@@ -132,7 +227,7 @@ func SaveConfig(cfg *model.Config) *model.AppError {
 		return err
 	}
 
-	if *utils.Cfg.ClusterSettings.Enable {
+	if *Config().ClusterSettings.Enable {
 		return model.NewLocAppError("saveConfig", "ent.cluster.save_config.error", nil, "")
 	}
 
@@ -143,7 +238,7 @@ func SaveConfig(cfg *model.Config) *model.AppError {
 	utils.EnableConfigWatch()
 
 	if einterfaces.GetMetricsInterface() != nil {
-		if *utils.Cfg.MetricsSettings.Enable {
+		if *Config().MetricsSettings.Enable {
 			einterfaces.GetMetricsInterface().StartServer()
 		} else {
 			einterfaces.GetMetricsInterface().StopServer()
@@ -161,6 +256,9 @@ func SaveConfig(cfg *model.Config) *model.AppError {
 	// start/restart email batching job if necessary
 	InitEmailBatching()
 
+	// restart the audit sinks in case their settings changed
+	InitAuditSinks()
+
 	return nil
 }
 
@@ -176,6 +274,15 @@ func RecycleDatabaseConnection() {
 	l4g.Warn(utils.T("api.admin.recycle_db_end.warn"))
 }
 
+// GetSlowQueries returns the database queries that were slow on this server.
+func GetSlowQueries() []*model.SlowQuery {
+	return Srv.Store.GetSlowQueries()
+}
+
+func ClearSlowQueries() {
+	Srv.Store.ClearSlowQueries()
+}
+
 func TestEmail(userId string, cfg *model.Config) *model.AppError {
 	if len(cfg.EmailSettings.SMTPServer) == 0 {
 		return model.NewLocAppError("testEmail", "api.admin.test_email.missing_server", nil, utils.T("api.context.invalid_param.app_error", map[string]interface{}{"Name": "SMTPServer"}))
@@ -184,10 +291,10 @@ func TestEmail(userId string, cfg *model.Config) *model.AppError {
 	// if the user hasn't changed their email settings, fill in the actual SMTP password so that
 	// the user can verify an existing SMTP connection
 	if cfg.EmailSettings.SMTPPassword == model.FAKE_SETTING {
-		if cfg.EmailSettings.SMTPServer == utils.Cfg.EmailSettings.SMTPServer &&
-			cfg.EmailSettings.SMTPPort == utils.Cfg.EmailSettings.SMTPPort &&
-			cfg.EmailSettings.SMTPUsername == utils.Cfg.EmailSettings.SMTPUsername {
-			cfg.EmailSettings.SMTPPassword = utils.Cfg.EmailSettings.SMTPPassword
+		if cfg.EmailSettings.SMTPServer == Config().EmailSettings.SMTPServer &&
+			cfg.EmailSettings.SMTPPort == Config().EmailSettings.SMTPPort &&
+			cfg.EmailSettings.SMTPUsername == Config().EmailSettings.SMTPUsername {
+			cfg.EmailSettings.SMTPPassword = Config().EmailSettings.SMTPPassword
 		} else {
 			return model.NewLocAppError("testEmail", "api.admin.test_email.reenter_password", nil, "")
 		}
@@ -0,0 +1,326 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func CreateAlertmanagerReceiver(receiver *model.AlertmanagerReceiver) (*model.AlertmanagerReceiver, *model.AppError) {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return nil, model.NewAppError("CreateAlertmanagerReceiver", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Alertmanager().SaveReceiver(receiver); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.AlertmanagerReceiver), nil
+	}
+}
+
+func GetAlertmanagerReceiver(receiverId string) (*model.AlertmanagerReceiver, *model.AppError) {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return nil, model.NewAppError("GetAlertmanagerReceiver", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Alertmanager().GetReceiver(receiverId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.AlertmanagerReceiver), nil
+	}
+}
+
+func GetAlertmanagerReceiversForTeamPage(teamId string, page, perPage int) ([]*model.AlertmanagerReceiver, *model.AppError) {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return nil, model.NewAppError("GetAlertmanagerReceiversForTeamPage", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Alertmanager().GetReceiversByTeam(teamId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.AlertmanagerReceiver), nil
+	}
+}
+
+func UpdateAlertmanagerReceiver(oldReceiver, updatedReceiver *model.AlertmanagerReceiver) (*model.AlertmanagerReceiver, *model.AppError) {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return nil, model.NewAppError("UpdateAlertmanagerReceiver", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	updatedReceiver.Id = oldReceiver.Id
+	updatedReceiver.CreatorId = oldReceiver.CreatorId
+	updatedReceiver.CreateAt = oldReceiver.CreateAt
+	updatedReceiver.TeamId = oldReceiver.TeamId
+	updatedReceiver.DeleteAt = oldReceiver.DeleteAt
+
+	if updatedReceiver.Credentials == nil {
+		updatedReceiver.Credentials = make(model.EncryptStringMap)
+	}
+
+	// A sanitized receiver sent back unchanged should not overwrite the stored password
+	if updatedReceiver.Credentials["password"] == model.FAKE_SETTING {
+		updatedReceiver.Credentials["password"] = oldReceiver.Credentials["password"]
+	}
+
+	if result := <-Srv.Store.Alertmanager().UpdateReceiver(updatedReceiver); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.AlertmanagerReceiver), nil
+	}
+}
+
+func DeleteAlertmanagerReceiver(receiverId string) *model.AppError {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return model.NewAppError("DeleteAlertmanagerReceiver", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Alertmanager().DeleteReceiver(receiverId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// HandleAlertmanagerMessage posts a notification from Alertmanager into the receiver's channel. Later
+// notifications for the same alert group, including the one sent when it resolves, update that post.
+func HandleAlertmanagerMessage(receiverId string, msg *model.AlertmanagerMessage) *model.AppError {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
+		return model.NewAppError("HandleAlertmanagerMessage", "web.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if msg == nil {
+		return model.NewAppError("HandleAlertmanagerMessage", "web.incoming_webhook.parse.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if err := msg.IsValid(); err != nil {
+		err.StatusCode = http.StatusBadRequest
+		return err
+	}
+
+	var receiver *model.AlertmanagerReceiver
+	if result := <-Srv.Store.Alertmanager().GetReceiver(receiverId); result.Err != nil {
+		return model.NewAppError("HandleAlertmanagerMessage", "web.incoming_webhook.invalid.app_error", nil, "err="+result.Err.Message, http.StatusBadRequest)
+	} else {
+		receiver = result.Data.(*model.AlertmanagerReceiver)
+	}
+
+	attachments := []*model.SlackAttachment{msg.ToAttachment()}
+
+	alertPost := &model.AlertmanagerPost{
+		ReceiverId: receiver.Id,
+		GroupKey:   msg.GroupKey,
+		Status:     msg.Status,
+		Labels:     model.StringMap{},
+	}
+
+	for _, matcher := range msg.SilenceMatchers() {
+		alertPost.Labels[matcher.Name] = matcher.Value
+	}
+
+	if result := <-Srv.Store.Alertmanager().GetPost(receiver.Id, msg.GroupKey); result.Err == nil {
+		existing := result.Data.(*model.AlertmanagerPost)
+		if post, err := GetSinglePost(existing.PostId); err == nil {
+			post.AddProp("attachments", attachments)
+			if _, err := updateAlertmanagerPost(post); err != nil {
+				return err
+			}
+
+			alertPost.PostId = post.Id
+			if result := <-Srv.Store.Alertmanager().SavePost(alertPost); result.Err != nil {
+				return result.Err
+			}
+
+			return nil
+		}
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return result.Err
+	}
+
+	// Resolved notifications for a group we have never posted about are not worth a new post
+	if msg.IsResolved() {
+		return nil
+	}
+
+	props := model.StringInterface{
+		"attachments":                            attachments,
+		model.POST_PROP_ALERTMANAGER_RECEIVER_ID: receiver.Id,
+		model.POST_PROP_ALERTMANAGER_GROUP_KEY:   msg.GroupKey,
+	}
+
+	post, err := CreateWebhookPost(receiver.CreatorId, receiver.TeamId, receiver.ChannelId, "", receiver.DisplayName, "", props, model.POST_SLACK_ATTACHMENT, model.INTEGRATION_TYPE_ALERTMANAGER, receiver.Id)
+	if err != nil {
+		return err
+	}
+
+	alertPost.PostId = post.Id
+	if result := <-Srv.Store.Alertmanager().SavePost(alertPost); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// DoPostAction runs the action with the given id from one of the post's attachments on behalf of
+// the user who clicked it.
+func DoPostAction(postId, actionId, userId string, duration time.Duration) (*model.Post, *model.AppError) {
+	post, err := GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	receiverId, _ := post.Props[model.POST_PROP_ALERTMANAGER_RECEIVER_ID].(string)
+	groupKey, _ := post.Props[model.POST_PROP_ALERTMANAGER_GROUP_KEY].(string)
+	if len(receiverId) == 0 || len(groupKey) == 0 {
+		return nil, model.NewAppError("DoPostAction", "api.post.do_action.action_id.app_error", nil, "post_id="+postId, http.StatusNotFound)
+	}
+
+	attachments := post.Attachments()
+
+	var attachment *model.SlackAttachment
+	for _, a := range attachments {
+		for _, action := range a.Actions {
+			if action.Id == actionId {
+				attachment = a
+			}
+		}
+	}
+
+	if attachment == nil {
+		return nil, model.NewAppError("DoPostAction", "api.post.do_action.action_id.app_error", nil, "action_id="+actionId, http.StatusNotFound)
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch actionId {
+	case model.ALERTMANAGER_ACTION_SILENCE:
+		if duration <= 0 {
+			duration = model.ALERTMANAGER_DEFAULT_SILENCE_DURATION
+		} else if duration > model.ALERTMANAGER_MAX_SILENCE_DURATION {
+			duration = model.ALERTMANAGER_MAX_SILENCE_DURATION
+		}
+
+		if err := silenceAlertmanagerGroup(receiverId, groupKey, user, duration); err != nil {
+			return nil, err
+		}
+
+		attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{
+			Title: "Silenced",
+			Value: fmt.Sprintf("by @%s for %s", user.Username, duration.String()),
+			Short: false,
+		})
+		removePostAction(attachment, model.ALERTMANAGER_ACTION_SILENCE)
+	case model.ALERTMANAGER_ACTION_ACK:
+		attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{
+			Title: "Acknowledged",
+			Value: "by @" + user.Username,
+			Short: false,
+		})
+		removePostAction(attachment, model.ALERTMANAGER_ACTION_ACK)
+	default:
+		return nil, model.NewAppError("DoPostAction", "api.post.do_action.action_id.app_error", nil, "action_id="+actionId, http.StatusNotFound)
+	}
+
+	post.AddProp("attachments", attachments)
+
+	return updateAlertmanagerPost(post)
+}
+
+func silenceAlertmanagerGroup(receiverId, groupKey string, user *model.User, duration time.Duration) *model.AppError {
+	rchan := Srv.Store.Alertmanager().GetReceiver(receiverId)
+	pchan := Srv.Store.Alertmanager().GetPost(receiverId, groupKey)
+
+	var receiver *model.AlertmanagerReceiver
+	if result := <-rchan; result.Err != nil {
+		return result.Err
+	} else {
+		receiver = result.Data.(*model.AlertmanagerReceiver)
+	}
+
+	var alertPost *model.AlertmanagerPost
+	if result := <-pchan; result.Err != nil {
+		return result.Err
+	} else {
+		alertPost = result.Data.(*model.AlertmanagerPost)
+	}
+
+	matchers := model.AlertmanagerMatchersFromLabels(alertPost.Labels)
+	if len(matchers) == 0 {
+		return model.NewAppError("silenceAlertmanagerGroup", "api.alertmanager.silence.matchers.app_error", nil, "group_key="+groupKey, http.StatusBadRequest)
+	}
+
+	now := time.Now().UTC()
+	silence := &model.AlertmanagerSilence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: user.Username,
+		Comment:   "Silenced from " + utils.ClientCfg["SiteName"] + " by @" + user.Username,
+	}
+
+	req, _ := http.NewRequest("POST", receiver.AlertmanagerURL+"/api/v1/silences", strings.NewReader(silence.ToJson()))
+	req.Header.Set("Content-Type", "application/json")
+	if username := receiver.Credentials["username"]; len(username) > 0 {
+		req.SetBasicAuth(username, receiver.Credentials["password"])
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return model.NewAppError("silenceAlertmanagerGroup", "api.alertmanager.silence.request.app_error", nil, "err="+err.Error(), http.StatusBadGateway)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		l4g.Error(utils.T("api.alertmanager.silence.response.error"), receiver.Id, resp.StatusCode)
+		return model.NewAppError("silenceAlertmanagerGroup", "api.alertmanager.silence.response.app_error", nil, fmt.Sprintf("status=%v", resp.StatusCode), http.StatusBadGateway)
+	}
+
+	return nil
+}
+
+func removePostAction(attachment *model.SlackAttachment, actionId string) {
+	actions := []*model.PostAction{}
+	for _, action := range attachment.Actions {
+		if action.Id != actionId {
+			actions = append(actions, action)
+		}
+	}
+	attachment.Actions = actions
+}
+
+// updateAlertmanagerPost saves changes to a post owned by an integration. Unlike UpdatePost it
+// neither checks who is editing nor marks the post as edited, since the integration itself is the author.
+func updateAlertmanagerPost(post *model.Post) (*model.Post, *model.AppError) {
+	var oldPost *model.Post
+	if result := <-Srv.Store.Post().Get(post.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		oldPost = result.Data.(*model.PostList).Posts[post.Id]
+	}
+
+	newPost := &model.Post{}
+	*newPost = *oldPost
+	newPost.Props = post.Props
+
+	if result := <-Srv.Store.Post().Update(newPost, oldPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rpost := result.Data.(*model.Post)
+
+		sendUpdatedPostEvent(rpost)
+
+		InvalidateCacheForChannelPosts(rpost.ChannelId)
+
+		return rpost, nil
+	}
+}
@@ -14,6 +14,8 @@ import (
 const (
 	DAY_MILLISECONDS   = 24 * 60 * 60 * 1000
 	MONTH_MILLISECONDS = 31 * DAY_MILLISECONDS
+
+	TOP_REACTIONS_ANALYTICS_LIMIT = 10
 )
 
 func GetAnalytics(name string, teamId string) (model.AnalyticsRows, *model.AppError) {
@@ -23,8 +25,8 @@ func GetAnalytics(name string, teamId string) (model.AnalyticsRows, *model.AppEr
 		return nil, r.Err
 	} else {
 		systemUserCount = r.Data.(int64)
-		if systemUserCount > int64(*utils.Cfg.AnalyticsSettings.MaxUsersForStatistics) {
-			l4g.Debug("More than %v users on the system, intensive queries skipped", *utils.Cfg.AnalyticsSettings.MaxUsersForStatistics)
+		if systemUserCount > int64(*Config().AnalyticsSettings.MaxUsersForStatistics) {
+			l4g.Debug("More than %v users on the system, intensive queries skipped", *Config().AnalyticsSettings.MaxUsersForStatistics)
 			skipIntensiveQueries = true
 		}
 	}
@@ -98,7 +100,7 @@ func GetAnalytics(name string, teamId string) (model.AnalyticsRows, *model.AppEr
 		}
 
 		// If in HA mode then aggregrate all the stats
-		if einterfaces.GetClusterInterface() != nil && *utils.Cfg.ClusterSettings.Enable {
+		if einterfaces.GetClusterInterface() != nil && *Config().ClusterSettings.Enable {
 			stats, err := einterfaces.GetClusterInterface().GetClusterStats()
 			if err != nil {
 				return nil, err
@@ -225,6 +227,71 @@ func GetAnalytics(name string, teamId string) (model.AnalyticsRows, *model.AppEr
 		}
 
 		return rows, nil
+	} else if name == "reaction_counts" {
+		var rows model.AnalyticsRows = make([]*model.AnalyticsRow, 3)
+		rows[0] = &model.AnalyticsRow{Name: "reaction_count", Value: -1}
+		rows[1] = &model.AnalyticsRow{Name: "post_with_reactions_count", Value: -1}
+		rows[2] = &model.AnalyticsRow{Name: "post_with_reactions_ratio", Value: -1}
+
+		if skipIntensiveQueries {
+			return rows, nil
+		}
+
+		reactionChan := Srv.Store.Reaction().AnalyticsReactionCounts(teamId)
+		postChan := Srv.Store.Post().AnalyticsPostCount(teamId, false, false)
+
+		if r := <-reactionChan; r.Err != nil {
+			return nil, r.Err
+		} else {
+			counts := r.Data.(model.AnalyticsRows)
+			rows[0].Value = counts[0].Value
+			rows[1].Value = counts[1].Value
+		}
+
+		if r := <-postChan; r.Err != nil {
+			return nil, r.Err
+		} else if postCount := r.Data.(int64); postCount > 0 {
+			rows[2].Value = rows[1].Value / float64(postCount)
+		} else {
+			rows[2].Value = 0
+		}
+
+		return rows, nil
+	} else if name == "reaction_counts_day" {
+		if skipIntensiveQueries {
+			rows := model.AnalyticsRows{&model.AnalyticsRow{Name: "", Value: -1}}
+			return rows, nil
+		}
+
+		if r := <-Srv.Store.Reaction().AnalyticsReactionCountsByDay(teamId); r.Err != nil {
+			return nil, r.Err
+		} else {
+			return r.Data.(model.AnalyticsRows), nil
+		}
+	} else if name == "top_reactions" {
+		// The top reactions are only counted for a team
+		if len(teamId) == 0 {
+			return nil, nil
+		}
+
+		if skipIntensiveQueries {
+			rows := model.AnalyticsRows{&model.AnalyticsRow{Name: "", Value: -1}}
+			return rows, nil
+		}
+
+		// Counting from the start of a day keeps the results cached for the rest of it
+		since := utils.MillisFromTime(utils.StartOfDay(utils.Yesterday().AddDate(0, 0, -29)))
+
+		if r := <-Srv.Store.Reaction().GetTopReactionsForTeam(teamId, since, TOP_REACTIONS_ANALYTICS_LIMIT); r.Err != nil {
+			return nil, r.Err
+		} else {
+			rows := model.AnalyticsRows{}
+			for _, count := range r.Data.([]*model.ReactionCount) {
+				rows = append(rows, &model.AnalyticsRow{Name: count.EmojiName, Value: float64(count.Count)})
+			}
+
+			return rows, nil
+		}
 	}
 
 	return nil, nil
@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// UpdateChannelAnnouncementSettings turns a channel into an announcement channel that only its
+// designated posters and admins can post in, or back into a regular channel. When autoAddTeamMembers
+// is turned on, every member of the team is added to the channel and its members can't leave it.
+func UpdateChannelAnnouncementSettings(channel *model.Channel, isAnnouncement bool, autoAddTeamMembers bool, userRequestorId string) (*model.Channel, *model.AppError) {
+	if channel.IsGroupOrDirect() {
+		return nil, model.NewAppError("UpdateChannelAnnouncementSettings", "app.channel.update_announcement_settings.group_or_direct.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if autoAddTeamMembers && !isAnnouncement {
+		return nil, model.NewAppError("UpdateChannelAnnouncementSettings", "app.channel.update_announcement_settings.auto_add.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	addTeamMembers := autoAddTeamMembers && !channel.AutoAddTeamMembers
+
+	channel.IsAnnouncement = isAnnouncement
+	channel.AutoAddTeamMembers = autoAddTeamMembers
+
+	rchannel, err := UpdateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if addTeamMembers {
+		if err := addTeamMembersToChannel(rchannel, userRequestorId); err != nil {
+			return nil, err
+		}
+	}
+
+	return rchannel, nil
+}
+
+// UpdateChannelAnnouncementPosters sets the members of an announcement channel that can post in it
+// besides its admins.
+func UpdateChannelAnnouncementPosters(channel *model.Channel, userIds []string) (*model.Channel, *model.AppError) {
+	posters := model.StringArray{}
+	seen := make(map[string]bool)
+	for _, userId := range userIds {
+		if len(userId) != 26 {
+			return nil, model.NewAppError("UpdateChannelAnnouncementPosters", "app.channel.update_announcement_posters.user_id.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+		}
+
+		if !seen[userId] {
+			seen[userId] = true
+			posters = append(posters, userId)
+		}
+	}
+
+	if len(posters) > model.CHANNEL_ANNOUNCEMENT_POSTERS_MAX {
+		return nil, model.NewAppError("UpdateChannelAnnouncementPosters", "app.channel.update_announcement_posters.size.app_error", map[string]interface{}{"Max": model.CHANNEL_ANNOUNCEMENT_POSTERS_MAX}, "", http.StatusBadRequest)
+	}
+
+	if len(posters) > 0 {
+		if result := <-Srv.Store.Channel().GetMembersByIds(channel.Id, posters); result.Err != nil {
+			return nil, result.Err
+		} else if members := *result.Data.(*model.ChannelMembers); len(members) != len(posters) {
+			return nil, model.NewAppError("UpdateChannelAnnouncementPosters", "app.channel.update_announcement_posters.not_member.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+		}
+	}
+
+	channel.AnnouncementPosters = posters
+	return UpdateChannel(channel)
+}
+
+// checkAnnouncementPoster returns a 403 if the channel is an announcement channel that the user wasn't
+// designated to post in and doesn't administer.
+func checkAnnouncementPoster(channel *model.Channel, userId string) *model.AppError {
+	if !channel.IsAnnouncement || channel.IsAnnouncementPoster(userId) {
+		return nil
+	}
+
+	if HasPermissionToChannel(userId, channel.Id, model.PERMISSION_MANAGE_CHANNEL_ROLES) {
+		return nil
+	}
+
+	return model.NewAppError("checkAnnouncementPoster", "app.channel.announcement.create_post.app_error", nil, "channel_id="+channel.Id+", user_id="+userId, http.StatusForbidden)
+}
+
+// addTeamMembersToChannel adds every member of the channel's team, except for guests, to the channel.
+func addTeamMembersToChannel(channel *model.Channel, userRequestorId string) *model.AppError {
+	for offset := 0; ; offset += CHANNEL_MEMBERS_BATCH_MAX_SIZE {
+		var teamMembers []*model.TeamMember
+		if result := <-Srv.Store.Team().GetMembers(channel.TeamId, offset, CHANNEL_MEMBERS_BATCH_MAX_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			teamMembers = result.Data.([]*model.TeamMember)
+		}
+
+		userIds := []string{}
+		for _, member := range teamMembers {
+			if !model.IsInRole(member.Roles, model.ROLE_TEAM_GUEST.Id) {
+				userIds = append(userIds, member.UserId)
+			}
+		}
+
+		if len(userIds) > 0 {
+			if _, err := AddChannelMembers(userIds, channel, userRequestorId); err != nil {
+				return err
+			}
+		}
+
+		if len(teamMembers) < CHANNEL_MEMBERS_BATCH_MAX_SIZE {
+			return nil
+		}
+	}
+}
+
+// joinAutoAddChannels adds a user that just joined a team to the team's announcement channels that
+// every team member is added to.
+func joinAutoAddChannels(teamId string, user *model.User) *model.AppError {
+	var channels []*model.Channel
+	if result := <-Srv.Store.Channel().GetAutoAddChannelsForTeam(teamId); result.Err != nil {
+		return result.Err
+	} else {
+		channels = result.Data.([]*model.Channel)
+	}
+
+	for _, channel := range channels {
+		if _, err := AddUserToChannel(user, channel); err != nil {
+			return err
+		}
+
+		if err := postJoinChannelMessage(user, channel); err != nil {
+			l4g.Error(utils.T("api.channel.post_user_add_remove_message_and_forget.error"), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	ARCHIVE_EXPORT_POSTS_PER_PAGE   = 1000
+	ARCHIVE_EXPORT_MEMBERS_PER_PAGE = 1000
+	ARCHIVE_EXPORT_USERS_PER_PAGE   = 100
+)
+
+// CreateArchiveExport saves a request for an archive of a channel or a team and starts generating
+// it in the background. The returned export is pending and can be polled for its progress.
+func CreateArchiveExport(export *model.ArchiveExport) (*model.ArchiveExport, *model.AppError) {
+	if len(Config().FileSettings.DriverName) == 0 {
+		return nil, model.NewAppError("CreateArchiveExport", "app.archive_export.storage.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	switch export.Type {
+	case model.ARCHIVE_EXPORT_TYPE_CHANNEL:
+		channel, err := GetChannel(export.ChannelId)
+		if err != nil {
+			return nil, err
+		}
+
+		export.TeamId = channel.TeamId
+	case model.ARCHIVE_EXPORT_TYPE_TEAM:
+		if _, err := GetTeam(export.TeamId); err != nil {
+			return nil, err
+		}
+
+		export.ChannelId = ""
+	default:
+		return nil, model.NewAppError("CreateArchiveExport", "model.archive_export.is_valid.type.app_error", nil, "type="+export.Type, http.StatusBadRequest)
+	}
+
+	export.Id = ""
+
+	if result := <-Srv.Store.ArchiveExport().Save(export); result.Err != nil {
+		return nil, result.Err
+	} else {
+		export = result.Data.(*model.ArchiveExport)
+	}
+
+	go RunArchiveExport(export)
+
+	return export, nil
+}
+
+func GetArchiveExport(exportId string) (*model.ArchiveExport, *model.AppError) {
+	if result := <-Srv.Store.ArchiveExport().Get(exportId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ArchiveExport), nil
+	}
+}
+
+func GetArchiveExports(page, perPage int) ([]*model.ArchiveExport, *model.AppError) {
+	if result := <-Srv.Store.ArchiveExport().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ArchiveExport), nil
+	}
+}
+
+// GetArchiveExportFile returns the archive of an export that has finished successfully.
+func GetArchiveExportFile(export *model.ArchiveExport) ([]byte, *model.AppError) {
+	if export.Status != model.ARCHIVE_EXPORT_STATUS_SUCCESS {
+		return nil, model.NewAppError("GetArchiveExportFile", "app.archive_export.not_finished.app_error", nil, "id="+export.Id+", status="+export.Status, http.StatusBadRequest)
+	}
+
+	return ReadFile(export.Path)
+}
+
+// RunArchiveExport generates the archive of an export and uploads it to the file store. The
+// archive holds a JSON object per line for each of the team, channels, members, membership
+// changes, posts, file infos and users, along with the files attached to the posts. The
+// export's progress is saved as its posts are written and it's marked as failed if anything
+// goes wrong.
+func RunArchiveExport(export *model.ArchiveExport) {
+	export.Status = model.ARCHIVE_EXPORT_STATUS_RUNNING
+	export.StartAt = model.GetMillis()
+	if result := <-Srv.Store.ArchiveExport().Update(export); result.Err != nil {
+		l4g.Error(utils.T("app.archive_export.run.update.error"), export.Id, result.Err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeArchiveExport(export, &buf); err != nil {
+		failArchiveExport(export, err)
+		return
+	}
+
+	if err := WriteFile(buf.Bytes(), export.Path); err != nil {
+		failArchiveExport(export, err)
+		return
+	}
+
+	export.Status = model.ARCHIVE_EXPORT_STATUS_SUCCESS
+	export.Progress = 100
+	export.Size = int64(buf.Len())
+	export.EndAt = model.GetMillis()
+	if result := <-Srv.Store.ArchiveExport().Update(export); result.Err != nil {
+		l4g.Error(utils.T("app.archive_export.run.update.error"), export.Id, result.Err.Error())
+	}
+}
+
+func failArchiveExport(export *model.ArchiveExport, err *model.AppError) {
+	l4g.Error(utils.T("app.archive_export.run.failed.error"), export.Id, err.Error())
+
+	export.Status = model.ARCHIVE_EXPORT_STATUS_FAILED
+	export.Error = err.Error()
+	export.EndAt = model.GetMillis()
+	if result := <-Srv.Store.ArchiveExport().Update(export); result.Err != nil {
+		l4g.Error(utils.T("app.archive_export.run.update.error"), export.Id, result.Err.Error())
+	}
+}
+
+// archiveExportWriter writes the files of an archive and keeps track of its progress.
+type archiveExportWriter struct {
+	export  *model.ArchiveExport
+	archive *zip.Writer
+	name    string
+	file    io.Writer
+	userIds map[string]bool
+	history []*model.ArchiveExportMembershipChange
+	infos   []*model.FileInfo
+
+	totalPosts   int64
+	writtenPosts int64
+}
+
+func writeArchiveExport(export *model.ArchiveExport, w io.Writer) *model.AppError {
+	aw := &archiveExportWriter{
+		export:  export,
+		archive: zip.NewWriter(w),
+		userIds: map[string]bool{},
+	}
+
+	var channels []*model.Channel
+	if export.Type == model.ARCHIVE_EXPORT_TYPE_CHANNEL {
+		channel, err := GetChannel(export.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		channels = []*model.Channel{channel}
+	} else {
+		if result := <-Srv.Store.Channel().GetAll(export.TeamId); result.Err != nil {
+			return result.Err
+		} else {
+			channels = result.Data.([]*model.Channel)
+		}
+	}
+
+	if len(export.TeamId) > 0 {
+		team, err := GetTeam(export.TeamId)
+		if err != nil {
+			return err
+		}
+
+		if err := aw.writeLine("team.jsonl", team); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range channels {
+		aw.totalPosts += channel.TotalMsgCount
+
+		if err := aw.writeLine("channels.jsonl", channel); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range channels {
+		if err := aw.writeMembers(channel); err != nil {
+			return err
+		}
+	}
+
+	for _, channel := range channels {
+		if err := aw.writePosts(channel); err != nil {
+			return err
+		}
+	}
+
+	if err := aw.writeHistoryAndFiles(); err != nil {
+		return err
+	}
+
+	if err := aw.writeUsers(); err != nil {
+		return err
+	}
+
+	if err := aw.archive.Close(); err != nil {
+		return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// writeLine appends a JSON object to one of the JSONL files of the archive, starting the file if
+// it isn't the one being written. Since the zip writer can only write one file at a time, every
+// line of a file must be written before the next file is started.
+func (aw *archiveExportWriter) writeLine(name string, v interface{}) *model.AppError {
+	if name != aw.name {
+		fw, err := aw.archive.Create(name)
+		if err != nil {
+			return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		aw.name = name
+		aw.file = fw
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err := aw.file.Write(append(data, '\n')); err != nil {
+		return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (aw *archiveExportWriter) writeFile(name string, data []byte) *model.AppError {
+	fw, err := aw.archive.Create(name)
+	if err != nil {
+		return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	aw.name = name
+	aw.file = fw
+
+	if _, err := fw.Write(data); err != nil {
+		return model.NewAppError("writeArchiveExport", "app.archive_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (aw *archiveExportWriter) writeMembers(channel *model.Channel) *model.AppError {
+	for offset := 0; ; offset += ARCHIVE_EXPORT_MEMBERS_PER_PAGE {
+		var members model.ChannelMembers
+		if result := <-Srv.Store.Channel().GetMembers(channel.Id, offset, ARCHIVE_EXPORT_MEMBERS_PER_PAGE); result.Err != nil {
+			return result.Err
+		} else {
+			members = *result.Data.(*model.ChannelMembers)
+		}
+
+		for i := range members {
+			aw.userIds[members[i].UserId] = true
+
+			if err := aw.writeLine("members.jsonl", &members[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(members) < ARCHIVE_EXPORT_MEMBERS_PER_PAGE {
+			return nil
+		}
+	}
+}
+
+// writePosts writes the posts of a channel. The membership changes recorded by its system
+// messages and the infos of the files attached to its posts are kept to be written once every
+// post has been.
+func (aw *archiveExportWriter) writePosts(channel *model.Channel) *model.AppError {
+	afterCreateAt := int64(0)
+	afterId := ""
+
+	for {
+		var posts []*model.Post
+		if result := <-Srv.Store.Post().GetPostsForArchive(channel.Id, afterCreateAt, afterId, ARCHIVE_EXPORT_POSTS_PER_PAGE); result.Err != nil {
+			return result.Err
+		} else {
+			posts = result.Data.([]*model.Post)
+		}
+
+		for _, post := range posts {
+			aw.userIds[post.UserId] = true
+
+			if err := aw.writeLine("posts.jsonl", post); err != nil {
+				return err
+			}
+
+			if change := archiveExportMembershipChange(post); change != nil {
+				aw.history = append(aw.history, change)
+			}
+
+			if len(post.FileIds) > 0 {
+				if result := <-Srv.Store.FileInfo().GetForPost(post.Id, false, false); result.Err != nil {
+					return result.Err
+				} else {
+					aw.infos = append(aw.infos, result.Data.([]*model.FileInfo)...)
+				}
+			}
+		}
+
+		aw.writtenPosts += int64(len(posts))
+		aw.updateProgress()
+
+		if len(posts) < ARCHIVE_EXPORT_POSTS_PER_PAGE {
+			break
+		}
+
+		afterCreateAt = posts[len(posts)-1].CreateAt
+		afterId = posts[len(posts)-1].Id
+	}
+
+	return nil
+}
+
+func (aw *archiveExportWriter) writeHistoryAndFiles() *model.AppError {
+	for _, change := range aw.history {
+		if err := aw.writeLine("membership_history.jsonl", change); err != nil {
+			return err
+		}
+	}
+
+	for _, info := range aw.infos {
+		if err := aw.writeLine("file_infos.jsonl", info); err != nil {
+			return err
+		}
+	}
+
+	for _, info := range aw.infos {
+		data, err := ReadFile(info.Path)
+		if err != nil {
+			return err
+		}
+
+		if err := aw.writeFile("files/"+info.Id+"/"+path.Base(info.Name), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (aw *archiveExportWriter) writeUsers() *model.AppError {
+	userIds := make([]string, 0, len(aw.userIds))
+	for userId := range aw.userIds {
+		userIds = append(userIds, userId)
+	}
+
+	for start := 0; start < len(userIds); start += ARCHIVE_EXPORT_USERS_PER_PAGE {
+		end := start + ARCHIVE_EXPORT_USERS_PER_PAGE
+		if end > len(userIds) {
+			end = len(userIds)
+		}
+
+		users, err := GetUsersByIds(userIds[start:end], true)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := aw.writeLine("users.jsonl", user); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateProgress saves the percentage of the posts that have been written when it changes. The
+// total is taken from the message counts of the channels so it's only an estimate, and the
+// export isn't shown as complete until its archive has been uploaded.
+func (aw *archiveExportWriter) updateProgress() {
+	progress := 99
+	if aw.totalPosts > 0 && aw.writtenPosts < aw.totalPosts {
+		progress = int(aw.writtenPosts * 100 / aw.totalPosts)
+	}
+
+	if progress > 99 {
+		progress = 99
+	}
+
+	if progress == aw.export.Progress {
+		return
+	}
+
+	aw.export.Progress = progress
+	if result := <-Srv.Store.ArchiveExport().Update(aw.export); result.Err != nil {
+		l4g.Error(utils.T("app.archive_export.run.update.error"), aw.export.Id, result.Err.Error())
+	}
+}
+
+// archiveExportMembershipChange returns the membership change recorded by a system message, or
+// nil if the post isn't one.
+func archiveExportMembershipChange(post *model.Post) *model.ArchiveExportMembershipChange {
+	var username interface{}
+
+	switch post.Type {
+	case model.POST_JOIN_CHANNEL, model.POST_LEAVE_CHANNEL:
+		username = post.Props["username"]
+	case model.POST_ADD_TO_CHANNEL:
+		username = post.Props["addedUsername"]
+	case model.POST_REMOVE_FROM_CHANNEL:
+		username = post.Props["removedUsername"]
+	default:
+		return nil
+	}
+
+	change := &model.ArchiveExportMembershipChange{
+		ChannelId: post.ChannelId,
+		Type:      post.Type,
+		ActorId:   post.UserId,
+		PostId:    post.Id,
+		At:        post.CreateAt,
+	}
+
+	if s, ok := username.(string); ok {
+		change.Username = s
+	}
+
+	return change
+}
@@ -4,6 +4,10 @@
 package app
 
 import (
+	"reflect"
+	"runtime"
+	"strings"
+
 	"github.com/mattermost/platform/model"
 )
 
@@ -22,3 +26,27 @@ func GetAuditsPage(userId string, page int, perPage int) (model.Audits, *model.A
 		return result.Data.(model.Audits), nil
 	}
 }
+
+func SearchAudits(filter *model.AuditFilter, page int, perPage int) (model.Audits, *model.AppError) {
+	if result := <-Srv.Store.Audit().Search(filter, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(model.Audits), nil
+	}
+}
+
+// GetAuditEventName returns the name of the function that handles a request, such as updateUserRoles,
+// which is the event name of the audits recorded while handling it.
+func GetAuditEventName(handleFunc interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handleFunc).Pointer()).Name()
+
+	if index := strings.LastIndex(name, "."); index != -1 {
+		name = name[index+1:]
+	}
+
+	if len(name) > model.AUDIT_EVENT_NAME_MAX_LENGTH {
+		name = name[:model.AUDIT_EVENT_NAME_MAX_LENGTH]
+	}
+
+	return name
+}
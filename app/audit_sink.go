@@ -0,0 +1,417 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	AUDIT_SINK_BATCH_SIZE     = 100
+	AUDIT_SINK_FLUSH_INTERVAL = time.Second
+	AUDIT_SINK_RETRY_INTERVAL = time.Second
+	AUDIT_SINK_TIMEOUT        = 5 * time.Second
+
+	AUDIT_SYSLOG_APP_NAME = "mattermost"
+
+	// The log audit facility and the informational severity of RFC 5424
+	AUDIT_SYSLOG_PRIORITY = 13*8 + 6
+
+	AUDIT_SYSLOG_MSGID_MAX_LENGTH    = 32
+	AUDIT_SYSLOG_HOSTNAME_MAX_LENGTH = 255
+)
+
+// AuditSink ships audits to a system outside of the database, such as a syslog server or a SIEM, so
+// that they can be followed without polling the audits API. Sinks are only used from the goroutine
+// of their worker so they don't need to be safe for concurrent use.
+type AuditSink interface {
+	Name() string
+	Write(audits model.Audits) error
+	Close()
+}
+
+// auditSinkWorker buffers the audits for a sink and writes them in batches from its own goroutine,
+// retrying batches that fail so that a sink that's briefly unavailable doesn't lose audits. Audits
+// are dropped when the buffer is full rather than slowing down the requests that record them.
+type auditSinkWorker struct {
+	sink       AuditSink
+	audits     chan *model.Audit
+	maxRetries int
+	stop       chan bool
+	stopped    chan bool
+}
+
+var auditSinkWorkers []*auditSinkWorker
+var auditSinkWorkersLock sync.RWMutex
+
+// InitAuditSinks starts the audit sinks that are enabled in the config and stops the ones that were
+// running, after they've written what they had buffered.
+func InitAuditSinks() {
+	settings := Config().AuditSettings
+
+	sinks := []AuditSink{}
+
+	if *settings.EnableSyslog {
+		sinks = append(sinks, newSyslogAuditSink(*settings.SyslogNetwork, *settings.SyslogAddress))
+	}
+
+	if *settings.EnableFile {
+		sinks = append(sinks, &fileAuditSink{fileName: *settings.FileName})
+	}
+
+	if *settings.EnableWebhook {
+		sinks = append(sinks, newWebhookAuditSink(*settings.WebhookUrl, *settings.WebhookSecret))
+	}
+
+	workers := []*auditSinkWorker{}
+	for _, sink := range sinks {
+		worker := &auditSinkWorker{
+			sink:       sink,
+			audits:     make(chan *model.Audit, *settings.BufferSize),
+			maxRetries: *settings.MaxRetries,
+			stop:       make(chan bool),
+			stopped:    make(chan bool),
+		}
+
+		go worker.run()
+
+		workers = append(workers, worker)
+	}
+
+	auditSinkWorkersLock.Lock()
+	previous := auditSinkWorkers
+	auditSinkWorkers = workers
+	auditSinkWorkersLock.Unlock()
+
+	for _, worker := range previous {
+		worker.Stop()
+	}
+}
+
+// StopAuditSinks stops the audit sinks after they've written what they had buffered.
+func StopAuditSinks() {
+	auditSinkWorkersLock.Lock()
+	previous := auditSinkWorkers
+	auditSinkWorkers = nil
+	auditSinkWorkersLock.Unlock()
+
+	for _, worker := range previous {
+		worker.Stop()
+	}
+}
+
+// SaveAudit saves an audit and sends it to the audit sinks.
+func SaveAudit(audit *model.Audit) *model.AppError {
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		return result.Err
+	}
+
+	sendAuditToSinks(audit)
+
+	return nil
+}
+
+func sendAuditToSinks(audit *model.Audit) {
+	auditSinkWorkersLock.RLock()
+	defer auditSinkWorkersLock.RUnlock()
+
+	for _, worker := range auditSinkWorkers {
+		select {
+		case worker.audits <- audit:
+		default:
+			l4g.Warn(utils.T("app.audit_sink.buffer_full.warn"), worker.sink.Name(), audit.Id)
+		}
+	}
+}
+
+func (w *auditSinkWorker) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(AUDIT_SINK_FLUSH_INTERVAL)
+	defer ticker.Stop()
+
+	batch := model.Audits{}
+
+	for {
+		select {
+		case audit := <-w.audits:
+			batch = append(batch, *audit)
+
+			if len(batch) >= AUDIT_SINK_BATCH_SIZE {
+				w.write(batch, w.maxRetries)
+				batch = model.Audits{}
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.write(batch, w.maxRetries)
+				batch = model.Audits{}
+			}
+		case <-w.stop:
+			// What's left is written without retrying so that stopping doesn't wait on a sink that's down
+			for len(w.audits) > 0 {
+				batch = append(batch, *<-w.audits)
+			}
+
+			if len(batch) > 0 {
+				w.write(batch, 0)
+			}
+
+			w.sink.Close()
+			return
+		}
+	}
+}
+
+// write writes a batch to the sink, retrying with an increasing interval until it succeeds, it has
+// been retried maxRetries times or the worker is stopped.
+func (w *auditSinkWorker) write(batch model.Audits, maxRetries int) {
+	interval := AUDIT_SINK_RETRY_INTERVAL
+
+	for attempt := 0; ; attempt++ {
+		err := w.sink.Write(batch)
+		if err == nil {
+			return
+		}
+
+		if attempt >= maxRetries {
+			l4g.Error(utils.T("app.audit_sink.write.error"), w.sink.Name(), len(batch), err.Error())
+			return
+		}
+
+		l4g.Warn(utils.T("app.audit_sink.write.retry.warn"), w.sink.Name(), attempt+1, err.Error())
+
+		select {
+		case <-time.After(interval):
+		case <-w.stop:
+			l4g.Error(utils.T("app.audit_sink.write.error"), w.sink.Name(), len(batch), err.Error())
+			return
+		}
+
+		interval *= 2
+	}
+}
+
+func (w *auditSinkWorker) Stop() {
+	close(w.stop)
+	<-w.stopped
+}
+
+// syslogAuditSink sends each audit to a syslog server as an RFC 5424 message with the audit as JSON.
+// Messages are sent one per datagram over UDP and with octet counting framing over TCP and TLS, as
+// described by RFC 6587 and RFC 5425. A batch that fails part of the way through is sent again from
+// the start, so the server may receive some audits more than once.
+type syslogAuditSink struct {
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+}
+
+func newSyslogAuditSink(network string, address string) *syslogAuditSink {
+	hostname, _ := os.Hostname()
+
+	return &syslogAuditSink{
+		network:  network,
+		address:  address,
+		hostname: hostname,
+	}
+}
+
+func (s *syslogAuditSink) Name() string {
+	return "syslog"
+}
+
+func (s *syslogAuditSink) Write(audits model.Audits) error {
+	if s.conn == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return err
+		}
+
+		s.conn = conn
+	}
+
+	for _, audit := range audits {
+		message := FormatSyslogAuditMessage(&audit, s.hostname, os.Getpid())
+		if s.network != model.AUDIT_SYSLOG_NETWORK_UDP {
+			message = strconv.Itoa(len(message)) + " " + message
+		}
+
+		s.conn.SetWriteDeadline(time.Now().Add(AUDIT_SINK_TIMEOUT))
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			s.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *syslogAuditSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: AUDIT_SINK_TIMEOUT}
+
+	switch s.network {
+	case model.AUDIT_SYSLOG_NETWORK_TLS:
+		return tls.DialWithDialer(dialer, "tcp", s.address, &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections})
+	default:
+		return dialer.Dial(s.network, s.address)
+	}
+}
+
+func (s *syslogAuditSink) Close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// FormatSyslogAuditMessage returns an audit as an RFC 5424 message. The message id is the audit's
+// event name and the message is the audit as JSON.
+func FormatSyslogAuditMessage(audit *model.Audit, hostname string, procId int) string {
+	timestamp := time.Unix(0, audit.CreateAt*int64(time.Millisecond)).UTC().Format("2006-01-02T15:04:05.000Z07:00")
+
+	return "<" + strconv.Itoa(AUDIT_SYSLOG_PRIORITY) + ">1 " +
+		timestamp + " " +
+		syslogHeaderField(hostname, AUDIT_SYSLOG_HOSTNAME_MAX_LENGTH) + " " +
+		AUDIT_SYSLOG_APP_NAME + " " +
+		strconv.Itoa(procId) + " " +
+		syslogHeaderField(audit.EventName, AUDIT_SYSLOG_MSGID_MAX_LENGTH) + " " +
+		"- " + // No structured data
+		"\xEF\xBB\xBF" + audit.ToJson()
+}
+
+// syslogHeaderField returns a value that can be used as a field of a syslog header, which only
+// allows printable ASCII characters other than spaces, or "-" if the value is empty.
+func syslogHeaderField(value string, maxLength int) string {
+	field := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(field) < maxLength; i++ {
+		if value[i] >= 33 && value[i] <= 126 {
+			field = append(field, value[i])
+		}
+	}
+
+	if len(field) == 0 {
+		return "-"
+	}
+
+	return string(field)
+}
+
+// fileAuditSink appends each audit to a file as a line of JSON.
+type fileAuditSink struct {
+	fileName string
+	file     *os.File
+}
+
+func (s *fileAuditSink) Name() string {
+	return "file"
+}
+
+func (s *fileAuditSink) Write(audits model.Audits) error {
+	if s.file == nil {
+		file, err := os.OpenFile(s.fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+
+		s.file = file
+	}
+
+	var buf bytes.Buffer
+	for _, audit := range audits {
+		buf.WriteString(audit.ToJson())
+		buf.WriteString("\n")
+	}
+
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		s.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (s *fileAuditSink) Close() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+// webhookAuditSink posts each batch of audits to a URL as a JSON array. If the sink has a secret,
+// the body is signed with it so that the receiver can tell that the audits came from this server.
+type webhookAuditSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func newWebhookAuditSink(url string, secret string) *webhookAuditSink {
+	return &webhookAuditSink{
+		url:    url,
+		secret: secret,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
+			},
+			Timeout: AUDIT_SINK_TIMEOUT,
+		},
+	}
+}
+
+func (s *webhookAuditSink) Name() string {
+	return "webhook"
+}
+
+func (s *webhookAuditSink) Write(audits model.Audits) error {
+	body := []byte(audits.ToJson())
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set(model.HEADER_AUDIT_SIGNATURE, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New("status=" + strconv.Itoa(resp.StatusCode))
+	}
+
+	return nil
+}
+
+func (s *webhookAuditSink) Close() {
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestFormatSyslogAuditMessage(t *testing.T) {
+	audit := &model.Audit{
+		Id:        model.NewId(),
+		CreateAt:  1500000000123,
+		UserId:    model.NewId(),
+		Action:    "/api/v4/users/login",
+		EventName: "login",
+	}
+
+	message := FormatSyslogAuditMessage(audit, "host name", 42)
+
+	expected := "<110>1 2017-07-14T02:40:00.123Z hostname mattermost 42 login - \xEF\xBB\xBF"
+	if !strings.HasPrefix(message, expected) {
+		t.Fatalf("bad header, got %v", message)
+	}
+
+	if decoded := model.AuditFromJson(strings.NewReader(strings.TrimPrefix(message, expected))); decoded == nil || decoded.Id != audit.Id {
+		t.Fatal("message should be the audit as json")
+	}
+
+	audit.EventName = ""
+	if message := FormatSyslogAuditMessage(audit, "", 42); !strings.HasPrefix(message, "<110>1 2017-07-14T02:40:00.123Z - mattermost 42 - - ") {
+		t.Fatalf("empty fields should be nil values, got %v", message)
+	}
+
+	audit.EventName = strings.Repeat("a", 40)
+	if message := FormatSyslogAuditMessage(audit, "host", 42); !strings.Contains(message, " 42 "+strings.Repeat("a", AUDIT_SYSLOG_MSGID_MAX_LENGTH)+" - ") {
+		t.Fatalf("message id should be truncated, got %v", message)
+	}
+}
+
+func TestFileAuditSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit_sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sink := &fileAuditSink{fileName: filepath.Join(dir, "audit.log")}
+	defer sink.Close()
+
+	audits := model.Audits{{Id: model.NewId()}, {Id: model.NewId()}}
+	if err := sink.Write(audits); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing after reopening the file should append to it
+	sink.Close()
+	if err := sink.Write(model.Audits{{Id: model.NewId()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(sink.fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("should have written 3 lines, got %v", len(lines))
+	}
+
+	if decoded := model.AuditFromJson(strings.NewReader(lines[1])); decoded == nil || decoded.Id != audits[1].Id {
+		t.Fatal("each line should be an audit as json")
+	}
+}
+
+func TestWebhookAuditSink(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	secret := model.NewId()
+	var received model.Audits
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if r.Header.Get(model.HEADER_AUDIT_SIGNATURE) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Error("bad signature")
+		}
+
+		received = model.AuditsFromJson(strings.NewReader(string(body)))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := newWebhookAuditSink(server.URL, secret)
+
+	audits := model.Audits{{Id: model.NewId()}, {Id: model.NewId()}}
+	if err := sink.Write(audits); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 2 || received[0].Id != audits[0].Id {
+		t.Fatal("should have posted the audits")
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Write(audits); err == nil {
+		t.Fatal("should fail when the response isn't successful")
+	}
+}
+
+type testAuditSink struct {
+	failures int
+	written  chan model.Audits
+	closed   bool
+}
+
+func (s *testAuditSink) Name() string {
+	return "test"
+}
+
+func (s *testAuditSink) Write(audits model.Audits) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("failed")
+	}
+
+	s.written <- audits
+	return nil
+}
+
+func (s *testAuditSink) Close() {
+	s.closed = true
+}
+
+func TestAuditSinkWorker(t *testing.T) {
+	utils.TranslationsPreInit()
+
+	sink := &testAuditSink{failures: 1, written: make(chan model.Audits, 10)}
+	worker := &auditSinkWorker{
+		sink:       sink,
+		audits:     make(chan *model.Audit, 10),
+		maxRetries: 1,
+		stop:       make(chan bool),
+		stopped:    make(chan bool),
+	}
+	go worker.run()
+
+	worker.audits <- &model.Audit{Id: model.NewId()}
+	worker.audits <- &model.Audit{Id: model.NewId()}
+
+	// The first write fails, so the batch should be retried
+	select {
+	case audits := <-sink.written:
+		if len(audits) != 2 {
+			t.Fatalf("should have written both audits in a batch, got %v", len(audits))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("should have retried the batch")
+	}
+
+	worker.audits <- &model.Audit{Id: model.NewId()}
+	worker.Stop()
+
+	select {
+	case audits := <-sink.written:
+		if len(audits) != 1 {
+			t.Fatalf("should have written the buffered audit when stopping, got %v", len(audits))
+		}
+	default:
+		t.Fatal("should have written the buffered audit when stopping")
+	}
+
+	if !sink.closed {
+		t.Fatal("should have closed the sink")
+	}
+}
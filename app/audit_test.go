@@ -0,0 +1,14 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+)
+
+func TestGetAuditEventName(t *testing.T) {
+	if name := GetAuditEventName(SearchAudits); name != "SearchAudits" {
+		t.Fatal("should have returned the name of the function", name)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -101,8 +102,29 @@ func CheckUserAdditionalAuthenticationCriteria(user *model.User, mfaToken string
 	return nil
 }
 
+// CheckUserMfa checks the second factor a user logged in with. The token is either a code from their
+// authenticator app, one of their backup codes or the assertion of one of their security keys.
 func CheckUserMfa(user *model.User, token string) *model.AppError {
-	if !user.MfaActive || !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !utils.IsLicensed || !*utils.License.Features.MFA || !*Config().ServiceSettings.EnableMultifactorAuthentication {
+		return nil
+	}
+
+	if IsWebAuthnEnabled() {
+		credentials, err := getUserCredentials(user.Id)
+		if err != nil {
+			return err
+		}
+
+		if len(credentials) > 0 {
+			if model.IsWebAuthnAssertion(token) {
+				return checkUserWebAuthn(user, credentials, token)
+			} else if !user.MfaActive {
+				return model.NewAppError("checkUserMfa", "api.user.check_user_mfa.security_key_required.app_error", nil, "", http.StatusUnauthorized)
+			}
+		}
+	}
+
+	if !user.MfaActive {
 		return nil
 	}
 
@@ -114,14 +136,37 @@ func CheckUserMfa(user *model.User, token string) *model.AppError {
 	if ok, err := mfaInterface.ValidateToken(user.MfaSecret, token); err != nil {
 		return err
 	} else if !ok {
+		if useMfaBackupCode(user, token) {
+			return nil
+		}
+
 		return model.NewAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
 	}
 
 	return nil
 }
 
+// useMfaBackupCode checks whether a token is one of the user's backup codes and uses it up if it is.
+func useMfaBackupCode(user *model.User, token string) bool {
+	remaining, ok := user.UseMfaBackupCode(token)
+	if !ok {
+		return false
+	}
+
+	if result := <-Srv.Store.User().UpdateMfaBackupCodes(user.Id, remaining, user.MfaBackupCodes); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return false
+	} else if !result.Data.(bool) {
+		// The code was used by another login at the same time
+		return false
+	}
+
+	user.MfaBackupCodes = remaining
+	return true
+}
+
 func checkUserLoginAttempts(user *model.User) *model.AppError {
-	if user.FailedAttempts >= utils.Cfg.ServiceSettings.MaximumLoginAttempts {
+	if user.FailedAttempts >= Config().ServiceSettings.MaximumLoginAttempts {
 		return model.NewAppError("checkUserLoginAttempts", "api.user.check_user_login_attempts.too_many.app_error", nil, "user_id="+user.Id, http.StatusUnauthorized)
 	}
 
@@ -129,7 +174,7 @@ func checkUserLoginAttempts(user *model.User) *model.AppError {
 }
 
 func checkEmailVerified(user *model.User) *model.AppError {
-	if !user.EmailVerified && utils.Cfg.EmailSettings.RequireEmailVerification {
+	if !user.EmailVerified && Config().EmailSettings.RequireEmailVerification {
 		return model.NewAppError("Login", "api.user.login.not_verified.app_error", nil, "user_id="+user.Id, http.StatusUnauthorized)
 	}
 	return nil
@@ -143,7 +188,7 @@ func checkUserNotDisabled(user *model.User) *model.AppError {
 }
 
 func authenticateUser(user *model.User, password, mfaToken string) (*model.User, *model.AppError) {
-	ldapAvailable := *utils.Cfg.LdapSettings.Enable && einterfaces.GetLdapInterface() != nil && utils.IsLicensed && *utils.License.Features.LDAP
+	ldapAvailable := *Config().LdapSettings.Enable && einterfaces.GetLdapInterface() != nil && utils.IsLicensed && *utils.License.Features.LDAP
 
 	if user.AuthService == model.USER_AUTH_SERVICE_LDAP {
 		if !ldapAvailable {
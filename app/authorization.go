@@ -34,6 +34,10 @@ func SessionHasPermissionToChannel(session model.Session, channelId string, perm
 		return false
 	}
 
+	if !session.IsChannelAllowedByOAuthScope(channelId) {
+		return false
+	}
+
 	cmc := Srv.Store.Channel().GetAllChannelMembersForUser(session.UserId, true)
 
 	var channelRoles []string
@@ -56,6 +60,12 @@ func SessionHasPermissionToChannel(session model.Session, channelId string, perm
 }
 
 func SessionHasPermissionToChannelByPost(session model.Session, postId string, permission *model.Permission) bool {
+	if len(model.OAuthScopeChannelIds(session.GetOAuthScope())) > 0 {
+		if result := <-Srv.Store.Channel().GetForPost(postId); result.Err != nil || !session.IsChannelAllowedByOAuthScope(result.Data.(*model.Channel).Id) {
+			return false
+		}
+	}
+
 	var channelMember *model.ChannelMember
 	if result := <-Srv.Store.Channel().GetMemberForPost(postId, session.UserId); result.Err == nil {
 		channelMember = result.Data.(*model.ChannelMember)
@@ -73,6 +83,78 @@ func SessionHasPermissionToChannelByPost(session model.Session, postId string, p
 	return SessionHasPermissionTo(session, permission)
 }
 
+// SessionHasPermissionToChannelsByPosts is SessionHasPermissionToChannelByPost for many posts at once.
+// The channels of the posts are looked up with a single query and each channel is only checked once.
+func SessionHasPermissionToChannelsByPosts(session model.Session, postIds []string, permission *model.Permission) bool {
+	result := <-Srv.Store.Post().GetChannelIdsForPosts(postIds)
+	if result.Err != nil {
+		return false
+	}
+	channelIds := result.Data.(map[string]string)
+
+	checked := make(map[string]bool)
+	for _, postId := range postIds {
+		channelId, ok := channelIds[postId]
+		if checked[channelId] {
+			continue
+		}
+
+		if !ok {
+			// Like SessionHasPermissionToChannelByPost, posts that don't exist fall back to the
+			// permissions of the session outside of any channel
+			if len(model.OAuthScopeChannelIds(session.GetOAuthScope())) > 0 || !SessionHasPermissionTo(session, permission) {
+				return false
+			}
+		} else if !SessionHasPermissionToChannel(session, channelId, permission) {
+			return false
+		}
+
+		checked[channelId] = true
+	}
+
+	return true
+}
+
+// FilterChannelsByOAuthScope drops the channels that an OAuth token restricted to some channels
+// wasn't granted from a team-wide list of channels.
+func FilterChannelsByOAuthScope(session model.Session, channels *model.ChannelList) *model.ChannelList {
+	if len(model.OAuthScopeChannelIds(session.GetOAuthScope())) == 0 {
+		return channels
+	}
+
+	filtered := model.ChannelList{}
+	for _, channel := range *channels {
+		if session.IsChannelAllowedByOAuthScope(channel.Id) {
+			filtered = append(filtered, channel)
+		}
+	}
+
+	return &filtered
+}
+
+// FilterPostsByOAuthScope drops the posts of the channels that an OAuth token restricted to some
+// channels wasn't granted from a team-wide list of posts, such as search results.
+func FilterPostsByOAuthScope(session model.Session, posts *model.PostList) *model.PostList {
+	if len(model.OAuthScopeChannelIds(session.GetOAuthScope())) == 0 {
+		return posts
+	}
+
+	filtered := model.NewPostList()
+	for _, postId := range posts.Order {
+		if post, ok := posts.Posts[postId]; ok && session.IsChannelAllowedByOAuthScope(post.ChannelId) {
+			filtered.AddOrder(postId)
+		}
+	}
+
+	for _, post := range posts.Posts {
+		if session.IsChannelAllowedByOAuthScope(post.ChannelId) {
+			filtered.AddPost(post)
+		}
+	}
+
+	return filtered
+}
+
 func SessionHasPermissionToUser(session model.Session, userId string) bool {
 	if userId == "" {
 		return false
@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	AUTO_RESPONDER_TASK_NAME     = "Auto Responder"
+	AUTO_RESPONDER_TASK_INTERVAL = time.Minute
+	AUTO_RESPONDER_BATCH_SIZE    = 100
+)
+
+// InitAutoResponders starts the job that updates the statuses of users whose auto responders start or
+// end, and turns off the auto responders whose date range is over, every minute.
+func InitAutoResponders() {
+	if task := model.GetTaskByName(AUTO_RESPONDER_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(AUTO_RESPONDER_TASK_NAME, CheckAutoResponders, AUTO_RESPONDER_TASK_INTERVAL)
+}
+
+// GetAutoResponder returns a user's auto responder, which is turned off if they've never set one.
+func GetAutoResponder(userId string) (*model.AutoResponder, *model.AppError) {
+	if result := <-Srv.Store.AutoResponder().Get(userId); result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return &model.AutoResponder{UserId: userId}, nil
+		}
+
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.AutoResponder), nil
+	}
+}
+
+// SetAutoResponder saves a user's auto responder and shows them as out of office while it's replying
+// to messages.
+func SetAutoResponder(responder *model.AutoResponder) (*model.AutoResponder, *model.AppError) {
+	if result := <-Srv.Store.AutoResponder().SaveOrUpdate(responder); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if !responder.Active {
+		if result := <-Srv.Store.AutoResponder().DeleteResponses(responder.UserId); result.Err != nil {
+			return nil, result.Err
+		}
+	}
+
+	if err := syncAutoResponderStatus(responder, model.GetMillis()); err != nil {
+		return nil, err
+	}
+
+	return responder, nil
+}
+
+// CheckAutoResponders turns off the auto responders whose date range is over and makes sure that the
+// users with an auto responder are shown as out of office only while it's replying to messages.
+func CheckAutoResponders() {
+	now := model.GetMillis()
+
+	offset := 0
+
+	for {
+		var responders []*model.AutoResponder
+		if result := <-Srv.Store.AutoResponder().GetActive(offset, AUTO_RESPONDER_BATCH_SIZE); result.Err != nil {
+			l4g.Error(utils.T("app.auto_responder.check.error"), result.Err.Error())
+			return
+		} else {
+			responders = result.Data.([]*model.AutoResponder)
+		}
+
+		// Turned off responders aren't returned any more, so the next page starts that much earlier
+		turnedOff := 0
+
+		for _, responder := range responders {
+			if responder.HasEndedAt(now) {
+				responder.Active = false
+				if _, err := SetAutoResponder(responder); err != nil {
+					l4g.Error(utils.T("app.auto_responder.disable.error"), responder.UserId, err.Error())
+				} else {
+					turnedOff++
+				}
+			} else if err := syncAutoResponderStatus(responder, now); err != nil {
+				l4g.Error(utils.T("app.auto_responder.status.error"), responder.UserId, err.Error())
+			}
+		}
+
+		if len(responders) < AUTO_RESPONDER_BATCH_SIZE {
+			return
+		}
+
+		offset += len(responders) - turnedOff
+	}
+}
+
+// syncAutoResponderStatus sets a user's status to out of office if their auto responder is replying
+// to messages and sets it back to online or away once it stops.
+func syncAutoResponderStatus(responder *model.AutoResponder, now int64) *model.AppError {
+	status := getStatusOrOffline(responder.UserId)
+
+	if responder.IsActiveAt(now) {
+		if status.Status == model.STATUS_OUT_OF_OFFICE {
+			return nil
+		}
+
+		status.Status = model.STATUS_OUT_OF_OFFICE
+		status.Manual = true
+		status.DNDEndTime = 0
+		status.ActiveChannel = ""
+	} else {
+		if status.Status != model.STATUS_OUT_OF_OFFICE {
+			return nil
+		}
+
+		if IsUserAway(status.LastActivityAt) {
+			status.Status = model.STATUS_AWAY
+		} else {
+			status.Status = model.STATUS_ONLINE
+		}
+		status.Manual = false
+	}
+
+	return updateStatus(status)
+}
+
+// sendAutoResponse replies to a direct message with the auto responder of the user that it was sent
+// to, if the auto responder is replying to messages and hasn't replied to the sender yet today. System
+// messages, including other auto responses, messages from webhooks and messages from bots don't get a
+// reply so that auto responders can't reply to each other.
+func sendAutoResponse(post *model.Post, channel *model.Channel) {
+	if channel.Type != model.CHANNEL_DIRECT || post.IsSystemMessage() || post.Props["from_webhook"] == "true" {
+		return
+	}
+
+	userId := channel.GetOtherUserIdForDM(post.UserId)
+	if len(userId) == 0 || userId == post.UserId {
+		return
+	}
+
+	responder, err := GetAutoResponder(userId)
+	if err != nil {
+		l4g.Error(utils.T("app.auto_responder.send.error"), userId, err.Error())
+		return
+	}
+
+	if !responder.IsActiveAt(model.GetMillis()) || IsBotUser(post.UserId) {
+		return
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if result := <-Srv.Store.AutoResponder().MarkResponseSent(userId, post.UserId, model.GetMillis(), startOfDay.UnixNano()/int64(time.Millisecond)); result.Err != nil {
+		l4g.Error(utils.T("app.auto_responder.send.error"), userId, result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		return
+	}
+
+	response := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    userId,
+		Message:   responder.Message,
+		Type:      model.POST_AUTO_RESPONDER,
+	}
+
+	if _, err := CreatePost(response, channel.TeamId, false); err != nil {
+		l4g.Error(utils.T("app.auto_responder.send.error"), userId, err.Error())
+	}
+}
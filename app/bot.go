@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+)
+
+// CreateBot creates the user backing a bot along with the bot itself. The user is given a random
+// password that is never returned since bots can't log in.
+func CreateBot(bot *model.Bot) (*model.Bot, *model.AppError) {
+	if !*Config().ServiceSettings.EnableBotAccountCreation {
+		return nil, model.NewAppError("CreateBot", "app.bot.create.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user := bot.ToUser()
+	user.Roles = model.ROLE_SYSTEM_USER.Id
+	user.Locale = *Config().LocalizationSettings.DefaultClientLocale
+
+	var ruser *model.User
+	if result := <-Srv.Store.User().Save(user); result.Err != nil {
+		return nil, result.Err
+	} else {
+		ruser = result.Data.(*model.User)
+	}
+
+	bot.UserId = ruser.Id
+	bot.DeleteAt = 0
+
+	if result := <-Srv.Store.Bot().Save(bot); result.Err != nil {
+		if presult := <-Srv.Store.User().PermanentDelete(ruser.Id); presult.Err != nil {
+			l4g.Error(presult.Err.Error())
+		}
+
+		return nil, result.Err
+	}
+
+	bot.Username = ruser.Username
+	bot.DisplayName = ruser.FirstName
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_NEW_USER, "", "", "", nil)
+	message.Add("user_id", ruser.Id)
+	go Publish(message)
+
+	return bot, nil
+}
+
+func GetBot(userId string, includeDeleted bool) (*model.Bot, *model.AppError) {
+	if result := <-Srv.Store.Bot().Get(userId, includeDeleted); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Bot), nil
+	}
+}
+
+// GetBotsPage returns the active bots owned by the given user, or all active bots if ownerId is empty.
+func GetBotsPage(ownerId string, page int, perPage int) ([]*model.Bot, *model.AppError) {
+	if result := <-Srv.Store.Bot().GetAll(ownerId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Bot), nil
+	}
+}
+
+func IsBotUser(userId string) bool {
+	result := <-Srv.Store.Bot().Get(userId, true)
+	return result.Err == nil
+}
+
+// DisableBot deactivates the user backing a bot and revokes all of its access tokens.
+func DisableBot(bot *model.Bot) *model.AppError {
+	if _, err := UpdateActiveNoLdap(bot.UserId, false); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.UserAccessToken().DeleteAllForUser(bot.UserId); result.Err != nil {
+		return result.Err
+	}
+
+	bot.DeleteAt = model.GetMillis()
+	if result := <-Srv.Store.Bot().Update(bot); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
@@ -9,11 +9,10 @@ import (
 
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
-	"github.com/mattermost/platform/utils"
 )
 
 func SaveBrandImage(imageData *multipart.FileHeader) *model.AppError {
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(Config().FileSettings.DriverName) == 0 {
 		return model.NewAppError("SaveBrandImage", "api.admin.upload_brand_image.storage.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -30,7 +29,7 @@ func SaveBrandImage(imageData *multipart.FileHeader) *model.AppError {
 }
 
 func GetBrandImage() ([]byte, *model.AppError) {
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(Config().FileSettings.DriverName) == 0 {
 		return nil, model.NewAppError("GetBrandImage", "api.admin.get_brand_image.storage.app_error", nil, "", http.StatusNotImplemented)
 	}
 
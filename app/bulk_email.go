@@ -0,0 +1,402 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	BULK_EMAIL_USERS_PER_PAGE      = 1000
+	BULK_EMAIL_RECIPIENTS_PER_PAGE = 100
+)
+
+// CreateBulkEmail saves a bulk email and starts sending it in the background. The returned bulk
+// email is pending and can be polled for its progress.
+func CreateBulkEmail(bulkEmail *model.BulkEmail) (*model.BulkEmail, *model.AppError) {
+	if bulkEmail.SendEmail && !Config().EmailSettings.SendEmailNotifications {
+		return nil, model.NewAppError("CreateBulkEmail", "app.bulk_email.email_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if bulkEmail.Segment == model.BULK_EMAIL_SEGMENT_TEAM {
+		if _, err := GetTeam(bulkEmail.TeamId); err != nil {
+			return nil, err
+		}
+	}
+
+	// Direct messages can only be sent by the admin or by a bot so that nobody else is impersonated
+	if bulkEmail.SendDirectMessage && len(bulkEmail.SenderId) > 0 {
+		if _, err := GetBot(bulkEmail.SenderId, false); err != nil {
+			return nil, err
+		}
+	} else {
+		bulkEmail.SenderId = ""
+	}
+
+	bulkEmail.Id = ""
+
+	if result := <-Srv.Store.BulkEmail().Save(bulkEmail); result.Err != nil {
+		return nil, result.Err
+	} else {
+		bulkEmail = result.Data.(*model.BulkEmail)
+	}
+
+	go RunBulkEmail(bulkEmail)
+
+	return bulkEmail, nil
+}
+
+func GetBulkEmail(bulkEmailId string) (*model.BulkEmail, *model.AppError) {
+	if result := <-Srv.Store.BulkEmail().Get(bulkEmailId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.BulkEmail), nil
+	}
+}
+
+func GetBulkEmails(page, perPage int) ([]*model.BulkEmail, *model.AppError) {
+	if result := <-Srv.Store.BulkEmail().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.BulkEmail), nil
+	}
+}
+
+func GetBulkEmailRecipients(bulkEmailId string, status string, page, perPage int) ([]*model.BulkEmailRecipient, *model.AppError) {
+	if result := <-Srv.Store.BulkEmail().GetRecipients(bulkEmailId, status, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.BulkEmailRecipient), nil
+	}
+}
+
+// CancelBulkEmail stops a bulk email from being sent to the recipients that it hasn't been sent to yet.
+func CancelBulkEmail(bulkEmail *model.BulkEmail) (*model.BulkEmail, *model.AppError) {
+	if bulkEmail.IsFinished() {
+		return nil, model.NewAppError("CancelBulkEmail", "app.bulk_email.cancel.finished.app_error", nil, "id="+bulkEmail.Id+", status="+bulkEmail.Status, http.StatusBadRequest)
+	}
+
+	bulkEmail.Status = model.BULK_EMAIL_STATUS_CANCELED
+	bulkEmail.EndAt = model.GetMillis()
+
+	if result := <-Srv.Store.BulkEmail().Update(bulkEmail); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.BulkEmail), nil
+	}
+}
+
+// RunBulkEmail finds the users of the bulk email's segment, saves them as its recipients and then
+// sends it to each of them, waiting between recipients so that no more than the configured number
+// are sent to per minute. The status of each recipient and the counts of the bulk email are saved
+// as it goes and it stops if it's canceled.
+func RunBulkEmail(bulkEmail *model.BulkEmail) {
+	bulkEmail.Status = model.BULK_EMAIL_STATUS_RUNNING
+	bulkEmail.StartAt = model.GetMillis()
+	if result := <-Srv.Store.BulkEmail().Update(bulkEmail); result.Err != nil {
+		l4g.Error(utils.T("app.bulk_email.run.update.error"), bulkEmail.Id, result.Err.Error())
+		return
+	}
+
+	userIds, err := getBulkEmailUserIds(bulkEmail)
+	if err != nil {
+		failBulkEmail(bulkEmail, err)
+		return
+	}
+
+	if result := <-Srv.Store.BulkEmail().SaveRecipients(bulkEmail.Id, userIds); result.Err != nil {
+		failBulkEmail(bulkEmail, result.Err)
+		return
+	}
+
+	bulkEmail.TotalRecipients = int64(len(userIds))
+	if !updateBulkEmailProgress(bulkEmail) {
+		return
+	}
+
+	interval := time.Minute / time.Duration(*Config().EmailSettings.BulkEmailMaxPerMinute)
+
+	for {
+		// Recipients are no longer pending once they've been sent to, so the first page is always read
+		result := <-Srv.Store.BulkEmail().GetRecipients(bulkEmail.Id, model.BULK_EMAIL_RECIPIENT_STATUS_PENDING, 0, BULK_EMAIL_RECIPIENTS_PER_PAGE)
+		if result.Err != nil {
+			failBulkEmail(bulkEmail, result.Err)
+			return
+		}
+
+		recipients := result.Data.([]*model.BulkEmailRecipient)
+		if len(recipients) == 0 {
+			break
+		}
+
+		for _, recipient := range recipients {
+			sendBulkEmailToRecipient(bulkEmail, recipient)
+
+			if result := <-Srv.Store.BulkEmail().UpdateRecipient(recipient); result.Err != nil {
+				failBulkEmail(bulkEmail, result.Err)
+				return
+			}
+
+			switch recipient.Status {
+			case model.BULK_EMAIL_RECIPIENT_STATUS_SENT:
+				bulkEmail.SentCount++
+			case model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED:
+				bulkEmail.SkippedCount++
+			default:
+				bulkEmail.FailedCount++
+			}
+
+			if recipient.Status != model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED {
+				time.Sleep(interval)
+			}
+		}
+
+		if !updateBulkEmailProgress(bulkEmail) {
+			return
+		}
+	}
+
+	bulkEmail.Status = model.BULK_EMAIL_STATUS_SUCCESS
+	bulkEmail.EndAt = model.GetMillis()
+	updateBulkEmailProgress(bulkEmail)
+}
+
+// updateBulkEmailProgress saves the counts of a running bulk email and returns false if it has been
+// canceled or can't be saved, in which case it shouldn't be sent to any more recipients.
+func updateBulkEmailProgress(bulkEmail *model.BulkEmail) bool {
+	if result := <-Srv.Store.BulkEmail().Get(bulkEmail.Id); result.Err != nil {
+		l4g.Error(utils.T("app.bulk_email.run.update.error"), bulkEmail.Id, result.Err.Error())
+		return false
+	} else if saved := result.Data.(*model.BulkEmail); saved.Status == model.BULK_EMAIL_STATUS_CANCELED {
+		bulkEmail.Status = saved.Status
+		bulkEmail.EndAt = saved.EndAt
+	}
+
+	if result := <-Srv.Store.BulkEmail().Update(bulkEmail); result.Err != nil {
+		l4g.Error(utils.T("app.bulk_email.run.update.error"), bulkEmail.Id, result.Err.Error())
+		return false
+	}
+
+	return !bulkEmail.IsFinished()
+}
+
+func failBulkEmail(bulkEmail *model.BulkEmail, err *model.AppError) {
+	l4g.Error(utils.T("app.bulk_email.run.failed.error"), bulkEmail.Id, err.Error())
+
+	bulkEmail.Status = model.BULK_EMAIL_STATUS_FAILED
+	bulkEmail.Error = err.Error()
+	bulkEmail.EndAt = model.GetMillis()
+	if result := <-Srv.Store.BulkEmail().Update(bulkEmail); result.Err != nil {
+		l4g.Error(utils.T("app.bulk_email.run.update.error"), bulkEmail.Id, result.Err.Error())
+	}
+}
+
+// getBulkEmailUserIds returns the ids of the users in the segment of a bulk email. Deactivated users
+// are left out when the segment is read from the store, but they're also skipped when sending since
+// they may be deactivated in the meantime.
+func getBulkEmailUserIds(bulkEmail *model.BulkEmail) ([]string, *model.AppError) {
+	userIds := []string{}
+	added := map[string]bool{}
+
+	addUserId := func(userId string) {
+		if !added[userId] {
+			added[userId] = true
+			userIds = append(userIds, userId)
+		}
+	}
+
+	switch bulkEmail.Segment {
+	case model.BULK_EMAIL_SEGMENT_ALL, model.BULK_EMAIL_SEGMENT_TEAM:
+		for offset := 0; ; offset += BULK_EMAIL_USERS_PER_PAGE {
+			var result store.StoreResult
+			if bulkEmail.Segment == model.BULK_EMAIL_SEGMENT_ALL {
+				result = <-Srv.Store.User().GetAllProfiles(offset, BULK_EMAIL_USERS_PER_PAGE)
+			} else {
+				result = <-Srv.Store.User().GetProfiles(bulkEmail.TeamId, offset, BULK_EMAIL_USERS_PER_PAGE)
+			}
+
+			if result.Err != nil {
+				return nil, result.Err
+			}
+
+			users := result.Data.([]*model.User)
+			for _, user := range users {
+				if user.DeleteAt == 0 {
+					addUserId(user.Id)
+				}
+			}
+
+			if len(users) < BULK_EMAIL_USERS_PER_PAGE {
+				break
+			}
+		}
+	case model.BULK_EMAIL_SEGMENT_INACTIVE:
+		since := model.GetInactivitySince(bulkEmail.InactiveDays)
+
+		for offset := 0; ; offset += BULK_EMAIL_USERS_PER_PAGE {
+			result := <-Srv.Store.User().GetInactiveUsers(since, offset, BULK_EMAIL_USERS_PER_PAGE)
+			if result.Err != nil {
+				return nil, result.Err
+			}
+
+			users := result.Data.([]*model.InactiveUser)
+			for _, user := range users {
+				addUserId(user.Id)
+			}
+
+			if len(users) < BULK_EMAIL_USERS_PER_PAGE {
+				break
+			}
+		}
+	case model.BULK_EMAIL_SEGMENT_OUTDATED_CLIENT:
+		result := <-Srv.Store.Device().GetVersionCounts(0)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		for _, count := range result.Data.([]*model.DeviceVersionCount) {
+			minVersion := bulkEmail.MinVersion
+			if len(minVersion) == 0 {
+				minVersion = getMinClientVersion(getClientPlatformForDevice(count.Platform))
+			}
+
+			if len(minVersion) == 0 || !model.IsValidVersion(count.AppVersion) || model.IsVersionAtLeast(count.AppVersion, minVersion) {
+				continue
+			}
+
+			result := <-Srv.Store.Device().GetUserIdsByAppVersion(count.Platform, count.AppVersion, 0)
+			if result.Err != nil {
+				return nil, result.Err
+			}
+
+			for _, userId := range result.Data.([]string) {
+				addUserId(userId)
+			}
+		}
+	}
+
+	return userIds, nil
+}
+
+func getClientPlatformForDevice(platform string) string {
+	switch platform {
+	case model.DEVICE_PLATFORM_IOS, model.DEVICE_PLATFORM_ANDROID:
+		return model.CLIENT_PLATFORM_MOBILE
+	case model.DEVICE_PLATFORM_DESKTOP:
+		return model.CLIENT_PLATFORM_DESKTOP
+	default:
+		return model.CLIENT_PLATFORM_WEB
+	}
+}
+
+// sendBulkEmailToRecipient sends a bulk email to one of its recipients and sets the recipient's
+// status. A recipient that is skipped has the reason as its error.
+func sendBulkEmailToRecipient(bulkEmail *model.BulkEmail, recipient *model.BulkEmailRecipient) {
+	recipient.Error = ""
+
+	user, err := GetUser(recipient.UserId)
+	if err != nil {
+		recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_FAILED
+		recipient.Error = err.Error()
+		return
+	}
+
+	if user.DeleteAt != 0 {
+		recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED
+		recipient.Error = model.BULK_EMAIL_SKIPPED_DEACTIVATED
+		return
+	}
+
+	if IsBotUser(user.Id) {
+		recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED
+		recipient.Error = model.BULK_EMAIL_SKIPPED_BOT
+		return
+	}
+
+	if !bulkEmail.Critical && hasUnsubscribedFromBulkEmail(user.Id) {
+		recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED
+		recipient.Error = model.BULK_EMAIL_SKIPPED_UNSUBSCRIBED
+		return
+	}
+
+	subject, message := bulkEmail.Render(user, Config().TeamSettings.SiteName)
+
+	if bulkEmail.SendEmail {
+		if err := sendBulkEmailMessage(bulkEmail, user, subject, message); err != nil {
+			recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_FAILED
+			recipient.Error = err.Error()
+			return
+		}
+	}
+
+	if bulkEmail.SendDirectMessage {
+		senderId := bulkEmail.SenderId
+		if len(senderId) == 0 {
+			senderId = bulkEmail.UserId
+		}
+
+		if err := sendBulkEmailDirectMessage(senderId, user.Id, message); err != nil {
+			recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_FAILED
+			recipient.Error = err.Error()
+			return
+		}
+	}
+
+	recipient.Status = model.BULK_EMAIL_RECIPIENT_STATUS_SENT
+}
+
+func hasUnsubscribedFromBulkEmail(userId string) bool {
+	if result := <-Srv.Store.Preference().Get(userId, model.PREFERENCE_CATEGORY_NOTIFICATIONS, model.PREFERENCE_NAME_BULK_EMAIL); result.Err != nil {
+		return false
+	} else {
+		return result.Data.(model.Preference).Value == "false"
+	}
+}
+
+func sendBulkEmailMessage(bulkEmail *model.BulkEmail, user *model.User, subject, message string) *model.AppError {
+	T := utils.GetUserTranslations(user.Locale)
+
+	bodyPage := utils.NewHTMLTemplate("bulk_email_body", user.Locale)
+	bodyPage.Props["SiteURL"] = utils.GetSiteURL()
+	bodyPage.Props["Title"] = subject
+	bodyPage.Html["Message"] = template.HTML(strings.Replace(html.EscapeString(message), "\n", "<br>", -1))
+
+	if !bulkEmail.Critical {
+		bodyPage.Props["Unsubscribe"] = T("app.bulk_email.unsubscribe")
+	}
+
+	if err := utils.SendMail(user.Email, subject, bodyPage.Render()); err != nil {
+		return model.NewAppError("sendBulkEmailMessage", "app.bulk_email.send_email.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func sendBulkEmailDirectMessage(senderId string, userId string, message string) *model.AppError {
+	channel, err := CreateDirectChannel(senderId, userId)
+	if err != nil {
+		return err
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    senderId,
+		Message:   message,
+	}
+
+	if _, err := CreatePost(post, "", false); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	CACHE_WARM_UP_LOG_INTERVAL = 100
+	CACHE_WARM_UP_POSTS        = 60 // the number of posts kept in the last posts cache
+)
+
+// WarmUpCaches loads the data of the channels with posts in the last CacheSettings.WarmUpHours into the
+// in memory caches so that the first requests after a restart don't all have to go to the database. It
+// takes a while on large servers, so it is meant to be run in the background.
+func WarmUpCaches() {
+	start := time.Now()
+	since := model.GetMillis() - int64(*Config().CacheSettings.WarmUpHours)*int64(time.Hour/time.Millisecond)
+
+	var channels []*model.Channel
+	if result := <-Srv.Store.Channel().GetActiveSince(since, *Config().CacheSettings.WarmUpChannels); result.Err != nil {
+		l4g.Error(utils.T("app.cache_warm_up.get_channels.error"), result.Err.Error())
+		return
+	} else {
+		channels = result.Data.([]*model.Channel)
+	}
+
+	l4g.Info(utils.T("app.cache_warm_up.start"), len(channels))
+
+	metrics := einterfaces.GetMetricsInterface()
+
+	for i, channel := range channels {
+		warmUpChannelCaches(channel.Id)
+
+		if metrics != nil {
+			metrics.SetCacheWarmUpProgress(float64(i+1) / float64(len(channels)) * 100)
+		}
+
+		if (i+1)%CACHE_WARM_UP_LOG_INTERVAL == 0 {
+			l4g.Info(utils.T("app.cache_warm_up.progress"), i+1, len(channels))
+		}
+	}
+
+	elapsed := time.Since(start)
+	if metrics != nil {
+		metrics.SetCacheWarmUpProgress(100)
+		metrics.ObserveCacheWarmUpDuration(elapsed.Seconds())
+	}
+
+	l4g.Info(utils.T("app.cache_warm_up.finish"), len(channels), elapsed.String())
+}
+
+func warmUpChannelCaches(channelId string) {
+	cchan := Srv.Store.Channel().Get(channelId, true)
+	mcchan := Srv.Store.Channel().GetMemberCount(channelId, true)
+	npchan := Srv.Store.Channel().GetAllChannelMembersNotifyPropsForChannel(channelId, true)
+	uchan := Srv.Store.User().GetAllProfilesInChannel(channelId, true)
+	pchan := Srv.Store.Post().GetPosts(channelId, 0, CACHE_WARM_UP_POSTS, true)
+
+	for _, storeChannel := range []store.StoreChannel{cchan, mcchan, npchan, uchan} {
+		if result := <-storeChannel; result.Err != nil {
+			l4g.Warn(utils.T("app.cache_warm_up.channel.warn"), channelId, result.Err.Error())
+		}
+	}
+
+	if result := <-pchan; result.Err != nil {
+		l4g.Warn(utils.T("app.cache_warm_up.channel.warn"), channelId, result.Err.Error())
+	} else {
+		for _, post := range result.Data.(*model.PostList).Posts {
+			if post.HasReactions {
+				if result := <-Srv.Store.Reaction().GetForPost(post.Id, true); result.Err != nil {
+					l4g.Warn(utils.T("app.cache_warm_up.channel.warn"), channelId, result.Err.Error())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/utils"
+)
+
+func TestWarmUpCaches(t *testing.T) {
+	th := Setup().InitBasic()
+
+	for _, name := range []string{"channel", "channel_member_counts", "last_posts"} {
+		utils.PurgeNamedCache(name)
+	}
+
+	WarmUpCaches()
+
+	lens := map[string]int{}
+	for _, stats := range utils.GetNamedCacheStats() {
+		lens[stats.Name] = stats.Len
+	}
+
+	for _, name := range []string{"channel", "channel_member_counts", "last_posts"} {
+		if lens[name] == 0 {
+			t.Fatalf("should have warmed up the %v cache with the channel %v", name, th.BasicChannel.Id)
+		}
+	}
+}
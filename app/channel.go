@@ -114,8 +114,8 @@ func CreateChannelWithUser(channel *model.Channel, userId string) (*model.Channe
 	if count, err := GetNumberOfChannelsOnTeam(channel.TeamId); err != nil {
 		return nil, err
 	} else {
-		if int64(count+1) > *utils.Cfg.TeamSettings.MaxChannelsPerTeam {
-			return nil, model.NewAppError("CreateChannelWithUser", "api.channel.create_channel.max_channel_limit.app_error", map[string]interface{}{"MaxChannelsPerTeam": *utils.Cfg.TeamSettings.MaxChannelsPerTeam}, "", http.StatusBadRequest)
+		if int64(count+1) > *Config().TeamSettings.MaxChannelsPerTeam {
+			return nil, model.NewAppError("CreateChannelWithUser", "api.channel.create_channel.max_channel_limit.app_error", map[string]interface{}{"MaxChannelsPerTeam": *Config().TeamSettings.MaxChannelsPerTeam}, "", http.StatusBadRequest)
 		}
 	}
 
@@ -194,7 +194,7 @@ func CreateDirectChannel(userId string, otherUserId string) (*model.Channel, *mo
 }
 
 func WaitForChannelMembership(channelId string, userId string) {
-	if len(utils.Cfg.SqlSettings.DataSourceReplicas) > 0 {
+	if len(Config().SqlSettings.DataSourceReplicas) > 0 {
 		now := model.GetMillis()
 
 		for model.GetMillis()-now < 12000 {
@@ -254,7 +254,7 @@ func CreateGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
 				UserId:      user.Id,
 				ChannelId:   group.Id,
 				NotifyProps: model.GetDefaultChannelNotifyProps(),
-				Roles:       model.ROLE_CHANNEL_USER.Id,
+				Roles:       getChannelMemberRoles(user),
 			}
 
 			if result := <-Srv.Store.Channel().SaveMember(cm); result.Err != nil {
@@ -273,10 +273,26 @@ func CreateGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
 }
 
 func UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
+	var oldChannel *model.Channel
+	if result := <-Srv.Store.Channel().GetFromMaster(channel.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		oldChannel = result.Data.(*model.Channel)
+	}
+
 	if result := <-Srv.Store.Channel().Update(channel); result.Err != nil {
 		return nil, result.Err
 	} else {
 		InvalidateCacheForChannel(channel)
+
+		if oldChannel.Name != channel.Name || oldChannel.TeamId != channel.TeamId {
+			InvalidateCacheForChannel(oldChannel)
+		}
+
+		if oldChannel.Name != channel.Name && oldChannel.TeamId == channel.TeamId && !oldChannel.IsGroupOrDirect() {
+			saveNameRedirect(model.NAME_REDIRECT_TYPE_CHANNEL, channel.TeamId, oldChannel.Name, channel.Id)
+		}
+
 		return channel, nil
 	}
 }
@@ -286,6 +302,75 @@ func PatchChannel(channel *model.Channel, patch *model.ChannelPatch) (*model.Cha
 	return UpdateChannel(channel)
 }
 
+// UpdateChannelAllowedReactions restricts the emoji users can react with in the channel. An empty
+// list removes the restriction.
+func UpdateChannelAllowedReactions(channel *model.Channel, emojiNames []string) (*model.Channel, *model.AppError) {
+	allowed := model.StringArray{}
+	seen := make(map[string]bool)
+	for _, emojiName := range emojiNames {
+		emojiName = strings.Trim(strings.TrimSpace(emojiName), ":")
+		if len(emojiName) == 0 || seen[emojiName] {
+			continue
+		}
+
+		seen[emojiName] = true
+		allowed = append(allowed, emojiName)
+	}
+
+	channel.AllowedReactions = allowed
+
+	if rchannel, err := UpdateChannel(channel); err != nil {
+		if err.Id == "model.channel.is_valid.allowed_reactions.app_error" {
+			err.StatusCode = http.StatusBadRequest
+		}
+
+		return nil, err
+	} else {
+		return rchannel, nil
+	}
+}
+
+// UpdateChannelIntegrationOverrides forbids or allows integrations to post with their own username
+// and icon in the channel.
+func UpdateChannelIntegrationOverrides(channel *model.Channel, forbidden bool) (*model.Channel, *model.AppError) {
+	channel.ForbidIntegrationOverrides = forbidden
+	return UpdateChannel(channel)
+}
+
+// UpdateChannelModerationSettings restricts posting, reacting and channel wide mentions for the
+// members and guests of a channel. Direct and group messages have no admins so they can't be moderated.
+func UpdateChannelModerationSettings(channel *model.Channel, settings *model.ChannelModerationSettings) (*model.Channel, *model.AppError) {
+	if channel.IsGroupOrDirect() {
+		return nil, model.NewAppError("UpdateChannelModerationSettings", "app.channel.update_moderation_settings.group_or_direct.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	channel.ModerationSettings = *settings
+	return UpdateChannel(channel)
+}
+
+// checkChannelModeration returns a 403 if the channel's moderation settings keep the user from doing
+// the moderated action. Channel admins, team admins and system admins are never kept from doing it.
+func checkChannelModeration(channel *model.Channel, userId string, action string) *model.AppError {
+	if channel.IsGroupOrDirect() || !channel.ModerationSettings.IsRestricted(action) {
+		return nil
+	}
+
+	if HasPermissionToChannel(userId, channel.Id, model.PERMISSION_MANAGE_CHANNEL_ROLES) {
+		return nil
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if channel.ModerationSettings.Allows(action, user.IsGuest()) {
+		return nil
+	}
+
+	return model.NewAppError("checkChannelModeration", "app.channel.moderation."+action+".app_error", nil, "channel_id="+channel.Id+", user_id="+userId, http.StatusForbidden)
+}
+
 func UpdateChannelMemberRoles(channelId string, userId string, newRoles string) (*model.ChannelMember, *model.AppError) {
 	var member *model.ChannelMember
 	var err *model.AppError
@@ -337,18 +422,38 @@ func UpdateChannelMemberNotifyProps(data map[string]string, channelId string, us
 }
 
 func DeleteChannel(channel *model.Channel, userId string) *model.AppError {
-	uc := Srv.Store.User().Get(userId)
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		return result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	T := utils.GetUserTranslations(user.Locale)
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		Message:   fmt.Sprintf(T("api.channel.delete_channel.archived"), user.Username),
+		Type:      model.POST_CHANNEL_DELETED,
+		UserId:    userId,
+		Props: model.StringInterface{
+			"username": user.Username,
+		},
+	}
+
+	return deleteChannel(channel, post)
+}
+
+// deleteChannel archives a channel after posting a message in it that says why it was archived.
+func deleteChannel(channel *model.Channel, post *model.Post) *model.AppError {
 	ihc := Srv.Store.Webhook().GetIncomingByChannel(channel.Id)
 	ohc := Srv.Store.Webhook().GetOutgoingByChannel(channel.Id, -1, -1)
 
-	if uresult := <-uc; uresult.Err != nil {
-		return uresult.Err
-	} else if ihcresult := <-ihc; ihcresult.Err != nil {
+	if ihcresult := <-ihc; ihcresult.Err != nil {
 		return ihcresult.Err
 	} else if ohcresult := <-ohc; ohcresult.Err != nil {
 		return ohcresult.Err
 	} else {
-		user := uresult.Data.(*model.User)
 		incomingHooks := ihcresult.Data.([]*model.IncomingWebhook)
 		outgoingHooks := ohcresult.Data.([]*model.OutgoingWebhook)
 
@@ -364,18 +469,6 @@ func DeleteChannel(channel *model.Channel, userId string) *model.AppError {
 			return err
 		}
 
-		T := utils.GetUserTranslations(user.Locale)
-
-		post := &model.Post{
-			ChannelId: channel.Id,
-			Message:   fmt.Sprintf(T("api.channel.delete_channel.archived"), user.Username),
-			Type:      model.POST_CHANNEL_DELETED,
-			UserId:    userId,
-			Props: model.StringInterface{
-				"username": user.Username,
-			},
-		}
-
 		if _, err := CreatePost(post, channel.TeamId, false); err != nil {
 			l4g.Error(utils.T("api.channel.delete_channel.failed_post.error"), err)
 		}
@@ -442,7 +535,7 @@ func addUserToChannel(user *model.User, channel *model.Channel) (*model.ChannelM
 		ChannelId:   channel.Id,
 		UserId:      user.Id,
 		NotifyProps: model.GetDefaultChannelNotifyProps(),
-		Roles:       model.ROLE_CHANNEL_USER.Id,
+		Roles:       getChannelMemberRoles(user),
 	}
 	if result := <-Srv.Store.Channel().SaveMember(newMember); result.Err != nil {
 		l4g.Error("Failed to add member user_id=%v channel_id=%v err=%v", user.Id, channel.Id, result.Err)
@@ -713,6 +806,28 @@ func GetPublicChannelsForTeam(teamId string, offset int, limit int) (*model.Chan
 	}
 }
 
+// GetPublicChannelCountForTeam returns the number of public channels on the team that haven't been
+// archived.
+func GetPublicChannelCountForTeam(teamId string) (int64, *model.AppError) {
+	tchan := Srv.Store.Channel().AnalyticsTypeCount(teamId, model.CHANNEL_OPEN)
+	dchan := Srv.Store.Channel().AnalyticsDeletedTypeCount(teamId, model.CHANNEL_OPEN)
+
+	var count int64
+	if result := <-tchan; result.Err != nil {
+		return 0, result.Err
+	} else {
+		count = result.Data.(int64)
+	}
+
+	if result := <-dchan; result.Err != nil {
+		return 0, result.Err
+	} else {
+		count -= result.Data.(int64)
+	}
+
+	return count, nil
+}
+
 func GetChannelMember(channelId string, userId string) (*model.ChannelMember, *model.AppError) {
 	if result := <-Srv.Store.Channel().GetMember(channelId, userId); result.Err != nil {
 		return nil, result.Err
@@ -753,6 +868,31 @@ func GetChannelMemberCount(channelId string) (int64, *model.AppError) {
 	}
 }
 
+// IsLargeChannelMemberCount returns whether a channel with the given number of members is over the
+// large channel threshold. Channels over it skip behaviors that get expensive with many members.
+func IsLargeChannelMemberCount(memberCount int64) bool {
+	threshold := *Config().TeamSettings.LargeChannelThreshold
+	return threshold > 0 && memberCount > threshold
+}
+
+func IsLargeChannel(channelId string) (bool, *model.AppError) {
+	memberCount, err := GetChannelMemberCount(channelId)
+	if err != nil {
+		return false, err
+	}
+
+	return IsLargeChannelMemberCount(memberCount), nil
+}
+
+func GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError) {
+	memberCount, err := GetChannelMemberCount(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ChannelStats{ChannelId: channelId, MemberCount: memberCount, LargeChannel: IsLargeChannelMemberCount(memberCount)}, nil
+}
+
 func GetChannelCounts(teamId string, userId string) (*model.ChannelCounts, *model.AppError) {
 	if result := <-Srv.Store.Channel().GetChannelCounts(teamId, userId); result.Err != nil {
 		return nil, result.Err
@@ -775,6 +915,14 @@ func GetChannelUnread(channelId, userId string) (*model.ChannelUnread, *model.Ap
 	return channelUnread, nil
 }
 
+func GetChannelLastRead(channelId, userId string) (*model.ChannelLastRead, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetChannelLastRead(channelId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ChannelLastRead), nil
+	}
+}
+
 func JoinChannel(channel *model.Channel, userId string) *model.AppError {
 	if channel.DeleteAt > 0 {
 		return model.NewLocAppError("JoinChannel", "api.channel.join_channel.already_deleted.app_error", nil, "")
@@ -929,6 +1077,11 @@ func removeUserFromChannel(userIdToRemove string, removerUserId string, channel
 		return model.NewLocAppError("RemoveUserFromChannel", "api.channel.remove.default.app_error", map[string]interface{}{"Channel": model.DEFAULT_CHANNEL}, "")
 	}
 
+	// Every member of the team belongs in an announcement channel that team members are added to
+	if channel.AutoAddTeamMembers && userIdToRemove == removerUserId {
+		return model.NewAppError("RemoveUserFromChannel", "app.channel.remove_user_from_channel.auto_add.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
 	if cmresult := <-Srv.Store.Channel().RemoveMember(channel.Id, userIdToRemove); cmresult.Err != nil {
 		return cmresult.Err
 	}
@@ -947,6 +1100,8 @@ func removeUserFromChannel(userIdToRemove string, removerUserId string, channel
 	userMsg.Add("remover_id", removerUserId)
 	go Publish(userMsg)
 
+	go CheckChannelAdminSuccession(channel)
+
 	return nil
 }
 
@@ -966,6 +1121,276 @@ func RemoveUserFromChannel(userIdToRemove string, removerUserId string, channel
 	return nil
 }
 
+const (
+	CHANNEL_MEMBERS_BATCH_MAX_SIZE       = 1000
+	CHANNEL_MEMBERS_BATCH_POST_USERNAMES = 20
+)
+
+func prepareChannelMembersBatch(userIds []string) ([]string, *model.AppError) {
+	unique := []string{}
+	seen := map[string]bool{}
+
+	for _, userId := range userIds {
+		if len(userId) != 26 {
+			return nil, model.NewAppError("prepareChannelMembersBatch", "api.channel.members_batch.user_id.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+		}
+
+		if !seen[userId] {
+			seen[userId] = true
+			unique = append(unique, userId)
+		}
+	}
+
+	if len(unique) == 0 || len(unique) > CHANNEL_MEMBERS_BATCH_MAX_SIZE {
+		return nil, model.NewAppError("prepareChannelMembersBatch", "api.channel.members_batch.size.app_error", map[string]interface{}{"Max": CHANNEL_MEMBERS_BATCH_MAX_SIZE}, "", http.StatusBadRequest)
+	}
+
+	return unique, nil
+}
+
+func getChannelMembersBatchUsers(userIds []string) (map[string]*model.User, *model.AppError) {
+	users := map[string]*model.User{}
+
+	if result := <-Srv.Store.User().GetProfileByIds(userIds, true); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, user := range result.Data.([]*model.User) {
+			users[user.Id] = user
+		}
+	}
+
+	return users, nil
+}
+
+func formatChannelMembersBatchUsernames(usernames []string) string {
+	if len(usernames) <= CHANNEL_MEMBERS_BATCH_POST_USERNAMES {
+		return strings.Join(usernames, ", ")
+	}
+
+	return utils.T("api.channel.members_batch.others", map[string]interface{}{
+		"Usernames": strings.Join(usernames[:CHANNEL_MEMBERS_BATCH_POST_USERNAMES], ", "),
+		"Count":     len(usernames) - CHANNEL_MEMBERS_BATCH_POST_USERNAMES,
+	})
+}
+
+// AddChannelMembers adds many users to a channel at once. Users that are already members, aren't active members of the
+// channel's team or don't exist are skipped. A single websocket event and system message cover everyone who was added.
+func AddChannelMembers(userIds []string, channel *model.Channel, userRequestorId string) (*model.ChannelMembersBatchResult, *model.AppError) {
+	if channel.DeleteAt > 0 {
+		return nil, model.NewAppError("AddChannelMembers", "api.channel.add_user_to_channel.deleted.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("AddChannelMembers", "api.channel.add_user_to_channel.type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	userIds, err := prepareChannelMembersBatch(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var userRequestor *model.User
+	if userRequestor, err = GetUser(userRequestorId); err != nil {
+		return nil, err
+	}
+
+	tmchan := Srv.Store.Team().GetMembersByIds(channel.TeamId, userIds)
+	cmchan := Srv.Store.Channel().GetMembersByIds(channel.Id, userIds)
+
+	users, err := getChannelMembersBatchUsers(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	teamMembers := map[string]bool{}
+	if result := <-tmchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, member := range result.Data.([]*model.TeamMember) {
+			teamMembers[member.UserId] = true
+		}
+	}
+
+	channelMembers := map[string]bool{}
+	if result := <-cmchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, member := range *result.Data.(*model.ChannelMembers) {
+			channelMembers[member.UserId] = true
+		}
+	}
+
+	batch := &model.ChannelMembersBatchResult{ChannelId: channel.Id, UserIds: []string{}, SkippedUserIds: []string{}}
+
+	newMembers := []*model.ChannelMember{}
+	for _, userId := range userIds {
+		user, ok := users[userId]
+		if !ok || user.DeleteAt > 0 || !teamMembers[userId] || channelMembers[userId] {
+			batch.SkippedUserIds = append(batch.SkippedUserIds, userId)
+			continue
+		}
+
+		newMembers = append(newMembers, &model.ChannelMember{
+			ChannelId:   channel.Id,
+			UserId:      userId,
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+			Roles:       getChannelMemberRoles(user),
+		})
+	}
+
+	if len(newMembers) == 0 {
+		return batch, nil
+	}
+
+	result := <-Srv.Store.Channel().SaveMembers(channel.Id, newMembers)
+
+	usernames := []string{}
+	for _, member := range result.Data.([]*model.ChannelMember) {
+		InvalidateCacheForUser(member.UserId)
+		batch.UserIds = append(batch.UserIds, member.UserId)
+		usernames = append(usernames, users[member.UserId].Username)
+	}
+	InvalidateCacheForChannelMembers(channel.Id)
+
+	if result.Err != nil {
+		l4g.Error("Failed to add members channel_id=%v added=%v err=%v", channel.Id, len(batch.UserIds), result.Err)
+		return nil, result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_USERS_ADDED, "", channel.Id, "", nil)
+	message.Add("user_ids", batch.UserIds)
+	message.Add("team_id", channel.TeamId)
+	Publish(message)
+
+	go PostAddManyToChannelMessage(userRequestor, usernames, channel)
+
+	UpdateChannelLastViewedAt([]string{channel.Id}, userRequestor.Id)
+
+	return batch, nil
+}
+
+// RemoveChannelMembers removes many users from a channel at once, skipping users that aren't members of it. The
+// removed users are sent the same websocket event as the remaining members even though they have left the channel.
+func RemoveChannelMembers(userIds []string, removerUserId string, channel *model.Channel) (*model.ChannelMembersBatchResult, *model.AppError) {
+	if channel.DeleteAt > 0 {
+		return nil, model.NewAppError("RemoveChannelMembers", "api.channel.remove_user_from_channel.deleted.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channel.Name == model.DEFAULT_CHANNEL {
+		return nil, model.NewAppError("RemoveChannelMembers", "api.channel.remove.default.app_error", map[string]interface{}{"Channel": model.DEFAULT_CHANNEL}, "", http.StatusBadRequest)
+	}
+
+	userIds, err := prepareChannelMembersBatch(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	cmchan := Srv.Store.Channel().GetMembersByIds(channel.Id, userIds)
+
+	users, err := getChannelMembersBatchUsers(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	channelMembers := map[string]bool{}
+	if result := <-cmchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, member := range *result.Data.(*model.ChannelMembers) {
+			channelMembers[member.UserId] = true
+		}
+	}
+
+	batch := &model.ChannelMembersBatchResult{ChannelId: channel.Id, UserIds: []string{}, SkippedUserIds: []string{}}
+
+	for _, userId := range userIds {
+		if channelMembers[userId] {
+			batch.UserIds = append(batch.UserIds, userId)
+		} else {
+			batch.SkippedUserIds = append(batch.SkippedUserIds, userId)
+		}
+	}
+
+	if len(batch.UserIds) == 0 {
+		return batch, nil
+	}
+
+	result := <-Srv.Store.Channel().RemoveMembers(channel.Id, batch.UserIds)
+
+	removed := map[string]bool{}
+	usernames := []string{}
+	for _, userId := range batch.UserIds {
+		InvalidateCacheForUser(userId)
+		removed[userId] = true
+
+		if user, ok := users[userId]; ok {
+			usernames = append(usernames, user.Username)
+		}
+	}
+	InvalidateCacheForChannelMembers(channel.Id)
+
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_USERS_REMOVED, "", channel.Id, "", nil)
+	message.Broadcast.IncludeUsers = removed
+	message.Add("user_ids", batch.UserIds)
+	message.Add("remover_id", removerUserId)
+	go Publish(message)
+
+	go PostRemoveManyFromChannelMessage(removerUserId, usernames, channel)
+	go CheckChannelAdminSuccession(channel)
+
+	return batch, nil
+}
+
+func PostAddManyToChannelMessage(user *model.User, addedUsernames []string, channel *model.Channel) *model.AppError {
+	addedUsername := formatChannelMembersBatchUsernames(addedUsernames)
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		Message:   fmt.Sprintf(utils.T("api.channel.add_member.added"), addedUsername, user.Username),
+		Type:      model.POST_ADD_TO_CHANNEL,
+		UserId:    user.Id,
+		Props: model.StringInterface{
+			"username":      user.Username,
+			"addedUsername": addedUsername,
+		},
+	}
+
+	if _, err := CreatePost(post, channel.TeamId, false); err != nil {
+		return model.NewLocAppError("postAddManyToChannelMessage", "api.channel.post_user_add_remove_message_and_forget.error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func PostRemoveManyFromChannelMessage(removerUserId string, removedUsernames []string, channel *model.Channel) *model.AppError {
+	removedUsername := formatChannelMembersBatchUsernames(removedUsernames)
+
+	translation := "api.channel.remove_members.removed"
+	if len(removedUsernames) == 1 {
+		translation = "api.channel.remove_member.removed"
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		Message:   fmt.Sprintf(utils.T(translation), removedUsername),
+		Type:      model.POST_REMOVE_FROM_CHANNEL,
+		UserId:    removerUserId,
+		Props: model.StringInterface{
+			"removedUsername": removedUsername,
+		},
+	}
+
+	if _, err := CreatePost(post, channel.TeamId, false); err != nil {
+		return model.NewLocAppError("postRemoveManyFromChannelMessage", "api.channel.post_user_add_remove_message_and_forget.error", nil, err.Error())
+	}
+
+	return nil
+}
+
 func GetNumberOfChannelsOnTeam(teamId string) (int, *model.AppError) {
 	// Get total number of channels on current team
 	if result := <-Srv.Store.Channel().GetTeamChannels(teamId); result.Err != nil {
@@ -1031,7 +1456,7 @@ func ViewChannel(view *model.ChannelView, userId string, clearPushNotifications
 	if len(view.PrevChannelId) > 0 {
 		channelIds = append(channelIds, view.PrevChannelId)
 
-		if *utils.Cfg.EmailSettings.SendPushNotifications && clearPushNotifications && len(view.ChannelId) > 0 {
+		if *Config().EmailSettings.SendPushNotifications && clearPushNotifications && len(view.ChannelId) > 0 {
 			pchan = Srv.Store.User().GetUnreadCountForChannel(userId, view.ChannelId)
 		}
 	}
@@ -1056,10 +1481,22 @@ func ViewChannel(view *model.ChannelView, userId string, clearPushNotifications
 		return result.Err
 	}
 
+	if *Config().TeamSettings.EnableReadReceipts {
+		for _, channelId := range channelIds {
+			if err := UpdateChannelMemberRead(channelId, userId); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+
 	return nil
 }
 
 func PermanentDeleteChannel(channel *model.Channel) *model.AppError {
+	if err := checkChannelNotHeld(channel.Id); err != nil {
+		return err
+	}
+
 	if result := <-Srv.Store.Post().PermanentDeleteByChannel(channel.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1068,6 +1505,14 @@ func PermanentDeleteChannel(channel *model.Channel) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.ChannelMemberRead().PermanentDeleteByChannel(channel.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.NameRedirect().PermanentDeleteByTarget(channel.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Channel().PermanentDelete(channel.Id); result.Err != nil {
 		return result.Err
 	}
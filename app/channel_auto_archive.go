@@ -0,0 +1,292 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	CHANNEL_AUTO_ARCHIVE_TASK_NAME     = "Channel Auto Archiving"
+	CHANNEL_AUTO_ARCHIVE_TASK_INTERVAL = 15 * time.Minute
+	CHANNEL_AUTO_ARCHIVE_RUN_INTERVAL  = 1 * time.Hour
+	CHANNEL_AUTO_ARCHIVE_BATCH_SIZE    = 200
+)
+
+// InitChannelAutoArchiving starts the job that checks every 15 minutes whether the channel auto-archiving
+// job has run in the last hour, so that it runs about once an hour across the cluster.
+func InitChannelAutoArchiving() {
+	if task := model.GetTaskByName(CHANNEL_AUTO_ARCHIVE_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(CHANNEL_AUTO_ARCHIVE_TASK_NAME, runChannelAutoArchivingIfDue, CHANNEL_AUTO_ARCHIVE_TASK_INTERVAL)
+}
+
+func runChannelAutoArchivingIfDue() {
+	if !*Config().TeamSettings.EnableChannelAutoArchiving {
+		return
+	}
+
+	var lastRun int64
+	if result := <-Srv.Store.System().Get(); result.Err != nil {
+		l4g.Error(utils.T("app.channel_auto_archive.run.error"), result.Err.Error())
+		return
+	} else {
+		lastRun, _ = strconv.ParseInt(result.Data.(model.StringMap)[model.SYSTEM_LAST_CHANNEL_AUTO_ARCHIVE_RUN], 10, 64)
+	}
+
+	now := model.GetMillis()
+	if lastRun > now-int64(CHANNEL_AUTO_ARCHIVE_RUN_INTERVAL/time.Millisecond) {
+		return
+	}
+
+	if result := <-Srv.Store.System().SaveOrUpdate(&model.System{Name: model.SYSTEM_LAST_CHANNEL_AUTO_ARCHIVE_RUN, Value: strconv.FormatInt(now, 10)}); result.Err != nil {
+		l4g.Error(utils.T("app.channel_auto_archive.run.error"), result.Err.Error())
+		return
+	}
+
+	RunChannelAutoArchiving()
+}
+
+// RunChannelAutoArchiving archives the channels whose grace period has ended and warns the channel
+// admins of the channels that have been idle for longer than TeamSettings.ChannelAutoArchiveIdleDays.
+// Warnings about channels that have been posted in since are forgotten first. Only a batch of channels
+// is archived and warned about each run, and the rest are left for the next run.
+func RunChannelAutoArchiving() {
+	if result := <-Srv.Store.ChannelAutoArchive().ResetActive(); result.Err != nil {
+		l4g.Error(utils.T("app.channel_auto_archive.run.error"), result.Err.Error())
+		return
+	}
+
+	if result := <-Srv.Store.ChannelAutoArchive().GetDue(model.GetMillis(), CHANNEL_AUTO_ARCHIVE_BATCH_SIZE); result.Err != nil {
+		l4g.Error(utils.T("app.channel_auto_archive.run.error"), result.Err.Error())
+	} else {
+		for _, archive := range result.Data.([]*model.ChannelAutoArchive) {
+			if err := archiveIdleChannel(archive); err != nil {
+				l4g.Error(utils.T("app.channel_auto_archive.archive.error"), archive.ChannelId, err.Error())
+			}
+		}
+	}
+
+	since := model.GetInactivitySince(*Config().TeamSettings.ChannelAutoArchiveIdleDays)
+	if result := <-Srv.Store.ChannelAutoArchive().GetIdleChannels(since, CHANNEL_AUTO_ARCHIVE_BATCH_SIZE); result.Err != nil {
+		l4g.Error(utils.T("app.channel_auto_archive.run.error"), result.Err.Error())
+	} else {
+		for _, channel := range result.Data.([]*model.Channel) {
+			if err := warnIdleChannel(channel); err != nil {
+				l4g.Error(utils.T("app.channel_auto_archive.warn.error"), channel.Id, err.Error())
+			}
+		}
+	}
+}
+
+// warnIdleChannel posts in an idle channel to tell its channel admins when it will be archived. The
+// warning is recorded after it's posted so that the post isn't counted as activity in the channel.
+func warnIdleChannel(channel *model.Channel) *model.AppError {
+	adminIds, err := getChannelAdminIds(channel)
+	if err != nil {
+		return err
+	}
+
+	poster, err := getChannelAutoArchivePoster(channel, adminIds)
+	if err != nil {
+		return err
+	}
+
+	mentions := []string{}
+	if len(adminIds) > 0 {
+		if result := <-Srv.Store.User().GetProfileByIds(adminIds, true); result.Err != nil {
+			return result.Err
+		} else {
+			for _, admin := range result.Data.([]*model.User) {
+				mentions = append(mentions, "@"+admin.Username)
+			}
+		}
+	}
+
+	graceDays := *Config().TeamSettings.ChannelAutoArchiveGracePeriodDays
+	T := utils.GetUserTranslations(poster.Locale)
+
+	message := T("app.channel_auto_archive.warning", map[string]interface{}{
+		"IdleDays":  *Config().TeamSettings.ChannelAutoArchiveIdleDays,
+		"GraceDays": graceDays,
+	})
+	if len(mentions) > 0 {
+		message = strings.Join(mentions, " ") + " " + message
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    poster.Id,
+		Type:      model.POST_SYSTEM_GENERIC,
+		Message:   message,
+	}
+
+	if _, err := CreatePost(post, channel.TeamId, false); err != nil {
+		return err
+	}
+
+	warnedAt := model.GetMillis()
+	archive := &model.ChannelAutoArchive{
+		ChannelId: channel.Id,
+		WarnedAt:  warnedAt,
+		ArchiveAt: warnedAt + int64(graceDays)*24*60*60*1000,
+	}
+
+	if result := <-Srv.Store.ChannelAutoArchive().Save(archive); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// archiveIdleChannel archives a channel whose grace period has ended, unless it has been posted in
+// since it was warned about.
+func archiveIdleChannel(archive *model.ChannelAutoArchive) *model.AppError {
+	channel, err := GetChannel(archive.ChannelId)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	if channel != nil && channel.DeleteAt == 0 && channel.LastPostAt <= archive.WarnedAt {
+		adminIds, err := getChannelAdminIds(channel)
+		if err != nil {
+			return err
+		}
+
+		poster, err := getChannelAutoArchivePoster(channel, adminIds)
+		if err != nil {
+			return err
+		}
+
+		T := utils.GetUserTranslations(poster.Locale)
+
+		post := &model.Post{
+			ChannelId: channel.Id,
+			UserId:    poster.Id,
+			Type:      model.POST_SYSTEM_GENERIC,
+			Message:   T("app.channel_auto_archive.archived", map[string]interface{}{"IdleDays": *Config().TeamSettings.ChannelAutoArchiveIdleDays}),
+		}
+
+		if err := deleteChannel(channel, post); err != nil {
+			return err
+		}
+
+		l4g.Info(utils.T("app.channel_auto_archive.archived.info"), channel.Id)
+	}
+
+	if result := <-Srv.Store.ChannelAutoArchive().Delete(archive.ChannelId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func getChannelAdminIds(channel *model.Channel) ([]string, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetAdminIds(channel.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]string), nil
+	}
+}
+
+// getChannelAutoArchivePoster returns the user that the auto-archiving posts in a channel are made as.
+// Users aren't notified of their own mentions, so it's the creator of the channel or a system admin
+// that isn't one of its channel admins if there is one, and the longest serving channel admin if not.
+func getChannelAutoArchivePoster(channel *model.Channel, adminIds []string) (*model.User, *model.AppError) {
+	isAdmin := make(map[string]bool)
+	for _, adminId := range adminIds {
+		isAdmin[adminId] = true
+	}
+
+	if len(channel.CreatorId) > 0 && !isAdmin[channel.CreatorId] {
+		if creator, err := GetUser(channel.CreatorId); err == nil && creator.DeleteAt == 0 {
+			return creator, nil
+		}
+	}
+
+	if result := <-Srv.Store.User().GetSystemAdminProfiles(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, admin := range result.Data.(map[string]*model.User) {
+			if admin.DeleteAt == 0 && !isAdmin[admin.Id] {
+				return admin, nil
+			}
+		}
+	}
+
+	if len(adminIds) > 0 {
+		return GetUser(adminIds[0])
+	}
+
+	return nil, model.NewAppError("getChannelAutoArchivePoster", "app.channel_auto_archive.poster.app_error", nil, "channel_id="+channel.Id, http.StatusNotFound)
+}
+
+// ExemptChannelFromAutoArchiving keeps a channel from ever being archived for being idle, including
+// when it has already been warned about.
+func ExemptChannelFromAutoArchiving(channel *model.Channel, userId string) (*model.ChannelAutoArchive, *model.AppError) {
+	if !(channel.Type == model.CHANNEL_OPEN || channel.Type == model.CHANNEL_PRIVATE) {
+		return nil, model.NewAppError("ExemptChannelFromAutoArchiving", "app.channel_auto_archive.channel_type.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	archive := &model.ChannelAutoArchive{
+		ChannelId: channel.Id,
+		Exempt:    true,
+		ExemptBy:  userId,
+	}
+
+	if result := <-Srv.Store.ChannelAutoArchive().Save(archive); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ChannelAutoArchive), nil
+	}
+}
+
+// RemoveChannelAutoArchiveExemption lets a channel be archived for being idle again. It's warned about
+// like any other channel once it has been idle for long enough.
+func RemoveChannelAutoArchiveExemption(channelId string) *model.AppError {
+	if archive, err := GetChannelAutoArchive(channelId); err != nil {
+		return err
+	} else if !archive.Exempt {
+		return model.NewAppError("RemoveChannelAutoArchiveExemption", "app.channel_auto_archive.not_exempt.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.ChannelAutoArchive().Delete(channelId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetChannelAutoArchive(channelId string) (*model.ChannelAutoArchive, *model.AppError) {
+	if result := <-Srv.Store.ChannelAutoArchive().Get(channelId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ChannelAutoArchive), nil
+	}
+}
+
+func GetUpcomingChannelArchivals(page int, perPage int) ([]*model.UpcomingChannelArchival, *model.AppError) {
+	if result := <-Srv.Store.ChannelAutoArchive().GetUpcoming(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.UpcomingChannelArchival), nil
+	}
+}
+
+func GetUpcomingChannelArchivalsCount() (int64, *model.AppError) {
+	if result := <-Srv.Store.ChannelAutoArchive().GetUpcomingCount(); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// ConvertChannelToPrivate makes a public channel private. Its members and history stay the same, but
+// only its members can see it from then on.
+func ConvertChannelToPrivate(channel *model.Channel, userId string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_OPEN {
+		return nil, model.NewAppError("ConvertChannelToPrivate", "app.channel.convert_to_private.not_public.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if channel.Name == model.DEFAULT_CHANNEL {
+		return nil, model.NewAppError("ConvertChannelToPrivate", "app.channel.convert_to_private.default_channel.app_error", map[string]interface{}{"Channel": model.DEFAULT_CHANNEL}, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	channel.Type = model.CHANNEL_PRIVATE
+
+	rchannel, err := UpdateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	postChannelConvertedMessage(rchannel, userId, "api.channel.convert_to_private.converted")
+	publishChannelConverted(rchannel, rchannel.TeamId, "")
+
+	return rchannel, nil
+}
+
+// ConvertChannelToPublic makes a private channel public, which lets everyone on the team read its
+// history. It's only allowed when TeamSettings.AllowPrivateToPublicConversion is on.
+func ConvertChannelToPublic(channel *model.Channel, userId string) (*model.Channel, *model.AppError) {
+	if !*Config().TeamSettings.AllowPrivateToPublicConversion {
+		return nil, model.NewAppError("ConvertChannelToPublic", "app.channel.convert_to_public.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if channel.Type != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("ConvertChannelToPublic", "app.channel.convert_to_public.not_private.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	channel.Type = model.CHANNEL_OPEN
+
+	rchannel, err := UpdateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	postChannelConvertedMessage(rchannel, userId, "api.channel.convert_to_public.converted")
+	publishChannelConverted(rchannel, rchannel.TeamId, "")
+
+	return rchannel, nil
+}
+
+// ConvertGroupMessageToChannel turns a group message into a private channel on the team, keeping its
+// members and history. Every member has to be on the team, and the user converting it becomes an
+// admin of the new channel.
+func ConvertGroupMessageToChannel(channel *model.Channel, userId string, teamId string, name string, displayName string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_GROUP {
+		return nil, model.NewAppError("ConvertGroupMessageToChannel", "app.channel.convert_group_message.not_group.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	var members *model.ChannelMembers
+	if result := <-Srv.Store.Channel().GetMembers(channel.Id, 0, model.CHANNEL_GROUP_MAX_USERS); result.Err != nil {
+		return nil, result.Err
+	} else {
+		members = result.Data.(*model.ChannelMembers)
+	}
+
+	userIds := []string{}
+	for _, member := range *members {
+		userIds = append(userIds, member.UserId)
+	}
+
+	if teamMembers, err := GetTeamMembersByIds(teamId, userIds); err != nil {
+		return nil, err
+	} else if len(teamMembers) != len(userIds) {
+		return nil, model.NewAppError("ConvertGroupMessageToChannel", "app.channel.convert_group_message.team_members.app_error", nil, "channel_id="+channel.Id+", team_id="+teamId, http.StatusBadRequest)
+	}
+
+	channel.Type = model.CHANNEL_PRIVATE
+	channel.TeamId = teamId
+	channel.Name = name
+	channel.DisplayName = displayName
+
+	rchannel, err := UpdateChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := UpdateChannelMemberRoles(rchannel.Id, userId, model.ROLE_CHANNEL_USER.Id+" "+model.ROLE_CHANNEL_ADMIN.Id); err != nil {
+		l4g.Error(err.Error())
+	}
+
+	postChannelConvertedMessage(rchannel, userId, "api.channel.convert_group_message.converted")
+	publishChannelConverted(rchannel, "", rchannel.Id)
+
+	return rchannel, nil
+}
+
+func postChannelConvertedMessage(channel *model.Channel, userId string, translationId string) {
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		l4g.Error(utils.T("api.channel.post_channel_converted_message.error"), result.Err)
+		return
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	T := utils.GetUserTranslations(user.Locale)
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		Message:   fmt.Sprintf(T(translationId), user.Username),
+		Type:      model.POST_CHANNEL_CONVERTED,
+		UserId:    userId,
+		Props: model.StringInterface{
+			"username":     user.Username,
+			"channel_type": channel.Type,
+		},
+	}
+
+	if _, err := CreatePost(post, channel.TeamId, false); err != nil {
+		l4g.Error(utils.T("api.channel.post_channel_converted_message.error"), err)
+	}
+}
+
+// publishChannelConverted tells clients that a channel changed type, either everyone on the team or,
+// for converted group messages, the members of the channel.
+func publishChannelConverted(channel *model.Channel, teamId string, channelId string) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_CONVERTED, teamId, channelId, "", nil)
+	message.Add("channel_id", channel.Id)
+	message.Add("team_id", channel.TeamId)
+	message.Add("channel_type", channel.Type)
+	go Publish(message)
+}
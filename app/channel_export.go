@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/mattermost/platform/model"
+)
+
+// ExportChannelSnapshot writes a zip archive of a channel as it was at the given time, for
+// e-discovery. Along with the channel, its posts, reactions and files, the archive holds a
+// manifest with the checksum of each of them and a summary signed with the export signing key.
+func ExportChannelSnapshot(channelId string, asOf int64, exportedBy string, w io.Writer) (*model.ChannelExportManifest, *model.AppError) {
+	now := model.GetMillis()
+	if asOf == 0 {
+		asOf = now
+	} else if asOf < 0 || asOf > now {
+		return nil, model.NewAppError("ExportChannelSnapshot", "app.channel_export.as_of.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+	}
+
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot *model.ChannelSnapshot
+	if result := <-Srv.Store.Post().GetChannelSnapshot(channelId, asOf); result.Err != nil {
+		return nil, result.Err
+	} else {
+		snapshot = result.Data.(*model.ChannelSnapshot)
+	}
+
+	snapshot.Channel = channel
+
+	// Show everything as it was at the time of the snapshot rather than how it was changed since
+	for _, post := range snapshot.Posts {
+		if len(post.OriginalId) > 0 {
+			post.Id = post.OriginalId
+			post.OriginalId = ""
+		}
+		post.DeleteAt = 0
+	}
+
+	for _, info := range snapshot.FileInfos {
+		info.DeleteAt = 0
+	}
+
+	manifest := &model.ChannelExportManifest{
+		ChannelId:     channel.Id,
+		TeamId:        channel.TeamId,
+		AsOf:          asOf,
+		ExportedAt:    now,
+		ExportedBy:    exportedBy,
+		PostCount:     len(snapshot.Posts),
+		ReactionCount: len(snapshot.Reactions),
+		FileCount:     len(snapshot.FileInfos),
+		Entries:       []*model.ChannelExportEntry{},
+	}
+
+	archive := zip.NewWriter(w)
+
+	if err := writeChannelExportJson(archive, manifest, "channel.json", snapshot.Channel); err != nil {
+		return nil, err
+	}
+
+	if err := writeChannelExportJson(archive, manifest, "posts.json", snapshot.Posts); err != nil {
+		return nil, err
+	}
+
+	if err := writeChannelExportJson(archive, manifest, "reactions.json", snapshot.Reactions); err != nil {
+		return nil, err
+	}
+
+	if err := writeChannelExportJson(archive, manifest, "file_infos.json", snapshot.FileInfos); err != nil {
+		return nil, err
+	}
+
+	for _, info := range snapshot.FileInfos {
+		data, err := ReadFile(info.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := writeChannelExportFile(archive, manifest, "files/"+info.Id+"/"+path.Base(info.Name), data); err != nil {
+			return nil, err
+		}
+	}
+
+	manifestData := []byte(manifest.ToJson())
+	if err := writeChannelExportFile(archive, nil, model.CHANNEL_EXPORT_MANIFEST_FILE, manifestData); err != nil {
+		return nil, err
+	}
+
+	summary := model.NewChannelExportSummary(manifestData, *Config().ComplianceSettings.ExportSigningKey)
+	if err := writeChannelExportFile(archive, nil, model.CHANNEL_EXPORT_SUMMARY_FILE, []byte(summary.ToJson())); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, model.NewAppError("ExportChannelSnapshot", "app.channel_export.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return manifest, nil
+}
+
+func writeChannelExportJson(archive *zip.Writer, manifest *model.ChannelExportManifest, name string, v interface{}) *model.AppError {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return model.NewAppError("ExportChannelSnapshot", "app.channel_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return writeChannelExportFile(archive, manifest, name, data)
+}
+
+// writeChannelExportFile adds a file to the archive and, unless manifest is nil, lists it in the manifest.
+func writeChannelExportFile(archive *zip.Writer, manifest *model.ChannelExportManifest, name string, data []byte) *model.AppError {
+	fw, err := archive.Create(name)
+	if err != nil {
+		return model.NewAppError("ExportChannelSnapshot", "app.channel_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err := fw.Write(data); err != nil {
+		return model.NewAppError("ExportChannelSnapshot", "app.channel_export.write.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if manifest != nil {
+		manifest.Entries = append(manifest.Entries, model.NewChannelExportEntry(name, data))
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+// CheckChannelAdminSuccession makes the longest tenured member of a channel a channel admin if the
+// channel has no active channel admins left and the config asks for it. It's called after members
+// are removed from a channel.
+func CheckChannelAdminSuccession(channel *model.Channel) {
+	if *Config().TeamSettings.ChannelAdminSuccession != model.CHANNEL_ADMIN_SUCCESSION_LONGEST_TENURED {
+		return
+	}
+
+	if !(channel.Type == model.CHANNEL_OPEN || channel.Type == model.CHANNEL_PRIVATE) || channel.DeleteAt > 0 {
+		return
+	}
+
+	if result := <-Srv.Store.Channel().GetAdminCount(channel.Id); result.Err != nil {
+		l4g.Error(utils.T("app.channel_successor.check.error"), channel.Id, result.Err.Error())
+		return
+	} else if result.Data.(int64) > 0 {
+		return
+	}
+
+	if member, err := PromoteChannelSuccessor(channel); err != nil {
+		// A channel that only has guests, bots or deactivated users left stays without an admin
+		if err.StatusCode != http.StatusNotFound {
+			l4g.Error(utils.T("app.channel_successor.check.error"), channel.Id, err.Error())
+		}
+	} else {
+		l4g.Info(utils.T("app.channel_successor.promoted.info"), member.UserId, channel.Id)
+	}
+}
+
+// PromoteChannelSuccessor makes the longest tenured member of a channel that isn't a guest, a bot or
+// deactivated a channel admin of it.
+func PromoteChannelSuccessor(channel *model.Channel) (*model.ChannelMember, *model.AppError) {
+	if err := checkChannelCanHaveAdmins("PromoteChannelSuccessor", channel); err != nil {
+		return nil, err
+	}
+
+	result := <-Srv.Store.Channel().GetLongestTenuredMember(channel.Id)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return makeChannelAdmin(result.Data.(*model.ChannelMember))
+}
+
+// AssignChannelAdmin makes a user a channel admin of a channel, adding them to the channel first if
+// they aren't a member of it.
+func AssignChannelAdmin(channel *model.Channel, userId string, assignerId string) (*model.ChannelMember, *model.AppError) {
+	if err := checkChannelCanHaveAdmins("AssignChannelAdmin", channel); err != nil {
+		return nil, err
+	}
+
+	member, err := GetChannelMember(channel.Id, userId)
+	if err != nil {
+		if err.Id != store.MISSING_CHANNEL_MEMBER_ERROR {
+			return nil, err
+		}
+
+		if member, err = AddChannelMember(userId, channel, assignerId); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, role := range member.GetRoles() {
+		if role == model.ROLE_CHANNEL_GUEST.Id {
+			return nil, model.NewAppError("AssignChannelAdmin", "app.channel_successor.guest.app_error", nil, "channel_id="+channel.Id+", user_id="+userId, http.StatusBadRequest)
+		}
+	}
+
+	return makeChannelAdmin(member)
+}
+
+func checkChannelCanHaveAdmins(where string, channel *model.Channel) *model.AppError {
+	if channel.IsGroupOrDirect() {
+		return model.NewAppError(where, "app.channel_successor.direct.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if channel.DeleteAt > 0 {
+		return model.NewAppError(where, "app.channel_successor.deleted.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func makeChannelAdmin(member *model.ChannelMember) (*model.ChannelMember, *model.AppError) {
+	if member.IsChannelAdmin() {
+		return member, nil
+	}
+
+	return UpdateChannelMemberRoles(member.ChannelId, member.UserId, strings.TrimSpace(member.Roles+" "+model.ROLE_CHANNEL_ADMIN.Id))
+}
+
+func GetOrphanedChannels(page int, perPage int) ([]*model.OrphanedChannel, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetOrphanedChannels(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.OrphanedChannel), nil
+	}
+}
+
+func GetOrphanedChannelsCount() (int64, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetOrphanedChannelsCount(); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	// Limits how many different outdated versions are tracked since clients choose their own versions
+	OUTDATED_CLIENTS_MAX = 1000
+)
+
+var outdatedClients = map[string]*model.OutdatedClient{}
+var outdatedClientsLock sync.Mutex
+
+func getMinClientVersion(platform string) string {
+	switch platform {
+	case model.CLIENT_PLATFORM_DESKTOP:
+		return *Config().ClientRequirementsSettings.DesktopMinVersion
+	case model.CLIENT_PLATFORM_MOBILE:
+		return *Config().ClientRequirementsSettings.MobileMinVersion
+	case model.CLIENT_PLATFORM_WEB:
+		return *Config().ClientRequirementsSettings.WebMinVersion
+	default:
+		return ""
+	}
+}
+
+// CheckClientVersion returns an upgrade required error if the request was made by a version of an
+// app that's older than the minimum version set for it. Requests that don't say which app made
+// them are allowed, as are pings so that outdated apps can still tell that the server is up.
+func CheckClientVersion(w http.ResponseWriter, r *http.Request, session *model.Session) *model.AppError {
+	if strings.HasSuffix(r.URL.Path, "/ping") {
+		return nil
+	}
+
+	value := r.Header.Get(model.HEADER_CLIENT_VERSION)
+	if len(value) == 0 {
+		value = r.URL.Query().Get(model.CLIENT_VERSION_QUERY_PARAM)
+	}
+
+	clientVersion := model.ParseClientVersion(value)
+	if clientVersion == nil {
+		return nil
+	}
+
+	minVersion := getMinClientVersion(clientVersion.Platform)
+	if len(minVersion) == 0 || model.IsVersionAtLeast(clientVersion.Version, minVersion) {
+		return nil
+	}
+
+	recordOutdatedClient(clientVersion, minVersion, session.UserId)
+
+	w.Header().Set(model.HEADER_MIN_CLIENT_VERSION, minVersion)
+
+	return model.NewAppError("CheckClientVersion", "app.client_version.upgrade_required.app_error",
+		map[string]interface{}{"Platform": clientVersion.Platform, "Version": clientVersion.Version, "MinVersion": minVersion},
+		"client="+clientVersion.String(), http.StatusUpgradeRequired)
+}
+
+func recordOutdatedClient(clientVersion *model.ClientVersion, minVersion string, userId string) {
+	outdatedClientsLock.Lock()
+	defer outdatedClientsLock.Unlock()
+
+	key := clientVersion.String()
+
+	outdated, ok := outdatedClients[key]
+	if !ok {
+		if len(outdatedClients) >= OUTDATED_CLIENTS_MAX {
+			return
+		}
+
+		outdated = &model.OutdatedClient{Platform: clientVersion.Platform, Version: clientVersion.Version}
+		outdatedClients[key] = outdated
+	}
+
+	outdated.MinVersion = minVersion
+	outdated.Count++
+	outdated.LastAttemptAt = model.GetMillis()
+	if len(userId) > 0 {
+		outdated.LastUserId = userId
+	}
+}
+
+// GetOutdatedClients returns the outdated versions of apps that have tried to connect to this server
+// since it started, most recent first.
+func GetOutdatedClients() []*model.OutdatedClient {
+	outdatedClientsLock.Lock()
+	defer outdatedClientsLock.Unlock()
+
+	list := []*model.OutdatedClient{}
+	for _, outdated := range outdatedClients {
+		copied := *outdated
+		list = append(list, &copied)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastAttemptAt > list[j].LastAttemptAt
+	})
+
+	return list
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	CLUSTER_HEALTH_CHECK_TASK_NAME = "Cluster Health Check"
+	CLUSTER_HEALTH_CHECK_INTERVAL  = 1 * time.Minute
+)
+
+var serverStartTime = model.GetMillis()
+
+// clusterDivergence is the last divergence between the nodes that the system admins were alerted
+// about, so that they're only alerted again once it changes.
+var clusterDivergence string
+var clusterDivergenceLock sync.Mutex
+
+// clusterProtocolVersion is the protocol version that this node sends cluster messages with.
+var clusterProtocolVersion = model.CLUSTER_PROTOCOL_MIN_VERSION
+var clusterProtocolVersionLock sync.RWMutex
+
+// InitClusterHealthCheck starts probing the other nodes of the cluster and alerting the system
+// admins when the nodes stop running the same configuration or database schema.
+func InitClusterHealthCheck() {
+	if task := model.GetTaskByName(CLUSTER_HEALTH_CHECK_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(CLUSTER_HEALTH_CHECK_TASK_NAME, CheckClusterHealth, CLUSTER_HEALTH_CHECK_INTERVAL)
+}
+
+func CheckClusterHealth() {
+	cluster := einterfaces.GetClusterInterface()
+	if cluster == nil || !*Config().ClusterSettings.Enable {
+		return
+	}
+
+	cluster.ProbeNodes()
+
+	infos := GetClusterStatus()
+	updateClusterProtocolVersion(cluster, infos)
+
+	divergence := getClusterDivergence(infos)
+
+	clusterDivergenceLock.Lock()
+	changed := divergence != clusterDivergence
+	clusterDivergence = divergence
+	clusterDivergenceLock.Unlock()
+
+	if !changed || len(divergence) == 0 {
+		return
+	}
+
+	l4g.Warn(utils.T("app.cluster.health_check.diverged.warn"), divergence)
+
+	// Every node sees the same divergence, so only the node with the lowest id sends the alert
+	for _, info := range infos {
+		if info.IsAlive() && info.Id < cluster.GetClusterId() {
+			return
+		}
+	}
+
+	alertSystemAdminsOfClusterDivergence(divergence)
+}
+
+// GetMyClusterInfo describes this node in the same way that the other nodes of the cluster are.
+func GetMyClusterInfo() *model.ClusterInfo {
+	info := &model.ClusterInfo{
+		Version:    model.CurrentVersion,
+		ConfigHash: utils.CfgHash,
+		StartTime:  serverStartTime,
+
+		ProtocolVersion:    model.CLUSTER_PROTOCOL_VERSION,
+		MinProtocolVersion: model.CLUSTER_PROTOCOL_MIN_VERSION,
+	}
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil {
+		info.Id = cluster.GetClusterId()
+	}
+
+	if sqlStore, ok := Srv.Store.(*store.SqlStore); ok {
+		info.SchemaVersion = sqlStore.SchemaVersion
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		info.Hostname = hostname
+	}
+
+	info.LastSuccessfulPing = model.GetMillis()
+	info.SetAlive(true)
+
+	return info
+}
+
+// GetClusterProtocolVersion returns the protocol version that this node sends cluster messages with.
+func GetClusterProtocolVersion() int {
+	clusterProtocolVersionLock.RLock()
+	defer clusterProtocolVersionLock.RUnlock()
+
+	return clusterProtocolVersion
+}
+
+// updateClusterProtocolVersion switches the protocol version that this node sends cluster messages
+// with to the newest one that every node understands. Nodes only switch to a newer version once the
+// last node that doesn't understand it has been upgraded, so the nodes of a cluster can be upgraded
+// one at a time without any of them receiving messages that they can't read. The version is left as
+// it is if the nodes can't agree on one.
+func updateClusterProtocolVersion(cluster einterfaces.ClusterInterface, infos []*model.ClusterInfo) {
+	version, err := model.NegotiateClusterProtocolVersion(infos)
+	if err != nil {
+		l4g.Error(utils.T("app.cluster.protocol.incompatible.error"), err.Error())
+		return
+	}
+
+	clusterProtocolVersionLock.Lock()
+	changed := version != clusterProtocolVersion
+	clusterProtocolVersion = version
+	clusterProtocolVersionLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	cluster.SetProtocolVersion(version)
+
+	if version < model.CLUSTER_PROTOCOL_VERSION {
+		l4g.Warn(utils.T("app.cluster.protocol.compatibility_mode.warn"), version)
+	} else {
+		l4g.Info(utils.T("app.cluster.protocol.updated.info"), version)
+	}
+}
+
+// getClusterDivergence describes the nodes that don't run the same version, configuration or
+// database schema as the rest, or returns an empty string if they all match.
+func getClusterDivergence(infos []*model.ClusterInfo) string {
+	versions := map[string][]string{}
+	schemaVersions := map[string][]string{}
+	configHashes := map[string][]string{}
+	protocolVersions := map[string][]string{}
+
+	for _, info := range infos {
+		if !info.IsAlive() {
+			continue
+		}
+
+		name := info.Hostname
+		if len(name) == 0 {
+			name = info.Id
+		}
+
+		versions[info.Version] = append(versions[info.Version], name)
+		schemaVersions[info.SchemaVersion] = append(schemaVersions[info.SchemaVersion], name)
+		configHashes[info.ConfigHash] = append(configHashes[info.ConfigHash], name)
+
+		minProtocolVersion, maxProtocolVersion := info.GetProtocolVersions()
+		protocolVersion := fmt.Sprintf("%v-%v", minProtocolVersion, maxProtocolVersion)
+		protocolVersions[protocolVersion] = append(protocolVersions[protocolVersion], name)
+	}
+
+	describe := func(label string, groups map[string][]string) string {
+		if len(groups) < 2 {
+			return ""
+		}
+
+		parts := []string{}
+		for value, names := range groups {
+			sort.Strings(names)
+			parts = append(parts, fmt.Sprintf("%v=%v (%v)", label, value, strings.Join(names, ", ")))
+		}
+		sort.Strings(parts)
+
+		return strings.Join(parts, "; ")
+	}
+
+	// Nodes supporting different protocol versions is expected while a cluster is being upgraded, so
+	// they only diverge once they don't have a version in common
+	protocolDivergence := ""
+	if _, err := model.NegotiateClusterProtocolVersion(infos); err != nil {
+		protocolDivergence = describe("protocol_versions", protocolVersions)
+	}
+
+	divergences := []string{}
+	for _, divergence := range []string{
+		describe("version", versions),
+		describe("schema_version", schemaVersions),
+		describe("config_hash", configHashes),
+		protocolDivergence,
+	} {
+		if len(divergence) > 0 {
+			divergences = append(divergences, divergence)
+		}
+	}
+
+	return strings.Join(divergences, "\n")
+}
+
+func alertSystemAdminsOfClusterDivergence(divergence string) {
+	var admins map[string]*model.User
+	if result := <-Srv.Store.User().GetSystemAdminProfiles(); result.Err != nil {
+		l4g.Error(utils.T("mattermost.system_admins.error"))
+		return
+	} else {
+		admins = result.Data.(map[string]*model.User)
+	}
+
+	for _, admin := range admins {
+		T := utils.GetUserTranslations(admin.Locale)
+
+		// There's no user to send the alert from, so it's sent to each admin's direct channel with themselves
+		channel, err := CreateDirectChannel(admin.Id, admin.Id)
+		if err != nil {
+			l4g.Error(utils.T("app.cluster.health_check.alert.error"), admin.Id, err.Error())
+			continue
+		}
+
+		post := &model.Post{
+			ChannelId: channel.Id,
+			UserId:    admin.Id,
+			Type:      model.POST_SYSTEM_GENERIC,
+			Message:   T("app.cluster.health_check.alert.message") + "\n\n```\n" + divergence + "\n```",
+		}
+
+		if _, err := CreatePost(post, "", false); err != nil {
+			l4g.Error(utils.T("app.cluster.health_check.alert.error"), admin.Id, err.Error())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetClusterDivergence(t *testing.T) {
+	node := func(hostname, configHash, schemaVersion string, alive bool) *model.ClusterInfo {
+		info := &model.ClusterInfo{Id: model.NewId(), Hostname: hostname, Version: "4.2.0", ConfigHash: configHash, SchemaVersion: schemaVersion}
+		info.SetAlive(alive)
+		return info
+	}
+
+	if divergence := getClusterDivergence([]*model.ClusterInfo{node("a", "hash", "4.2.0", true), node("b", "hash", "4.2.0", true)}); divergence != "" {
+		t.Fatal("matching nodes shouldn't diverge", divergence)
+	}
+
+	if divergence := getClusterDivergence([]*model.ClusterInfo{node("a", "hash", "4.2.0", true), node("b", "other", "4.1.0", false)}); divergence != "" {
+		t.Fatal("nodes that aren't alive shouldn't be compared", divergence)
+	}
+
+	divergence := getClusterDivergence([]*model.ClusterInfo{node("a", "hash", "4.2.0", true), node("b", "other", "4.2.0", true), node("c", "hash", "4.2.0", true)})
+	if !strings.Contains(divergence, "config_hash=other (b)") || !strings.Contains(divergence, "config_hash=hash (a, c)") || strings.Contains(divergence, "schema_version") {
+		t.Fatal("should have described the nodes with a different config", divergence)
+	}
+
+	if divergence := getClusterDivergence([]*model.ClusterInfo{node("a", "hash", "4.2.0", true), node("b", "hash", "4.1.0", true)}); !strings.Contains(divergence, "schema_version=4.1.0 (b)") {
+		t.Fatal("should have described the nodes with a different schema", divergence)
+	}
+}
+
+func TestGetClusterDivergenceProtocolVersions(t *testing.T) {
+	node := func(hostname string, minProtocolVersion, protocolVersion int) *model.ClusterInfo {
+		info := &model.ClusterInfo{Id: model.NewId(), Hostname: hostname, Version: "4.2.0", MinProtocolVersion: minProtocolVersion, ProtocolVersion: protocolVersion}
+		info.SetAlive(true)
+		return info
+	}
+
+	if divergence := getClusterDivergence([]*model.ClusterInfo{node("a", 1, 2), node("b", 0, 0)}); divergence != "" {
+		t.Fatal("nodes that have a protocol version in common shouldn't diverge", divergence)
+	}
+
+	if divergence := getClusterDivergence([]*model.ClusterInfo{node("a", 2, 3), node("b", 0, 0)}); !strings.Contains(divergence, "protocol_versions=1-1 (b)") {
+		t.Fatal("should have described the nodes that can't talk to each other", divergence)
+	}
+}
@@ -74,7 +74,7 @@ func ListAutocompleteCommands(teamId string, T goi18n.TranslateFunc) ([]*model.C
 		}
 	}
 
-	if *utils.Cfg.ServiceSettings.EnableCommands {
+	if *Config().ServiceSettings.EnableCommands {
 		if result := <-Srv.Store.Command().GetByTeam(teamId); result.Err != nil {
 			return nil, result.Err
 		} else {
@@ -93,7 +93,7 @@ func ListAutocompleteCommands(teamId string, T goi18n.TranslateFunc) ([]*model.C
 }
 
 func ListTeamCommands(teamId string) ([]*model.Command, *model.AppError) {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return nil, model.NewAppError("ListTeamCommands", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -116,7 +116,7 @@ func ListAllCommands(teamId string, T goi18n.TranslateFunc) ([]*model.Command, *
 		}
 	}
 
-	if *utils.Cfg.ServiceSettings.EnableCommands {
+	if *Config().ServiceSettings.EnableCommands {
 		if result := <-Srv.Store.Command().GetByTeam(teamId); result.Err != nil {
 			return nil, result.Err
 		} else {
@@ -145,7 +145,7 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 		response := provider.DoCommand(args, message)
 		return HandleCommandResponse(provider.GetCommand(args.T), args, response, true)
 	} else {
-		if !*utils.Cfg.ServiceSettings.EnableCommands {
+		if !*Config().ServiceSettings.EnableCommands {
 			return nil, model.NewAppError("ExecuteCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 		}
 
@@ -205,7 +205,7 @@ func ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.App
 					}
 
 					tr := &http.Transport{
-						TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+						TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
 					}
 					client := &http.Client{Transport: tr}
 
@@ -250,7 +250,7 @@ func HandleCommandResponse(command *model.Command, args *model.CommandArgs, resp
 		post.AddProp("from_webhook", "true")
 	}
 
-	if utils.Cfg.ServiceSettings.EnablePostUsernameOverride {
+	if Config().ServiceSettings.EnablePostUsernameOverride {
 		if len(command.Username) != 0 {
 			post.AddProp("override_username", command.Username)
 		} else if len(response.Username) != 0 {
@@ -258,7 +258,7 @@ func HandleCommandResponse(command *model.Command, args *model.CommandArgs, resp
 		}
 	}
 
-	if utils.Cfg.ServiceSettings.EnablePostIconOverride {
+	if Config().ServiceSettings.EnablePostIconOverride {
 		if len(command.IconURL) != 0 {
 			post.AddProp("override_icon_url", command.IconURL)
 		} else if len(response.IconURL) != 0 {
@@ -268,6 +268,10 @@ func HandleCommandResponse(command *model.Command, args *model.CommandArgs, resp
 		}
 	}
 
+	if !builtIn {
+		setIntegrationMetadata(post, model.INTEGRATION_TYPE_SLASH_COMMAND, command.Id)
+	}
+
 	if _, err := CreateCommandPost(post, args.TeamId, response); err != nil {
 		l4g.Error(err.Error())
 	}
@@ -276,12 +280,16 @@ func HandleCommandResponse(command *model.Command, args *model.CommandArgs, resp
 }
 
 func CreateCommand(cmd *model.Command) (*model.Command, *model.AppError) {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return nil, model.NewAppError("CreateCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
 	cmd.Trigger = strings.ToLower(cmd.Trigger)
 
+	if err := checkTeamIntegrationQuota(cmd.TeamId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Command().GetByTeam(cmd.TeamId); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -307,7 +315,7 @@ func CreateCommand(cmd *model.Command) (*model.Command, *model.AppError) {
 }
 
 func GetCommand(commandId string) (*model.Command, *model.AppError) {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return nil, model.NewAppError("GetCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -320,7 +328,7 @@ func GetCommand(commandId string) (*model.Command, *model.AppError) {
 }
 
 func UpdateCommand(oldCmd, updatedCmd *model.Command) (*model.Command, *model.AppError) {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return nil, model.NewAppError("UpdateCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -341,7 +349,7 @@ func UpdateCommand(oldCmd, updatedCmd *model.Command) (*model.Command, *model.Ap
 }
 
 func RegenCommandToken(cmd *model.Command) (*model.Command, *model.AppError) {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return nil, model.NewAppError("RegenCommandToken", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -355,7 +363,7 @@ func RegenCommandToken(cmd *model.Command) (*model.Command, *model.AppError) {
 }
 
 func DeleteCommand(commandId string) *model.AppError {
-	if !*utils.Cfg.ServiceSettings.EnableCommands {
+	if !*Config().ServiceSettings.EnableCommands {
 		return model.NewAppError("DeleteCommand", "api.command.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
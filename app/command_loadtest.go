@@ -66,7 +66,7 @@ type LoadTestProvider struct {
 }
 
 func init() {
-	if !utils.Cfg.ServiceSettings.EnableTesting {
+	if !Config().ServiceSettings.EnableTesting {
 		RegisterCommandProvider(&LoadTestProvider{})
 	}
 }
@@ -87,7 +87,7 @@ func (me *LoadTestProvider) GetCommand(T goi18n.TranslateFunc) *model.Command {
 
 func (me *LoadTestProvider) DoCommand(args *model.CommandArgs, message string) *model.CommandResponse {
 	//This command is only available when EnableTesting is true
-	if !utils.Cfg.ServiceSettings.EnableTesting {
+	if !Config().ServiceSettings.EnableTesting {
 		return &model.CommandResponse{}
 	}
 
@@ -5,6 +5,7 @@ package app
 
 import (
 	"io/ioutil"
+	"net/http"
 
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
@@ -12,7 +13,7 @@ import (
 )
 
 func GetComplianceReports(page, perPage int) (model.Compliances, *model.AppError) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance {
+	if !*Config().ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance {
 		return nil, model.NewLocAppError("GetComplianceReports", "ent.compliance.licence_disable.app_error", nil, "")
 	}
 
@@ -24,24 +25,41 @@ func GetComplianceReports(page, perPage int) (model.Compliances, *model.AppError
 }
 
 func SaveComplianceReport(job *model.Compliance) (*model.Compliance, *model.AppError) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
+	if !*Config().ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
 		return nil, model.NewLocAppError("saveComplianceReport", "ent.compliance.licence_disable.app_error", nil, "")
 	}
 
 	job.Type = model.COMPLIANCE_TYPE_ADHOC
 
+	if len(job.Format) == 0 {
+		job.Format = model.COMPLIANCE_FORMAT_CSV
+	}
+
+	// CSV exports are written by the compliance interface, other formats by their formatter
+	var formatter ComplianceExportFormatter
+	if job.Format != model.COMPLIANCE_FORMAT_CSV {
+		if formatter = GetComplianceExportFormatter(job.Format); formatter == nil {
+			return nil, model.NewAppError("saveComplianceReport", "app.compliance.save.format.app_error", map[string]interface{}{"Format": job.Format}, "", http.StatusBadRequest)
+		}
+	}
+
 	if result := <-Srv.Store.Compliance().Save(job); result.Err != nil {
 		return nil, result.Err
 	} else {
 		job = result.Data.(*model.Compliance)
-		go einterfaces.GetComplianceInterface().RunComplianceJob(job)
+
+		if formatter != nil {
+			go RunComplianceExport(job, formatter)
+		} else {
+			go einterfaces.GetComplianceInterface().RunComplianceJob(job)
+		}
 	}
 
 	return job, nil
 }
 
 func GetComplianceReport(reportId string) (*model.Compliance, *model.AppError) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
+	if !*Config().ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
 		return nil, model.NewLocAppError("downloadComplianceReport", "ent.compliance.licence_disable.app_error", nil, "")
 	}
 
@@ -53,7 +71,7 @@ func GetComplianceReport(reportId string) (*model.Compliance, *model.AppError) {
 }
 
 func GetComplianceFile(job *model.Compliance) ([]byte, *model.AppError) {
-	if f, err := ioutil.ReadFile(*utils.Cfg.ComplianceSettings.Directory + "compliance/" + job.JobName() + ".zip"); err != nil {
+	if f, err := ioutil.ReadFile(getComplianceArchivePath(job)); err != nil {
 		return nil, model.NewLocAppError("readFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
 
 	} else {
@@ -0,0 +1,164 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// ComplianceExportFormatter exports the posts of compliance jobs in the format of an archiving
+// service. CSV exports are written by the compliance interface and don't have a formatter.
+type ComplianceExportFormatter interface {
+	// FormatExport returns the files of the export of a job, keyed by their path in its archive.
+	FormatExport(job *model.Compliance, posts []*model.CompliancePost) (map[string][]byte, *model.AppError)
+
+	// DeliverExport sends the files of an export to the archiving service when one is configured.
+	DeliverExport(job *model.Compliance, files map[string][]byte) *model.AppError
+}
+
+var complianceExportFormatters = make(map[string]ComplianceExportFormatter)
+
+func RegisterComplianceExportFormatter(format string, formatter ComplianceExportFormatter) {
+	complianceExportFormatters[format] = formatter
+}
+
+func GetComplianceExportFormatter(format string) ComplianceExportFormatter {
+	formatter, ok := complianceExportFormatters[format]
+	if ok {
+		return formatter
+	}
+
+	return nil
+}
+
+// GetComplianceExportFormats returns the formats that compliance jobs can be exported in.
+func GetComplianceExportFormats() []string {
+	formats := []string{model.COMPLIANCE_FORMAT_CSV}
+	for format := range complianceExportFormatters {
+		formats = append(formats, format)
+	}
+
+	sort.Strings(formats[1:])
+
+	return formats
+}
+
+// complianceConversation is the posts of a channel that were exported by a compliance job.
+type complianceConversation struct {
+	TeamName           string
+	ChannelName        string
+	ChannelDisplayName string
+	Posts              []*model.CompliancePost
+}
+
+// groupCompliancePostsByChannel groups the posts of a compliance export by channel, keeping the
+// channels in the order of their first post.
+func groupCompliancePostsByChannel(posts []*model.CompliancePost) []*complianceConversation {
+	var conversations []*complianceConversation
+	conversationsByChannel := make(map[string]*complianceConversation)
+
+	for _, post := range posts {
+		key := post.TeamName + "/" + post.ChannelName
+
+		conversation, ok := conversationsByChannel[key]
+		if !ok {
+			conversation = &complianceConversation{
+				TeamName:           post.TeamName,
+				ChannelName:        post.ChannelName,
+				ChannelDisplayName: post.ChannelDisplayName,
+			}
+			conversationsByChannel[key] = conversation
+			conversations = append(conversations, conversation)
+		}
+
+		conversation.Posts = append(conversation.Posts, post)
+	}
+
+	return conversations
+}
+
+// RunComplianceExport exports the posts of a compliance job with a formatter, writes the export to
+// the job's archive so that it can be downloaded and delivers it to the format's archiving service.
+func RunComplianceExport(job *model.Compliance, formatter ComplianceExportFormatter) {
+	job.Status = model.COMPLIANCE_STATUS_RUNNING
+	if result := <-Srv.Store.Compliance().Update(job); result.Err != nil {
+		l4g.Error(utils.T("app.compliance.run_export.update.error"), job.Id, result.Err.Error())
+	}
+
+	if err := runComplianceExport(job, formatter); err != nil {
+		l4g.Error(utils.T("app.compliance.run_export.error"), job.Id, job.Format, err.Error())
+		job.Status = model.COMPLIANCE_STATUS_FAILED
+	} else {
+		job.Status = model.COMPLIANCE_STATUS_FINISHED
+	}
+
+	if result := <-Srv.Store.Compliance().Update(job); result.Err != nil {
+		l4g.Error(utils.T("app.compliance.run_export.update.error"), job.Id, result.Err.Error())
+	}
+}
+
+func runComplianceExport(job *model.Compliance, formatter ComplianceExportFormatter) *model.AppError {
+	result := <-Srv.Store.Compliance().ComplianceExport(job)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	posts := result.Data.([]*model.CompliancePost)
+	job.Count = len(posts)
+
+	files, err := formatter.FormatExport(job, posts)
+	if err != nil {
+		return err
+	}
+
+	if err := writeComplianceArchive(getComplianceArchivePath(job), files); err != nil {
+		return err
+	}
+
+	return formatter.DeliverExport(job, files)
+}
+
+func getComplianceArchivePath(job *model.Compliance) string {
+	return *Config().ComplianceSettings.Directory + "compliance/" + job.JobName() + ".zip"
+}
+
+func writeComplianceArchive(path string, files map[string][]byte) *model.AppError {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return model.NewLocAppError("writeComplianceArchive", "app.compliance.write_archive.app_error", nil, err.Error())
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return model.NewLocAppError("writeComplianceArchive", "app.compliance.write_archive.app_error", nil, err.Error())
+	}
+	defer f.Close()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(f)
+	for _, name := range names {
+		if w, err := archive.Create(name); err != nil {
+			return model.NewLocAppError("writeComplianceArchive", "app.compliance.write_archive.app_error", nil, err.Error())
+		} else if _, err := w.Write(files[name]); err != nil {
+			return model.NewLocAppError("writeComplianceArchive", "app.compliance.write_archive.app_error", nil, err.Error())
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return model.NewLocAppError("writeComplianceArchive", "app.compliance.write_archive.app_error", nil, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/xml"
+	"path"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	ACTIANCE_EXPORT_FILE_NAME = "actiance_export.xml"
+	ACTIANCE_USER_TYPE        = "user"
+)
+
+type ActianceExportFormatter struct {
+}
+
+func init() {
+	RegisterComplianceExportFormatter(model.COMPLIANCE_FORMAT_ACTIANCE, &ActianceExportFormatter{})
+}
+
+type actianceExport struct {
+	XMLName       xml.Name                `xml:"FileDump"`
+	Conversations []*actianceConversation `xml:"Conversation"`
+}
+
+// actianceConversation is the posts of a channel. Users enter the conversation when they first
+// post in it and leave it when it ends.
+type actianceConversation struct {
+	Perspective         string                 `xml:"Perspective,attr"`
+	RoomId              string                 `xml:"RoomID"`
+	StartTime           int64                  `xml:"StartTimeUTC"`
+	ParticipantsEntered []*actianceParticipant `xml:"ParticipantEntered"`
+	Messages            []*actianceMessage     `xml:"Message"`
+	ParticipantsLeft    []*actianceParticipant `xml:"ParticipantLeft"`
+	EndTime             int64                  `xml:"EndTimeUTC"`
+}
+
+type actianceParticipant struct {
+	LoginName        string `xml:"LoginName"`
+	UserType         string `xml:"UserType"`
+	DateTime         int64  `xml:"DateTimeUTC"`
+	CorporateEmailId string `xml:"CorporateEmailID"`
+}
+
+type actianceMessage struct {
+	LoginName string `xml:"LoginName"`
+	UserType  string `xml:"UserType"`
+	DateTime  int64  `xml:"DateTimeUTC"`
+	Content   string `xml:"Content"`
+}
+
+func (me *ActianceExportFormatter) FormatExport(job *model.Compliance, posts []*model.CompliancePost) (map[string][]byte, *model.AppError) {
+	export := &actianceExport{}
+
+	for _, conversation := range groupCompliancePostsByChannel(posts) {
+		export.Conversations = append(export.Conversations, newActianceConversation(conversation))
+	}
+
+	data, err := xml.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, model.NewLocAppError("ActianceExportFormatter.FormatExport", "app.compliance.actiance.marshal.app_error", nil, err.Error())
+	}
+
+	return map[string][]byte{ACTIANCE_EXPORT_FILE_NAME: append([]byte(xml.Header), data...)}, nil
+}
+
+func newActianceConversation(conversation *complianceConversation) *actianceConversation {
+	first := conversation.Posts[0]
+	last := conversation.Posts[len(conversation.Posts)-1]
+
+	actiance := &actianceConversation{
+		Perspective: conversation.ChannelDisplayName,
+		RoomId:      conversation.TeamName + "/" + conversation.ChannelName,
+		StartTime:   first.PostCreateAt / 1000,
+		EndTime:     last.PostCreateAt / 1000,
+	}
+
+	entered := make(map[string]bool)
+	for _, post := range conversation.Posts {
+		if !entered[post.UserEmail] {
+			entered[post.UserEmail] = true
+
+			actiance.ParticipantsEntered = append(actiance.ParticipantsEntered, &actianceParticipant{
+				LoginName:        post.UserUsername,
+				UserType:         ACTIANCE_USER_TYPE,
+				DateTime:         post.PostCreateAt / 1000,
+				CorporateEmailId: post.UserEmail,
+			})
+
+			actiance.ParticipantsLeft = append(actiance.ParticipantsLeft, &actianceParticipant{
+				LoginName:        post.UserUsername,
+				UserType:         ACTIANCE_USER_TYPE,
+				DateTime:         actiance.EndTime,
+				CorporateEmailId: post.UserEmail,
+			})
+		}
+
+		actiance.Messages = append(actiance.Messages, &actianceMessage{
+			LoginName: post.UserUsername,
+			UserType:  ACTIANCE_USER_TYPE,
+			DateTime:  post.PostCreateAt / 1000,
+			Content:   post.PostMessage,
+		})
+	}
+
+	return actiance
+}
+
+// DeliverExport uploads the export to the SFTP server of the compliance settings, if there's one,
+// from which Actiance Vantage imports it.
+func (me *ActianceExportFormatter) DeliverExport(job *model.Compliance, files map[string][]byte) *model.AppError {
+	settings := Config().ComplianceSettings
+	if len(*settings.SftpServer) == 0 {
+		return nil
+	}
+
+	return utils.UploadFileWithSftp(
+		*settings.SftpServer,
+		*settings.SftpUsername,
+		*settings.SftpPassword,
+		*settings.SftpHostKey,
+		path.Join(*settings.SftpDirectory, job.JobName()+".xml"),
+		files[ACTIANCE_EXPORT_FILE_NAME],
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type GlobalRelayExportFormatter struct {
+}
+
+func init() {
+	RegisterComplianceExportFormatter(model.COMPLIANCE_FORMAT_GLOBALRELAY, &GlobalRelayExportFormatter{})
+}
+
+// FormatExport writes the posts of each channel as an email, which is from the first user who
+// posted in the channel and to everyone who did.
+func (me *GlobalRelayExportFormatter) FormatExport(job *model.Compliance, posts []*model.CompliancePost) (map[string][]byte, *model.AppError) {
+	files := make(map[string][]byte)
+
+	for i, conversation := range groupCompliancePostsByChannel(posts) {
+		message, err := newGlobalRelayMessage(job, i, conversation)
+		if err != nil {
+			return nil, model.NewLocAppError("GlobalRelayExportFormatter.FormatExport", "app.compliance.global_relay.message.app_error", nil, err.Error())
+		}
+
+		files[conversation.TeamName+"-"+conversation.ChannelName+".eml"] = message
+	}
+
+	return files, nil
+}
+
+func newGlobalRelayMessage(job *model.Compliance, index int, conversation *complianceConversation) ([]byte, error) {
+	first := conversation.Posts[0]
+	last := conversation.Posts[len(conversation.Posts)-1]
+
+	var participants []string
+	seen := make(map[string]bool)
+	for _, post := range conversation.Posts {
+		if !seen[post.UserEmail] {
+			seen[post.UserEmail] = true
+			participants = append(participants, (&mail.Address{Name: post.UserUsername, Address: post.UserEmail}).String())
+		}
+	}
+
+	subject := fmt.Sprintf("Mattermost compliance export: %v (%v messages)", conversation.ChannelDisplayName, len(conversation.Posts))
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %v\r\n", participants[0])
+	fmt.Fprintf(&message, "To: %v\r\n", strings.Join(participants, ", "))
+	fmt.Fprintf(&message, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %v\r\n", complianceTime(last.PostCreateAt).Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%v.%v@mattermost>\r\n", job.Id, index)
+	fmt.Fprintf(&message, "X-Mattermost-TeamName: %v\r\n", conversation.TeamName)
+	fmt.Fprintf(&message, "X-Mattermost-ChannelName: %v\r\n", conversation.ChannelName)
+	fmt.Fprintf(&message, "X-Mattermost-ChannelDisplayName: %v\r\n", mime.QEncoding.Encode("utf-8", conversation.ChannelDisplayName))
+	fmt.Fprintf(&message, "X-Mattermost-StartTime: %v\r\n", complianceTime(first.PostCreateAt).Format(time.RFC3339))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	message.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&message)
+	for _, post := range conversation.Posts {
+		if _, err := fmt.Fprintf(body, "[%v] %v <%v>: %v\r\n", complianceTime(post.PostCreateAt).Format(time.RFC3339), post.UserUsername, post.UserEmail, post.PostMessage); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return message.Bytes(), nil
+}
+
+func complianceTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
+
+// DeliverExport emails each message of the export to the Global Relay address of the compliance
+// settings, if there's one, through the SMTP server of the email settings.
+func (me *GlobalRelayExportFormatter) DeliverExport(job *model.Compliance, files map[string][]byte) *model.AppError {
+	address := *Config().ComplianceSettings.GlobalRelayEmailAddress
+	if len(address) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := utils.SendRawMailUsingConfig(address, files[name], Config()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io/ioutil"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func testCompliancePosts() []*model.CompliancePost {
+	return []*model.CompliancePost{
+		{TeamName: "team", ChannelName: "town-square", ChannelDisplayName: "Town Square", UserUsername: "alice", UserEmail: "alice@example.com", PostId: model.NewId(), PostCreateAt: 1500000000000, PostMessage: "hello"},
+		{TeamName: "team", ChannelName: "off-topic", ChannelDisplayName: "Off-Topic", UserUsername: "bob", UserEmail: "bob@example.com", PostId: model.NewId(), PostCreateAt: 1500000001000, PostMessage: "unrelated"},
+		{TeamName: "team", ChannelName: "town-square", ChannelDisplayName: "Town Square", UserUsername: "bob", UserEmail: "bob@example.com", PostId: model.NewId(), PostCreateAt: 1500000002000, PostMessage: "<hi> & bye"},
+		{TeamName: "team", ChannelName: "town-square", ChannelDisplayName: "Town Square", UserUsername: "alice", UserEmail: "alice@example.com", PostId: model.NewId(), PostCreateAt: 1500000003000, PostMessage: "ünïcödé"},
+	}
+}
+
+func TestGetComplianceExportFormats(t *testing.T) {
+	formats := GetComplianceExportFormats()
+	if len(formats) != 3 || formats[0] != model.COMPLIANCE_FORMAT_CSV || formats[1] != model.COMPLIANCE_FORMAT_ACTIANCE || formats[2] != model.COMPLIANCE_FORMAT_GLOBALRELAY {
+		t.Fatal("should have returned the csv format followed by the registered ones", formats)
+	}
+
+	if GetComplianceExportFormatter(model.COMPLIANCE_FORMAT_CSV) != nil || GetComplianceExportFormatter("junk") != nil {
+		t.Fatal("shouldn't have a formatter")
+	}
+}
+
+func TestGroupCompliancePostsByChannel(t *testing.T) {
+	posts := testCompliancePosts()
+
+	conversations := groupCompliancePostsByChannel(posts)
+	if len(conversations) != 2 {
+		t.Fatal("should have grouped the posts by channel", conversations)
+	}
+
+	if conversations[0].ChannelName != "town-square" || len(conversations[0].Posts) != 3 || conversations[0].Posts[1] != posts[2] {
+		t.Fatal("should have kept the posts of the first channel in order", conversations[0])
+	}
+
+	if conversations[1].ChannelName != "off-topic" || len(conversations[1].Posts) != 1 {
+		t.Fatal("should have grouped the posts of the second channel", conversations[1])
+	}
+}
+
+func TestActianceExportFormatter(t *testing.T) {
+	job := &model.Compliance{Id: model.NewId(), Format: model.COMPLIANCE_FORMAT_ACTIANCE}
+
+	files, err := GetComplianceExportFormatter(model.COMPLIANCE_FORMAT_ACTIANCE).FormatExport(job, testCompliancePosts())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Fatal("should have written a single file", files)
+	}
+
+	export := &actianceExport{}
+	if err := xml.Unmarshal(files[ACTIANCE_EXPORT_FILE_NAME], export); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(export.Conversations) != 2 {
+		t.Fatal("should have written a conversation per channel", export.Conversations)
+	}
+
+	conversation := export.Conversations[0]
+	if conversation.Perspective != "Town Square" || conversation.RoomId != "team/town-square" || conversation.StartTime != 1500000000 || conversation.EndTime != 1500000003 {
+		t.Fatal("should have written the channel", conversation)
+	}
+
+	if len(conversation.ParticipantsEntered) != 2 || conversation.ParticipantsEntered[1].CorporateEmailId != "bob@example.com" || conversation.ParticipantsEntered[1].DateTime != 1500000002 {
+		t.Fatal("should have written when the participants entered", conversation.ParticipantsEntered)
+	}
+
+	if len(conversation.ParticipantsLeft) != 2 || conversation.ParticipantsLeft[0].DateTime != conversation.EndTime {
+		t.Fatal("should have written when the participants left", conversation.ParticipantsLeft)
+	}
+
+	if len(conversation.Messages) != 3 || conversation.Messages[1].Content != "<hi> & bye" || conversation.Messages[2].Content != "ünïcödé" {
+		t.Fatal("should have written the messages", conversation.Messages)
+	}
+}
+
+func TestGlobalRelayExportFormatter(t *testing.T) {
+	job := &model.Compliance{Id: model.NewId(), Format: model.COMPLIANCE_FORMAT_GLOBALRELAY}
+
+	files, err := GetComplianceExportFormatter(model.COMPLIANCE_FORMAT_GLOBALRELAY).FormatExport(job, testCompliancePosts())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 2 {
+		t.Fatal("should have written an email per channel", files)
+	}
+
+	message, readErr := mail.ReadMessage(strings.NewReader(string(files["team-town-square.eml"])))
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if from, _ := message.Header.AddressList("From"); len(from) != 1 || from[0].Address != "alice@example.com" {
+		t.Fatal("should have been from the first participant", from)
+	}
+
+	if to, _ := message.Header.AddressList("To"); len(to) != 2 || to[1].Address != "bob@example.com" {
+		t.Fatal("should have been to every participant", to)
+	}
+
+	if message.Header.Get("X-Mattermost-ChannelName") != "town-square" || !strings.Contains(message.Header.Get("Message-ID"), job.Id) {
+		t.Fatal("should have written the headers", message.Header)
+	}
+
+	if date, _ := message.Header.Date(); date.Unix() != 1500000003 {
+		t.Fatal("should have been dated with the last post", date)
+	}
+
+	body, readErr := ioutil.ReadAll(quotedprintable.NewReader(message.Body))
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\r\n")
+	if len(lines) != 3 || lines[0] != "[2017-07-14T02:40:00Z] alice <alice@example.com>: hello" || !strings.HasSuffix(lines[2], ": ünïcödé") {
+		t.Fatal("should have written the messages", lines)
+	}
+}
+
+func TestWriteComplianceArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compliance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "compliance", "export.zip")
+	if err := writeComplianceArchive(path, map[string][]byte{"b.eml": []byte("b"), "a.eml": []byte("a")}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if len(archive.File) != 2 || archive.File[0].Name != "a.eml" || archive.File[1].Name != "b.eml" {
+		t.Fatal("should have written the files in order", archive.File)
+	}
+}
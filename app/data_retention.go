@@ -0,0 +1,408 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	DATA_RETENTION_TASK_NAME     = "Data Retention"
+	DATA_RETENTION_TASK_INTERVAL = 15 * time.Minute
+)
+
+// InitDataRetention starts the job that checks every 15 minutes whether the data retention job is due,
+// so that it runs once a day after the start time in the config.
+func InitDataRetention() {
+	if task := model.GetTaskByName(DATA_RETENTION_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(DATA_RETENTION_TASK_NAME, runDataRetentionIfDue, DATA_RETENTION_TASK_INTERVAL)
+}
+
+func runDataRetentionIfDue() {
+	settings := Config().DataRetentionSettings
+	if !*settings.EnableMessageDeletion && !*settings.EnableFileDeletion {
+		return
+	}
+
+	startTime := getDataRetentionStartTime(time.Now(), *settings.DeletionJobStartTime)
+	if time.Now().Before(startTime) {
+		return
+	}
+
+	var lastRun int64
+	if result := <-Srv.Store.System().Get(); result.Err != nil {
+		l4g.Error(utils.T("app.data_retention.run.error"), result.Err.Error())
+		return
+	} else {
+		lastRun, _ = strconv.ParseInt(result.Data.(model.StringMap)[model.SYSTEM_LAST_DATA_RETENTION_RUN], 10, 64)
+	}
+
+	if lastRun >= startTime.UnixNano()/int64(time.Millisecond) {
+		return
+	}
+
+	// The run is saved first so that a run that fails part of the way through isn't retried every 15 minutes
+	if result := <-Srv.Store.System().SaveOrUpdate(&model.System{Name: model.SYSTEM_LAST_DATA_RETENTION_RUN, Value: strconv.FormatInt(model.GetMillis(), 10)}); result.Err != nil {
+		l4g.Error(utils.T("app.data_retention.run.error"), result.Err.Error())
+		return
+	}
+
+	RunDataRetention()
+}
+
+// getDataRetentionStartTime returns the time on the day of now that the data retention job should
+// start at, in the server's time zone.
+func getDataRetentionStartTime(now time.Time, startTime string) time.Time {
+	start, err := time.Parse(model.DATA_RETENTION_START_TIME_LAYOUT, startTime)
+	if err != nil {
+		start, _ = time.Parse(model.DATA_RETENTION_START_TIME_LAYOUT, model.DATA_RETENTION_DEFAULT_START)
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, now.Location())
+}
+
+// RunDataRetention deletes the messages and the files that are older than the global data retention
+// settings or than the policy of their team or channel allows. Messages and files are only deleted if
+// their deletion is enabled in the config.
+func RunDataRetention() {
+	settings := Config().DataRetentionSettings
+
+	policies, err := getAllRetentionPolicies()
+	if err != nil {
+		l4g.Error(utils.T("app.data_retention.run.error"), err.Error())
+		return
+	}
+
+	l4g.Info(utils.T("app.data_retention.run.start.info"))
+
+	now := model.GetMillis()
+	var deletedPosts, deletedFiles int64
+
+	if *settings.EnableMessageDeletion {
+		count, err := deleteExpiredPosts(nil, model.GetRetentionEndTime(now, *settings.MessageRetentionDays))
+		deletedPosts += count
+		if err != nil {
+			l4g.Error(utils.T("app.data_retention.run.error"), err.Error())
+		}
+
+		for _, policy := range policies {
+			count, err := deleteExpiredPosts(policy, model.GetRetentionEndTime(now, policy.MessageRetentionDays))
+			deletedPosts += count
+			if err != nil {
+				l4g.Error(utils.T("app.data_retention.run.policy.error"), policy.Id, err.Error())
+			}
+		}
+	}
+
+	if *settings.EnableFileDeletion {
+		count, err := deleteExpiredFiles(nil, model.GetRetentionEndTime(now, *settings.FileRetentionDays))
+		deletedFiles += count
+		if err != nil {
+			l4g.Error(utils.T("app.data_retention.run.error"), err.Error())
+		}
+
+		for _, policy := range policies {
+			count, err := deleteExpiredFiles(policy, model.GetRetentionEndTime(now, policy.FileRetentionDays))
+			deletedFiles += count
+			if err != nil {
+				l4g.Error(utils.T("app.data_retention.run.policy.error"), policy.Id, err.Error())
+			}
+		}
+	}
+
+	l4g.Info(utils.T("app.data_retention.run.finish.info"), deletedPosts, deletedFiles)
+}
+
+// deleteExpiredPosts deletes the posts created before endTime that a policy applies to, along with
+// their files, one batch at a time. It returns the number of posts that were deleted.
+func deleteExpiredPosts(policy *model.RetentionPolicy, endTime int64) (int64, *model.AppError) {
+	if endTime == 0 {
+		return 0, nil
+	}
+
+	batchSize := *Config().DataRetentionSettings.BatchSize
+	var deleted int64
+
+	for {
+		var postIds []string
+		if result := <-Srv.Store.RetentionPolicy().GetExpiredPostIds(policy, endTime, batchSize); result.Err != nil {
+			return deleted, result.Err
+		} else {
+			postIds = result.Data.([]string)
+		}
+
+		if len(postIds) == 0 {
+			return deleted, nil
+		}
+
+		var infos []*model.FileInfo
+		if result := <-Srv.Store.FileInfo().GetForPosts(postIds); result.Err != nil {
+			return deleted, result.Err
+		} else {
+			infos = result.Data.([]*model.FileInfo)
+		}
+
+		if err := permanentDeleteFiles(infos); err != nil {
+			return deleted, err
+		}
+
+		if result := <-Srv.Store.Post().PermanentDeleteBatch(postIds); result.Err != nil {
+			return deleted, result.Err
+		} else {
+			deleted += result.Data.(int64)
+		}
+
+		for _, postId := range postIds {
+			deletePostFromSearch(postId)
+		}
+
+		if len(postIds) < batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deleteExpiredFiles deletes the files created before endTime that are attached to posts that a
+// policy applies to, one batch at a time. It returns the number of files that were deleted.
+func deleteExpiredFiles(policy *model.RetentionPolicy, endTime int64) (int64, *model.AppError) {
+	if endTime == 0 {
+		return 0, nil
+	}
+
+	batchSize := *Config().DataRetentionSettings.BatchSize
+	var deleted int64
+
+	for {
+		var infos []*model.FileInfo
+		if result := <-Srv.Store.RetentionPolicy().GetExpiredFileInfos(policy, endTime, batchSize); result.Err != nil {
+			return deleted, result.Err
+		} else {
+			infos = result.Data.([]*model.FileInfo)
+		}
+
+		if len(infos) == 0 {
+			return deleted, nil
+		}
+
+		if err := permanentDeleteFiles(infos); err != nil {
+			return deleted, err
+		}
+
+		deleted += int64(len(infos))
+
+		if len(infos) < batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// permanentDeleteFiles removes files, along with their thumbnails and previews, from the file store
+// and then deletes their records. A file that can't be removed from the file store is logged and its
+// record is deleted anyway so that the job doesn't get stuck on it.
+func permanentDeleteFiles(infos []*model.FileInfo) *model.AppError {
+	if len(infos) == 0 {
+		return nil
+	}
+
+	fileIds := make([]string, 0, len(infos))
+	postIds := map[string]bool{}
+
+	for _, info := range infos {
+		for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+			if len(path) == 0 {
+				continue
+			}
+
+			if err := RemoveFile(path); err != nil {
+				l4g.Warn(utils.T("app.data_retention.remove_file.warn"), info.Id, err.Error())
+			}
+		}
+
+		fileIds = append(fileIds, info.Id)
+		postIds[info.PostId] = true
+	}
+
+	if result := <-Srv.Store.FileInfo().PermanentDeleteBatch(fileIds); result.Err != nil {
+		return result.Err
+	}
+
+	for postId := range postIds {
+		Srv.Store.FileInfo().InvalidateFileInfosForPostCache(postId)
+	}
+
+	if engine := einterfaces.GetSearchEngineInterface(); engine != nil && engine.IsIndexingEnabled() {
+		for _, fileId := range fileIds {
+			if err := engine.DeleteFile(fileId); err != nil {
+				l4g.Error(utils.T("app.search_engine.delete_file.error"), fileId, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func getAllRetentionPolicies() ([]*model.RetentionPolicy, *model.AppError) {
+	var count int64
+	if result := <-Srv.Store.RetentionPolicy().GetCount(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		count = result.Data.(int64)
+	}
+
+	if result := <-Srv.Store.RetentionPolicy().GetAll(0, int(count)); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.RetentionPolicy), nil
+	}
+}
+
+func CreateRetentionPolicy(policy *model.RetentionPolicy) (*model.RetentionPolicy, *model.AppError) {
+	if err := checkRetentionPolicyTarget(policy); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.RetentionPolicy().Save(policy); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.RetentionPolicy), nil
+	}
+}
+
+func GetRetentionPolicy(policyId string) (*model.RetentionPolicy, *model.AppError) {
+	if result := <-Srv.Store.RetentionPolicy().Get(policyId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.RetentionPolicy), nil
+	}
+}
+
+func GetRetentionPolicies(page int, perPage int) ([]*model.RetentionPolicy, *model.AppError) {
+	if result := <-Srv.Store.RetentionPolicy().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.RetentionPolicy), nil
+	}
+}
+
+func GetRetentionPoliciesCount() (int64, *model.AppError) {
+	if result := <-Srv.Store.RetentionPolicy().GetCount(); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+// UpdateRetentionPolicy changes the number of days that a policy keeps messages and files for. The
+// team or channel of a policy can't be changed.
+func UpdateRetentionPolicy(policy *model.RetentionPolicy) (*model.RetentionPolicy, *model.AppError) {
+	oldPolicy, err := GetRetentionPolicy(policy.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPolicy.MessageRetentionDays = policy.MessageRetentionDays
+	oldPolicy.FileRetentionDays = policy.FileRetentionDays
+
+	if result := <-Srv.Store.RetentionPolicy().Update(oldPolicy); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.RetentionPolicy), nil
+	}
+}
+
+func DeleteRetentionPolicy(policyId string) *model.AppError {
+	if result := <-Srv.Store.RetentionPolicy().Delete(policyId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func checkRetentionPolicyTarget(policy *model.RetentionPolicy) *model.AppError {
+	if len(policy.ChannelId) > 0 {
+		if len(policy.TeamId) > 0 {
+			return model.NewAppError("CreateRetentionPolicy", "model.retention_policy.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		channel, err := GetChannel(policy.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		if channel.IsGroupOrDirect() {
+			return model.NewAppError("CreateRetentionPolicy", "app.data_retention.direct_channel.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+		}
+	} else if len(policy.TeamId) > 0 {
+		if _, err := GetTeam(policy.TeamId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRetentionPreview returns the number of messages and files that the next run of the data
+// retention job would delete, for the global settings followed by each policy. The counts for
+// messages or files are 0 if their deletion isn't enabled.
+func GetRetentionPreview() ([]*model.RetentionPreview, *model.AppError) {
+	settings := Config().DataRetentionSettings
+
+	policies, err := getAllRetentionPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	global := &model.RetentionPreview{
+		MessageRetentionDays: *settings.MessageRetentionDays,
+		FileRetentionDays:    *settings.FileRetentionDays,
+	}
+
+	previews := []*model.RetentionPreview{global}
+	for _, policy := range policies {
+		previews = append(previews, &model.RetentionPreview{
+			PolicyId:             policy.Id,
+			TeamId:               policy.TeamId,
+			ChannelId:            policy.ChannelId,
+			MessageRetentionDays: policy.MessageRetentionDays,
+			FileRetentionDays:    policy.FileRetentionDays,
+		})
+	}
+
+	now := model.GetMillis()
+
+	for i, preview := range previews {
+		var policy *model.RetentionPolicy
+		if i > 0 {
+			policy = policies[i-1]
+		}
+
+		if endTime := model.GetRetentionEndTime(now, preview.MessageRetentionDays); *settings.EnableMessageDeletion && endTime > 0 {
+			if result := <-Srv.Store.RetentionPolicy().GetExpiredPostCount(policy, endTime); result.Err != nil {
+				return nil, result.Err
+			} else {
+				preview.MessageCount = result.Data.(int64)
+			}
+		}
+
+		if endTime := model.GetRetentionEndTime(now, preview.FileRetentionDays); *settings.EnableFileDeletion && endTime > 0 {
+			if result := <-Srv.Store.RetentionPolicy().GetExpiredFileCount(policy, endTime); result.Err != nil {
+				return nil, result.Err
+			} else {
+				preview.FileCount = result.Data.(int64)
+			}
+		}
+	}
+
+	return previews, nil
+}
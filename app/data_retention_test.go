@@ -0,0 +1,21 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetDataRetentionStartTime(t *testing.T) {
+	now := time.Date(2017, time.July, 14, 15, 30, 0, 0, time.UTC)
+
+	if start := getDataRetentionStartTime(now, "02:15"); !start.Equal(time.Date(2017, time.July, 14, 2, 15, 0, 0, time.UTC)) {
+		t.Fatalf("bad start time %v", start)
+	}
+
+	if start := getDataRetentionStartTime(now, "junk"); !start.Equal(time.Date(2017, time.July, 14, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("should use the default start time for a bad one, got %v", start)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+// RecordDevice saves the app that made the request as the device for the session, replacing what
+// was previously known about it.
+func RecordDevice(r *http.Request, session *model.Session) {
+	clientVersionHeader := r.Header.Get(model.HEADER_CLIENT_VERSION)
+
+	appVersion := ""
+	if clientVersion := model.ParseClientVersion(clientVersionHeader); clientVersion != nil {
+		appVersion = clientVersion.Version
+	}
+
+	device := &model.Device{
+		UserId:     session.UserId,
+		SessionId:  session.Id,
+		Platform:   model.GetDevicePlatform(session.DeviceId, clientVersionHeader, r.UserAgent()),
+		AppVersion: appVersion,
+		PushToken:  session.DeviceId,
+		UserAgent:  r.UserAgent(),
+	}
+
+	var result store.StoreResult
+	if existing := <-Srv.Store.Device().GetBySessionId(session.Id); existing.Err == nil {
+		device.Id = existing.Data.(*model.Device).Id
+		device.CreateAt = existing.Data.(*model.Device).CreateAt
+		result = <-Srv.Store.Device().Update(device)
+	} else {
+		result = <-Srv.Store.Device().Save(device)
+	}
+
+	if result.Err != nil {
+		l4g.Error(utils.T("app.device.record.error"), session.Id, result.Err.Error())
+	}
+}
+
+// updateDevicePushToken changes the push token of the session's device, if it has one, to match the
+// device id attached to the session.
+func updateDevicePushToken(sessionId string, pushToken string) {
+	result := <-Srv.Store.Device().GetBySessionId(sessionId)
+	if result.Err != nil {
+		return
+	}
+
+	device := result.Data.(*model.Device)
+	device.PushToken = pushToken
+
+	// Only the push token says for certain whether a mobile device is on iOS or Android
+	if platform := model.GetDevicePlatform(pushToken, "", ""); platform != model.DEVICE_PLATFORM_WEB {
+		device.Platform = platform
+	}
+
+	if result := <-Srv.Store.Device().Update(device); result.Err != nil {
+		l4g.Error(utils.T("app.device.record.error"), sessionId, result.Err.Error())
+	}
+}
+
+func GetDevicesForUser(userId string) ([]*model.Device, *model.AppError) {
+	if result := <-Srv.Store.Device().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		devices := result.Data.([]*model.Device)
+		for _, device := range devices {
+			device.Sanitize()
+		}
+
+		return devices, nil
+	}
+}
+
+// RevokeDevice signs the user out of the device, which also stops it from receiving push notifications.
+func RevokeDevice(userId string, deviceId string) *model.AppError {
+	var device *model.Device
+	if result := <-Srv.Store.Device().Get(deviceId); result.Err != nil {
+		return result.Err
+	} else {
+		device = result.Data.(*model.Device)
+	}
+
+	if device.UserId != userId {
+		return model.NewAppError("RevokeDevice", "app.device.revoke.user_id.app_error", nil, "device_id="+deviceId, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.Session().Get(device.SessionId); result.Err == nil {
+		if err := RevokeSession(result.Data.(*model.Session)); err != nil {
+			return err
+		}
+	}
+
+	if result := <-Srv.Store.Device().DeleteBySessionId(device.SessionId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetDeviceVersionCounts(since int64) ([]*model.DeviceVersionCount, *model.AppError) {
+	if result := <-Srv.Store.Device().GetVersionCounts(since); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.DeviceVersionCount), nil
+	}
+}
@@ -36,6 +36,7 @@ const (
 	TRACK_CONFIG_SUPPORT      = "config_support"
 	TRACK_CONFIG_NATIVEAPP    = "config_nativeapp"
 	TRACK_CONFIG_ANALYTICS    = "config_analytics"
+	TRACK_CONFIG_INCIDENT     = "config_incident"
 
 	TRACK_ACTIVITY = "activity"
 	TRACK_LICENSE  = "license"
@@ -45,7 +46,7 @@ const (
 var client *analytics.Client
 
 func SendDailyDiagnostics() {
-	if *utils.Cfg.LogSettings.EnableDiagnostics {
+	if *Config().LogSettings.EnableDiagnostics {
 		initDiagnostics("")
 		trackActivity()
 		trackConfig()
@@ -153,217 +154,228 @@ func trackActivity() {
 
 func trackConfig() {
 	SendDiagnostic(TRACK_CONFIG_SERVICE, map[string]interface{}{
-		"web_server_mode":                               *utils.Cfg.ServiceSettings.WebserverMode,
-		"enable_security_fix_alert":                     *utils.Cfg.ServiceSettings.EnableSecurityFixAlert,
-		"enable_insecure_outgoing_connections":          *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections,
-		"enable_incoming_webhooks":                      utils.Cfg.ServiceSettings.EnableIncomingWebhooks,
-		"enable_outgoing_webhooks":                      utils.Cfg.ServiceSettings.EnableOutgoingWebhooks,
-		"enable_commands":                               *utils.Cfg.ServiceSettings.EnableCommands,
-		"enable_only_admin_integrations":                *utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations,
-		"enable_post_username_override":                 utils.Cfg.ServiceSettings.EnablePostUsernameOverride,
-		"enable_post_icon_override":                     utils.Cfg.ServiceSettings.EnablePostIconOverride,
-		"enable_custom_emoji":                           *utils.Cfg.ServiceSettings.EnableCustomEmoji,
-		"restrict_custom_emoji_creation":                *utils.Cfg.ServiceSettings.RestrictCustomEmojiCreation,
-		"enable_testing":                                utils.Cfg.ServiceSettings.EnableTesting,
-		"enable_developer":                              *utils.Cfg.ServiceSettings.EnableDeveloper,
-		"enable_multifactor_authentication":             *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication,
-		"enforce_multifactor_authentication":            *utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication,
-		"enable_oauth_service_provider":                 utils.Cfg.ServiceSettings.EnableOAuthServiceProvider,
-		"connection_security":                           *utils.Cfg.ServiceSettings.ConnectionSecurity,
-		"uses_letsencrypt":                              *utils.Cfg.ServiceSettings.UseLetsEncrypt,
-		"forward_80_to_443":                             *utils.Cfg.ServiceSettings.Forward80To443,
-		"maximum_login_attempts":                        utils.Cfg.ServiceSettings.MaximumLoginAttempts,
-		"session_length_web_in_days":                    *utils.Cfg.ServiceSettings.SessionLengthWebInDays,
-		"session_length_mobile_in_days":                 *utils.Cfg.ServiceSettings.SessionLengthMobileInDays,
-		"session_length_sso_in_days":                    *utils.Cfg.ServiceSettings.SessionLengthSSOInDays,
-		"session_cache_in_minutes":                      *utils.Cfg.ServiceSettings.SessionCacheInMinutes,
-		"isdefault_site_url":                            isDefault(*utils.Cfg.ServiceSettings.SiteURL, model.SERVICE_SETTINGS_DEFAULT_SITE_URL),
-		"isdefault_tls_cert_file":                       isDefault(*utils.Cfg.ServiceSettings.TLSCertFile, model.SERVICE_SETTINGS_DEFAULT_TLS_CERT_FILE),
-		"isdefault_tls_key_file":                        isDefault(*utils.Cfg.ServiceSettings.TLSKeyFile, model.SERVICE_SETTINGS_DEFAULT_TLS_KEY_FILE),
-		"isdefault_read_timeout":                        isDefault(*utils.Cfg.ServiceSettings.ReadTimeout, model.SERVICE_SETTINGS_DEFAULT_READ_TIMEOUT),
-		"isdefault_write_timeout":                       isDefault(*utils.Cfg.ServiceSettings.WriteTimeout, model.SERVICE_SETTINGS_DEFAULT_WRITE_TIMEOUT),
-		"isdefault_google_developer_key":                isDefault(utils.Cfg.ServiceSettings.GoogleDeveloperKey, ""),
-		"isdefault_allow_cors_from":                     isDefault(*utils.Cfg.ServiceSettings.AllowCorsFrom, model.SERVICE_SETTINGS_DEFAULT_ALLOW_CORS_FROM),
-		"restrict_post_delete":                          *utils.Cfg.ServiceSettings.RestrictPostDelete,
-		"allow_edit_post":                               *utils.Cfg.ServiceSettings.AllowEditPost,
-		"post_edit_time_limit":                          *utils.Cfg.ServiceSettings.PostEditTimeLimit,
-		"enable_user_typing_messages":                   *utils.Cfg.ServiceSettings.EnableUserTypingMessages,
-		"time_between_user_typing_updates_milliseconds": *utils.Cfg.ServiceSettings.TimeBetweenUserTypingUpdatesMilliseconds,
-		"cluster_log_timeout_milliseconds":              *utils.Cfg.ServiceSettings.ClusterLogTimeoutMilliseconds,
+		"web_server_mode":                               *Config().ServiceSettings.WebserverMode,
+		"enable_security_fix_alert":                     *Config().ServiceSettings.EnableSecurityFixAlert,
+		"enable_insecure_outgoing_connections":          *Config().ServiceSettings.EnableInsecureOutgoingConnections,
+		"enable_incoming_webhooks":                      Config().ServiceSettings.EnableIncomingWebhooks,
+		"enable_outgoing_webhooks":                      Config().ServiceSettings.EnableOutgoingWebhooks,
+		"enable_commands":                               *Config().ServiceSettings.EnableCommands,
+		"enable_only_admin_integrations":                *Config().ServiceSettings.EnableOnlyAdminIntegrations,
+		"enable_post_username_override":                 Config().ServiceSettings.EnablePostUsernameOverride,
+		"enable_post_icon_override":                     Config().ServiceSettings.EnablePostIconOverride,
+		"enable_custom_emoji":                           *Config().ServiceSettings.EnableCustomEmoji,
+		"restrict_custom_emoji_creation":                *Config().ServiceSettings.RestrictCustomEmojiCreation,
+		"enable_testing":                                Config().ServiceSettings.EnableTesting,
+		"enable_developer":                              *Config().ServiceSettings.EnableDeveloper,
+		"enable_multifactor_authentication":             *Config().ServiceSettings.EnableMultifactorAuthentication,
+		"enforce_multifactor_authentication":            *Config().ServiceSettings.EnforceMultifactorAuthentication,
+		"enable_oauth_service_provider":                 Config().ServiceSettings.EnableOAuthServiceProvider,
+		"connection_security":                           *Config().ServiceSettings.ConnectionSecurity,
+		"uses_letsencrypt":                              *Config().ServiceSettings.UseLetsEncrypt,
+		"forward_80_to_443":                             *Config().ServiceSettings.Forward80To443,
+		"maximum_login_attempts":                        Config().ServiceSettings.MaximumLoginAttempts,
+		"session_length_web_in_days":                    *Config().ServiceSettings.SessionLengthWebInDays,
+		"session_length_mobile_in_days":                 *Config().ServiceSettings.SessionLengthMobileInDays,
+		"session_length_sso_in_days":                    *Config().ServiceSettings.SessionLengthSSOInDays,
+		"session_cache_in_minutes":                      *Config().ServiceSettings.SessionCacheInMinutes,
+		"isdefault_site_url":                            isDefault(*Config().ServiceSettings.SiteURL, model.SERVICE_SETTINGS_DEFAULT_SITE_URL),
+		"isdefault_tls_cert_file":                       isDefault(*Config().ServiceSettings.TLSCertFile, model.SERVICE_SETTINGS_DEFAULT_TLS_CERT_FILE),
+		"isdefault_tls_key_file":                        isDefault(*Config().ServiceSettings.TLSKeyFile, model.SERVICE_SETTINGS_DEFAULT_TLS_KEY_FILE),
+		"isdefault_read_timeout":                        isDefault(*Config().ServiceSettings.ReadTimeout, model.SERVICE_SETTINGS_DEFAULT_READ_TIMEOUT),
+		"isdefault_write_timeout":                       isDefault(*Config().ServiceSettings.WriteTimeout, model.SERVICE_SETTINGS_DEFAULT_WRITE_TIMEOUT),
+		"isdefault_google_developer_key":                isDefault(Config().ServiceSettings.GoogleDeveloperKey, ""),
+		"isdefault_allow_cors_from":                     isDefault(*Config().ServiceSettings.AllowCorsFrom, model.SERVICE_SETTINGS_DEFAULT_ALLOW_CORS_FROM),
+		"restrict_post_delete":                          *Config().ServiceSettings.RestrictPostDelete,
+		"allow_edit_post":                               *Config().ServiceSettings.AllowEditPost,
+		"post_edit_time_limit":                          *Config().ServiceSettings.PostEditTimeLimit,
+		"enable_user_typing_messages":                   *Config().ServiceSettings.EnableUserTypingMessages,
+		"time_between_user_typing_updates_milliseconds": *Config().ServiceSettings.TimeBetweenUserTypingUpdatesMilliseconds,
+		"cluster_log_timeout_milliseconds":              *Config().ServiceSettings.ClusterLogTimeoutMilliseconds,
+		"enable_graphql":                                *Config().ServiceSettings.EnableGraphQL,
+		"enable_grpc_server":                            *Config().ServiceSettings.EnableGrpcServer,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_TEAM, map[string]interface{}{
-		"enable_user_creation":                utils.Cfg.TeamSettings.EnableUserCreation,
-		"enable_team_creation":                utils.Cfg.TeamSettings.EnableTeamCreation,
-		"restrict_team_invite":                *utils.Cfg.TeamSettings.RestrictTeamInvite,
-		"restrict_public_channel_creation":    *utils.Cfg.TeamSettings.RestrictPublicChannelCreation,
-		"restrict_private_channel_creation":   *utils.Cfg.TeamSettings.RestrictPrivateChannelCreation,
-		"restrict_public_channel_management":  *utils.Cfg.TeamSettings.RestrictPublicChannelManagement,
-		"restrict_private_channel_management": *utils.Cfg.TeamSettings.RestrictPrivateChannelManagement,
-		"restrict_public_channel_deletion":    *utils.Cfg.TeamSettings.RestrictPublicChannelDeletion,
-		"restrict_private_channel_deletion":   *utils.Cfg.TeamSettings.RestrictPrivateChannelDeletion,
-		"enable_open_server":                  *utils.Cfg.TeamSettings.EnableOpenServer,
-		"enable_custom_brand":                 *utils.Cfg.TeamSettings.EnableCustomBrand,
-		"restrict_direct_message":             *utils.Cfg.TeamSettings.RestrictDirectMessage,
-		"max_notifications_per_channel":       *utils.Cfg.TeamSettings.MaxNotificationsPerChannel,
-		"max_users_per_team":                  utils.Cfg.TeamSettings.MaxUsersPerTeam,
-		"max_channels_per_team":               *utils.Cfg.TeamSettings.MaxChannelsPerTeam,
-		"isdefault_site_name":                 isDefault(utils.Cfg.TeamSettings.SiteName, "Mattermost"),
-		"isdefault_custom_brand_text":         isDefault(*utils.Cfg.TeamSettings.CustomBrandText, model.TEAM_SETTINGS_DEFAULT_CUSTOM_BRAND_TEXT),
-		"isdefault_custom_description_text":   isDefault(*utils.Cfg.TeamSettings.CustomDescriptionText, model.TEAM_SETTINGS_DEFAULT_CUSTOM_DESCRIPTION_TEXT),
-		"isdefault_user_status_away_timeout":  isDefault(*utils.Cfg.TeamSettings.UserStatusAwayTimeout, model.TEAM_SETTINGS_DEFAULT_USER_STATUS_AWAY_TIMEOUT),
+		"enable_user_creation":                Config().TeamSettings.EnableUserCreation,
+		"enable_team_creation":                Config().TeamSettings.EnableTeamCreation,
+		"restrict_team_invite":                *Config().TeamSettings.RestrictTeamInvite,
+		"restrict_public_channel_creation":    *Config().TeamSettings.RestrictPublicChannelCreation,
+		"restrict_private_channel_creation":   *Config().TeamSettings.RestrictPrivateChannelCreation,
+		"restrict_public_channel_management":  *Config().TeamSettings.RestrictPublicChannelManagement,
+		"restrict_private_channel_management": *Config().TeamSettings.RestrictPrivateChannelManagement,
+		"restrict_public_channel_deletion":    *Config().TeamSettings.RestrictPublicChannelDeletion,
+		"restrict_private_channel_deletion":   *Config().TeamSettings.RestrictPrivateChannelDeletion,
+		"enable_open_server":                  *Config().TeamSettings.EnableOpenServer,
+		"enable_custom_brand":                 *Config().TeamSettings.EnableCustomBrand,
+		"restrict_direct_message":             *Config().TeamSettings.RestrictDirectMessage,
+		"max_notifications_per_channel":       *Config().TeamSettings.MaxNotificationsPerChannel,
+		"large_channel_threshold":             *Config().TeamSettings.LargeChannelThreshold,
+		"max_users_per_team":                  Config().TeamSettings.MaxUsersPerTeam,
+		"max_channels_per_team":               *Config().TeamSettings.MaxChannelsPerTeam,
+		"isdefault_site_name":                 isDefault(Config().TeamSettings.SiteName, "Mattermost"),
+		"isdefault_custom_brand_text":         isDefault(*Config().TeamSettings.CustomBrandText, model.TEAM_SETTINGS_DEFAULT_CUSTOM_BRAND_TEXT),
+		"isdefault_custom_description_text":   isDefault(*Config().TeamSettings.CustomDescriptionText, model.TEAM_SETTINGS_DEFAULT_CUSTOM_DESCRIPTION_TEXT),
+		"isdefault_user_status_away_timeout":  isDefault(*Config().TeamSettings.UserStatusAwayTimeout, model.TEAM_SETTINGS_DEFAULT_USER_STATUS_AWAY_TIMEOUT),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_SQL, map[string]interface{}{
-		"driver_name":          utils.Cfg.SqlSettings.DriverName,
-		"trace":                utils.Cfg.SqlSettings.Trace,
-		"max_idle_conns":       utils.Cfg.SqlSettings.MaxIdleConns,
-		"max_open_conns":       utils.Cfg.SqlSettings.MaxOpenConns,
-		"data_source_replicas": len(utils.Cfg.SqlSettings.DataSourceReplicas),
+		"driver_name":          Config().SqlSettings.DriverName,
+		"trace":                Config().SqlSettings.Trace,
+		"max_idle_conns":       Config().SqlSettings.MaxIdleConns,
+		"max_open_conns":       Config().SqlSettings.MaxOpenConns,
+		"data_source_replicas": len(Config().SqlSettings.DataSourceReplicas),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_LOG, map[string]interface{}{
-		"enable_console":           utils.Cfg.LogSettings.EnableConsole,
-		"console_level":            utils.Cfg.LogSettings.ConsoleLevel,
-		"enable_file":              utils.Cfg.LogSettings.EnableFile,
-		"file_level":               utils.Cfg.LogSettings.FileLevel,
-		"enable_webhook_debugging": utils.Cfg.LogSettings.EnableWebhookDebugging,
-		"isdefault_file_format":    isDefault(utils.Cfg.LogSettings.FileFormat, ""),
-		"isdefault_file_location":  isDefault(utils.Cfg.LogSettings.FileLocation, ""),
+		"enable_console":           Config().LogSettings.EnableConsole,
+		"console_level":            Config().LogSettings.ConsoleLevel,
+		"enable_file":              Config().LogSettings.EnableFile,
+		"file_level":               Config().LogSettings.FileLevel,
+		"enable_webhook_debugging": Config().LogSettings.EnableWebhookDebugging,
+		"isdefault_file_format":    isDefault(Config().LogSettings.FileFormat, ""),
+		"isdefault_file_location":  isDefault(Config().LogSettings.FileLocation, ""),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_PASSWORD, map[string]interface{}{
-		"minimum_length": *utils.Cfg.PasswordSettings.MinimumLength,
-		"lowercase":      *utils.Cfg.PasswordSettings.Lowercase,
-		"number":         *utils.Cfg.PasswordSettings.Number,
-		"uppercase":      *utils.Cfg.PasswordSettings.Uppercase,
-		"symbol":         *utils.Cfg.PasswordSettings.Symbol,
+		"minimum_length": *Config().PasswordSettings.MinimumLength,
+		"lowercase":      *Config().PasswordSettings.Lowercase,
+		"number":         *Config().PasswordSettings.Number,
+		"uppercase":      *Config().PasswordSettings.Uppercase,
+		"symbol":         *Config().PasswordSettings.Symbol,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_FILE, map[string]interface{}{
-		"enable_public_links": utils.Cfg.FileSettings.EnablePublicLink,
-		"driver_name":         utils.Cfg.FileSettings.DriverName,
-		"amazon_s3_ssl":       *utils.Cfg.FileSettings.AmazonS3SSL,
-		"thumbnail_width":     utils.Cfg.FileSettings.ThumbnailWidth,
-		"thumbnail_height":    utils.Cfg.FileSettings.ThumbnailHeight,
-		"preview_width":       utils.Cfg.FileSettings.PreviewWidth,
-		"preview_height":      utils.Cfg.FileSettings.PreviewHeight,
-		"profile_width":       utils.Cfg.FileSettings.ProfileWidth,
-		"profile_height":      utils.Cfg.FileSettings.ProfileHeight,
-		"max_file_size":       *utils.Cfg.FileSettings.MaxFileSize,
+		"enable_public_links": Config().FileSettings.EnablePublicLink,
+		"driver_name":         Config().FileSettings.DriverName,
+		"amazon_s3_ssl":       *Config().FileSettings.AmazonS3SSL,
+		"thumbnail_width":     Config().FileSettings.ThumbnailWidth,
+		"thumbnail_height":    Config().FileSettings.ThumbnailHeight,
+		"preview_width":       Config().FileSettings.PreviewWidth,
+		"preview_height":      Config().FileSettings.PreviewHeight,
+		"profile_width":       Config().FileSettings.ProfileWidth,
+		"profile_height":      Config().FileSettings.ProfileHeight,
+		"max_file_size":       *Config().FileSettings.MaxFileSize,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_EMAIL, map[string]interface{}{
-		"enable_sign_up_with_email":       utils.Cfg.EmailSettings.EnableSignUpWithEmail,
-		"enable_sign_in_with_email":       *utils.Cfg.EmailSettings.EnableSignInWithEmail,
-		"enable_sign_in_with_username":    *utils.Cfg.EmailSettings.EnableSignInWithUsername,
-		"require_email_verification":      utils.Cfg.EmailSettings.RequireEmailVerification,
-		"send_email_notifications":        utils.Cfg.EmailSettings.SendEmailNotifications,
-		"connection_security":             utils.Cfg.EmailSettings.ConnectionSecurity,
-		"send_push_notifications":         *utils.Cfg.EmailSettings.SendPushNotifications,
-		"push_notification_contents":      *utils.Cfg.EmailSettings.PushNotificationContents,
-		"enable_email_batching":           *utils.Cfg.EmailSettings.EnableEmailBatching,
-		"email_batching_buffer_size":      *utils.Cfg.EmailSettings.EmailBatchingBufferSize,
-		"email_batching_interval":         *utils.Cfg.EmailSettings.EmailBatchingInterval,
-		"isdefault_feedback_name":         isDefault(utils.Cfg.EmailSettings.FeedbackName, ""),
-		"isdefault_feedback_email":        isDefault(utils.Cfg.EmailSettings.FeedbackEmail, ""),
-		"isdefault_feedback_organization": isDefault(*utils.Cfg.EmailSettings.FeedbackOrganization, model.EMAIL_SETTINGS_DEFAULT_FEEDBACK_ORGANIZATION),
+		"enable_sign_up_with_email":       Config().EmailSettings.EnableSignUpWithEmail,
+		"enable_sign_in_with_email":       *Config().EmailSettings.EnableSignInWithEmail,
+		"enable_sign_in_with_username":    *Config().EmailSettings.EnableSignInWithUsername,
+		"require_email_verification":      Config().EmailSettings.RequireEmailVerification,
+		"send_email_notifications":        Config().EmailSettings.SendEmailNotifications,
+		"connection_security":             Config().EmailSettings.ConnectionSecurity,
+		"send_push_notifications":         *Config().EmailSettings.SendPushNotifications,
+		"push_notification_contents":      *Config().EmailSettings.PushNotificationContents,
+		"enable_email_batching":           *Config().EmailSettings.EnableEmailBatching,
+		"email_batching_buffer_size":      *Config().EmailSettings.EmailBatchingBufferSize,
+		"email_batching_interval":         *Config().EmailSettings.EmailBatchingInterval,
+		"isdefault_feedback_name":         isDefault(Config().EmailSettings.FeedbackName, ""),
+		"isdefault_feedback_email":        isDefault(Config().EmailSettings.FeedbackEmail, ""),
+		"isdefault_feedback_organization": isDefault(*Config().EmailSettings.FeedbackOrganization, model.EMAIL_SETTINGS_DEFAULT_FEEDBACK_ORGANIZATION),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_RATE, map[string]interface{}{
-		"enable_rate_limiter":      *utils.Cfg.RateLimitSettings.Enable,
-		"vary_by_remote_address":   utils.Cfg.RateLimitSettings.VaryByRemoteAddr,
-		"per_sec":                  utils.Cfg.RateLimitSettings.PerSec,
-		"max_burst":                *utils.Cfg.RateLimitSettings.MaxBurst,
-		"memory_store_size":        utils.Cfg.RateLimitSettings.MemoryStoreSize,
-		"isdefault_vary_by_header": isDefault(utils.Cfg.RateLimitSettings.VaryByHeader, ""),
+		"enable_rate_limiter":      *Config().RateLimitSettings.Enable,
+		"vary_by_remote_address":   Config().RateLimitSettings.VaryByRemoteAddr,
+		"per_sec":                  Config().RateLimitSettings.PerSec,
+		"max_burst":                *Config().RateLimitSettings.MaxBurst,
+		"memory_store_size":        Config().RateLimitSettings.MemoryStoreSize,
+		"isdefault_vary_by_header": isDefault(Config().RateLimitSettings.VaryByHeader, ""),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_PRIVACY, map[string]interface{}{
-		"show_email_address": utils.Cfg.PrivacySettings.ShowEmailAddress,
-		"show_full_name":     utils.Cfg.PrivacySettings.ShowFullName,
+		"show_email_address": Config().PrivacySettings.ShowEmailAddress,
+		"show_full_name":     Config().PrivacySettings.ShowFullName,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_OAUTH, map[string]interface{}{
-		"enable_gitlab":    utils.Cfg.GitLabSettings.Enable,
-		"enable_google":    utils.Cfg.GoogleSettings.Enable,
-		"enable_office365": utils.Cfg.Office365Settings.Enable,
+		"enable_gitlab":    Config().GitLabSettings.Enable,
+		"enable_google":    Config().GoogleSettings.Enable,
+		"enable_office365": Config().Office365Settings.Enable,
+		"enable_openid":    *Config().OpenIdSettings.Enable,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_SUPPORT, map[string]interface{}{
-		"isdefault_terms_of_service_link": isDefault(*utils.Cfg.SupportSettings.TermsOfServiceLink, model.SUPPORT_SETTINGS_DEFAULT_TERMS_OF_SERVICE_LINK),
-		"isdefault_privacy_policy_link":   isDefault(*utils.Cfg.SupportSettings.PrivacyPolicyLink, model.SUPPORT_SETTINGS_DEFAULT_PRIVACY_POLICY_LINK),
-		"isdefault_about_link":            isDefault(*utils.Cfg.SupportSettings.AboutLink, model.SUPPORT_SETTINGS_DEFAULT_ABOUT_LINK),
-		"isdefault_help_link":             isDefault(*utils.Cfg.SupportSettings.HelpLink, model.SUPPORT_SETTINGS_DEFAULT_HELP_LINK),
-		"isdefault_report_a_problem_link": isDefault(*utils.Cfg.SupportSettings.ReportAProblemLink, model.SUPPORT_SETTINGS_DEFAULT_REPORT_A_PROBLEM_LINK),
-		"isdefault_support_email":         isDefault(*utils.Cfg.SupportSettings.SupportEmail, model.SUPPORT_SETTINGS_DEFAULT_SUPPORT_EMAIL),
+		"isdefault_terms_of_service_link": isDefault(*Config().SupportSettings.TermsOfServiceLink, model.SUPPORT_SETTINGS_DEFAULT_TERMS_OF_SERVICE_LINK),
+		"isdefault_privacy_policy_link":   isDefault(*Config().SupportSettings.PrivacyPolicyLink, model.SUPPORT_SETTINGS_DEFAULT_PRIVACY_POLICY_LINK),
+		"isdefault_about_link":            isDefault(*Config().SupportSettings.AboutLink, model.SUPPORT_SETTINGS_DEFAULT_ABOUT_LINK),
+		"isdefault_help_link":             isDefault(*Config().SupportSettings.HelpLink, model.SUPPORT_SETTINGS_DEFAULT_HELP_LINK),
+		"isdefault_report_a_problem_link": isDefault(*Config().SupportSettings.ReportAProblemLink, model.SUPPORT_SETTINGS_DEFAULT_REPORT_A_PROBLEM_LINK),
+		"isdefault_support_email":         isDefault(*Config().SupportSettings.SupportEmail, model.SUPPORT_SETTINGS_DEFAULT_SUPPORT_EMAIL),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_LDAP, map[string]interface{}{
-		"enable":                         *utils.Cfg.LdapSettings.Enable,
-		"connection_security":            *utils.Cfg.LdapSettings.ConnectionSecurity,
-		"skip_certificate_verification":  *utils.Cfg.LdapSettings.SkipCertificateVerification,
-		"sync_interval_minutes":          *utils.Cfg.LdapSettings.SyncIntervalMinutes,
-		"query_timeout":                  *utils.Cfg.LdapSettings.QueryTimeout,
-		"max_page_size":                  *utils.Cfg.LdapSettings.MaxPageSize,
-		"isdefault_first_name_attribute": isDefault(*utils.Cfg.LdapSettings.FirstNameAttribute, model.LDAP_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE),
-		"isdefault_last_name_attribute":  isDefault(*utils.Cfg.LdapSettings.LastNameAttribute, model.LDAP_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE),
-		"isdefault_email_attribute":      isDefault(*utils.Cfg.LdapSettings.EmailAttribute, model.LDAP_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE),
-		"isdefault_username_attribute":   isDefault(*utils.Cfg.LdapSettings.UsernameAttribute, model.LDAP_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE),
-		"isdefault_nickname_attribute":   isDefault(*utils.Cfg.LdapSettings.NicknameAttribute, model.LDAP_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE),
-		"isdefault_id_attribute":         isDefault(*utils.Cfg.LdapSettings.IdAttribute, model.LDAP_SETTINGS_DEFAULT_ID_ATTRIBUTE),
-		"isdefault_position_attribute":   isDefault(*utils.Cfg.LdapSettings.PositionAttribute, model.LDAP_SETTINGS_DEFAULT_POSITION_ATTRIBUTE),
-		"isdefault_login_field_name":     isDefault(*utils.Cfg.LdapSettings.LoginFieldName, model.LDAP_SETTINGS_DEFAULT_LOGIN_FIELD_NAME),
+		"enable":                         *Config().LdapSettings.Enable,
+		"connection_security":            *Config().LdapSettings.ConnectionSecurity,
+		"skip_certificate_verification":  *Config().LdapSettings.SkipCertificateVerification,
+		"sync_interval_minutes":          *Config().LdapSettings.SyncIntervalMinutes,
+		"enable_group_sync":              *Config().LdapSettings.EnableGroupSync,
+		"group_sync_interval_minutes":    *Config().LdapSettings.GroupSyncIntervalMinutes,
+		"query_timeout":                  *Config().LdapSettings.QueryTimeout,
+		"max_page_size":                  *Config().LdapSettings.MaxPageSize,
+		"isdefault_first_name_attribute": isDefault(*Config().LdapSettings.FirstNameAttribute, model.LDAP_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE),
+		"isdefault_last_name_attribute":  isDefault(*Config().LdapSettings.LastNameAttribute, model.LDAP_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE),
+		"isdefault_email_attribute":      isDefault(*Config().LdapSettings.EmailAttribute, model.LDAP_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE),
+		"isdefault_username_attribute":   isDefault(*Config().LdapSettings.UsernameAttribute, model.LDAP_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE),
+		"isdefault_nickname_attribute":   isDefault(*Config().LdapSettings.NicknameAttribute, model.LDAP_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE),
+		"isdefault_id_attribute":         isDefault(*Config().LdapSettings.IdAttribute, model.LDAP_SETTINGS_DEFAULT_ID_ATTRIBUTE),
+		"isdefault_position_attribute":   isDefault(*Config().LdapSettings.PositionAttribute, model.LDAP_SETTINGS_DEFAULT_POSITION_ATTRIBUTE),
+		"isdefault_login_field_name":     isDefault(*Config().LdapSettings.LoginFieldName, model.LDAP_SETTINGS_DEFAULT_LOGIN_FIELD_NAME),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_COMPLIANCE, map[string]interface{}{
-		"enable":       *utils.Cfg.ComplianceSettings.Enable,
-		"enable_daily": *utils.Cfg.ComplianceSettings.EnableDaily,
+		"enable":       *Config().ComplianceSettings.Enable,
+		"enable_daily": *Config().ComplianceSettings.EnableDaily,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_LOCALIZATION, map[string]interface{}{
-		"default_server_locale": *utils.Cfg.LocalizationSettings.DefaultServerLocale,
-		"default_client_locale": *utils.Cfg.LocalizationSettings.DefaultClientLocale,
-		"available_locales":     *utils.Cfg.LocalizationSettings.AvailableLocales,
+		"default_server_locale": *Config().LocalizationSettings.DefaultServerLocale,
+		"default_client_locale": *Config().LocalizationSettings.DefaultClientLocale,
+		"available_locales":     *Config().LocalizationSettings.AvailableLocales,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_SAML, map[string]interface{}{
-		"enable":                         *utils.Cfg.SamlSettings.Enable,
-		"verify":                         *utils.Cfg.SamlSettings.Verify,
-		"encrypt":                        *utils.Cfg.SamlSettings.Encrypt,
-		"isdefault_first_name_attribute": isDefault(*utils.Cfg.SamlSettings.FirstNameAttribute, model.SAML_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE),
-		"isdefault_last_name_attribute":  isDefault(*utils.Cfg.SamlSettings.LastNameAttribute, model.SAML_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE),
-		"isdefault_email_attribute":      isDefault(*utils.Cfg.SamlSettings.EmailAttribute, model.SAML_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE),
-		"isdefault_username_attribute":   isDefault(*utils.Cfg.SamlSettings.UsernameAttribute, model.SAML_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE),
-		"isdefault_nickname_attribute":   isDefault(*utils.Cfg.SamlSettings.NicknameAttribute, model.SAML_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE),
-		"isdefault_locale_attribute":     isDefault(*utils.Cfg.SamlSettings.LocaleAttribute, model.SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE),
-		"isdefault_position_attribute":   isDefault(*utils.Cfg.SamlSettings.PositionAttribute, model.SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE),
-		"isdefault_login_button_text":    isDefault(*utils.Cfg.SamlSettings.LoginButtonText, model.USER_AUTH_SERVICE_SAML_TEXT),
+		"enable":                         *Config().SamlSettings.Enable,
+		"verify":                         *Config().SamlSettings.Verify,
+		"encrypt":                        *Config().SamlSettings.Encrypt,
+		"isdefault_first_name_attribute": isDefault(*Config().SamlSettings.FirstNameAttribute, model.SAML_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE),
+		"isdefault_last_name_attribute":  isDefault(*Config().SamlSettings.LastNameAttribute, model.SAML_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE),
+		"isdefault_email_attribute":      isDefault(*Config().SamlSettings.EmailAttribute, model.SAML_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE),
+		"isdefault_username_attribute":   isDefault(*Config().SamlSettings.UsernameAttribute, model.SAML_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE),
+		"isdefault_nickname_attribute":   isDefault(*Config().SamlSettings.NicknameAttribute, model.SAML_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE),
+		"isdefault_locale_attribute":     isDefault(*Config().SamlSettings.LocaleAttribute, model.SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE),
+		"isdefault_position_attribute":   isDefault(*Config().SamlSettings.PositionAttribute, model.SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE),
+		"isdefault_login_button_text":    isDefault(*Config().SamlSettings.LoginButtonText, model.USER_AUTH_SERVICE_SAML_TEXT),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_CLUSTER, map[string]interface{}{
-		"enable": *utils.Cfg.ClusterSettings.Enable,
+		"enable": *Config().ClusterSettings.Enable,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_METRICS, map[string]interface{}{
-		"enable":             *utils.Cfg.MetricsSettings.Enable,
-		"block_profile_rate": *utils.Cfg.MetricsSettings.BlockProfileRate,
+		"enable":             *Config().MetricsSettings.Enable,
+		"block_profile_rate": *Config().MetricsSettings.BlockProfileRate,
 	})
 
 	SendDiagnostic(TRACK_CONFIG_NATIVEAPP, map[string]interface{}{
-		"isdefault_app_download_link":         isDefault(*utils.Cfg.NativeAppSettings.AppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_APP_DOWNLOAD_LINK),
-		"isdefault_android_app_download_link": isDefault(*utils.Cfg.NativeAppSettings.AndroidAppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_ANDROID_APP_DOWNLOAD_LINK),
-		"isdefault_iosapp_download_link":      isDefault(*utils.Cfg.NativeAppSettings.IosAppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_IOS_APP_DOWNLOAD_LINK),
+		"isdefault_app_download_link":         isDefault(*Config().NativeAppSettings.AppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_APP_DOWNLOAD_LINK),
+		"isdefault_android_app_download_link": isDefault(*Config().NativeAppSettings.AndroidAppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_ANDROID_APP_DOWNLOAD_LINK),
+		"isdefault_iosapp_download_link":      isDefault(*Config().NativeAppSettings.IosAppDownloadLink, model.NATIVEAPP_SETTINGS_DEFAULT_IOS_APP_DOWNLOAD_LINK),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_WEBRTC, map[string]interface{}{
-		"enable":             *utils.Cfg.WebrtcSettings.Enable,
-		"isdefault_stun_uri": isDefault(*utils.Cfg.WebrtcSettings.StunURI, model.WEBRTC_SETTINGS_DEFAULT_STUN_URI),
-		"isdefault_turn_uri": isDefault(*utils.Cfg.WebrtcSettings.TurnURI, model.WEBRTC_SETTINGS_DEFAULT_TURN_URI),
+		"enable":             *Config().WebrtcSettings.Enable,
+		"isdefault_stun_uri": isDefault(*Config().WebrtcSettings.StunURI, model.WEBRTC_SETTINGS_DEFAULT_STUN_URI),
+		"isdefault_turn_uri": isDefault(*Config().WebrtcSettings.TurnURI, model.WEBRTC_SETTINGS_DEFAULT_TURN_URI),
 	})
 
 	SendDiagnostic(TRACK_CONFIG_ANALYTICS, map[string]interface{}{
-		"isdefault_max_users_for_statistics": isDefault(*utils.Cfg.AnalyticsSettings.MaxUsersForStatistics, model.ANALYTICS_SETTINGS_DEFAULT_MAX_USERS_FOR_STATISTICS),
+		"isdefault_max_users_for_statistics": isDefault(*Config().AnalyticsSettings.MaxUsersForStatistics, model.ANALYTICS_SETTINGS_DEFAULT_MAX_USERS_FOR_STATISTICS),
+	})
+
+	SendDiagnostic(TRACK_CONFIG_INCIDENT, map[string]interface{}{
+		"enable":             *Config().IncidentSettings.Enable,
+		"enable_public_feed": *Config().IncidentSettings.EnablePublicFeed,
 	})
 }
 
@@ -391,7 +403,7 @@ func trackServer() {
 	data := map[string]interface{}{
 		"edition":          model.BuildEnterpriseReady,
 		"version":          model.CurrentVersion,
-		"database_type":    utils.Cfg.SqlSettings.DriverName,
+		"database_type":    Config().SqlSettings.DriverName,
 		"operating_system": runtime.GOOS,
 	}
 
@@ -116,6 +116,7 @@ func TestDiagnostics(t *testing.T) {
 			TRACK_CONFIG_SUPPORT,
 			TRACK_CONFIG_NATIVEAPP,
 			TRACK_CONFIG_ANALYTICS,
+			TRACK_CONFIG_INCIDENT,
 			TRACK_ACTIVITY,
 			TRACK_SERVER,
 		} {
@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// SaveDraft creates or replaces the user's draft for a channel or thread and tells the user's other
+// sessions about it.
+func SaveDraft(draft *model.Draft) (*model.Draft, *model.AppError) {
+	draft.CreateAt = 0
+	if existing, err := GetDraft(draft.UserId, draft.ChannelId, draft.RootId); err == nil {
+		draft.CreateAt = existing.CreateAt
+	}
+
+	if result := <-Srv.Store.Draft().Save(draft); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rdraft := result.Data.(*model.Draft)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_DRAFT_UPDATED, "", "", rdraft.UserId, nil)
+		message.Add("draft", rdraft.ToJson())
+		go Publish(message)
+
+		return rdraft, nil
+	}
+}
+
+func GetDraft(userId, channelId, rootId string) (*model.Draft, *model.AppError) {
+	if result := <-Srv.Store.Draft().Get(userId, channelId, rootId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Draft), nil
+	}
+}
+
+func GetDraftsForUser(userId string) ([]*model.Draft, *model.AppError) {
+	if result := <-Srv.Store.Draft().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Draft), nil
+	}
+}
+
+func DeleteDraft(userId, channelId, rootId string) *model.AppError {
+	if result := <-Srv.Store.Draft().Delete(userId, channelId, rootId); result.Err != nil {
+		return result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED, "", "", userId, nil)
+	message.Add("channel_id", channelId)
+	message.Add("root_id", rootId)
+	go Publish(message)
+
+	return nil
+}
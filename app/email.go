@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/url"
+	"strings"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/model"
@@ -18,13 +19,13 @@ func SendChangeUsernameEmail(oldUsername, newUsername, email, locale, siteURL st
 
 	subject := T("api.templates.username_change_subject",
 		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"],
-		"TeamDisplayName": utils.Cfg.TeamSettings.SiteName})
+		"TeamDisplayName": Config().TeamSettings.SiteName})
 
 	bodyPage := utils.NewHTMLTemplate("email_change_body", locale)
 	bodyPage.Props["SiteURL"] = siteURL
 	bodyPage.Props["Title"] = T("api.templates.username_change_body.title")
 	bodyPage.Html["Info"] = template.HTML(T("api.templates.username_change_body.info",
-		map[string]interface{}{"TeamDisplayName": utils.Cfg.TeamSettings.SiteName, "NewUsername": newUsername}))
+		map[string]interface{}{"TeamDisplayName": Config().TeamSettings.SiteName, "NewUsername": newUsername}))
 
 	if err := utils.SendMail(email, subject, bodyPage.Render()); err != nil {
 		return model.NewLocAppError("SendChangeUsernameEmail", "api.user.send_email_change_username_and_forget.error", nil, err.Error())
@@ -36,18 +37,18 @@ func SendChangeUsernameEmail(oldUsername, newUsername, email, locale, siteURL st
 func SendEmailChangeVerifyEmail(userId, newUserEmail, locale, siteURL string) *model.AppError {
 	T := utils.GetUserTranslations(locale)
 
-	link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+utils.Cfg.EmailSettings.InviteSalt), url.QueryEscape(newUserEmail))
+	link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+Config().EmailSettings.InviteSalt), url.QueryEscape(newUserEmail))
 
 	subject := T("api.templates.email_change_verify_subject",
 		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"],
-		"TeamDisplayName": utils.Cfg.TeamSettings.SiteName})
+		"TeamDisplayName": Config().TeamSettings.SiteName})
 
 
 	bodyPage := utils.NewHTMLTemplate("email_change_verify_body", locale)
 	bodyPage.Props["SiteURL"] = siteURL
 	bodyPage.Props["Title"] = T("api.templates.email_change_verify_body.title")
 	bodyPage.Props["Info"] = T("api.templates.email_change_verify_body.info",
-		map[string]interface{}{"TeamDisplayName": utils.Cfg.TeamSettings.SiteName})
+		map[string]interface{}{"TeamDisplayName": Config().TeamSettings.SiteName})
 	bodyPage.Props["VerifyUrl"] = link
 	bodyPage.Props["VerifyButton"] = T("api.templates.email_change_verify_body.button")
 
@@ -63,13 +64,13 @@ func SendEmailChangeEmail(oldEmail, newEmail, locale, siteURL string) *model.App
 
 	subject := T("api.templates.email_change_subject",
 		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"],
-		"TeamDisplayName": utils.Cfg.TeamSettings.SiteName})
+		"TeamDisplayName": Config().TeamSettings.SiteName})
 
 	bodyPage := utils.NewHTMLTemplate("email_change_body", locale)
 	bodyPage.Props["SiteURL"] = siteURL
 	bodyPage.Props["Title"] = T("api.templates.email_change_body.title")
 	bodyPage.Html["Info"] = template.HTML(T("api.templates.email_change_body.info",
-		map[string]interface{}{"TeamDisplayName": utils.Cfg.TeamSettings.SiteName, "NewEmail": newEmail}))
+		map[string]interface{}{"TeamDisplayName": Config().TeamSettings.SiteName, "NewEmail": newEmail}))
 
 	if err := utils.SendMail(oldEmail, subject, bodyPage.Render()); err != nil {
 		return model.NewLocAppError("SendEmailChangeEmail", "api.user.send_email_change_email_and_forget.error", nil, err.Error())
@@ -81,7 +82,7 @@ func SendEmailChangeEmail(oldEmail, newEmail, locale, siteURL string) *model.App
 func SendVerifyEmail(userId, userEmail, locale, siteURL string) *model.AppError {
 	T := utils.GetUserTranslations(locale)
 
-	link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+utils.Cfg.EmailSettings.InviteSalt), url.QueryEscape(userEmail))
+	link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+Config().EmailSettings.InviteSalt), url.QueryEscape(userEmail))
 
 	url, _ := url.Parse(siteURL)
 
@@ -139,13 +140,13 @@ func SendWelcomeEmail(userId string, email string, verified bool, locale, siteUR
 	bodyPage.Props["Info3"] = T("api.templates.welcome_body.info3")
 	bodyPage.Props["SiteURL"] = siteURL
 
-	if *utils.Cfg.NativeAppSettings.AppDownloadLink != "" {
+	if *Config().NativeAppSettings.AppDownloadLink != "" {
 		bodyPage.Props["AppDownloadInfo"] = T("api.templates.welcome_body.app_download_info")
-		bodyPage.Props["AppDownloadLink"] = *utils.Cfg.NativeAppSettings.AppDownloadLink
+		bodyPage.Props["AppDownloadLink"] = *Config().NativeAppSettings.AppDownloadLink
 	}
 
 	if !verified {
-		link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+utils.Cfg.EmailSettings.InviteSalt), url.QueryEscape(email))
+		link := fmt.Sprintf("%s/do_verify_email?uid=%s&hid=%s&email=%s", siteURL, userId, model.HashPassword(userId+Config().EmailSettings.InviteSalt), url.QueryEscape(email))
 		bodyPage.Props["VerifyUrl"] = link
 	}
 
@@ -161,13 +162,13 @@ func SendPasswordChangeEmail(email, method, locale, siteURL string) *model.AppEr
 
 	subject := T("api.templates.password_change_subject",
 		map[string]interface{}{"SiteName": utils.ClientCfg["SiteName"],
-		"TeamDisplayName": utils.Cfg.TeamSettings.SiteName})
+		"TeamDisplayName": Config().TeamSettings.SiteName})
 
 	bodyPage := utils.NewHTMLTemplate("password_change_body", locale)
 	bodyPage.Props["SiteURL"] = siteURL
 	bodyPage.Props["Title"] = T("api.templates.password_change_body.title")
 	bodyPage.Html["Info"] = template.HTML(T("api.templates.password_change_body.info",
-		map[string]interface{}{"TeamDisplayName": utils.Cfg.TeamSettings.SiteName, "TeamURL": siteURL, "Method": method}))
+		map[string]interface{}{"TeamDisplayName": Config().TeamSettings.SiteName, "TeamURL": siteURL, "Method": method}))
 
 	if err := utils.SendMail(email, subject, bodyPage.Render()); err != nil {
 		return model.NewLocAppError("SendPasswordChangeEmail", "api.user.send_password_change_email_and_forget.error", nil, err.Error())
@@ -227,42 +228,117 @@ func SendMfaChangeEmail(email string, activated bool, locale, siteURL string) *m
 	return nil
 }
 
-func SendInviteEmails(team *model.Team, senderName string, invites []string, siteURL string) {
+// SendInviteEmails records an invitation for every email address and sends each of them a link to join the team.
+func SendInviteEmails(team *model.Team, senderName string, senderId string, invites []string, siteURL string) {
 	for _, invite := range invites {
 		if len(invite) > 0 {
-			senderRole := utils.T("api.team.invite_members.member")
-
-			subject := utils.T("api.templates.invite_subject",
-				map[string]interface{}{"SenderName": senderName,
-				"TeamDisplayName": team.DisplayName,
-				"SiteName": utils.ClientCfg["SiteName"]})
-
-			bodyPage := utils.NewHTMLTemplate("invite_body", model.DEFAULT_LOCALE)
-			bodyPage.Props["SiteURL"] = siteURL
-			bodyPage.Props["Title"] = utils.T("api.templates.invite_body.title")
-			bodyPage.Html["Info"] = template.HTML(utils.T("api.templates.invite_body.info",
-				map[string]interface{}{"SenderStatus": senderRole, "SenderName": senderName, "TeamDisplayName": team.DisplayName}))
-			bodyPage.Props["Button"] = utils.T("api.templates.invite_body.button")
-			bodyPage.Html["ExtraInfo"] = template.HTML(utils.T("api.templates.invite_body.extra_info",
-				map[string]interface{}{"TeamDisplayName": team.DisplayName, "TeamURL": siteURL + "/" + team.Name}))
-
-			props := make(map[string]string)
-			props["email"] = invite
-			props["id"] = team.Id
-			props["display_name"] = team.DisplayName
-			props["name"] = team.Name
-			props["time"] = fmt.Sprintf("%v", model.GetMillis())
-			data := model.MapToJson(props)
-			hash := model.HashPassword(fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt))
-			bodyPage.Props["Link"] = fmt.Sprintf("%s/signup_user_complete/?d=%s&h=%s", siteURL, url.QueryEscape(data), url.QueryEscape(hash))
-
-			if !utils.Cfg.EmailSettings.SendEmailNotifications {
-				l4g.Info(utils.T("api.team.invite_members.sending.info"), invite, bodyPage.Props["Link"])
+			invitation, err := saveInvitation(&model.Invitation{Email: invite, TeamId: team.Id, InviterId: senderId})
+			if err != nil {
+				l4g.Error(utils.T("api.team.invite_members.send.error"), err)
+				continue
 			}
 
-			if err := utils.SendMail(invite, subject, bodyPage.Render()); err != nil {
+			sendInviteEmail(team, senderName, invitation, siteURL)
+		}
+	}
+}
+
+func sendInviteEmail(team *model.Team, senderName string, invitation *model.Invitation, siteURL string) {
+	senderRole := utils.T("api.team.invite_members.member")
+
+	subject := utils.T("api.templates.invite_subject",
+		map[string]interface{}{"SenderName": senderName,
+			"TeamDisplayName": team.DisplayName,
+			"SiteName":        utils.ClientCfg["SiteName"]})
+
+	bodyPage := utils.NewHTMLTemplate("invite_body", model.DEFAULT_LOCALE)
+	bodyPage.Props["SiteURL"] = siteURL
+	bodyPage.Props["Title"] = utils.T("api.templates.invite_body.title")
+	bodyPage.Html["Info"] = template.HTML(utils.T("api.templates.invite_body.info",
+		map[string]interface{}{"SenderStatus": senderRole, "SenderName": senderName, "TeamDisplayName": team.DisplayName}))
+	bodyPage.Props["Button"] = utils.T("api.templates.invite_body.button")
+	bodyPage.Html["ExtraInfo"] = template.HTML(utils.T("api.templates.invite_body.extra_info",
+		map[string]interface{}{"TeamDisplayName": team.DisplayName, "TeamURL": siteURL + "/" + team.Name}))
+
+	props := make(map[string]string)
+	props["email"] = invitation.Email
+	props["id"] = team.Id
+	props["display_name"] = team.DisplayName
+	props["name"] = team.Name
+	props["token"] = invitation.Token
+	props["time"] = fmt.Sprintf("%v", invitation.UpdateAt)
+	data := model.MapToJson(props)
+	hash := model.HashPassword(fmt.Sprintf("%v:%v", data, Config().EmailSettings.InviteSalt))
+	bodyPage.Props["Link"] = fmt.Sprintf("%s/signup_user_complete/?d=%s&h=%s", siteURL, url.QueryEscape(data), url.QueryEscape(hash))
+
+	if !Config().EmailSettings.SendEmailNotifications {
+		l4g.Info(utils.T("api.team.invite_members.sending.info"), invitation.Email, bodyPage.Props["Link"])
+	}
+
+	if err := utils.SendMail(invitation.Email, subject, bodyPage.Render()); err != nil {
+		l4g.Error(utils.T("api.team.invite_members.send.error"), err)
+	}
+}
+
+// SendGuestInviteEmails sends invitations to join the team as guests with access to only the given channels.
+// The channels are signed into the link along with the team so that they can't be changed by the guest.
+func SendGuestInviteEmails(team *model.Team, channels []*model.Channel, senderName string, senderId string, invites []string, siteURL string) {
+	channelIds := make([]string, len(channels))
+	for i, channel := range channels {
+		channelIds[i] = channel.Id
+	}
+
+	for _, invite := range invites {
+		if len(invite) > 0 {
+			invitation, err := saveInvitation(&model.Invitation{Email: invite, TeamId: team.Id, InviterId: senderId, Guest: true, ChannelIds: channelIds})
+			if err != nil {
 				l4g.Error(utils.T("api.team.invite_members.send.error"), err)
+				continue
 			}
+
+			sendGuestInviteEmail(team, channels, senderName, invitation, siteURL)
 		}
 	}
 }
+
+func sendGuestInviteEmail(team *model.Team, channels []*model.Channel, senderName string, invitation *model.Invitation, siteURL string) {
+	channelIds := make([]string, len(channels))
+	channelNames := make([]string, len(channels))
+	for i, channel := range channels {
+		channelIds[i] = channel.Id
+		channelNames[i] = channel.DisplayName
+	}
+
+	subject := utils.T("api.templates.invite_subject",
+		map[string]interface{}{"SenderName": senderName,
+			"TeamDisplayName": team.DisplayName,
+			"SiteName":        utils.ClientCfg["SiteName"]})
+
+	bodyPage := utils.NewHTMLTemplate("invite_body", model.DEFAULT_LOCALE)
+	bodyPage.Props["SiteURL"] = siteURL
+	bodyPage.Props["Title"] = utils.T("api.templates.invite_body.title")
+	bodyPage.Html["Info"] = template.HTML(utils.T("api.templates.invite_guest_body.info",
+		map[string]interface{}{"SenderName": senderName, "ChannelNames": strings.Join(channelNames, ", "), "TeamDisplayName": team.DisplayName}))
+	bodyPage.Props["Button"] = utils.T("api.templates.invite_body.button")
+
+	props := make(map[string]string)
+	props["email"] = invitation.Email
+	props["id"] = team.Id
+	props["display_name"] = team.DisplayName
+	props["name"] = team.Name
+	props["guest"] = "true"
+	props["channels"] = strings.Join(channelIds, ",")
+	props["token"] = invitation.Token
+	props["time"] = fmt.Sprintf("%v", invitation.UpdateAt)
+	data := model.MapToJson(props)
+	hash := model.HashPassword(fmt.Sprintf("%v:%v", data, Config().EmailSettings.InviteSalt))
+	bodyPage.Props["Link"] = fmt.Sprintf("%s/signup_user_complete/?d=%s&h=%s", siteURL, url.QueryEscape(data), url.QueryEscape(hash))
+
+	if !Config().EmailSettings.SendEmailNotifications {
+		l4g.Info(utils.T("api.team.invite_members.sending.info"), invitation.Email, bodyPage.Props["Link"])
+	}
+
+	if err := utils.SendMail(invitation.Email, subject, bodyPage.Render()); err != nil {
+		l4g.Error(utils.T("api.team.invite_members.send.error"), err)
+	}
+}
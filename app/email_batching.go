@@ -24,9 +24,9 @@ const (
 var emailBatchingJob *EmailBatchingJob
 
 func InitEmailBatching() {
-	if *utils.Cfg.EmailSettings.EnableEmailBatching {
+	if *Config().EmailSettings.EnableEmailBatching {
 		if emailBatchingJob == nil {
-			emailBatchingJob = MakeEmailBatchingJob(*utils.Cfg.EmailSettings.EmailBatchingBufferSize)
+			emailBatchingJob = MakeEmailBatchingJob(*Config().EmailSettings.EmailBatchingBufferSize)
 		}
 
 		// note that we don't support changing EmailBatchingBufferSize without restarting the server
@@ -36,7 +36,7 @@ func InitEmailBatching() {
 }
 
 func AddNotificationEmailToBatch(user *model.User, post *model.Post, team *model.Team) *model.AppError {
-	if !*utils.Cfg.EmailSettings.EnableEmailBatching {
+	if !*Config().EmailSettings.EnableEmailBatching {
 		return model.NewLocAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.disabled.app_error", nil, "")
 	}
 
@@ -71,8 +71,8 @@ func (job *EmailBatchingJob) Start() {
 		task.Cancel()
 	}
 
-	l4g.Debug(utils.T("api.email_batching.start.starting"), *utils.Cfg.EmailSettings.EmailBatchingInterval)
-	model.CreateRecurringTask(EMAIL_BATCHING_TASK_NAME, job.CheckPendingEmails, time.Duration(*utils.Cfg.EmailSettings.EmailBatchingInterval)*time.Second)
+	l4g.Debug(utils.T("api.email_batching.start.starting"), *Config().EmailSettings.EmailBatchingInterval)
+	model.CreateRecurringTask(EMAIL_BATCHING_TASK_NAME, job.CheckPendingEmails, time.Duration(*Config().EmailSettings.EmailBatchingInterval)*time.Second)
 }
 
 func (job *EmailBatchingJob) Add(user *model.User, post *model.Post, team *model.Team) bool {
@@ -196,14 +196,14 @@ func sendBatchedEmailNotification(userId string, notifications []*batchedNotific
 	tm := time.Unix(notifications[0].post.CreateAt/1000, 0)
 
 	subject := translateFunc("api.email_batching.send_batched_email_notification.subject", len(notifications), map[string]interface{}{
-		"SiteName": utils.Cfg.TeamSettings.SiteName,
+		"SiteName": Config().TeamSettings.SiteName,
 		"Year":     tm.Year(),
 		"Month":    translateFunc(tm.Month().String()),
 		"Day":      tm.Day(),
 	})
 
 	body := utils.NewHTMLTemplate("post_batched_body", user.Locale)
-	body.Props["SiteURL"] = *utils.Cfg.ServiceSettings.SiteURL
+	body.Props["SiteURL"] = *Config().ServiceSettings.SiteURL
 	body.Props["Posts"] = template.HTML(contents)
 	body.Props["BodyText"] = translateFunc("api.email_batching.send_batched_email_notification.body_text", len(notifications))
 
@@ -218,7 +218,7 @@ func renderBatchedPost(template *utils.HTMLTemplate, post *model.Post, teamName
 
 	template.Props["Button"] = translateFunc("api.email_batching.render_batched_post.go_to_post")
 	template.Props["PostMessage"] = GetMessageForNotification(post, translateFunc)
-	template.Props["PostLink"] = *utils.Cfg.ServiceSettings.SiteURL + "/" + teamName + "/pl/" + post.Id
+	template.Props["PostLink"] = *Config().ServiceSettings.SiteURL + "/" + teamName + "/pl/" + post.Id
 
 	tm := time.Unix(post.CreateAt/1000, 0)
 	timezone, _ := tm.Zone()
@@ -494,7 +494,7 @@ func TestSendInviteEmails(t *testing.T) {
 	utils.DeleteMailBox(email1)
 	utils.DeleteMailBox(email2)
 
-	SendInviteEmails(th.BasicTeam, senderName, invites, siteURL)
+	SendInviteEmails(th.BasicTeam, senderName, th.BasicUser.Id, invites, siteURL)
 
 	//Check if the email was send to the rigth email address to email1
 	var resultsMailbox utils.JSONMessageHeaderInbucket
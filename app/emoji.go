@@ -6,6 +6,7 @@ package app
 import (
 	"bytes"
 	"image"
+	"image/color"
 	"image/color/palette"
 	"image/draw"
 	"image/gif"
@@ -14,26 +15,33 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"sort"
+	"strings"
 
+	l4g "github.com/alecthomas/log4go"
 	"github.com/disintegration/imaging"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 const (
 	MaxEmojiFileSize = 1000 * 1024 // 1 MB
 	MaxEmojiWidth    = 128
 	MaxEmojiHeight   = 128
+
+	EMOJI_AUTOCOMPLETE_LIMIT = 100
 )
 
 func CreateEmoji(sessionUserId string, emoji *model.Emoji, multiPartImageData *multipart.Form) (*model.Emoji, *model.AppError) {
 	// wipe the emoji id so that existing emojis can't get overwritten
 	emoji.Id = ""
+	emoji.Aliases = cleanEmojiAliases(emoji.Aliases)
 
 	// do our best to validate the emoji before committing anything to the DB so that we don't have to clean up
 	// orphaned files left over when validation fails later on
 	emoji.PreSave()
 	if err := emoji.IsValid(); err != nil {
-		return nil, err
+		return nil, setEmojiValidationStatus(err)
 	}
 
 	if emoji.CreatorId != sessionUserId {
@@ -44,6 +52,10 @@ func CreateEmoji(sessionUserId string, emoji *model.Emoji, multiPartImageData *m
 		return nil, model.NewAppError("createEmoji", "api.emoji.create.duplicate.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if err := checkEmojiAliasesAvailable(emoji); err != nil {
+		return nil, err
+	}
+
 	if imageData := multiPartImageData.File["image"]; len(imageData) == 0 {
 		err := model.NewLocAppError("Context", "api.context.invalid_body_param.app_error", map[string]interface{}{"Name": "createEmoji"}, "")
 		err.StatusCode = http.StatusBadRequest
@@ -67,6 +79,165 @@ func GetEmojiList() ([]*model.Emoji, *model.AppError) {
 	}
 }
 
+func GetEmoji(emojiId string) (*model.Emoji, *model.AppError) {
+	if result := <-Srv.Store.Emoji().Get(emojiId, false); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Emoji), nil
+	}
+}
+
+// PatchEmoji changes the category or aliases of a custom emoji. Its name and image can't be changed.
+func PatchEmoji(emoji *model.Emoji, patch *model.EmojiPatch) (*model.Emoji, *model.AppError) {
+	emoji.Patch(patch)
+	emoji.Aliases = cleanEmojiAliases(emoji.Aliases)
+
+	if err := emoji.IsValid(); err != nil {
+		return nil, setEmojiValidationStatus(err)
+	}
+
+	if err := checkEmojiAliasesAvailable(emoji); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Emoji().Update(emoji); result.Err != nil {
+		return nil, setEmojiValidationStatus(result.Err)
+	} else {
+		return result.Data.(*model.Emoji), nil
+	}
+}
+
+// SearchEmoji returns the custom emoji with a name or alias that matches the term, with any colons
+// around the term ignored.
+func SearchEmoji(term string, prefixOnly bool, limit int) ([]*model.Emoji, *model.AppError) {
+	term = strings.Trim(strings.TrimSpace(term), ":")
+	if len(term) == 0 {
+		return []*model.Emoji{}, nil
+	}
+
+	if result := <-Srv.Store.Emoji().Search(term, prefixOnly, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Emoji), nil
+	}
+}
+
+func GetEmojiByName(name string) (*model.Emoji, *model.AppError) {
+	if result := <-Srv.Store.Emoji().GetByName(name); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Emoji), nil
+	}
+}
+
+// GetEmojiStats returns how often the custom emoji has been used in reactions and in message text.
+func GetEmojiStats(emoji *model.Emoji) (*model.EmojiStats, *model.AppError) {
+	if result := <-Srv.Store.Reaction().GetEmojiStats([]string{emoji.Name}); result.Err != nil {
+		return nil, result.Err
+	} else {
+		stats := result.Data.([]*model.EmojiStats)[0]
+		stats.EmojiId = emoji.Id
+
+		return stats, nil
+	}
+}
+
+// GetEmojiAnalytics returns the usage of every custom emoji, most used first.
+func GetEmojiAnalytics() ([]*model.EmojiStats, *model.AppError) {
+	list, err := GetEmojiList()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(list))
+	ids := make(map[string]string, len(list))
+	for i, emoji := range list {
+		names[i] = emoji.Name
+		ids[emoji.Name] = emoji.Id
+	}
+
+	var stats []*model.EmojiStats
+	if result := <-Srv.Store.Reaction().GetEmojiStats(names); result.Err != nil {
+		return nil, result.Err
+	} else {
+		stats = result.Data.([]*model.EmojiStats)
+	}
+
+	for _, stat := range stats {
+		stat.EmojiId = ids[stat.EmojiName]
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if total, other := stats[i].ReactionCount+stats[i].MessageCount, stats[j].ReactionCount+stats[j].MessageCount; total != other {
+			return total > other
+		}
+
+		return stats[i].EmojiName < stats[j].EmojiName
+	})
+
+	return stats, nil
+}
+
+// RecordEmojiMessageUsage counts a use in message text of each of the given emoji that is a custom emoji.
+func RecordEmojiMessageUsage(emojiNames []string) {
+	if !*Config().ServiceSettings.EnableCustomEmoji {
+		return
+	}
+
+	customNames := []string{}
+	for _, name := range emojiNames {
+		if result := <-Srv.Store.Emoji().GetByName(name); result.Err == nil {
+			customNames = append(customNames, name)
+		}
+	}
+
+	if len(customNames) == 0 {
+		return
+	}
+
+	if result := <-Srv.Store.Reaction().IncrementEmojiMessageCounts(customNames, model.GetMillis()); result.Err != nil {
+		l4g.Error(utils.T("app.emoji.record_message_usage.error"), result.Err)
+	}
+}
+
+func cleanEmojiAliases(aliases model.StringArray) model.StringArray {
+	cleaned := model.StringArray{}
+	seen := make(map[string]bool)
+	for _, alias := range aliases {
+		alias = strings.Trim(strings.TrimSpace(alias), ":")
+		if len(alias) == 0 || seen[alias] {
+			continue
+		}
+
+		seen[alias] = true
+		cleaned = append(cleaned, alias)
+	}
+
+	return cleaned
+}
+
+// checkEmojiAliasesAvailable makes sure that none of the aliases of an emoji are already the name of
+// another custom emoji, since it would be ambiguous which emoji was meant.
+func checkEmojiAliasesAvailable(emoji *model.Emoji) *model.AppError {
+	for _, alias := range emoji.Aliases {
+		if result := <-Srv.Store.Emoji().GetByName(alias); result.Err == nil && result.Data != nil && result.Data.(*model.Emoji).Id != emoji.Id {
+			return model.NewAppError("checkEmojiAliasesAvailable", "api.emoji.alias.duplicate.app_error", map[string]interface{}{"Alias": alias}, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+func setEmojiValidationStatus(err *model.AppError) *model.AppError {
+	if err.Id == "model.emoji.category.app_error" || err.Id == "model.emoji.aliases.app_error" {
+		err.StatusCode = http.StatusBadRequest
+	}
+
+	return err
+}
+
 func UploadEmojiImage(id string, imageData *multipart.FileHeader) *model.AppError {
 	file, err := imageData.Open()
 	if err != nil {
@@ -77,65 +248,137 @@ func UploadEmojiImage(id string, imageData *multipart.FileHeader) *model.AppErro
 	buf := bytes.NewBuffer(nil)
 	io.Copy(buf, file)
 
-	// make sure the file is an image and is within the required dimensions
-	if config, _, err := image.DecodeConfig(bytes.NewReader(buf.Bytes())); err != nil {
-		return model.NewAppError("uploadEmojiImage", "api.emoji.upload.image.app_error", nil, "", http.StatusBadRequest)
-	} else if config.Width > MaxEmojiWidth || config.Height > MaxEmojiHeight {
-		data := buf.Bytes()
-		newbuf := bytes.NewBuffer(nil)
-		if info, err := model.GetInfoForBytes(imageData.Filename, data); err != nil {
-			return err
-		} else if info.MimeType == "image/gif" {
-			if gif_data, err := gif.DecodeAll(bytes.NewReader(data)); err != nil {
-				return model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.gif_decode_error", nil, "", http.StatusBadRequest)
-			} else {
-				resized_gif := resizeEmojiGif(gif_data)
-				if err := gif.EncodeAll(newbuf, resized_gif); err != nil {
-					return model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.gif_encode_error", nil, "", http.StatusBadRequest)
-				}
-				if err := WriteFile(newbuf.Bytes(), getEmojiImagePath(id)); err != nil {
-					return err
-				}
-			}
-		} else {
-			if img, _, err := image.Decode(bytes.NewReader(data)); err != nil {
-				return model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.decode_error", nil, "", http.StatusBadRequest)
-			} else {
-				resized_image := resizeEmoji(img, config.Width, config.Height)
-				if err := png.Encode(newbuf, resized_image); err != nil {
-					return model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.encode_error", nil, "", http.StatusBadRequest)
-				}
-				if err := WriteFile(newbuf.Bytes(), getEmojiImagePath(id)); err != nil {
-					return err
-				}
+	data, appErr := prepareEmojiImage(buf.Bytes())
+	if appErr != nil {
+		return appErr
+	}
+
+	return WriteFile(data, getEmojiImagePath(id))
+}
+
+// prepareEmojiImage makes sure that the data is an image within the configured limits and shrinks it to fit
+// within MaxEmojiWidth and MaxEmojiHeight. Animated gifs keep all of their frames. Animated pngs can't be
+// resized without losing their animation, so they have to be uploaded at the right size.
+func prepareEmojiImage(data []byte) ([]byte, *model.AppError) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.image.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	maxWidth := *Config().ServiceSettings.MaxEmojiUploadWidth
+	maxHeight := *Config().ServiceSettings.MaxEmojiUploadHeight
+	if config.Width > maxWidth || config.Height > maxHeight {
+		return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.dimensions.app_error", map[string]interface{}{"Width": maxWidth, "Height": maxHeight}, "", http.StatusBadRequest)
+	}
+
+	tooLarge := config.Width > MaxEmojiWidth || config.Height > MaxEmojiHeight
+
+	switch format {
+	case "gif":
+		gifData, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.gif_decode_error", nil, "", http.StatusBadRequest)
+		}
+
+		if err := checkEmojiFrameCount(len(gifData.Image)); err != nil {
+			return nil, err
+		}
+
+		if tooLarge {
+			newbuf := bytes.NewBuffer(nil)
+			if err := gif.EncodeAll(newbuf, resizeEmojiGif(gifData)); err != nil {
+				return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.gif_encode_error", nil, "", http.StatusBadRequest)
 			}
+
+			return newbuf.Bytes(), nil
 		}
-	} else {
-		if err := WriteFile(buf.Bytes(), getEmojiImagePath(id)); err != nil {
-			return err
+	case "png":
+		if frames := model.GetAnimatedPngFrameCount(data); frames > 0 {
+			if err := checkEmojiFrameCount(frames); err != nil {
+				return nil, err
+			}
+
+			if tooLarge {
+				return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.animated_png.app_error", map[string]interface{}{"Width": MaxEmojiWidth, "Height": MaxEmojiHeight}, "", http.StatusBadRequest)
+			}
+
+			return data, nil
 		}
 	}
 
+	if !tooLarge {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.decode_error", nil, "", http.StatusBadRequest)
+	}
+
+	newbuf := bytes.NewBuffer(nil)
+	if err := png.Encode(newbuf, resizeEmoji(img, config.Width, config.Height)); err != nil {
+		return nil, model.NewAppError("uploadEmojiImage", "api.emoji.upload.large_image.encode_error", nil, "", http.StatusBadRequest)
+	}
+
+	return newbuf.Bytes(), nil
+}
+
+func checkEmojiFrameCount(frames int) *model.AppError {
+	if maxFrames := *Config().ServiceSettings.MaxEmojiFrames; frames > maxFrames {
+		return model.NewAppError("uploadEmojiImage", "api.emoji.upload.frames.app_error", map[string]interface{}{"MaxFrames": maxFrames}, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
+// resizeEmojiGif shrinks every frame of a gif. Frames can be smaller than the gif and rely on the frames
+// before them for the rest of the image, so each frame is drawn over the previous ones as the gif's disposal
+// methods describe and the whole image is resized. The resized frames then each replace the entire image.
 func resizeEmojiGif(gifImg *gif.GIF) *gif.GIF {
-	// Create a new RGBA image to hold the incremental frames.
-	firstFrame := gifImg.Image[0].Bounds()
-	b := image.Rect(0, 0, firstFrame.Dx(), firstFrame.Dy())
-	img := image.NewRGBA(b)
+	bounds := image.Rect(0, 0, gifImg.Config.Width, gifImg.Config.Height)
+	if bounds.Empty() {
+		bounds = gifImg.Image[0].Bounds()
+	}
+
+	canvas := image.NewRGBA(bounds)
+	var previous *image.RGBA
 
 	resizedImage := image.Image(nil)
-	// Resize each frame.
 	for index, frame := range gifImg.Image {
-		bounds := frame.Bounds()
-		draw.Draw(img, bounds, frame, bounds.Min, draw.Over)
-		resizedImage = resizeEmoji(img, firstFrame.Dx(), firstFrame.Dy())
+		disposal := byte(gif.DisposalNone)
+		if index < len(gifImg.Disposal) {
+			disposal = gifImg.Disposal[index]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, bounds.Min, draw.Src)
+		}
+
+		frameBounds := frame.Bounds()
+		draw.Draw(canvas, frameBounds, frame, frameBounds.Min, draw.Over)
+
+		resizedImage = resizeEmoji(canvas, bounds.Dx(), bounds.Dy())
 		gifImg.Image[index] = imageToPaletted(resizedImage)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frameBounds, image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
 	}
+
+	// Each frame is now the entire image, so it has to be cleared before the next one is drawn
+	gifImg.Disposal = make([]byte, len(gifImg.Image))
+	for index := range gifImg.Disposal {
+		gifImg.Disposal[index] = gif.DisposalBackground
+	}
+
 	// Set new gif width and height
 	gifImg.Config.Width = resizedImage.Bounds().Dx()
 	gifImg.Config.Height = resizedImage.Bounds().Dy()
+	gifImg.BackgroundIndex = 0
 	return gifImg
 }
 
@@ -156,9 +399,13 @@ func resizeEmoji(img image.Image, width int, height int) image.Image {
 	return emoji
 }
 
+// emojiPalette is used for resized gif frames. Its first color is transparent so that transparent parts of the
+// frames stay transparent.
+var emojiPalette = append(color.Palette{color.Transparent}, palette.Plan9[:len(palette.Plan9)-1]...)
+
 func imageToPaletted(img image.Image) *image.Paletted {
 	b := img.Bounds()
-	pm := image.NewPaletted(b, palette.Plan9)
+	pm := image.NewPaletted(b, emojiPalette)
 	draw.FloydSteinberg.Draw(pm, b, img, image.ZP)
 	return pm
 }
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	EMOJI_USAGE_CLEANUP_TASK_NAME     = "Emoji Usage Cleanup"
+	EMOJI_USAGE_CLEANUP_TASK_INTERVAL = 24 * time.Hour
+)
+
+func InitEmojiUsage() {
+	if task := model.GetTaskByName(EMOJI_USAGE_CLEANUP_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(EMOJI_USAGE_CLEANUP_TASK_NAME, CleanupEmojiUsage, EMOJI_USAGE_CLEANUP_TASK_INTERVAL)
+}
+
+// RecordEmojiUsage counts a use of each of the given emoji towards the user's frequently used emoji.
+func RecordEmojiUsage(userId string, emojiNames []string) {
+	if len(emojiNames) == 0 {
+		return
+	}
+
+	if result := <-Srv.Store.EmojiUsage().Increment(userId, emojiNames, model.GetEmojiUsageDay(model.GetMillis())); result.Err != nil {
+		l4g.Error(utils.T("app.emoji_usage.record.error"), userId, result.Err)
+	}
+}
+
+// GetFrequentlyUsedEmoji returns the emoji the user reacted with or used in messages most often over
+// the last EMOJI_USAGE_WINDOW_DAYS days.
+func GetFrequentlyUsedEmoji(userId string, limit int) ([]*model.EmojiUsageCount, *model.AppError) {
+	since := model.GetEmojiUsageDay(model.GetMillis()) - (model.EMOJI_USAGE_WINDOW_DAYS-1)*model.EMOJI_USAGE_DAY_MILLIS
+
+	if result := <-Srv.Store.EmojiUsage().GetFrequent(userId, since, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.EmojiUsageCount), nil
+	}
+}
+
+// CleanupEmojiUsage removes the daily counts that have fallen out of the usage window.
+func CleanupEmojiUsage() {
+	before := model.GetEmojiUsageDay(model.GetMillis()) - (model.EMOJI_USAGE_WINDOW_DAYS-1)*model.EMOJI_USAGE_DAY_MILLIS
+
+	if result := <-Srv.Store.EmojiUsage().PermanentDeleteBefore(before); result.Err != nil {
+		l4g.Error(utils.T("app.emoji_usage.cleanup.error"), result.Err)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	EVENT_POLL_QUEUE_SIZE   = 256
+	EVENT_POLL_QUEUE_EXPIRY = 60 * time.Second
+	EVENT_POLL_TIMEOUT      = 30 * time.Second
+)
+
+// eventPollQueue keeps the recent events of a session for clients that long-poll for them because
+// they can use neither websockets nor server-sent events. The queue is registered with its hub like
+// any other connection and lasts until its session stops polling for EVENT_POLL_QUEUE_EXPIRY. Since
+// queues are kept in memory, the polls of a session have to reach the same server.
+type eventPollQueue struct {
+	webCon       *WebConn
+	mutex        sync.Mutex
+	events       []*model.WebSocketEvent
+	nextSequence int64
+	notify       chan bool
+	lastPollAt   time.Time
+	closed       bool
+}
+
+var eventPollQueues = make(map[string]*eventPollQueue)
+var eventPollQueuesMutex sync.Mutex
+
+func newEventPollQueue(webCon *WebConn) *eventPollQueue {
+	return &eventPollQueue{
+		webCon:     webCon,
+		notify:     make(chan bool),
+		lastPollAt: time.Now(),
+	}
+}
+
+// PollEvents returns the events of a session that come after the given sequence number, waiting up
+// to EVENT_POLL_TIMEOUT for one if there aren't any yet or until done is closed. A sequence number
+// of -1 asks for all of the events still queued. If the session's queue expired since the client's
+// last poll, the events of the new queue are returned from its hello event, which has a new
+// connection id. Like a websocket client that couldn't resume its connection, the client then has
+// to fetch its data again.
+func PollEvents(session *model.Session, since int64, done <-chan struct{}) []*model.WebSocketEvent {
+	queue := getEventPollQueue(session)
+
+	timeout := time.NewTimer(EVENT_POLL_TIMEOUT)
+	defer timeout.Stop()
+
+	for {
+		events, notify := queue.getSince(since)
+		if len(events) > 0 || notify == nil {
+			return events
+		}
+
+		select {
+		case <-notify:
+		case <-timeout.C:
+			return events
+		case <-done:
+			return events
+		}
+	}
+}
+
+func getEventPollQueue(session *model.Session) *eventPollQueue {
+	eventPollQueuesMutex.Lock()
+	defer eventPollQueuesMutex.Unlock()
+
+	if queue, ok := eventPollQueues[session.Id]; ok {
+		return queue
+	}
+
+	webCon := NewWebConn(nil, *session, utils.T, "")
+
+	// The queue keeps the events until they're polled, so the connection isn't resumed
+	webCon.resumable = false
+
+	queue := newEventPollQueue(webCon)
+	eventPollQueues[session.Id] = queue
+
+	HubRegister(webCon)
+	go queue.run(session.Id)
+
+	return queue
+}
+
+func removeEventPollQueue(sessionId string, queue *eventPollQueue) {
+	eventPollQueuesMutex.Lock()
+	if eventPollQueues[sessionId] == queue {
+		delete(eventPollQueues, sessionId)
+	}
+	eventPollQueuesMutex.Unlock()
+
+	queue.close()
+}
+
+// run queues the events sent to the queue's connection until the queue expires or the hub drops
+// the connection.
+func (q *eventPollQueue) run(sessionId string) {
+	ticker := time.NewTicker(EVENT_POLL_QUEUE_EXPIRY / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-q.webCon.Send:
+			if !ok {
+				removeEventPollQueue(sessionId, q)
+				return
+			}
+
+			if event, ok := msg.(*model.WebSocketEvent); ok {
+				q.add(event)
+			}
+
+		case <-ticker.C:
+			if q.expired() {
+				removeEventPollQueue(sessionId, q)
+
+				if len(hubs) != 0 {
+					HubUnregister(q.webCon)
+				}
+
+				return
+			}
+		}
+	}
+}
+
+func (q *eventPollQueue) add(event *model.WebSocketEvent) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.events) == EVENT_POLL_QUEUE_SIZE {
+		copy(q.events, q.events[1:])
+		q.events = q.events[:len(q.events)-1]
+	}
+
+	q.events = append(q.events, event)
+	q.nextSequence = event.Sequence + 1
+
+	close(q.notify)
+	q.notify = make(chan bool)
+}
+
+// getSince returns the queued events that come after the given sequence number, along with a
+// channel that is closed when another event is queued. The channel is nil once the queue is closed.
+func (q *eventPollQueue) getSince(since int64) ([]*model.WebSocketEvent, chan bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.lastPollAt = time.Now()
+
+	// The queue hasn't reached that sequence number, so it must have been given by an expired queue
+	if since >= q.nextSequence {
+		since = -1
+	}
+
+	events := []*model.WebSocketEvent{}
+	for _, event := range q.events {
+		if event.Sequence > since {
+			events = append(events, event)
+		}
+	}
+
+	if q.closed {
+		return events, nil
+	}
+
+	return events, q.notify
+}
+
+func (q *eventPollQueue) expired() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return time.Since(q.lastPollAt) > EVENT_POLL_QUEUE_EXPIRY
+}
+
+func (q *eventPollQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.notify)
+	}
+}
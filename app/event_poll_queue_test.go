@@ -0,0 +1,81 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func newTestPollEvent(sequence int64) *model.WebSocketEvent {
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", "", "", nil)
+	event.Sequence = sequence
+	return event
+}
+
+func TestEventPollQueue(t *testing.T) {
+	queue := newEventPollQueue(nil)
+
+	events, notify := queue.getSince(-1)
+	if len(events) != 0 || notify == nil {
+		t.Fatal("should have waited for the first event")
+	}
+
+	queue.add(newTestPollEvent(0))
+
+	select {
+	case <-notify:
+	default:
+		t.Fatal("should have been notified of the event")
+	}
+
+	queue.add(newTestPollEvent(1))
+	queue.add(newTestPollEvent(2))
+
+	if events, _ := queue.getSince(0); len(events) != 2 || events[0].Sequence != 1 || events[1].Sequence != 2 {
+		t.Fatal("should have returned the events after the sequence number", events)
+	}
+
+	if events, _ := queue.getSince(2); len(events) != 0 {
+		t.Fatal("shouldn't have returned any events", events)
+	}
+
+	// A sequence number the queue hasn't reached must come from an expired queue
+	if events, _ := queue.getSince(7); len(events) != 3 || events[0].Sequence != 0 {
+		t.Fatal("should have returned all of the events", events)
+	}
+
+	for i := int64(3); i < EVENT_POLL_QUEUE_SIZE+3; i++ {
+		queue.add(newTestPollEvent(i))
+	}
+
+	if events, _ := queue.getSince(-1); len(events) != EVENT_POLL_QUEUE_SIZE || events[0].Sequence != 3 {
+		t.Fatal("should have dropped the oldest events")
+	}
+
+	if queue.expired() {
+		t.Fatal("shouldn't have expired")
+	}
+
+	queue.lastPollAt = time.Now().Add(-EVENT_POLL_QUEUE_EXPIRY - time.Second)
+	if !queue.expired() {
+		t.Fatal("should have expired")
+	}
+
+	_, notify = queue.getSince(EVENT_POLL_QUEUE_SIZE + 2)
+	queue.close()
+	queue.close()
+
+	select {
+	case <-notify:
+	default:
+		t.Fatal("should have been notified that the queue closed")
+	}
+
+	if _, notify := queue.getSince(-1); notify != nil {
+		t.Fatal("shouldn't wait on a closed queue")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+)
+
+func GetExperiments() ([]*model.Experiment, *model.AppError) {
+	if result := <-Srv.Store.Experiment().GetAll(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Experiment), nil
+	}
+}
+
+func GetExperiment(name string) (*model.Experiment, *model.AppError) {
+	if result := <-Srv.Store.Experiment().Get(name); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Experiment), nil
+	}
+}
+
+func SaveExperiment(experiment *model.Experiment) (*model.Experiment, *model.AppError) {
+	if result := <-Srv.Store.Experiment().Save(experiment); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Experiment), nil
+	}
+}
+
+func DeleteExperiment(name string) *model.AppError {
+	if result := <-Srv.Store.Experiment().Delete(name); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetExperimentSummary(name string) (*model.ExperimentSummary, *model.AppError) {
+	if _, err := GetExperiment(name); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Experiment().GetSummary(name); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ExperimentSummary), nil
+	}
+}
+
+// GetExperimentVariantsForUser returns the variant the user is assigned to for every active
+// experiment the user takes part in.
+func GetExperimentVariantsForUser(userId string) (map[string]string, *model.AppError) {
+	experiments, err := GetExperiments()
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := EvaluateFeatureFlagsForUser(userId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make(map[string]string)
+	for _, experiment := range experiments {
+		if isUserInExperiment(experiment, flags) {
+			variants[experiment.Name] = experiment.GetVariant(userId)
+		}
+	}
+
+	return variants, nil
+}
+
+func isUserInExperiment(experiment *model.Experiment, flags map[string]bool) bool {
+	if !experiment.Active {
+		return false
+	}
+
+	return len(experiment.FlagName) == 0 || flags[experiment.FlagName]
+}
+
+// LogExperimentExposure records that the user was shown their variant of the experiment and reports
+// the exposure to the metrics server.
+func LogExperimentExposure(name string, userId string) (*model.ExperimentExposure, *model.AppError) {
+	experiment, err := GetExperiment(name)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := EvaluateFeatureFlagsForUser(userId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUserInExperiment(experiment, flags) {
+		return nil, model.NewAppError("LogExperimentExposure", "api.experiment.exposure.not_enrolled.app_error", nil, "name="+name+", user_id="+userId, http.StatusBadRequest)
+	}
+
+	exposure := &model.ExperimentExposure{
+		ExperimentName: experiment.Name,
+		UserId:         userId,
+		Variant:        experiment.GetVariant(userId),
+	}
+
+	if result := <-Srv.Store.Experiment().SaveExposure(exposure); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if metrics := einterfaces.GetMetricsInterface(); metrics != nil {
+		metrics.IncrementExperimentExposure(experiment.Name, exposure.Variant)
+	}
+
+	return exposure, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// GetFeatureFlags returns the flags defined in the config file combined with the ones stored in
+// the database, which take precedence.
+func GetFeatureFlags() ([]*model.FeatureFlag, *model.AppError) {
+	if result := <-Srv.Store.FeatureFlag().GetAll(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return model.MergeFeatureFlags(Config().FeatureFlagSettings.Flags, result.Data.([]*model.FeatureFlag)), nil
+	}
+}
+
+func SaveFeatureFlag(flag *model.FeatureFlag) (*model.FeatureFlag, *model.AppError) {
+	if result := <-Srv.Store.FeatureFlag().Save(flag); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.FeatureFlag), nil
+	}
+}
+
+func DeleteFeatureFlag(name string) *model.AppError {
+	if result := <-Srv.Store.FeatureFlag().Delete(name); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// EvaluateFeatureFlagsForUser returns the state of every flag for the user. When teamId is empty the
+// user's team memberships are used to match team allowlists.
+func EvaluateFeatureFlagsForUser(userId string, teamId string) (map[string]bool, *model.AppError) {
+	flags, err := GetFeatureFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	var teamIds []string
+	if len(teamId) > 0 {
+		teamIds = []string{teamId}
+	} else {
+		members, err := GetTeamMembersForUser(userId)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if member.DeleteAt == 0 {
+				teamIds = append(teamIds, member.TeamId)
+			}
+		}
+	}
+
+	return model.EvaluateFeatureFlags(flags, userId, teamIds), nil
+}
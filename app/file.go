@@ -26,7 +26,9 @@ import (
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/disintegration/imaging"
+	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
 	s3 "github.com/minio/minio-go"
 	"github.com/rwcarlsen/goexif/exif"
@@ -57,16 +59,16 @@ const (
 )
 
 func ReadFile(path string) ([]byte, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+	if Config().FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := Config().FileSettings.AmazonS3Endpoint
+		accessKey := Config().FileSettings.AmazonS3AccessKeyId
+		secretKey := Config().FileSettings.AmazonS3SecretAccessKey
+		secure := *Config().FileSettings.AmazonS3SSL
 		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
 		if err != nil {
 			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
 		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+		bucket := Config().FileSettings.AmazonS3Bucket
 		minioObject, err := s3Clnt.GetObject(bucket, path)
 		defer minioObject.Close()
 		if err != nil {
@@ -77,8 +79,8 @@ func ReadFile(path string) ([]byte, *model.AppError) {
 		} else {
 			return f, nil
 		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if f, err := ioutil.ReadFile(utils.Cfg.FileSettings.Directory + path); err != nil {
+	} else if Config().FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if f, err := ioutil.ReadFile(Config().FileSettings.Directory + path); err != nil {
 			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
 		} else {
 			return f, nil
@@ -89,16 +91,16 @@ func ReadFile(path string) ([]byte, *model.AppError) {
 }
 
 func MoveFile(oldPath, newPath string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+	if Config().FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := Config().FileSettings.AmazonS3Endpoint
+		accessKey := Config().FileSettings.AmazonS3AccessKeyId
+		secretKey := Config().FileSettings.AmazonS3SecretAccessKey
+		secure := *Config().FileSettings.AmazonS3SSL
 		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
 		if err != nil {
 			return model.NewLocAppError("moveFile", "api.file.write_file.s3.app_error", nil, err.Error())
 		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+		bucket := Config().FileSettings.AmazonS3Bucket
 
 		var copyConds = s3.NewCopyConditions()
 		if err = s3Clnt.CopyObject(bucket, newPath, "/"+path.Join(bucket, oldPath), copyConds); err != nil {
@@ -107,12 +109,12 @@ func MoveFile(oldPath, newPath string) *model.AppError {
 		if err = s3Clnt.RemoveObject(bucket, oldPath); err != nil {
 			return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
 		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+newPath), 0774); err != nil {
+	} else if Config().FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if err := os.MkdirAll(filepath.Dir(Config().FileSettings.Directory+newPath), 0774); err != nil {
 			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
 		}
 
-		if err := os.Rename(utils.Cfg.FileSettings.Directory+oldPath, utils.Cfg.FileSettings.Directory+newPath); err != nil {
+		if err := os.Rename(Config().FileSettings.Directory+oldPath, Config().FileSettings.Directory+newPath); err != nil {
 			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
 		}
 	} else {
@@ -122,17 +124,44 @@ func MoveFile(oldPath, newPath string) *model.AppError {
 	return nil
 }
 
+// RemoveFile deletes a file from the file store. A file that doesn't exist is ignored.
+func RemoveFile(path string) *model.AppError {
+	if Config().FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := Config().FileSettings.AmazonS3Endpoint
+		accessKey := Config().FileSettings.AmazonS3AccessKeyId
+		secretKey := Config().FileSettings.AmazonS3SecretAccessKey
+		secure := *Config().FileSettings.AmazonS3SSL
+		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
+		if err != nil {
+			return model.NewLocAppError("RemoveFile", "api.file.write_file.s3.app_error", nil, err.Error())
+		}
+		bucket := Config().FileSettings.AmazonS3Bucket
+
+		if err = s3Clnt.RemoveObject(bucket, path); err != nil {
+			return model.NewLocAppError("RemoveFile", "api.file.remove_file.delete_from_s3.app_error", nil, err.Error())
+		}
+	} else if Config().FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if err := os.Remove(Config().FileSettings.Directory + path); err != nil && !os.IsNotExist(err) {
+			return model.NewLocAppError("RemoveFile", "api.file.remove_file.removing.app_error", nil, err.Error())
+		}
+	} else {
+		return model.NewLocAppError("RemoveFile", "api.file.remove_file.configured.app_error", nil, "")
+	}
+
+	return nil
+}
+
 func WriteFile(f []byte, path string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
+	if Config().FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+		endpoint := Config().FileSettings.AmazonS3Endpoint
+		accessKey := Config().FileSettings.AmazonS3AccessKeyId
+		secretKey := Config().FileSettings.AmazonS3SecretAccessKey
+		secure := *Config().FileSettings.AmazonS3SSL
 		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
 		if err != nil {
 			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
 		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+		bucket := Config().FileSettings.AmazonS3Bucket
 		ext := filepath.Ext(path)
 
 		if model.IsFileExtImage(ext) {
@@ -143,8 +172,8 @@ func WriteFile(f []byte, path string) *model.AppError {
 		if err != nil {
 			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
 		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := writeFileLocally(f, utils.Cfg.FileSettings.Directory+path); err != nil {
+	} else if Config().FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if err := writeFileLocally(f, Config().FileSettings.Directory+path); err != nil {
 			return err
 		}
 	} else {
@@ -168,14 +197,14 @@ func writeFileLocally(f []byte, path string) *model.AppError {
 }
 
 func openFileWriteStream(path string) (io.Writer, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
+	if Config().FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
 		return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.s3.app_error", nil, "")
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+path), 0774); err != nil {
+	} else if Config().FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
+		if err := os.MkdirAll(filepath.Dir(Config().FileSettings.Directory+path), 0774); err != nil {
 			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.creating_dir.app_error", nil, err.Error())
 		}
 
-		if fileHandle, err := os.Create(utils.Cfg.FileSettings.Directory + path); err != nil {
+		if fileHandle, err := os.Create(Config().FileSettings.Directory + path); err != nil {
 			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.local_server.app_error", nil, err.Error())
 		} else {
 			fileHandle.Chmod(0644)
@@ -221,6 +250,8 @@ func GetInfoForFilename(post *model.Post, teamId string, filename string) *model
 		if err != nil {
 			l4g.Warn(utils.T("api.file.migrate_filenames_to_file_infos.info.app_error"), post.Id, filename, err)
 		}
+
+		extractFileContent(info, data)
 	}
 
 	// Generate a new ID because with the old system, you could very rarely get multiple posts referencing the same file
@@ -359,12 +390,12 @@ func MigrateFilenamesToFileInfos(post *model.Post) []*model.FileInfo {
 }
 
 func GeneratePublicLink(siteURL string, info *model.FileInfo) string {
-	hash := GeneratePublicLinkHash(info.Id, *utils.Cfg.FileSettings.PublicLinkSalt)
+	hash := GeneratePublicLinkHash(info.Id, *Config().FileSettings.PublicLinkSalt)
 	return fmt.Sprintf("%s/files/%v/public?h=%s", siteURL, info.Id, hash)
 }
 
 func GeneratePublicLinkV3(siteURL string, info *model.FileInfo) string {
-	hash := GeneratePublicLinkHash(info.Id, *utils.Cfg.FileSettings.PublicLinkSalt)
+	hash := GeneratePublicLinkHash(info.Id, *Config().FileSettings.PublicLinkSalt)
 	return fmt.Sprintf("%s%s/public/files/%v/get?h=%s", siteURL, model.API_URL_SUFFIX_V3, info.Id, hash)
 }
 
@@ -377,7 +408,7 @@ func GeneratePublicLinkHash(fileId, salt string) string {
 }
 
 func UploadFiles(teamId string, channelId string, userId string, fileHeaders []*multipart.FileHeader, clientIds []string) (*model.FileUploadResponse, *model.AppError) {
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(Config().FileSettings.DriverName) == 0 {
 		return nil, model.NewAppError("uploadFile", "api.file.upload_file.storage.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -436,6 +467,8 @@ func DoUploadFile(teamId string, channelId string, userId string, rawFilename st
 	info.Id = model.NewId()
 	info.CreatorId = userId
 
+	extractFileContent(info, data)
+
 	pathPrefix := "teams/" + teamId + "/channels/" + channelId + "/users/" + userId + "/" + info.Id + "/"
 	info.Path = pathPrefix + filename
 
@@ -452,6 +485,12 @@ func DoUploadFile(teamId string, channelId string, userId string, rawFilename st
 		info.ThumbnailPath = pathPrefix + nameWithoutExtension + "_thumb.jpg"
 	}
 
+	if len(teamId) > 0 {
+		if err := checkTeamStorageQuota(teamId, info.Size); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := WriteFile(data, info.Path); err != nil {
 		return nil, err
 	}
@@ -460,9 +499,76 @@ func DoUploadFile(teamId string, channelId string, userId string, rawFilename st
 		return nil, result.Err
 	}
 
+	if len(teamId) > 0 {
+		incrementTeamStorageBytes(teamId, info.Size)
+	}
+
 	return info, nil
 }
 
+// extractFileContent fills in the text of the file so that it can be found by searching. A file
+// that can't be read is still uploaded, it just can't be found by its content.
+func extractFileContent(info *model.FileInfo, data []byte) {
+	if !*Config().FileSettings.ExtractContent {
+		return
+	}
+
+	if content, err := utils.ExtractFileContent(info.Name, data); err != nil {
+		l4g.Warn(utils.T("api.file.extract_content.warn"), info.Name, err.Error())
+	} else {
+		info.Content = content
+	}
+}
+
+// SearchFilesInTeam returns the files in the user's channels on the team whose name or content
+// matches the search terms.
+func SearchFilesInTeam(terms string, userId string, teamId string, isOrSearch bool) ([]*model.FileInfo, *model.AppError) {
+	paramsList := []*model.SearchParams{}
+	for _, params := range model.ParseSearchParams(terms) {
+		// Files don't have hashtags
+		if !params.IsHashtag {
+			params.OrTerms = isOrSearch
+			paramsList = append(paramsList, params)
+		}
+	}
+
+	// Fall back to searching the database if the search engine fails
+	if engine := einterfaces.GetSearchEngineInterface(); engine != nil && engine.IsSearchingEnabled() {
+		if infos, err := searchFilesInTeamWithEngine(engine, paramsList, userId, teamId); err != nil {
+			l4g.Warn(utils.T("app.search_engine.search_files.warn"), err.Error())
+		} else {
+			return infos, nil
+		}
+	}
+
+	channels := []store.StoreChannel{}
+
+	for _, params := range paramsList {
+		// don't allow users to search for everything
+		if params.Terms != "*" {
+			channels = append(channels, Srv.Store.FileInfo().Search(teamId, userId, params))
+		}
+	}
+
+	infos := []*model.FileInfo{}
+	found := map[string]bool{}
+
+	for _, channel := range channels {
+		if result := <-channel; result.Err != nil {
+			return nil, result.Err
+		} else {
+			for _, info := range result.Data.([]*model.FileInfo) {
+				if !found[info.Id] {
+					found[info.Id] = true
+					infos = append(infos, info)
+				}
+			}
+		}
+	}
+
+	return infos, nil
+}
+
 func HandleImages(previewPathList []string, thumbnailPathList []string, fileData [][]byte) {
 	for i, data := range fileData {
 		go func(i int, data []byte) {
@@ -535,8 +641,8 @@ func getImageOrientation(imageData []byte) (int, error) {
 }
 
 func generateThumbnailImage(img image.Image, thumbnailPath string, width int, height int) {
-	thumbWidth := float64(utils.Cfg.FileSettings.ThumbnailWidth)
-	thumbHeight := float64(utils.Cfg.FileSettings.ThumbnailHeight)
+	thumbWidth := float64(Config().FileSettings.ThumbnailWidth)
+	thumbHeight := float64(Config().FileSettings.ThumbnailHeight)
 	imgWidth := float64(width)
 	imgHeight := float64(height)
 
@@ -544,9 +650,9 @@ func generateThumbnailImage(img image.Image, thumbnailPath string, width int, he
 	if imgHeight < thumbHeight && imgWidth < thumbWidth {
 		thumbnail = img
 	} else if imgHeight/imgWidth < thumbHeight/thumbWidth {
-		thumbnail = imaging.Resize(img, 0, utils.Cfg.FileSettings.ThumbnailHeight, imaging.Lanczos)
+		thumbnail = imaging.Resize(img, 0, Config().FileSettings.ThumbnailHeight, imaging.Lanczos)
 	} else {
-		thumbnail = imaging.Resize(img, utils.Cfg.FileSettings.ThumbnailWidth, 0, imaging.Lanczos)
+		thumbnail = imaging.Resize(img, Config().FileSettings.ThumbnailWidth, 0, imaging.Lanczos)
 	}
 
 	buf := new(bytes.Buffer)
@@ -563,8 +669,8 @@ func generateThumbnailImage(img image.Image, thumbnailPath string, width int, he
 
 func generatePreviewImage(img image.Image, previewPath string, width int) {
 	var preview image.Image
-	if width > int(utils.Cfg.FileSettings.PreviewWidth) {
-		preview = imaging.Resize(img, utils.Cfg.FileSettings.PreviewWidth, utils.Cfg.FileSettings.PreviewHeight, imaging.Lanczos)
+	if width > int(Config().FileSettings.PreviewWidth) {
+		preview = imaging.Resize(img, Config().FileSettings.PreviewWidth, Config().FileSettings.PreviewHeight, imaging.Lanczos)
 	} else {
 		preview = img
 	}
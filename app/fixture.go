@@ -0,0 +1,136 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/platform/model"
+)
+
+var fixtureReactionEmojiNames = []string{"+1", "smile", "tada", "heart", "eyes", "rocket", "100", "laughing", "wave", "-1"}
+
+// CreateFixtures creates the users, teams, channels, posts and reactions described by the request
+// through the same code paths used by the API. Every user joins every team and channel, posts are
+// written by the users in turn and reactions are added by the users following the post's author.
+func CreateFixtures(request *model.FixtureRequest) (*model.FixtureResult, *model.AppError) {
+	request.SetDefaults()
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+
+	result := &model.FixtureResult{
+		Teams:    []*model.Team{},
+		Users:    []*model.User{},
+		Channels: []*model.Channel{},
+	}
+
+	for i := 0; i < request.Users; i++ {
+		user := &model.User{
+			Email:         fmt.Sprintf("success+%v-user-%v@simulator.amazonses.com", request.Prefix, i),
+			Username:      fmt.Sprintf("%v-user-%v", request.Prefix, i),
+			Nickname:      fmt.Sprintf("%v User %v", request.Prefix, i),
+			Password:      request.Password,
+			EmailVerified: true,
+		}
+
+		if ruser, err := CreateUser(user); err != nil {
+			return nil, err
+		} else {
+			result.Users = append(result.Users, ruser)
+		}
+	}
+
+	for i := 0; i < request.Teams; i++ {
+		team := &model.Team{
+			DisplayName: fmt.Sprintf("%v Team %v", request.Prefix, i),
+			Name:        fmt.Sprintf("%v-team-%v", request.Prefix, i),
+			Email:       fmt.Sprintf("success+%v-team-%v@simulator.amazonses.com", request.Prefix, i),
+			Type:        model.TEAM_OPEN,
+		}
+
+		rteam, err := CreateTeam(team)
+		if err != nil {
+			return nil, err
+		}
+		result.Teams = append(result.Teams, rteam)
+
+		for _, user := range result.Users {
+			if err := JoinUserToTeam(rteam, user, ""); err != nil {
+				return nil, err
+			}
+		}
+
+		for j := 0; j < request.ChannelsPerTeam; j++ {
+			channel, err := createFixtureChannel(request, rteam, j, result)
+			if err != nil {
+				return nil, err
+			}
+			result.Channels = append(result.Channels, channel)
+		}
+	}
+
+	return result, nil
+}
+
+func createFixtureChannel(request *model.FixtureRequest, team *model.Team, index int, result *model.FixtureResult) (*model.Channel, *model.AppError) {
+	channel := &model.Channel{
+		TeamId:      team.Id,
+		Name:        fmt.Sprintf("%v-channel-%v", request.Prefix, index),
+		DisplayName: fmt.Sprintf("%v Channel %v", request.Prefix, index),
+		Type:        model.CHANNEL_OPEN,
+	}
+
+	if len(result.Users) > 0 {
+		channel.CreatorId = result.Users[0].Id
+	}
+
+	rchannel, err := CreateChannel(channel, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range result.Users {
+		if _, err := AddUserToChannel(user, rchannel); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; i < request.PostsPerChannel; i++ {
+		author := i % len(result.Users)
+
+		post := &model.Post{
+			ChannelId: rchannel.Id,
+			UserId:    result.Users[author].Id,
+			Message:   fmt.Sprintf("%v message %v in %v", request.Prefix, i, rchannel.Name),
+			CreateAt:  request.StartAt + int64(i)*request.PostInterval,
+		}
+
+		rpost, err := CreatePost(post, team.Id, false)
+		if err != nil {
+			return nil, err
+		}
+		result.Posts++
+
+		for j := 0; j < request.ReactionsPerPost; j++ {
+			reaction := &model.Reaction{
+				UserId:    result.Users[(author+j+1)%len(result.Users)].Id,
+				PostId:    rpost.Id,
+				EmojiName: fixtureReactionEmojiNames[j],
+				CreateAt:  rpost.CreateAt,
+			}
+
+			if sresult := <-Srv.Store.Reaction().Save(reaction); sresult.Err != nil {
+				return nil, sresult.Err
+			}
+			result.Reactions++
+		}
+
+		if request.ReactionsPerPost > 0 {
+			InvalidateCacheForReactions(rpost.Id)
+		}
+	}
+
+	return rchannel, nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+)
+
+// AddGroupMessageParticipants adds users to a group message. A group message is named after its
+// members, so it's renamed, and it can't be changed to have the same members as another one.
+func AddGroupMessageParticipants(channel *model.Channel, userIds []string, userRequestorId string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_GROUP {
+		return nil, model.NewAppError("AddGroupMessageParticipants", "app.channel.group_message.not_group.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	memberIds, err := getGroupMessageMemberIds(channel.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := make(map[string]bool)
+	for _, memberId := range memberIds {
+		isMember[memberId] = true
+	}
+
+	isAdded := make(map[string]bool)
+	addedIds := []string{}
+	for _, userId := range userIds {
+		if !isMember[userId] && !isAdded[userId] {
+			isAdded[userId] = true
+			addedIds = append(addedIds, userId)
+		}
+	}
+
+	if len(addedIds) == 0 {
+		return channel, nil
+	}
+
+	if len(memberIds)+len(addedIds) > model.CHANNEL_GROUP_MAX_USERS {
+		return nil, model.NewAppError("AddGroupMessageParticipants", "app.channel.group_message.too_many.app_error", map[string]interface{}{"Max": model.CHANNEL_GROUP_MAX_USERS}, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	users, err := getGroupMessageUsers(append(memberIds, addedIds...))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.DeleteAt != 0 && isAdded[user.Id] {
+			return nil, model.NewAppError("AddGroupMessageParticipants", "api.channel.create_group.bad_user.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+		}
+	}
+
+	oldName, oldDisplayName := channel.Name, channel.DisplayName
+
+	rchannel, err := renameGroupMessage(channel, users)
+	if err != nil {
+		return nil, err
+	}
+
+	savedIds := []string{}
+	for _, user := range users {
+		if !isAdded[user.Id] {
+			continue
+		}
+
+		cm := &model.ChannelMember{
+			UserId:      user.Id,
+			ChannelId:   rchannel.Id,
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+			Roles:       getChannelMemberRoles(user),
+		}
+
+		if result := <-Srv.Store.Channel().SaveMember(cm); result.Err != nil {
+			// Undo the change so that the group message is still named after the members it has
+			removeGroupMessageMembers(rchannel.Id, savedIds)
+			restoreGroupMessageName(rchannel, oldName, oldDisplayName)
+			return nil, result.Err
+		}
+
+		savedIds = append(savedIds, user.Id)
+		InvalidateCacheForUser(user.Id)
+	}
+
+	InvalidateCacheForChannelMembers(rchannel.Id)
+
+	var requestor *model.User
+	for _, user := range users {
+		if user.Id == userRequestorId {
+			requestor = user
+		}
+	}
+
+	if requestor != nil {
+		for _, user := range users {
+			if !isAdded[user.Id] {
+				continue
+			}
+
+			if err := PostAddToChannelMessage(requestor, user, rchannel); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_GROUP_ADDED, "", rchannel.Id, "", nil)
+	message.Add("teammate_ids", model.ArrayToJson(append(memberIds, addedIds...)))
+	go Publish(message)
+
+	return rchannel, nil
+}
+
+// RemoveGroupMessageParticipant removes a user from a group message, which is renamed after its
+// remaining members. A group message needs at least three members, so it can't be made smaller than
+// that.
+func RemoveGroupMessageParticipant(channel *model.Channel, userId string, removerUserId string) (*model.Channel, *model.AppError) {
+	if channel.Type != model.CHANNEL_GROUP {
+		return nil, model.NewAppError("RemoveGroupMessageParticipant", "app.channel.group_message.not_group.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	memberIds, err := getGroupMessageMemberIds(channel.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingIds := []string{}
+	for _, memberId := range memberIds {
+		if memberId != userId {
+			remainingIds = append(remainingIds, memberId)
+		}
+	}
+
+	if len(remainingIds) == len(memberIds) {
+		return nil, model.NewAppError("RemoveGroupMessageParticipant", "app.channel.group_message.not_member.app_error", nil, "channel_id="+channel.Id+", user_id="+userId, http.StatusBadRequest)
+	}
+
+	if len(remainingIds) < model.CHANNEL_GROUP_MIN_USERS {
+		return nil, model.NewAppError("RemoveGroupMessageParticipant", "app.channel.group_message.too_few.app_error", map[string]interface{}{"Min": model.CHANNEL_GROUP_MIN_USERS}, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	users, err := getGroupMessageUsers(remainingIds)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName, oldDisplayName := channel.Name, channel.DisplayName
+
+	rchannel, err := renameGroupMessage(channel, users)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeUserFromChannel(userId, removerUserId, rchannel); err != nil {
+		restoreGroupMessageName(rchannel, oldName, oldDisplayName)
+		return nil, err
+	}
+
+	if removedUser, err := GetUser(userId); err != nil {
+		l4g.Error(err.Error())
+	} else if userId == removerUserId {
+		go postLeaveChannelMessage(removedUser, rchannel)
+	} else {
+		go PostRemoveFromChannelMessage(removerUserId, removedUser, rchannel)
+	}
+
+	return rchannel, nil
+}
+
+func getGroupMessageMemberIds(channelId string) ([]string, *model.AppError) {
+	var members *model.ChannelMembers
+	if result := <-Srv.Store.Channel().GetMembers(channelId, 0, model.CHANNEL_GROUP_MAX_USERS); result.Err != nil {
+		return nil, result.Err
+	} else {
+		members = result.Data.(*model.ChannelMembers)
+	}
+
+	memberIds := []string{}
+	for _, member := range *members {
+		memberIds = append(memberIds, member.UserId)
+	}
+
+	return memberIds, nil
+}
+
+func getGroupMessageUsers(userIds []string) ([]*model.User, *model.AppError) {
+	if result := <-Srv.Store.User().GetProfileByIds(userIds, true); result.Err != nil {
+		return nil, result.Err
+	} else if users := result.Data.([]*model.User); len(users) != len(userIds) {
+		return nil, model.NewAppError("getGroupMessageUsers", "api.channel.create_group.bad_user.app_error", nil, "user_ids="+model.ArrayToJson(userIds), http.StatusBadRequest)
+	} else {
+		return users, nil
+	}
+}
+
+// renameGroupMessage gives a group message the name and display name it would have been created
+// with for the given members.
+func renameGroupMessage(channel *model.Channel, users []*model.User) (*model.Channel, *model.AppError) {
+	userIds := []string{}
+	for _, user := range users {
+		userIds = append(userIds, user.Id)
+	}
+
+	channel.Name = model.GetGroupNameFromUserIds(userIds)
+	channel.DisplayName = model.GetGroupDisplayNameFromUsers(users, true)
+
+	rchannel, err := UpdateChannel(channel)
+	if err != nil {
+		if err.Id == "store.sql_channel.update.exists.app_error" {
+			return nil, model.NewAppError("renameGroupMessage", "app.channel.group_message.exists.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+		}
+
+		return nil, err
+	}
+
+	return rchannel, nil
+}
+
+// restoreGroupMessageName gives a group message back the name it had before renameGroupMessage when
+// its members couldn't be changed.
+func restoreGroupMessageName(channel *model.Channel, name string, displayName string) {
+	channel.Name = name
+	channel.DisplayName = displayName
+
+	if _, err := UpdateChannel(channel); err != nil {
+		l4g.Error(err.Error())
+	}
+}
+
+// removeGroupMessageMembers removes the members that were added to a group message before the
+// rest of its change failed.
+func removeGroupMessageMembers(channelId string, userIds []string) {
+	for _, userId := range userIds {
+		if result := <-Srv.Store.Channel().RemoveMember(channelId, userId); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+
+		InvalidateCacheForUser(userId)
+	}
+
+	InvalidateCacheForChannelMembers(channelId)
+}
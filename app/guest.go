@@ -0,0 +1,149 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func getTeamMemberRoles(user *model.User) string {
+	if user.IsGuest() {
+		return model.ROLE_TEAM_GUEST.Id
+	}
+
+	return model.ROLE_TEAM_USER.Id
+}
+
+func getChannelMemberRoles(user *model.User) string {
+	if user.IsGuest() {
+		return model.ROLE_CHANNEL_GUEST.Id
+	}
+
+	return model.ROLE_CHANNEL_USER.Id
+}
+
+// GetUsersSharingChannelsPage returns the users of a team that share at least one channel with the given user.
+func GetUsersSharingChannelsPage(userId string, teamId string, page int, perPage int, asAdmin bool) ([]*model.User, *model.AppError) {
+	if result := <-Srv.Store.User().GetProfilesSharingChannels(userId, teamId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		users := result.Data.([]*model.User)
+
+		for _, user := range users {
+			SanitizeProfile(user, asAdmin)
+		}
+
+		return users, nil
+	}
+}
+
+func SearchUsersSharingChannels(userId string, term string, searchOptions map[string]bool, asAdmin bool) ([]*model.User, *model.AppError) {
+	if result := <-Srv.Store.User().SearchSharingChannels(userId, term, searchOptions); result.Err != nil {
+		return nil, result.Err
+	} else {
+		users := result.Data.([]*model.User)
+
+		for _, user := range users {
+			SanitizeProfile(user, asAdmin)
+		}
+
+		return users, nil
+	}
+}
+
+func InviteGuestsToChannels(teamId string, invite *model.GuestsInvite, senderId string) *model.AppError {
+	if !*Config().TeamSettings.EnableGuestAccounts {
+		return model.NewAppError("InviteGuestsToChannels", "api.team.invite_guests.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	tchan := Srv.Store.Team().Get(teamId)
+	uchan := Srv.Store.User().Get(senderId)
+
+	var team *model.Team
+	if result := <-tchan; result.Err != nil {
+		return result.Err
+	} else {
+		team = result.Data.(*model.Team)
+	}
+
+	var user *model.User
+	if result := <-uchan; result.Err != nil {
+		return result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	channels := make([]*model.Channel, 0, len(invite.Channels))
+	for _, channelId := range invite.Channels {
+		channel, err := GetChannel(channelId)
+		if err != nil {
+			return err
+		}
+
+		if channel.TeamId != team.Id || channel.Type == model.CHANNEL_DIRECT || channel.Type == model.CHANNEL_GROUP {
+			return model.NewAppError("InviteGuestsToChannels", "api.team.invite_guests.channel.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+		}
+
+		channels = append(channels, channel)
+	}
+
+	SendGuestInviteEmails(team, channels, user.GetDisplayName(), user.Id, invite.Emails, utils.GetSiteURL())
+
+	return nil
+}
+
+// PromoteGuestToUser gives a guest the regular roles on the system and on all of their teams and channels.
+func PromoteGuestToUser(userId string) (*model.User, *model.AppError) {
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsGuest() {
+		return nil, model.NewAppError("PromoteGuestToUser", "app.user.promote_guest.not_guest.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	return updateUserGuestRoles(userId, model.ROLE_SYSTEM_USER.Id, model.ROLE_TEAM_USER.Id, model.ROLE_CHANNEL_USER.Id)
+}
+
+// DemoteUserToGuest restricts a user to the channels they are already a member of.
+func DemoteUserToGuest(userId string) (*model.User, *model.AppError) {
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsGuest() {
+		return nil, model.NewAppError("DemoteUserToGuest", "app.user.demote_user.already_guest.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	if user.IsInRole(model.ROLE_SYSTEM_ADMIN.Id) {
+		return nil, model.NewAppError("DemoteUserToGuest", "app.user.demote_user.system_admin.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	return updateUserGuestRoles(userId, model.ROLE_SYSTEM_GUEST.Id, model.ROLE_TEAM_GUEST.Id, model.ROLE_CHANNEL_GUEST.Id)
+}
+
+func updateUserGuestRoles(userId string, systemRoles string, teamRoles string, channelRoles string) (*model.User, *model.AppError) {
+	user, err := UpdateUserRoles(userId, systemRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Team().UpdateMembersRolesForUser(userId, teamRoles); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if result := <-Srv.Store.Channel().UpdateMembersRolesForUser(userId, channelRoles); result.Err != nil {
+		return nil, result.Err
+	}
+
+	InvalidateCacheForUser(userId)
+	ClearSessionCacheForUser(userId)
+
+	return user, nil
+}
@@ -8,7 +8,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"unicode/utf8"
@@ -93,8 +95,39 @@ type PostImportData struct {
 
 	Message  *string `json:"message"`
 	CreateAt *int64  `json:"create_at"`
+
+	Reactions   *[]ReactionImportData   `json:"reactions"`
+	Attachments *[]AttachmentImportData `json:"attachments"`
+}
+
+type ReactionImportData struct {
+	User      *string `json:"user"`
+	EmojiName *string `json:"emoji_name"`
+	CreateAt  *int64  `json:"create_at"`
+}
+
+type AttachmentImportData struct {
+	Path *string `json:"path"`
 }
 
+// BulkImportLineError is an error with one of the lines of a bulk import file.
+type BulkImportLineError struct {
+	LineNumber int
+	Err        *model.AppError
+}
+
+// BulkImportCheckpoint records how far an import got so that it can be resumed from there.
+type BulkImportCheckpoint struct {
+	LineNumber int `json:"line_number"`
+}
+
+const (
+	// As many file ids as fit in a post
+	POST_IMPORT_MAX_ATTACHMENTS = 5
+
+	BULK_IMPORT_CHECKPOINT_INTERVAL = 100
+)
+
 //
 // -- Bulk Import Functions --
 // These functions import data directly into the database. Security and permission checks are bypassed but validity is
@@ -102,26 +135,28 @@ type PostImportData struct {
 //
 
 func BulkImport(fileReader io.Reader, dryRun bool) (*model.AppError, int) {
+	return bulkImport(fileReader, dryRun, 0, nil)
+}
+
+// bulkImport imports the lines of a file, stopping at the first line that fails. The version line
+// is always read but the other lines up to skipLines are skipped. If afterLine is set, it's called
+// with the number of each line once it has been imported.
+func bulkImport(fileReader io.Reader, dryRun bool, skipLines int, afterLine func(lineNumber int) *model.AppError) (*model.AppError, int) {
 	scanner := bufio.NewScanner(fileReader)
 	lineNumber := 0
 	for scanner.Scan() {
-		decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
 		lineNumber++
 
-		var line LineImportData
-		if err := decoder.Decode(&line); err != nil {
-			return model.NewLocAppError("BulkImport", "app.import.bulk_import.json_decode.error", nil, err.Error()), lineNumber
-		} else {
-			if lineNumber == 1 {
-				importDataFileVersion, apperr := processImportDataFileVersionLine(line)
-				if apperr != nil {
-					return apperr, lineNumber
-				}
+		if lineNumber != 1 && lineNumber <= skipLines {
+			continue
+		}
 
-				if importDataFileVersion != 1 {
-					return model.NewAppError("BulkImport", "app.import.bulk_import.unsupported_version.error", nil, "", http.StatusBadRequest), lineNumber
-				}
-			} else if err := ImportLine(line, dryRun); err != nil {
+		if err := importLineText(scanner.Text(), lineNumber, dryRun); err != nil {
+			return err, lineNumber
+		}
+
+		if afterLine != nil && lineNumber > skipLines {
+			if err := afterLine(lineNumber); err != nil {
 				return err, lineNumber
 			}
 		}
@@ -134,6 +169,131 @@ func BulkImport(fileReader io.Reader, dryRun bool) (*model.AppError, int) {
 	return nil, 0
 }
 
+// ValidateBulkImport checks every line of a file without importing anything and returns the errors
+// with each line that isn't valid. It doesn't stop at the first invalid line so that all of the
+// problems with a file can be fixed at once.
+func ValidateBulkImport(fileReader io.Reader) ([]*BulkImportLineError, *model.AppError) {
+	lineErrors := []*BulkImportLineError{}
+
+	scanner := bufio.NewScanner(fileReader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		if err := importLineText(scanner.Text(), lineNumber, true); err != nil {
+			lineErrors = append(lineErrors, &BulkImportLineError{LineNumber: lineNumber, Err: err})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return lineErrors, model.NewLocAppError("BulkImport", "app.import.bulk_import.file_scan.error", nil, err.Error())
+	}
+
+	return lineErrors, nil
+}
+
+// ResumableBulkImport imports a file like BulkImport does while recording the last line that was
+// imported in a checkpoint file. If the checkpoint file already exists, the import carries on after
+// the line that it records. The checkpoint file is removed once the whole file has been imported.
+func ResumableBulkImport(fileReader io.Reader, checkpointPath string) (*model.AppError, int) {
+	checkpoint, err := ReadBulkImportCheckpoint(checkpointPath)
+	if err != nil {
+		return err, 0
+	}
+
+	lastLine := checkpoint.LineNumber
+
+	err, lineNumber := bulkImport(fileReader, false, checkpoint.LineNumber, func(lineNumber int) *model.AppError {
+		lastLine = lineNumber
+
+		if lineNumber%BULK_IMPORT_CHECKPOINT_INTERVAL == 0 {
+			return WriteBulkImportCheckpoint(checkpointPath, &BulkImportCheckpoint{LineNumber: lineNumber})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if lastLine > checkpoint.LineNumber {
+			if werr := WriteBulkImportCheckpoint(checkpointPath, &BulkImportCheckpoint{LineNumber: lastLine}); werr != nil {
+				l4g.Error(werr.Error())
+			}
+		}
+
+		return err, lineNumber
+	}
+
+	if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		return model.NewAppError("ResumableBulkImport", "app.import.checkpoint.remove.error", nil, err.Error(), http.StatusInternalServerError), 0
+	}
+
+	return nil, 0
+}
+
+// ReadBulkImportCheckpoint reads a checkpoint file. A checkpoint at the start of the file is
+// returned if the checkpoint file doesn't exist.
+func ReadBulkImportCheckpoint(path string) (*BulkImportCheckpoint, *model.AppError) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &BulkImportCheckpoint{}, nil
+	} else if err != nil {
+		return nil, model.NewAppError("ReadBulkImportCheckpoint", "app.import.checkpoint.read.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	var checkpoint BulkImportCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil || checkpoint.LineNumber < 0 {
+		return nil, model.NewAppError("ReadBulkImportCheckpoint", "app.import.checkpoint.invalid.error", nil, "path="+path, http.StatusBadRequest)
+	}
+
+	return &checkpoint, nil
+}
+
+// WriteBulkImportCheckpoint writes a checkpoint file. The checkpoint is written to a temporary file
+// first so that the existing checkpoint isn't lost if the import is stopped while it's written.
+func WriteBulkImportCheckpoint(path string, checkpoint *BulkImportCheckpoint) *model.AppError {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return model.NewAppError("WriteBulkImportCheckpoint", "app.import.checkpoint.write.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return model.NewAppError("WriteBulkImportCheckpoint", "app.import.checkpoint.write.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return model.NewAppError("WriteBulkImportCheckpoint", "app.import.checkpoint.write.error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// importLineText decodes and imports a single line of a file, which must be the version line if
+// it's the first one.
+func importLineText(text string, lineNumber int, dryRun bool) *model.AppError {
+	decoder := json.NewDecoder(strings.NewReader(text))
+
+	var line LineImportData
+	if err := decoder.Decode(&line); err != nil {
+		return model.NewLocAppError("BulkImport", "app.import.bulk_import.json_decode.error", nil, err.Error())
+	}
+
+	if lineNumber == 1 {
+		importDataFileVersion, apperr := processImportDataFileVersionLine(line)
+		if apperr != nil {
+			return apperr
+		}
+
+		if importDataFileVersion != 1 {
+			return model.NewAppError("BulkImport", "app.import.bulk_import.unsupported_version.error", nil, "", http.StatusBadRequest)
+		}
+
+		return nil
+	}
+
+	return ImportLine(line, dryRun)
+}
+
 func processImportDataFileVersionLine(line LineImportData) (int, *model.AppError) {
 	if line.Type != "version" || line.Version == nil {
 		return -1, model.NewAppError("BulkImport", "app.import.process_import_data_file_version_line.invalid_version.error", nil, "", http.StatusBadRequest)
@@ -398,7 +558,7 @@ func ImportUser(data *UserImportData, dryRun bool) *model.AppError {
 	if data.Locale != nil {
 		user.Locale = *data.Locale
 	} else {
-		user.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
+		user.Locale = *Config().LocalizationSettings.DefaultClientLocale
 	}
 
 	var roles string
@@ -772,16 +932,93 @@ func ImportPost(data *PostImportData, dryRun bool) *model.AppError {
 
 	post.Hashtags, _ = model.ParseHashtags(post.Message)
 
+	// The attachments of a post that was already imported aren't uploaded again so that an import
+	// can be run more than once.
+	var attachments []*model.FileInfo
+	if data.Attachments != nil && len(post.FileIds) == 0 {
+		for _, adata := range *data.Attachments {
+			info, err := importAttachment(&adata, team, channel, user)
+			if err != nil {
+				return err
+			}
+
+			attachments = append(attachments, info)
+			post.FileIds = append(post.FileIds, info.Id)
+		}
+	}
+
 	if post.Id == "" {
 		if result := <-Srv.Store.Post().Save(post); result.Err != nil {
 			return result.Err
 		}
+
+		recordPostIntegrity(post)
 	} else {
 		if result := <-Srv.Store.Post().Overwrite(post); result.Err != nil {
 			return result.Err
 		}
 	}
 
+	for _, info := range attachments {
+		if result := <-Srv.Store.FileInfo().AttachToPost(info.Id, post.Id); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	if data.Reactions != nil {
+		for _, rdata := range *data.Reactions {
+			if err := importReaction(&rdata, post); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// importAttachment uploads a file from the machine running the import as if the user had uploaded it.
+func importAttachment(data *AttachmentImportData, team *model.Team, channel *model.Channel, user *model.User) (*model.FileInfo, *model.AppError) {
+	fileData, err := ioutil.ReadFile(*data.Path)
+	if err != nil {
+		return nil, model.NewAppError("BulkImport", "app.import.import_attachment.read.error", map[string]interface{}{"Path": *data.Path}, err.Error(), http.StatusBadRequest)
+	}
+
+	info, appErr := DoUploadFile(team.Id, channel.Id, user.Id, *data.Path, fileData)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if info.IsImage() {
+		HandleImages([]string{info.PreviewPath}, []string{info.ThumbnailPath}, [][]byte{fileData})
+	}
+
+	return info, nil
+}
+
+// importReaction adds a reaction to a post. Reactions that already exist are left as they are.
+func importReaction(data *ReactionImportData, post *model.Post) *model.AppError {
+	var user *model.User
+	if result := <-Srv.Store.User().GetByUsername(*data.User); result.Err != nil {
+		return model.NewAppError("BulkImport", "app.import.import_post.user_not_found.error", map[string]interface{}{"Username": *data.User}, "", http.StatusBadRequest)
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	reaction := &model.Reaction{
+		UserId:    user.Id,
+		PostId:    post.Id,
+		EmojiName: *data.EmojiName,
+		CreateAt:  post.CreateAt,
+	}
+
+	if data.CreateAt != nil {
+		reaction.CreateAt = *data.CreateAt
+	}
+
+	if result := <-Srv.Store.Reaction().Save(reaction); result.Err != nil {
+		return result.Err
+	}
+
 	return nil
 }
 
@@ -810,6 +1047,52 @@ func validatePostImportData(data *PostImportData) *model.AppError {
 		return model.NewAppError("BulkImport", "app.import.validate_post_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
 	}
 
+	if data.Reactions != nil {
+		for _, rdata := range *data.Reactions {
+			if err := validateReactionImportData(&rdata); err != nil {
+				return err
+			}
+		}
+	}
+
+	if data.Attachments != nil {
+		if len(*data.Attachments) > POST_IMPORT_MAX_ATTACHMENTS {
+			return model.NewAppError("BulkImport", "app.import.validate_post_import_data.attachments_count.error", map[string]interface{}{"Max": POST_IMPORT_MAX_ATTACHMENTS}, "", http.StatusBadRequest)
+		}
+
+		for _, adata := range *data.Attachments {
+			if err := validateAttachmentImportData(&adata); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateReactionImportData(data *ReactionImportData) *model.AppError {
+	if data.User == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.user_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.EmojiName == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.emoji_name_missing.error", nil, "", http.StatusBadRequest)
+	} else if len(*data.EmojiName) == 0 || len(*data.EmojiName) > model.EMOJI_NAME_MAX_LENGTH {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.emoji_name_length.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.CreateAt != nil && *data.CreateAt == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_reaction_import_data.create_at_zero.error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func validateAttachmentImportData(data *AttachmentImportData) *model.AppError {
+	if data.Path == nil || len(*data.Path) == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_attachment_import_data.path_missing.error", nil, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -819,7 +1102,11 @@ func validatePostImportData(data *PostImportData) *model.AppError {
 // some of the usual checks. (IsValid is still run)
 //
 
-func OldImportPost(post *model.Post) {
+// OldImportPost saves a post, splitting it into several posts if its message is too long, and
+// returns the id of the first one that was saved or an empty string if none of them were.
+func OldImportPost(post *model.Post) string {
+	var firstPostId string
+
 	// Workaround for empty messages, which may be the case if they are webhook posts.
 	firstIteration := true
 	for messageRuneCount := utf8.RuneCountInString(post.Message); messageRuneCount > 0 || firstIteration; messageRuneCount = utf8.RuneCountInString(post.Message) {
@@ -836,6 +1123,12 @@ func OldImportPost(post *model.Post) {
 
 		if result := <-Srv.Store.Post().Save(post); result.Err != nil {
 			l4g.Debug(utils.T("api.import.import_post.saving.debug"), post.UserId, post.Message)
+		} else {
+			recordPostIntegrity(post)
+
+			if len(firstPostId) == 0 {
+				firstPostId = post.Id
+			}
 		}
 
 		for _, fileId := range post.FileIds {
@@ -848,6 +1141,8 @@ func OldImportPost(post *model.Post) {
 		post.CreateAt++
 		post.Message = remainder
 	}
+
+	return firstPostId
 }
 
 func OldImportUser(team *model.Team, user *model.User) *model.User {
@@ -902,7 +1197,7 @@ func OldImportFile(file io.Reader, teamId string, channelId string, userId strin
 	return fileInfo, nil
 }
 
-func OldImportIncomingWebhookPost(post *model.Post, props model.StringInterface) {
+func OldImportIncomingWebhookPost(post *model.Post, props model.StringInterface) string {
 	linkWithTextRegex := regexp.MustCompile(`<([^<\|]+)\|([^>]+)>`)
 	post.Message = linkWithTextRegex.ReplaceAllString(post.Message, "[${2}](${1})")
 
@@ -924,5 +1219,5 @@ func OldImportIncomingWebhookPost(post *model.Post, props model.StringInterface)
 		}
 	}
 
-	OldImportPost(post)
+	return OldImportPost(post)
 }
@@ -6,6 +6,9 @@ package app
 import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
 	"testing"
@@ -569,6 +572,125 @@ func TestImportValidatePostImportData(t *testing.T) {
 	if err := validatePostImportData(&data); err != nil {
 		t.Fatal("Should have succeeded.")
 	}
+
+	// Test with valid reactions and attachments.
+	data.Reactions = &[]ReactionImportData{
+		{
+			User:      ptrStr("username"),
+			EmojiName: ptrStr("smile"),
+		},
+	}
+	data.Attachments = &[]AttachmentImportData{
+		{
+			Path: ptrStr("images/test.png"),
+		},
+	}
+	if err := validatePostImportData(&data); err != nil {
+		t.Fatal("Should have succeeded.")
+	}
+
+	// Test with an invalid reaction.
+	data.Reactions = &[]ReactionImportData{
+		{
+			User: ptrStr("username"),
+		},
+	}
+	if err := validatePostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to invalid reaction.")
+	}
+	data.Reactions = nil
+
+	// Test with an invalid attachment.
+	data.Attachments = &[]AttachmentImportData{{}}
+	if err := validatePostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to invalid attachment.")
+	}
+
+	// Test with too many attachments.
+	attachments := []AttachmentImportData{}
+	for i := 0; i <= POST_IMPORT_MAX_ATTACHMENTS; i++ {
+		attachments = append(attachments, AttachmentImportData{Path: ptrStr("images/test.png")})
+	}
+	data.Attachments = &attachments
+	if err := validatePostImportData(&data); err == nil {
+		t.Fatal("Should have failed due to too many attachments.")
+	}
+}
+
+func TestImportValidateReactionImportData(t *testing.T) {
+	// Test with minimum required valid properties.
+	data := ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("smile"),
+	}
+	if err := validateReactionImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	// Test with missing required properties.
+	data = ReactionImportData{
+		EmojiName: ptrStr("smile"),
+	}
+	if err := validateReactionImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = ReactionImportData{
+		User: ptrStr("username"),
+	}
+	if err := validateReactionImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	// Test with invalid emoji name.
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr(strings.Repeat("1234567890", 10)),
+	}
+	if err := validateReactionImportData(&data); err == nil {
+		t.Fatal("Should have failed due to too long emoji name.")
+	}
+
+	// Test with invalid CreateAt.
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("smile"),
+		CreateAt:  ptrInt64(0),
+	}
+	if err := validateReactionImportData(&data); err == nil {
+		t.Fatal("Should have failed due to 0 create-at value.")
+	}
+
+	// Test with valid all optional parameters.
+	data = ReactionImportData{
+		User:      ptrStr("username"),
+		EmojiName: ptrStr("smile"),
+		CreateAt:  ptrInt64(model.GetMillis()),
+	}
+	if err := validateReactionImportData(&data); err != nil {
+		t.Fatal("Should have succeeded.")
+	}
+}
+
+func TestImportValidateAttachmentImportData(t *testing.T) {
+	data := AttachmentImportData{
+		Path: ptrStr("images/test.png"),
+	}
+	if err := validateAttachmentImportData(&data); err != nil {
+		t.Fatal("Validation failed but should have been valid.")
+	}
+
+	data = AttachmentImportData{}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
+
+	data = AttachmentImportData{
+		Path: ptrStr(""),
+	}
+	if err := validateAttachmentImportData(&data); err == nil {
+		t.Fatal("Should have failed due to missing required property.")
+	}
 }
 
 func TestImportImportTeam(t *testing.T) {
@@ -1767,6 +1889,121 @@ func TestImportBulkImport(t *testing.T) {
 	}
 }
 
+func TestImportValidateBulkImport(t *testing.T) {
+	teamName := model.NewId()
+	channelName := model.NewId()
+	username := "n" + model.NewId()
+
+	// Validate a valid file.
+	data1 := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + teamName + `"}}
+{"type": "channel", "channel": {"type": "O", "display_name": "xr6m6udffngark2uekvr3hoeny", "team": "` + teamName + `", "name": "` + channelName + `"}}
+{"type": "user", "user": {"username": "` + username + `", "email": "` + username + `@example.com", "teams": [{"name": "` + teamName + `", "channels": [{"name": "` + channelName + `"}]}]}}
+{"type": "post", "post": {"team": "` + teamName + `", "channel": "` + channelName + `", "user": "` + username + `", "message": "Hello World", "create_at": 123456789012, "reactions": [{"user": "` + username + `", "emoji_name": "smile"}], "attachments": [{"path": "images/test.png"}]}}`
+
+	if lineErrors, err := ValidateBulkImport(strings.NewReader(data1)); err != nil || len(lineErrors) != 0 {
+		t.Fatalf("ValidateBulkImport should have succeeded: %v, %v", err, lineErrors)
+	}
+
+	// Validate a file with several invalid lines.
+	data2 := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4"}}
+{"type": "channel", "channel": {"type": "O", "display_name": "xr6m6udffngark2uekvr3hoeny", "team": "` + teamName + `", "name": "` + channelName + `"}}
+{"type": "post", "post": {"team": "` + teamName + `", "channel": "` + channelName + `", "user": "` + username + `", "message": "Hello World", "create_at": 123456789012, "reactions": [{"user": "` + username + `"}]}}
+{"type": "post", "post": {"team": "` + teamName + `"`
+
+	if lineErrors, err := ValidateBulkImport(strings.NewReader(data2)); err != nil {
+		t.Fatal(err)
+	} else if len(lineErrors) != 3 {
+		t.Fatalf("Should have found 3 invalid lines: %v", lineErrors)
+	} else if lineErrors[0].LineNumber != 2 || lineErrors[1].LineNumber != 4 || lineErrors[2].LineNumber != 5 {
+		t.Fatalf("Should have reported the invalid lines: %v, %v, %v", lineErrors[0].LineNumber, lineErrors[1].LineNumber, lineErrors[2].LineNumber)
+	}
+
+	// Validate a file without a version line.
+	data3 := `{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + teamName + `"}}`
+	if lineErrors, err := ValidateBulkImport(strings.NewReader(data3)); err != nil || len(lineErrors) != 1 || lineErrors[0].LineNumber != 1 {
+		t.Fatalf("Should have failed due to missing version line on line 1.")
+	}
+}
+
+func TestImportBulkImportCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+
+	if checkpoint, err := ReadBulkImportCheckpoint(path); err != nil || checkpoint.LineNumber != 0 {
+		t.Fatal("Should have started at the beginning of the file without a checkpoint file.")
+	}
+
+	if err := WriteBulkImportCheckpoint(path, &BulkImportCheckpoint{LineNumber: 300}); err != nil {
+		t.Fatal(err)
+	}
+
+	if checkpoint, err := ReadBulkImportCheckpoint(path); err != nil || checkpoint.LineNumber != 300 {
+		t.Fatal("Should have read the checkpoint back.")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("junk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadBulkImportCheckpoint(path); err == nil {
+		t.Fatal("Should have failed due to invalid checkpoint file.")
+	}
+}
+
+func TestImportResumableBulkImport(t *testing.T) {
+	_ = Setup()
+
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.json")
+
+	teamName := model.NewId()
+	channelName := model.NewId()
+	username := "n" + model.NewId()
+
+	data := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "lskmw2d7a5ao7ppwqh5ljchvr4", "name": "` + teamName + `"}}
+{"type": "channel", "channel": {"type": "O", "display_name": "xr6m6udffngark2uekvr3hoeny", "team": "` + teamName + `", "name": "` + channelName + `"}}
+{"type": "user", "user": {"username": "` + username + `", "email": "` + username + `@example.com", "teams": [{"name": "` + teamName + `", "channels": [{"name": "` + channelName + `"}]}]}}
+{"type": "post", "post": {"team": "` + teamName + `", "channel": "` + channelName + `", "user": "nonexistent` + model.NewId() + `", "message": "Hello World", "create_at": 123456789012}}`
+
+	// The import should stop at the post by a user that doesn't exist and record the line before it.
+	if err, line := ResumableBulkImport(strings.NewReader(data), path); err == nil || line != 5 {
+		t.Fatal("Should have failed on line 5.")
+	}
+
+	if checkpoint, err := ReadBulkImportCheckpoint(path); err != nil || checkpoint.LineNumber != 4 {
+		t.Fatal("Should have recorded line 4 as the last line imported.")
+	}
+
+	// Resume the import with the post fixed. The lines that were already imported are skipped so
+	// they aren't validated again.
+	data = `{"type": "version", "version": 1}
+{"type": "team", "team": {}}
+{"type": "channel", "channel": {}}
+{"type": "user", "user": {}}
+{"type": "post", "post": {"team": "` + teamName + `", "channel": "` + channelName + `", "user": "` + username + `", "message": "Hello World", "create_at": 123456789012}}`
+
+	if err, line := ResumableBulkImport(strings.NewReader(data), path); err != nil || line != 0 {
+		t.Fatalf("Should have skipped the lines that were already imported: %v, %v", err, line)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("Should have removed the checkpoint file.")
+	}
+}
+
 func TestImportProcessImportDataFileVersionLine(t *testing.T) {
 	_ = Setup()
 
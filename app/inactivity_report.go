@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	INACTIVITY_REPORT_CSV_BATCH_SIZE = 1000
+)
+
+func GetInactiveUsers(days int, page int, perPage int) ([]*model.InactiveUser, *model.AppError) {
+	if result := <-Srv.Store.User().GetInactiveUsers(model.GetInactivitySince(days), page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.InactiveUser), nil
+	}
+}
+
+func GetInactiveUsersCount(days int) (int64, *model.AppError) {
+	if result := <-Srv.Store.User().GetInactiveUsersCount(model.GetInactivitySince(days)); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+func GetInactiveChannels(days int, page int, perPage int) ([]*model.InactiveChannel, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetInactiveChannels(model.GetInactivitySince(days), page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.InactiveChannel), nil
+	}
+}
+
+func GetInactiveChannelsCount(days int) (int64, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetInactiveChannelsCount(model.GetInactivitySince(days)); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+// WriteInactiveUsersCsv writes every user that has been inactive for a number of days as CSV, reading
+// them from the store in batches. The start of the report is fixed before the first batch so that
+// users don't move between pages while it's written.
+func WriteInactiveUsersCsv(w io.Writer, days int) *model.AppError {
+	since := model.GetInactivitySince(days)
+
+	writer := csv.NewWriter(w)
+	writer.Write(model.InactiveUserCsvHeader())
+
+	for offset := 0; ; offset += INACTIVITY_REPORT_CSV_BATCH_SIZE {
+		result := <-Srv.Store.User().GetInactiveUsers(since, offset, INACTIVITY_REPORT_CSV_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		users := result.Data.([]*model.InactiveUser)
+		for _, user := range users {
+			writer.Write(user.CsvRecord())
+		}
+
+		if len(users) < INACTIVITY_REPORT_CSV_BATCH_SIZE {
+			break
+		}
+	}
+
+	return flushInactivityReportCsv(writer)
+}
+
+// WriteInactiveChannelsCsv writes every channel that hasn't been posted in for a number of days as
+// CSV, in the same way as WriteInactiveUsersCsv.
+func WriteInactiveChannelsCsv(w io.Writer, days int) *model.AppError {
+	since := model.GetInactivitySince(days)
+
+	writer := csv.NewWriter(w)
+	writer.Write(model.InactiveChannelCsvHeader())
+
+	for offset := 0; ; offset += INACTIVITY_REPORT_CSV_BATCH_SIZE {
+		result := <-Srv.Store.Channel().GetInactiveChannels(since, offset, INACTIVITY_REPORT_CSV_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		channels := result.Data.([]*model.InactiveChannel)
+		for _, channel := range channels {
+			writer.Write(channel.CsvRecord())
+		}
+
+		if len(channels) < INACTIVITY_REPORT_CSV_BATCH_SIZE {
+			break
+		}
+	}
+
+	return flushInactivityReportCsv(writer)
+}
+
+func flushInactivityReportCsv(writer *csv.Writer) *model.AppError {
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return model.NewLocAppError("flushInactivityReportCsv", "app.inactivity_report.write_csv.app_error", nil, err.Error())
+	}
+
+	return nil
+}
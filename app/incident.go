@@ -0,0 +1,162 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	INCIDENT_FEED_RESOLVED_WINDOW = 7 * 24 * 60 * 60 * 1000
+)
+
+func CreateIncident(incident *model.Incident) (*model.Incident, *model.AppError) {
+	if !*Config().IncidentSettings.Enable {
+		return nil, model.NewAppError("CreateIncident", "api.incident.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	var rincident *model.Incident
+	if result := <-Srv.Store.Incident().Save(incident); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rincident = result.Data.(*model.Incident)
+	}
+
+	if post := announceIncident(rincident, rincident.CreatorId); post != nil {
+		rincident.PostId = post.Id
+		if result := <-Srv.Store.Incident().Update(rincident); result.Err != nil {
+			l4g.Error(utils.T("api.incident.announce.error"), rincident.Id, result.Err)
+		}
+	}
+
+	return rincident, nil
+}
+
+func GetIncident(incidentId string) (*model.Incident, *model.AppError) {
+	if !*Config().IncidentSettings.Enable {
+		return nil, model.NewAppError("GetIncident", "api.incident.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Incident().Get(incidentId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Incident), nil
+	}
+}
+
+func GetIncidentsPage(page, perPage int) ([]*model.Incident, *model.AppError) {
+	if !*Config().IncidentSettings.Enable {
+		return nil, model.NewAppError("GetIncidentsPage", "api.incident.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Incident().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Incident), nil
+	}
+}
+
+func PatchIncident(incidentId string, patch *model.IncidentPatch, userId string) (*model.Incident, *model.AppError) {
+	incident, err := GetIncident(incidentId)
+	if err != nil {
+		return nil, err
+	}
+
+	incident.Patch(patch)
+
+	return updateIncident(incident, userId)
+}
+
+func ResolveIncident(incidentId string, userId string) (*model.Incident, *model.AppError) {
+	incident, err := GetIncident(incidentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if incident.IsResolved() {
+		return incident, nil
+	}
+
+	incident.Status = model.INCIDENT_STATUS_RESOLVED
+
+	return updateIncident(incident, userId)
+}
+
+func updateIncident(incident *model.Incident, userId string) (*model.Incident, *model.AppError) {
+	var rincident *model.Incident
+	if result := <-Srv.Store.Incident().Update(incident); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rincident = result.Data.(*model.Incident)
+	}
+
+	announceIncident(rincident, userId)
+
+	return rincident, nil
+}
+
+// GetIncidentFeed returns the summary published to external status pages. It contains the open
+// incidents along with the ones resolved during the last week.
+func GetIncidentFeed() (*model.IncidentFeed, *model.AppError) {
+	if !*Config().IncidentSettings.Enable || !*Config().IncidentSettings.EnablePublicFeed {
+		return nil, model.NewAppError("GetIncidentFeed", "api.incident.feed_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.Incident().GetForFeed(model.GetMillis() - INCIDENT_FEED_RESOLVED_WINDOW); result.Err != nil {
+		return nil, result.Err
+	} else {
+		incidents := result.Data.([]*model.Incident)
+		for _, incident := range incidents {
+			incident.Sanitize()
+		}
+
+		return model.NewIncidentFeed(incidents), nil
+	}
+}
+
+// announceIncident posts the current state of the incident to the announcement channel. The first
+// announcement starts a thread and every later change is posted as a reply to it. Failures are
+// logged since the incident itself has already been saved.
+func announceIncident(incident *model.Incident, userId string) *model.Post {
+	channelId := *Config().IncidentSettings.AnnouncementChannelId
+	if len(channelId) == 0 {
+		return nil
+	}
+
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		l4g.Error(utils.T("api.incident.announce.error"), incident.Id, err)
+		return nil
+	}
+
+	message := fmt.Sprintf("#### %v: %v\n**%v:** %v | **%v:** %v",
+		utils.T("api.incident.announce.incident"), incident.Title,
+		utils.T("api.incident.announce.status"), incident.Status,
+		utils.T("api.incident.announce.severity"), incident.Severity)
+
+	if len(incident.Message) > 0 {
+		message += "\n\n" + incident.Message
+	}
+
+	post := &model.Post{
+		ChannelId: channel.Id,
+		UserId:    userId,
+		RootId:    incident.PostId,
+		ParentId:  incident.PostId,
+		Message:   message,
+	}
+	post.AddProp(model.POST_PROP_INCIDENT_ID, incident.Id)
+
+	if rpost, err := CreatePost(post, channel.TeamId, false); err != nil {
+		l4g.Error(utils.T("api.incident.announce.error"), incident.Id, err)
+		return nil
+	} else {
+		return rpost
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// getIntegrationDisplayNamePolicy returns whether posts that integrations make in a channel may show
+// the username and icon that the integration asks for. Overrides can be forbidden for the whole
+// system with the EnablePostUsernameOverride and EnablePostIconOverride settings or for a single
+// channel by the people who manage it.
+func getIntegrationDisplayNamePolicy(channelId string) string {
+	if !Config().ServiceSettings.EnablePostUsernameOverride && !Config().ServiceSettings.EnablePostIconOverride {
+		return model.INTEGRATION_DISPLAY_NAME_CREATOR
+	}
+
+	if result := <-Srv.Store.Channel().Get(channelId, true); result.Err == nil && result.Data.(*model.Channel).ForbidIntegrationOverrides {
+		return model.INTEGRATION_DISPLAY_NAME_CREATOR
+	}
+
+	return model.INTEGRATION_DISPLAY_NAME_OVERRIDE
+}
+
+// setIntegrationMetadata marks a post as made by an integration and removes the username and icon
+// overrides that aren't allowed in its channel.
+func setIntegrationMetadata(post *model.Post, integrationType string, integrationId string) *model.IntegrationMetadata {
+	metadata := &model.IntegrationMetadata{
+		Type:              integrationType,
+		IntegrationId:     integrationId,
+		DisplayNamePolicy: getIntegrationDisplayNamePolicy(post.ChannelId),
+	}
+
+	post.SetIntegrationMetadata(metadata)
+
+	if !metadata.AllowsOverrides() || !Config().ServiceSettings.EnablePostUsernameOverride {
+		delete(post.Props, "override_username")
+	}
+
+	if !metadata.AllowsOverrides() || !Config().ServiceSettings.EnablePostIconOverride {
+		delete(post.Props, "override_icon_url")
+	}
+
+	return metadata
+}
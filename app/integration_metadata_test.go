@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestCreateWebhookPostIntegrationMetadata(t *testing.T) {
+	th := Setup().InitBasic()
+
+	enableUsernameOverride := utils.Cfg.ServiceSettings.EnablePostUsernameOverride
+	enableIconOverride := utils.Cfg.ServiceSettings.EnablePostIconOverride
+	defer func() {
+		utils.Cfg.ServiceSettings.EnablePostUsernameOverride = enableUsernameOverride
+		utils.Cfg.ServiceSettings.EnablePostIconOverride = enableIconOverride
+	}()
+	utils.Cfg.ServiceSettings.EnablePostUsernameOverride = true
+	utils.Cfg.ServiceSettings.EnablePostIconOverride = true
+
+	hookId := model.NewId()
+	props := model.StringInterface{model.POST_PROP_FROM_INTEGRATION: map[string]interface{}{"type": model.INTEGRATION_TYPE_BOT}}
+
+	post, err := CreateWebhookPost(th.BasicUser.Id, th.BasicTeam.Id, th.BasicChannel.Id, "hello", "robot", "http://example.com/icon.png", props, "", model.INTEGRATION_TYPE_INCOMING_WEBHOOK, hookId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata := post.GetIntegrationMetadata(); metadata == nil || metadata.Type != model.INTEGRATION_TYPE_INCOMING_WEBHOOK || metadata.IntegrationId != hookId || !metadata.AllowsOverrides() {
+		t.Fatal("should have set the metadata of the webhook")
+	}
+
+	if post.Props["override_username"] != "robot" {
+		t.Fatal("should have kept the username override")
+	}
+
+	if _, err := UpdateChannelIntegrationOverrides(th.BasicChannel, true); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err = CreateWebhookPost(th.BasicUser.Id, th.BasicTeam.Id, th.BasicChannel.Id, "hello", "robot", "http://example.com/icon.png", nil, "", model.INTEGRATION_TYPE_INCOMING_WEBHOOK, hookId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata := post.GetIntegrationMetadata(); metadata == nil || metadata.AllowsOverrides() {
+		t.Fatal("overrides should be forbidden in the channel")
+	}
+
+	if _, ok := post.Props["override_username"]; ok {
+		t.Fatal("should have removed the username override")
+	}
+
+	if _, ok := post.Props["override_icon_url"]; ok {
+		t.Fatal("should have removed the icon override")
+	}
+}
+
+func TestCreatePostAsUserIntegrationMetadata(t *testing.T) {
+	th := Setup().InitBasic()
+
+	post := &model.Post{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "hello"}
+	post.SetIntegrationMetadata(&model.IntegrationMetadata{Type: model.INTEGRATION_TYPE_BOT, IntegrationId: th.BasicUser.Id, DisplayNamePolicy: model.INTEGRATION_DISPLAY_NAME_OVERRIDE})
+
+	rpost, err := CreatePostAsUser(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rpost.IsFromIntegration() {
+		t.Fatal("users shouldn't be able to say that their posts come from an integration")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	INVITATION_EXPIRY_TASK_NAME     = "Invitation Expiry"
+	INVITATION_EXPIRY_TASK_INTERVAL = time.Hour
+)
+
+// InitInvitationExpiry starts the job that deletes the invitations that have expired every hour.
+func InitInvitationExpiry() {
+	if task := model.GetTaskByName(INVITATION_EXPIRY_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(INVITATION_EXPIRY_TASK_NAME, DeleteExpiredInvitations, INVITATION_EXPIRY_TASK_INTERVAL)
+}
+
+func DeleteExpiredInvitations() {
+	if result := <-Srv.Store.Invitation().DeleteExpired(model.GetMillis()); result.Err != nil {
+		l4g.Error(utils.T("app.invitation.delete_expired.error"), result.Err.Error())
+	} else if count := result.Data.(int64); count > 0 {
+		l4g.Debug(utils.T("app.invitation.delete_expired.debug"), count)
+	}
+}
+
+func saveInvitation(invitation *model.Invitation) (*model.Invitation, *model.AppError) {
+	if result := <-Srv.Store.Invitation().Save(invitation); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Invitation), nil
+	}
+}
+
+func GetInvitation(invitationId string) (*model.Invitation, *model.AppError) {
+	if result := <-Srv.Store.Invitation().Get(invitationId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Invitation), nil
+	}
+}
+
+func GetPendingInvitationsForTeam(teamId string, page int, perPage int) ([]*model.Invitation, *model.AppError) {
+	if result := <-Srv.Store.Invitation().GetPendingForTeam(teamId, model.GetMillis(), page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Invitation), nil
+	}
+}
+
+// ResendInvitation sends an invitation again with a new link, which also makes it pending for another
+// 48 hours. The link that was sent before stops working.
+func ResendInvitation(invitation *model.Invitation) (*model.Invitation, *model.AppError) {
+	team, err := GetTeam(invitation.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The email still comes from whoever sent the invitation in the first place
+	senderName := "Administrator"
+	if len(invitation.InviterId) > 0 {
+		if inviter, err := GetUser(invitation.InviterId); err != nil {
+			return nil, err
+		} else {
+			senderName = inviter.GetDisplayName()
+		}
+	}
+
+	var channels []*model.Channel
+	if invitation.Guest {
+		for _, channelId := range invitation.ChannelIds {
+			if channel, err := GetChannel(channelId); err != nil {
+				return nil, err
+			} else {
+				channels = append(channels, channel)
+			}
+		}
+	}
+
+	invitation.Renew(model.GetMillis())
+	if result := <-Srv.Store.Invitation().Update(invitation); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if invitation.Guest {
+		sendGuestInviteEmail(team, channels, senderName, invitation, utils.GetSiteURL())
+	} else {
+		sendInviteEmail(team, senderName, invitation, utils.GetSiteURL())
+	}
+
+	return invitation, nil
+}
+
+func RevokeInvitation(invitationId string) *model.AppError {
+	if result := <-Srv.Store.Invitation().Delete(invitationId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// getInvitationForLink returns the invitation that a signup link was sent for. Links that were sent before
+// invitations were recorded don't have one, so nil is returned for them.
+func getInvitationForLink(where string, props map[string]string) (*model.Invitation, *model.AppError) {
+	token, ok := props["token"]
+	if !ok {
+		return nil, nil
+	}
+
+	var invitation *model.Invitation
+	if result := <-Srv.Store.Invitation().GetByToken(token); result.Err != nil {
+		return nil, model.NewLocAppError(where, "api.user.create_user.signup_link_revoked.app_error", nil, result.Err.Error())
+	} else {
+		invitation = result.Data.(*model.Invitation)
+	}
+
+	if invitation.IsExpired() {
+		return nil, model.NewLocAppError(where, "api.user.create_user.signup_link_expired.app_error", nil, "")
+	}
+
+	return invitation, nil
+}
+
+// acceptInvitation removes an invitation once it has been used to join its team.
+func acceptInvitation(invitation *model.Invitation) {
+	if invitation == nil {
+		return
+	}
+
+	if result := <-Srv.Store.Invitation().Delete(invitation.Id); result.Err != nil {
+		l4g.Error(utils.T("app.invitation.accept.error"), invitation.Id, result.Err.Error())
+	}
+}
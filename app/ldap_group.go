@@ -0,0 +1,287 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	LDAP_GROUP_SYNC_TASK_NAME   = "LDAP Group Sync"
+	LDAP_GROUP_SYNC_MEMBER_PAGE = 200
+)
+
+// InitLdapGroupSync starts the job that synchronizes the channels linked to LDAP groups with the members
+// of those groups.
+func InitLdapGroupSync() {
+	if task := model.GetTaskByName(LDAP_GROUP_SYNC_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	if !*Config().LdapSettings.EnableGroupSync {
+		return
+	}
+
+	model.CreateRecurringTask(LDAP_GROUP_SYNC_TASK_NAME, func() {
+		if result, err := SyncLdapGroups(false); err != nil {
+			l4g.Error(utils.T("app.ldap_group.sync.error"), err.Error())
+		} else {
+			for _, message := range result.Errors {
+				l4g.Error(utils.T("app.ldap_group.sync.error"), message)
+			}
+		}
+	}, time.Duration(*Config().LdapSettings.GroupSyncIntervalMinutes)*time.Minute)
+}
+
+func getLdapGroupSyncInterface(where string) (einterfaces.LdapInterface, *model.AppError) {
+	ldapI := einterfaces.GetLdapInterface()
+	if ldapI == nil || !utils.IsLicensed || !*utils.License.Features.LDAP || !*Config().LdapSettings.Enable || !*Config().LdapSettings.EnableGroupSync {
+		return nil, model.NewAppError(where, "app.ldap_group.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	return ldapI, nil
+}
+
+func LinkLdapGroup(group *model.LdapGroup) (*model.LdapGroup, *model.AppError) {
+	if _, err := getLdapGroupSyncInterface("LinkLdapGroup"); err != nil {
+		return nil, err
+	}
+
+	channel, err := GetChannel(group.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("LinkLdapGroup", "app.ldap_group.link.channel_type.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.LdapGroup().Save(group); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LdapGroup), nil
+	}
+}
+
+func GetLdapGroup(groupId string) (*model.LdapGroup, *model.AppError) {
+	if result := <-Srv.Store.LdapGroup().Get(groupId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LdapGroup), nil
+	}
+}
+
+// GetLdapGroups returns the groups linked to a channel, or every linked group if channelId is empty.
+func GetLdapGroups(channelId string) ([]*model.LdapGroup, *model.AppError) {
+	var result store.StoreResult
+	if len(channelId) == 0 {
+		result = <-Srv.Store.LdapGroup().GetAll()
+	} else {
+		result = <-Srv.Store.LdapGroup().GetForChannel(channelId)
+	}
+
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.LdapGroup), nil
+}
+
+func UnlinkLdapGroup(groupId string) *model.AppError {
+	if result := <-Srv.Store.LdapGroup().Delete(groupId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// SyncLdapGroups updates the members of every channel linked to an LDAP group. The members of the
+// linked groups are added to the channel and its team, and LDAP users that aren't in any of them
+// anymore are removed from the channel. Other users are left alone. With dryRun, nothing is changed
+// and the result lists the changes that would have been made. Channels that can't be synchronized are
+// reported in the result's errors without stopping the others.
+func SyncLdapGroups(dryRun bool) (*model.LdapGroupSyncResult, *model.AppError) {
+	ldapI, err := getLdapGroupSyncInterface("SyncLdapGroups")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.LdapGroupSyncResult{
+		DryRun:  dryRun,
+		StartAt: model.GetMillis(),
+		Changes: []*model.LdapGroupSyncChange{},
+		Errors:  []string{},
+	}
+
+	groups, err := GetLdapGroups("")
+	if err != nil {
+		return nil, err
+	}
+
+	var channelIds []string
+	groupsByChannel := map[string][]*model.LdapGroup{}
+	for _, group := range groups {
+		if _, ok := groupsByChannel[group.ChannelId]; !ok {
+			channelIds = append(channelIds, group.ChannelId)
+		}
+		groupsByChannel[group.ChannelId] = append(groupsByChannel[group.ChannelId], group)
+	}
+
+	if len(channelIds) == 0 {
+		result.EndAt = model.GetMillis()
+		return result, nil
+	}
+
+	// The members of a group are listed by their LDAP id, which is stored as the AuthData of their account
+	usersByLdapId := map[string]*model.User{}
+	ldapUsers := map[string]*model.User{}
+	if uresult := <-Srv.Store.User().GetAllUsingAuthService(model.USER_AUTH_SERVICE_LDAP); uresult.Err != nil {
+		return nil, uresult.Err
+	} else {
+		for _, user := range uresult.Data.([]*model.User) {
+			if user.AuthData != nil && len(*user.AuthData) > 0 {
+				usersByLdapId[*user.AuthData] = user
+				ldapUsers[user.Id] = user
+			}
+		}
+	}
+
+	for _, channelId := range channelIds {
+		changes, err := syncLdapGroupChannel(ldapI, channelId, groupsByChannel[channelId], usersByLdapId, ldapUsers, dryRun)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.Changes = append(result.Changes, changes...)
+	}
+
+	result.EndAt = model.GetMillis()
+	return result, nil
+}
+
+func syncLdapGroupChannel(ldapI einterfaces.LdapInterface, channelId string, groups []*model.LdapGroup, usersByLdapId map[string]*model.User, ldapUsers map[string]*model.User, dryRun bool) ([]*model.LdapGroupSyncChange, *model.AppError) {
+	channel, err := GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	// A group that can't be read stops the sync of the channel, since its members would otherwise be removed
+	wanted := map[string]bool{}
+	for _, group := range groups {
+		memberIds, err := ldapI.GetGroupMemberIds(group.RemoteId)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, memberId := range memberIds {
+			if user := usersByLdapId[memberId]; user != nil && user.DeleteAt == 0 {
+				wanted[user.Id] = true
+			}
+		}
+	}
+
+	members := map[string]bool{}
+	for page := 0; ; page++ {
+		channelMembers, err := GetChannelMembersPage(channel.Id, page, LDAP_GROUP_SYNC_MEMBER_PAGE)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range *channelMembers {
+			members[member.UserId] = true
+		}
+
+		if len(*channelMembers) < LDAP_GROUP_SYNC_MEMBER_PAGE {
+			break
+		}
+	}
+
+	changes := getLdapGroupSyncChanges(channel, wanted, members, ldapUsers)
+	if dryRun {
+		return changes, nil
+	}
+
+	var firstErr *model.AppError
+	applied := make([]*model.LdapGroupSyncChange, 0, len(changes))
+	for _, change := range changes {
+		if err := applyLdapGroupSyncChange(channel, ldapUsers[change.UserId], change); err != nil {
+			l4g.Error(utils.T("app.ldap_group.sync.change.error"), change.Action, change.UserId, channel.Id, err.Error())
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			applied = append(applied, change)
+		}
+	}
+
+	now := model.GetMillis()
+	for _, group := range groups {
+		if result := <-Srv.Store.LdapGroup().UpdateLastSyncAt(group.Id, now); result.Err != nil && firstErr == nil {
+			firstErr = result.Err
+		}
+	}
+
+	return applied, firstErr
+}
+
+// getLdapGroupSyncChanges compares the members that a channel should have according to its linked groups
+// with the ones it has. Only the LDAP users among the members can be removed.
+func getLdapGroupSyncChanges(channel *model.Channel, wanted map[string]bool, members map[string]bool, ldapUsers map[string]*model.User) []*model.LdapGroupSyncChange {
+	changes := []*model.LdapGroupSyncChange{}
+
+	for userId := range wanted {
+		if !members[userId] {
+			changes = append(changes, &model.LdapGroupSyncChange{
+				Action:    model.LDAP_GROUP_SYNC_ACTION_ADD,
+				UserId:    userId,
+				TeamId:    channel.TeamId,
+				ChannelId: channel.Id,
+			})
+		}
+	}
+
+	// Nobody can leave the default channel of a team
+	if channel.Name != model.DEFAULT_CHANNEL {
+		for userId := range members {
+			if ldapUsers[userId] != nil && !wanted[userId] {
+				changes = append(changes, &model.LdapGroupSyncChange{
+					Action:    model.LDAP_GROUP_SYNC_ACTION_REMOVE,
+					UserId:    userId,
+					TeamId:    channel.TeamId,
+					ChannelId: channel.Id,
+				})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Action != changes[j].Action {
+			return changes[i].Action < changes[j].Action
+		}
+		return changes[i].UserId < changes[j].UserId
+	})
+
+	return changes
+}
+
+func applyLdapGroupSyncChange(channel *model.Channel, user *model.User, change *model.LdapGroupSyncChange) *model.AppError {
+	if change.Action == model.LDAP_GROUP_SYNC_ACTION_REMOVE {
+		return removeUserFromChannel(change.UserId, change.UserId, channel)
+	}
+
+	// The user has to be on the team before they can be added to one of its channels
+	if err := AddUserToTeamByTeamId(channel.TeamId, user); err != nil {
+		return err
+	}
+
+	_, err := AddUserToChannel(user, channel)
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestGetLdapGroupSyncChanges(t *testing.T) {
+	channel := &model.Channel{Id: model.NewId(), TeamId: model.NewId(), Name: "developers"}
+
+	kept := &model.User{Id: "a" + model.NewId()[1:]}
+	added := &model.User{Id: "b" + model.NewId()[1:]}
+	removed := &model.User{Id: "c" + model.NewId()[1:]}
+	emailUser := "d" + model.NewId()[1:]
+
+	wanted := map[string]bool{kept.Id: true, added.Id: true}
+	members := map[string]bool{kept.Id: true, removed.Id: true, emailUser: true}
+	ldapUsers := map[string]*model.User{kept.Id: kept, added.Id: added, removed.Id: removed}
+
+	changes := getLdapGroupSyncChanges(channel, wanted, members, ldapUsers)
+	if len(changes) != 2 {
+		t.Fatal("should have added the missing group member and removed the LDAP user that left", len(changes))
+	}
+
+	if change := changes[0]; change.Action != model.LDAP_GROUP_SYNC_ACTION_ADD || change.UserId != added.Id || change.TeamId != channel.TeamId || change.ChannelId != channel.Id {
+		t.Fatal("should have added the group member", change)
+	}
+
+	if change := changes[1]; change.Action != model.LDAP_GROUP_SYNC_ACTION_REMOVE || change.UserId != removed.Id {
+		t.Fatal("should have removed the LDAP user that isn't in the group", change)
+	}
+
+	channel.Name = model.DEFAULT_CHANNEL
+	changes = getLdapGroupSyncChanges(channel, wanted, members, ldapUsers)
+	if len(changes) != 1 || changes[0].Action != model.LDAP_GROUP_SYNC_ACTION_ADD {
+		t.Fatal("should not have removed anyone from the default channel", changes)
+	}
+
+	if changes := getLdapGroupSyncChanges(channel, wanted, wanted, ldapUsers); len(changes) != 0 {
+		t.Fatal("should not have changed a channel that is in sync", changes)
+	}
+}
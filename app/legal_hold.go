@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	LEGAL_HOLD_EXPORT_BATCH_SIZE = 1000
+)
+
+// CreateLegalHold holds a user or a channel so that its content can't be deleted until the hold is
+// released.
+func CreateLegalHold(hold *model.LegalHold) (*model.LegalHold, *model.AppError) {
+	switch hold.TargetType {
+	case model.LEGAL_HOLD_TARGET_USER:
+		if _, err := GetUser(hold.TargetId); err != nil {
+			return nil, err
+		}
+	case model.LEGAL_HOLD_TARGET_CHANNEL:
+		if _, err := GetChannel(hold.TargetId); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, model.NewAppError("CreateLegalHold", "model.legal_hold.is_valid.target_type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.LegalHold().Save(hold); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LegalHold), nil
+	}
+}
+
+func GetLegalHold(holdId string) (*model.LegalHold, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().Get(holdId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LegalHold), nil
+	}
+}
+
+func GetLegalHolds(page, perPage int) ([]*model.LegalHold, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.LegalHold), nil
+	}
+}
+
+func GetLegalHoldsCount() (int64, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().GetCount(); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
+// DeleteLegalHold releases a hold, after which the data retention job and users can delete the
+// content again.
+func DeleteLegalHold(holdId string) *model.AppError {
+	if result := <-Srv.Store.LegalHold().Delete(holdId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// checkPostNotHeld returns an error if the post was made by a held user or in a held channel.
+func checkPostNotHeld(post *model.Post) *model.AppError {
+	if result := <-Srv.Store.LegalHold().IsHeld(post.UserId, post.ChannelId); result.Err != nil {
+		return result.Err
+	} else if result.Data.(bool) {
+		return model.NewAppError("checkPostNotHeld", "app.legal_hold.post_held.app_error", nil, "post_id="+post.Id, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// checkUserNotHeld returns an error if permanently deleting the user would delete held content.
+func checkUserNotHeld(userId string) *model.AppError {
+	if result := <-Srv.Store.LegalHold().HasHeldUserContent(userId); result.Err != nil {
+		return result.Err
+	} else if result.Data.(bool) {
+		return model.NewAppError("checkUserNotHeld", "app.legal_hold.user_held.app_error", nil, "user_id="+userId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// checkChannelNotHeld returns an error if permanently deleting the channel would delete held content.
+func checkChannelNotHeld(channelId string) *model.AppError {
+	if result := <-Srv.Store.LegalHold().HasHeldChannelContent(channelId); result.Err != nil {
+		return result.Err
+	} else if result.Data.(bool) {
+		return model.NewAppError("checkChannelNotHeld", "app.legal_hold.channel_held.app_error", nil, "channel_id="+channelId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// WriteLegalHoldExportCsv writes the held messages, including deleted ones, that were posted after
+// startTime and up to endTime as CSV.
+func WriteLegalHoldExportCsv(w io.Writer, startTime int64, endTime int64) *model.AppError {
+	if startTime < 0 || endTime <= startTime {
+		return model.NewAppError("WriteLegalHoldExportCsv", "app.legal_hold.export.time_range.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(model.CompliancePostHeader())
+
+	for offset := 0; ; offset += LEGAL_HOLD_EXPORT_BATCH_SIZE {
+		result := <-Srv.Store.LegalHold().GetHeldPosts(startTime, endTime, offset, LEGAL_HOLD_EXPORT_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+
+		cposts := result.Data.([]*model.CompliancePost)
+		for _, cpost := range cposts {
+			writer.Write(cpost.Row())
+		}
+
+		if len(cposts) < LEGAL_HOLD_EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return model.NewAppError("WriteLegalHoldExportCsv", "app.legal_hold.export.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
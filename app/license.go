@@ -23,7 +23,7 @@ func LoadLicense() {
 
 	if len(licenseId) != 26 {
 		// Lets attempt to load the file from disk since it was missing from the DB
-		fileName := utils.GetLicenseFileLocation(*utils.Cfg.ServiceSettings.LicenseFileLocation)
+		fileName := utils.GetLicenseFileLocation(*Config().ServiceSettings.LicenseFileLocation)
 
 		if _, err := os.Stat(fileName); err == nil {
 			l4g.Info("License key has not been uploaded.  Loading license key from disk at %v", fileName)
@@ -10,10 +10,11 @@ import (
 
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
-	"github.com/mattermost/platform/utils"
 	"github.com/mssola/user_agent"
 )
 
+// AuthenticateUserForLogin checks a user's password and second factor. The MFA token may be the
+// assertion of one of the user's security keys instead of a code, see BeginWebAuthnLogin.
 func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId string, ldapOnly bool) (*model.User, *model.AppError) {
 	if len(password) == 0 {
 		err := model.NewLocAppError("AuthenticateUserForLogin", "api.user.login.blank_pwd.app_error", nil, "")
@@ -41,6 +42,14 @@ func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId string,
 		}
 	}
 
+	// bots can only authenticate with access tokens
+	if IsBotUser(user.Id) {
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementLoginFail()
+		}
+		return nil, model.NewAppError("AuthenticateUserForLogin", "api.user.login.bot_login_forbidden.app_error", nil, "user_id="+user.Id, http.StatusUnauthorized)
+	}
+
 	// and then authenticate them
 	if user, err = authenticateUser(user, password, mfaToken); err != nil {
 		if einterfaces.GetMetricsInterface() != nil {
@@ -59,10 +68,10 @@ func AuthenticateUserForLogin(id, loginId, password, mfaToken, deviceId string,
 func DoLogin(w http.ResponseWriter, r *http.Request, user *model.User, deviceId string) (*model.Session, *model.AppError) {
 	session := &model.Session{UserId: user.Id, Roles: user.GetRawRoles(), DeviceId: deviceId, IsOAuth: false}
 
-	maxAge := *utils.Cfg.ServiceSettings.SessionLengthWebInDays * 60 * 60 * 24
+	maxAge := *Config().ServiceSettings.SessionLengthWebInDays * 60 * 60 * 24
 
 	if len(deviceId) > 0 {
-		session.SetExpireInDays(*utils.Cfg.ServiceSettings.SessionLengthMobileInDays)
+		session.SetExpireInDays(*Config().ServiceSettings.SessionLengthMobileInDays)
 
 		// A special case where we logout of all other sessions with the same Id
 		if err := RevokeSessionsForDeviceId(user.Id, deviceId, ""); err != nil {
@@ -70,7 +79,7 @@ func DoLogin(w http.ResponseWriter, r *http.Request, user *model.User, deviceId
 			return nil, err
 		}
 	} else {
-		session.SetExpireInDays(*utils.Cfg.ServiceSettings.SessionLengthWebInDays)
+		session.SetExpireInDays(*Config().ServiceSettings.SessionLengthWebInDays)
 	}
 
 	ua := user_agent.New(r.UserAgent())
@@ -104,6 +113,8 @@ func DoLogin(w http.ResponseWriter, r *http.Request, user *model.User, deviceId
 		return nil, err
 	}
 
+	RecordDevice(r, session)
+
 	w.Header().Set(model.HEADER_TOKEN, session.Token)
 
 	secure := false
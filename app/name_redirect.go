@@ -0,0 +1,98 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// saveNameRedirect records the old name of a team or a channel that was just renamed. Failing to do so
+// shouldn't fail the rename, so errors are only logged.
+func saveNameRedirect(redirectType string, teamId string, oldName string, targetId string) {
+	if *Config().TeamSettings.NameRedirectGracePeriodDays == 0 {
+		return
+	}
+
+	redirect := &model.NameRedirect{
+		Type:     redirectType,
+		TeamId:   teamId,
+		OldName:  oldName,
+		TargetId: targetId,
+	}
+
+	if result := <-Srv.Store.NameRedirect().Save(redirect); result.Err != nil {
+		l4g.Error(utils.T("app.name_redirect.save.error"), oldName, result.Err)
+	}
+}
+
+// getNameRedirect returns the id of the team or channel that was renamed from oldName within the grace
+// period, or an empty string if there's none.
+func getNameRedirect(redirectType string, teamId string, oldName string) string {
+	days := *Config().TeamSettings.NameRedirectGracePeriodDays
+	if days == 0 {
+		return ""
+	}
+
+	since := model.GetMillis() - int64(days)*24*60*60*1000
+
+	if result := <-Srv.Store.NameRedirect().Get(redirectType, teamId, oldName, since); result.Err != nil {
+		if result.Err.StatusCode != http.StatusNotFound {
+			l4g.Error(result.Err.Error())
+		}
+		return ""
+	} else {
+		return result.Data.(*model.NameRedirect).TargetId
+	}
+}
+
+// ResolveTeamByName works like GetTeamByName, but if there's no team with the name, it returns the team
+// that was renamed from it within the grace period. Callers can compare the name of the returned team
+// with the one they asked for to tell if they were redirected.
+func ResolveTeamByName(name string) (*model.Team, *model.AppError) {
+	team, err := GetTeamByName(name)
+	if err == nil || err.StatusCode != http.StatusNotFound {
+		return team, err
+	}
+
+	if targetId := getNameRedirect(model.NAME_REDIRECT_TYPE_TEAM, "", name); targetId != "" {
+		if target, targetErr := GetTeam(targetId); targetErr == nil && target.DeleteAt == 0 {
+			return target, nil
+		}
+	}
+
+	return nil, err
+}
+
+// ResolveChannelByName works like GetChannelByName, but if there's no channel with the name in the team,
+// it returns the channel that was renamed from it within the grace period.
+func ResolveChannelByName(channelName string, teamId string) (*model.Channel, *model.AppError) {
+	channel, err := GetChannelByName(channelName, teamId)
+	if err == nil || err.StatusCode != http.StatusNotFound {
+		return channel, err
+	}
+
+	if targetId := getNameRedirect(model.NAME_REDIRECT_TYPE_CHANNEL, teamId, channelName); targetId != "" {
+		if target, targetErr := GetChannel(targetId); targetErr == nil && target.DeleteAt == 0 && target.TeamId == teamId {
+			return target, nil
+		}
+	}
+
+	return nil, err
+}
+
+// ResolveChannelByNameForTeamName resolves both the team name and the channel name, following renames
+// of either.
+func ResolveChannelByNameForTeamName(channelName string, teamName string) (*model.Channel, *model.AppError) {
+	team, err := ResolveTeamByName(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveChannelByName(channelName, team.Id)
+}
@@ -4,12 +4,9 @@
 package app
 
 import (
-	"crypto/tls"
 	"fmt"
 	"html"
 	"html/template"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"path/filepath"
 	"sort"
@@ -24,6 +21,16 @@ import (
 	"github.com/nicksnyder/go-i18n/i18n"
 )
 
+// incrementMentionCount counts a mention against the thread for replies and against the channel
+// for everything else. Direct and group messages always count against the channel.
+func incrementMentionCount(post *model.Post, channel *model.Channel, userId string) store.StoreChannel {
+	if len(post.RootId) > 0 && channel.Type != model.CHANNEL_DIRECT && channel.Type != model.CHANNEL_GROUP {
+		return Srv.Store.Post().IncrementThreadMentionCount(post.RootId, userId)
+	}
+
+	return Srv.Store.Channel().IncrementMentionCount(post.ChannelId, userId)
+}
+
 func SendNotifications(post *model.Post, team *model.Team, channel *model.Channel, sender *model.User) ([]string, *model.AppError) {
 	pchan := Srv.Store.User().GetAllProfilesInChannel(channel.Id, true)
 	cmnchan := Srv.Store.Channel().GetAllChannelMembersNotifyPropsForChannel(channel.Id, true)
@@ -72,17 +79,29 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 		var potentialOtherMentions []string
 		mentionedUserIds, potentialOtherMentions, hereNotification, channelNotification, allNotification = GetExplicitMentions(post.Message, keywords)
 
-		// get users that have comment thread mentions enabled
+		// get followers of the thread that have comment thread mentions enabled
 		if len(post.RootId) > 0 {
-			if result := <-Srv.Store.Post().Get(post.RootId); result.Err != nil {
+			rpchan := Srv.Store.Post().GetSingle(post.RootId)
+			tfchan := Srv.Store.Post().GetThreadFollowers(post.RootId)
+
+			var root *model.Post
+			if result := <-rpchan; result.Err != nil {
+				return nil, result.Err
+			} else {
+				root = result.Data.(*model.Post)
+			}
+
+			if result := <-tfchan; result.Err != nil {
 				return nil, result.Err
 			} else {
-				list := result.Data.(*model.PostList)
+				for _, followerId := range result.Data.([]string) {
+					profile, ok := profileMap[followerId]
+					if !ok {
+						continue
+					}
 
-				for _, threadPost := range list.Posts {
-					profile := profileMap[threadPost.UserId]
-					if profile.NotifyProps["comments"] == "any" || (profile.NotifyProps["comments"] == "root" && threadPost.Id == list.Order[0]) {
-						mentionedUserIds[threadPost.UserId] = true
+					if profile.NotifyProps["comments"] == "any" || (profile.NotifyProps["comments"] == "root" && followerId == root.UserId) {
+						mentionedUserIds[followerId] = true
 					}
 				}
 			}
@@ -114,7 +133,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	mentionedUsersList := make([]string, 0, len(mentionedUserIds))
 	for id := range mentionedUserIds {
 		mentionedUsersList = append(mentionedUsersList, id)
-		updateMentionChans = append(updateMentionChans, Srv.Store.Channel().IncrementMentionCount(post.ChannelId, id))
+		updateMentionChans = append(updateMentionChans, incrementMentionCount(post, channel, id))
 	}
 
 	senderName := ""
@@ -149,7 +168,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 		senderUsername = sender.Username
 	}
 
-	if utils.Cfg.EmailSettings.SendEmailNotifications {
+	if Config().EmailSettings.SendEmailNotifications {
 		for _, id := range mentionedUsersList {
 			userAllowsEmails := profileMap[id].NotifyProps[model.EMAIL_NOTIFY_PROP] != "false"
 			if channelEmail, ok := channelMemberNotifyPropsMap[id][model.EMAIL_NOTIFY_PROP]; ok {
@@ -178,41 +197,41 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 
 	T := utils.GetUserTranslations(sender.Locale)
 
-	// If the channel has more than 1K users then @here is disabled
-	if hereNotification && int64(len(profileMap)) > *utils.Cfg.TeamSettings.MaxNotificationsPerChannel {
+	// If the channel has too many members then @here is disabled
+	if hereNotification && int64(len(profileMap)) > getChannelMentionsMemberLimit() {
 		hereNotification = false
 		SendEphemeralPost(
 			team.Id,
 			post.UserId,
 			&model.Post{
 				ChannelId: post.ChannelId,
-				Message:   T("api.post.disabled_here", map[string]interface{}{"Users": *utils.Cfg.TeamSettings.MaxNotificationsPerChannel}),
+				Message:   T("api.post.disabled_here", map[string]interface{}{"Users": getChannelMentionsMemberLimit()}),
 				CreateAt:  post.CreateAt + 1,
 			},
 		)
 	}
 
-	// If the channel has more than 1K users then @channel is disabled
-	if channelNotification && int64(len(profileMap)) > *utils.Cfg.TeamSettings.MaxNotificationsPerChannel {
+	// If the channel has too many members then @channel is disabled
+	if channelNotification && int64(len(profileMap)) > getChannelMentionsMemberLimit() {
 		SendEphemeralPost(
 			team.Id,
 			post.UserId,
 			&model.Post{
 				ChannelId: post.ChannelId,
-				Message:   T("api.post.disabled_channel", map[string]interface{}{"Users": *utils.Cfg.TeamSettings.MaxNotificationsPerChannel}),
+				Message:   T("api.post.disabled_channel", map[string]interface{}{"Users": getChannelMentionsMemberLimit()}),
 				CreateAt:  post.CreateAt + 1,
 			},
 		)
 	}
 
-	// If the channel has more than 1K users then @all is disabled
-	if allNotification && int64(len(profileMap)) > *utils.Cfg.TeamSettings.MaxNotificationsPerChannel {
+	// If the channel has too many members then @all is disabled
+	if allNotification && int64(len(profileMap)) > getChannelMentionsMemberLimit() {
 		SendEphemeralPost(
 			team.Id,
 			post.UserId,
 			&model.Post{
 				ChannelId: post.ChannelId,
-				Message:   T("api.post.disabled_all", map[string]interface{}{"Users": *utils.Cfg.TeamSettings.MaxNotificationsPerChannel}),
+				Message:   T("api.post.disabled_all", map[string]interface{}{"Users": getChannelMentionsMemberLimit()}),
 				CreateAt:  post.CreateAt + 1,
 			},
 		)
@@ -230,7 +249,7 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 
 			if status.Status == model.STATUS_ONLINE && profileFound && !alreadyMentioned {
 				mentionedUsersList = append(mentionedUsersList, status.UserId)
-				updateMentionChans = append(updateMentionChans, Srv.Store.Channel().IncrementMentionCount(post.ChannelId, status.UserId))
+				updateMentionChans = append(updateMentionChans, incrementMentionCount(post, channel, status.UserId))
 			}
 		}
 	}
@@ -245,8 +264,8 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	}
 
 	sendPushNotifications := false
-	if *utils.Cfg.EmailSettings.SendPushNotifications {
-		pushServer := *utils.Cfg.EmailSettings.PushNotificationServer
+	if *Config().EmailSettings.SendPushNotifications {
+		pushServer := *Config().EmailSettings.PushNotificationServer
 		if pushServer == model.MHPNS && (!utils.IsLicensed || !*utils.License.Features.MHPNS) {
 			l4g.Warn(utils.T("api.post.send_notifications_and_forget.push_notification.mhpnsWarn"))
 			sendPushNotifications = false
@@ -283,6 +302,44 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 		}
 	}
 
+	publishPostedEvent(post, team, channel, channelName, senderUsername, fchan, mentionedUsersList)
+	return mentionedUsersList, nil
+}
+
+// sendPostedEvent lets the channel members know about a new post without sending any notifications. It is used
+// for posts created alongside another post whose notifications were already sent.
+func sendPostedEvent(post *model.Post, team *model.Team, channel *model.Channel, sender *model.User) *model.AppError {
+	channelName := channel.DisplayName
+	if channel.Type == model.CHANNEL_GROUP {
+		if result := <-Srv.Store.User().GetAllProfilesInChannel(channel.Id, true); result.Err != nil {
+			return result.Err
+		} else {
+			userList := []*model.User{}
+			for _, u := range result.Data.(map[string]*model.User) {
+				if u.Id != sender.Id {
+					userList = append(userList, u)
+				}
+			}
+			userList = append(userList, sender)
+			channelName = model.GetGroupDisplayNameFromUsers(userList, false)
+		}
+	}
+
+	senderUsername := sender.Username
+	if value, ok := post.Props["override_username"]; ok && post.Props["from_webhook"] == "true" {
+		senderUsername = value.(string)
+	}
+
+	var fchan store.StoreChannel
+	if len(post.FileIds) != 0 {
+		fchan = Srv.Store.FileInfo().GetForPost(post.Id, true, true)
+	}
+
+	publishPostedEvent(post, team, channel, channelName, senderUsername, fchan, nil)
+	return nil
+}
+
+func publishPostedEvent(post *model.Post, team *model.Team, channel *model.Channel, channelName string, senderUsername string, fchan store.StoreChannel, mentionedUsersList []string) {
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", post.ChannelId, "", nil)
 	message.Add("post", post.ToJson())
 	message.Add("channel_type", channel.Type)
@@ -314,7 +371,6 @@ func SendNotifications(post *model.Post, team *model.Team, channel *model.Channe
 	}
 
 	Publish(message)
-	return mentionedUsersList, nil
 }
 
 func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Channel, team *model.Team, senderName string, sender *model.User) *model.AppError {
@@ -337,11 +393,11 @@ func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Ch
 				team = teams[0]
 			} else {
 				// in case the user hasn't joined any teams we send them to the select_team page
-				team = &model.Team{Name: "select_team", DisplayName: utils.Cfg.TeamSettings.SiteName}
+				team = &model.Team{Name: "select_team", DisplayName: Config().TeamSettings.SiteName}
 			}
 		}
 	}
-	if *utils.Cfg.EmailSettings.EnableEmailBatching {
+	if *Config().EmailSettings.EnableEmailBatching {
 		var sendBatched bool
 
 		if result := <-Srv.Store.Preference().Get(user.Id, model.PREFERENCE_CATEGORY_NOTIFICATIONS, model.PREFERENCE_NAME_EMAIL_INTERVAL); result.Err != nil {
@@ -402,7 +458,7 @@ func sendNotificationEmail(post *model.Post, user *model.User, channel *model.Ch
 			"ChannelName": channelName, "Month": month, "Day": day, "Year": year}
 	}
 
-	subject := fmt.Sprintf("[%v] %v", utils.Cfg.TeamSettings.SiteName, userLocale(mailTemplate, mailParameters))
+	subject := fmt.Sprintf("[%v] %v", Config().TeamSettings.SiteName, userLocale(mailTemplate, mailParameters))
 
 	bodyPage := utils.NewHTMLTemplate("post_body", user.Locale)
 	bodyPage.Props["SiteURL"] = utils.GetSiteURL()
@@ -492,7 +548,7 @@ func sendPushNotification(post *model.Post, user *model.User, channel *model.Cha
 	msg.ChannelId = channel.Id
 	msg.ChannelName = channel.Name
 
-	if *utils.Cfg.EmailSettings.PushNotificationContents == model.FULL_NOTIFICATION {
+	if *Config().EmailSettings.PushNotificationContents == model.FULL_NOTIFICATION {
 		if channel.Type == model.CHANNEL_DIRECT {
 			msg.Category = model.CATEGORY_DM
 			msg.Message = senderName + ": " + model.ClearMentionTags(post.Message)
@@ -556,22 +612,9 @@ func ClearPushNotification(userId string, channelId string) *model.AppError {
 func sendToPushProxy(msg model.PushNotification, session *model.Session) {
 	msg.ServerId = utils.CfgDiagnosticId
 
-	tr := &http.Transport{
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
-		DisableKeepAlives: true,
-	}
-	httpClient := &http.Client{Transport: tr}
-	request, _ := http.NewRequest("POST", *utils.Cfg.EmailSettings.PushNotificationServer+model.API_URL_SUFFIX_V1+"/send_push", strings.NewReader(msg.ToJson()))
-
-	if resp, err := httpClient.Do(request); err != nil {
+	if pushResponse, err := sendToPushProxies(msg); err != nil {
 		l4g.Error("Device push reported as error for UserId=%v SessionId=%v message=%v", session.UserId, session.Id, err.Error())
 	} else {
-		pushResponse := model.PushResponseFromJson(resp.Body)
-		if resp.Body != nil {
-			ioutil.ReadAll(resp.Body)
-			resp.Body.Close()
-		}
-
 		if pushResponse[model.PUSH_STATUS] == model.PUSH_STATUS_REMOVE {
 			l4g.Info("Device was reported as removed for UserId=%v SessionId=%v removing push for this session", session.UserId, session.Id)
 			AttachDeviceId(session.Id, "", session.ExpiresAt)
@@ -712,6 +755,17 @@ func GetExplicitMentions(message string, keywords map[string][]string) (map[stri
 	return mentioned, potentialOthersMentioned, hereMentioned, channelMentioned, allMentioned
 }
 
+// getChannelMentionsMemberLimit returns the number of members above which @channel, @all and @here
+// aren't expanded to every member of a channel.
+func getChannelMentionsMemberLimit() int64 {
+	limit := *Config().TeamSettings.MaxNotificationsPerChannel
+	if threshold := *Config().TeamSettings.LargeChannelThreshold; threshold > 0 && threshold < limit {
+		limit = threshold
+	}
+
+	return limit
+}
+
 // Given a map of user IDs to profiles, returns a list of mention
 // keywords for all users in the channel.
 func GetMentionKeywordsInChannel(profiles map[string]*model.User) map[string][]string {
@@ -737,7 +791,7 @@ func GetMentionKeywordsInChannel(profiles map[string]*model.User) map[string][]s
 		}
 
 		// Add @channel and @all to keywords if user has them turned on
-		if int64(len(profiles)) < *utils.Cfg.TeamSettings.MaxNotificationsPerChannel && profile.NotifyProps["channel"] == "true" {
+		if int64(len(profiles)) < getChannelMentionsMemberLimit() && profile.NotifyProps["channel"] == "true" {
 			keywords["@channel"] = append(keywords["@channel"], profile.Id)
 			keywords["@all"] = append(keywords["@all"], profile.Id)
 		}
@@ -786,6 +840,10 @@ func DoesNotifyPropsAllowPushNotification(user *model.User, channelNotifyProps m
 }
 
 func DoesStatusAllowPushNotification(userNotifyProps model.StringMap, status *model.Status, channelId string) bool {
+	if status.Status == model.STATUS_DND {
+		return false
+	}
+
 	if pushStatus, ok := userNotifyProps["push_status"]; (pushStatus == model.STATUS_ONLINE || !ok) && (status.ActiveChannel != channelId || model.GetMillis()-status.LastActivityAt > model.STATUS_CHANNEL_TIMEOUT) {
 		return true
 	} else if pushStatus == model.STATUS_AWAY && (status.Status == model.STATUS_AWAY || status.Status == model.STATUS_OFFLINE) {
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 func TestSendNotifications(t *testing.T) {
@@ -310,6 +311,49 @@ func TestGetMentionKeywords(t *testing.T) {
 	} else if ids, ok := mentions["@all"]; !ok || len(ids) != 2 || (ids[0] != user3.Id && ids[1] != user3.Id) || (ids[0] != user4.Id && ids[1] != user4.Id) {
 		t.Fatal("should've mentioned user3 and user4 with @all")
 	}
+
+	// channel wide mentions aren't expanded in large channels
+	largeChannelThreshold := *utils.Cfg.TeamSettings.LargeChannelThreshold
+	defer func() {
+		*utils.Cfg.TeamSettings.LargeChannelThreshold = largeChannelThreshold
+	}()
+	*utils.Cfg.TeamSettings.LargeChannelThreshold = 3
+
+	mentions = GetMentionKeywordsInChannel(profiles)
+	if _, ok := mentions["@channel"]; ok {
+		t.Fatal("shouldn't have mentioned anyone with @channel in a large channel")
+	} else if _, ok := mentions["@all"]; ok {
+		t.Fatal("shouldn't have mentioned anyone with @all in a large channel")
+	}
+}
+
+func TestGetChannelMentionsMemberLimit(t *testing.T) {
+	Setup()
+
+	maxNotificationsPerChannel := *utils.Cfg.TeamSettings.MaxNotificationsPerChannel
+	largeChannelThreshold := *utils.Cfg.TeamSettings.LargeChannelThreshold
+	defer func() {
+		*utils.Cfg.TeamSettings.MaxNotificationsPerChannel = maxNotificationsPerChannel
+		*utils.Cfg.TeamSettings.LargeChannelThreshold = largeChannelThreshold
+	}()
+
+	*utils.Cfg.TeamSettings.MaxNotificationsPerChannel = 1000
+	*utils.Cfg.TeamSettings.LargeChannelThreshold = 500
+	if limit := getChannelMentionsMemberLimit(); limit != 500 {
+		t.Fatal("should have used the large channel threshold")
+	}
+
+	*utils.Cfg.TeamSettings.LargeChannelThreshold = 5000
+	if limit := getChannelMentionsMemberLimit(); limit != 1000 {
+		t.Fatal("should have used the maximum notifications per channel")
+	}
+
+	*utils.Cfg.TeamSettings.LargeChannelThreshold = 0
+	if limit := getChannelMentionsMemberLimit(); limit != 1000 {
+		t.Fatal("should have ignored a disabled large channel threshold")
+	} else if IsLargeChannelMemberCount(1000000) {
+		t.Fatal("no channel should be large with the threshold disabled")
+	}
 }
 
 func TestDoesNotifyPropsAllowPushNotification(t *testing.T) {
@@ -600,4 +644,13 @@ func TestDoesStatusAllowPushNotification(t *testing.T) {
 	if DoesStatusAllowPushNotification(userNotifyProps, online, "") {
 		t.Fatal("Should have been false")
 	}
+
+	// WHEN user is in do not disturb
+	dnd := &model.Status{UserId: userId, Status: model.STATUS_DND, Manual: true, LastActivityAt: 0, ActiveChannel: ""}
+	for _, pushStatus := range []string{model.STATUS_ONLINE, model.STATUS_AWAY, model.STATUS_OFFLINE} {
+		userNotifyProps["push_status"] = pushStatus
+		if DoesStatusAllowPushNotification(userNotifyProps, dnd, channelId) {
+			t.Fatal("Should have been false")
+		}
+	}
 }
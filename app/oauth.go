@@ -42,8 +42,125 @@ func RevokeAccessToken(token string) *model.AppError {
 	return nil
 }
 
+// RevokeOAuthToken ends the grant that the given access or refresh token belongs to, as long as it was
+// issued to clientId. Unknown tokens are ignored so that clients cannot probe for valid tokens.
+func RevokeOAuthToken(clientId, token, tokenTypeHint string) *model.AppError {
+	var accessData *model.AccessData
+
+	first, second := Srv.Store.OAuth().GetAccessData, Srv.Store.OAuth().GetAccessDataByRefreshToken
+	if tokenTypeHint == model.REFRESH_TOKEN_TYPE_HINT {
+		first, second = second, first
+	}
+
+	if result := <-first(token); result.Err == nil {
+		accessData = result.Data.(*model.AccessData)
+	} else if result := <-second(token); result.Err == nil {
+		accessData = result.Data.(*model.AccessData)
+	}
+
+	if accessData == nil || accessData.ClientId != clientId {
+		return nil
+	}
+
+	schan := Srv.Store.Session().Remove(accessData.Token)
+
+	if result := <-Srv.Store.OAuth().RemoveAccessDataByRefreshToken(accessData.RefreshToken); result.Err != nil {
+		return model.NewLocAppError("RevokeOAuthToken", "api.oauth.revoke_access_token.del_token.app_error", nil, "")
+	}
+
+	if result := <-schan; result.Err != nil {
+		return model.NewLocAppError("RevokeOAuthToken", "api.oauth.revoke_access_token.del_session.app_error", nil, "")
+	}
+
+	ClearSessionCacheForUser(accessData.UserId)
+
+	return nil
+}
+
+const (
+	OPENID_DISCOVERY_CACHE_SIZE = 10
+	OPENID_DISCOVERY_CACHE_SEC  = 60 * 60 // 1 hour
+)
+
+var openIdDiscoveryCache *utils.Cache = utils.NewLru(OPENID_DISCOVERY_CACHE_SIZE)
+
+// getSSOService returns the settings for logging in with an OAuth service. The endpoints of the
+// generic OpenID Connect service aren't configured and are found through discovery from its issuer
+// instead.
+func getSSOService(service string) (*model.SSOSettings, *model.AppError) {
+	if service != model.SERVICE_OPENID {
+		return Config().GetSSOService(service), nil
+	}
+
+	settings := Config().OpenIdSettings
+	sso := &model.SSOSettings{
+		Enable: *settings.Enable,
+		Id:     *settings.Id,
+		Secret: *settings.Secret,
+		Scope:  *settings.Scope,
+	}
+
+	if !sso.Enable {
+		return sso, nil
+	}
+
+	configuration, err := getOpenIdProviderConfiguration(*settings.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	sso.AuthEndpoint = configuration.AuthorizationEndpoint
+	sso.TokenEndpoint = configuration.TokenEndpoint
+	sso.UserApiEndpoint = configuration.UserInfoEndpoint
+
+	return sso, nil
+}
+
+// getOpenIdProviderConfiguration fetches the discovery document of an OpenID Connect provider. It is
+// cached for a while since it rarely changes.
+func getOpenIdProviderConfiguration(issuer string) (*model.OpenIdConfiguration, *model.AppError) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	if cached, ok := openIdDiscoveryCache.Get(issuer); ok {
+		return cached.(*model.OpenIdConfiguration), nil
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.Get(issuer + model.OPENID_CONFIGURATION_PATH)
+	if err != nil {
+		return nil, model.NewAppError("getOpenIdProviderConfiguration", "app.openid.discovery.app_error", nil, "issuer="+issuer+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.NewAppError("getOpenIdProviderConfiguration", "app.openid.discovery.app_error", nil, "issuer="+issuer+", status="+resp.Status, http.StatusInternalServerError)
+	}
+
+	configuration := model.OpenIdConfigurationFromJson(resp.Body)
+	if configuration == nil || strings.TrimSuffix(configuration.Issuer, "/") != issuer ||
+		len(configuration.AuthorizationEndpoint) == 0 || len(configuration.TokenEndpoint) == 0 || len(configuration.UserInfoEndpoint) == 0 {
+		return nil, model.NewAppError("getOpenIdProviderConfiguration", "app.openid.discovery.app_error", nil, "issuer="+issuer+", invalid configuration", http.StatusInternalServerError)
+	}
+
+	openIdDiscoveryCache.AddWithExpiresInSecs(issuer, configuration, OPENID_DISCOVERY_CACHE_SEC)
+
+	return configuration, nil
+}
+
 func GetAuthorizationCode(service string, props map[string]string, loginHint string) (string, *model.AppError) {
-	sso := utils.Cfg.GetSSOService(service)
+	sso, err := getSSOService(service)
+	if err != nil {
+		return "", err
+	}
+
 	if sso != nil && !sso.Enable {
 		return "", model.NewLocAppError("GetAuthorizationCode", "api.user.get_authorization_code.unsupported.app_error", nil, "service="+service)
 	}
@@ -71,7 +188,11 @@ func GetAuthorizationCode(service string, props map[string]string, loginHint str
 }
 
 func AuthorizeOAuthUser(service, code, state, redirectUri string) (io.ReadCloser, string, map[string]string, *model.AppError) {
-	sso := utils.Cfg.GetSSOService(service)
+	sso, appErr := getSSOService(service)
+	if appErr != nil {
+		return nil, "", nil, appErr
+	}
+
 	if sso == nil || !sso.Enable {
 		return nil, "", nil, model.NewLocAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.unsupported.app_error", nil, "service="+service)
 	}
@@ -99,7 +220,7 @@ func AuthorizeOAuthUser(service, code, state, redirectUri string) (io.ReadCloser
 	p.Set("redirect_uri", redirectUri)
 
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
 	}
 	client := &http.Client{Transport: tr}
 	req, _ := http.NewRequest("POST", sso.TokenEndpoint, strings.NewReader(p.Encode()))
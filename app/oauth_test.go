@@ -4,9 +4,13 @@
 package app
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 func TestOAuthRevokeAccessToken(t *testing.T) {
@@ -42,3 +46,49 @@ func TestOAuthRevokeAccessToken(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGetSSOServiceOpenId(t *testing.T) {
+	Setup()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != model.OPENID_CONFIGURATION_PATH {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(model.NewOpenIdConfiguration(server.URL).ToJson()))
+	}))
+	defer server.Close()
+
+	enable := *utils.Cfg.OpenIdSettings.Enable
+	issuer := *utils.Cfg.OpenIdSettings.Issuer
+	id := *utils.Cfg.OpenIdSettings.Id
+	defer func() {
+		*utils.Cfg.OpenIdSettings.Enable = enable
+		*utils.Cfg.OpenIdSettings.Issuer = issuer
+		*utils.Cfg.OpenIdSettings.Id = id
+	}()
+
+	*utils.Cfg.OpenIdSettings.Enable = true
+	*utils.Cfg.OpenIdSettings.Issuer = server.URL + "/"
+	*utils.Cfg.OpenIdSettings.Id = model.NewId()
+
+	if sso, err := getSSOService(model.SERVICE_OPENID); err != nil {
+		t.Fatal(err)
+	} else if sso.Id != *utils.Cfg.OpenIdSettings.Id || sso.AuthEndpoint != server.URL+"/oauth/authorize" ||
+		sso.TokenEndpoint != server.URL+"/oauth/access_token" || sso.UserApiEndpoint != server.URL+model.OPENID_USERINFO_PATH {
+		t.Fatal("should have found the endpoints through discovery")
+	}
+
+	if authUrl, err := GetAuthorizationCode(model.SERVICE_OPENID, map[string]string{}, ""); err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(authUrl, server.URL+"/oauth/authorize?") {
+		t.Fatal("should have sent the user to the provider's authorization endpoint")
+	}
+
+	*utils.Cfg.OpenIdSettings.Issuer = "http://localhost:1/" + model.NewId()
+	if _, err := getSSOService(model.SERVICE_OPENID); err == nil {
+		t.Fatal("should have failed without a discovery document")
+	}
+}
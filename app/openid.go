@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/rsa"
+	"net/http"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+)
+
+var openIdSigningKey *rsa.PrivateKey
+var openIdSigningKeyLock sync.Mutex
+
+// getOpenIdSigningKey returns the key that ID tokens are signed with. The key is generated the first
+// time it's needed and stored so that every server in a cluster signs with the same key.
+func getOpenIdSigningKey() (*rsa.PrivateKey, *model.AppError) {
+	openIdSigningKeyLock.Lock()
+	defer openIdSigningKeyLock.Unlock()
+
+	if openIdSigningKey != nil {
+		return openIdSigningKey, nil
+	}
+
+	if result := <-Srv.Store.System().GetByName(model.SYSTEM_OPENID_SIGNING_KEY); result.Err == nil {
+		key, err := model.OpenIdSigningKeyFromString(result.Data.(*model.System).Value)
+		if err != nil {
+			return nil, model.NewAppError("getOpenIdSigningKey", "app.openid.signing_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		openIdSigningKey = key
+		return openIdSigningKey, nil
+	}
+
+	key, err := model.NewOpenIdSigningKey()
+	if err != nil {
+		return nil, model.NewAppError("getOpenIdSigningKey", "app.openid.signing_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if result := <-Srv.Store.System().Save(&model.System{Name: model.SYSTEM_OPENID_SIGNING_KEY, Value: model.OpenIdSigningKeyToString(key)}); result.Err != nil {
+		// Another server may have saved its key first, in which case that one is used instead
+		if result := <-Srv.Store.System().GetByName(model.SYSTEM_OPENID_SIGNING_KEY); result.Err != nil {
+			return nil, result.Err
+		} else if key, err = model.OpenIdSigningKeyFromString(result.Data.(*model.System).Value); err != nil {
+			return nil, model.NewAppError("getOpenIdSigningKey", "app.openid.signing_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	openIdSigningKey = key
+	return openIdSigningKey, nil
+}
+
+// GetOpenIdJsonWebKeys returns the public keys that clients can check ID tokens with.
+func GetOpenIdJsonWebKeys() (*model.JsonWebKeySet, *model.AppError) {
+	key, err := getOpenIdSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.JsonWebKeySet{Keys: []model.JsonWebKey{model.NewJsonWebKey(&key.PublicKey)}}, nil
+}
+
+// CreateOpenIdToken returns a signed ID token for a user if the scope granted to the client includes
+// openid, or an empty string if it doesn't.
+func CreateOpenIdToken(issuer, clientId string, user *model.User, scope, nonce string) (string, *model.AppError) {
+	if !model.OAuthScopeIsOpenId(scope) {
+		return "", nil
+	}
+
+	key, err := getOpenIdSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	idToken, signErr := model.SignOpenIdToken(model.NewOpenIdToken(issuer, clientId, user, scope, nonce), key)
+	if signErr != nil {
+		return "", model.NewAppError("CreateOpenIdToken", "app.openid.sign.app_error", nil, signErr.Error(), http.StatusInternalServerError)
+	}
+
+	return idToken, nil
+}
+
+// GetOpenIdUserInfo returns the claims about the user of an OAuth session that its scope gives
+// access to.
+func GetOpenIdUserInfo(session *model.Session) (*model.OpenIdClaims, *model.AppError) {
+	scope := session.GetOAuthScope()
+	if !session.IsOAuth || !model.OAuthScopeIsOpenId(scope) {
+		return nil, model.NewAppError("GetOpenIdUserInfo", "app.openid.userinfo.scope.app_error", nil, "session_id="+session.Id, http.StatusForbidden)
+	}
+
+	user, err := GetUser(session.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewOpenIdClaims(user, scope), nil
+}
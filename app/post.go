@@ -4,6 +4,7 @@
 package app
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"net/url"
@@ -66,6 +67,32 @@ func CreatePostAsUser(post *model.Post) (*model.Post, *model.AppError) {
 		return nil, err
 	}
 
+	if err := checkAnnouncementPoster(channel, post.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := checkChannelModeration(channel, post.UserId, model.CHANNEL_MODERATION_CREATE_POST); err != nil {
+		return nil, err
+	}
+
+	if len(channel.TeamId) > 0 {
+		if err := checkTeamPostQuota(channel.TeamId); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, _, hereMentioned, channelMentioned, allMentioned := GetExplicitMentions(post.Message, map[string][]string{}); hereMentioned || channelMentioned || allMentioned {
+		if err := checkChannelModeration(channel, post.UserId, model.CHANNEL_MODERATION_USE_CHANNEL_MENTIONS); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the server can say that a post comes from an integration
+	delete(post.Props, model.POST_PROP_FROM_INTEGRATION)
+	if IsBotUser(post.UserId) {
+		setIntegrationMetadata(post, model.INTEGRATION_TYPE_BOT, post.UserId)
+	}
+
 	if rp, err := CreatePost(post, channel.TeamId, true); err != nil {
 		if err.Id == "api.post.create_post.root_id.app_error" ||
 			err.Id == "api.post.create_post.channel_root_id.app_error" ||
@@ -80,6 +107,12 @@ func CreatePostAsUser(post *model.Post) (*model.Post, *model.AppError) {
 			if result := <-Srv.Store.Channel().UpdateLastViewedAt([]string{post.ChannelId}, post.UserId); result.Err != nil {
 				l4g.Error(utils.T("api.post.create_post.last_viewed.error"), post.ChannelId, post.UserId, result.Err)
 			}
+
+			if !rp.IsSystemMessage() {
+				emojiNames := model.EmojiNamesFromMessage(rp.Message)
+				go RecordEmojiUsage(rp.UserId, emojiNames)
+				go RecordEmojiMessageUsage(emojiNames)
+			}
 		}
 
 		return rp, nil
@@ -88,32 +121,9 @@ func CreatePostAsUser(post *model.Post) (*model.Post, *model.AppError) {
 }
 
 func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.Post, *model.AppError) {
-	var pchan store.StoreChannel
-	if len(post.RootId) > 0 {
-		pchan = Srv.Store.Post().Get(post.RootId)
-	}
-
-	// Verify the parent/child relationships are correct
-	if pchan != nil {
-		if presult := <-pchan; presult.Err != nil {
-			return nil, model.NewLocAppError("createPost", "api.post.create_post.root_id.app_error", nil, "")
-		} else {
-			list := presult.Data.(*model.PostList)
-			if len(list.Posts) == 0 || !list.IsChannelId(post.ChannelId) {
-				return nil, model.NewLocAppError("createPost", "api.post.create_post.channel_root_id.app_error", nil, "")
-			}
-
-			if post.ParentId == "" {
-				post.ParentId = post.RootId
-			}
-
-			if post.RootId != post.ParentId {
-				parent := list.Posts[post.ParentId]
-				if parent == nil {
-					return nil, model.NewLocAppError("createPost", "api.post.create_post.parent_id.app_error", nil, "")
-				}
-			}
-		}
+	root, err := getPostRoot(post)
+	if err != nil {
+		return nil, err
 	}
 
 	post.Hashtags, _ = model.ParseHashtags(post.Message)
@@ -125,10 +135,18 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.P
 		rpost = result.Data.(*model.Post)
 	}
 
+	recordPostIntegrity(rpost)
+
+	if len(teamId) > 0 {
+		go incrementTeamPostCount(teamId)
+	}
+
 	if einterfaces.GetMetricsInterface() != nil {
 		einterfaces.GetMetricsInterface().IncrementPostCreate()
 	}
 
+	go indexPostForSearch(rpost)
+
 	if len(post.FileIds) > 0 {
 		// There's a rare bug where the client sends up duplicate FileIds so protect against that
 		post.FileIds = utils.RemoveDuplicatesFromStringArray(post.FileIds)
@@ -144,14 +162,263 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.P
 		}
 	}
 
-	if err := handlePostEvents(rpost, teamId, triggerWebhooks); err != nil {
+	if root != nil && len(root.RootId) == 0 && !rpost.IsSystemMessage() {
+		followThreadForReply(rpost, root)
+	}
+
+	if err := handlePostEvents(rpost, teamId, triggerWebhooks, true); err != nil {
 		return nil, err
 	}
 
 	return rpost, nil
 }
 
-func handlePostEvents(post *model.Post, teamId string, triggerWebhooks bool) *model.AppError {
+// getPostRoot verifies the parent/child relationships of a reply and returns the root of its thread,
+// or nil if the post isn't a reply.
+func getPostRoot(post *model.Post) (*model.Post, *model.AppError) {
+	if len(post.RootId) == 0 {
+		return nil, nil
+	}
+
+	if presult := <-Srv.Store.Post().Get(post.RootId); presult.Err != nil {
+		return nil, model.NewLocAppError("createPost", "api.post.create_post.root_id.app_error", nil, "")
+	} else {
+		list := presult.Data.(*model.PostList)
+		if len(list.Posts) == 0 || !list.IsChannelId(post.ChannelId) {
+			return nil, model.NewLocAppError("createPost", "api.post.create_post.channel_root_id.app_error", nil, "")
+		}
+
+		if post.ParentId == "" {
+			post.ParentId = post.RootId
+		}
+
+		if post.RootId != post.ParentId {
+			parent := list.Posts[post.ParentId]
+			if parent == nil {
+				return nil, model.NewLocAppError("createPost", "api.post.create_post.parent_id.app_error", nil, "")
+			}
+		}
+
+		return list.Posts[post.RootId], nil
+	}
+}
+
+// CreateReplyAlsoInChannel creates a reply to a thread along with a copy of it at the root of the
+// channel. Both posts are saved together and linked like cross-posted posts so that later edits and
+// deletes apply to both of them. Only the reply sends notifications.
+func CreateReplyAlsoInChannel(post *model.Post) (*model.Post, *model.AppError) {
+	if len(post.RootId) == 0 {
+		return nil, model.NewAppError("CreateReplyAlsoInChannel", "api.post.create_reply_also_in_channel.root_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(post.FileIds) > 0 {
+		return nil, model.NewAppError("CreateReplyAlsoInChannel", "api.post.create_reply_also_in_channel.file_ids.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	var channel *model.Channel
+	if result := <-Srv.Store.Channel().Get(post.ChannelId, true); result.Err != nil {
+		return nil, model.NewAppError("CreateReplyAlsoInChannel", "api.context.invalid_param.app_error", map[string]interface{}{"Name": "post.channel_id"}, result.Err.Error(), http.StatusBadRequest)
+	} else {
+		channel = result.Data.(*model.Channel)
+	}
+
+	if channel.DeleteAt != 0 {
+		return nil, model.NewAppError("CreateReplyAlsoInChannel", "api.post.create_post.can_not_post_to_deleted.error", nil, "", http.StatusBadRequest)
+	}
+
+	root, err := getPostRoot(post)
+	if err != nil {
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	crossPostId := model.NewId()
+	post.Hashtags, _ = model.ParseHashtags(post.Message)
+	post.AddProp(model.POST_PROPS_CROSS_POST_ID, crossPostId)
+
+	channelPost := &model.Post{}
+	*channelPost = *post
+	channelPost.RootId = ""
+	channelPost.ParentId = ""
+	channelPost.PendingPostId = ""
+
+	channelPost.Props = model.StringInterface{}
+	for key, value := range post.Props {
+		channelPost.Props[key] = value
+	}
+	channelPost.AddProp(model.POST_PROPS_THREAD_ROOT_ID, post.RootId)
+
+	if result := <-Srv.Store.Post().SaveCrossPosts([]*model.Post{post, channelPost}); result.Err != nil {
+		return nil, result.Err
+	}
+
+	for _, rpost := range []*model.Post{post, channelPost} {
+		recordPostIntegrity(rpost)
+
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementPostCreate()
+		}
+
+		go indexPostForSearch(rpost)
+	}
+
+	if len(root.RootId) == 0 && !post.IsSystemMessage() {
+		followThreadForReply(post, root)
+	}
+
+	// The posts are already saved so a failure here shouldn't be reported as a failure to post
+	if err := handlePostEvents(post, channel.TeamId, true, true); err != nil {
+		l4g.Error(utils.T("api.post.create_reply_also_in_channel.events.error"), post.Id, err)
+	}
+
+	if err := handlePostEvents(channelPost, channel.TeamId, false, false); err != nil {
+		l4g.Error(utils.T("api.post.create_reply_also_in_channel.events.error"), channelPost.Id, err)
+	}
+
+	if _, ok := post.Props["from_webhook"]; !ok {
+		if result := <-Srv.Store.Channel().UpdateLastViewedAt([]string{post.ChannelId}, post.UserId); result.Err != nil {
+			l4g.Error(utils.T("api.post.create_post.last_viewed.error"), post.ChannelId, post.UserId, result.Err)
+		}
+
+		if !post.IsSystemMessage() {
+			emojiNames := model.EmojiNamesFromMessage(post.Message)
+			go RecordEmojiUsage(post.UserId, emojiNames)
+			go RecordEmojiMessageUsage(emojiNames)
+		}
+	}
+
+	return post, nil
+}
+
+// CreateCrossPosts creates a copy of the post in each of the given channels. The copies are linked
+// through a shared cross post id so that later edits and deletes apply to all of them, and either
+// all of them are created or none are.
+func CreateCrossPosts(post *model.Post, channelIds []string) (*model.PostList, *model.AppError) {
+	channelIds = utils.RemoveDuplicatesFromStringArray(channelIds)
+	if len(channelIds) == 0 || len(channelIds) > model.CROSS_POST_MAX_CHANNELS {
+		return nil, model.NewAppError("CreateCrossPosts", "api.post.create_cross_posts.channel_ids.app_error", map[string]interface{}{"Max": model.CROSS_POST_MAX_CHANNELS}, "", http.StatusBadRequest)
+	}
+
+	if len(post.RootId) > 0 || len(post.ParentId) > 0 {
+		return nil, model.NewAppError("CreateCrossPosts", "api.post.create_cross_posts.root_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(post.FileIds) > 0 {
+		return nil, model.NewAppError("CreateCrossPosts", "api.post.create_cross_posts.file_ids.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	channels := make(map[string]*model.Channel, len(channelIds))
+	for _, channelId := range channelIds {
+		if result := <-Srv.Store.Channel().Get(channelId, true); result.Err != nil {
+			return nil, model.NewAppError("CreateCrossPosts", "api.context.invalid_param.app_error", map[string]interface{}{"Name": "channel_ids"}, result.Err.Error(), http.StatusBadRequest)
+		} else if channel := result.Data.(*model.Channel); channel.DeleteAt != 0 {
+			return nil, model.NewAppError("CreateCrossPosts", "api.post.create_post.can_not_post_to_deleted.error", nil, "channel_id="+channelId, http.StatusBadRequest)
+		} else {
+			channels[channelId] = channel
+		}
+	}
+
+	crossPostId := model.NewId()
+	hashtags, _ := model.ParseHashtags(post.Message)
+
+	posts := make([]*model.Post, 0, len(channelIds))
+	for _, channelId := range channelIds {
+		crossPost := &model.Post{}
+		*crossPost = *post
+		crossPost.Id = ""
+		crossPost.ChannelId = channelId
+		crossPost.Hashtags = hashtags
+
+		crossPost.Props = model.StringInterface{}
+		for key, value := range post.Props {
+			crossPost.Props[key] = value
+		}
+		crossPost.AddProp(model.POST_PROPS_CROSS_POST_ID, crossPostId)
+
+		posts = append(posts, crossPost)
+	}
+
+	if result := <-Srv.Store.Post().SaveCrossPosts(posts); result.Err != nil {
+		return nil, result.Err
+	}
+
+	list := model.NewPostList()
+	for _, rpost := range posts {
+		recordPostIntegrity(rpost)
+
+		if einterfaces.GetMetricsInterface() != nil {
+			einterfaces.GetMetricsInterface().IncrementPostCreate()
+		}
+
+		go indexPostForSearch(rpost)
+
+		// The posts are already saved so a failure here shouldn't be reported as a failure to post
+		if err := handlePostEvents(rpost, channels[rpost.ChannelId].TeamId, true, true); err != nil {
+			l4g.Error(utils.T("api.post.create_cross_posts.events.error"), rpost.Id, err)
+		}
+
+		list.AddPost(rpost)
+		list.AddOrder(rpost.Id)
+	}
+
+	if result := <-Srv.Store.Channel().UpdateLastViewedAt(channelIds, post.UserId); result.Err != nil {
+		l4g.Error(utils.T("api.post.create_post.last_viewed.error"), channelIds, post.UserId, result.Err)
+	}
+
+	if !post.IsSystemMessage() {
+		emojiNames := model.EmojiNamesFromMessage(post.Message)
+		go RecordEmojiUsage(post.UserId, emojiNames)
+		go RecordEmojiMessageUsage(emojiNames)
+	}
+
+	return list, nil
+}
+
+func getCrossPosts(crossPostId string) ([]*model.Post, *model.AppError) {
+	if result := <-Srv.Store.Post().GetCrossPosts(crossPostId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Post), nil
+	}
+}
+
+// updateCrossPosts copies the message of an edited post to the other posts it was cross-posted with.
+func updateCrossPosts(post *model.Post) {
+	crossPosts, err := getCrossPosts(post.GetCrossPostId())
+	if err != nil {
+		l4g.Error(utils.T("api.post.update_cross_posts.error"), post.Id, err)
+		return
+	}
+
+	for _, oldPost := range crossPosts {
+		if oldPost.Id == post.Id || oldPost.Message == post.Message {
+			continue
+		}
+
+		newPost := &model.Post{}
+		*newPost = *oldPost
+
+		newPost.Message = post.Message
+		newPost.EditAt = post.EditAt
+		newPost.Hashtags = post.Hashtags
+
+		if result := <-Srv.Store.Post().Update(newPost, oldPost); result.Err != nil {
+			l4g.Error(utils.T("api.post.update_cross_posts.error"), post.Id, result.Err)
+		} else {
+			rpost := result.Data.(*model.Post)
+
+			sendUpdatedPostEvent(rpost)
+
+			go indexPostForSearch(rpost)
+			go handlePostEventHooks(model.POST_EVENT_HOOK_EVENT_POST_EDITED, rpost)
+			notifySharedChannelChanged(rpost.ChannelId)
+
+			InvalidateCacheForChannelPosts(rpost.ChannelId)
+		}
+	}
+}
+
+func handlePostEvents(post *model.Post, teamId string, triggerWebhooks bool, sendNotifications bool) *model.AppError {
 	var tchan store.StoreChannel
 	if len(teamId) > 0 {
 		tchan = Srv.Store.Team().Get(teamId)
@@ -188,7 +455,11 @@ func handlePostEvents(post *model.Post, teamId string, triggerWebhooks bool) *mo
 		user = result.Data.(*model.User)
 	}
 
-	if _, err := SendNotifications(post, team, channel, user); err != nil {
+	if sendNotifications {
+		if _, err := SendNotifications(post, team, channel, user); err != nil {
+			return err
+		}
+	} else if err := sendPostedEvent(post, team, channel, user); err != nil {
 		return err
 	}
 
@@ -200,6 +471,13 @@ func handlePostEvents(post *model.Post, teamId string, triggerWebhooks bool) *mo
 		}()
 	}
 
+	go handlePostEventHooks(model.POST_EVENT_HOOK_EVENT_POSTED, post)
+	notifySharedChannelChanged(post.ChannelId)
+
+	if sendNotifications {
+		go sendAutoResponse(post, channel)
+	}
+
 	return nil
 }
 
@@ -249,7 +527,7 @@ func SendEphemeralPost(teamId, userId string, post *model.Post) *model.Post {
 
 func UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model.AppError) {
 	if utils.IsLicensed {
-		if *utils.Cfg.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_NEVER {
+		if *Config().ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_NEVER {
 			err := model.NewAppError("UpdatePost", "api.post.update_post.permissions_denied.app_error", nil, "", http.StatusForbidden)
 			return nil, err
 		}
@@ -282,8 +560,8 @@ func UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model.AppError
 		}
 
 		if utils.IsLicensed {
-			if *utils.Cfg.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_TIME_LIMIT && model.GetMillis() > oldPost.CreateAt+int64(*utils.Cfg.ServiceSettings.PostEditTimeLimit*1000) {
-				err := model.NewAppError("UpdatePost", "api.post.update_post.permissions_time_limit.app_error", map[string]interface{}{"timeLimit": *utils.Cfg.ServiceSettings.PostEditTimeLimit}, "", http.StatusBadRequest)
+			if *Config().ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_TIME_LIMIT && model.GetMillis() > oldPost.CreateAt+int64(*Config().ServiceSettings.PostEditTimeLimit*1000) {
+				err := model.NewAppError("UpdatePost", "api.post.update_post.permissions_time_limit.app_error", map[string]interface{}{"timeLimit": *Config().ServiceSettings.PostEditTimeLimit}, "", http.StatusBadRequest)
 				return nil, err
 			}
 		}
@@ -301,6 +579,12 @@ func UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model.AppError
 		newPost.HasReactions = post.HasReactions
 		newPost.FileIds = post.FileIds
 		newPost.Props = post.Props
+
+		// The integration that made a post can't be changed by editing it
+		delete(newPost.Props, model.POST_PROP_FROM_INTEGRATION)
+		if metadata := oldPost.GetIntegrationMetadata(); metadata != nil {
+			newPost.SetIntegrationMetadata(metadata)
+		}
 	}
 
 	if result := <-Srv.Store.Post().Update(newPost, oldPost); result.Err != nil {
@@ -310,8 +594,16 @@ func UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model.AppError
 
 		sendUpdatedPostEvent(rpost)
 
+		go indexPostForSearch(rpost)
+		go handlePostEventHooks(model.POST_EVENT_HOOK_EVENT_POST_EDITED, rpost)
+		notifySharedChannelChanged(rpost.ChannelId)
+
 		InvalidateCacheForChannelPosts(rpost.ChannelId)
 
+		if newPost.Message != oldPost.Message && len(rpost.GetCrossPostId()) > 0 {
+			updateCrossPosts(rpost)
+		}
+
 		return rpost, nil
 	}
 }
@@ -343,7 +635,12 @@ func sendUpdatedPostEvent(post *model.Post) {
 }
 
 func GetPostsPage(channelId string, page int, perPage int) (*model.PostList, *model.AppError) {
-	if result := <-Srv.Store.Post().GetPosts(channelId, page*perPage, perPage, true); result.Err != nil {
+	return GetPostsPageContext(context.Background(), channelId, page, perPage)
+}
+
+// GetPostsPageContext is GetPostsPage with the database queries cancelled along with ctx.
+func GetPostsPageContext(ctx context.Context, channelId string, page int, perPage int) (*model.PostList, *model.AppError) {
+	if result := <-Srv.Store.Post().GetPostsContext(ctx, channelId, page*perPage, perPage, true); result.Err != nil {
 		return nil, result.Err
 	} else {
 		return result.Data.(*model.PostList), nil
@@ -371,7 +668,12 @@ func GetPostsSince(channelId string, time int64) (*model.PostList, *model.AppErr
 }
 
 func GetSinglePost(postId string) (*model.Post, *model.AppError) {
-	if result := <-Srv.Store.Post().GetSingle(postId); result.Err != nil {
+	return GetSinglePostContext(context.Background(), postId)
+}
+
+// GetSinglePostContext is GetSinglePost with the database query cancelled along with ctx.
+func GetSinglePostContext(ctx context.Context, postId string) (*model.Post, *model.AppError) {
+	if result := <-Srv.Store.Post().GetSingleContext(ctx, postId); result.Err != nil {
 		return nil, result.Err
 	} else {
 		return result.Data.(*model.Post), nil
@@ -379,13 +681,26 @@ func GetSinglePost(postId string) (*model.Post, *model.AppError) {
 }
 
 func GetPostThread(postId string) (*model.PostList, *model.AppError) {
-	if result := <-Srv.Store.Post().Get(postId); result.Err != nil {
+	return GetPostThreadContext(context.Background(), postId)
+}
+
+// GetPostThreadContext is GetPostThread with the database queries cancelled along with ctx.
+func GetPostThreadContext(ctx context.Context, postId string) (*model.PostList, *model.AppError) {
+	if result := <-Srv.Store.Post().GetContext(ctx, postId); result.Err != nil {
 		return nil, result.Err
 	} else {
 		return result.Data.(*model.PostList), nil
 	}
 }
 
+func GetPostHistory(postId string) ([]*model.PostHistory, *model.AppError) {
+	if result := <-Srv.Store.Post().GetPostHistory(postId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.PostHistory), nil
+	}
+}
+
 func GetFlaggedPosts(userId string, offset int, limit int) (*model.PostList, *model.AppError) {
 	if result := <-Srv.Store.Post().GetFlaggedPosts(userId, offset, limit); result.Err != nil {
 		return nil, result.Err
@@ -473,20 +788,106 @@ func DeletePost(postId string) (*model.Post, *model.AppError) {
 	} else {
 		post := result.Data.(*model.Post)
 
-		if result := <-Srv.Store.Post().Delete(postId, model.GetMillis()); result.Err != nil {
-			return nil, result.Err
+		if err := checkPostNotHeld(post); err != nil {
+			return nil, err
 		}
 
-		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_DELETED, "", post.ChannelId, "", nil)
-		message.Add("post", post.ToJson())
+		if err := deletePost(post); err != nil {
+			return nil, err
+		}
 
+		if crossPostId := post.GetCrossPostId(); len(crossPostId) > 0 {
+			deleteCrossPosts(crossPostId)
+		}
+
+		return post, nil
+	}
+}
+
+func deletePost(post *model.Post) *model.AppError {
+	if result := <-Srv.Store.Post().Delete(post.Id, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_DELETED, "", post.ChannelId, "", nil)
+	message.Add("post", post.ToJson())
+
+	go Publish(message)
+	go DeletePostFiles(post)
+	go DeleteFlaggedPosts(post.Id)
+	go deletePostFromSearch(post.Id)
+	go handlePostEventHooks(model.POST_EVENT_HOOK_EVENT_POST_DELETED, post)
+	notifySharedChannelChanged(post.ChannelId)
+
+	InvalidateCacheForChannelPosts(post.ChannelId)
+
+	return nil
+}
+
+// RestorePost brings back a deleted post along with the replies, reactions and files that were
+// deleted with it. A reply can't be restored while the post that it replies to is deleted.
+func RestorePost(postId string) (*model.Post, *model.AppError) {
+	var post *model.Post
+	if result := <-Srv.Store.Post().GetPostsByIdsIncludeDeleted([]string{postId}); result.Err != nil {
+		return nil, result.Err
+	} else if posts := result.Data.([]*model.Post); len(posts) == 0 {
+		return nil, model.NewAppError("RestorePost", "app.post.restore.not_found.app_error", nil, "id="+postId, http.StatusNotFound)
+	} else {
+		post = posts[0]
+	}
+
+	if len(post.RootId) > 0 {
+		if result := <-Srv.Store.Post().GetSingle(post.RootId); result.Err != nil {
+			return nil, model.NewAppError("RestorePost", "app.post.restore.root_deleted.app_error", nil, "id="+postId, http.StatusBadRequest)
+		}
+	}
+
+	var restored []*model.Post
+	if result := <-Srv.Store.Post().Restore(postId, model.GetMillis()); result.Err != nil {
+		return nil, result.Err
+	} else {
+		restored = result.Data.([]*model.Post)
+	}
+
+	for _, rpost := range restored {
+		if result := <-Srv.Store.FileInfo().RestoreForPost(rpost.Id); result.Err != nil {
+			l4g.Warn(utils.T("app.post.restore.files.warn"), rpost.Id, result.Err)
+		}
+
+		InvalidateCacheForReactions(rpost.Id)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_RESTORED, "", rpost.ChannelId, "", nil)
+		message.Add("post", rpost.ToJson())
 		go Publish(message)
-		go DeletePostFiles(post)
-		go DeleteFlaggedPosts(post.Id)
 
-		InvalidateCacheForChannelPosts(post.ChannelId)
+		go indexPostForSearch(rpost)
 
-		return post, nil
+		if rpost.Id == postId {
+			post = rpost
+		}
+	}
+
+	InvalidateCacheForChannelPosts(post.ChannelId)
+
+	return post, nil
+}
+
+// deleteCrossPosts deletes the remaining posts that share the given cross post id.
+func deleteCrossPosts(crossPostId string) {
+	crossPosts, err := getCrossPosts(crossPostId)
+	if err != nil {
+		l4g.Error(utils.T("api.post.delete_cross_posts.error"), crossPostId, err)
+		return
+	}
+
+	for _, post := range crossPosts {
+		if err := checkPostNotHeld(post); err != nil {
+			continue
+		}
+
+		if err := deletePost(post); err != nil {
+			l4g.Error(utils.T("api.post.delete_cross_posts.error"), crossPostId, err)
+		}
 	}
 }
 
@@ -509,10 +910,30 @@ func DeletePostFiles(post *model.Post) {
 
 func SearchPostsInTeam(terms string, userId string, teamId string, isOrSearch bool) (*model.PostList, *model.AppError) {
 	paramsList := model.ParseSearchParams(terms)
+	for _, params := range paramsList {
+		params.OrTerms = isOrSearch
+	}
+
+	// The search engine doesn't index reactions, so those searches always use the database
+	filtersByReaction := false
+	for _, params := range paramsList {
+		if params.HasReaction || len(params.ReactedBy) > 0 {
+			filtersByReaction = true
+		}
+	}
+
+	// Fall back to searching the database if the search engine fails
+	if engine := einterfaces.GetSearchEngineInterface(); engine != nil && engine.IsSearchingEnabled() && !filtersByReaction {
+		if posts, err := searchPostsInTeamWithEngine(engine, paramsList, userId, teamId); err != nil {
+			l4g.Warn(utils.T("app.search_engine.search_posts.warn"), err.Error())
+		} else {
+			return posts, nil
+		}
+	}
+
 	channels := []store.StoreChannel{}
 
 	for _, params := range paramsList {
-		params.OrTerms = isOrSearch
 		// don't allow users to search for everything
 		if params.Terms != "*" {
 			channels = append(channels, Srv.Store.Post().Search(teamId, userId, params))
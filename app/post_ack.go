@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+// UpdateChannelMemberRead records the most recent post of the channel as read by the user. If the user
+// hadn't seen it before, the members of the channel are told so that authors can see who read their posts.
+func UpdateChannelMemberRead(channelId, userId string) *model.AppError {
+	var lastPost *model.Post
+	if result := <-Srv.Store.Post().GetPosts(channelId, 0, 1, true); result.Err != nil {
+		return result.Err
+	} else if list := result.Data.(*model.PostList); len(list.Order) == 0 {
+		return nil
+	} else {
+		lastPost = list.Posts[list.Order[0]]
+	}
+
+	if result := <-Srv.Store.ChannelMemberRead().Get(channelId, userId); result.Err == nil && result.Data.(*model.ChannelMemberRead).LastPostId == lastPost.Id {
+		return nil
+	}
+
+	read := &model.ChannelMemberRead{ChannelId: channelId, UserId: userId, LastPostId: lastPost.Id}
+	if result := <-Srv.Store.ChannelMemberRead().Save(read); result.Err != nil {
+		return result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_READ, "", channelId, "", nil)
+	message.Add("user_id", userId)
+	message.Add("last_post_id", read.LastPostId)
+	message.Add("last_read_at", read.LastReadAt)
+	go Publish(message)
+
+	return nil
+}
+
+// GetPostAcks returns a read receipt for each member of the post's channel other than its author that
+// has viewed the channel since the post was made.
+func GetPostAcks(post *model.Post) ([]*model.PostAck, *model.AppError) {
+	if !*Config().TeamSettings.EnableReadReceipts {
+		return nil, model.NewAppError("GetPostAcks", "app.post_ack.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	var reads []*model.ChannelMemberRead
+	if result := <-Srv.Store.ChannelMemberRead().GetReadSince(post.ChannelId, post.CreateAt); result.Err != nil {
+		return nil, result.Err
+	} else {
+		reads = result.Data.([]*model.ChannelMemberRead)
+	}
+
+	acks := []*model.PostAck{}
+	for _, read := range reads {
+		if read.UserId != post.UserId {
+			acks = append(acks, &model.PostAck{PostId: post.Id, UserId: read.UserId, AckAt: read.LastReadAt})
+		}
+	}
+
+	return acks, nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// postEventHookRetryInterval is how long to wait before retrying a failed delivery. It doubles
+// after each attempt.
+var postEventHookRetryInterval = time.Second
+
+func CreatePostEventHook(hook *model.PostEventHook) (*model.PostEventHook, *model.AppError) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("CreatePostEventHook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if err := checkPostEventHookChannel(hook); err != nil {
+		return nil, err
+	}
+
+	hook.Id = ""
+	hook.Secret = ""
+
+	if result := <-Srv.Store.PostEventHook().Save(hook); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostEventHook), nil
+	}
+}
+
+func UpdatePostEventHook(oldHook, updatedHook *model.PostEventHook) (*model.PostEventHook, *model.AppError) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("UpdatePostEventHook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	updatedHook.Id = oldHook.Id
+	updatedHook.Secret = oldHook.Secret
+	updatedHook.CreatorId = oldHook.CreatorId
+	updatedHook.TeamId = oldHook.TeamId
+	updatedHook.CreateAt = oldHook.CreateAt
+	updatedHook.DeleteAt = oldHook.DeleteAt
+
+	if err := checkPostEventHookChannel(updatedHook); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.PostEventHook().Update(updatedHook); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostEventHook), nil
+	}
+}
+
+// checkPostEventHookChannel checks that the channel of a hook is part of the hook's team.
+func checkPostEventHookChannel(hook *model.PostEventHook) *model.AppError {
+	if len(hook.ChannelId) == 0 {
+		return nil
+	}
+
+	channel, err := GetChannel(hook.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	if channel.TeamId != hook.TeamId || channel.DeleteAt != 0 {
+		return model.NewAppError("checkPostEventHookChannel", "api.post_event_hook.channel.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func GetPostEventHook(hookId string) (*model.PostEventHook, *model.AppError) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("GetPostEventHook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.PostEventHook().Get(hookId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostEventHook), nil
+	}
+}
+
+func GetPostEventHooksForTeamPage(teamId string, page, perPage int) ([]*model.PostEventHook, *model.AppError) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("GetPostEventHooksForTeamPage", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.PostEventHook().GetByTeam(teamId, page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.PostEventHook), nil
+	}
+}
+
+func DeletePostEventHook(hookId string) *model.AppError {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return model.NewAppError("DeletePostEventHook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if result := <-Srv.Store.PostEventHook().Delete(hookId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func RegenPostEventHookSecret(hook *model.PostEventHook) (*model.PostEventHook, *model.AppError) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("RegenPostEventHookSecret", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	hook.Secret = model.NewId()
+
+	if result := <-Srv.Store.PostEventHook().Update(hook); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PostEventHook), nil
+	}
+}
+
+// handlePostEventHooks sends a post event to the hooks on the post's channel and on its team.
+// Unlike outgoing webhooks, every post is sent, including the posts made by integrations.
+func handlePostEventHooks(event string, post *model.Post) {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
+		return
+	}
+
+	channel, err := GetChannel(post.ChannelId)
+	if err != nil {
+		l4g.Error(utils.T("app.post_event_hook.handle.error"), post.Id, err.Error())
+		return
+	}
+
+	// Hooks belong to a team so there are none for direct and group messages
+	if len(channel.TeamId) == 0 {
+		return
+	}
+
+	var hooks []*model.PostEventHook
+	if result := <-Srv.Store.PostEventHook().GetForChannel(channel.TeamId, channel.Id); result.Err != nil {
+		l4g.Error(utils.T("app.post_event_hook.handle.error"), post.Id, result.Err.Error())
+		return
+	} else {
+		hooks = result.Data.([]*model.PostEventHook)
+	}
+
+	for _, hook := range hooks {
+		if !hook.WantsEvent(event) {
+			continue
+		}
+
+		payload := &model.PostEventHookPayload{
+			Event:     event,
+			HookId:    hook.Id,
+			TeamId:    channel.TeamId,
+			ChannelId: channel.Id,
+			Timestamp: model.GetMillis(),
+			Post:      post,
+		}
+
+		go deliverPostEventHook(hook, payload)
+	}
+}
+
+// deliverPostEventHook posts a payload to the callback URL of a hook, retrying with a growing
+// interval until it's accepted, it's rejected outright or it has been attempted too many times.
+// Each attempt has the same delivery id so that the receiver can ignore duplicates.
+func deliverPostEventHook(hook *model.PostEventHook, payload *model.PostEventHookPayload) {
+	body := []byte(payload.ToJson())
+	deliveryId := model.NewId()
+	interval := postEventHookRetryInterval
+
+	for attempt := 1; ; attempt++ {
+		retry, err := sendPostEventHookDelivery(hook, payload.Event, deliveryId, body)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt >= model.POST_EVENT_HOOK_MAX_ATTEMPTS {
+			l4g.Error(utils.T("app.post_event_hook.deliver.failed.error"), hook.Id, deliveryId, attempt, err.Error())
+			return
+		}
+
+		l4g.Warn(utils.T("app.post_event_hook.deliver.retry.warn"), hook.Id, deliveryId, attempt, err.Error())
+
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// sendPostEventHookDelivery makes a single attempt at a delivery. It returns whether the delivery
+// should be retried if it failed, which it is unless the receiver rejected it with a client error.
+func sendPostEventHookDelivery(hook *model.PostEventHook, event string, deliveryId string, body []byte) (bool, *model.AppError) {
+	req, err := http.NewRequest("POST", hook.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, model.NewAppError("sendPostEventHookDelivery", "app.post_event_hook.deliver.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.HEADER_POST_EVENT_HOOK_EVENT, event)
+	req.Header.Set(model.HEADER_POST_EVENT_HOOK_DELIVERY, deliveryId)
+	req.Header.Set(model.HEADER_POST_EVENT_HOOK_SIGNATURE, model.SignPostEventHookPayload(hook.Secret, body))
+
+	tr := &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
+		DisableKeepAlives: true,
+	}
+	client := &http.Client{Transport: tr, Timeout: httpTimeout}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, model.NewAppError("sendPostEventHookDelivery", "app.post_event_hook.deliver.app_error", nil, err.Error(), http.StatusBadGateway)
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, model.NewAppError("sendPostEventHookDelivery", "app.post_event_hook.deliver.app_error", nil, "status="+strconv.Itoa(resp.StatusCode), http.StatusBadGateway)
+}
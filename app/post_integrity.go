@@ -0,0 +1,164 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	POST_INTEGRITY_TASK_NAME     = "Post Integrity Verification"
+	POST_INTEGRITY_TASK_INTERVAL = 24 * time.Hour
+	POST_INTEGRITY_BATCH_SIZE    = 200
+)
+
+// InitPostIntegrity starts the job that verifies the integrity chains of all channels every day.
+func InitPostIntegrity() {
+	if task := model.GetTaskByName(POST_INTEGRITY_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	if !*Config().ComplianceSettings.EnablePostIntegrityChain {
+		return
+	}
+
+	model.CreateRecurringTask(POST_INTEGRITY_TASK_NAME, VerifyAllPostIntegrityChains, POST_INTEGRITY_TASK_INTERVAL)
+}
+
+// recordPostIntegrity appends a newly created post to the integrity chain of its channel.
+func recordPostIntegrity(post *model.Post) {
+	if !*Config().ComplianceSettings.EnablePostIntegrityChain {
+		return
+	}
+
+	if result := <-Srv.Store.PostIntegrity().Append(post); result.Err != nil {
+		l4g.Error(utils.T("app.post_integrity.record.error"), post.Id, result.Err.Error())
+	}
+}
+
+// VerifyPostIntegrityChain checks every entry of a channel's integrity chain against the channel's
+// posts. Posts that were edited are checked against the message they were created with, so only
+// changes made without going through the server or posts that were permanently deleted are reported.
+func VerifyPostIntegrityChain(channelId string) (*model.PostIntegrityVerification, *model.AppError) {
+	if !*Config().ComplianceSettings.EnablePostIntegrityChain {
+		return nil, model.NewAppError("VerifyPostIntegrityChain", "app.post_integrity.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	verification := &model.PostIntegrityVerification{
+		ChannelId:  channelId,
+		VerifiedAt: model.GetMillis(),
+		Failures:   []*model.PostIntegrityFailure{},
+	}
+
+	var previous *model.PostIntegrityRecord
+	for {
+		var records []*model.PostIntegrityRecord
+		afterSequence := int64(0)
+		if previous != nil {
+			afterSequence = previous.Sequence
+		}
+
+		if result := <-Srv.Store.PostIntegrity().GetForChannel(channelId, afterSequence, POST_INTEGRITY_BATCH_SIZE); result.Err != nil {
+			return nil, result.Err
+		} else {
+			records = result.Data.([]*model.PostIntegrityRecord)
+		}
+
+		if len(records) == 0 {
+			break
+		}
+
+		posts, err := getPostsForIntegrityRecords(records)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			verification.RecordCount++
+
+			if previous == nil && (record.Sequence != 1 || record.PreviousHash != "") {
+				verification.AddFailure(record, model.POST_INTEGRITY_FAILURE_BROKEN_CHAIN)
+			} else if previous != nil && (record.Sequence != previous.Sequence+1 || record.PreviousHash != previous.Hash) {
+				verification.AddFailure(record, model.POST_INTEGRITY_FAILURE_BROKEN_CHAIN)
+			}
+
+			if post, ok := posts[record.PostId]; !ok {
+				verification.AddFailure(record, model.POST_INTEGRITY_FAILURE_MISSING_POST)
+			} else if message, err := getOriginalPostMessage(post); err != nil {
+				return nil, err
+			} else if model.PostIntegrityHash(post, message, record.PreviousHash) != record.Hash {
+				verification.AddFailure(record, model.POST_INTEGRITY_FAILURE_MODIFIED_POST)
+			}
+
+			previous = record
+		}
+	}
+
+	return verification, nil
+}
+
+// VerifyAllPostIntegrityChains verifies the chains of all channels and logs the ones that fail.
+func VerifyAllPostIntegrityChains() {
+	for offset := 0; ; offset += POST_INTEGRITY_BATCH_SIZE {
+		var channelIds []string
+		if result := <-Srv.Store.PostIntegrity().GetChannelIds(offset, POST_INTEGRITY_BATCH_SIZE); result.Err != nil {
+			l4g.Error(utils.T("app.post_integrity.verify.error"), "", result.Err.Error())
+			return
+		} else {
+			channelIds = result.Data.([]string)
+		}
+
+		for _, channelId := range channelIds {
+			if verification, err := VerifyPostIntegrityChain(channelId); err != nil {
+				l4g.Error(utils.T("app.post_integrity.verify.error"), channelId, err.Error())
+			} else if !verification.IsValid() {
+				l4g.Critical(utils.T("app.post_integrity.verify.tampered.critical"), channelId, len(verification.Failures))
+			}
+		}
+
+		if len(channelIds) < POST_INTEGRITY_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+func getPostsForIntegrityRecords(records []*model.PostIntegrityRecord) (map[string]*model.Post, *model.AppError) {
+	postIds := make([]string, len(records))
+	for i, record := range records {
+		postIds[i] = record.PostId
+	}
+
+	if result := <-Srv.Store.Post().GetPostsByIdsIncludeDeleted(postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		posts := make(map[string]*model.Post)
+		for _, post := range result.Data.([]*model.Post) {
+			posts[post.Id] = post
+		}
+
+		return posts, nil
+	}
+}
+
+// getOriginalPostMessage returns the message a post was created with, which is kept in the post's
+// history when it has been edited since.
+func getOriginalPostMessage(post *model.Post) (string, *model.AppError) {
+	if post.EditAt == 0 {
+		return post.Message, nil
+	}
+
+	if result := <-Srv.Store.Post().GetPostHistory(post.Id); result.Err != nil {
+		return "", result.Err
+	} else if history := result.Data.([]*model.PostHistory); len(history) > 0 {
+		// The history is ordered from the most recent revision
+		return history[len(history)-1].Message, nil
+	}
+
+	return post.Message, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	STATUS_EXPIRY_TASK_NAME     = "Status Expiry"
+	STATUS_EXPIRY_TASK_INTERVAL = 30 * time.Second
+	STATUS_EXPIRY_BATCH_SIZE    = 100
+)
+
+func InitStatusExpiry() {
+	if task := model.GetTaskByName(STATUS_EXPIRY_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(STATUS_EXPIRY_TASK_NAME, ClearExpiredStatuses, STATUS_EXPIRY_TASK_INTERVAL)
+}
+
+// SetStatusDoNotDisturb sets the user's status to do not disturb until endTime, or until they change
+// it themselves if endTime is 0. Push notifications aren't sent while a user is in do not disturb.
+func SetStatusDoNotDisturb(userId string, endTime int64) (*model.Status, *model.AppError) {
+	if endTime < 0 || (endTime > 0 && endTime <= model.GetMillis()) {
+		return nil, model.NewAppError("SetStatusDoNotDisturb", "app.status.set_dnd.end_time.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	status := getStatusOrOffline(userId)
+	status.Status = model.STATUS_DND
+	status.Manual = true
+	status.DNDEndTime = endTime
+	status.ActiveChannel = ""
+
+	if err := updateStatus(status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func SetCustomStatus(userId string, customStatus *model.CustomStatus) (*model.Status, *model.AppError) {
+	if err := customStatus.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if customStatus.ExpiresAt > 0 && customStatus.ExpiresAt <= model.GetMillis() {
+		return nil, model.NewAppError("SetCustomStatus", "app.status.set_custom_status.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	status := getStatusOrOffline(userId)
+	status.SetCustomStatus(customStatus)
+
+	if err := updateStatus(status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func ClearCustomStatus(userId string) (*model.Status, *model.AppError) {
+	status := getStatusOrOffline(userId)
+	if status.GetCustomStatus() == nil {
+		return status, nil
+	}
+
+	status.SetCustomStatus(nil)
+
+	if err := updateStatus(status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// ClearExpiredStatuses ends the do not disturb periods and clears the custom statuses whose expiry time
+// has passed. Once do not disturb ends, the user is shown as online or away depending on their last activity.
+func ClearExpiredStatuses() {
+	now := model.GetMillis()
+
+	var expired []*model.Status
+	if result := <-Srv.Store.Status().GetExpired(now, STATUS_EXPIRY_BATCH_SIZE); result.Err != nil {
+		l4g.Error(utils.T("app.status.clear_expired.get_expired.error"), result.Err)
+		return
+	} else {
+		expired = result.Data.([]*model.Status)
+	}
+
+	for _, expiredStatus := range expired {
+		// The cached status may have been changed since the stored one was read
+		status, err := GetStatus(expiredStatus.UserId)
+		if err != nil {
+			status = expiredStatus
+		}
+
+		changed := false
+
+		if status.DNDEndTime > 0 && status.DNDEndTime <= now {
+			if IsUserAway(status.LastActivityAt) {
+				status.Status = model.STATUS_AWAY
+			} else {
+				status.Status = model.STATUS_ONLINE
+			}
+			status.Manual = false
+			status.DNDEndTime = 0
+			changed = true
+		}
+
+		if status.CustomStatusExpiresAt > 0 && status.CustomStatusExpiresAt <= now {
+			status.SetCustomStatus(nil)
+			changed = true
+		}
+
+		if changed {
+			if err := updateStatus(status); err != nil {
+				l4g.Error(utils.T("app.status.clear_expired.error"), status.UserId, err)
+			}
+		}
+	}
+}
+
+func getStatusOrOffline(userId string) *model.Status {
+	if status, err := GetStatus(userId); err == nil {
+		return status
+	}
+
+	return &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
+}
+
+func updateStatus(status *model.Status) *model.AppError {
+	AddStatusCache(status)
+
+	if result := <-Srv.Store.Status().SaveOrUpdate(status); result.Err != nil {
+		return result.Err
+	}
+
+	publishStatusChange(status)
+
+	return nil
+}
+
+func publishStatusChange(status *model.Status) {
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", status.UserId, nil)
+	event.Add("status", status.Status)
+	event.Add("user_id", status.UserId)
+	event.Add("dnd_end_time", status.DNDEndTime)
+	event.Add("custom_status_emoji", status.CustomStatusEmoji)
+	event.Add("custom_status_text", status.CustomStatusText)
+	event.Add("custom_status_expires_at", status.CustomStatusExpiresAt)
+	go Publish(event)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	PUSH_PROXY_HEALTH_CHECK_TASK_NAME = "Push Proxy Health Check"
+	PUSH_PROXY_HEALTH_CHECK_INTERVAL  = 30 * time.Second
+	PUSH_PROXY_MAX_FAILURES           = 3
+)
+
+// pushProxyFailures counts the consecutive failed requests to each push proxy. Proxies that
+// reach PUSH_PROXY_MAX_FAILURES are skipped until the health check finds them reachable again.
+var pushProxyFailures = map[string]int{}
+var pushProxyFailuresLock sync.Mutex
+
+func InitPushProxyHealthCheck() {
+	if task := model.GetTaskByName(PUSH_PROXY_HEALTH_CHECK_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(PUSH_PROXY_HEALTH_CHECK_TASK_NAME, CheckPushProxyHealth, PUSH_PROXY_HEALTH_CHECK_INTERVAL)
+}
+
+// GetPushProxiesForPlatform returns the push proxies to try, in order, for a device on the given
+// platform. Proxies with routing rules that match the platform come first, followed by the
+// default push notification server.
+func GetPushProxiesForPlatform(platform string) []string {
+	urls := []string{}
+	seen := map[string]bool{}
+
+	add := func(url string) {
+		url = strings.TrimRight(url, "/")
+		if len(url) == 0 || seen[url] {
+			return
+		}
+
+		if url == model.MHPNS && (!utils.IsLicensed || !*utils.License.Features.MHPNS) {
+			return
+		}
+
+		seen[url] = true
+		urls = append(urls, url)
+	}
+
+	for _, proxy := range Config().EmailSettings.PushProxies {
+		if proxy.SupportsPlatform(platform) {
+			add(proxy.Url)
+		}
+	}
+
+	add(*Config().EmailSettings.PushNotificationServer)
+
+	return urls
+}
+
+func isPushProxyHealthy(url string) bool {
+	pushProxyFailuresLock.Lock()
+	defer pushProxyFailuresLock.Unlock()
+
+	return pushProxyFailures[url] < PUSH_PROXY_MAX_FAILURES
+}
+
+func recordPushProxyResult(url string, success bool) {
+	pushProxyFailuresLock.Lock()
+	if success {
+		delete(pushProxyFailures, url)
+	} else {
+		pushProxyFailures[url]++
+		if pushProxyFailures[url] == PUSH_PROXY_MAX_FAILURES {
+			l4g.Warn(utils.T("app.push_proxy.unhealthy.warn"), url)
+		}
+	}
+	pushProxyFailuresLock.Unlock()
+
+	if metrics := einterfaces.GetMetricsInterface(); metrics != nil {
+		if success {
+			metrics.IncrementPushProxySuccess(url)
+		} else {
+			metrics.IncrementPushProxyFailure(url)
+		}
+	}
+}
+
+// CheckPushProxyHealth tries to reach each push proxy that has been marked as unhealthy and
+// starts using it again once it responds.
+func CheckPushProxyHealth() {
+	pushProxyFailuresLock.Lock()
+	unhealthy := []string{}
+	for url, failures := range pushProxyFailures {
+		if failures >= PUSH_PROXY_MAX_FAILURES {
+			unhealthy = append(unhealthy, url)
+		}
+	}
+	pushProxyFailuresLock.Unlock()
+
+	for _, url := range unhealthy {
+		if resp, err := pushProxyHttpClient().Get(url); err == nil {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode < http.StatusInternalServerError {
+				l4g.Info(utils.T("app.push_proxy.healthy.info"), url)
+				pushProxyFailuresLock.Lock()
+				delete(pushProxyFailures, url)
+				pushProxyFailuresLock.Unlock()
+			}
+		}
+	}
+}
+
+func pushProxyHttpClient() *http.Client {
+	tr := &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
+		DisableKeepAlives: true,
+	}
+
+	return &http.Client{Transport: tr, Timeout: httpTimeout}
+}
+
+// sendToPushProxies sends the notification to the first healthy push proxy for the device's
+// platform that accepts it, failing over to the next proxy when one can't be reached. When every
+// proxy is unhealthy they are all tried anyway.
+func sendToPushProxies(msg model.PushNotification) (model.PushResponse, *model.AppError) {
+	urls := GetPushProxiesForPlatform(msg.Platform)
+
+	healthy := []string{}
+	for _, url := range urls {
+		if isPushProxyHealthy(url) {
+			healthy = append(healthy, url)
+		}
+	}
+
+	if len(healthy) > 0 {
+		urls = healthy
+	}
+
+	var lastErr *model.AppError
+	for _, url := range urls {
+		request, _ := http.NewRequest("POST", url+model.API_URL_SUFFIX_V1+"/send_push", strings.NewReader(msg.ToJson()))
+
+		resp, err := pushProxyHttpClient().Do(request)
+		if err != nil {
+			lastErr = model.NewAppError("sendToPushProxies", "app.push_proxy.send.app_error", nil, "url="+url+", "+err.Error(), http.StatusInternalServerError)
+		} else if resp.StatusCode >= http.StatusInternalServerError {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = model.NewAppError("sendToPushProxies", "app.push_proxy.send.app_error", nil, "url="+url+", status="+resp.Status, http.StatusInternalServerError)
+		} else {
+			pushResponse := model.PushResponseFromJson(resp.Body)
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			recordPushProxyResult(url, true)
+			return pushResponse, nil
+		}
+
+		l4g.Warn(lastErr.Error())
+		recordPushProxyResult(url, false)
+	}
+
+	if lastErr == nil {
+		lastErr = model.NewAppError("sendToPushProxies", "app.push_proxy.no_proxies.app_error", nil, "platform="+msg.Platform, http.StatusInternalServerError)
+	}
+
+	return nil, lastErr
+}
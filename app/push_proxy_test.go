@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestGetPushProxiesForPlatform(t *testing.T) {
+	Setup()
+
+	server := *utils.Cfg.EmailSettings.PushNotificationServer
+	proxies := utils.Cfg.EmailSettings.PushProxies
+	defer func() {
+		*utils.Cfg.EmailSettings.PushNotificationServer = server
+		utils.Cfg.EmailSettings.PushProxies = proxies
+	}()
+
+	*utils.Cfg.EmailSettings.PushNotificationServer = "http://default.example.com/"
+	utils.Cfg.EmailSettings.PushProxies = []*model.PushProxy{
+		{Url: "http://apple.example.com", Platforms: []string{model.PUSH_NOTIFY_APPLE, model.PUSH_NOTIFY_APPLE_REACT_NATIVE}},
+		{Url: "http://backup.example.com"},
+		{Url: "http://default.example.com"},
+	}
+
+	if urls := GetPushProxiesForPlatform(model.PUSH_NOTIFY_APPLE); len(urls) != 3 || urls[0] != "http://apple.example.com" || urls[1] != "http://backup.example.com" || urls[2] != "http://default.example.com" {
+		t.Fatal("should have routed apple devices to the apple proxy first", urls)
+	}
+
+	if urls := GetPushProxiesForPlatform(model.PUSH_NOTIFY_ANDROID); len(urls) != 2 || urls[0] != "http://backup.example.com" || urls[1] != "http://default.example.com" {
+		t.Fatal("shouldn't have routed android devices to the apple proxy", urls)
+	}
+}
+
+func TestSendToPushProxiesFailover(t *testing.T) {
+	Setup()
+
+	server := *utils.Cfg.EmailSettings.PushNotificationServer
+	proxies := utils.Cfg.EmailSettings.PushProxies
+	defer func() {
+		*utils.Cfg.EmailSettings.PushNotificationServer = server
+		utils.Cfg.EmailSettings.PushProxies = proxies
+	}()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	sent := 0
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		resp := model.NewOkPushResponse()
+		w.Write([]byte(resp.ToJson()))
+	}))
+	defer working.Close()
+
+	*utils.Cfg.EmailSettings.PushNotificationServer = working.URL
+	utils.Cfg.EmailSettings.PushProxies = []*model.PushProxy{{Url: failing.URL}}
+
+	msg := model.PushNotification{Platform: model.PUSH_NOTIFY_ANDROID, DeviceId: model.NewId()}
+
+	for i := 0; i < PUSH_PROXY_MAX_FAILURES; i++ {
+		if resp, err := sendToPushProxies(msg); err != nil {
+			t.Fatal(err)
+		} else if resp[model.PUSH_STATUS] != model.PUSH_STATUS_OK {
+			t.Fatal("should have failed over to the working proxy")
+		}
+	}
+
+	if isPushProxyHealthy(failing.URL) {
+		t.Fatal("should have marked the failing proxy as unhealthy")
+	}
+
+	if _, err := sendToPushProxies(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != PUSH_PROXY_MAX_FAILURES+1 {
+		t.Fatal("should have sent every notification through the working proxy")
+	}
+
+	recordPushProxyResult(failing.URL, true)
+	if !isPushProxyHealthy(failing.URL) {
+		t.Fatal("should be healthy after a successful request")
+	}
+}
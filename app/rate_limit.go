@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	RATE_LIMIT_CLASS_LOGIN       = "login"
+	RATE_LIMIT_CLASS_FILE_UPLOAD = "file_upload"
+	RATE_LIMIT_CLASS_WEBSOCKET   = "websocket"
+	RATE_LIMIT_CLASS_API         = "api"
+
+	ENDPOINT_RATE_LIMIT_CACHE_SIZE = 50000
+)
+
+var endpointRateLimiters map[string]*utils.TokenBucketLimiter
+
+// InitEndpointRateLimiters creates the per endpoint class rate limiters from the service settings.
+func InitEndpointRateLimiters() {
+	if !*Config().ServiceSettings.EnableEndpointRateLimits {
+		endpointRateLimiters = nil
+		return
+	}
+
+	settings := Config().ServiceSettings
+	endpointRateLimiters = map[string]*utils.TokenBucketLimiter{
+		RATE_LIMIT_CLASS_LOGIN:       utils.NewTokenBucketLimiter(*settings.LoginRateLimitPerMinute, *settings.LoginRateLimitMaxBurst, ENDPOINT_RATE_LIMIT_CACHE_SIZE),
+		RATE_LIMIT_CLASS_FILE_UPLOAD: utils.NewTokenBucketLimiter(*settings.FileUploadRateLimitPerMinute, *settings.FileUploadRateLimitMaxBurst, ENDPOINT_RATE_LIMIT_CACHE_SIZE),
+		RATE_LIMIT_CLASS_WEBSOCKET:   utils.NewTokenBucketLimiter(*settings.WebSocketRateLimitPerMinute, *settings.WebSocketRateLimitMaxBurst, ENDPOINT_RATE_LIMIT_CACHE_SIZE),
+		RATE_LIMIT_CLASS_API:         utils.NewTokenBucketLimiter(*settings.ApiRateLimitPerMinute, *settings.ApiRateLimitMaxBurst, ENDPOINT_RATE_LIMIT_CACHE_SIZE),
+	}
+}
+
+// endpointRateLimitConfigListener rebuilds the endpoint rate limiters when a reloaded config changes
+// their settings. The limiters are left alone otherwise so that other config changes don't reset
+// the tokens that have already been taken.
+func endpointRateLimitConfigListener(oldCfg *model.Config, newCfg *model.Config) {
+	if oldCfg != nil && endpointRateLimitSettingsEqual(&oldCfg.ServiceSettings, &newCfg.ServiceSettings) {
+		return
+	}
+
+	InitEndpointRateLimiters()
+}
+
+func endpointRateLimitSettingsEqual(a *model.ServiceSettings, b *model.ServiceSettings) bool {
+	return *a.EnableEndpointRateLimits == *b.EnableEndpointRateLimits &&
+		*a.LoginRateLimitPerMinute == *b.LoginRateLimitPerMinute &&
+		*a.LoginRateLimitMaxBurst == *b.LoginRateLimitMaxBurst &&
+		*a.FileUploadRateLimitPerMinute == *b.FileUploadRateLimitPerMinute &&
+		*a.FileUploadRateLimitMaxBurst == *b.FileUploadRateLimitMaxBurst &&
+		*a.WebSocketRateLimitPerMinute == *b.WebSocketRateLimitPerMinute &&
+		*a.WebSocketRateLimitMaxBurst == *b.WebSocketRateLimitMaxBurst &&
+		*a.ApiRateLimitPerMinute == *b.ApiRateLimitPerMinute &&
+		*a.ApiRateLimitMaxBurst == *b.ApiRateLimitMaxBurst
+}
+
+// GetRateLimitClass works out which class of endpoint a request is for.
+func GetRateLimitClass(r *http.Request) string {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	if r.Method == "POST" && strings.HasSuffix(path, "/users/login") {
+		return RATE_LIMIT_CLASS_LOGIN
+	} else if r.Method == "POST" && (strings.HasSuffix(path, "/files") || strings.HasSuffix(path, "/files/upload")) {
+		return RATE_LIMIT_CLASS_FILE_UPLOAD
+	} else if strings.HasSuffix(path, "/websocket") {
+		return RATE_LIMIT_CLASS_WEBSOCKET
+	}
+
+	return RATE_LIMIT_CLASS_API
+}
+
+// CheckEndpointRateLimit takes a token for the request from the rate limiter for its endpoint
+// class. When the request is over the limit, it sets the Retry-After header and returns an error.
+func CheckEndpointRateLimit(w http.ResponseWriter, r *http.Request, session *model.Session, ipAddress string) *model.AppError {
+	retryAfter, err := checkEndpointRateLimit(GetRateLimitClass(r), session, ipAddress)
+	if err != nil {
+		w.Header().Set(model.HEADER_RETRY_AFTER, strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+	}
+
+	return err
+}
+
+// CheckEndpointRateLimitForClass takes a token from the rate limiter of an endpoint class for
+// requests that aren't made over HTTP, such as gRPC calls.
+func CheckEndpointRateLimitForClass(class string, session *model.Session, ipAddress string) *model.AppError {
+	_, err := checkEndpointRateLimit(class, session, ipAddress)
+	return err
+}
+
+// checkEndpointRateLimit limits requests by session or user as configured, or by IP address when
+// they don't have a session. It returns how long to wait before retrying a request that is over
+// the limit.
+func checkEndpointRateLimit(class string, session *model.Session, ipAddress string) (time.Duration, *model.AppError) {
+	limiters := endpointRateLimiters
+	if limiters == nil {
+		return 0, nil
+	}
+
+	key := "ip:" + ipAddress
+	if len(session.Id) > 0 {
+		if *Config().ServiceSettings.EndpointRateLimitVaryBy == model.ENDPOINT_RATE_LIMIT_VARY_BY_USER {
+			key = "user:" + session.UserId
+		} else {
+			key = "session:" + session.Id
+		}
+	}
+
+	if allowed, retryAfter := limiters[class].Allow(key); !allowed {
+		return retryAfter, model.NewAppError("CheckEndpointRateLimit", "app.rate_limit.too_many_requests.app_error", nil, "class="+class+", key="+key, http.StatusTooManyRequests)
+	}
+
+	return 0, nil
+}
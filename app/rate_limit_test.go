@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestGetRateLimitClass(t *testing.T) {
+	for _, tc := range []struct {
+		Method string
+		Path   string
+		Class  string
+	}{
+		{"POST", "/api/v4/users/login", RATE_LIMIT_CLASS_LOGIN},
+		{"POST", "/api/v3/users/login", RATE_LIMIT_CLASS_LOGIN},
+		{"POST", "/api/v4/files", RATE_LIMIT_CLASS_FILE_UPLOAD},
+		{"POST", "/api/v3/teams/abc/files/upload", RATE_LIMIT_CLASS_FILE_UPLOAD},
+		{"GET", "/api/v4/websocket", RATE_LIMIT_CLASS_WEBSOCKET},
+		{"GET", "/api/v3/users/websocket", RATE_LIMIT_CLASS_WEBSOCKET},
+		{"GET", "/api/v4/files/abc", RATE_LIMIT_CLASS_API},
+		{"GET", "/api/v4/users/me", RATE_LIMIT_CLASS_API},
+	} {
+		r, _ := http.NewRequest(tc.Method, tc.Path, nil)
+		if class := GetRateLimitClass(r); class != tc.Class {
+			t.Fatalf("%v %v should be in class %v but was %v", tc.Method, tc.Path, tc.Class, class)
+		}
+	}
+}
+
+func TestCheckEndpointRateLimit(t *testing.T) {
+	Setup()
+
+	enabled := *utils.Cfg.ServiceSettings.EnableEndpointRateLimits
+	maxBurst := *utils.Cfg.ServiceSettings.LoginRateLimitMaxBurst
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = enabled
+		*utils.Cfg.ServiceSettings.LoginRateLimitMaxBurst = maxBurst
+		InitEndpointRateLimiters()
+	}()
+
+	*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = true
+	*utils.Cfg.ServiceSettings.LoginRateLimitMaxBurst = 2
+	InitEndpointRateLimiters()
+
+	r, _ := http.NewRequest("POST", "/api/v4/users/login", nil)
+	session := &model.Session{}
+
+	for i := 0; i < 2; i++ {
+		if err := CheckEndpointRateLimit(httptest.NewRecorder(), r, session, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := CheckEndpointRateLimit(w, r, session, "10.0.0.1"); err == nil || err.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("should have been rate limited")
+	} else if w.Header().Get(model.HEADER_RETRY_AFTER) == "" {
+		t.Fatal("should have set the Retry-After header")
+	}
+
+	if err := CheckEndpointRateLimit(httptest.NewRecorder(), r, session, "10.0.0.2"); err != nil {
+		t.Fatal("each IP address should have its own limit")
+	}
+
+	session.Id = model.NewId()
+	session.UserId = model.NewId()
+	if err := CheckEndpointRateLimit(httptest.NewRecorder(), r, session, "10.0.0.1"); err != nil {
+		t.Fatal("requests with a session should be limited by session")
+	}
+
+	r, _ = http.NewRequest("GET", "/api/v4/users/me", nil)
+	if err := CheckEndpointRateLimit(httptest.NewRecorder(), r, &model.Session{}, "10.0.0.1"); err != nil {
+		t.Fatal("each endpoint class should have its own limit")
+	}
+}
+
+func TestEndpointRateLimitConfigListener(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	enabled := *utils.Cfg.ServiceSettings.EnableEndpointRateLimits
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = enabled
+		InitEndpointRateLimiters()
+	}()
+
+	*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = true
+	InitEndpointRateLimiters()
+	limiter := endpointRateLimiters[RATE_LIMIT_CLASS_API]
+
+	oldCfg := utils.CloneConfig(utils.Cfg)
+	newCfg := utils.CloneConfig(utils.Cfg)
+	newCfg.TeamSettings.SiteName = "Changed " + oldCfg.TeamSettings.SiteName
+
+	endpointRateLimitConfigListener(oldCfg, newCfg)
+	if endpointRateLimiters[RATE_LIMIT_CLASS_API] != limiter {
+		t.Fatal("should leave the limiters alone when their settings didn't change")
+	}
+
+	*newCfg.ServiceSettings.ApiRateLimitMaxBurst += 1
+	endpointRateLimitConfigListener(oldCfg, newCfg)
+	if endpointRateLimiters[RATE_LIMIT_CLASS_API] == limiter {
+		t.Fatal("should have rebuilt the limiters when their settings changed")
+	}
+}
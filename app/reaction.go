@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+// SaveReactionForPost adds a reaction to a post in the channel if the channel accepts the emoji and its
+// moderation settings let the user react.
+func SaveReactionForPost(reaction *model.Reaction, channel *model.Channel) (*model.Reaction, *model.AppError) {
+	if !channel.IsReactionAllowed(reaction.EmojiName) {
+		return nil, model.NewAppError("SaveReactionForPost", "api.reaction.save_reaction.not_allowed.app_error", nil,
+			"channelId="+channel.Id+", emojiName="+reaction.EmojiName, http.StatusForbidden)
+	}
+
+	if err := checkChannelModeration(channel, reaction.UserId, model.CHANNEL_MODERATION_CREATE_REACTIONS); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Reaction().Save(reaction); result.Err != nil {
+		return nil, result.Err
+	} else {
+		reaction = result.Data.(*model.Reaction)
+
+		InvalidateCacheForReactions(reaction.PostId)
+		go RecordEmojiUsage(reaction.UserId, []string{reaction.EmojiName})
+		go SyncReactionsForSharedChannel(channel.Id, reaction.PostId)
+
+		return reaction, nil
+	}
+}
+
+// GetReactionsForPosts returns the reactions for each of the given posts keyed by post id.
+func GetReactionsForPosts(postIds []string) (map[string][]*model.Reaction, *model.AppError) {
+	return GetReactionsForPostsContext(context.Background(), postIds)
+}
+
+// GetReactionsForPostsContext is GetReactionsForPosts with the database query cancelled along with ctx.
+func GetReactionsForPostsContext(ctx context.Context, postIds []string) (map[string][]*model.Reaction, *model.AppError) {
+	if result := <-Srv.Store.Reaction().GetForPostsContext(ctx, postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(map[string][]*model.Reaction), nil
+	}
+}
+
+// GetTopReactionsForTeam returns the emoji most used as reactions in the team since the given time.
+// The time is truncated to the minute so that repeated requests can be served from the cache.
+func GetTopReactionsForTeam(teamId string, since int64, limit int) ([]*model.ReactionCount, *model.AppError) {
+	since = since - since%(60*1000)
+
+	if result := <-Srv.Store.Reaction().GetTopReactionsForTeam(teamId, since, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ReactionCount), nil
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+)
+
+// CreateRemoteCluster adds a remote that hasn't connected yet and returns the invite that its
+// administrator has to accept to connect it.
+func CreateRemoteCluster(rc *model.RemoteCluster) (*model.RemoteCluster, string, *model.AppError) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return nil, "", model.NewAppError("CreateRemoteCluster", "app.remote_cluster.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	siteURL := *Config().ServiceSettings.SiteURL
+	if len(siteURL) == 0 {
+		return nil, "", model.NewAppError("CreateRemoteCluster", "app.remote_cluster.site_url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	rc.RemoteId = ""
+	rc.Token = ""
+	rc.SiteURL = ""
+	rc.RemoteRecordId = ""
+	rc.RemoteToken = ""
+	rc.LastPingAt = 0
+
+	if rc.DefaultTeamId != "" {
+		if _, err := GetTeam(rc.DefaultTeamId); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var rrc *model.RemoteCluster
+	if result := <-Srv.Store.RemoteCluster().Save(rc); result.Err != nil {
+		return nil, "", result.Err
+	} else {
+		rrc = result.Data.(*model.RemoteCluster)
+	}
+
+	invite := &model.RemoteClusterInvite{
+		RemoteId: rrc.RemoteId,
+		SiteURL:  siteURL,
+		Token:    rrc.Token,
+	}
+
+	return rrc, invite.Encode(), nil
+}
+
+// AcceptRemoteClusterInvite connects this server to the server that created an invite. The remote is
+// only kept if the other server confirms the connection.
+func AcceptRemoteClusterInvite(encodedInvite string, rc *model.RemoteCluster) (*model.RemoteCluster, *model.AppError) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return nil, model.NewAppError("AcceptRemoteClusterInvite", "app.remote_cluster.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	siteURL := *Config().ServiceSettings.SiteURL
+	if len(siteURL) == 0 {
+		return nil, model.NewAppError("AcceptRemoteClusterInvite", "app.remote_cluster.site_url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	invite := model.DecodeRemoteClusterInvite(encodedInvite)
+	if invite == nil {
+		return nil, model.NewAppError("AcceptRemoteClusterInvite", "app.remote_cluster.accept_invite.invalid.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if rc.DefaultTeamId != "" {
+		if _, err := GetTeam(rc.DefaultTeamId); err != nil {
+			return nil, err
+		}
+	}
+
+	rc.RemoteId = ""
+	rc.Token = ""
+	rc.SiteURL = invite.SiteURL
+	rc.RemoteRecordId = invite.RemoteId
+	rc.RemoteToken = invite.Token
+
+	var rrc *model.RemoteCluster
+	if result := <-Srv.Store.RemoteCluster().Save(rc); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rrc = result.Data.(*model.RemoteCluster)
+	}
+
+	confirmation := &model.RemoteClusterConfirmation{
+		RemoteId: rrc.RemoteId,
+		SiteURL:  siteURL,
+		Token:    rrc.Token,
+	}
+
+	if err := sendToRemoteCluster(rrc, "/confirm_invite", []byte(confirmation.ToJson())); err != nil {
+		if result := <-Srv.Store.RemoteCluster().Delete(rrc.RemoteId); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+
+		return nil, err
+	}
+
+	rrc.LastPingAt = model.GetMillis()
+	if result := <-Srv.Store.RemoteCluster().UpdateLastPingAt(rrc.RemoteId, rrc.LastPingAt); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+
+	return rrc, nil
+}
+
+// ConfirmRemoteClusterInvite is called by a server that accepted an invite from this one, and tells
+// this server how to reach it.
+func ConfirmRemoteClusterInvite(rc *model.RemoteCluster, confirmation *model.RemoteClusterConfirmation) (*model.RemoteCluster, *model.AppError) {
+	if rc.IsConfirmed() {
+		return nil, model.NewAppError("ConfirmRemoteClusterInvite", "app.remote_cluster.confirm_invite.confirmed.app_error", nil, "remote_id="+rc.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(confirmation.RemoteId) != 26 || len(confirmation.Token) != 26 || !model.IsValidHttpUrl(confirmation.SiteURL) {
+		return nil, model.NewAppError("ConfirmRemoteClusterInvite", "app.remote_cluster.confirm_invite.invalid.app_error", nil, "remote_id="+rc.RemoteId, http.StatusBadRequest)
+	}
+
+	rc.SiteURL = confirmation.SiteURL
+	rc.RemoteRecordId = confirmation.RemoteId
+	rc.RemoteToken = confirmation.Token
+	rc.LastPingAt = model.GetMillis()
+
+	if result := <-Srv.Store.RemoteCluster().Update(rc); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.RemoteCluster), nil
+	}
+}
+
+// AuthenticateRemoteCluster returns the remote that a request was made by, given the id and token
+// that it sent.
+func AuthenticateRemoteCluster(remoteId string, token string) (*model.RemoteCluster, *model.AppError) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return nil, model.NewAppError("AuthenticateRemoteCluster", "app.remote_cluster.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if len(remoteId) != 26 || len(token) == 0 {
+		return nil, model.NewAppError("AuthenticateRemoteCluster", "app.remote_cluster.authenticate.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	var rc *model.RemoteCluster
+	if result := <-Srv.Store.RemoteCluster().Get(remoteId); result.Err != nil {
+		return nil, model.NewAppError("AuthenticateRemoteCluster", "app.remote_cluster.authenticate.app_error", nil, result.Err.Error(), http.StatusUnauthorized)
+	} else {
+		rc = result.Data.(*model.RemoteCluster)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(rc.Token)) != 1 {
+		return nil, model.NewAppError("AuthenticateRemoteCluster", "app.remote_cluster.authenticate.app_error", nil, "remote_id="+remoteId, http.StatusUnauthorized)
+	}
+
+	rc.LastPingAt = model.GetMillis()
+	go func() {
+		if result := <-Srv.Store.RemoteCluster().UpdateLastPingAt(rc.RemoteId, rc.LastPingAt); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+	}()
+
+	return rc, nil
+}
+
+func GetRemoteCluster(remoteId string) (*model.RemoteCluster, *model.AppError) {
+	if result := <-Srv.Store.RemoteCluster().Get(remoteId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.RemoteCluster), nil
+	}
+}
+
+func GetRemoteClusters() ([]*model.RemoteCluster, *model.AppError) {
+	if result := <-Srv.Store.RemoteCluster().GetAll(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.RemoteCluster), nil
+	}
+}
+
+// DeleteRemoteCluster disconnects a remote and stops syncing every channel shared with it. The
+// channels and the content already synced are kept.
+func DeleteRemoteCluster(remoteId string) *model.AppError {
+	if _, err := GetRemoteCluster(remoteId); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.RemoteCluster().Delete(remoteId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// sendToRemoteCluster posts a request to the remote cluster API of a remote.
+func sendToRemoteCluster(rc *model.RemoteCluster, path string, body []byte) *model.AppError {
+	url := strings.TrimRight(rc.SiteURL, "/") + model.API_URL_SUFFIX + "/remotecluster" + path
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return model.NewAppError("sendToRemoteCluster", "app.remote_cluster.send.app_error", nil, "remote_id="+rc.RemoteId+", "+err.Error(), http.StatusBadRequest)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.HEADER_REMOTE_ID, rc.RemoteRecordId)
+	req.Header.Set(model.HEADER_REMOTE_TOKEN, rc.RemoteToken)
+
+	tr := &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
+		DisableKeepAlives: true,
+	}
+	client := &http.Client{Transport: tr, Timeout: httpTimeout}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return model.NewAppError("sendToRemoteCluster", "app.remote_cluster.send.app_error", nil, "remote_id="+rc.RemoteId+", "+err.Error(), http.StatusBadGateway)
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	details := "remote_id=" + rc.RemoteId + ", status=" + strconv.Itoa(resp.StatusCode)
+	if appErr := model.AppErrorFromJson(resp.Body); appErr != nil {
+		details += ", " + appErr.Error()
+	}
+
+	return model.NewAppError("sendToRemoteCluster", "app.remote_cluster.send.app_error", nil, details, http.StatusBadGateway)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 
+	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -57,8 +58,7 @@ func AddSamlPublicCertificate(fileData *multipart.FileHeader) *model.AppError {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.PublicCertificateFile = fileData.Filename
 
@@ -77,8 +77,7 @@ func AddSamlPrivateCertificate(fileData *multipart.FileHeader) *model.AppError {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.PrivateKeyFile = fileData.Filename
 
@@ -97,8 +96,7 @@ func AddSamlIdpCertificate(fileData *multipart.FileHeader) *model.AppError {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.IdpCertificateFile = fileData.Filename
 
@@ -128,12 +126,11 @@ func RemoveSamlFile(filename string) *model.AppError {
 }
 
 func RemoveSamlPublicCertificate() *model.AppError {
-	if err := RemoveSamlFile(*utils.Cfg.SamlSettings.PublicCertificateFile); err != nil {
+	if err := RemoveSamlFile(*Config().SamlSettings.PublicCertificateFile); err != nil {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.PublicCertificateFile = ""
 	*cfg.SamlSettings.Encrypt = false
@@ -149,12 +146,11 @@ func RemoveSamlPublicCertificate() *model.AppError {
 }
 
 func RemoveSamlPrivateCertificate() *model.AppError {
-	if err := RemoveSamlFile(*utils.Cfg.SamlSettings.PrivateKeyFile); err != nil {
+	if err := RemoveSamlFile(*Config().SamlSettings.PrivateKeyFile); err != nil {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.PrivateKeyFile = ""
 	*cfg.SamlSettings.Encrypt = false
@@ -170,12 +166,11 @@ func RemoveSamlPrivateCertificate() *model.AppError {
 }
 
 func RemoveSamlIdpCertificate() *model.AppError {
-	if err := RemoveSamlFile(*utils.Cfg.SamlSettings.IdpCertificateFile); err != nil {
+	if err := RemoveSamlFile(*Config().SamlSettings.IdpCertificateFile); err != nil {
 		return err
 	}
 
-	cfg := &model.Config{}
-	*cfg = *utils.Cfg
+	cfg := utils.CloneConfig(Config())
 
 	*cfg.SamlSettings.IdpCertificateFile = ""
 	*cfg.SamlSettings.Enable = false
@@ -193,9 +188,84 @@ func RemoveSamlIdpCertificate() *model.AppError {
 func GetSamlCertificateStatus() *model.SamlCertificateStatus {
 	status := &model.SamlCertificateStatus{}
 
-	status.IdpCertificateFile = utils.FileExistsInConfigFolder(*utils.Cfg.SamlSettings.IdpCertificateFile)
-	status.PrivateKeyFile = utils.FileExistsInConfigFolder(*utils.Cfg.SamlSettings.PrivateKeyFile)
-	status.PublicCertificateFile = utils.FileExistsInConfigFolder(*utils.Cfg.SamlSettings.PublicCertificateFile)
+	status.IdpCertificateFile = utils.FileExistsInConfigFolder(*Config().SamlSettings.IdpCertificateFile)
+	status.PrivateKeyFile = utils.FileExistsInConfigFolder(*Config().SamlSettings.PrivateKeyFile)
+	status.PublicCertificateFile = utils.FileExistsInConfigFolder(*Config().SamlSettings.PublicCertificateFile)
 
 	return status
 }
+
+// SyncSamlGroupMemberships updates the team and channel memberships of a user that logged in with SAML
+// to match the groups listed in their assertion. The teams and channels that are in a group mapping
+// are managed by it, so the user is added to the ones their groups are mapped to and removed from the
+// ones none of their groups are mapped to anymore. Other memberships are left alone. Failures are
+// logged rather than returned so that they don't stop the user from logging in.
+func SyncSamlGroupMemberships(user *model.User, groups []string) {
+	mappings := Config().SamlSettings.GroupMappings
+	if len(mappings) == 0 {
+		return
+	}
+
+	inGroup := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		inGroup[group] = true
+	}
+
+	// Each team and channel in a mapping is true if one of the user's groups is mapped to it
+	teams := map[string]bool{}
+	channels := map[string]bool{}
+
+	for _, mapping := range mappings {
+		granted := inGroup[mapping.Group]
+
+		teams[mapping.TeamId] = teams[mapping.TeamId] || granted
+		for _, channelId := range mapping.ChannelIds {
+			channels[channelId] = channels[channelId] || granted
+		}
+	}
+
+	for channelId, granted := range channels {
+		if granted {
+			continue
+		}
+
+		if _, err := GetChannelMember(channelId, user.Id); err != nil {
+			continue
+		}
+
+		if channel, err := GetChannel(channelId); err != nil {
+			l4g.Error(utils.T("app.saml.sync_group_memberships.channel.error"), user.Id, channelId, err)
+		} else if err := removeUserFromChannel(user.Id, user.Id, channel); err != nil {
+			l4g.Error(utils.T("app.saml.sync_group_memberships.channel.error"), user.Id, channelId, err)
+		}
+	}
+
+	// The user has to be on a team before they can be added to its channels
+	for teamId, granted := range teams {
+		if granted {
+			if err := AddUserToTeamByTeamId(teamId, user); err != nil {
+				l4g.Error(utils.T("app.saml.sync_group_memberships.team.error"), user.Id, teamId, err)
+			}
+		} else if member, err := GetTeamMember(teamId, user.Id); err == nil && member.DeleteAt == 0 {
+			if err := RemoveUserFromTeam(teamId, user.Id); err != nil {
+				l4g.Error(utils.T("app.saml.sync_group_memberships.team.error"), user.Id, teamId, err)
+			}
+		}
+	}
+
+	for channelId, granted := range channels {
+		if !granted {
+			continue
+		}
+
+		if _, err := GetChannelMember(channelId, user.Id); err == nil {
+			continue
+		}
+
+		if channel, err := GetChannel(channelId); err != nil {
+			l4g.Error(utils.T("app.saml.sync_group_memberships.channel.error"), user.Id, channelId, err)
+		} else if _, err := AddUserToChannel(user, channel); err != nil {
+			l4g.Error(utils.T("app.saml.sync_group_memberships.channel.error"), user.Id, channelId, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestSyncSamlGroupMemberships(t *testing.T) {
+	th := Setup().InitBasic()
+
+	team := th.CreateTeam()
+	channel := th.CreateChannel(team)
+	user := th.CreateUser()
+
+	if err := JoinUserToTeam(th.BasicTeam, user, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	groupMappings := utils.Cfg.SamlSettings.GroupMappings
+	defer func() {
+		utils.Cfg.SamlSettings.GroupMappings = groupMappings
+	}()
+	utils.Cfg.SamlSettings.GroupMappings = []*model.SamlGroupMapping{
+		{Group: "engineering", TeamId: team.Id, ChannelIds: []string{channel.Id}},
+	}
+
+	SyncSamlGroupMemberships(user, []string{"engineering", "sales"})
+
+	if member, err := GetTeamMember(team.Id, user.Id); err != nil || member.DeleteAt != 0 {
+		t.Fatal("should have added the user to the mapped team")
+	} else if _, err := GetChannelMember(channel.Id, user.Id); err != nil {
+		t.Fatal("should have added the user to the mapped channel")
+	}
+
+	SyncSamlGroupMemberships(user, []string{"sales"})
+
+	if member, err := GetTeamMember(team.Id, user.Id); err != nil || member.DeleteAt == 0 {
+		t.Fatal("should have removed the user from the mapped team")
+	} else if _, err := GetChannelMember(channel.Id, user.Id); err == nil {
+		t.Fatal("should have removed the user from the mapped channel")
+	}
+
+	if member, err := GetTeamMember(th.BasicTeam.Id, user.Id); err != nil || member.DeleteAt != 0 {
+		t.Fatal("shouldn't have removed the user from a team that isn't mapped")
+	}
+}
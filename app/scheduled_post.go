@@ -0,0 +1,148 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SCHEDULED_POSTS_TASK_NAME     = "Scheduled Posts"
+	SCHEDULED_POSTS_TASK_INTERVAL = 30 * time.Second
+	SCHEDULED_POSTS_BATCH_SIZE    = 100
+
+	// A post that couldn't be published is tried again once its claim expires
+	SCHEDULED_POSTS_CLAIM_TIMEOUT = 5 * time.Minute
+	SCHEDULED_POSTS_MAX_ATTEMPTS  = 3
+)
+
+func InitScheduledPosts() {
+	if task := model.GetTaskByName(SCHEDULED_POSTS_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(SCHEDULED_POSTS_TASK_NAME, PublishDueScheduledPosts, SCHEDULED_POSTS_TASK_INTERVAL)
+}
+
+func CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if scheduledPost.ScheduledAt <= model.GetMillis() {
+		return nil, model.NewAppError("CreateScheduledPost", "app.scheduled_post.create.scheduled_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.Channel().Get(scheduledPost.ChannelId, true); result.Err != nil {
+		return nil, model.NewAppError("CreateScheduledPost", "api.context.invalid_param.app_error", map[string]interface{}{"Name": "scheduled_post.channel_id"}, result.Err.Error(), http.StatusBadRequest)
+	} else if result.Data.(*model.Channel).DeleteAt != 0 {
+		return nil, model.NewAppError("CreateScheduledPost", "api.post.create_post.can_not_post_to_deleted.error", nil, "", http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Save(scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPost(scheduledPostId string) (*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().Get(scheduledPostId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPostsForUser(userId string) ([]*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ScheduledPost), nil
+	}
+}
+
+func CancelScheduledPost(scheduledPostId string) *model.AppError {
+	if result := <-Srv.Store.ScheduledPost().Delete(scheduledPostId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// PublishDueScheduledPosts creates the posts whose scheduled time has passed. Each scheduled post is
+// claimed before it is published so that only one server publishes it.
+func PublishDueScheduledPosts() {
+	now := model.GetMillis()
+	claimedBefore := now - int64(SCHEDULED_POSTS_CLAIM_TIMEOUT/time.Millisecond)
+
+	var scheduledPosts []*model.ScheduledPost
+	if result := <-Srv.Store.ScheduledPost().GetDue(now, claimedBefore, SCHEDULED_POSTS_BATCH_SIZE); result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.publish.get_due.error"), result.Err)
+		return
+	} else {
+		scheduledPosts = result.Data.([]*model.ScheduledPost)
+	}
+
+	for _, scheduledPost := range scheduledPosts {
+		if result := <-Srv.Store.ScheduledPost().Claim(scheduledPost.Id, now, claimedBefore); result.Err != nil {
+			continue
+		}
+
+		scheduledPost.ClaimedAt = now
+		scheduledPost.Attempts++
+		publishScheduledPost(scheduledPost)
+	}
+}
+
+// publishScheduledPost creates the post of a claimed scheduled post. The scheduled post is deleted
+// first, while the claim is still ours, so that it can't be published twice if deleting it fails. If
+// creating the post fails, the scheduled post is put back with its claim so that it's tried again once
+// the claim expires, unless the user can't post in the channel anymore or it has failed too many times.
+// Then the scheduled post is dropped and the user is told about it.
+func publishScheduledPost(scheduledPost *model.ScheduledPost) {
+	if !HasPermissionToChannel(scheduledPost.UserId, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		l4g.Warn(utils.T("app.scheduled_post.publish.permissions.warn"), scheduledPost.Id, scheduledPost.UserId, scheduledPost.ChannelId)
+		dropScheduledPost(scheduledPost)
+		return
+	}
+
+	if result := <-Srv.Store.ScheduledPost().DeleteClaimed(scheduledPost.Id, scheduledPost.ClaimedAt); result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.publish.delete.error"), scheduledPost.Id, result.Err)
+		return
+	}
+
+	if _, err := CreatePostAsUser(scheduledPost.ToPost()); err != nil {
+		l4g.Error(utils.T("app.scheduled_post.publish.error"), scheduledPost.Id, err)
+
+		if scheduledPost.Attempts >= SCHEDULED_POSTS_MAX_ATTEMPTS {
+			sendScheduledPostFailed(scheduledPost)
+		} else if result := <-Srv.Store.ScheduledPost().Restore(scheduledPost); result.Err != nil {
+			l4g.Error(utils.T("app.scheduled_post.publish.restore.error"), scheduledPost.Id, result.Err)
+			sendScheduledPostFailed(scheduledPost)
+		}
+	}
+}
+
+// dropScheduledPost deletes a scheduled post that can't be published and tells the user about it.
+func dropScheduledPost(scheduledPost *model.ScheduledPost) {
+	if result := <-Srv.Store.ScheduledPost().Delete(scheduledPost.Id); result.Err != nil {
+		l4g.Error(utils.T("app.scheduled_post.publish.delete.error"), scheduledPost.Id, result.Err)
+		return
+	}
+
+	sendScheduledPostFailed(scheduledPost)
+}
+
+// sendScheduledPostFailed sends the message of a scheduled post that won't be published back to the
+// user in an ephemeral post, so that it isn't lost.
+func sendScheduledPostFailed(scheduledPost *model.ScheduledPost) {
+	SendEphemeralPost("", scheduledPost.UserId, &model.Post{
+		ChannelId: scheduledPost.ChannelId,
+		RootId:    scheduledPost.RootId,
+		ParentId:  scheduledPost.RootId,
+		Message:   utils.T("app.scheduled_post.publish.failed.message", map[string]interface{}{"Message": scheduledPost.Message}),
+	})
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SEARCH_ENGINE_INDEXING_TASK_NAME     = "Search Engine Indexing"
+	SEARCH_ENGINE_INDEXING_TASK_INTERVAL = time.Minute
+)
+
+// InitSearchEngine creates the search indexes and starts the job that indexes the posts, files and
+// users changed since it last ran.
+func InitSearchEngine() {
+	if task := model.GetTaskByName(SEARCH_ENGINE_INDEXING_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	engine := einterfaces.GetSearchEngineInterface()
+	if engine == nil || !engine.IsIndexingEnabled() {
+		return
+	}
+
+	if err := engine.Start(); err != nil {
+		l4g.Error(utils.T("app.search_engine.start.error"), err.Error())
+		return
+	}
+
+	model.CreateRecurringTask(SEARCH_ENGINE_INDEXING_TASK_NAME, IndexForSearchEngine, SEARCH_ENGINE_INDEXING_TASK_INTERVAL)
+}
+
+func IndexForSearchEngine() {
+	engine := einterfaces.GetSearchEngineInterface()
+	if engine == nil || !engine.IsIndexingEnabled() {
+		return
+	}
+
+	indexBatchesForSearchEngine("posts", model.SYSTEM_LAST_INDEXED_POSTS_TIME, func(startTime int64, limit int) (int, int64, *model.AppError) {
+		result := <-Srv.Store.Post().GetPostsBatchForIndexing(startTime, limit)
+		if result.Err != nil {
+			return 0, 0, result.Err
+		}
+
+		posts := result.Data.([]*model.PostForIndexing)
+		if len(posts) == 0 {
+			return 0, 0, nil
+		}
+
+		return len(posts), posts[len(posts)-1].UpdateAt, engine.BulkIndexPosts(posts)
+	})
+
+	indexBatchesForSearchEngine("files", model.SYSTEM_LAST_INDEXED_FILES_TIME, func(startTime int64, limit int) (int, int64, *model.AppError) {
+		result := <-Srv.Store.FileInfo().GetFilesBatchForIndexing(startTime, limit)
+		if result.Err != nil {
+			return 0, 0, result.Err
+		}
+
+		infos := result.Data.([]*model.FileForIndexing)
+		if len(infos) == 0 {
+			return 0, 0, nil
+		}
+
+		return len(infos), infos[len(infos)-1].UpdateAt, engine.BulkIndexFiles(infos)
+	})
+
+	indexBatchesForSearchEngine("users", model.SYSTEM_LAST_INDEXED_USERS_TIME, func(startTime int64, limit int) (int, int64, *model.AppError) {
+		result := <-Srv.Store.User().GetUsersBatchForIndexing(startTime, limit)
+		if result.Err != nil {
+			return 0, 0, result.Err
+		}
+
+		users := result.Data.([]*model.UserForIndexing)
+		if len(users) == 0 {
+			return 0, 0, nil
+		}
+
+		return len(users), users[len(users)-1].UpdateAt, engine.BulkIndexUsers(users)
+	})
+}
+
+// indexBatchesForSearchEngine indexes everything updated since the time saved under systemKey one
+// batch at a time, saving the progress after each batch. indexBatch returns the size of the batch
+// and the update time of its newest item.
+func indexBatchesForSearchEngine(name string, systemKey string, indexBatch func(startTime int64, limit int) (int, int64, *model.AppError)) {
+	var startTime int64
+	if result := <-Srv.Store.System().Get(); result.Err != nil {
+		l4g.Error(utils.T("app.search_engine.index.error"), name, result.Err.Error())
+		return
+	} else {
+		startTime, _ = strconv.ParseInt(result.Data.(model.StringMap)[systemKey], 10, 64)
+	}
+
+	limit := *Config().ElasticsearchSettings.BulkIndexingBatchSize
+
+	for {
+		count, lastUpdateAt, err := indexBatch(startTime, limit)
+		if err != nil {
+			l4g.Error(utils.T("app.search_engine.index.error"), name, err.Error())
+			return
+		} else if count == 0 {
+			return
+		}
+
+		// Move on even if the whole batch was updated at the same time so that the job can't get stuck
+		if lastUpdateAt == startTime && count == limit {
+			lastUpdateAt++
+		}
+
+		if result := <-Srv.Store.System().SaveOrUpdate(&model.System{Name: systemKey, Value: strconv.FormatInt(lastUpdateAt, 10)}); result.Err != nil {
+			l4g.Error(utils.T("app.search_engine.index.error"), name, result.Err.Error())
+			return
+		}
+
+		if count < limit {
+			return
+		}
+
+		startTime = lastUpdateAt
+	}
+}
+
+func indexPostForSearch(post *model.Post) {
+	engine := einterfaces.GetSearchEngineInterface()
+	if engine == nil || !engine.IsIndexingEnabled() {
+		return
+	}
+
+	if result := <-Srv.Store.Channel().Get(post.ChannelId, true); result.Err != nil {
+		l4g.Error(utils.T("app.search_engine.index_post.error"), post.Id, result.Err.Error())
+	} else if err := engine.IndexPost(post, result.Data.(*model.Channel).TeamId); err != nil {
+		l4g.Error(utils.T("app.search_engine.index_post.error"), post.Id, err.Error())
+	}
+}
+
+func deletePostFromSearch(postId string) {
+	engine := einterfaces.GetSearchEngineInterface()
+	if engine == nil || !engine.IsIndexingEnabled() {
+		return
+	}
+
+	if err := engine.DeletePost(postId); err != nil {
+		l4g.Error(utils.T("app.search_engine.delete_post.error"), postId, err.Error())
+	}
+}
+
+func searchPostsInTeamWithEngine(engine einterfaces.SearchEngineInterface, paramsList []*model.SearchParams, userId string, teamId string) (*model.PostList, *model.AppError) {
+	postIds, err := searchInTeamWithEngine(paramsList, userId, teamId, engine.SearchPosts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := model.NewPostList()
+
+	if result := <-Srv.Store.Post().GetPostsByIds(postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, post := range result.Data.([]*model.Post) {
+			list.AddPost(post)
+			list.AddOrder(post.Id)
+		}
+	}
+
+	list.MakeNonNil()
+
+	return list, nil
+}
+
+func searchFilesInTeamWithEngine(engine einterfaces.SearchEngineInterface, paramsList []*model.SearchParams, userId string, teamId string) ([]*model.FileInfo, *model.AppError) {
+	fileIds, err := searchInTeamWithEngine(paramsList, userId, teamId, engine.SearchFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []*model.FileInfo{}
+
+	// The engine may be behind the database, so skip any files that have since been deleted
+	for _, id := range fileIds {
+		if result := <-Srv.Store.FileInfo().Get(id); result.Err == nil {
+			infos = append(infos, result.Data.(*model.FileInfo))
+		}
+	}
+
+	return infos, nil
+}
+
+// searchInTeamWithEngine runs search for each of the search params, limited to the channels that
+// the user belongs to on the team, and returns the ids of the results without any duplicates.
+func searchInTeamWithEngine(paramsList []*model.SearchParams, userId string, teamId string, search func(channelIds []string, userIds []string, params *model.SearchParams) ([]string, *model.AppError)) ([]string, *model.AppError) {
+	var channels model.ChannelList
+	if result := <-Srv.Store.Channel().GetChannels(teamId, userId); result.Err != nil {
+		if result.Err.Id != "store.sql_channel.get_channels.not_found.app_error" {
+			return nil, result.Err
+		}
+	} else {
+		channels = *result.Data.(*model.ChannelList)
+	}
+
+	ids := []string{}
+	found := map[string]bool{}
+
+	for _, params := range paramsList {
+		// don't allow users to search for everything
+		if params.Terms == "*" || (params.Terms == "" && !params.HasFilters()) {
+			continue
+		}
+
+		inChannels := map[string]bool{}
+		for _, name := range params.InChannels {
+			inChannels[name] = true
+		}
+
+		channelIds := []string{}
+		for _, channel := range channels {
+			if len(params.InChannels) == 0 || inChannels[channel.Name] {
+				channelIds = append(channelIds, channel.Id)
+			}
+		}
+
+		userIds := []string{}
+		if len(params.FromUsers) > 0 {
+			if result := <-Srv.Store.User().GetProfilesByUsernames(params.FromUsers, teamId); result.Err != nil {
+				return nil, result.Err
+			} else {
+				for id := range result.Data.(map[string]*model.User) {
+					userIds = append(userIds, id)
+				}
+			}
+
+			if len(userIds) == 0 {
+				continue
+			}
+		}
+
+		if results, err := search(channelIds, userIds, params); err != nil {
+			return nil, err
+		} else {
+			for _, id := range results {
+				if !found[id] {
+					found[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func searchUsersInTeamWithEngine(engine einterfaces.SearchEngineInterface, teamId string, term string, searchOptions map[string]bool) ([]*model.User, *model.AppError) {
+	fields := []string{"username", "first_name", "last_name", "nickname", "email"}
+	if searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME] {
+		fields = []string{"username", "nickname"}
+	} else if searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY] {
+		fields = []string{"username", "first_name", "last_name", "nickname"}
+	} else if searchOptions[store.USER_SEARCH_OPTION_ALL_NO_FULL_NAME] {
+		fields = []string{"username", "nickname", "email"}
+	}
+
+	userIds, err := engine.SearchUsersInTeam(teamId, term, fields, searchOptions[store.USER_SEARCH_OPTION_ALLOW_INACTIVE])
+	if err != nil {
+		return nil, err
+	} else if len(userIds) == 0 {
+		return []*model.User{}, nil
+	}
+
+	if result := <-Srv.Store.User().GetProfileByIds(userIds, true); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.User), nil
+	}
+}
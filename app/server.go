@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	l4g "github.com/alecthomas/log4go"
@@ -27,6 +28,35 @@ type Server struct {
 	WebSocketRouter *WebSocketRouter
 	Router          *mux.Router
 	GracefulServer  *graceful.Server
+
+	// AdditionalServers serve the additional listeners of the service settings
+	AdditionalServers []*graceful.Server
+
+	configService     utils.ConfigService
+	configServiceLock sync.RWMutex
+
+	rateLimitConfigListenerId string
+}
+
+// ConfigService returns the service that provides the config the server runs with.
+func (s *Server) ConfigService() utils.ConfigService {
+	s.configServiceLock.RLock()
+	defer s.configServiceLock.RUnlock()
+
+	if s.configService == nil {
+		return utils.GlobalConfigService
+	}
+
+	return s.configService
+}
+
+// SetConfigService replaces the config the server runs with, such as with a StaticConfigService in
+// tests that change settings. A nil service goes back to the loaded config file.
+func (s *Server) SetConfigService(service utils.ConfigService) {
+	s.configServiceLock.Lock()
+	defer s.configServiceLock.Unlock()
+
+	s.configService = service
 }
 
 var allowedMethods []string = []string{
@@ -43,9 +73,9 @@ type CorsWrapper struct {
 }
 
 func (cw *CorsWrapper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if len(*utils.Cfg.ServiceSettings.AllowCorsFrom) > 0 {
+	if len(*Config().ServiceSettings.AllowCorsFrom) > 0 {
 		origin := r.Header.Get("Origin")
-		if *utils.Cfg.ServiceSettings.AllowCorsFrom == "*" || strings.Contains(*utils.Cfg.ServiceSettings.AllowCorsFrom, origin) {
+		if *Config().ServiceSettings.AllowCorsFrom == "*" || strings.Contains(*Config().ServiceSettings.AllowCorsFrom, origin) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 
 			if r.Method == "OPTIONS" {
@@ -77,6 +107,16 @@ func NewServer() {
 	Srv = &Server{}
 }
 
+// Config returns the config that the app layer should read settings from. It is provided by the
+// config service of the server, or is the loaded config file before the server is created.
+func Config() *model.Config {
+	if Srv == nil {
+		return utils.Cfg
+	}
+
+	return Srv.ConfigService().Config()
+}
+
 func InitStores() {
 	Srv.Store = store.NewSqlStore()
 }
@@ -90,14 +130,14 @@ func (m *VaryBy) Key(r *http.Request) string {
 func initalizeThrottledVaryBy() *throttled.VaryBy {
 	vary := throttled.VaryBy{}
 
-	if utils.Cfg.RateLimitSettings.VaryByRemoteAddr {
+	if Config().RateLimitSettings.VaryByRemoteAddr {
 		vary.RemoteAddr = true
 	}
 
-	if len(utils.Cfg.RateLimitSettings.VaryByHeader) > 0 {
-		vary.Headers = strings.Fields(utils.Cfg.RateLimitSettings.VaryByHeader)
+	if len(Config().RateLimitSettings.VaryByHeader) > 0 {
+		vary.Headers = strings.Fields(Config().RateLimitSettings.VaryByHeader)
 
-		if utils.Cfg.RateLimitSettings.VaryByRemoteAddr {
+		if Config().RateLimitSettings.VaryByRemoteAddr {
 			l4g.Warn(utils.T("api.server.start_server.rate.warn"))
 			vary.RemoteAddr = false
 		}
@@ -122,18 +162,21 @@ func StartServer() {
 
 	var handler http.Handler = &CorsWrapper{Srv.Router}
 
-	if *utils.Cfg.RateLimitSettings.Enable {
+	InitEndpointRateLimiters()
+	Srv.rateLimitConfigListenerId = utils.AddConfigListener(endpointRateLimitConfigListener)
+
+	if *Config().RateLimitSettings.Enable {
 		l4g.Info(utils.T("api.server.start_server.rate.info"))
 
-		store, err := memstore.New(utils.Cfg.RateLimitSettings.MemoryStoreSize)
+		store, err := memstore.New(Config().RateLimitSettings.MemoryStoreSize)
 		if err != nil {
 			l4g.Critical(utils.T("api.server.start_server.rate_limiting_memory_store"))
 			return
 		}
 
 		quota := throttled.RateQuota{
-			MaxRate:  throttled.PerSec(utils.Cfg.RateLimitSettings.PerSec),
-			MaxBurst: *utils.Cfg.RateLimitSettings.MaxBurst,
+			MaxRate:  throttled.PerSec(Config().RateLimitSettings.PerSec),
+			MaxBurst: *Config().RateLimitSettings.MaxBurst,
 		}
 
 		rateLimiter, err := throttled.NewGCRARateLimiter(store, quota)
@@ -154,18 +197,17 @@ func StartServer() {
 		handler = httpRateLimiter.RateLimit(handler)
 	}
 
-	Srv.GracefulServer = &graceful.Server{
-		Timeout: TIME_TO_WAIT_FOR_CONNECTIONS_TO_CLOSE_ON_SERVER_SHUTDOWN,
-		Server: &http.Server{
-			Addr:         utils.Cfg.ServiceSettings.ListenAddress,
-			Handler:      handlers.RecoveryHandler(handlers.PrintRecoveryStack(true))(handler),
-			ReadTimeout:  time.Duration(*utils.Cfg.ServiceSettings.ReadTimeout) * time.Second,
-			WriteTimeout: time.Duration(*utils.Cfg.ServiceSettings.WriteTimeout) * time.Second,
-		},
+	handler = handlers.RecoveryHandler(handlers.PrintRecoveryStack(true))(handler)
+
+	Srv.GracefulServer = newGracefulServer(Config().ServiceSettings.ListenAddress, handler)
+	l4g.Info(utils.T("api.server.start_server.listening.info"), Config().ServiceSettings.ListenAddress)
+
+	Srv.AdditionalServers = nil
+	for _, listener := range Config().ServiceSettings.AdditionalListeners {
+		startAdditionalListener(listener, handler)
 	}
-	l4g.Info(utils.T("api.server.start_server.listening.info"), utils.Cfg.ServiceSettings.ListenAddress)
 
-	if *utils.Cfg.ServiceSettings.Forward80To443 {
+	if *Config().ServiceSettings.Forward80To443 {
 		go func() {
 			listener, err := net.Listen("tcp", ":80")
 			if err != nil {
@@ -180,10 +222,10 @@ func StartServer() {
 
 	go func() {
 		var err error
-		if *utils.Cfg.ServiceSettings.ConnectionSecurity == model.CONN_SECURITY_TLS {
-			if *utils.Cfg.ServiceSettings.UseLetsEncrypt {
+		if *Config().ServiceSettings.ConnectionSecurity == model.CONN_SECURITY_TLS {
+			if *Config().ServiceSettings.UseLetsEncrypt {
 				var m letsencrypt.Manager
-				m.CacheFile(*utils.Cfg.ServiceSettings.LetsEncryptCertificateCacheFile)
+				m.CacheFile(*Config().ServiceSettings.LetsEncryptCertificateCacheFile)
 
 				tlsConfig := &tls.Config{
 					GetCertificate: m.GetCertificate,
@@ -193,7 +235,7 @@ func StartServer() {
 
 				err = Srv.GracefulServer.ListenAndServeTLSConfig(tlsConfig)
 			} else {
-				err = Srv.GracefulServer.ListenAndServeTLS(*utils.Cfg.ServiceSettings.TLSCertFile, *utils.Cfg.ServiceSettings.TLSKeyFile)
+				err = Srv.GracefulServer.ListenAndServeTLS(*Config().ServiceSettings.TLSCertFile, *Config().ServiceSettings.TLSKeyFile)
 			}
 		} else {
 			err = Srv.GracefulServer.ListenAndServe()
@@ -205,11 +247,75 @@ func StartServer() {
 	}()
 }
 
+func newGracefulServer(addr string, handler http.Handler) *graceful.Server {
+	return &graceful.Server{
+		Timeout: TIME_TO_WAIT_FOR_CONNECTIONS_TO_CLOSE_ON_SERVER_SHUTDOWN,
+		Server: &http.Server{
+			Addr:         addr,
+			Handler:      handler,
+			ReadTimeout:  time.Duration(*Config().ServiceSettings.ReadTimeout) * time.Second,
+			WriteTimeout: time.Duration(*Config().ServiceSettings.WriteTimeout) * time.Second,
+		},
+	}
+}
+
+// startAdditionalListener serves the handler on a listener of the service settings, binding it to
+// the listener's network so that an address can be served on IPv4 or IPv6 only.
+func startAdditionalListener(listener *model.Listener, handler http.Handler) {
+	server := newGracefulServer(listener.ListenAddress, handler)
+	Srv.AdditionalServers = append(Srv.AdditionalServers, server)
+
+	l4g.Info(utils.T("api.server.start_server.listening.info"), listener.ListenAddress)
+
+	go func() {
+		l, err := listenAdditional(listener, server)
+		if err == nil {
+			err = server.Serve(l)
+		}
+
+		if err != nil {
+			l4g.Critical(utils.T("api.server.start_server.listener.critical"), listener.ListenAddress, err)
+		}
+	}()
+}
+
+func listenAdditional(listener *model.Listener, server *graceful.Server) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if listener.ConnectionSecurity == model.CONN_SECURITY_TLS {
+		cert, err := tls.LoadX509KeyPair(listener.TLSCertFile, listener.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{"h2"},
+		}
+	}
+
+	l, err := net.Listen(listener.Network, listener.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		server.TLSConfig = tlsConfig
+		return tls.NewListener(l, tlsConfig), nil
+	}
+
+	return l, nil
+}
+
 func StopServer() {
 
 	l4g.Info(utils.T("api.server.stop_server.stopping.info"))
 
 	Srv.GracefulServer.Stop(TIME_TO_WAIT_FOR_CONNECTIONS_TO_CLOSE_ON_SERVER_SHUTDOWN)
+	for _, server := range Srv.AdditionalServers {
+		server.Stop(TIME_TO_WAIT_FOR_CONNECTIONS_TO_CLOSE_ON_SERVER_SHUTDOWN)
+	}
+	StopAuditSinks()
+	utils.RemoveConfigListener(Srv.rateLimitConfigListenerId)
 	Srv.Store.Close()
 	HubStop()
 
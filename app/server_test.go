@@ -0,0 +1,33 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/utils"
+)
+
+func TestServerConfigService(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	s := &Server{}
+	if s.ConfigService().Config() != utils.Cfg {
+		t.Fatal("should serve the loaded config by default")
+	}
+
+	cfg := utils.CloneConfig(utils.Cfg)
+	cfg.TeamSettings.SiteName = "Static " + utils.Cfg.TeamSettings.SiteName
+
+	s.SetConfigService(utils.NewStaticConfigService(cfg))
+	if s.ConfigService().Config() != cfg || utils.Cfg.TeamSettings.SiteName == cfg.TeamSettings.SiteName {
+		t.Fatal("should serve its own config without changing the loaded one")
+	}
+
+	s.SetConfigService(nil)
+	if s.ConfigService().Config() != utils.Cfg {
+		t.Fatal("should go back to the loaded config")
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
 	l4g "github.com/alecthomas/log4go"
 )
 
-var sessionCache *utils.Cache = utils.NewLru(model.SESSION_CACHE_SIZE)
+var sessionCache *utils.Cache = utils.NewNamedLru("session", model.SESSION_CACHE_SIZE)
 
 func CreateSession(session *model.Session) (*model.Session, *model.AppError) {
 	if result := <-Srv.Store.Session().Save(session); result.Err != nil {
@@ -43,6 +45,13 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 
 	if session == nil {
 		if sessionResult := <-Srv.Store.Session().Get(token); sessionResult.Err != nil {
+			// The token may be a user access token whose session has yet to be created
+			if len(token) == 26 {
+				if session, err := createSessionForUserAccessToken(token); err == nil {
+					return session, nil
+				}
+			}
+
 			return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
 		} else {
 			session = sessionResult.Data.(*model.Session)
@@ -87,6 +96,7 @@ func RevokeAllSessions(userId string) *model.AppError {
 			}
 
 			RevokeWebrtcToken(session.Id)
+			publishSessionRevoked(session)
 		}
 	}
 
@@ -121,7 +131,7 @@ func ClearSessionCacheForUserSkipClusterSend(userId string) {
 }
 
 func AddSessionToCache(session *model.Session) {
-	sessionCache.AddWithExpiresInSecs(session.Token, session, int64(*utils.Cfg.ServiceSettings.SessionCacheInMinutes*60))
+	sessionCache.AddWithExpiresInSecs(session.Token, session, int64(*Config().ServiceSettings.SessionCacheInMinutes*60))
 }
 
 func SessionCacheLength() int {
@@ -167,16 +177,154 @@ func RevokeSession(session *model.Session) *model.AppError {
 		}
 	}
 
+	if result := <-Srv.Store.Device().DeleteBySessionId(session.Id); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+
 	RevokeWebrtcToken(session.Id)
 	ClearSessionCacheForUser(session.UserId)
+	publishSessionRevoked(session)
+
+	return nil
+}
+
+// RevokeOtherSessions revokes all of a user's sessions except for the current one. Sessions created
+// for personal access tokens are left alone since those are revoked along with their tokens.
+func RevokeOtherSessions(userId string, currentSessionId string) *model.AppError {
+	sessions, err := GetSessions(userId)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.Id == currentSessionId || session.IsUserAccessToken() {
+			continue
+		}
+
+		if err := RevokeSession(session); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// publishSessionRevoked tells the websocket connections of a revoked session to log out.
+func publishSessionRevoked(session *model.Session) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SESSION_REVOKED, "", "", session.UserId, nil)
+	message.Broadcast.SessionId = session.Id
+	message.Add("session_id", session.Id)
+	Publish(message)
+}
+
+// UpdateSessionLabel sets the name a user has given to one of their sessions so they can tell their
+// devices apart. An empty label removes it.
+func UpdateSessionLabel(userId string, sessionId string, label string) (*model.Session, *model.AppError) {
+	label = strings.TrimSpace(label)
+	if utf8.RuneCountInString(label) > model.SESSION_LABEL_MAX_RUNES {
+		return nil, model.NewAppError("UpdateSessionLabel", "app.session.update_label.length.app_error", map[string]interface{}{"Max": model.SESSION_LABEL_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	var session *model.Session
+	if result := <-Srv.Store.Session().Get(sessionId); result.Err != nil {
+		result.Err.StatusCode = http.StatusBadRequest
+		return nil, result.Err
+	} else {
+		session = result.Data.(*model.Session)
+	}
+
+	// Sessions can also be looked up by token, so make sure the id was really used
+	if session.Id != sessionId || session.UserId != userId {
+		return nil, model.NewAppError("UpdateSessionLabel", "app.session.update_label.not_found.app_error", nil, "session_id="+sessionId, http.StatusBadRequest)
+	}
+
+	if len(label) > 0 {
+		session.AddProp(model.SESSION_PROP_LABEL, label)
+	} else {
+		delete(session.Props, model.SESSION_PROP_LABEL)
+	}
+
+	if result := <-Srv.Store.Session().UpdateProps(session); result.Err != nil {
+		return nil, result.Err
+	}
+
+	ClearSessionCacheForUser(userId)
+
+	return session, nil
+}
+
 func AttachDeviceId(sessionId string, deviceId string, expiresAt int64) *model.AppError {
 	if result := <-Srv.Store.Session().UpdateDeviceId(sessionId, deviceId, expiresAt); result.Err != nil {
 		return result.Err
 	}
 
+	updateDevicePushToken(sessionId, deviceId)
+
+	return nil
+}
+
+// CheckSessionMfa returns an error when MFA is enforced and the user of a session hasn't set it up
+// yet, so that the session can only be used to set it up. It's shared by the APIs that accept
+// session tokens.
+func CheckSessionMfa(session *model.Session) *model.AppError {
+	// Must be licensed for MFA and have it configured for enforcement
+	if !utils.IsLicensed || !*utils.License.Features.MFA || !*Config().ServiceSettings.EnableMultifactorAuthentication || !*Config().ServiceSettings.EnforceMultifactorAuthentication {
+		return nil
+	}
+
+	// OAuth integrations are excepted
+	if session.IsOAuth {
+		return nil
+	}
+
+	// Users have until the end of the grace period to set up MFA
+	if *Config().ServiceSettings.EnforceMultifactorAuthenticationAfter > model.GetMillis() {
+		return nil
+	}
+
+	user, err := GetUser(session.UserId)
+	if err != nil {
+		return model.NewAppError("CheckSessionMfa", "api.context.session_expired.app_error", nil, "MfaRequired", http.StatusUnauthorized)
+	}
+
+	// Only required for email and ldap accounts
+	if user.AuthService != "" &&
+		user.AuthService != model.USER_AUTH_SERVICE_EMAIL &&
+		user.AuthService != model.USER_AUTH_SERVICE_LDAP {
+		return nil
+	}
+
+	if !user.MfaActive {
+		// A security key counts as MFA
+		if hasCredentials, _ := HasUserCredentials(user.Id); hasCredentials {
+			return nil
+		}
+
+		return model.NewAppError("CheckSessionMfa", "api.context.mfa_required.app_error", nil, "MfaRequired", http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+// CheckSessionOAuthScope returns an error when the scope granted to an OAuth session doesn't allow
+// a request. A request is a write when it can change anything, and channelId is the channel that it
+// is for, if any. Other sessions are always allowed.
+func CheckSessionOAuthScope(session *model.Session, isWrite bool, channelId string) *model.AppError {
+	if !session.IsOAuth {
+		return nil
+	}
+
+	if session.IsOAuthUserInfoOnly() {
+		return model.NewAppError("CheckSessionOAuthScope", "api.context.oauth_scope.user_info_only.app_error", nil, "scope="+session.GetOAuthScope(), http.StatusForbidden)
+	}
+
+	if session.IsOAuthReadOnly() && isWrite {
+		return model.NewAppError("CheckSessionOAuthScope", "api.context.oauth_scope.read_only.app_error", nil, "scope="+session.GetOAuthScope(), http.StatusForbidden)
+	}
+
+	if len(channelId) > 0 && !session.IsChannelAllowedByOAuthScope(channelId) {
+		return model.NewAppError("CheckSessionOAuthScope", "api.context.oauth_scope.channel.app_error", nil, "channel_id="+channelId, http.StatusForbidden)
+	}
+
 	return nil
 }
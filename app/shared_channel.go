@@ -0,0 +1,698 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+// sharedChannelSyncs holds the channels being synced. A channel that changes while it's being synced
+// is marked so that it's synced again once the current sync is done.
+var sharedChannelSyncs = struct {
+	sync.Mutex
+	pending map[string]bool
+}{pending: make(map[string]bool)}
+
+// ShareChannelWithRemote starts syncing a channel with a remote, which creates its own copy of the
+// channel in its default team. Only channels that were shared from this server can be shared with
+// more remotes.
+func ShareChannelWithRemote(channel *model.Channel, remoteId string, userId string) (*model.SharedChannelRemote, *model.AppError) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return nil, model.NewAppError("ShareChannelWithRemote", "app.remote_cluster.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if (channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE) || channel.DeleteAt != 0 {
+		return nil, model.NewAppError("ShareChannelWithRemote", "app.shared_channel.share.type.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	rc, err := GetRemoteCluster(remoteId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rc.IsConfirmed() {
+		return nil, model.NewAppError("ShareChannelWithRemote", "app.shared_channel.share.not_confirmed.app_error", nil, "remote_id="+remoteId, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.SharedChannel().Get(channel.Id); result.Err == nil {
+		if !result.Data.(*model.SharedChannel).Home {
+			return nil, model.NewAppError("ShareChannelWithRemote", "app.shared_channel.share.not_home.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+		}
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return nil, result.Err
+	} else {
+		sc := &model.SharedChannel{
+			ChannelId: channel.Id,
+			TeamId:    channel.TeamId,
+			SharedId:  channel.Id,
+			Home:      true,
+			CreatorId: userId,
+		}
+
+		if result := <-Srv.Store.SharedChannel().Save(sc); result.Err != nil {
+			return nil, result.Err
+		}
+	}
+
+	invite := &model.SharedChannelInvite{
+		SharedId:    channel.Id,
+		Type:        channel.Type,
+		Name:        channel.Name,
+		DisplayName: channel.DisplayName,
+		Purpose:     channel.Purpose,
+		Header:      channel.Header,
+	}
+
+	if err := sendToRemoteCluster(rc, "/channel_invite", []byte(invite.ToJson())); err != nil {
+		return nil, err
+	}
+
+	remote := &model.SharedChannelRemote{
+		ChannelId: channel.Id,
+		RemoteId:  remoteId,
+		CreatorId: userId,
+	}
+
+	var rremote *model.SharedChannelRemote
+	if result := <-Srv.Store.SharedChannel().SaveRemote(remote); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rremote = result.Data.(*model.SharedChannelRemote)
+	}
+
+	// The remote is sent the channel's history as well
+	notifySharedChannelChanged(channel.Id)
+
+	return rremote, nil
+}
+
+// UnshareChannelWithRemote stops syncing a channel with a remote. The remote keeps its copy of the
+// channel.
+func UnshareChannelWithRemote(channelId string, remoteId string) *model.AppError {
+	if result := <-Srv.Store.SharedChannel().DeleteRemote(channelId, remoteId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetSharedChannelRemotes(channelId string) ([]*model.SharedChannelRemote, *model.AppError) {
+	if result := <-Srv.Store.SharedChannel().GetRemotes(channelId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.SharedChannelRemote), nil
+	}
+}
+
+// ReceiveSharedChannelInvite creates this server's copy of a channel that a remote shared with it.
+// The copy is created in the remote's default team, and is given another name if the team already
+// has a channel with the same name.
+func ReceiveSharedChannelInvite(rc *model.RemoteCluster, invite *model.SharedChannelInvite) (*model.Channel, *model.AppError) {
+	if len(invite.SharedId) != 26 || (invite.Type != model.CHANNEL_OPEN && invite.Type != model.CHANNEL_PRIVATE) {
+		return nil, model.NewAppError("ReceiveSharedChannelInvite", "app.shared_channel.invite.invalid.app_error", nil, "remote_id="+rc.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(rc.DefaultTeamId) == 0 {
+		return nil, model.NewAppError("ReceiveSharedChannelInvite", "app.shared_channel.invite.no_team.app_error", nil, "remote_id="+rc.RemoteId, http.StatusBadRequest)
+	}
+
+	// The invite is sent again if the remote didn't get the response the first time
+	if result := <-Srv.Store.SharedChannel().GetForRemote(invite.SharedId, rc.RemoteId); result.Err == nil {
+		return GetChannel(result.Data.(*model.SharedChannel).ChannelId)
+	}
+
+	channel := &model.Channel{
+		TeamId:      rc.DefaultTeamId,
+		Type:        invite.Type,
+		Name:        invite.Name,
+		DisplayName: invite.DisplayName,
+		Purpose:     invite.Purpose,
+		Header:      invite.Header,
+		CreatorId:   rc.CreatorId,
+	}
+
+	rchannel, err := CreateChannel(channel, true)
+	if err != nil && err.Id == store.CHANNEL_EXISTS_ERROR {
+		name := invite.Name
+		if len(name)+len(rc.Name)+1 > model.CHANNEL_NAME_MAX_LENGTH {
+			name = name[:model.CHANNEL_NAME_MAX_LENGTH-len(rc.Name)-1]
+		}
+
+		channel.Id = ""
+		channel.Name = name + "-" + rc.Name
+		rchannel, err = CreateChannel(channel, true)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &model.SharedChannel{
+		ChannelId: rchannel.Id,
+		TeamId:    rchannel.TeamId,
+		SharedId:  invite.SharedId,
+		RemoteId:  rc.RemoteId,
+		CreatorId: rc.CreatorId,
+	}
+
+	if result := <-Srv.Store.SharedChannel().Save(sc); result.Err != nil {
+		return nil, result.Err
+	}
+
+	// Changes made here are synced back to the channel's home
+	remote := &model.SharedChannelRemote{
+		ChannelId: rchannel.Id,
+		RemoteId:  rc.RemoteId,
+		CreatorId: rc.CreatorId,
+	}
+
+	if result := <-Srv.Store.SharedChannel().SaveRemote(remote); result.Err != nil {
+		return nil, result.Err
+	}
+
+	return rchannel, nil
+}
+
+// SyncReactionsForSharedChannel marks a post whose reactions changed so that they're synced if the
+// post is in a shared channel.
+func SyncReactionsForSharedChannel(channelId string, postId string) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return
+	}
+
+	if result := <-Srv.Store.SharedChannel().Get(channelId); result.Err != nil {
+		return
+	}
+
+	if result := <-Srv.Store.SharedChannel().TouchPost(postId, model.GetMillis()); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return
+	}
+
+	notifySharedChannelChanged(channelId)
+}
+
+// notifySharedChannelChanged syncs a channel with its remotes in the background if it's shared.
+func notifySharedChannelChanged(channelId string) {
+	if !*Config().ServiceSettings.EnableSharedChannels {
+		return
+	}
+
+	sharedChannelSyncs.Lock()
+	if _, ok := sharedChannelSyncs.pending[channelId]; ok {
+		sharedChannelSyncs.pending[channelId] = true
+		sharedChannelSyncs.Unlock()
+		return
+	}
+	sharedChannelSyncs.pending[channelId] = false
+	sharedChannelSyncs.Unlock()
+
+	go func() {
+		for {
+			syncSharedChannel(channelId)
+
+			sharedChannelSyncs.Lock()
+			if sharedChannelSyncs.pending[channelId] {
+				sharedChannelSyncs.pending[channelId] = false
+				sharedChannelSyncs.Unlock()
+				continue
+			}
+			delete(sharedChannelSyncs.pending, channelId)
+			sharedChannelSyncs.Unlock()
+			return
+		}
+	}()
+}
+
+func syncSharedChannel(channelId string) {
+	var sc *model.SharedChannel
+	if result := <-Srv.Store.SharedChannel().Get(channelId); result.Err != nil {
+		if result.Err.StatusCode != http.StatusNotFound {
+			l4g.Error(result.Err.Error())
+		}
+		return
+	} else {
+		sc = result.Data.(*model.SharedChannel)
+	}
+
+	remotes, err := GetSharedChannelRemotes(channelId)
+	if err != nil {
+		l4g.Error(err.Error())
+		return
+	}
+
+	for _, remote := range remotes {
+		if err := syncSharedChannelRemote(sc, remote); err != nil {
+			l4g.Warn(utils.T("app.shared_channel.sync.warn"), channelId, remote.RemoteId, err.Error())
+		}
+	}
+}
+
+// syncSharedChannelRemote sends a remote the changes to a channel since it was last synced, in
+// batches of the posts that changed. If a batch can't be sent, the rest are sent the next time that
+// the channel changes.
+func syncSharedChannelRemote(sc *model.SharedChannel, remote *model.SharedChannelRemote) *model.AppError {
+	rc, err := GetRemoteCluster(remote.RemoteId)
+	if err != nil {
+		return err
+	}
+
+	if !rc.IsConfirmed() {
+		return nil
+	}
+
+	for {
+		var posts []*model.Post
+		if result := <-Srv.Store.SharedChannel().GetPostsForSync(sc.ChannelId, remote.LastSyncAt, model.REMOTE_CLUSTER_SYNC_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			posts = result.Data.([]*model.Post)
+		}
+
+		if len(posts) == 0 {
+			return nil
+		}
+
+		msg, err := buildSharedChannelSyncMsg(sc, rc, posts)
+		if err != nil {
+			return err
+		}
+
+		if !msg.IsEmpty() {
+			if err := sendToRemoteCluster(rc, "/msg", []byte(msg.ToJson())); err != nil {
+				return err
+			}
+		}
+
+		lastSyncAt := posts[len(posts)-1].UpdateAt
+		if len(posts) == model.REMOTE_CLUSTER_SYNC_BATCH_SIZE && posts[0].UpdateAt != lastSyncAt {
+			// Other posts may have changed at the same time as the last one, so that time is synced again
+			lastSyncAt--
+		}
+
+		if result := <-Srv.Store.SharedChannel().UpdateRemoteLastSyncAt(remote.Id, lastSyncAt); result.Err != nil {
+			return result.Err
+		}
+		remote.LastSyncAt = lastSyncAt
+
+		if len(posts) < model.REMOTE_CLUSTER_SYNC_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+// buildSharedChannelSyncMsg collects the changes to send to a remote. Posts by the remote's own
+// users came from it, so they aren't sent back, but the reactions to them are.
+func buildSharedChannelSyncMsg(sc *model.SharedChannel, rc *model.RemoteCluster, posts []*model.Post) (*model.SharedChannelSyncMsg, *model.AppError) {
+	msg := &model.SharedChannelSyncMsg{
+		SharedId:        sc.SharedId,
+		Users:           []*model.User{},
+		Posts:           []*model.Post{},
+		Reactions:       []*model.Reaction{},
+		ReactionPostIds: []string{},
+		Attachments:     []*model.SharedChannelAttachment{},
+	}
+
+	users := map[string]*model.User{}
+	getUser := func(userId string) *model.User {
+		if user, ok := users[userId]; ok {
+			return user
+		}
+
+		user, err := GetUser(userId)
+		if err != nil {
+			l4g.Warn(err.Error())
+		}
+		users[userId] = user
+		return user
+	}
+
+	sent := map[string]bool{}
+	addUser := func(user *model.User) {
+		if !sent[user.Id] {
+			sent[user.Id] = true
+			msg.Users = append(msg.Users, &model.User{
+				Id:        user.Id,
+				Username:  user.Username,
+				FirstName: user.FirstName,
+				LastName:  user.LastName,
+				Nickname:  user.Nickname,
+			})
+		}
+	}
+
+	for _, post := range posts {
+		msg.ReactionPostIds = append(msg.ReactionPostIds, post.Id)
+
+		user := getUser(post.UserId)
+		if user == nil || user.Props[model.USER_PROP_REMOTE_ID] == rc.RemoteId {
+			continue
+		}
+
+		addUser(user)
+		msg.Posts = append(msg.Posts, post)
+
+		if len(post.FileIds) == 0 || post.DeleteAt != 0 {
+			continue
+		}
+
+		var infos []*model.FileInfo
+		if result := <-Srv.Store.FileInfo().GetForPost(post.Id, false, true); result.Err != nil {
+			return nil, result.Err
+		} else {
+			infos = result.Data.([]*model.FileInfo)
+		}
+
+		for _, info := range infos {
+			if info.Size > model.REMOTE_CLUSTER_MAX_ATTACHMENT_SIZE {
+				continue
+			}
+
+			data, err := ReadFile(info.Path)
+			if err != nil {
+				l4g.Warn(err.Error())
+				continue
+			}
+
+			msg.Attachments = append(msg.Attachments, &model.SharedChannelAttachment{Info: info, Data: data})
+		}
+	}
+
+	reactions, err := GetReactionsForPosts(msg.ReactionPostIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, postId := range msg.ReactionPostIds {
+		for _, reaction := range reactions[postId] {
+			user := getUser(reaction.UserId)
+			if user == nil || user.Props[model.USER_PROP_REMOTE_ID] == rc.RemoteId {
+				continue
+			}
+
+			addUser(user)
+			msg.Reactions = append(msg.Reactions, reaction)
+		}
+	}
+
+	return msg, nil
+}
+
+// ReceiveSharedChannelSync applies changes sent by a remote to this server's copy of a shared
+// channel. A remote can only change what its own users did, and a post is only changed if the
+// remote's version is newer, so that when a post was changed on both servers the latest change wins.
+func ReceiveSharedChannelSync(rc *model.RemoteCluster, msg *model.SharedChannelSyncMsg) *model.AppError {
+	var sc *model.SharedChannel
+	if result := <-Srv.Store.SharedChannel().GetForRemote(msg.SharedId, rc.RemoteId); result.Err != nil {
+		return result.Err
+	} else {
+		sc = result.Data.(*model.SharedChannel)
+	}
+
+	// The users that the remote is allowed to post and react as
+	remoteUsers := map[string]bool{}
+	for _, user := range msg.Users {
+		if ruser, err := upsertRemoteUser(rc, user); err != nil {
+			l4g.Warn(utils.T("app.shared_channel.receive.user.warn"), user.Id, rc.RemoteId, err.Error())
+		} else {
+			remoteUsers[ruser.Id] = true
+		}
+	}
+
+	for _, attachment := range msg.Attachments {
+		if attachment.Info == nil || !remoteUsers[attachment.Info.CreatorId] {
+			continue
+		}
+
+		if err := saveRemoteAttachment(rc, attachment); err != nil {
+			l4g.Warn(utils.T("app.shared_channel.receive.attachment.warn"), attachment.Info.Id, rc.RemoteId, err.Error())
+		}
+	}
+
+	var firstUpdateAt int64
+	for _, post := range msg.Posts {
+		if !remoteUsers[post.UserId] {
+			continue
+		}
+
+		var existing *model.Post
+		if result := <-Srv.Store.Post().GetSingle(post.Id); result.Err == nil {
+			existing = result.Data.(*model.Post)
+		}
+		isNew := existing == nil
+
+		post.ChannelId = sc.ChannelId
+		post.OriginalId = ""
+		post.AddProp(model.POST_PROP_REMOTE_ID, rc.RemoteId)
+
+		if err := checkRemotePost(sc, existing, post); err != nil {
+			l4g.Warn(utils.T("app.shared_channel.receive.post.warn"), post.Id, rc.RemoteId, err.Error())
+			continue
+		}
+
+		if result := <-Srv.Store.SharedChannel().UpsertPost(post); result.Err != nil {
+			l4g.Warn(utils.T("app.shared_channel.receive.post.warn"), post.Id, rc.RemoteId, result.Err.Error())
+			continue
+		} else if !result.Data.(bool) {
+			continue
+		}
+
+		if isNew && post.DeleteAt == 0 && len(sc.TeamId) > 0 {
+			go incrementTeamPostCount(sc.TeamId)
+		}
+
+		if firstUpdateAt == 0 || post.UpdateAt < firstUpdateAt {
+			firstUpdateAt = post.UpdateAt
+		}
+
+		publishRemotePost(post, isNew)
+	}
+
+	receiveRemoteReactions(rc, sc, msg, remoteUsers)
+
+	if firstUpdateAt != 0 {
+		InvalidateCacheForChannelPosts(sc.ChannelId)
+		relaySharedChannelPosts(sc, rc, firstUpdateAt)
+	}
+
+	return nil
+}
+
+// checkRemotePost returns an error if a post sent by a remote can't be saved. Like posts made on
+// this server, new posts count against the post quota of the channel's team and held posts can't
+// be deleted.
+func checkRemotePost(sc *model.SharedChannel, existing *model.Post, post *model.Post) *model.AppError {
+	if existing == nil {
+		if post.DeleteAt == 0 && len(sc.TeamId) > 0 {
+			return checkTeamPostQuota(sc.TeamId)
+		}
+
+		return nil
+	}
+
+	if post.DeleteAt != 0 && existing.DeleteAt == 0 {
+		return checkPostNotHeld(existing)
+	}
+
+	return nil
+}
+
+// upsertRemoteUser creates or updates the user standing in for a user of a remote. Its username has
+// the remote's name added so that it can't be mistaken for a user of this server.
+func upsertRemoteUser(rc *model.RemoteCluster, remoteUser *model.User) (*model.User, *model.AppError) {
+	username := strings.ToLower(remoteUser.Username)
+	if len(username)+len(rc.Name)+1 > model.USER_NAME_MAX_LENGTH {
+		username = username[:model.USER_NAME_MAX_LENGTH-len(rc.Name)-1]
+	}
+
+	user := &model.User{
+		Id:        remoteUser.Id,
+		Username:  username + "." + rc.Name,
+		Email:     remoteUser.Id + "@" + rc.RemoteId + ".invalid",
+		FirstName: remoteUser.FirstName,
+		LastName:  remoteUser.LastName,
+		Nickname:  remoteUser.Nickname,
+		Roles:     model.ROLE_SYSTEM_USER.Id,
+		Locale:    *Config().LocalizationSettings.DefaultClientLocale,
+		Props:     model.StringMap{model.USER_PROP_REMOTE_ID: rc.RemoteId},
+	}
+
+	if result := <-Srv.Store.SharedChannel().UpsertRemoteUser(user); result.Err != nil {
+		return nil, result.Err
+	} else {
+		InvalidateCacheForUser(user.Id)
+		return result.Data.(*model.User), nil
+	}
+}
+
+// saveRemoteAttachment stores a file attached to a post from a remote unless it's already here.
+func saveRemoteAttachment(rc *model.RemoteCluster, attachment *model.SharedChannelAttachment) *model.AppError {
+	info := attachment.Info
+
+	if result := <-Srv.Store.FileInfo().Get(info.Id); result.Err == nil {
+		return nil
+	}
+
+	if int64(len(attachment.Data)) != info.Size || info.Size > model.REMOTE_CLUSTER_MAX_ATTACHMENT_SIZE {
+		return model.NewAppError("saveRemoteAttachment", "app.shared_channel.receive.attachment_size.app_error", nil, "file_id="+info.Id, http.StatusBadRequest)
+	}
+
+	pathPrefix := "remote/" + rc.RemoteId + "/" + info.Id + "/"
+	filename := filepath.Base(info.Name)
+	info.Path = pathPrefix + filename
+	info.ThumbnailPath = ""
+	info.PreviewPath = ""
+
+	if info.IsImage() && strings.LastIndex(filename, ".") > 0 {
+		nameWithoutExtension := filename[:strings.LastIndex(filename, ".")]
+		info.PreviewPath = pathPrefix + nameWithoutExtension + "_preview.jpg"
+		info.ThumbnailPath = pathPrefix + nameWithoutExtension + "_thumb.jpg"
+	}
+
+	if err := WriteFile(attachment.Data, info.Path); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.FileInfo().Save(info); result.Err != nil {
+		return result.Err
+	}
+
+	if len(info.PreviewPath) > 0 {
+		HandleImages([]string{info.PreviewPath}, []string{info.ThumbnailPath}, [][]byte{attachment.Data})
+	}
+
+	return nil
+}
+
+// receiveRemoteReactions brings the reactions of the remote's users to each post up to date with the
+// remote.
+func receiveRemoteReactions(rc *model.RemoteCluster, sc *model.SharedChannel, msg *model.SharedChannelSyncMsg, remoteUsers map[string]bool) {
+	incoming := map[string][]*model.Reaction{}
+	for _, reaction := range msg.Reactions {
+		if remoteUsers[reaction.UserId] {
+			incoming[reaction.PostId] = append(incoming[reaction.PostId], reaction)
+		}
+	}
+
+	for _, postId := range msg.ReactionPostIds {
+		post, err := GetSinglePost(postId)
+		if err != nil || post.ChannelId != sc.ChannelId {
+			continue
+		}
+
+		var existing []*model.Reaction
+		if result := <-Srv.Store.Reaction().GetForPost(postId, false); result.Err != nil {
+			l4g.Warn(result.Err.Error())
+			continue
+		} else {
+			existing = result.Data.([]*model.Reaction)
+		}
+
+		key := func(reaction *model.Reaction) string {
+			return reaction.UserId + ":" + reaction.EmojiName
+		}
+
+		existingKeys := map[string]bool{}
+		for _, reaction := range existing {
+			existingKeys[key(reaction)] = true
+		}
+
+		incomingKeys := map[string]bool{}
+		changed := false
+
+		for _, reaction := range incoming[postId] {
+			incomingKeys[key(reaction)] = true
+			if existingKeys[key(reaction)] {
+				continue
+			}
+
+			added := &model.Reaction{UserId: reaction.UserId, PostId: postId, EmojiName: reaction.EmojiName}
+			if result := <-Srv.Store.Reaction().Save(added); result.Err != nil {
+				l4g.Warn(result.Err.Error())
+				continue
+			}
+
+			changed = true
+			publishRemoteReaction(model.WEBSOCKET_EVENT_REACTION_ADDED, post, added)
+		}
+
+		for _, reaction := range existing {
+			if incomingKeys[key(reaction)] {
+				continue
+			}
+
+			if user, err := GetUser(reaction.UserId); err != nil || user.Props[model.USER_PROP_REMOTE_ID] != rc.RemoteId {
+				continue
+			}
+
+			if result := <-Srv.Store.Reaction().Delete(reaction); result.Err != nil {
+				l4g.Warn(result.Err.Error())
+				continue
+			}
+
+			changed = true
+			publishRemoteReaction(model.WEBSOCKET_EVENT_REACTION_REMOVED, post, reaction)
+		}
+
+		if changed {
+			InvalidateCacheForReactions(postId)
+
+			// The post is touched so that the reactions are passed on to the channel's other remotes
+			if result := <-Srv.Store.SharedChannel().TouchPost(postId, model.GetMillis()); result.Err != nil {
+				l4g.Error(result.Err.Error())
+			}
+			notifySharedChannelChanged(sc.ChannelId)
+		}
+	}
+}
+
+// relaySharedChannelPosts makes sure that posts received from one remote are passed on to the
+// channel's other remotes. The posts keep the times they were changed at on the remote, which may be
+// before the other remotes were last synced.
+func relaySharedChannelPosts(sc *model.SharedChannel, rc *model.RemoteCluster, since int64) {
+	remotes, err := GetSharedChannelRemotes(sc.ChannelId)
+	if err != nil {
+		l4g.Error(err.Error())
+		return
+	}
+
+	for _, remote := range remotes {
+		if remote.RemoteId == rc.RemoteId || remote.LastSyncAt < since {
+			continue
+		}
+
+		if result := <-Srv.Store.SharedChannel().UpdateRemoteLastSyncAt(remote.Id, since-1); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+	}
+
+	notifySharedChannelChanged(sc.ChannelId)
+}
+
+func publishRemotePost(post *model.Post, isNew bool) {
+	event := model.WEBSOCKET_EVENT_POST_EDITED
+	if post.DeleteAt != 0 {
+		event = model.WEBSOCKET_EVENT_POST_DELETED
+	} else if isNew {
+		event = model.WEBSOCKET_EVENT_POSTED
+	}
+
+	message := model.NewWebSocketEvent(event, "", post.ChannelId, "", nil)
+	message.Add("post", post.ToJson())
+	go Publish(message)
+}
+
+func publishRemoteReaction(event string, post *model.Post, reaction *model.Reaction) {
+	message := model.NewWebSocketEvent(event, "", post.ChannelId, "", nil)
+	message.Add("reaction", reaction.ToJson())
+	go Publish(message)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+func GetSidebarCategories(userId string, teamId string) ([]*model.SidebarCategoryWithChannels, *model.AppError) {
+	if result := <-Srv.Store.Channel().GetSidebarCategories(userId, teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.SidebarCategoryWithChannels), nil
+	}
+}
+
+// GetSidebarCategory returns one of the user's categories for the team. Other users' categories are
+// reported as missing.
+func GetSidebarCategory(userId string, teamId string, categoryId string) (*model.SidebarCategoryWithChannels, *model.AppError) {
+	var category *model.SidebarCategoryWithChannels
+	if result := <-Srv.Store.Channel().GetSidebarCategory(categoryId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		category = result.Data.(*model.SidebarCategoryWithChannels)
+	}
+
+	if category.UserId != userId || category.TeamId != teamId {
+		return nil, model.NewAppError("GetSidebarCategory", "app.sidebar_category.get.app_error", nil, "category_id="+categoryId, http.StatusNotFound)
+	}
+
+	return category, nil
+}
+
+func CreateSidebarCategory(userId string, teamId string, category *model.SidebarCategoryWithChannels) (*model.SidebarCategoryWithChannels, *model.AppError) {
+	category.Id = ""
+	category.UserId = userId
+	category.TeamId = teamId
+	if category.Channels == nil {
+		category.Channels = []string{}
+	}
+
+	if err := checkSidebarChannels(userId, teamId, category.Channels); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Channel().SaveSidebarCategory(category); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rcategory := result.Data.(*model.SidebarCategoryWithChannels)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SIDEBAR_CATEGORY_CREATED, "", "", userId, nil)
+		message.Add("team_id", teamId)
+		message.Add("category_id", rcategory.Id)
+		go Publish(message)
+
+		return rcategory, nil
+	}
+}
+
+// UpdateSidebarCategory renames, collapses or expands a category and sets the channels in it. Channels
+// that are moved into the category are taken out of the category they were in before.
+func UpdateSidebarCategory(userId string, teamId string, category *model.SidebarCategoryWithChannels) (*model.SidebarCategoryWithChannels, *model.AppError) {
+	oldCategory, err := GetSidebarCategory(userId, teamId, category.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSidebarChannels(userId, teamId, category.Channels); err != nil {
+		return nil, err
+	}
+
+	oldCategory.DisplayName = category.DisplayName
+	oldCategory.Collapsed = category.Collapsed
+	oldCategory.Channels = category.Channels
+	if oldCategory.Channels == nil {
+		oldCategory.Channels = []string{}
+	}
+
+	if result := <-Srv.Store.Channel().UpdateSidebarCategory(oldCategory); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rcategory := result.Data.(*model.SidebarCategoryWithChannels)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SIDEBAR_CATEGORY_UPDATED, "", "", userId, nil)
+		message.Add("team_id", teamId)
+		message.Add("category_id", rcategory.Id)
+		go Publish(message)
+
+		return rcategory, nil
+	}
+}
+
+// UpdateSidebarCategoryOrder sorts the user's categories for the team. The order has to list every one
+// of the user's categories for the team exactly once.
+func UpdateSidebarCategoryOrder(userId string, teamId string, categoryOrder []string) *model.AppError {
+	categories, err := GetSidebarCategories(userId, teamId)
+	if err != nil {
+		return err
+	}
+
+	if len(categoryOrder) != len(categories) {
+		return model.NewAppError("UpdateSidebarCategoryOrder", "app.sidebar_category.update_order.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	ids := make(map[string]bool)
+	for _, category := range categories {
+		ids[category.Id] = true
+	}
+
+	for _, categoryId := range categoryOrder {
+		if !ids[categoryId] {
+			return model.NewAppError("UpdateSidebarCategoryOrder", "app.sidebar_category.update_order.app_error", nil, "user_id="+userId+", category_id="+categoryId, http.StatusBadRequest)
+		}
+		delete(ids, categoryId)
+	}
+
+	if result := <-Srv.Store.Channel().UpdateSidebarCategoryOrder(userId, teamId, categoryOrder); result.Err != nil {
+		return result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SIDEBAR_CATEGORY_ORDER_UPDATED, "", "", userId, nil)
+	message.Add("team_id", teamId)
+	message.Add("order", categoryOrder)
+	go Publish(message)
+
+	return nil
+}
+
+func DeleteSidebarCategory(userId string, teamId string, categoryId string) *model.AppError {
+	if _, err := GetSidebarCategory(userId, teamId, categoryId); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.Channel().DeleteSidebarCategory(categoryId); result.Err != nil {
+		return result.Err
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SIDEBAR_CATEGORY_DELETED, "", "", userId, nil)
+	message.Add("team_id", teamId)
+	message.Add("category_id", categoryId)
+	go Publish(message)
+
+	return nil
+}
+
+// checkSidebarChannels returns a 400 unless the user is a member of every channel, and the channels are
+// either in the team or are direct and group messages.
+func checkSidebarChannels(userId string, teamId string, channelIds []string) *model.AppError {
+	if len(channelIds) == 0 {
+		return nil
+	}
+
+	var channels *model.ChannelList
+	if result := <-Srv.Store.Channel().GetChannels(teamId, userId); result.Err != nil {
+		if result.Err.Id == "store.sql_channel.get_channels.not_found.app_error" {
+			return model.NewAppError("checkSidebarChannels", "app.sidebar_category.channel.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+		}
+		return result.Err
+	} else {
+		channels = result.Data.(*model.ChannelList)
+	}
+
+	memberOf := make(map[string]bool)
+	for _, channel := range *channels {
+		memberOf[channel.Id] = true
+	}
+
+	for _, channelId := range channelIds {
+		if !memberOf[channelId] {
+			return model.NewAppError("checkSidebarChannels", "app.sidebar_category.channel.app_error", nil, "user_id="+userId+", channel_id="+channelId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
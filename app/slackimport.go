@@ -11,6 +11,7 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -40,17 +41,20 @@ type SlackFile struct {
 }
 
 type SlackPost struct {
-	User        string                   `json:"user"`
-	BotId       string                   `json:"bot_id"`
-	BotUsername string                   `json:"username"`
-	Text        string                   `json:"text"`
-	TimeStamp   string                   `json:"ts"`
-	Type        string                   `json:"type"`
-	SubType     string                   `json:"subtype"`
-	Comment     *SlackComment            `json:"comment"`
-	Upload      bool                     `json:"upload"`
-	File        *SlackFile               `json:"file"`
-	Attachments []*model.SlackAttachment `json:"attachments"`
+	User            string                   `json:"user"`
+	BotId           string                   `json:"bot_id"`
+	BotUsername     string                   `json:"username"`
+	Text            string                   `json:"text"`
+	TimeStamp       string                   `json:"ts"`
+	ThreadTimeStamp string                   `json:"thread_ts"`
+	Type            string                   `json:"type"`
+	SubType         string                   `json:"subtype"`
+	Comment         *SlackComment            `json:"comment"`
+	Upload          bool                     `json:"upload"`
+	File            *SlackFile               `json:"file"`
+	Attachments     []*model.SlackAttachment `json:"attachments"`
+	Reactions       []SlackReaction          `json:"reactions"`
+	PinnedTo        []string                 `json:"pinned_to"`
 }
 
 type SlackComment struct {
@@ -58,6 +62,73 @@ type SlackComment struct {
 	Comment string `json:"comment"`
 }
 
+type SlackReaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+// SlackImportSummary counts the items of a Slack export that couldn't be imported so that they can
+// be listed at the end of the import log.
+type SlackImportSummary struct {
+	PostsWithoutUser   int
+	PostsNotSaved      int
+	UnsupportedPosts   map[string]int
+	RepliesWithoutRoot int
+	ReactionsSkipped   int
+}
+
+func NewSlackImportSummary() *SlackImportSummary {
+	return &SlackImportSummary{
+		UnsupportedPosts: make(map[string]int),
+	}
+}
+
+func (s *SlackImportSummary) AddUnsupportedPost(sPost SlackPost) {
+	postType := sPost.Type
+	if sPost.SubType != "" {
+		postType += "/" + sPost.SubType
+	}
+
+	s.UnsupportedPosts[postType]++
+}
+
+func (s *SlackImportSummary) WriteReport(log *bytes.Buffer) {
+	log.WriteString(utils.T("api.slackimport.slack_import.skipped"))
+	log.WriteString("=============\r\n\r\n")
+
+	if s.PostsWithoutUser == 0 && s.PostsNotSaved == 0 && len(s.UnsupportedPosts) == 0 && s.RepliesWithoutRoot == 0 && s.ReactionsSkipped == 0 {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.none"))
+		return
+	}
+
+	if s.PostsWithoutUser > 0 {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.posts_without_user", map[string]interface{}{"Count": s.PostsWithoutUser}))
+	}
+
+	if s.PostsNotSaved > 0 {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.posts_not_saved", map[string]interface{}{"Count": s.PostsNotSaved}))
+	}
+
+	postTypes := make([]string, 0, len(s.UnsupportedPosts))
+	for postType := range s.UnsupportedPosts {
+		postTypes = append(postTypes, postType)
+	}
+	sort.Strings(postTypes)
+
+	for _, postType := range postTypes {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.unsupported_posts", map[string]interface{}{"Count": s.UnsupportedPosts[postType], "Type": postType}))
+	}
+
+	if s.RepliesWithoutRoot > 0 {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.replies_without_root", map[string]interface{}{"Count": s.RepliesWithoutRoot}))
+	}
+
+	if s.ReactionsSkipped > 0 {
+		log.WriteString(utils.T("api.slackimport.slack_import.skipped.reactions", map[string]interface{}{"Count": s.ReactionsSkipped}))
+	}
+}
+
 func truncateRunes(s string, i int) string {
 	runes := []rune(s)
 	if len(runes) > i {
@@ -217,15 +288,27 @@ func SlackAddBotUser(teamId string, log *bytes.Buffer) *model.User {
 	}
 }
 
-func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, users map[string]*model.User, uploads map[string]*zip.File, botUser *model.User) {
+func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, users map[string]*model.User, uploads map[string]*zip.File, botUser *model.User, summary *SlackImportSummary) {
+	// Replies have to be imported after the root post of their thread so that they can be attached to it
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].TimeStamp < posts[j].TimeStamp
+	})
+
+	threads := make(map[string]string)
+	var reactions []*model.Reaction
+
 	for _, sPost := range posts {
+		var postId string
+
 		switch {
 		case sPost.Type == "message" && (sPost.SubType == "" || sPost.SubType == "file_share"):
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.without_user.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -240,7 +323,8 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 					newPost.Message = sPost.File.Title
 				}
 			}
-			OldImportPost(&newPost)
+			SlackSetPostThreadAndPin(&newPost, sPost, threads, summary)
+			postId = OldImportPost(&newPost)
 			for _, fileId := range newPost.FileIds {
 				if result := <-Srv.Store.FileInfo().AttachToPost(fileId, newPost.Id); result.Err != nil {
 					l4g.Error(utils.T("api.slackimport.slack_add_posts.attach_files.error"), newPost.Id, newPost.FileIds, result.Err)
@@ -250,12 +334,15 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 		case sPost.Type == "message" && sPost.SubType == "file_comment":
 			if sPost.Comment == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_comment.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if sPost.Comment.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_usr.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.Comment.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -264,13 +351,16 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				Message:   sPost.Comment.Comment,
 				CreateAt:  SlackConvertTimeStamp(sPost.TimeStamp),
 			}
-			OldImportPost(&newPost)
+			SlackSetPostThreadAndPin(&newPost, sPost, threads, summary)
+			postId = OldImportPost(&newPost)
 		case sPost.Type == "message" && sPost.SubType == "bot_message":
 			if botUser == nil {
 				l4g.Warn(utils.T("api.slackimport.slack_add_posts.bot_user_no_exists.warn"))
+				summary.PostsWithoutUser++
 				continue
 			} else if sPost.BotId == "" {
 				l4g.Warn(utils.T("api.slackimport.slack_add_posts.no_bot_id.warn"))
+				summary.PostsWithoutUser++
 				continue
 			}
 
@@ -288,13 +378,16 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				Type:      model.POST_SLACK_ATTACHMENT,
 			}
 
-			OldImportIncomingWebhookPost(post, props)
+			SlackSetPostThreadAndPin(post, sPost, threads, summary)
+			postId = OldImportIncomingWebhookPost(post, props)
 		case sPost.Type == "message" && (sPost.SubType == "channel_join" || sPost.SubType == "channel_leave"):
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_usr.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 
@@ -315,13 +408,15 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 					"username": users[sPost.User].Username,
 				},
 			}
-			OldImportPost(&newPost)
+			postId = OldImportPost(&newPost)
 		case sPost.Type == "message" && sPost.SubType == "me_message":
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.without_user.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -330,13 +425,16 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				Message:   "*" + sPost.Text + "*",
 				CreateAt:  SlackConvertTimeStamp(sPost.TimeStamp),
 			}
-			OldImportPost(&newPost)
+			SlackSetPostThreadAndPin(&newPost, sPost, threads, summary)
+			postId = OldImportPost(&newPost)
 		case sPost.Type == "message" && sPost.SubType == "channel_topic":
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_usr.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -346,13 +444,15 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				CreateAt:  SlackConvertTimeStamp(sPost.TimeStamp),
 				Type:      model.POST_HEADER_CHANGE,
 			}
-			OldImportPost(&newPost)
+			postId = OldImportPost(&newPost)
 		case sPost.Type == "message" && sPost.SubType == "channel_purpose":
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_usr.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -362,13 +462,15 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				CreateAt:  SlackConvertTimeStamp(sPost.TimeStamp),
 				Type:      model.POST_PURPOSE_CHANGE,
 			}
-			OldImportPost(&newPost)
+			postId = OldImportPost(&newPost)
 		case sPost.Type == "message" && sPost.SubType == "channel_name":
 			if sPost.User == "" {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.msg_no_usr.debug"))
+				summary.PostsWithoutUser++
 				continue
 			} else if users[sPost.User] == nil {
 				l4g.Debug(utils.T("api.slackimport.slack_add_posts.user_no_exists.debug"), sPost.User)
+				summary.PostsWithoutUser++
 				continue
 			}
 			newPost := model.Post{
@@ -378,11 +480,91 @@ func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, use
 				CreateAt:  SlackConvertTimeStamp(sPost.TimeStamp),
 				Type:      model.POST_DISPLAYNAME_CHANGE,
 			}
-			OldImportPost(&newPost)
+			postId = OldImportPost(&newPost)
 		default:
 			l4g.Warn(utils.T("api.slackimport.slack_add_posts.unsupported.warn"), sPost.Type, sPost.SubType)
+			summary.AddUnsupportedPost(sPost)
+			continue
+		}
+
+		if len(postId) == 0 {
+			summary.PostsNotSaved++
+			continue
+		}
+
+		if sPost.ThreadTimeStamp == "" || sPost.ThreadTimeStamp == sPost.TimeStamp {
+			threads[sPost.TimeStamp] = postId
+		}
+
+		reactions = append(reactions, SlackConvertReactions(sPost.Reactions, postId, SlackConvertTimeStamp(sPost.TimeStamp), users, summary)...)
+	}
+
+	if len(reactions) > 0 {
+		if result := <-Srv.Store.Reaction().SaveMultiple(reactions); result.Err != nil {
+			l4g.Error(utils.T("api.slackimport.slack_add_posts.save_reactions.error"), channel.Id, result.Err)
+			summary.ReactionsSkipped += len(reactions)
+		} else {
+			// Reactions that already existed, if the export was imported before, aren't inserted again
+			summary.ReactionsSkipped += len(reactions) - result.Data.(int)
+		}
+	}
+}
+
+// SlackSetPostThreadAndPin attaches a reply to the root post of its thread, which must have been
+// imported already, and pins the post if it was pinned in Slack. A reply whose root post couldn't be
+// found is imported as a regular post.
+func SlackSetPostThreadAndPin(post *model.Post, sPost SlackPost, threads map[string]string, summary *SlackImportSummary) {
+	post.IsPinned = len(sPost.PinnedTo) > 0
+
+	if sPost.ThreadTimeStamp == "" || sPost.ThreadTimeStamp == sPost.TimeStamp {
+		return
+	}
+
+	if rootId, ok := threads[sPost.ThreadTimeStamp]; ok {
+		post.RootId = rootId
+		post.ParentId = rootId
+	} else {
+		summary.RepliesWithoutRoot++
+	}
+}
+
+// SlackConvertReactions returns a reaction for each user that reacted to a post in Slack. Reactions
+// of users that weren't imported or with emojis whose names are too long are skipped.
+func SlackConvertReactions(sReactions []SlackReaction, postId string, createAt int64, users map[string]*model.User, summary *SlackImportSummary) []*model.Reaction {
+	var reactions []*model.Reaction
+
+	for _, sReaction := range sReactions {
+		// Skin tones are appended to the name of the emoji, as in thumbsup::skin-tone-2
+		emojiName := strings.SplitN(sReaction.Name, "::", 2)[0]
+
+		// The export only lists some of the users when lots of them used the same reaction
+		if len(sReaction.Users) < sReaction.Count {
+			summary.ReactionsSkipped += sReaction.Count - len(sReaction.Users)
+		}
+
+		for _, userId := range sReaction.Users {
+			user := users[userId]
+			if user == nil {
+				summary.ReactionsSkipped++
+				continue
+			}
+
+			reaction := &model.Reaction{
+				UserId:    user.Id,
+				PostId:    postId,
+				EmojiName: emojiName,
+				CreateAt:  createAt,
+			}
+			if reaction.IsValid() != nil {
+				summary.ReactionsSkipped++
+				continue
+			}
+
+			reactions = append(reactions, reaction)
 		}
 	}
+
+	return reactions
 }
 
 func SlackUploadFile(sPost SlackPost, uploads map[string]*zip.File, teamId string, channelId string, userId string) (*model.FileInfo, bool) {
@@ -455,7 +637,7 @@ func SlackSanitiseChannelProperties(channel model.Channel) model.Channel {
 	return channel
 }
 
-func SlackAddChannels(teamId string, slackchannels []SlackChannel, posts map[string][]SlackPost, users map[string]*model.User, uploads map[string]*zip.File, botUser *model.User, log *bytes.Buffer) map[string]*model.Channel {
+func SlackAddChannels(teamId string, slackchannels []SlackChannel, posts map[string][]SlackPost, users map[string]*model.User, uploads map[string]*zip.File, botUser *model.User, log *bytes.Buffer, summary *SlackImportSummary) map[string]*model.Channel {
 	// Write Header
 	log.WriteString(utils.T("api.slackimport.slack_add_channels.added"))
 	log.WriteString("=================\r\n\r\n")
@@ -496,7 +678,7 @@ func SlackAddChannels(teamId string, slackchannels []SlackChannel, posts map[str
 		addSlackUsersToChannel(sChannel.Members, users, mChannel, log)
 		log.WriteString(newChannel.DisplayName + "\r\n")
 		addedChannels[sChannel.Id] = mChannel
-		SlackAddPosts(teamId, mChannel, posts[sChannel.Name], users, uploads, botUser)
+		SlackAddPosts(teamId, mChannel, posts[sChannel.Name], users, uploads, botUser, summary)
 	}
 
 	return addedChannels
@@ -664,7 +846,8 @@ func SlackImport(fileData multipart.File, fileSize int64, teamID string) (*model
 	addedUsers := SlackAddUsers(teamID, users, log)
 	botUser := SlackAddBotUser(teamID, log)
 
-	SlackAddChannels(teamID, channels, posts, addedUsers, uploads, botUser, log)
+	summary := NewSlackImportSummary()
+	SlackAddChannels(teamID, channels, posts, addedUsers, uploads, botUser, log, summary)
 
 	if botUser != nil {
 		deactivateSlackBotUser(botUser)
@@ -672,6 +855,8 @@ func SlackImport(fileData multipart.File, fileSize int64, teamID string) (*model
 
 	InvalidateAllCaches()
 
+	summary.WriteReport(log)
+
 	log.WriteString(utils.T("api.slackimport.slack_import.notes"))
 	log.WriteString("=======\r\n\r\n")
 
@@ -327,3 +327,96 @@ in this~.`,
 		}
 	}
 }
+
+func TestSlackParseThreadedPosts(t *testing.T) {
+	data := `[
+		{"type": "message", "user": "U1", "text": "root", "ts": "1438805072.000002", "thread_ts": "1438805072.000002", "pinned_to": ["C1"], "reactions": [{"name": "thumbsup::skin-tone-2", "users": ["U1", "U2"], "count": 3}]},
+		{"type": "message", "user": "U2", "text": "reply", "ts": "1438805082.000003", "thread_ts": "1438805072.000002"}
+	]`
+
+	posts, err := SlackParsePosts(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Error occurred parsing posts: %v", err)
+	}
+
+	if len(posts) != 2 || posts[1].ThreadTimeStamp != posts[0].TimeStamp {
+		t.Fatal("should have parsed the thread", posts)
+	}
+
+	if len(posts[0].PinnedTo) != 1 || len(posts[0].Reactions) != 1 || posts[0].Reactions[0].Count != 3 || len(posts[0].Reactions[0].Users) != 2 {
+		t.Fatal("should have parsed the pins and reactions", posts[0])
+	}
+}
+
+func TestSlackSetPostThreadAndPin(t *testing.T) {
+	summary := NewSlackImportSummary()
+	rootId := model.NewId()
+	threads := map[string]string{"1438805072.000002": rootId}
+
+	root := &model.Post{}
+	SlackSetPostThreadAndPin(root, SlackPost{TimeStamp: "1438805072.000002", ThreadTimeStamp: "1438805072.000002", PinnedTo: []string{"C1"}}, threads, summary)
+	if len(root.RootId) != 0 || !root.IsPinned {
+		t.Fatal("should have pinned the root post without attaching it to a thread", root)
+	}
+
+	reply := &model.Post{}
+	SlackSetPostThreadAndPin(reply, SlackPost{TimeStamp: "1438805082.000003", ThreadTimeStamp: "1438805072.000002"}, threads, summary)
+	if reply.RootId != rootId || reply.ParentId != rootId || reply.IsPinned {
+		t.Fatal("should have attached the reply to the root post", reply)
+	}
+
+	orphan := &model.Post{}
+	SlackSetPostThreadAndPin(orphan, SlackPost{TimeStamp: "1438805092.000004", ThreadTimeStamp: "1438805000.000001"}, threads, summary)
+	if len(orphan.RootId) != 0 || len(orphan.ParentId) != 0 {
+		t.Fatal("should have imported the reply as a regular post", orphan)
+	}
+
+	if summary.RepliesWithoutRoot != 1 {
+		t.Fatal("should have counted the reply without a root post", summary.RepliesWithoutRoot)
+	}
+}
+
+func TestSlackConvertReactions(t *testing.T) {
+	summary := NewSlackImportSummary()
+	users := map[string]*model.User{
+		"U1": {Id: model.NewId()},
+		"U2": {Id: model.NewId()},
+	}
+	postId := model.NewId()
+
+	sReactions := []SlackReaction{
+		{Name: "thumbsup::skin-tone-2", Users: []string{"U1", "U3"}, Count: 2},
+		{Name: "smile", Users: []string{"U1", "U2"}, Count: 5},
+		{Name: strings.Repeat("a", 65), Users: []string{"U2"}, Count: 1},
+	}
+
+	reactions := SlackConvertReactions(sReactions, postId, 1000, users, summary)
+	if len(reactions) != 3 {
+		t.Fatal("should have converted the reactions of the imported users", reactions)
+	}
+
+	if reactions[0].EmojiName != "thumbsup" || reactions[0].UserId != users["U1"].Id || reactions[0].PostId != postId || reactions[0].CreateAt != 1000 {
+		t.Fatal("should have removed the skin tone from the emoji name", reactions[0])
+	}
+
+	if reactions[1].EmojiName != "smile" || reactions[2].UserId != users["U2"].Id {
+		t.Fatal("should have converted every user's reaction", reactions[1], reactions[2])
+	}
+
+	// U3 wasn't imported, 3 of the users of the second reaction weren't listed and the last emoji name is too long
+	if summary.ReactionsSkipped != 5 {
+		t.Fatal("should have counted the skipped reactions", summary.ReactionsSkipped)
+	}
+}
+
+func TestSlackImportSummaryAddUnsupportedPost(t *testing.T) {
+	summary := NewSlackImportSummary()
+
+	summary.AddUnsupportedPost(SlackPost{Type: "message", SubType: "pinned_item"})
+	summary.AddUnsupportedPost(SlackPost{Type: "message", SubType: "pinned_item"})
+	summary.AddUnsupportedPost(SlackPost{Type: "reminder"})
+
+	if len(summary.UnsupportedPosts) != 2 || summary.UnsupportedPosts["message/pinned_item"] != 2 || summary.UnsupportedPosts["reminder"] != 1 {
+		t.Fatal("should have counted the unsupported posts by type", summary.UnsupportedPosts)
+	}
+}
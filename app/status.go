@@ -12,7 +12,7 @@ import (
 	"github.com/mattermost/platform/utils"
 )
 
-var statusCache *utils.Cache = utils.NewLru(model.STATUS_CACHE_SIZE)
+var statusCache *utils.Cache = utils.NewNamedLru("status", model.STATUS_CACHE_SIZE)
 
 func ClearStatusCache() {
 	statusCache.Purge()
@@ -172,6 +172,7 @@ func SetStatusOnline(userId string, sessionId string, manual bool) {
 		status.Status = model.STATUS_ONLINE
 		status.Manual = false // for "online" there's no manual setting
 		status.LastActivityAt = model.GetMillis()
+		status.DNDEndTime = 0
 	}
 
 	AddStatusCache(status)
@@ -180,6 +181,7 @@ func SetStatusOnline(userId string, sessionId string, manual bool) {
 	// or enough time has passed since the previous action
 	if status.Status != oldStatus || status.Manual != oldManual || status.LastActivityAt-oldTime > model.STATUS_MIN_UPDATE_TIME {
 		achan := Srv.Store.Session().UpdateLastActivityAt(sessionId, status.LastActivityAt)
+		dchan := Srv.Store.Device().UpdateLastSeenAt(sessionId, status.LastActivityAt)
 
 		var schan store.StoreChannel
 		if broadcast {
@@ -192,16 +194,17 @@ func SetStatusOnline(userId string, sessionId string, manual bool) {
 			l4g.Error(utils.T("api.status.last_activity.error"), userId, sessionId, result.Err)
 		}
 
+		if result := <-dchan; result.Err != nil {
+			l4g.Error(utils.T("app.device.update_last_seen_at.error"), sessionId, result.Err)
+		}
+
 		if result := <-schan; result.Err != nil {
 			l4g.Error(utils.T("api.status.save_status.error"), userId, result.Err)
 		}
 	}
 
 	if broadcast {
-		event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", status.UserId, nil)
-		event.Add("status", model.STATUS_ONLINE)
-		event.Add("user_id", status.UserId)
-		go Publish(event)
+		publishStatusChange(status)
 	}
 }
 
@@ -211,7 +214,12 @@ func SetStatusOffline(userId string, manual bool) {
 		return // manually set status always overrides non-manual one
 	}
 
-	status = &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: manual, LastActivityAt: model.GetMillis(), ActiveChannel: ""}
+	offlineStatus := &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: manual, LastActivityAt: model.GetMillis(), ActiveChannel: ""}
+	if err == nil {
+		// the custom status is kept while the user is offline
+		offlineStatus.SetCustomStatus(status.GetCustomStatus())
+	}
+	status = offlineStatus
 
 	AddStatusCache(status)
 
@@ -219,10 +227,7 @@ func SetStatusOffline(userId string, manual bool) {
 		l4g.Error(utils.T("api.status.save_status.error"), userId, result.Err)
 	}
 
-	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", status.UserId, nil)
-	event.Add("status", model.STATUS_OFFLINE)
-	event.Add("user_id", status.UserId)
-	go Publish(event)
+	publishStatusChange(status)
 }
 
 func SetStatusAwayIfNeeded(userId string, manual bool) {
@@ -249,6 +254,7 @@ func SetStatusAwayIfNeeded(userId string, manual bool) {
 	status.Status = model.STATUS_AWAY
 	status.Manual = manual
 	status.ActiveChannel = ""
+	status.DNDEndTime = 0
 
 	AddStatusCache(status)
 
@@ -256,10 +262,7 @@ func SetStatusAwayIfNeeded(userId string, manual bool) {
 		l4g.Error(utils.T("api.status.save_status.error"), userId, result.Err)
 	}
 
-	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", status.UserId, nil)
-	event.Add("status", model.STATUS_AWAY)
-	event.Add("user_id", status.UserId)
-	go Publish(event)
+	publishStatusChange(status)
 }
 
 func GetStatusFromCache(userId string) *model.Status {
@@ -287,5 +290,5 @@ func GetStatus(userId string) (*model.Status, *model.AppError) {
 }
 
 func IsUserAway(lastActivityAt int64) bool {
-	return model.GetMillis()-lastActivityAt >= *utils.Cfg.TeamSettings.UserStatusAwayTimeout*1000
+	return model.GetMillis()-lastActivityAt >= *Config().TeamSettings.UserStatusAwayTimeout*1000
 }
@@ -63,7 +63,7 @@ func isTeamEmailAllowed(user *model.User) bool {
 
 	// commas and @ signs are optional
 	// can be in the form of "@corp.mattermost.com, mattermost.com mattermost.org" -> corp.mattermost.com mattermost.com mattermost.org
-	domains := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(strings.Replace(utils.Cfg.TeamSettings.RestrictCreationToDomains, "@", " ", -1), ",", " ", -1))))
+	domains := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(strings.Replace(Config().TeamSettings.RestrictCreationToDomains, "@", " ", -1), ",", " ", -1))))
 
 	matched := false
 	for _, d := range domains {
@@ -73,7 +73,7 @@ func isTeamEmailAllowed(user *model.User) bool {
 		}
 	}
 
-	if len(utils.Cfg.TeamSettings.RestrictCreationToDomains) > 0 && !matched {
+	if len(Config().TeamSettings.RestrictCreationToDomains) > 0 && !matched {
 		return false
 	}
 
@@ -125,6 +125,43 @@ func PatchTeam(teamId string, patch *model.TeamPatch) (*model.Team, *model.AppEr
 	return updatedTeam, nil
 }
 
+// UpdateTeamName changes the name that's used in the team's URL. The old name keeps resolving to the
+// team for the grace period set by TeamSettings.NameRedirectGracePeriodDays.
+func UpdateTeamName(teamId string, name string) (*model.Team, *model.AppError) {
+	team, err := GetTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	oldName := team.Name
+	if oldName == name {
+		team.Sanitize()
+		return team, nil
+	}
+
+	team.Name = name
+	if err := team.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.Team().UpdateName(name, teamId); result.Err != nil {
+		return nil, result.Err
+	}
+
+	saveNameRedirect(model.NAME_REDIRECT_TYPE_TEAM, "", oldName, teamId)
+
+	updatedTeam, err := GetTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedTeam.Sanitize()
+
+	sendUpdatedTeamEvent(updatedTeam)
+
+	return updatedTeam, nil
+}
+
 func sendUpdatedTeamEvent(team *model.Team) {
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_UPDATE_TEAM, "", "", "", nil)
 	message.Add("team", team.ToJson())
@@ -197,7 +234,7 @@ func AddUserToTeamByTeamId(teamId string, user *model.User) *model.AppError {
 func AddUserToTeamByHash(userId string, hash string, data string) (*model.Team, *model.AppError) {
 	props := model.MapFromJson(strings.NewReader(data))
 
-	if !model.ComparePassword(hash, fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt)) {
+	if !model.ComparePassword(hash, fmt.Sprintf("%v:%v", data, Config().EmailSettings.InviteSalt)) {
 		return nil, model.NewLocAppError("JoinUserToTeamByHash", "api.user.create_user.signup_link_invalid.app_error", nil, "")
 	}
 
@@ -206,6 +243,11 @@ func AddUserToTeamByHash(userId string, hash string, data string) (*model.Team,
 		return nil, model.NewLocAppError("JoinUserToTeamByHash", "api.user.create_user.signup_link_expired.app_error", nil, "")
 	}
 
+	invitation, err := getInvitationForLink("JoinUserToTeamByHash", props)
+	if err != nil {
+		return nil, err
+	}
+
 	tchan := Srv.Store.Team().Get(props["id"])
 	uchan := Srv.Store.User().Get(userId)
 
@@ -227,6 +269,8 @@ func AddUserToTeamByHash(userId string, hash string, data string) (*model.Team,
 		return nil, err
 	}
 
+	acceptInvitation(invitation)
+
 	return team, nil
 }
 
@@ -260,10 +304,10 @@ func joinUserToTeam(team *model.Team, user *model.User) (bool, *model.AppError)
 	tm := &model.TeamMember{
 		TeamId: team.Id,
 		UserId: user.Id,
-		Roles:  model.ROLE_TEAM_USER.Id,
+		Roles:  getTeamMemberRoles(user),
 	}
 
-	if team.Email == user.Email {
+	if team.Email == user.Email && !user.IsGuest() {
 		tm.Roles = model.ROLE_TEAM_USER.Id + " " + model.ROLE_TEAM_ADMIN.Id
 	}
 
@@ -307,9 +351,16 @@ func JoinUserToTeam(team *model.Team, user *model.User, userRequestorId string)
 		channelRole = model.ROLE_CHANNEL_USER.Id + " " + model.ROLE_CHANNEL_ADMIN.Id
 	}
 
-	// Soft error if there is an issue joining the default channels
-	if err := JoinDefaultChannels(team.Id, user, channelRole, userRequestorId); err != nil {
-		l4g.Error(utils.T("api.user.create_user.joining.error"), user.Id, team.Id, err)
+	// Guests only join the channels they're invited to
+	if !user.IsGuest() {
+		// Soft error if there is an issue joining the default channels
+		if err := JoinDefaultChannels(team.Id, user, channelRole, userRequestorId); err != nil {
+			l4g.Error(utils.T("api.user.create_user.joining.error"), user.Id, team.Id, err)
+		}
+
+		if err := joinAutoAddChannels(team.Id, user); err != nil {
+			l4g.Error(utils.T("api.user.create_user.joining.error"), user.Id, team.Id, err)
+		}
 	}
 
 	ClearSessionCacheForUser(user.Id)
@@ -634,7 +685,7 @@ func InviteNewUsersToTeam(emailList []string, teamId, senderId string) *model.Ap
 		user = result.Data.(*model.User)
 	}
 
-	SendInviteEmails(team, user.GetDisplayName(), emailList, utils.GetSiteURL())
+	SendInviteEmails(team, user.GetDisplayName(), user.Id, emailList, utils.GetSiteURL())
 
 	return nil
 }
@@ -687,24 +738,36 @@ func GetTeamsUnreadForUser(excludeTeamId string, userId string) ([]*model.TeamUn
 }
 
 func PermanentDeleteTeam(team *model.Team) *model.AppError {
+	var channels *model.ChannelList
+	if result := <-Srv.Store.Channel().GetTeamChannels(team.Id); result.Err != nil {
+		return result.Err
+	} else {
+		channels = result.Data.(*model.ChannelList)
+	}
+
+	for _, c := range *channels {
+		if err := checkChannelNotHeld(c.Id); err != nil {
+			return err
+		}
+	}
+
 	team.DeleteAt = model.GetMillis()
 	if result := <-Srv.Store.Team().Update(team); result.Err != nil {
 		return result.Err
 	}
 
-	if result := <-Srv.Store.Channel().GetTeamChannels(team.Id); result.Err != nil {
-		return result.Err
-	} else {
-		channels := result.Data.(*model.ChannelList)
-		for _, c := range *channels {
-			PermanentDeleteChannel(c)
-		}
+	for _, c := range *channels {
+		PermanentDeleteChannel(c)
 	}
 
 	if result := <-Srv.Store.Team().RemoveAllMembersByTeam(team.Id); result.Err != nil {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.NameRedirect().PermanentDeleteByTarget(team.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Team().PermanentDelete(team.Id); result.Err != nil {
 		return result.Err
 	}
@@ -726,6 +789,14 @@ func SoftDeleteTeam(teamId string) *model.AppError {
 	return nil
 }
 
+func GetTeamMemberCount(teamId string) (int64, *model.AppError) {
+	if result := <-Srv.Store.Team().GetTotalMemberCount(teamId); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
 func GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
 	tchan := Srv.Store.Team().GetTotalMemberCount(teamId)
 	achan := Srv.Store.Team().GetActiveMemberCount(teamId)
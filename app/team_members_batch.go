@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	TEAM_MEMBERS_BATCH_MAX_SIZE            = 5000
+	TEAM_MEMBERS_BATCH_SYNC_MAX_SIZE       = 100
+	TEAM_MEMBERS_BATCH_JOB_UPDATE_INTERVAL = 100
+)
+
+func GetTeamMembersBatchJob(jobId string) (*model.TeamMembersBatchJob, *model.AppError) {
+	if result := <-Srv.Store.Team().GetMembersBatchJob(jobId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamMembersBatchJob), nil
+	}
+}
+
+// StartTeamMembersBatchJob applies the job's action to each of the given users. Batches of up to
+// TEAM_MEMBERS_BATCH_SYNC_MAX_SIZE users are processed before returning, larger ones are processed
+// in the background and the returned job can be used to follow their progress.
+func StartTeamMembersBatchJob(job *model.TeamMembersBatchJob, userIds []string) (*model.TeamMembersBatchJob, *model.AppError) {
+	userIds, err := prepareTeamMembersBatch(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := GetTeam(job.TeamId); err != nil {
+		return nil, err
+	}
+
+	job.Total = len(userIds)
+
+	if result := <-Srv.Store.Team().SaveMembersBatchJob(job); result.Err != nil {
+		return nil, result.Err
+	} else {
+		job = result.Data.(*model.TeamMembersBatchJob)
+	}
+
+	if len(userIds) <= TEAM_MEMBERS_BATCH_SYNC_MAX_SIZE {
+		runTeamMembersBatchJob(job, userIds)
+		return job, nil
+	}
+
+	running := *job
+	running.Failures = model.StringMap{}
+	go runTeamMembersBatchJob(&running, userIds)
+
+	return job, nil
+}
+
+func prepareTeamMembersBatch(userIds []string) ([]string, *model.AppError) {
+	unique := []string{}
+	seen := map[string]bool{}
+
+	for _, userId := range userIds {
+		if len(userId) != 26 {
+			return nil, model.NewAppError("prepareTeamMembersBatch", "api.team.members_batch.user_id.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+		}
+
+		if !seen[userId] {
+			seen[userId] = true
+			unique = append(unique, userId)
+		}
+	}
+
+	if len(unique) == 0 || len(unique) > TEAM_MEMBERS_BATCH_MAX_SIZE {
+		return nil, model.NewAppError("prepareTeamMembersBatch", "api.team.members_batch.size.app_error", map[string]interface{}{"Max": TEAM_MEMBERS_BATCH_MAX_SIZE}, "", http.StatusBadRequest)
+	}
+
+	return unique, nil
+}
+
+func runTeamMembersBatchJob(job *model.TeamMembersBatchJob, userIds []string) {
+	job.Status = model.TEAM_MEMBERS_BATCH_JOB_STATUS_RUNNING
+	updateTeamMembersBatchJob(job)
+
+	team, err := GetTeam(job.TeamId)
+	if err != nil {
+		l4g.Error(utils.T("app.team.run_members_batch_job.error"), job.Id, err)
+		job.Status = model.TEAM_MEMBERS_BATCH_JOB_STATUS_FAILED
+		job.Error = err.Error()
+		updateTeamMembersBatchJob(job)
+		return
+	}
+
+	for i, userId := range userIds {
+		if err := applyTeamMembersBatchAction(team, job, userId); err != nil {
+			job.AddFailure(userId, err.Id)
+		} else {
+			job.Succeeded++
+		}
+
+		// Report progress periodically so that large batches can be followed
+		if (i+1)%TEAM_MEMBERS_BATCH_JOB_UPDATE_INTERVAL == 0 && i+1 < len(userIds) {
+			updateTeamMembersBatchJob(job)
+		}
+	}
+
+	job.Status = model.TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED
+	updateTeamMembersBatchJob(job)
+}
+
+func updateTeamMembersBatchJob(job *model.TeamMembersBatchJob) {
+	if result := <-Srv.Store.Team().UpdateMembersBatchJob(job); result.Err != nil {
+		l4g.Error(utils.T("app.team.run_members_batch_job.update.error"), job.Id, result.Err)
+	}
+}
+
+func applyTeamMembersBatchAction(team *model.Team, job *model.TeamMembersBatchJob, userId string) *model.AppError {
+	switch job.Action {
+	case model.TEAM_MEMBERS_BATCH_ACTION_ADD:
+		user, err := GetUser(userId)
+		if err != nil {
+			return err
+		}
+
+		if err := JoinUserToTeam(team, user, job.CreatorId); err != nil {
+			return err
+		}
+
+		if len(job.Roles) > 0 {
+			if _, err := UpdateTeamMemberRoles(team.Id, userId, job.Roles); err != nil {
+				return err
+			}
+		}
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_ADDED_TO_TEAM, "", "", userId, nil)
+		message.Add("team_id", team.Id)
+		message.Add("user_id", userId)
+		Publish(message)
+	case model.TEAM_MEMBERS_BATCH_ACTION_REMOVE:
+		user, err := GetUser(userId)
+		if err != nil {
+			return err
+		}
+
+		if err := LeaveTeam(team, user); err != nil {
+			return err
+		}
+	case model.TEAM_MEMBERS_BATCH_ACTION_UPDATE_ROLES:
+		if _, err := UpdateTeamMemberRoles(team.Id, userId, job.Roles); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+)
+
+// GetTeamQuota returns the quota of a team. A team without one gets a quota that doesn't limit
+// anything.
+func GetTeamQuota(teamId string) (*model.TeamQuota, *model.AppError) {
+	if result := <-Srv.Store.TeamQuota().GetQuota(teamId); result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return &model.TeamQuota{TeamId: teamId}, nil
+		}
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamQuota), nil
+	}
+}
+
+func UpdateTeamQuota(quota *model.TeamQuota) (*model.TeamQuota, *model.AppError) {
+	if result := <-Srv.Store.TeamQuota().SaveQuota(quota); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamQuota), nil
+	}
+}
+
+// GetTeamUsage returns what a team has used so far, with the post count only covering the current
+// month.
+func GetTeamUsage(teamId string) (*model.TeamUsage, *model.AppError) {
+	ucchan := Srv.Store.TeamQuota().GetUsage(teamId)
+	icchan := Srv.Store.TeamQuota().CountIntegrations(teamId)
+
+	var usage *model.TeamUsage
+	if result := <-ucchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		usage = result.Data.(*model.TeamUsage)
+	}
+
+	if month := model.CurrentUsageMonth(); usage.Month != month {
+		usage.Month = month
+		usage.PostCount = 0
+	}
+
+	if result := <-icchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		usage.IntegrationCount = result.Data.(int64)
+	}
+
+	return usage, nil
+}
+
+func checkTeamPostQuota(teamId string) *model.AppError {
+	quota, err := GetTeamQuota(teamId)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxPostsPerMonth == 0 {
+		return nil
+	}
+
+	usage, err := GetTeamUsage(teamId)
+	if err != nil {
+		return err
+	}
+
+	if usage.PostCount >= quota.MaxPostsPerMonth {
+		return model.NewAppError("checkTeamPostQuota", "app.team_quota.posts_exceeded.app_error", map[string]interface{}{"Max": quota.MaxPostsPerMonth}, "team_id="+teamId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+func checkTeamStorageQuota(teamId string, size int64) *model.AppError {
+	quota, err := GetTeamQuota(teamId)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxStorageBytes == 0 {
+		return nil
+	}
+
+	usage, err := GetTeamUsage(teamId)
+	if err != nil {
+		return err
+	}
+
+	if usage.StorageBytes+size > quota.MaxStorageBytes {
+		return model.NewAppError("checkTeamStorageQuota", "app.team_quota.storage_exceeded.app_error", map[string]interface{}{"Max": quota.MaxStorageBytes}, "team_id="+teamId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+func checkTeamIntegrationQuota(teamId string) *model.AppError {
+	quota, err := GetTeamQuota(teamId)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxIntegrations == 0 {
+		return nil
+	}
+
+	usage, err := GetTeamUsage(teamId)
+	if err != nil {
+		return err
+	}
+
+	if usage.IntegrationCount >= quota.MaxIntegrations {
+		return model.NewAppError("checkTeamIntegrationQuota", "app.team_quota.integrations_exceeded.app_error", map[string]interface{}{"Max": quota.MaxIntegrations}, "team_id="+teamId, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+func incrementTeamPostCount(teamId string) {
+	if result := <-Srv.Store.TeamQuota().IncrementPostCount(teamId, model.CurrentUsageMonth()); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
+
+func incrementTeamStorageBytes(teamId string, bytes int64) {
+	if result := <-Srv.Store.TeamQuota().IncrementStorageBytes(teamId, bytes); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	TEAM_TEMPLATE_PAGE_SIZE = 200
+)
+
+// GetTeamTemplateContent captures the structure of a team: its settings, channels, team admins and
+// integrations. Integration secrets are left out.
+func GetTeamTemplateContent(teamId string) (*model.TeamTemplateContent, *model.AppError) {
+	team, err := GetTeam(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &model.TeamTemplateContent{
+		Type:             team.Type,
+		Description:      team.Description,
+		AllowOpenInvite:  team.AllowOpenInvite,
+		AllowedDomains:   team.AllowedDomains,
+		Channels:         []*model.TeamTemplateChannel{},
+		AdminUserIds:     model.StringArray{},
+		IncomingWebhooks: []*model.TeamTemplateIncomingWebhook{},
+		OutgoingWebhooks: []*model.TeamTemplateOutgoingWebhook{},
+		Commands:         []*model.TeamTemplateCommand{},
+	}
+
+	channelNames := make(map[string]string)
+
+	if result := <-Srv.Store.Channel().GetTeamChannels(teamId); result.Err == nil {
+		for _, channel := range *result.Data.(*model.ChannelList) {
+			if channel.DeleteAt != 0 || (channel.Type != model.CHANNEL_OPEN && channel.Type != model.CHANNEL_PRIVATE) {
+				continue
+			}
+
+			channelNames[channel.Id] = channel.Name
+			content.Channels = append(content.Channels, &model.TeamTemplateChannel{
+				Name:        channel.Name,
+				DisplayName: channel.DisplayName,
+				Type:        channel.Type,
+				Header:      channel.Header,
+				Purpose:     channel.Purpose,
+			})
+		}
+	}
+
+	for offset := 0; ; offset += TEAM_TEMPLATE_PAGE_SIZE {
+		members, err := GetTeamMembers(teamId, offset, TEAM_TEMPLATE_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if member.DeleteAt == 0 && model.IsInRole(member.Roles, model.ROLE_TEAM_ADMIN.Id) {
+				content.AdminUserIds = append(content.AdminUserIds, member.UserId)
+			}
+		}
+
+		if len(members) < TEAM_TEMPLATE_PAGE_SIZE {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += TEAM_TEMPLATE_PAGE_SIZE {
+		result := <-Srv.Store.Webhook().GetIncomingByTeam(teamId, offset, TEAM_TEMPLATE_PAGE_SIZE)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		hooks := result.Data.([]*model.IncomingWebhook)
+		for _, hook := range hooks {
+			if channelName, ok := channelNames[hook.ChannelId]; ok {
+				content.IncomingWebhooks = append(content.IncomingWebhooks, &model.TeamTemplateIncomingWebhook{
+					ChannelName: channelName,
+					DisplayName: hook.DisplayName,
+					Description: hook.Description,
+				})
+			}
+		}
+
+		if len(hooks) < TEAM_TEMPLATE_PAGE_SIZE {
+			break
+		}
+	}
+
+	if result := <-Srv.Store.Webhook().GetOutgoingByTeam(teamId, -1, -1); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, hook := range result.Data.([]*model.OutgoingWebhook) {
+			channelName := ""
+			if len(hook.ChannelId) > 0 {
+				var ok bool
+				if channelName, ok = channelNames[hook.ChannelId]; !ok {
+					continue
+				}
+			}
+
+			content.OutgoingWebhooks = append(content.OutgoingWebhooks, &model.TeamTemplateOutgoingWebhook{
+				ChannelName:  channelName,
+				TriggerWords: hook.TriggerWords,
+				TriggerWhen:  hook.TriggerWhen,
+				CallbackURLs: hook.CallbackURLs,
+				DisplayName:  hook.DisplayName,
+				Description:  hook.Description,
+				ContentType:  hook.ContentType,
+			})
+		}
+	}
+
+	if result := <-Srv.Store.Command().GetByTeam(teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, cmd := range result.Data.([]*model.Command) {
+			content.Commands = append(content.Commands, &model.TeamTemplateCommand{
+				Trigger:          cmd.Trigger,
+				Method:           cmd.Method,
+				Username:         cmd.Username,
+				IconURL:          cmd.IconURL,
+				AutoComplete:     cmd.AutoComplete,
+				AutoCompleteDesc: cmd.AutoCompleteDesc,
+				AutoCompleteHint: cmd.AutoCompleteHint,
+				DisplayName:      cmd.DisplayName,
+				Description:      cmd.Description,
+				URL:              cmd.URL,
+			})
+		}
+	}
+
+	return content, nil
+}
+
+func SaveTeamAsTemplate(teamId string, template *model.TeamTemplate) (*model.TeamTemplate, *model.AppError) {
+	content, err := GetTeamTemplateContent(teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	template.SourceTeamId = teamId
+	template.Content = *content
+
+	if result := <-Srv.Store.TeamTemplate().Save(template); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamTemplate), nil
+	}
+}
+
+func GetTeamTemplatesPage(page int, perPage int) ([]*model.TeamTemplate, *model.AppError) {
+	if result := <-Srv.Store.TeamTemplate().GetAll(page*perPage, perPage); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.TeamTemplate), nil
+	}
+}
+
+func GetTeamTemplate(templateId string) (*model.TeamTemplate, *model.AppError) {
+	if result := <-Srv.Store.TeamTemplate().Get(templateId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamTemplate), nil
+	}
+}
+
+func DeleteTeamTemplate(templateId string) *model.AppError {
+	if result := <-Srv.Store.TeamTemplate().Delete(templateId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetTeamCloneJob(jobId string) (*model.TeamCloneJob, *model.AppError) {
+	if result := <-Srv.Store.TeamTemplate().GetCloneJob(jobId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.TeamCloneJob), nil
+	}
+}
+
+// CloneTeam creates a new team and copies the structure of the source team into it in the
+// background. The returned job reports the progress of the copy.
+func CloneTeam(sourceTeamId string, team *model.Team, creatorId string) (*model.TeamCloneJob, *model.AppError) {
+	content, err := GetTeamTemplateContent(sourceTeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	return startTeamCloneJob(&model.TeamCloneJob{SourceTeamId: sourceTeamId, CreatorId: creatorId}, team, content)
+}
+
+// CreateTeamFromTemplate creates a new team and copies the template's structure into it in the
+// background. The returned job reports the progress of the copy.
+func CreateTeamFromTemplate(templateId string, team *model.Team, creatorId string) (*model.TeamCloneJob, *model.AppError) {
+	template, err := GetTeamTemplate(templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	return startTeamCloneJob(&model.TeamCloneJob{TemplateId: templateId, CreatorId: creatorId}, team, &template.Content)
+}
+
+func startTeamCloneJob(job *model.TeamCloneJob, team *model.Team, content *model.TeamTemplateContent) (*model.TeamCloneJob, *model.AppError) {
+	if len(team.Type) == 0 {
+		team.Type = content.Type
+	}
+
+	if len(team.Description) == 0 {
+		team.Description = content.Description
+	}
+
+	if len(team.AllowedDomains) == 0 {
+		team.AllowedDomains = content.AllowedDomains
+	}
+
+	team.AllowOpenInvite = content.AllowOpenInvite
+
+	// The team is created up front so that name conflicts are reported to the caller
+	rteam, err := CreateTeamWithUser(team, job.CreatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	job.TeamId = rteam.Id
+
+	if result := <-Srv.Store.TeamTemplate().SaveCloneJob(job); result.Err != nil {
+		return nil, result.Err
+	} else {
+		job = result.Data.(*model.TeamCloneJob)
+	}
+
+	running := *job
+	go runTeamCloneJob(&running, content)
+
+	return job, nil
+}
+
+func runTeamCloneJob(job *model.TeamCloneJob, content *model.TeamTemplateContent) {
+	job.Status = model.TEAM_CLONE_JOB_STATUS_RUNNING
+	if result := <-Srv.Store.TeamTemplate().UpdateCloneJob(job); result.Err != nil {
+		l4g.Error(utils.T("app.team_template.run_clone_job.update.error"), job.Id, result.Err)
+	}
+
+	if err := applyTeamTemplateContent(job.TeamId, job.CreatorId, content); err != nil {
+		l4g.Error(utils.T("app.team_template.run_clone_job.error"), job.Id, err)
+		job.Status = model.TEAM_CLONE_JOB_STATUS_FAILED
+		job.Error = err.Error()
+	} else {
+		job.Status = model.TEAM_CLONE_JOB_STATUS_FINISHED
+	}
+
+	if result := <-Srv.Store.TeamTemplate().UpdateCloneJob(job); result.Err != nil {
+		l4g.Error(utils.T("app.team_template.run_clone_job.update.error"), job.Id, result.Err)
+	}
+}
+
+func applyTeamTemplateContent(teamId string, creatorId string, content *model.TeamTemplateContent) *model.AppError {
+	channelIds := make(map[string]string)
+	channels := make(map[string]*model.Channel)
+
+	if result := <-Srv.Store.Channel().GetTeamChannels(teamId); result.Err == nil {
+		for _, channel := range *result.Data.(*model.ChannelList) {
+			channelIds[channel.Name] = channel.Id
+			channels[channel.Id] = channel
+		}
+	}
+
+	for _, templateChannel := range content.Channels {
+		if _, ok := channelIds[templateChannel.Name]; ok {
+			continue
+		}
+
+		channel, err := CreateChannel(&model.Channel{
+			TeamId:      teamId,
+			Name:        templateChannel.Name,
+			DisplayName: templateChannel.DisplayName,
+			Type:        templateChannel.Type,
+			Header:      templateChannel.Header,
+			Purpose:     templateChannel.Purpose,
+			CreatorId:   creatorId,
+		}, true)
+		if err != nil {
+			return err
+		}
+
+		channelIds[channel.Name] = channel.Id
+		channels[channel.Id] = channel
+	}
+
+	team, err := GetTeam(teamId)
+	if err != nil {
+		return err
+	}
+
+	for _, userId := range content.AdminUserIds {
+		// Admins that have since been deactivated or removed are skipped
+		user, err := GetUser(userId)
+		if err != nil || user.DeleteAt != 0 {
+			continue
+		}
+
+		if err := JoinUserToTeam(team, user, creatorId); err != nil {
+			return err
+		}
+
+		if _, err := UpdateTeamMemberRoles(teamId, userId, model.ROLE_TEAM_USER.Id+" "+model.ROLE_TEAM_ADMIN.Id); err != nil {
+			return err
+		}
+	}
+
+	if Config().ServiceSettings.EnableIncomingWebhooks {
+		for _, templateHook := range content.IncomingWebhooks {
+			channelId, ok := channelIds[templateHook.ChannelName]
+			if !ok {
+				continue
+			}
+
+			hook := &model.IncomingWebhook{
+				ChannelId:   channelId,
+				DisplayName: templateHook.DisplayName,
+				Description: templateHook.Description,
+			}
+
+			if _, err := CreateIncomingWebhookForChannel(creatorId, channels[channelId], hook); err != nil {
+				return err
+			}
+		}
+	}
+
+	if Config().ServiceSettings.EnableOutgoingWebhooks {
+		for _, templateHook := range content.OutgoingWebhooks {
+			channelId := ""
+			if len(templateHook.ChannelName) > 0 {
+				var ok bool
+				if channelId, ok = channelIds[templateHook.ChannelName]; !ok {
+					continue
+				}
+			}
+
+			hook := &model.OutgoingWebhook{
+				CreatorId:    creatorId,
+				TeamId:       teamId,
+				ChannelId:    channelId,
+				TriggerWords: templateHook.TriggerWords,
+				TriggerWhen:  templateHook.TriggerWhen,
+				CallbackURLs: templateHook.CallbackURLs,
+				DisplayName:  templateHook.DisplayName,
+				Description:  templateHook.Description,
+				ContentType:  templateHook.ContentType,
+			}
+
+			if _, err := CreateOutgoingWebhook(hook); err != nil {
+				return err
+			}
+		}
+	}
+
+	if *Config().ServiceSettings.EnableCommands {
+		for _, templateCmd := range content.Commands {
+			cmd := &model.Command{
+				CreatorId:        creatorId,
+				TeamId:           teamId,
+				Trigger:          templateCmd.Trigger,
+				Method:           templateCmd.Method,
+				Username:         templateCmd.Username,
+				IconURL:          templateCmd.IconURL,
+				AutoComplete:     templateCmd.AutoComplete,
+				AutoCompleteDesc: templateCmd.AutoCompleteDesc,
+				AutoCompleteHint: templateCmd.AutoCompleteHint,
+				DisplayName:      templateCmd.DisplayName,
+				Description:      templateCmd.Description,
+				URL:              templateCmd.URL,
+			}
+
+			if _, err := CreateCommand(cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
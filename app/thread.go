@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func getThreadRootPost(postId string) (*model.Post, *model.AppError) {
+	if result := <-Srv.Store.Post().GetSingle(postId); result.Err != nil {
+		result.Err.StatusCode = http.StatusNotFound
+		return nil, result.Err
+	} else {
+		post := result.Data.(*model.Post)
+
+		if len(post.RootId) > 0 {
+			return nil, model.NewAppError("getThreadRootPost", "app.thread.get_root_post.not_root.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+		}
+
+		return post, nil
+	}
+}
+
+func GetThreadMembership(userId, postId string) (*model.ThreadMembership, *model.AppError) {
+	if result := <-Srv.Store.Post().GetThreadMembership(postId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ThreadMembership), nil
+	}
+}
+
+// FollowThread subscribes the user to replies in the thread started by the given root post.
+func FollowThread(userId, postId string) (*model.ThreadMembership, *model.AppError) {
+	if _, err := getThreadRootPost(postId); err != nil {
+		return nil, err
+	}
+
+	membership, err := GetThreadMembership(userId, postId)
+	if err != nil {
+		if err.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+
+		membership = &model.ThreadMembership{
+			PostId:       postId,
+			UserId:       userId,
+			LastViewedAt: model.GetMillis(),
+		}
+	}
+
+	membership.Following = true
+
+	return saveThreadMembership(membership)
+}
+
+// UnfollowThread stops the user from being notified about replies in the thread unless they are
+// mentioned explicitly.
+func UnfollowThread(userId, postId string) (*model.ThreadMembership, *model.AppError) {
+	membership, err := GetThreadMembership(userId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Following = false
+	membership.MentionCount = 0
+
+	return saveThreadMembership(membership)
+}
+
+func saveThreadMembership(membership *model.ThreadMembership) (*model.ThreadMembership, *model.AppError) {
+	if result := <-Srv.Store.Post().SaveThreadMembership(membership); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ThreadMembership), nil
+	}
+}
+
+func MarkThreadAsRead(userId, postId string) *model.AppError {
+	if result := <-Srv.Store.Post().UpdateThreadLastViewedAt(postId, userId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func GetUnreadThreadsForUser(userId, teamId string) ([]*model.ThreadUnread, *model.AppError) {
+	if result := <-Srv.Store.Post().GetUnreadThreadsForUser(userId, teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ThreadUnread), nil
+	}
+}
+
+// followThreadForReply makes the author of a reply follow the thread, marking it as read up to
+// their reply, and makes sure the author of the root post follows it unless they've opted out.
+func followThreadForReply(reply *model.Post, root *model.Post) {
+	if reply.UserId != root.UserId {
+		if result := <-Srv.Store.Post().SaveThreadMembershipIfNotExists(&model.ThreadMembership{
+			PostId:       root.Id,
+			UserId:       root.UserId,
+			Following:    true,
+			LastViewedAt: root.CreateAt,
+		}); result.Err != nil {
+			l4g.Error(utils.T("app.thread.follow_thread_for_reply.error"), root.Id, root.UserId, result.Err)
+		}
+	}
+
+	if result := <-Srv.Store.Post().SaveThreadMembership(&model.ThreadMembership{
+		PostId:       root.Id,
+		UserId:       reply.UserId,
+		Following:    true,
+		LastViewedAt: reply.CreateAt,
+	}); result.Err != nil {
+		l4g.Error(utils.T("app.thread.follow_thread_for_reply.error"), root.Id, reply.UserId, result.Err)
+	}
+}
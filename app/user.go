@@ -37,7 +37,7 @@ func CreateUserWithHash(user *model.User, hash string, data string) (*model.User
 
 	props := model.MapFromJson(strings.NewReader(data))
 
-	if !model.ComparePassword(hash, fmt.Sprintf("%v:%v", data, utils.Cfg.EmailSettings.InviteSalt)) {
+	if !model.ComparePassword(hash, fmt.Sprintf("%v:%v", data, Config().EmailSettings.InviteSalt)) {
 		return nil, model.NewLocAppError("CreateUserWithHash", "api.user.create_user.signup_link_invalid.app_error", nil, "")
 	}
 
@@ -45,6 +45,11 @@ func CreateUserWithHash(user *model.User, hash string, data string) (*model.User
 		return nil, model.NewLocAppError("CreateUserWithHash", "api.user.create_user.signup_link_expired.app_error", nil, "")
 	}
 
+	invitation, err := getInvitationForLink("CreateUserWithHash", props)
+	if err != nil {
+		return nil, err
+	}
+
 	teamId := props["id"]
 
 	var team *model.Team
@@ -54,20 +59,46 @@ func CreateUserWithHash(user *model.User, hash string, data string) (*model.User
 		team = result.Data.(*model.Team)
 	}
 
+	isGuest := props["guest"] == "true"
+	if isGuest && !*Config().TeamSettings.EnableGuestAccounts {
+		return nil, model.NewAppError("CreateUserWithHash", "api.user.create_user.guest_accounts_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
 	user.Email = props["email"]
 	user.EmailVerified = true
 
 	var ruser *model.User
-	var err *model.AppError
 	if ruser, err = CreateUser(user); err != nil {
 		return nil, err
 	}
 
+	if isGuest {
+		if ruser, err = UpdateUserRoles(ruser.Id, model.ROLE_SYSTEM_GUEST.Id); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := JoinUserToTeam(team, ruser, ""); err != nil {
 		return nil, err
 	}
 
-	AddDirectChannels(team.Id, ruser)
+	if isGuest {
+		for _, channelId := range strings.Split(props["channels"], ",") {
+			if len(channelId) == 0 {
+				continue
+			}
+
+			if channel, err := GetChannel(channelId); err != nil {
+				l4g.Error(err.Error())
+			} else if _, err := AddUserToChannel(ruser, channel); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	} else {
+		AddDirectChannels(team.Id, ruser)
+	}
+
+	acceptInvitation(invitation)
 
 	return ruser, nil
 }
@@ -123,7 +154,7 @@ func CreateUserFromSignup(user *model.User) (*model.User, *model.AppError) {
 		return nil, err
 	}
 
-	if !IsFirstUserAccount() && !*utils.Cfg.TeamSettings.EnableOpenServer {
+	if !IsFirstUserAccount() && !*Config().TeamSettings.EnableOpenServer {
 		err := model.NewLocAppError("CreateUserFromSignup", "api.user.create_user.no_open_server", nil, "email="+user.Email)
 		err.StatusCode = http.StatusForbidden
 		return nil, err
@@ -144,7 +175,7 @@ func CreateUserFromSignup(user *model.User) (*model.User, *model.AppError) {
 }
 
 func IsUserSignUpAllowed() *model.AppError {
-	if !utils.Cfg.EmailSettings.EnableSignUpWithEmail || !utils.Cfg.TeamSettings.EnableUserCreation {
+	if !Config().EmailSettings.EnableSignUpWithEmail || !Config().TeamSettings.EnableUserCreation {
 		err := model.NewLocAppError("IsUserSignUpAllowed", "api.user.create_user.signup_email_disabled.app_error", nil, "")
 		err.StatusCode = http.StatusNotImplemented
 		return err
@@ -169,7 +200,7 @@ func IsFirstUserAccount() bool {
 }
 
 func CreateUser(user *model.User) (*model.User, *model.AppError) {
-	if !user.IsSSOUser() && !CheckUserDomain(user, utils.Cfg.TeamSettings.RestrictCreationToDomains) {
+	if !user.IsSSOUser() && !CheckUserDomain(user, Config().TeamSettings.RestrictCreationToDomains) {
 		return nil, model.NewLocAppError("CreateUser", "api.user.create_user.accepted_domain.app_error", nil, "")
 	}
 
@@ -186,7 +217,7 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 		}
 	}
 
-	user.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
+	user.Locale = *Config().LocalizationSettings.DefaultClientLocale
 
 	if ruser, err := createUser(user); err != nil {
 		return nil, err
@@ -231,7 +262,7 @@ func createUser(user *model.User) (*model.User, *model.AppError) {
 }
 
 func CreateOAuthUser(service string, userData io.Reader, teamId string) (*model.User, *model.AppError) {
-	if !utils.Cfg.TeamSettings.EnableUserCreation {
+	if !Config().TeamSettings.EnableUserCreation {
 		return nil, model.NewAppError("CreateOAuthUser", "api.user.create_user.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -368,12 +399,12 @@ func GetUserByAuth(authData *string, authService string) (*model.User, *model.Ap
 }
 
 func GetUserForLogin(loginId string, onlyLdap bool) (*model.User, *model.AppError) {
-	ldapAvailable := *utils.Cfg.LdapSettings.Enable && einterfaces.GetLdapInterface() != nil && utils.IsLicensed && *utils.License.Features.LDAP
+	ldapAvailable := *Config().LdapSettings.Enable && einterfaces.GetLdapInterface() != nil && utils.IsLicensed && *utils.License.Features.LDAP
 
 	if result := <-Srv.Store.User().GetForLogin(
 		loginId,
-		*utils.Cfg.EmailSettings.EnableSignInWithUsername && !onlyLdap,
-		*utils.Cfg.EmailSettings.EnableSignInWithEmail && !onlyLdap,
+		*Config().EmailSettings.EnableSignInWithUsername && !onlyLdap,
+		*Config().EmailSettings.EnableSignInWithEmail && !onlyLdap,
 		ldapAvailable,
 	); result.Err != nil && result.Err.Id == "store.sql_user.get_for_login.multiple_users" {
 		// don't fall back to LDAP in this case since we already know there's an LDAP user, but that it shouldn't work
@@ -406,6 +437,14 @@ func GetUsers(offset int, limit int) ([]*model.User, *model.AppError) {
 	}
 }
 
+func GetTotalUsersCount() (int64, *model.AppError) {
+	if result := <-Srv.Store.User().GetTotalUsersCount(); result.Err != nil {
+		return 0, result.Err
+	} else {
+		return result.Data.(int64), nil
+	}
+}
+
 func GetUsersMap(offset int, limit int, asAdmin bool) (map[string]*model.User, *model.AppError) {
 	users, err := GetUsers(offset, limit)
 	if err != nil {
@@ -673,9 +712,66 @@ func DeactivateMfa(userId string) *model.AppError {
 		return err
 	}
 
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		return result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	if result := <-Srv.Store.User().UpdateMfaBackupCodes(userId, "", user.MfaBackupCodes); result.Err != nil {
+		return result.Err
+	}
+
 	return nil
 }
 
+// RegenerateMfaBackupCodes replaces a user's backup codes with new ones. The new codes are only
+// returned by this call.
+func RegenerateMfaBackupCodes(userId string) (*model.MfaBackupCodes, *model.AppError) {
+	user, err := getUserForMfaBackupCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes := model.NewMfaBackupCodes()
+
+	if result := <-Srv.Store.User().UpdateMfaBackupCodes(userId, hashes, user.MfaBackupCodes); result.Err != nil {
+		return nil, result.Err
+	} else if !result.Data.(bool) {
+		return nil, model.NewAppError("RegenerateMfaBackupCodes", "app.user.mfa_backup_codes.conflict.app_error", nil, "user_id="+userId, http.StatusConflict)
+	}
+
+	return &model.MfaBackupCodes{Codes: codes, Remaining: len(codes)}, nil
+}
+
+// GetMfaBackupCodeCount returns how many of their backup codes a user has left.
+func GetMfaBackupCodeCount(userId string) (*model.MfaBackupCodes, *model.AppError) {
+	user, err := getUserForMfaBackupCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MfaBackupCodes{Remaining: user.MfaBackupCodeCount()}, nil
+}
+
+func getUserForMfaBackupCodes(userId string) (*model.User, *model.AppError) {
+	if !utils.IsLicensed || !*utils.License.Features.MFA || !*Config().ServiceSettings.EnableMultifactorAuthentication {
+		return nil, model.NewAppError("getUserForMfaBackupCodes", "api.user.update_mfa.not_available.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.MfaActive {
+		return nil, model.NewAppError("getUserForMfaBackupCodes", "app.user.mfa_backup_codes.mfa_inactive.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	return user, nil
+}
+
 func CreateProfileImage(username string, userId string) ([]byte, *model.AppError) {
 	colors := []color.NRGBA{
 		{197, 8, 126, 255},
@@ -712,7 +808,7 @@ func CreateProfileImage(username string, userId string) ([]byte, *model.AppError
 
 	initial := string(strings.ToUpper(username)[0])
 
-	fontBytes, err := ioutil.ReadFile(utils.FindDir("fonts") + utils.Cfg.FileSettings.InitialFont)
+	fontBytes, err := ioutil.ReadFile(utils.FindDir("fonts") + Config().FileSettings.InitialFont)
 	if err != nil {
 		return nil, model.NewLocAppError("CreateProfileImage", "api.user.create_profile_image.default_font.app_error", nil, err.Error())
 	}
@@ -721,8 +817,8 @@ func CreateProfileImage(username string, userId string) ([]byte, *model.AppError
 		return nil, model.NewLocAppError("CreateProfileImage", "api.user.create_profile_image.default_font.app_error", nil, err.Error())
 	}
 
-	width := int(utils.Cfg.FileSettings.ProfileWidth)
-	height := int(utils.Cfg.FileSettings.ProfileHeight)
+	width := int(Config().FileSettings.ProfileWidth)
+	height := int(Config().FileSettings.ProfileHeight)
 	color := colors[int64(seed)%int64(len(colors))]
 	dstImg := image.NewRGBA(image.Rect(0, 0, width, height))
 	srcImg := image.White
@@ -755,7 +851,7 @@ func GetProfileImage(user *model.User) ([]byte, bool, *model.AppError) {
 	var img []byte
 	readFailed := false
 
-	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+	if len(Config().FileSettings.DriverName) == 0 {
 		var err *model.AppError
 		if img, err = CreateProfileImage(user.Username, user.Id); err != nil {
 			return nil, false, err
@@ -808,7 +904,7 @@ func SetProfileImage(userId string, imageData *multipart.FileHeader) *model.AppE
 	}
 
 	// Scale profile image
-	img = imaging.Resize(img, utils.Cfg.FileSettings.ProfileWidth, utils.Cfg.FileSettings.ProfileHeight, imaging.Lanczos)
+	img = imaging.Resize(img, Config().FileSettings.ProfileWidth, Config().FileSettings.ProfileHeight, imaging.Lanczos)
 
 	buf := new(bytes.Buffer)
 	err = png.Encode(buf, img)
@@ -827,7 +923,7 @@ func SetProfileImage(userId string, imageData *multipart.FileHeader) *model.AppE
 	if user, err := GetUser(userId); err != nil {
 		l4g.Error(utils.T("api.user.get_me.getting.error"), userId)
 	} else {
-		options := utils.Cfg.GetSanitizeOptions()
+		options := Config().GetSanitizeOptions()
 		user.SanitizeProfile(options)
 
 		omitUsers := make(map[string]bool, 1)
@@ -912,7 +1008,7 @@ func UpdateActive(user *model.User, active bool) (*model.User, *model.AppError)
 		}
 
 		ruser := result.Data.([2]*model.User)[0]
-		options := utils.Cfg.GetSanitizeOptions()
+		options := Config().GetSanitizeOptions()
 		options["passwordupdate"] = false
 		ruser.Sanitize(options)
 
@@ -925,7 +1021,7 @@ func UpdateActive(user *model.User, active bool) (*model.User, *model.AppError)
 }
 
 func SanitizeProfile(user *model.User, asAdmin bool) {
-	options := utils.Cfg.GetSanitizeOptions()
+	options := Config().GetSanitizeOptions()
 	if asAdmin {
 		options["email"] = true
 		options["fullname"] = true
@@ -989,7 +1085,7 @@ func UpdateUser(user *model.User, sendNotifications bool) (*model.User, *model.A
 					}
 				}()
 
-				if utils.Cfg.EmailSettings.RequireEmailVerification {
+				if Config().EmailSettings.RequireEmailVerification {
 					go func() {
 						if err := SendEmailChangeVerifyEmail(rusers[0].Id, rusers[0].Email, rusers[0].Locale, utils.GetSiteURL()); err != nil {
 							l4g.Error(err.Error())
@@ -1209,6 +1305,10 @@ func UpdateUserRoles(userId string, newRoles string) (*model.User, *model.AppErr
 }
 
 func PermanentDeleteUser(user *model.User) *model.AppError {
+	if err := checkUserNotHeld(user.Id); err != nil {
+		return err
+	}
+
 	l4g.Warn(utils.T("api.user.permanent_delete_user.attempting.warn"), user.Email, user.Id)
 	if user.IsInRole(model.ROLE_SYSTEM_ADMIN.Id) {
 		l4g.Warn(utils.T("api.user.permanent_delete_user.system_admin.warn"), user.Email)
@@ -1234,6 +1334,10 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.PostEventHook().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Command().PermanentDeleteByUser(user.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1242,6 +1346,30 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-Srv.Store.Draft().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.Device().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.ChannelMemberRead().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.UserAccessToken().DeleteAllForUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.UserCredential().DeleteAllForUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-Srv.Store.Bot().PermanentDelete(user.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-Srv.Store.Channel().PermanentDeleteMembersByUser(user.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1335,6 +1463,19 @@ func SearchUsersNotInChannel(teamId string, channelId string, term string, searc
 }
 
 func SearchUsersInTeam(teamId string, term string, searchOptions map[string]bool, asAdmin bool) ([]*model.User, *model.AppError) {
+	// Fall back to searching the database if the search engine fails
+	if engine := einterfaces.GetSearchEngineInterface(); engine != nil && engine.IsSearchingEnabled() {
+		if users, err := searchUsersInTeamWithEngine(engine, teamId, term, searchOptions); err != nil {
+			l4g.Warn(utils.T("app.search_engine.search_users.warn"), err.Error())
+		} else {
+			for _, user := range users {
+				SanitizeProfile(user, asAdmin)
+			}
+
+			return users, nil
+		}
+	}
+
 	if result := <-Srv.Store.User().Search(teamId, term, searchOptions); result.Err != nil {
 		return nil, result.Err
 	} else {
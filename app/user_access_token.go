@@ -0,0 +1,163 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+)
+
+// CreateUserAccessToken creates a token that authenticates as the user. Only bots can have tokens
+// when user access tokens are disabled.
+func CreateUserAccessToken(token *model.UserAccessToken) (*model.UserAccessToken, *model.AppError) {
+	if !*Config().ServiceSettings.EnableUserAccessTokens && !IsBotUser(token.UserId) {
+		return nil, model.NewAppError("CreateUserAccessToken", "app.user_access_token.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user, err := GetUser(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeleteAt != 0 {
+		return nil, model.NewAppError("CreateUserAccessToken", "app.user_access_token.create.inactive.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	token.Id = ""
+	token.Token = model.NewId()
+
+	if result := <-Srv.Store.UserAccessToken().Save(token); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.UserAccessToken), nil
+	}
+}
+
+// createSessionForUserAccessToken creates the session for an access token the first time it's used
+// or after its previous session was revoked.
+func createSessionForUserAccessToken(tokenString string) (*model.Session, *model.AppError) {
+	var token *model.UserAccessToken
+	if result := <-Srv.Store.UserAccessToken().GetByToken(tokenString); result.Err != nil {
+		return nil, model.NewAppError("createSessionForUserAccessToken", "app.user_access_token.invalid_or_missing", nil, result.Err.Error(), http.StatusUnauthorized)
+	} else {
+		token = result.Data.(*model.UserAccessToken)
+
+		if !token.IsActive {
+			return nil, model.NewAppError("createSessionForUserAccessToken", "app.user_access_token.invalid_or_missing", nil, "inactive_token", http.StatusUnauthorized)
+		}
+	}
+
+	var user *model.User
+	if result := <-Srv.Store.User().Get(token.UserId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	if user.DeleteAt != 0 {
+		return nil, model.NewAppError("createSessionForUserAccessToken", "app.user_access_token.invalid_or_missing", nil, "inactive_user_id="+user.Id, http.StatusUnauthorized)
+	}
+
+	session := &model.Session{Token: token.Token, UserId: user.Id, Roles: user.GetRawRoles(), IsOAuth: false}
+	session.AddProp(model.SESSION_PROP_TYPE, model.SESSION_TYPE_USER_ACCESS_TOKEN)
+	session.AddProp(model.SESSION_PROP_USER_ACCESS_TOKEN_ID, token.Id)
+
+	if result := <-Srv.Store.Session().Save(session); result.Err != nil {
+		return nil, result.Err
+	} else {
+		session = result.Data.(*model.Session)
+	}
+
+	AddSessionToCache(session)
+
+	return session, nil
+}
+
+// UpdateUserAccessTokenLastUsedIfNeeded records that the access token of the session was used, at
+// most once every USER_ACCESS_TOKEN_LAST_USED_INTERVAL. Returns true if it was recorded.
+func UpdateUserAccessTokenLastUsedIfNeeded(session *model.Session) bool {
+	if !session.IsUserAccessToken() {
+		return false
+	}
+
+	// A session that was just created has yet to be recorded as used
+	now := model.GetMillis()
+	if session.LastActivityAt != session.CreateAt && now-session.LastActivityAt < model.USER_ACCESS_TOKEN_LAST_USED_INTERVAL {
+		return false
+	}
+
+	session.LastActivityAt = now
+
+	schan := Srv.Store.Session().UpdateLastActivityAt(session.Id, now)
+	tchan := Srv.Store.UserAccessToken().UpdateLastUsedAt(session.Props[model.SESSION_PROP_USER_ACCESS_TOKEN_ID], now)
+
+	if result := <-schan; result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+
+	if result := <-tchan; result.Err != nil {
+		l4g.Error(result.Err.Error())
+	}
+
+	return true
+}
+
+func GetUserAccessToken(tokenId string) (*model.UserAccessToken, *model.AppError) {
+	if result := <-Srv.Store.UserAccessToken().Get(tokenId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.UserAccessToken), nil
+	}
+}
+
+// GetUserAccessTokensForUser returns the tokens of a user without their values.
+func GetUserAccessTokensForUser(userId string) ([]*model.UserAccessToken, *model.AppError) {
+	if result := <-Srv.Store.UserAccessToken().GetByUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		tokens := result.Data.([]*model.UserAccessToken)
+
+		for _, token := range tokens {
+			token.Sanitize()
+		}
+
+		return tokens, nil
+	}
+}
+
+func RevokeUserAccessToken(token *model.UserAccessToken) *model.AppError {
+	if result := <-Srv.Store.UserAccessToken().Delete(token.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return revokeUserAccessTokenSession(token)
+}
+
+// DisableUserAccessToken stops the token from authenticating until it is enabled again.
+func DisableUserAccessToken(token *model.UserAccessToken) *model.AppError {
+	if result := <-Srv.Store.UserAccessToken().UpdateTokenDisable(token.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return revokeUserAccessTokenSession(token)
+}
+
+func EnableUserAccessToken(token *model.UserAccessToken) *model.AppError {
+	if result := <-Srv.Store.UserAccessToken().UpdateTokenEnable(token.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func revokeUserAccessTokenSession(token *model.UserAccessToken) *model.AppError {
+	// The token may have never been used or all of the user's sessions may have been revoked since
+	if result := <-Srv.Store.Session().Get(token.Token); result.Err == nil {
+		return RevokeSession(result.Data.(*model.Session))
+	}
+
+	return nil
+}
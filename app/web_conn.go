@@ -5,6 +5,7 @@ package app
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/mattermost/platform/einterfaces"
@@ -25,17 +26,26 @@ const (
 )
 
 type WebConn struct {
+	ConnectionId              string
 	WebSocket                 *websocket.Conn
 	Send                      chan model.WebSocketMessage
 	SessionToken              string
+	SessionId                 string
 	SessionExpiresAt          int64
 	Session                   *model.Session
+	OAuthScope                string
 	UserId                    string
 	T                         goi18n.TranslateFunc
 	Locale                    string
 	AllChannelMembers         map[string]string
 	LastAllChannelMembersTime int64
 	Sequence                  int64
+	resumeConnectionId        string
+	resumeLastSequence        int64
+	detachedAt                int64
+	superseded                bool
+	resumable                 bool
+	filter                    *webConnFilter
 }
 
 func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFunc, locale string) *WebConn {
@@ -44,13 +54,51 @@ func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFun
 	}
 
 	return &WebConn{
+		ConnectionId:     model.NewId(),
 		Send:             make(chan model.WebSocketMessage, 256),
 		WebSocket:        ws,
 		UserId:           session.UserId,
 		SessionToken:     session.Token,
+		SessionId:        session.Id,
 		SessionExpiresAt: session.ExpiresAt,
+		OAuthScope:       session.GetOAuthScope(),
 		T:                t,
 		Locale:           locale,
+		resumable:        true,
+		filter:           newWebConnFilter(),
+	}
+}
+
+// NewServerSentEventsConn creates a connection whose events are sent to the client as server-sent
+// events, for clients that can't open a websocket. Like NewEventStreamConn, its events are read
+// from Send once it's registered with its hub, but its user is set online and its client can
+// resume it after losing it, the same way as a websocket connection. Since server-sent events only
+// go one way, the client can't send requests over it.
+func NewServerSentEventsConn(session model.Session, t goi18n.TranslateFunc, locale string) *WebConn {
+	webCon := NewWebConn(nil, session, t, locale)
+	webCon.Session = &session
+
+	return webCon
+}
+
+// NewEventStreamConn creates a connection that isn't backed by a websocket, so that the events of
+// a session can be streamed over another protocol. It has to be registered with its hub like any
+// other connection, after which its events are read from Send. Send is closed if the reader falls
+// too far behind or the hub stops. Unlike websocket connections, it doesn't set its user online
+// and it can't be resumed once it's unregistered.
+func NewEventStreamConn(session *model.Session) *WebConn {
+	return &WebConn{
+		ConnectionId:     model.NewId(),
+		Send:             make(chan model.WebSocketMessage, 256),
+		UserId:           session.UserId,
+		SessionToken:     session.Token,
+		SessionId:        session.Id,
+		SessionExpiresAt: session.ExpiresAt,
+		Session:          session,
+		OAuthScope:       session.GetOAuthScope(),
+		T:                utils.T,
+		Locale:           *Config().LocalizationSettings.DefaultServerLocale,
+		filter:           newWebConnFilter(),
 	}
 }
 
@@ -105,16 +153,8 @@ func (c *WebConn) WritePump() {
 				return
 			}
 
-			var msgBytes []byte
-			if evt, ok := msg.(*model.WebSocketEvent); ok {
-				cpyEvt := &model.WebSocketEvent{}
-				*cpyEvt = *evt
-				cpyEvt.Sequence = c.Sequence
-				msgBytes = []byte(cpyEvt.ToJson())
-				c.Sequence++
-			} else {
-				msgBytes = []byte(msg.ToJson())
-			}
+			// Events are given their sequence number by the hub before being queued
+			msgBytes := []byte(msg.ToJson())
 
 			c.WebSocket.SetWriteDeadline(time.Now().Add(WRITE_WAIT))
 			if err := c.WebSocket.WriteMessage(websocket.TextMessage, msgBytes); err != nil {
@@ -157,6 +197,16 @@ func (c *WebConn) WritePump() {
 	}
 }
 
+// close ends the connection's writer by closing its websocket, or Send if it doesn't have one.
+// It must only be called by the connection's hub.
+func (webCon *WebConn) close() {
+	if webCon.WebSocket != nil {
+		webCon.WebSocket.Close()
+	} else {
+		close(webCon.Send)
+	}
+}
+
 func (webCon *WebConn) InvalidateCache() {
 	webCon.AllChannelMembers = nil
 	webCon.LastAllChannelMembersTime = 0
@@ -181,25 +231,74 @@ func (webCon *WebConn) IsAuthenticated() bool {
 		}
 
 		webCon.SessionToken = session.Token
+		webCon.SessionId = session.Id
 		webCon.SessionExpiresAt = session.ExpiresAt
 		webCon.Session = session
+		webCon.OAuthScope = session.GetOAuthScope()
 	}
 
 	return true
 }
 
-func (webCon *WebConn) SendHello() {
+func (webCon *WebConn) NewHelloEvent() *model.WebSocketEvent {
 	msg := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_HELLO, "", "", webCon.UserId, nil)
 	msg.Add("server_version", fmt.Sprintf("%v.%v.%v.%v", model.CurrentVersion, model.BuildNumber, utils.CfgHash, utils.IsLicensed))
-	webCon.Send <- msg
+	msg.Add(model.WEBSOCKET_PARAM_CONNECTION_ID, webCon.ConnectionId)
+	return msg
+}
+
+// RequestResume asks for the events missed since lastSequence by a previous connection of the
+// same client to be replayed once this connection is registered with its hub.
+func (webCon *WebConn) RequestResume(connectionId string, lastSequence int64) {
+	webCon.resumeConnectionId = connectionId
+	webCon.resumeLastSequence = lastSequence
+}
+
+// RestrictTo limits the connection to the events of the given channels and event types for as
+// long as it's open. It's meant for special-purpose clients, such as a dashboard that only shows
+// the posts of a few channels, and is checked before the events are serialized for the client.
+func (webCon *WebConn) RestrictTo(channelIds []string, events []string) {
+	webCon.filter.Restrict(channelIds, events)
+}
+
+// Subscribe resumes sending the events of the given channels and event types after the client
+// unsubscribed from them. Subscriptions only last as long as the connection.
+func (webCon *WebConn) Subscribe(channelIds []string, events []string) {
+	webCon.filter.Subscribe(channelIds, events)
+}
+
+// Unsubscribe stops sending the events of the given channels and event types, such as those of
+// channels that the client has muted.
+func (webCon *WebConn) Unsubscribe(channelIds []string, events []string) *model.AppError {
+	if !webCon.filter.Unsubscribe(channelIds, events) {
+		return model.NewAppError("Unsubscribe", "app.web_conn.unsubscribe.too_many.app_error", map[string]interface{}{"Max": WEBCONN_FILTER_MAX_SIZE}, "", http.StatusBadRequest)
+	}
+
+	return nil
 }
 
 func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
+	// Events destined to a specific session are sent even if the session was just revoked since
+	// they're how the client finds out about it
+	if len(msg.Broadcast.SessionId) > 0 {
+		return webCon.SessionId == msg.Broadcast.SessionId
+	}
+
 	// IMPORTANT: Do not send event if WebConn does not have a session
 	if !webCon.IsAuthenticated() {
 		return false
 	}
 
+	// if the client unsubscribed from the event's type or channel
+	if !webCon.filter.Allows(msg) {
+		return false
+	}
+
+	// OAuth tokens restricted to some channels only get the events of those channels
+	if len(model.OAuthScopeChannelIds(webCon.OAuthScope)) > 0 && !model.OAuthScopeAllowsChannel(webCon.OAuthScope, msg.Broadcast.ChannelId) {
+		return false
+	}
+
 	// If the event is destined to a specific user
 	if len(msg.Broadcast.UserId) > 0 && webCon.UserId != msg.Broadcast.UserId {
 		return false
@@ -212,6 +311,13 @@ func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
 		}
 	}
 
+	// if the user is included send the message even if they aren't in the channel or team
+	if len(msg.Broadcast.IncludeUsers) > 0 {
+		if _, ok := msg.Broadcast.IncludeUsers[webCon.UserId]; ok {
+			return true
+		}
+	}
+
 	// Only report events to users who are in the channel for the event
 	if len(msg.Broadcast.ChannelId) > 0 {
 		if model.GetMillis()-webCon.LastAllChannelMembersTime > WEBCONN_MEMBER_CACHE_TIME {
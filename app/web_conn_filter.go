@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	WEBCONN_FILTER_MAX_SIZE = 1000
+)
+
+// webConnFilter holds the channels and event types that the client of a connection has
+// unsubscribed from, along with the only channels and event types that it asked for when
+// connecting, if any. It is updated from the connection's reader and checked by its hub.
+type webConnFilter struct {
+	mutex           sync.RWMutex
+	channels        map[string]bool
+	events          map[string]bool
+	allowedChannels map[string]bool
+	allowedEvents   map[string]bool
+}
+
+func newWebConnFilter() *webConnFilter {
+	return &webConnFilter{
+		channels: make(map[string]bool),
+		events:   make(map[string]bool),
+	}
+}
+
+// ParseWebConnFilter reads the channels and event types that a client wants to be limited to
+// from the query of its websocket handshake. Both are comma separated lists that may be omitted.
+func ParseWebConnFilter(query url.Values) ([]string, []string, *model.AppError) {
+	channelIds := splitWebConnFilterParam(query.Get(model.WEBSOCKET_PARAM_CHANNEL_IDS))
+	events := splitWebConnFilterParam(query.Get(model.WEBSOCKET_PARAM_EVENTS))
+
+	if err := ValidateWebConnFilter(channelIds, events); err != nil {
+		return nil, nil, err
+	}
+
+	return channelIds, events, nil
+}
+
+// ValidateWebConnFilter checks the channels and event types that a connection is to be limited to.
+func ValidateWebConnFilter(channelIds []string, events []string) *model.AppError {
+	if len(channelIds) > WEBCONN_FILTER_MAX_SIZE {
+		return newWebConnFilterParamError(model.WEBSOCKET_PARAM_CHANNEL_IDS)
+	}
+
+	for _, channelId := range channelIds {
+		if len(channelId) != 26 {
+			return newWebConnFilterParamError(model.WEBSOCKET_PARAM_CHANNEL_IDS)
+		}
+	}
+
+	if len(events) > WEBCONN_FILTER_MAX_SIZE {
+		return newWebConnFilterParamError(model.WEBSOCKET_PARAM_EVENTS)
+	}
+
+	for _, event := range events {
+		if len(event) == 0 || event == model.WEBSOCKET_EVENT_HELLO {
+			return newWebConnFilterParamError(model.WEBSOCKET_PARAM_EVENTS)
+		}
+	}
+
+	return nil
+}
+
+func splitWebConnFilterParam(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func newWebConnFilterParamError(name string) *model.AppError {
+	return model.NewAppError("ParseWebConnFilter", "app.web_conn.filter.invalid_param.app_error", map[string]interface{}{"Name": name}, "", http.StatusBadRequest)
+}
+
+// Restrict limits the filter to the given channels and event types. Events that aren't sent to
+// a channel are only checked against the event types. An empty list leaves that part unrestricted.
+func (f *webConnFilter) Restrict(channelIds []string, events []string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.allowedChannels = nil
+	if len(channelIds) > 0 {
+		f.allowedChannels = make(map[string]bool, len(channelIds))
+		for _, channelId := range channelIds {
+			f.allowedChannels[channelId] = true
+		}
+	}
+
+	f.allowedEvents = nil
+	if len(events) > 0 {
+		f.allowedEvents = make(map[string]bool, len(events))
+		for _, event := range events {
+			f.allowedEvents[event] = true
+		}
+	}
+}
+
+func (f *webConnFilter) Subscribe(channelIds []string, events []string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, channelId := range channelIds {
+		delete(f.channels, channelId)
+	}
+
+	for _, event := range events {
+		delete(f.events, event)
+	}
+}
+
+// Unsubscribe returns false without changing the filter if it would grow past WEBCONN_FILTER_MAX_SIZE.
+func (f *webConnFilter) Unsubscribe(channelIds []string, events []string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.channels)+len(channelIds) > WEBCONN_FILTER_MAX_SIZE || len(f.events)+len(events) > WEBCONN_FILTER_MAX_SIZE {
+		return false
+	}
+
+	for _, channelId := range channelIds {
+		f.channels[channelId] = true
+	}
+
+	for _, event := range events {
+		f.events[event] = true
+	}
+
+	return true
+}
+
+func (f *webConnFilter) Allows(msg *model.WebSocketEvent) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if f.events[msg.Event] {
+		return false
+	}
+
+	if f.allowedEvents != nil && !f.allowedEvents[msg.Event] {
+		return false
+	}
+
+	if len(msg.Broadcast.ChannelId) > 0 {
+		if f.channels[msg.Broadcast.ChannelId] {
+			return false
+		}
+
+		if f.allowedChannels != nil && !f.allowedChannels[msg.Broadcast.ChannelId] {
+			return false
+		}
+	}
+
+	return true
+}
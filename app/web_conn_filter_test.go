@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestWebConnFilter(t *testing.T) {
+	filter := newWebConnFilter()
+	channelId := model.NewId()
+	otherChannelId := model.NewId()
+
+	posted := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", channelId, "", nil)
+	typing := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", otherChannelId, "", nil)
+	status := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", model.NewId(), nil)
+
+	if !filter.Allows(posted) || !filter.Allows(typing) || !filter.Allows(status) {
+		t.Fatal("should allow every event by default")
+	}
+
+	if !filter.Unsubscribe([]string{channelId}, []string{model.WEBSOCKET_EVENT_TYPING}) {
+		t.Fatal("should have unsubscribed")
+	}
+
+	if filter.Allows(posted) {
+		t.Fatal("should not allow the events of an unsubscribed channel")
+	}
+
+	if filter.Allows(typing) {
+		t.Fatal("should not allow an unsubscribed event type")
+	}
+
+	if !filter.Allows(status) {
+		t.Fatal("should allow other events")
+	}
+
+	filter.Subscribe([]string{channelId}, []string{model.WEBSOCKET_EVENT_TYPING})
+	if !filter.Allows(posted) || !filter.Allows(typing) {
+		t.Fatal("should allow the events again after subscribing")
+	}
+
+	tooMany := make([]string, WEBCONN_FILTER_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = model.NewId()
+	}
+
+	if filter.Unsubscribe(tooMany, nil) {
+		t.Fatal("should not unsubscribe from too many channels")
+	} else if len(filter.channels) != 0 {
+		t.Fatal("should not have changed the filter")
+	}
+}
+
+func TestWebConnFilterRestrict(t *testing.T) {
+	filter := newWebConnFilter()
+	channelId := model.NewId()
+	otherChannelId := model.NewId()
+
+	filter.Restrict([]string{channelId}, []string{model.WEBSOCKET_EVENT_POSTED, model.WEBSOCKET_EVENT_STATUS_CHANGE})
+
+	if !filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", channelId, "", nil)) {
+		t.Fatal("should allow a requested event type in a requested channel")
+	}
+
+	if filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", otherChannelId, "", nil)) {
+		t.Fatal("should not allow the events of other channels")
+	}
+
+	if filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", channelId, "", nil)) {
+		t.Fatal("should not allow other event types")
+	}
+
+	if !filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_STATUS_CHANGE, "", "", model.NewId(), nil)) {
+		t.Fatal("should allow a requested event type that isn't sent to a channel")
+	}
+
+	if !filter.Unsubscribe([]string{channelId}, nil) {
+		t.Fatal("should have unsubscribed")
+	} else if filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", channelId, "", nil)) {
+		t.Fatal("should not allow the events of an unsubscribed channel")
+	}
+
+	filter.Restrict(nil, []string{model.WEBSOCKET_EVENT_POSTED})
+	if !filter.Allows(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", otherChannelId, "", nil)) {
+		t.Fatal("should allow the events of every channel when none were requested")
+	}
+}
+
+func TestParseWebConnFilter(t *testing.T) {
+	channelId := model.NewId()
+	otherChannelId := model.NewId()
+
+	query := url.Values{}
+	if channelIds, events, err := ParseWebConnFilter(query); err != nil {
+		t.Fatal(err)
+	} else if channelIds != nil || events != nil {
+		t.Fatal("should not have restricted anything")
+	}
+
+	query.Set(model.WEBSOCKET_PARAM_CHANNEL_IDS, channelId+","+otherChannelId)
+	query.Set(model.WEBSOCKET_PARAM_EVENTS, model.WEBSOCKET_EVENT_POSTED)
+	if channelIds, events, err := ParseWebConnFilter(query); err != nil {
+		t.Fatal(err)
+	} else if len(channelIds) != 2 || channelIds[0] != channelId || channelIds[1] != otherChannelId {
+		t.Fatal("should have parsed the channel ids", channelIds)
+	} else if len(events) != 1 || events[0] != model.WEBSOCKET_EVENT_POSTED {
+		t.Fatal("should have parsed the events", events)
+	}
+
+	query.Set(model.WEBSOCKET_PARAM_CHANNEL_IDS, channelId+",junk")
+	if _, _, err := ParseWebConnFilter(query); err == nil {
+		t.Fatal("should have failed on an invalid channel id")
+	}
+
+	query.Set(model.WEBSOCKET_PARAM_CHANNEL_IDS, channelId)
+	query.Set(model.WEBSOCKET_PARAM_EVENTS, model.WEBSOCKET_EVENT_POSTED+",")
+	if _, _, err := ParseWebConnFilter(query); err == nil {
+		t.Fatal("should have failed on an empty event type")
+	}
+}
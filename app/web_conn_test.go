@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestWebConnShouldSendEventWithChannelScope(t *testing.T) {
+	userId := model.NewId()
+	channelId := model.NewId()
+
+	webCon := &WebConn{
+		UserId:           userId,
+		SessionExpiresAt: model.GetMillis() + 60*1000,
+		OAuthScope:       model.OAUTH_SCOPE_READ + " " + model.OAUTH_SCOPE_CHANNEL_PREFIX + channelId,
+		filter:           newWebConnFilter(),
+	}
+
+	if webCon.ShouldSendEvent(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", model.NewId(), "", nil)) {
+		t.Fatal("should not send the events of channels that weren't granted")
+	}
+
+	if webCon.ShouldSendEvent(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_CREATED, model.NewId(), "", "", nil)) {
+		t.Fatal("should not send team events")
+	}
+
+	if webCon.ShouldSendEvent(model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PREFERENCE_CHANGED, "", "", userId, nil)) {
+		t.Fatal("should not send user events that aren't about a granted channel")
+	}
+}
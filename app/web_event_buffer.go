@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	// WEBCONN_EVENT_BUFFER_SIZE must stay below the capacity of WebConn.Send so that a
+	// resumed connection can queue all of its replayed events without blocking.
+	WEBCONN_EVENT_BUFFER_SIZE   = 128
+	WEBCONN_EVENT_BUFFER_EXPIRY = 1000 * 60 * 2 // 2 minutes
+)
+
+// webEventBuffer keeps the last WEBCONN_EVENT_BUFFER_SIZE events sent on each connection of a
+// hub. It is only used from the hub's goroutine.
+type webEventBuffer struct {
+	connections map[string][]*model.WebSocketEvent
+}
+
+func newWebEventBuffer() *webEventBuffer {
+	return &webEventBuffer{
+		connections: make(map[string][]*model.WebSocketEvent),
+	}
+}
+
+func (b *webEventBuffer) Add(connectionId string, event *model.WebSocketEvent) {
+	events := b.connections[connectionId]
+
+	if len(events) == WEBCONN_EVENT_BUFFER_SIZE {
+		copy(events, events[1:])
+		events = events[:len(events)-1]
+	}
+
+	b.connections[connectionId] = append(events, event)
+}
+
+func (b *webEventBuffer) GetSince(connectionId string, sequence int64) ([]*model.WebSocketEvent, bool) {
+	events := b.connections[connectionId]
+
+	// The client must have received every event sent before the ones still buffered
+	if len(events) == 0 || events[0].Sequence > sequence+1 {
+		return nil, false
+	}
+
+	missed := []*model.WebSocketEvent{}
+	for _, event := range events {
+		if event.Sequence > sequence {
+			missed = append(missed, event)
+		}
+	}
+
+	return missed, true
+}
+
+func (b *webEventBuffer) Remove(connectionId string) {
+	delete(b.connections, connectionId)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestWebEventBuffer(t *testing.T) {
+	buffer := newWebEventBuffer()
+	connectionId := model.NewId()
+
+	if _, ok := buffer.GetSince(connectionId, 0); ok {
+		t.Fatal("should not resume an unknown connection")
+	}
+
+	for i := 0; i < 10; i++ {
+		buffer.Add(connectionId, &model.WebSocketEvent{Event: model.WEBSOCKET_EVENT_POSTED, Sequence: int64(i)})
+	}
+
+	if events, ok := buffer.GetSince(connectionId, 6); !ok {
+		t.Fatal("should have returned the missed events")
+	} else if len(events) != 3 || events[0].Sequence != 7 || events[2].Sequence != 9 {
+		t.Fatal("should have returned the events after the sequence")
+	}
+
+	if events, ok := buffer.GetSince(connectionId, 9); !ok || len(events) != 0 {
+		t.Fatal("should not have missed any event")
+	}
+
+	for i := 10; i < WEBCONN_EVENT_BUFFER_SIZE+10; i++ {
+		buffer.Add(connectionId, &model.WebSocketEvent{Event: model.WEBSOCKET_EVENT_POSTED, Sequence: int64(i)})
+	}
+
+	if _, ok := buffer.GetSince(connectionId, 8); ok {
+		t.Fatal("should not resume once missed events were dropped")
+	}
+
+	if events, ok := buffer.GetSince(connectionId, 9); !ok || len(events) != WEBCONN_EVENT_BUFFER_SIZE {
+		t.Fatal("should have kept the most recent events")
+	}
+
+	buffer.Remove(connectionId)
+	if _, ok := buffer.GetSince(connectionId, 9); ok {
+		t.Fatal("should have removed the connection")
+	}
+}
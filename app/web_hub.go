@@ -19,12 +19,14 @@ import (
 
 type Hub struct {
 	connections    []*WebConn
+	detached       []*WebConn
 	count          int64
 	register       chan *WebConn
 	unregister     chan *WebConn
 	broadcast      chan *model.WebSocketEvent
 	stop           chan string
 	invalidateUser chan string
+	eventBuffer    *webEventBuffer
 	ExplicitStop   bool
 }
 
@@ -38,6 +40,7 @@ func NewWebHub() *Hub {
 		broadcast:      make(chan *model.WebSocketEvent, 4096),
 		stop:           make(chan string),
 		invalidateUser: make(chan string),
+		eventBuffer:    newWebEventBuffer(),
 		ExplicitStop:   false,
 	}
 }
@@ -216,10 +219,6 @@ func InvalidateCacheForReactionsSkipClusterSend(postId string) {
 
 func (h *Hub) Register(webConn *WebConn) {
 	h.register <- webConn
-
-	if webConn.IsAuthenticated() {
-		webConn.SendHello()
-	}
 }
 
 func (h *Hub) Unregister(webConn *WebConn) {
@@ -240,6 +239,120 @@ func (h *Hub) Stop() {
 	h.stop <- "all"
 }
 
+func (h *Hub) getEventBuffer() einterfaces.WebSocketEventBufferInterface {
+	if buffer := einterfaces.GetWebSocketEventBufferInterface(); buffer != nil {
+		return buffer
+	}
+
+	return h.eventBuffer
+}
+
+// sequenceEvent returns a copy of the event numbered for the given connection and records it so
+// that it can be replayed if the connection is resumed.
+func (h *Hub) sequenceEvent(webCon *WebConn, msg *model.WebSocketEvent) *model.WebSocketEvent {
+	evt := &model.WebSocketEvent{}
+	*evt = *msg
+	evt.Sequence = webCon.Sequence
+	webCon.Sequence++
+
+	h.getEventBuffer().Add(webCon.ConnectionId, evt)
+
+	return evt
+}
+
+// detach keeps a lost connection around for WEBCONN_EVENT_BUFFER_EXPIRY so that its client
+// can resume it. It returns false if the connection can't be resumed.
+func (h *Hub) detach(webCon *WebConn) bool {
+	if len(webCon.UserId) == 0 || !webCon.resumable || webCon.superseded || webCon.detachedAt != 0 {
+		return false
+	}
+
+	webCon.detachedAt = model.GetMillis()
+	h.detached = append(h.detached, webCon)
+
+	return true
+}
+
+func (h *Hub) pruneDetached() {
+	now := model.GetMillis()
+
+	kept := h.detached[:0]
+	for _, webCon := range h.detached {
+		if now-webCon.detachedAt > WEBCONN_EVENT_BUFFER_EXPIRY {
+			h.getEventBuffer().Remove(webCon.ConnectionId)
+		} else {
+			kept = append(kept, webCon)
+		}
+	}
+
+	h.detached = kept
+}
+
+// resume hands the connection the client asked to resume over to the new WebConn and queues
+// the events the client missed. If that isn't possible, the new WebConn keeps its own connection
+// id, which tells the client that it has to fetch its data again.
+func (h *Hub) resume(webCon *WebConn) {
+	connectionId := webCon.resumeConnectionId
+	lastSequence := webCon.resumeLastSequence
+	webCon.resumeConnectionId = ""
+
+	previous := -1
+	for i, candidate := range h.detached {
+		if candidate.ConnectionId == connectionId && candidate.UserId == webCon.UserId {
+			previous = i
+			break
+		}
+	}
+
+	// The previous connection may still be open if the server hasn't noticed it was lost yet
+	live := -1
+	if previous == -1 {
+		for i, candidate := range h.connections {
+			if candidate.ConnectionId == connectionId && candidate.UserId == webCon.UserId && candidate.resumable {
+				live = i
+				break
+			}
+		}
+	}
+
+	var previousConn *WebConn
+	if previous != -1 {
+		previousConn = h.detached[previous]
+	} else if live != -1 {
+		previousConn = h.connections[live]
+	} else {
+		l4g.Debug(fmt.Sprintf("webhub.resume: unknown connection_id=%v for userId=%v", connectionId, webCon.UserId))
+		return
+	}
+
+	if lastSequence >= previousConn.Sequence {
+		l4g.Debug(fmt.Sprintf("webhub.resume: invalid sequence for connection_id=%v userId=%v", connectionId, webCon.UserId))
+		return
+	}
+
+	events, ok := h.getEventBuffer().GetSince(connectionId, lastSequence)
+	if !ok {
+		l4g.Debug(fmt.Sprintf("webhub.resume: missed events are no longer buffered for connection_id=%v userId=%v", connectionId, webCon.UserId))
+		return
+	}
+
+	if previous != -1 {
+		h.detached = append(h.detached[:previous], h.detached[previous+1:]...)
+	} else {
+		h.connections[live] = h.connections[len(h.connections)-1]
+		h.connections = h.connections[:len(h.connections)-1]
+		previousConn.superseded = true
+		previousConn.close()
+	}
+
+	webCon.ConnectionId = connectionId
+	webCon.Sequence = previousConn.Sequence
+
+	for _, event := range events {
+		webCon.Send <- event
+	}
+}
+
 func (h *Hub) Start() {
 	var doStart func()
 	var doRecoverableStart func()
@@ -249,9 +362,19 @@ func (h *Hub) Start() {
 		for {
 			select {
 			case webCon := <-h.register:
+				h.pruneDetached()
+
+				if len(webCon.resumeConnectionId) > 0 {
+					h.resume(webCon)
+				}
+
 				h.connections = append(h.connections, webCon)
 				atomic.StoreInt64(&h.count, int64(len(h.connections)))
 
+				if webCon.IsAuthenticated() {
+					webCon.Send <- h.sequenceEvent(webCon, webCon.NewHelloEvent())
+				}
+
 			case webCon := <-h.unregister:
 				userId := webCon.UserId
 
@@ -274,6 +397,10 @@ func (h *Hub) Start() {
 					// Delete the webcon we are unregistering
 					h.connections[indexToDel] = h.connections[len(h.connections)-1]
 					h.connections = h.connections[:len(h.connections)-1]
+
+					if !h.detach(webCon) {
+						h.getEventBuffer().Remove(webCon.ConnectionId)
+					}
 				}
 
 				if len(userId) == 0 {
@@ -291,11 +418,17 @@ func (h *Hub) Start() {
 					}
 				}
 
+				for _, webCon := range h.detached {
+					if webCon.UserId == userId {
+						webCon.InvalidateCache()
+					}
+				}
+
 			case msg := <-h.broadcast:
 				for _, webCon := range h.connections {
 					if webCon.ShouldSendEvent(msg) {
 						select {
-						case webCon.Send <- msg:
+						case webCon.Send <- h.sequenceEvent(webCon, msg):
 						default:
 							l4g.Error(fmt.Sprintf("webhub.broadcast: cannot send, closing websocket for userId=%v", webCon.UserId))
 							close(webCon.Send)
@@ -306,13 +439,25 @@ func (h *Hub) Start() {
 									break
 								}
 							}
+
+							if !h.detach(webCon) {
+								h.getEventBuffer().Remove(webCon.ConnectionId)
+							}
 						}
 					}
 				}
 
+				// Keep buffering the events of lost connections in case their clients resume them
+				h.pruneDetached()
+				for _, webCon := range h.detached {
+					if webCon.ShouldSendEvent(msg) {
+						h.sequenceEvent(webCon, msg)
+					}
+				}
+
 			case <-h.stop:
 				for _, webCon := range h.connections {
-					webCon.WebSocket.Close()
+					webCon.close()
 				}
 				h.ExplicitStop = true
 
@@ -0,0 +1,267 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	WEBAUTHN_CHALLENGE_CACHE_SIZE = 10000
+	WEBAUTHN_CHALLENGE_EXPIRY     = 300 // 5 minutes
+)
+
+// webAuthnChallengeCache holds the outstanding challenges. A registration challenge is kept for each
+// user, keyed by the ceremony and user id. Since anyone can start a login, login challenges are
+// keyed by the challenge itself so that a user can have several of them and starting a login
+// doesn't replace the challenge of another one. A challenge can only be answered once.
+var webAuthnChallengeCache *utils.Cache = utils.NewLru(WEBAUTHN_CHALLENGE_CACHE_SIZE)
+
+func IsWebAuthnEnabled() bool {
+	return utils.IsLicensed && *utils.License.Features.MFA && *Config().ServiceSettings.EnableMultifactorAuthentication && *Config().ServiceSettings.EnableWebAuthn
+}
+
+func checkWebAuthnEnabled(where string) *model.AppError {
+	if !IsWebAuthnEnabled() {
+		return model.NewAppError(where, "app.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	return nil
+}
+
+func newWebAuthnChallenge(ceremony, userId string) string {
+	challenge := model.NewWebAuthnChallenge()
+	webAuthnChallengeCache.AddWithExpiresInSecs(ceremony+":"+userId, challenge, WEBAUTHN_CHALLENGE_EXPIRY)
+	return challenge
+}
+
+func newWebAuthnLoginChallenge(userId string) string {
+	challenge := model.NewWebAuthnChallenge()
+	webAuthnChallengeCache.AddWithExpiresInSecs(model.WEBAUTHN_CEREMONY_GET+":"+challenge, userId, WEBAUTHN_CHALLENGE_EXPIRY)
+	return challenge
+}
+
+// useWebAuthnLoginChallenge forgets a login challenge and returns whether it was given to the user.
+func useWebAuthnLoginChallenge(userId, challenge string) bool {
+	key := model.WEBAUTHN_CEREMONY_GET + ":" + challenge
+
+	challengeUserId, ok := webAuthnChallengeCache.Get(key)
+	if !ok || challengeUserId.(string) != userId {
+		return false
+	}
+
+	webAuthnChallengeCache.Remove(key)
+	return true
+}
+
+// useWebAuthnChallenge returns the registration challenge that was last given to a user and
+// forgets it.
+func useWebAuthnChallenge(ceremony, userId string) (string, bool) {
+	key := ceremony + ":" + userId
+
+	challenge, ok := webAuthnChallengeCache.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	webAuthnChallengeCache.Remove(key)
+	return challenge.(string), true
+}
+
+// BeginWebAuthnRegistration returns the options a client needs to register a new security key.
+func BeginWebAuthnRegistration(userId string) (*model.WebAuthnCreationOptions, *model.AppError) {
+	if err := checkWebAuthnEnabled("BeginWebAuthnRegistration"); err != nil {
+		return nil, err
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := getUserCredentials(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rpId := model.WebAuthnOrigin(utils.GetSiteURL())
+	challenge := newWebAuthnChallenge(model.WEBAUTHN_CEREMONY_CREATE, userId)
+
+	return model.NewWebAuthnCreationOptions(challenge, rpId, Config().TeamSettings.SiteName, user, credentials), nil
+}
+
+// FinishWebAuthnRegistration checks a security key's response to the registration challenge and
+// saves the key.
+func FinishWebAuthnRegistration(userId string, attestation *model.WebAuthnAttestation) (*model.UserCredential, *model.AppError) {
+	if err := checkWebAuthnEnabled("FinishWebAuthnRegistration"); err != nil {
+		return nil, err
+	}
+
+	challenge, ok := useWebAuthnChallenge(model.WEBAUTHN_CEREMONY_CREATE, userId)
+	if !ok {
+		return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.challenge.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	origin, rpId := model.WebAuthnOrigin(utils.GetSiteURL())
+
+	credential, err := model.VerifyWebAuthnAttestation(attestation, challenge, origin, rpId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := getUserCredentials(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range credentials {
+		if existing.CredentialId == credential.CredentialId {
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.register.exists.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+		}
+	}
+
+	credential.UserId = userId
+	credential.Name = strings.TrimSpace(credential.Name)
+
+	if result := <-Srv.Store.UserCredential().Save(credential); result.Err != nil {
+		return nil, result.Err
+	} else {
+		credential = result.Data.(*model.UserCredential)
+		credential.Sanitize()
+		return credential, nil
+	}
+}
+
+// GetUserCredentials returns the security keys of a user without their public keys.
+func GetUserCredentials(userId string) ([]*model.UserCredential, *model.AppError) {
+	credentials, err := getUserCredentials(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, credential := range credentials {
+		credential.Sanitize()
+	}
+
+	return credentials, nil
+}
+
+func getUserCredentials(userId string) ([]*model.UserCredential, *model.AppError) {
+	if result := <-Srv.Store.UserCredential().GetByUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.UserCredential), nil
+	}
+}
+
+// DeleteUserCredential removes one of a user's security keys.
+func DeleteUserCredential(userId, credentialId string) *model.AppError {
+	var credential *model.UserCredential
+	if result := <-Srv.Store.UserCredential().Get(credentialId); result.Err != nil {
+		return result.Err
+	} else {
+		credential = result.Data.(*model.UserCredential)
+	}
+
+	if credential.UserId != userId {
+		return model.NewAppError("DeleteUserCredential", "app.webauthn.delete.user_id.app_error", nil, "user_id="+userId+", credential_id="+credentialId, http.StatusBadRequest)
+	}
+
+	if result := <-Srv.Store.UserCredential().Delete(credentialId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// BeginWebAuthnLogin returns the options a client needs to log in a user with one of their security
+// keys. The assertion that the key returns is then passed as the MFA token when logging in.
+func BeginWebAuthnLogin(loginId string) (*model.WebAuthnRequestOptions, *model.AppError) {
+	if err := checkWebAuthnEnabled("BeginWebAuthnLogin"); err != nil {
+		return nil, err
+	}
+
+	// Unknown users get the same error as users without security keys so that this can't be used
+	// to find out which users exist
+	user, err := GetUserForLogin(loginId, false)
+	if err != nil {
+		return nil, model.NewAppError("BeginWebAuthnLogin", "app.webauthn.login.no_credentials.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	credentials, err := getUserCredentials(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentials) == 0 {
+		return nil, model.NewAppError("BeginWebAuthnLogin", "app.webauthn.login.no_credentials.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	_, rpId := model.WebAuthnOrigin(utils.GetSiteURL())
+	challenge := newWebAuthnLoginChallenge(user.Id)
+
+	return model.NewWebAuthnRequestOptions(challenge, rpId, credentials), nil
+}
+
+// checkUserWebAuthn checks that a security key's assertion answers the login challenge given to the
+// user by one of their keys.
+func checkUserWebAuthn(user *model.User, credentials []*model.UserCredential, token string) *model.AppError {
+	assertion := model.WebAuthnAssertionFromJson(strings.NewReader(token))
+	if assertion == nil {
+		return model.NewAppError("checkUserWebAuthn", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	var credential *model.UserCredential
+	for _, c := range credentials {
+		if c.CredentialId == assertion.Id {
+			credential = c
+			break
+		}
+	}
+
+	if credential == nil {
+		return model.NewAppError("checkUserWebAuthn", "api.user.check_user_mfa.bad_code.app_error", nil, "unknown credential", http.StatusUnauthorized)
+	}
+
+	challenge := assertion.GetChallenge()
+	if !useWebAuthnLoginChallenge(user.Id, challenge) {
+		return model.NewAppError("checkUserWebAuthn", "app.webauthn.challenge.app_error", nil, "user_id="+user.Id, http.StatusUnauthorized)
+	}
+
+	origin, rpId := model.WebAuthnOrigin(utils.GetSiteURL())
+
+	signCount, err := model.VerifyWebAuthnAssertion(assertion, credential, challenge, origin, rpId)
+	if err != nil {
+		err.StatusCode = http.StatusUnauthorized
+		return err
+	}
+
+	if result := <-Srv.Store.UserCredential().UpdateSignCount(credential.Id, signCount, model.GetMillis()); result.Err != nil {
+		return result.Err
+	} else if !result.Data.(bool) {
+		// Another login used a later signature from the key at the same time
+		return model.NewAppError("checkUserWebAuthn", "model.webauthn.assertion.sign_count.app_error", nil, "credential_id="+credential.Id, http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+// HasUserCredentials returns whether a user has registered any security keys that they can log in
+// with.
+func HasUserCredentials(userId string) (bool, *model.AppError) {
+	if !IsWebAuthnEnabled() {
+		return false, nil
+	}
+
+	credentials, err := getUserCredentials(userId)
+	if err != nil {
+		return false, err
+	}
+
+	return len(credentials) > 0, nil
+}
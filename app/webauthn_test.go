@@ -0,0 +1,37 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestWebAuthnLoginChallenge(t *testing.T) {
+	userId := model.NewId()
+
+	first := newWebAuthnLoginChallenge(userId)
+	second := newWebAuthnLoginChallenge(userId)
+
+	if useWebAuthnLoginChallenge(model.NewId(), first) {
+		t.Fatal("should not accept the challenge of another user")
+	}
+
+	if !useWebAuthnLoginChallenge(userId, first) {
+		t.Fatal("starting another login should not replace the first challenge")
+	}
+
+	if useWebAuthnLoginChallenge(userId, first) {
+		t.Fatal("should not accept a challenge twice")
+	}
+
+	if !useWebAuthnLoginChallenge(userId, second) {
+		t.Fatal("should accept the second challenge")
+	}
+
+	if useWebAuthnLoginChallenge(userId, "") {
+		t.Fatal("should not accept a missing challenge")
+	}
+}
@@ -25,7 +25,7 @@ const (
 )
 
 func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Channel, user *model.User) *model.AppError {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil
 	}
 
@@ -88,7 +88,7 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 				contentType = "application/x-www-form-urlencoded"
 			}
 			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
 			}
 			client := &http.Client{Transport: tr}
 
@@ -107,7 +107,7 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 						respProps := model.MapFromJson(resp.Body)
 
 						if text, ok := respProps["text"]; ok {
-							if _, err := CreateWebhookPost(hook.CreatorId, hook.TeamId, post.ChannelId, text, respProps["username"], respProps["icon_url"], post.Props, post.Type); err != nil {
+							if _, err := CreateWebhookPost(hook.CreatorId, hook.TeamId, post.ChannelId, text, respProps["username"], respProps["icon_url"], post.Props, post.Type, model.INTEGRATION_TYPE_OUTGOING_WEBHOOK, hook.Id); err != nil {
 								l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
 							}
 						}
@@ -121,7 +121,7 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 	return nil
 }
 
-func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overrideIconUrl string, props model.StringInterface, postType string) (*model.Post, *model.AppError) {
+func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overrideIconUrl string, props model.StringInterface, postType, integrationType, integrationId string) (*model.Post, *model.AppError) {
 	// parse links into Markdown format
 	linkWithTextRegex := regexp.MustCompile(`<([^<\|]+)\|([^>]+)>`)
 	text = linkWithTextRegex.ReplaceAllString(text, "[${2}](${1})")
@@ -133,7 +133,7 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 		metrics.IncrementWebhookPost()
 	}
 
-	if utils.Cfg.ServiceSettings.EnablePostUsernameOverride {
+	if Config().ServiceSettings.EnablePostUsernameOverride {
 		if len(overrideUsername) != 0 {
 			post.AddProp("override_username", overrideUsername)
 		} else {
@@ -141,7 +141,7 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 		}
 	}
 
-	if utils.Cfg.ServiceSettings.EnablePostIconOverride {
+	if Config().ServiceSettings.EnablePostIconOverride {
 		if len(overrideIconUrl) != 0 {
 			post.AddProp("override_icon_url", overrideIconUrl)
 		}
@@ -153,12 +153,20 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 				if attachments, success := val.([]*model.SlackAttachment); success {
 					parseSlackAttachment(post, attachments)
 				}
-			} else if key != "override_icon_url" && key != "override_username" && key != "from_webhook" {
+			} else if key != "override_icon_url" && key != "override_username" && key != "from_webhook" && key != model.POST_PROP_FROM_INTEGRATION {
 				post.AddProp(key, val)
 			}
 		}
 	}
 
+	setIntegrationMetadata(post, integrationType, integrationId)
+
+	if len(teamId) > 0 {
+		if err := checkTeamPostQuota(teamId); err != nil {
+			return nil, err
+		}
+	}
+
 	if _, err := CreatePost(post, teamId, false); err != nil {
 		return nil, model.NewLocAppError("CreateWebhookPost", "api.post.create_webhook_post.creating.app_error", nil, "err="+err.Message)
 	}
@@ -167,13 +175,17 @@ func CreateWebhookPost(userId, teamId, channelId, text, overrideUsername, overri
 }
 
 func CreateIncomingWebhookForChannel(creatorId string, channel *model.Channel, hook *model.IncomingWebhook) (*model.IncomingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("CreateIncomingWebhookForChannel", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
 	hook.UserId = creatorId
 	hook.TeamId = channel.TeamId
 
+	if err := checkTeamIntegrationQuota(hook.TeamId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Webhook().SaveIncoming(hook); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -182,7 +194,7 @@ func CreateIncomingWebhookForChannel(creatorId string, channel *model.Channel, h
 }
 
 func UpdateIncomingWebhook(oldHook, updatedHook *model.IncomingWebhook) (*model.IncomingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("UpdateIncomingWebhook", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -196,12 +208,14 @@ func UpdateIncomingWebhook(oldHook, updatedHook *model.IncomingWebhook) (*model.
 	if result := <-Srv.Store.Webhook().UpdateIncoming(updatedHook); result.Err != nil {
 		return nil, result.Err
 	} else {
+		// Changes to the username pattern and the icon must apply to the next request
+		InvalidateCacheForWebhook(oldHook.Id)
 		return result.Data.(*model.IncomingWebhook), nil
 	}
 }
 
 func DeleteIncomingWebhook(hookId string) *model.AppError {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return model.NewAppError("DeleteIncomingWebhook", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -215,7 +229,7 @@ func DeleteIncomingWebhook(hookId string) *model.AppError {
 }
 
 func GetIncomingWebhook(hookId string) (*model.IncomingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("GetIncomingWebhook", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -227,7 +241,7 @@ func GetIncomingWebhook(hookId string) (*model.IncomingWebhook, *model.AppError)
 }
 
 func GetIncomingWebhooksForTeamPage(teamId string, page, perPage int) ([]*model.IncomingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("GetIncomingWebhooksForTeamPage", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -239,7 +253,7 @@ func GetIncomingWebhooksForTeamPage(teamId string, page, perPage int) ([]*model.
 }
 
 func GetIncomingWebhooksPage(page, perPage int) ([]*model.IncomingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return nil, model.NewAppError("GetIncomingWebhooksPage", "api.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -251,7 +265,7 @@ func GetIncomingWebhooksPage(page, perPage int) ([]*model.IncomingWebhook, *mode
 }
 
 func CreateOutgoingWebhook(hook *model.OutgoingWebhook) (*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("CreateOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -276,6 +290,10 @@ func CreateOutgoingWebhook(hook *model.OutgoingWebhook) (*model.OutgoingWebhook,
 		return nil, model.NewAppError("CreateOutgoingWebhook", "api.webhook.create_outgoing.triggers.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if err := checkTeamIntegrationQuota(hook.TeamId); err != nil {
+		return nil, err
+	}
+
 	if result := <-Srv.Store.Webhook().GetOutgoingByTeam(hook.TeamId, -1, -1); result.Err != nil {
 		return nil, result.Err
 	} else {
@@ -299,7 +317,7 @@ func CreateOutgoingWebhook(hook *model.OutgoingWebhook) (*model.OutgoingWebhook,
 }
 
 func UpdateOutgoingWebhook(oldHook, updatedHook *model.OutgoingWebhook) (*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("UpdateOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -350,7 +368,7 @@ func UpdateOutgoingWebhook(oldHook, updatedHook *model.OutgoingWebhook) (*model.
 }
 
 func GetOutgoingWebhook(hookId string) (*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("GetOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -362,7 +380,7 @@ func GetOutgoingWebhook(hookId string) (*model.OutgoingWebhook, *model.AppError)
 }
 
 func GetOutgoingWebhooksPage(page, perPage int) ([]*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("GetOutgoingWebhooksPage", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -374,7 +392,7 @@ func GetOutgoingWebhooksPage(page, perPage int) ([]*model.OutgoingWebhook, *mode
 }
 
 func GetOutgoingWebhooksForChannelPage(channelId string, page, perPage int) ([]*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("GetOutgoingWebhooksForChannelPage", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -386,7 +404,7 @@ func GetOutgoingWebhooksForChannelPage(channelId string, page, perPage int) ([]*
 }
 
 func GetOutgoingWebhooksForTeamPage(teamId string, page, perPage int) ([]*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("GetOutgoingWebhooksForTeamPage", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -398,7 +416,7 @@ func GetOutgoingWebhooksForTeamPage(teamId string, page, perPage int) ([]*model.
 }
 
 func DeleteOutgoingWebhook(hookId string) *model.AppError {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return model.NewAppError("DeleteOutgoingWebhook", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -410,7 +428,7 @@ func DeleteOutgoingWebhook(hookId string) *model.AppError {
 }
 
 func RegenOutgoingWebhookToken(hook *model.OutgoingWebhook) (*model.OutgoingWebhook, *model.AppError) {
-	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+	if !Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil, model.NewAppError("RegenOutgoingWebhookToken", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -424,7 +442,7 @@ func RegenOutgoingWebhookToken(hook *model.OutgoingWebhook) (*model.OutgoingWebh
 }
 
 func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *model.AppError {
-	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
+	if !Config().ServiceSettings.EnableIncomingWebhooks {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
@@ -492,7 +510,14 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 	}
 
 	overrideUsername := req.Username
+	if len(overrideUsername) != 0 && !hook.AllowsUsername(overrideUsername) {
+		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.username.app_error", nil, "username="+overrideUsername, http.StatusForbidden)
+	}
+
 	overrideIconUrl := req.IconURL
+	if len(hook.FixedIconURL) != 0 {
+		overrideIconUrl = hook.FixedIconURL
+	}
 
 	result := <-cchan
 	if result.Err != nil && result.Err.Id == store.MISSING_CHANNEL_ERROR && directUserId != "" {
@@ -514,7 +539,7 @@ func HandleIncomingWebhook(hookId string, req *model.IncomingWebhookRequest) *mo
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.permissions.app_error", nil, "", http.StatusForbidden)
 	}
 
-	if _, err := CreateWebhookPost(hook.UserId, hook.TeamId, channel.Id, text, overrideUsername, overrideIconUrl, req.Props, webhookType); err != nil {
+	if _, err := CreateWebhookPost(hook.UserId, hook.TeamId, channel.Id, text, overrideUsername, overrideIconUrl, req.Props, webhookType, model.INTEGRATION_TYPE_INCOMING_WEBHOOK, hook.Id); err != nil {
 		return err
 	}
 
@@ -56,7 +56,9 @@ func (wr *WebSocketRouter) ServeWebSocket(conn *WebConn, r *model.WebSocketReque
 			go SetStatusOnline(session.UserId, session.Id, false)
 
 			conn.SessionToken = session.Token
+			conn.SessionId = session.Id
 			conn.UserId = session.UserId
+			conn.OAuthScope = session.GetOAuthScope()
 
 			HubRegister(conn)
 
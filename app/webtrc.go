@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/mattermost/platform/model"
-	"github.com/mattermost/platform/utils"
 )
 
 func RevokeWebrtcToken(sessionId string) {
@@ -19,14 +18,14 @@ func RevokeWebrtcToken(sessionId string) {
 	data["janus"] = "remove_token"
 	data["token"] = token
 	data["transaction"] = model.NewId()
-	data["admin_secret"] = *utils.Cfg.WebrtcSettings.GatewayAdminSecret
+	data["admin_secret"] = *Config().WebrtcSettings.GatewayAdminSecret
 
-	rq, _ := http.NewRequest("POST", *utils.Cfg.WebrtcSettings.GatewayAdminUrl, strings.NewReader(model.MapToJson(data)))
+	rq, _ := http.NewRequest("POST", *Config().WebrtcSettings.GatewayAdminUrl, strings.NewReader(model.MapToJson(data)))
 	rq.Header.Set("Content-Type", "application/json")
 
 	// we do not care about the response
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *Config().ServiceSettings.EnableInsecureOutgoingConnections},
 	}
 	httpClient := &http.Client{Transport: tr}
 	httpClient.Do(rq)
@@ -0,0 +1,392 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Load generation and benchmarks",
+	Long: `Simulate load against a running server and report latency percentiles. Benchmarks log in as
+users created by the fixtures API, named [prefix]-user-[n], so the same data can be used for every run.`,
+}
+
+var benchLoginCmd = &cobra.Command{
+	Use:     "login",
+	Short:   "Benchmark logging in",
+	Example: "  bench login --url http://localhost:8065 --prefix load --users 100 --concurrency 20",
+	RunE:    benchLoginCmdF,
+}
+
+var benchChannelsCmd = &cobra.Command{
+	Use:     "channels",
+	Short:   "Benchmark fetching a user's channels",
+	Example: "  bench channels --url http://localhost:8065 --prefix load --duration 1m",
+	RunE:    benchChannelsCmdF,
+}
+
+var benchPostsCmd = &cobra.Command{
+	Use:     "posts",
+	Short:   "Benchmark creating posts",
+	Example: "  bench posts --url http://localhost:8065 --prefix load --channel load-channel-1",
+	RunE:    benchPostsCmdF,
+}
+
+var benchWebSocketCmd = &cobra.Command{
+	Use:     "websocket",
+	Short:   "Benchmark WebSocket listeners",
+	Long:    "Open a WebSocket for each worker, reporting connection latency and the events received while connected.",
+	Example: "  bench websocket --url http://localhost:8065 --prefix load --concurrency 500",
+	RunE:    benchWebSocketCmdF,
+}
+
+func init() {
+	benchCmd.PersistentFlags().String("url", "", "URL of the server to benchmark")
+	benchCmd.PersistentFlags().String("prefix", "", "Prefix the users, teams and channels were created with")
+	benchCmd.PersistentFlags().Int("users", 10, "Number of users to log in as")
+	benchCmd.PersistentFlags().String("password", "", "Password of the users")
+	benchCmd.PersistentFlags().Int("concurrency", 10, "Number of simultaneous workers")
+	benchCmd.PersistentFlags().Duration("duration", 30*time.Second, "How long to run the benchmark for")
+	benchCmd.PersistentFlags().String("team", "", "Team name to use, defaults to the first team with the prefix")
+	benchPostsCmd.Flags().String("channel", "", "Channel name to post in, defaults to the first channel with the prefix")
+
+	benchCmd.AddCommand(
+		benchLoginCmd,
+		benchChannelsCmd,
+		benchPostsCmd,
+		benchWebSocketCmd,
+	)
+}
+
+type benchOptions struct {
+	url         string
+	prefix      string
+	users       int
+	password    string
+	concurrency int
+	duration    time.Duration
+	team        string
+}
+
+// benchResults collects the latency of every request made by the workers.
+type benchResults struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+	errors    int
+	events    int
+}
+
+func (r *benchResults) record(latency time.Duration, err *model.AppError) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err != nil {
+		r.errors++
+	} else {
+		r.latencies = append(r.latencies, latency)
+	}
+}
+
+func (r *benchResults) recordEvent() {
+	r.lock.Lock()
+	r.events++
+	r.lock.Unlock()
+}
+
+// benchWorker is run by each worker with a logged in client until the deadline passes.
+type benchWorker func(client *model.Client4, user *model.User, deadline time.Time, results *benchResults)
+
+func getBenchOptions(cmd *cobra.Command) (*benchOptions, error) {
+	options := &benchOptions{}
+	options.url, _ = cmd.Flags().GetString("url")
+	options.prefix, _ = cmd.Flags().GetString("prefix")
+	options.users, _ = cmd.Flags().GetInt("users")
+	options.password, _ = cmd.Flags().GetString("password")
+	options.concurrency, _ = cmd.Flags().GetInt("concurrency")
+	options.duration, _ = cmd.Flags().GetDuration("duration")
+	options.team, _ = cmd.Flags().GetString("team")
+
+	if options.url == "" {
+		return nil, errors.New("URL is required")
+	}
+
+	if options.prefix == "" {
+		return nil, errors.New("Prefix is required")
+	}
+
+	if options.password == "" {
+		return nil, errors.New("Password is required")
+	}
+
+	if options.users <= 0 || options.concurrency <= 0 || options.duration <= 0 {
+		return nil, errors.New("Users, concurrency and duration must be greater than zero")
+	}
+
+	options.url = strings.TrimRight(options.url, "/")
+
+	if resp, err := http.Get(options.url + model.API_URL_SUFFIX + "/system/ping"); err != nil {
+		return nil, errors.New("Unable to reach " + options.url + ": " + err.Error())
+	} else {
+		resp.Body.Close()
+	}
+
+	if options.team == "" {
+		options.team = options.prefix + "-team-0"
+	}
+
+	return options, nil
+}
+
+func (o *benchOptions) username(worker int) string {
+	return fmt.Sprintf("%v-user-%v", o.prefix, worker%o.users)
+}
+
+// runBench logs each worker in as one of the users and runs the benchmark on all of them at once.
+func runBench(options *benchOptions, worker benchWorker) (*benchResults, error) {
+	clients := make([]*model.Client4, options.concurrency)
+	users := make([]*model.User, options.concurrency)
+
+	for i := range clients {
+		clients[i] = model.NewAPIv4Client(options.url)
+		if user, resp := clients[i].Login(options.username(i), options.password); resp.Error != nil {
+			return nil, errors.New("Unable to log in as " + options.username(i) + ": " + resp.Error.Error())
+		} else {
+			users[i] = user
+		}
+	}
+
+	results := &benchResults{}
+	deadline := time.Now().Add(options.duration)
+
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			worker(clients[i], users[i], deadline, results)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		client.Logout()
+	}
+
+	return results, nil
+}
+
+func timeBenchRequest(results *benchResults, request func() *model.Response) {
+	start := time.Now()
+
+	// Client4 panics on a nil response when it can't connect, which happens once the server is overloaded
+	defer func() {
+		if r := recover(); r != nil {
+			results.record(time.Since(start), model.NewLocAppError("timeBenchRequest", "model.client.connecting.app_error", nil, fmt.Sprint(r)))
+		}
+	}()
+
+	resp := request()
+	results.record(time.Since(start), resp.Error)
+}
+
+func benchPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	index := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+
+	return sorted[index]
+}
+
+func printBenchResults(name string, options *benchOptions, results *benchResults) {
+	latencies := results.latencies
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	total := len(latencies) + results.errors
+
+	CommandPrintln(fmt.Sprintf("Benchmark:   %v", name))
+	CommandPrintln(fmt.Sprintf("Workers:     %v", options.concurrency))
+	CommandPrintln(fmt.Sprintf("Duration:    %v", options.duration))
+	CommandPrintln(fmt.Sprintf("Requests:    %v", total))
+	CommandPrintln(fmt.Sprintf("Errors:      %v", results.errors))
+	CommandPrintln(fmt.Sprintf("Throughput:  %.2f/s", float64(total)/options.duration.Seconds()))
+	if results.events > 0 {
+		CommandPrintln(fmt.Sprintf("Events:      %v", results.events))
+	}
+
+	for _, percentile := range []float64{50, 90, 95, 99, 100} {
+		CommandPrintln(fmt.Sprintf("p%-3v        %v", percentile, benchPercentile(latencies, percentile)))
+	}
+}
+
+func benchLoginCmdF(cmd *cobra.Command, args []string) error {
+	options, err := getBenchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	results, err := runBench(options, func(client *model.Client4, user *model.User, deadline time.Time, results *benchResults) {
+		for time.Now().Before(deadline) {
+			timeBenchRequest(results, func() *model.Response {
+				_, resp := client.Login(user.Username, options.password)
+				return resp
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	printBenchResults("login", options, results)
+	return nil
+}
+
+func benchChannelsCmdF(cmd *cobra.Command, args []string) error {
+	options, err := getBenchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	team, err := getBenchTeam(options)
+	if err != nil {
+		return err
+	}
+
+	results, err := runBench(options, func(client *model.Client4, user *model.User, deadline time.Time, results *benchResults) {
+		for time.Now().Before(deadline) {
+			timeBenchRequest(results, func() *model.Response {
+				_, resp := client.GetChannelsForTeamForUser(team.Id, user.Id, "")
+				return resp
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	printBenchResults("channels", options, results)
+	return nil
+}
+
+func benchPostsCmdF(cmd *cobra.Command, args []string) error {
+	options, err := getBenchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	team, err := getBenchTeam(options)
+	if err != nil {
+		return err
+	}
+
+	channelName, _ := cmd.Flags().GetString("channel")
+	if channelName == "" {
+		channelName = options.prefix + "-channel-0"
+	}
+
+	client := model.NewAPIv4Client(options.url)
+	if _, resp := client.Login(options.username(0), options.password); resp.Error != nil {
+		return errors.New("Unable to log in as " + options.username(0) + ": " + resp.Error.Error())
+	}
+
+	channel, resp := client.GetChannelByName(channelName, team.Id, "")
+	client.Logout()
+	if resp.Error != nil {
+		return errors.New("Unable to find channel " + channelName + ": " + resp.Error.Error())
+	}
+
+	results, err := runBench(options, func(client *model.Client4, user *model.User, deadline time.Time, results *benchResults) {
+		for i := 0; time.Now().Before(deadline); i++ {
+			post := &model.Post{
+				ChannelId: channel.Id,
+				Message:   fmt.Sprintf("Benchmark post %v from %v", i, user.Username),
+			}
+
+			timeBenchRequest(results, func() *model.Response {
+				_, resp := client.CreatePost(post)
+				return resp
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	printBenchResults("posts", options, results)
+	return nil
+}
+
+func benchWebSocketCmdF(cmd *cobra.Command, args []string) error {
+	options, err := getBenchOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	webSocketUrl := strings.Replace(strings.Replace(options.url, "https://", "wss://", 1), "http://", "ws://", 1)
+
+	results, err := runBench(options, func(client *model.Client4, user *model.User, deadline time.Time, results *benchResults) {
+		start := time.Now()
+		webSocketClient, appErr := model.NewWebSocketClient4(webSocketUrl, client.AuthToken)
+		if appErr != nil {
+			results.record(0, appErr)
+			return
+		}
+		results.record(time.Since(start), nil)
+
+		webSocketClient.Listen()
+		timeout := time.After(deadline.Sub(time.Now()))
+
+		for {
+			select {
+			case _, ok := <-webSocketClient.EventChannel:
+				if !ok {
+					return
+				}
+				results.recordEvent()
+			case _, ok := <-webSocketClient.ResponseChannel:
+				if !ok {
+					return
+				}
+			case <-timeout:
+				webSocketClient.Close()
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	printBenchResults("websocket", options, results)
+	return nil
+}
+
+func getBenchTeam(options *benchOptions) (*model.Team, error) {
+	client := model.NewAPIv4Client(options.url)
+	if _, resp := client.Login(options.username(0), options.password); resp.Error != nil {
+		return nil, errors.New("Unable to log in as " + options.username(0) + ": " + resp.Error.Error())
+	}
+	defer client.Logout()
+
+	if team, resp := client.GetTeamByName(options.team, ""); resp.Error != nil {
+		return nil, errors.New("Unable to find team " + options.team + ": " + resp.Error.Error())
+	} else {
+		return team, nil
+	}
+}
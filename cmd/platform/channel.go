@@ -4,6 +4,7 @@ package main
 
 import (
 	"errors"
+	"os"
 
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
@@ -69,6 +70,16 @@ Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channe
 	RunE:    restoreChannelsCmdF,
 }
 
+var exportChannelCmd = &cobra.Command{
+	Use:   "export [channel] [file]",
+	Short: "Export a channel",
+	Long: `Export a channel as it was at a point in time to a zip archive, for e-discovery.
+The archive includes a manifest of its contents signed with the export signing key.
+Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
+	Example: "  channel export myteam:mychannel mychannel.zip --as_of 1496275200000",
+	RunE:    exportChannelCmdF,
+}
+
 func init() {
 	channelCreateCmd.Flags().String("name", "", "Channel Name")
 	channelCreateCmd.Flags().String("display_name", "", "Channel Display Name")
@@ -77,6 +88,8 @@ func init() {
 	channelCreateCmd.Flags().String("purpose", "", "Channel purpose")
 	channelCreateCmd.Flags().Bool("private", false, "Create a private channel.")
 
+	exportChannelCmd.Flags().Int64("as_of", 0, "Time in milliseconds to export the channel as of. Defaults to now.")
+
 	channelCmd.AddCommand(
 		channelCreateCmd,
 		removeChannelUsersCmd,
@@ -84,6 +97,7 @@ func init() {
 		deleteChannelsCmd,
 		listChannelsCmd,
 		restoreChannelsCmd,
+		exportChannelCmd,
 	)
 }
 
@@ -285,3 +299,34 @@ func restoreChannelsCmdF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func exportChannelCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if !utils.IsLicensed {
+		return errors.New(utils.T("cli.license.critical"))
+	}
+
+	if len(args) != 2 {
+		return errors.New("Enter a channel and a file to export to.")
+	}
+
+	asOf, _ := cmd.Flags().GetInt64("as_of")
+
+	channel := getChannelFromChannelArg(args[0])
+	if channel == nil {
+		return errors.New("Unable to find channel '" + args[0] + "'")
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := app.ExportChannelSnapshot(channel.Id, asOf, "", file); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"fmt"
 	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +36,8 @@ var bulkImportCmd = &cobra.Command{
 func init() {
 	bulkImportCmd.Flags().Bool("apply", false, "Save the import data to the database. Use with caution - this cannot be reverted.")
 	bulkImportCmd.Flags().Bool("validate", false, "Validate the import data without making any changes to the system.")
+	bulkImportCmd.Flags().Int64("id-seed", 0, "Generate ids from this seed so the import can be replayed with the same ids. For testing only.")
+	bulkImportCmd.Flags().String("checkpoint", "", "Record the progress of the import in this file and resume from it if it exists. Use with --apply.")
 
 	importCmd.AddCommand(
 		bulkImportCmd,
@@ -87,10 +90,25 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 		return errors.New("Validate flag error")
 	}
 
+	idSeed, err := cmd.Flags().GetInt64("id-seed")
+	if err != nil {
+		return errors.New("Id seed flag error")
+	}
+
+	checkpointPath, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		return errors.New("Checkpoint flag error")
+	}
+
 	if len(args) != 1 {
 		return errors.New("Incorrect number of arguments.")
 	}
 
+	if idSeed != 0 {
+		model.SetIdSeed(idSeed)
+		defer model.ClearIdSeed()
+	}
+
 	fileReader, err := os.Open(args[0])
 	if err != nil {
 		return err
@@ -100,6 +118,9 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 	if apply && validate {
 		CommandPrettyPrintln("Use only one of --apply or --validate.")
 		return nil
+	} else if len(checkpointPath) > 0 && !apply {
+		CommandPrettyPrintln("The --checkpoint flag can only be used with --apply.")
+		return nil
 	} else if apply && !validate {
 		CommandPrettyPrintln("Running Bulk Import. This may take a long time.")
 	} else {
@@ -110,17 +131,62 @@ func bulkImportCmdF(cmd *cobra.Command, args []string) error {
 
 	CommandPrettyPrintln("")
 
-	if err, lineNumber := app.BulkImport(fileReader, !apply); err != nil {
-		CommandPrettyPrintln(err.Error())
+	if !apply {
+		return validateBulkImportFile(fileReader)
+	}
+
+	if len(checkpointPath) > 0 {
+		checkpoint, err := app.ReadBulkImportCheckpoint(checkpointPath)
+		if err != nil {
+			return err
+		}
+
+		if checkpoint.LineNumber > 0 {
+			CommandPrettyPrintln(fmt.Sprintf("Resuming the import after data file line %v.", checkpoint.LineNumber))
+		}
+	}
+
+	var importErr *model.AppError
+	var lineNumber int
+	if len(checkpointPath) > 0 {
+		importErr, lineNumber = app.ResumableBulkImport(fileReader, checkpointPath)
+	} else {
+		importErr, lineNumber = app.BulkImport(fileReader, false)
+	}
+
+	if importErr != nil {
+		CommandPrettyPrintln(importErr.Error())
 		if lineNumber != 0 {
 			CommandPrettyPrintln(fmt.Sprintf("Error occurred on data file line %v", lineNumber))
 		}
-	} else {
-		if apply {
-			CommandPrettyPrintln("Finished Bulk Import.")
-		} else {
-			CommandPrettyPrintln("Validation complete. You can now perform the import by rerunning this command with the --apply flag.")
+
+		if len(checkpointPath) > 0 {
+			CommandPrettyPrintln("Rerun this command with the same --checkpoint flag to resume the import once the error has been fixed.")
 		}
+	} else {
+		CommandPrettyPrintln("Finished Bulk Import.")
+	}
+
+	return nil
+}
+
+func validateBulkImportFile(fileReader *os.File) error {
+	lineErrors, err := app.ValidateBulkImport(fileReader)
+
+	for _, lineError := range lineErrors {
+		CommandPrettyPrintln(fmt.Sprintf("Line %v: %v", lineError.LineNumber, lineError.Err.Error()))
+	}
+
+	if err != nil {
+		CommandPrettyPrintln(err.Error())
+		return nil
+	}
+
+	if len(lineErrors) > 0 {
+		CommandPrettyPrintln("")
+		CommandPrettyPrintln(fmt.Sprintf("Validation found %v invalid lines. Fix them and rerun the validation before importing the data.", len(lineErrors)))
+	} else {
+		CommandPrettyPrintln("Validation complete. You can now perform the import by rerunning this command with the --apply flag.")
 	}
 
 	return nil
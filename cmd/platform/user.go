@@ -270,7 +270,7 @@ func inviteUser(email string, team *model.Team, teamArg string) {
 		CommandPrintErrorln("Can't find team '" + teamArg + "'")
 		return
 	}
-	app.SendInviteEmails(team, "Administrator", invites, *utils.Cfg.ServiceSettings.SiteURL)
+	app.SendInviteEmails(team, "Administrator", "", invites, *utils.Cfg.ServiceSettings.SiteURL)
 	CommandPrettyPrintln("Invites may or may not have been sent.")
 }
 
@@ -11,6 +11,13 @@ type ClusterInterface interface {
 	StartInterNodeCommunication()
 	StopInterNodeCommunication()
 	GetClusterInfos() []*model.ClusterInfo
+	// ProbeNodes pings every other node, updating the latency and liveness reported for it by
+	// GetClusterInfos.
+	ProbeNodes()
+	// SetProtocolVersion sets the protocol version that messages to the other nodes are encoded with
+	// by ClusterMessage.ToJsonForVersion. Until it's called, messages are sent with
+	// model.CLUSTER_PROTOCOL_MIN_VERSION so that nodes running an older version can read them.
+	SetProtocolVersion(version int)
 	GetClusterStats() ([]*model.ClusterStats, *model.AppError)
 	ClearSessionCacheForUser(userId string)
 	InvalidateCacheForUser(userId string)
@@ -27,6 +34,7 @@ type ClusterInterface interface {
 	GetClusterId() string
 	ConfigChanged(previousConfig *model.Config, newConfig *model.Config, sendToOtherServer bool) *model.AppError
 	InvalidateAllCaches() *model.AppError
+	InvalidateCache(cacheName string) *model.AppError
 }
 
 var theClusterInterface ClusterInterface
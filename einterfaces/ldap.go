@@ -18,6 +18,7 @@ type LdapInterface interface {
 	SyncNow()
 	RunTest() *model.AppError
 	GetAllLdapUsers() ([]*model.User, *model.AppError)
+	GetGroupMemberIds(groupDN string) ([]string, *model.AppError)
 }
 
 var theLdapInterface LdapInterface
@@ -3,6 +3,10 @@
 
 package einterfaces
 
+import (
+	"github.com/mattermost/platform/model"
+)
+
 type MetricsInterface interface {
 	StartServer()
 	StopServer()
@@ -11,6 +15,8 @@ type MetricsInterface interface {
 	IncrementWebhookPost()
 	IncrementPostSentEmail()
 	IncrementPostSentPush()
+	IncrementPushProxySuccess(proxyUrl string)
+	IncrementPushProxyFailure(proxyUrl string)
 	IncrementPostBroadcast()
 	IncrementPostFileAttachment(count int)
 
@@ -18,6 +24,8 @@ type MetricsInterface interface {
 	IncrementHttpError()
 	ObserveHttpRequestDuration(elapsed float64)
 
+	ObserveSqlQueryDuration(operation string, elapsed float64)
+
 	IncrementClusterRequest()
 	ObserveClusterRequestDuration(elapsed float64)
 
@@ -34,8 +42,15 @@ type MetricsInterface interface {
 
 	IncrementWebsocketEvent(eventType string)
 
+	IncrementExperimentExposure(experiment string, variant string)
+
 	AddMemCacheHitCounter(cacheName string, amount float64)
 	AddMemCacheMissCounter(cacheName string, amount float64)
+
+	ObserveMemCacheStats(stats *model.CacheStats)
+
+	SetCacheWarmUpProgress(percent float64)
+	ObserveCacheWarmUpDuration(elapsed float64)
 }
 
 var theMetricsInterface MetricsInterface
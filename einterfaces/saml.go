@@ -10,7 +10,9 @@ import (
 type SamlInterface interface {
 	ConfigureSP() *model.AppError
 	BuildRequest(relayState string) (*model.SamlAuthRequest, *model.AppError)
-	DoLogin(encodedXML string, relayState map[string]string) (*model.User, *model.AppError)
+	// DoLogin returns the user that an assertion is for and the groups that the assertion lists them
+	// in under the GroupAttribute, which are used to sync their team and channel memberships.
+	DoLogin(encodedXML string, relayState map[string]string) (*model.User, []string, *model.AppError)
 	GetMetadata() (string, *model.AppError)
 }
 
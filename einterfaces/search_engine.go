@@ -0,0 +1,39 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package einterfaces
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+type SearchEngineInterface interface {
+	Start() *model.AppError
+	IsIndexingEnabled() bool
+	IsSearchingEnabled() bool
+
+	IndexPost(post *model.Post, teamId string) *model.AppError
+	BulkIndexPosts(posts []*model.PostForIndexing) *model.AppError
+	DeletePost(postId string) *model.AppError
+	SearchPosts(channelIds []string, userIds []string, params *model.SearchParams) ([]string, *model.AppError)
+
+	IndexFile(info *model.FileInfo, channelId string) *model.AppError
+	BulkIndexFiles(infos []*model.FileForIndexing) *model.AppError
+	DeleteFile(fileId string) *model.AppError
+	SearchFiles(channelIds []string, userIds []string, params *model.SearchParams) ([]string, *model.AppError)
+
+	IndexUser(user *model.User, teamIds []string) *model.AppError
+	BulkIndexUsers(users []*model.UserForIndexing) *model.AppError
+	DeleteUser(userId string) *model.AppError
+	SearchUsersInTeam(teamId string, term string, fields []string, allowInactive bool) ([]string, *model.AppError)
+}
+
+var theSearchEngineInterface SearchEngineInterface
+
+func RegisterSearchEngineInterface(newInterface SearchEngineInterface) {
+	theSearchEngineInterface = newInterface
+}
+
+func GetSearchEngineInterface() SearchEngineInterface {
+	return theSearchEngineInterface
+}
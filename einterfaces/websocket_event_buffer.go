@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package einterfaces
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// WebSocketEventBufferInterface keeps the recent events sent on each WebSocket connection so that
+// a client reconnecting shortly after losing its connection can have the events it missed
+// replayed. By default they are kept in memory by each hub; registering an implementation allows
+// them to be kept elsewhere, such as in Redis. Implementations are used by several hubs at once.
+type WebSocketEventBufferInterface interface {
+	// Add records an event sent on the given connection. Events are added in sequence order.
+	Add(connectionId string, event *model.WebSocketEvent)
+
+	// GetSince returns the events sent on the connection after the given sequence number. It
+	// returns false if some of those events are no longer buffered.
+	GetSince(connectionId string, sequence int64) ([]*model.WebSocketEvent, bool)
+
+	// Remove forgets the events of a connection that can no longer be resumed.
+	Remove(connectionId string)
+}
+
+var theWebSocketEventBufferInterface WebSocketEventBufferInterface
+
+func RegisterWebSocketEventBufferInterface(newInterface WebSocketEventBufferInterface) {
+	theWebSocketEventBufferInterface = newInterface
+}
+
+func GetWebSocketEventBufferInterface() WebSocketEventBufferInterface {
+	return theWebSocketEventBufferInterface
+}
@@ -0,0 +1,506 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	INDEX_POSTS = "posts"
+	INDEX_FILES = "files"
+	INDEX_USERS = "users"
+
+	DOCUMENT_TYPE_POST = "post"
+	DOCUMENT_TYPE_FILE = "file"
+	DOCUMENT_TYPE_USER = "user"
+
+	SEARCH_RESULTS_LIMIT = 100
+)
+
+var indexMappings = map[string]string{
+	INDEX_POSTS: `{"mappings": {"post": {"properties": {
+		"id": {"type": "keyword"},
+		"team_id": {"type": "keyword"},
+		"channel_id": {"type": "keyword"},
+		"user_id": {"type": "keyword"},
+		"type": {"type": "keyword"},
+		"create_at": {"type": "long"},
+		"message": {"type": "text"},
+		"hashtags": {"type": "keyword"}
+	}}}}`,
+	INDEX_FILES: `{"mappings": {"file": {"properties": {
+		"id": {"type": "keyword"},
+		"channel_id": {"type": "keyword"},
+		"post_id": {"type": "keyword"},
+		"user_id": {"type": "keyword"},
+		"create_at": {"type": "long"},
+		"name": {"type": "text"},
+		"extension": {"type": "keyword"},
+		"content": {"type": "text"}
+	}}}}`,
+	INDEX_USERS: `{"mappings": {"user": {"properties": {
+		"id": {"type": "keyword"},
+		"team_ids": {"type": "keyword"},
+		"delete_at": {"type": "long"},
+		"username": {"type": "text"},
+		"nickname": {"type": "text"},
+		"first_name": {"type": "text"},
+		"last_name": {"type": "text"},
+		"email": {"type": "text"}
+	}}}}`,
+}
+
+type postDocument struct {
+	Id        string   `json:"id"`
+	TeamId    string   `json:"team_id"`
+	ChannelId string   `json:"channel_id"`
+	UserId    string   `json:"user_id"`
+	Type      string   `json:"type"`
+	CreateAt  int64    `json:"create_at"`
+	Message   string   `json:"message"`
+	Hashtags  []string `json:"hashtags"`
+}
+
+type fileDocument struct {
+	Id        string `json:"id"`
+	ChannelId string `json:"channel_id"`
+	PostId    string `json:"post_id"`
+	UserId    string `json:"user_id"`
+	CreateAt  int64  `json:"create_at"`
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+	Content   string `json:"content"`
+}
+
+type userDocument struct {
+	Id        string   `json:"id"`
+	TeamIds   []string `json:"team_ids"`
+	DeleteAt  int64    `json:"delete_at"`
+	Username  string   `json:"username"`
+	Nickname  string   `json:"nickname"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Email     string   `json:"email"`
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Id string `json:"_id"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+}
+
+// ElasticsearchSearchEngine indexes and searches posts, files and users through the Elasticsearch
+// REST API. The settings are read from the config on every request so config changes apply
+// without restarting.
+type ElasticsearchSearchEngine struct {
+	settings func() *model.ElasticsearchSettings
+}
+
+func init() {
+	einterfaces.RegisterSearchEngineInterface(NewElasticsearchSearchEngine(func() *model.ElasticsearchSettings {
+		return &utils.Cfg.ElasticsearchSettings
+	}))
+}
+
+func NewElasticsearchSearchEngine(settings func() *model.ElasticsearchSettings) *ElasticsearchSearchEngine {
+	return &ElasticsearchSearchEngine{settings: settings}
+}
+
+func (es *ElasticsearchSearchEngine) IsIndexingEnabled() bool {
+	return *es.settings().EnableIndexing
+}
+
+func (es *ElasticsearchSearchEngine) IsSearchingEnabled() bool {
+	settings := es.settings()
+	return *settings.EnableIndexing && *settings.EnableSearching
+}
+
+// Start creates any of the indexes that don't exist yet.
+func (es *ElasticsearchSearchEngine) Start() *model.AppError {
+	for _, name := range []string{INDEX_POSTS, INDEX_FILES, INDEX_USERS} {
+		if status, _, err := es.request("HEAD", "/"+es.indexName(name), nil); err != nil && status != http.StatusNotFound {
+			return err
+		} else if status == http.StatusOK {
+			continue
+		}
+
+		if _, _, err := es.request("PUT", "/"+es.indexName(name), []byte(indexMappings[name])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (es *ElasticsearchSearchEngine) IndexPost(post *model.Post, teamId string) *model.AppError {
+	return es.BulkIndexPosts([]*model.PostForIndexing{{Post: *post, TeamId: teamId}})
+}
+
+// BulkIndexPosts indexes the posts and removes the deleted ones from the index.
+func (es *ElasticsearchSearchEngine) BulkIndexPosts(posts []*model.PostForIndexing) *model.AppError {
+	var body bytes.Buffer
+
+	for _, post := range posts {
+		if post.DeleteAt != 0 {
+			writeBulkAction(&body, "delete", es.indexName(INDEX_POSTS), DOCUMENT_TYPE_POST, post.Id, nil)
+			continue
+		}
+
+		hashtags := []string{}
+		for _, hashtag := range strings.Fields(post.Hashtags) {
+			hashtags = append(hashtags, strings.ToLower(hashtag))
+		}
+
+		writeBulkAction(&body, "index", es.indexName(INDEX_POSTS), DOCUMENT_TYPE_POST, post.Id, &postDocument{
+			Id:        post.Id,
+			TeamId:    post.TeamId,
+			ChannelId: post.ChannelId,
+			UserId:    post.UserId,
+			Type:      post.Type,
+			CreateAt:  post.CreateAt,
+			Message:   post.Message,
+			Hashtags:  hashtags,
+		})
+	}
+
+	return es.bulk(&body)
+}
+
+func (es *ElasticsearchSearchEngine) DeletePost(postId string) *model.AppError {
+	return es.deleteDocument(INDEX_POSTS, DOCUMENT_TYPE_POST, postId)
+}
+
+// SearchPosts returns the ids of the newest posts in the given channels that match the search
+// params, optionally limited to posts made by the given users.
+func (es *ElasticsearchSearchEngine) SearchPosts(channelIds []string, userIds []string, params *model.SearchParams) ([]string, *model.AppError) {
+	if len(channelIds) == 0 {
+		return []string{}, nil
+	}
+
+	filters := []interface{}{
+		map[string]interface{}{"terms": map[string]interface{}{"channel_id": channelIds}},
+	}
+
+	if len(userIds) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"user_id": userIds}})
+	}
+
+	createAtRange := map[string]interface{}{}
+	if afterDate := params.GetAfterDateMillis(); afterDate != 0 {
+		createAtRange["gte"] = afterDate
+	}
+	if beforeDate := params.GetBeforeDateMillis(); beforeDate != 0 {
+		createAtRange["lt"] = beforeDate
+	}
+	if len(createAtRange) > 0 {
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"create_at": createAtRange}})
+	}
+
+	must := []interface{}{}
+
+	if params.IsHashtag {
+		hashtags := []string{}
+		for _, hashtag := range strings.Fields(params.Terms) {
+			hashtags = append(hashtags, strings.ToLower(hashtag))
+		}
+
+		must = append(must, map[string]interface{}{"terms": map[string]interface{}{"hashtags": hashtags}})
+	} else if len(params.Terms) > 0 {
+		operator := "and"
+		if params.OrTerms {
+			operator = "or"
+		}
+
+		must = append(must, map[string]interface{}{
+			"simple_query_string": map[string]interface{}{
+				"query":            params.Terms,
+				"fields":           []string{"message"},
+				"default_operator": operator,
+			},
+		})
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+				"must":   must,
+				"must_not": []interface{}{
+					map[string]interface{}{"prefix": map[string]interface{}{"type": model.POST_SYSTEM_MESSAGE_PREFIX}},
+				},
+			},
+		},
+		"sort":    []interface{}{map[string]interface{}{"create_at": map[string]interface{}{"order": "desc"}}},
+		"size":    SEARCH_RESULTS_LIMIT,
+		"_source": false,
+	}
+
+	return es.search(INDEX_POSTS, query)
+}
+
+func (es *ElasticsearchSearchEngine) IndexFile(info *model.FileInfo, channelId string) *model.AppError {
+	return es.BulkIndexFiles([]*model.FileForIndexing{{FileInfo: *info, ChannelId: channelId}})
+}
+
+// BulkIndexFiles indexes the files and removes the deleted ones from the index.
+func (es *ElasticsearchSearchEngine) BulkIndexFiles(infos []*model.FileForIndexing) *model.AppError {
+	var body bytes.Buffer
+
+	for _, info := range infos {
+		if info.DeleteAt != 0 {
+			writeBulkAction(&body, "delete", es.indexName(INDEX_FILES), DOCUMENT_TYPE_FILE, info.Id, nil)
+			continue
+		}
+
+		writeBulkAction(&body, "index", es.indexName(INDEX_FILES), DOCUMENT_TYPE_FILE, info.Id, &fileDocument{
+			Id:        info.Id,
+			ChannelId: info.ChannelId,
+			PostId:    info.PostId,
+			UserId:    info.CreatorId,
+			CreateAt:  info.CreateAt,
+			Name:      info.Name,
+			Extension: info.Extension,
+			Content:   info.Content,
+		})
+	}
+
+	return es.bulk(&body)
+}
+
+func (es *ElasticsearchSearchEngine) DeleteFile(fileId string) *model.AppError {
+	return es.deleteDocument(INDEX_FILES, DOCUMENT_TYPE_FILE, fileId)
+}
+
+// SearchFiles returns the ids of the newest files in the given channels whose name or content
+// matches the search params, optionally limited to files uploaded by the given users.
+func (es *ElasticsearchSearchEngine) SearchFiles(channelIds []string, userIds []string, params *model.SearchParams) ([]string, *model.AppError) {
+	if len(channelIds) == 0 {
+		return []string{}, nil
+	}
+
+	filters := []interface{}{
+		map[string]interface{}{"terms": map[string]interface{}{"channel_id": channelIds}},
+	}
+
+	if len(userIds) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"user_id": userIds}})
+	}
+
+	must := []interface{}{}
+
+	if len(params.Terms) > 0 {
+		operator := "and"
+		if params.OrTerms {
+			operator = "or"
+		}
+
+		must = append(must, map[string]interface{}{
+			"simple_query_string": map[string]interface{}{
+				"query":            params.Terms,
+				"fields":           []string{"name", "content"},
+				"default_operator": operator,
+			},
+		})
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+				"must":   must,
+			},
+		},
+		"sort":    []interface{}{map[string]interface{}{"create_at": map[string]interface{}{"order": "desc"}}},
+		"size":    SEARCH_RESULTS_LIMIT,
+		"_source": false,
+	}
+
+	return es.search(INDEX_FILES, query)
+}
+
+func (es *ElasticsearchSearchEngine) IndexUser(user *model.User, teamIds []string) *model.AppError {
+	return es.BulkIndexUsers([]*model.UserForIndexing{{User: *user, TeamIds: teamIds}})
+}
+
+// BulkIndexUsers indexes the users. Deactivated users stay in the index so that they can still be
+// found by searches that allow inactive users.
+func (es *ElasticsearchSearchEngine) BulkIndexUsers(users []*model.UserForIndexing) *model.AppError {
+	var body bytes.Buffer
+
+	for _, user := range users {
+		teamIds := user.TeamIds
+		if teamIds == nil {
+			teamIds = []string{}
+		}
+
+		writeBulkAction(&body, "index", es.indexName(INDEX_USERS), DOCUMENT_TYPE_USER, user.Id, &userDocument{
+			Id:        user.Id,
+			TeamIds:   teamIds,
+			DeleteAt:  user.DeleteAt,
+			Username:  user.Username,
+			Nickname:  user.Nickname,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+		})
+	}
+
+	return es.bulk(&body)
+}
+
+func (es *ElasticsearchSearchEngine) DeleteUser(userId string) *model.AppError {
+	return es.deleteDocument(INDEX_USERS, DOCUMENT_TYPE_USER, userId)
+}
+
+// SearchUsersInTeam returns the ids of the users in the team with any of the given fields starting
+// with the term.
+func (es *ElasticsearchSearchEngine) SearchUsersInTeam(teamId string, term string, fields []string, allowInactive bool) ([]string, *model.AppError) {
+	filters := []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"team_ids": teamId}},
+	}
+
+	if !allowInactive {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"delete_at": 0}})
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":  term,
+						"type":   "phrase_prefix",
+						"fields": fields,
+					},
+				},
+			},
+		},
+		"size":    SEARCH_RESULTS_LIMIT,
+		"_source": false,
+	}
+
+	return es.search(INDEX_USERS, query)
+}
+
+func (es *ElasticsearchSearchEngine) indexName(name string) string {
+	return *es.settings().IndexPrefix + name
+}
+
+func (es *ElasticsearchSearchEngine) search(index string, query map[string]interface{}) ([]string, *model.AppError) {
+	body, _ := json.Marshal(query)
+
+	_, data, err := es.request("POST", "/"+es.indexName(index)+"/_search", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, model.NewAppError("ElasticsearchSearchEngine.search", "elasticsearch.search.decode.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	ids := make([]string, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.Id)
+	}
+
+	return ids, nil
+}
+
+func (es *ElasticsearchSearchEngine) deleteDocument(index string, documentType string, id string) *model.AppError {
+	if status, _, err := es.request("DELETE", "/"+es.indexName(index)+"/"+documentType+"/"+id, nil); err != nil && status != http.StatusNotFound {
+		return err
+	}
+
+	return nil
+}
+
+func writeBulkAction(body *bytes.Buffer, action string, index string, documentType string, id string, document interface{}) {
+	meta, _ := json.Marshal(map[string]interface{}{
+		action: map[string]string{"_index": index, "_type": documentType, "_id": id},
+	})
+	body.Write(meta)
+	body.WriteByte('\n')
+
+	if document != nil {
+		source, _ := json.Marshal(document)
+		body.Write(source)
+		body.WriteByte('\n')
+	}
+}
+
+func (es *ElasticsearchSearchEngine) bulk(body *bytes.Buffer) *model.AppError {
+	if body.Len() == 0 {
+		return nil
+	}
+
+	_, data, err := es.request("POST", "/_bulk", body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var response bulkResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return model.NewAppError("ElasticsearchSearchEngine.bulk", "elasticsearch.bulk.decode.app_error", nil, err.Error(), http.StatusInternalServerError)
+	} else if response.Errors {
+		return model.NewAppError("ElasticsearchSearchEngine.bulk", "elasticsearch.bulk.failed.app_error", nil, string(data), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// request sends a request to Elasticsearch, returning an error along with the status code when the
+// request doesn't succeed.
+func (es *ElasticsearchSearchEngine) request(method string, path string, body []byte) (int, []byte, *model.AppError) {
+	settings := es.settings()
+
+	req, err := http.NewRequest(method, strings.TrimRight(*settings.ConnectionUrl, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, model.NewAppError("ElasticsearchSearchEngine.request", "elasticsearch.request.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if path == "/_bulk" {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if len(*settings.Username) > 0 {
+		req.SetBasicAuth(*settings.Username, *settings.Password)
+	}
+
+	client := &http.Client{Timeout: time.Duration(*settings.RequestTimeoutSeconds) * time.Second}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, model.NewAppError("ElasticsearchSearchEngine.request", "elasticsearch.request.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer resp.Body.Close()
+
+	data, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, data, model.NewAppError("ElasticsearchSearchEngine.request", "elasticsearch.request.status.app_error", nil, method+" "+path+" status="+strconv.Itoa(resp.StatusCode)+" "+string(data), http.StatusInternalServerError)
+	}
+
+	return resp.StatusCode, data, nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+func newTestSearchEngine(handler func(w http.ResponseWriter, r *http.Request)) (*ElasticsearchSearchEngine, *[]recordedRequest, func()) {
+	requests := []recordedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{r.Method, r.URL.Path, string(body)})
+		handler(w, r)
+	}))
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	*cfg.ElasticsearchSettings.ConnectionUrl = server.URL
+	*cfg.ElasticsearchSettings.EnableIndexing = true
+	*cfg.ElasticsearchSettings.IndexPrefix = "test_"
+
+	engine := NewElasticsearchSearchEngine(func() *model.ElasticsearchSettings {
+		return &cfg.ElasticsearchSettings
+	})
+
+	return engine, &requests, server.Close
+}
+
+func TestStart(t *testing.T) {
+	engine, requests, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" && r.URL.Path == "/test_posts" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer close()
+
+	if err := engine.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	created := []string{}
+	for _, request := range *requests {
+		if request.method == "PUT" {
+			created = append(created, request.path)
+
+			if !strings.Contains(request.body, `"message"`) {
+				t.Fatal("should have created the posts index with its mapping")
+			}
+		}
+	}
+
+	if len(created) != 1 || created[0] != "/test_posts" {
+		t.Fatal("should only have created the missing index", created)
+	}
+}
+
+func TestBulkIndexPosts(t *testing.T) {
+	engine, requests, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": false}`))
+	})
+	defer close()
+
+	post := &model.PostForIndexing{TeamId: model.NewId()}
+	post.Id = model.NewId()
+	post.ChannelId = model.NewId()
+	post.Message = "hello #World"
+	post.Hashtags = "#World"
+
+	deleted := &model.PostForIndexing{}
+	deleted.Id = model.NewId()
+	deleted.DeleteAt = model.GetMillis()
+
+	if err := engine.BulkIndexPosts([]*model.PostForIndexing{post, deleted}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 1 || (*requests)[0].path != "/_bulk" {
+		t.Fatal("should have sent a single bulk request")
+	}
+
+	lines := strings.Split(strings.TrimSpace((*requests)[0].body), "\n")
+	if len(lines) != 3 {
+		t.Fatal("should have indexed one post and deleted the other", lines)
+	}
+
+	var document postDocument
+	if err := json.Unmarshal([]byte(lines[1]), &document); err != nil {
+		t.Fatal(err)
+	} else if document.Id != post.Id || document.TeamId != post.TeamId || len(document.Hashtags) != 1 || document.Hashtags[0] != "#world" {
+		t.Fatal("indexed the wrong post", lines[1])
+	}
+
+	if !strings.Contains(lines[2], `"delete"`) || !strings.Contains(lines[2], deleted.Id) {
+		t.Fatal("should have deleted the post", lines[2])
+	}
+
+	if err := engine.BulkIndexPosts([]*model.PostForIndexing{}); err != nil || len(*requests) != 1 {
+		t.Fatal("shouldn't send an empty bulk request")
+	}
+}
+
+func TestSearchPosts(t *testing.T) {
+	engine, requests, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"hits": [{"_id": "post1"}, {"_id": "post2"}]}}`))
+	})
+	defer close()
+
+	channelId := model.NewId()
+	ids, err := engine.SearchPosts([]string{channelId}, nil, &model.SearchParams{Terms: "hello world", OrTerms: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 2 || ids[0] != "post1" || ids[1] != "post2" {
+		t.Fatal("returned the wrong posts", ids)
+	}
+
+	request := (*requests)[0]
+	if request.path != "/test_posts/_search" {
+		t.Fatal("searched the wrong index", request.path)
+	} else if !strings.Contains(request.body, channelId) || !strings.Contains(request.body, `"default_operator":"or"`) {
+		t.Fatal("sent the wrong query", request.body)
+	}
+
+	if ids, err := engine.SearchPosts([]string{}, nil, &model.SearchParams{Terms: "hello"}); err != nil || len(ids) != 0 || len(*requests) != 1 {
+		t.Fatal("shouldn't search without any channels")
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	engine, _, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer close()
+
+	if _, err := engine.SearchUsersInTeam(model.NewId(), "user", []string{"username"}, false); err == nil {
+		t.Fatal("should have failed")
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	engine, requests, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"hits": [{"_id": "file1"}]}}`))
+	})
+	defer close()
+
+	channelId := model.NewId()
+	ids, err := engine.SearchFiles([]string{channelId}, nil, &model.SearchParams{Terms: "quarterly report"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 1 || ids[0] != "file1" {
+		t.Fatal("returned the wrong files", ids)
+	}
+
+	request := (*requests)[0]
+	if request.path != "/test_files/_search" {
+		t.Fatal("searched the wrong index", request.path)
+	} else if !strings.Contains(request.body, channelId) || !strings.Contains(request.body, `"content"`) || !strings.Contains(request.body, `"default_operator":"and"`) {
+		t.Fatal("sent the wrong query", request.body)
+	}
+}
+
+func TestSearchPostsDateRange(t *testing.T) {
+	engine, requests, close := newTestSearchEngine(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hits": {"hits": []}}`))
+	})
+	defer close()
+
+	if _, err := engine.SearchPosts([]string{model.NewId()}, nil, &model.SearchParams{Terms: "hello", AfterDate: "2017-05-01", BeforeDate: "2017-05-03"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := (*requests)[0].body; !strings.Contains(body, `"range":{"create_at":{"gte":1493683200000,"lt":1493769600000}}`) {
+		t.Fatal("should have filtered by the dates", body)
+	}
+}
@@ -0,0 +1,704 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+const (
+	STRING  = "String"
+	INT     = "Int"
+	FLOAT   = "Float"
+	BOOLEAN = "Boolean"
+	ID      = "ID"
+
+	MAX_SELECTION_DEPTH = 10
+)
+
+// Schema describes the data that can be queried. Only queries are supported, so it only has
+// a root query type.
+type Schema struct {
+	Query *Object
+}
+
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object. Type is nil for scalar fields and List is set for fields
+// that resolve to a list of values.
+type Field struct {
+	Type      *Object
+	List      bool
+	Arguments map[string]*ArgumentDefinition
+	Resolve   ResolveFunc
+}
+
+type ArgumentDefinition struct {
+	Type         string
+	List         bool
+	Required     bool
+	DefaultValue interface{}
+}
+
+type ResolveParams struct {
+	Context   interface{}
+	Sources   []interface{}
+	Arguments map[string]interface{}
+}
+
+// ResolveFunc resolves a field for every object that it was selected on at once, so that the
+// values can be loaded with a single query, and returns the value of each source in order.
+// A value that is an error is reported and resolved to null, while returning an error
+// resolves the field to null for every source. A list field resolves to []interface{}.
+type ResolveFunc func(p *ResolveParams) ([]interface{}, error)
+
+// ResolveEach builds a ResolveFunc out of one that resolves the field for a single source.
+func ResolveEach(resolve func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error)) ResolveFunc {
+	return func(p *ResolveParams) ([]interface{}, error) {
+		values := make([]interface{}, len(p.Sources))
+		for i, source := range p.Sources {
+			if value, err := resolve(p.Context, source, p.Arguments); err != nil {
+				values[i] = err
+			} else {
+				values[i] = value
+			}
+		}
+
+		return values, nil
+	}
+}
+
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+func (r *Response) ToJson() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// Request is the body of a GraphQL request sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func RequestFromJson(data io.Reader) *Request {
+	decoder := json.NewDecoder(data)
+	var r Request
+	err := decoder.Decode(&r)
+	if err == nil {
+		return &r
+	} else {
+		return nil
+	}
+}
+
+// orderedMap is a response object, which keeps its fields in the order that they were selected.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+func (m *orderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type executor struct {
+	schema    *Schema
+	document  *Document
+	variables map[string]interface{}
+	context   interface{}
+	errors    []*Error
+}
+
+// collectedField is a field of the response along with every selection that makes it up, since
+// a field can be selected more than once through fragments.
+type collectedField struct {
+	key        string
+	selections []*Selection
+}
+
+// Execute runs a query against the schema. Errors that make the whole request invalid, such as
+// syntax errors or unknown fields, are returned without data. Errors that happen while
+// resolving a field are returned along with the rest of the data.
+func Execute(schema *Schema, query string, operationName string, variables map[string]interface{}, context interface{}) *Response {
+	document, err := Parse(query)
+	if err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	e := &executor{
+		schema:   schema,
+		document: document,
+		context:  context,
+	}
+
+	operation, err := e.getOperation(operationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	if e.variables, err = e.coerceVariables(operation, variables); err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	// The fields of the query type are resolved with a single source that has no value
+	results, err := e.executeSelections(schema.Query, []interface{}{struct{}{}}, operation.Selections, nil, 1)
+	if err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	return &Response{Data: results[0], Errors: e.errors}
+}
+
+func (e *executor) getOperation(name string) (*Operation, *Error) {
+	var operation *Operation
+
+	if len(name) == 0 {
+		if len(e.document.Operations) > 1 {
+			return nil, &Error{Message: "An operation name is required when the document contains more than one operation"}
+		}
+
+		operation = e.document.Operations[0]
+	} else {
+		for _, candidate := range e.document.Operations {
+			if candidate.Name == name {
+				operation = candidate
+				break
+			}
+		}
+
+		if operation == nil {
+			return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q", name)}
+		}
+	}
+
+	if operation.Type != OPERATION_QUERY {
+		return nil, &Error{Message: fmt.Sprintf("Only queries are supported, not %vs", operation.Type)}
+	}
+
+	return operation, nil
+}
+
+func (e *executor) coerceVariables(operation *Operation, values map[string]interface{}) (map[string]interface{}, *Error) {
+	variables := make(map[string]interface{}, len(operation.Variables))
+
+	for _, definition := range operation.Variables {
+		if _, ok := variables[definition.Name]; ok {
+			return nil, &Error{Message: fmt.Sprintf("There can be only one variable named $%v", definition.Name)}
+		}
+
+		value, ok := values[definition.Name]
+		if !ok && definition.DefaultValue != nil {
+			var err *Error
+			if value, err = e.resolveValue(definition.DefaultValue); err != nil {
+				return nil, err
+			}
+		}
+
+		coerced, err := coerceValue(definition.Type, value)
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Variable $%v of type %v %v", definition.Name, definition.Type, err.Error())}
+		}
+
+		variables[definition.Name] = coerced
+	}
+
+	return variables, nil
+}
+
+func (e *executor) executeSelections(object *Object, sources []interface{}, selections []*Selection, path []interface{}, depth int) ([]*orderedMap, *Error) {
+	if depth > MAX_SELECTION_DEPTH {
+		return nil, &Error{Message: fmt.Sprintf("The query can't be more than %v levels deep", MAX_SELECTION_DEPTH), Path: path}
+	}
+
+	fields, err := e.collectFields(object, selections, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*orderedMap, len(sources))
+
+	// Only the sources that aren't null have their fields resolved
+	var indexes []int
+	var live []interface{}
+	for i, source := range sources {
+		if !isNil(source) {
+			results[i] = newOrderedMap()
+			indexes = append(indexes, i)
+			live = append(live, source)
+		}
+	}
+
+	if len(live) == 0 {
+		return results, nil
+	}
+
+	for _, collected := range fields {
+		selection := collected.selections[0]
+
+		if selection.Name == "__typename" {
+			for _, i := range indexes {
+				results[i].Set(collected.key, object.Name)
+			}
+			continue
+		}
+
+		field := object.Fields[selection.Name]
+		if field == nil {
+			return nil, &Error{Message: fmt.Sprintf("Cannot query field %q on type %q", selection.Name, object.Name), Locations: []Location{selection.Location}}
+		}
+
+		var subselections []*Selection
+		for _, s := range collected.selections {
+			subselections = append(subselections, s.Selections...)
+		}
+
+		if field.Type == nil && len(subselections) > 0 {
+			return nil, &Error{Message: fmt.Sprintf("Field %q of type %q can't have a selection of subfields", selection.Name, object.Name), Locations: []Location{selection.Location}}
+		} else if field.Type != nil && len(subselections) == 0 {
+			return nil, &Error{Message: fmt.Sprintf("Field %q of type %q must have a selection of subfields", selection.Name, object.Name), Locations: []Location{selection.Location}}
+		}
+
+		arguments, err := e.coerceArguments(field, selection)
+		if err != nil {
+			return nil, err
+		}
+
+		fieldPath := append(append([]interface{}{}, path...), collected.key)
+
+		values, resolveErr := field.Resolve(&ResolveParams{
+			Context:   e.context,
+			Sources:   live,
+			Arguments: arguments,
+		})
+		if resolveErr == nil && len(values) != len(live) {
+			resolveErr = fmt.Errorf("Field %q of type %q resolved to the wrong number of values", selection.Name, object.Name)
+		}
+
+		if resolveErr != nil {
+			e.errors = append(e.errors, &Error{Message: resolveErr.Error(), Locations: []Location{selection.Location}, Path: fieldPath})
+			for _, i := range indexes {
+				results[i].Set(collected.key, nil)
+			}
+			continue
+		}
+
+		for j, value := range values {
+			if valueErr, ok := value.(error); ok {
+				e.errors = append(e.errors, &Error{Message: valueErr.Error(), Locations: []Location{selection.Location}, Path: fieldPath})
+				values[j] = nil
+			} else if isNil(value) {
+				values[j] = nil
+			}
+		}
+
+		if field.Type == nil {
+			for j, i := range indexes {
+				results[i].Set(collected.key, values[j])
+			}
+			continue
+		}
+
+		if !field.List {
+			children, err := e.executeSelections(field.Type, values, subselections, fieldPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			for j, i := range indexes {
+				if children[j] == nil {
+					results[i].Set(collected.key, nil)
+				} else {
+					results[i].Set(collected.key, children[j])
+				}
+			}
+			continue
+		}
+
+		// The items of every list are resolved together before being split up again
+		var items []interface{}
+		for _, value := range values {
+			if list, ok := value.([]interface{}); ok {
+				items = append(items, list...)
+			}
+		}
+
+		children, err := e.executeSelections(field.Type, items, subselections, fieldPath, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		offset := 0
+		for j, i := range indexes {
+			list, ok := values[j].([]interface{})
+			if !ok {
+				results[i].Set(collected.key, nil)
+				continue
+			}
+
+			childList := make([]interface{}, len(list))
+			for k := range list {
+				if child := children[offset+k]; child != nil {
+					childList[k] = child
+				}
+			}
+			offset += len(list)
+
+			results[i].Set(collected.key, childList)
+		}
+	}
+
+	return results, nil
+}
+
+func (e *executor) collectFields(object *Object, selections []*Selection, visitedFragments map[string]bool) ([]*collectedField, *Error) {
+	var fields []*collectedField
+
+	add := func(collected []*collectedField) {
+		for _, c := range collected {
+			merged := false
+			for _, field := range fields {
+				if field.key == c.key {
+					field.selections = append(field.selections, c.selections...)
+					merged = true
+					break
+				}
+			}
+
+			if !merged {
+				fields = append(fields, c)
+			}
+		}
+	}
+
+	for _, selection := range selections {
+		if include, err := e.shouldInclude(selection.Directives); err != nil {
+			return nil, err
+		} else if !include {
+			continue
+		}
+
+		if len(selection.FragmentSpread) > 0 {
+			if visitedFragments[selection.FragmentSpread] {
+				continue
+			}
+			visitedFragments[selection.FragmentSpread] = true
+
+			fragment := e.document.Fragments[selection.FragmentSpread]
+			if fragment == nil {
+				return nil, &Error{Message: fmt.Sprintf("Unknown fragment %q", selection.FragmentSpread), Locations: []Location{selection.Location}}
+			}
+
+			if fragment.TypeCondition != object.Name {
+				continue
+			}
+
+			if include, err := e.shouldInclude(fragment.Directives); err != nil {
+				return nil, err
+			} else if !include {
+				continue
+			}
+
+			collected, err := e.collectFields(object, fragment.Selections, visitedFragments)
+			if err != nil {
+				return nil, err
+			}
+			add(collected)
+		} else if selection.InlineFragment {
+			if len(selection.TypeCondition) > 0 && selection.TypeCondition != object.Name {
+				continue
+			}
+
+			collected, err := e.collectFields(object, selection.Selections, visitedFragments)
+			if err != nil {
+				return nil, err
+			}
+			add(collected)
+		} else {
+			add([]*collectedField{{key: selection.ResponseKey(), selections: []*Selection{selection}}})
+		}
+	}
+
+	for _, field := range fields {
+		for _, selection := range field.selections[1:] {
+			if selection.Name != field.selections[0].Name {
+				return nil, &Error{Message: fmt.Sprintf("Fields %q and %q conflict because they are both named %q", field.selections[0].Name, selection.Name, field.key), Locations: []Location{selection.Location}}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// shouldInclude applies the @skip and @include directives.
+func (e *executor) shouldInclude(directives []*Directive) (bool, *Error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			return false, &Error{Message: fmt.Sprintf("Unknown directive @%v", directive.Name)}
+		}
+
+		if len(directive.Arguments) != 1 || directive.Arguments[0].Name != "if" {
+			return false, &Error{Message: fmt.Sprintf("The @%v directive requires a single if argument", directive.Name)}
+		}
+
+		value, err := e.resolveValue(directive.Arguments[0].Value)
+		if err != nil {
+			return false, err
+		}
+
+		condition, ok := value.(bool)
+		if !ok {
+			return false, &Error{Message: fmt.Sprintf("The if argument of the @%v directive must be a Boolean", directive.Name)}
+		}
+
+		if (directive.Name == "skip") == condition {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (e *executor) coerceArguments(field *Field, selection *Selection) (map[string]interface{}, *Error) {
+	arguments := make(map[string]interface{}, len(field.Arguments))
+
+	for _, argument := range selection.Arguments {
+		definition := field.Arguments[argument.Name]
+		if definition == nil {
+			return nil, &Error{Message: fmt.Sprintf("Unknown argument %q on field %q", argument.Name, selection.Name), Locations: []Location{selection.Location}}
+		}
+
+		value, err := e.resolveValue(argument.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if coerced, err := coerceValue(definition.asType(), value); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Argument %q of field %q %v", argument.Name, selection.Name, err.Error()), Locations: []Location{selection.Location}}
+		} else {
+			arguments[argument.Name] = coerced
+		}
+	}
+
+	for name, definition := range field.Arguments {
+		if _, ok := arguments[name]; ok {
+			continue
+		}
+
+		if definition.DefaultValue != nil {
+			arguments[name] = definition.DefaultValue
+		} else if definition.Required {
+			return nil, &Error{Message: fmt.Sprintf("Argument %q of field %q is required", name, selection.Name), Locations: []Location{selection.Location}}
+		}
+	}
+
+	return arguments, nil
+}
+
+func (d *ArgumentDefinition) asType() *Type {
+	if d.List {
+		return &Type{Elem: &Type{Name: d.Type}, NonNull: d.Required}
+	}
+
+	return &Type{Name: d.Type, NonNull: d.Required}
+}
+
+// resolveValue turns a value of the query into the Go value that it represents, replacing variables
+// by their value.
+func (e *executor) resolveValue(value *Value) (interface{}, *Error) {
+	switch value.Kind {
+	case VALUE_VARIABLE:
+		if variable, ok := e.variables[value.Raw]; ok {
+			return variable, nil
+		}
+
+		return nil, &Error{Message: fmt.Sprintf("Variable $%v is not defined", value.Raw)}
+	case VALUE_INT:
+		var i int64
+		if _, err := fmt.Sscan(value.Raw, &i); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Invalid Int %v", value.Raw)}
+		}
+		return i, nil
+	case VALUE_FLOAT:
+		var f float64
+		if _, err := fmt.Sscan(value.Raw, &f); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Invalid Float %v", value.Raw)}
+		}
+		return f, nil
+	case VALUE_STRING, VALUE_ENUM:
+		return value.Raw, nil
+	case VALUE_BOOLEAN:
+		return value.Raw == "true", nil
+	case VALUE_LIST:
+		list := make([]interface{}, len(value.List))
+		for i, item := range value.List {
+			var err *Error
+			if list[i], err = e.resolveValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case VALUE_OBJECT:
+		object := make(map[string]interface{}, len(value.Fields))
+		for _, field := range value.Fields {
+			var err *Error
+			if object[field.Name], err = e.resolveValue(field.Value); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+
+	return nil, nil
+}
+
+// coerceValue checks that a value, which comes from either the query or the JSON encoded variables,
+// has the given type and returns it as a string, int, float64, bool or []interface{}.
+func coerceValue(t *Type, value interface{}) (interface{}, error) {
+	if value == nil {
+		if t.NonNull {
+			return nil, fmt.Errorf("can't be null")
+		}
+
+		return nil, nil
+	}
+
+	if t.Elem != nil {
+		list, ok := value.([]interface{})
+		if !ok {
+			// A single value is accepted where a list is expected
+			list = []interface{}{value}
+		}
+
+		coerced := make([]interface{}, len(list))
+		for i, item := range list {
+			var err error
+			if coerced[i], err = coerceValue(t.Elem, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return coerced, nil
+	}
+
+	switch t.Name {
+	case STRING:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ID:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case int64:
+			return fmt.Sprint(v), nil
+		}
+	case INT:
+		switch v := value.(type) {
+		case int64:
+			if v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int(v), nil
+			}
+		case int:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+				return int(v), nil
+			}
+		}
+	case FLOAT:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		}
+	case BOOLEAN:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	default:
+		return nil, fmt.Errorf("has an unknown type")
+	}
+
+	return nil, fmt.Errorf("must be of type %v", t.String())
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
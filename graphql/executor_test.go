@@ -0,0 +1,269 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package graphql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testAuthor struct {
+	Id   string
+	Name string
+}
+
+type testBook struct {
+	Id       string
+	Title    string
+	AuthorId string
+}
+
+func newTestSchema(authorBatches *int) *Schema {
+	authors := map[string]*testAuthor{
+		"a1": {Id: "a1", Name: "Ada"},
+		"a2": {Id: "a2", Name: "Brian"},
+	}
+
+	books := []*testBook{
+		{Id: "b1", Title: "First", AuthorId: "a1"},
+		{Id: "b2", Title: "Second", AuthorId: "a2"},
+		{Id: "b3", Title: "Third", AuthorId: "a1"},
+		{Id: "b4", Title: "Secret", AuthorId: "a2"},
+	}
+
+	authorType := &Object{
+		Name: "Author",
+		Fields: map[string]*Field{
+			"id": {Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				return source.(*testAuthor).Id, nil
+			})},
+			"name": {Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				return source.(*testAuthor).Name, nil
+			})},
+		},
+	}
+
+	bookType := &Object{
+		Name: "Book",
+		Fields: map[string]*Field{
+			"id": {Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				return source.(*testBook).Id, nil
+			})},
+			"title": {Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+				if book := source.(*testBook); book.Title == "Secret" {
+					return nil, errors.New("You can't read this title")
+				} else {
+					return book.Title, nil
+				}
+			})},
+			"author": {
+				Type: authorType,
+				Resolve: func(p *ResolveParams) ([]interface{}, error) {
+					*authorBatches++
+
+					values := make([]interface{}, len(p.Sources))
+					for i, source := range p.Sources {
+						values[i] = authors[source.(*testBook).AuthorId]
+					}
+
+					return values, nil
+				},
+			},
+		},
+	}
+
+	queryType := &Object{
+		Name: "Query",
+		Fields: map[string]*Field{
+			"books": {
+				Type: bookType,
+				List: true,
+				Arguments: map[string]*ArgumentDefinition{
+					"first": {Type: INT, DefaultValue: 10},
+				},
+				Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					var list []interface{}
+					for i := 0; i < len(books) && i < arguments["first"].(int); i++ {
+						list = append(list, books[i])
+					}
+					return list, nil
+				}),
+			},
+			"author": {
+				Type: authorType,
+				Arguments: map[string]*ArgumentDefinition{
+					"id": {Type: ID, Required: true},
+				},
+				Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					if author, ok := authors[arguments["id"].(string)]; ok {
+						return author, nil
+					} else {
+						// A typed nil is resolved to null like an untyped one
+						return (*testAuthor)(nil), nil
+					}
+				}),
+			},
+			"viewer": {
+				Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+					return context, nil
+				}),
+			},
+		},
+	}
+
+	return &Schema{Query: queryType}
+}
+
+func TestExecute(t *testing.T) {
+	authorBatches := 0
+	schema := newTestSchema(&authorBatches)
+
+	query := `
+		query Books($first: Int) {
+			viewer
+			books(first: $first) {
+				...BookFields
+				writer: author { name }
+				author { id }
+			}
+			missing: author(id: "a3") { name }
+		}
+
+		fragment BookFields on Book {
+			__typename
+			id
+			title
+		}
+	`
+
+	response := Execute(schema, query, "", map[string]interface{}{"first": float64(3)}, "someone")
+	if len(response.Errors) != 0 {
+		t.Fatal(response.Errors[0])
+	}
+
+	expected := `{"data":{"viewer":"someone","books":[` +
+		`{"__typename":"Book","id":"b1","title":"First","writer":{"name":"Ada"},"author":{"id":"a1"}},` +
+		`{"__typename":"Book","id":"b2","title":"Second","writer":{"name":"Brian"},"author":{"id":"a2"}},` +
+		`{"__typename":"Book","id":"b3","title":"Third","writer":{"name":"Ada"},"author":{"id":"a1"}}` +
+		`],"missing":null}}`
+	if json := response.ToJson(); json != expected {
+		t.Fatal("should have returned the selected fields in order", json)
+	}
+
+	if authorBatches != 2 {
+		t.Fatal("should have resolved the authors of every book together for each selection", authorBatches)
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	authorBatches := 0
+	schema := newTestSchema(&authorBatches)
+
+	response := Execute(schema, `{ books { id title } }`, "", nil, nil)
+	if len(response.Errors) != 1 {
+		t.Fatal("should have returned the error of the field")
+	} else if err := response.Errors[0]; err.Message != "You can't read this title" || len(err.Path) != 2 || err.Path[0] != "books" || err.Path[1] != "title" {
+		t.Fatal("should have returned where the error happened", err)
+	}
+
+	if json := response.ToJson(); !strings.Contains(json, `{"id":"b4","title":null}`) || !strings.Contains(json, `{"id":"b1","title":"First"}`) {
+		t.Fatal("should have resolved the field that failed to null and kept the rest", json)
+	}
+}
+
+func TestExecuteDirectives(t *testing.T) {
+	authorBatches := 0
+	schema := newTestSchema(&authorBatches)
+
+	query := `query Q($withAuthor: Boolean!) {
+		books(first: 1) {
+			id @skip(if: true)
+			title @include(if: true)
+			author @include(if: $withAuthor) { name }
+		}
+	}`
+
+	response := Execute(schema, query, "Q", map[string]interface{}{"withAuthor": false}, nil)
+	if json := response.ToJson(); json != `{"data":{"books":[{"title":"First"}]}}` {
+		t.Fatal("should have applied the directives", json)
+	}
+
+	if authorBatches != 0 {
+		t.Fatal("should not have resolved a field that was skipped")
+	}
+}
+
+func TestExecuteInvalid(t *testing.T) {
+	authorBatches := 0
+	schema := newTestSchema(&authorBatches)
+
+	for _, test := range []struct {
+		query     string
+		operation string
+		variables map[string]interface{}
+		message   string
+	}{
+		{`{ books { id }`, "", nil, "Syntax error"},
+		{`{ books { isbn } }`, "", nil, `Cannot query field "isbn" on type "Book"`},
+		{`{ books }`, "", nil, "must have a selection of subfields"},
+		{`{ viewer { id } }`, "", nil, "can't have a selection of subfields"},
+		{`{ author { name } }`, "", nil, `Argument "id" of field "author" is required`},
+		{`{ author(id: "a1", name: "Ada") { name } }`, "", nil, "Unknown argument"},
+		{`{ books(first: "ten") { id } }`, "", nil, "must be of type Int"},
+		{`{ books(first: $first) { id } }`, "", nil, "Variable $first is not defined"},
+		{`query Q($first: Int!) { books(first: $first) { id } }`, "", nil, "can't be null"},
+		{`query Q($first: Int) { books(first: $first) { id } }`, "", map[string]interface{}{"first": 1.5}, "must be of type Int"},
+		{`{ books { ...Missing } }`, "", nil, "Unknown fragment"},
+		{`{ books { id id: title } }`, "", nil, "conflict"},
+		{`{ books { id @defer } }`, "", nil, "Unknown directive"},
+		{`mutation { books { id } }`, "", nil, "Only queries are supported"},
+		{`query A { viewer } query B { viewer }`, "", nil, "operation name is required"},
+		{`query A { viewer }`, "B", nil, "Unknown operation"},
+		{`{ books { author { name } } }`, "", nil, ""},
+	} {
+		response := Execute(schema, test.query, test.operation, test.variables, nil)
+
+		if len(test.message) == 0 {
+			if len(response.Errors) != 0 {
+				t.Fatal("should have succeeded", test.query, response.Errors[0])
+			}
+			continue
+		}
+
+		if len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, test.message) {
+			t.Fatal("should have failed", test.query, response.ToJson())
+		} else if response.Data != nil {
+			t.Fatal("should not have returned data for an invalid request", test.query)
+		}
+	}
+}
+
+func TestExecuteMaxDepth(t *testing.T) {
+	authorBatches := 0
+	schema := newTestSchema(&authorBatches)
+
+	selfType := &Object{Name: "Self"}
+	selfType.Fields = map[string]*Field{
+		"id": {Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+			return "self", nil
+		})},
+		"self": {Type: selfType, Resolve: ResolveEach(func(context interface{}, source interface{}, arguments map[string]interface{}) (interface{}, error) {
+			return source, nil
+		})},
+	}
+	schema.Query.Fields["self"] = selfType.Fields["self"]
+
+	deepQuery := func(depth int) string {
+		return "{" + strings.Repeat(" self {", depth) + " id" + strings.Repeat(" }", depth) + " }"
+	}
+
+	if response := Execute(schema, deepQuery(MAX_SELECTION_DEPTH-1), "", nil, nil); len(response.Errors) != 0 {
+		t.Fatal("should have allowed a query up to the maximum depth", response.Errors[0])
+	}
+
+	if response := Execute(schema, deepQuery(MAX_SELECTION_DEPTH), "", nil, nil); len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, "levels deep") {
+		t.Fatal("should not have allowed a query past the maximum depth")
+	}
+}
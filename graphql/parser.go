@@ -0,0 +1,795 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	OPERATION_QUERY        = "query"
+	OPERATION_MUTATION     = "mutation"
+	OPERATION_SUBSCRIPTION = "subscription"
+
+	VALUE_VARIABLE = "variable"
+	VALUE_INT      = "int"
+	VALUE_FLOAT    = "float"
+	VALUE_STRING   = "string"
+	VALUE_BOOLEAN  = "boolean"
+	VALUE_NULL     = "null"
+	VALUE_ENUM     = "enum"
+	VALUE_LIST     = "list"
+	VALUE_OBJECT   = "object"
+)
+
+// Document is a parsed GraphQL request. Only executable definitions are supported, so a document
+// holds operations and the fragments that they use.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []*Selection
+}
+
+type VariableDefinition struct {
+	Name         string
+	Type         *Type
+	DefaultValue *Value
+}
+
+// Type is a type reference such as String, [ID] or Int!. Elem is set for list types.
+type Type struct {
+	Name    string
+	Elem    *Type
+	NonNull bool
+}
+
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+
+	if t.NonNull {
+		s += "!"
+	}
+
+	return s
+}
+
+// Selection is a field, a fragment spread when FragmentSpread is set or an inline fragment when
+// InlineFragment is set.
+type Selection struct {
+	Alias          string
+	Name           string
+	Arguments      []*Argument
+	Directives     []*Directive
+	Selections     []*Selection
+	FragmentSpread string
+	InlineFragment bool
+	TypeCondition  string
+	Location       Location
+}
+
+// ResponseKey returns the name that the field is given in the response.
+func (s *Selection) ResponseKey() string {
+	if len(s.Alias) > 0 {
+		return s.Alias
+	}
+
+	return s.Name
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []*Selection
+}
+
+type Argument struct {
+	Name  string
+	Value *Value
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+type Value struct {
+	Kind   string
+	Raw    string
+	List   []*Value
+	Fields []*Argument
+}
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind     int
+	value    string
+	location Location
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a GraphQL document made of operations and fragments.
+func Parse(query string) (*Document, *Error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	doc := &Document{
+		Fragments: make(map[string]*Fragment),
+	}
+
+	for p.peek().kind != tokenEOF {
+		if p.peekName("fragment") {
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, p.errorf("There can be only one fragment named %q", fragment.Name)
+			}
+
+			doc.Fragments[fragment.Name] = fragment
+		} else {
+			operation, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.Operations = append(doc.Operations, operation)
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, &Error{Message: "The document doesn't contain an operation"}
+	}
+
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) peekPunctuator(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunctuator && t.value == value
+}
+
+func (p *parser) peekName(value string) bool {
+	t := p.peek()
+	return t.kind == tokenName && t.value == value
+}
+
+func (p *parser) errorf(format string, args ...interface{}) *Error {
+	return &Error{
+		Message:   "Syntax error: " + fmt.Sprintf(format, args...),
+		Locations: []Location{p.peek().location},
+	}
+}
+
+func (p *parser) unexpected() *Error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return p.errorf("Unexpected end of document")
+	}
+
+	return p.errorf("Unexpected %q", t.value)
+}
+
+func (p *parser) expectPunctuator(value string) *Error {
+	if !p.peekPunctuator(value) {
+		return p.unexpected()
+	}
+
+	p.next()
+	return nil
+}
+
+func (p *parser) expectName() (string, *Error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected()
+	}
+
+	return p.next().value, nil
+}
+
+func (p *parser) parseOperation() (*Operation, *Error) {
+	operation := &Operation{Type: OPERATION_QUERY}
+
+	// A document can be a lone selection set, which is shorthand for a query
+	if !p.peekPunctuator("{") {
+		t := p.peek()
+		if t.kind != tokenName || (t.value != OPERATION_QUERY && t.value != OPERATION_MUTATION && t.value != OPERATION_SUBSCRIPTION) {
+			return nil, p.unexpected()
+		}
+		operation.Type = p.next().value
+
+		if p.peek().kind == tokenName {
+			operation.Name = p.next().value
+		}
+
+		if p.peekPunctuator("(") {
+			p.next()
+
+			for !p.peekPunctuator(")") {
+				definition, err := p.parseVariableDefinition()
+				if err != nil {
+					return nil, err
+				}
+
+				operation.Variables = append(operation.Variables, definition)
+			}
+			p.next()
+		}
+
+		var err *Error
+		if operation.Directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err *Error
+	if operation.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinition() (*VariableDefinition, *Error) {
+	if err := p.expectPunctuator("$"); err != nil {
+		return nil, err
+	}
+
+	definition := &VariableDefinition{}
+
+	var err *Error
+	if definition.Name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectPunctuator(":"); err != nil {
+		return nil, err
+	}
+
+	if definition.Type, err = p.parseType(); err != nil {
+		return nil, err
+	}
+
+	if p.peekPunctuator("=") {
+		p.next()
+
+		if definition.DefaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+
+	return definition, nil
+}
+
+func (p *parser) parseType() (*Type, *Error) {
+	t := &Type{}
+
+	if p.peekPunctuator("[") {
+		p.next()
+
+		var err *Error
+		if t.Elem, err = p.parseType(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expectPunctuator("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		var err *Error
+		if t.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peekPunctuator("!") {
+		p.next()
+		t.NonNull = true
+	}
+
+	return t, nil
+}
+
+func (p *parser) parseFragment() (*Fragment, *Error) {
+	p.next()
+
+	fragment := &Fragment{}
+
+	var err *Error
+	if fragment.Name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Name == "on" {
+		return nil, p.errorf("A fragment can't be named \"on\"")
+	}
+
+	if !p.peekName("on") {
+		return nil, p.unexpected()
+	}
+	p.next()
+
+	if fragment.TypeCondition, err = p.expectName(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	if fragment.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+
+	return fragment, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Selection, *Error) {
+	if err := p.expectPunctuator("{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*Selection
+	for !p.peekPunctuator("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+
+		selections = append(selections, selection)
+	}
+	p.next()
+
+	if len(selections) == 0 {
+		return nil, p.errorf("A selection set can't be empty")
+	}
+
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (*Selection, *Error) {
+	selection := &Selection{Location: p.peek().location}
+
+	var err *Error
+
+	if p.peekPunctuator("...") {
+		p.next()
+
+		if p.peek().kind == tokenName && !p.peekName("on") {
+			selection.FragmentSpread = p.next().value
+
+			if selection.Directives, err = p.parseDirectives(); err != nil {
+				return nil, err
+			}
+
+			return selection, nil
+		}
+
+		selection.InlineFragment = true
+
+		if p.peekName("on") {
+			p.next()
+
+			if selection.TypeCondition, err = p.expectName(); err != nil {
+				return nil, err
+			}
+		}
+
+		if selection.Directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+
+		if selection.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+
+		return selection, nil
+	}
+
+	if selection.Name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+
+	if p.peekPunctuator(":") {
+		p.next()
+
+		selection.Alias = selection.Name
+		if selection.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if selection.Arguments, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+
+	if selection.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	if p.peekPunctuator("{") {
+		if selection.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return selection, nil
+}
+
+func (p *parser) parseArguments() ([]*Argument, *Error) {
+	if !p.peekPunctuator("(") {
+		return nil, nil
+	}
+	p.next()
+
+	var arguments []*Argument
+	for !p.peekPunctuator(")") {
+		argument := &Argument{}
+
+		var err *Error
+		if argument.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expectPunctuator(":"); err != nil {
+			return nil, err
+		}
+
+		if argument.Value, err = p.parseValue(false); err != nil {
+			return nil, err
+		}
+
+		arguments = append(arguments, argument)
+	}
+	p.next()
+
+	return arguments, nil
+}
+
+func (p *parser) parseDirectives() ([]*Directive, *Error) {
+	var directives []*Directive
+
+	for p.peekPunctuator("@") {
+		p.next()
+
+		directive := &Directive{}
+
+		var err *Error
+		if directive.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+
+		if directive.Arguments, err = p.parseArguments(); err != nil {
+			return nil, err
+		}
+
+		directives = append(directives, directive)
+	}
+
+	return directives, nil
+}
+
+// parseValue parses an input value. Variables aren't allowed in constant values such as defaults.
+func (p *parser) parseValue(constant bool) (*Value, *Error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenInt:
+		p.next()
+		return &Value{Kind: VALUE_INT, Raw: t.value}, nil
+	case tokenFloat:
+		p.next()
+		return &Value{Kind: VALUE_FLOAT, Raw: t.value}, nil
+	case tokenString:
+		p.next()
+		return &Value{Kind: VALUE_STRING, Raw: t.value}, nil
+	case tokenName:
+		p.next()
+
+		switch t.value {
+		case "true", "false":
+			return &Value{Kind: VALUE_BOOLEAN, Raw: t.value}, nil
+		case "null":
+			return &Value{Kind: VALUE_NULL}, nil
+		default:
+			return &Value{Kind: VALUE_ENUM, Raw: t.value}, nil
+		}
+	case tokenPunctuator:
+		switch t.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			p.next()
+
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+
+			return &Value{Kind: VALUE_VARIABLE, Raw: name}, nil
+		case "[":
+			p.next()
+
+			value := &Value{Kind: VALUE_LIST}
+			for !p.peekPunctuator("]") {
+				item, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+
+				value.List = append(value.List, item)
+			}
+			p.next()
+
+			return value, nil
+		case "{":
+			p.next()
+
+			value := &Value{Kind: VALUE_OBJECT}
+			for !p.peekPunctuator("}") {
+				field := &Argument{}
+
+				var err *Error
+				if field.Name, err = p.expectName(); err != nil {
+					return nil, err
+				}
+
+				if err := p.expectPunctuator(":"); err != nil {
+					return nil, err
+				}
+
+				if field.Value, err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+
+				value.Fields = append(value.Fields, field)
+			}
+			p.next()
+
+			return value, nil
+		}
+	}
+
+	return nil, p.unexpected()
+}
+
+func lex(query string) ([]token, *Error) {
+	var tokens []token
+
+	line := 1
+	lineStart := 0
+	i := 0
+
+	errorf := func(format string, args ...interface{}) *Error {
+		return &Error{
+			Message:   "Syntax error: " + fmt.Sprintf(format, args...),
+			Locations: []Location{{Line: line, Column: i - lineStart + 1}},
+		}
+	}
+
+	for i < len(query) {
+		c := query[i]
+		location := Location{Line: line, Column: i - lineStart + 1}
+
+		switch {
+		case c == '\n':
+			i++
+			line++
+			lineStart = i
+		case c == '\r':
+			i++
+			if i < len(query) && query[i] == '\n' {
+				i++
+			}
+			line++
+			lineStart = i
+		case c == ' ' || c == '\t' || c == ',':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, token{tokenPunctuator, "...", location})
+			i += 3
+		case strings.IndexByte("!$():=@[]{}|", c) != -1:
+			tokens = append(tokens, token{tokenPunctuator, string(c), location})
+			i++
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			start := i
+			for i < len(query) && isNameChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, query[start:i], location})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			kind := tokenInt
+
+			if c == '-' {
+				i++
+			}
+
+			if i < len(query) && query[i] == '0' {
+				i++
+			} else if digits := countDigits(query[i:]); digits > 0 {
+				i += digits
+			} else {
+				return nil, errorf("Invalid number")
+			}
+
+			if i < len(query) && query[i] == '.' {
+				kind = tokenFloat
+				i++
+
+				digits := countDigits(query[i:])
+				if digits == 0 {
+					return nil, errorf("Invalid number")
+				}
+				i += digits
+			}
+
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				kind = tokenFloat
+				i++
+
+				if i < len(query) && (query[i] == '+' || query[i] == '-') {
+					i++
+				}
+
+				digits := countDigits(query[i:])
+				if digits == 0 {
+					return nil, errorf("Invalid number")
+				}
+				i += digits
+			}
+
+			if i < len(query) && (isNameChar(query[i]) || query[i] == '.') {
+				return nil, errorf("Invalid number")
+			}
+
+			tokens = append(tokens, token{kind, query[start:i], location})
+		case c == '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				return nil, errorf("Block strings aren't supported")
+			}
+
+			value, length, err := lexString(query[i:])
+			if err != "" {
+				return nil, errorf("%v", err)
+			}
+
+			tokens = append(tokens, token{tokenString, value, location})
+			i += length
+		default:
+			r, _ := utf8.DecodeRuneInString(query[i:])
+			return nil, errorf("Unexpected character %q", r)
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", Location{Line: line, Column: i - lineStart + 1}})
+
+	return tokens, nil
+}
+
+// lexString reads the quoted string at the start of s and returns its value and its length in s.
+func lexString(s string) (string, int, string) {
+	var value []byte
+
+	i := 1
+	for i < len(s) {
+		c := s[i]
+
+		switch {
+		case c == '"':
+			return string(value), i + 1, ""
+		case c == '\n' || c == '\r':
+			return "", 0, "Unterminated string"
+		case c == '\\':
+			if i+1 >= len(s) {
+				return "", 0, "Unterminated string"
+			}
+
+			switch s[i+1] {
+			case '"', '\\', '/':
+				value = append(value, s[i+1])
+			case 'b':
+				value = append(value, '\b')
+			case 'f':
+				value = append(value, '\f')
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case 'u':
+				if i+6 > len(s) {
+					return "", 0, "Invalid unicode escape sequence"
+				}
+
+				code, err := strconv.ParseUint(s[i+2:i+6], 16, 32)
+				if err != nil {
+					return "", 0, "Invalid unicode escape sequence"
+				}
+
+				value = append(value, string(rune(code))...)
+				i += 4
+			default:
+				return "", 0, "Invalid escape sequence"
+			}
+
+			i += 2
+		default:
+			value = append(value, c)
+			i++
+		}
+	}
+
+	return "", 0, "Unterminated string"
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func countDigits(s string) int {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package graphql
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	query := `
+		# Fetches a channel along with its posts
+		query GetChannel($id: ID!, $perPage: Int = 30) @include(if: true) {
+			channel(id: $id) {
+				id
+				displayName: display_name
+				posts(page: 0, per_page: $perPage) {
+					...PostFields
+					... on Post @skip(if: false) {
+						user { username }
+					}
+				}
+			}
+		}
+
+		fragment PostFields on Post {
+			id, message
+		}
+	`
+
+	doc, err := Parse(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatal("should have parsed one operation")
+	}
+
+	operation := doc.Operations[0]
+	if operation.Type != OPERATION_QUERY || operation.Name != "GetChannel" {
+		t.Fatal("should have parsed the operation type and name", operation.Type, operation.Name)
+	}
+
+	if len(operation.Variables) != 2 {
+		t.Fatal("should have parsed the variables")
+	} else if id := operation.Variables[0]; id.Name != "id" || id.Type.String() != "ID!" || id.DefaultValue != nil {
+		t.Fatal("should have parsed the id variable", id.Name, id.Type)
+	} else if perPage := operation.Variables[1]; perPage.Type.String() != "Int" || perPage.DefaultValue == nil || perPage.DefaultValue.Kind != VALUE_INT || perPage.DefaultValue.Raw != "30" {
+		t.Fatal("should have parsed the default value of the perPage variable")
+	}
+
+	if len(operation.Directives) != 1 || operation.Directives[0].Name != "include" {
+		t.Fatal("should have parsed the directive of the operation")
+	}
+
+	if len(operation.Selections) != 1 {
+		t.Fatal("should have parsed one field")
+	}
+
+	channel := operation.Selections[0]
+	if channel.Name != "channel" || channel.Location.Line != 4 {
+		t.Fatal("should have parsed the channel field", channel.Name, channel.Location)
+	} else if len(channel.Arguments) != 1 || channel.Arguments[0].Value.Kind != VALUE_VARIABLE || channel.Arguments[0].Value.Raw != "id" {
+		t.Fatal("should have parsed the argument of the channel field")
+	} else if len(channel.Selections) != 3 {
+		t.Fatal("should have parsed the fields of the channel")
+	}
+
+	if displayName := channel.Selections[1]; displayName.Alias != "displayName" || displayName.Name != "display_name" || displayName.ResponseKey() != "displayName" {
+		t.Fatal("should have parsed the alias")
+	}
+
+	posts := channel.Selections[2]
+	if len(posts.Arguments) != 2 || posts.Arguments[0].Value.Kind != VALUE_INT || posts.Arguments[1].Value.Kind != VALUE_VARIABLE {
+		t.Fatal("should have parsed the arguments of the posts field")
+	} else if len(posts.Selections) != 2 || posts.Selections[0].FragmentSpread != "PostFields" {
+		t.Fatal("should have parsed the fragment spread")
+	} else if inline := posts.Selections[1]; !inline.InlineFragment || inline.TypeCondition != "Post" || len(inline.Directives) != 1 || len(inline.Selections) != 1 {
+		t.Fatal("should have parsed the inline fragment")
+	}
+
+	if fragment := doc.Fragments["PostFields"]; fragment == nil || fragment.TypeCondition != "Post" || len(fragment.Selections) != 2 {
+		t.Fatal("should have parsed the fragment")
+	}
+}
+
+func TestParseShorthand(t *testing.T) {
+	doc, err := Parse(`{ me { id } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Operations) != 1 || doc.Operations[0].Type != OPERATION_QUERY || doc.Operations[0].Name != "" {
+		t.Fatal("should have parsed an anonymous query")
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	doc, err := Parse(`{ field(a: -12, b: 1.5e3, c: "say \"hi\"\né", d: true, e: null, f: ENUM, g: [1, "two"], h: {x: 1}) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arguments := doc.Operations[0].Selections[0].Arguments
+
+	expected := []struct {
+		kind string
+		raw  string
+	}{
+		{VALUE_INT, "-12"},
+		{VALUE_FLOAT, "1.5e3"},
+		{VALUE_STRING, "say \"hi\"\né"},
+		{VALUE_BOOLEAN, "true"},
+		{VALUE_NULL, ""},
+		{VALUE_ENUM, "ENUM"},
+		{VALUE_LIST, ""},
+		{VALUE_OBJECT, ""},
+	}
+
+	if len(arguments) != len(expected) {
+		t.Fatal("should have parsed every argument")
+	}
+
+	for i, e := range expected {
+		if arguments[i].Value.Kind != e.kind || arguments[i].Value.Raw != e.raw {
+			t.Fatal("should have parsed the value", arguments[i].Name, arguments[i].Value.Kind, arguments[i].Value.Raw)
+		}
+	}
+
+	if list := arguments[6].Value.List; len(list) != 2 || list[0].Kind != VALUE_INT || list[1].Kind != VALUE_STRING {
+		t.Fatal("should have parsed the list")
+	}
+
+	if fields := arguments[7].Value.Fields; len(fields) != 1 || fields[0].Name != "x" || fields[0].Value.Raw != "1" {
+		t.Fatal("should have parsed the object")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`{`,
+		`{ }`,
+		`{ me { id }`,
+		`query { me(id: ) }`,
+		`query Q($id) { me }`,
+		`query Q($id: ID = $other) { me }`,
+		`fragment F on User { id }`,
+		`fragment on on User { id } { me }`,
+		`{ me } fragment F on User { id } fragment F on User { id }`,
+		`{ me(id: "unterminated) }`,
+		`{ me(id: "bad \q escape") }`,
+		`{ me(id: 01) }`,
+		`{ me(id: 1.) }`,
+		`{ me(id: """block""") }`,
+		`{ me ? }`,
+		`other { me }`,
+	} {
+		if _, err := Parse(query); err == nil {
+			t.Fatal("should have failed to parse", query)
+		} else if len(err.Message) == 0 {
+			t.Fatal("should have had a message", query)
+		}
+	}
+
+	if _, err := Parse("{\n  me(id: ?)\n}"); err == nil || len(err.Locations) != 1 || err.Locations[0].Line != 2 || err.Locations[0].Column != 10 {
+		t.Fatal("should have reported where the error is", err)
+	}
+}
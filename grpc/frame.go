@@ -0,0 +1,51 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	FRAME_HEADER_SIZE        = 5
+	DEFAULT_MAX_MESSAGE_SIZE = 4 * 1024 * 1024
+)
+
+// readMessage reads a length-prefixed message. It returns io.EOF if the stream ended before
+// the next message started.
+func readMessage(r io.Reader, maxSize int) ([]byte, error) {
+	header := make([]byte, FRAME_HEADER_SIZE)
+	if _, err := io.ReadFull(r, header); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, NewStatus(CODE_INTERNAL, "grpc: unable to read the message header: "+err.Error())
+	}
+
+	if header[0] != 0 {
+		return nil, NewStatus(CODE_UNIMPLEMENTED, "grpc: compressed messages are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if int64(length) > int64(maxSize) {
+		return nil, NewStatus(CODE_RESOURCE_EXHAUSTED, fmt.Sprintf("grpc: received message larger than max (%v vs. %v)", length, maxSize))
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, NewStatus(CODE_INTERNAL, "grpc: unable to read the message: "+err.Error())
+	}
+
+	return data, nil
+}
+
+func writeMessage(w io.Writer, data []byte) error {
+	frame := make([]byte, FRAME_HEADER_SIZE+len(data))
+	binary.BigEndian.PutUint32(frame[1:FRAME_HEADER_SIZE], uint32(len(data)))
+	copy(frame[FRAME_HEADER_SIZE:], data)
+
+	_, err := w.Write(frame)
+	return err
+}
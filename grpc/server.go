@@ -0,0 +1,409 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+)
+
+const (
+	CONTENT_TYPE      = "application/grpc"
+	HANDSHAKE_TIMEOUT = 10 * time.Second
+)
+
+// Call describes a call to a method. Its context is done once the client cancels the call, its
+// deadline passes or the server stops.
+type Call struct {
+	Method     string
+	Header     http.Header
+	RemoteAddr string
+	Context    context.Context
+}
+
+// Metadata returns the value of a metadata key sent by the client, such as "authorization".
+func (c *Call) Metadata(key string) string {
+	return c.Header.Get(key)
+}
+
+type UnaryHandler func(call *Call, request proto.Message) (proto.Message, error)
+
+type StreamHandler func(call *Call, request proto.Message, stream *ServerStream) error
+
+// Method is a method that receives a single message and replies with a single message.
+type Method struct {
+	Name       string
+	NewRequest func() proto.Message
+	Handler    UnaryHandler
+}
+
+// StreamMethod is a method that receives a single message and streams back any number of
+// messages until its handler returns.
+type StreamMethod struct {
+	Name       string
+	NewRequest func() proto.Message
+	Handler    StreamHandler
+}
+
+// Service is a set of methods served under the fully qualified name of a service, such as
+// "mattermost.Platform".
+type Service struct {
+	Name    string
+	Methods []*Method
+	Streams []*StreamMethod
+}
+
+type ServerStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	mutex   sync.Mutex
+}
+
+// Send writes a message to the client right away. It's safe to call from several goroutines.
+func (s *ServerStream) Send(message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return NewStatus(CODE_INTERNAL, "grpc: error while marshaling: "+err.Error())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := writeMessage(s.w, data); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
+
+// Server serves gRPC calls over HTTP/2. Connections are served in cleartext unless TLSConfig
+// is set. Only identity encoding is supported and only unary and server streaming methods can
+// be registered.
+type Server struct {
+	TLSConfig      *tls.Config
+	MaxMessageSize int
+
+	methods map[string]*Method
+	streams map[string]*StreamMethod
+	http2   *http2.Server
+
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	stopped  bool
+}
+
+func NewServer() *Server {
+	return &Server{
+		MaxMessageSize: DEFAULT_MAX_MESSAGE_SIZE,
+		methods:        make(map[string]*Method),
+		streams:        make(map[string]*StreamMethod),
+		http2:          &http2.Server{},
+		conns:          make(map[net.Conn]bool),
+	}
+}
+
+// Register adds the methods of a service to the server. It must be called before the server
+// starts serving.
+func (s *Server) Register(service *Service) {
+	for _, method := range service.Methods {
+		s.methods["/"+service.Name+"/"+method.Name] = method
+	}
+
+	for _, stream := range service.Streams {
+		s.streams["/"+service.Name+"/"+stream.Name] = stream
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves connections until the server is stopped.
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	if s.TLSConfig != nil {
+		config := s.TLSConfig.Clone()
+		config.NextProtos = []string{http2.NextProtoTLS}
+		listener = tls.NewListener(listener, config)
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve serves the connections of the listener until the server is stopped, in which case it
+// returns nil.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		listener.Close()
+		return nil
+	}
+	s.listener = listener
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			stopped := s.stopped
+			s.mutex.Unlock()
+
+			if stopped {
+				return nil
+			}
+
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+
+			return err
+		}
+
+		go s.serveConn(conn)
+	}
+}
+
+// Stop closes the listener and every connection, which cancels the calls in progress.
+func (s *Server) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stopped = true
+
+	if s.listener != nil {
+		s.listener.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *Server) trackConn(conn net.Conn, add bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if add {
+		if s.stopped {
+			return false
+		}
+
+		s.conns[conn] = true
+	} else {
+		delete(s.conns, conn)
+	}
+
+	return true
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	if !s.trackConn(conn, true) {
+		return
+	}
+	defer s.trackConn(conn, false)
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		tlsConn.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+		if err := tlsConn.Handshake(); err != nil {
+			l4g.Debug(fmt.Sprintf("grpc: TLS handshake failed for ip=%v err=%v", conn.RemoteAddr(), err.Error()))
+			return
+		}
+		tlsConn.SetDeadline(time.Time{})
+	}
+
+	s.http2.ServeConn(conn, &http2.ServeConnOpts{Handler: s})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "gRPC requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != CONTENT_TYPE && !strings.HasPrefix(contentType, CONTENT_TYPE+"+") && !strings.HasPrefix(contentType, CONTENT_TYPE+";") {
+		http.Error(w, "gRPC requests must have the application/grpc content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w = &responseWriter{ResponseWriter: w}
+	w.Header().Set("Content-Type", CONTENT_TYPE)
+
+	call := &Call{
+		Method:     r.URL.Path,
+		Header:     r.Header,
+		RemoteAddr: r.RemoteAddr,
+		Context:    r.Context(),
+	}
+
+	if timeout := r.Header.Get("Grpc-Timeout"); len(timeout) > 0 {
+		duration, ok := parseTimeout(timeout)
+		if !ok {
+			writeStatus(w, NewStatus(CODE_INTERNAL, "grpc: malformed grpc-timeout: "+timeout))
+			return
+		}
+
+		var cancel context.CancelFunc
+		call.Context, cancel = context.WithTimeout(call.Context, duration)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			l4g.Error(fmt.Sprintf("grpc: recovering from a panic in method=%v: %v", call.Method, r))
+			l4g.Error(string(debug.Stack()))
+			writeStatus(w, NewStatus(CODE_INTERNAL, "grpc: the method failed unexpectedly"))
+		}
+	}()
+
+	if method, ok := s.methods[call.Method]; ok {
+		writeStatus(w, s.serveUnary(call, method, w, r))
+	} else if stream, ok := s.streams[call.Method]; ok {
+		writeStatus(w, s.serveStream(call, stream, w, r))
+	} else {
+		writeStatus(w, NewStatus(CODE_UNIMPLEMENTED, "grpc: unknown method "+call.Method))
+	}
+}
+
+func (s *Server) readRequest(r *http.Request, request proto.Message) *Status {
+	data, err := readMessage(r.Body, s.MaxMessageSize)
+	if err == io.EOF {
+		return NewStatus(CODE_INTERNAL, "grpc: the request has no message")
+	} else if err != nil {
+		return statusFromError(err)
+	}
+
+	if err := proto.Unmarshal(data, request); err != nil {
+		return NewStatus(CODE_INTERNAL, "grpc: error unmarshalling the request: "+err.Error())
+	}
+
+	return nil
+}
+
+func (s *Server) serveUnary(call *Call, method *Method, w http.ResponseWriter, r *http.Request) *Status {
+	request := method.NewRequest()
+	if status := s.readRequest(r, request); status != nil {
+		return status
+	}
+
+	response, err := method.Handler(call, request)
+	if err != nil {
+		return statusFromError(err)
+	}
+
+	data, err := proto.Marshal(response)
+	if err != nil {
+		return NewStatus(CODE_INTERNAL, "grpc: error while marshaling: "+err.Error())
+	}
+
+	if err := writeMessage(w, data); err != nil {
+		return NewStatus(CODE_UNAVAILABLE, err.Error())
+	}
+
+	return statusFromError(nil)
+}
+
+func (s *Server) serveStream(call *Call, method *StreamMethod, w http.ResponseWriter, r *http.Request) *Status {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return NewStatus(CODE_INTERNAL, "grpc: streaming is not supported by the connection")
+	}
+
+	request := method.NewRequest()
+	if status := s.readRequest(r, request); status != nil {
+		return status
+	}
+
+	// Send the headers right away so that the client knows the call was accepted
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return statusFromError(method.Handler(call, request, &ServerStream{w: w, flusher: flusher}))
+}
+
+// responseWriter keeps track of whether the headers of a response were sent.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// writeStatus ends a call by setting its grpc-status and grpc-message trailers. Calls that end
+// before anything was sent get them as headers instead, which is a trailers-only response.
+func writeStatus(w http.ResponseWriter, status *Status) {
+	prefix := http.TrailerPrefix
+	if rw, ok := w.(*responseWriter); ok && !rw.wroteHeader {
+		prefix = ""
+	}
+
+	w.Header().Set(prefix+"Grpc-Status", strconv.FormatUint(uint64(status.Code), 10))
+
+	if len(status.Message) > 0 {
+		w.Header().Set(prefix+"Grpc-Message", encodeStatusMessage(status.Message))
+	}
+}
+
+// parseTimeout parses the value of a grpc-timeout header, such as "100m" for 100 milliseconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, false
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, false
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+
+	return time.Duration(amount) * unit, true
+}
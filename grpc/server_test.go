@@ -0,0 +1,230 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+)
+
+type testMessage struct {
+	Text  string `protobuf:"bytes,1,opt,name=text" json:"text,omitempty"`
+	Count int64  `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *testMessage) Reset()         { *m = testMessage{} }
+func (m *testMessage) String() string { return proto.CompactTextString(m) }
+func (*testMessage) ProtoMessage()    {}
+
+func newTestMessage() proto.Message {
+	return &testMessage{}
+}
+
+func startTestServer(t *testing.T) (*Server, string) {
+	server := NewServer()
+	server.Register(&Service{
+		Name: "test.Echo",
+		Methods: []*Method{
+			{
+				Name:       "Echo",
+				NewRequest: newTestMessage,
+				Handler: func(call *Call, request proto.Message) (proto.Message, error) {
+					if call.Metadata("authorization") != "Bearer token" {
+						return nil, NewStatus(CODE_UNAUTHENTICATED, "invalid token")
+					}
+
+					return &testMessage{Text: request.(*testMessage).Text, Count: request.(*testMessage).Count + 1}, nil
+				},
+			},
+			{
+				Name:       "Panic",
+				NewRequest: newTestMessage,
+				Handler: func(call *Call, request proto.Message) (proto.Message, error) {
+					panic("oops")
+				},
+			},
+		},
+		Streams: []*StreamMethod{
+			{
+				Name:       "Repeat",
+				NewRequest: newTestMessage,
+				Handler: func(call *Call, request proto.Message, stream *ServerStream) error {
+					for i := int64(0); i < request.(*testMessage).Count; i++ {
+						if err := stream.Send(&testMessage{Text: request.(*testMessage).Text, Count: i}); err != nil {
+							return err
+						}
+					}
+
+					return NewStatus(CODE_ABORTED, "done repeating 100%")
+				},
+			},
+			{
+				Name:       "Wait",
+				NewRequest: newTestMessage,
+				Handler: func(call *Call, request proto.Message, stream *ServerStream) error {
+					<-call.Context.Done()
+					return NewStatus(CODE_DEADLINE_EXCEEDED, call.Context.Err().Error())
+				},
+			},
+		},
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	return server, "http://" + listener.Addr().String()
+}
+
+func testCall(t *testing.T, url string, method string, request proto.Message, header http.Header) ([]*testMessage, string, string) {
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	data, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	writeMessage(&body, data)
+
+	r, _ := http.NewRequest("POST", url+method, &body)
+	r.Header.Set("Content-Type", "application/grpc")
+	for key, values := range header {
+		r.Header[key] = values
+	}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("should have returned 200, got", resp.StatusCode)
+	}
+
+	var messages []*testMessage
+	for {
+		data, err := readMessage(resp.Body, DEFAULT_MAX_MESSAGE_SIZE)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		message := &testMessage{}
+		if err := proto.Unmarshal(data, message); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+
+	ioutil.ReadAll(resp.Body)
+
+	// Calls that fail before sending anything have their status in the headers
+	if status := resp.Header.Get("Grpc-Status"); len(status) > 0 {
+		return messages, status, resp.Header.Get("Grpc-Message")
+	}
+
+	return messages, resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+func TestServerUnary(t *testing.T) {
+	server, url := startTestServer(t)
+	defer server.Stop()
+
+	header := http.Header{"Authorization": []string{"Bearer token"}}
+
+	messages, status, _ := testCall(t, url, "/test.Echo/Echo", &testMessage{Text: "hello", Count: 41}, header)
+	if status != "0" {
+		t.Fatal("should have succeeded, got status", status)
+	} else if len(messages) != 1 || messages[0].Text != "hello" || messages[0].Count != 42 {
+		t.Fatal("should have echoed the request", messages)
+	}
+
+	messages, status, message := testCall(t, url, "/test.Echo/Echo", &testMessage{Text: "hello"}, nil)
+	if status != "16" || message != "invalid token" || len(messages) != 0 {
+		t.Fatal("should have returned the status of the handler", status, message)
+	}
+
+	if _, status, _ := testCall(t, url, "/test.Echo/Missing", &testMessage{}, header); status != "12" {
+		t.Fatal("should have returned unimplemented for an unknown method, got", status)
+	}
+
+	if _, status, _ := testCall(t, url, "/test.Echo/Panic", &testMessage{}, header); status != "13" {
+		t.Fatal("should have recovered from the panic, got", status)
+	}
+}
+
+func TestServerStream(t *testing.T) {
+	server, url := startTestServer(t)
+	defer server.Stop()
+
+	messages, status, message := testCall(t, url, "/test.Echo/Repeat", &testMessage{Text: "again", Count: 3}, nil)
+	if len(messages) != 3 {
+		t.Fatal("should have streamed 3 messages", messages)
+	}
+
+	for i, message := range messages {
+		if message.Text != "again" || message.Count != int64(i) {
+			t.Fatal("should have streamed the messages in order", messages)
+		}
+	}
+
+	if status != "10" || message != "done repeating 100%25" {
+		t.Fatal("should have returned the encoded status of the handler", status, message)
+	}
+
+	start := time.Now()
+	if _, status, _ := testCall(t, url, "/test.Echo/Wait", &testMessage{}, http.Header{"Grpc-Timeout": []string{"50m"}}); status != "4" {
+		t.Fatal("should have ended the call at its deadline, got", status)
+	} else if time.Since(start) > 5*time.Second {
+		t.Fatal("should have honoured the timeout")
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	if duration, ok := parseTimeout("100m"); !ok || duration != 100*time.Millisecond {
+		t.Fatal("should have parsed milliseconds", duration)
+	}
+
+	if duration, ok := parseTimeout("2H"); !ok || duration != 2*time.Hour {
+		t.Fatal("should have parsed hours", duration)
+	}
+
+	for _, value := range []string{"", "S", "10", "10x", "-1S", "123456789S"} {
+		if _, ok := parseTimeout(value); ok {
+			t.Fatal("shouldn't have parsed", value)
+		}
+	}
+}
+
+func TestCodeFromHttpStatus(t *testing.T) {
+	if code := CodeFromHttpStatus(http.StatusForbidden); code != CODE_PERMISSION_DENIED {
+		t.Fatal("should have mapped 403 to permission denied", code)
+	}
+
+	if code := CodeFromHttpStatus(http.StatusTeapot); code != CODE_INTERNAL {
+		t.Fatal("should have mapped an unknown status to internal", code)
+	}
+}
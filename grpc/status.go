@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpc
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+)
+
+type Code uint32
+
+const (
+	CODE_OK                  Code = 0
+	CODE_CANCELED            Code = 1
+	CODE_UNKNOWN             Code = 2
+	CODE_INVALID_ARGUMENT    Code = 3
+	CODE_DEADLINE_EXCEEDED   Code = 4
+	CODE_NOT_FOUND           Code = 5
+	CODE_ALREADY_EXISTS      Code = 6
+	CODE_PERMISSION_DENIED   Code = 7
+	CODE_RESOURCE_EXHAUSTED  Code = 8
+	CODE_FAILED_PRECONDITION Code = 9
+	CODE_ABORTED             Code = 10
+	CODE_OUT_OF_RANGE        Code = 11
+	CODE_UNIMPLEMENTED       Code = 12
+	CODE_INTERNAL            Code = 13
+	CODE_UNAVAILABLE         Code = 14
+	CODE_DATA_LOSS           Code = 15
+	CODE_UNAUTHENTICATED     Code = 16
+)
+
+// Status ends a call with the given code and message when it's returned by a method. Any other
+// error ends the call with CODE_UNKNOWN.
+type Status struct {
+	Code    Code
+	Message string
+}
+
+func NewStatus(code Code, message string) *Status {
+	return &Status{Code: code, Message: message}
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("grpc: code=%v message=%v", s.Code, s.Message)
+}
+
+func statusFromError(err error) *Status {
+	if err == nil {
+		return NewStatus(CODE_OK, "")
+	}
+
+	if status, ok := err.(*Status); ok {
+		return status
+	}
+
+	return NewStatus(CODE_UNKNOWN, err.Error())
+}
+
+// CodeFromHttpStatus returns the code that is the closest to an HTTP status code, so that errors
+// which carry one can be reported to gRPC clients.
+func CodeFromHttpStatus(status int) Code {
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return CODE_OK
+	case http.StatusBadRequest:
+		return CODE_INVALID_ARGUMENT
+	case http.StatusUnauthorized:
+		return CODE_UNAUTHENTICATED
+	case http.StatusForbidden:
+		return CODE_PERMISSION_DENIED
+	case http.StatusNotFound:
+		return CODE_NOT_FOUND
+	case http.StatusConflict:
+		return CODE_ALREADY_EXISTS
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return CODE_RESOURCE_EXHAUSTED
+	case http.StatusNotImplemented:
+		return CODE_UNIMPLEMENTED
+	case http.StatusServiceUnavailable:
+		return CODE_UNAVAILABLE
+	case http.StatusGatewayTimeout:
+		return CODE_DEADLINE_EXCEEDED
+	}
+
+	return CODE_INTERNAL
+}
+
+// encodeStatusMessage percent-encodes a status message as required for the grpc-message trailer.
+func encodeStatusMessage(message string) string {
+	var encoded bytes.Buffer
+
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		} else {
+			encoded.WriteByte(c)
+		}
+	}
+
+	return encoded.String()
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpcapi
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/golang/protobuf/proto"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/grpc"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SERVICE_NAME = "mattermost.Platform"
+)
+
+var server *grpc.Server
+
+// NewPlatformService returns the service described by mattermost.proto.
+func NewPlatformService() *grpc.Service {
+	return &grpc.Service{
+		Name: SERVICE_NAME,
+		Methods: []*grpc.Method{
+			{
+				Name:       "CreatePost",
+				NewRequest: func() proto.Message { return &CreatePostRequest{} },
+				Handler:    createPost,
+			},
+			{
+				Name:       "GetUser",
+				NewRequest: func() proto.Message { return &GetUserRequest{} },
+				Handler:    getUser,
+			},
+			{
+				Name:       "GetUsersByIds",
+				NewRequest: func() proto.Message { return &GetUsersByIdsRequest{} },
+				Handler:    getUsersByIds,
+			},
+			{
+				Name:       "GetChannel",
+				NewRequest: func() proto.Message { return &GetChannelRequest{} },
+				Handler:    getChannel,
+			},
+		},
+		Streams: []*grpc.StreamMethod{
+			{
+				Name:       "StreamEvents",
+				NewRequest: func() proto.Message { return &StreamEventsRequest{} },
+				Handler:    streamEvents,
+			},
+		},
+	}
+}
+
+// StartServer starts serving the gRPC API on its own port if it's enabled. It's served over TLS
+// with the server's certificate files when the connection security is TLS.
+func StartServer() {
+	if !*utils.Cfg.ServiceSettings.EnableGrpcServer {
+		return
+	}
+
+	s := grpc.NewServer()
+	s.Register(NewPlatformService())
+
+	if *utils.Cfg.ServiceSettings.ConnectionSecurity == model.CONN_SECURITY_TLS {
+		cert, err := tls.LoadX509KeyPair(*utils.Cfg.ServiceSettings.TLSCertFile, *utils.Cfg.ServiceSettings.TLSKeyFile)
+		if err != nil {
+			l4g.Critical(utils.T("api.grpc.start_server.tls.critical"), err)
+			return
+		}
+
+		s.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	server = s
+
+	addr := *utils.Cfg.ServiceSettings.GrpcListenAddress
+	l4g.Info(utils.T("api.grpc.start_server.listening.info"), addr)
+
+	go func() {
+		if err := s.ListenAndServe(addr); err != nil {
+			l4g.Critical(utils.T("api.grpc.start_server.starting.critical"), err)
+		}
+	}()
+}
+
+// StopServer stops the gRPC server, if it was started, and ends the calls in progress. It must
+// be called before the hubs are stopped since event streams are registered with them.
+func StopServer() {
+	if server == nil {
+		return
+	}
+
+	l4g.Info(utils.T("api.grpc.stop_server.stopping.info"))
+
+	server.Stop()
+	server = nil
+}
+
+// getSession returns the session that a call was made with. Its token is sent as authorization
+// metadata in the same format as the Authorization header of the REST API. Like the REST API, calls
+// are rate limited, users that have to set up MFA can't use their session yet and OAuth tokens are
+// limited to their scope, for which isWrite tells whether the method can change anything.
+func getSession(call *grpc.Call, isWrite bool) (*model.Session, *model.AppError) {
+	token := ""
+
+	authHeader := call.Metadata(model.HEADER_AUTH)
+	if len(authHeader) > 6 && strings.ToUpper(authHeader[0:6]) == model.HEADER_BEARER {
+		token = authHeader[7:]
+	} else if len(authHeader) > 5 && strings.ToLower(authHeader[0:5]) == model.HEADER_TOKEN {
+		token = authHeader[6:]
+	}
+
+	if len(token) == 0 {
+		return nil, model.NewAppError("getSession", "api.grpc.session.missing_token.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	session, err := app.GetSession(token)
+	if err != nil {
+		l4g.Error(utils.T("api.context.invalid_session.error"), err.Error())
+		return nil, model.NewAppError("getSession", "api.context.session_expired.app_error", nil, "token="+token, http.StatusUnauthorized)
+	}
+
+	if err := app.CheckEndpointRateLimitForClass(app.RATE_LIMIT_CLASS_API, session, getIpAddress(call)); err != nil {
+		return nil, err
+	}
+
+	if err := app.CheckSessionMfa(session); err != nil {
+		return nil, err
+	}
+
+	if err := app.CheckSessionOAuthScope(session, isWrite, ""); err != nil {
+		return nil, err
+	}
+
+	app.UpdateUserAccessTokenLastUsedIfNeeded(session)
+
+	return session, nil
+}
+
+// getIpAddress returns the address of the client that made a call, the same way as for requests
+// to the REST API.
+func getIpAddress(call *grpc.Call) string {
+	return utils.GetIpAddress(&http.Request{Header: call.Header, RemoteAddr: call.RemoteAddr})
+}
+
+func isSystemAdmin(session *model.Session) bool {
+	return app.SessionHasPermissionTo(*session, model.PERMISSION_MANAGE_SYSTEM)
+}
+
+func newInvalidParamError(where string, parameter string) *model.AppError {
+	return model.NewAppError(where, "api.context.invalid_param.app_error", map[string]interface{}{"Name": parameter}, "", http.StatusBadRequest)
+}
+
+func newPermissionError(where string, session *model.Session, permission *model.Permission) *model.AppError {
+	return model.NewAppError(where, "api.context.permissions.app_error", nil, "userId="+session.UserId+", "+"permission="+permission.Id, http.StatusForbidden)
+}
+
+// statusFromAppError returns the status that a call ends with when it fails with an error, using
+// the translation of the error in the server's language as its message.
+func statusFromAppError(err *model.AppError) *grpc.Status {
+	err.Translate(utils.T)
+
+	if err.StatusCode >= http.StatusInternalServerError {
+		l4g.Error(utils.T("api.grpc.call.error"), err.Where, err.Message, err.DetailedError)
+	}
+
+	return grpc.NewStatus(grpc.CodeFromHttpStatus(err.StatusCode), err.Message)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpcapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/grpc"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func newTestCall(session *model.Session) *grpc.Call {
+	header := http.Header{}
+	header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+session.Token)
+
+	return &grpc.Call{Method: "/" + SERVICE_NAME + "/GetChannel", Header: header, RemoteAddr: "10.0.0.1:1234"}
+}
+
+func newTestSession(scope string) *model.Session {
+	session := &model.Session{Id: model.NewId(), Token: model.NewId(), UserId: model.NewId(), IsOAuth: len(scope) > 0}
+	session.SetExpireInDays(1)
+	if len(scope) > 0 {
+		session.AddProp(model.SESSION_PROP_OAUTH_SCOPE, scope)
+	}
+
+	app.AddSessionToCache(session)
+
+	return session
+}
+
+func TestGetSession(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	t.Run("WithoutToken", func(t *testing.T) {
+		if _, err := getSession(&grpc.Call{Header: http.Header{}}, false); err == nil || err.StatusCode != http.StatusUnauthorized {
+			t.Fatal("should require a token")
+		}
+	})
+
+	t.Run("WithReadScope", func(t *testing.T) {
+		call := newTestCall(newTestSession(model.OAUTH_SCOPE_READ))
+
+		if _, err := getSession(call, false); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := getSession(call, true); err == nil || err.StatusCode != http.StatusForbidden {
+			t.Fatal("should not be allowed to write with a read scope")
+		}
+	})
+
+	t.Run("WithUserInfoScope", func(t *testing.T) {
+		call := newTestCall(newTestSession(model.OAUTH_SCOPE_OPENID + " " + model.OAUTH_SCOPE_PROFILE))
+
+		if _, err := getSession(call, false); err == nil || err.StatusCode != http.StatusForbidden {
+			t.Fatal("should only be allowed to read the user info")
+		}
+	})
+
+	t.Run("WithRateLimits", func(t *testing.T) {
+		enabled := *utils.Cfg.ServiceSettings.EnableEndpointRateLimits
+		maxBurst := *utils.Cfg.ServiceSettings.ApiRateLimitMaxBurst
+		defer func() {
+			*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = enabled
+			*utils.Cfg.ServiceSettings.ApiRateLimitMaxBurst = maxBurst
+			app.InitEndpointRateLimiters()
+		}()
+
+		*utils.Cfg.ServiceSettings.EnableEndpointRateLimits = true
+		*utils.Cfg.ServiceSettings.ApiRateLimitMaxBurst = 1
+		app.InitEndpointRateLimiters()
+
+		call := newTestCall(newTestSession(""))
+
+		if _, err := getSession(call, false); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := getSession(call, false); err == nil || err.StatusCode != http.StatusTooManyRequests {
+			t.Fatal("should have been rate limited")
+		}
+	})
+}
+
+func TestGetChannelWithChannelScope(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	call := newTestCall(newTestSession(model.OAUTH_SCOPE_READ + " " + model.OAUTH_SCOPE_CHANNEL_PREFIX + model.NewId()))
+
+	if _, err := getChannel(call, &GetChannelRequest{ChannelId: model.NewId()}); err == nil {
+		t.Fatal("should not be allowed to read a channel that wasn't granted")
+	} else if status, ok := err.(*grpc.Status); !ok || status.Code != grpc.CodeFromHttpStatus(http.StatusForbidden) {
+		t.Fatal("should have been denied permission", err)
+	}
+}
+
+func TestCreatePostWithReadScope(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	call := newTestCall(newTestSession(model.OAUTH_SCOPE_READ))
+	post := postToMessage(&model.Post{ChannelId: model.NewId(), Message: "hello"})
+
+	if _, err := createPost(call, &CreatePostRequest{Post: post}); err == nil {
+		t.Fatal("should not be allowed to post with a read scope")
+	} else if status, ok := err.(*grpc.Status); !ok || status.Code != grpc.CodeFromHttpStatus(http.StatusForbidden) {
+		t.Fatal("should have been denied permission", err)
+	}
+}
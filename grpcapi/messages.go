@@ -0,0 +1,244 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mattermost/platform/model"
+)
+
+// The messages of mattermost.proto. Their field numbers have to be kept in sync with it.
+
+type Post struct {
+	Id            string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	CreateAt      int64    `protobuf:"varint,2,opt,name=create_at" json:"create_at,omitempty"`
+	UpdateAt      int64    `protobuf:"varint,3,opt,name=update_at" json:"update_at,omitempty"`
+	EditAt        int64    `protobuf:"varint,4,opt,name=edit_at" json:"edit_at,omitempty"`
+	DeleteAt      int64    `protobuf:"varint,5,opt,name=delete_at" json:"delete_at,omitempty"`
+	IsPinned      bool     `protobuf:"varint,6,opt,name=is_pinned" json:"is_pinned,omitempty"`
+	UserId        string   `protobuf:"bytes,7,opt,name=user_id" json:"user_id,omitempty"`
+	ChannelId     string   `protobuf:"bytes,8,opt,name=channel_id" json:"channel_id,omitempty"`
+	RootId        string   `protobuf:"bytes,9,opt,name=root_id" json:"root_id,omitempty"`
+	ParentId      string   `protobuf:"bytes,10,opt,name=parent_id" json:"parent_id,omitempty"`
+	OriginalId    string   `protobuf:"bytes,11,opt,name=original_id" json:"original_id,omitempty"`
+	Message       string   `protobuf:"bytes,12,opt,name=message" json:"message,omitempty"`
+	Type          string   `protobuf:"bytes,13,opt,name=type" json:"type,omitempty"`
+	PropsJson     string   `protobuf:"bytes,14,opt,name=props_json" json:"props_json,omitempty"`
+	Hashtags      string   `protobuf:"bytes,15,opt,name=hashtags" json:"hashtags,omitempty"`
+	FileIds       []string `protobuf:"bytes,16,rep,name=file_ids" json:"file_ids,omitempty"`
+	PendingPostId string   `protobuf:"bytes,17,opt,name=pending_post_id" json:"pending_post_id,omitempty"`
+}
+
+func (m *Post) Reset()         { *m = Post{} }
+func (m *Post) String() string { return proto.CompactTextString(m) }
+func (*Post) ProtoMessage()    {}
+
+type User struct {
+	Id                string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	CreateAt          int64  `protobuf:"varint,2,opt,name=create_at" json:"create_at,omitempty"`
+	UpdateAt          int64  `protobuf:"varint,3,opt,name=update_at" json:"update_at,omitempty"`
+	DeleteAt          int64  `protobuf:"varint,4,opt,name=delete_at" json:"delete_at,omitempty"`
+	Username          string `protobuf:"bytes,5,opt,name=username" json:"username,omitempty"`
+	AuthService       string `protobuf:"bytes,6,opt,name=auth_service" json:"auth_service,omitempty"`
+	Email             string `protobuf:"bytes,7,opt,name=email" json:"email,omitempty"`
+	EmailVerified     bool   `protobuf:"varint,8,opt,name=email_verified" json:"email_verified,omitempty"`
+	Nickname          string `protobuf:"bytes,9,opt,name=nickname" json:"nickname,omitempty"`
+	FirstName         string `protobuf:"bytes,10,opt,name=first_name" json:"first_name,omitempty"`
+	LastName          string `protobuf:"bytes,11,opt,name=last_name" json:"last_name,omitempty"`
+	Position          string `protobuf:"bytes,12,opt,name=position" json:"position,omitempty"`
+	Roles             string `protobuf:"bytes,13,opt,name=roles" json:"roles,omitempty"`
+	Locale            string `protobuf:"bytes,14,opt,name=locale" json:"locale,omitempty"`
+	LastPictureUpdate int64  `protobuf:"varint,15,opt,name=last_picture_update" json:"last_picture_update,omitempty"`
+}
+
+func (m *User) Reset()         { *m = User{} }
+func (m *User) String() string { return proto.CompactTextString(m) }
+func (*User) ProtoMessage()    {}
+
+type UserList struct {
+	Users []*User `protobuf:"bytes,1,rep,name=users" json:"users,omitempty"`
+}
+
+func (m *UserList) Reset()         { *m = UserList{} }
+func (m *UserList) String() string { return proto.CompactTextString(m) }
+func (*UserList) ProtoMessage()    {}
+
+type Channel struct {
+	Id            string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	CreateAt      int64  `protobuf:"varint,2,opt,name=create_at" json:"create_at,omitempty"`
+	UpdateAt      int64  `protobuf:"varint,3,opt,name=update_at" json:"update_at,omitempty"`
+	DeleteAt      int64  `protobuf:"varint,4,opt,name=delete_at" json:"delete_at,omitempty"`
+	TeamId        string `protobuf:"bytes,5,opt,name=team_id" json:"team_id,omitempty"`
+	Type          string `protobuf:"bytes,6,opt,name=type" json:"type,omitempty"`
+	DisplayName   string `protobuf:"bytes,7,opt,name=display_name" json:"display_name,omitempty"`
+	Name          string `protobuf:"bytes,8,opt,name=name" json:"name,omitempty"`
+	Header        string `protobuf:"bytes,9,opt,name=header" json:"header,omitempty"`
+	Purpose       string `protobuf:"bytes,10,opt,name=purpose" json:"purpose,omitempty"`
+	LastPostAt    int64  `protobuf:"varint,11,opt,name=last_post_at" json:"last_post_at,omitempty"`
+	TotalMsgCount int64  `protobuf:"varint,12,opt,name=total_msg_count" json:"total_msg_count,omitempty"`
+	CreatorId     string `protobuf:"bytes,13,opt,name=creator_id" json:"creator_id,omitempty"`
+}
+
+func (m *Channel) Reset()         { *m = Channel{} }
+func (m *Channel) String() string { return proto.CompactTextString(m) }
+func (*Channel) ProtoMessage()    {}
+
+type Event struct {
+	Event     string `protobuf:"bytes,1,opt,name=event" json:"event,omitempty"`
+	DataJson  string `protobuf:"bytes,2,opt,name=data_json" json:"data_json,omitempty"`
+	UserId    string `protobuf:"bytes,3,opt,name=user_id" json:"user_id,omitempty"`
+	ChannelId string `protobuf:"bytes,4,opt,name=channel_id" json:"channel_id,omitempty"`
+	TeamId    string `protobuf:"bytes,5,opt,name=team_id" json:"team_id,omitempty"`
+	Seq       int64  `protobuf:"varint,6,opt,name=seq" json:"seq,omitempty"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+
+type CreatePostRequest struct {
+	Post *Post `protobuf:"bytes,1,opt,name=post" json:"post,omitempty"`
+}
+
+func (m *CreatePostRequest) Reset()         { *m = CreatePostRequest{} }
+func (m *CreatePostRequest) String() string { return proto.CompactTextString(m) }
+func (*CreatePostRequest) ProtoMessage()    {}
+
+type GetUserRequest struct {
+	UserId string `protobuf:"bytes,1,opt,name=user_id" json:"user_id,omitempty"`
+}
+
+func (m *GetUserRequest) Reset()         { *m = GetUserRequest{} }
+func (m *GetUserRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUserRequest) ProtoMessage()    {}
+
+type GetUsersByIdsRequest struct {
+	UserIds []string `protobuf:"bytes,1,rep,name=user_ids" json:"user_ids,omitempty"`
+}
+
+func (m *GetUsersByIdsRequest) Reset()         { *m = GetUsersByIdsRequest{} }
+func (m *GetUsersByIdsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUsersByIdsRequest) ProtoMessage()    {}
+
+type GetChannelRequest struct {
+	ChannelId string `protobuf:"bytes,1,opt,name=channel_id" json:"channel_id,omitempty"`
+}
+
+func (m *GetChannelRequest) Reset()         { *m = GetChannelRequest{} }
+func (m *GetChannelRequest) String() string { return proto.CompactTextString(m) }
+func (*GetChannelRequest) ProtoMessage()    {}
+
+type StreamEventsRequest struct {
+	ChannelIds []string `protobuf:"bytes,1,rep,name=channel_ids" json:"channel_ids,omitempty"`
+	Events     []string `protobuf:"bytes,2,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *StreamEventsRequest) Reset()         { *m = StreamEventsRequest{} }
+func (m *StreamEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamEventsRequest) ProtoMessage()    {}
+
+func postToMessage(post *model.Post) *Post {
+	message := &Post{
+		Id:            post.Id,
+		CreateAt:      post.CreateAt,
+		UpdateAt:      post.UpdateAt,
+		EditAt:        post.EditAt,
+		DeleteAt:      post.DeleteAt,
+		IsPinned:      post.IsPinned,
+		UserId:        post.UserId,
+		ChannelId:     post.ChannelId,
+		RootId:        post.RootId,
+		ParentId:      post.ParentId,
+		OriginalId:    post.OriginalId,
+		Message:       post.Message,
+		Type:          post.Type,
+		Hashtags:      post.Hashtags,
+		FileIds:       post.FileIds,
+		PendingPostId: post.PendingPostId,
+	}
+
+	if len(post.Props) > 0 {
+		message.PropsJson = model.StringInterfaceToJson(post.Props)
+	}
+
+	return message
+}
+
+// postFromMessage returns the post that a client asked to create, or nil if its props aren't
+// a JSON object.
+func postFromMessage(message *Post) *model.Post {
+	post := &model.Post{
+		ChannelId:     message.ChannelId,
+		RootId:        message.RootId,
+		ParentId:      message.ParentId,
+		Message:       message.Message,
+		Type:          message.Type,
+		FileIds:       message.FileIds,
+		PendingPostId: message.PendingPostId,
+	}
+
+	if len(message.PropsJson) > 0 {
+		if err := json.Unmarshal([]byte(message.PropsJson), &post.Props); err != nil || post.Props == nil {
+			return nil
+		}
+	}
+
+	return post
+}
+
+func userToMessage(user *model.User) *User {
+	return &User{
+		Id:                user.Id,
+		CreateAt:          user.CreateAt,
+		UpdateAt:          user.UpdateAt,
+		DeleteAt:          user.DeleteAt,
+		Username:          user.Username,
+		AuthService:       user.AuthService,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Nickname:          user.Nickname,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		Position:          user.Position,
+		Roles:             user.Roles,
+		Locale:            user.Locale,
+		LastPictureUpdate: user.LastPictureUpdate,
+	}
+}
+
+func channelToMessage(channel *model.Channel) *Channel {
+	return &Channel{
+		Id:            channel.Id,
+		CreateAt:      channel.CreateAt,
+		UpdateAt:      channel.UpdateAt,
+		DeleteAt:      channel.DeleteAt,
+		TeamId:        channel.TeamId,
+		Type:          channel.Type,
+		DisplayName:   channel.DisplayName,
+		Name:          channel.Name,
+		Header:        channel.Header,
+		Purpose:       channel.Purpose,
+		LastPostAt:    channel.LastPostAt,
+		TotalMsgCount: channel.TotalMsgCount,
+		CreatorId:     channel.CreatorId,
+	}
+}
+
+func eventToMessage(event *model.WebSocketEvent) *Event {
+	message := &Event{
+		Event:    event.Event,
+		DataJson: model.StringInterfaceToJson(event.Data),
+		Seq:      event.Sequence,
+	}
+
+	if event.Broadcast != nil {
+		message.UserId = event.Broadcast.UserId
+		message.ChannelId = event.Broadcast.ChannelId
+		message.TeamId = event.Broadcast.TeamId
+	}
+
+	return message
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpcapi
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostMessage(t *testing.T) {
+	post := &model.Post{
+		Id:        model.NewId(),
+		UserId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Message:   "hello",
+		Props:     model.StringInterface{"from_service": "true"},
+		FileIds:   []string{model.NewId()},
+	}
+
+	data, err := proto.Marshal(&CreatePostRequest{Post: postToMessage(post)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := &CreatePostRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		t.Fatal(err)
+	}
+
+	rpost := postFromMessage(request.Post)
+	if rpost == nil {
+		t.Fatal("should have read the post")
+	}
+
+	if len(rpost.Id) != 0 || len(rpost.UserId) != 0 {
+		t.Fatal("shouldn't have read the fields that are set by the server")
+	}
+
+	if rpost.ChannelId != post.ChannelId || rpost.Message != post.Message || rpost.Props["from_service"] != "true" || len(rpost.FileIds) != 1 || rpost.FileIds[0] != post.FileIds[0] {
+		t.Fatal("should have read the post back", rpost)
+	}
+
+	if postFromMessage(&Post{PropsJson: "[]"}) != nil {
+		t.Fatal("shouldn't have read props that aren't an object")
+	}
+
+	if message := postToMessage(&model.Post{}); len(message.PropsJson) != 0 {
+		t.Fatal("shouldn't have encoded empty props", message.PropsJson)
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	channelId := model.NewId()
+
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POSTED, "", channelId, "", nil)
+	event.Add("post", "{}")
+	event.Sequence = 7
+
+	message := eventToMessage(event)
+	if message.Event != model.WEBSOCKET_EVENT_POSTED || message.ChannelId != channelId || message.Seq != 7 || message.DataJson != `{"post":"{}"}` {
+		t.Fatal("should have converted the event", message)
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rmessage := &Event{}
+	if err := proto.Unmarshal(data, rmessage); err != nil {
+		t.Fatal(err)
+	} else if *rmessage != *message {
+		t.Fatal("should have read the event back", rmessage)
+	}
+}
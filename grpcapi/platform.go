@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package grpcapi
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/grpc"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func createPost(call *grpc.Call, request proto.Message) (proto.Message, error) {
+	session, err := getSession(call, true)
+	if err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	message := request.(*CreatePostRequest).Post
+	if message == nil {
+		return nil, statusFromAppError(newInvalidParamError("createPost", "post"))
+	}
+
+	post := postFromMessage(message)
+	if post == nil {
+		return nil, statusFromAppError(newInvalidParamError("createPost", "post.props_json"))
+	}
+
+	post.UserId = session.UserId
+
+	if !app.SessionHasPermissionToChannel(*session, post.ChannelId, model.PERMISSION_CREATE_POST) {
+		return nil, statusFromAppError(newPermissionError("createPost", session, model.PERMISSION_CREATE_POST))
+	}
+
+	if rp, err := app.CreatePostAsUser(post); err != nil {
+		return nil, statusFromAppError(err)
+	} else {
+		return postToMessage(rp), nil
+	}
+}
+
+func getUser(call *grpc.Call, request proto.Message) (proto.Message, error) {
+	session, err := getSession(call, false)
+	if err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	userId := request.(*GetUserRequest).UserId
+	if len(userId) != 26 {
+		return nil, statusFromAppError(newInvalidParamError("getUser", "user_id"))
+	}
+
+	// No permission check required
+
+	if user, err := app.GetUser(userId); err != nil {
+		return nil, statusFromAppError(err)
+	} else {
+		app.SanitizeProfile(user, isSystemAdmin(session))
+		return userToMessage(user), nil
+	}
+}
+
+func getUsersByIds(call *grpc.Call, request proto.Message) (proto.Message, error) {
+	session, err := getSession(call, false)
+	if err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	userIds := request.(*GetUsersByIdsRequest).UserIds
+	if len(userIds) == 0 {
+		return nil, statusFromAppError(newInvalidParamError("getUsersByIds", "user_ids"))
+	}
+
+	// No permission check required
+
+	if users, err := app.GetUsersByIds(userIds, isSystemAdmin(session)); err != nil {
+		return nil, statusFromAppError(err)
+	} else {
+		list := &UserList{}
+		for _, user := range users {
+			list.Users = append(list.Users, userToMessage(user))
+		}
+
+		return list, nil
+	}
+}
+
+func getChannel(call *grpc.Call, request proto.Message) (proto.Message, error) {
+	session, err := getSession(call, false)
+	if err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	channelId := request.(*GetChannelRequest).ChannelId
+	if len(channelId) != 26 {
+		return nil, statusFromAppError(newInvalidParamError("getChannel", "channel_id"))
+	}
+
+	if err := app.CheckSessionOAuthScope(session, false, channelId); err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	channel, err := app.GetChannel(channelId)
+	if err != nil {
+		return nil, statusFromAppError(err)
+	}
+
+	if channel.Type == model.CHANNEL_OPEN {
+		if !app.SessionHasPermissionToTeam(*session, channel.TeamId, model.PERMISSION_READ_PUBLIC_CHANNEL) {
+			return nil, statusFromAppError(newPermissionError("getChannel", session, model.PERMISSION_READ_PUBLIC_CHANNEL))
+		}
+	} else {
+		if !app.SessionHasPermissionToChannel(*session, channelId, model.PERMISSION_READ_CHANNEL) {
+			return nil, statusFromAppError(newPermissionError("getChannel", session, model.PERMISSION_READ_CHANNEL))
+		}
+	}
+
+	return channelToMessage(channel), nil
+}
+
+// streamEvents registers a connection for the session with its hub and streams the events that
+// it receives until the call is cancelled or the client falls too far behind.
+func streamEvents(call *grpc.Call, request proto.Message, stream *grpc.ServerStream) error {
+	session, err := getSession(call, false)
+	if err != nil {
+		return statusFromAppError(err)
+	}
+
+	filter := request.(*StreamEventsRequest)
+	if err := app.ValidateWebConnFilter(filter.ChannelIds, filter.Events); err != nil {
+		return statusFromAppError(err)
+	}
+
+	conn := app.NewEventStreamConn(session)
+	conn.RestrictTo(filter.ChannelIds, filter.Events)
+
+	app.HubRegister(conn)
+	defer app.HubUnregister(conn)
+
+	for {
+		select {
+		case msg, ok := <-conn.Send:
+			if !ok {
+				return grpc.NewStatus(grpc.CODE_UNAVAILABLE, utils.T("api.grpc.stream_events.closed.app_error"))
+			}
+
+			if event, ok := msg.(*model.WebSocketEvent); ok {
+				if err := stream.Send(eventToMessage(event)); err != nil {
+					return err
+				}
+			}
+
+		case <-call.Context.Done():
+			return grpc.NewStatus(grpc.CODE_CANCELED, call.Context.Err().Error())
+		}
+	}
+}
@@ -12,6 +12,8 @@ const (
 	ACCESS_TOKEN_GRANT_TYPE  = "authorization_code"
 	ACCESS_TOKEN_TYPE        = "bearer"
 	REFRESH_TOKEN_GRANT_TYPE = "refresh_token"
+	ACCESS_TOKEN_TYPE_HINT   = "access_token"
+	REFRESH_TOKEN_TYPE_HINT  = "refresh_token"
 )
 
 type AccessData struct {
@@ -21,6 +23,7 @@ type AccessData struct {
 	RefreshToken string `json:"refresh_token"`
 	RedirectUri  string `json:"redirect_uri"`
 	ExpiresAt    int64  `json:"expires_at"`
+	Scopes       string `json:"scopes"`
 }
 
 type AccessResponse struct {
@@ -29,6 +32,7 @@ type AccessResponse struct {
 	ExpiresIn    int32  `json:"expires_in"`
 	Scope        string `json:"scope"`
 	RefreshToken string `json:"refresh_token"`
+	IdToken      string `json:"id_token,omitempty"`
 }
 
 // IsValid validates the AccessData and returns an error if it isn't configured
@@ -55,6 +59,10 @@ func (ad *AccessData) IsValid() *AppError {
 		return NewLocAppError("AccessData.IsValid", "model.access.is_valid.redirect_uri.app_error", nil, "")
 	}
 
+	if len(ad.Scopes) > OAUTH_SCOPES_MAX_LENGTH {
+		return NewLocAppError("AccessData.IsValid", "model.access.is_valid.scopes.app_error", nil, "")
+	}
+
 	return nil
 }
 
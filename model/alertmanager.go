@@ -0,0 +1,306 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ALERTMANAGER_STATUS_FIRING   = "firing"
+	ALERTMANAGER_STATUS_RESOLVED = "resolved"
+
+	ALERTMANAGER_ACTION_SILENCE = "silence"
+	ALERTMANAGER_ACTION_ACK     = "ack"
+
+	ALERTMANAGER_DEFAULT_SILENCE_DURATION = time.Hour
+	ALERTMANAGER_MAX_SILENCE_DURATION     = 7 * 24 * time.Hour
+
+	ALERTMANAGER_COLOR_FIRING   = "#db3b21"
+	ALERTMANAGER_COLOR_RESOLVED = "#1a7f37"
+
+	POST_PROP_ALERTMANAGER_RECEIVER_ID = "alertmanager_receiver_id"
+	POST_PROP_ALERTMANAGER_GROUP_KEY   = "alertmanager_group_key"
+)
+
+// AlertmanagerReceiver is an integration that accepts Prometheus Alertmanager webhook
+// notifications for a channel and can call back to the Alertmanager API to silence them.
+type AlertmanagerReceiver struct {
+	Id              string           `json:"id"`
+	CreateAt        int64            `json:"create_at"`
+	UpdateAt        int64            `json:"update_at"`
+	DeleteAt        int64            `json:"delete_at"`
+	CreatorId       string           `json:"creator_id"`
+	ChannelId       string           `json:"channel_id"`
+	TeamId          string           `json:"team_id"`
+	DisplayName     string           `json:"display_name"`
+	AlertmanagerURL string           `json:"alertmanager_url"`
+	Credentials     EncryptStringMap `json:"credentials"`
+}
+
+// AlertmanagerPost records the post created for an Alertmanager alert group so that
+// later notifications for the same group update it instead of creating a new post.
+type AlertmanagerPost struct {
+	ReceiverId string    `json:"receiver_id"`
+	GroupKey   string    `json:"group_key"`
+	PostId     string    `json:"post_id"`
+	Status     string    `json:"status"`
+	Labels     StringMap `json:"labels"`
+	UpdateAt   int64     `json:"update_at"`
+}
+
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+// AlertmanagerMessage is the payload sent by the Alertmanager webhook receiver.
+type AlertmanagerMessage struct {
+	Version           string               `json:"version"`
+	GroupKey          string               `json:"groupKey"`
+	Status            string               `json:"status"`
+	Receiver          string               `json:"receiver"`
+	GroupLabels       map[string]string    `json:"groupLabels"`
+	CommonLabels      map[string]string    `json:"commonLabels"`
+	CommonAnnotations map[string]string    `json:"commonAnnotations"`
+	ExternalURL       string               `json:"externalURL"`
+	Alerts            []*AlertmanagerAlert `json:"alerts"`
+}
+
+type AlertmanagerSilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+}
+
+// AlertmanagerSilence is the body posted to the Alertmanager silences API.
+type AlertmanagerSilence struct {
+	Matchers  []*AlertmanagerSilenceMatcher `json:"matchers"`
+	StartsAt  time.Time                     `json:"startsAt"`
+	EndsAt    time.Time                     `json:"endsAt"`
+	CreatedBy string                        `json:"createdBy"`
+	Comment   string                        `json:"comment"`
+}
+
+func (o *AlertmanagerReceiver) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.id.app_error", nil, "")
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.update_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.creator_id.app_error", nil, "")
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.channel_id.app_error", nil, "")
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.team_id.app_error", nil, "")
+	}
+
+	if len(o.DisplayName) > 64 {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.display_name.app_error", nil, "")
+	}
+
+	if len(o.AlertmanagerURL) > 1024 || !IsValidHttpUrl(o.AlertmanagerURL) {
+		return NewLocAppError("AlertmanagerReceiver.IsValid", "model.alertmanager_receiver.url.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func (o *AlertmanagerReceiver) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Credentials == nil {
+		o.Credentials = make(EncryptStringMap)
+	}
+
+	o.AlertmanagerURL = strings.TrimRight(o.AlertmanagerURL, "/")
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *AlertmanagerReceiver) PreUpdate() {
+	o.AlertmanagerURL = strings.TrimRight(o.AlertmanagerURL, "/")
+	o.UpdateAt = GetMillis()
+}
+
+// Sanitize removes the stored password so that it is never sent back to clients.
+func (o *AlertmanagerReceiver) Sanitize() {
+	if len(o.Credentials["password"]) > 0 {
+		o.Credentials["password"] = FAKE_SETTING
+	}
+}
+
+func (o *AlertmanagerReceiver) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AlertmanagerReceiverFromJson(data io.Reader) *AlertmanagerReceiver {
+	decoder := json.NewDecoder(data)
+	var o AlertmanagerReceiver
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func AlertmanagerReceiverListToJson(l []*AlertmanagerReceiver) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AlertmanagerReceiverListFromJson(data io.Reader) []*AlertmanagerReceiver {
+	decoder := json.NewDecoder(data)
+	var o []*AlertmanagerReceiver
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func AlertmanagerMessageFromJson(data io.Reader) *AlertmanagerMessage {
+	decoder := json.NewDecoder(data)
+	var o AlertmanagerMessage
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *AlertmanagerMessage) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func (o *AlertmanagerMessage) IsValid() *AppError {
+	if len(o.GroupKey) == 0 {
+		return NewLocAppError("AlertmanagerMessage.IsValid", "model.alertmanager_message.group_key.app_error", nil, "")
+	}
+
+	if o.Status != ALERTMANAGER_STATUS_FIRING && o.Status != ALERTMANAGER_STATUS_RESOLVED {
+		return NewLocAppError("AlertmanagerMessage.IsValid", "model.alertmanager_message.status.app_error", nil, "status="+o.Status)
+	}
+
+	return nil
+}
+
+func (o *AlertmanagerMessage) IsResolved() bool {
+	return o.Status == ALERTMANAGER_STATUS_RESOLVED
+}
+
+// SilenceMatchers returns equality matchers for the labels that the group was formed on,
+// falling back to the labels common to every alert in the group.
+func (o *AlertmanagerMessage) SilenceMatchers() []*AlertmanagerSilenceMatcher {
+	labels := o.GroupLabels
+	if len(labels) == 0 {
+		labels = o.CommonLabels
+	}
+
+	return AlertmanagerMatchersFromLabels(labels)
+}
+
+func AlertmanagerMatchersFromLabels(labels map[string]string) []*AlertmanagerSilenceMatcher {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matchers := make([]*AlertmanagerSilenceMatcher, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, &AlertmanagerSilenceMatcher{Name: name, Value: labels[name]})
+	}
+
+	return matchers
+}
+
+// ToAttachment builds the Slack-style attachment shown for the alert group, including the
+// silence and acknowledge buttons while the group is still firing.
+func (o *AlertmanagerMessage) ToAttachment() *SlackAttachment {
+	attachment := &SlackAttachment{
+		Title:     fmt.Sprintf("[%s:%d] %s", strings.ToUpper(o.Status), len(o.Alerts), o.CommonLabels["alertname"]),
+		TitleLink: o.ExternalURL,
+		Fields:    []*SlackAttachmentField{},
+	}
+
+	if o.IsResolved() {
+		attachment.Color = ALERTMANAGER_COLOR_RESOLVED
+	} else {
+		attachment.Color = ALERTMANAGER_COLOR_FIRING
+		attachment.Actions = []*PostAction{
+			{Id: ALERTMANAGER_ACTION_SILENCE, Name: "Silence"},
+			{Id: ALERTMANAGER_ACTION_ACK, Name: "Acknowledge"},
+		}
+	}
+
+	text := []string{}
+	for _, alert := range o.Alerts {
+		line := "- " + alert.Labels["alertname"]
+		if summary := alert.Annotations["summary"]; len(summary) > 0 {
+			line += ": " + summary
+		} else if description := alert.Annotations["description"]; len(description) > 0 {
+			line += ": " + description
+		}
+		text = append(text, line)
+	}
+	attachment.Text = strings.Join(text, "\n")
+	attachment.Fallback = attachment.Title
+
+	for _, matcher := range o.SilenceMatchers() {
+		attachment.Fields = append(attachment.Fields, &SlackAttachmentField{Title: matcher.Name, Value: matcher.Value, Short: true})
+	}
+
+	return attachment
+}
+
+func (o *AlertmanagerSilence) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlertmanagerReceiverJson(t *testing.T) {
+	o := AlertmanagerReceiver{Id: NewId(), Credentials: EncryptStringMap{"username": "admin", "password": "secret"}}
+	json := o.ToJson()
+	ro := AlertmanagerReceiverFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id {
+		t.Fatal("Ids do not match")
+	}
+
+	if ro.Credentials["password"] != "secret" {
+		t.Fatal("credentials do not match")
+	}
+}
+
+func TestAlertmanagerReceiverIsValid(t *testing.T) {
+	o := AlertmanagerReceiver{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	o.CreateAt = GetMillis()
+	o.UpdateAt = GetMillis()
+	o.CreatorId = NewId()
+	o.ChannelId = NewId()
+	o.TeamId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a url")
+	}
+
+	o.AlertmanagerURL = "ftp://alertmanager"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AlertmanagerURL = "http://alertmanager:9093"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.DisplayName = strings.Repeat("a", 65)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestAlertmanagerReceiverPreSaveAndSanitize(t *testing.T) {
+	o := AlertmanagerReceiver{AlertmanagerURL: "http://alertmanager:9093/"}
+	o.PreSave()
+
+	if o.AlertmanagerURL != "http://alertmanager:9093" {
+		t.Fatal("trailing slash should have been trimmed")
+	}
+
+	if o.Credentials == nil {
+		t.Fatal("credentials should be set")
+	}
+
+	o.Credentials["password"] = "secret"
+	o.Sanitize()
+	if o.Credentials["password"] != FAKE_SETTING {
+		t.Fatal("password should have been sanitized")
+	}
+}
+
+func TestAlertmanagerMessage(t *testing.T) {
+	data := `{
+		"version": "4",
+		"groupKey": "{}:{alertname=\"HighLatency\"}",
+		"status": "firing",
+		"receiver": "mattermost",
+		"groupLabels": {"alertname": "HighLatency", "service": "api"},
+		"commonLabels": {"alertname": "HighLatency", "service": "api", "severity": "page"},
+		"commonAnnotations": {},
+		"externalURL": "http://alertmanager:9093",
+		"alerts": [
+			{"status": "firing", "labels": {"alertname": "HighLatency", "instance": "a"}, "annotations": {"summary": "Latency is high"}}
+		]
+	}`
+
+	msg := AlertmanagerMessageFromJson(strings.NewReader(data))
+	if msg == nil {
+		t.Fatal("should have parsed")
+	}
+
+	if err := msg.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	matchers := msg.SilenceMatchers()
+	if len(matchers) != 2 || matchers[0].Name != "alertname" || matchers[1].Name != "service" || matchers[1].Value != "api" {
+		t.Fatal("matchers should come from the group labels in sorted order")
+	}
+
+	attachment := msg.ToAttachment()
+	if attachment.Title != "[FIRING:1] HighLatency" {
+		t.Fatal("bad title " + attachment.Title)
+	}
+
+	if attachment.Color != ALERTMANAGER_COLOR_FIRING || len(attachment.Actions) != 2 {
+		t.Fatal("firing alerts should be red with actions")
+	}
+
+	if !strings.Contains(attachment.Text, "Latency is high") {
+		t.Fatal("text should include the alert summary")
+	}
+
+	msg.Status = ALERTMANAGER_STATUS_RESOLVED
+	attachment = msg.ToAttachment()
+	if attachment.Color != ALERTMANAGER_COLOR_RESOLVED || len(attachment.Actions) != 0 {
+		t.Fatal("resolved alerts should be green without actions")
+	}
+
+	msg.GroupLabels = nil
+	if len(msg.SilenceMatchers()) != 3 {
+		t.Fatal("should fall back to the common labels")
+	}
+
+	msg.Status = "pending"
+	if err := msg.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	msg.Status = ALERTMANAGER_STATUS_FIRING
+	msg.GroupKey = ""
+	if err := msg.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestPostAttachments(t *testing.T) {
+	p := &Post{}
+	p.AddProp("attachments", []*SlackAttachment{{Text: "hello", Actions: []*PostAction{{Id: "ack", Name: "Ack"}}}})
+
+	if attachments := p.Attachments(); len(attachments) != 1 || attachments[0].Text != "hello" {
+		t.Fatal("should return the attachments")
+	}
+
+	rp := PostFromJson(strings.NewReader(p.ToJson()))
+	if attachments := rp.Attachments(); len(attachments) != 1 || attachments[0].Actions[0].Id != "ack" {
+		t.Fatal("should decode the attachments")
+	}
+}
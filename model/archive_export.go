@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	ARCHIVE_EXPORT_TYPE_CHANNEL = "channel"
+	ARCHIVE_EXPORT_TYPE_TEAM    = "team"
+
+	ARCHIVE_EXPORT_STATUS_PENDING = "pending"
+	ARCHIVE_EXPORT_STATUS_RUNNING = "running"
+	ARCHIVE_EXPORT_STATUS_SUCCESS = "success"
+	ARCHIVE_EXPORT_STATUS_FAILED  = "failed"
+
+	ARCHIVE_EXPORT_ERROR_MAX_RUNES = 1024
+)
+
+// ArchiveExport is a zip archive of a channel, or of every channel of a team, that an admin asked
+// for. The archive is generated in the background and Progress is the percentage of it that has
+// been written so far. It can be downloaded once its status is success.
+type ArchiveExport struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	TeamId    string `json:"team_id"`
+	ChannelId string `json:"channel_id"`
+	UserId    string `json:"user_id"`
+	Status    string `json:"status"`
+	Progress  int    `json:"progress"`
+	Error     string `json:"error"`
+	Path      string `json:"-"`
+	Size      int64  `json:"size"`
+	CreateAt  int64  `json:"create_at"`
+	UpdateAt  int64  `json:"update_at"`
+	StartAt   int64  `json:"start_at"`
+	EndAt     int64  `json:"end_at"`
+}
+
+// ArchiveExportMembershipChange is a line of the membership history of an archive, which is read
+// from the system messages posted when users join, leave, are added to or removed from a channel.
+// Username is the user whose membership changed and ActorId the user who posted the message.
+type ArchiveExportMembershipChange struct {
+	ChannelId string `json:"channel_id"`
+	Type      string `json:"type"`
+	Username  string `json:"username"`
+	ActorId   string `json:"actor_id"`
+	PostId    string `json:"post_id"`
+	At        int64  `json:"at"`
+}
+
+func (o *ArchiveExport) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	switch o.Type {
+	case ARCHIVE_EXPORT_TYPE_CHANNEL:
+		if len(o.ChannelId) != 26 {
+			return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+
+		// Direct and group messages don't belong to a team
+		if !(len(o.TeamId) == 26 || len(o.TeamId) == 0) {
+			return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	case ARCHIVE_EXPORT_TYPE_TEAM:
+		if len(o.TeamId) != 26 {
+			return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+
+		if len(o.ChannelId) != 0 {
+			return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.type.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(o.Status == ARCHIVE_EXPORT_STATUS_PENDING || o.Status == ARCHIVE_EXPORT_STATUS_RUNNING ||
+		o.Status == ARCHIVE_EXPORT_STATUS_SUCCESS || o.Status == ARCHIVE_EXPORT_STATUS_FAILED) {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.Progress < 0 || o.Progress > 100 {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.progress.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Error) > ARCHIVE_EXPORT_ERROR_MAX_RUNES {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.error.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("ArchiveExport.IsValid", "model.archive_export.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ArchiveExport) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.Status = ARCHIVE_EXPORT_STATUS_PENDING
+	o.Progress = 0
+	o.Error = ""
+	o.Path = "exports/" + o.Id + ".zip"
+	o.Size = 0
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.StartAt = 0
+	o.EndAt = 0
+}
+
+func (o *ArchiveExport) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if utf8.RuneCountInString(o.Error) > ARCHIVE_EXPORT_ERROR_MAX_RUNES {
+		runes := []rune(o.Error)
+		o.Error = string(runes[:ARCHIVE_EXPORT_ERROR_MAX_RUNES])
+	}
+}
+
+// FileName returns the name that the archive is downloaded as.
+func (o *ArchiveExport) FileName() string {
+	if o.Type == ARCHIVE_EXPORT_TYPE_CHANNEL {
+		return "channel-" + o.ChannelId + "-" + o.Id + ".zip"
+	}
+
+	return "team-" + o.TeamId + "-" + o.Id + ".zip"
+}
+
+func (o *ArchiveExport) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ArchiveExportFromJson(data io.Reader) *ArchiveExport {
+	decoder := json.NewDecoder(data)
+	var o ArchiveExport
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ArchiveExportListToJson(l []*ArchiveExport) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ArchiveExportListFromJson(data io.Reader) []*ArchiveExport {
+	decoder := json.NewDecoder(data)
+	var o []*ArchiveExport
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
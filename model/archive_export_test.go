@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArchiveExportJson(t *testing.T) {
+	o := ArchiveExport{Type: ARCHIVE_EXPORT_TYPE_TEAM, TeamId: NewId(), UserId: NewId()}
+	o.PreSave()
+
+	json := o.ToJson()
+	ro := ArchiveExportFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.TeamId != ro.TeamId || o.Status != ro.Status {
+		t.Fatal("archive exports do not match")
+	}
+
+	if strings.Contains(json, o.Path) || len(ro.Path) != 0 {
+		t.Fatal("should not have included the path of the archive")
+	}
+
+	list := ArchiveExportListFromJson(strings.NewReader(ArchiveExportListToJson([]*ArchiveExport{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("archive export lists do not match")
+	}
+}
+
+func TestArchiveExportIsValid(t *testing.T) {
+	o := ArchiveExport{Type: ARCHIVE_EXPORT_TYPE_CHANNEL, ChannelId: NewId(), TeamId: NewId(), UserId: NewId()}
+	o.PreSave()
+
+	if o.Status != ARCHIVE_EXPORT_STATUS_PENDING || len(o.Path) == 0 {
+		t.Fatal("should have made the export pending")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TeamId = ""
+	if err := o.IsValid(); err != nil {
+		t.Fatal("should allow channels without a team", err)
+	}
+
+	o.Type = ARCHIVE_EXPORT_TYPE_TEAM
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.TeamId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("team exports shouldn't have a channel")
+	}
+
+	o.ChannelId = ""
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Type = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Type = ARCHIVE_EXPORT_TYPE_TEAM
+	o.Progress = 101
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Progress = 100
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Status = ARCHIVE_EXPORT_STATUS_FAILED
+	o.Error = strings.Repeat("x", ARCHIVE_EXPORT_ERROR_MAX_RUNES+1)
+	o.PreUpdate()
+	if err := o.IsValid(); err != nil {
+		t.Fatal("should have truncated the error", err)
+	}
+}
@@ -6,16 +6,131 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net/url"
+	"strconv"
 )
 
+const (
+	AUDIT_EVENT_NAME_MAX_LENGTH  = 64
+	AUDIT_TARGET_TYPE_MAX_LENGTH = 32
+
+	AUDIT_TARGET_USER    = "user"
+	AUDIT_TARGET_TEAM    = "team"
+	AUDIT_TARGET_CHANNEL = "channel"
+	AUDIT_TARGET_POST    = "post"
+	AUDIT_TARGET_SESSION = "session"
+	AUDIT_TARGET_CONFIG  = "config"
+
+	AUDIT_TARGET_REMOTE_CLUSTER = "remote_cluster"
+
+	// The HMAC-SHA256 of the body of a delivery to the audit webhook, keyed with its secret
+	HEADER_AUDIT_SIGNATURE = "X-Mattermost-Audit-Signature"
+)
+
+// Audit records something that was done on the server. UserId is the user that the audit is about,
+// for compatibility with audits recorded before the structured fields, while ActorId is the user who
+// did it. EventName identifies the kind of event, such as the API handler that was called, and the
+// target is the object that the event acted on.
 type Audit struct {
-	Id        string `json:"id"`
-	CreateAt  int64  `json:"create_at"`
-	UserId    string `json:"user_id"`
-	Action    string `json:"action"`
-	ExtraInfo string `json:"extra_info"`
-	IpAddress string `json:"ip_address"`
-	SessionId string `json:"session_id"`
+	Id         string          `json:"id"`
+	CreateAt   int64           `json:"create_at"`
+	UserId     string          `json:"user_id"`
+	Action     string          `json:"action"`
+	ExtraInfo  string          `json:"extra_info"`
+	IpAddress  string          `json:"ip_address"`
+	SessionId  string          `json:"session_id"`
+	EventName  string          `json:"event_name"`
+	ActorId    string          `json:"actor_id"`
+	TargetType string          `json:"target_type"`
+	TargetId   string          `json:"target_id"`
+	Metadata   StringInterface `json:"metadata"`
+}
+
+// AuditFilter restricts the audits returned by a search. Empty fields and times of 0 don't filter.
+// UserId matches audits about the user as well as audits of what the user did.
+type AuditFilter struct {
+	UserId     string
+	EventName  string
+	TargetType string
+	TargetId   string
+	Since      int64
+	Until      int64
+}
+
+// AuditFilterFromQuery reads a filter from the query parameters of a request, returning the name of
+// the first invalid parameter if there is one.
+func AuditFilterFromQuery(query url.Values) (*AuditFilter, string) {
+	filter := &AuditFilter{
+		UserId:     query.Get("user_id"),
+		EventName:  query.Get("event_name"),
+		TargetType: query.Get("target_type"),
+		TargetId:   query.Get("target_id"),
+	}
+
+	if len(filter.UserId) != 0 && len(filter.UserId) != 26 {
+		return nil, "user_id"
+	}
+
+	if len(filter.EventName) > AUDIT_EVENT_NAME_MAX_LENGTH {
+		return nil, "event_name"
+	}
+
+	if len(filter.TargetType) > AUDIT_TARGET_TYPE_MAX_LENGTH {
+		return nil, "target_type"
+	}
+
+	if len(filter.TargetId) != 0 && len(filter.TargetId) != 26 {
+		return nil, "target_id"
+	}
+
+	if since := query.Get("since"); len(since) > 0 {
+		if val, err := strconv.ParseInt(since, 10, 64); err != nil || val < 0 {
+			return nil, "since"
+		} else {
+			filter.Since = val
+		}
+	}
+
+	if until := query.Get("until"); len(until) > 0 {
+		if val, err := strconv.ParseInt(until, 10, 64); err != nil || val < 0 {
+			return nil, "until"
+		} else {
+			filter.Until = val
+		}
+	}
+
+	return filter, ""
+}
+
+// ToQuery returns the query parameters that AuditFilterFromQuery reads the filter from.
+func (o *AuditFilter) ToQuery() url.Values {
+	query := url.Values{}
+
+	if len(o.UserId) > 0 {
+		query.Set("user_id", o.UserId)
+	}
+
+	if len(o.EventName) > 0 {
+		query.Set("event_name", o.EventName)
+	}
+
+	if len(o.TargetType) > 0 {
+		query.Set("target_type", o.TargetType)
+	}
+
+	if len(o.TargetId) > 0 {
+		query.Set("target_id", o.TargetId)
+	}
+
+	if o.Since > 0 {
+		query.Set("since", strconv.FormatInt(o.Since, 10))
+	}
+
+	if o.Until > 0 {
+		query.Set("until", strconv.FormatInt(o.Until, 10))
+	}
+
+	return query
 }
 
 func (o *Audit) ToJson() string {
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -17,3 +18,33 @@ func TestAuditJson(t *testing.T) {
 		t.Fatal("Ids do not match")
 	}
 }
+
+func TestAuditFilterFromQuery(t *testing.T) {
+	filter := &AuditFilter{UserId: NewId(), EventName: "updateConfig", TargetType: AUDIT_TARGET_USER, TargetId: NewId(), Since: 1000, Until: 2000}
+
+	if rfilter, invalid := AuditFilterFromQuery(filter.ToQuery()); invalid != "" {
+		t.Fatal("should have been valid", invalid)
+	} else if *rfilter != *filter {
+		t.Fatal("should have round tripped the filter", rfilter, filter)
+	}
+
+	if rfilter, invalid := AuditFilterFromQuery(url.Values{}); invalid != "" || *rfilter != (AuditFilter{}) {
+		t.Fatal("should have returned an empty filter")
+	}
+
+	for name, value := range map[string]string{
+		"user_id":     "junk",
+		"event_name":  strings.Repeat("a", AUDIT_EVENT_NAME_MAX_LENGTH+1),
+		"target_type": strings.Repeat("a", AUDIT_TARGET_TYPE_MAX_LENGTH+1),
+		"target_id":   "junk",
+		"since":       "junk",
+		"until":       "-1",
+	} {
+		query := url.Values{}
+		query.Set(name, value)
+
+		if _, invalid := AuditFilterFromQuery(query); invalid != name {
+			t.Fatal("should have been invalid", name, invalid)
+		}
+	}
+}
@@ -58,6 +58,10 @@ var PERMISSION_MANAGE_TEAM *Permission
 var PERMISSION_IMPORT_TEAM *Permission
 var PERMISSION_VIEW_TEAM *Permission
 var PERMISSION_LIST_USERS_WITHOUT_TEAM *Permission
+var PERMISSION_VIEW_MEMBERS *Permission
+var PERMISSION_INVITE_GUEST *Permission
+var PERMISSION_CREATE_BOT *Permission
+var PERMISSION_MANAGE_OTHERS_BOTS *Permission
 
 // General permission that encompases all system admin functions
 // in the future this could be broken up to allow access to some
@@ -66,9 +70,11 @@ var PERMISSION_MANAGE_SYSTEM *Permission
 
 var ROLE_SYSTEM_USER *Role
 var ROLE_SYSTEM_ADMIN *Role
+var ROLE_SYSTEM_GUEST *Role
 
 var ROLE_TEAM_USER *Role
 var ROLE_TEAM_ADMIN *Role
+var ROLE_TEAM_GUEST *Role
 
 var ROLE_CHANNEL_USER *Role
 var ROLE_CHANNEL_ADMIN *Role
@@ -292,6 +298,26 @@ func InitalizePermissions() {
 		"authentication.permisssions.list_users_without_team.name",
 		"authentication.permisssions.list_users_without_team.description",
 	}
+	PERMISSION_VIEW_MEMBERS = &Permission{
+		"view_members",
+		"authentication.permissions.view_members.name",
+		"authentication.permissions.view_members.description",
+	}
+	PERMISSION_INVITE_GUEST = &Permission{
+		"invite_guest",
+		"authentication.permissions.invite_guest.name",
+		"authentication.permissions.invite_guest.description",
+	}
+	PERMISSION_CREATE_BOT = &Permission{
+		"create_bot",
+		"authentication.permissions.create_bot.name",
+		"authentication.permissions.create_bot.description",
+	}
+	PERMISSION_MANAGE_OTHERS_BOTS = &Permission{
+		"manage_others_bots",
+		"authentication.permissions.manage_others_bots.name",
+		"authentication.permissions.manage_others_bots.description",
+	}
 }
 
 func InitalizeRoles() {
@@ -323,10 +349,15 @@ func InitalizeRoles() {
 	}
 	BuiltInRoles[ROLE_CHANNEL_ADMIN.Id] = ROLE_CHANNEL_ADMIN
 	ROLE_CHANNEL_GUEST = &Role{
-		"guest",
-		"authentication.roles.global_guest.name",
-		"authentication.roles.global_guest.description",
-		[]string{},
+		"channel_guest",
+		"authentication.roles.channel_guest.name",
+		"authentication.roles.channel_guest.description",
+		[]string{
+			PERMISSION_READ_CHANNEL.Id,
+			PERMISSION_UPLOAD_FILE.Id,
+			PERMISSION_CREATE_POST.Id,
+			PERMISSION_EDIT_POST.Id,
+		},
 	}
 	BuiltInRoles[ROLE_CHANNEL_GUEST.Id] = ROLE_CHANNEL_GUEST
 
@@ -342,6 +373,15 @@ func InitalizeRoles() {
 		},
 	}
 	BuiltInRoles[ROLE_TEAM_USER.Id] = ROLE_TEAM_USER
+	ROLE_TEAM_GUEST = &Role{
+		"team_guest",
+		"authentication.roles.team_guest.name",
+		"authentication.roles.team_guest.description",
+		[]string{
+			PERMISSION_VIEW_TEAM.Id,
+		},
+	}
+	BuiltInRoles[ROLE_TEAM_GUEST.Id] = ROLE_TEAM_GUEST
 	ROLE_TEAM_ADMIN = &Role{
 		"team_admin",
 		"authentication.roles.team_admin.name",
@@ -357,6 +397,7 @@ func InitalizeRoles() {
 			PERMISSION_MANAGE_SLASH_COMMANDS.Id,
 			PERMISSION_MANAGE_OTHERS_SLASH_COMMANDS.Id,
 			PERMISSION_MANAGE_WEBHOOKS.Id,
+			PERMISSION_INVITE_GUEST.Id,
 		},
 	}
 	BuiltInRoles[ROLE_TEAM_ADMIN.Id] = ROLE_TEAM_ADMIN
@@ -370,9 +411,21 @@ func InitalizeRoles() {
 			PERMISSION_CREATE_GROUP_CHANNEL.Id,
 			PERMISSION_PERMANENT_DELETE_USER.Id,
 			PERMISSION_MANAGE_OAUTH.Id,
+			PERMISSION_VIEW_MEMBERS.Id,
+			PERMISSION_CREATE_BOT.Id,
 		},
 	}
 	BuiltInRoles[ROLE_SYSTEM_USER.Id] = ROLE_SYSTEM_USER
+
+	// Guests can only see and use the channels they've been added to, so they get all of their
+	// permissions from their team and channel memberships
+	ROLE_SYSTEM_GUEST = &Role{
+		"system_guest",
+		"authentication.roles.global_guest.name",
+		"authentication.roles.global_guest.description",
+		[]string{},
+	}
+	BuiltInRoles[ROLE_SYSTEM_GUEST.Id] = ROLE_SYSTEM_GUEST
 	ROLE_SYSTEM_ADMIN = &Role{
 		"system_admin",
 		"authentication.roles.global_admin.name",
@@ -406,6 +459,9 @@ func InitalizeRoles() {
 							PERMISSION_CREATE_TEAM.Id,
 							PERMISSION_ADD_USER_TO_TEAM.Id,
 							PERMISSION_LIST_USERS_WITHOUT_TEAM.Id,
+							PERMISSION_VIEW_MEMBERS.Id,
+							PERMISSION_CREATE_BOT.Id,
+							PERMISSION_MANAGE_OTHERS_BOTS.Id,
 						},
 						ROLE_TEAM_USER.Permissions...,
 					),
@@ -4,16 +4,26 @@
 package model
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"regexp"
 )
 
 const (
 	AUTHCODE_EXPIRE_TIME   = 60 * 10 // 10 minutes
 	AUTHCODE_RESPONSE_TYPE = "code"
 	DEFAULT_SCOPE          = "user"
+
+	PKCE_CHALLENGE_METHOD_S256 = "S256"
+	PKCE_MIN_LENGTH            = 43
+	PKCE_MAX_LENGTH            = 128
 )
 
+var pkceValueRegex = regexp.MustCompile(`^[A-Za-z0-9\-._~]+$`)
+
 type AuthData struct {
 	ClientId    string `json:"client_id"`
 	UserId      string `json:"user_id"`
@@ -23,6 +33,12 @@ type AuthData struct {
 	RedirectUri string `json:"redirect_uri"`
 	State       string `json:"state"`
 	Scope       string `json:"scope"`
+
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+
+	// Nonce is passed through to the ID token when the openid scope is granted
+	Nonce string `json:"nonce"`
 }
 
 // IsValid validates the AuthData and returns an error if it isn't configured
@@ -57,13 +73,46 @@ func (ad *AuthData) IsValid() *AppError {
 		return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.state.app_error", nil, "client_id="+ad.ClientId)
 	}
 
-	if len(ad.Scope) > 128 {
+	if len(ad.Scope) > OAUTH_SCOPES_MAX_LENGTH {
 		return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.scope.app_error", nil, "client_id="+ad.ClientId)
 	}
 
+	if len(ad.Nonce) > OPENID_NONCE_MAX_LENGTH {
+		return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.nonce.app_error", nil, "client_id="+ad.ClientId)
+	}
+
+	if len(ad.CodeChallenge) > 0 || len(ad.CodeChallengeMethod) > 0 {
+		if !IsValidPKCEValue(ad.CodeChallenge) {
+			return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.code_challenge.app_error", nil, "client_id="+ad.ClientId)
+		}
+
+		if ad.CodeChallengeMethod != PKCE_CHALLENGE_METHOD_S256 {
+			return NewLocAppError("AuthData.IsValid", "model.authorize.is_valid.code_challenge_method.app_error", nil, "client_id="+ad.ClientId)
+		}
+	}
+
 	return nil
 }
 
+// IsValidPKCEValue checks that a PKCE code verifier or code challenge has the length and
+// character set required by RFC 7636.
+func IsValidPKCEValue(value string) bool {
+	return len(value) >= PKCE_MIN_LENGTH && len(value) <= PKCE_MAX_LENGTH && pkceValueRegex.MatchString(value)
+}
+
+// VerifyCodeChallenge reports whether the code verifier sent with the access token request matches the
+// code challenge that was sent when the authorization code was issued.
+func (ad *AuthData) VerifyCodeChallenge(codeVerifier string) bool {
+	if len(ad.CodeChallenge) == 0 || ad.CodeChallengeMethod != PKCE_CHALLENGE_METHOD_S256 || !IsValidPKCEValue(codeVerifier) {
+		return false
+	}
+
+	hash := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(ad.CodeChallenge)) == 1
+}
+
 func (ad *AuthData) PreSave() {
 	if ad.ExpiresIn == 0 {
 		ad.ExpiresIn = AUTHCODE_EXPIRE_TIME
@@ -134,3 +134,61 @@ func TestAuthIsValid(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAuthVerifyCodeChallenge(t *testing.T) {
+	// Example values from RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	ad := AuthData{}
+	if ad.VerifyCodeChallenge(verifier) {
+		t.Fatal("should not verify without a challenge")
+	}
+
+	ad.CodeChallenge = challenge
+	ad.CodeChallengeMethod = "plain"
+	if ad.VerifyCodeChallenge(verifier) {
+		t.Fatal("should only verify S256 challenges")
+	}
+
+	ad.CodeChallengeMethod = PKCE_CHALLENGE_METHOD_S256
+	if !ad.VerifyCodeChallenge(verifier) {
+		t.Fatal("should have verified")
+	}
+
+	if ad.VerifyCodeChallenge(verifier[1:]) {
+		t.Fatal("should not verify a different verifier")
+	}
+
+	if ad.VerifyCodeChallenge("") {
+		t.Fatal("should not verify an empty verifier")
+	}
+}
+
+func TestAuthIsValidCodeChallenge(t *testing.T) {
+	ad := AuthData{ClientId: NewId(), UserId: NewId(), Code: NewId(), ExpiresIn: 1, CreateAt: 1, RedirectUri: "http://example.com"}
+
+	if err := ad.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	ad.CodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("should have failed without a challenge method")
+	}
+
+	ad.CodeChallengeMethod = PKCE_CHALLENGE_METHOD_S256
+	if err := ad.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	ad.CodeChallenge = "too-short"
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("should have failed with a short challenge")
+	}
+
+	ad.CodeChallenge = NewRandomString(43) + "!"
+	if err := ad.IsValid(); err == nil {
+		t.Fatal("should have failed with invalid characters")
+	}
+}
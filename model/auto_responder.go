@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	AUTO_RESPONDER_MESSAGE_MAX_RUNES = 1000
+)
+
+// AutoResponder is a reply that's posted on a user's behalf to the first direct message that each
+// other user sends them on a day, such as an out of office message. It's only used while Active is
+// true and, if they're set, after StartAt and before EndAt.
+type AutoResponder struct {
+	UserId   string `json:"user_id"`
+	Active   bool   `json:"active"`
+	Message  string `json:"message"`
+	StartAt  int64  `json:"start_at"`
+	EndAt    int64  `json:"end_at"`
+	UpdateAt int64  `json:"update_at"`
+}
+
+func (o *AutoResponder) IsValid() *AppError {
+	if len(o.UserId) != 26 {
+		return NewAppError("AutoResponder.IsValid", "model.auto_responder.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.Active && len(o.Message) == 0 {
+		return NewAppError("AutoResponder.IsValid", "model.auto_responder.is_valid.message.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > AUTO_RESPONDER_MESSAGE_MAX_RUNES {
+		return NewAppError("AutoResponder.IsValid", "model.auto_responder.is_valid.message.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if o.StartAt < 0 || o.EndAt < 0 || (o.StartAt > 0 && o.EndAt > 0 && o.EndAt <= o.StartAt) {
+		return NewAppError("AutoResponder.IsValid", "model.auto_responder.is_valid.date_range.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *AutoResponder) PreSave() {
+	o.UpdateAt = GetMillis()
+}
+
+// IsActiveAt returns true if the auto responder should reply to messages at the given time.
+func (o *AutoResponder) IsActiveAt(time int64) bool {
+	return o.Active && (o.StartAt == 0 || o.StartAt <= time) && (o.EndAt == 0 || time < o.EndAt)
+}
+
+// HasEndedAt returns true if the auto responder is active but its date range is over by the given time.
+func (o *AutoResponder) HasEndedAt(time int64) bool {
+	return o.Active && o.EndAt > 0 && o.EndAt <= time
+}
+
+func (o *AutoResponder) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AutoResponderFromJson(data io.Reader) *AutoResponder {
+	decoder := json.NewDecoder(data)
+	var o AutoResponder
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// AutoResponse records the last time that a user's auto responder replied to another user, so that it
+// only replies to them once a day.
+type AutoResponse struct {
+	UserId   string
+	SenderId string
+	SentAt   int64
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoResponderJson(t *testing.T) {
+	o := AutoResponder{UserId: NewId(), Active: true, Message: "I'm away", EndAt: GetMillis()}
+	json := o.ToJson()
+	ro := AutoResponderFromJson(strings.NewReader(json))
+
+	if ro == nil || *ro != o {
+		t.Fatal("auto responders do not match")
+	}
+}
+
+func TestAutoResponderIsValid(t *testing.T) {
+	o := AutoResponder{UserId: NewId()}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Active = true
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid when active without a message")
+	}
+
+	o.Message = strings.Repeat("a", AUTO_RESPONDER_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with a message that's too long")
+	}
+
+	o.Message = "I'm away"
+	o.StartAt = 2000
+	o.EndAt = 1000
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid when ending before it starts")
+	}
+
+	o.EndAt = 3000
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoResponderIsActiveAt(t *testing.T) {
+	o := AutoResponder{UserId: NewId(), Active: true, Message: "I'm away"}
+
+	if !o.IsActiveAt(1000) {
+		t.Fatal("should be active without a date range")
+	}
+
+	o.StartAt = 2000
+	o.EndAt = 3000
+
+	if o.IsActiveAt(1000) || !o.IsActiveAt(2000) || o.IsActiveAt(3000) {
+		t.Fatal("should only be active during the date range")
+	}
+
+	if o.HasEndedAt(2000) || !o.HasEndedAt(3000) {
+		t.Fatal("should have ended at the end of the date range")
+	}
+
+	o.Active = false
+	if o.IsActiveAt(2000) || o.HasEndedAt(3000) {
+		t.Fatal("shouldn't be active when turned off")
+	}
+}
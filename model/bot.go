@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	BOT_DESCRIPTION_MAX_RUNES = 1024
+)
+
+// Bot is an account used by an integration. It is backed by a user so that it can post and join
+// channels, but it cannot log in and can only authenticate with the access tokens of its owner.
+type Bot struct {
+	UserId      string `json:"user_id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	OwnerId     string `json:"owner_id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	DeleteAt    int64  `json:"delete_at"`
+}
+
+func (o *Bot) IsValid() *AppError {
+	if len(o.UserId) != 26 {
+		return NewAppError("Bot.IsValid", "model.bot.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.OwnerId) != 26 {
+		return NewAppError("Bot.IsValid", "model.bot.is_valid.owner_id.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Description) > BOT_DESCRIPTION_MAX_RUNES {
+		return NewAppError("Bot.IsValid", "model.bot.is_valid.description.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("Bot.IsValid", "model.bot.is_valid.create_at.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("Bot.IsValid", "model.bot.is_valid.update_at.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Bot) PreSave() {
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *Bot) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// ToUser returns the user backing a new bot.
+func (o *Bot) ToUser() *User {
+	return &User{
+		Username:      o.Username,
+		Email:         o.Username + "@localhost",
+		FirstName:     o.DisplayName,
+		Password:      NewId(),
+		EmailVerified: true,
+	}
+}
+
+func (o *Bot) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BotFromJson(data io.Reader) *Bot {
+	decoder := json.NewDecoder(data)
+	var o Bot
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func BotListToJson(l []*Bot) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BotListFromJson(data io.Reader) []*Bot {
+	decoder := json.NewDecoder(data)
+	var o []*Bot
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
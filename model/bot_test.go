@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBotJson(t *testing.T) {
+	o := Bot{UserId: NewId(), Username: "bot", Description: "a bot"}
+	json := o.ToJson()
+	ro := BotFromJson(strings.NewReader(json))
+
+	if o.UserId != ro.UserId || o.Username != ro.Username || o.Description != ro.Description {
+		t.Fatal("bots do not match")
+	}
+}
+
+func TestBotIsValid(t *testing.T) {
+	o := Bot{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.OwnerId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Description = strings.Repeat("a", BOT_DESCRIPTION_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestBotToUser(t *testing.T) {
+	o := Bot{Username: "bot" + NewId(), DisplayName: "Bot"}
+	user := o.ToUser()
+
+	if user.Username != o.Username || user.FirstName != o.DisplayName {
+		t.Fatal("should have used the bot's names")
+	}
+
+	user.PreSave()
+	if err := user.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	BULK_EMAIL_SEGMENT_ALL             = "all"
+	BULK_EMAIL_SEGMENT_TEAM            = "team"
+	BULK_EMAIL_SEGMENT_INACTIVE        = "inactive"
+	BULK_EMAIL_SEGMENT_OUTDATED_CLIENT = "outdated_client"
+
+	BULK_EMAIL_STATUS_PENDING  = "pending"
+	BULK_EMAIL_STATUS_RUNNING  = "running"
+	BULK_EMAIL_STATUS_SUCCESS  = "success"
+	BULK_EMAIL_STATUS_FAILED   = "failed"
+	BULK_EMAIL_STATUS_CANCELED = "canceled"
+
+	BULK_EMAIL_RECIPIENT_STATUS_PENDING = "pending"
+	BULK_EMAIL_RECIPIENT_STATUS_SENT    = "sent"
+	BULK_EMAIL_RECIPIENT_STATUS_FAILED  = "failed"
+	BULK_EMAIL_RECIPIENT_STATUS_SKIPPED = "skipped"
+
+	// The reasons that a recipient was skipped, which are kept as its error
+	BULK_EMAIL_SKIPPED_DEACTIVATED  = "deactivated"
+	BULK_EMAIL_SKIPPED_BOT          = "bot"
+	BULK_EMAIL_SKIPPED_UNSUBSCRIBED = "unsubscribed"
+
+	BULK_EMAIL_SUBJECT_MAX_RUNES = 256
+	BULK_EMAIL_MESSAGE_MAX_RUNES = POST_MESSAGE_MAX_RUNES
+	BULK_EMAIL_ERROR_MAX_RUNES   = 1024
+)
+
+// BulkEmail is a message that an admin sends to every user of a segment, by email, as a direct
+// message or both. It's sent in the background at a limited rate and the status of each recipient
+// is kept as a BulkEmailRecipient. Messages that aren't critical aren't sent to users that have
+// unsubscribed from them.
+//
+// The subject and message can include the {{username}}, {{first_name}}, {{last_name}} and
+// {{site_name}} placeholders, which are replaced for each recipient.
+type BulkEmail struct {
+	Id                string `json:"id"`
+	UserId            string `json:"user_id"`
+	Segment           string `json:"segment"`
+	TeamId            string `json:"team_id"`
+	InactiveDays      int    `json:"inactive_days"`
+	MinVersion        string `json:"min_version"`
+	Subject           string `json:"subject"`
+	Message           string `json:"message"`
+	SendEmail         bool   `json:"send_email"`
+	SendDirectMessage bool   `json:"send_direct_message"`
+	SenderId          string `json:"sender_id"` // The bot that sends the direct messages, or the admin if it's empty
+	Critical          bool   `json:"critical"`
+	Status            string `json:"status"`
+	TotalRecipients   int64  `json:"total_recipients"`
+	SentCount         int64  `json:"sent_count"`
+	FailedCount       int64  `json:"failed_count"`
+	SkippedCount      int64  `json:"skipped_count"`
+	Error             string `json:"error"`
+	CreateAt          int64  `json:"create_at"`
+	UpdateAt          int64  `json:"update_at"`
+	StartAt           int64  `json:"start_at"`
+	EndAt             int64  `json:"end_at"`
+}
+
+type BulkEmailRecipient struct {
+	BulkEmailId string `json:"bulk_email_id"`
+	UserId      string `json:"user_id"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	UpdateAt    int64  `json:"update_at"`
+}
+
+func (o *BulkEmail) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Segment {
+	case BULK_EMAIL_SEGMENT_ALL, BULK_EMAIL_SEGMENT_OUTDATED_CLIENT:
+	case BULK_EMAIL_SEGMENT_TEAM:
+		if len(o.TeamId) != 26 {
+			return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	case BULK_EMAIL_SEGMENT_INACTIVE:
+		if o.InactiveDays < 1 || o.InactiveDays > INACTIVITY_REPORT_MAX_DAYS {
+			return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.inactive_days.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.segment.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.MinVersion) > 0 && !IsValidVersion(o.MinVersion) {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.min_version.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !o.SendEmail && !o.SendDirectMessage {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.delivery.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if (o.SendEmail && len(strings.TrimSpace(o.Subject)) == 0) || utf8.RuneCountInString(o.Subject) > BULK_EMAIL_SUBJECT_MAX_RUNES {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.subject.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(strings.TrimSpace(o.Message)) == 0 || utf8.RuneCountInString(o.Message) > BULK_EMAIL_MESSAGE_MAX_RUNES {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.message.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(len(o.SenderId) == 26 || len(o.SenderId) == 0) {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.sender_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(o.Status == BULK_EMAIL_STATUS_PENDING || o.Status == BULK_EMAIL_STATUS_RUNNING || o.Status == BULK_EMAIL_STATUS_SUCCESS ||
+		o.Status == BULK_EMAIL_STATUS_FAILED || o.Status == BULK_EMAIL_STATUS_CANCELED) {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Error) > BULK_EMAIL_ERROR_MAX_RUNES {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.error.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("BulkEmail.IsValid", "model.bulk_email.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *BulkEmail) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Segment != BULK_EMAIL_SEGMENT_TEAM {
+		o.TeamId = ""
+	}
+
+	if o.Segment != BULK_EMAIL_SEGMENT_INACTIVE {
+		o.InactiveDays = 0
+	}
+
+	if o.Segment != BULK_EMAIL_SEGMENT_OUTDATED_CLIENT {
+		o.MinVersion = ""
+	}
+
+	o.Status = BULK_EMAIL_STATUS_PENDING
+	o.TotalRecipients = 0
+	o.SentCount = 0
+	o.FailedCount = 0
+	o.SkippedCount = 0
+	o.Error = ""
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.StartAt = 0
+	o.EndAt = 0
+}
+
+func (o *BulkEmail) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if utf8.RuneCountInString(o.Error) > BULK_EMAIL_ERROR_MAX_RUNES {
+		runes := []rune(o.Error)
+		o.Error = string(runes[:BULK_EMAIL_ERROR_MAX_RUNES])
+	}
+}
+
+// IsFinished returns true if the bulk email won't be sent to any more recipients.
+func (o *BulkEmail) IsFinished() bool {
+	return o.Status == BULK_EMAIL_STATUS_SUCCESS || o.Status == BULK_EMAIL_STATUS_FAILED || o.Status == BULK_EMAIL_STATUS_CANCELED
+}
+
+// Render returns the subject and message of the bulk email with the placeholders replaced for a
+// recipient.
+func (o *BulkEmail) Render(user *User, siteName string) (string, string) {
+	replacer := strings.NewReplacer(
+		"{{username}}", user.Username,
+		"{{first_name}}", user.FirstName,
+		"{{last_name}}", user.LastName,
+		"{{site_name}}", siteName,
+	)
+
+	return replacer.Replace(o.Subject), replacer.Replace(o.Message)
+}
+
+func (o *BulkEmail) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BulkEmailFromJson(data io.Reader) *BulkEmail {
+	decoder := json.NewDecoder(data)
+	var o BulkEmail
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func BulkEmailListToJson(l []*BulkEmail) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BulkEmailListFromJson(data io.Reader) []*BulkEmail {
+	decoder := json.NewDecoder(data)
+	var o []*BulkEmail
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *BulkEmailRecipient) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if utf8.RuneCountInString(o.Error) > BULK_EMAIL_ERROR_MAX_RUNES {
+		runes := []rune(o.Error)
+		o.Error = string(runes[:BULK_EMAIL_ERROR_MAX_RUNES])
+	}
+}
+
+func IsValidBulkEmailRecipientStatus(status string) bool {
+	switch status {
+	case BULK_EMAIL_RECIPIENT_STATUS_PENDING, BULK_EMAIL_RECIPIENT_STATUS_SENT, BULK_EMAIL_RECIPIENT_STATUS_FAILED, BULK_EMAIL_RECIPIENT_STATUS_SKIPPED:
+		return true
+	default:
+		return false
+	}
+}
+
+func BulkEmailRecipientListToJson(l []*BulkEmailRecipient) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BulkEmailRecipientListFromJson(data io.Reader) []*BulkEmailRecipient {
+	decoder := json.NewDecoder(data)
+	var o []*BulkEmailRecipient
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBulkEmailJson(t *testing.T) {
+	o := BulkEmail{Segment: BULK_EMAIL_SEGMENT_ALL, UserId: NewId(), Subject: "subject", Message: "message", SendEmail: true}
+	o.PreSave()
+
+	ro := BulkEmailFromJson(strings.NewReader(o.ToJson()))
+	if o.Id != ro.Id || o.Message != ro.Message || o.Status != ro.Status {
+		t.Fatal("bulk emails do not match")
+	}
+
+	list := BulkEmailListFromJson(strings.NewReader(BulkEmailListToJson([]*BulkEmail{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("bulk email lists do not match")
+	}
+
+	recipients := BulkEmailRecipientListFromJson(strings.NewReader(BulkEmailRecipientListToJson([]*BulkEmailRecipient{{BulkEmailId: o.Id, UserId: NewId(), Status: BULK_EMAIL_RECIPIENT_STATUS_SENT}})))
+	if len(recipients) != 1 || recipients[0].BulkEmailId != o.Id || recipients[0].Status != BULK_EMAIL_RECIPIENT_STATUS_SENT {
+		t.Fatal("bulk email recipient lists do not match")
+	}
+}
+
+func TestBulkEmailIsValid(t *testing.T) {
+	o := BulkEmail{Segment: BULK_EMAIL_SEGMENT_TEAM, TeamId: NewId(), InactiveDays: 30, UserId: NewId(), Subject: "subject", Message: "message", SendEmail: true}
+	o.PreSave()
+
+	if o.Status != BULK_EMAIL_STATUS_PENDING || o.InactiveDays != 0 {
+		t.Fatal("should have made the bulk email pending and cleared the settings of other segments")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TeamId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should require a team")
+	}
+
+	o.Segment = BULK_EMAIL_SEGMENT_INACTIVE
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should require a number of inactive days")
+	}
+
+	o.InactiveDays = 30
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Segment = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Segment = BULK_EMAIL_SEGMENT_OUTDATED_CLIENT
+	o.MinVersion = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should require a valid version")
+	}
+
+	o.MinVersion = "3.9.0"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Subject = " "
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should require a subject for emails")
+	}
+
+	o.SendEmail = false
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be sent in some way")
+	}
+
+	o.SendDirectMessage = true
+	if err := o.IsValid(); err != nil {
+		t.Fatal("direct messages shouldn't need a subject", err)
+	}
+
+	o.Message = strings.Repeat("a", BULK_EMAIL_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = "message"
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestBulkEmailRender(t *testing.T) {
+	o := BulkEmail{Subject: "Hello {{first_name}}", Message: "Hi {{username}}, {{site_name}} is moving. {{unknown}}"}
+	user := &User{Username: "someone", FirstName: "Some"}
+
+	subject, message := o.Render(user, "Mattermost")
+	if subject != "Hello Some" {
+		t.Fatal("should have replaced the placeholders of the subject", subject)
+	} else if message != "Hi someone, Mattermost is moving. {{unknown}}" {
+		t.Fatal("should have replaced the placeholders of the message", message)
+	}
+}
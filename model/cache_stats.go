@@ -0,0 +1,42 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CacheStats describes the usage of one of the in memory caches of a server. ApproximateBytes
+// is extrapolated from a sample of the cached entries.
+type CacheStats struct {
+	Name             string  `json:"name"`
+	Size             int     `json:"size"`
+	Len              int     `json:"len"`
+	ApproximateBytes int64   `json:"approximate_bytes"`
+	Hits             int64   `json:"hits"`
+	Misses           int64   `json:"misses"`
+	HitRatio         float64 `json:"hit_ratio"`
+	Evictions        int64   `json:"evictions"`
+}
+
+func CacheStatsListToJson(l []*CacheStats) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func CacheStatsListFromJson(data io.Reader) []*CacheStats {
+	decoder := json.NewDecoder(data)
+	var o []*CacheStats
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
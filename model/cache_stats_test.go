@@ -0,0 +1,19 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCacheStatsListJson(t *testing.T) {
+	l := []*CacheStats{{Name: "channel", Size: 100, Len: 10, ApproximateBytes: 2048, Hits: 3, Misses: 1, HitRatio: 0.75, Evictions: 2}}
+	json := CacheStatsListToJson(l)
+	rl := CacheStatsListFromJson(strings.NewReader(json))
+
+	if len(rl) != 1 || *rl[0] != *l[0] {
+		t.Fatal("cache stats do not match")
+	}
+}
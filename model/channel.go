@@ -27,23 +27,35 @@ const (
 	CHANNEL_HEADER_MAX_RUNES       = 1024
 	CHANNEL_PURPOSE_MAX_RUNES      = 250
 	CHANNEL_CACHE_SIZE             = 25000
+
+	CHANNEL_ALLOWED_REACTIONS_MAX_LENGTH = 1024
+	CHANNEL_ALLOWED_REACTION_MAX_LENGTH  = 64
+
+	CHANNEL_ANNOUNCEMENT_POSTERS_MAX        = 100
+	CHANNEL_ANNOUNCEMENT_POSTERS_MAX_LENGTH = 3000
 )
 
 type Channel struct {
-	Id            string `json:"id"`
-	CreateAt      int64  `json:"create_at"`
-	UpdateAt      int64  `json:"update_at"`
-	DeleteAt      int64  `json:"delete_at"`
-	TeamId        string `json:"team_id"`
-	Type          string `json:"type"`
-	DisplayName   string `json:"display_name"`
-	Name          string `json:"name"`
-	Header        string `json:"header"`
-	Purpose       string `json:"purpose"`
-	LastPostAt    int64  `json:"last_post_at"`
-	TotalMsgCount int64  `json:"total_msg_count"`
-	ExtraUpdateAt int64  `json:"extra_update_at"`
-	CreatorId     string `json:"creator_id"`
+	Id                         string                    `json:"id"`
+	CreateAt                   int64                     `json:"create_at"`
+	UpdateAt                   int64                     `json:"update_at"`
+	DeleteAt                   int64                     `json:"delete_at"`
+	TeamId                     string                    `json:"team_id"`
+	Type                       string                    `json:"type"`
+	DisplayName                string                    `json:"display_name"`
+	Name                       string                    `json:"name"`
+	Header                     string                    `json:"header"`
+	Purpose                    string                    `json:"purpose"`
+	LastPostAt                 int64                     `json:"last_post_at"`
+	TotalMsgCount              int64                     `json:"total_msg_count"`
+	ExtraUpdateAt              int64                     `json:"extra_update_at"`
+	CreatorId                  string                    `json:"creator_id"`
+	AllowedReactions           StringArray               `json:"allowed_reactions,omitempty"`
+	ForbidIntegrationOverrides bool                      `json:"forbid_integration_overrides"`
+	ModerationSettings         ChannelModerationSettings `json:"moderation_settings"`
+	IsAnnouncement             bool                      `json:"is_announcement"`
+	AnnouncementPosters        StringArray               `json:"announcement_posters,omitempty"`
+	AutoAddTeamMembers         bool                      `json:"auto_add_team_members"`
 }
 
 type ChannelPatch struct {
@@ -139,9 +151,64 @@ func (o *Channel) IsValid() *AppError {
 		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.creator_id.app_error", nil, "")
 	}
 
+	for _, emojiName := range o.AllowedReactions {
+		if len(emojiName) == 0 || len(emojiName) > CHANNEL_ALLOWED_REACTION_MAX_LENGTH {
+			return NewLocAppError("Channel.IsValid", "model.channel.is_valid.allowed_reactions.app_error", nil, "id="+o.Id)
+		}
+	}
+
+	if len(ArrayToJson(o.AllowedReactions)) > CHANNEL_ALLOWED_REACTIONS_MAX_LENGTH {
+		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.allowed_reactions.app_error", nil, "id="+o.Id)
+	}
+
+	if (o.IsAnnouncement || o.AutoAddTeamMembers) && o.IsGroupOrDirect() {
+		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.announcement.app_error", nil, "id="+o.Id)
+	}
+
+	if o.AutoAddTeamMembers && !o.IsAnnouncement {
+		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.auto_add_team_members.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.AnnouncementPosters) > CHANNEL_ANNOUNCEMENT_POSTERS_MAX {
+		return NewLocAppError("Channel.IsValid", "model.channel.is_valid.announcement_posters.app_error", nil, "id="+o.Id)
+	}
+
+	for _, userId := range o.AnnouncementPosters {
+		if len(userId) != 26 {
+			return NewLocAppError("Channel.IsValid", "model.channel.is_valid.announcement_posters.app_error", nil, "id="+o.Id)
+		}
+	}
+
 	return nil
 }
 
+// IsReactionAllowed returns whether users can react with the given emoji in the channel. Channels
+// without a list of allowed reactions accept any emoji.
+func (o *Channel) IsReactionAllowed(emojiName string) bool {
+	if len(o.AllowedReactions) == 0 {
+		return true
+	}
+
+	for _, allowed := range o.AllowedReactions {
+		if allowed == emojiName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAnnouncementPoster returns whether the user was designated to post in the announcement channel.
+func (o *Channel) IsAnnouncementPoster(userId string) bool {
+	for _, posterId := range o.AnnouncementPosters {
+		if posterId == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (o *Channel) PreSave() {
 	if o.Id == "" {
 		o.Id = NewId()
@@ -182,6 +249,20 @@ func (o *Channel) Patch(patch *ChannelPatch) {
 	}
 }
 
+// GetOtherUserIdForDM returns the id of the user in a direct message channel that isn't userId.
+func (o *Channel) GetOtherUserIdForDM(userId string) string {
+	userIds := strings.Split(o.Name, "__")
+	if len(userIds) != 2 {
+		return ""
+	}
+
+	if userIds[0] == userId {
+		return userIds[1]
+	}
+
+	return userIds[0]
+}
+
 func GetDMNameFromIds(userId1, userId2 string) string {
 	if userId1 > userId2 {
 		return userId2 + "__" + userId1
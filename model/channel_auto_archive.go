@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ChannelAutoArchive tracks a channel that the auto-archiving job has either warned about or been told
+// to leave alone. A warned channel is archived at ArchiveAt unless it's posted in after WarnedAt. An
+// exempt channel is never warned about or archived, and ExemptBy is the user that exempted it.
+type ChannelAutoArchive struct {
+	ChannelId string `json:"channel_id"`
+	Exempt    bool   `json:"exempt"`
+	ExemptBy  string `json:"exempt_by"`
+	WarnedAt  int64  `json:"warned_at"`
+	ArchiveAt int64  `json:"archive_at"`
+	UpdateAt  int64  `json:"update_at"`
+}
+
+// UpcomingChannelArchival is a channel that has been warned about and will be archived at ArchiveAt
+// unless someone posts in it first.
+type UpcomingChannelArchival struct {
+	Id          string `json:"id"`
+	TeamId      string `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	LastPostAt  int64  `json:"last_post_at"`
+	WarnedAt    int64  `json:"warned_at"`
+	ArchiveAt   int64  `json:"archive_at"`
+}
+
+func (o *ChannelAutoArchive) IsValid() *AppError {
+	if len(o.ChannelId) != 26 {
+		return NewAppError("ChannelAutoArchive.IsValid", "model.channel_auto_archive.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.Exempt {
+		if len(o.ExemptBy) != 26 {
+			return NewAppError("ChannelAutoArchive.IsValid", "model.channel_auto_archive.is_valid.exempt_by.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+		}
+	} else if o.WarnedAt <= 0 || o.ArchiveAt < o.WarnedAt {
+		return NewAppError("ChannelAutoArchive.IsValid", "model.channel_auto_archive.is_valid.archive_at.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ChannelAutoArchive) PreSave() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *ChannelAutoArchive) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelAutoArchiveFromJson(data io.Reader) *ChannelAutoArchive {
+	decoder := json.NewDecoder(data)
+	var o ChannelAutoArchive
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func UpcomingChannelArchivalListToJson(list []*UpcomingChannelArchival) string {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UpcomingChannelArchivalListFromJson(data io.Reader) []*UpcomingChannelArchival {
+	decoder := json.NewDecoder(data)
+
+	var list []*UpcomingChannelArchival
+	if err := decoder.Decode(&list); err != nil {
+		return nil
+	} else {
+		return list
+	}
+}
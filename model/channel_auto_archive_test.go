@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelAutoArchiveJson(t *testing.T) {
+	o := ChannelAutoArchive{ChannelId: NewId(), WarnedAt: 1000, ArchiveAt: 2000}
+	ro := ChannelAutoArchiveFromJson(strings.NewReader(o.ToJson()))
+
+	if *ro != o {
+		t.Fatal("auto archives do not match")
+	}
+
+	list := []*UpcomingChannelArchival{{Id: NewId(), Name: "town", ArchiveAt: 2000}}
+	rlist := UpcomingChannelArchivalListFromJson(strings.NewReader(UpcomingChannelArchivalListToJson(list)))
+
+	if len(rlist) != 1 || *rlist[0] != *list[0] {
+		t.Fatal("upcoming archivals do not match")
+	}
+}
+
+func TestChannelAutoArchiveIsValid(t *testing.T) {
+	o := ChannelAutoArchive{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without being warned or exempt")
+	}
+
+	o.WarnedAt = 2000
+	o.ArchiveAt = 1000
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid when archived before being warned")
+	}
+
+	o.ArchiveAt = 2000
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o = ChannelAutoArchive{ChannelId: NewId(), Exempt: true}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without the user that exempted it")
+	}
+
+	o.ExemptBy = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+const (
+	CHANNEL_EXPORT_MANIFEST_FILE  = "manifest.json"
+	CHANNEL_EXPORT_SUMMARY_FILE   = "summary.json"
+	CHANNEL_EXPORT_SIGNATURE_ALGO = "HMAC-SHA256"
+)
+
+// ChannelSnapshot is the content of a channel as it was at a point in time. Edited posts appear
+// as they were at that time and posts, reactions and files deleted since then are included.
+type ChannelSnapshot struct {
+	Channel   *Channel    `json:"channel"`
+	AsOf      int64       `json:"as_of"`
+	Posts     []*Post     `json:"posts"`
+	Reactions []*Reaction `json:"reactions"`
+	FileInfos []*FileInfo `json:"file_infos"`
+}
+
+// ChannelExportEntry describes one of the files in a channel export.
+type ChannelExportEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// ChannelExportManifest lists every file in a channel export along with its checksum.
+type ChannelExportManifest struct {
+	ChannelId     string                `json:"channel_id"`
+	TeamId        string                `json:"team_id"`
+	AsOf          int64                 `json:"as_of"`
+	ExportedAt    int64                 `json:"exported_at"`
+	ExportedBy    string                `json:"exported_by"`
+	PostCount     int                   `json:"post_count"`
+	ReactionCount int                   `json:"reaction_count"`
+	FileCount     int                   `json:"file_count"`
+	Entries       []*ChannelExportEntry `json:"entries"`
+}
+
+// ChannelExportSummary is signed by the server so that the manifest, and through its checksums
+// every file in the export, can be shown to not have been modified since the export.
+type ChannelExportSummary struct {
+	ManifestSha256 string `json:"manifest_sha256"`
+	Algorithm      string `json:"algorithm"`
+	Signature      string `json:"signature"`
+}
+
+func NewChannelExportEntry(name string, data []byte) *ChannelExportEntry {
+	sum := sha256.Sum256(data)
+
+	return &ChannelExportEntry{
+		Name:   name,
+		Size:   int64(len(data)),
+		Sha256: hex.EncodeToString(sum[:]),
+	}
+}
+
+// NewChannelExportSummary signs the encoded manifest of an export with the given key.
+func NewChannelExportSummary(manifest []byte, key string) *ChannelExportSummary {
+	sum := sha256.Sum256(manifest)
+
+	return &ChannelExportSummary{
+		ManifestSha256: hex.EncodeToString(sum[:]),
+		Algorithm:      CHANNEL_EXPORT_SIGNATURE_ALGO,
+		Signature:      signChannelExportManifest(manifest, key),
+	}
+}
+
+// Verify returns true if the summary was signed with the given key for the encoded manifest.
+func (o *ChannelExportSummary) Verify(manifest []byte, key string) bool {
+	return hmac.Equal([]byte(o.Signature), []byte(signChannelExportManifest(manifest, key)))
+}
+
+func signChannelExportManifest(manifest []byte, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(manifest)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (o *ChannelExportManifest) ToJson() string {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelExportManifestFromJson(data io.Reader) *ChannelExportManifest {
+	decoder := json.NewDecoder(data)
+	var o ChannelExportManifest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *ChannelExportSummary) ToJson() string {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelExportSummaryFromJson(data io.Reader) *ChannelExportSummary {
+	decoder := json.NewDecoder(data)
+	var o ChannelExportSummary
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelExportEntry(t *testing.T) {
+	entry := NewChannelExportEntry("posts.json", []byte("abc"))
+
+	if entry.Size != 3 {
+		t.Fatal("should have set the size")
+	}
+
+	if entry.Sha256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatal("should have computed the checksum", entry.Sha256)
+	}
+}
+
+func TestChannelExportSummary(t *testing.T) {
+	manifest := &ChannelExportManifest{ChannelId: NewId(), AsOf: GetMillis()}
+	manifest.Entries = append(manifest.Entries, NewChannelExportEntry("posts.json", []byte("[]")))
+
+	data := []byte(manifest.ToJson())
+	key := NewRandomString(32)
+
+	summary := NewChannelExportSummary(data, key)
+	rsummary := ChannelExportSummaryFromJson(strings.NewReader(summary.ToJson()))
+
+	if rsummary.Algorithm != CHANNEL_EXPORT_SIGNATURE_ALGO || len(rsummary.ManifestSha256) != 64 {
+		t.Fatal("summaries do not match")
+	}
+
+	if !rsummary.Verify(data, key) {
+		t.Fatal("should have verified the signature")
+	}
+
+	if rsummary.Verify(data, NewRandomString(32)) {
+		t.Fatal("should not verify with another key")
+	}
+
+	manifest.PostCount = 1
+	if rsummary.Verify([]byte(manifest.ToJson()), key) {
+		t.Fatal("should not verify a modified manifest")
+	}
+
+	if rmanifest := ChannelExportManifestFromJson(strings.NewReader(string(data))); rmanifest.ChannelId != manifest.ChannelId || len(rmanifest.Entries) != 1 {
+		t.Fatal("manifests do not match")
+	}
+}
@@ -26,6 +26,19 @@ type ChannelUnread struct {
 	NotifyProps  StringMap `json:"-"`
 }
 
+// ChannelLastRead describes where a user stopped reading a channel. LastReadPostId is the newest post
+// created before the user last viewed the channel and is empty if there is no such post. MsgCount is
+// the number of posts after it.
+type ChannelLastRead struct {
+	TeamId         string `json:"team_id"`
+	ChannelId      string `json:"channel_id"`
+	UserId         string `json:"user_id"`
+	LastViewedAt   int64  `json:"last_viewed_at"`
+	LastReadPostId string `json:"last_read_post_id"`
+	MsgCount       int64  `json:"msg_count"`
+	MentionCount   int64  `json:"mention_count"`
+}
+
 type ChannelMember struct {
 	ChannelId    string    `json:"channel_id"`
 	UserId       string    `json:"user_id"`
@@ -35,10 +48,19 @@ type ChannelMember struct {
 	MentionCount int64     `json:"mention_count"`
 	NotifyProps  StringMap `json:"notify_props"`
 	LastUpdateAt int64     `json:"last_update_at"`
+	JoinAt       int64     `json:"join_at"`
 }
 
 type ChannelMembers []ChannelMember
 
+// ChannelMembersBatchResult describes which users were added to or removed from a channel in a single request
+// and which were skipped because they couldn't be or already had been.
+type ChannelMembersBatchResult struct {
+	ChannelId      string   `json:"channel_id"`
+	UserIds        []string `json:"user_ids"`
+	SkippedUserIds []string `json:"skipped_user_ids"`
+}
+
 func (o *ChannelMembers) ToJson() string {
 	if b, err := json.Marshal(o); err != nil {
 		return "[]"
@@ -56,6 +78,26 @@ func (o *ChannelUnread) ToJson() string {
 	}
 }
 
+func (o *ChannelLastRead) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelLastReadFromJson(data io.Reader) *ChannelLastRead {
+	decoder := json.NewDecoder(data)
+	var o ChannelLastRead
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
 func ChannelMembersFromJson(data io.Reader) *ChannelMembers {
 	decoder := json.NewDecoder(data)
 	var o ChannelMembers
@@ -98,6 +140,25 @@ func ChannelMemberFromJson(data io.Reader) *ChannelMember {
 	}
 }
 
+func (o *ChannelMembersBatchResult) ToJson() string {
+	if b, err := json.Marshal(o); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelMembersBatchResultFromJson(data io.Reader) *ChannelMembersBatchResult {
+	decoder := json.NewDecoder(data)
+	var o ChannelMembersBatchResult
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
 func (o *ChannelMember) IsValid() *AppError {
 
 	if len(o.ChannelId) != 26 {
@@ -139,6 +200,10 @@ func (o *ChannelMember) IsValid() *AppError {
 
 func (o *ChannelMember) PreSave() {
 	o.LastUpdateAt = GetMillis()
+
+	if o.JoinAt == 0 {
+		o.JoinAt = o.LastUpdateAt
+	}
 }
 
 func (o *ChannelMember) PreUpdate() {
@@ -149,6 +214,17 @@ func (o *ChannelMember) GetRoles() []string {
 	return strings.Fields(o.Roles)
 }
 
+// IsChannelAdmin returns true if the member has the channel admin role.
+func (o *ChannelMember) IsChannelAdmin() bool {
+	for _, role := range o.GetRoles() {
+		if role == ROLE_CHANNEL_ADMIN.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
 func IsChannelNotifyLevelValid(notifyLevel string) bool {
 	return notifyLevel == CHANNEL_NOTIFY_DEFAULT ||
 		notifyLevel == CHANNEL_NOTIFY_ALL ||
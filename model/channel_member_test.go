@@ -70,6 +70,34 @@ func TestChannelMemberIsValid(t *testing.T) {
 	}
 }
 
+func TestChannelMemberPreSave(t *testing.T) {
+	o := ChannelMember{ChannelId: NewId(), UserId: NewId()}
+	o.PreSave()
+
+	if o.JoinAt == 0 || o.JoinAt != o.LastUpdateAt {
+		t.Fatal("should have set the join time")
+	}
+
+	o.JoinAt = 1
+	o.PreSave()
+
+	if o.JoinAt != 1 {
+		t.Fatal("shouldn't have changed the join time")
+	}
+}
+
+func TestChannelMemberIsChannelAdmin(t *testing.T) {
+	o := ChannelMember{Roles: ROLE_CHANNEL_USER.Id}
+	if o.IsChannelAdmin() {
+		t.Fatal("shouldn't be a channel admin")
+	}
+
+	o.Roles = ROLE_CHANNEL_USER.Id + " " + ROLE_CHANNEL_ADMIN.Id
+	if !o.IsChannelAdmin() {
+		t.Fatal("should be a channel admin")
+	}
+}
+
 func TestChannelUnreadJson(t *testing.T) {
 	o := ChannelUnread{ChannelId: NewId(), TeamId: NewId(), MsgCount: 5, MentionCount: 3}
 	json := o.ToJson()
@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	CHANNEL_MODERATION_CREATE_POST          = "create_post"
+	CHANNEL_MODERATION_CREATE_REACTIONS     = "create_reactions"
+	CHANNEL_MODERATION_USE_CHANNEL_MENTIONS = "use_channel_mentions"
+)
+
+// ChannelModerationSettings restricts what the members and the guests of a channel can do in it.
+// Channel admins, team admins and system admins are never restricted, and everything is allowed
+// until a setting is turned on.
+type ChannelModerationSettings struct {
+	DisableMemberPosts           bool `json:"disable_member_posts"`
+	DisableGuestPosts            bool `json:"disable_guest_posts"`
+	DisableMemberReactions       bool `json:"disable_member_reactions"`
+	DisableGuestReactions        bool `json:"disable_guest_reactions"`
+	DisableMemberChannelMentions bool `json:"disable_member_channel_mentions"`
+	DisableGuestChannelMentions  bool `json:"disable_guest_channel_mentions"`
+}
+
+// IsRestricted returns whether anyone is kept from doing the moderated action.
+func (o *ChannelModerationSettings) IsRestricted(action string) bool {
+	return !o.Allows(action, false) || !o.Allows(action, true)
+}
+
+// Allows returns whether a member, or a guest if isGuest is true, can do the moderated action.
+// Unknown actions are always allowed.
+func (o *ChannelModerationSettings) Allows(action string, isGuest bool) bool {
+	switch action {
+	case CHANNEL_MODERATION_CREATE_POST:
+		if isGuest {
+			return !o.DisableGuestPosts
+		}
+		return !o.DisableMemberPosts
+	case CHANNEL_MODERATION_CREATE_REACTIONS:
+		if isGuest {
+			return !o.DisableGuestReactions
+		}
+		return !o.DisableMemberReactions
+	case CHANNEL_MODERATION_USE_CHANNEL_MENTIONS:
+		if isGuest {
+			return !o.DisableGuestChannelMentions
+		}
+		return !o.DisableMemberChannelMentions
+	}
+
+	return true
+}
+
+func (o ChannelModerationSettings) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelModerationSettingsFromJson(data io.Reader) *ChannelModerationSettings {
+	decoder := json.NewDecoder(data)
+	var o ChannelModerationSettings
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
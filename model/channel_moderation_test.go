@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelModerationSettingsJson(t *testing.T) {
+	o := ChannelModerationSettings{DisableGuestPosts: true, DisableMemberChannelMentions: true}
+	json := o.ToJson()
+	ro := ChannelModerationSettingsFromJson(strings.NewReader(json))
+
+	if *ro != o {
+		t.Fatal("settings should be the same after a round trip")
+	}
+
+	if ChannelModerationSettingsFromJson(strings.NewReader("junk")) != nil {
+		t.Fatal("shouldn't decode junk")
+	}
+}
+
+func TestChannelModerationSettingsAllows(t *testing.T) {
+	o := ChannelModerationSettings{}
+
+	for _, action := range []string{CHANNEL_MODERATION_CREATE_POST, CHANNEL_MODERATION_CREATE_REACTIONS, CHANNEL_MODERATION_USE_CHANNEL_MENTIONS} {
+		if !o.Allows(action, false) || !o.Allows(action, true) || o.IsRestricted(action) {
+			t.Fatal("everything should be allowed by default")
+		}
+	}
+
+	o.DisableGuestPosts = true
+	if !o.Allows(CHANNEL_MODERATION_CREATE_POST, false) {
+		t.Fatal("members should still be allowed to post")
+	}
+	if o.Allows(CHANNEL_MODERATION_CREATE_POST, true) {
+		t.Fatal("guests shouldn't be allowed to post")
+	}
+	if !o.IsRestricted(CHANNEL_MODERATION_CREATE_POST) {
+		t.Fatal("posting should be restricted")
+	}
+
+	o.DisableMemberReactions = true
+	if o.Allows(CHANNEL_MODERATION_CREATE_REACTIONS, false) {
+		t.Fatal("members shouldn't be allowed to react")
+	}
+	if !o.Allows(CHANNEL_MODERATION_CREATE_REACTIONS, true) {
+		t.Fatal("guests should still be allowed to react")
+	}
+
+	o.DisableGuestChannelMentions = true
+	if o.Allows(CHANNEL_MODERATION_USE_CHANNEL_MENTIONS, true) {
+		t.Fatal("guests shouldn't be allowed to use channel mentions")
+	}
+	if o.IsRestricted("junk") {
+		t.Fatal("unknown actions shouldn't be restricted")
+	}
+}
@@ -11,6 +11,11 @@ import (
 type ChannelStats struct {
 	ChannelId   string `json:"channel_id"`
 	MemberCount int64  `json:"member_count"`
+
+	// LargeChannel is set when the channel has more members than the large channel threshold, in which
+	// case the server doesn't send typing events or expand channel wide mentions for it and clients
+	// shouldn't load its whole member list.
+	LargeChannel bool `json:"large_channel"`
 }
 
 func (o *ChannelStats) ToJson() string {
@@ -125,6 +125,79 @@ func TestChannelIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.AllowedReactions = []string{"+1", ""}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AllowedReactions = make([]string, 200)
+	for i := range o.AllowedReactions {
+		o.AllowedReactions[i] = "emoji"
+	}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AllowedReactions = []string{"+1", "-1"}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.AutoAddTeamMembers = true
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.IsAnnouncement = true
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.AnnouncementPosters = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AnnouncementPosters = []string{NewId(), NewId()}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Type = CHANNEL_DIRECT
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestChannelIsAnnouncementPoster(t *testing.T) {
+	userId := NewId()
+	o := Channel{IsAnnouncement: true, AnnouncementPosters: []string{userId}}
+
+	if !o.IsAnnouncementPoster(userId) {
+		t.Fatal("should be a poster")
+	}
+
+	if o.IsAnnouncementPoster(NewId()) {
+		t.Fatal("shouldn't be a poster")
+	}
+}
+
+func TestChannelIsReactionAllowed(t *testing.T) {
+	o := Channel{}
+
+	if !o.IsReactionAllowed("smile") {
+		t.Fatal("any reaction should be allowed without a list")
+	}
+
+	o.AllowedReactions = []string{"+1", "-1"}
+	if !o.IsReactionAllowed("+1") || !o.IsReactionAllowed("-1") {
+		t.Fatal("listed reactions should be allowed")
+	}
+
+	if o.IsReactionAllowed("smile") {
+		t.Fatal("unlisted reactions shouldn't be allowed")
+	}
 }
 
 func TestChannelPreSave(t *testing.T) {
@@ -158,3 +231,18 @@ func TestGetGroupNameFromUserIds(t *testing.T) {
 		t.Fatal("name too long")
 	}
 }
+
+func TestChannelGetOtherUserIdForDM(t *testing.T) {
+	userId1 := NewId()
+	userId2 := NewId()
+	o := Channel{Name: GetDMNameFromIds(userId1, userId2), Type: CHANNEL_DIRECT}
+
+	if o.GetOtherUserIdForDM(userId1) != userId2 || o.GetOtherUserIdForDM(userId2) != userId1 {
+		t.Fatal("should have returned the other user")
+	}
+
+	o.Name = "town-square"
+	if o.GetOtherUserIdForDM(userId1) != "" {
+		t.Fatal("should be empty for a channel that isn't a direct message")
+	}
+}
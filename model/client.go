@@ -32,6 +32,7 @@ const (
 	HEADER_AUTH               = "Authorization"
 	HEADER_REQUESTED_WITH     = "X-Requested-With"
 	HEADER_REQUESTED_WITH_XML = "XMLHttpRequest"
+	HEADER_RETRY_AFTER        = "Retry-After"
 	STATUS                    = "status"
 	STATUS_OK                 = "OK"
 	STATUS_FAIL               = "FAIL"
@@ -1975,6 +1976,70 @@ func (c *Client) GetAccessToken(data url.Values) (*Result, *AppError) {
 	}
 }
 
+// RevokeOAuthToken revokes an OAuth access or refresh token, along with the rest of the grant it
+// belongs to. The data must include the token and the client_id and client_secret of the app.
+func (c *Client) RevokeOAuthToken(data url.Values) (*Result, *AppError) {
+	if r, err := c.DoPost("/oauth/revoke", data.Encode(), "application/x-www-form-urlencoded"); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
+// GetOpenIdConfiguration returns the OpenID Connect discovery document of the server.
+func (c *Client) GetOpenIdConfiguration() (*Result, *AppError) {
+	if r, err := c.doOpenIdGet(OPENID_CONFIGURATION_PATH, ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), OpenIdConfigurationFromJson(r.Body)}, nil
+	}
+}
+
+// GetOpenIdJsonWebKeys returns the public keys that ID tokens issued by the server can be checked with.
+func (c *Client) GetOpenIdJsonWebKeys() (*Result, *AppError) {
+	if r, err := c.doOpenIdGet(OPENID_JWKS_PATH, ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), JsonWebKeySetFromJson(r.Body)}, nil
+	}
+}
+
+// GetOpenIdUserInfo returns the claims about the user that an OAuth access token with the openid
+// scope was granted for.
+func (c *Client) GetOpenIdUserInfo(accessToken string) (*Result, *AppError) {
+	if r, err := c.doOpenIdGet(OPENID_USERINFO_PATH, accessToken); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), OpenIdClaimsFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) doOpenIdGet(url string, accessToken string) (*http.Response, *AppError) {
+	rq, _ := http.NewRequest("GET", c.Url+url, nil)
+	rq.Close = true
+
+	if len(accessToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, HEADER_BEARER+" "+accessToken)
+	}
+
+	if rp, err := c.HttpClient.Do(rq); err != nil {
+		return nil, NewLocAppError(url, "model.client.connecting.app_error", nil, err.Error())
+	} else if rp.StatusCode >= 300 {
+		defer closeBody(rp)
+		return nil, AppErrorFromJson(rp.Body)
+	} else {
+		return rp, nil
+	}
+}
+
 func (c *Client) CreateIncomingWebhook(hook *IncomingWebhook) (*Result, *AppError) {
 	if r, err := c.DoApiPost(c.GetTeamRoute()+"/hooks/incoming/create", hook.ToJson()); err != nil {
 		return nil, err
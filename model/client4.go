@@ -21,6 +21,11 @@ type Response struct {
 	RequestId     string
 	Etag          string
 	ServerVersion string
+	Pagination    *Pagination
+
+	// RedirectedFrom is set to the name that was asked for when a lookup by name was resolved to a
+	// team or channel that has since been renamed
+	RedirectedFrom string
 }
 
 type Client4 struct {
@@ -29,10 +34,12 @@ type Client4 struct {
 	HttpClient *http.Client // The http client
 	AuthToken  string
 	AuthType   string
+	// ClientVersion is sent in the X-Client-Version header if set, for example "desktop/3.7.1"
+	ClientVersion string
 }
 
 func NewAPIv4Client(url string) *Client4 {
-	return &Client4{url, url + API_URL_SUFFIX, &http.Client{}, "", ""}
+	return &Client4{url, url + API_URL_SUFFIX, &http.Client{}, "", "", ""}
 }
 
 func BuildResponse(r *http.Response) *Response {
@@ -41,6 +48,9 @@ func BuildResponse(r *http.Response) *Response {
 		RequestId:     r.Header.Get(HEADER_REQUEST_ID),
 		Etag:          r.Header.Get(HEADER_ETAG_SERVER),
 		ServerVersion: r.Header.Get(HEADER_VERSION_ID),
+		Pagination:    PaginationFromHeaders(r.Header),
+
+		RedirectedFrom: r.Header.Get(HEADER_REDIRECTED_FROM),
 	}
 }
 
@@ -94,6 +104,10 @@ func (c *Client4) GetTeamMembersRoute(teamId string) string {
 	return fmt.Sprintf(c.GetTeamRoute(teamId) + "/members")
 }
 
+func (c *Client4) GetTeamMembersBatchRoute(teamId string) string {
+	return fmt.Sprintf(c.GetTeamMembersRoute(teamId) + "/batch")
+}
+
 func (c *Client4) GetTeamStatsRoute(teamId string) string {
 	return fmt.Sprintf(c.GetTeamRoute(teamId) + "/stats")
 }
@@ -102,6 +116,14 @@ func (c *Client4) GetTeamImportRoute(teamId string) string {
 	return fmt.Sprintf(c.GetTeamRoute(teamId) + "/import")
 }
 
+func (c *Client4) GetInvitationsRoute(teamId string) string {
+	return c.GetTeamRoute(teamId) + "/invitations"
+}
+
+func (c *Client4) GetInvitationRoute(teamId, invitationId string) string {
+	return fmt.Sprintf(c.GetInvitationsRoute(teamId)+"/%v", invitationId)
+}
+
 func (c *Client4) GetChannelsRoute() string {
 	return fmt.Sprintf("/channels")
 }
@@ -158,6 +180,10 @@ func (c *Client4) GetSystemRoute() string {
 	return fmt.Sprintf("/system")
 }
 
+func (c *Client4) GetAnalyticsRoute() string {
+	return fmt.Sprintf("/analytics")
+}
+
 func (c *Client4) GetTestEmailRoute() string {
 	return fmt.Sprintf("/email/test")
 }
@@ -190,6 +216,46 @@ func (c *Client4) GetComplianceReportRoute(reportId string) string {
 	return fmt.Sprintf("/compliance/reports/%v", reportId)
 }
 
+func (c *Client4) GetComplianceFormatsRoute() string {
+	return fmt.Sprintf("/compliance/formats")
+}
+
+func (c *Client4) GetReportsRoute() string {
+	return fmt.Sprintf("/reports")
+}
+
+func (c *Client4) GetArchiveExportsRoute() string {
+	return fmt.Sprintf("/exports")
+}
+
+func (c *Client4) GetArchiveExportRoute(exportId string) string {
+	return fmt.Sprintf(c.GetArchiveExportsRoute()+"/%v", exportId)
+}
+
+func (c *Client4) GetBulkEmailsRoute() string {
+	return fmt.Sprintf("/bulk_emails")
+}
+
+func (c *Client4) GetBulkEmailRoute(bulkEmailId string) string {
+	return fmt.Sprintf(c.GetBulkEmailsRoute()+"/%v", bulkEmailId)
+}
+
+func (c *Client4) GetRetentionPoliciesRoute() string {
+	return fmt.Sprintf("/retention_policies")
+}
+
+func (c *Client4) GetRetentionPolicyRoute(policyId string) string {
+	return fmt.Sprintf(c.GetRetentionPoliciesRoute()+"/%v", policyId)
+}
+
+func (c *Client4) GetLegalHoldsRoute() string {
+	return fmt.Sprintf("/legal_holds")
+}
+
+func (c *Client4) GetLegalHoldRoute(holdId string) string {
+	return fmt.Sprintf(c.GetLegalHoldsRoute()+"/%v", holdId)
+}
+
 func (c *Client4) GetOutgoingWebhooksRoute() string {
 	return fmt.Sprintf("/hooks/outgoing")
 }
@@ -198,6 +264,98 @@ func (c *Client4) GetOutgoingWebhookRoute(hookID string) string {
 	return fmt.Sprintf(c.GetOutgoingWebhooksRoute()+"/%v", hookID)
 }
 
+func (c *Client4) GetAlertmanagerHooksRoute() string {
+	return fmt.Sprintf("/hooks/alertmanager")
+}
+
+func (c *Client4) GetAlertmanagerHookRoute(hookId string) string {
+	return fmt.Sprintf(c.GetAlertmanagerHooksRoute()+"/%v", hookId)
+}
+
+func (c *Client4) GetPostEventHooksRoute() string {
+	return fmt.Sprintf("/hooks/post_events")
+}
+
+func (c *Client4) GetPostEventHookRoute(hookId string) string {
+	return fmt.Sprintf(c.GetPostEventHooksRoute()+"/%v", hookId)
+}
+
+func (c *Client4) GetIncidentsRoute() string {
+	return fmt.Sprintf("/incidents")
+}
+
+func (c *Client4) GetIncidentRoute(incidentId string) string {
+	return fmt.Sprintf(c.GetIncidentsRoute()+"/%v", incidentId)
+}
+
+func (c *Client4) GetFeatureFlagsRoute() string {
+	return fmt.Sprintf("/feature_flags")
+}
+
+func (c *Client4) GetFeatureFlagRoute(name string) string {
+	return fmt.Sprintf(c.GetFeatureFlagsRoute()+"/%v", name)
+}
+
+func (c *Client4) GetExperimentsRoute() string {
+	return fmt.Sprintf("/experiments")
+}
+
+func (c *Client4) GetExperimentRoute(name string) string {
+	return fmt.Sprintf(c.GetExperimentsRoute()+"/%v", name)
+}
+
+func (c *Client4) GetReactionsRoute() string {
+	return fmt.Sprintf("/reactions")
+}
+
+func (c *Client4) GetTeamTemplatesRoute() string {
+	return fmt.Sprintf("/team_templates")
+}
+
+func (c *Client4) GetTeamTemplateRoute(templateId string) string {
+	return fmt.Sprintf(c.GetTeamTemplatesRoute()+"/%v", templateId)
+}
+
+func (c *Client4) GetTeamCloneJobRoute(jobId string) string {
+	return fmt.Sprintf("/team_clone_jobs/%v", jobId)
+}
+
+func (c *Client4) GetScheduledPostsRoute() string {
+	return fmt.Sprintf("/scheduled_posts")
+}
+
+func (c *Client4) GetScheduledPostRoute(scheduledPostId string) string {
+	return fmt.Sprintf(c.GetScheduledPostsRoute()+"/%v", scheduledPostId)
+}
+
+func (c *Client4) GetBotsRoute() string {
+	return fmt.Sprintf("/bots")
+}
+
+func (c *Client4) GetBotRoute(botUserId string) string {
+	return fmt.Sprintf(c.GetBotsRoute()+"/%v", botUserId)
+}
+
+func (c *Client4) GetGraphQLRoute() string {
+	return fmt.Sprintf("/graphql")
+}
+
+func (c *Client4) GetDraftsRoute(userId string) string {
+	return fmt.Sprintf(c.GetUserRoute(userId) + "/drafts")
+}
+
+func (c *Client4) GetDevicesRoute(userId string) string {
+	return fmt.Sprintf(c.GetUserRoute(userId) + "/devices")
+}
+
+func (c *Client4) GetThreadForUserRoute(userId, postId string) string {
+	return fmt.Sprintf(c.GetUserRoute(userId)+"/threads/%v", postId)
+}
+
+func (c *Client4) GetTestingRoute() string {
+	return fmt.Sprintf("/testing")
+}
+
 func (c *Client4) GetPreferencesRoute(userId string) string {
 	return fmt.Sprintf(c.GetUserRoute(userId) + "/preferences")
 }
@@ -218,6 +376,14 @@ func (c *Client4) GetLdapRoute() string {
 	return fmt.Sprintf("/ldap")
 }
 
+func (c *Client4) GetLdapGroupsRoute() string {
+	return c.GetLdapRoute() + "/groups"
+}
+
+func (c *Client4) GetLdapGroupRoute(groupId string) string {
+	return fmt.Sprintf(c.GetLdapGroupsRoute()+"/%v", groupId)
+}
+
 func (c *Client4) GetBrandRoute() string {
 	return fmt.Sprintf("/brand")
 }
@@ -234,6 +400,26 @@ func (c *Client4) GetEmojisRoute() string {
 	return fmt.Sprintf("/emoji")
 }
 
+func (c *Client4) GetEmojiRoute(emojiId string) string {
+	return fmt.Sprintf(c.GetEmojisRoute()+"/%v", emojiId)
+}
+
+func (c *Client4) GetEventsRoute() string {
+	return fmt.Sprintf("/events")
+}
+
+func (c *Client4) GetChannelCategoriesRoute(userId, teamId string) string {
+	return c.GetUserRoute(userId) + c.GetTeamRoute(teamId) + "/channels/categories"
+}
+
+func (c *Client4) GetRemoteClustersRoute() string {
+	return "/remotecluster"
+}
+
+func (c *Client4) GetRemoteClusterRoute(remoteId string) string {
+	return c.GetRemoteClustersRoute() + "/" + remoteId
+}
+
 func (c *Client4) DoApiGet(url string, etag string) (*http.Response, *AppError) {
 	return c.DoApiRequest(http.MethodGet, url, "", etag)
 }
@@ -262,6 +448,10 @@ func (c *Client4) DoApiRequest(method, url, data, etag string) (*http.Response,
 		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
 	}
 
+	if len(c.ClientVersion) > 0 {
+		rq.Header.Set(HEADER_CLIENT_VERSION, c.ClientVersion)
+	}
+
 	if rp, err := c.HttpClient.Do(rq); err != nil {
 		return nil, NewLocAppError(url, "model.client.connecting.app_error", nil, err.Error())
 	} else if rp.StatusCode == 304 {
@@ -385,6 +575,16 @@ func (c *Client4) LoginWithDevice(loginId string, password string, deviceId stri
 	return c.login(m)
 }
 
+// LoginWithMFA authenticates a user by login id, password and a second factor, which is either an
+// MFA code or the JSON of a security key's assertion.
+func (c *Client4) LoginWithMFA(loginId, password, mfaToken string) (*User, *Response) {
+	m := make(map[string]string)
+	m["login_id"] = loginId
+	m["password"] = password
+	m["token"] = mfaToken
+	return c.login(m)
+}
+
 func (c *Client4) login(m map[string]string) (*User, *Response) {
 	if r, err := c.DoApiPost("/users/login", MapToJson(m)); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
@@ -667,6 +867,81 @@ func (c *Client4) GenerateMfaSecret(userId string) (*MfaSecret, *Response) {
 	}
 }
 
+// GetMfaBackupCodeCount returns how many MFA backup codes a user has left.
+func (c *Client4) GetMfaBackupCodeCount(userId string) (*MfaBackupCodes, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/mfa/backup_codes", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MfaBackupCodesFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RegenerateMfaBackupCodes replaces a user's MFA backup codes with new ones. The codes are only
+// returned by this call.
+func (c *Client4) RegenerateMfaBackupCodes(userId string) (*MfaBackupCodes, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/mfa/backup_codes/regenerate", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MfaBackupCodesFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// BeginWebAuthnRegistration returns the options for registering a new security key for a user with
+// navigator.credentials.create. Must be logged in as the user.
+func (c *Client4) BeginWebAuthnRegistration(userId string) (*WebAuthnCreationOptions, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/webauthn/register/begin", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return WebAuthnCreationOptionsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// FinishWebAuthnRegistration registers a security key for a user with its response to the options
+// from BeginWebAuthnRegistration.
+func (c *Client4) FinishWebAuthnRegistration(userId string, attestation *WebAuthnAttestation) (*UserCredential, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/webauthn/register/finish", attestation.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserCredentialFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUserCredentials returns the security keys a user has registered.
+func (c *Client4) GetUserCredentials(userId string) ([]*UserCredential, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/webauthn/credentials", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserCredentialListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteUserCredential removes one of a user's security keys.
+func (c *Client4) DeleteUserCredential(userId, credentialId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetUserRoute(userId) + "/webauthn/credentials/" + credentialId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// BeginWebAuthnLogin returns the options for logging in with a security key with
+// navigator.credentials.get. The key's assertion is then passed as the MFA token to LoginWithMFA.
+func (c *Client4) BeginWebAuthnLogin(loginId string) (*WebAuthnRequestOptions, *Response) {
+	requestBody := map[string]string{"login_id": loginId}
+	if r, err := c.DoApiPost(c.GetUsersRoute()+"/webauthn/login/begin", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return WebAuthnRequestOptionsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // UpdateUserPassword updates a user's password. Must be logged in as the user or be a system administrator.
 func (c *Client4) UpdateUserPassword(userId, currentPassword, newPassword string) (bool, *Response) {
 	requestBody := map[string]string{"current_password": currentPassword, "new_password": newPassword}
@@ -689,6 +964,26 @@ func (c *Client4) UpdateUserRoles(userId, roles string) (bool, *Response) {
 	}
 }
 
+// PromoteGuestToUser gives a guest the regular roles on the system and on all of their teams and channels.
+func (c *Client4) PromoteGuestToUser(userId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/promote", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// DemoteUserToGuest restricts a user to the channels they are already a member of.
+func (c *Client4) DemoteUserToGuest(userId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/demote", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // DeleteUser deactivates a user in the system based on the provided user id string.
 func (c *Client4) DeleteUser(userId string) (bool, *Response) {
 	if r, err := c.DoApiDelete(c.GetUserRoute(userId)); err != nil {
@@ -743,6 +1038,78 @@ func (c *Client4) RevokeSession(userId, sessionId string) (bool, *Response) {
 	}
 }
 
+// RevokeOtherSessions revokes all of a user's sessions except for the one making the request.
+func (c *Client4) RevokeOtherSessions(userId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/sessions/revoke/others", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// UpdateSessionLabel names one of a user's sessions. An empty label removes the name.
+func (c *Client4) UpdateSessionLabel(userId, sessionId, label string) (*Session, *Response) {
+	requestBody := map[string]string{"session_id": sessionId, "label": label}
+	if r, err := c.DoApiPut(c.GetUserRoute(userId)+"/sessions/label", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SessionFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateUserAccessToken creates an access token for a user. The token is only returned by this call.
+func (c *Client4) CreateUserAccessToken(userId string, description string) (*UserAccessToken, *Response) {
+	token := &UserAccessToken{Description: description}
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/tokens", token.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUserAccessTokens returns the access tokens of a user without their values.
+func (c *Client4) GetUserAccessTokens(userId string) ([]*UserAccessToken, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/tokens", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DisableUserAccessToken stops an access token of a user from authenticating until it is enabled again.
+func (c *Client4) DisableUserAccessToken(userId string, tokenId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/tokens/"+tokenId+"/disable", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// EnableUserAccessToken enables a disabled access token of a user.
+func (c *Client4) EnableUserAccessToken(userId string, tokenId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/tokens/"+tokenId+"/enable", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// RevokeUserAccessToken permanently deletes an access token of a user.
+func (c *Client4) RevokeUserAccessToken(userId string, tokenId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetUserRoute(userId) + "/tokens/" + tokenId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // AttachDeviceId attaches a mobile device ID to the current session.
 func (c *Client4) AttachDeviceId(deviceId string) (bool, *Response) {
 	requestBody := map[string]string{"device_id": deviceId}
@@ -955,6 +1322,18 @@ func (c *Client4) PatchTeam(teamId string, patch *TeamPatch) (*Team, *Response)
 	}
 }
 
+// UpdateTeamName changes the name used in the team's URL. The old name keeps
+// resolving to the team for a grace period.
+func (c *Client4) UpdateTeamName(teamId, name string) (*Team, *Response) {
+	requestBody := map[string]string{"name": name}
+	if r, err := c.DoApiPut(c.GetTeamRoute(teamId)+"/name", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // SoftDeleteTeam deletes the team softly (archive only, not permanent delete).
 func (c *Client4) SoftDeleteTeam(teamId string) (bool, *Response) {
 	if r, err := c.DoApiDelete(c.GetTeamRoute(teamId)); err != nil {
@@ -1035,6 +1414,52 @@ func (c *Client4) AddTeamMembers(teamId string, userIds []string) ([]*TeamMember
 	}
 }
 
+// AddTeamMembersBatch adds the given users to a team, optionally with the given roles. Large
+// batches are processed in the background and can be followed with GetTeamMembersBatchJob.
+func (c *Client4) AddTeamMembersBatch(teamId string, userIds []string, roles string) (*TeamMembersBatchJob, *Response) {
+	batch := &TeamMembersBatch{UserIds: userIds, Roles: roles}
+	if r, err := c.DoApiPost(c.GetTeamMembersBatchRoute(teamId)+"/add", batch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamMembersBatchJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RemoveTeamMembersBatch removes the given users from a team. Large batches are processed in
+// the background and can be followed with GetTeamMembersBatchJob.
+func (c *Client4) RemoveTeamMembersBatch(teamId string, userIds []string) (*TeamMembersBatchJob, *Response) {
+	batch := &TeamMembersBatch{UserIds: userIds}
+	if r, err := c.DoApiPost(c.GetTeamMembersBatchRoute(teamId)+"/remove", batch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamMembersBatchJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateTeamMembersRolesBatch sets the roles of the given team members. Large batches are
+// processed in the background and can be followed with GetTeamMembersBatchJob.
+func (c *Client4) UpdateTeamMembersRolesBatch(teamId string, userIds []string, roles string) (*TeamMembersBatchJob, *Response) {
+	batch := &TeamMembersBatch{UserIds: userIds, Roles: roles}
+	if r, err := c.DoApiPost(c.GetTeamMembersBatchRoute(teamId)+"/roles", batch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamMembersBatchJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTeamMembersBatchJob returns the status and result report of a batch of team member changes.
+func (c *Client4) GetTeamMembersBatchJob(teamId, jobId string) (*TeamMembersBatchJob, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamMembersBatchRoute(teamId)+"/jobs/"+jobId, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamMembersBatchJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // RemoveTeamMember will remove a user from a team.
 func (c *Client4) RemoveTeamMember(teamId, userId string) (bool, *Response) {
 	if r, err := c.DoApiDelete(c.GetTeamMemberRoute(teamId, userId)); err != nil {
@@ -1108,39 +1533,194 @@ func (c *Client4) InviteUsersToTeam(teamId string, userEmails []string) (bool, *
 	}
 }
 
-// Channel Section
+// InviteGuestsToTeam will send invite emails to join a team as guests with access to only the invited channels.
+func (c *Client4) InviteGuestsToTeam(teamId string, invite *GuestsInvite) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetTeamRoute(teamId)+"/invite-guests/email", invite.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
 
-// CreateChannel creates a channel based on the provided channel struct.
-func (c *Client4) CreateChannel(channel *Channel) (*Channel, *Response) {
-	if r, err := c.DoApiPost(c.GetChannelsRoute(), channel.ToJson()); err != nil {
+// GetPendingInvitations returns a page of the email invitations to a team that haven't been accepted
+// and haven't expired, most recently sent first.
+func (c *Client4) GetPendingInvitations(teamId string, page int, perPage int) ([]*Invitation, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetInvitationsRoute(teamId)+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return ChannelFromJson(r.Body), BuildResponse(r)
+		return InvitationListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// UpdateChannel update a channel based on the provided channel struct.
-func (c *Client4) UpdateChannel(channel *Channel) (*Channel, *Response) {
-	if r, err := c.DoApiPut(c.GetChannelRoute(channel.Id), channel.ToJson()); err != nil {
+// ResendInvitation sends an invitation again with a new link. The link that was sent before stops working.
+func (c *Client4) ResendInvitation(teamId, invitationId string) (*Invitation, *Response) {
+	if r, err := c.DoApiPost(c.GetInvitationRoute(teamId, invitationId)+"/resend", ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return ChannelFromJson(r.Body), BuildResponse(r)
+		return InvitationFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// PatchChannel partially updates a channel. Any missing fields are not updated.
-func (c *Client4) PatchChannel(channelId string, patch *ChannelPatch) (*Channel, *Response) {
-	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/patch", patch.ToJson()); err != nil {
-		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+// RevokeInvitation deletes an invitation so that its link can't be used to join the team anymore.
+func (c *Client4) RevokeInvitation(teamId, invitationId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetInvitationRoute(teamId, invitationId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return ChannelFromJson(r.Body), BuildResponse(r)
+		return CheckStatusOK(r), BuildResponse(r)
 	}
 }
 
-// CreateDirectChannel creates a direct message channel based on the two user
+// Channel Section
+
+// CreateChannel creates a channel based on the provided channel struct.
+func (c *Client4) CreateChannel(channel *Channel) (*Channel, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelsRoute(), channel.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannel update a channel based on the provided channel struct.
+func (c *Client4) UpdateChannel(channel *Channel) (*Channel, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channel.Id), channel.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// PatchChannel partially updates a channel. Any missing fields are not updated.
+func (c *Client4) PatchChannel(channelId string, patch *ChannelPatch) (*Channel, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/patch", patch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannelAllowedReactions restricts the emoji that can be used as reactions in a channel. An
+// empty list allows any emoji.
+func (c *Client4) UpdateChannelAllowedReactions(channelId string, emojiNames []string) (*Channel, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/reactions/allowed", ArrayToJson(emojiNames)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannelIntegrationOverrides forbids or allows webhooks, slash commands and bots to post in
+// a channel with their own username and icon.
+func (c *Client4) UpdateChannelIntegrationOverrides(channelId string, forbidden bool) (*Channel, *Response) {
+	requestBody := map[string]interface{}{"forbidden": forbidden}
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/integration_overrides", StringInterfaceToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannelModerationSettings restricts posting, reacting and channel wide mentions for the
+// members and guests of a channel.
+func (c *Client4) UpdateChannelModerationSettings(channelId string, settings *ChannelModerationSettings) (*Channel, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/moderation", settings.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannelAnnouncementSettings turns a channel into an announcement channel that only its
+// designated posters and admins can post in. Every team member is added to it and kept in it when
+// autoAddTeamMembers is true.
+func (c *Client4) UpdateChannelAnnouncementSettings(channelId string, isAnnouncement bool, autoAddTeamMembers bool) (*Channel, *Response) {
+	requestBody := map[string]interface{}{"is_announcement": isAnnouncement, "auto_add_team_members": autoAddTeamMembers}
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/announcement", StringInterfaceToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateChannelAnnouncementPosters sets the members of an announcement channel that can post in it.
+func (c *Client4) UpdateChannelAnnouncementPosters(channelId string, userIds []string) (*Channel, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/announcement/posters", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ConvertChannelToPrivate makes a public channel private.
+func (c *Client4) ConvertChannelToPrivate(channelId string) (*Channel, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/convert", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ConvertChannelToPublic makes a private channel public, which exposes its history
+// to everyone on the team. The server only allows it when it's turned on in the config.
+func (c *Client4) ConvertChannelToPublic(channelId string) (*Channel, *Response) {
+	requestBody := map[string]interface{}{"confirm": true}
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/convert_to_public", StringInterfaceToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ConvertGroupMessageToChannel turns a group message into a private channel on a team,
+// keeping its members and history.
+func (c *Client4) ConvertGroupMessageToChannel(channelId, teamId, name, displayName string) (*Channel, *Response) {
+	requestBody := map[string]string{"team_id": teamId, "name": name, "display_name": displayName}
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/convert_to_channel", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AddGroupMessageParticipants adds users to a group message, which is renamed
+// after its new members.
+func (c *Client4) AddGroupMessageParticipants(channelId string, userIds []string) (*Channel, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/participants", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RemoveGroupMessageParticipant removes a user from a group message, which is
+// renamed after its remaining members.
+func (c *Client4) RemoveGroupMessageParticipant(channelId, userId string) (*Channel, *Response) {
+	if r, err := c.DoApiDelete(c.GetChannelRoute(channelId) + "/participants/" + userId); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateDirectChannel creates a direct message channel based on the two user
 // ids provided.
 func (c *Client4) CreateDirectChannel(userId1, userId2 string) (*Channel, *Response) {
 	requestBody := []string{userId1, userId2}
@@ -1182,6 +1762,30 @@ func (c *Client4) GetPinnedPosts(channelId string, etag string) (*PostList, *Res
 	}
 }
 
+// ExportChannel gets a zip archive of a channel as it was at the given time in milliseconds,
+// or as it is now if asOf is 0. Must have manage_system permission.
+func (c *Client4) ExportChannel(channelId string, asOf int64) ([]byte, *Response) {
+	query := fmt.Sprintf("?as_of=%v", asOf)
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/export"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("ExportChannel", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		return data, BuildResponse(r)
+	}
+}
+
+// VerifyChannelPostIntegrity checks the posts of a channel against its integrity chain and returns
+// the entries that don't match. Must have manage_system permission.
+func (c *Client4) VerifyChannelPostIntegrity(channelId string) (*PostIntegrityVerification, *Response) {
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/integrity", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostIntegrityVerificationFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPublicChannelsForTeam returns a list of public channels based on the provided team id string.
 func (c *Client4) GetPublicChannelsForTeam(teamId string, page int, perPage int, etag string) (*ChannelList, *Response) {
 	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
@@ -1317,6 +1921,17 @@ func (c *Client4) GetChannelUnread(channelId, userId string) (*ChannelUnread, *R
 	}
 }
 
+// GetChannelLastRead returns the last post a user read in a channel along with the number of
+// unread posts and mentions after it.
+func (c *Client4) GetChannelLastRead(channelId, userId string) (*ChannelLastRead, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+c.GetChannelRoute(channelId)+"/last_read", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelLastReadFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // UpdateChannelRoles will update the roles on a channel for a user.
 func (c *Client4) UpdateChannelRoles(channelId, userId, roles string) (bool, *Response) {
 	requestBody := map[string]string{"roles": roles}
@@ -1328,6 +1943,19 @@ func (c *Client4) UpdateChannelRoles(channelId, userId, roles string) (bool, *Re
 	}
 }
 
+// AssignChannelAdmin makes a user a channel admin of a channel, adding them to the channel if they
+// aren't a member of it. If userId is empty, the longest tenured member of the channel is made a
+// channel admin instead. Must have manage_team permission for the channel's team.
+func (c *Client4) AssignChannelAdmin(channelId, userId string) (*ChannelMember, *Response) {
+	requestBody := map[string]string{"user_id": userId}
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/admin", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelMemberFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // UpdateChannelNotifyProps will update the notification properties on a channel for a user.
 func (c *Client4) UpdateChannelNotifyProps(channelId, userId string, props map[string]string) (bool, *Response) {
 	if r, err := c.DoApiPut(c.GetChannelMemberRoute(channelId, userId)+"/notify_props", MapToJson(props)); err != nil {
@@ -1349,6 +1977,26 @@ func (c *Client4) AddChannelMember(channelId, userId string) (*ChannelMember, *R
 	}
 }
 
+// AddChannelMembers adds many users to a channel at once and returns which were added and which were skipped.
+func (c *Client4) AddChannelMembers(channelId string, userIds []string) (*ChannelMembersBatchResult, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelMembersRoute(channelId)+"/batch", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelMembersBatchResultFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RemoveChannelMembers removes many users from a channel at once and returns which were removed and which were skipped.
+func (c *Client4) RemoveChannelMembers(channelId string, userIds []string) (*ChannelMembersBatchResult, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelMembersRoute(channelId)+"/batch/remove", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelMembersBatchResultFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // RemoveUserFromChannel will delete the channel member object for a user, effectively removing the user from a channel.
 func (c *Client4) RemoveUserFromChannel(channelId, userId string) (bool, *Response) {
 	if r, err := c.DoApiDelete(c.GetChannelMemberRoute(channelId, userId)); err != nil {
@@ -1371,6 +2019,29 @@ func (c *Client4) CreatePost(post *Post) (*Post, *Response) {
 	}
 }
 
+// CreateReplyAlsoInChannel creates a reply to a thread along with a copy of it at the root of the
+// channel. Later edits and deletes of either post apply to both of them.
+func (c *Client4) CreateReplyAlsoInChannel(post *Post) (*Post, *Response) {
+	if r, err := c.DoApiPost(c.GetPostsRoute()+"?also_send_to_channel=true", post.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateCrossPosts creates a copy of the post in each of the given channels. Later edits and
+// deletes of any of the copies apply to all of them.
+func (c *Client4) CreateCrossPosts(post *Post, channelIds []string) (*PostList, *Response) {
+	request := &CrossPostRequest{Post: post, ChannelIds: channelIds}
+	if r, err := c.DoApiPost(c.GetPostsRoute()+"/multi", request.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // UpdatePost updates a post based on the provided post struct.
 func (c *Client4) UpdatePost(postId string, post *Post) (*Post, *Response) {
 	if r, err := c.DoApiPut(c.GetPostRoute(postId), post.ToJson()); err != nil {
@@ -1411,6 +2082,17 @@ func (c *Client4) UnpinPost(postId string) (bool, *Response) {
 	}
 }
 
+// DoPostAction performs an action from one of a post's attachments, such as silencing an
+// Alertmanager alert. The duration is only used by silence actions and may be empty.
+func (c *Client4) DoPostAction(postId, actionId, duration string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/actions/"+actionId, MapToJson(map[string]string{"duration": duration})); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // GetPost gets a single post.
 func (c *Client4) GetPost(postId string, etag string) (*Post, *Response) {
 	if r, err := c.DoApiGet(c.GetPostRoute(postId), etag); err != nil {
@@ -1431,6 +2113,17 @@ func (c *Client4) DeletePost(postId string) (bool, *Response) {
 	}
 }
 
+// RestorePost brings back a deleted post along with the replies and reactions that were deleted
+// with it. Must have manage_system permission.
+func (c *Client4) RestorePost(postId string) (*Post, *Response) {
+	if r, err := c.DoApiPost(c.GetPostRoute(postId)+"/restore", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPostThread gets a post with all the other posts in the same thread.
 func (c *Client4) GetPostThread(postId string, etag string) (*PostList, *Response) {
 	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/thread", etag); err != nil {
@@ -1441,6 +2134,26 @@ func (c *Client4) GetPostThread(postId string, etag string) (*PostList, *Respons
 	}
 }
 
+// GetPostHistory gets the previous versions of a post, most recently replaced first.
+func (c *Client4) GetPostHistory(postId string) ([]*PostHistory, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/history", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostHistoryListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPostAcks gets the read receipts of a post, one for each user who has seen it.
+func (c *Client4) GetPostAcks(postId string) ([]*PostAck, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/acks", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostAckListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPostsForChannel gets a page of posts with an array for ordering for a channel.
 func (c *Client4) GetPostsForChannel(channelId string, page, perPage int, etag string) (*PostList, *Response) {
 	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
@@ -1625,6 +2338,17 @@ func (c *Client4) GetFileInfosForPost(postId string, etag string) ([]*FileInfo,
 	}
 }
 
+// SearchFiles returns the files in the user's channels on the team whose name or contents match the terms string.
+func (c *Client4) SearchFiles(teamId string, terms string, isOrSearch bool) ([]*FileInfo, *Response) {
+	requestBody := map[string]string{"terms": terms, "is_or_search": strconv.FormatBool(isOrSearch)}
+	if r, err := c.DoApiPost(c.GetTeamRoute(teamId)+"/files/search", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return FileInfosFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // General Section
 
 // GetPing will ping the server and to see if it is up and running.
@@ -1699,6 +2423,27 @@ func (c *Client4) DatabaseRecycle() (bool, *Response) {
 	}
 }
 
+// GetSlowQueries returns the database queries that were slow on the server, with their string
+// literals redacted. Must have manage_system permission.
+func (c *Client4) GetSlowQueries() ([]*SlowQuery, *Response) {
+	if r, err := c.DoApiGet(c.GetDatabaseRoute()+"/slow_queries", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SlowQueryListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ClearSlowQueries empties the slow query log of the server. Must have manage_system permission.
+func (c *Client4) ClearSlowQueries() (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetDatabaseRoute() + "/slow_queries"); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // InvalidateCaches will purge the cache and can affect the performance while is cleaning.
 func (c *Client4) InvalidateCaches() (bool, *Response) {
 	if r, err := c.DoApiPost(c.GetCacheRoute()+"/invalidate", ""); err != nil {
@@ -1709,6 +2454,26 @@ func (c *Client4) InvalidateCaches() (bool, *Response) {
 	}
 }
 
+// GetCacheStats will return the statistics of the in memory caches of the server.
+func (c *Client4) GetCacheStats() ([]*CacheStats, *Response) {
+	if r, err := c.DoApiGet(c.GetCacheRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CacheStatsListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// InvalidateCache will purge a single in memory cache of every server.
+func (c *Client4) InvalidateCache(cacheName string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetCacheRoute()+"/"+cacheName+"/invalidate", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
 // UpdateConfig will update the server configuration
 func (c *Client4) UpdateConfig(config *Config) (*Config, *Response) {
 	if r, err := c.DoApiPut(c.GetConfigRoute(), config.ToJson()); err != nil {
@@ -1866,68 +2631,190 @@ func (c *Client4) DeleteOutgoingWebhook(hookId string) (bool, *Response) {
 	}
 }
 
-// Preferences Section
-
-// GetPreferences returns the user's preferences.
-func (c *Client4) GetPreferences(userId string) (Preferences, *Response) {
-	if r, err := c.DoApiGet(c.GetPreferencesRoute(userId), ""); err != nil {
+// CreateAlertmanagerHook creates an Alertmanager receiver for a channel.
+func (c *Client4) CreateAlertmanagerHook(receiver *AlertmanagerReceiver) (*AlertmanagerReceiver, *Response) {
+	if r, err := c.DoApiPost(c.GetAlertmanagerHooksRoute(), receiver.ToJson()); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
-		preferences, _ := PreferencesFromJson(r.Body)
 		defer closeBody(r)
-		return preferences, BuildResponse(r)
+		return AlertmanagerReceiverFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// UpdatePreferences saves the user's preferences.
-func (c *Client4) UpdatePreferences(userId string, preferences *Preferences) (bool, *Response) {
-	if r, err := c.DoApiPut(c.GetPreferencesRoute(userId), preferences.ToJson()); err != nil {
-		return false, &Response{StatusCode: r.StatusCode, Error: err}
+// UpdateAlertmanagerHook updates an Alertmanager receiver.
+func (c *Client4) UpdateAlertmanagerHook(receiver *AlertmanagerReceiver) (*AlertmanagerReceiver, *Response) {
+	if r, err := c.DoApiPut(c.GetAlertmanagerHookRoute(receiver.Id), receiver.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return true, BuildResponse(r)
+		return AlertmanagerReceiverFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// DeletePreferences deletes the user's preferences.
-func (c *Client4) DeletePreferences(userId string, preferences *Preferences) (bool, *Response) {
-	if r, err := c.DoApiPost(c.GetPreferencesRoute(userId)+"/delete", preferences.ToJson()); err != nil {
-		return false, &Response{StatusCode: r.StatusCode, Error: err}
+// GetAlertmanagerHooksForTeam returns a page of Alertmanager receivers for a team. Page counting starts at 0.
+func (c *Client4) GetAlertmanagerHooksForTeam(teamId string, page int, perPage int) ([]*AlertmanagerReceiver, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v&team_id=%v", page, perPage, teamId)
+	if r, err := c.DoApiGet(c.GetAlertmanagerHooksRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return true, BuildResponse(r)
+		return AlertmanagerReceiverListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// GetPreferencesByCategory returns the user's preferences from the provided category string.
-func (c *Client4) GetPreferencesByCategory(userId string, category string) (Preferences, *Response) {
-	url := fmt.Sprintf(c.GetPreferencesRoute(userId)+"/%s", category)
-	if r, err := c.DoApiGet(url, ""); err != nil {
+// GetAlertmanagerHook returns an Alertmanager receiver given its id.
+func (c *Client4) GetAlertmanagerHook(hookId string) (*AlertmanagerReceiver, *Response) {
+	if r, err := c.DoApiGet(c.GetAlertmanagerHookRoute(hookId), ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
-		preferences, _ := PreferencesFromJson(r.Body)
 		defer closeBody(r)
-		return preferences, BuildResponse(r)
+		return AlertmanagerReceiverFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// GetPreferenceByCategoryAndName returns the user's preferences from the provided category and preference name string.
-func (c *Client4) GetPreferenceByCategoryAndName(userId string, category string, preferenceName string) (*Preference, *Response) {
-	url := fmt.Sprintf(c.GetPreferencesRoute(userId)+"/%s/name/%v", category, preferenceName)
-	if r, err := c.DoApiGet(url, ""); err != nil {
-		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+// DeleteAlertmanagerHook deletes an Alertmanager receiver given its id.
+func (c *Client4) DeleteAlertmanagerHook(hookId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetAlertmanagerHookRoute(hookId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return PreferenceFromJson(r.Body), BuildResponse(r)
+		return CheckStatusOK(r), BuildResponse(r)
 	}
 }
 
-// SAML Section
-
-// GetSamlMetadata returns metadata for the SAML configuration.
-func (c *Client4) GetSamlMetadata() (string, *Response) {
-	if r, err := c.DoApiGet(c.GetSamlRoute()+"/metadata", ""); err != nil {
-		return "", &Response{StatusCode: r.StatusCode, Error: err}
+// PostAlertmanagerMessage delivers an Alertmanager webhook notification to a receiver.
+func (c *Client4) PostAlertmanagerMessage(hookId string, msg *AlertmanagerMessage) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetAlertmanagerHookRoute(hookId)+"/alerts", msg.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// CreatePostEventHook subscribes a callback URL to the post events of a channel or a team.
+func (c *Client4) CreatePostEventHook(hook *PostEventHook) (*PostEventHook, *Response) {
+	if r, err := c.DoApiPost(c.GetPostEventHooksRoute(), hook.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostEventHookFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdatePostEventHook updates the events, scope and callback URL of a post event hook.
+func (c *Client4) UpdatePostEventHook(hook *PostEventHook) (*PostEventHook, *Response) {
+	if r, err := c.DoApiPut(c.GetPostEventHookRoute(hook.Id), hook.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostEventHookFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPostEventHooksForTeam returns a page of the post event hooks of a team.
+func (c *Client4) GetPostEventHooksForTeam(teamId string, page, perPage int) ([]*PostEventHook, *Response) {
+	query := fmt.Sprintf("?team_id=%v&page=%v&per_page=%v", teamId, page, perPage)
+	if r, err := c.DoApiGet(c.GetPostEventHooksRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostEventHookListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPostEventHook returns a post event hook.
+func (c *Client4) GetPostEventHook(hookId string) (*PostEventHook, *Response) {
+	if r, err := c.DoApiGet(c.GetPostEventHookRoute(hookId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostEventHookFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RegenPostEventHookSecret replaces the secret that the deliveries of a post event hook are signed with.
+func (c *Client4) RegenPostEventHookSecret(hookId string) (*PostEventHook, *Response) {
+	if r, err := c.DoApiPost(c.GetPostEventHookRoute(hookId)+"/regen_secret", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PostEventHookFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeletePostEventHook deletes a post event hook.
+func (c *Client4) DeletePostEventHook(hookId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetPostEventHookRoute(hookId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Preferences Section
+
+// GetPreferences returns the user's preferences.
+func (c *Client4) GetPreferences(userId string) (Preferences, *Response) {
+	if r, err := c.DoApiGet(c.GetPreferencesRoute(userId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		preferences, _ := PreferencesFromJson(r.Body)
+		defer closeBody(r)
+		return preferences, BuildResponse(r)
+	}
+}
+
+// UpdatePreferences saves the user's preferences.
+func (c *Client4) UpdatePreferences(userId string, preferences *Preferences) (bool, *Response) {
+	if r, err := c.DoApiPut(c.GetPreferencesRoute(userId), preferences.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return true, BuildResponse(r)
+	}
+}
+
+// DeletePreferences deletes the user's preferences.
+func (c *Client4) DeletePreferences(userId string, preferences *Preferences) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetPreferencesRoute(userId)+"/delete", preferences.ToJson()); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return true, BuildResponse(r)
+	}
+}
+
+// GetPreferencesByCategory returns the user's preferences from the provided category string.
+func (c *Client4) GetPreferencesByCategory(userId string, category string) (Preferences, *Response) {
+	url := fmt.Sprintf(c.GetPreferencesRoute(userId)+"/%s", category)
+	if r, err := c.DoApiGet(url, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		preferences, _ := PreferencesFromJson(r.Body)
+		defer closeBody(r)
+		return preferences, BuildResponse(r)
+	}
+}
+
+// GetPreferenceByCategoryAndName returns the user's preferences from the provided category and preference name string.
+func (c *Client4) GetPreferenceByCategoryAndName(userId string, category string, preferenceName string) (*Preference, *Response) {
+	url := fmt.Sprintf(c.GetPreferencesRoute(userId)+"/%s/name/%v", category, preferenceName)
+	if r, err := c.DoApiGet(url, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return PreferenceFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SAML Section
+
+// GetSamlMetadata returns metadata for the SAML configuration.
+func (c *Client4) GetSamlMetadata() (string, *Response) {
+	if r, err := c.DoApiGet(c.GetSamlRoute()+"/metadata", ""); err != nil {
+		return "", &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
 		buf := new(bytes.Buffer)
@@ -2083,231 +2970,1490 @@ func (c *Client4) DownloadComplianceReport(reportId string) ([]byte, *Response)
 	}
 }
 
-// Cluster Section
-
-// GetClusterStatus returns the status of all the configured cluster nodes.
-func (c *Client4) GetClusterStatus() ([]*ClusterInfo, *Response) {
-	if r, err := c.DoApiGet(c.GetClusterRoute()+"/status", ""); err != nil {
+// GetComplianceFormats returns the formats that compliance reports can be exported in.
+func (c *Client4) GetComplianceFormats() ([]string, *Response) {
+	if r, err := c.DoApiGet(c.GetComplianceFormatsRoute(), ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return ClusterInfosFromJson(r.Body), BuildResponse(r)
+		return ArrayFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// LDAP Section
+// Archive Export Section
 
-// SyncLdap will force a sync with the configured LDAP server.
-func (c *Client4) SyncLdap() (bool, *Response) {
-	if r, err := c.DoApiPost(c.GetLdapRoute()+"/sync", ""); err != nil {
-		return false, &Response{StatusCode: r.StatusCode, Error: err}
+// CreateArchiveExport requests an archive of a channel or a team. The archive is generated in the
+// background and can be followed with GetArchiveExport.
+func (c *Client4) CreateArchiveExport(export *ArchiveExport) (*ArchiveExport, *Response) {
+	if r, err := c.DoApiPost(c.GetArchiveExportsRoute(), export.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CheckStatusOK(r), BuildResponse(r)
+		return ArchiveExportFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// TestLdap will attempt to connect to the configured LDAP server and return OK if configured
-// correctly.
-func (c *Client4) TestLdap() (bool, *Response) {
-	if r, err := c.DoApiPost(c.GetLdapRoute()+"/test", ""); err != nil {
-		return false, &Response{StatusCode: r.StatusCode, Error: err}
+// GetArchiveExports returns a page of the archive exports, most recent first.
+func (c *Client4) GetArchiveExports(page, perPage int) ([]*ArchiveExport, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetArchiveExportsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CheckStatusOK(r), BuildResponse(r)
+		return ArchiveExportListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// Audits Section
+// GetArchiveExport returns the status and progress of an archive export.
+func (c *Client4) GetArchiveExport(exportId string) (*ArchiveExport, *Response) {
+	if r, err := c.DoApiGet(c.GetArchiveExportRoute(exportId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ArchiveExportFromJson(r.Body), BuildResponse(r)
+	}
+}
 
-// GetAudits returns a list of audits for the whole system.
-func (c *Client4) GetAudits(page int, perPage int, etag string) (Audits, *Response) {
-	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
-	if r, err := c.DoApiGet("/audits"+query, etag); err != nil {
+// DownloadArchiveExport returns the zip archive of an export that has finished successfully.
+func (c *Client4) DownloadArchiveExport(exportId string) ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetArchiveExportRoute(exportId)+"/download", ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		defer closeBody(r)
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("DownloadArchiveExport", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
 	} else {
 		defer closeBody(r)
-		return AuditsFromJson(r.Body), BuildResponse(r)
+		return data, BuildResponse(r)
 	}
 }
 
-// Brand Section
+// Inactivity Report Section
 
-// GetBrandImage retrieves the previously uploaded brand image.
-func (c *Client4) GetBrandImage() ([]byte, *Response) {
-	if r, err := c.DoApiGet(c.GetBrandRoute()+"/image", ""); err != nil {
+// GetInactiveUsersReport returns a page of the users who haven't been active for a number of days,
+// least recently active first. Must have manage_system permission.
+func (c *Client4) GetInactiveUsersReport(days int, page int, perPage int) ([]*InactiveUser, *Response) {
+	query := fmt.Sprintf("?days=%v&page=%v&per_page=%v", days, page, perPage)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/inactive_users"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return InactiveUserListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DownloadInactiveUsersReport returns every user who hasn't been active for a number of days as CSV.
+func (c *Client4) DownloadInactiveUsersReport(days int) ([]byte, *Response) {
+	query := fmt.Sprintf("?days=%v", days)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/inactive_users/download"+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
-		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("GetBrandImage", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+		defer closeBody(r)
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("DownloadInactiveUsersReport", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
 	} else {
+		defer closeBody(r)
 		return data, BuildResponse(r)
 	}
 }
 
-// UploadBrandImage sets the brand image for the system.
-func (c *Client4) UploadBrandImage(data []byte) (bool, *Response) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+// GetInactiveChannelsReport returns a page of the public and private channels that haven't been
+// posted in for a number of days, least recently posted in first. Must have manage_system permission.
+func (c *Client4) GetInactiveChannelsReport(days int, page int, perPage int) ([]*InactiveChannel, *Response) {
+	query := fmt.Sprintf("?days=%v&page=%v&per_page=%v", days, page, perPage)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/inactive_channels"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return InactiveChannelListFromJson(r.Body), BuildResponse(r)
+	}
+}
 
-	if part, err := writer.CreateFormFile("image", "brand.png"); err != nil {
-		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.no_file.app_error", nil, err.Error(), http.StatusBadRequest)}
-	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
-		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.no_file.app_error", nil, err.Error(), http.StatusBadRequest)}
+// DownloadInactiveChannelsReport returns every channel that hasn't been posted in for a number of
+// days as CSV.
+func (c *Client4) DownloadInactiveChannelsReport(days int) ([]byte, *Response) {
+	query := fmt.Sprintf("?days=%v", days)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/inactive_channels/download"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		defer closeBody(r)
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("DownloadInactiveChannelsReport", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		defer closeBody(r)
+		return data, BuildResponse(r)
 	}
+}
 
-	if err := writer.Close(); err != nil {
-		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.writer.app_error", nil, err.Error(), http.StatusBadRequest)}
+// GetOrphanedChannelsReport returns a page of the public and private channels that have no active
+// channel admins, oldest first. Must have manage_system permission.
+func (c *Client4) GetOrphanedChannelsReport(page int, perPage int) ([]*OrphanedChannel, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/orphaned_channels"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OrphanedChannelListFromJson(r.Body), BuildResponse(r)
 	}
+}
 
-	rq, _ := http.NewRequest("POST", c.ApiUrl+c.GetBrandRoute()+"/image", bytes.NewReader(body.Bytes()))
-	rq.Header.Set("Content-Type", writer.FormDataContentType())
-	rq.Close = true
+// Channel Auto Archiving Section
 
-	if len(c.AuthToken) > 0 {
-		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
+// ExemptChannelFromAutoArchiving keeps a channel from being archived for being idle. Must have
+// permission to manage the channel.
+func (c *Client4) ExemptChannelFromAutoArchiving(channelId string) (*ChannelAutoArchive, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelRoute(channelId)+"/auto_archive/exempt", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ChannelAutoArchiveFromJson(r.Body), BuildResponse(r)
 	}
+}
 
-	if rp, err := c.HttpClient.Do(rq); err != nil {
-		return false, &Response{StatusCode: http.StatusForbidden, Error: NewAppError(c.GetBrandRoute()+"/image", "model.client.connecting.app_error", nil, err.Error(), http.StatusForbidden)}
-	} else if rp.StatusCode >= 300 {
-		return false, &Response{StatusCode: rp.StatusCode, Error: AppErrorFromJson(rp.Body)}
+// RemoveChannelAutoArchiveExemption lets a channel be archived for being idle again. Must have
+// permission to manage the channel.
+func (c *Client4) RemoveChannelAutoArchiveExemption(channelId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetChannelRoute(channelId) + "/auto_archive/exempt"); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
-		defer closeBody(rp)
-		return CheckStatusOK(rp), BuildResponse(rp)
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
 	}
 }
 
-// Logs Section
-
-// GetLogs page of logs as a string array.
-func (c *Client4) GetLogs(page, perPage int) ([]string, *Response) {
+// GetUpcomingChannelArchivalsReport returns a page of the channels that will be archived for being
+// idle unless they're posted in, soonest first. Must have manage_system permission.
+func (c *Client4) GetUpcomingChannelArchivalsReport(page int, perPage int) ([]*UpcomingChannelArchival, *Response) {
 	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
-	if r, err := c.DoApiGet("/logs"+query, ""); err != nil {
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/upcoming_channel_archivals"+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return ArrayFromJson(r.Body), BuildResponse(r)
+		return UpcomingChannelArchivalListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// Commands Section
+// Retention Policy Section
 
-// CreateCommand will create a new command if the user have the right permissions.
-func (c *Client4) CreateCommand(cmd *Command) (*Command, *Response) {
-	if r, err := c.DoApiPost(c.GetCommandsRoute(), cmd.ToJson()); err != nil {
+// CreateRetentionPolicy creates a policy that overrides the global data retention settings for a
+// team or a channel. Must have manage_system permission.
+func (c *Client4) CreateRetentionPolicy(policy *RetentionPolicy) (*RetentionPolicy, *Response) {
+	if r, err := c.DoApiPost(c.GetRetentionPoliciesRoute(), policy.ToJson()); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CommandFromJson(r.Body), BuildResponse(r)
+		return RetentionPolicyFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// UpdateCommand updates a command based on the provided Command struct
-func (c *Client4) UpdateCommand(cmd *Command) (*Command, *Response) {
-	if r, err := c.DoApiPut(c.GetCommandRoute(cmd.Id), cmd.ToJson()); err != nil {
+// GetRetentionPolicies returns a page of the retention policies, team policies first. Must have
+// manage_system permission.
+func (c *Client4) GetRetentionPolicies(page, perPage int) ([]*RetentionPolicy, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetRetentionPoliciesRoute()+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CommandFromJson(r.Body), BuildResponse(r)
+		return RetentionPolicyListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// DeleteCommand deletes a command based on the provided command id string
-func (c *Client4) DeleteCommand(commandId string) (bool, *Response) {
-	if r, err := c.DoApiDelete(c.GetCommandRoute(commandId)); err != nil {
-		return false, &Response{StatusCode: r.StatusCode, Error: err}
+// GetRetentionPolicy returns a retention policy. Must have manage_system permission.
+func (c *Client4) GetRetentionPolicy(policyId string) (*RetentionPolicy, *Response) {
+	if r, err := c.DoApiGet(c.GetRetentionPolicyRoute(policyId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CheckStatusOK(r), BuildResponse(r)
+		return RetentionPolicyFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// ListCommands will retrieve a list of commands available in the team.
-func (c *Client4) ListCommands(teamId string, customOnly bool) ([]*Command, *Response) {
-	query := fmt.Sprintf("?team_id=%v&custom_only=%v", teamId, customOnly)
-	if r, err := c.DoApiGet(c.GetCommandsRoute()+query, ""); err != nil {
+// UpdateRetentionPolicy changes the number of days that a retention policy keeps messages and files
+// for. Must have manage_system permission.
+func (c *Client4) UpdateRetentionPolicy(policy *RetentionPolicy) (*RetentionPolicy, *Response) {
+	if r, err := c.DoApiPut(c.GetRetentionPolicyRoute(policy.Id), policy.ToJson()); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CommandListFromJson(r.Body), BuildResponse(r)
+		return RetentionPolicyFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// ListCommands will retrieve a list of commands available in the team.
-func (c *Client4) ListAutocompleteCommands(teamId string) ([]*Command, *Response) {
-	if r, err := c.DoApiGet(c.GetTeamAutoCompleteCommandsRoute(teamId), ""); err != nil {
+// DeleteRetentionPolicy deletes a retention policy so that the global settings apply to its team or
+// channel again. Must have manage_system permission.
+func (c *Client4) DeleteRetentionPolicy(policyId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetRetentionPolicyRoute(policyId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetRetentionPreview returns the number of messages and files that the next run of the data
+// retention job would delete, for the global settings and for each policy. Must have manage_system
+// permission.
+func (c *Client4) GetRetentionPreview() ([]*RetentionPreview, *Response) {
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/retention_preview", ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return CommandListFromJson(r.Body), BuildResponse(r)
+		return RetentionPreviewListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// Status Section
+// Legal Hold Section
 
-// GetUserStatus returns a user based on the provided user id string.
-func (c *Client4) GetUserStatus(userId, etag string) (*Status, *Response) {
-	if r, err := c.DoApiGet(c.GetUserStatusRoute(userId), etag); err != nil {
+// CreateLegalHold holds a user or a channel so that the data retention job and user initiated
+// deletes skip its content. Must have manage_system permission.
+func (c *Client4) CreateLegalHold(hold *LegalHold) (*LegalHold, *Response) {
+	if r, err := c.DoApiPost(c.GetLegalHoldsRoute(), hold.ToJson()); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return StatusFromJson(r.Body), BuildResponse(r)
+		return LegalHoldFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// GetUsersStatusesByIds returns a list of users status based on the provided user ids.
-func (c *Client4) GetUsersStatusesByIds(userIds []string) ([]*Status, *Response) {
-	if r, err := c.DoApiPost(c.GetUserStatusesRoute()+"/ids", ArrayToJson(userIds)); err != nil {
+// GetLegalHolds returns a page of the legal holds, newest first. Must have manage_system permission.
+func (c *Client4) GetLegalHolds(page, perPage int) ([]*LegalHold, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetLegalHoldsRoute()+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return StatusListFromJson(r.Body), BuildResponse(r)
+		return LegalHoldListFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// UpdateUserStatus sets a user's status based on the provided user id string.
-func (c *Client4) UpdateUserStatus(userId string, userStatus *Status) (*Status, *Response) {
-	if r, err := c.DoApiPut(c.GetUserStatusRoute(userId), userStatus.ToJson()); err != nil {
+// GetLegalHold returns a legal hold. Must have manage_system permission.
+func (c *Client4) GetLegalHold(holdId string) (*LegalHold, *Response) {
+	if r, err := c.DoApiGet(c.GetLegalHoldRoute(holdId), ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return StatusFromJson(r.Body), BuildResponse(r)
-
+		return LegalHoldFromJson(r.Body), BuildResponse(r)
 	}
 }
 
-// Emoji Section
-
-// CreateEmoji will save an emoji to the server if the current user has permission
-// to do so. If successful, the provided emoji will be returned with its Id field
-// filled in. Otherwise, an error will be returned.
-func (c *Client4) CreateEmoji(emoji *Emoji, image []byte, filename string) (*Emoji, *Response) {
+// DeleteLegalHold releases a legal hold. Must have manage_system permission.
+func (c *Client4) DeleteLegalHold(holdId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetLegalHoldRoute(holdId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// DownloadLegalHoldExport returns a CSV file of the held messages, including deleted ones, that were
+// posted after startTime and up to endTime, in milliseconds. Must have manage_system permission.
+func (c *Client4) DownloadLegalHoldExport(startTime, endTime int64) ([]byte, *Response) {
+	query := fmt.Sprintf("?start=%v&end=%v", startTime, endTime)
+	if r, err := c.DoApiGet(c.GetReportsRoute()+"/legal_hold_export"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		defer closeBody(r)
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("DownloadLegalHoldExport", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		defer closeBody(r)
+		return data, BuildResponse(r)
+	}
+}
+
+// Bulk Email Section
+
+// CreateBulkEmail starts sending a message to every user of a segment. It's sent in the background
+// and can be followed with GetBulkEmail. Must have manage_system permission.
+func (c *Client4) CreateBulkEmail(bulkEmail *BulkEmail) (*BulkEmail, *Response) {
+	if r, err := c.DoApiPost(c.GetBulkEmailsRoute(), bulkEmail.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BulkEmailFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBulkEmails returns a page of the bulk emails, most recent first.
+func (c *Client4) GetBulkEmails(page, perPage int) ([]*BulkEmail, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetBulkEmailsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BulkEmailListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBulkEmail returns the status and counts of a bulk email.
+func (c *Client4) GetBulkEmail(bulkEmailId string) (*BulkEmail, *Response) {
+	if r, err := c.DoApiGet(c.GetBulkEmailRoute(bulkEmailId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BulkEmailFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CancelBulkEmail stops a bulk email from being sent to the recipients it hasn't been sent to yet.
+func (c *Client4) CancelBulkEmail(bulkEmailId string) (*BulkEmail, *Response) {
+	if r, err := c.DoApiPost(c.GetBulkEmailRoute(bulkEmailId)+"/cancel", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BulkEmailFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBulkEmailRecipients returns a page of the recipients of a bulk email and whether it was sent to
+// them. If status isn't empty, only the recipients with that status are returned.
+func (c *Client4) GetBulkEmailRecipients(bulkEmailId string, status string, page, perPage int) ([]*BulkEmailRecipient, *Response) {
+	query := fmt.Sprintf("?status=%v&page=%v&per_page=%v", url.QueryEscape(status), page, perPage)
+	if r, err := c.DoApiGet(c.GetBulkEmailRoute(bulkEmailId)+"/recipients"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BulkEmailRecipientListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Cluster Section
+
+// GetClusterStatus returns the status of all the configured cluster nodes.
+func (c *Client4) GetClusterStatus() ([]*ClusterInfo, *Response) {
+	if r, err := c.DoApiGet(c.GetClusterRoute()+"/status", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ClusterInfosFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// LDAP Section
+
+// SyncLdap will force a sync with the configured LDAP server.
+func (c *Client4) SyncLdap() (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetLdapRoute()+"/sync", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// TestLdap will attempt to connect to the configured LDAP server and return OK if configured
+// correctly.
+func (c *Client4) TestLdap() (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetLdapRoute()+"/test", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// LinkLdapGroup links an LDAP group to a channel so that the group sync keeps the channel's
+// members in line with the group's.
+func (c *Client4) LinkLdapGroup(group *LdapGroup) (*LdapGroup, *Response) {
+	if r, err := c.DoApiPost(c.GetLdapGroupsRoute(), group.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return LdapGroupFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetLdapGroups returns the LDAP groups linked to a channel, or every linked group if channelId
+// is empty.
+func (c *Client4) GetLdapGroups(channelId string) ([]*LdapGroup, *Response) {
+	query := ""
+	if len(channelId) > 0 {
+		query = "?channel_id=" + channelId
+	}
+
+	if r, err := c.DoApiGet(c.GetLdapGroupsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return LdapGroupListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UnlinkLdapGroup removes the link between an LDAP group and a channel. The members of the channel
+// are left as they are.
+func (c *Client4) UnlinkLdapGroup(groupId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetLdapGroupRoute(groupId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// SyncLdapGroups synchronizes the channels linked to LDAP groups right away and returns the changes
+// that were made. With dryRun, nothing is changed and the changes that would be made are returned.
+func (c *Client4) SyncLdapGroups(dryRun bool) (*LdapGroupSyncResult, *Response) {
+	if r, err := c.DoApiPost(c.GetLdapGroupsRoute()+fmt.Sprintf("/sync?dry_run=%v", dryRun), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return LdapGroupSyncResultFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Audits Section
+
+// GetAudits returns a list of audits for the whole system.
+func (c *Client4) GetAudits(page int, perPage int, etag string) (Audits, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet("/audits"+query, etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return AuditsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SearchAudits returns a page of the audits for the whole system that match a filter, most recent first.
+func (c *Client4) SearchAudits(filter *AuditFilter, page int, perPage int) (Audits, *Response) {
+	query := filter.ToQuery()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	if r, err := c.DoApiGet("/audits?"+query.Encode(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return AuditsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Brand Section
+
+// GetBrandImage retrieves the previously uploaded brand image.
+func (c *Client4) GetBrandImage() ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetBrandRoute()+"/image", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else if data, err := ioutil.ReadAll(r.Body); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: NewAppError("GetBrandImage", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode)}
+	} else {
+		return data, BuildResponse(r)
+	}
+}
+
+// UploadBrandImage sets the brand image for the system.
+func (c *Client4) UploadBrandImage(data []byte) (bool, *Response) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	if part, err := writer.CreateFormFile("image", filename); err != nil {
-		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error())}
-	} else if _, err = io.Copy(part, bytes.NewBuffer(image)); err != nil {
-		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error())}
+	if part, err := writer.CreateFormFile("image", "brand.png"); err != nil {
+		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.no_file.app_error", nil, err.Error(), http.StatusBadRequest)}
+	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
+		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.no_file.app_error", nil, err.Error(), http.StatusBadRequest)}
 	}
 
-	if err := writer.WriteField("emoji", emoji.ToJson()); err != nil {
-		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.emoji.app_error", nil, err.Error())}
+	if err := writer.Close(); err != nil {
+		return false, &Response{Error: NewAppError("UploadBrandImage", "model.client.set_profile_user.writer.app_error", nil, err.Error(), http.StatusBadRequest)}
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.writer.app_error", nil, err.Error())}
+	rq, _ := http.NewRequest("POST", c.ApiUrl+c.GetBrandRoute()+"/image", bytes.NewReader(body.Bytes()))
+	rq.Header.Set("Content-Type", writer.FormDataContentType())
+	rq.Close = true
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
 	}
 
-	return c.DoEmojiUploadFile(c.GetEmojisRoute(), body.Bytes(), writer.FormDataContentType())
+	if rp, err := c.HttpClient.Do(rq); err != nil {
+		return false, &Response{StatusCode: http.StatusForbidden, Error: NewAppError(c.GetBrandRoute()+"/image", "model.client.connecting.app_error", nil, err.Error(), http.StatusForbidden)}
+	} else if rp.StatusCode >= 300 {
+		return false, &Response{StatusCode: rp.StatusCode, Error: AppErrorFromJson(rp.Body)}
+	} else {
+		defer closeBody(rp)
+		return CheckStatusOK(rp), BuildResponse(rp)
+	}
 }
 
-// GetEmojiList returns a list of custom emoji in the system.
-func (c *Client4) GetEmojiList() ([]*Emoji, *Response) {
-	if r, err := c.DoApiGet(c.GetEmojisRoute(), ""); err != nil {
+// Logs Section
+
+// GetLogs page of logs as a string array.
+func (c *Client4) GetLogs(page, perPage int) ([]string, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet("/logs"+query, ""); err != nil {
 		return nil, &Response{StatusCode: r.StatusCode, Error: err}
 	} else {
 		defer closeBody(r)
-		return EmojiListFromJson(r.Body), BuildResponse(r)
+		return ArrayFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetOutdatedClients returns the outdated versions of apps that have tried to connect to the server.
+// Must have manage_system permission.
+func (c *Client4) GetOutdatedClients() ([]*OutdatedClient, *Response) {
+	if r, err := c.DoApiGet(c.GetSystemRoute()+"/outdated_clients", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return OutdatedClientListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Commands Section
+
+// CreateCommand will create a new command if the user have the right permissions.
+func (c *Client4) CreateCommand(cmd *Command) (*Command, *Response) {
+	if r, err := c.DoApiPost(c.GetCommandsRoute(), cmd.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateCommand updates a command based on the provided Command struct
+func (c *Client4) UpdateCommand(cmd *Command) (*Command, *Response) {
+	if r, err := c.DoApiPut(c.GetCommandRoute(cmd.Id), cmd.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteCommand deletes a command based on the provided command id string
+func (c *Client4) DeleteCommand(commandId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetCommandRoute(commandId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// ListCommands will retrieve a list of commands available in the team.
+func (c *Client4) ListCommands(teamId string, customOnly bool) ([]*Command, *Response) {
+	query := fmt.Sprintf("?team_id=%v&custom_only=%v", teamId, customOnly)
+	if r, err := c.DoApiGet(c.GetCommandsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ListCommands will retrieve a list of commands available in the team.
+func (c *Client4) ListAutocompleteCommands(teamId string) ([]*Command, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamAutoCompleteCommandsRoute(teamId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CommandListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Status Section
+
+// GetUserStatus returns a user based on the provided user id string.
+func (c *Client4) GetUserStatus(userId, etag string) (*Status, *Response) {
+	if r, err := c.DoApiGet(c.GetUserStatusRoute(userId), etag); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetUsersStatusesByIds returns a list of users status based on the provided user ids.
+func (c *Client4) GetUsersStatusesByIds(userIds []string) ([]*Status, *Response) {
+	if r, err := c.DoApiPost(c.GetUserStatusesRoute()+"/ids", ArrayToJson(userIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateUserStatus sets a user's status based on the provided user id string.
+func (c *Client4) UpdateUserStatus(userId string, userStatus *Status) (*Status, *Response) {
+	if r, err := c.DoApiPut(c.GetUserStatusRoute(userId), userStatus.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusFromJson(r.Body), BuildResponse(r)
+
+	}
+}
+
+// UpdateUserCustomStatus sets the custom status shown next to a user's status. It is
+// cleared automatically once its expiry time has passed, if it has one.
+func (c *Client4) UpdateUserCustomStatus(userId string, customStatus *CustomStatus) (*Status, *Response) {
+	if r, err := c.DoApiPut(c.GetUserStatusRoute(userId)+"/custom", customStatus.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StatusFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RemoveUserCustomStatus clears the custom status of a user.
+func (c *Client4) RemoveUserCustomStatus(userId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetUserStatusRoute(userId) + "/custom"); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetUserAutoResponder returns the auto responder of a user, which is turned off if they've never set one.
+func (c *Client4) GetUserAutoResponder(userId string) (*AutoResponder, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/auto_responder", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return AutoResponderFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateUserAutoResponder sets the auto responder of a user. While it's replying to messages, the
+// user's status is out of office.
+func (c *Client4) UpdateUserAutoResponder(userId string, responder *AutoResponder) (*AutoResponder, *Response) {
+	if r, err := c.DoApiPut(c.GetUserRoute(userId)+"/auto_responder", responder.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return AutoResponderFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Emoji Section
+
+// CreateEmoji will save an emoji to the server if the current user has permission
+// to do so. If successful, the provided emoji will be returned with its Id field
+// filled in. Otherwise, an error will be returned.
+func (c *Client4) CreateEmoji(emoji *Emoji, image []byte, filename string) (*Emoji, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if part, err := writer.CreateFormFile("image", filename); err != nil {
+		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error())}
+	} else if _, err = io.Copy(part, bytes.NewBuffer(image)); err != nil {
+		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.image.app_error", nil, err.Error())}
+	}
+
+	if err := writer.WriteField("emoji", emoji.ToJson()); err != nil {
+		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.emoji.app_error", nil, err.Error())}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, &Response{StatusCode: http.StatusForbidden, Error: NewLocAppError("CreateEmoji", "model.client.create_emoji.writer.app_error", nil, err.Error())}
+	}
+
+	return c.DoEmojiUploadFile(c.GetEmojisRoute(), body.Bytes(), writer.FormDataContentType())
+}
+
+// GetEmojiList returns a list of custom emoji in the system.
+func (c *Client4) GetEmojiList() ([]*Emoji, *Response) {
+	if r, err := c.DoApiGet(c.GetEmojisRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// PatchEmoji partially updates the category and aliases of a custom emoji. Only fields that are set
+// in the patch are changed. Must be the creator of the emoji or have manage_system permission.
+func (c *Client4) PatchEmoji(emojiId string, patch *EmojiPatch) (*Emoji, *Response) {
+	if r, err := c.DoApiPut(c.GetEmojiRoute(emojiId)+"/patch", patch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SearchEmoji returns a page of the custom emoji with a name or alias that matches the search.
+func (c *Client4) SearchEmoji(search *EmojiSearch, perPage int) ([]*Emoji, *Response) {
+	query := fmt.Sprintf("?per_page=%v", perPage)
+	if r, err := c.DoApiPost(c.GetEmojisRoute()+"/search"+query, search.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AutocompleteEmoji returns the custom emoji with a name or alias that starts with the given name.
+func (c *Client4) AutocompleteEmoji(name string) ([]*Emoji, *Response) {
+	query := fmt.Sprintf("?name=%v", url.QueryEscape(name))
+	if r, err := c.DoApiGet(c.GetEmojisRoute()+"/autocomplete"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetEmojiStats returns how often a custom emoji has been used in reactions and messages.
+func (c *Client4) GetEmojiStats(emojiName string) (*EmojiStats, *Response) {
+	if r, err := c.DoApiGet(c.GetEmojisRoute()+"/"+emojiName+"/stats", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiStatsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetAnalyticsOld returns the system analytics with the given name, such as standard, post_counts_day,
+// reaction_counts or top_reactions, for a team if a team id is given. Must have manage_system
+// permission.
+func (c *Client4) GetAnalyticsOld(name, teamId string) (AnalyticsRows, *Response) {
+	query := fmt.Sprintf("?name=%v&team_id=%v", url.QueryEscape(name), teamId)
+	if r, err := c.DoApiGet(c.GetAnalyticsRoute()+"/old"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return AnalyticsRowsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetEmojiAnalytics returns the usage of every custom emoji, most used first.
+func (c *Client4) GetEmojiAnalytics() ([]*EmojiStats, *Response) {
+	if r, err := c.DoApiGet(c.GetAnalyticsRoute()+"/emoji", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiStatsListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetFrequentlyUsedEmoji returns the emoji a user has used most in reactions and messages recently,
+// most used first.
+func (c *Client4) GetFrequentlyUsedEmoji(userId string, limit int) ([]*EmojiUsageCount, *Response) {
+	query := fmt.Sprintf("?limit=%v", limit)
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/emoji/frequent"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return EmojiUsageCountListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Reactions Section
+
+// GetBulkReactions returns the reactions for each of the given posts keyed by post id.
+func (c *Client4) GetBulkReactions(postIds []string) (map[string][]*Reaction, *Response) {
+	if r, err := c.DoApiPost(c.GetReactionsRoute()+"/ids", ArrayToJson(postIds)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ReactionsByPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTopReactionsForTeam returns the emoji most used as reactions in a team since the given time.
+func (c *Client4) GetTopReactionsForTeam(teamId string, since int64, limit int) ([]*ReactionCount, *Response) {
+	query := fmt.Sprintf("?since=%v&limit=%v", since, limit)
+	if r, err := c.DoApiGet(c.GetTeamRoute(teamId)+"/reactions/top"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ReactionCountsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Incidents Section
+
+// CreateIncident opens a new incident and announces it in the configured channel.
+func (c *Client4) CreateIncident(incident *Incident) (*Incident, *Response) {
+	if r, err := c.DoApiPost(c.GetIncidentsRoute(), incident.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetIncidents returns a page of incidents, most recent first.
+func (c *Client4) GetIncidents(page int, perPage int) ([]*Incident, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetIncidentsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetIncident returns an incident given its id.
+func (c *Client4) GetIncident(incidentId string) (*Incident, *Response) {
+	if r, err := c.DoApiGet(c.GetIncidentRoute(incidentId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// PatchIncident partially updates an incident. Any missing fields are not updated.
+func (c *Client4) PatchIncident(incidentId string, patch *IncidentPatch) (*Incident, *Response) {
+	if r, err := c.DoApiPut(c.GetIncidentRoute(incidentId)+"/patch", patch.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ResolveIncident marks an incident as resolved.
+func (c *Client4) ResolveIncident(incidentId string) (*Incident, *Response) {
+	if r, err := c.DoApiPost(c.GetIncidentRoute(incidentId)+"/resolve", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetIncidentFeed returns the public summary of current incidents. It does not require a session.
+func (c *Client4) GetIncidentFeed() (*IncidentFeed, *Response) {
+	if r, err := c.DoApiGet("/public/incidents", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return IncidentFeedFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Feature Flags Section
+
+// GetFeatureFlags returns every feature flag defined in the config file or the database.
+func (c *Client4) GetFeatureFlags() ([]*FeatureFlag, *Response) {
+	if r, err := c.DoApiGet(c.GetFeatureFlagsRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return FeatureFlagListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SaveFeatureFlag creates or replaces the feature flag with the same name.
+func (c *Client4) SaveFeatureFlag(flag *FeatureFlag) (*FeatureFlag, *Response) {
+	if r, err := c.DoApiPut(c.GetFeatureFlagRoute(flag.Name), flag.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return FeatureFlagFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteFeatureFlag removes a feature flag stored in the database.
+func (c *Client4) DeleteFeatureFlag(name string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetFeatureFlagRoute(name)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetFeatureFlagsForUser returns the state of every feature flag for a user. The teamId is optional
+// and restricts team allowlists to that team.
+func (c *Client4) GetFeatureFlagsForUser(userId string, teamId string) (map[string]bool, *Response) {
+	query := ""
+	if len(teamId) > 0 {
+		query = "?team_id=" + teamId
+	}
+
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/feature_flags"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MapBoolFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Experiments Section
+
+// GetExperiments returns every experiment.
+func (c *Client4) GetExperiments() ([]*Experiment, *Response) {
+	if r, err := c.DoApiGet(c.GetExperimentsRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ExperimentListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetExperiment returns an experiment given its name.
+func (c *Client4) GetExperiment(name string) (*Experiment, *Response) {
+	if r, err := c.DoApiGet(c.GetExperimentRoute(name), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ExperimentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SaveExperiment creates or replaces the experiment with the same name.
+func (c *Client4) SaveExperiment(experiment *Experiment) (*Experiment, *Response) {
+	if r, err := c.DoApiPut(c.GetExperimentRoute(experiment.Name), experiment.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ExperimentFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteExperiment removes an experiment and its recorded exposures.
+func (c *Client4) DeleteExperiment(name string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetExperimentRoute(name)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetExperimentSummary returns the number of users assigned to each variant of an experiment.
+func (c *Client4) GetExperimentSummary(name string) (*ExperimentSummary, *Response) {
+	if r, err := c.DoApiGet(c.GetExperimentRoute(name)+"/summary", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ExperimentSummaryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetExperimentVariantsForUser returns the variant a user is assigned to for every experiment
+// they take part in.
+func (c *Client4) GetExperimentVariantsForUser(userId string) (map[string]string, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/experiments", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return MapFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// LogExperimentExposure records that a user was shown their variant of an experiment.
+func (c *Client4) LogExperimentExposure(userId string, name string) (*ExperimentExposure, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+"/experiments/"+name+"/exposure", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ExperimentExposureFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Team Templates Section
+
+// SaveTeamAsTemplate saves the structure of a team as a template new teams can be created from.
+func (c *Client4) SaveTeamAsTemplate(teamId string, template *TeamTemplate) (*TeamTemplate, *Response) {
+	if r, err := c.DoApiPost(c.GetTeamRoute(teamId)+"/template", template.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamTemplateFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTeamTemplates returns a page of team templates.
+func (c *Client4) GetTeamTemplates(page int, perPage int) ([]*TeamTemplate, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetTeamTemplatesRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamTemplateListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTeamTemplate returns a team template given its id.
+func (c *Client4) GetTeamTemplate(templateId string) (*TeamTemplate, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamTemplateRoute(templateId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamTemplateFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteTeamTemplate deletes a team template.
+func (c *Client4) DeleteTeamTemplate(templateId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetTeamTemplateRoute(templateId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// CloneTeam creates a new team and copies the structure of an existing team into it. The copy
+// runs in the background and can be followed with GetTeamCloneJob.
+func (c *Client4) CloneTeam(teamId string, team *Team) (*TeamCloneJob, *Response) {
+	if r, err := c.DoApiPost(c.GetTeamRoute(teamId)+"/clone", team.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamCloneJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateTeamFromTemplate creates a new team with the structure of a template. The copy runs in
+// the background and can be followed with GetTeamCloneJob.
+func (c *Client4) CreateTeamFromTemplate(templateId string, team *Team) (*TeamCloneJob, *Response) {
+	if r, err := c.DoApiPost(c.GetTeamTemplateRoute(templateId)+"/clone", team.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamCloneJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTeamCloneJob returns the status of a team clone job.
+func (c *Client4) GetTeamCloneJob(jobId string) (*TeamCloneJob, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamCloneJobRoute(jobId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamCloneJobFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Scheduled Posts Section
+
+// CreateScheduledPost schedules a post to be created for the current user once its ScheduledAt time has passed.
+func (c *Client4) CreateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	if r, err := c.DoApiPost(c.GetScheduledPostsRoute(), scheduledPost.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetScheduledPostsForUser returns the posts a user has scheduled that have not been published yet.
+func (c *Client4) GetScheduledPostsForUser(userId string) ([]*ScheduledPost, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+"/posts/scheduled", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ScheduledPostListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteScheduledPost cancels a scheduled post before it is published.
+func (c *Client4) DeleteScheduledPost(scheduledPostId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetScheduledPostRoute(scheduledPostId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Drafts Section
+
+// GetDrafts returns the drafts a user has saved, most recently updated first.
+func (c *Client4) GetDrafts(userId string) ([]*Draft, *Response) {
+	if r, err := c.DoApiGet(c.GetDraftsRoute(userId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return DraftListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// SaveDraft creates or replaces a user's draft for the draft's channel or thread.
+func (c *Client4) SaveDraft(userId string, draft *Draft) (*Draft, *Response) {
+	if r, err := c.DoApiPut(c.GetDraftsRoute(userId), draft.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return DraftFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteDraft deletes a user's draft for a channel, or for a thread in the channel when rootId is set.
+func (c *Client4) DeleteDraft(userId, channelId, rootId string) (bool, *Response) {
+	query := ""
+	if len(rootId) > 0 {
+		query = "?root_id=" + rootId
+	}
+
+	if r, err := c.DoApiDelete(c.GetDraftsRoute(userId) + "/" + channelId + query); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Devices Section
+
+// GetDevicesForUser returns the devices a user is signed in on, most recently seen first.
+func (c *Client4) GetDevicesForUser(userId string) ([]*Device, *Response) {
+	if r, err := c.DoApiGet(c.GetDevicesRoute(userId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return DeviceListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RevokeDevice signs a user out of one of their devices.
+func (c *Client4) RevokeDevice(userId, deviceId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetDevicesRoute(userId) + "/" + deviceId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetDeviceVersionCounts returns how many of the devices seen since the given time are on each
+// platform and app version. Must have manage_system permission.
+func (c *Client4) GetDeviceVersionCounts(since int64) ([]*DeviceVersionCount, *Response) {
+	query := fmt.Sprintf("?since=%v", since)
+	if r, err := c.DoApiGet(c.GetSystemRoute()+"/devices/versions"+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return DeviceVersionCountListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Threads Section
+
+// FollowThread makes a user follow the thread started by a root post.
+func (c *Client4) FollowThread(userId, postId string) (*ThreadMembership, *Response) {
+	if r, err := c.DoApiPut(c.GetThreadForUserRoute(userId, postId)+"/following", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UnfollowThread stops a user from following the thread started by a root post.
+func (c *Client4) UnfollowThread(userId, postId string) (*ThreadMembership, *Response) {
+	if r, err := c.DoApiDelete(c.GetThreadForUserRoute(userId, postId) + "/following"); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// MarkThreadAsRead marks all replies in a thread as read by a user and clears their mentions in it.
+func (c *Client4) MarkThreadAsRead(userId, postId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetThreadForUserRoute(userId, postId)+"/read", ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetUnreadThreadsForUser returns the threads a user follows on a team that have unread replies or mentions.
+func (c *Client4) GetUnreadThreadsForUser(userId, teamId string) ([]*ThreadUnread, *Response) {
+	if r, err := c.DoApiGet(c.GetUserRoute(userId)+c.GetTeamRoute(teamId)+"/threads/unread", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return ThreadUnreadListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Channel Categories Section
+
+// GetSidebarCategoriesForTeamForUser returns the user's sidebar categories for a team in the order
+// they appear in.
+func (c *Client4) GetSidebarCategoriesForTeamForUser(userId, teamId string) ([]*SidebarCategoryWithChannels, *Response) {
+	if r, err := c.DoApiGet(c.GetChannelCategoriesRoute(userId, teamId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SidebarCategoryListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// CreateSidebarCategoryForTeamForUser adds a category below the user's other categories for a team.
+func (c *Client4) CreateSidebarCategoryForTeamForUser(userId, teamId string, category *SidebarCategoryWithChannels) (*SidebarCategoryWithChannels, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelCategoriesRoute(userId, teamId), category.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SidebarCategoryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetSidebarCategoryForTeamForUser returns one of the user's sidebar categories.
+func (c *Client4) GetSidebarCategoryForTeamForUser(userId, teamId, categoryId string) (*SidebarCategoryWithChannels, *Response) {
+	if r, err := c.DoApiGet(c.GetChannelCategoriesRoute(userId, teamId)+"/"+categoryId, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SidebarCategoryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateSidebarCategoryForTeamForUser changes the display name, collapsed state and channels of a category.
+func (c *Client4) UpdateSidebarCategoryForTeamForUser(userId, teamId string, category *SidebarCategoryWithChannels) (*SidebarCategoryWithChannels, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelCategoriesRoute(userId, teamId)+"/"+category.Id, category.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SidebarCategoryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateSidebarCategoryOrderForTeamForUser sorts the user's categories for a team. Every one of the
+// categories has to be listed.
+func (c *Client4) UpdateSidebarCategoryOrderForTeamForUser(userId, teamId string, categoryOrder []string) (bool, *Response) {
+	if r, err := c.DoApiPut(c.GetChannelCategoriesRoute(userId, teamId)+"/order", ArrayToJson(categoryOrder)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// DeleteSidebarCategoryForTeamForUser deletes a category. Its channels go back to the default sidebar.
+func (c *Client4) DeleteSidebarCategoryForTeamForUser(userId, teamId, categoryId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetChannelCategoriesRoute(userId, teamId) + "/" + categoryId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Team Quotas Section
+
+// GetTeamQuota returns the limits on what a team can use. A limit of zero means
+// that there's no limit.
+func (c *Client4) GetTeamQuota(teamId string) (*TeamQuota, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamRoute(teamId)+"/quota", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamQuotaFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// UpdateTeamQuota replaces the limits on what a team can use. Must be a system
+// administrator.
+func (c *Client4) UpdateTeamQuota(teamId string, quota *TeamQuota) (*TeamQuota, *Response) {
+	if r, err := c.DoApiPut(c.GetTeamRoute(teamId)+"/quota", quota.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamQuotaFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetTeamUsage returns the posts made this month, the storage and the number
+// of integrations that a team is using.
+func (c *Client4) GetTeamUsage(teamId string) (*TeamUsage, *Response) {
+	if r, err := c.DoApiGet(c.GetTeamRoute(teamId)+"/usage", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return TeamUsageFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Remote Clusters Section
+
+// CreateRemoteCluster adds a remote server and returns it along with the invite
+// that the remote's administrator has to accept.
+func (c *Client4) CreateRemoteCluster(rc *RemoteCluster) (*RemoteClusterWithInvite, *Response) {
+	if r, err := c.DoApiPost(c.GetRemoteClustersRoute(), rc.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return RemoteClusterWithInviteFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetRemoteClusters returns the remote servers that this server is connected to.
+func (c *Client4) GetRemoteClusters() ([]*RemoteCluster, *Response) {
+	if r, err := c.DoApiGet(c.GetRemoteClustersRoute(), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return RemoteClusterListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// AcceptRemoteClusterInvite connects to the server that created the invite.
+func (c *Client4) AcceptRemoteClusterInvite(invite, name, displayName, defaultTeamId string) (*RemoteCluster, *Response) {
+	requestBody := map[string]string{
+		"invite":          invite,
+		"name":            name,
+		"display_name":    displayName,
+		"default_team_id": defaultTeamId,
+	}
+
+	if r, err := c.DoApiPost(c.GetRemoteClustersRoute()+"/accept_invite", MapToJson(requestBody)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return RemoteClusterFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DeleteRemoteCluster disconnects a remote server.
+func (c *Client4) DeleteRemoteCluster(remoteId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetRemoteClusterRoute(remoteId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetSharedChannelRemotes returns the remote servers that a channel is shared with.
+func (c *Client4) GetSharedChannelRemotes(channelId string) ([]*SharedChannelRemote, *Response) {
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/remotes", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return SharedChannelRemoteListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ShareChannelWithRemote starts syncing a channel with a remote server.
+func (c *Client4) ShareChannelWithRemote(channelId, remoteId string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+"/remotes/"+remoteId, ""); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// UnshareChannelWithRemote stops syncing a channel with a remote server.
+func (c *Client4) UnshareChannelWithRemote(channelId, remoteId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetChannelRoute(channelId) + "/remotes/" + remoteId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Bots Section
+
+// CreateBot creates a bot owned by the current user.
+func (c *Client4) CreateBot(bot *Bot) (*Bot, *Response) {
+	if r, err := c.DoApiPost(c.GetBotsRoute(), bot.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BotFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBots returns a page of the bots owned by the current user, or of all bots for system admins.
+func (c *Client4) GetBots(page int, perPage int) ([]*Bot, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetBotsRoute()+query, ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BotListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBot returns a bot based on the provided bot user id string.
+func (c *Client4) GetBot(botUserId string) (*Bot, *Response) {
+	if r, err := c.DoApiGet(c.GetBotRoute(botUserId), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return BotFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DisableBot deactivates a bot and revokes all of its access tokens.
+func (c *Client4) DisableBot(botUserId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetBotRoute(botUserId)); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// CreateBotAccessToken creates an access token for a bot. The token is only returned by this call.
+func (c *Client4) CreateBotAccessToken(botUserId string, description string) (*UserAccessToken, *Response) {
+	token := &UserAccessToken{Description: description}
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/tokens", token.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetBotAccessTokens returns the access tokens of a bot without their values.
+func (c *Client4) GetBotAccessTokens(botUserId string) ([]*UserAccessToken, *Response) {
+	if r, err := c.DoApiGet(c.GetBotRoute(botUserId)+"/tokens", ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return UserAccessTokenListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// RevokeBotAccessToken revokes an access token of a bot.
+func (c *Client4) RevokeBotAccessToken(botUserId string, tokenId string) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetBotRoute(botUserId) + "/tokens/" + tokenId); err != nil {
+		return false, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// Events Section
+
+// PollEvents returns the events sent to the session after the given sequence number, waiting
+// for one for a while if there aren't any yet. Pass -1 to get all of the events still queued. It's
+// meant for clients that can use neither websockets nor server-sent events.
+func (c *Client4) PollEvents(since int64) ([]*WebSocketEvent, *Response) {
+	if r, err := c.DoApiGet(c.GetEventsRoute()+fmt.Sprintf("/poll?since=%v", since), ""); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return WebSocketEventListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GraphQL Section
+
+// ExecuteGraphQL runs a read-only GraphQL query and returns the decoded response, which holds
+// the data under "data" and any errors with the query under "errors".
+func (c *Client4) ExecuteGraphQL(query string, variables map[string]interface{}) (map[string]interface{}, *Response) {
+	request := map[string]interface{}{"query": query, "variables": variables}
+	if r, err := c.DoApiPost(c.GetGraphQLRoute(), StringInterfaceToJson(request)); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return StringInterfaceFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// Testing Section
+
+// CreateFixtures creates the users, teams, channels, posts and reactions described by the request.
+// Only available when testing and developer mode are both enabled.
+func (c *Client4) CreateFixtures(request *FixtureRequest) (*FixtureResult, *Response) {
+	if r, err := c.DoApiPost(c.GetTestingRoute()+"/fixtures", request.ToJson()); err != nil {
+		return nil, &Response{StatusCode: r.StatusCode, Error: err}
+	} else {
+		defer closeBody(r)
+		return FixtureResultFromJson(r.Body), BuildResponse(r)
 	}
 }
@@ -0,0 +1,93 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	HEADER_CLIENT_VERSION     = "X-Client-Version"
+	HEADER_MIN_CLIENT_VERSION = "X-Min-Client-Version"
+
+	// Browsers can't set headers on the websocket handshake, so clients can use this query parameter instead
+	CLIENT_VERSION_QUERY_PARAM = "client_version"
+
+	CLIENT_PLATFORM_DESKTOP = "desktop"
+	CLIENT_PLATFORM_MOBILE  = "mobile"
+	CLIENT_PLATFORM_WEB     = "web"
+)
+
+// ClientVersion is the app and version that a client reports in the X-Client-Version header, in
+// the form "desktop/3.7.1". The android and ios apps are both treated as the mobile app.
+type ClientVersion struct {
+	Platform string
+	Version  string
+}
+
+// OutdatedClient counts the requests made by one version of an app that's older than the minimum
+// supported version.
+type OutdatedClient struct {
+	Platform      string `json:"platform"`
+	Version       string `json:"version"`
+	MinVersion    string `json:"min_version"`
+	Count         int64  `json:"count"`
+	LastUserId    string `json:"last_user_id"`
+	LastAttemptAt int64  `json:"last_attempt_at"`
+}
+
+// ParseClientVersion returns nil if the value isn't a known platform followed by a valid version.
+// Anything after the version, such as a pre-release suffix, is ignored.
+func ParseClientVersion(value string) *ClientVersion {
+	parts := strings.SplitN(strings.TrimSpace(value), "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+
+	platform := strings.ToLower(parts[0])
+	if platform == "android" || platform == "ios" {
+		platform = CLIENT_PLATFORM_MOBILE
+	}
+
+	if platform != CLIENT_PLATFORM_DESKTOP && platform != CLIENT_PLATFORM_MOBILE && platform != CLIENT_PLATFORM_WEB {
+		return nil
+	}
+
+	version := parts[1]
+	if end := strings.IndexAny(version, "-+ "); end != -1 {
+		version = version[:end]
+	}
+
+	if !IsValidVersion(version) {
+		return nil
+	}
+
+	return &ClientVersion{Platform: platform, Version: version}
+}
+
+func (o *ClientVersion) String() string {
+	return o.Platform + "/" + o.Version
+}
+
+func OutdatedClientListToJson(list []*OutdatedClient) string {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OutdatedClientListFromJson(data io.Reader) []*OutdatedClient {
+	decoder := json.NewDecoder(data)
+
+	var list []*OutdatedClient
+	if err := decoder.Decode(&list); err != nil {
+		return nil
+	} else {
+		return list
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClientVersion(t *testing.T) {
+	if v := ParseClientVersion("desktop/3.7.1"); v == nil || v.Platform != CLIENT_PLATFORM_DESKTOP || v.Version != "3.7.1" {
+		t.Fatal("should have parsed the desktop version", v)
+	}
+
+	if v := ParseClientVersion("iOS/1.2.0-rc1"); v == nil || v.Platform != CLIENT_PLATFORM_MOBILE || v.Version != "1.2.0" {
+		t.Fatal("should have parsed the mobile version without its suffix", v)
+	}
+
+	for _, value := range []string{"", "desktop", "desktop/", "toaster/1.0.0", "web/latest", "web/1"} {
+		if v := ParseClientVersion(value); v != nil {
+			t.Fatal("shouldn't have parsed an invalid version", value, v)
+		}
+	}
+}
+
+func TestIsVersionAtLeast(t *testing.T) {
+	if !IsVersionAtLeast("3.7.1", "3.7.0") || !IsVersionAtLeast("3.7.0", "3.7") || !IsVersionAtLeast("4.0.0", "3.10.2") {
+		t.Fatal("should be at least the min version")
+	}
+
+	if IsVersionAtLeast("3.6.9", "3.7.0") || IsVersionAtLeast("3.9.0", "3.10.0") || IsVersionAtLeast("2.99.99", "3.0.0") {
+		t.Fatal("should be older than the min version")
+	}
+}
+
+func TestOutdatedClientListJson(t *testing.T) {
+	list := []*OutdatedClient{{Platform: CLIENT_PLATFORM_DESKTOP, Version: "3.6.0", MinVersion: "3.7.0", Count: 2}}
+
+	if result := OutdatedClientListFromJson(strings.NewReader(OutdatedClientListToJson(list))); len(result) != 1 || *result[0] != *list[0] {
+		t.Fatal("should have round tripped the list", result)
+	}
+}
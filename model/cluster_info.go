@@ -11,13 +11,24 @@ import (
 	"sync/atomic"
 )
 
+// ClusterInfo describes a node of the cluster. PingLatency is the round trip in milliseconds of the
+// last probe of the node, and EventRelayLag is how long, in milliseconds, the last event published
+// on the node took to reach this one. ProtocolVersion and MinProtocolVersion are the newest and the
+// oldest versions of the cluster messages that the node can talk to other nodes with.
 type ClusterInfo struct {
 	Id                 string       `json:"id"`
 	Version            string       `json:"version"`
+	SchemaVersion      string       `json:"schema_version"`
 	ConfigHash         string       `json:"config_hash"`
 	InterNodeUrl       string       `json:"internode_url"`
 	Hostname           string       `json:"hostname"`
+	StartTime          int64        `json:"start_time"`
+	Uptime             int64        `json:"uptime"`
 	LastSuccessfulPing int64        `json:"last_ping"`
+	PingLatency        int64        `json:"ping_latency"`
+	EventRelayLag      int64        `json:"event_relay_lag"`
+	ProtocolVersion    int          `json:"protocol_version"`
+	MinProtocolVersion int          `json:"min_protocol_version"`
 	Alive              int32        `json:"is_alive"`
 	Mutex              sync.RWMutex `json:"-"`
 }
@@ -62,6 +73,20 @@ func (me *ClusterInfo) IsAlive() bool {
 	return atomic.LoadInt32(&me.Alive) == 1
 }
 
+// GetProtocolVersions returns the oldest and the newest protocol versions that a node can talk to other
+// nodes with. Nodes that don't report them only understand version 1.
+func (me *ClusterInfo) GetProtocolVersions() (int, int) {
+	minVersion, version := me.MinProtocolVersion, me.ProtocolVersion
+	if minVersion == 0 {
+		minVersion = 1
+	}
+	if version == 0 {
+		version = 1
+	}
+
+	return minVersion, version
+}
+
 func (me *ClusterInfo) HaveEstablishedInitialContact() bool {
 	me.Mutex.RLock()
 	defer me.Mutex.RUnlock()
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// CLUSTER_PROTOCOL_VERSION is the newest version of the messages sent between the nodes of a
+	// cluster that this server can send and understand. Version 1 messages only have an event and its
+	// data, and version 2 added the envelope fields that say which node sent a message and when.
+	CLUSTER_PROTOCOL_VERSION = 2
+
+	// CLUSTER_PROTOCOL_MIN_VERSION is the oldest version that this server can still talk to other
+	// nodes with. Nodes that can't speak at least this version can't join the cluster.
+	CLUSTER_PROTOCOL_MIN_VERSION = 1
+
+	CLUSTER_EVENT_PUBLISH                                           = "publish"
+	CLUSTER_EVENT_UPDATE_STATUS                                     = "update_status"
+	CLUSTER_EVENT_INVALIDATE_ALL_CACHES                             = "inv_all_caches"
+	CLUSTER_EVENT_INVALIDATE_CACHE                                  = "inv_cache"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER                         = "inv_user"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL                      = "inv_channel"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_BY_NAME              = "inv_channel_name"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_MEMBERS              = "inv_channel_members"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_MEMBERS_NOTIFY_PROPS = "inv_channel_members_notify_props"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_POSTS                = "inv_channel_posts"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_WEBHOOK                      = "inv_webhook"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_REACTIONS                    = "inv_reactions"
+	CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER                      = "clear_session_user"
+	CLUSTER_EVENT_CONFIG_CHANGED                                    = "config_changed"
+)
+
+// ClusterMessage is the envelope that events are sent between the nodes of a cluster in. Messages are
+// encoded with the protocol version that every node of the cluster understands, so the fields that
+// were added after version 1 are left out when talking to older nodes, and a message without a
+// ProtocolVersion is a version 1 message.
+type ClusterMessage struct {
+	ProtocolVersion int               `json:"protocol_version,omitempty"`
+	Event           string            `json:"event"`
+	Data            string            `json:"data,omitempty"`
+	OriginId        string            `json:"origin_id,omitempty"`
+	CreateAt        int64             `json:"create_at,omitempty"`
+	Props           map[string]string `json:"props,omitempty"`
+}
+
+// ToJsonForVersion encodes a message so that a node that speaks the given protocol version can read
+// it.
+func (o *ClusterMessage) ToJsonForVersion(version int) string {
+	msg := &ClusterMessage{
+		Event: o.Event,
+		Data:  o.Data,
+	}
+
+	if version >= 2 {
+		msg.ProtocolVersion = version
+		msg.OriginId = o.OriginId
+		msg.CreateAt = o.CreateAt
+		msg.Props = o.Props
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+// ClusterMessageFromJson decodes a message of any protocol version. Fields added by versions newer
+// than this server understands are ignored, so a newer node's message is read as the newest version
+// that this server knows about.
+func ClusterMessageFromJson(data io.Reader) (*ClusterMessage, *AppError) {
+	decoder := json.NewDecoder(data)
+
+	var o ClusterMessage
+	if err := decoder.Decode(&o); err != nil {
+		return nil, NewAppError("ClusterMessageFromJson", "model.cluster_message.decode.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if len(o.Event) == 0 {
+		return nil, NewAppError("ClusterMessageFromJson", "model.cluster_message.decode.app_error", nil, "missing event", http.StatusBadRequest)
+	}
+
+	if o.ProtocolVersion == 0 {
+		o.ProtocolVersion = 1
+	} else if o.ProtocolVersion > CLUSTER_PROTOCOL_VERSION {
+		o.ProtocolVersion = CLUSTER_PROTOCOL_VERSION
+	}
+
+	return &o, nil
+}
+
+// NegotiateClusterProtocolVersion returns the newest protocol version that every live node of a
+// cluster understands. Nodes that don't report their protocol versions only understand version 1.
+// It returns an error if a node is too old or too new for the others to talk to.
+func NegotiateClusterProtocolVersion(infos []*ClusterInfo) (int, *AppError) {
+	version := CLUSTER_PROTOCOL_VERSION
+	minVersion := CLUSTER_PROTOCOL_MIN_VERSION
+
+	for _, info := range infos {
+		if !info.IsAlive() {
+			continue
+		}
+
+		nodeMinVersion, nodeVersion := info.GetProtocolVersions()
+		if nodeVersion < version {
+			version = nodeVersion
+		}
+		if nodeMinVersion > minVersion {
+			minVersion = nodeMinVersion
+		}
+	}
+
+	if version < minVersion {
+		return minVersion, NewAppError("NegotiateClusterProtocolVersion", "model.cluster_message.negotiate.incompatible.app_error", map[string]interface{}{"Version": version, "MinVersion": minVersion}, "version="+strconv.Itoa(version)+", min_version="+strconv.Itoa(minVersion), http.StatusConflict)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterMessageJson(t *testing.T) {
+	o := ClusterMessage{
+		Event:    CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL,
+		Data:     NewId(),
+		OriginId: NewId(),
+		CreateAt: GetMillis(),
+		Props:    map[string]string{"key": "value"},
+	}
+
+	if ro, err := ClusterMessageFromJson(strings.NewReader(o.ToJsonForVersion(2))); err != nil {
+		t.Fatal(err)
+	} else if ro.ProtocolVersion != 2 || ro.Event != o.Event || ro.Data != o.Data || ro.OriginId != o.OriginId || ro.CreateAt != o.CreateAt || ro.Props["key"] != "value" {
+		t.Fatal("messages do not match")
+	}
+
+	legacy := o.ToJsonForVersion(1)
+	if strings.Contains(legacy, "protocol_version") || strings.Contains(legacy, "origin_id") || strings.Contains(legacy, "props") {
+		t.Fatal("shouldn't send fields that version 1 nodes don't know about", legacy)
+	}
+
+	if ro, err := ClusterMessageFromJson(strings.NewReader(legacy)); err != nil {
+		t.Fatal(err)
+	} else if ro.ProtocolVersion != 1 || ro.Event != o.Event || ro.Data != o.Data || ro.OriginId != "" {
+		t.Fatal("should have read the message as version 1", ro)
+	}
+
+	if ro, err := ClusterMessageFromJson(strings.NewReader(`{"protocol_version": 99, "event": "inv_channel", "data": "abc", "new_field": true}`)); err != nil {
+		t.Fatal(err)
+	} else if ro.ProtocolVersion != CLUSTER_PROTOCOL_VERSION || ro.Data != "abc" {
+		t.Fatal("should have read a newer message as the newest known version", ro)
+	}
+
+	if _, err := ClusterMessageFromJson(strings.NewReader(`{"data": "abc"}`)); err == nil {
+		t.Fatal("should have failed without an event")
+	}
+
+	if _, err := ClusterMessageFromJson(strings.NewReader(`junk`)); err == nil {
+		t.Fatal("should have failed to decode junk")
+	}
+}
+
+func TestNegotiateClusterProtocolVersion(t *testing.T) {
+	node := func(minVersion, version int, alive bool) *ClusterInfo {
+		info := &ClusterInfo{Id: NewId(), MinProtocolVersion: minVersion, ProtocolVersion: version}
+		info.SetAlive(alive)
+		return info
+	}
+
+	if version, err := NegotiateClusterProtocolVersion([]*ClusterInfo{node(1, CLUSTER_PROTOCOL_VERSION, true), node(1, CLUSTER_PROTOCOL_VERSION, true)}); err != nil || version != CLUSTER_PROTOCOL_VERSION {
+		t.Fatal("nodes on the same version should use it", version, err)
+	}
+
+	if version, err := NegotiateClusterProtocolVersion([]*ClusterInfo{node(1, 2, true), node(0, 0, true)}); err != nil || version != 1 {
+		t.Fatal("nodes that don't report a version should be treated as version 1", version, err)
+	}
+
+	if version, err := NegotiateClusterProtocolVersion([]*ClusterInfo{node(1, 2, true), node(1, 1, false)}); err != nil || version != 2 {
+		t.Fatal("nodes that aren't alive should be ignored", version, err)
+	}
+
+	if _, err := NegotiateClusterProtocolVersion([]*ClusterInfo{node(1, 1, true), node(2, 3, true)}); err == nil {
+		t.Fatal("should fail when the nodes don't have a version in common")
+	}
+}
@@ -18,6 +18,10 @@ const (
 
 	COMPLIANCE_TYPE_DAILY = "daily"
 	COMPLIANCE_TYPE_ADHOC = "adhoc"
+
+	COMPLIANCE_FORMAT_CSV         = "csv"
+	COMPLIANCE_FORMAT_ACTIANCE    = "actiance"
+	COMPLIANCE_FORMAT_GLOBALRELAY = "globalrelay"
 )
 
 type Compliance struct {
@@ -32,6 +36,7 @@ type Compliance struct {
 	EndAt    int64  `json:"end_at"`
 	Keywords string `json:"keywords"`
 	Emails   string `json:"emails"`
+	Format   string `json:"format"`
 }
 
 type Compliances []Compliance
@@ -54,6 +59,10 @@ func (me *Compliance) PreSave() {
 		me.Status = COMPLIANCE_STATUS_CREATED
 	}
 
+	if me.Format == "" {
+		me.Format = COMPLIANCE_FORMAT_CSV
+	}
+
 	me.Count = 0
 	me.Emails = strings.ToLower(me.Emails)
 	me.Keywords = strings.ToLower(me.Keywords)
@@ -98,6 +107,11 @@ func (me *Compliance) IsValid() *AppError {
 		return NewLocAppError("Compliance.IsValid", "model.compliance.is_valid.start_end_at.app_error", nil, "")
 	}
 
+	// Formats are provided by export formatters, so only the length of the name is checked here
+	if len(me.Format) == 0 || len(me.Format) > 32 {
+		return NewLocAppError("Compliance.IsValid", "model.compliance.is_valid.format.app_error", nil, "")
+	}
+
 	return nil
 }
 
@@ -17,3 +17,32 @@ func TestCompliance(t *testing.T) {
 		t.Fatal("JobName do not match")
 	}
 }
+
+func TestComplianceIsValid(t *testing.T) {
+	o := Compliance{Desc: "test", StartAt: 1000, EndAt: 2000}
+	o.PreSave()
+
+	if o.Format != COMPLIANCE_FORMAT_CSV {
+		t.Fatal("should have defaulted to the csv format")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Format = COMPLIANCE_FORMAT_ACTIANCE
+	o.PreSave()
+	if o.Format != COMPLIANCE_FORMAT_ACTIANCE {
+		t.Fatal("should have kept the format")
+	}
+
+	o.Format = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Format = strings.Repeat("a", 33)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
@@ -6,7 +6,9 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net"
 	"net/url"
+	"time"
 )
 
 const (
@@ -27,6 +29,7 @@ const (
 	SERVICE_GITLAB    = "gitlab"
 	SERVICE_GOOGLE    = "google"
 	SERVICE_OFFICE365 = "office365"
+	SERVICE_OPENID    = "openid"
 
 	WEBSERVER_MODE_REGULAR  = "regular"
 	WEBSERVER_MODE_GZIP     = "gzip"
@@ -53,13 +56,36 @@ const (
 	PERMISSIONS_DELETE_POST_TEAM_ADMIN   = "team_admin"
 	PERMISSIONS_DELETE_POST_SYSTEM_ADMIN = "system_admin"
 
+	CHANNEL_ADMIN_SUCCESSION_NONE            = "none"
+	CHANNEL_ADMIN_SUCCESSION_LONGEST_TENURED = "longest_tenured"
+
 	ALLOW_EDIT_POST_ALWAYS     = "always"
 	ALLOW_EDIT_POST_NEVER      = "never"
 	ALLOW_EDIT_POST_TIME_LIMIT = "time_limit"
 
+	ENDPOINT_RATE_LIMIT_VARY_BY_SESSION = "session"
+	ENDPOINT_RATE_LIMIT_VARY_BY_USER    = "user"
+
+	CACHE_TYPE_LRU   = "lru"
+	CACHE_TYPE_REDIS = "redis"
+
 	EMAIL_BATCHING_BUFFER_SIZE = 256
 	EMAIL_BATCHING_INTERVAL    = 30
 
+	BULK_EMAIL_MAX_PER_MINUTE = 60
+
+	AUDIT_SYSLOG_NETWORK_UDP = "udp"
+	AUDIT_SYSLOG_NETWORK_TCP = "tcp"
+	AUDIT_SYSLOG_NETWORK_TLS = "tls"
+
+	AUDIT_SINK_BUFFER_SIZE = 1000
+	AUDIT_SINK_MAX_RETRIES = 5
+
+	DATA_RETENTION_DEFAULT_DAYS      = 365
+	DATA_RETENTION_DEFAULT_BATCH     = 3000
+	DATA_RETENTION_DEFAULT_START     = "02:00"
+	DATA_RETENTION_START_TIME_LAYOUT = "15:04"
+
 	SITENAME_MAX_LENGTH = 30
 
 	SERVICE_SETTINGS_DEFAULT_SITE_URL        = ""
@@ -69,6 +95,10 @@ const (
 	SERVICE_SETTINGS_DEFAULT_WRITE_TIMEOUT   = 300
 	SERVICE_SETTINGS_DEFAULT_ALLOW_CORS_FROM = ""
 
+	SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_FRAMES        = 100
+	SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_UPLOAD_WIDTH  = 1024
+	SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_UPLOAD_HEIGHT = 1024
+
 	TEAM_SETTINGS_DEFAULT_CUSTOM_BRAND_TEXT        = ""
 	TEAM_SETTINGS_DEFAULT_CUSTOM_DESCRIPTION_TEXT  = ""
 	TEAM_SETTINGS_DEFAULT_USER_STATUS_AWAY_TIMEOUT = 300
@@ -98,6 +128,7 @@ const (
 	SAML_SETTINGS_DEFAULT_NICKNAME_ATTRIBUTE   = ""
 	SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE     = ""
 	SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE   = ""
+	SAML_SETTINGS_DEFAULT_GROUP_ATTRIBUTE      = ""
 
 	NATIVEAPP_SETTINGS_DEFAULT_APP_DOWNLOAD_LINK         = "https://about.mattermost.com/downloads/"
 	NATIVEAPP_SETTINGS_DEFAULT_ANDROID_APP_DOWNLOAD_LINK = "https://about.mattermost.com/mattermost-android-app/"
@@ -106,6 +137,12 @@ const (
 	WEBRTC_SETTINGS_DEFAULT_STUN_URI = ""
 	WEBRTC_SETTINGS_DEFAULT_TURN_URI = ""
 
+	OPENID_SETTINGS_DEFAULT_SCOPE            = "openid profile email"
+	OPENID_SETTINGS_DEFAULT_USERNAME_CLAIM   = "preferred_username"
+	OPENID_SETTINGS_DEFAULT_EMAIL_CLAIM      = "email"
+	OPENID_SETTINGS_DEFAULT_FIRST_NAME_CLAIM = "given_name"
+	OPENID_SETTINGS_DEFAULT_LAST_NAME_CLAIM  = "family_name"
+
 	ANALYTICS_SETTINGS_DEFAULT_MAX_USERS_FOR_STATISTICS = 2500
 )
 
@@ -137,6 +174,8 @@ type ServiceSettings struct {
 	EnableInsecureOutgoingConnections        *bool
 	EnableMultifactorAuthentication          *bool
 	EnforceMultifactorAuthentication         *bool
+	EnforceMultifactorAuthenticationAfter    *int64
+	EnableWebAuthn                           *bool
 	AllowCorsFrom                            *string
 	SessionLengthWebInDays                   *int
 	SessionLengthMobileInDays                *int
@@ -147,12 +186,33 @@ type ServiceSettings struct {
 	WebserverMode                            *string
 	EnableCustomEmoji                        *bool
 	RestrictCustomEmojiCreation              *string
+	MaxEmojiFrames                           *int
+	MaxEmojiUploadWidth                      *int
+	MaxEmojiUploadHeight                     *int
 	RestrictPostDelete                       *string
 	AllowEditPost                            *string
 	PostEditTimeLimit                        *int
 	TimeBetweenUserTypingUpdatesMilliseconds *int64
 	EnableUserTypingMessages                 *bool
 	ClusterLogTimeoutMilliseconds            *int
+	EnableEndpointRateLimits                 *bool
+	EndpointRateLimitVaryBy                  *string
+	LoginRateLimitPerMinute                  *int
+	LoginRateLimitMaxBurst                   *int
+	FileUploadRateLimitPerMinute             *int
+	FileUploadRateLimitMaxBurst              *int
+	WebSocketRateLimitPerMinute              *int
+	WebSocketRateLimitMaxBurst               *int
+	ApiRateLimitPerMinute                    *int
+	ApiRateLimitMaxBurst                     *int
+	IdSeed                                   *int64
+	EnableBotAccountCreation                 *bool
+	EnableUserAccessTokens                   *bool
+	EnableGraphQL                            *bool
+	EnableGrpcServer                         *bool
+	GrpcListenAddress                        *string
+	AdditionalListeners                      []*Listener
+	EnableSharedChannels                     *bool
 }
 
 type ClusterSettings struct {
@@ -161,6 +221,35 @@ type ClusterSettings struct {
 	InterNodeUrls          []string
 }
 
+type CacheSettings struct {
+	CacheType      *string
+	RedisAddress   *string
+	RedisPassword  *string
+	RedisDatabase  *int
+	MemoryBudgetMB *int
+	CacheSizes     map[string]int
+	EnableWarmUp   *bool
+	WarmUpHours    *int
+	WarmUpChannels *int
+}
+
+type ElasticsearchSettings struct {
+	ConnectionUrl         *string
+	Username              *string
+	Password              *string
+	EnableIndexing        *bool
+	EnableSearching       *bool
+	IndexPrefix           *string
+	RequestTimeoutSeconds *int
+	BulkIndexingBatchSize *int
+}
+
+type ClientRequirementsSettings struct {
+	DesktopMinVersion *string
+	MobileMinVersion  *string
+	WebMinVersion     *string
+}
+
 type MetricsSettings struct {
 	Enable           *bool
 	BlockProfileRate *int
@@ -181,14 +270,32 @@ type SSOSettings struct {
 	UserApiEndpoint string
 }
 
+// OpenIdSettings configure login through any OpenID Connect provider. The provider's endpoints are
+// found through discovery from its issuer, and the claims that users are created from can be changed
+// to match what the provider returns.
+type OpenIdSettings struct {
+	Enable         *bool
+	Issuer         *string
+	Id             *string
+	Secret         *string
+	Scope          *string
+	UsernameClaim  *string
+	EmailClaim     *string
+	FirstNameClaim *string
+	LastNameClaim  *string
+	ButtonText     *string
+}
+
 type SqlSettings struct {
-	DriverName         string
-	DataSource         string
-	DataSourceReplicas []string
-	MaxIdleConns       int
-	MaxOpenConns       int
-	Trace              bool
-	AtRestEncryptKey   string
+	DriverName                     string
+	DataSource                     string
+	DataSourceReplicas             []string
+	MaxIdleConns                   int
+	MaxOpenConns                   int
+	Trace                          bool
+	AtRestEncryptKey               string
+	SlowQueryThresholdMilliseconds *int
+	SlowQueryExplainThreshold      *int
 }
 
 type LogSettings struct {
@@ -229,6 +336,7 @@ type FileSettings struct {
 	AmazonS3Region          string
 	AmazonS3Endpoint        string
 	AmazonS3SSL             *bool
+	ExtractContent          *bool
 }
 
 type EmailSettings struct {
@@ -250,10 +358,12 @@ type EmailSettings struct {
 	SendPushNotifications             *bool
 	PushNotificationServer            *string
 	PushNotificationContents          *string
+	PushProxies                       []*PushProxy
 	EnableEmailBatching               *bool
 	EmailBatchingBufferSize           *int
 	EmailBatchingInterval             *int
 	SkipServerCertificateVerification *bool
+	BulkEmailMaxPerMinute             *int
 }
 
 type RateLimitSettings struct {
@@ -301,6 +411,15 @@ type TeamSettings struct {
 	UserStatusAwayTimeout               *int64
 	MaxChannelsPerTeam                  *int64
 	MaxNotificationsPerChannel          *int64
+	LargeChannelThreshold               *int64
+	EnableReadReceipts                  *bool
+	EnableGuestAccounts                 *bool
+	ChannelAdminSuccession              *string
+	NameRedirectGracePeriodDays         *int
+	AllowPrivateToPublicConversion      *bool
+	EnableChannelAutoArchiving          *bool
+	ChannelAutoArchiveIdleDays          *int
+	ChannelAutoArchiveGracePeriodDays   *int
 }
 
 type LdapSettings struct {
@@ -326,7 +445,9 @@ type LdapSettings struct {
 	PositionAttribute  *string
 
 	// Syncronization
-	SyncIntervalMinutes *int
+	SyncIntervalMinutes      *int
+	EnableGroupSync          *bool
+	GroupSyncIntervalMinutes *int
 
 	// Advanced
 	SkipCertificateVerification *bool
@@ -338,9 +459,17 @@ type LdapSettings struct {
 }
 
 type ComplianceSettings struct {
-	Enable      *bool
-	Directory   *string
-	EnableDaily *bool
+	Enable                   *bool
+	Directory                *string
+	EnableDaily              *bool
+	ExportSigningKey         *string
+	EnablePostIntegrityChain *bool
+	GlobalRelayEmailAddress  *string
+	SftpServer               *string
+	SftpUsername             *string
+	SftpPassword             *string
+	SftpHostKey              *string
+	SftpDirectory            *string
 }
 
 type LocalizationSettings struct {
@@ -372,6 +501,10 @@ type SamlSettings struct {
 	LocaleAttribute    *string
 	PositionAttribute  *string
 
+	// Group Mapping
+	GroupAttribute *string
+	GroupMappings  []*SamlGroupMapping
+
 	LoginButtonText *string
 }
 
@@ -392,29 +525,69 @@ type WebrtcSettings struct {
 	TurnSharedKey       *string
 }
 
+type IncidentSettings struct {
+	Enable                *bool
+	AnnouncementChannelId *string
+	EnablePublicFeed      *bool
+}
+
+type FeatureFlagSettings struct {
+	Flags []*FeatureFlag
+}
+
+type AuditSettings struct {
+	EnableSyslog  *bool
+	SyslogNetwork *string
+	SyslogAddress *string
+	EnableFile    *bool
+	FileName      *string
+	EnableWebhook *bool
+	WebhookUrl    *string
+	WebhookSecret *string
+	BufferSize    *int
+	MaxRetries    *int
+}
+
+type DataRetentionSettings struct {
+	EnableMessageDeletion *bool
+	EnableFileDeletion    *bool
+	MessageRetentionDays  *int
+	FileRetentionDays     *int
+	DeletionJobStartTime  *string
+	BatchSize             *int
+}
+
 type Config struct {
-	ServiceSettings      ServiceSettings
-	TeamSettings         TeamSettings
-	SqlSettings          SqlSettings
-	LogSettings          LogSettings
-	PasswordSettings     PasswordSettings
-	FileSettings         FileSettings
-	EmailSettings        EmailSettings
-	RateLimitSettings    RateLimitSettings
-	PrivacySettings      PrivacySettings
-	SupportSettings      SupportSettings
-	GitLabSettings       SSOSettings
-	GoogleSettings       SSOSettings
-	Office365Settings    SSOSettings
-	LdapSettings         LdapSettings
-	ComplianceSettings   ComplianceSettings
-	LocalizationSettings LocalizationSettings
-	SamlSettings         SamlSettings
-	NativeAppSettings    NativeAppSettings
-	ClusterSettings      ClusterSettings
-	MetricsSettings      MetricsSettings
-	AnalyticsSettings    AnalyticsSettings
-	WebrtcSettings       WebrtcSettings
+	ServiceSettings            ServiceSettings
+	TeamSettings               TeamSettings
+	SqlSettings                SqlSettings
+	LogSettings                LogSettings
+	PasswordSettings           PasswordSettings
+	FileSettings               FileSettings
+	EmailSettings              EmailSettings
+	RateLimitSettings          RateLimitSettings
+	PrivacySettings            PrivacySettings
+	SupportSettings            SupportSettings
+	GitLabSettings             SSOSettings
+	GoogleSettings             SSOSettings
+	Office365Settings          SSOSettings
+	OpenIdSettings             OpenIdSettings
+	LdapSettings               LdapSettings
+	ComplianceSettings         ComplianceSettings
+	LocalizationSettings       LocalizationSettings
+	SamlSettings               SamlSettings
+	NativeAppSettings          NativeAppSettings
+	ClusterSettings            ClusterSettings
+	CacheSettings              CacheSettings
+	ElasticsearchSettings      ElasticsearchSettings
+	ClientRequirementsSettings ClientRequirementsSettings
+	MetricsSettings            MetricsSettings
+	AnalyticsSettings          AnalyticsSettings
+	WebrtcSettings             WebrtcSettings
+	IncidentSettings           IncidentSettings
+	FeatureFlagSettings        FeatureFlagSettings
+	AuditSettings              AuditSettings
+	DataRetentionSettings      DataRetentionSettings
 }
 
 func (o *Config) ToJson() string {
@@ -456,6 +629,16 @@ func (o *Config) SetDefaults() {
 		o.SqlSettings.AtRestEncryptKey = NewRandomString(32)
 	}
 
+	if o.SqlSettings.SlowQueryThresholdMilliseconds == nil {
+		o.SqlSettings.SlowQueryThresholdMilliseconds = new(int)
+		*o.SqlSettings.SlowQueryThresholdMilliseconds = 1000
+	}
+
+	if o.SqlSettings.SlowQueryExplainThreshold == nil {
+		o.SqlSettings.SlowQueryExplainThreshold = new(int)
+		*o.SqlSettings.SlowQueryExplainThreshold = 5
+	}
+
 	if o.FileSettings.AmazonS3Endpoint == "" {
 		// Defaults to "s3.amazonaws.com"
 		o.FileSettings.AmazonS3Endpoint = "s3.amazonaws.com"
@@ -471,6 +654,11 @@ func (o *Config) SetDefaults() {
 		*o.FileSettings.AmazonS3SSL = true // Secure by default.
 	}
 
+	if o.FileSettings.ExtractContent == nil {
+		o.FileSettings.ExtractContent = new(bool)
+		*o.FileSettings.ExtractContent = true
+	}
+
 	if o.FileSettings.MaxFileSize == nil {
 		o.FileSettings.MaxFileSize = new(int64)
 		*o.FileSettings.MaxFileSize = 52428800 // 50 MB
@@ -533,6 +721,16 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.EnforceMultifactorAuthentication = false
 	}
 
+	if o.ServiceSettings.EnforceMultifactorAuthenticationAfter == nil {
+		o.ServiceSettings.EnforceMultifactorAuthenticationAfter = new(int64)
+		*o.ServiceSettings.EnforceMultifactorAuthenticationAfter = 0
+	}
+
+	if o.ServiceSettings.EnableWebAuthn == nil {
+		o.ServiceSettings.EnableWebAuthn = new(bool)
+		*o.ServiceSettings.EnableWebAuthn = false
+	}
+
 	if o.PasswordSettings.MinimumLength == nil {
 		o.PasswordSettings.MinimumLength = new(int)
 		*o.PasswordSettings.MinimumLength = PASSWORD_MINIMUM_LENGTH
@@ -642,6 +840,51 @@ func (o *Config) SetDefaults() {
 		*o.TeamSettings.MaxNotificationsPerChannel = 1000
 	}
 
+	if o.TeamSettings.LargeChannelThreshold == nil {
+		o.TeamSettings.LargeChannelThreshold = new(int64)
+		*o.TeamSettings.LargeChannelThreshold = 5000
+	}
+
+	if o.TeamSettings.EnableReadReceipts == nil {
+		o.TeamSettings.EnableReadReceipts = new(bool)
+		*o.TeamSettings.EnableReadReceipts = false
+	}
+
+	if o.TeamSettings.EnableGuestAccounts == nil {
+		o.TeamSettings.EnableGuestAccounts = new(bool)
+		*o.TeamSettings.EnableGuestAccounts = false
+	}
+
+	if o.TeamSettings.ChannelAdminSuccession == nil {
+		o.TeamSettings.ChannelAdminSuccession = new(string)
+		*o.TeamSettings.ChannelAdminSuccession = CHANNEL_ADMIN_SUCCESSION_NONE
+	}
+
+	if o.TeamSettings.NameRedirectGracePeriodDays == nil {
+		o.TeamSettings.NameRedirectGracePeriodDays = new(int)
+		*o.TeamSettings.NameRedirectGracePeriodDays = 30
+	}
+
+	if o.TeamSettings.AllowPrivateToPublicConversion == nil {
+		o.TeamSettings.AllowPrivateToPublicConversion = new(bool)
+		*o.TeamSettings.AllowPrivateToPublicConversion = false
+	}
+
+	if o.TeamSettings.EnableChannelAutoArchiving == nil {
+		o.TeamSettings.EnableChannelAutoArchiving = new(bool)
+		*o.TeamSettings.EnableChannelAutoArchiving = false
+	}
+
+	if o.TeamSettings.ChannelAutoArchiveIdleDays == nil {
+		o.TeamSettings.ChannelAutoArchiveIdleDays = new(int)
+		*o.TeamSettings.ChannelAutoArchiveIdleDays = 90
+	}
+
+	if o.TeamSettings.ChannelAutoArchiveGracePeriodDays == nil {
+		o.TeamSettings.ChannelAutoArchiveGracePeriodDays = new(int)
+		*o.TeamSettings.ChannelAutoArchiveGracePeriodDays = 7
+	}
+
 	if o.EmailSettings.EnableSignInWithEmail == nil {
 		o.EmailSettings.EnableSignInWithEmail = new(bool)
 
@@ -672,6 +915,10 @@ func (o *Config) SetDefaults() {
 		*o.EmailSettings.PushNotificationContents = GENERIC_NOTIFICATION
 	}
 
+	if o.EmailSettings.PushProxies == nil {
+		o.EmailSettings.PushProxies = []*PushProxy{}
+	}
+
 	if o.EmailSettings.FeedbackOrganization == nil {
 		o.EmailSettings.FeedbackOrganization = new(string)
 		*o.EmailSettings.FeedbackOrganization = EMAIL_SETTINGS_DEFAULT_FEEDBACK_ORGANIZATION
@@ -697,6 +944,11 @@ func (o *Config) SetDefaults() {
 		*o.EmailSettings.SkipServerCertificateVerification = false
 	}
 
+	if o.EmailSettings.BulkEmailMaxPerMinute == nil {
+		o.EmailSettings.BulkEmailMaxPerMinute = new(int)
+		*o.EmailSettings.BulkEmailMaxPerMinute = BULK_EMAIL_MAX_PER_MINUTE
+	}
+
 	if !IsSafeLink(o.SupportSettings.TermsOfServiceLink) {
 		o.SupportSettings.TermsOfServiceLink = nil
 	}
@@ -827,6 +1079,16 @@ func (o *Config) SetDefaults() {
 		*o.LdapSettings.SyncIntervalMinutes = 60
 	}
 
+	if o.LdapSettings.EnableGroupSync == nil {
+		o.LdapSettings.EnableGroupSync = new(bool)
+		*o.LdapSettings.EnableGroupSync = false
+	}
+
+	if o.LdapSettings.GroupSyncIntervalMinutes == nil {
+		o.LdapSettings.GroupSyncIntervalMinutes = new(int)
+		*o.LdapSettings.GroupSyncIntervalMinutes = 60
+	}
+
 	if o.LdapSettings.SkipCertificateVerification == nil {
 		o.LdapSettings.SkipCertificateVerification = new(bool)
 		*o.LdapSettings.SkipCertificateVerification = false
@@ -909,6 +1171,21 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.RestrictCustomEmojiCreation = RESTRICT_EMOJI_CREATION_ALL
 	}
 
+	if o.ServiceSettings.MaxEmojiFrames == nil {
+		o.ServiceSettings.MaxEmojiFrames = new(int)
+		*o.ServiceSettings.MaxEmojiFrames = SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_FRAMES
+	}
+
+	if o.ServiceSettings.MaxEmojiUploadWidth == nil {
+		o.ServiceSettings.MaxEmojiUploadWidth = new(int)
+		*o.ServiceSettings.MaxEmojiUploadWidth = SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_UPLOAD_WIDTH
+	}
+
+	if o.ServiceSettings.MaxEmojiUploadHeight == nil {
+		o.ServiceSettings.MaxEmojiUploadHeight = new(int)
+		*o.ServiceSettings.MaxEmojiUploadHeight = SERVICE_SETTINGS_DEFAULT_MAX_EMOJI_UPLOAD_HEIGHT
+	}
+
 	if o.ServiceSettings.RestrictPostDelete == nil {
 		o.ServiceSettings.RestrictPostDelete = new(string)
 		*o.ServiceSettings.RestrictPostDelete = PERMISSIONS_DELETE_POST_ALL
@@ -968,6 +1245,46 @@ func (o *Config) SetDefaults() {
 		*o.ComplianceSettings.EnableDaily = false
 	}
 
+	if o.ComplianceSettings.ExportSigningKey == nil || len(*o.ComplianceSettings.ExportSigningKey) == 0 {
+		o.ComplianceSettings.ExportSigningKey = new(string)
+		*o.ComplianceSettings.ExportSigningKey = NewRandomString(32)
+	}
+
+	if o.ComplianceSettings.EnablePostIntegrityChain == nil {
+		o.ComplianceSettings.EnablePostIntegrityChain = new(bool)
+		*o.ComplianceSettings.EnablePostIntegrityChain = false
+	}
+
+	if o.ComplianceSettings.GlobalRelayEmailAddress == nil {
+		o.ComplianceSettings.GlobalRelayEmailAddress = new(string)
+		*o.ComplianceSettings.GlobalRelayEmailAddress = ""
+	}
+
+	if o.ComplianceSettings.SftpServer == nil {
+		o.ComplianceSettings.SftpServer = new(string)
+		*o.ComplianceSettings.SftpServer = ""
+	}
+
+	if o.ComplianceSettings.SftpUsername == nil {
+		o.ComplianceSettings.SftpUsername = new(string)
+		*o.ComplianceSettings.SftpUsername = ""
+	}
+
+	if o.ComplianceSettings.SftpPassword == nil {
+		o.ComplianceSettings.SftpPassword = new(string)
+		*o.ComplianceSettings.SftpPassword = ""
+	}
+
+	if o.ComplianceSettings.SftpHostKey == nil {
+		o.ComplianceSettings.SftpHostKey = new(string)
+		*o.ComplianceSettings.SftpHostKey = ""
+	}
+
+	if o.ComplianceSettings.SftpDirectory == nil {
+		o.ComplianceSettings.SftpDirectory = new(string)
+		*o.ComplianceSettings.SftpDirectory = ""
+	}
+
 	if o.LocalizationSettings.DefaultServerLocale == nil {
 		o.LocalizationSettings.DefaultServerLocale = new(string)
 		*o.LocalizationSettings.DefaultServerLocale = DEFAULT_LOCALE
@@ -1068,6 +1385,15 @@ func (o *Config) SetDefaults() {
 		*o.SamlSettings.PositionAttribute = SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE
 	}
 
+	if o.SamlSettings.GroupAttribute == nil {
+		o.SamlSettings.GroupAttribute = new(string)
+		*o.SamlSettings.GroupAttribute = SAML_SETTINGS_DEFAULT_GROUP_ATTRIBUTE
+	}
+
+	if o.SamlSettings.GroupMappings == nil {
+		o.SamlSettings.GroupMappings = []*SamlGroupMapping{}
+	}
+
 	if o.SamlSettings.LocaleAttribute == nil {
 		o.SamlSettings.LocaleAttribute = new(string)
 		*o.SamlSettings.LocaleAttribute = SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE
@@ -1158,7 +1484,20 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.ClusterLogTimeoutMilliseconds = 2000
 	}
 
+	o.defaultEndpointRateLimitSettings()
+	o.defaultIdSeedSettings()
 	o.defaultWebrtcSettings()
+	o.defaultIncidentSettings()
+	o.defaultOpenIdSettings()
+	o.defaultCacheSettings()
+	o.defaultElasticsearchSettings()
+	o.defaultClientRequirementsSettings()
+	o.defaultAuditSettings()
+	o.defaultDataRetentionSettings()
+
+	if o.FeatureFlagSettings.Flags == nil {
+		o.FeatureFlagSettings.Flags = []*FeatureFlag{}
+	}
 }
 
 func (o *Config) IsValid() *AppError {
@@ -1167,6 +1506,10 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.login_attempts.app_error", nil, "")
 	}
 
+	if *o.ServiceSettings.EnforceMultifactorAuthenticationAfter < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.enforce_mfa_after.app_error", nil, "")
+	}
+
 	if len(*o.ServiceSettings.SiteURL) != 0 {
 		if _, err := url.ParseRequestURI(*o.ServiceSettings.SiteURL); err != nil {
 			return NewLocAppError("Config.IsValid", "model.config.is_valid.site_url.app_error", nil, "")
@@ -1177,6 +1520,16 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.listen_address.app_error", nil, "")
 	}
 
+	for _, listener := range o.ServiceSettings.AdditionalListeners {
+		if err := listener.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if *o.ServiceSettings.EnableGrpcServer && len(*o.ServiceSettings.GrpcListenAddress) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.grpc_listen_address.app_error", nil, "")
+	}
+
 	if *o.ClusterSettings.Enable && *o.EmailSettings.EnableEmailBatching {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.cluster_email_batching.app_error", nil, "")
 	}
@@ -1197,10 +1550,30 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_notify_per_channel.app_error", nil, "")
 	}
 
+	if *o.TeamSettings.LargeChannelThreshold < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.large_channel_threshold.app_error", nil, "")
+	}
+
 	if !(*o.TeamSettings.RestrictDirectMessage == DIRECT_MESSAGE_ANY || *o.TeamSettings.RestrictDirectMessage == DIRECT_MESSAGE_TEAM) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.restrict_direct_message.app_error", nil, "")
 	}
 
+	if !(*o.TeamSettings.ChannelAdminSuccession == CHANNEL_ADMIN_SUCCESSION_NONE || *o.TeamSettings.ChannelAdminSuccession == CHANNEL_ADMIN_SUCCESSION_LONGEST_TENURED) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.channel_admin_succession.app_error", nil, "")
+	}
+
+	if *o.TeamSettings.NameRedirectGracePeriodDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.name_redirect_grace_period_days.app_error", nil, "")
+	}
+
+	if *o.TeamSettings.ChannelAutoArchiveIdleDays <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.channel_auto_archive_idle_days.app_error", nil, "")
+	}
+
+	if *o.TeamSettings.ChannelAutoArchiveGracePeriodDays < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.channel_auto_archive_grace_period_days.app_error", nil, "")
+	}
+
 	if len(o.SqlSettings.AtRestEncryptKey) < 32 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.encrypt_sql.app_error", nil, "")
 	}
@@ -1221,6 +1594,14 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.sql_max_conn.app_error", nil, "")
 	}
 
+	if *o.SqlSettings.SlowQueryThresholdMilliseconds < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.sql_slow_query_threshold.app_error", nil, "")
+	}
+
+	if *o.SqlSettings.SlowQueryExplainThreshold < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.sql_slow_query_explain_threshold.app_error", nil, "")
+	}
+
 	if *o.FileSettings.MaxFileSize <= 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_file_size.app_error", nil, "")
 	}
@@ -1269,6 +1650,19 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_reset_salt.app_error", nil, "")
 	}
 
+	if len(*o.ComplianceSettings.ExportSigningKey) < 32 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.compliance_export_signing_key.app_error", nil, "")
+	}
+
+	if len(*o.ComplianceSettings.GlobalRelayEmailAddress) > 0 && !IsValidEmail(*o.ComplianceSettings.GlobalRelayEmailAddress) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.compliance_global_relay_email_address.app_error", nil, "")
+	}
+
+	// The host key of the SFTP server is required so that exports are never uploaded to an impostor
+	if len(*o.ComplianceSettings.SftpServer) > 0 && len(*o.ComplianceSettings.SftpHostKey) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.compliance_sftp_host_key.app_error", nil, "")
+	}
+
 	if *o.EmailSettings.EmailBatchingBufferSize <= 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_batching_buffer_size.app_error", nil, "")
 	}
@@ -1277,6 +1671,22 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.email_batching_interval.app_error", nil, "")
 	}
 
+	if *o.EmailSettings.BulkEmailMaxPerMinute <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.bulk_email_max_per_minute.app_error", nil, "")
+	}
+
+	for _, proxy := range o.EmailSettings.PushProxies {
+		if !IsValidHttpUrl(proxy.Url) {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.push_proxy_url.app_error", nil, "")
+		}
+
+		for _, platform := range proxy.Platforms {
+			if !IsValidPushPlatform(platform) {
+				return NewLocAppError("Config.IsValid", "model.config.is_valid.push_proxy_platform.app_error", map[string]interface{}{"Platform": platform}, "")
+			}
+		}
+	}
+
 	if o.RateLimitSettings.MemoryStoreSize <= 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.rate_mem.app_error", nil, "")
 	}
@@ -1293,6 +1703,10 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.ldap_sync_interval.app_error", nil, "")
 	}
 
+	if *o.LdapSettings.GroupSyncIntervalMinutes <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.ldap_group_sync_interval.app_error", nil, "")
+	}
+
 	if *o.LdapSettings.MaxPageSize < 0 {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.ldap_max_page_size.app_error", nil, "")
 	}
@@ -1359,6 +1773,16 @@ func (o *Config) IsValid() *AppError {
 		if len(*o.SamlSettings.EmailAttribute) == 0 {
 			return NewLocAppError("Config.IsValid", "model.config.is_valid.saml_email_attribute.app_error", nil, "")
 		}
+
+		if len(o.SamlSettings.GroupMappings) > 0 && len(*o.SamlSettings.GroupAttribute) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.saml_group_attribute.app_error", nil, "")
+		}
+	}
+
+	for _, mapping := range o.SamlSettings.GroupMappings {
+		if err := mapping.IsValid(); err != nil {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.saml_group_mapping.app_error", map[string]interface{}{"Group": mapping.Group}, err.Error())
+		}
 	}
 
 	if *o.PasswordSettings.MinimumLength < PASSWORD_MINIMUM_LENGTH || *o.PasswordSettings.MinimumLength > PASSWORD_MAXIMUM_LENGTH {
@@ -1373,10 +1797,56 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_burst.app_error", nil, "")
 	}
 
+	if err := o.isValidEndpointRateLimitSettings(); err != nil {
+		return err
+	}
+
+	if *o.ServiceSettings.MaxEmojiFrames <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_emoji_frames.app_error", nil, "")
+	}
+
+	if *o.ServiceSettings.MaxEmojiUploadWidth <= 0 || *o.ServiceSettings.MaxEmojiUploadHeight <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.max_emoji_upload_dimensions.app_error", nil, "")
+	}
+
 	if err := o.isValidWebrtcSettings(); err != nil {
 		return err
 	}
 
+	if err := o.isValidOpenIdSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidCacheSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidElasticsearchSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidClientRequirementsSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidAuditSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidDataRetentionSettings(); err != nil {
+		return err
+	}
+
+	if len(*o.IncidentSettings.AnnouncementChannelId) != 0 && len(*o.IncidentSettings.AnnouncementChannelId) != 26 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.incident_announcement_channel_id.app_error", nil, "")
+	}
+
+	for _, flag := range o.FeatureFlagSettings.Flags {
+		if err := flag.IsValid(); err != nil {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.feature_flag.app_error", map[string]interface{}{"Name": flag.Name}, err.Error())
+		}
+	}
+
 	if !(*o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_NONE || *o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_TLS) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.webserver_security.app_error", nil, "")
 	}
@@ -1416,6 +1886,10 @@ func (o *Config) Sanitize() {
 
 	o.EmailSettings.InviteSalt = FAKE_SETTING
 	o.EmailSettings.PasswordResetSalt = FAKE_SETTING
+	*o.ComplianceSettings.ExportSigningKey = FAKE_SETTING
+	if len(*o.ComplianceSettings.SftpPassword) > 0 {
+		*o.ComplianceSettings.SftpPassword = FAKE_SETTING
+	}
 	if len(o.EmailSettings.SMTPPassword) > 0 {
 		o.EmailSettings.SMTPPassword = FAKE_SETTING
 	}
@@ -1424,12 +1898,125 @@ func (o *Config) Sanitize() {
 		o.GitLabSettings.Secret = FAKE_SETTING
 	}
 
+	if len(*o.OpenIdSettings.Secret) > 0 {
+		*o.OpenIdSettings.Secret = FAKE_SETTING
+	}
+
 	o.SqlSettings.DataSource = FAKE_SETTING
 	o.SqlSettings.AtRestEncryptKey = FAKE_SETTING
 
 	for i := range o.SqlSettings.DataSourceReplicas {
 		o.SqlSettings.DataSourceReplicas[i] = FAKE_SETTING
 	}
+
+	if len(*o.CacheSettings.RedisPassword) > 0 {
+		*o.CacheSettings.RedisPassword = FAKE_SETTING
+	}
+
+	if len(*o.ElasticsearchSettings.Password) > 0 {
+		*o.ElasticsearchSettings.Password = FAKE_SETTING
+	}
+
+	if len(*o.AuditSettings.WebhookSecret) > 0 {
+		*o.AuditSettings.WebhookSecret = FAKE_SETTING
+	}
+}
+
+func (o *Config) defaultEndpointRateLimitSettings() {
+	if o.ServiceSettings.EnableEndpointRateLimits == nil {
+		o.ServiceSettings.EnableEndpointRateLimits = new(bool)
+		*o.ServiceSettings.EnableEndpointRateLimits = false
+	}
+
+	if o.ServiceSettings.EndpointRateLimitVaryBy == nil {
+		o.ServiceSettings.EndpointRateLimitVaryBy = new(string)
+		*o.ServiceSettings.EndpointRateLimitVaryBy = ENDPOINT_RATE_LIMIT_VARY_BY_SESSION
+	}
+
+	if o.ServiceSettings.LoginRateLimitPerMinute == nil {
+		o.ServiceSettings.LoginRateLimitPerMinute = new(int)
+		*o.ServiceSettings.LoginRateLimitPerMinute = 10
+	}
+
+	if o.ServiceSettings.LoginRateLimitMaxBurst == nil {
+		o.ServiceSettings.LoginRateLimitMaxBurst = new(int)
+		*o.ServiceSettings.LoginRateLimitMaxBurst = 5
+	}
+
+	if o.ServiceSettings.FileUploadRateLimitPerMinute == nil {
+		o.ServiceSettings.FileUploadRateLimitPerMinute = new(int)
+		*o.ServiceSettings.FileUploadRateLimitPerMinute = 60
+	}
+
+	if o.ServiceSettings.FileUploadRateLimitMaxBurst == nil {
+		o.ServiceSettings.FileUploadRateLimitMaxBurst = new(int)
+		*o.ServiceSettings.FileUploadRateLimitMaxBurst = 10
+	}
+
+	if o.ServiceSettings.WebSocketRateLimitPerMinute == nil {
+		o.ServiceSettings.WebSocketRateLimitPerMinute = new(int)
+		*o.ServiceSettings.WebSocketRateLimitPerMinute = 30
+	}
+
+	if o.ServiceSettings.WebSocketRateLimitMaxBurst == nil {
+		o.ServiceSettings.WebSocketRateLimitMaxBurst = new(int)
+		*o.ServiceSettings.WebSocketRateLimitMaxBurst = 10
+	}
+
+	if o.ServiceSettings.ApiRateLimitPerMinute == nil {
+		o.ServiceSettings.ApiRateLimitPerMinute = new(int)
+		*o.ServiceSettings.ApiRateLimitPerMinute = 600
+	}
+
+	if o.ServiceSettings.ApiRateLimitMaxBurst == nil {
+		o.ServiceSettings.ApiRateLimitMaxBurst = new(int)
+		*o.ServiceSettings.ApiRateLimitMaxBurst = 100
+	}
+
+	if o.ServiceSettings.EnableBotAccountCreation == nil {
+		o.ServiceSettings.EnableBotAccountCreation = new(bool)
+		*o.ServiceSettings.EnableBotAccountCreation = false
+	}
+
+	if o.ServiceSettings.EnableUserAccessTokens == nil {
+		o.ServiceSettings.EnableUserAccessTokens = new(bool)
+		*o.ServiceSettings.EnableUserAccessTokens = false
+	}
+
+	if o.ServiceSettings.EnableGraphQL == nil {
+		o.ServiceSettings.EnableGraphQL = new(bool)
+		*o.ServiceSettings.EnableGraphQL = false
+	}
+
+	if o.ServiceSettings.EnableGrpcServer == nil {
+		o.ServiceSettings.EnableGrpcServer = new(bool)
+		*o.ServiceSettings.EnableGrpcServer = false
+	}
+
+	if o.ServiceSettings.GrpcListenAddress == nil {
+		o.ServiceSettings.GrpcListenAddress = new(string)
+		*o.ServiceSettings.GrpcListenAddress = ":8066"
+	}
+
+	if o.ServiceSettings.AdditionalListeners == nil {
+		o.ServiceSettings.AdditionalListeners = []*Listener{}
+	}
+
+	for _, listener := range o.ServiceSettings.AdditionalListeners {
+		listener.SetDefaults()
+	}
+
+	if o.ServiceSettings.EnableSharedChannels == nil {
+		o.ServiceSettings.EnableSharedChannels = new(bool)
+		*o.ServiceSettings.EnableSharedChannels = false
+	}
+}
+
+func (o *Config) defaultIdSeedSettings() {
+	if o.ServiceSettings.IdSeed == nil {
+		o.ServiceSettings.IdSeed = new(int64)
+		*o.ServiceSettings.IdSeed = 0
+	}
 }
 
 func (o *Config) defaultWebrtcSettings() {
@@ -1474,6 +2061,287 @@ func (o *Config) defaultWebrtcSettings() {
 	}
 }
 
+func (o *Config) defaultIncidentSettings() {
+	if o.IncidentSettings.Enable == nil {
+		o.IncidentSettings.Enable = new(bool)
+		*o.IncidentSettings.Enable = false
+	}
+
+	if o.IncidentSettings.AnnouncementChannelId == nil {
+		o.IncidentSettings.AnnouncementChannelId = new(string)
+		*o.IncidentSettings.AnnouncementChannelId = ""
+	}
+
+	if o.IncidentSettings.EnablePublicFeed == nil {
+		o.IncidentSettings.EnablePublicFeed = new(bool)
+		*o.IncidentSettings.EnablePublicFeed = false
+	}
+}
+
+func (o *Config) defaultOpenIdSettings() {
+	if o.OpenIdSettings.Enable == nil {
+		o.OpenIdSettings.Enable = new(bool)
+		*o.OpenIdSettings.Enable = false
+	}
+
+	if o.OpenIdSettings.Issuer == nil {
+		o.OpenIdSettings.Issuer = new(string)
+		*o.OpenIdSettings.Issuer = ""
+	}
+
+	if o.OpenIdSettings.Id == nil {
+		o.OpenIdSettings.Id = new(string)
+		*o.OpenIdSettings.Id = ""
+	}
+
+	if o.OpenIdSettings.Secret == nil {
+		o.OpenIdSettings.Secret = new(string)
+		*o.OpenIdSettings.Secret = ""
+	}
+
+	if o.OpenIdSettings.Scope == nil {
+		o.OpenIdSettings.Scope = new(string)
+		*o.OpenIdSettings.Scope = OPENID_SETTINGS_DEFAULT_SCOPE
+	}
+
+	if o.OpenIdSettings.UsernameClaim == nil {
+		o.OpenIdSettings.UsernameClaim = new(string)
+		*o.OpenIdSettings.UsernameClaim = OPENID_SETTINGS_DEFAULT_USERNAME_CLAIM
+	}
+
+	if o.OpenIdSettings.EmailClaim == nil {
+		o.OpenIdSettings.EmailClaim = new(string)
+		*o.OpenIdSettings.EmailClaim = OPENID_SETTINGS_DEFAULT_EMAIL_CLAIM
+	}
+
+	if o.OpenIdSettings.FirstNameClaim == nil {
+		o.OpenIdSettings.FirstNameClaim = new(string)
+		*o.OpenIdSettings.FirstNameClaim = OPENID_SETTINGS_DEFAULT_FIRST_NAME_CLAIM
+	}
+
+	if o.OpenIdSettings.LastNameClaim == nil {
+		o.OpenIdSettings.LastNameClaim = new(string)
+		*o.OpenIdSettings.LastNameClaim = OPENID_SETTINGS_DEFAULT_LAST_NAME_CLAIM
+	}
+
+	if o.OpenIdSettings.ButtonText == nil {
+		o.OpenIdSettings.ButtonText = new(string)
+		*o.OpenIdSettings.ButtonText = ""
+	}
+}
+
+func (o *Config) defaultCacheSettings() {
+	if o.CacheSettings.CacheType == nil {
+		o.CacheSettings.CacheType = new(string)
+		*o.CacheSettings.CacheType = CACHE_TYPE_LRU
+	}
+
+	if o.CacheSettings.RedisAddress == nil {
+		o.CacheSettings.RedisAddress = new(string)
+		*o.CacheSettings.RedisAddress = ""
+	}
+
+	if o.CacheSettings.RedisPassword == nil {
+		o.CacheSettings.RedisPassword = new(string)
+		*o.CacheSettings.RedisPassword = ""
+	}
+
+	if o.CacheSettings.RedisDatabase == nil {
+		o.CacheSettings.RedisDatabase = new(int)
+		*o.CacheSettings.RedisDatabase = 0
+	}
+
+	if o.CacheSettings.MemoryBudgetMB == nil {
+		o.CacheSettings.MemoryBudgetMB = new(int)
+		*o.CacheSettings.MemoryBudgetMB = 0
+	}
+
+	if o.CacheSettings.CacheSizes == nil {
+		o.CacheSettings.CacheSizes = make(map[string]int)
+	}
+
+	if o.CacheSettings.EnableWarmUp == nil {
+		o.CacheSettings.EnableWarmUp = new(bool)
+		*o.CacheSettings.EnableWarmUp = false
+	}
+
+	if o.CacheSettings.WarmUpHours == nil {
+		o.CacheSettings.WarmUpHours = new(int)
+		*o.CacheSettings.WarmUpHours = 24
+	}
+
+	if o.CacheSettings.WarmUpChannels == nil {
+		o.CacheSettings.WarmUpChannels = new(int)
+		*o.CacheSettings.WarmUpChannels = 1000
+	}
+}
+
+func (o *Config) defaultClientRequirementsSettings() {
+	if o.ClientRequirementsSettings.DesktopMinVersion == nil {
+		o.ClientRequirementsSettings.DesktopMinVersion = new(string)
+		*o.ClientRequirementsSettings.DesktopMinVersion = ""
+	}
+
+	if o.ClientRequirementsSettings.MobileMinVersion == nil {
+		o.ClientRequirementsSettings.MobileMinVersion = new(string)
+		*o.ClientRequirementsSettings.MobileMinVersion = ""
+	}
+
+	if o.ClientRequirementsSettings.WebMinVersion == nil {
+		o.ClientRequirementsSettings.WebMinVersion = new(string)
+		*o.ClientRequirementsSettings.WebMinVersion = ""
+	}
+}
+
+func (o *Config) defaultAuditSettings() {
+	if o.AuditSettings.EnableSyslog == nil {
+		o.AuditSettings.EnableSyslog = new(bool)
+		*o.AuditSettings.EnableSyslog = false
+	}
+
+	if o.AuditSettings.SyslogNetwork == nil {
+		o.AuditSettings.SyslogNetwork = new(string)
+		*o.AuditSettings.SyslogNetwork = AUDIT_SYSLOG_NETWORK_UDP
+	}
+
+	if o.AuditSettings.SyslogAddress == nil {
+		o.AuditSettings.SyslogAddress = new(string)
+		*o.AuditSettings.SyslogAddress = "localhost:514"
+	}
+
+	if o.AuditSettings.EnableFile == nil {
+		o.AuditSettings.EnableFile = new(bool)
+		*o.AuditSettings.EnableFile = false
+	}
+
+	if o.AuditSettings.FileName == nil {
+		o.AuditSettings.FileName = new(string)
+		*o.AuditSettings.FileName = ""
+	}
+
+	if o.AuditSettings.EnableWebhook == nil {
+		o.AuditSettings.EnableWebhook = new(bool)
+		*o.AuditSettings.EnableWebhook = false
+	}
+
+	if o.AuditSettings.WebhookUrl == nil {
+		o.AuditSettings.WebhookUrl = new(string)
+		*o.AuditSettings.WebhookUrl = ""
+	}
+
+	if o.AuditSettings.WebhookSecret == nil {
+		o.AuditSettings.WebhookSecret = new(string)
+		*o.AuditSettings.WebhookSecret = ""
+	}
+
+	if o.AuditSettings.BufferSize == nil {
+		o.AuditSettings.BufferSize = new(int)
+		*o.AuditSettings.BufferSize = AUDIT_SINK_BUFFER_SIZE
+	}
+
+	if o.AuditSettings.MaxRetries == nil {
+		o.AuditSettings.MaxRetries = new(int)
+		*o.AuditSettings.MaxRetries = AUDIT_SINK_MAX_RETRIES
+	}
+}
+
+func (o *Config) defaultDataRetentionSettings() {
+	if o.DataRetentionSettings.EnableMessageDeletion == nil {
+		o.DataRetentionSettings.EnableMessageDeletion = new(bool)
+		*o.DataRetentionSettings.EnableMessageDeletion = false
+	}
+
+	if o.DataRetentionSettings.EnableFileDeletion == nil {
+		o.DataRetentionSettings.EnableFileDeletion = new(bool)
+		*o.DataRetentionSettings.EnableFileDeletion = false
+	}
+
+	if o.DataRetentionSettings.MessageRetentionDays == nil {
+		o.DataRetentionSettings.MessageRetentionDays = new(int)
+		*o.DataRetentionSettings.MessageRetentionDays = DATA_RETENTION_DEFAULT_DAYS
+	}
+
+	if o.DataRetentionSettings.FileRetentionDays == nil {
+		o.DataRetentionSettings.FileRetentionDays = new(int)
+		*o.DataRetentionSettings.FileRetentionDays = DATA_RETENTION_DEFAULT_DAYS
+	}
+
+	if o.DataRetentionSettings.DeletionJobStartTime == nil {
+		o.DataRetentionSettings.DeletionJobStartTime = new(string)
+		*o.DataRetentionSettings.DeletionJobStartTime = DATA_RETENTION_DEFAULT_START
+	}
+
+	if o.DataRetentionSettings.BatchSize == nil {
+		o.DataRetentionSettings.BatchSize = new(int)
+		*o.DataRetentionSettings.BatchSize = DATA_RETENTION_DEFAULT_BATCH
+	}
+}
+
+func (o *Config) defaultElasticsearchSettings() {
+	if o.ElasticsearchSettings.ConnectionUrl == nil {
+		o.ElasticsearchSettings.ConnectionUrl = new(string)
+		*o.ElasticsearchSettings.ConnectionUrl = ""
+	}
+
+	if o.ElasticsearchSettings.Username == nil {
+		o.ElasticsearchSettings.Username = new(string)
+		*o.ElasticsearchSettings.Username = ""
+	}
+
+	if o.ElasticsearchSettings.Password == nil {
+		o.ElasticsearchSettings.Password = new(string)
+		*o.ElasticsearchSettings.Password = ""
+	}
+
+	if o.ElasticsearchSettings.EnableIndexing == nil {
+		o.ElasticsearchSettings.EnableIndexing = new(bool)
+		*o.ElasticsearchSettings.EnableIndexing = false
+	}
+
+	if o.ElasticsearchSettings.EnableSearching == nil {
+		o.ElasticsearchSettings.EnableSearching = new(bool)
+		*o.ElasticsearchSettings.EnableSearching = false
+	}
+
+	if o.ElasticsearchSettings.IndexPrefix == nil {
+		o.ElasticsearchSettings.IndexPrefix = new(string)
+		*o.ElasticsearchSettings.IndexPrefix = "mattermost_"
+	}
+
+	if o.ElasticsearchSettings.RequestTimeoutSeconds == nil {
+		o.ElasticsearchSettings.RequestTimeoutSeconds = new(int)
+		*o.ElasticsearchSettings.RequestTimeoutSeconds = 30
+	}
+
+	if o.ElasticsearchSettings.BulkIndexingBatchSize == nil {
+		o.ElasticsearchSettings.BulkIndexingBatchSize = new(int)
+		*o.ElasticsearchSettings.BulkIndexingBatchSize = 500
+	}
+}
+
+func (o *Config) isValidEndpointRateLimitSettings() *AppError {
+	if *o.ServiceSettings.EndpointRateLimitVaryBy != ENDPOINT_RATE_LIMIT_VARY_BY_SESSION && *o.ServiceSettings.EndpointRateLimitVaryBy != ENDPOINT_RATE_LIMIT_VARY_BY_USER {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.endpoint_rate_limit_vary_by.app_error", nil, "")
+	}
+
+	for _, limit := range []int{
+		*o.ServiceSettings.LoginRateLimitPerMinute,
+		*o.ServiceSettings.LoginRateLimitMaxBurst,
+		*o.ServiceSettings.FileUploadRateLimitPerMinute,
+		*o.ServiceSettings.FileUploadRateLimitMaxBurst,
+		*o.ServiceSettings.WebSocketRateLimitPerMinute,
+		*o.ServiceSettings.WebSocketRateLimitMaxBurst,
+		*o.ServiceSettings.ApiRateLimitPerMinute,
+		*o.ServiceSettings.ApiRateLimitMaxBurst,
+	} {
+		if limit <= 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.endpoint_rate_limit.app_error", nil, "")
+		}
+	}
+
+	return nil
+}
+
 func (o *Config) isValidWebrtcSettings() *AppError {
 	if *o.WebrtcSettings.Enable {
 		if len(*o.WebrtcSettings.GatewayWebsocketUrl) == 0 || !IsValidWebsocketUrl(*o.WebrtcSettings.GatewayWebsocketUrl) {
@@ -1499,3 +2367,132 @@ func (o *Config) isValidWebrtcSettings() *AppError {
 
 	return nil
 }
+
+func (o *Config) isValidOpenIdSettings() *AppError {
+	if *o.OpenIdSettings.Enable {
+		if !IsValidHttpUrl(*o.OpenIdSettings.Issuer) {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_issuer.app_error", nil, "")
+		} else if len(*o.OpenIdSettings.Id) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_id.app_error", nil, "")
+		} else if len(*o.OpenIdSettings.UsernameClaim) == 0 || len(*o.OpenIdSettings.EmailClaim) == 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_claims.app_error", nil, "")
+		}
+	}
+
+	return nil
+}
+
+func (o *Config) isValidElasticsearchSettings() *AppError {
+	if *o.ElasticsearchSettings.EnableIndexing && len(*o.ElasticsearchSettings.ConnectionUrl) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.elasticsearch_connection_url.app_error", nil, "")
+	}
+
+	if *o.ElasticsearchSettings.EnableSearching && !*o.ElasticsearchSettings.EnableIndexing {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.elasticsearch_enable_searching.app_error", nil, "")
+	}
+
+	if *o.ElasticsearchSettings.RequestTimeoutSeconds <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.elasticsearch_request_timeout.app_error", nil, "")
+	}
+
+	if *o.ElasticsearchSettings.BulkIndexingBatchSize <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.elasticsearch_batch_size.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func (o *Config) isValidClientRequirementsSettings() *AppError {
+	for _, version := range []string{*o.ClientRequirementsSettings.DesktopMinVersion, *o.ClientRequirementsSettings.MobileMinVersion, *o.ClientRequirementsSettings.WebMinVersion} {
+		if len(version) > 0 && !IsValidVersion(version) {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.client_min_version.app_error", map[string]interface{}{"Version": version}, "")
+		}
+	}
+
+	return nil
+}
+
+func (o *Config) isValidAuditSettings() *AppError {
+	if *o.AuditSettings.EnableSyslog {
+		network := *o.AuditSettings.SyslogNetwork
+		if !(network == AUDIT_SYSLOG_NETWORK_UDP || network == AUDIT_SYSLOG_NETWORK_TCP || network == AUDIT_SYSLOG_NETWORK_TLS) {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_syslog_network.app_error", nil, "")
+		}
+
+		if _, _, err := net.SplitHostPort(*o.AuditSettings.SyslogAddress); err != nil {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_syslog_address.app_error", nil, err.Error())
+		}
+	}
+
+	if *o.AuditSettings.EnableFile && len(*o.AuditSettings.FileName) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_file_name.app_error", nil, "")
+	}
+
+	if *o.AuditSettings.EnableWebhook && !IsValidHttpUrl(*o.AuditSettings.WebhookUrl) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_webhook_url.app_error", nil, "")
+	}
+
+	if *o.AuditSettings.BufferSize <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_buffer_size.app_error", nil, "")
+	}
+
+	if *o.AuditSettings.MaxRetries < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.audit_max_retries.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func (o *Config) isValidDataRetentionSettings() *AppError {
+	if *o.DataRetentionSettings.MessageRetentionDays <= 0 || *o.DataRetentionSettings.MessageRetentionDays > RETENTION_POLICY_MAX_DAYS {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_message_retention_days.app_error", nil, "")
+	}
+
+	if *o.DataRetentionSettings.FileRetentionDays <= 0 || *o.DataRetentionSettings.FileRetentionDays > RETENTION_POLICY_MAX_DAYS {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_file_retention_days.app_error", nil, "")
+	}
+
+	if _, err := time.Parse(DATA_RETENTION_START_TIME_LAYOUT, *o.DataRetentionSettings.DeletionJobStartTime); err != nil {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_job_start_time.app_error", nil, err.Error())
+	}
+
+	if *o.DataRetentionSettings.BatchSize <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention_batch_size.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func (o *Config) isValidCacheSettings() *AppError {
+	if *o.CacheSettings.CacheType != CACHE_TYPE_LRU && *o.CacheSettings.CacheType != CACHE_TYPE_REDIS {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_type.app_error", nil, "")
+	}
+
+	if *o.CacheSettings.CacheType == CACHE_TYPE_REDIS && len(*o.CacheSettings.RedisAddress) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_redis_address.app_error", nil, "")
+	}
+
+	if *o.CacheSettings.RedisDatabase < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_redis_database.app_error", nil, "")
+	}
+
+	if *o.CacheSettings.MemoryBudgetMB < 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_memory_budget.app_error", nil, "")
+	}
+
+	if *o.CacheSettings.WarmUpHours <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_warm_up_hours.app_error", nil, "")
+	}
+
+	if *o.CacheSettings.WarmUpChannels <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_warm_up_channels.app_error", nil, "")
+	}
+
+	for name, size := range o.CacheSettings.CacheSizes {
+		if size <= 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.cache_sizes.app_error", map[string]interface{}{"Name": name}, "")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	POST_PROPS_CROSS_POST_ID  = "cross_post_id"
+	POST_PROPS_THREAD_ROOT_ID = "thread_root_id" // Set on the channel copy of a thread reply
+
+	CROSS_POST_MAX_CHANNELS = 20
+)
+
+// CrossPost links a post to the other copies of its message created by the same cross-post
+// request. All of the linked posts share the CrossPostId, which is also kept in their props.
+type CrossPost struct {
+	CrossPostId string `json:"cross_post_id"`
+	PostId      string `json:"post_id"`
+	ChannelId   string `json:"channel_id"`
+}
+
+// CrossPostRequest is the body of a request to post one message to several channels.
+type CrossPostRequest struct {
+	Post       *Post    `json:"post"`
+	ChannelIds []string `json:"channel_ids"`
+}
+
+func (o *CrossPost) IsValid() *AppError {
+	if len(o.CrossPostId) != 26 {
+		return NewAppError("CrossPost.IsValid", "model.cross_post.is_valid.cross_post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.PostId) != 26 {
+		return NewAppError("CrossPost.IsValid", "model.cross_post.is_valid.post_id.app_error", nil, "cross_post_id="+o.CrossPostId, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("CrossPost.IsValid", "model.cross_post.is_valid.channel_id.app_error", nil, "cross_post_id="+o.CrossPostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *CrossPostRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func CrossPostRequestFromJson(data io.Reader) *CrossPostRequest {
+	decoder := json.NewDecoder(data)
+	var o CrossPostRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// GetCrossPostId returns the id shared by the posts created together with this one, or an
+// empty string if the post wasn't cross-posted.
+func (o *Post) GetCrossPostId() string {
+	if crossPostId, ok := o.Props[POST_PROPS_CROSS_POST_ID].(string); ok {
+		return crossPostId
+	}
+
+	return ""
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrossPostRequestJson(t *testing.T) {
+	o := CrossPostRequest{Post: &Post{Message: "hello"}, ChannelIds: []string{NewId(), NewId()}}
+	json := o.ToJson()
+	ro := CrossPostRequestFromJson(strings.NewReader(json))
+
+	if ro.Post == nil || ro.Post.Message != "hello" || len(ro.ChannelIds) != 2 || ro.ChannelIds[1] != o.ChannelIds[1] {
+		t.Fatal("requests do not match")
+	}
+}
+
+func TestCrossPostIsValid(t *testing.T) {
+	o := CrossPost{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CrossPostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostGetCrossPostId(t *testing.T) {
+	o := Post{}
+
+	if o.GetCrossPostId() != "" {
+		t.Fatal("should not have a cross post id")
+	}
+
+	crossPostId := NewId()
+	o.AddProp(POST_PROPS_CROSS_POST_ID, crossPostId)
+	if o.GetCrossPostId() != crossPostId {
+		t.Fatal("should have returned the cross post id")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	CUSTOM_STATUS_EMOJI_MAX_RUNES = 64
+	CUSTOM_STATUS_TEXT_MAX_RUNES  = 100
+)
+
+// CustomStatus is a short message that a user shows next to their status. It is cleared
+// automatically once ExpiresAt has passed, unless ExpiresAt is 0.
+type CustomStatus struct {
+	Emoji     string `json:"emoji"`
+	Text      string `json:"text"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+func (o *CustomStatus) IsValid() *AppError {
+	if len(o.Emoji) == 0 && len(o.Text) == 0 {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.empty.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Emoji) > CUSTOM_STATUS_EMOJI_MAX_RUNES {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.emoji.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Text) > CUSTOM_STATUS_TEXT_MAX_RUNES {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.text.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.ExpiresAt < 0 {
+		return NewAppError("CustomStatus.IsValid", "model.custom_status.is_valid.expires_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *CustomStatus) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func CustomStatusFromJson(data io.Reader) *CustomStatus {
+	decoder := json.NewDecoder(data)
+	var o CustomStatus
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
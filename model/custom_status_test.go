@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomStatusJson(t *testing.T) {
+	o := CustomStatus{Emoji: "calendar", Text: "In a meeting", ExpiresAt: GetMillis()}
+	json := o.ToJson()
+	ro := CustomStatusFromJson(strings.NewReader(json))
+
+	if ro == nil || *ro != o {
+		t.Fatal("custom statuses do not match")
+	}
+}
+
+func TestCustomStatusIsValid(t *testing.T) {
+	o := CustomStatus{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emoji = "calendar"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Emoji = strings.Repeat("a", CUSTOM_STATUS_EMOJI_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emoji = ""
+	o.Text = strings.Repeat("😀", CUSTOM_STATUS_TEXT_MAX_RUNES)
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Text += "a"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Text = "In a meeting"
+	o.ExpiresAt = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
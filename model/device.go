@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	DEVICE_PLATFORM_IOS     = "ios"
+	DEVICE_PLATFORM_ANDROID = "android"
+	DEVICE_PLATFORM_DESKTOP = "desktop"
+	DEVICE_PLATFORM_WEB     = "web"
+
+	DEVICE_APP_VERSION_MAX_LENGTH = 32
+	DEVICE_PUSH_TOKEN_MAX_LENGTH  = 512
+	DEVICE_USER_AGENT_MAX_LENGTH  = 512
+)
+
+// Device is an app that a user has signed in from. Each session has at most one device, which is
+// kept separately from the session so that it can be described without parsing the session's props.
+type Device struct {
+	Id         string `json:"id"`
+	UserId     string `json:"user_id"`
+	SessionId  string `json:"session_id"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+	PushToken  string `json:"push_token"` // In the same form as Session.DeviceId, which is the push platform followed by the token
+	UserAgent  string `json:"user_agent"`
+	CreateAt   int64  `json:"create_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+}
+
+type DeviceVersionCount struct {
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+	Count      int64  `json:"count"`
+}
+
+func (o *Device) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("Device.IsValid", "model.device.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("Device.IsValid", "model.device.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.SessionId) != 26 {
+		return NewAppError("Device.IsValid", "model.device.is_valid.session_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Platform {
+	case DEVICE_PLATFORM_IOS, DEVICE_PLATFORM_ANDROID, DEVICE_PLATFORM_DESKTOP, DEVICE_PLATFORM_WEB:
+	default:
+		return NewAppError("Device.IsValid", "model.device.is_valid.platform.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.AppVersion) > DEVICE_APP_VERSION_MAX_LENGTH {
+		return NewAppError("Device.IsValid", "model.device.is_valid.app_version.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PushToken) > DEVICE_PUSH_TOKEN_MAX_LENGTH {
+		return NewAppError("Device.IsValid", "model.device.is_valid.push_token.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserAgent) > DEVICE_USER_AGENT_MAX_LENGTH {
+		return NewAppError("Device.IsValid", "model.device.is_valid.user_agent.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("Device.IsValid", "model.device.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.LastSeenAt == 0 {
+		return NewAppError("Device.IsValid", "model.device.is_valid.last_seen_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Device) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+
+	o.LastSeenAt = GetMillis()
+
+	if len(o.UserAgent) > DEVICE_USER_AGENT_MAX_LENGTH {
+		o.UserAgent = o.UserAgent[:DEVICE_USER_AGENT_MAX_LENGTH]
+	}
+}
+
+// Sanitize removes the push token so that it can't be used to send notifications to the device.
+func (o *Device) Sanitize() {
+	o.PushToken = ""
+}
+
+// GetDevicePlatform works out which platform a device is on from its push token, falling back to
+// the app named in its X-Client-Version header and then to its user agent.
+func GetDevicePlatform(pushToken string, clientVersion string, userAgent string) string {
+	if index := strings.Index(pushToken, ":"); index != -1 {
+		switch pushToken[:index] {
+		case PUSH_NOTIFY_APPLE, PUSH_NOTIFY_APPLE_REACT_NATIVE:
+			return DEVICE_PLATFORM_IOS
+		case PUSH_NOTIFY_ANDROID, PUSH_NOTIFY_ANDROID_REACT_NATIVE:
+			return DEVICE_PLATFORM_ANDROID
+		}
+	}
+
+	if index := strings.Index(clientVersion, "/"); index != -1 {
+		switch strings.ToLower(clientVersion[:index]) {
+		case DEVICE_PLATFORM_IOS:
+			return DEVICE_PLATFORM_IOS
+		case DEVICE_PLATFORM_ANDROID:
+			return DEVICE_PLATFORM_ANDROID
+		case CLIENT_PLATFORM_DESKTOP:
+			return DEVICE_PLATFORM_DESKTOP
+		}
+	}
+
+	switch {
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPad"):
+		return DEVICE_PLATFORM_IOS
+	case strings.Contains(userAgent, "Android"):
+		return DEVICE_PLATFORM_ANDROID
+	case strings.Contains(userAgent, "Electron"):
+		return DEVICE_PLATFORM_DESKTOP
+	}
+
+	return DEVICE_PLATFORM_WEB
+}
+
+func (o *Device) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DeviceFromJson(data io.Reader) *Device {
+	decoder := json.NewDecoder(data)
+	var o Device
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func DeviceListToJson(l []*Device) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DeviceListFromJson(data io.Reader) []*Device {
+	decoder := json.NewDecoder(data)
+	var o []*Device
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func DeviceVersionCountListToJson(l []*DeviceVersionCount) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DeviceVersionCountListFromJson(data io.Reader) []*DeviceVersionCount {
+	decoder := json.NewDecoder(data)
+	var o []*DeviceVersionCount
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeviceJson(t *testing.T) {
+	o := Device{Id: NewId(), UserId: NewId(), Platform: DEVICE_PLATFORM_IOS, AppVersion: "1.0.0"}
+	json := o.ToJson()
+	ro := DeviceFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.UserId != ro.UserId || o.Platform != ro.Platform || o.AppVersion != ro.AppVersion {
+		t.Fatal("devices do not match")
+	}
+}
+
+func TestDeviceIsValid(t *testing.T) {
+	o := Device{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.SessionId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Platform = DEVICE_PLATFORM_WEB
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.AppVersion = strings.Repeat("1", DEVICE_APP_VERSION_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AppVersion = "3.7.1"
+	o.PushToken = strings.Repeat("a", DEVICE_PUSH_TOKEN_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PushToken = ""
+	o.UserAgent = strings.Repeat("a", DEVICE_USER_AGENT_MAX_LENGTH+1)
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal("should have truncated the user agent", err)
+	}
+}
+
+func TestGetDevicePlatform(t *testing.T) {
+	cases := []struct {
+		pushToken     string
+		clientVersion string
+		userAgent     string
+		platform      string
+	}{
+		{"apple:token", "", "", DEVICE_PLATFORM_IOS},
+		{"apple_rn:token", "", "", DEVICE_PLATFORM_IOS},
+		{"android_rn:token", "ios/1.0.0", "", DEVICE_PLATFORM_ANDROID},
+		{"", "ios/1.0.0", "okhttp/3.4.1", DEVICE_PLATFORM_IOS},
+		{"", "Android/1.0.0", "", DEVICE_PLATFORM_ANDROID},
+		{"", "desktop/3.7.1", "", DEVICE_PLATFORM_DESKTOP},
+		{"", "", "Mozilla/5.0 (Macintosh) Mattermost/3.7.1 Electron/1.6.6 Safari/537.36", DEVICE_PLATFORM_DESKTOP},
+		{"", "", "Mozilla/5.0 (iPhone; CPU iPhone OS 10_3 like Mac OS X) Mobile/14E277", DEVICE_PLATFORM_IOS},
+		{"", "", "Mozilla/5.0 (Linux; Android 7.0) Chrome/58.0 Mobile Safari/537.36", DEVICE_PLATFORM_ANDROID},
+		{"", "web/3.9.0", "Mozilla/5.0 (Windows NT 10.0) Chrome/58.0 Safari/537.36", DEVICE_PLATFORM_WEB},
+		{"", "", "", DEVICE_PLATFORM_WEB},
+	}
+
+	for _, c := range cases {
+		if platform := GetDevicePlatform(c.pushToken, c.clientVersion, c.userAgent); platform != c.platform {
+			t.Fatalf("expected %v for %v, got %v", c.platform, c, platform)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Draft is an unsent message kept on the server so that it follows the user across devices. A user
+// has at most one draft per channel and one per thread, identified by ChannelId and RootId.
+type Draft struct {
+	UserId    string          `json:"user_id"`
+	ChannelId string          `json:"channel_id"`
+	RootId    string          `json:"root_id"`
+	Message   string          `json:"message"`
+	Props     StringInterface `json:"props"`
+	FileIds   StringArray     `json:"file_ids,omitempty"`
+	CreateAt  int64           `json:"create_at"`
+	UpdateAt  int64           `json:"update_at"`
+}
+
+func (o *Draft) IsValid() *AppError {
+	if len(o.UserId) != 26 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.channel_id.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if !(len(o.RootId) == 26 || len(o.RootId) == 0) {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.root_id.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > POST_MESSAGE_MAX_RUNES {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.message.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.file_ids.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_RUNES {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.props.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.create_at.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.update_at.app_error", nil, "user_id="+o.UserId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Draft) PreSave() {
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+func (o *Draft) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DraftFromJson(data io.Reader) *Draft {
+	decoder := json.NewDecoder(data)
+	var o Draft
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func DraftListToJson(l []*Draft) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DraftListFromJson(data io.Reader) []*Draft {
+	decoder := json.NewDecoder(data)
+	var o []*Draft
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
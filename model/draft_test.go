@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDraftJson(t *testing.T) {
+	o := Draft{UserId: NewId(), ChannelId: NewId(), Message: "hello"}
+	json := o.ToJson()
+	ro := DraftFromJson(strings.NewReader(json))
+
+	if o.UserId != ro.UserId || o.ChannelId != ro.ChannelId || o.Message != ro.Message {
+		t.Fatal("drafts do not match")
+	}
+}
+
+func TestDraftIsValid(t *testing.T) {
+	o := Draft{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = NewId()
+	o.Message = strings.Repeat("a", POST_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = "hello"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDraftPreSave(t *testing.T) {
+	o := Draft{}
+	o.PreSave()
+
+	if o.CreateAt == 0 || o.UpdateAt == 0 || o.Props == nil || o.FileIds == nil {
+		t.Fatal("should have set defaults")
+	}
+
+	createAt := o.CreateAt
+	o.PreSave()
+
+	if o.CreateAt != createAt {
+		t.Fatal("shouldn't have changed the create time")
+	}
+}
@@ -8,13 +8,58 @@ import (
 	"io"
 )
 
+const (
+	EMOJI_NAME_MAX_LENGTH    = 64
+	EMOJI_ALIASES_MAX_LENGTH = 1024
+
+	// Custom emoji can be put in the same categories as the built-in emoji so that they're shown
+	// alongside them. Emoji without a category are shown in the custom category.
+	EMOJI_CATEGORY_PEOPLE   = "people"
+	EMOJI_CATEGORY_NATURE   = "nature"
+	EMOJI_CATEGORY_FOODS    = "foods"
+	EMOJI_CATEGORY_ACTIVITY = "activity"
+	EMOJI_CATEGORY_PLACES   = "places"
+	EMOJI_CATEGORY_OBJECTS  = "objects"
+	EMOJI_CATEGORY_SYMBOLS  = "symbols"
+	EMOJI_CATEGORY_FLAGS    = "flags"
+	EMOJI_CATEGORY_CUSTOM   = "custom"
+)
+
+var emojiCategories = map[string]bool{
+	EMOJI_CATEGORY_PEOPLE:   true,
+	EMOJI_CATEGORY_NATURE:   true,
+	EMOJI_CATEGORY_FOODS:    true,
+	EMOJI_CATEGORY_ACTIVITY: true,
+	EMOJI_CATEGORY_PLACES:   true,
+	EMOJI_CATEGORY_OBJECTS:  true,
+	EMOJI_CATEGORY_SYMBOLS:  true,
+	EMOJI_CATEGORY_FLAGS:    true,
+	EMOJI_CATEGORY_CUSTOM:   true,
+}
+
 type Emoji struct {
-	Id        string `json:"id"`
-	CreateAt  int64  `json:"create_at"`
-	UpdateAt  int64  `json:"update_at"`
-	DeleteAt  int64  `json:"delete_at"`
-	CreatorId string `json:"creator_id"`
-	Name      string `json:"name"`
+	Id        string      `json:"id"`
+	CreateAt  int64       `json:"create_at"`
+	UpdateAt  int64       `json:"update_at"`
+	DeleteAt  int64       `json:"delete_at"`
+	CreatorId string      `json:"creator_id"`
+	Name      string      `json:"name"`
+	Category  string      `json:"category"`
+	Aliases   StringArray `json:"aliases"` // Other names that the emoji can be used and searched for by
+}
+
+type EmojiPatch struct {
+	Category *string      `json:"category"`
+	Aliases  *StringArray `json:"aliases"`
+}
+
+type EmojiSearch struct {
+	Term       string `json:"term"`
+	PrefixOnly bool   `json:"prefix_only"`
+}
+
+func IsValidEmojiCategory(category string) bool {
+	return len(category) == 0 || emojiCategories[category]
 }
 
 func (emoji *Emoji) IsValid() *AppError {
@@ -34,10 +79,26 @@ func (emoji *Emoji) IsValid() *AppError {
 		return NewLocAppError("Emoji.IsValid", "model.emoji.user_id.app_error", nil, "")
 	}
 
-	if len(emoji.Name) == 0 || len(emoji.Name) > 64 {
+	if len(emoji.Name) == 0 || len(emoji.Name) > EMOJI_NAME_MAX_LENGTH {
 		return NewLocAppError("Emoji.IsValid", "model.emoji.name.app_error", nil, "")
 	}
 
+	if !IsValidEmojiCategory(emoji.Category) {
+		return NewLocAppError("Emoji.IsValid", "model.emoji.category.app_error", nil, "id="+emoji.Id)
+	}
+
+	seen := map[string]bool{emoji.Name: true}
+	for _, alias := range emoji.Aliases {
+		if len(alias) == 0 || len(alias) > EMOJI_NAME_MAX_LENGTH || seen[alias] {
+			return NewLocAppError("Emoji.IsValid", "model.emoji.aliases.app_error", nil, "id="+emoji.Id)
+		}
+		seen[alias] = true
+	}
+
+	if len(ArrayToJson(emoji.Aliases)) > EMOJI_ALIASES_MAX_LENGTH {
+		return NewLocAppError("Emoji.IsValid", "model.emoji.aliases.app_error", nil, "id="+emoji.Id)
+	}
+
 	return nil
 }
 
@@ -48,10 +109,28 @@ func (emoji *Emoji) PreSave() {
 
 	emoji.CreateAt = GetMillis()
 	emoji.UpdateAt = emoji.CreateAt
+
+	if emoji.Aliases == nil {
+		emoji.Aliases = StringArray{}
+	}
 }
 
 func (emoji *Emoji) PreUpdate() {
 	emoji.UpdateAt = GetMillis()
+
+	if emoji.Aliases == nil {
+		emoji.Aliases = StringArray{}
+	}
+}
+
+func (emoji *Emoji) Patch(patch *EmojiPatch) {
+	if patch.Category != nil {
+		emoji.Category = *patch.Category
+	}
+
+	if patch.Aliases != nil {
+		emoji.Aliases = *patch.Aliases
+	}
 }
 
 func (emoji *Emoji) ToJson() string {
@@ -93,3 +172,43 @@ func EmojiListFromJson(data io.Reader) []*Emoji {
 		return nil
 	}
 }
+
+func (p *EmojiPatch) ToJson() string {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EmojiPatchFromJson(data io.Reader) *EmojiPatch {
+	decoder := json.NewDecoder(data)
+	var patch EmojiPatch
+	err := decoder.Decode(&patch)
+	if err == nil {
+		return &patch
+	} else {
+		return nil
+	}
+}
+
+func (s *EmojiSearch) ToJson() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EmojiSearchFromJson(data io.Reader) *EmojiSearch {
+	decoder := json.NewDecoder(data)
+	var search EmojiSearch
+	err := decoder.Decode(&search)
+	if err == nil {
+		return &search
+	} else {
+		return nil
+	}
+}
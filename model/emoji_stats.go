@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// EmojiMessageCount is the number of messages that have used an emoji in their text since it was created.
+type EmojiMessageCount struct {
+	EmojiName  string `json:"emoji_name"`
+	Count      int64  `json:"count"`
+	LastUsedAt int64  `json:"last_used_at"`
+}
+
+// EmojiStats describes how often a custom emoji has been used, both as a reaction and in message text.
+type EmojiStats struct {
+	EmojiId           string `json:"emoji_id"`
+	EmojiName         string `json:"emoji_name"`
+	ReactionCount     int64  `json:"reaction_count"`
+	ReactionUserCount int64  `json:"reaction_user_count"`
+	MessageCount      int64  `json:"message_count"`
+	LastUsedAt        int64  `json:"last_used_at"`
+}
+
+func (o *EmojiStats) ToJson() string {
+	if b, err := json.Marshal(o); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EmojiStatsFromJson(data io.Reader) *EmojiStats {
+	decoder := json.NewDecoder(data)
+	var o EmojiStats
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func EmojiStatsListToJson(l []*EmojiStats) string {
+	if b, err := json.Marshal(l); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EmojiStatsListFromJson(data io.Reader) []*EmojiStats {
+	decoder := json.NewDecoder(data)
+	var o []*EmojiStats
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmojiStatsJson(t *testing.T) {
+	stats := &EmojiStats{EmojiId: NewId(), EmojiName: "party_parrot", ReactionCount: 3, ReactionUserCount: 2, MessageCount: 5}
+
+	if result := EmojiStatsFromJson(strings.NewReader(stats.ToJson())); *result != *stats {
+		t.Fatal("stats should have round tripped")
+	}
+
+	list := EmojiStatsListFromJson(strings.NewReader(EmojiStatsListToJson([]*EmojiStats{stats})))
+	if len(list) != 1 || *list[0] != *stats {
+		t.Fatal("stats list should have round tripped")
+	}
+}
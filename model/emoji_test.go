@@ -60,4 +60,57 @@ func TestEmojiIsValid(t *testing.T) {
 	if err := emoji.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+	emoji.Category = "unknown"
+	if err := emoji.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown category")
+	}
+
+	emoji.Category = EMOJI_CATEGORY_PEOPLE
+	if err := emoji.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	emoji.Aliases = StringArray{"alias", ""}
+	if err := emoji.IsValid(); err == nil {
+		t.Fatal("should be invalid with an empty alias")
+	}
+
+	emoji.Aliases = StringArray{"alias", "alias"}
+	if err := emoji.IsValid(); err == nil {
+		t.Fatal("should be invalid with duplicate aliases")
+	}
+
+	emoji.Aliases = StringArray{emoji.Name}
+	if err := emoji.IsValid(); err == nil {
+		t.Fatal("should be invalid with an alias that's the same as the name")
+	}
+
+	emoji.Aliases = StringArray{}
+	for i := 0; i < 20; i++ {
+		emoji.Aliases = append(emoji.Aliases, strings.Repeat(string(rune('a'+i)), 64))
+	}
+	if err := emoji.IsValid(); err == nil {
+		t.Fatal("should be invalid with too many aliases")
+	}
+
+	emoji.Aliases = StringArray{"alias", "other_alias"}
+	if err := emoji.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmojiPatch(t *testing.T) {
+	emoji := &Emoji{Name: "name", Category: EMOJI_CATEGORY_PEOPLE, Aliases: StringArray{"alias"}}
+
+	category := EMOJI_CATEGORY_OBJECTS
+	emoji.Patch(&EmojiPatch{Category: &category})
+	if emoji.Category != EMOJI_CATEGORY_OBJECTS || len(emoji.Aliases) != 1 {
+		t.Fatal("should only have patched the category")
+	}
+
+	patch := EmojiPatchFromJson(strings.NewReader(`{"aliases": ["one", "two"]}`))
+	emoji.Patch(patch)
+	if emoji.Category != EMOJI_CATEGORY_OBJECTS || len(emoji.Aliases) != 2 || emoji.Aliases[1] != "two" {
+		t.Fatal("should only have patched the aliases")
+	}
 }
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	EMOJI_USAGE_NAME_MAX_LENGTH = 64
+	EMOJI_USAGE_WINDOW_DAYS     = 30
+	EMOJI_USAGE_DAY_MILLIS      = 24 * 60 * 60 * 1000
+)
+
+var emojiUsageRegex = regexp.MustCompile(`:([a-zA-Z0-9_+\-]+):`)
+
+// EmojiUsage counts how many times a user used an emoji on a given day, either as a reaction or in
+// a message. Day is the start of the UTC day in milliseconds.
+type EmojiUsage struct {
+	UserId    string `json:"user_id"`
+	EmojiName string `json:"emoji_name"`
+	Day       int64  `json:"day"`
+	Count     int64  `json:"count"`
+}
+
+type EmojiUsageCount struct {
+	EmojiName string `json:"emoji_name"`
+	Count     int64  `json:"count"`
+}
+
+// GetEmojiUsageDay returns the start of the UTC day containing the given time in milliseconds.
+func GetEmojiUsageDay(millis int64) int64 {
+	return millis - millis%EMOJI_USAGE_DAY_MILLIS
+}
+
+// EmojiNamesFromMessage returns the names of the emoji used in a message, such as "smile" for
+// ":smile:". Each name is only returned once and text like "12:30:45" isn't mistaken for an emoji.
+func EmojiNamesFromMessage(message string) []string {
+	names := []string{}
+	seen := make(map[string]bool)
+
+	for _, match := range emojiUsageRegex.FindAllStringSubmatchIndex(message, -1) {
+		if match[0] > 0 {
+			if r, _ := utf8.DecodeLastRuneInString(message[:match[0]]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+				continue
+			}
+		}
+
+		if match[1] < len(message) {
+			if r, _ := utf8.DecodeRuneInString(message[match[1]:]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+				continue
+			}
+		}
+
+		name := strings.ToLower(message[match[2]:match[3]])
+		if len(name) > EMOJI_USAGE_NAME_MAX_LENGTH || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func EmojiUsageCountListToJson(l []*EmojiUsageCount) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EmojiUsageCountListFromJson(data io.Reader) []*EmojiUsageCount {
+	decoder := json.NewDecoder(data)
+	var o []*EmojiUsageCount
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmojiNamesFromMessage(t *testing.T) {
+	for message, expected := range map[string]string{
+		"":                              "",
+		"no emoji here":                 "",
+		":smile:":                       "smile",
+		"hello :smile: world :+1:":      "smile,+1",
+		":smile::smile: and :Smile:":    "smile",
+		"meet at 12:30:45":              "",
+		"(:thumbsup:)":                  "thumbsup",
+		"a:smile:b":                     "",
+		":white_check_mark: :-1: :100:": "white_check_mark,-1,100",
+	} {
+		if names := strings.Join(EmojiNamesFromMessage(message), ","); names != expected {
+			t.Fatalf("expected %q for %q, got %q", expected, message, names)
+		}
+	}
+}
+
+func TestGetEmojiUsageDay(t *testing.T) {
+	day := GetEmojiUsageDay(GetMillis())
+
+	if day%EMOJI_USAGE_DAY_MILLIS != 0 {
+		t.Fatal("should be the start of a day")
+	}
+
+	if GetEmojiUsageDay(day+EMOJI_USAGE_DAY_MILLIS-1) != day {
+		t.Fatal("should be the same day")
+	}
+}
+
+func TestEmojiUsageCountListJson(t *testing.T) {
+	counts := []*EmojiUsageCount{{EmojiName: "smile", Count: 3}}
+	rcounts := EmojiUsageCountListFromJson(strings.NewReader(EmojiUsageCountListToJson(counts)))
+
+	if len(rcounts) != 1 || *rcounts[0] != *counts[0] {
+		t.Fatal("counts do not match")
+	}
+}
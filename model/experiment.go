@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	EXPERIMENT_NAME_MAX_LENGTH        = 64
+	EXPERIMENT_DESCRIPTION_MAX_LENGTH = 1024
+	EXPERIMENT_VARIANT_MAX_LENGTH     = 32
+	EXPERIMENT_MIN_VARIANTS           = 2
+	EXPERIMENT_MAX_VARIANTS           = 10
+)
+
+// Experiment splits users between variants of a feature. Users are assigned with a stable hash of
+// their id so they always see the same variant. When FlagName is set only the users the feature
+// flag is enabled for take part in the experiment.
+type Experiment struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	FlagName    string      `json:"flag_name"`
+	Variants    StringArray `json:"variants"`
+	Active      bool        `json:"active"`
+	CreateAt    int64       `json:"create_at"`
+	UpdateAt    int64       `json:"update_at"`
+}
+
+// ExperimentExposure records that a user was shown a variant of an experiment.
+type ExperimentExposure struct {
+	ExperimentName string `json:"experiment_name"`
+	UserId         string `json:"user_id"`
+	Variant        string `json:"variant"`
+	CreateAt       int64  `json:"create_at"`
+	LastExposureAt int64  `json:"last_exposure_at"`
+	ExposureCount  int64  `json:"exposure_count"`
+}
+
+type ExperimentVariantSummary struct {
+	Variant   string `json:"variant"`
+	Users     int64  `json:"users"`
+	Exposures int64  `json:"exposures"`
+}
+
+type ExperimentSummary struct {
+	Name     string                      `json:"name"`
+	Variants []*ExperimentVariantSummary `json:"variants"`
+}
+
+func IsValidExperimentName(name string) bool {
+	return len(name) > 0 && len(name) <= EXPERIMENT_NAME_MAX_LENGTH && featureFlagNameRegex.MatchString(name)
+}
+
+func (o *Experiment) IsValid() *AppError {
+	if !IsValidExperimentName(o.Name) {
+		return NewAppError("Experiment.IsValid", "model.experiment.is_valid.name.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	if len(o.Description) > EXPERIMENT_DESCRIPTION_MAX_LENGTH {
+		return NewAppError("Experiment.IsValid", "model.experiment.is_valid.description.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	if len(o.FlagName) > 0 && !IsValidFeatureFlagName(o.FlagName) {
+		return NewAppError("Experiment.IsValid", "model.experiment.is_valid.flag_name.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	if len(o.Variants) < EXPERIMENT_MIN_VARIANTS || len(o.Variants) > EXPERIMENT_MAX_VARIANTS {
+		return NewAppError("Experiment.IsValid", "model.experiment.is_valid.variants.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	seen := make(map[string]bool, len(o.Variants))
+	for _, variant := range o.Variants {
+		if len(variant) > EXPERIMENT_VARIANT_MAX_LENGTH || !featureFlagNameRegex.MatchString(variant) || seen[variant] {
+			return NewAppError("Experiment.IsValid", "model.experiment.is_valid.variants.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+		}
+		seen[variant] = true
+	}
+
+	return nil
+}
+
+func (o *Experiment) PreSave() {
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *Experiment) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// GetVariant returns the variant the user is assigned to. Variants are split evenly and the
+// experiment name is part of the hash so different experiments split users independently.
+func (o *Experiment) GetVariant(userId string) string {
+	if len(o.Variants) == 0 {
+		return ""
+	}
+
+	return o.Variants[hashBucket("experiment:"+o.Name+":"+userId, len(o.Variants))]
+}
+
+func (o *Experiment) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ExperimentFromJson(data io.Reader) *Experiment {
+	decoder := json.NewDecoder(data)
+	var o Experiment
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ExperimentListToJson(l []*Experiment) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ExperimentListFromJson(data io.Reader) []*Experiment {
+	decoder := json.NewDecoder(data)
+	var o []*Experiment
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *ExperimentExposure) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ExperimentExposureFromJson(data io.Reader) *ExperimentExposure {
+	decoder := json.NewDecoder(data)
+	var o ExperimentExposure
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *ExperimentSummary) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ExperimentSummaryFromJson(data io.Reader) *ExperimentSummary {
+	decoder := json.NewDecoder(data)
+	var o ExperimentSummary
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
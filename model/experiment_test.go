@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExperimentJson(t *testing.T) {
+	o := Experiment{Name: "new_onboarding", Variants: StringArray{"control", "tour"}, Active: true}
+	json := o.ToJson()
+	ro := ExperimentFromJson(strings.NewReader(json))
+
+	if o.Name != ro.Name || len(ro.Variants) != 2 || !ro.Active {
+		t.Fatal("experiments do not match")
+	}
+}
+
+func TestExperimentIsValid(t *testing.T) {
+	o := Experiment{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = "new_onboarding"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without variants")
+	}
+
+	o.Variants = StringArray{"control"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with one variant")
+	}
+
+	o.Variants = StringArray{"control", "control"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with duplicate variants")
+	}
+
+	o.Variants = StringArray{"control", "Tour"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad variant name")
+	}
+
+	o.Variants = StringArray{"control", "tour"}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.FlagName = "Bad Flag"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.FlagName = "onboarding"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExperimentGetVariant(t *testing.T) {
+	o := Experiment{Name: "new_onboarding", Variants: StringArray{"control", "tour"}}
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		userId := NewId()
+		variant := o.GetVariant(userId)
+
+		if variant != o.GetVariant(userId) {
+			t.Fatal("variant should be stable")
+		}
+
+		counts[variant]++
+	}
+
+	if counts["control"] < 400 || counts["tour"] < 400 {
+		t.Fatal("variants should be split evenly", counts)
+	}
+
+	if (&Experiment{Name: "empty"}).GetVariant(NewId()) != "" {
+		t.Fatal("should have no variant")
+	}
+}
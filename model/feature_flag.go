@@ -0,0 +1,206 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+)
+
+const (
+	FEATURE_FLAG_NAME_MAX_LENGTH        = 64
+	FEATURE_FLAG_DESCRIPTION_MAX_LENGTH = 1024
+)
+
+var featureFlagNameRegex = regexp.MustCompile(`^[a-z0-9_\-]+$`)
+
+// FeatureFlag controls whether a server feature is enabled for a given user. A flag applies to users
+// that are explicitly allowed, to members of allowed teams and to a stable percentage of everyone else.
+type FeatureFlag struct {
+	Name              string      `json:"name"`
+	Description       string      `json:"description"`
+	Enabled           bool        `json:"enabled"`
+	RolloutPercentage int         `json:"rollout_percentage"`
+	UserIds           StringArray `json:"user_ids"`
+	TeamIds           StringArray `json:"team_ids"`
+	CreateAt          int64       `json:"create_at"`
+	UpdateAt          int64       `json:"update_at"`
+}
+
+func IsValidFeatureFlagName(name string) bool {
+	return len(name) > 0 && len(name) <= FEATURE_FLAG_NAME_MAX_LENGTH && featureFlagNameRegex.MatchString(name)
+}
+
+func (o *FeatureFlag) IsValid() *AppError {
+	if !IsValidFeatureFlagName(o.Name) {
+		return NewAppError("FeatureFlag.IsValid", "model.feature_flag.is_valid.name.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	if len(o.Description) > FEATURE_FLAG_DESCRIPTION_MAX_LENGTH {
+		return NewAppError("FeatureFlag.IsValid", "model.feature_flag.is_valid.description.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	if o.RolloutPercentage < 0 || o.RolloutPercentage > 100 {
+		return NewAppError("FeatureFlag.IsValid", "model.feature_flag.is_valid.rollout_percentage.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+	}
+
+	for _, userId := range o.UserIds {
+		if len(userId) != 26 {
+			return NewAppError("FeatureFlag.IsValid", "model.feature_flag.is_valid.user_ids.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+		}
+	}
+
+	for _, teamId := range o.TeamIds {
+		if len(teamId) != 26 {
+			return NewAppError("FeatureFlag.IsValid", "model.feature_flag.is_valid.team_ids.app_error", nil, "name="+o.Name, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+func (o *FeatureFlag) PreSave() {
+	if o.UserIds == nil {
+		o.UserIds = StringArray{}
+	}
+
+	if o.TeamIds == nil {
+		o.TeamIds = StringArray{}
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *FeatureFlag) PreUpdate() {
+	if o.UserIds == nil {
+		o.UserIds = StringArray{}
+	}
+
+	if o.TeamIds == nil {
+		o.TeamIds = StringArray{}
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+// IsEnabledFor returns whether the flag is on for a user who belongs to the given teams. The
+// percentage rollout hashes the flag name with the user id so a user keeps the same result as the
+// percentage grows and different flags roll out to different users.
+func (o *FeatureFlag) IsEnabledFor(userId string, teamIds []string) bool {
+	if !o.Enabled {
+		return false
+	}
+
+	for _, id := range o.UserIds {
+		if id == userId {
+			return true
+		}
+	}
+
+	for _, id := range o.TeamIds {
+		for _, teamId := range teamIds {
+			if id == teamId {
+				return true
+			}
+		}
+	}
+
+	if o.RolloutPercentage <= 0 || len(userId) == 0 {
+		return false
+	}
+
+	return FeatureFlagBucket(o.Name, userId) < o.RolloutPercentage
+}
+
+// FeatureFlagBucket places a user in one of 100 buckets for the given flag.
+func FeatureFlagBucket(name string, userId string) int {
+	return hashBucket(name+":"+userId, 100)
+}
+
+func hashBucket(key string, buckets int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(buckets))
+}
+
+// EvaluateFeatureFlags returns the state of every flag for a user who belongs to the given teams.
+func EvaluateFeatureFlags(flags []*FeatureFlag, userId string, teamIds []string) map[string]bool {
+	result := make(map[string]bool, len(flags))
+
+	for _, flag := range flags {
+		result[flag.Name] = flag.IsEnabledFor(userId, teamIds)
+	}
+
+	return result
+}
+
+// MergeFeatureFlags combines the flags defined in the config file with the ones stored in the
+// database. A stored flag replaces a config flag with the same name. The result is sorted by name.
+func MergeFeatureFlags(configFlags []*FeatureFlag, storedFlags []*FeatureFlag) []*FeatureFlag {
+	byName := make(map[string]*FeatureFlag, len(configFlags)+len(storedFlags))
+
+	for _, flag := range configFlags {
+		byName[flag.Name] = flag
+	}
+
+	for _, flag := range storedFlags {
+		byName[flag.Name] = flag
+	}
+
+	flags := make([]*FeatureFlag, 0, len(byName))
+	for _, flag := range byName {
+		flags = append(flags, flag)
+	}
+
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+
+	return flags
+}
+
+func (o *FeatureFlag) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func FeatureFlagFromJson(data io.Reader) *FeatureFlag {
+	decoder := json.NewDecoder(data)
+	var o FeatureFlag
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func FeatureFlagListToJson(l []*FeatureFlag) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func FeatureFlagListFromJson(data io.Reader) []*FeatureFlag {
+	decoder := json.NewDecoder(data)
+	var o []*FeatureFlag
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFeatureFlagJson(t *testing.T) {
+	o := FeatureFlag{Name: "new_sidebar", RolloutPercentage: 10, UserIds: StringArray{NewId()}}
+	json := o.ToJson()
+	ro := FeatureFlagFromJson(strings.NewReader(json))
+
+	if o.Name != ro.Name || ro.RolloutPercentage != 10 || len(ro.UserIds) != 1 {
+		t.Fatal("flags do not match")
+	}
+}
+
+func TestFeatureFlagIsValid(t *testing.T) {
+	o := FeatureFlag{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = "New Sidebar"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = "new_sidebar"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RolloutPercentage = 101
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RolloutPercentage = 50
+	o.UserIds = StringArray{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserIds = nil
+	o.TeamIds = StringArray{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestFeatureFlagIsEnabledFor(t *testing.T) {
+	userId := NewId()
+	teamId := NewId()
+
+	o := FeatureFlag{Name: "new_sidebar", UserIds: StringArray{userId}, TeamIds: StringArray{teamId}, RolloutPercentage: 100}
+	if o.IsEnabledFor(userId, nil) {
+		t.Fatal("a disabled flag should be off for everyone")
+	}
+
+	o.Enabled = true
+	o.RolloutPercentage = 0
+	if !o.IsEnabledFor(userId, nil) {
+		t.Fatal("should be on for allowed users")
+	}
+
+	if !o.IsEnabledFor(NewId(), []string{NewId(), teamId}) {
+		t.Fatal("should be on for members of allowed teams")
+	}
+
+	if o.IsEnabledFor(NewId(), []string{NewId()}) {
+		t.Fatal("should be off for everyone else")
+	}
+
+	o.RolloutPercentage = 100
+	if !o.IsEnabledFor(NewId(), nil) {
+		t.Fatal("should be on for everyone")
+	}
+
+	enabled := 0
+	o.RolloutPercentage = 30
+	for i := 0; i < 1000; i++ {
+		id := NewId()
+		if o.IsEnabledFor(id, nil) {
+			enabled++
+		}
+
+		if o.IsEnabledFor(id, nil) != (FeatureFlagBucket(o.Name, id) < 30) {
+			t.Fatal("should be stable for a user")
+		}
+	}
+
+	if enabled < 200 || enabled > 400 {
+		t.Fatalf("should roll out to about 30%% of users, got %v of 1000", enabled)
+	}
+}
+
+func TestMergeFeatureFlags(t *testing.T) {
+	configFlags := []*FeatureFlag{{Name: "b", Enabled: false}, {Name: "c", Enabled: true}}
+	storedFlags := []*FeatureFlag{{Name: "b", Enabled: true, RolloutPercentage: 100}, {Name: "a"}}
+
+	flags := MergeFeatureFlags(configFlags, storedFlags)
+	if len(flags) != 3 || flags[0].Name != "a" || flags[1].Name != "b" || flags[2].Name != "c" {
+		t.Fatal("should be merged and sorted by name")
+	}
+
+	if !flags[1].Enabled {
+		t.Fatal("stored flags should take precedence")
+	}
+
+	result := EvaluateFeatureFlags(flags, NewId(), nil)
+	if len(result) != 3 || result["a"] || !result["b"] || result["c"] {
+		t.Fatal("bad evaluation")
+	}
+}
@@ -5,6 +5,7 @@ package model
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"image"
 	"image/gif"
@@ -14,6 +15,12 @@ import (
 	"strings"
 )
 
+const (
+	FILE_INFO_CONTENT_MAX_SIZE = 65535
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
 type FileInfo struct {
 	Id              string `json:"id"`
 	CreatorId       string `json:"user_id"`
@@ -31,6 +38,7 @@ type FileInfo struct {
 	Width           int    `json:"width,omitempty"`
 	Height          int    `json:"height,omitempty"`
 	HasPreviewImage bool   `json:"has_preview_image,omitempty"`
+	Content         string `json:"-"` // text extracted for searching, not sent back to the client
 }
 
 func (info *FileInfo) ToJson() string {
@@ -109,6 +117,10 @@ func (o *FileInfo) IsValid() *AppError {
 		return NewLocAppError("FileInfo.IsValid", "model.file_info.is_valid.path.app_error", nil, "id="+o.Id)
 	}
 
+	if len(o.Content) > FILE_INFO_CONTENT_MAX_SIZE {
+		return NewLocAppError("FileInfo.IsValid", "model.file_info.is_valid.content.app_error", nil, "id="+o.Id)
+	}
+
 	return nil
 }
 
@@ -148,6 +160,9 @@ func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
 				} else {
 					info.HasPreviewImage = len(gifConfig.Image) == 1
 				}
+			} else if info.MimeType == "image/png" {
+				// Animated pngs are shown the same way as animated gifs
+				info.HasPreviewImage = GetAnimatedPngFrameCount(data) <= 1
 			} else {
 				info.HasPreviewImage = true
 			}
@@ -157,6 +172,40 @@ func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
 	return info, err
 }
 
+// GetAnimatedPngFrameCount returns the number of frames in an animated png or 0 if the data isn't one. A png is
+// only animated if its animation control chunk comes before its image data.
+func GetAnimatedPngFrameCount(data []byte) int {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return 0
+	}
+
+	for offset := len(pngSignature); offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunkType := string(data[offset+4 : offset+8])
+
+		start := offset + 8
+		if length < 0 || start+length > len(data) {
+			return 0
+		}
+
+		switch chunkType {
+		case "acTL":
+			if length < 8 {
+				return 0
+			}
+
+			return int(binary.BigEndian.Uint32(data[start:]))
+		case "IDAT", "IEND":
+			return 0
+		}
+
+		// Skip the chunk's data and its checksum
+		offset = start + length + 4
+	}
+
+	return 0
+}
+
 func GetEtagForFileInfos(infos []*FileInfo) string {
 	if len(infos) == 0 {
 		return Etag()
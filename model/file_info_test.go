@@ -198,3 +198,41 @@ func TestGetInfoForFile(t *testing.T) {
 		t.Fatalf("Got incorrect mime type: %v", info.MimeType)
 	}
 }
+
+func TestGetAnimatedPngFrameCount(t *testing.T) {
+	chunk := func(chunkType string, data ...byte) []byte {
+		return append(append([]byte{0, 0, 0, byte(len(data))}, chunkType...), append(data, 0, 0, 0, 0)...)
+	}
+
+	header := chunk("IHDR", make([]byte, 13)...)
+	animationControl := chunk("acTL", 0, 0, 0, 3, 0, 0, 0, 0)
+	imageData := chunk("IDAT", 1, 2, 3)
+
+	animated := append(append(append(append([]byte{}, pngSignature...), header...), animationControl...), imageData...)
+	if count := GetAnimatedPngFrameCount(animated); count != 3 {
+		t.Fatal("should have returned the number of frames", count)
+	}
+
+	// The animation control chunk is ignored after the image data
+	still := append(append(append(append([]byte{}, pngSignature...), header...), imageData...), animationControl...)
+	if count := GetAnimatedPngFrameCount(still); count != 0 {
+		t.Fatal("shouldn't be animated", count)
+	}
+
+	if count := GetAnimatedPngFrameCount(animated[:len(pngSignature)+len(header)+10]); count != 0 {
+		t.Fatal("shouldn't read past the end of the data", count)
+	}
+
+	if count := GetAnimatedPngFrameCount([]byte("GIF89a")); count != 0 {
+		t.Fatal("shouldn't count frames of other formats", count)
+	}
+
+	pngFile, err := ioutil.ReadFile("../tests/test.png")
+	if err != nil {
+		t.Fatalf("Failed to load test.png: %v", err.Error())
+	}
+
+	if count := GetAnimatedPngFrameCount(pngFile); count != 0 {
+		t.Fatal("shouldn't be animated", count)
+	}
+}
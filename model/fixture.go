@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+const (
+	FIXTURE_MAX_TEAMS              = 20
+	FIXTURE_MAX_USERS              = 1000
+	FIXTURE_MAX_CHANNELS_PER_TEAM  = 50
+	FIXTURE_MAX_POSTS_PER_CHANNEL  = 1000
+	FIXTURE_MAX_REACTIONS_PER_POST = 10
+	FIXTURE_DEFAULT_POST_INTERVAL  = 60 * 1000
+	FIXTURE_PREFIX_MAX_LENGTH      = 10
+)
+
+var validFixturePrefix = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// FixtureRequest describes a set of test data to create. Everything created is named after the
+// prefix and an index, and posts are timestamped from StartAt at PostInterval milliseconds
+// apart, so a request with StartAt set always produces the same data on a clean server.
+type FixtureRequest struct {
+	Prefix           string `json:"prefix"`
+	Password         string `json:"password"`
+	Teams            int    `json:"teams"`
+	Users            int    `json:"users"`
+	ChannelsPerTeam  int    `json:"channels_per_team"`
+	PostsPerChannel  int    `json:"posts_per_channel"`
+	ReactionsPerPost int    `json:"reactions_per_post"`
+	StartAt          int64  `json:"start_at"`
+	PostInterval     int64  `json:"post_interval"`
+}
+
+// FixtureResult lists the teams, users and channels created for a FixtureRequest.
+type FixtureResult struct {
+	Teams     []*Team    `json:"teams"`
+	Users     []*User    `json:"users"`
+	Channels  []*Channel `json:"channels"`
+	Posts     int        `json:"posts"`
+	Reactions int        `json:"reactions"`
+}
+
+func (o *FixtureRequest) IsValid() *AppError {
+	if len(o.Prefix) > FIXTURE_PREFIX_MAX_LENGTH || !validFixturePrefix.MatchString(o.Prefix) {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.prefix.app_error", map[string]interface{}{"MaxLength": FIXTURE_PREFIX_MAX_LENGTH}, "", http.StatusBadRequest)
+	}
+
+	if len(o.Password) == 0 {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.password.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.Teams < 0 || o.Teams > FIXTURE_MAX_TEAMS {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.teams.app_error", map[string]interface{}{"Max": FIXTURE_MAX_TEAMS}, "", http.StatusBadRequest)
+	}
+
+	if o.Users < 0 || o.Users > FIXTURE_MAX_USERS {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.users.app_error", map[string]interface{}{"Max": FIXTURE_MAX_USERS}, "", http.StatusBadRequest)
+	}
+
+	if o.ChannelsPerTeam < 0 || o.ChannelsPerTeam > FIXTURE_MAX_CHANNELS_PER_TEAM {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.channels_per_team.app_error", map[string]interface{}{"Max": FIXTURE_MAX_CHANNELS_PER_TEAM}, "", http.StatusBadRequest)
+	}
+
+	if o.PostsPerChannel < 0 || o.PostsPerChannel > FIXTURE_MAX_POSTS_PER_CHANNEL {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.posts_per_channel.app_error", map[string]interface{}{"Max": FIXTURE_MAX_POSTS_PER_CHANNEL}, "", http.StatusBadRequest)
+	}
+
+	if o.ReactionsPerPost < 0 || o.ReactionsPerPost > FIXTURE_MAX_REACTIONS_PER_POST || o.ReactionsPerPost > o.Users {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.reactions_per_post.app_error", map[string]interface{}{"Max": FIXTURE_MAX_REACTIONS_PER_POST}, "", http.StatusBadRequest)
+	}
+
+	if o.PostsPerChannel > 0 && o.Users == 0 {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.posts_without_users.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.StartAt < 0 || o.PostInterval < 0 {
+		return NewAppError("FixtureRequest.IsValid", "model.fixture_request.is_valid.timestamps.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *FixtureRequest) SetDefaults() {
+	if o.StartAt == 0 {
+		o.StartAt = GetMillis() - int64(o.PostsPerChannel)*FIXTURE_DEFAULT_POST_INTERVAL
+	}
+
+	if o.PostInterval == 0 {
+		o.PostInterval = FIXTURE_DEFAULT_POST_INTERVAL
+	}
+}
+
+func (o *FixtureRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func FixtureRequestFromJson(data io.Reader) *FixtureRequest {
+	decoder := json.NewDecoder(data)
+	var o FixtureRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *FixtureResult) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func FixtureResultFromJson(data io.Reader) *FixtureResult {
+	decoder := json.NewDecoder(data)
+	var o FixtureResult
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixtureRequestJson(t *testing.T) {
+	o := FixtureRequest{Prefix: "load", Password: "passwd", Teams: 1, Users: 2, StartAt: 1000}
+	json := o.ToJson()
+	ro := FixtureRequestFromJson(strings.NewReader(json))
+
+	if o != *ro {
+		t.Fatal("requests do not match")
+	}
+}
+
+func TestFixtureRequestIsValid(t *testing.T) {
+	o := FixtureRequest{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Prefix = "Load"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Prefix = "loadtesting1"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Prefix = "load1"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Password = "passwd"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Teams = FIXTURE_MAX_TEAMS + 1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Teams = 1
+	o.PostsPerChannel = 10
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Users = 2
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ReactionsPerPost = 3
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ReactionsPerPost = 2
+	o.StartAt = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestFixtureRequestSetDefaults(t *testing.T) {
+	o := FixtureRequest{PostsPerChannel: 10}
+	o.SetDefaults()
+
+	if o.PostInterval != FIXTURE_DEFAULT_POST_INTERVAL {
+		t.Fatal("should have set the post interval")
+	}
+
+	if o.StartAt == 0 || o.StartAt+10*o.PostInterval > GetMillis() {
+		t.Fatal("should have started the posts in the past")
+	}
+
+	o = FixtureRequest{StartAt: 1000, PostInterval: 10}
+	o.SetDefaults()
+
+	if o.StartAt != 1000 || o.PostInterval != 10 {
+		t.Fatal("should not have changed the timestamps")
+	}
+}
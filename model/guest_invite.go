@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// GuestsInvite invites people by email to join a team as guests who can only access the given channels.
+type GuestsInvite struct {
+	Emails   []string `json:"emails"`
+	Channels []string `json:"channels"`
+}
+
+func (o *GuestsInvite) IsValid() *AppError {
+	if len(o.Emails) == 0 {
+		return NewAppError("GuestsInvite.IsValid", "model.guests_invite.is_valid.emails.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, email := range o.Emails {
+		if !IsValidEmail(email) {
+			return NewAppError("GuestsInvite.IsValid", "model.guests_invite.is_valid.email.app_error", nil, "email="+email, http.StatusBadRequest)
+		}
+	}
+
+	if len(o.Channels) == 0 {
+		return NewAppError("GuestsInvite.IsValid", "model.guests_invite.is_valid.channels.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, channelId := range o.Channels {
+		if len(channelId) != 26 {
+			return NewAppError("GuestsInvite.IsValid", "model.guests_invite.is_valid.channel.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+func (o *GuestsInvite) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func GuestsInviteFromJson(data io.Reader) *GuestsInvite {
+	decoder := json.NewDecoder(data)
+	var o GuestsInvite
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuestsInviteJson(t *testing.T) {
+	o := GuestsInvite{Emails: []string{"guest@example.com"}, Channels: []string{NewId()}}
+	json := o.ToJson()
+	ro := GuestsInviteFromJson(strings.NewReader(json))
+
+	if ro == nil || len(ro.Emails) != 1 || ro.Emails[0] != o.Emails[0] || len(ro.Channels) != 1 || ro.Channels[0] != o.Channels[0] {
+		t.Fatal("invites do not match")
+	}
+}
+
+func TestGuestsInviteIsValid(t *testing.T) {
+	o := GuestsInvite{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emails = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Emails = []string{"guest@example.com"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Channels = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Channels = []string{NewId()}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+const (
+	INACTIVITY_REPORT_MAX_DAYS = 3650
+)
+
+// InactiveUser is an active account whose owner hasn't used it since the start of a report. Users
+// that have never been online have a LastActivityAt of 0.
+type InactiveUser struct {
+	Id             string `json:"id"`
+	Username       string `json:"username"`
+	Email          string `json:"email"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	CreateAt       int64  `json:"create_at"`
+	LastActivityAt int64  `json:"last_activity_at"`
+}
+
+// InactiveChannel is a public or private channel that hasn't been posted in since the start of a
+// report.
+type InactiveChannel struct {
+	Id            string `json:"id"`
+	TeamId        string `json:"team_id"`
+	TeamName      string `json:"team_name"`
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+	Type          string `json:"type"`
+	CreateAt      int64  `json:"create_at"`
+	LastPostAt    int64  `json:"last_post_at"`
+	TotalMsgCount int64  `json:"total_msg_count"`
+}
+
+// GetInactivitySince returns the time in milliseconds before which something must have last been
+// active to be reported as inactive for a number of days.
+func GetInactivitySince(days int) int64 {
+	return GetMillis() - int64(days)*24*60*60*1000
+}
+
+func InactiveUserCsvHeader() []string {
+	return []string{"Id", "Username", "Email", "FirstName", "LastName", "CreateAt", "LastActivityAt"}
+}
+
+func (o *InactiveUser) CsvRecord() []string {
+	return []string{
+		o.Id,
+		o.Username,
+		o.Email,
+		o.FirstName,
+		o.LastName,
+		strconv.FormatInt(o.CreateAt, 10),
+		strconv.FormatInt(o.LastActivityAt, 10),
+	}
+}
+
+func InactiveChannelCsvHeader() []string {
+	return []string{"Id", "TeamId", "TeamName", "Name", "DisplayName", "Type", "CreateAt", "LastPostAt", "TotalMsgCount"}
+}
+
+func (o *InactiveChannel) CsvRecord() []string {
+	return []string{
+		o.Id,
+		o.TeamId,
+		o.TeamName,
+		o.Name,
+		o.DisplayName,
+		o.Type,
+		strconv.FormatInt(o.CreateAt, 10),
+		strconv.FormatInt(o.LastPostAt, 10),
+		strconv.FormatInt(o.TotalMsgCount, 10),
+	}
+}
+
+func InactiveUserListToJson(list []*InactiveUser) string {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func InactiveUserListFromJson(data io.Reader) []*InactiveUser {
+	decoder := json.NewDecoder(data)
+
+	var list []*InactiveUser
+	if err := decoder.Decode(&list); err != nil {
+		return nil
+	} else {
+		return list
+	}
+}
+
+func InactiveChannelListToJson(list []*InactiveChannel) string {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func InactiveChannelListFromJson(data io.Reader) []*InactiveChannel {
+	decoder := json.NewDecoder(data)
+
+	var list []*InactiveChannel
+	if err := decoder.Decode(&list); err != nil {
+		return nil
+	} else {
+		return list
+	}
+}
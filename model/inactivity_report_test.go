@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInactiveUserJson(t *testing.T) {
+	list := []*InactiveUser{{Id: NewId(), Username: "someone", LastActivityAt: 1000}}
+
+	rlist := InactiveUserListFromJson(strings.NewReader(InactiveUserListToJson(list)))
+	if len(rlist) != 1 || rlist[0].Id != list[0].Id || rlist[0].LastActivityAt != 1000 {
+		t.Fatal("should have round tripped the list")
+	}
+
+	if record := list[0].CsvRecord(); len(record) != len(InactiveUserCsvHeader()) || record[6] != "1000" {
+		t.Fatal("should have matched the header", record)
+	}
+}
+
+func TestInactiveChannelJson(t *testing.T) {
+	list := []*InactiveChannel{{Id: NewId(), Name: "town-square", Type: CHANNEL_OPEN, TotalMsgCount: 5}}
+
+	rlist := InactiveChannelListFromJson(strings.NewReader(InactiveChannelListToJson(list)))
+	if len(rlist) != 1 || rlist[0].Id != list[0].Id || rlist[0].TotalMsgCount != 5 {
+		t.Fatal("should have round tripped the list")
+	}
+
+	if record := list[0].CsvRecord(); len(record) != len(InactiveChannelCsvHeader()) || record[8] != "5" {
+		t.Fatal("should have matched the header", record)
+	}
+}
+
+func TestGetInactivitySince(t *testing.T) {
+	now := GetMillis()
+
+	if since := GetInactivitySince(1); since > now-24*60*60*1000 || since < now-25*60*60*1000 {
+		t.Fatal("should have been a day ago", since, now)
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	INCIDENT_STATUS_INVESTIGATING = "investigating"
+	INCIDENT_STATUS_IDENTIFIED    = "identified"
+	INCIDENT_STATUS_MONITORING    = "monitoring"
+	INCIDENT_STATUS_RESOLVED      = "resolved"
+
+	INCIDENT_SEVERITY_MINOR    = "minor"
+	INCIDENT_SEVERITY_MAJOR    = "major"
+	INCIDENT_SEVERITY_CRITICAL = "critical"
+
+	INCIDENT_FEED_STATUS_OPERATIONAL = "operational"
+	INCIDENT_FEED_STATUS_DEGRADED    = "degraded"
+	INCIDENT_FEED_STATUS_OUTAGE      = "outage"
+
+	INCIDENT_TITLE_MAX_RUNES   = 128
+	INCIDENT_MESSAGE_MAX_RUNES = 4000
+
+	POST_PROP_INCIDENT_ID = "incident_id"
+)
+
+// Incident is a server problem announced by a system admin. Every change is announced in the
+// configured channel and the current incidents are published on the public status feed.
+type Incident struct {
+	Id         string `json:"id"`
+	CreateAt   int64  `json:"create_at"`
+	UpdateAt   int64  `json:"update_at"`
+	ResolvedAt int64  `json:"resolved_at"`
+	CreatorId  string `json:"creator_id"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+	Status     string `json:"status"`
+	Severity   string `json:"severity"`
+	PostId     string `json:"post_id"`
+}
+
+type IncidentPatch struct {
+	Title    *string `json:"title"`
+	Message  *string `json:"message"`
+	Status   *string `json:"status"`
+	Severity *string `json:"severity"`
+}
+
+// IncidentFeed is the public summary of the server's health used by external status pages.
+type IncidentFeed struct {
+	Status    string      `json:"status"`
+	UpdateAt  int64       `json:"update_at"`
+	Incidents []*Incident `json:"incidents"`
+}
+
+func IsValidIncidentStatus(status string) bool {
+	switch status {
+	case INCIDENT_STATUS_INVESTIGATING, INCIDENT_STATUS_IDENTIFIED, INCIDENT_STATUS_MONITORING, INCIDENT_STATUS_RESOLVED:
+		return true
+	}
+
+	return false
+}
+
+func IsValidIncidentSeverity(severity string) bool {
+	switch severity {
+	case INCIDENT_SEVERITY_MINOR, INCIDENT_SEVERITY_MAJOR, INCIDENT_SEVERITY_CRITICAL:
+		return true
+	}
+
+	return false
+}
+
+func (o *Incident) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Title) == 0 || utf8.RuneCountInString(o.Title) > INCIDENT_TITLE_MAX_RUNES {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.title.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > INCIDENT_MESSAGE_MAX_RUNES {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.message.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidIncidentStatus(o.Status) {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidIncidentSeverity(o.Severity) {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.severity.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PostId) != 0 && len(o.PostId) != 26 {
+		return NewAppError("Incident.IsValid", "model.incident.is_valid.post_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Incident) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Status == "" {
+		o.Status = INCIDENT_STATUS_INVESTIGATING
+	}
+
+	if o.Severity == "" {
+		o.Severity = INCIDENT_SEVERITY_MINOR
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.ResolvedAt = 0
+
+	if o.IsResolved() {
+		o.ResolvedAt = o.CreateAt
+	}
+}
+
+func (o *Incident) PreUpdate() {
+	o.UpdateAt = GetMillis()
+
+	if o.IsResolved() && o.ResolvedAt == 0 {
+		o.ResolvedAt = o.UpdateAt
+	} else if !o.IsResolved() {
+		o.ResolvedAt = 0
+	}
+}
+
+func (o *Incident) IsResolved() bool {
+	return o.Status == INCIDENT_STATUS_RESOLVED
+}
+
+// Sanitize removes the fields that only make sense inside the server before the incident is published.
+func (o *Incident) Sanitize() {
+	o.CreatorId = ""
+	o.PostId = ""
+}
+
+func (o *Incident) Patch(patch *IncidentPatch) {
+	if patch.Title != nil {
+		o.Title = *patch.Title
+	}
+
+	if patch.Message != nil {
+		o.Message = *patch.Message
+	}
+
+	if patch.Status != nil {
+		o.Status = *patch.Status
+	}
+
+	if patch.Severity != nil {
+		o.Severity = *patch.Severity
+	}
+}
+
+func (o *Incident) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func IncidentFromJson(data io.Reader) *Incident {
+	decoder := json.NewDecoder(data)
+	var o Incident
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func IncidentListToJson(l []*Incident) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func IncidentListFromJson(data io.Reader) []*Incident {
+	decoder := json.NewDecoder(data)
+	var o []*Incident
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *IncidentPatch) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func IncidentPatchFromJson(data io.Reader) *IncidentPatch {
+	decoder := json.NewDecoder(data)
+	var o IncidentPatch
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// NewIncidentFeed summarizes the given incidents. The overall status is an outage while any open
+// incident is critical and degraded while any other incident is open.
+func NewIncidentFeed(incidents []*Incident) *IncidentFeed {
+	feed := &IncidentFeed{Status: INCIDENT_FEED_STATUS_OPERATIONAL, Incidents: incidents}
+
+	for _, incident := range incidents {
+		if incident.UpdateAt > feed.UpdateAt {
+			feed.UpdateAt = incident.UpdateAt
+		}
+
+		if incident.IsResolved() {
+			continue
+		}
+
+		if incident.Severity == INCIDENT_SEVERITY_CRITICAL {
+			feed.Status = INCIDENT_FEED_STATUS_OUTAGE
+		} else if feed.Status == INCIDENT_FEED_STATUS_OPERATIONAL {
+			feed.Status = INCIDENT_FEED_STATUS_DEGRADED
+		}
+	}
+
+	return feed
+}
+
+func (o *IncidentFeed) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func IncidentFeedFromJson(data io.Reader) *IncidentFeed {
+	decoder := json.NewDecoder(data)
+	var o IncidentFeed
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
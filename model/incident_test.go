@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIncidentJson(t *testing.T) {
+	o := Incident{Id: NewId(), Title: "Outage"}
+	json := o.ToJson()
+	ro := IncidentFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Title != ro.Title {
+		t.Fatal("incidents do not match")
+	}
+}
+
+func TestIncidentIsValid(t *testing.T) {
+	o := Incident{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CreatorId = NewId()
+	o.Title = "Outage"
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Title = strings.Repeat("a", INCIDENT_TITLE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Title = "Outage"
+	o.Message = strings.Repeat("a", INCIDENT_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = ""
+	o.Status = "broken"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Status = INCIDENT_STATUS_MONITORING
+	o.Severity = "huge"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Severity = INCIDENT_SEVERITY_MAJOR
+	o.PostId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestIncidentPreSaveAndPatch(t *testing.T) {
+	o := Incident{Title: "Outage"}
+	o.PreSave()
+
+	if o.Status != INCIDENT_STATUS_INVESTIGATING || o.Severity != INCIDENT_SEVERITY_MINOR || o.ResolvedAt != 0 {
+		t.Fatal("should have set the defaults")
+	}
+
+	status := INCIDENT_STATUS_RESOLVED
+	title := "Resolved outage"
+	o.Patch(&IncidentPatch{Title: &title, Status: &status})
+	o.PreUpdate()
+
+	if o.Title != title || !o.IsResolved() || o.ResolvedAt == 0 {
+		t.Fatal("should have been patched and resolved")
+	}
+
+	status = INCIDENT_STATUS_IDENTIFIED
+	o.Patch(&IncidentPatch{Status: &status})
+	o.PreUpdate()
+
+	if o.ResolvedAt != 0 {
+		t.Fatal("reopening should clear the resolved time")
+	}
+}
+
+func TestNewIncidentFeed(t *testing.T) {
+	feed := NewIncidentFeed(nil)
+	if feed.Status != INCIDENT_FEED_STATUS_OPERATIONAL {
+		t.Fatal("should be operational without incidents")
+	}
+
+	incidents := []*Incident{
+		{Id: NewId(), UpdateAt: 3, Status: INCIDENT_STATUS_RESOLVED, Severity: INCIDENT_SEVERITY_CRITICAL},
+		{Id: NewId(), UpdateAt: 2, Status: INCIDENT_STATUS_MONITORING, Severity: INCIDENT_SEVERITY_MINOR},
+	}
+
+	feed = NewIncidentFeed(incidents)
+	if feed.Status != INCIDENT_FEED_STATUS_DEGRADED || feed.UpdateAt != 3 {
+		t.Fatal("should be degraded while a minor incident is open")
+	}
+
+	incidents[1].Severity = INCIDENT_SEVERITY_CRITICAL
+	feed = NewIncidentFeed(incidents)
+	if feed.Status != INCIDENT_FEED_STATUS_OUTAGE {
+		t.Fatal("should be an outage while a critical incident is open")
+	}
+
+	rfeed := IncidentFeedFromJson(strings.NewReader(feed.ToJson()))
+	if rfeed.Status != feed.Status || len(rfeed.Incidents) != 2 {
+		t.Fatal("feeds do not match")
+	}
+}
@@ -14,18 +14,26 @@ import (
 
 const (
 	DEFAULT_WEBHOOK_USERNAME = "webhook"
+
+	INCOMING_WEBHOOK_USERNAME_PATTERN_MAX_LENGTH = 128
+	INCOMING_WEBHOOK_FIXED_ICON_URL_MAX_LENGTH   = 1024
 )
 
+// IncomingWebhook posts messages to a channel. A hook with a UsernamePattern can only post with a
+// username override that entirely matches the pattern, and a hook with a FixedIconURL always posts
+// with that icon whatever icon the request asks for. Hooks without them can use any override.
 type IncomingWebhook struct {
-	Id          string `json:"id"`
-	CreateAt    int64  `json:"create_at"`
-	UpdateAt    int64  `json:"update_at"`
-	DeleteAt    int64  `json:"delete_at"`
-	UserId      string `json:"user_id"`
-	ChannelId   string `json:"channel_id"`
-	TeamId      string `json:"team_id"`
-	DisplayName string `json:"display_name"`
-	Description string `json:"description"`
+	Id              string `json:"id"`
+	CreateAt        int64  `json:"create_at"`
+	UpdateAt        int64  `json:"update_at"`
+	DeleteAt        int64  `json:"delete_at"`
+	UserId          string `json:"user_id"`
+	ChannelId       string `json:"channel_id"`
+	TeamId          string `json:"team_id"`
+	DisplayName     string `json:"display_name"`
+	Description     string `json:"description"`
+	UsernamePattern string `json:"username_pattern"`
+	FixedIconURL    string `json:"fixed_icon_url"`
 }
 
 type IncomingWebhookRequest struct {
@@ -112,9 +120,41 @@ func (o *IncomingWebhook) IsValid() *AppError {
 		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.description.app_error", nil, "")
 	}
 
+	if len(o.UsernamePattern) > INCOMING_WEBHOOK_USERNAME_PATTERN_MAX_LENGTH {
+		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.username_pattern.app_error", nil, "")
+	}
+
+	if len(o.UsernamePattern) > 0 {
+		if _, err := o.compileUsernamePattern(); err != nil {
+			return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.username_pattern.app_error", nil, err.Error())
+		}
+	}
+
+	if len(o.FixedIconURL) > INCOMING_WEBHOOK_FIXED_ICON_URL_MAX_LENGTH || (len(o.FixedIconURL) > 0 && !IsValidHttpUrl(o.FixedIconURL)) {
+		return NewLocAppError("IncomingWebhook.IsValid", "model.incoming_hook.fixed_icon_url.app_error", nil, "")
+	}
+
 	return nil
 }
 
+func (o *IncomingWebhook) compileUsernamePattern() (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + o.UsernamePattern + ")$")
+}
+
+// AllowsUsername returns whether the hook may post with the given username override.
+func (o *IncomingWebhook) AllowsUsername(username string) bool {
+	if len(o.UsernamePattern) == 0 {
+		return true
+	}
+
+	pattern, err := o.compileUsernamePattern()
+	if err != nil {
+		return false
+	}
+
+	return pattern.MatchString(username)
+}
+
 func (o *IncomingWebhook) PreSave() {
 	if o.Id == "" {
 		o.Id = NewId()
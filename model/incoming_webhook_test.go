@@ -89,6 +89,48 @@ func TestIncomingWebhookIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.UsernamePattern = "alerts-("
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UsernamePattern = strings.Repeat("a", INCOMING_WEBHOOK_USERNAME_PATTERN_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UsernamePattern = "alerts-.*"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.FixedIconURL = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.FixedIconURL = "https://example.com/icon.png"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIncomingWebhookAllowsUsername(t *testing.T) {
+	o := IncomingWebhook{}
+
+	if !o.AllowsUsername("anyone") {
+		t.Fatal("hooks without a pattern should allow any username")
+	}
+
+	o.UsernamePattern = "alerts|alerts-[a-z]+"
+	if !o.AllowsUsername("alerts") || !o.AllowsUsername("alerts-prod") {
+		t.Fatal("should allow usernames that match the pattern")
+	}
+
+	if o.AllowsUsername("admin") || o.AllowsUsername("alerts-prod-admin2") || o.AllowsUsername("my-alerts") {
+		t.Fatal("should only allow usernames that entirely match the pattern")
+	}
 }
 
 func TestIncomingWebhookPreSave(t *testing.T) {
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	POST_PROP_FROM_INTEGRATION = "from_integration"
+
+	INTEGRATION_TYPE_INCOMING_WEBHOOK = "incoming_webhook"
+	INTEGRATION_TYPE_OUTGOING_WEBHOOK = "outgoing_webhook"
+	INTEGRATION_TYPE_SLASH_COMMAND    = "slash_command"
+	INTEGRATION_TYPE_ALERTMANAGER     = "alertmanager"
+	INTEGRATION_TYPE_BOT              = "bot"
+
+	// The post may show the username and icon that the integration asked for
+	INTEGRATION_DISPLAY_NAME_OVERRIDE = "override"
+	// The post shows the user that owns the integration because overrides are forbidden
+	INTEGRATION_DISPLAY_NAME_CREATOR = "creator"
+)
+
+// IntegrationMetadata describes the integration that made a post. It's only ever set by the server
+// and is stored in the post's props under POST_PROP_FROM_INTEGRATION.
+type IntegrationMetadata struct {
+	Type              string `json:"type"`
+	IntegrationId     string `json:"integration_id"`
+	DisplayNamePolicy string `json:"display_name_policy"`
+}
+
+func (o *IntegrationMetadata) IsValid() *AppError {
+	switch o.Type {
+	case INTEGRATION_TYPE_INCOMING_WEBHOOK, INTEGRATION_TYPE_OUTGOING_WEBHOOK, INTEGRATION_TYPE_SLASH_COMMAND,
+		INTEGRATION_TYPE_ALERTMANAGER, INTEGRATION_TYPE_BOT:
+	default:
+		return NewAppError("IntegrationMetadata.IsValid", "model.integration_metadata.is_valid.type.app_error", nil, "type="+o.Type, http.StatusBadRequest)
+	}
+
+	if len(o.IntegrationId) != 26 {
+		return NewAppError("IntegrationMetadata.IsValid", "model.integration_metadata.is_valid.integration_id.app_error", nil, "type="+o.Type, http.StatusBadRequest)
+	}
+
+	if o.DisplayNamePolicy != INTEGRATION_DISPLAY_NAME_OVERRIDE && o.DisplayNamePolicy != INTEGRATION_DISPLAY_NAME_CREATOR {
+		return NewAppError("IntegrationMetadata.IsValid", "model.integration_metadata.is_valid.display_name_policy.app_error", nil, "type="+o.Type, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// AllowsOverrides returns whether the post may show the username and icon that the integration
+// asked for instead of its owner's.
+func (o *IntegrationMetadata) AllowsOverrides() bool {
+	return o.DisplayNamePolicy == INTEGRATION_DISPLAY_NAME_OVERRIDE
+}
+
+// GetIntegrationMetadata returns the metadata of the integration that made the post, whether it
+// was added in memory or decoded generically after being read back from the database, or nil if
+// the post wasn't made by an integration.
+func (o *Post) GetIntegrationMetadata() *IntegrationMetadata {
+	value, ok := o.Props[POST_PROP_FROM_INTEGRATION]
+	if !ok || value == nil {
+		return nil
+	}
+
+	if metadata, ok := value.(*IntegrationMetadata); ok {
+		return metadata
+	}
+
+	var metadata *IntegrationMetadata
+	if b, err := json.Marshal(value); err == nil {
+		json.Unmarshal(b, &metadata)
+	}
+	return metadata
+}
+
+func (o *Post) SetIntegrationMetadata(metadata *IntegrationMetadata) {
+	o.AddProp(POST_PROP_FROM_INTEGRATION, metadata)
+}
+
+// IsFromIntegration returns whether the post was made by a webhook, a slash command or a bot.
+func (o *Post) IsFromIntegration() bool {
+	return o.GetIntegrationMetadata() != nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntegrationMetadataIsValid(t *testing.T) {
+	o := IntegrationMetadata{Type: INTEGRATION_TYPE_INCOMING_WEBHOOK, IntegrationId: NewId(), DisplayNamePolicy: INTEGRATION_DISPLAY_NAME_OVERRIDE}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Type = "plugin"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with an unknown type")
+	}
+
+	o.Type = INTEGRATION_TYPE_BOT
+	o.IntegrationId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with a bad integration id")
+	}
+
+	o.IntegrationId = NewId()
+	o.DisplayNamePolicy = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid without a display name policy")
+	}
+}
+
+func TestPostIntegrationMetadata(t *testing.T) {
+	o := Post{Id: NewId(), ChannelId: NewId(), UserId: NewId(), CreateAt: GetMillis(), UpdateAt: GetMillis()}
+
+	if o.IsFromIntegration() {
+		t.Fatal("shouldn't be from an integration")
+	}
+
+	metadata := &IntegrationMetadata{Type: INTEGRATION_TYPE_SLASH_COMMAND, IntegrationId: NewId(), DisplayNamePolicy: INTEGRATION_DISPLAY_NAME_CREATOR}
+	o.SetIntegrationMetadata(metadata)
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	ro := PostFromJson(strings.NewReader(o.ToJson()))
+	if decoded := ro.GetIntegrationMetadata(); decoded == nil || *decoded != *metadata {
+		t.Fatal("should have decoded the metadata")
+	}
+
+	o.AddProp(POST_PROP_FROM_INTEGRATION, "junk")
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with bad metadata")
+	}
+}
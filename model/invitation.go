@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	INVITATION_EXPIRY_TIME = 1000 * 60 * 60 * 48 // 48 hours
+	INVITATION_TOKEN_SIZE  = 64
+
+	INVITATION_CHANNEL_IDS_MAX_RUNES = 4000
+)
+
+// Invitation is an invitation to join a team that was sent by email and hasn't been accepted yet. Its
+// token is part of the signup link, so the link stops working once the invitation is revoked or resent.
+type Invitation struct {
+	Id         string      `json:"id"`
+	Token      string      `json:"-"`
+	Email      string      `json:"email"`
+	TeamId     string      `json:"team_id"`
+	InviterId  string      `json:"inviter_id"`
+	Guest      bool        `json:"guest"`
+	ChannelIds StringArray `json:"channel_ids"`
+	CreateAt   int64       `json:"create_at"`
+	UpdateAt   int64       `json:"update_at"`
+	ExpireAt   int64       `json:"expire_at"`
+}
+
+func (o *Invitation) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.Token) != INVITATION_TOKEN_SIZE {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.token.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Email) > 128 || !IsValidEmail(o.Email) {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.email.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	// Invitations sent from the command line don't have an inviter
+	if !(len(o.InviterId) == 26 || len(o.InviterId) == 0) {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.inviter_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	for _, channelId := range o.ChannelIds {
+		if len(channelId) != 26 {
+			return NewAppError("Invitation.IsValid", "model.invitation.is_valid.channel_ids.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.ChannelIds)) > INVITATION_CHANNEL_IDS_MAX_RUNES {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.channel_ids.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ExpireAt <= o.UpdateAt {
+		return NewAppError("Invitation.IsValid", "model.invitation.is_valid.expire_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Invitation) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.ChannelIds == nil {
+		o.ChannelIds = []string{}
+	}
+
+	o.CreateAt = GetMillis()
+	o.Renew(o.CreateAt)
+}
+
+// Renew gives the invitation a new token and expiry time, which is done whenever it is sent.
+func (o *Invitation) Renew(now int64) {
+	o.Token = NewRandomString(INVITATION_TOKEN_SIZE)
+	o.UpdateAt = now
+	o.ExpireAt = now + INVITATION_EXPIRY_TIME
+}
+
+func (o *Invitation) IsExpired() bool {
+	return o.ExpireAt <= GetMillis()
+}
+
+func (o *Invitation) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func InvitationFromJson(data io.Reader) *Invitation {
+	decoder := json.NewDecoder(data)
+	var o Invitation
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func InvitationListToJson(l []*Invitation) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func InvitationListFromJson(data io.Reader) []*Invitation {
+	decoder := json.NewDecoder(data)
+	var o []*Invitation
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInvitationJson(t *testing.T) {
+	o := Invitation{Id: NewId(), Token: NewRandomString(INVITATION_TOKEN_SIZE), Email: "test@example.com", TeamId: NewId()}
+	json := o.ToJson()
+	ro := InvitationFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Email != ro.Email || o.TeamId != ro.TeamId {
+		t.Fatal("invitations do not match")
+	}
+
+	if strings.Contains(json, o.Token) || len(ro.Token) != 0 {
+		t.Fatal("should not have included the token")
+	}
+
+	list := InvitationListFromJson(strings.NewReader(InvitationListToJson([]*Invitation{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("invitation lists do not match")
+	}
+}
+
+func TestInvitationIsValid(t *testing.T) {
+	o := Invitation{Email: "test@example.com", TeamId: NewId()}
+	o.PreSave()
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.InviterId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.InviterId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.InviterId = ""
+	o.ChannelIds = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelIds = []string{NewId()}
+	o.Email = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Email = "test@example.com"
+	o.Token = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Token = NewRandomString(INVITATION_TOKEN_SIZE)
+	o.ExpireAt = o.UpdateAt
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestInvitationRenew(t *testing.T) {
+	o := Invitation{Email: "test@example.com", TeamId: NewId()}
+	o.PreSave()
+
+	if o.IsExpired() {
+		t.Fatal("should not have expired")
+	}
+
+	token := o.Token
+	o.Renew(GetMillis() - INVITATION_EXPIRY_TIME - 1)
+
+	if o.Token == token || len(o.Token) != INVITATION_TOKEN_SIZE {
+		t.Fatal("should have given the invitation a new token")
+	}
+
+	if !o.IsExpired() {
+		t.Fatal("should have expired")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	LDAP_GROUP_REMOTE_ID_MAX_RUNES    = 512
+	LDAP_GROUP_DISPLAY_NAME_MAX_RUNES = 128
+
+	LDAP_GROUP_SYNC_ACTION_ADD    = "add"
+	LDAP_GROUP_SYNC_ACTION_REMOVE = "remove"
+)
+
+// LdapGroup links a group of the LDAP server, identified by its DN, to a channel. The members of the
+// group are added to the channel and its team by the group sync job, and LDAP users that aren't in any
+// group linked to the channel are removed from it.
+type LdapGroup struct {
+	Id          string `json:"id"`
+	RemoteId    string `json:"remote_id"`
+	DisplayName string `json:"display_name"`
+	ChannelId   string `json:"channel_id"`
+	CreatorId   string `json:"creator_id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	LastSyncAt  int64  `json:"last_sync_at"`
+}
+
+// LdapGroupSyncChange is a membership that the group sync job made or, in a dry run, would have made.
+type LdapGroupSyncChange struct {
+	Action    string `json:"action"`
+	UserId    string `json:"user_id"`
+	TeamId    string `json:"team_id"`
+	ChannelId string `json:"channel_id"`
+}
+
+type LdapGroupSyncResult struct {
+	DryRun  bool                   `json:"dry_run"`
+	StartAt int64                  `json:"start_at"`
+	EndAt   int64                  `json:"end_at"`
+	Changes []*LdapGroupSyncChange `json:"changes"`
+	Errors  []string               `json:"errors"`
+}
+
+func (o *LdapGroup) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.RemoteId) == 0 || utf8.RuneCountInString(o.RemoteId) > LDAP_GROUP_REMOTE_ID_MAX_RUNES {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.remote_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.DisplayName) > LDAP_GROUP_DISPLAY_NAME_MAX_RUNES {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.display_name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("LdapGroup.IsValid", "model.ldap_group.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *LdapGroup) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.LastSyncAt = 0
+}
+
+func (o *LdapGroup) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LdapGroupFromJson(data io.Reader) *LdapGroup {
+	decoder := json.NewDecoder(data)
+	var o LdapGroup
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func LdapGroupListToJson(l []*LdapGroup) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LdapGroupListFromJson(data io.Reader) []*LdapGroup {
+	decoder := json.NewDecoder(data)
+	var o []*LdapGroup
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *LdapGroupSyncResult) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LdapGroupSyncResultFromJson(data io.Reader) *LdapGroupSyncResult {
+	decoder := json.NewDecoder(data)
+	var o LdapGroupSyncResult
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLdapGroupJson(t *testing.T) {
+	o := LdapGroup{Id: NewId(), RemoteId: "cn=developers,ou=groups,dc=example,dc=com", ChannelId: NewId()}
+	json := o.ToJson()
+	ro := LdapGroupFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.RemoteId != ro.RemoteId || o.ChannelId != ro.ChannelId {
+		t.Fatal("ldap groups do not match")
+	}
+
+	list := LdapGroupListFromJson(strings.NewReader(LdapGroupListToJson([]*LdapGroup{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("ldap group lists do not match")
+	}
+}
+
+func TestLdapGroupIsValid(t *testing.T) {
+	o := LdapGroup{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RemoteId = "cn=developers,ou=groups,dc=example,dc=com"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CreatorId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CreateAt = GetMillis()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UpdateAt = o.CreateAt
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.DisplayName = strings.Repeat("a", LDAP_GROUP_DISPLAY_NAME_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.DisplayName = "Developers"
+	o.RemoteId = strings.Repeat("a", LDAP_GROUP_REMOTE_ID_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestLdapGroupPreSave(t *testing.T) {
+	o := LdapGroup{LastSyncAt: 5}
+	o.PreSave()
+
+	if len(o.Id) != 26 || o.CreateAt == 0 || o.UpdateAt != o.CreateAt || o.LastSyncAt != 0 {
+		t.Fatal("should have set the id and times")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	LEGAL_HOLD_TARGET_USER    = "user"
+	LEGAL_HOLD_TARGET_CHANNEL = "channel"
+
+	LEGAL_HOLD_REASON_MAX_RUNES = 1024
+)
+
+// LegalHold keeps the content of a user or a channel from being deleted. While a user is held, the
+// data retention job and user initiated deletes skip the messages and files they posted, and while a
+// channel is held, they skip everything that was posted in it.
+type LegalHold struct {
+	Id         string `json:"id"`
+	TargetType string `json:"target_type"`
+	TargetId   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	CreatorId  string `json:"creator_id"`
+	CreateAt   int64  `json:"create_at"`
+}
+
+func (o *LegalHold) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.TargetType != LEGAL_HOLD_TARGET_USER && o.TargetType != LEGAL_HOLD_TARGET_CHANNEL {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.target_type.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TargetId) != 26 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.target_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Reason) > LEGAL_HOLD_REASON_MAX_RUNES {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.reason.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *LegalHold) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *LegalHold) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LegalHoldFromJson(data io.Reader) *LegalHold {
+	decoder := json.NewDecoder(data)
+	var o LegalHold
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func LegalHoldListToJson(l []*LegalHold) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LegalHoldListFromJson(data io.Reader) []*LegalHold {
+	decoder := json.NewDecoder(data)
+	var o []*LegalHold
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLegalHoldJson(t *testing.T) {
+	o := LegalHold{Id: NewId(), TargetType: LEGAL_HOLD_TARGET_USER, TargetId: NewId(), Reason: "litigation"}
+	json := o.ToJson()
+	ro := LegalHoldFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.TargetId != ro.TargetId || o.Reason != ro.Reason {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestLegalHoldIsValid(t *testing.T) {
+	o := LegalHold{TargetType: LEGAL_HOLD_TARGET_CHANNEL, TargetId: NewId(), CreatorId: NewId()}
+	o.PreSave()
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TargetType = "team"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with an unknown target type")
+	}
+
+	o.TargetType = LEGAL_HOLD_TARGET_USER
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TargetId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid without a target")
+	}
+
+	o.TargetId = NewId()
+	o.Reason = strings.Repeat("a", LEGAL_HOLD_REASON_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with a long reason")
+	}
+
+	o.Reason = ""
+	o.CreatorId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid without a creator")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net"
+)
+
+const (
+	LISTENER_NETWORK_DUAL_STACK = "tcp"
+	LISTENER_NETWORK_IPV4       = "tcp4"
+	LISTENER_NETWORK_IPV6       = "tcp6"
+)
+
+// Listener is an address that the server accepts connections on in addition to the listen address
+// of the service settings. Each listener has its own connection security so that, for example, an
+// internal interface can be served without TLS while a public IPv6 address is served with it.
+type Listener struct {
+	ListenAddress      string
+	Network            string
+	ConnectionSecurity string
+	TLSCertFile        string
+	TLSKeyFile         string
+}
+
+func (o *Listener) SetDefaults() {
+	if len(o.Network) == 0 {
+		o.Network = LISTENER_NETWORK_DUAL_STACK
+	}
+}
+
+func (o *Listener) IsValid() *AppError {
+	if _, _, err := net.SplitHostPort(o.ListenAddress); err != nil {
+		return NewLocAppError("Listener.IsValid", "model.listener.is_valid.listen_address.app_error", map[string]interface{}{"ListenAddress": o.ListenAddress}, err.Error())
+	}
+
+	if !(o.Network == LISTENER_NETWORK_DUAL_STACK || o.Network == LISTENER_NETWORK_IPV4 || o.Network == LISTENER_NETWORK_IPV6) {
+		return NewLocAppError("Listener.IsValid", "model.listener.is_valid.network.app_error", map[string]interface{}{"ListenAddress": o.ListenAddress}, "network="+o.Network)
+	}
+
+	if !(o.ConnectionSecurity == CONN_SECURITY_NONE || o.ConnectionSecurity == CONN_SECURITY_TLS) {
+		return NewLocAppError("Listener.IsValid", "model.listener.is_valid.connection_security.app_error", map[string]interface{}{"ListenAddress": o.ListenAddress}, "")
+	}
+
+	if o.ConnectionSecurity == CONN_SECURITY_TLS && (len(o.TLSCertFile) == 0 || len(o.TLSKeyFile) == 0) {
+		return NewLocAppError("Listener.IsValid", "model.listener.is_valid.tls_files.app_error", map[string]interface{}{"ListenAddress": o.ListenAddress}, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestListenerIsValid(t *testing.T) {
+	listener := &Listener{ListenAddress: "[::1]:8065"}
+	listener.SetDefaults()
+
+	if listener.Network != LISTENER_NETWORK_DUAL_STACK {
+		t.Fatal("should have defaulted to a dual stack network")
+	}
+
+	if err := listener.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	listener.Network = LISTENER_NETWORK_IPV4
+	listener.ListenAddress = "127.0.0.1:8065"
+	if err := listener.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	listener.ListenAddress = "127.0.0.1"
+	if err := listener.IsValid(); err == nil {
+		t.Fatal("should be invalid without a port")
+	}
+
+	listener.ListenAddress = ":8065"
+	listener.Network = "udp"
+	if err := listener.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad network")
+	}
+
+	listener.Network = LISTENER_NETWORK_IPV6
+	listener.ConnectionSecurity = CONN_SECURITY_STARTTLS
+	if err := listener.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad connection security")
+	}
+
+	listener.ConnectionSecurity = CONN_SECURITY_TLS
+	if err := listener.IsValid(); err == nil {
+		t.Fatal("should be invalid with tls and without certificate files")
+	}
+
+	listener.TLSCertFile = "cert.pem"
+	listener.TLSKeyFile = "key.pem"
+	if err := listener.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	MFA_BACKUP_CODE_COUNT  = 10
+	MFA_BACKUP_CODE_LENGTH = 10
+)
+
+// MfaBackupCodes holds the one time codes a user can log in with instead of an MFA token. The codes
+// themselves are only set when they're generated since only their hashes are stored.
+type MfaBackupCodes struct {
+	Codes     []string `json:"codes,omitempty"`
+	Remaining int      `json:"remaining"`
+}
+
+func (me *MfaBackupCodes) ToJson() string {
+	b, err := json.Marshal(me)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func MfaBackupCodesFromJson(data io.Reader) *MfaBackupCodes {
+	decoder := json.NewDecoder(data)
+	var me MfaBackupCodes
+	err := decoder.Decode(&me)
+	if err == nil {
+		return &me
+	} else {
+		return nil
+	}
+}
+
+// NewMfaBackupCodes generates a new set of backup codes and returns them along with the hashes to
+// store for them. The codes are random enough that a fast hash is sufficient.
+func NewMfaBackupCodes() ([]string, string) {
+	codes := make([]string, MFA_BACKUP_CODE_COUNT)
+	hashes := make([]string, MFA_BACKUP_CODE_COUNT)
+	for i := range codes {
+		codes[i] = NewRandomString(MFA_BACKUP_CODE_LENGTH)
+		hashes[i] = HashMfaBackupCode(codes[i])
+	}
+
+	return codes, strings.Join(hashes, " ")
+}
+
+// HashMfaBackupCode hashes a backup code, ignoring case and any spaces or dashes the user typed.
+func HashMfaBackupCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.Replace(code, " ", "", -1)
+	code = strings.Replace(code, "-", "", -1)
+
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// MfaBackupCodeCount returns the number of backup codes the user hasn't used yet.
+func (u *User) MfaBackupCodeCount() int {
+	return len(strings.Fields(u.MfaBackupCodes))
+}
+
+// UseMfaBackupCode checks a code against the user's backup codes and returns the hashes of the codes
+// that are left if it's one of them.
+func (u *User) UseMfaBackupCode(code string) (string, bool) {
+	hash := HashMfaBackupCode(code)
+
+	found := false
+	remaining := []string{}
+	for _, stored := range strings.Fields(u.MfaBackupCodes) {
+		if !found && subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			found = true
+		} else {
+			remaining = append(remaining, stored)
+		}
+	}
+
+	return strings.Join(remaining, " "), found
+}
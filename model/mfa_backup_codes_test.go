@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMfaBackupCodesJson(t *testing.T) {
+	codes := MfaBackupCodes{Codes: []string{NewRandomString(MFA_BACKUP_CODE_LENGTH)}, Remaining: 1}
+	json := codes.ToJson()
+	rcodes := MfaBackupCodesFromJson(strings.NewReader(json))
+
+	if len(rcodes.Codes) != 1 || rcodes.Codes[0] != codes.Codes[0] || rcodes.Remaining != 1 {
+		t.Fatal("codes should be the same")
+	}
+}
+
+func TestUserUseMfaBackupCode(t *testing.T) {
+	codes, hashes := NewMfaBackupCodes()
+	if len(codes) != MFA_BACKUP_CODE_COUNT {
+		t.Fatal("should have generated all the codes", len(codes))
+	}
+
+	for _, code := range codes {
+		if len(code) != MFA_BACKUP_CODE_LENGTH || strings.Contains(hashes, code) {
+			t.Fatal("codes should only be stored hashed", code)
+		}
+	}
+
+	user := User{MfaBackupCodes: hashes}
+	if user.MfaBackupCodeCount() != MFA_BACKUP_CODE_COUNT {
+		t.Fatal("should count every code", user.MfaBackupCodeCount())
+	}
+
+	if _, ok := user.UseMfaBackupCode(NewRandomString(MFA_BACKUP_CODE_LENGTH)); ok {
+		t.Fatal("should not accept an unknown code")
+	}
+
+	code := strings.ToUpper(codes[3][:5] + "-" + codes[3][5:])
+	remaining, ok := user.UseMfaBackupCode(code)
+	if !ok {
+		t.Fatal("should accept a code regardless of case and dashes")
+	}
+
+	user.MfaBackupCodes = remaining
+	if user.MfaBackupCodeCount() != MFA_BACKUP_CODE_COUNT-1 {
+		t.Fatal("should have used up the code", user.MfaBackupCodeCount())
+	}
+
+	if _, ok := user.UseMfaBackupCode(codes[3]); ok {
+		t.Fatal("should not accept a code twice")
+	}
+}
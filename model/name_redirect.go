@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+)
+
+const (
+	NAME_REDIRECT_TYPE_TEAM    = "team"
+	NAME_REDIRECT_TYPE_CHANNEL = "channel"
+
+	// HEADER_REDIRECTED_FROM is set on responses to lookups by name that were resolved from the
+	// name a team or channel had before it was renamed
+	HEADER_REDIRECTED_FROM = "X-Redirected-From"
+)
+
+// NameRedirect records the name that a team or a channel had before it was renamed so that links and
+// lookups using the old name keep finding it for a while. TeamId is only set for channels since
+// channel names are only unique within a team.
+type NameRedirect struct {
+	Id       string `json:"id"`
+	Type     string `json:"type"`
+	TeamId   string `json:"team_id"`
+	OldName  string `json:"old_name"`
+	TargetId string `json:"target_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+func (o *NameRedirect) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	switch o.Type {
+	case NAME_REDIRECT_TYPE_TEAM:
+		if len(o.TeamId) != 0 {
+			return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	case NAME_REDIRECT_TYPE_CHANNEL:
+		if len(o.TeamId) != 26 {
+			return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	default:
+		return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.type.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.OldName) == 0 || len(o.OldName) > CHANNEL_NAME_MAX_LENGTH {
+		return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.old_name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TargetId) != 26 {
+		return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.target_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("NameRedirect.IsValid", "model.name_redirect.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *NameRedirect) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
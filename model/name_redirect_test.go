@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameRedirectIsValid(t *testing.T) {
+	o := NameRedirect{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	o.Type = NAME_REDIRECT_TYPE_TEAM
+	o.OldName = "old-name"
+	o.TargetId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TeamId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("team redirects shouldn't have a team id")
+	}
+
+	o.Type = NAME_REDIRECT_TYPE_CHANNEL
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TeamId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("channel redirects should have a team id")
+	}
+
+	o.TeamId = NewId()
+	o.Type = "user"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with an unknown type")
+	}
+
+	o.Type = NAME_REDIRECT_TYPE_CHANNEL
+	o.OldName = strings.Repeat("a", CHANNEL_NAME_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a name that's too long")
+	}
+
+	o.OldName = "old-name"
+	o.TargetId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad target id")
+	}
+}
@@ -29,6 +29,7 @@ type OAuthApp struct {
 	CallbackUrls StringArray `json:"callback_urls"`
 	Homepage     string      `json:"homepage"`
 	IsTrusted    bool        `json:"is_trusted"`
+	Scopes       string      `json:"scopes"`
 }
 
 // IsValid validates the app and returns an error if it isn't configured
@@ -83,6 +84,10 @@ func (a *OAuthApp) IsValid() *AppError {
 		}
 	}
 
+	if len(a.Scopes) > 0 && !IsValidOAuthScope(a.Scopes) {
+		return NewLocAppError("OAuthApp.IsValid", "model.oauth.is_valid.scopes.app_error", nil, "app_id="+a.Id)
+	}
+
 	return nil
 }
 
@@ -101,6 +106,16 @@ func (a *OAuthApp) PreSave() {
 	a.UpdateAt = a.CreateAt
 }
 
+// GetScopes returns the widest scope users can grant to the app. Apps without scopes may act with
+// the full permissions of the user.
+func (a *OAuthApp) GetScopes() string {
+	if len(a.Scopes) == 0 {
+		return OAUTH_SCOPE_USER
+	}
+
+	return a.Scopes
+}
+
 // PreUpdate should be run before updating the app in the db.
 func (a *OAuthApp) PreUpdate() {
 	a.UpdateAt = GetMillis()
@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+)
+
+// OAuth scopes are space separated as described in RFC 6749. The "user" scope grants the full
+// permissions of the authorizing user, the "read" scope only allows reading and any number of
+// "channel:<channel_id>" scopes limit channel access to those channels. The OpenID Connect scopes
+// "openid", "profile" and "email" add an ID token and user info to a grant. A grant with only
+// those scopes can read the user info, but can't use the rest of the API.
+const (
+	OAUTH_SCOPE_USER           = DEFAULT_SCOPE
+	OAUTH_SCOPE_READ           = "read"
+	OAUTH_SCOPE_CHANNEL_PREFIX = "channel:"
+	OAUTH_SCOPE_OPENID         = "openid"
+	OAUTH_SCOPE_PROFILE        = "profile"
+	OAUTH_SCOPE_EMAIL          = "email"
+	OAUTH_SCOPES_MAX_LENGTH    = 1024
+
+	SESSION_PROP_OAUTH_SCOPE = "oauth_scope"
+)
+
+func isValidOAuthScopeItem(item string) bool {
+	switch item {
+	case OAUTH_SCOPE_USER, OAUTH_SCOPE_READ, OAUTH_SCOPE_OPENID, OAUTH_SCOPE_PROFILE, OAUTH_SCOPE_EMAIL:
+		return true
+	}
+
+	return strings.HasPrefix(item, OAUTH_SCOPE_CHANNEL_PREFIX) && len(strings.TrimPrefix(item, OAUTH_SCOPE_CHANNEL_PREFIX)) == 26
+}
+
+// IsValidOAuthScope checks that every scope in the space separated list is known and that the list
+// grants either full access, read access or OpenID Connect user info.
+func IsValidOAuthScope(scope string) bool {
+	if len(scope) > OAUTH_SCOPES_MAX_LENGTH {
+		return false
+	}
+
+	items := strings.Fields(scope)
+	if len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if !isValidOAuthScopeItem(item) {
+			return false
+		}
+	}
+
+	return OAuthScopeAllowsRead(scope) || OAuthScopeIsOpenId(scope)
+}
+
+func oauthScopeHas(scope string, item string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OAuthScopeIsOpenId returns whether a grant should come with an ID token.
+func OAuthScopeIsOpenId(scope string) bool {
+	return oauthScopeHas(scope, OAUTH_SCOPE_OPENID)
+}
+
+func OAuthScopeAllowsWrite(scope string) bool {
+	return oauthScopeHas(scope, OAUTH_SCOPE_USER)
+}
+
+func OAuthScopeAllowsRead(scope string) bool {
+	return oauthScopeHas(scope, OAUTH_SCOPE_USER) || oauthScopeHas(scope, OAUTH_SCOPE_READ)
+}
+
+// OAuthScopeChannelIds returns the channels a scope is restricted to. An empty result means that
+// the scope is not restricted to any channel.
+func OAuthScopeChannelIds(scope string) []string {
+	channelIds := []string{}
+
+	for _, item := range strings.Fields(scope) {
+		if strings.HasPrefix(item, OAUTH_SCOPE_CHANNEL_PREFIX) {
+			channelIds = append(channelIds, strings.TrimPrefix(item, OAUTH_SCOPE_CHANNEL_PREFIX))
+		}
+	}
+
+	return channelIds
+}
+
+// OAuthScopeAllowsChannel returns whether a scope gives access to the given channel.
+func OAuthScopeAllowsChannel(scope string, channelId string) bool {
+	channelIds := OAuthScopeChannelIds(scope)
+	if len(channelIds) == 0 {
+		return true
+	}
+
+	for _, id := range channelIds {
+		if id == channelId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOAuthScopeWithin returns whether the requested scope grants no more than the allowed scope.
+func IsOAuthScopeWithin(requested string, allowed string) bool {
+	if OAuthScopeAllowsWrite(requested) && !OAuthScopeAllowsWrite(allowed) {
+		return false
+	}
+
+	if OAuthScopeAllowsRead(requested) && !OAuthScopeAllowsRead(allowed) {
+		return false
+	}
+
+	// Channel restrictions don't matter to a grant that can't access the API
+	if !OAuthScopeAllowsRead(requested) {
+		return true
+	}
+
+	allowedChannelIds := OAuthScopeChannelIds(allowed)
+	if len(allowedChannelIds) == 0 {
+		return true
+	}
+
+	requestedChannelIds := OAuthScopeChannelIds(requested)
+	if len(requestedChannelIds) == 0 {
+		return false
+	}
+
+	for _, channelId := range requestedChannelIds {
+		if !OAuthScopeAllowsChannel(allowed, channelId) {
+			return false
+		}
+	}
+
+	return true
+}
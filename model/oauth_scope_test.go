@@ -0,0 +1,98 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidOAuthScope(t *testing.T) {
+	channelId := NewId()
+
+	for _, scope := range []string{"user", "read", "user read", "read channel:" + channelId, " user  channel:" + channelId + " ", "openid profile email read", "openid profile email", "openid"} {
+		if !IsValidOAuthScope(scope) {
+			t.Fatal("should be valid: " + scope)
+		}
+	}
+
+	for _, scope := range []string{"", "admin", "profile email", "channel:" + channelId, "read channel:junk", "read " + strings.Repeat("channel:"+channelId+" ", 40)} {
+		if IsValidOAuthScope(scope) {
+			t.Fatal("should be invalid: " + scope)
+		}
+	}
+}
+
+func TestOAuthScopeAccess(t *testing.T) {
+	channelId := NewId()
+
+	if !OAuthScopeAllowsWrite("user") || OAuthScopeAllowsWrite("read") {
+		t.Fatal("only the user scope should allow writing")
+	}
+
+	if !OAuthScopeAllowsRead("user") || !OAuthScopeAllowsRead("read") {
+		t.Fatal("both scopes should allow reading")
+	}
+
+	if !OAuthScopeAllowsChannel("read", NewId()) {
+		t.Fatal("scopes without channels should allow every channel")
+	}
+
+	scope := "read channel:" + channelId
+	if !OAuthScopeAllowsChannel(scope, channelId) || OAuthScopeAllowsChannel(scope, NewId()) {
+		t.Fatal("should only allow the listed channel")
+	}
+}
+
+func TestIsOAuthScopeWithin(t *testing.T) {
+	channelId := NewId()
+
+	if !IsOAuthScopeWithin("read", "user") {
+		t.Fatal("read is narrower than user")
+	}
+
+	if IsOAuthScopeWithin("user", "read") {
+		t.Fatal("user is wider than read")
+	}
+
+	if !IsOAuthScopeWithin("read channel:"+channelId, "read") {
+		t.Fatal("restricting to a channel is narrower")
+	}
+
+	if IsOAuthScopeWithin("read", "read channel:"+channelId) {
+		t.Fatal("dropping the channel restriction is wider")
+	}
+
+	if IsOAuthScopeWithin("read channel:"+NewId(), "read channel:"+channelId) {
+		t.Fatal("another channel is not allowed")
+	}
+
+	if !IsOAuthScopeWithin("openid profile email", "read channel:"+channelId) {
+		t.Fatal("user info is narrower than any channel")
+	}
+}
+
+func TestSessionOAuthScope(t *testing.T) {
+	s := Session{}
+	if s.IsOAuthReadOnly() || s.IsOAuthScopeRestricted() || !s.IsChannelAllowedByOAuthScope(NewId()) {
+		t.Fatal("regular sessions should have full access")
+	}
+
+	channelId := NewId()
+	s.IsOAuth = true
+	s.AddProp(SESSION_PROP_OAUTH_SCOPE, "read channel:"+channelId)
+	if !s.IsOAuthReadOnly() || !s.IsChannelAllowedByOAuthScope(channelId) || s.IsChannelAllowedByOAuthScope(NewId()) {
+		t.Fatal("should be restricted by the scope")
+	}
+
+	s.AddProp(SESSION_PROP_OAUTH_SCOPE, "openid profile email")
+	if !s.IsOAuthUserInfoOnly() || !s.IsOAuthScopeRestricted() {
+		t.Fatal("should only give access to the user info")
+	}
+
+	s.AddProp(SESSION_PROP_OAUTH_SCOPE, "user channel:"+channelId)
+	if s.IsOAuthReadOnly() || !s.IsOAuthScopeRestricted() {
+		t.Fatal("should be restricted to the channel")
+	}
+}
@@ -100,4 +100,14 @@ func TestOAuthAppIsValid(t *testing.T) {
 	if err := app.IsValid(); err != nil {
 		t.Fatal()
 	}
+
+	app.Scopes = "admin"
+	if err := app.IsValid(); err == nil {
+		t.Fatal()
+	}
+
+	app.Scopes = "read channel:" + NewId()
+	if err := app.IsValid(); err != nil {
+		t.Fatal()
+	}
 }
@@ -0,0 +1,342 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+)
+
+const (
+	USER_AUTH_SERVICE_OPENID = "openid"
+
+	OPENID_TOKEN_EXPIRY     = 60 * 60 // 1 hour
+	OPENID_SIGNING_ALG      = "RS256"
+	OPENID_SIGNING_KEY_BITS = 2048
+	OPENID_NONCE_MAX_LENGTH = 128
+
+	OPENID_CONFIGURATION_PATH = "/.well-known/openid-configuration"
+	OPENID_USERINFO_PATH      = "/oauth/userinfo"
+	OPENID_JWKS_PATH          = "/oauth/jwks"
+)
+
+var jwtEncoding = base64.RawURLEncoding
+
+// OpenIdConfiguration is the provider metadata served for OpenID Connect discovery.
+type OpenIdConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksUri                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// OpenIdClaims are the claims about a user that are returned by the userinfo endpoint and included
+// in ID tokens. Only the claims allowed by the granted scopes are filled in.
+type OpenIdClaims struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	Nickname          string `json:"nickname,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// OpenIdToken is the payload of an ID token.
+type OpenIdToken struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+	IssuedAt  int64  `json:"iat"`
+	Nonce     string `json:"nonce,omitempty"`
+	OpenIdClaims
+}
+
+// JsonWebKey is the public half of a key that ID tokens are signed with, as described in RFC 7517.
+type JsonWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type JsonWebKeySet struct {
+	Keys []JsonWebKey `json:"keys"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyId     string `json:"kid"`
+}
+
+// NewOpenIdConfiguration describes the OpenID Connect provider at the given issuer, which is the
+// site URL of the server.
+func NewOpenIdConfiguration(issuer string) *OpenIdConfiguration {
+	return &OpenIdConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/access_token",
+		UserInfoEndpoint:                  issuer + OPENID_USERINFO_PATH,
+		JwksUri:                           issuer + OPENID_JWKS_PATH,
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		ScopesSupported:                   []string{OAUTH_SCOPE_OPENID, OAUTH_SCOPE_PROFILE, OAUTH_SCOPE_EMAIL, OAUTH_SCOPE_USER, OAUTH_SCOPE_READ},
+		ResponseTypesSupported:            []string{AUTHCODE_RESPONSE_TYPE},
+		GrantTypesSupported:               []string{ACCESS_TOKEN_GRANT_TYPE, REFRESH_TOKEN_GRANT_TYPE},
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  []string{OPENID_SIGNING_ALG},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{PKCE_CHALLENGE_METHOD_S256},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce",
+			"name", "given_name", "family_name", "nickname", "preferred_username", "locale", "updated_at",
+			"email", "email_verified",
+		},
+	}
+}
+
+// NewOpenIdClaims returns the claims about a user that the scope gives access to.
+func NewOpenIdClaims(user *User, scope string) *OpenIdClaims {
+	claims := &OpenIdClaims{Subject: user.Id}
+
+	if oauthScopeHas(scope, OAUTH_SCOPE_PROFILE) {
+		claims.Name = user.GetFullName()
+		claims.GivenName = user.FirstName
+		claims.FamilyName = user.LastName
+		claims.Nickname = user.Nickname
+		claims.PreferredUsername = user.Username
+		claims.Locale = user.Locale
+		claims.UpdatedAt = user.UpdateAt / 1000
+	}
+
+	if oauthScopeHas(scope, OAUTH_SCOPE_EMAIL) {
+		emailVerified := user.EmailVerified
+		claims.Email = user.Email
+		claims.EmailVerified = &emailVerified
+	}
+
+	return claims
+}
+
+// NewOpenIdToken returns the payload of an ID token issued to a client for a user.
+func NewOpenIdToken(issuer, clientId string, user *User, scope, nonce string) *OpenIdToken {
+	now := GetMillis() / 1000
+
+	return &OpenIdToken{
+		Issuer:       issuer,
+		Audience:     clientId,
+		IssuedAt:     now,
+		ExpiresAt:    now + OPENID_TOKEN_EXPIRY,
+		Nonce:        nonce,
+		OpenIdClaims: *NewOpenIdClaims(user, scope),
+	}
+}
+
+// NewOpenIdSigningKey generates a key for signing ID tokens.
+func NewOpenIdSigningKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, OPENID_SIGNING_KEY_BITS)
+}
+
+// OpenIdSigningKeyToString encodes a signing key so that it can be stored.
+func OpenIdSigningKeyToString(key *rsa.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PrivateKey(key))
+}
+
+func OpenIdSigningKeyFromString(data string) (*rsa.PrivateKey, error) {
+	der, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParsePKCS1PrivateKey(der)
+}
+
+// OpenIdKeyId identifies a signing key by a hash of its public key so that clients can tell when it
+// changes.
+func OpenIdKeyId(key *rsa.PublicKey) string {
+	hash := sha256.Sum256(x509.MarshalPKCS1PublicKey(key))
+	return jwtEncoding.EncodeToString(hash[:12])
+}
+
+func NewJsonWebKey(key *rsa.PublicKey) JsonWebKey {
+	return JsonWebKey{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: OPENID_SIGNING_ALG,
+		KeyId:     OpenIdKeyId(key),
+		Modulus:   jwtEncoding.EncodeToString(key.N.Bytes()),
+		Exponent:  jwtEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// SignOpenIdToken encodes an ID token as a JSON Web Token signed with RS256.
+func SignOpenIdToken(token *OpenIdToken, key *rsa.PrivateKey) (string, error) {
+	header, err := json.Marshal(jwtHeader{Algorithm: OPENID_SIGNING_ALG, Type: "JWT", KeyId: OpenIdKeyId(&key.PublicKey)})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	signed := jwtEncoding.EncodeToString(header) + "." + jwtEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + jwtEncoding.EncodeToString(signature), nil
+}
+
+// VerifyOpenIdToken checks the signature of an ID token against one of the keys in a key set and
+// returns its payload. The claims in the payload still need to be checked by the caller.
+func VerifyOpenIdToken(idToken string, keys *JsonWebKeySet) (*OpenIdToken, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	rawHeader, err := jwtEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Algorithm != OPENID_SIGNING_ALG {
+		return nil, errors.New("unsupported algorithm " + header.Algorithm)
+	}
+
+	signature, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, jwk := range keys.Keys {
+		if jwk.KeyId != header.KeyId {
+			continue
+		}
+
+		n, err := jwtEncoding.DecodeString(jwk.Modulus)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := jwtEncoding.DecodeString(jwk.Exponent)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], signature); err != nil {
+			return nil, err
+		}
+
+		payload, err := jwtEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		var token OpenIdToken
+		if err := json.Unmarshal(payload, &token); err != nil {
+			return nil, err
+		}
+
+		return &token, nil
+	}
+
+	return nil, errors.New("unknown key " + header.KeyId)
+}
+
+func (o *OpenIdConfiguration) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OpenIdConfigurationFromJson(data io.Reader) *OpenIdConfiguration {
+	decoder := json.NewDecoder(data)
+	var o OpenIdConfiguration
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *OpenIdClaims) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OpenIdClaimsFromJson(data io.Reader) *OpenIdClaims {
+	decoder := json.NewDecoder(data)
+	var o OpenIdClaims
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *JsonWebKeySet) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func JsonWebKeySetFromJson(data io.Reader) *JsonWebKeySet {
+	decoder := json.NewDecoder(data)
+	var o JsonWebKeySet
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type OpenIdProvider struct {
+}
+
+// OpenIdUser holds the claims about a user returned by the provider's userinfo endpoint. Which claims
+// are used for the username, email and name comes from the OpenIdSettings.
+type OpenIdUser map[string]interface{}
+
+func init() {
+	provider := &OpenIdProvider{}
+	einterfaces.RegisterOauthProvider(model.USER_AUTH_SERVICE_OPENID, provider)
+}
+
+func userFromOpenIdUser(oiu OpenIdUser) *model.User {
+	user := &model.User{}
+
+	user.Email = strings.TrimSpace(oiu.getClaim(*utils.Cfg.OpenIdSettings.EmailClaim))
+
+	username := oiu.getClaim(*utils.Cfg.OpenIdSettings.UsernameClaim)
+	if username == "" {
+		username = strings.Split(user.Email, "@")[0]
+	}
+	user.Username = model.CleanUsername(username)
+
+	user.FirstName = oiu.getClaim(*utils.Cfg.OpenIdSettings.FirstNameClaim)
+	user.LastName = oiu.getClaim(*utils.Cfg.OpenIdSettings.LastNameClaim)
+
+	authData := oiu.getAuthData()
+	user.AuthData = &authData
+	user.AuthService = model.USER_AUTH_SERVICE_OPENID
+
+	return user
+}
+
+func openIdUserFromJson(data io.Reader) OpenIdUser {
+	decoder := json.NewDecoder(data)
+	var oiu OpenIdUser
+	err := decoder.Decode(&oiu)
+	if err == nil {
+		return oiu
+	} else {
+		return nil
+	}
+}
+
+// getClaim returns a claim as a string, or an empty string if the user doesn't have it.
+func (oiu OpenIdUser) getClaim(name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+
+	switch value := oiu[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (oiu OpenIdUser) IsValid() bool {
+	if oiu == nil {
+		return false
+	}
+
+	if len(oiu.getAuthData()) == 0 {
+		return false
+	}
+
+	if len(oiu.getClaim(*utils.Cfg.OpenIdSettings.EmailClaim)) == 0 {
+		return false
+	}
+
+	// An address that the provider hasn't verified could belong to someone else
+	if verified, ok := oiu["email_verified"].(bool); ok && !verified {
+		return false
+	}
+
+	return true
+}
+
+func (oiu OpenIdUser) getAuthData() string {
+	return oiu.getClaim("sub")
+}
+
+func (m *OpenIdProvider) GetIdentifier() string {
+	return model.USER_AUTH_SERVICE_OPENID
+}
+
+func (m *OpenIdProvider) GetUserFromJson(data io.Reader) *model.User {
+	oiu := openIdUserFromJson(data)
+	if oiu.IsValid() {
+		return userFromOpenIdUser(oiu)
+	}
+
+	return &model.User{}
+}
+
+func (m *OpenIdProvider) GetAuthDataFromJson(data io.Reader) string {
+	oiu := openIdUserFromJson(data)
+
+	if oiu.IsValid() {
+		return oiu.getAuthData()
+	}
+
+	return ""
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestOpenIdProvider(t *testing.T) {
+	utils.Cfg.SetDefaults()
+
+	provider := &OpenIdProvider{}
+
+	user := provider.GetUserFromJson(strings.NewReader(`{"sub": "1234", "preferred_username": "Jo Smith", "email": "jo@example.com", "email_verified": true, "given_name": "Jo", "family_name": "Smith"}`))
+	if user.AuthService != model.USER_AUTH_SERVICE_OPENID || user.AuthData == nil || *user.AuthData != "1234" {
+		t.Fatal("should have set the auth data from the subject")
+	} else if user.Username != model.CleanUsername("Jo Smith") || user.Email != "jo@example.com" || user.FirstName != "Jo" || user.LastName != "Smith" {
+		t.Fatal("should have mapped the default claims")
+	}
+
+	if authData := provider.GetAuthDataFromJson(strings.NewReader(`{"sub": "1234", "email": "jo@example.com"}`)); authData != "1234" {
+		t.Fatal("should have returned the subject as the auth data")
+	}
+
+	if user := provider.GetUserFromJson(strings.NewReader(`{"sub": "1234", "email": "jo@example.com"}`)); user.Username != "jo" {
+		t.Fatal("should have taken the username from the email without a username claim")
+	}
+
+	if user := provider.GetUserFromJson(strings.NewReader(`{"sub": "1234", "email": "jo@example.com", "email_verified": false}`)); user.AuthData != nil {
+		t.Fatal("shouldn't have accepted an unverified email")
+	}
+
+	if user := provider.GetUserFromJson(strings.NewReader(`{"email": "jo@example.com"}`)); user.AuthData != nil {
+		t.Fatal("shouldn't have accepted a user without a subject")
+	}
+
+	usernameClaim := *utils.Cfg.OpenIdSettings.UsernameClaim
+	emailClaim := *utils.Cfg.OpenIdSettings.EmailClaim
+	defer func() {
+		*utils.Cfg.OpenIdSettings.UsernameClaim = usernameClaim
+		*utils.Cfg.OpenIdSettings.EmailClaim = emailClaim
+	}()
+	*utils.Cfg.OpenIdSettings.UsernameClaim = "uid"
+	*utils.Cfg.OpenIdSettings.EmailClaim = "mail"
+
+	if user := provider.GetUserFromJson(strings.NewReader(`{"sub": "1234", "uid": "jsmith", "mail": "jsmith@example.com", "email": "jo@example.com"}`)); user.Username != "jsmith" || user.Email != "jsmith@example.com" {
+		t.Fatal("should have used the configured claims")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewOpenIdClaims(t *testing.T) {
+	user := &User{Id: NewId(), Username: "username", FirstName: "First", LastName: "Last", Email: "test@example.com", EmailVerified: true}
+
+	if claims := NewOpenIdClaims(user, "openid read"); claims.Subject != user.Id || claims.PreferredUsername != "" || claims.Email != "" || claims.EmailVerified != nil {
+		t.Fatal("should only have the subject without the profile or email scopes", claims)
+	}
+
+	if claims := NewOpenIdClaims(user, "openid profile read"); claims.Name != "First Last" || claims.PreferredUsername != "username" || claims.Email != "" {
+		t.Fatal("should have the profile claims", claims)
+	}
+
+	if claims := NewOpenIdClaims(user, "openid email read"); claims.Name != "" || claims.Email != user.Email || claims.EmailVerified == nil || !*claims.EmailVerified {
+		t.Fatal("should have the email claims", claims)
+	}
+}
+
+func TestSignOpenIdToken(t *testing.T) {
+	key, err := NewOpenIdSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded, err := OpenIdSigningKeyFromString(OpenIdSigningKeyToString(key)); err != nil {
+		t.Fatal(err)
+	} else if decoded.N.Cmp(key.N) != 0 {
+		t.Fatal("should have decoded the same key")
+	}
+
+	user := &User{Id: NewId(), Email: "test@example.com"}
+	token := NewOpenIdToken("https://chat.example.com", NewId(), user, "openid email read", "nonce")
+
+	idToken, err := SignOpenIdToken(token, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := &JsonWebKeySet{Keys: []JsonWebKey{NewJsonWebKey(&key.PublicKey)}}
+	keys = JsonWebKeySetFromJson(strings.NewReader(keys.ToJson()))
+
+	if verified, err := VerifyOpenIdToken(idToken, keys); err != nil {
+		t.Fatal(err)
+	} else if verified.Subject != user.Id || verified.Audience != token.Audience || verified.Nonce != "nonce" || verified.Email != user.Email || verified.ExpiresAt != verified.IssuedAt+OPENID_TOKEN_EXPIRY {
+		t.Fatal("should have returned the claims", verified)
+	}
+
+	parts := strings.Split(idToken, ".")
+	tampered := parts[0] + "." + jwtEncoding.EncodeToString([]byte(`{"sub":"someone else"}`)) + "." + parts[2]
+	if _, err := VerifyOpenIdToken(tampered, keys); err == nil {
+		t.Fatal("should have failed for a tampered token")
+	}
+
+	other, _ := NewOpenIdSigningKey()
+	if _, err := VerifyOpenIdToken(idToken, &JsonWebKeySet{Keys: []JsonWebKey{NewJsonWebKey(&other.PublicKey)}}); err == nil {
+		t.Fatal("should have failed for another key")
+	}
+}
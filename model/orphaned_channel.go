@@ -0,0 +1,44 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// OrphanedChannel is a public or private channel that has no active channel admins left, so only
+// team and system admins can manage it. CreatorId is the user that created the channel, who may no
+// longer be a member of it.
+type OrphanedChannel struct {
+	Id          string `json:"id"`
+	TeamId      string `json:"team_id"`
+	TeamName    string `json:"team_name"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	CreatorId   string `json:"creator_id"`
+	CreateAt    int64  `json:"create_at"`
+	MemberCount int64  `json:"member_count"`
+}
+
+func OrphanedChannelListToJson(list []*OrphanedChannel) string {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OrphanedChannelListFromJson(data io.Reader) []*OrphanedChannel {
+	decoder := json.NewDecoder(data)
+
+	var list []*OrphanedChannel
+	if err := decoder.Decode(&list); err != nil {
+		return nil
+	} else {
+		return list
+	}
+}
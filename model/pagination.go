@@ -0,0 +1,72 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	HEADER_TOTAL_COUNT = "X-Total-Count"
+	HEADER_HAS_MORE    = "X-Has-More"
+	HEADER_NEXT_CURSOR = "X-Next-Cursor"
+
+	PAGINATION_TOTAL_COUNT_UNKNOWN = -1
+)
+
+// Pagination describes where a page of results returned by a list endpoint sits in the full list.
+// It's sent as response headers so that the body of the response stays the same for clients that
+// don't ask for it.
+type Pagination struct {
+	// TotalCount is the number of items in the full list, which may be approximate for lists that
+	// are expensive to count, or PAGINATION_TOTAL_COUNT_UNKNOWN if it couldn't be counted.
+	TotalCount int64 `json:"total_count"`
+	HasMore    bool  `json:"has_more"`
+	// NextCursor is what to request to get the next page, which is the next page number unless the
+	// endpoint documents otherwise. It's empty when there are no more pages.
+	NextCursor string `json:"next_cursor"`
+}
+
+// NewPagination works out whether there are more pages after a page of count results. Without a
+// total count, a full page is assumed to have more after it.
+func NewPagination(page int, perPage int, count int, totalCount int64) *Pagination {
+	p := &Pagination{TotalCount: totalCount}
+
+	if totalCount == PAGINATION_TOTAL_COUNT_UNKNOWN {
+		p.HasMore = perPage > 0 && count >= perPage
+	} else {
+		p.HasMore = int64(page*perPage+count) < totalCount
+	}
+
+	if p.HasMore {
+		p.NextCursor = strconv.Itoa(page + 1)
+	}
+
+	return p
+}
+
+func (p *Pagination) SetHeaders(header http.Header) {
+	header.Set(HEADER_TOTAL_COUNT, strconv.FormatInt(p.TotalCount, 10))
+	header.Set(HEADER_HAS_MORE, strconv.FormatBool(p.HasMore))
+	header.Set(HEADER_NEXT_CURSOR, p.NextCursor)
+}
+
+// PaginationFromHeaders returns the pagination sent with a response, or nil if there wasn't any.
+func PaginationFromHeaders(header http.Header) *Pagination {
+	if len(header.Get(HEADER_HAS_MORE)) == 0 {
+		return nil
+	}
+
+	p := &Pagination{TotalCount: PAGINATION_TOTAL_COUNT_UNKNOWN}
+
+	if totalCount, err := strconv.ParseInt(header.Get(HEADER_TOTAL_COUNT), 10, 64); err == nil {
+		p.TotalCount = totalCount
+	}
+
+	p.HasMore, _ = strconv.ParseBool(header.Get(HEADER_HAS_MORE))
+	p.NextCursor = header.Get(HEADER_NEXT_CURSOR)
+
+	return p
+}
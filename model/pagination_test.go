@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewPagination(t *testing.T) {
+	if p := NewPagination(0, 10, 10, 25); !p.HasMore || p.NextCursor != "1" {
+		t.Fatal("should have more pages", p)
+	}
+
+	if p := NewPagination(2, 10, 5, 25); p.HasMore || p.NextCursor != "" {
+		t.Fatal("should be the last page", p)
+	}
+
+	if p := NewPagination(1, 10, 10, PAGINATION_TOTAL_COUNT_UNKNOWN); !p.HasMore || p.NextCursor != "2" {
+		t.Fatal("should assume a full page has more after it", p)
+	}
+
+	if p := NewPagination(1, 10, 3, PAGINATION_TOTAL_COUNT_UNKNOWN); p.HasMore {
+		t.Fatal("shouldn't have more after a partial page", p)
+	}
+}
+
+func TestPaginationHeaders(t *testing.T) {
+	if PaginationFromHeaders(http.Header{}) != nil {
+		t.Fatal("shouldn't have pagination without the headers")
+	}
+
+	header := http.Header{}
+	NewPagination(0, 10, 10, 25).SetHeaders(header)
+
+	if p := PaginationFromHeaders(header); p == nil || p.TotalCount != 25 || !p.HasMore || p.NextCursor != "1" {
+		t.Fatal("should have read the pagination from the headers", p)
+	}
+
+	header = http.Header{}
+	NewPagination(0, 10, 3, PAGINATION_TOTAL_COUNT_UNKNOWN).SetHeaders(header)
+
+	if p := PaginationFromHeaders(header); p == nil || p.TotalCount != PAGINATION_TOTAL_COUNT_UNKNOWN || p.HasMore || p.NextCursor != "" {
+		t.Fatal("should have read the pagination from the headers", p)
+	}
+}
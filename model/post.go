@@ -24,7 +24,9 @@ const (
 	POST_DISPLAYNAME_CHANGE    = "system_displayname_change"
 	POST_PURPOSE_CHANGE        = "system_purpose_change"
 	POST_CHANNEL_DELETED       = "system_channel_deleted"
+	POST_CHANNEL_CONVERTED     = "system_channel_converted"
 	POST_EPHEMERAL             = "system_ephemeral"
+	POST_AUTO_RESPONDER        = "system_auto_responder"
 	POST_FILEIDS_MAX_RUNES     = 150
 	POST_FILENAMES_MAX_RUNES   = 4000
 	POST_HASHTAGS_MAX_RUNES    = 1000
@@ -137,7 +139,8 @@ func (o *Post) IsValid() *AppError {
 		o.Type == POST_JOIN_CHANNEL || o.Type == POST_LEAVE_CHANNEL ||
 		o.Type == POST_REMOVE_FROM_CHANNEL || o.Type == POST_ADD_TO_CHANNEL ||
 		o.Type == POST_SLACK_ATTACHMENT || o.Type == POST_HEADER_CHANGE || o.Type == POST_PURPOSE_CHANGE ||
-		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED) {
+		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED || o.Type == POST_AUTO_RESPONDER ||
+		o.Type == POST_CHANNEL_CONVERTED) {
 		return NewLocAppError("Post.IsValid", "model.post.is_valid.type.app_error", nil, "id="+o.Type)
 	}
 
@@ -153,6 +156,12 @@ func (o *Post) IsValid() *AppError {
 		return NewLocAppError("Post.IsValid", "model.post.is_valid.props.app_error", nil, "id="+o.Id)
 	}
 
+	if _, ok := o.Props[POST_PROP_FROM_INTEGRATION]; ok {
+		if metadata := o.GetIntegrationMetadata(); metadata == nil || metadata.IsValid() != nil {
+			return NewLocAppError("Post.IsValid", "model.post.is_valid.from_integration.app_error", nil, "id="+o.Id)
+		}
+	}
+
 	return nil
 }
 
@@ -195,6 +204,20 @@ func (o *Post) AddProp(key string, value interface{}) {
 	o.Props[key] = value
 }
 
+// Attachments returns the Slack-style attachments stored in the post's props, whether they
+// were added in memory or decoded generically after being read back from the database.
+func (o *Post) Attachments() []*SlackAttachment {
+	if attachments, ok := o.Props["attachments"].([]*SlackAttachment); ok {
+		return attachments
+	}
+
+	var attachments []*SlackAttachment
+	if b, err := json.Marshal(o.Props["attachments"]); err == nil {
+		json.Unmarshal(b, &attachments)
+	}
+	return attachments
+}
+
 func (o *Post) IsSystemMessage() bool {
 	return len(o.Type) >= len(POST_SYSTEM_MESSAGE_PREFIX) && o.Type[:len(POST_SYSTEM_MESSAGE_PREFIX)] == POST_SYSTEM_MESSAGE_PREFIX
 }
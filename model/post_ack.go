@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ChannelMemberRead records the most recent post of a channel that a member has seen. It is only
+// kept when read receipts are enabled and is used to tell the author of a post who has read it.
+type ChannelMemberRead struct {
+	ChannelId  string `json:"channel_id"`
+	UserId     string `json:"user_id"`
+	LastPostId string `json:"last_post_id"`
+	LastReadAt int64  `json:"last_read_at"`
+}
+
+// PostAck is a read receipt, showing that a user had seen a post by AckAt.
+type PostAck struct {
+	PostId string `json:"post_id"`
+	UserId string `json:"user_id"`
+	AckAt  int64  `json:"ack_at"`
+}
+
+func (o *ChannelMemberRead) IsValid() *AppError {
+	if len(o.ChannelId) != 26 {
+		return NewAppError("ChannelMemberRead.IsValid", "model.channel_member_read.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ChannelMemberRead.IsValid", "model.channel_member_read.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.LastPostId) != 26 {
+		return NewAppError("ChannelMemberRead.IsValid", "model.channel_member_read.is_valid.last_post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.LastReadAt == 0 {
+		return NewAppError("ChannelMemberRead.IsValid", "model.channel_member_read.is_valid.last_read_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ChannelMemberRead) PreSave() {
+	if o.LastReadAt == 0 {
+		o.LastReadAt = GetMillis()
+	}
+}
+
+func (o *ChannelMemberRead) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ChannelMemberReadFromJson(data io.Reader) *ChannelMemberRead {
+	decoder := json.NewDecoder(data)
+	var o ChannelMemberRead
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PostAckListToJson(l []*PostAck) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostAckListFromJson(data io.Reader) []*PostAck {
+	decoder := json.NewDecoder(data)
+	var o []*PostAck
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
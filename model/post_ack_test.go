@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelMemberReadJson(t *testing.T) {
+	o := ChannelMemberRead{ChannelId: NewId(), UserId: NewId(), LastPostId: NewId(), LastReadAt: GetMillis()}
+	json := o.ToJson()
+	ro := ChannelMemberReadFromJson(strings.NewReader(json))
+
+	if ro == nil || *ro != o {
+		t.Fatal("reads do not match")
+	}
+}
+
+func TestChannelMemberReadIsValid(t *testing.T) {
+	o := ChannelMemberRead{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.LastPostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostAckListJson(t *testing.T) {
+	acks := []*PostAck{{PostId: NewId(), UserId: NewId(), AckAt: GetMillis()}}
+	json := PostAckListToJson(acks)
+	racks := PostAckListFromJson(strings.NewReader(json))
+
+	if len(racks) != 1 || *racks[0] != *acks[0] {
+		t.Fatal("acks do not match")
+	}
+}
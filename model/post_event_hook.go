@@ -0,0 +1,222 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	POST_EVENT_HOOK_EVENT_POSTED       = "posted"
+	POST_EVENT_HOOK_EVENT_POST_EDITED  = "post_edited"
+	POST_EVENT_HOOK_EVENT_POST_DELETED = "post_deleted"
+
+	POST_EVENT_HOOK_MAX_ATTEMPTS = 5
+
+	POST_EVENT_HOOK_DISPLAY_NAME_MAX_RUNES = 64
+	POST_EVENT_HOOK_DESCRIPTION_MAX_RUNES  = 128
+	POST_EVENT_HOOK_CALLBACK_URL_MAX_RUNES = 1024
+
+	HEADER_POST_EVENT_HOOK_EVENT     = "X-Mattermost-Event"
+	HEADER_POST_EVENT_HOOK_DELIVERY  = "X-Mattermost-Delivery"
+	HEADER_POST_EVENT_HOOK_SIGNATURE = "X-Mattermost-Signature"
+)
+
+// PostEventHook subscribes a callback URL to the posts that are created, edited or deleted in a
+// channel, or in every channel of a team when ChannelId is empty. Each delivery is signed with
+// the hook's secret so that the receiver can check that it came from the server.
+type PostEventHook struct {
+	Id          string      `json:"id"`
+	Secret      string      `json:"secret"`
+	CreateAt    int64       `json:"create_at"`
+	UpdateAt    int64       `json:"update_at"`
+	DeleteAt    int64       `json:"delete_at"`
+	CreatorId   string      `json:"creator_id"`
+	TeamId      string      `json:"team_id"`
+	ChannelId   string      `json:"channel_id"`
+	Events      StringArray `json:"events"`
+	CallbackURL string      `json:"callback_url"`
+	DisplayName string      `json:"display_name"`
+	Description string      `json:"description"`
+}
+
+// PostEventHookPayload is the body of a delivery.
+type PostEventHookPayload struct {
+	Event     string `json:"event"`
+	HookId    string `json:"hook_id"`
+	TeamId    string `json:"team_id"`
+	ChannelId string `json:"channel_id"`
+	Timestamp int64  `json:"timestamp"`
+	Post      *Post  `json:"post"`
+}
+
+func IsValidPostEventHookEvent(event string) bool {
+	return event == POST_EVENT_HOOK_EVENT_POSTED || event == POST_EVENT_HOOK_EVENT_POST_EDITED || event == POST_EVENT_HOOK_EVENT_POST_DELETED
+}
+
+func (o *PostEventHook) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.Secret) != 26 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.secret.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 0 && len(o.ChannelId) != 26 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Events) == 0 {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.events.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	for _, event := range o.Events {
+		if !IsValidPostEventHookEvent(event) {
+			return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.events.app_error", nil, "id="+o.Id+", event="+event, http.StatusBadRequest)
+		}
+	}
+
+	if utf8.RuneCountInString(o.CallbackURL) > POST_EVENT_HOOK_CALLBACK_URL_MAX_RUNES || !IsValidHttpUrl(o.CallbackURL) {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.callback_url.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.DisplayName) > POST_EVENT_HOOK_DISPLAY_NAME_MAX_RUNES {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.display_name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Description) > POST_EVENT_HOOK_DESCRIPTION_MAX_RUNES {
+		return NewAppError("PostEventHook.IsValid", "model.post_event_hook.is_valid.description.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// PreSave gives the hook its id and secret. A hook that doesn't list any events receives all of them.
+func (o *PostEventHook) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Secret == "" {
+		o.Secret = NewId()
+	}
+
+	if len(o.Events) == 0 {
+		o.Events = StringArray{POST_EVENT_HOOK_EVENT_POSTED, POST_EVENT_HOOK_EVENT_POST_EDITED, POST_EVENT_HOOK_EVENT_POST_DELETED}
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *PostEventHook) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// WantsEvent returns true if the hook is subscribed to the event.
+func (o *PostEventHook) WantsEvent(event string) bool {
+	for _, e := range o.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignPostEventHookPayload returns the signature sent with a delivery, which is the hex-encoded
+// HMAC-SHA256 of its body keyed with the hook's secret.
+func SignPostEventHookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPostEventHookSignature returns true if the signature of a delivery matches its body.
+func VerifyPostEventHookSignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(SignPostEventHookPayload(secret, body)))
+}
+
+func (o *PostEventHook) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostEventHookFromJson(data io.Reader) *PostEventHook {
+	decoder := json.NewDecoder(data)
+	var o PostEventHook
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PostEventHookListToJson(l []*PostEventHook) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostEventHookListFromJson(data io.Reader) []*PostEventHook {
+	decoder := json.NewDecoder(data)
+	var o []*PostEventHook
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *PostEventHookPayload) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostEventHookPayloadFromJson(data io.Reader) *PostEventHookPayload {
+	decoder := json.NewDecoder(data)
+	var o PostEventHookPayload
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
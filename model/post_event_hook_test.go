@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostEventHookJson(t *testing.T) {
+	o := PostEventHook{Id: NewId(), TeamId: NewId(), Events: StringArray{POST_EVENT_HOOK_EVENT_POSTED}}
+	json := o.ToJson()
+	ro := PostEventHookFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.TeamId != ro.TeamId || len(ro.Events) != 1 {
+		t.Fatal("hooks do not match")
+	}
+
+	list := PostEventHookListFromJson(strings.NewReader(PostEventHookListToJson([]*PostEventHook{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("hook lists do not match")
+	}
+}
+
+func TestPostEventHookIsValid(t *testing.T) {
+	o := PostEventHook{CreatorId: NewId(), TeamId: NewId(), CallbackURL: "http://nowhere.com"}
+	o.PreSave()
+
+	if len(o.Secret) != 26 || len(o.Events) != 3 {
+		t.Fatal("should have set the secret and subscribed to every event")
+	}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ChannelId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	o.Events = StringArray{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Events = StringArray{POST_EVENT_HOOK_EVENT_POST_DELETED}
+	o.CallbackURL = "nowhere.com"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CallbackURL = "https://nowhere.com/hook"
+	o.DisplayName = strings.Repeat("x", POST_EVENT_HOOK_DISPLAY_NAME_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.DisplayName = "Archiver"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.WantsEvent(POST_EVENT_HOOK_EVENT_POSTED) || !o.WantsEvent(POST_EVENT_HOOK_EVENT_POST_DELETED) {
+		t.Fatal("should only want the events it's subscribed to")
+	}
+}
+
+func TestPostEventHookSignature(t *testing.T) {
+	secret := NewId()
+	body := []byte((&PostEventHookPayload{Event: POST_EVENT_HOOK_EVENT_POSTED, Post: &Post{Id: NewId()}}).ToJson())
+
+	signature := SignPostEventHookPayload(secret, body)
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Fatal("should have named the algorithm", signature)
+	}
+
+	if !VerifyPostEventHookSignature(secret, body, signature) {
+		t.Fatal("should have verified the signature")
+	}
+
+	if VerifyPostEventHookSignature(NewId(), body, signature) {
+		t.Fatal("shouldn't have verified the signature with another secret")
+	}
+
+	if VerifyPostEventHookSignature(secret, append(body, ' '), signature) {
+		t.Fatal("shouldn't have verified the signature of another body")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// PostHistory is a previous revision of a post, saved when the post's message is edited.
+// CreateAt is when the revision was written and ReplacedAt is when it was edited away.
+type PostHistory struct {
+	Id         string          `json:"id"`
+	PostId     string          `json:"post_id"`
+	UserId     string          `json:"user_id"`
+	ChannelId  string          `json:"channel_id"`
+	RootId     string          `json:"root_id"`
+	Message    string          `json:"message"`
+	Props      StringInterface `json:"props"`
+	Hashtags   string          `json:"hashtags"`
+	FileIds    StringArray     `json:"file_ids,omitempty"`
+	CreateAt   int64           `json:"create_at"`
+	ReplacedAt int64           `json:"replaced_at"`
+}
+
+// NewPostHistory creates the revision for the contents of a post before an edit made at replacedAt.
+func NewPostHistory(post *Post, replacedAt int64) *PostHistory {
+	createAt := post.EditAt
+	if createAt == 0 {
+		createAt = post.CreateAt
+	}
+
+	return &PostHistory{
+		Id:         NewId(),
+		PostId:     post.Id,
+		UserId:     post.UserId,
+		ChannelId:  post.ChannelId,
+		RootId:     post.RootId,
+		Message:    post.Message,
+		Props:      post.Props,
+		Hashtags:   post.Hashtags,
+		FileIds:    post.FileIds,
+		CreateAt:   createAt,
+		ReplacedAt: replacedAt,
+	}
+}
+
+func PostHistoryListToJson(l []*PostHistory) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostHistoryListFromJson(data io.Reader) []*PostHistory {
+	decoder := json.NewDecoder(data)
+	var o []*PostHistory
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPostHistory(t *testing.T) {
+	post := &Post{Id: NewId(), UserId: NewId(), ChannelId: NewId(), Message: "original", CreateAt: 1000}
+
+	history := NewPostHistory(post, 2000)
+	if history.PostId != post.Id || history.Message != post.Message || history.CreateAt != 1000 || history.ReplacedAt != 2000 {
+		t.Fatal("revision doesn't match the post")
+	}
+
+	post.EditAt = 1500
+	if history := NewPostHistory(post, 2000); history.CreateAt != 1500 {
+		t.Fatal("revision of an edited post should start at the edit")
+	}
+}
+
+func TestPostHistoryListJson(t *testing.T) {
+	l := []*PostHistory{NewPostHistory(&Post{Id: NewId(), Message: "original"}, GetMillis())}
+
+	rl := PostHistoryListFromJson(strings.NewReader(PostHistoryListToJson(l)))
+	if len(rl) != 1 || rl[0].Id != l[0].Id || rl[0].Message != l[0].Message {
+		t.Fatal("lists do not match")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	POST_INTEGRITY_FAILURE_BROKEN_CHAIN  = "broken_chain"
+	POST_INTEGRITY_FAILURE_MISSING_POST  = "missing_post"
+	POST_INTEGRITY_FAILURE_MODIFIED_POST = "modified_post"
+)
+
+// PostIntegrityRecord is an entry in the append-only chain of the posts of a channel. Its hash covers
+// the post as it was created along with the hash of the previous entry, so that modifying, removing
+// or reordering any post in the channel breaks the chain from that point on.
+type PostIntegrityRecord struct {
+	PostId       string `json:"post_id"`
+	ChannelId    string `json:"channel_id"`
+	Sequence     int64  `json:"sequence"`
+	PreviousHash string `json:"previous_hash"`
+	Hash         string `json:"hash"`
+	CreateAt     int64  `json:"create_at"`
+}
+
+// PostIntegrityFailure describes an entry of a chain that doesn't match the channel's posts.
+type PostIntegrityFailure struct {
+	PostId   string `json:"post_id"`
+	Sequence int64  `json:"sequence"`
+	Reason   string `json:"reason"`
+}
+
+// PostIntegrityVerification is the result of checking the chain of a channel against its posts.
+type PostIntegrityVerification struct {
+	ChannelId   string                  `json:"channel_id"`
+	VerifiedAt  int64                   `json:"verified_at"`
+	RecordCount int64                   `json:"record_count"`
+	Failures    []*PostIntegrityFailure `json:"failures"`
+}
+
+// postIntegrityContent is the part of a post that is covered by its hash. Only fields that can't be
+// changed once the post is created are included, with the message being the one it was created with.
+type postIntegrityContent struct {
+	PreviousHash string `json:"previous_hash"`
+	Id           string `json:"id"`
+	ChannelId    string `json:"channel_id"`
+	UserId       string `json:"user_id"`
+	RootId       string `json:"root_id"`
+	Type         string `json:"type"`
+	CreateAt     int64  `json:"create_at"`
+	Message      string `json:"message"`
+}
+
+// NewPostIntegrityRecord creates the entry for a post that follows previous, which is nil for the
+// first post of a channel.
+func NewPostIntegrityRecord(post *Post, previous *PostIntegrityRecord) *PostIntegrityRecord {
+	record := &PostIntegrityRecord{
+		PostId:    post.Id,
+		ChannelId: post.ChannelId,
+		Sequence:  1,
+	}
+
+	if previous != nil {
+		record.Sequence = previous.Sequence + 1
+		record.PreviousHash = previous.Hash
+	}
+
+	record.Hash = PostIntegrityHash(post, post.Message, record.PreviousHash)
+
+	return record
+}
+
+// PostIntegrityHash hashes a post with the message it was created with and the hash of the entry
+// before it.
+func PostIntegrityHash(post *Post, message string, previousHash string) string {
+	b, _ := json.Marshal(&postIntegrityContent{
+		PreviousHash: previousHash,
+		Id:           post.Id,
+		ChannelId:    post.ChannelId,
+		UserId:       post.UserId,
+		RootId:       post.RootId,
+		Type:         post.Type,
+		CreateAt:     post.CreateAt,
+		Message:      message,
+	})
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (o *PostIntegrityRecord) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewAppError("PostIntegrityRecord.IsValid", "model.post_integrity.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("PostIntegrityRecord.IsValid", "model.post_integrity.is_valid.channel_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.Sequence < 1 {
+		return NewAppError("PostIntegrityRecord.IsValid", "model.post_integrity.is_valid.sequence.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if len(o.Hash) != 64 || (o.Sequence > 1 && len(o.PreviousHash) != 64) {
+		return NewAppError("PostIntegrityRecord.IsValid", "model.post_integrity.is_valid.hash.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PostIntegrityRecord.IsValid", "model.post_integrity.is_valid.create_at.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PostIntegrityRecord) PreSave() {
+	o.CreateAt = GetMillis()
+}
+
+func (o *PostIntegrityVerification) IsValid() bool {
+	return len(o.Failures) == 0
+}
+
+func (o *PostIntegrityVerification) AddFailure(record *PostIntegrityRecord, reason string) {
+	o.Failures = append(o.Failures, &PostIntegrityFailure{
+		PostId:   record.PostId,
+		Sequence: record.Sequence,
+		Reason:   reason,
+	})
+}
+
+func (o *PostIntegrityVerification) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostIntegrityVerificationFromJson(data io.Reader) *PostIntegrityVerification {
+	decoder := json.NewDecoder(data)
+	var o PostIntegrityVerification
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostIntegrityRecord(t *testing.T) {
+	post := &Post{Id: NewId(), ChannelId: NewId(), UserId: NewId(), Message: "hello", CreateAt: GetMillis()}
+
+	first := NewPostIntegrityRecord(post, nil)
+	first.PreSave()
+	if err := first.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Sequence != 1 || first.PreviousHash != "" {
+		t.Fatal("should have started the chain")
+	}
+
+	if first.Hash != PostIntegrityHash(post, "hello", "") {
+		t.Fatal("should have hashed the post")
+	}
+
+	if first.Hash == PostIntegrityHash(post, "edited", "") {
+		t.Fatal("should have included the message in the hash")
+	}
+
+	post2 := &Post{Id: NewId(), ChannelId: post.ChannelId, UserId: post.UserId, Message: "hello", CreateAt: post.CreateAt}
+
+	second := NewPostIntegrityRecord(post2, first)
+	second.PreSave()
+	if err := second.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Sequence != 2 || second.PreviousHash != first.Hash {
+		t.Fatal("should have chained the record to the previous one")
+	}
+
+	if second.Hash == PostIntegrityHash(post2, "hello", "") {
+		t.Fatal("should have included the previous hash in the hash")
+	}
+
+	second.PreviousHash = ""
+	if err := second.IsValid(); err == nil {
+		t.Fatal("should be invalid without the previous hash")
+	}
+}
+
+func TestPostIntegrityVerificationJson(t *testing.T) {
+	verification := &PostIntegrityVerification{ChannelId: NewId(), RecordCount: 2}
+	if !verification.IsValid() {
+		t.Fatal("should be valid without failures")
+	}
+
+	verification.AddFailure(&PostIntegrityRecord{PostId: NewId(), Sequence: 2}, POST_INTEGRITY_FAILURE_MODIFIED_POST)
+
+	rverification := PostIntegrityVerificationFromJson(strings.NewReader(verification.ToJson()))
+	if rverification.ChannelId != verification.ChannelId || rverification.IsValid() || rverification.Failures[0].Reason != POST_INTEGRITY_FAILURE_MODIFIED_POST {
+		t.Fatal("verifications do not match")
+	}
+}
@@ -38,6 +38,9 @@ const (
 	PREFERENCE_CATEGORY_NOTIFICATIONS = "notifications"
 	PREFERENCE_NAME_EMAIL_INTERVAL    = "email_interval"
 	PREFERENCE_DEFAULT_EMAIL_INTERVAL = "30" // default to match the interval of the "immediate" setting (ie 30 seconds)
+
+	// Users that set this to "false" don't receive bulk emails that aren't critical
+	PREFERENCE_NAME_BULK_EMAIL = "bulk_email"
 )
 
 type Preference struct {
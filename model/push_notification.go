@@ -38,6 +38,37 @@ type PushNotification struct {
 	Type             string `json:"type"`
 }
 
+// PushProxy is an additional push notification proxy. When Platforms is set, only notifications
+// for devices on those platforms are sent to it.
+type PushProxy struct {
+	Url       string
+	Platforms []string
+}
+
+// SupportsPlatform returns whether notifications for devices on the platform can be sent to the proxy.
+func (o *PushProxy) SupportsPlatform(platform string) bool {
+	if len(o.Platforms) == 0 {
+		return true
+	}
+
+	for _, p := range o.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidPushPlatform(platform string) bool {
+	switch platform {
+	case PUSH_NOTIFY_APPLE, PUSH_NOTIFY_ANDROID, PUSH_NOTIFY_APPLE_REACT_NATIVE, PUSH_NOTIFY_ANDROID_REACT_NATIVE:
+		return true
+	}
+
+	return false
+}
+
 func (me *PushNotification) ToJson() string {
 	b, err := json.Marshal(me)
 	if err != nil {
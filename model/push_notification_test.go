@@ -92,3 +92,19 @@ func TestPushNotificationDeviceId(t *testing.T) {
 	msg.Platform = ""
 	msg.DeviceId = ""
 }
+
+func TestPushProxySupportsPlatform(t *testing.T) {
+	proxy := PushProxy{Url: "http://push.example.com"}
+	if !proxy.SupportsPlatform(PUSH_NOTIFY_ANDROID) {
+		t.Fatal("a proxy without routing rules should support every platform")
+	}
+
+	proxy.Platforms = []string{PUSH_NOTIFY_APPLE}
+	if !proxy.SupportsPlatform(PUSH_NOTIFY_APPLE) {
+		t.Fatal("should support a platform it's routed")
+	}
+
+	if proxy.SupportsPlatform(PUSH_NOTIFY_ANDROID) {
+		t.Fatal("shouldn't support a platform it isn't routed")
+	}
+}
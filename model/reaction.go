@@ -13,6 +13,7 @@ type Reaction struct {
 	PostId    string `json:"post_id"`
 	EmojiName string `json:"emoji_name"`
 	CreateAt  int64  `json:"create_at"`
+	DeleteAt  int64  `json:"delete_at"`
 }
 
 func (o *Reaction) ToJson() string {
@@ -76,3 +77,45 @@ func (o *Reaction) PreSave() {
 		o.CreateAt = GetMillis()
 	}
 }
+
+func ReactionsByPostToJson(o map[string][]*Reaction) string {
+	if b, err := json.Marshal(o); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ReactionsByPostFromJson(data io.Reader) map[string][]*Reaction {
+	var o map[string][]*Reaction
+
+	if err := json.NewDecoder(data).Decode(&o); err != nil {
+		return nil
+	} else {
+		return o
+	}
+}
+
+// ReactionCount is the number of times an emoji was used as a reaction.
+type ReactionCount struct {
+	EmojiName string `json:"emoji_name"`
+	Count     int64  `json:"count"`
+}
+
+func ReactionCountsToJson(o []*ReactionCount) string {
+	if b, err := json.Marshal(o); err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ReactionCountsFromJson(data io.Reader) []*ReactionCount {
+	var o []*ReactionCount
+
+	if err := json.NewDecoder(data).Decode(&o); err != nil {
+		return nil
+	} else {
+		return o
+	}
+}
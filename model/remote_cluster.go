@@ -0,0 +1,252 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	// HEADER_REMOTE_ID and HEADER_REMOTE_TOKEN identify a remote cluster making a request to this
+	// server. The id is the one that this server gave the remote, and the token is the secret that
+	// was exchanged when the invitation was accepted.
+	HEADER_REMOTE_ID    = "X-Mattermost-Remote-Id"
+	HEADER_REMOTE_TOKEN = "X-Mattermost-Remote-Token"
+
+	REMOTE_CLUSTER_NAME_MAX_LENGTH         = 32
+	REMOTE_CLUSTER_DISPLAY_NAME_MAX_RUNES  = 64
+	REMOTE_CLUSTER_SITE_URL_MAX_LENGTH     = 512
+	REMOTE_CLUSTER_OFFLINE_AFTER_SECONDS   = 5 * 60
+	REMOTE_CLUSTER_SYNC_BATCH_SIZE         = 100
+	REMOTE_CLUSTER_MAX_ATTACHMENT_SIZE     = 10 * 1024 * 1024
+	REMOTE_CLUSTER_INVITE_MAX_ENCODED_SIZE = 4096
+)
+
+// RemoteCluster is another Mattermost server that this one shares channels with. Each server has
+// its own id for the other, and RemoteId is the id that this server gave the remote. The remote
+// identifies itself with RemoteId and Token, and this server identifies itself to the remote with
+// RemoteRecordId and RemoteToken. A remote is pending until the server it was invited from
+// confirms the invitation.
+type RemoteCluster struct {
+	RemoteId       string `json:"remote_id"`
+	Name           string `json:"name"`
+	DisplayName    string `json:"display_name"`
+	SiteURL        string `json:"site_url"`
+	Token          string `json:"token"`
+	RemoteRecordId string `json:"remote_record_id"`
+	RemoteToken    string `json:"remote_token"`
+	DefaultTeamId  string `json:"default_team_id"`
+	CreatorId      string `json:"creator_id"`
+	CreateAt       int64  `json:"create_at"`
+	LastPingAt     int64  `json:"last_ping_at"`
+}
+
+// RemoteClusterInvite is given to the administrator of another server to connect it to this one.
+type RemoteClusterInvite struct {
+	RemoteId string `json:"remote_id"`
+	SiteURL  string `json:"site_url"`
+	Token    string `json:"token"`
+}
+
+// RemoteClusterConfirmation is sent by a server that accepted an invitation back to the server
+// that created it.
+type RemoteClusterConfirmation struct {
+	RemoteId string `json:"remote_id"`
+	SiteURL  string `json:"site_url"`
+	Token    string `json:"token"`
+}
+
+func (o *RemoteCluster) IsValid() *AppError {
+	if len(o.RemoteId) != 26 {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidRemoteClusterName(o.Name) {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.name.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.DisplayName) > REMOTE_CLUSTER_DISPLAY_NAME_MAX_RUNES {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.display_name.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(o.SiteURL) > REMOTE_CLUSTER_SITE_URL_MAX_LENGTH || (len(o.SiteURL) > 0 && !IsValidHttpUrl(o.SiteURL)) {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.site_url.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(o.Token) != 26 {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.token.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(o.DefaultTeamId) > 0 && len(o.DefaultTeamId) != 26 {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.default_team_id.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.creator_id.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("RemoteCluster.IsValid", "model.remote_cluster.is_valid.create_at.app_error", nil, "remote_id="+o.RemoteId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// IsValidRemoteClusterName checks that a name can be used in the usernames of users from the
+// remote, so it follows the same rules as usernames.
+func IsValidRemoteClusterName(name string) bool {
+	return len(name) > 0 && len(name) <= REMOTE_CLUSTER_NAME_MAX_LENGTH && IsValidUsername(name)
+}
+
+func (o *RemoteCluster) PreSave() {
+	if o.RemoteId == "" {
+		o.RemoteId = NewId()
+	}
+
+	if o.Token == "" {
+		o.Token = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+// IsConfirmed is whether both servers know how to reach each other.
+func (o *RemoteCluster) IsConfirmed() bool {
+	return len(o.SiteURL) > 0 && len(o.RemoteToken) > 0
+}
+
+// IsOnline is whether the remote answered recently.
+func (o *RemoteCluster) IsOnline() bool {
+	return o.LastPingAt > GetMillis()-REMOTE_CLUSTER_OFFLINE_AFTER_SECONDS*1000
+}
+
+// Sanitize removes the secrets used to talk to the remote.
+func (o *RemoteCluster) Sanitize() {
+	o.Token = ""
+	o.RemoteToken = ""
+}
+
+func (o *RemoteCluster) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RemoteClusterFromJson(data io.Reader) *RemoteCluster {
+	decoder := json.NewDecoder(data)
+	var o RemoteCluster
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func RemoteClusterListToJson(l []*RemoteCluster) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RemoteClusterListFromJson(data io.Reader) []*RemoteCluster {
+	decoder := json.NewDecoder(data)
+	var o []*RemoteCluster
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+// Encode turns the invite into a single string that can be pasted into the other server.
+func (o *RemoteClusterInvite) Encode() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	}
+
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+// DecodeRemoteClusterInvite reads an invite created by Encode, returning nil if it isn't one.
+func DecodeRemoteClusterInvite(encoded string) *RemoteClusterInvite {
+	if len(encoded) > REMOTE_CLUSTER_INVITE_MAX_ENCODED_SIZE {
+		return nil
+	}
+
+	b, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+
+	var o RemoteClusterInvite
+	if err := json.Unmarshal(b, &o); err != nil {
+		return nil
+	}
+
+	if len(o.RemoteId) != 26 || len(o.Token) != 26 || !IsValidHttpUrl(o.SiteURL) {
+		return nil
+	}
+
+	return &o
+}
+
+func (o *RemoteClusterConfirmation) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RemoteClusterConfirmationFromJson(data io.Reader) *RemoteClusterConfirmation {
+	decoder := json.NewDecoder(data)
+	var o RemoteClusterConfirmation
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// RemoteClusterWithInvite is returned when a remote is created, along with the invite to give its
+// administrator.
+type RemoteClusterWithInvite struct {
+	RemoteCluster *RemoteCluster `json:"remote_cluster"`
+	Invite        string         `json:"invite"`
+}
+
+func (o *RemoteClusterWithInvite) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RemoteClusterWithInviteFromJson(data io.Reader) *RemoteClusterWithInvite {
+	decoder := json.NewDecoder(data)
+	var o RemoteClusterWithInvite
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
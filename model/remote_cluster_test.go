@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemoteClusterIsValid(t *testing.T) {
+	o := RemoteCluster{Name: "remote", CreatorId: NewId()}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Name = "Not Valid"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = strings.Repeat("a", REMOTE_CLUSTER_NAME_MAX_LENGTH+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = "remote"
+	o.SiteURL = "nowhere"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.SiteURL = "https://example.com"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.IsConfirmed() {
+		t.Fatal("shouldn't be confirmed without the remote's token")
+	}
+
+	o.RemoteToken = NewId()
+	if !o.IsConfirmed() {
+		t.Fatal("should be confirmed")
+	}
+
+	o.Sanitize()
+	if o.Token != "" || o.RemoteToken != "" {
+		t.Fatal("tokens should have been removed")
+	}
+}
+
+func TestRemoteClusterInvite(t *testing.T) {
+	o := RemoteClusterInvite{RemoteId: NewId(), SiteURL: "https://example.com", Token: NewId()}
+
+	if ro := DecodeRemoteClusterInvite(o.Encode()); ro == nil || *ro != o {
+		t.Fatal("invites do not match")
+	}
+
+	if DecodeRemoteClusterInvite("junk") != nil {
+		t.Fatal("shouldn't decode junk")
+	}
+
+	o.SiteURL = "nowhere"
+	if DecodeRemoteClusterInvite(o.Encode()) != nil {
+		t.Fatal("shouldn't decode an invite without a valid site url")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	RETENTION_POLICY_MAX_DAYS = 36500
+)
+
+// RetentionPolicy overrides the global data retention settings for a team or a channel. Exactly one
+// of TeamId and ChannelId is set. A channel's policy takes precedence over its team's policy, which
+// takes precedence over the global settings. A number of days of 0 keeps the messages or files
+// forever.
+type RetentionPolicy struct {
+	Id                   string `json:"id"`
+	TeamId               string `json:"team_id"`
+	ChannelId            string `json:"channel_id"`
+	MessageRetentionDays int    `json:"message_retention_days"`
+	FileRetentionDays    int    `json:"file_retention_days"`
+	CreateAt             int64  `json:"create_at"`
+	UpdateAt             int64  `json:"update_at"`
+}
+
+// RetentionPreview is what the next run of the data retention job would delete for the global
+// settings, when PolicyId is empty, or for a policy.
+type RetentionPreview struct {
+	PolicyId             string `json:"policy_id"`
+	TeamId               string `json:"team_id"`
+	ChannelId            string `json:"channel_id"`
+	MessageRetentionDays int    `json:"message_retention_days"`
+	FileRetentionDays    int    `json:"file_retention_days"`
+	MessageCount         int64  `json:"message_count"`
+	FileCount            int64  `json:"file_count"`
+}
+
+func (o *RetentionPolicy) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) == 26 {
+		if len(o.ChannelId) != 0 {
+			return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	} else if len(o.TeamId) == 0 {
+		if len(o.ChannelId) != 26 {
+			return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+		}
+	} else {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.MessageRetentionDays < 0 || o.MessageRetentionDays > RETENTION_POLICY_MAX_DAYS {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.message_retention_days.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.FileRetentionDays < 0 || o.FileRetentionDays > RETENTION_POLICY_MAX_DAYS {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.file_retention_days.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("RetentionPolicy.IsValid", "model.retention_policy.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *RetentionPolicy) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *RetentionPolicy) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// GetRetentionEndTime returns the time in milliseconds before which messages or files kept for a
+// number of days are deleted, or 0 if they're kept forever.
+func GetRetentionEndTime(now int64, days int) int64 {
+	if days <= 0 {
+		return 0
+	}
+
+	return now - int64(days)*24*60*60*1000
+}
+
+func (o *RetentionPolicy) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RetentionPolicyFromJson(data io.Reader) *RetentionPolicy {
+	decoder := json.NewDecoder(data)
+	var o RetentionPolicy
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func RetentionPolicyListToJson(l []*RetentionPolicy) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RetentionPolicyListFromJson(data io.Reader) []*RetentionPolicy {
+	decoder := json.NewDecoder(data)
+	var o []*RetentionPolicy
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func RetentionPreviewListToJson(l []*RetentionPreview) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func RetentionPreviewListFromJson(data io.Reader) []*RetentionPreview {
+	decoder := json.NewDecoder(data)
+	var o []*RetentionPreview
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
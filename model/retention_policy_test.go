@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRetentionPolicyJson(t *testing.T) {
+	o := RetentionPolicy{Id: NewId(), TeamId: NewId(), MessageRetentionDays: 30}
+	json := o.ToJson()
+	ro := RetentionPolicyFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.MessageRetentionDays != ro.MessageRetentionDays {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestRetentionPolicyIsValid(t *testing.T) {
+	o := RetentionPolicy{TeamId: NewId(), MessageRetentionDays: 30, FileRetentionDays: 7}
+	o.PreSave()
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with both a team and a channel")
+	}
+
+	o.TeamId = ""
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.ChannelId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid without a team or a channel")
+	}
+
+	o.ChannelId = NewId()
+	o.MessageRetentionDays = 0
+	o.FileRetentionDays = 0
+	if err := o.IsValid(); err != nil {
+		t.Fatal("should be valid when keeping data forever")
+	}
+
+	o.MessageRetentionDays = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with negative days")
+	}
+
+	o.MessageRetentionDays = 0
+	o.FileRetentionDays = RETENTION_POLICY_MAX_DAYS + 1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't be valid with too many days")
+	}
+}
+
+func TestGetRetentionEndTime(t *testing.T) {
+	if endTime := GetRetentionEndTime(10*24*60*60*1000, 3); endTime != 7*24*60*60*1000 {
+		t.Fatalf("bad end time %v", endTime)
+	}
+
+	if endTime := GetRetentionEndTime(GetMillis(), 0); endTime != 0 {
+		t.Fatal("should keep data forever with 0 days")
+	}
+}
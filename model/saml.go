@@ -22,6 +22,32 @@ type SamlAuthRequest struct {
 	RelayState        string
 }
 
+// SamlGroupMapping makes the users in an IdP group members of a team and of some of its channels when
+// they log in with SAML.
+type SamlGroupMapping struct {
+	Group      string
+	TeamId     string
+	ChannelIds StringArray
+}
+
+func (m *SamlGroupMapping) IsValid() *AppError {
+	if len(m.Group) == 0 {
+		return NewLocAppError("SamlGroupMapping.IsValid", "model.saml_group_mapping.is_valid.group.app_error", nil, "")
+	}
+
+	if len(m.TeamId) != 26 {
+		return NewLocAppError("SamlGroupMapping.IsValid", "model.saml_group_mapping.is_valid.team_id.app_error", nil, "group="+m.Group)
+	}
+
+	for _, channelId := range m.ChannelIds {
+		if len(channelId) != 26 {
+			return NewLocAppError("SamlGroupMapping.IsValid", "model.saml_group_mapping.is_valid.channel_id.app_error", nil, "group="+m.Group)
+		}
+	}
+
+	return nil
+}
+
 type SamlCertificateStatus struct {
 	IdpCertificateFile    bool `json:"idp_certificate_file"`
 	PrivateKeyFile        bool `json:"private_key_file"`
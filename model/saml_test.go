@@ -22,3 +22,32 @@ func TestSamlCertificateStatusJson(t *testing.T) {
 		t.Fatal("should be nil")
 	}
 }
+
+func TestSamlGroupMappingIsValid(t *testing.T) {
+	mapping := &SamlGroupMapping{Group: "engineering", TeamId: NewId(), ChannelIds: []string{NewId()}}
+	if err := mapping.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping.ChannelIds = nil
+	if err := mapping.IsValid(); err != nil {
+		t.Fatal("should be valid without channels")
+	}
+
+	mapping.ChannelIds = []string{"junk"}
+	if err := mapping.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad channel id")
+	}
+
+	mapping.ChannelIds = nil
+	mapping.TeamId = "junk"
+	if err := mapping.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad team id")
+	}
+
+	mapping.TeamId = NewId()
+	mapping.Group = ""
+	if err := mapping.IsValid(); err == nil {
+		t.Fatal("should be invalid without a group")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// ScheduledPost is a post that will be created on behalf of its user once ScheduledAt has passed.
+type ScheduledPost struct {
+	Id          string          `json:"id"`
+	UserId      string          `json:"user_id"`
+	ChannelId   string          `json:"channel_id"`
+	RootId      string          `json:"root_id"`
+	Message     string          `json:"message"`
+	Props       StringInterface `json:"props"`
+	FileIds     StringArray     `json:"file_ids,omitempty"`
+	ScheduledAt int64           `json:"scheduled_at"`
+	CreateAt    int64           `json:"create_at"`
+	UpdateAt    int64           `json:"update_at"`
+
+	// ClaimedAt is when a server last started publishing the post and Attempts is how many times
+	// that has happened. A claim that is still held keeps other servers from publishing it too.
+	ClaimedAt int64 `json:"-"`
+	Attempts  int   `json:"-"`
+}
+
+func (o *ScheduledPost) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(len(o.RootId) == 26 || len(o.RootId) == 0) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.root_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Message) == 0 && len(o.FileIds) == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.message.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > POST_MESSAGE_MAX_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.message.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.file_ids.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.props.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ScheduledAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.scheduled_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ScheduledPost) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+
+	o.ClaimedAt = 0
+	o.Attempts = 0
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+// ToPost returns the post that should be created when the scheduled post is published.
+func (o *ScheduledPost) ToPost() *Post {
+	post := &Post{
+		UserId:    o.UserId,
+		ChannelId: o.ChannelId,
+		RootId:    o.RootId,
+		ParentId:  o.RootId,
+		Message:   o.Message,
+		FileIds:   o.FileIds,
+	}
+
+	if o.Props != nil {
+		post.Props = make(map[string]interface{}, len(o.Props))
+		for key, value := range o.Props {
+			post.Props[key] = value
+		}
+	}
+
+	return post
+}
+
+func (o *ScheduledPost) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostFromJson(data io.Reader) *ScheduledPost {
+	decoder := json.NewDecoder(data)
+	var o ScheduledPost
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ScheduledPostListToJson(l []*ScheduledPost) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostListFromJson(data io.Reader) []*ScheduledPost {
+	decoder := json.NewDecoder(data)
+	var o []*ScheduledPost
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduledPostJson(t *testing.T) {
+	o := ScheduledPost{Id: NewId(), Message: "hello", ScheduledAt: GetMillis()}
+	json := o.ToJson()
+	ro := ScheduledPostFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Message != ro.Message || o.ScheduledAt != ro.ScheduledAt {
+		t.Fatal("scheduled posts do not match")
+	}
+}
+
+func TestScheduledPostIsValid(t *testing.T) {
+	o := ScheduledPost{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = "hello"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ScheduledAt = GetMillis()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = NewId()
+	o.Message = strings.Repeat("a", POST_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a message or files")
+	}
+
+	o.FileIds = []string{NewId()}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduledPostToPost(t *testing.T) {
+	o := ScheduledPost{UserId: NewId(), ChannelId: NewId(), RootId: NewId(), Message: "hello"}
+	o.Props = StringInterface{"attachments": "a"}
+
+	post := o.ToPost()
+	if post.UserId != o.UserId || post.ChannelId != o.ChannelId || post.Message != o.Message {
+		t.Fatal("post does not match scheduled post")
+	}
+
+	if post.RootId != o.RootId || post.ParentId != o.RootId {
+		t.Fatal("post should reply to the scheduled post's thread")
+	}
+
+	post.Props["attachments"] = "b"
+	if o.Props["attachments"] != "a" {
+		t.Fatal("post props should be a copy")
+	}
+}
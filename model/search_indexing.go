@@ -0,0 +1,23 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+// PostForIndexing is a post along with the team it belongs to, which the search engine needs to
+// limit results to a team without looking up the channel of every post.
+type PostForIndexing struct {
+	Post
+	TeamId string `json:"team_id"`
+}
+
+// FileForIndexing is a file attached to a post along with the channel of that post.
+type FileForIndexing struct {
+	FileInfo
+	ChannelId string `json:"channel_id"`
+}
+
+// UserForIndexing is a user along with the teams they belong to.
+type UserForIndexing struct {
+	User
+	TeamIds []string `json:"team_ids"`
+}
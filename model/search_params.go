@@ -6,20 +6,55 @@ package model
 import (
 	"regexp"
 	"strings"
+	"time"
 )
 
 var searchTermPuncStart = regexp.MustCompile(`^[^\pL\d\s#"]+`)
 var searchTermPuncEnd = regexp.MustCompile(`[^\pL\d\s*"]+$`)
 
+const (
+	SEARCH_DATE_FORMAT = "2006-01-02"
+)
+
 type SearchParams struct {
-	Terms      string
-	IsHashtag  bool
-	InChannels []string
-	FromUsers  []string
-	OrTerms    bool
+	Terms       string
+	IsHashtag   bool
+	InChannels  []string
+	FromUsers   []string
+	OrTerms     bool
+	AfterDate   string
+	BeforeDate  string
+	HasReaction bool
+	ReactedBy   []string
 }
 
-var searchFlags = [...]string{"from", "channel", "in"}
+var searchFlags = [...]string{"from", "channel", "in", "after", "before", "has", "reacted-by"}
+
+// HasFilters returns true if the search is narrowed down by anything other than its terms.
+func (p *SearchParams) HasFilters() bool {
+	return len(p.InChannels) != 0 || len(p.FromUsers) != 0 || p.GetAfterDateMillis() != 0 || p.GetBeforeDateMillis() != 0 ||
+		p.HasReaction || len(p.ReactedBy) != 0
+}
+
+// GetAfterDateMillis returns the end of the day given by the after: flag in UTC, since posts made on
+// that day aren't after it, or 0 if there isn't a valid date.
+func (p *SearchParams) GetAfterDateMillis() int64 {
+	if date, err := time.Parse(SEARCH_DATE_FORMAT, p.AfterDate); err != nil {
+		return 0
+	} else {
+		return date.AddDate(0, 0, 1).UnixNano() / int64(time.Millisecond)
+	}
+}
+
+// GetBeforeDateMillis returns the start of the day given by the before: flag in UTC, or 0 if there
+// isn't a valid date.
+func (p *SearchParams) GetBeforeDateMillis() int64 {
+	if date, err := time.Parse(SEARCH_DATE_FORMAT, p.BeforeDate); err != nil {
+		return 0
+	} else {
+		return date.UnixNano() / int64(time.Millisecond)
+	}
+}
 
 func splitWordsNoQuotes(text string) []string {
 	words := []string{}
@@ -127,48 +162,54 @@ func ParseSearchParams(text string) []*SearchParams {
 	hashtagTerms := strings.Join(hashtagTermList, " ")
 	plainTerms := strings.Join(plainTermList, " ")
 
-	inChannels := []string{}
-	fromUsers := []string{}
+	// the filters are shared by the plain and hashtag searches
+	filters := SearchParams{
+		InChannels: []string{},
+		FromUsers:  []string{},
+		ReactedBy:  []string{},
+	}
 
 	for _, flagPair := range flags {
 		flag := flagPair[0]
 		value := flagPair[1]
 
 		if flag == "in" || flag == "channel" {
-			inChannels = append(inChannels, value)
+			filters.InChannels = append(filters.InChannels, value)
 		} else if flag == "from" {
-			fromUsers = append(fromUsers, value)
+			filters.FromUsers = append(filters.FromUsers, value)
+		} else if flag == "after" {
+			filters.AfterDate = value
+		} else if flag == "before" {
+			filters.BeforeDate = value
+		} else if flag == "has" && (strings.EqualFold(value, "reaction") || strings.EqualFold(value, "reactions")) {
+			filters.HasReaction = true
+		} else if flag == "reacted-by" {
+			filters.ReactedBy = append(filters.ReactedBy, strings.TrimPrefix(value, "@"))
 		}
 	}
 
 	paramsList := []*SearchParams{}
 
 	if len(plainTerms) > 0 {
-		paramsList = append(paramsList, &SearchParams{
-			Terms:      plainTerms,
-			IsHashtag:  false,
-			InChannels: inChannels,
-			FromUsers:  fromUsers,
-		})
+		params := filters
+		params.Terms = plainTerms
+		params.IsHashtag = false
+		paramsList = append(paramsList, &params)
 	}
 
 	if len(hashtagTerms) > 0 {
-		paramsList = append(paramsList, &SearchParams{
-			Terms:      hashtagTerms,
-			IsHashtag:  true,
-			InChannels: inChannels,
-			FromUsers:  fromUsers,
-		})
+		params := filters
+		params.Terms = hashtagTerms
+		params.IsHashtag = true
+		paramsList = append(paramsList, &params)
 	}
 
 	// special case for when no terms are specified but we still have a filter
-	if len(plainTerms) == 0 && len(hashtagTerms) == 0 && (len(inChannels) != 0 || len(fromUsers) != 0) {
-		paramsList = append(paramsList, &SearchParams{
-			Terms:      "",
-			IsHashtag:  false,
-			InChannels: inChannels,
-			FromUsers:  fromUsers,
-		})
+	if len(plainTerms) == 0 && len(hashtagTerms) == 0 && filters.HasFilters() {
+		params := filters
+		params.Terms = ""
+		params.IsHashtag = false
+		paramsList = append(paramsList, &params)
 	}
 
 	return paramsList
@@ -221,3 +221,47 @@ func TestParseSearchParams(t *testing.T) {
 		t.Fatalf("Incorrect output from parse search params: %v", sp[0])
 	}
 }
+
+func TestParseSearchParamsFilters(t *testing.T) {
+	if sp := ParseSearchParams("testing after:2017-05-01 before:2017-06-01"); len(sp) != 1 || sp[0].Terms != "testing" || sp[0].AfterDate != "2017-05-01" || sp[0].BeforeDate != "2017-06-01" {
+		t.Fatalf("Incorrect output from parse search params: %v", sp)
+	}
+
+	if sp := ParseSearchParams("after: 2017-05-01"); len(sp) != 1 || sp[0].Terms != "" || sp[0].AfterDate != "2017-05-01" {
+		t.Fatalf("Incorrect output from parse search params: %v", sp)
+	}
+
+	if sp := ParseSearchParams("has:reaction"); len(sp) != 1 || sp[0].Terms != "" || !sp[0].HasReaction {
+		t.Fatalf("Incorrect output from parse search params: %v", sp)
+	}
+
+	if sp := ParseSearchParams("has:nothing"); len(sp) != 0 {
+		t.Fatalf("Incorrect output from parse search params: %v", sp)
+	}
+
+	if sp := ParseSearchParams("testing #hashtag reacted-by:@someone"); len(sp) != 2 || len(sp[0].ReactedBy) != 1 || sp[0].ReactedBy[0] != "someone" || len(sp[1].ReactedBy) != 1 || !sp[1].IsHashtag {
+		t.Fatalf("Incorrect output from parse search params: %v", sp)
+	}
+}
+
+func TestSearchParamsDates(t *testing.T) {
+	params := &SearchParams{AfterDate: "2017-05-01", BeforeDate: "2017-05-03"}
+
+	if after := params.GetAfterDateMillis(); after != 1493683200000 {
+		t.Fatal("after date should be the end of the day", after)
+	}
+
+	if before := params.GetBeforeDateMillis(); before != 1493769600000 {
+		t.Fatal("before date should be the start of the day", before)
+	}
+
+	if !params.HasFilters() {
+		t.Fatal("dates should be filters")
+	}
+
+	params = &SearchParams{AfterDate: "yesterday"}
+
+	if params.GetAfterDateMillis() != 0 || params.HasFilters() {
+		t.Fatal("invalid dates should be ignored")
+	}
+}
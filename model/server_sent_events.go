@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	HEADER_LAST_EVENT_ID = "Last-Event-ID"
+
+	SERVER_SENT_EVENTS_PARAM_LAST_EVENT_ID = "last_event_id"
+	SERVER_SENT_EVENTS_MAX_EVENT_SIZE      = 1024 * 1024 // 1MB
+)
+
+// ServerSentEventId returns the id of a server-sent event, which is made of the id of the connection
+// that it was sent on and its sequence number so that the client can resume the connection with it.
+func ServerSentEventId(connectionId string, sequence int64) string {
+	return connectionId + ":" + strconv.FormatInt(sequence, 10)
+}
+
+// ParseServerSentEventId returns the connection id and the sequence number of a server-sent event id.
+func ParseServerSentEventId(id string) (string, int64, bool) {
+	parts := strings.Split(id, ":")
+	if len(parts) != 2 || len(parts[0]) != 26 {
+		return "", 0, false
+	}
+
+	sequence, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || sequence < 0 {
+		return "", 0, false
+	}
+
+	return parts[0], sequence, true
+}
+
+// ReadServerSentEvents calls onData with the data of each event of a server-sent events stream
+// until the stream ends. Comments, which the server sends to keep the stream open, and the other
+// fields of the events are skipped.
+func ReadServerSentEvents(r io.Reader, onData func(data string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), SERVER_SENT_EVENTS_MAX_EVENT_SIZE)
+
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if len(line) == 0 {
+			if len(data) > 0 {
+				onData(strings.Join(data, "\n"))
+				data = nil
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if index := strings.Index(line, ":"); index != -1 {
+			field = line[:index]
+			value = strings.TrimPrefix(line[index+1:], " ")
+		}
+
+		if field == "data" {
+			data = append(data, value)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServerSentEventId(t *testing.T) {
+	connectionId := NewId()
+
+	if rconnectionId, sequence, ok := ParseServerSentEventId(ServerSentEventId(connectionId, 12)); !ok || rconnectionId != connectionId || sequence != 12 {
+		t.Fatal("should have parsed the id back")
+	}
+
+	for _, id := range []string{"", connectionId, connectionId + ":", connectionId + ":junk", connectionId + ":-1", "junk:12", connectionId + ":12:12"} {
+		if _, _, ok := ParseServerSentEventId(id); ok {
+			t.Fatal("shouldn't have parsed an invalid id", id)
+		}
+	}
+}
+
+func TestReadServerSentEvents(t *testing.T) {
+	stream := ":\n\nid: 1\ndata: {\"event\":\"hello\"}\n\ndata:first\ndata: second\n\n:\n\nid: 3\n\ndata: last"
+
+	var events []string
+	if err := ReadServerSentEvents(strings.NewReader(stream), func(data string) {
+		events = append(events, data)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || events[0] != `{"event":"hello"}` || events[1] != "first\nsecond" {
+		t.Fatal("should have read the events", events)
+	}
+}
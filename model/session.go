@@ -10,11 +10,16 @@ import (
 )
 
 const (
-	SESSION_COOKIE_TOKEN  = "MMAUTHTOKEN"
-	SESSION_CACHE_SIZE    = 35000
-	SESSION_PROP_PLATFORM = "platform"
-	SESSION_PROP_OS       = "os"
-	SESSION_PROP_BROWSER  = "browser"
+	SESSION_COOKIE_TOKEN              = "MMAUTHTOKEN"
+	SESSION_CACHE_SIZE                = 35000
+	SESSION_PROP_PLATFORM             = "platform"
+	SESSION_PROP_OS                   = "os"
+	SESSION_PROP_BROWSER              = "browser"
+	SESSION_PROP_TYPE                 = "type"
+	SESSION_PROP_USER_ACCESS_TOKEN_ID = "user_access_token_id"
+	SESSION_PROP_LABEL                = "label"
+	SESSION_LABEL_MAX_RUNES           = 64
+	SESSION_TYPE_USER_ACCESS_TOKEN    = "UserAccessToken"
 )
 
 type Session struct {
@@ -56,7 +61,10 @@ func (me *Session) PreSave() {
 		me.Id = NewId()
 	}
 
-	me.Token = NewId()
+	// Sessions for user access tokens use the access token as their token
+	if me.Token == "" {
+		me.Token = NewId()
+	}
 
 	me.CreateAt = GetMillis()
 	me.LastActivityAt = me.CreateAt
@@ -114,6 +122,41 @@ func (me *Session) IsMobileApp() bool {
 	return len(me.DeviceId) > 0
 }
 
+// GetOAuthScope returns the scope granted to an OAuth session. Other sessions, and OAuth sessions
+// created before scopes were supported, have the full permissions of the user.
+func (me *Session) GetOAuthScope() string {
+	if !me.IsOAuth || len(me.Props[SESSION_PROP_OAUTH_SCOPE]) == 0 {
+		return OAUTH_SCOPE_USER
+	}
+
+	return me.Props[SESSION_PROP_OAUTH_SCOPE]
+}
+
+// IsUserAccessToken returns true if the session was created for a user access token.
+func (me *Session) IsUserAccessToken() bool {
+	return me.Props[SESSION_PROP_TYPE] == SESSION_TYPE_USER_ACCESS_TOKEN
+}
+
+func (me *Session) IsOAuthReadOnly() bool {
+	return !OAuthScopeAllowsWrite(me.GetOAuthScope())
+}
+
+// IsOAuthUserInfoOnly returns whether an OAuth session was only granted OpenID Connect scopes, which
+// limits it to reading the user info.
+func (me *Session) IsOAuthUserInfoOnly() bool {
+	return !OAuthScopeAllowsRead(me.GetOAuthScope())
+}
+
+// IsOAuthScopeRestricted returns whether an OAuth session was granted less than the full
+// permissions of its user.
+func (me *Session) IsOAuthScopeRestricted() bool {
+	return me.IsOAuthReadOnly() || len(OAuthScopeChannelIds(me.GetOAuthScope())) > 0
+}
+
+func (me *Session) IsChannelAllowedByOAuthScope(channelId string) bool {
+	return OAuthScopeAllowsChannel(me.GetOAuthScope(), channelId)
+}
+
 func (me *Session) GetUserRoles() []string {
 	return strings.Fields(me.Roles)
 }
@@ -0,0 +1,213 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	// POST_PROP_REMOTE_ID is set on posts that were synced from a remote cluster.
+	POST_PROP_REMOTE_ID = "remote_id"
+
+	// USER_PROP_REMOTE_ID is set on the users created to stand in for the users of a remote cluster.
+	USER_PROP_REMOTE_ID = "remote_id"
+)
+
+// SharedChannel marks a channel as shared with other servers. The server that shared it is its home,
+// and the channel has its home id as its SharedId on every server. Channels that aren't at home
+// are only synced with the remote that they came from, which is RemoteId.
+type SharedChannel struct {
+	ChannelId string `json:"channel_id"`
+	TeamId    string `json:"team_id"`
+	SharedId  string `json:"shared_id"`
+	Home      bool   `json:"home"`
+	RemoteId  string `json:"remote_id"`
+	CreatorId string `json:"creator_id"`
+	CreateAt  int64  `json:"create_at"`
+	UpdateAt  int64  `json:"update_at"`
+}
+
+// SharedChannelRemote is a remote that a shared channel is synced with. LastSyncAt is the time, on
+// this server's clock, up to which changes to the channel have been sent to the remote.
+type SharedChannelRemote struct {
+	Id         string `json:"id"`
+	ChannelId  string `json:"channel_id"`
+	RemoteId   string `json:"remote_id"`
+	CreatorId  string `json:"creator_id"`
+	LastSyncAt int64  `json:"last_sync_at"`
+	CreateAt   int64  `json:"create_at"`
+}
+
+// SharedChannelInvite asks a remote to create its copy of a shared channel.
+type SharedChannelInvite struct {
+	SharedId    string `json:"shared_id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Purpose     string `json:"purpose"`
+	Header      string `json:"header"`
+}
+
+// SharedChannelSyncMsg carries the changes to a shared channel since it was last synced. Posts
+// include deleted ones so that deletions are synced as well. Reactions has every reaction to the
+// posts in ReactionPostIds, so that reactions missing from it were removed.
+type SharedChannelSyncMsg struct {
+	SharedId        string                     `json:"shared_id"`
+	Users           []*User                    `json:"users"`
+	Posts           []*Post                    `json:"posts"`
+	Reactions       []*Reaction                `json:"reactions"`
+	ReactionPostIds []string                   `json:"reaction_post_ids"`
+	Attachments     []*SharedChannelAttachment `json:"attachments"`
+}
+
+// SharedChannelAttachment is a file attached to a synced post along with its contents.
+type SharedChannelAttachment struct {
+	Info *FileInfo `json:"info"`
+	Data []byte    `json:"data"`
+}
+
+func (o *SharedChannel) IsValid() *AppError {
+	if len(o.ChannelId) != 26 {
+		return NewAppError("SharedChannel.IsValid", "model.shared_channel.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("SharedChannel.IsValid", "model.shared_channel.is_valid.team_id.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if len(o.SharedId) != 26 || (o.Home && o.SharedId != o.ChannelId) {
+		return NewAppError("SharedChannel.IsValid", "model.shared_channel.is_valid.shared_id.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if o.Home == (len(o.RemoteId) > 0) || (len(o.RemoteId) > 0 && len(o.RemoteId) != 26) {
+		return NewAppError("SharedChannel.IsValid", "model.shared_channel.is_valid.remote_id.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("SharedChannel.IsValid", "model.shared_channel.is_valid.creator_id.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *SharedChannel) PreSave() {
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *SharedChannelRemote) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("SharedChannelRemote.IsValid", "model.shared_channel_remote.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewAppError("SharedChannelRemote.IsValid", "model.shared_channel_remote.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.RemoteId) != 26 {
+		return NewAppError("SharedChannelRemote.IsValid", "model.shared_channel_remote.is_valid.remote_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("SharedChannelRemote.IsValid", "model.shared_channel_remote.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *SharedChannelRemote) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *SharedChannel) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SharedChannelFromJson(data io.Reader) *SharedChannel {
+	decoder := json.NewDecoder(data)
+	var o SharedChannel
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func SharedChannelRemoteListToJson(l []*SharedChannelRemote) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SharedChannelRemoteListFromJson(data io.Reader) []*SharedChannelRemote {
+	decoder := json.NewDecoder(data)
+	var o []*SharedChannelRemote
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *SharedChannelInvite) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SharedChannelInviteFromJson(data io.Reader) *SharedChannelInvite {
+	decoder := json.NewDecoder(data)
+	var o SharedChannelInvite
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *SharedChannelSyncMsg) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SharedChannelSyncMsgFromJson(data io.Reader) *SharedChannelSyncMsg {
+	decoder := json.NewDecoder(data)
+	var o SharedChannelSyncMsg
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// IsEmpty is whether there's nothing to sync.
+func (o *SharedChannelSyncMsg) IsEmpty() bool {
+	return len(o.Users) == 0 && len(o.Posts) == 0 && len(o.Reactions) == 0 && len(o.ReactionPostIds) == 0 && len(o.Attachments) == 0
+}
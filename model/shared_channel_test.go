@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSharedChannelIsValid(t *testing.T) {
+	channelId := NewId()
+	o := SharedChannel{ChannelId: channelId, TeamId: NewId(), SharedId: channelId, Home: true, CreatorId: NewId()}
+
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RemoteId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("a channel at home shouldn't have a remote")
+	}
+
+	o.Home = false
+	o.SharedId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RemoteId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("a channel that isn't at home should have a remote")
+	}
+
+	o.Home = true
+	if err := o.IsValid(); err == nil {
+		t.Fatal("a channel at home should have its own id as its shared id")
+	}
+}
+
+func TestSharedChannelSyncMsgJson(t *testing.T) {
+	o := SharedChannelSyncMsg{SharedId: NewId()}
+
+	if !o.IsEmpty() {
+		t.Fatal("should be empty")
+	}
+
+	o.Posts = []*Post{{Id: NewId(), Message: "message"}}
+	o.ReactionPostIds = []string{o.Posts[0].Id}
+	o.Attachments = []*SharedChannelAttachment{{Info: &FileInfo{Id: NewId()}, Data: []byte("data")}}
+
+	ro := SharedChannelSyncMsgFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.IsEmpty() || ro.Posts[0].Message != "message" || ro.ReactionPostIds[0] != o.Posts[0].Id || string(ro.Attachments[0].Data) != "data" {
+		t.Fatal("messages do not match")
+	}
+}
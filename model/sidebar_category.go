@@ -0,0 +1,145 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	SIDEBAR_CATEGORY_DISPLAY_NAME_MAX_RUNES = 64
+	SIDEBAR_CATEGORY_MAX_CHANNELS           = 1000
+)
+
+// SidebarCategory is a group of channels that a user made in their sidebar for a team. Categories are
+// kept on the server so that the user's sidebar looks the same on all of their devices.
+type SidebarCategory struct {
+	Id          string `json:"id"`
+	UserId      string `json:"user_id"`
+	TeamId      string `json:"team_id"`
+	DisplayName string `json:"display_name"`
+	SortOrder   int64  `json:"sort_order"`
+	Collapsed   bool   `json:"collapsed"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+}
+
+// SidebarChannel places one of a user's channels in one of their sidebar categories.
+type SidebarChannel struct {
+	CategoryId string `json:"category_id"`
+	ChannelId  string `json:"channel_id"`
+	UserId     string `json:"user_id"`
+	SortOrder  int64  `json:"sort_order"`
+}
+
+// SidebarCategoryWithChannels is a category along with the ids of its channels in the order that they
+// appear in the sidebar.
+type SidebarCategoryWithChannels struct {
+	SidebarCategory
+	Channels []string `json:"channel_ids"`
+}
+
+func (o *SidebarCategory) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.DisplayName) == 0 || utf8.RuneCountInString(o.DisplayName) > SIDEBAR_CATEGORY_DISPLAY_NAME_MAX_RUNES {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.display_name.app_error", map[string]interface{}{"Max": SIDEBAR_CATEGORY_DISPLAY_NAME_MAX_RUNES}, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *SidebarCategory) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *SidebarCategory) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *SidebarCategoryWithChannels) IsValid() *AppError {
+	if err := o.SidebarCategory.IsValid(); err != nil {
+		return err
+	}
+
+	if len(o.Channels) > SIDEBAR_CATEGORY_MAX_CHANNELS {
+		return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.channels.app_error", map[string]interface{}{"Max": SIDEBAR_CATEGORY_MAX_CHANNELS}, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	seen := make(map[string]bool)
+	for _, channelId := range o.Channels {
+		if len(channelId) != 26 || seen[channelId] {
+			return NewAppError("SidebarCategory.IsValid", "model.sidebar_category.is_valid.channel_id.app_error", nil, "id="+o.Id+", channel_id="+channelId, http.StatusBadRequest)
+		}
+		seen[channelId] = true
+	}
+
+	return nil
+}
+
+func (o *SidebarCategoryWithChannels) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SidebarCategoryFromJson(data io.Reader) *SidebarCategoryWithChannels {
+	decoder := json.NewDecoder(data)
+	var o SidebarCategoryWithChannels
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func SidebarCategoryListToJson(l []*SidebarCategoryWithChannels) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SidebarCategoryListFromJson(data io.Reader) []*SidebarCategoryWithChannels {
+	decoder := json.NewDecoder(data)
+	var o []*SidebarCategoryWithChannels
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
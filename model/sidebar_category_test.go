@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSidebarCategoryJson(t *testing.T) {
+	o := SidebarCategoryWithChannels{
+		SidebarCategory: SidebarCategory{Id: NewId(), UserId: NewId(), TeamId: NewId(), DisplayName: "Projects"},
+		Channels:        []string{NewId(), NewId()},
+	}
+	json := o.ToJson()
+	ro := SidebarCategoryFromJson(strings.NewReader(json))
+
+	if ro.Id != o.Id || ro.DisplayName != o.DisplayName {
+		t.Fatal("ids do not match")
+	}
+
+	if len(ro.Channels) != 2 || ro.Channels[1] != o.Channels[1] {
+		t.Fatal("channels do not match")
+	}
+
+	if !strings.Contains(json, `"channel_ids"`) || !strings.Contains(json, `"display_name"`) {
+		t.Fatal("the category fields should be at the top level")
+	}
+
+	list := SidebarCategoryListFromJson(strings.NewReader(SidebarCategoryListToJson([]*SidebarCategoryWithChannels{&o})))
+	if len(list) != 1 || list[0].Id != o.Id {
+		t.Fatal("list did not match")
+	}
+}
+
+func TestSidebarCategoryIsValid(t *testing.T) {
+	o := SidebarCategoryWithChannels{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	o.UserId = NewId()
+	o.TeamId = NewId()
+	o.DisplayName = "Projects"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.DisplayName = strings.Repeat("a", SIDEBAR_CATEGORY_DISPLAY_NAME_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a long display name")
+	}
+
+	o.DisplayName = "Projects"
+	o.Channels = []string{NewId(), "junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a bad channel id")
+	}
+
+	channelId := NewId()
+	o.Channels = []string{channelId, channelId}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a duplicate channel")
+	}
+
+	o.Channels = []string{channelId}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,6 +20,7 @@ type SlackAttachment struct {
 	Footer     string                  `json:"footer"`
 	FooterIcon string                  `json:"footer_icon"`
 	Timestamp  interface{}             `json:"ts"` // This is either a string or an int64
+	Actions    []*PostAction           `json:"actions,omitempty"`
 }
 
 type SlackAttachmentField struct {
@@ -27,3 +28,9 @@ type SlackAttachmentField struct {
 	Value interface{} `json:"value"`
 	Short bool        `json:"short"`
 }
+
+// PostAction is a button rendered below an attachment that the server handles when clicked.
+type PostAction struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SlowQuery is a database query that took longer than SqlSettings.SlowQueryThresholdMilliseconds,
+// with its string literals redacted. Plan is the query's EXPLAIN output once it has been slow
+// SqlSettings.SlowQueryExplainThreshold times.
+type SlowQuery struct {
+	Connection  string `json:"connection"`
+	Operation   string `json:"operation"`
+	Query       string `json:"query"`
+	Count       int64  `json:"count"`
+	TotalMillis int64  `json:"total_millis"`
+	MaxMillis   int64  `json:"max_millis"`
+	FirstSeenAt int64  `json:"first_seen_at"`
+	LastSeenAt  int64  `json:"last_seen_at"`
+	Plan        string `json:"plan,omitempty"`
+}
+
+func SlowQueryListToJson(l []*SlowQuery) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SlowQueryListFromJson(data io.Reader) []*SlowQuery {
+	decoder := json.NewDecoder(data)
+	var o []*SlowQuery
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
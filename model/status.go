@@ -11,18 +11,24 @@ import (
 const (
 	STATUS_OFFLINE         = "offline"
 	STATUS_AWAY            = "away"
+	STATUS_DND             = "dnd"
 	STATUS_ONLINE          = "online"
+	STATUS_OUT_OF_OFFICE   = "ooo"
 	STATUS_CACHE_SIZE      = SESSION_CACHE_SIZE
 	STATUS_CHANNEL_TIMEOUT = 20000  // 20 seconds
 	STATUS_MIN_UPDATE_TIME = 120000 // 2 minutes
 )
 
 type Status struct {
-	UserId         string `json:"user_id"`
-	Status         string `json:"status"`
-	Manual         bool   `json:"manual"`
-	LastActivityAt int64  `json:"last_activity_at"`
-	ActiveChannel  string `json:"-" db:"-"`
+	UserId                string `json:"user_id"`
+	Status                string `json:"status"`
+	Manual                bool   `json:"manual"`
+	LastActivityAt        int64  `json:"last_activity_at"`
+	DNDEndTime            int64  `json:"dnd_end_time"`
+	CustomStatusEmoji     string `json:"custom_status_emoji"`
+	CustomStatusText      string `json:"custom_status_text"`
+	CustomStatusExpiresAt int64  `json:"custom_status_expires_at"`
+	ActiveChannel         string `json:"-" db:"-"`
 }
 
 func (o *Status) ToJson() string {
@@ -45,6 +51,26 @@ func StatusFromJson(data io.Reader) *Status {
 	}
 }
 
+// GetCustomStatus returns the custom status set by the user, or nil if there isn't one.
+func (o *Status) GetCustomStatus() *CustomStatus {
+	if len(o.CustomStatusEmoji) == 0 && len(o.CustomStatusText) == 0 {
+		return nil
+	}
+
+	return &CustomStatus{Emoji: o.CustomStatusEmoji, Text: o.CustomStatusText, ExpiresAt: o.CustomStatusExpiresAt}
+}
+
+// SetCustomStatus replaces the custom status of the user. Passing nil clears it.
+func (o *Status) SetCustomStatus(customStatus *CustomStatus) {
+	if customStatus == nil {
+		customStatus = &CustomStatus{}
+	}
+
+	o.CustomStatusEmoji = customStatus.Emoji
+	o.CustomStatusText = customStatus.Text
+	o.CustomStatusExpiresAt = customStatus.ExpiresAt
+}
+
 func StatusListToJson(u []*Status) string {
 	b, err := json.Marshal(u)
 	if err != nil {
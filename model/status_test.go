@@ -10,7 +10,7 @@ import (
 )
 
 func TestStatus(t *testing.T) {
-	status := Status{NewId(), STATUS_ONLINE, true, 0, ""}
+	status := Status{NewId(), STATUS_ONLINE, true, 0, 0, "", "", 0, ""}
 	json := status.ToJson()
 	status2 := StatusFromJson(strings.NewReader(json))
 
@@ -32,7 +32,7 @@ func TestStatus(t *testing.T) {
 }
 
 func TestStatusListToJson(t *testing.T) {
-	statuses := []*Status{{NewId(), STATUS_ONLINE, true, 0, ""}, {NewId(), STATUS_OFFLINE, true, 0, ""}}
+	statuses := []*Status{{NewId(), STATUS_ONLINE, true, 0, 0, "", "", 0, ""}, {NewId(), STATUS_OFFLINE, true, 0, 0, "", "", 0, ""}}
 	jsonStatuses := StatusListToJson(statuses)
 
 	var dat []map[string]interface{}
@@ -71,3 +71,21 @@ func TestStatusListFromJson(t *testing.T) {
 		t.Fatal("UserId should be equal")
 	}
 }
+
+func TestStatusCustomStatus(t *testing.T) {
+	status := Status{UserId: NewId(), Status: STATUS_ONLINE}
+
+	if status.GetCustomStatus() != nil {
+		t.Fatal("should not have a custom status")
+	}
+
+	status.SetCustomStatus(&CustomStatus{Emoji: "palm_tree", Text: "On vacation", ExpiresAt: 1234})
+	if customStatus := status.GetCustomStatus(); customStatus == nil || customStatus.Emoji != "palm_tree" || customStatus.Text != "On vacation" || customStatus.ExpiresAt != 1234 {
+		t.Fatal("should have set the custom status")
+	}
+
+	status.SetCustomStatus(nil)
+	if status.GetCustomStatus() != nil || status.CustomStatusExpiresAt != 0 {
+		t.Fatal("should have cleared the custom status")
+	}
+}
@@ -9,11 +9,17 @@ import (
 )
 
 const (
-	SYSTEM_DIAGNOSTIC_ID        = "DiagnosticId"
-	SYSTEM_RAN_UNIT_TESTS       = "RanUnitTests"
-	SYSTEM_LAST_SECURITY_TIME   = "LastSecurityTime"
-	SYSTEM_ACTIVE_LICENSE_ID    = "ActiveLicenseId"
-	SYSTEM_LAST_COMPLIANCE_TIME = "LastComplianceTime"
+	SYSTEM_DIAGNOSTIC_ID                 = "DiagnosticId"
+	SYSTEM_RAN_UNIT_TESTS                = "RanUnitTests"
+	SYSTEM_LAST_SECURITY_TIME            = "LastSecurityTime"
+	SYSTEM_ACTIVE_LICENSE_ID             = "ActiveLicenseId"
+	SYSTEM_LAST_COMPLIANCE_TIME          = "LastComplianceTime"
+	SYSTEM_LAST_INDEXED_POSTS_TIME       = "LastIndexedPostsTime"
+	SYSTEM_LAST_INDEXED_FILES_TIME       = "LastIndexedFilesTime"
+	SYSTEM_LAST_INDEXED_USERS_TIME       = "LastIndexedUsersTime"
+	SYSTEM_OPENID_SIGNING_KEY            = "OpenIdSigningKey"
+	SYSTEM_LAST_DATA_RETENTION_RUN       = "LastDataRetentionRun"
+	SYSTEM_LAST_CHANNEL_AUTO_ARCHIVE_RUN = "LastChannelAutoArchiveRun"
 )
 
 type System struct {
@@ -0,0 +1,173 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	TEAM_MEMBERS_BATCH_ACTION_ADD          = "add"
+	TEAM_MEMBERS_BATCH_ACTION_REMOVE       = "remove"
+	TEAM_MEMBERS_BATCH_ACTION_UPDATE_ROLES = "update_roles"
+
+	TEAM_MEMBERS_BATCH_JOB_STATUS_CREATED  = "created"
+	TEAM_MEMBERS_BATCH_JOB_STATUS_RUNNING  = "running"
+	TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED = "finished"
+	TEAM_MEMBERS_BATCH_JOB_STATUS_FAILED   = "failed"
+
+	TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH  = 1024
+	TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES      = 500
+	TEAM_MEMBERS_BATCH_JOB_FAILURES_MAX_SIZE = 65535
+)
+
+// TeamMembersBatch is the request body of the batch team member endpoints.
+type TeamMembersBatch struct {
+	UserIds []string `json:"user_ids"`
+	Roles   string   `json:"roles"`
+}
+
+// TeamMembersBatchJob tracks the processing of a batch of team member changes and reports
+// its result. Failures maps the id of each user that could not be processed to the id of
+// the error, and holds at most TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES entries.
+type TeamMembersBatchJob struct {
+	Id          string    `json:"id"`
+	CreatorId   string    `json:"creator_id"`
+	TeamId      string    `json:"team_id"`
+	Action      string    `json:"action"`
+	Roles       string    `json:"roles"`
+	Status      string    `json:"status"`
+	Total       int       `json:"total"`
+	Succeeded   int       `json:"succeeded"`
+	FailedCount int       `json:"failed_count"`
+	Failures    StringMap `json:"failures"`
+	Error       string    `json:"error"`
+	CreateAt    int64     `json:"create_at"`
+	UpdateAt    int64     `json:"update_at"`
+}
+
+func (o *TeamMembersBatch) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamMembersBatchFromJson(data io.Reader) *TeamMembersBatch {
+	decoder := json.NewDecoder(data)
+	var o TeamMembersBatch
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *TeamMembersBatchJob) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Action {
+	case TEAM_MEMBERS_BATCH_ACTION_ADD, TEAM_MEMBERS_BATCH_ACTION_REMOVE, TEAM_MEMBERS_BATCH_ACTION_UPDATE_ROLES:
+	default:
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.action.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Roles) > 64 || (len(o.Roles) > 0 && !IsValidUserRoles(o.Roles)) {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.roles.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Status {
+	case TEAM_MEMBERS_BATCH_JOB_STATUS_CREATED, TEAM_MEMBERS_BATCH_JOB_STATUS_RUNNING, TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED, TEAM_MEMBERS_BATCH_JOB_STATUS_FAILED:
+	default:
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Failures) > TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.failures.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Error) > TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH {
+		return NewAppError("TeamMembersBatchJob.IsValid", "model.team_members_batch_job.is_valid.error.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *TeamMembersBatchJob) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Status == "" {
+		o.Status = TEAM_MEMBERS_BATCH_JOB_STATUS_CREATED
+	}
+
+	if o.Failures == nil {
+		o.Failures = StringMap{}
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *TeamMembersBatchJob) PreUpdate() {
+	if len(o.Error) > TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH {
+		o.Error = o.Error[:TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH]
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+// AddFailure records that the given user could not be processed. Only the first
+// TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES failures are kept in the report, but all are counted.
+func (o *TeamMembersBatchJob) AddFailure(userId string, errorId string) {
+	if o.Failures == nil {
+		o.Failures = StringMap{}
+	}
+
+	if len(o.Failures) < TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES {
+		o.Failures[userId] = errorId
+	}
+
+	o.FailedCount++
+}
+
+func (o *TeamMembersBatchJob) IsDone() bool {
+	return o.Status == TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED || o.Status == TEAM_MEMBERS_BATCH_JOB_STATUS_FAILED
+}
+
+func (o *TeamMembersBatchJob) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamMembersBatchJobFromJson(data io.Reader) *TeamMembersBatchJob {
+	decoder := json.NewDecoder(data)
+	var o TeamMembersBatchJob
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeamMembersBatchJson(t *testing.T) {
+	o := TeamMembersBatch{UserIds: []string{NewId(), NewId()}, Roles: "team_user team_admin"}
+	json := o.ToJson()
+	ro := TeamMembersBatchFromJson(strings.NewReader(json))
+
+	if len(ro.UserIds) != 2 || ro.UserIds[1] != o.UserIds[1] || ro.Roles != o.Roles {
+		t.Fatal("batches do not match")
+	}
+}
+
+func TestTeamMembersBatchJobIsValid(t *testing.T) {
+	o := TeamMembersBatchJob{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	o.CreatorId = NewId()
+	o.TeamId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without an action")
+	}
+
+	o.Action = TEAM_MEMBERS_BATCH_ACTION_UPDATE_ROLES
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Status != TEAM_MEMBERS_BATCH_JOB_STATUS_CREATED {
+		t.Fatal("should default to created")
+	}
+
+	o.Roles = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with unknown roles")
+	}
+
+	o.Roles = "team_user team_admin"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Error = strings.Repeat("a", TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH+1)
+	o.PreUpdate()
+	if err := o.IsValid(); err != nil {
+		t.Fatal("error should have been truncated")
+	}
+}
+
+func TestTeamMembersBatchJobAddFailure(t *testing.T) {
+	o := TeamMembersBatchJob{}
+
+	for i := 0; i < TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES+10; i++ {
+		o.AddFailure(NewId(), "some.app_error")
+	}
+
+	if len(o.Failures) != TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES {
+		t.Fatal("should cap the reported failures")
+	}
+
+	if o.FailedCount != TEAM_MEMBERS_BATCH_JOB_MAX_FAILURES+10 {
+		t.Fatal("should count every failure")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// TeamQuota caps what a team can use. A limit of zero means that there's no limit.
+type TeamQuota struct {
+	TeamId           string `json:"team_id"`
+	MaxPostsPerMonth int64  `json:"max_posts_per_month"`
+	MaxStorageBytes  int64  `json:"max_storage_bytes"`
+	MaxIntegrations  int64  `json:"max_integrations"`
+	UpdateAt         int64  `json:"update_at"`
+}
+
+// TeamUsage is what a team has used so far. PostCount only counts the posts made in Month, which is
+// formatted like 2017-10, and starts over every month. IntegrationCount is counted when it's asked for
+// rather than kept in the database.
+type TeamUsage struct {
+	TeamId           string `json:"team_id"`
+	Month            string `json:"month"`
+	PostCount        int64  `json:"post_count"`
+	StorageBytes     int64  `json:"storage_bytes"`
+	IntegrationCount int64  `json:"integration_count" db:"-"`
+	UpdateAt         int64  `json:"update_at"`
+}
+
+// CurrentUsageMonth returns the month that posts are being counted for.
+func CurrentUsageMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
+
+func (o *TeamQuota) IsValid() *AppError {
+	if len(o.TeamId) != 26 {
+		return NewAppError("TeamQuota.IsValid", "model.team_quota.is_valid.team_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.MaxPostsPerMonth < 0 {
+		return NewAppError("TeamQuota.IsValid", "model.team_quota.is_valid.max_posts_per_month.app_error", nil, "team_id="+o.TeamId, http.StatusBadRequest)
+	}
+
+	if o.MaxStorageBytes < 0 {
+		return NewAppError("TeamQuota.IsValid", "model.team_quota.is_valid.max_storage_bytes.app_error", nil, "team_id="+o.TeamId, http.StatusBadRequest)
+	}
+
+	if o.MaxIntegrations < 0 {
+		return NewAppError("TeamQuota.IsValid", "model.team_quota.is_valid.max_integrations.app_error", nil, "team_id="+o.TeamId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *TeamQuota) PreSave() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *TeamQuota) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamQuotaFromJson(data io.Reader) *TeamQuota {
+	decoder := json.NewDecoder(data)
+	var o TeamQuota
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *TeamUsage) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamUsageFromJson(data io.Reader) *TeamUsage {
+	decoder := json.NewDecoder(data)
+	var o TeamUsage
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
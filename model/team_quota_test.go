@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeamQuotaJson(t *testing.T) {
+	o := TeamQuota{TeamId: NewId(), MaxPostsPerMonth: 1000, MaxStorageBytes: 1 << 30}
+	ro := TeamQuotaFromJson(strings.NewReader(o.ToJson()))
+
+	if *ro != o {
+		t.Fatal("quotas do not match")
+	}
+
+	u := TeamUsage{TeamId: o.TeamId, Month: CurrentUsageMonth(), PostCount: 5, IntegrationCount: 2}
+	ru := TeamUsageFromJson(strings.NewReader(u.ToJson()))
+
+	if *ru != u {
+		t.Fatal("usage does not match")
+	}
+}
+
+func TestTeamQuotaIsValid(t *testing.T) {
+	o := TeamQuota{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.TeamId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.MaxPostsPerMonth = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a negative limit")
+	}
+
+	o.MaxPostsPerMonth = 0
+	o.MaxStorageBytes = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a negative limit")
+	}
+
+	o.MaxStorageBytes = 0
+	o.MaxIntegrations = -1
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with a negative limit")
+	}
+}
+
+func TestCurrentUsageMonth(t *testing.T) {
+	if month := CurrentUsageMonth(); len(month) != 7 || month[4] != '-' {
+		t.Fatal("month should be formatted like 2017-10")
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	TEAM_TEMPLATE_NAME_MAX_RUNES        = 64
+	TEAM_TEMPLATE_DESCRIPTION_MAX_RUNES = 1024
+	TEAM_TEMPLATE_CONTENT_MAX_SIZE      = 65535
+
+	TEAM_CLONE_JOB_STATUS_CREATED  = "created"
+	TEAM_CLONE_JOB_STATUS_RUNNING  = "running"
+	TEAM_CLONE_JOB_STATUS_FINISHED = "finished"
+	TEAM_CLONE_JOB_STATUS_FAILED   = "failed"
+
+	TEAM_CLONE_JOB_ERROR_MAX_LENGTH = 1024
+)
+
+// TeamTemplate is a reusable copy of a team's structure that new teams can be created from.
+type TeamTemplate struct {
+	Id           string              `json:"id"`
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	CreatorId    string              `json:"creator_id"`
+	SourceTeamId string              `json:"source_team_id"`
+	Content      TeamTemplateContent `json:"content"`
+	CreateAt     int64               `json:"create_at"`
+}
+
+// TeamTemplateContent describes the structure of a team. Channels are referenced by name since
+// channel ids differ between teams, and integrations are stored without their tokens so that a new
+// secret is generated for every team created from the template.
+type TeamTemplateContent struct {
+	Type             string                         `json:"type"`
+	Description      string                         `json:"description"`
+	AllowOpenInvite  bool                           `json:"allow_open_invite"`
+	AllowedDomains   string                         `json:"allowed_domains"`
+	Channels         []*TeamTemplateChannel         `json:"channels"`
+	AdminUserIds     StringArray                    `json:"admin_user_ids"`
+	IncomingWebhooks []*TeamTemplateIncomingWebhook `json:"incoming_webhooks"`
+	OutgoingWebhooks []*TeamTemplateOutgoingWebhook `json:"outgoing_webhooks"`
+	Commands         []*TeamTemplateCommand         `json:"commands"`
+}
+
+type TeamTemplateChannel struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	Header      string `json:"header"`
+	Purpose     string `json:"purpose"`
+}
+
+type TeamTemplateIncomingWebhook struct {
+	ChannelName string `json:"channel_name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+type TeamTemplateOutgoingWebhook struct {
+	ChannelName  string      `json:"channel_name"`
+	TriggerWords StringArray `json:"trigger_words"`
+	TriggerWhen  int         `json:"trigger_when"`
+	CallbackURLs StringArray `json:"callback_urls"`
+	DisplayName  string      `json:"display_name"`
+	Description  string      `json:"description"`
+	ContentType  string      `json:"content_type"`
+}
+
+type TeamTemplateCommand struct {
+	Trigger          string `json:"trigger"`
+	Method           string `json:"method"`
+	Username         string `json:"username"`
+	IconURL          string `json:"icon_url"`
+	AutoComplete     bool   `json:"auto_complete"`
+	AutoCompleteDesc string `json:"auto_complete_desc"`
+	AutoCompleteHint string `json:"auto_complete_hint"`
+	DisplayName      string `json:"display_name"`
+	Description      string `json:"description"`
+	URL              string `json:"url"`
+}
+
+// TeamCloneJob tracks the copy of a team or template's structure into a newly created team.
+type TeamCloneJob struct {
+	Id           string `json:"id"`
+	CreatorId    string `json:"creator_id"`
+	SourceTeamId string `json:"source_team_id"`
+	TemplateId   string `json:"template_id"`
+	TeamId       string `json:"team_id"`
+	Status       string `json:"status"`
+	Error        string `json:"error"`
+	CreateAt     int64  `json:"create_at"`
+	UpdateAt     int64  `json:"update_at"`
+}
+
+func (o *TeamTemplate) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.SourceTeamId) != 0 && len(o.SourceTeamId) != 26 {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.source_team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Name) == 0 || utf8.RuneCountInString(o.Name) > TEAM_TEMPLATE_NAME_MAX_RUNES {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Description) > TEAM_TEMPLATE_DESCRIPTION_MAX_RUNES {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.description.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Content.ToJson()) > TEAM_TEMPLATE_CONTENT_MAX_SIZE {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.content.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("TeamTemplate.IsValid", "model.team_template.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *TeamTemplate) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *TeamTemplate) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamTemplateFromJson(data io.Reader) *TeamTemplate {
+	decoder := json.NewDecoder(data)
+	var o TeamTemplate
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func TeamTemplateListToJson(l []*TeamTemplate) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamTemplateListFromJson(data io.Reader) []*TeamTemplate {
+	decoder := json.NewDecoder(data)
+	var o []*TeamTemplate
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o TeamTemplateContent) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func (o *TeamCloneJob) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.creator_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.team_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	// a job copies either a team or a template, never both
+	fromTeam := len(o.SourceTeamId) == 26 && len(o.TemplateId) == 0
+	fromTemplate := len(o.SourceTeamId) == 0 && len(o.TemplateId) == 26
+	if !fromTeam && !fromTemplate {
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.source.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	switch o.Status {
+	case TEAM_CLONE_JOB_STATUS_CREATED, TEAM_CLONE_JOB_STATUS_RUNNING, TEAM_CLONE_JOB_STATUS_FINISHED, TEAM_CLONE_JOB_STATUS_FAILED:
+	default:
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.status.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Error) > TEAM_CLONE_JOB_ERROR_MAX_LENGTH {
+		return NewAppError("TeamCloneJob.IsValid", "model.team_clone_job.is_valid.error.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *TeamCloneJob) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Status == "" {
+		o.Status = TEAM_CLONE_JOB_STATUS_CREATED
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *TeamCloneJob) PreUpdate() {
+	if len(o.Error) > TEAM_CLONE_JOB_ERROR_MAX_LENGTH {
+		o.Error = o.Error[:TEAM_CLONE_JOB_ERROR_MAX_LENGTH]
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+func (o *TeamCloneJob) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func TeamCloneJobFromJson(data io.Reader) *TeamCloneJob {
+	decoder := json.NewDecoder(data)
+	var o TeamCloneJob
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
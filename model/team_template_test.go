@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeamTemplateJson(t *testing.T) {
+	o := TeamTemplate{Id: NewId(), Name: "Support"}
+	o.Content.Channels = []*TeamTemplateChannel{{Name: "triage", DisplayName: "Triage", Type: CHANNEL_OPEN}}
+	json := o.ToJson()
+	ro := TeamTemplateFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || len(ro.Content.Channels) != 1 || ro.Content.Channels[0].Name != "triage" {
+		t.Fatal("templates do not match")
+	}
+}
+
+func TestTeamTemplateIsValid(t *testing.T) {
+	o := TeamTemplate{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CreatorId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Name = "Support"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.CreateAt = GetMillis()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.SourceTeamId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.SourceTeamId = NewId()
+	o.Content.Description = strings.Repeat("a", TEAM_TEMPLATE_CONTENT_MAX_SIZE)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid when the content is too large")
+	}
+}
+
+func TestTeamCloneJobIsValid(t *testing.T) {
+	o := TeamCloneJob{Id: NewId(), CreatorId: NewId(), TeamId: NewId(), Status: TEAM_CLONE_JOB_STATUS_CREATED}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without a source")
+	}
+
+	o.SourceTeamId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.TemplateId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid with both sources")
+	}
+
+	o.SourceTeamId = ""
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestTeamCloneJobPreUpdate(t *testing.T) {
+	o := TeamCloneJob{Error: strings.Repeat("a", TEAM_CLONE_JOB_ERROR_MAX_LENGTH+10)}
+	o.PreUpdate()
+
+	if len(o.Error) != TEAM_CLONE_JOB_ERROR_MAX_LENGTH {
+		t.Fatal("should have truncated the error")
+	}
+}
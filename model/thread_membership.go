@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ThreadMembership tracks a user's relationship with the thread started by a root post. Users
+// follow the threads they reply to or are mentioned in, and mentions in replies are counted
+// against the thread rather than the channel.
+type ThreadMembership struct {
+	PostId       string `json:"post_id"`
+	UserId       string `json:"user_id"`
+	Following    bool   `json:"following"`
+	LastViewedAt int64  `json:"last_viewed_at"`
+	MentionCount int64  `json:"mention_count"`
+	UpdateAt     int64  `json:"update_at"`
+}
+
+// ThreadUnread counts the replies a user hasn't read yet in a thread they follow.
+type ThreadUnread struct {
+	PostId        string `json:"post_id"`
+	ChannelId     string `json:"channel_id"`
+	UnreadReplies int64  `json:"unread_replies"`
+	MentionCount  int64  `json:"mention_count"`
+}
+
+func (o *ThreadMembership) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.user_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.update_at.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ThreadMembership) PreSave() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *ThreadMembership) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadMembershipFromJson(data io.Reader) *ThreadMembership {
+	decoder := json.NewDecoder(data)
+	var o ThreadMembership
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ThreadUnreadListToJson(l []*ThreadUnread) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadUnreadListFromJson(data io.Reader) []*ThreadUnread {
+	decoder := json.NewDecoder(data)
+	var o []*ThreadUnread
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThreadMembershipJson(t *testing.T) {
+	o := ThreadMembership{PostId: NewId(), UserId: NewId(), Following: true}
+	json := o.ToJson()
+	ro := ThreadMembershipFromJson(strings.NewReader(json))
+
+	if o != *ro {
+		t.Fatal("memberships do not match")
+	}
+}
+
+func TestThreadMembershipIsValid(t *testing.T) {
+	o := ThreadMembership{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PostId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -65,6 +65,7 @@ type User struct {
 	Locale             string    `json:"locale"`
 	MfaActive          bool      `json:"mfa_active,omitempty"`
 	MfaSecret          string    `json:"mfa_secret,omitempty"`
+	MfaBackupCodes     string    `json:"mfa_backup_codes,omitempty"`
 	LastActivityAt     int64     `db:"-" json:"last_activity_at,omitempty"`
 }
 
@@ -302,6 +303,7 @@ func (u *User) Sanitize(options map[string]bool) {
 	u.AuthData = new(string)
 	*u.AuthData = ""
 	u.MfaSecret = ""
+	u.MfaBackupCodes = ""
 
 	if len(options) != 0 && !options["email"] {
 		u.Email = ""
@@ -323,6 +325,7 @@ func (u *User) ClearNonProfileFields() {
 	u.AuthData = new(string)
 	*u.AuthData = ""
 	u.MfaSecret = ""
+	u.MfaBackupCodes = ""
 	u.EmailVerified = false
 	u.AllowMarketing = false
 	u.Props = StringMap{}
@@ -423,6 +426,11 @@ func IsValidUserRoles(userRoles string) bool {
 		return false
 	}
 
+	// Guests can't have any of the other system roles
+	if len(roles) > 1 && IsInRole(userRoles, "system_guest") {
+		return false
+	}
+
 	return true
 }
 
@@ -452,6 +460,11 @@ func IsInRole(userRoles string, inRole string) bool {
 	return false
 }
 
+// IsGuest returns true if the user can only access the channels they've been added to.
+func (u *User) IsGuest() bool {
+	return IsInRole(u.Roles, ROLE_SYSTEM_GUEST.Id)
+}
+
 func (u *User) IsSSOUser() bool {
 	if u.AuthService != "" && u.AuthService != USER_AUTH_SERVICE_EMAIL {
 		return true
@@ -460,7 +473,7 @@ func (u *User) IsSSOUser() bool {
 }
 
 func (u *User) IsOAuthUser() bool {
-	if u.AuthService == USER_AUTH_SERVICE_GITLAB {
+	if u.AuthService == USER_AUTH_SERVICE_GITLAB || u.AuthService == USER_AUTH_SERVICE_OPENID {
 		return true
 	}
 	return false
@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	USER_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES = 255
+	USER_ACCESS_TOKEN_LAST_USED_INTERVAL    = 5 * 60 * 1000 // 5 minutes
+)
+
+// UserAccessToken is a long lived token that authenticates as its user without logging in. A
+// session that never expires is created for the token the first time it is used. The token itself
+// is only returned on creation.
+type UserAccessToken struct {
+	Id          string `json:"id"`
+	Token       string `json:"token,omitempty"`
+	UserId      string `json:"user_id"`
+	Description string `json:"description"`
+	IsActive    bool   `json:"is_active"`
+	CreateAt    int64  `json:"create_at"`
+	LastUsedAt  int64  `json:"last_used_at"`
+}
+
+func (o *UserAccessToken) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.Token) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.token.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Description) > USER_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.description.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("UserAccessToken.IsValid", "model.user_access_token.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *UserAccessToken) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.IsActive = true
+	o.CreateAt = GetMillis()
+	o.LastUsedAt = 0
+}
+
+func (o *UserAccessToken) Sanitize() {
+	o.Token = ""
+}
+
+func (o *UserAccessToken) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UserAccessTokenFromJson(data io.Reader) *UserAccessToken {
+	decoder := json.NewDecoder(data)
+	var o UserAccessToken
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func UserAccessTokenListToJson(l []*UserAccessToken) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UserAccessTokenListFromJson(data io.Reader) []*UserAccessToken {
+	decoder := json.NewDecoder(data)
+	var o []*UserAccessToken
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
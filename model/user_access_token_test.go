@@ -0,0 +1,52 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserAccessTokenJson(t *testing.T) {
+	o := UserAccessToken{Id: NewId(), Token: NewId(), UserId: NewId(), Description: "token"}
+	json := o.ToJson()
+	ro := UserAccessTokenFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Token != ro.Token || o.Description != ro.Description {
+		t.Fatal("tokens do not match")
+	}
+
+	ro.Sanitize()
+	if ro.Token != "" {
+		t.Fatal("should have removed the token")
+	}
+}
+
+func TestUserAccessTokenIsValid(t *testing.T) {
+	o := UserAccessToken{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Token = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Description = strings.Repeat("a", USER_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	USER_CREDENTIAL_NAME_MAX_RUNES      = 64
+	USER_CREDENTIAL_CREDENTIAL_ID_SIZE  = 1400
+	USER_CREDENTIAL_PUBLIC_KEY_MAX_SIZE = 2048
+)
+
+// UserCredential is a security key registered by a user with WebAuthn. CredentialId is the id the
+// authenticator gave the key and PublicKey is its COSE encoded public key, both base64 encoded.
+type UserCredential struct {
+	Id           string `json:"id"`
+	UserId       string `json:"user_id"`
+	Name         string `json:"name"`
+	CredentialId string `json:"credential_id"`
+	PublicKey    string `json:"public_key,omitempty"`
+	SignCount    int64  `json:"sign_count"`
+	CreateAt     int64  `json:"create_at"`
+	LastUsedAt   int64  `json:"last_used_at"`
+}
+
+func (o *UserCredential) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Name) > USER_CREDENTIAL_NAME_MAX_RUNES {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.name.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.CredentialId) == 0 || len(o.CredentialId) > USER_CREDENTIAL_CREDENTIAL_ID_SIZE {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.credential_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.PublicKey) == 0 || len(o.PublicKey) > USER_CREDENTIAL_PUBLIC_KEY_MAX_SIZE {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.public_key.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("UserCredential.IsValid", "model.user_credential.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *UserCredential) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+	o.LastUsedAt = 0
+}
+
+func (o *UserCredential) Sanitize() {
+	o.PublicKey = ""
+}
+
+func (o *UserCredential) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UserCredentialFromJson(data io.Reader) *UserCredential {
+	decoder := json.NewDecoder(data)
+	var o UserCredential
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func UserCredentialListToJson(l []*UserCredential) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UserCredentialListFromJson(data io.Reader) []*UserCredential {
+	decoder := json.NewDecoder(data)
+	var o []*UserCredential
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
@@ -245,6 +245,14 @@ func TestRoles(t *testing.T) {
 		t.Fatal()
 	}
 
+	if !IsValidUserRoles("system_guest") {
+		t.Fatal()
+	}
+
+	if IsValidUserRoles("system_guest system_user") {
+		t.Fatal()
+	}
+
 	if IsInRole("system_admin junk", "admin") {
 		t.Fatal()
 	}
@@ -257,3 +265,15 @@ func TestRoles(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestUserIsGuest(t *testing.T) {
+	user := User{Roles: ROLE_SYSTEM_USER.Id}
+	if user.IsGuest() {
+		t.Fatal("should not be a guest")
+	}
+
+	user.Roles = ROLE_SYSTEM_GUEST.Id
+	if !user.IsGuest() {
+		t.Fatal("should be a guest")
+	}
+}
@@ -11,10 +11,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	mathrand "math/rand"
 	"net/mail"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	goi18n "github.com/nicksnyder/go-i18n/i18n"
@@ -125,12 +128,60 @@ var encoding = base32.NewEncoding("ybndrfg8ejkmcpqxot1uwisza345h769")
 func NewId() string {
 	var b bytes.Buffer
 	encoder := base32.NewEncoder(encoding, &b)
-	encoder.Write(uuid.NewRandom())
+	encoder.Write(newIdBytes())
 	encoder.Close()
 	b.Truncate(26) // removes the '==' padding
 	return b.String()
 }
 
+// seededIds, when set, replaces the random UUIDs used by NewId with a repeatable sequence. Since a
+// seed is rarely set, NewId only takes the lock when isSet says that one is.
+var seededIds struct {
+	sync.Mutex
+	isSet  int32
+	source *mathrand.Rand
+}
+
+// SetIdSeed makes NewId return the same sequence of ids every time it is given the same seed so
+// that test runs and imports can be replayed. The ids are predictable, so this is only meant for
+// developer and testing use.
+func SetIdSeed(seed int64) {
+	seededIds.Lock()
+	seededIds.source = mathrand.New(mathrand.NewSource(seed))
+	atomic.StoreInt32(&seededIds.isSet, 1)
+	seededIds.Unlock()
+}
+
+// ClearIdSeed returns NewId to generating random ids.
+func ClearIdSeed() {
+	seededIds.Lock()
+	atomic.StoreInt32(&seededIds.isSet, 0)
+	seededIds.source = nil
+	seededIds.Unlock()
+}
+
+func newIdBytes() []byte {
+	if atomic.LoadInt32(&seededIds.isSet) == 0 {
+		return uuid.NewRandom()
+	}
+
+	seededIds.Lock()
+	defer seededIds.Unlock()
+
+	if seededIds.source == nil {
+		return uuid.NewRandom()
+	}
+
+	b := make([]byte, 16)
+	seededIds.source.Read(b)
+
+	// Set the version and variant bits so the ids look like the version 4 UUIDs used otherwise
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return b
+}
+
 func NewRandomString(length int) string {
 	var b bytes.Buffer
 	str := make([]byte, length+8)
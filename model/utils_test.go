@@ -17,6 +17,35 @@ func TestNewId(t *testing.T) {
 	}
 }
 
+func TestNewIdWithSeed(t *testing.T) {
+	defer ClearIdSeed()
+
+	SetIdSeed(42)
+	first := []string{NewId(), NewId(), NewId()}
+
+	SetIdSeed(42)
+	for i := range first {
+		if id := NewId(); id != first[i] {
+			t.Fatal("seeded ids should be repeatable")
+		} else if len(id) != 26 || !IsValidAlphaNum(id, false) {
+			t.Fatal("seeded ids should be valid")
+		}
+	}
+
+	SetIdSeed(43)
+	if NewId() == first[0] {
+		t.Fatal("different seeds should generate different ids")
+	}
+
+	ClearIdSeed()
+	SetIdSeed(42)
+	NewId()
+	ClearIdSeed()
+	if NewId() == first[1] {
+		t.Fatal("ids should be random once the seed is cleared")
+	}
+}
+
 func TestRandomString(t *testing.T) {
 	for i := 0; i < 1000; i++ {
 		r := NewRandomString(32)
@@ -5,6 +5,7 @@ package model
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -136,3 +137,24 @@ func IsPreviousVersionsSupported(versionToCheck string) bool {
 
 	return false
 }
+
+var validVersion = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// IsValidVersion returns true if the version is a plain major.minor or major.minor.patch version.
+func IsValidVersion(version string) bool {
+	return validVersion.MatchString(version)
+}
+
+// IsVersionAtLeast returns true if the version is the same as or newer than minVersion.
+func IsVersionAtLeast(version string, minVersion string) bool {
+	major, minor, patch := SplitVersion(version)
+	minMajor, minMinor, minPatch := SplitVersion(minVersion)
+
+	if major != minMajor {
+		return major > minMajor
+	} else if minor != minMinor {
+		return minor > minMinor
+	} else {
+		return patch >= minPatch
+	}
+}
@@ -0,0 +1,644 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	WEBAUTHN_CHALLENGE_SIZE = 32
+	WEBAUTHN_TIMEOUT        = 60000 // 1 minute
+
+	WEBAUTHN_CEREMONY_CREATE = "webauthn.create"
+	WEBAUTHN_CEREMONY_GET    = "webauthn.get"
+
+	COSE_ALGORITHM_ES256 = -7
+	COSE_ALGORITHM_RS256 = -257
+
+	webAuthnFlagUserPresent            = 0x01
+	webAuthnFlagAttestedCredentialData = 0x40
+
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+	coseCurveP256  = 1
+
+	cborMaxDepth = 16
+)
+
+var webAuthnEncoding = base64.RawURLEncoding
+
+// WebAuthnCreationOptions are the options a client passes to navigator.credentials.create to register
+// a security key. The fields are named as in the WebAuthn specification with binary values base64url
+// encoded.
+type WebAuthnCreationOptions struct {
+	Challenge          string                         `json:"challenge"`
+	RelyingParty       WebAuthnRelyingParty           `json:"rp"`
+	User               WebAuthnUser                   `json:"user"`
+	Parameters         []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout            int                            `json:"timeout"`
+	ExcludeCredentials []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	Attestation        string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions are the options a client passes to navigator.credentials.get to log in with
+// one of the user's security keys.
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	Timeout          int                            `json:"timeout"`
+	RelyingPartyId   string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+}
+
+type WebAuthnRelyingParty struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUser struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int    `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+// WebAuthnAttestation is the response of a security key to navigator.credentials.create, along with
+// the name the user gave the key. Binary values are base64url encoded.
+type WebAuthnAttestation struct {
+	Name              string `json:"name"`
+	ClientDataJSON    string `json:"client_data_json"`
+	AttestationObject string `json:"attestation_object"`
+}
+
+// WebAuthnAssertion is the response of a security key to navigator.credentials.get. Binary values
+// are base64url encoded.
+type WebAuthnAssertion struct {
+	Id                string `json:"id"`
+	ClientDataJSON    string `json:"client_data_json"`
+	AuthenticatorData string `json:"authenticator_data"`
+	Signature         string `json:"signature"`
+}
+
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type webAuthnAuthenticatorData struct {
+	RelyingPartyIdHash []byte
+	Flags              byte
+	SignCount          uint32
+	CredentialId       []byte
+	PublicKey          []byte
+}
+
+func NewWebAuthnChallenge() string {
+	b := make([]byte, WEBAUTHN_CHALLENGE_SIZE)
+	rand.Read(b)
+	return webAuthnEncoding.EncodeToString(b)
+}
+
+// NewWebAuthnCreationOptions returns the options for registering a new security key for a user. The
+// user's existing keys are excluded so that the same key isn't registered twice.
+func NewWebAuthnCreationOptions(challenge, rpId, siteName string, user *User, credentials []*UserCredential) *WebAuthnCreationOptions {
+	options := &WebAuthnCreationOptions{
+		Challenge:    challenge,
+		RelyingParty: WebAuthnRelyingParty{Id: rpId, Name: siteName},
+		User: WebAuthnUser{
+			Id:          webAuthnEncoding.EncodeToString([]byte(user.Id)),
+			Name:        user.Username,
+			DisplayName: user.GetDisplayName(),
+		},
+		Parameters: []WebAuthnCredentialParameter{
+			{Type: "public-key", Algorithm: COSE_ALGORITHM_ES256},
+			{Type: "public-key", Algorithm: COSE_ALGORITHM_RS256},
+		},
+		Timeout:            WEBAUTHN_TIMEOUT,
+		ExcludeCredentials: []WebAuthnCredentialDescriptor{},
+		Attestation:        "none",
+	}
+
+	for _, credential := range credentials {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, NewWebAuthnCredentialDescriptor(credential))
+	}
+
+	return options
+}
+
+// NewWebAuthnRequestOptions returns the options for logging in with one of a user's security keys.
+func NewWebAuthnRequestOptions(challenge, rpId string, credentials []*UserCredential) *WebAuthnRequestOptions {
+	options := &WebAuthnRequestOptions{
+		Challenge:        challenge,
+		Timeout:          WEBAUTHN_TIMEOUT,
+		RelyingPartyId:   rpId,
+		AllowCredentials: []WebAuthnCredentialDescriptor{},
+	}
+
+	for _, credential := range credentials {
+		options.AllowCredentials = append(options.AllowCredentials, NewWebAuthnCredentialDescriptor(credential))
+	}
+
+	return options
+}
+
+// NewWebAuthnCredentialDescriptor describes a registered security key to a client.
+func NewWebAuthnCredentialDescriptor(credential *UserCredential) WebAuthnCredentialDescriptor {
+	return WebAuthnCredentialDescriptor{Type: "public-key", Id: credential.CredentialId}
+}
+
+func (o *WebAuthnCreationOptions) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebAuthnCreationOptionsFromJson(data io.Reader) *WebAuthnCreationOptions {
+	decoder := json.NewDecoder(data)
+	var o WebAuthnCreationOptions
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *WebAuthnRequestOptions) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebAuthnRequestOptionsFromJson(data io.Reader) *WebAuthnRequestOptions {
+	decoder := json.NewDecoder(data)
+	var o WebAuthnRequestOptions
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *WebAuthnAttestation) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebAuthnAttestationFromJson(data io.Reader) *WebAuthnAttestation {
+	decoder := json.NewDecoder(data)
+	var o WebAuthnAttestation
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *WebAuthnAssertion) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebAuthnAssertionFromJson(data io.Reader) *WebAuthnAssertion {
+	decoder := json.NewDecoder(data)
+	var o WebAuthnAssertion
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// GetChallenge returns the challenge that the assertion says it answers, without verifying it, so
+// that the challenge can be looked up. It returns an empty string if the client data is malformed.
+func (o *WebAuthnAssertion) GetChallenge() string {
+	clientDataJSON, err := webAuthnEncoding.DecodeString(o.ClientDataJSON)
+	if err != nil {
+		return ""
+	}
+
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return ""
+	}
+
+	return clientData.Challenge
+}
+
+// IsWebAuthnAssertion returns true if an MFA token is a security key's assertion rather than a code.
+func IsWebAuthnAssertion(token string) bool {
+	return strings.HasPrefix(strings.TrimSpace(token), "{")
+}
+
+// WebAuthnOrigin returns the origin and relying party id that security keys are registered for when
+// the server is accessed at siteURL.
+func WebAuthnOrigin(siteURL string) (string, string) {
+	u, err := url.Parse(siteURL)
+	if err != nil {
+		return "", ""
+	}
+
+	return u.Scheme + "://" + u.Host, u.Hostname()
+}
+
+// VerifyWebAuthnAttestation checks a security key's response to a registration challenge and returns
+// the credential to store for it. The attestation statement isn't verified since keys are registered
+// without asking for one, so any key the user chooses is trusted.
+func VerifyWebAuthnAttestation(attestation *WebAuthnAttestation, challenge, origin, rpId string) (*UserCredential, *AppError) {
+	if err := verifyWebAuthnClientData(attestation.ClientDataJSON, WEBAUTHN_CEREMONY_CREATE, challenge, origin); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := webAuthnEncoding.DecodeString(attestation.AttestationObject)
+	if err != nil {
+		return nil, NewAppError("VerifyWebAuthnAttestation", "model.webauthn.attestation.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	decoded, _, err := decodeCbor(attestationObject, 0)
+	if err != nil {
+		return nil, NewAppError("VerifyWebAuthnAttestation", "model.webauthn.attestation.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	fields, _ := decoded.(map[interface{}]interface{})
+	rawAuthData, _ := fields["authData"].([]byte)
+
+	authData, err := parseWebAuthnAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, NewAppError("VerifyWebAuthnAttestation", "model.webauthn.attestation.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if appErr := verifyWebAuthnAuthenticatorData(authData, rpId); appErr != nil {
+		return nil, appErr
+	}
+
+	if authData.Flags&webAuthnFlagAttestedCredentialData == 0 {
+		return nil, NewAppError("VerifyWebAuthnAttestation", "model.webauthn.attestation.app_error", nil, "missing credential data", http.StatusBadRequest)
+	}
+
+	if _, _, err := parseCosePublicKey(authData.PublicKey); err != nil {
+		return nil, NewAppError("VerifyWebAuthnAttestation", "model.webauthn.attestation.public_key.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	return &UserCredential{
+		Name:         attestation.Name,
+		CredentialId: webAuthnEncoding.EncodeToString(authData.CredentialId),
+		PublicKey:    base64.StdEncoding.EncodeToString(authData.PublicKey),
+		SignCount:    int64(authData.SignCount),
+	}, nil
+}
+
+// VerifyWebAuthnAssertion checks a security key's response to a login challenge and returns the key's
+// new signature count. An assertion whose count hasn't gone up is rejected since it may come from a
+// cloned key, unless the key doesn't count signatures at all.
+func VerifyWebAuthnAssertion(assertion *WebAuthnAssertion, credential *UserCredential, challenge, origin, rpId string) (int64, *AppError) {
+	if assertion.Id != credential.CredentialId {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.app_error", nil, "credential mismatch", http.StatusUnauthorized)
+	}
+
+	if err := verifyWebAuthnClientData(assertion.ClientDataJSON, WEBAUTHN_CEREMONY_GET, challenge, origin); err != nil {
+		return 0, err
+	}
+
+	clientDataJSON, _ := webAuthnEncoding.DecodeString(assertion.ClientDataJSON)
+
+	rawAuthData, err := webAuthnEncoding.DecodeString(assertion.AuthenticatorData)
+	if err != nil {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	authData, err := parseWebAuthnAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	if appErr := verifyWebAuthnAuthenticatorData(authData, rpId); appErr != nil {
+		appErr.StatusCode = http.StatusUnauthorized
+		return 0, appErr
+	}
+
+	signature, err := webAuthnEncoding.DecodeString(assertion.Signature)
+	if err != nil {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(credential.PublicKey)
+	if err != nil {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if err := verifyCoseSignature(publicKey, append(rawAuthData, clientDataHash[:]...), signature); err != nil {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.signature.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	signCount := int64(authData.SignCount)
+	if (signCount != 0 || credential.SignCount != 0) && signCount <= credential.SignCount {
+		return 0, NewAppError("VerifyWebAuthnAssertion", "model.webauthn.assertion.sign_count.app_error", nil, "credential_id="+credential.Id, http.StatusUnauthorized)
+	}
+
+	return signCount, nil
+}
+
+func verifyWebAuthnClientData(encoded string, ceremony string, challenge string, origin string) *AppError {
+	clientDataJSON, err := webAuthnEncoding.DecodeString(encoded)
+	if err != nil {
+		return NewAppError("verifyWebAuthnClientData", "model.webauthn.client_data.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return NewAppError("verifyWebAuthnClientData", "model.webauthn.client_data.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if clientData.Type != ceremony {
+		return NewAppError("verifyWebAuthnClientData", "model.webauthn.client_data.app_error", nil, "type="+clientData.Type, http.StatusBadRequest)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(challenge)) != 1 {
+		return NewAppError("verifyWebAuthnClientData", "model.webauthn.client_data.challenge.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if clientData.Origin != origin {
+		return NewAppError("verifyWebAuthnClientData", "model.webauthn.client_data.origin.app_error", nil, "origin="+clientData.Origin, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func verifyWebAuthnAuthenticatorData(authData *webAuthnAuthenticatorData, rpId string) *AppError {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+	if !bytes.Equal(authData.RelyingPartyIdHash, rpIdHash[:]) {
+		return NewAppError("verifyWebAuthnAuthenticatorData", "model.webauthn.authenticator_data.rp_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if authData.Flags&webAuthnFlagUserPresent == 0 {
+		return NewAppError("verifyWebAuthnAuthenticatorData", "model.webauthn.authenticator_data.user_present.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func parseWebAuthnAuthenticatorData(data []byte) (*webAuthnAuthenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	authData := &webAuthnAuthenticatorData{
+		RelyingPartyIdHash: data[:32],
+		Flags:              data[32],
+		SignCount:          binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.Flags&webAuthnFlagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// The attested credential data is the authenticator's AAGUID, then the length of the credential id,
+	// the credential id and the credential's public key
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data is too short")
+	}
+
+	credentialIdLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if credentialIdLength == 0 || len(rest) < credentialIdLength {
+		return nil, errors.New("invalid credential id")
+	}
+
+	authData.CredentialId = rest[:credentialIdLength]
+	rest = rest[credentialIdLength:]
+
+	_, remaining, err := decodeCbor(rest, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	authData.PublicKey = rest[:len(rest)-len(remaining)]
+
+	return authData, nil
+}
+
+// parseCosePublicKey decodes an ES256 or RS256 public key in the COSE format used by WebAuthn.
+func parseCosePublicKey(data []byte) (crypto.PublicKey, int64, error) {
+	decoded, _, err := decodeCbor(data, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("public key is not a map")
+	}
+
+	keyType, _ := key[int64(1)].(int64)
+	algorithm, _ := key[int64(3)].(int64)
+
+	if keyType == coseKeyTypeEC2 && algorithm == COSE_ALGORITHM_ES256 {
+		curve, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("unsupported elliptic curve key")
+		}
+
+		publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, 0, errors.New("point is not on the curve")
+		}
+
+		return publicKey, algorithm, nil
+	} else if keyType == coseKeyTypeRSA && algorithm == COSE_ALGORITHM_RS256 {
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("unsupported rsa key")
+		}
+
+		exponent := 0
+		for _, b := range e {
+			exponent = exponent<<8 | int(b)
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, algorithm, nil
+	}
+
+	return nil, 0, errors.New("unsupported key type or algorithm")
+}
+
+func verifyCoseSignature(publicKey []byte, data []byte, signature []byte) error {
+	key, _, err := parseCosePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeCbor decodes the CBOR data item at the start of data and returns it with the data that
+// follows it. Only what WebAuthn uses is supported: integers, byte and text strings, arrays, maps,
+// tags and simple values. Integers are decoded as int64 and maps as map[interface{}]interface{}.
+func decodeCbor(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("cbor: nested too deeply")
+	}
+
+	if len(data) == 0 {
+		return nil, nil, errors.New("cbor: unexpected end of data")
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	var arg uint64
+	if info < 24 {
+		arg = uint64(info)
+	} else if info <= 27 {
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+
+		for _, b := range data[:size] {
+			arg = arg<<8 | uint64(b)
+		}
+		data = data[size:]
+	} else {
+		return nil, nil, errors.New("cbor: indefinite lengths are not supported")
+	}
+
+	switch major {
+	case 0, 1:
+		if arg > 1<<63-1 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+
+		if major == 1 {
+			return -1 - int64(arg), data, nil
+		}
+
+		return int64(arg), data, nil
+	case 2, 3:
+		if arg > uint64(len(data)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+
+		if major == 3 {
+			return string(data[:arg]), data[arg:], nil
+		}
+
+		return data[:arg], data[arg:], nil
+	case 4:
+		if arg > uint64(len(data)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+
+		items := make([]interface{}, arg)
+		for i := range items {
+			var err error
+			if items[i], data, err = decodeCbor(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return items, data, nil
+	case 5:
+		if arg > uint64(len(data)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+
+		items := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value interface{}
+			var err error
+			if key, data, err = decodeCbor(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errors.New("cbor: unsupported map key")
+			}
+
+			if value, data, err = decodeCbor(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+
+			items[key] = value
+		}
+
+		return items, data, nil
+	case 6:
+		return decodeCbor(data, depth+1)
+	default:
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		}
+
+		return nil, nil, errors.New("cbor: unsupported simple value")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testSecurityKey is a software security key that responds to WebAuthn ceremonies.
+type testSecurityKey struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	signCount    uint32
+}
+
+func newTestSecurityKey(t *testing.T) *testSecurityKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testSecurityKey{key: key, credentialId: []byte(NewId())}
+}
+
+func encodeTestCborHead(major byte, arg int) []byte {
+	if arg < 24 {
+		return []byte{major<<5 | byte(arg)}
+	} else if arg < 256 {
+		return []byte{major<<5 | 24, byte(arg)}
+	}
+
+	return []byte{major<<5 | 25, byte(arg >> 8), byte(arg)}
+}
+
+func encodeTestCborInt(i int) []byte {
+	if i < 0 {
+		return encodeTestCborHead(1, -1-i)
+	}
+
+	return encodeTestCborHead(0, i)
+}
+
+func encodeTestCborBytes(b []byte) []byte {
+	return append(encodeTestCborHead(2, len(b)), b...)
+}
+
+func encodeTestCborString(s string) []byte {
+	return append(encodeTestCborHead(3, len(s)), s...)
+}
+
+func (k *testSecurityKey) publicKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	k.key.X.FillBytes(x)
+	k.key.Y.FillBytes(y)
+
+	data := encodeTestCborHead(5, 5)
+	data = append(data, encodeTestCborInt(1)...)
+	data = append(data, encodeTestCborInt(coseKeyTypeEC2)...)
+	data = append(data, encodeTestCborInt(3)...)
+	data = append(data, encodeTestCborInt(COSE_ALGORITHM_ES256)...)
+	data = append(data, encodeTestCborInt(-1)...)
+	data = append(data, encodeTestCborInt(coseCurveP256)...)
+	data = append(data, encodeTestCborInt(-2)...)
+	data = append(data, encodeTestCborBytes(x)...)
+	data = append(data, encodeTestCborInt(-3)...)
+	data = append(data, encodeTestCborBytes(y)...)
+	return data
+}
+
+func (k *testSecurityKey) authenticatorData(rpId string, attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+	data := append([]byte{}, rpIdHash[:]...)
+
+	flags := byte(webAuthnFlagUserPresent)
+	if attested {
+		flags |= webAuthnFlagAttestedCredentialData
+	}
+	data = append(data, flags)
+
+	k.signCount++
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], k.signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = append(data, byte(len(k.credentialId)>>8), byte(len(k.credentialId)))
+		data = append(data, k.credentialId...)
+		data = append(data, k.publicKey()...)
+	}
+
+	return data
+}
+
+func testClientData(ceremony, challenge, origin string) []byte {
+	b, _ := json.Marshal(webAuthnClientData{Type: ceremony, Challenge: challenge, Origin: origin})
+	return b
+}
+
+func (k *testSecurityKey) create(challenge, origin, rpId string) *WebAuthnAttestation {
+	attestationObject := encodeTestCborHead(5, 3)
+	attestationObject = append(attestationObject, encodeTestCborString("fmt")...)
+	attestationObject = append(attestationObject, encodeTestCborString("none")...)
+	attestationObject = append(attestationObject, encodeTestCborString("attStmt")...)
+	attestationObject = append(attestationObject, encodeTestCborHead(5, 0)...)
+	attestationObject = append(attestationObject, encodeTestCborString("authData")...)
+	attestationObject = append(attestationObject, encodeTestCborBytes(k.authenticatorData(rpId, true))...)
+
+	return &WebAuthnAttestation{
+		Name:              "Test key",
+		ClientDataJSON:    webAuthnEncoding.EncodeToString(testClientData(WEBAUTHN_CEREMONY_CREATE, challenge, origin)),
+		AttestationObject: webAuthnEncoding.EncodeToString(attestationObject),
+	}
+}
+
+func (k *testSecurityKey) get(t *testing.T, challenge, origin, rpId string) *WebAuthnAssertion {
+	clientData := testClientData(WEBAUTHN_CEREMONY_GET, challenge, origin)
+	authData := k.authenticatorData(rpId, false)
+
+	clientDataHash := sha256.Sum256(clientData)
+	hash := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, k.key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &WebAuthnAssertion{
+		Id:                webAuthnEncoding.EncodeToString(k.credentialId),
+		ClientDataJSON:    webAuthnEncoding.EncodeToString(clientData),
+		AuthenticatorData: webAuthnEncoding.EncodeToString(authData),
+		Signature:         webAuthnEncoding.EncodeToString(signature),
+	}
+}
+
+func TestWebAuthnOrigin(t *testing.T) {
+	if origin, rpId := WebAuthnOrigin("https://chat.example.com:8443/subpath"); origin != "https://chat.example.com:8443" || rpId != "chat.example.com" {
+		t.Fatal("wrong origin", origin, rpId)
+	}
+}
+
+func TestNewWebAuthnCreationOptions(t *testing.T) {
+	user := &User{Id: NewId(), Username: "username"}
+	credential := &UserCredential{CredentialId: "credential"}
+
+	options := NewWebAuthnCreationOptions("challenge", "chat.example.com", "Mattermost", user, []*UserCredential{credential})
+	if options.RelyingParty.Id != "chat.example.com" || options.User.Name != "username" || options.User.Id != webAuthnEncoding.EncodeToString([]byte(user.Id)) {
+		t.Fatal("should have described the relying party and user", options)
+	}
+
+	if len(options.ExcludeCredentials) != 1 || options.ExcludeCredentials[0].Id != "credential" {
+		t.Fatal("should have excluded the existing credentials", options.ExcludeCredentials)
+	}
+
+	if decoded := WebAuthnCreationOptionsFromJson(strings.NewReader(options.ToJson())); decoded == nil || decoded.Challenge != "challenge" || decoded.Parameters[0].Algorithm != COSE_ALGORITHM_ES256 {
+		t.Fatal("should have round tripped through json", decoded)
+	}
+}
+
+func TestVerifyWebAuthn(t *testing.T) {
+	origin, rpId := WebAuthnOrigin("https://chat.example.com")
+	key := newTestSecurityKey(t)
+
+	challenge := NewWebAuthnChallenge()
+	if _, err := VerifyWebAuthnAttestation(key.create(NewWebAuthnChallenge(), origin, rpId), challenge, origin, rpId); err == nil {
+		t.Fatal("should fail for the wrong challenge")
+	}
+
+	if _, err := VerifyWebAuthnAttestation(key.create(challenge, "https://evil.example.com", rpId), challenge, origin, rpId); err == nil {
+		t.Fatal("should fail for the wrong origin")
+	}
+
+	if _, err := VerifyWebAuthnAttestation(key.create(challenge, origin, "evil.example.com"), challenge, origin, rpId); err == nil {
+		t.Fatal("should fail for the wrong relying party")
+	}
+
+	credential, err := VerifyWebAuthnAttestation(key.create(challenge, origin, rpId), challenge, origin, rpId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if credential.Name != "Test key" || credential.CredentialId != webAuthnEncoding.EncodeToString(key.credentialId) || credential.SignCount != int64(key.signCount) {
+		t.Fatal("should have returned the credential", credential)
+	}
+
+	challenge = NewWebAuthnChallenge()
+	if assertion := key.get(t, challenge, origin, rpId); assertion.GetChallenge() != challenge {
+		t.Fatal("should have read the challenge from the client data")
+	}
+
+	if (&WebAuthnAssertion{ClientDataJSON: "garbage"}).GetChallenge() != "" {
+		t.Fatal("should not read a challenge from malformed client data")
+	}
+
+	signCount, err := VerifyWebAuthnAssertion(key.get(t, challenge, origin, rpId), credential, challenge, origin, rpId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signCount != int64(key.signCount) {
+		t.Fatal("should have returned the new sign count", signCount)
+	}
+
+	replayed := key.get(t, challenge, origin, rpId)
+	credential.SignCount = int64(key.signCount)
+	if _, err := VerifyWebAuthnAssertion(replayed, credential, challenge, origin, rpId); err == nil {
+		t.Fatal("should fail when the sign count hasn't gone up")
+	}
+
+	credential.SignCount = 0
+	forged := key.get(t, challenge, origin, rpId)
+	forged.Signature = replayed.Signature
+	if _, err := VerifyWebAuthnAssertion(forged, credential, challenge, origin, rpId); err == nil {
+		t.Fatal("should fail for a bad signature")
+	}
+
+	other := newTestSecurityKey(t)
+	if _, err := VerifyWebAuthnAssertion(other.get(t, challenge, origin, rpId), credential, challenge, origin, rpId); err == nil {
+		t.Fatal("should fail for another key")
+	}
+}
+
+func TestDecodeCbor(t *testing.T) {
+	data := encodeTestCborHead(4, 3)
+	data = append(data, encodeTestCborInt(-300)...)
+	data = append(data, encodeTestCborString("a")...)
+	data = append(data, encodeTestCborBytes([]byte{1, 2})...)
+	data = append(data, 0xf5)
+
+	decoded, rest, err := decodeCbor(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := decoded.([]interface{})
+	if len(items) != 3 || items[0].(int64) != -300 || items[1].(string) != "a" || len(items[2].([]byte)) != 2 {
+		t.Fatal("should have decoded the array", items)
+	}
+
+	if len(rest) != 1 {
+		t.Fatal("should have left the data after the array")
+	}
+
+	if _, _, err := decodeCbor(encodeTestCborHead(2, 10), 0); err == nil {
+		t.Fatal("should fail for truncated data")
+	}
+
+	nested := []byte{}
+	for i := 0; i <= cborMaxDepth+1; i++ {
+		nested = append(nested, encodeTestCborHead(4, 1)...)
+	}
+	if _, _, err := decodeCbor(append(nested, 0), 0); err == nil {
+		t.Fatal("should fail for deeply nested data")
+	}
+}
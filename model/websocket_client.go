@@ -5,11 +5,23 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
 	"github.com/gorilla/websocket"
 )
 
 const (
 	SOCKET_MAX_MESSAGE_SIZE_KB = 8 * 1024 // 8KB
+
+	WEBSOCKET_PARAM_CONNECTION_ID = "connection_id"
+	WEBSOCKET_PARAM_LAST_SEQUENCE = "last_seq"
+	WEBSOCKET_PARAM_CHANNEL_IDS   = "channel_ids"
+	WEBSOCKET_PARAM_EVENTS        = "events"
 )
 
 type WebSocketClient struct {
@@ -22,6 +34,12 @@ type WebSocketClient struct {
 	EventChannel    chan *WebSocketEvent
 	ResponseChannel chan *WebSocketResponse
 	ListenError     *AppError
+	ConnectionId    string        // The id of the connection given by the server in the hello event
+	LastSequence    int64         // The sequence number of the last event received from the server
+	FilterChannels  []string      // The only channels whose events are sent to the client, if any
+	FilterEvents    []string      // The only event types that are sent to the client, if any
+	EventStreamUrl  string        // The server-sent events URL to fall back to like "http://localhost:8065/api/v4/events"
+	EventStream     io.ReadCloser // The server-sent events stream used instead of Conn when the websocket upgrade failed
 }
 
 // NewWebSocketClient constructs a new WebSocket client with convienence
@@ -42,6 +60,12 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 		make(chan *WebSocketEvent, 100),
 		make(chan *WebSocketResponse, 100),
 		nil,
+		"",
+		-1,
+		nil,
+		nil,
+		"",
+		nil,
 	}
 
 	client.SendMessage(WEBSOCKET_AUTHENTICATION_CHALLENGE, map[string]interface{}{"token": authToken})
@@ -52,32 +76,59 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 // NewWebSocketClient4 constructs a new WebSocket client with convienence
 // methods for talking to the server. Uses the v4 endpoint.
 func NewWebSocketClient4(url, authToken string) (*WebSocketClient, *AppError) {
-	conn, _, err := websocket.DefaultDialer.Dial(url+API_URL_SUFFIX+"/websocket", nil)
-	if err != nil {
+	return NewWebSocketClient4WithFilter(url, authToken, nil, nil)
+}
+
+// NewWebSocketClient4WithFilter constructs a new WebSocket client that is only sent the events
+// of the given channels and event types. Either can be left empty to not filter on it. Uses the
+// v4 endpoint.
+func NewWebSocketClient4WithFilter(url, authToken string, channelIds []string, events []string) (*WebSocketClient, *AppError) {
+	client := newWebSocketClient4(url, authToken, channelIds, events)
+
+	if err := client.dial(false); err != nil {
 		return nil, NewLocAppError("NewWebSocketClient4", "model.websocket_client.connect_fail.app_error", nil, err.Error())
 	}
 
-	client := &WebSocketClient{
+	client.SendMessage(WEBSOCKET_AUTHENTICATION_CHALLENGE, map[string]interface{}{"token": authToken})
+
+	return client, nil
+}
+
+// NewServerSentEventsClient4 constructs a new client that receives its events as server-sent events
+// instead of over a websocket, as the v4 WebSocket clients do when the websocket upgrade fails.
+// Since the connection only goes one way, messages can't be sent with it.
+func NewServerSentEventsClient4(url, authToken string) (*WebSocketClient, *AppError) {
+	client := newWebSocketClient4(url, authToken, nil, nil)
+
+	if err := client.openEventStream(false); err != nil {
+		return nil, NewLocAppError("NewServerSentEventsClient4", "model.websocket_client.connect_fail.app_error", nil, err.Error())
+	}
+
+	return client, nil
+}
+
+func newWebSocketClient4(url, authToken string, channelIds []string, events []string) *WebSocketClient {
+	return &WebSocketClient{
 		url,
 		url + API_URL_SUFFIX,
 		url + API_URL_SUFFIX + "/websocket",
-		conn,
+		nil,
 		authToken,
 		1,
 		make(chan *WebSocketEvent, 100),
 		make(chan *WebSocketResponse, 100),
 		nil,
+		"",
+		-1,
+		channelIds,
+		events,
+		"http" + strings.TrimPrefix(url, "ws") + API_URL_SUFFIX + "/events",
+		nil,
 	}
-
-	client.SendMessage(WEBSOCKET_AUTHENTICATION_CHALLENGE, map[string]interface{}{"token": authToken})
-
-	return client, nil
 }
 
 func (wsc *WebSocketClient) Connect() *AppError {
-	var err error
-	wsc.Conn, _, err = websocket.DefaultDialer.Dial(wsc.ConnectUrl, nil)
-	if err != nil {
+	if err := wsc.dial(false); err != nil {
 		return NewLocAppError("Connect", "model.websocket_client.connect_fail.app_error", nil, err.Error())
 	}
 
@@ -89,11 +140,111 @@ func (wsc *WebSocketClient) Connect() *AppError {
 	return nil
 }
 
+// Resume reconnects to the server and asks for the events missed since the connection was lost
+// to be replayed. If the server no longer has them, the hello event carries a new connection id
+// and the client should fetch its data again.
+func (wsc *WebSocketClient) Resume() *AppError {
+	if len(wsc.ConnectionId) == 0 {
+		return wsc.Connect()
+	}
+
+	if err := wsc.dial(true); err != nil {
+		return NewLocAppError("Resume", "model.websocket_client.connect_fail.app_error", nil, err.Error())
+	}
+
+	wsc.EventChannel = make(chan *WebSocketEvent, 100)
+	wsc.ResponseChannel = make(chan *WebSocketResponse, 100)
+
+	wsc.SendMessage(WEBSOCKET_AUTHENTICATION_CHALLENGE, map[string]interface{}{"token": wsc.AuthToken})
+
+	return nil
+}
+
+// dial opens a websocket to the server, resuming the previous connection if asked to. If the
+// server or a proxy in between refuses the websocket upgrade, it opens a server-sent events stream
+// instead when the client has an EventStreamUrl.
+func (wsc *WebSocketClient) dial(resume bool) error {
+	params := url.Values{}
+	if resume {
+		params.Set(WEBSOCKET_PARAM_CONNECTION_ID, wsc.ConnectionId)
+		params.Set(WEBSOCKET_PARAM_LAST_SEQUENCE, strconv.FormatInt(wsc.LastSequence, 10))
+	}
+
+	var err error
+	wsc.EventStream = nil
+	wsc.Conn, _, err = websocket.DefaultDialer.Dial(wsc.dialUrl(wsc.ConnectUrl, params), nil)
+	if err == websocket.ErrBadHandshake && len(wsc.EventStreamUrl) > 0 {
+		return wsc.openEventStream(resume)
+	}
+
+	return err
+}
+
+// openEventStream opens a server-sent events stream to receive the events with. The id of the
+// last event received is sent when resuming so that the server replays the events that were missed.
+func (wsc *WebSocketClient) openEventStream(resume bool) error {
+	req, err := http.NewRequest("GET", wsc.dialUrl(wsc.EventStreamUrl, nil), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(HEADER_AUTH, HEADER_BEARER+" "+wsc.AuthToken)
+	req.Header.Set("Accept", "text/event-stream")
+	if resume {
+		req.Header.Set(HEADER_LAST_EVENT_ID, ServerSentEventId(wsc.ConnectionId, wsc.LastSequence))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer closeBody(resp)
+		return errors.New(AppErrorFromJson(resp.Body).Error())
+	}
+
+	wsc.Conn = nil
+	wsc.EventStream = resp.Body
+
+	return nil
+}
+
+// dialUrl returns the URL to open a connection with, adding the client's filter to the given params
+func (wsc *WebSocketClient) dialUrl(connectUrl string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	if len(wsc.FilterChannels) > 0 {
+		params.Set(WEBSOCKET_PARAM_CHANNEL_IDS, strings.Join(wsc.FilterChannels, ","))
+	}
+
+	if len(wsc.FilterEvents) > 0 {
+		params.Set(WEBSOCKET_PARAM_EVENTS, strings.Join(wsc.FilterEvents, ","))
+	}
+
+	if len(params) == 0 {
+		return connectUrl
+	}
+
+	return connectUrl + "?" + params.Encode()
+}
+
 func (wsc *WebSocketClient) Close() {
-	wsc.Conn.Close()
+	if wsc.EventStream != nil {
+		wsc.EventStream.Close()
+	} else {
+		wsc.Conn.Close()
+	}
 }
 
 func (wsc *WebSocketClient) Listen() {
+	if wsc.EventStream != nil {
+		go wsc.listenEventStream()
+		return
+	}
+
 	go func() {
 		defer func() {
 			wsc.Conn.Close()
@@ -114,7 +265,7 @@ func (wsc *WebSocketClient) Listen() {
 
 			var event WebSocketEvent
 			if err := json.Unmarshal(rawMsg, &event); err == nil && event.IsValid() {
-				wsc.EventChannel <- &event
+				wsc.handleEvent(&event)
 				continue
 			}
 
@@ -128,7 +279,46 @@ func (wsc *WebSocketClient) Listen() {
 	}()
 }
 
+// listenEventStream reads the events of a server-sent events stream until it's closed.
+func (wsc *WebSocketClient) listenEventStream() {
+	stream := wsc.EventStream
+
+	defer func() {
+		stream.Close()
+		close(wsc.ResponseChannel)
+		close(wsc.EventChannel)
+	}()
+
+	err := ReadServerSentEvents(stream, func(data string) {
+		var event WebSocketEvent
+		if err := json.Unmarshal([]byte(data), &event); err == nil && event.IsValid() {
+			wsc.handleEvent(&event)
+		}
+	})
+
+	if err != nil {
+		wsc.ListenError = NewLocAppError("NewWebSocketClient", "model.websocket_client.connect_fail.app_error", nil, err.Error())
+	}
+}
+
+func (wsc *WebSocketClient) handleEvent(event *WebSocketEvent) {
+	if event.Event == WEBSOCKET_EVENT_HELLO {
+		if connectionId, ok := event.Data[WEBSOCKET_PARAM_CONNECTION_ID].(string); ok {
+			wsc.ConnectionId = connectionId
+		}
+	}
+
+	wsc.LastSequence = event.Sequence
+	wsc.EventChannel <- event
+}
+
+// SendMessage sends a message to the server over the websocket. Messages can't be sent when the
+// client fell back to server-sent events, so they're dropped.
 func (wsc *WebSocketClient) SendMessage(action string, data map[string]interface{}) {
+	if wsc.Conn == nil {
+		return
+	}
+
 	req := &WebSocketRequest{}
 	req.Seq = wsc.Sequence
 	req.Action = action
@@ -150,6 +340,28 @@ func (wsc *WebSocketClient) UserTyping(channelId, parentId string) {
 	wsc.SendMessage("user_typing", data)
 }
 
+// Subscribe will resume sending the events of the given channels and event types
+// after they were unsubscribed from on this connection
+func (wsc *WebSocketClient) Subscribe(channelIds []string, events []string) {
+	data := map[string]interface{}{
+		"channel_ids": channelIds,
+		"events":      events,
+	}
+
+	wsc.SendMessage("subscribe", data)
+}
+
+// Unsubscribe will stop sending the events of the given channels and event types
+// on this connection
+func (wsc *WebSocketClient) Unsubscribe(channelIds []string, events []string) {
+	data := map[string]interface{}{
+		"channel_ids": channelIds,
+		"events":      events,
+	}
+
+	wsc.SendMessage("unsubscribe", data)
+}
+
 // GetStatuses will return a map of string statuses using user id as the key
 func (wsc *WebSocketClient) GetStatuses() {
 	wsc.SendMessage("get_statuses", nil)
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocketClientServerSentEventsFallback(t *testing.T) {
+	connectionId := NewId()
+	token := NewId()
+	lastEventIds := make(chan string, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case API_URL_SUFFIX + "/websocket":
+			// Like a proxy that doesn't let websockets through
+			w.WriteHeader(http.StatusBadRequest)
+		case API_URL_SUFFIX + "/events":
+			if r.Header.Get(HEADER_AUTH) != HEADER_BEARER+" "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(NewAppError("connectServerSentEvents", "api.context.session_expired.app_error", nil, "", http.StatusUnauthorized).ToJson()))
+				return
+			}
+
+			lastEventIds <- r.Header.Get(HEADER_LAST_EVENT_ID)
+
+			hello := NewWebSocketEvent(WEBSOCKET_EVENT_HELLO, "", "", "", nil)
+			hello.Add(WEBSOCKET_PARAM_CONNECTION_ID, connectionId)
+			hello.Sequence = 4
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, ":\n\nid: %v\ndata: %v\n\n", ServerSentEventId(connectionId, 4), hello.ToJson())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	url := strings.Replace(server.URL, "http", "ws", 1)
+
+	client, err := NewWebSocketClient4(url, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Conn != nil || client.EventStream == nil {
+		t.Fatal("should have fallen back to server-sent events")
+	}
+
+	if lastEventId := <-lastEventIds; len(lastEventId) != 0 {
+		t.Fatal("shouldn't have resumed a connection", lastEventId)
+	}
+
+	client.Listen()
+
+	select {
+	case event := <-client.EventChannel:
+		if event.Event != WEBSOCKET_EVENT_HELLO || client.ConnectionId != connectionId || client.LastSequence != 4 {
+			t.Fatal("should have received the hello event", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("should have received an event")
+	}
+
+	// Messages can't be sent over the stream
+	client.UserTyping(NewId(), "")
+
+	if _, ok := <-client.EventChannel; ok {
+		t.Fatal("should have stopped listening at the end of the stream")
+	}
+
+	if err := client.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if lastEventId := <-lastEventIds; lastEventId != ServerSentEventId(connectionId, 4) {
+		t.Fatal("should have resumed from the last event", lastEventId)
+	}
+
+	if _, err := NewWebSocketClient4(url, NewId()); err == nil {
+		t.Fatal("should have failed to open the stream without a valid token")
+	}
+}
@@ -13,8 +13,10 @@ const (
 	WEBSOCKET_EVENT_POSTED             = "posted"
 	WEBSOCKET_EVENT_POST_EDITED        = "post_edited"
 	WEBSOCKET_EVENT_POST_DELETED       = "post_deleted"
+	WEBSOCKET_EVENT_POST_RESTORED      = "post_restored"
 	WEBSOCKET_EVENT_CHANNEL_DELETED    = "channel_deleted"
 	WEBSOCKET_EVENT_CHANNEL_CREATED    = "channel_created"
+	WEBSOCKET_EVENT_CHANNEL_CONVERTED  = "channel_converted"
 	WEBSOCKET_EVENT_DIRECT_ADDED       = "direct_added"
 	WEBSOCKET_EVENT_GROUP_ADDED        = "group_added"
 	WEBSOCKET_EVENT_NEW_USER           = "new_user"
@@ -24,6 +26,8 @@ const (
 	WEBSOCKET_EVENT_USER_ADDED         = "user_added"
 	WEBSOCKET_EVENT_USER_UPDATED       = "user_updated"
 	WEBSOCKET_EVENT_USER_REMOVED       = "user_removed"
+	WEBSOCKET_EVENT_USERS_ADDED        = "users_added"
+	WEBSOCKET_EVENT_USERS_REMOVED      = "users_removed"
 	WEBSOCKET_EVENT_PREFERENCE_CHANGED = "preference_changed"
 	WEBSOCKET_EVENT_EPHEMERAL_MESSAGE  = "ephemeral_message"
 	WEBSOCKET_EVENT_STATUS_CHANGE      = "status_change"
@@ -32,6 +36,15 @@ const (
 	WEBSOCKET_AUTHENTICATION_CHALLENGE = "authentication_challenge"
 	WEBSOCKET_EVENT_REACTION_ADDED     = "reaction_added"
 	WEBSOCKET_EVENT_REACTION_REMOVED   = "reaction_removed"
+	WEBSOCKET_EVENT_DRAFT_UPDATED      = "draft_updated"
+	WEBSOCKET_EVENT_DRAFT_DELETED      = "draft_deleted"
+	WEBSOCKET_EVENT_CHANNEL_READ       = "channel_read"
+	WEBSOCKET_EVENT_SESSION_REVOKED    = "session_revoked"
+
+	WEBSOCKET_EVENT_SIDEBAR_CATEGORY_CREATED       = "sidebar_category_created"
+	WEBSOCKET_EVENT_SIDEBAR_CATEGORY_UPDATED       = "sidebar_category_updated"
+	WEBSOCKET_EVENT_SIDEBAR_CATEGORY_DELETED       = "sidebar_category_deleted"
+	WEBSOCKET_EVENT_SIDEBAR_CATEGORY_ORDER_UPDATED = "sidebar_category_order_updated"
 )
 
 type WebSocketMessage interface {
@@ -41,10 +54,12 @@ type WebSocketMessage interface {
 }
 
 type WebsocketBroadcast struct {
-	OmitUsers map[string]bool `json:"omit_users"` // broadcast is omitted for users listed here
-	UserId    string          `json:"user_id"`    // broadcast only occurs for this user
-	ChannelId string          `json:"channel_id"` // broadcast only occurs for users in this channel
-	TeamId    string          `json:"team_id"`    // broadcast only occurs for users in this team
+	OmitUsers    map[string]bool `json:"omit_users"`    // broadcast is omitted for users listed here
+	IncludeUsers map[string]bool `json:"include_users"` // broadcast always occurs for users listed here
+	UserId       string          `json:"user_id"`       // broadcast only occurs for this user
+	ChannelId    string          `json:"channel_id"`    // broadcast only occurs for users in this channel
+	TeamId       string          `json:"team_id"`       // broadcast only occurs for users in this team
+	SessionId    string          `json:"session_id"`    // broadcast only occurs for connections of this session
 }
 
 type WebSocketEvent struct {
@@ -91,6 +106,26 @@ func WebSocketEventFromJson(data io.Reader) *WebSocketEvent {
 	}
 }
 
+func WebSocketEventListToJson(l []*WebSocketEvent) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func WebSocketEventListFromJson(data io.Reader) []*WebSocketEvent {
+	decoder := json.NewDecoder(data)
+	var o []*WebSocketEvent
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
 type WebSocketResponse struct {
 	Status   string                 `json:"status"`
 	SeqReply int64                  `json:"seq_reply,omitempty"`
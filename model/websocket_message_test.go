@@ -32,6 +32,20 @@ func TestWebSocketEvent(t *testing.T) {
 	}
 }
 
+func TestWebSocketEventListJson(t *testing.T) {
+	m := NewWebSocketEvent("some_event", "", NewId(), "", nil)
+	m.Sequence = 3
+
+	result := WebSocketEventListFromJson(strings.NewReader(WebSocketEventListToJson([]*WebSocketEvent{m})))
+	if len(result) != 1 || result[0].Event != m.Event || result[0].Sequence != 3 {
+		t.Fatal("should have read the list back")
+	}
+
+	if WebSocketEventListFromJson(strings.NewReader("junk")) != nil {
+		t.Fatal("should not have parsed")
+	}
+}
+
 func TestWebSocketResponse(t *testing.T) {
 	m := NewWebSocketResponse("OK", 1, map[string]interface{}{})
 	e := NewWebSocketError(1, &AppError{})
@@ -0,0 +1,238 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlAlertmanagerStore struct {
+	*SqlStore
+}
+
+func NewSqlAlertmanagerStore(sqlStore *SqlStore) AlertmanagerStore {
+	s := &SqlAlertmanagerStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.AlertmanagerReceiver{}, "AlertmanagerReceivers").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("DisplayName").SetMaxSize(64)
+		table.ColMap("AlertmanagerURL").SetMaxSize(1024)
+		table.ColMap("Credentials").SetMaxSize(1024)
+
+		tablep := db.AddTableWithName(model.AlertmanagerPost{}, "AlertmanagerPosts").SetKeys(false, "ReceiverId", "GroupKey")
+		tablep.ColMap("ReceiverId").SetMaxSize(26)
+		tablep.ColMap("GroupKey").SetMaxSize(255)
+		tablep.ColMap("PostId").SetMaxSize(26)
+		tablep.ColMap("Status").SetMaxSize(16)
+		tablep.ColMap("Labels").SetMaxSize(2000)
+	}
+
+	return s
+}
+
+func (s SqlAlertmanagerStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_alertmanager_receivers_team_id", "AlertmanagerReceivers", "TeamId")
+	s.CreateIndexIfNotExists("idx_alertmanager_receivers_delete_at", "AlertmanagerReceivers", "DeleteAt")
+	s.CreateIndexIfNotExists("idx_alertmanager_posts_post_id", "AlertmanagerPosts", "PostId")
+}
+
+func (s SqlAlertmanagerStore) SaveReceiver(receiver *model.AlertmanagerReceiver) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(receiver.Id) > 0 {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.SaveReceiver", "store.sql_alertmanager.save_receiver.existing.app_error", nil, "id="+receiver.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		receiver.PreSave()
+		if result.Err = receiver.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(receiver); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.SaveReceiver", "store.sql_alertmanager.save_receiver.app_error", nil, "id="+receiver.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = receiver
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) UpdateReceiver(receiver *model.AlertmanagerReceiver) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		receiver.PreUpdate()
+		if result.Err = receiver.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := s.GetMaster().Update(receiver); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.UpdateReceiver", "store.sql_alertmanager.update_receiver.app_error", nil, "id="+receiver.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = receiver
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) GetReceiver(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var receiver model.AlertmanagerReceiver
+
+		if err := s.GetReplica().SelectOne(&receiver, "SELECT * FROM AlertmanagerReceivers WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.GetReceiver", "store.sql_alertmanager.get_receiver.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.GetReceiver", "store.sql_alertmanager.get_receiver.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &receiver
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) GetReceiversByTeam(teamId string, offset, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var receivers []*model.AlertmanagerReceiver
+
+		if _, err := s.GetReplica().Select(&receivers,
+			`SELECT
+				*
+			FROM
+				AlertmanagerReceivers
+			WHERE
+				TeamId = :TeamId
+				AND DeleteAt = 0
+			ORDER BY
+				CreateAt
+			LIMIT :Limit
+			OFFSET :Offset`, map[string]interface{}{"TeamId": teamId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.GetReceiversByTeam", "store.sql_alertmanager.get_receivers_by_team.app_error", nil, "teamId="+teamId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = receivers
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) DeleteReceiver(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE AlertmanagerReceivers SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.DeleteReceiver", "store.sql_alertmanager.delete_receiver.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM AlertmanagerPosts WHERE ReceiverId = :ReceiverId", map[string]interface{}{"ReceiverId": id}); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.DeleteReceiver", "store.sql_alertmanager.delete_receiver.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) SavePost(alertPost *model.AlertmanagerPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		alertPost.UpdateAt = model.GetMillis()
+
+		if count, err := s.GetMaster().SelectInt("SELECT COUNT(0) FROM AlertmanagerPosts WHERE ReceiverId = :ReceiverId AND GroupKey = :GroupKey",
+			map[string]interface{}{"ReceiverId": alertPost.ReceiverId, "GroupKey": alertPost.GroupKey}); err != nil {
+			result.Err = model.NewAppError("SqlAlertmanagerStore.SavePost", "store.sql_alertmanager.save_post.app_error", nil, "receiverId="+alertPost.ReceiverId+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			if err := s.GetMaster().Insert(alertPost); err != nil {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.SavePost", "store.sql_alertmanager.save_post.app_error", nil, "receiverId="+alertPost.ReceiverId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			if _, err := s.GetMaster().Update(alertPost); err != nil {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.SavePost", "store.sql_alertmanager.save_post.app_error", nil, "receiverId="+alertPost.ReceiverId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = alertPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAlertmanagerStore) GetPost(receiverId string, groupKey string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var alertPost model.AlertmanagerPost
+
+		if err := s.GetMaster().SelectOne(&alertPost, "SELECT * FROM AlertmanagerPosts WHERE ReceiverId = :ReceiverId AND GroupKey = :GroupKey",
+			map[string]interface{}{"ReceiverId": receiverId, "GroupKey": groupKey}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.GetPost", "store.sql_alertmanager.get_post.app_error", nil, "receiverId="+receiverId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlAlertmanagerStore.GetPost", "store.sql_alertmanager.get_post.app_error", nil, "receiverId="+receiverId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &alertPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestAlertmanagerStoreReceivers(t *testing.T) {
+	Setup()
+
+	receiver := &model.AlertmanagerReceiver{
+		CreatorId:       model.NewId(),
+		ChannelId:       model.NewId(),
+		TeamId:          model.NewId(),
+		AlertmanagerURL: "http://alertmanager.example.com:9093/",
+		Credentials:     model.EncryptStringMap{"username": "admin", "password": "secret"},
+	}
+
+	if result := <-store.Alertmanager().SaveReceiver(receiver); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Alertmanager().SaveReceiver(receiver); result.Err == nil {
+		t.Fatal("shouldn't be able to update from save")
+	}
+
+	if result := <-store.Alertmanager().GetReceiver(receiver.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.AlertmanagerReceiver); saved.AlertmanagerURL != "http://alertmanager.example.com:9093" {
+		t.Fatal("should have trimmed the trailing slash")
+	} else if saved.Credentials["password"] != "secret" {
+		t.Fatal("should have decrypted the stored credentials")
+	}
+
+	receiver.DisplayName = "prometheus"
+	if result := <-store.Alertmanager().UpdateReceiver(receiver); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Alertmanager().GetReceiversByTeam(receiver.TeamId, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if receivers := result.Data.([]*model.AlertmanagerReceiver); len(receivers) != 1 || receivers[0].DisplayName != "prometheus" {
+		t.Fatal("should have returned the updated receiver")
+	}
+
+	if result := <-store.Alertmanager().DeleteReceiver(receiver.Id, model.GetMillis()); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Alertmanager().GetReceiver(receiver.Id); result.Err == nil {
+		t.Fatal("should not return a deleted receiver")
+	}
+}
+
+func TestAlertmanagerStorePosts(t *testing.T) {
+	Setup()
+
+	alertPost := &model.AlertmanagerPost{
+		ReceiverId: model.NewId(),
+		GroupKey:   "{}:{alertname=\"HighLatency\"}",
+		PostId:     model.NewId(),
+		Status:     model.ALERTMANAGER_STATUS_FIRING,
+	}
+
+	Must(store.Alertmanager().SavePost(alertPost))
+
+	alertPost.Status = model.ALERTMANAGER_STATUS_RESOLVED
+	Must(store.Alertmanager().SavePost(alertPost))
+
+	if result := <-store.Alertmanager().GetPost(alertPost.ReceiverId, alertPost.GroupKey); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.AlertmanagerPost); saved.PostId != alertPost.PostId || saved.Status != model.ALERTMANAGER_STATUS_RESOLVED {
+		t.Fatal("should have updated the existing record")
+	}
+
+	if result := <-store.Alertmanager().GetPost(alertPost.ReceiverId, "missing"); result.Err == nil {
+		t.Fatal("should have failed on a missing group")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlArchiveExportStore struct {
+	*SqlStore
+}
+
+func NewSqlArchiveExportStore(sqlStore *SqlStore) ArchiveExportStore {
+	s := &SqlArchiveExportStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ArchiveExport{}, "ArchiveExports").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Type").SetMaxSize(32)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("Error").SetMaxSize(model.ARCHIVE_EXPORT_ERROR_MAX_RUNES)
+		table.ColMap("Path").SetMaxSize(512)
+	}
+
+	return s
+}
+
+func (s SqlArchiveExportStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_archive_exports_create_at", "ArchiveExports", "CreateAt")
+}
+
+func (s SqlArchiveExportStore) Save(export *model.ArchiveExport) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(export.Id) > 0 {
+			result.Err = model.NewAppError("SqlArchiveExportStore.Save", "store.sql_archive_export.save.existing.app_error", nil, "id="+export.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		export.PreSave()
+		if result.Err = export.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(export); err != nil {
+			result.Err = model.NewAppError("SqlArchiveExportStore.Save", "store.sql_archive_export.save.app_error", nil, "id="+export.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = export
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlArchiveExportStore) Update(export *model.ArchiveExport) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		export.PreUpdate()
+		if result.Err = export.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(export); err != nil {
+			result.Err = model.NewAppError("SqlArchiveExportStore.Update", "store.sql_archive_export.update.app_error", nil, "id="+export.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlArchiveExportStore.Update", "store.sql_archive_export.update.app_error", nil, "id="+export.Id, http.StatusNotFound)
+		} else {
+			result.Data = export
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlArchiveExportStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var export model.ArchiveExport
+
+		// Read from the master so that the progress is up to date
+		if err := s.GetMaster().SelectOne(&export, "SELECT * FROM ArchiveExports WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlArchiveExportStore.Get", "store.sql_archive_export.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlArchiveExportStore.Get", "store.sql_archive_export.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &export
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns a page of the archive exports, most recently requested first.
+func (s SqlArchiveExportStore) GetAll(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var exports []*model.ArchiveExport
+
+		if _, err := s.GetMaster().Select(&exports, "SELECT * FROM ArchiveExports ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlArchiveExportStore.GetAll", "store.sql_archive_export.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = exports
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestArchiveExportStore(t *testing.T) {
+	Setup()
+
+	e1 := &model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_CHANNEL, ChannelId: model.NewId(), UserId: model.NewId()}
+	if result := <-store.ArchiveExport().Save(e1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ArchiveExport().Save(e1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing export")
+	}
+
+	e2 := &model.ArchiveExport{Type: model.ARCHIVE_EXPORT_TYPE_TEAM, TeamId: model.NewId(), UserId: model.NewId()}
+	e2 = Must(store.ArchiveExport().Save(e2)).(*model.ArchiveExport)
+
+	e1.Status = model.ARCHIVE_EXPORT_STATUS_RUNNING
+	e1.Progress = 50
+	if result := <-store.ArchiveExport().Update(e1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ArchiveExport().Get(e1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.ArchiveExport); saved.Status != model.ARCHIVE_EXPORT_STATUS_RUNNING || saved.Progress != 50 || saved.Path != e1.Path {
+		t.Fatal("should have updated the export")
+	}
+
+	if result := <-store.ArchiveExport().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found an export")
+	}
+
+	if result := <-store.ArchiveExport().GetAll(0, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if exports := result.Data.([]*model.ArchiveExport); len(exports) != 1 || exports[0].Id != e2.Id {
+		t.Fatal("should have returned the most recent export first")
+	}
+}
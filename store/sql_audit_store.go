@@ -22,6 +22,11 @@ func NewSqlAuditStore(sqlStore *SqlStore) AuditStore {
 		table.ColMap("ExtraInfo").SetMaxSize(1024)
 		table.ColMap("IpAddress").SetMaxSize(64)
 		table.ColMap("SessionId").SetMaxSize(26)
+		table.ColMap("EventName").SetMaxSize(64)
+		table.ColMap("ActorId").SetMaxSize(26)
+		table.ColMap("TargetType").SetMaxSize(32)
+		table.ColMap("TargetId").SetMaxSize(26)
+		table.ColMap("Metadata").SetMaxSize(4096)
 	}
 
 	return s
@@ -29,6 +34,10 @@ func NewSqlAuditStore(sqlStore *SqlStore) AuditStore {
 
 func (s SqlAuditStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_audits_user_id", "Audits", "UserId")
+	s.CreateIndexIfNotExists("idx_audits_create_at", "Audits", "CreateAt")
+	s.CreateIndexIfNotExists("idx_audits_event_name", "Audits", "EventName")
+	s.CreateIndexIfNotExists("idx_audits_actor_id", "Audits", "ActorId")
+	s.CreateIndexIfNotExists("idx_audits_target_id", "Audits", "TargetId")
 }
 
 func (s SqlAuditStore) Save(audit *model.Audit) StoreChannel {
@@ -91,6 +100,71 @@ func (s SqlAuditStore) Get(user_id string, offset int, limit int) StoreChannel {
 	return storeChannel
 }
 
+// Search returns the audits that match a filter, most recent first.
+func (s SqlAuditStore) Search(filter *model.AuditFilter, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if limit > 1000 {
+			result.Err = model.NewLocAppError("SqlAuditStore.Search", "store.sql_audit.get.limit.app_error", nil, "user_id="+filter.UserId)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		query := "SELECT * FROM Audits WHERE 1 = 1"
+
+		if len(filter.UserId) > 0 {
+			query += " AND (UserId = :UserId OR ActorId = :UserId)"
+		}
+
+		if len(filter.EventName) > 0 {
+			query += " AND EventName = :EventName"
+		}
+
+		if len(filter.TargetType) > 0 {
+			query += " AND TargetType = :TargetType"
+		}
+
+		if len(filter.TargetId) > 0 {
+			query += " AND TargetId = :TargetId"
+		}
+
+		if filter.Since > 0 {
+			query += " AND CreateAt >= :Since"
+		}
+
+		if filter.Until > 0 {
+			query += " AND CreateAt <= :Until"
+		}
+
+		query += " ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset"
+
+		var audits model.Audits
+		if _, err := s.GetReplica().Select(&audits, query, map[string]interface{}{
+			"UserId":     filter.UserId,
+			"EventName":  filter.EventName,
+			"TargetType": filter.TargetType,
+			"TargetId":   filter.TargetId,
+			"Since":      filter.Since,
+			"Until":      filter.Until,
+			"Limit":      limit,
+			"Offset":     offset,
+		}); err != nil {
+			result.Err = model.NewLocAppError("SqlAuditStore.Search", "store.sql_audit.search.app_error", nil, err.Error())
+		} else {
+			result.Data = audits
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlAuditStore) PermanentDeleteByUser(userId string) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
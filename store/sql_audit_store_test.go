@@ -58,3 +58,63 @@ func TestSqlAuditStore(t *testing.T) {
 		t.Fatal(r2.Err)
 	}
 }
+
+func TestSqlAuditStoreSearch(t *testing.T) {
+	Setup()
+
+	actorId := model.NewId()
+	targetId := model.NewId()
+
+	a1 := &model.Audit{UserId: actorId, ActorId: actorId, EventName: "login", IpAddress: "ipaddress"}
+	Must(store.Audit().Save(a1))
+	time.Sleep(10 * time.Millisecond)
+
+	a2 := &model.Audit{
+		UserId:     targetId,
+		ActorId:    actorId,
+		EventName:  "updateUserRoles",
+		TargetType: model.AUDIT_TARGET_USER,
+		TargetId:   targetId,
+		Metadata:   model.StringInterface{"roles": "system_user system_admin"},
+	}
+	Must(store.Audit().Save(a2))
+
+	if result := <-store.Audit().Search(&model.AuditFilter{UserId: actorId}, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 2 || audits[0].Id != a2.Id || audits[1].Id != a1.Id {
+		t.Fatal("should have returned the audits about and by the user, most recent first")
+	} else if audits[0].Metadata["roles"] != "system_user system_admin" || audits[0].TargetType != model.AUDIT_TARGET_USER {
+		t.Fatal("should have saved the structured fields")
+	}
+
+	if result := <-store.Audit().Search(&model.AuditFilter{UserId: actorId, EventName: "login"}, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 1 || audits[0].Id != a1.Id {
+		t.Fatal("should have filtered by event name")
+	}
+
+	if result := <-store.Audit().Search(&model.AuditFilter{TargetType: model.AUDIT_TARGET_USER, TargetId: targetId}, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 1 || audits[0].Id != a2.Id {
+		t.Fatal("should have filtered by target")
+	}
+
+	if result := <-store.Audit().Search(&model.AuditFilter{UserId: actorId, Since: a2.CreateAt}, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 1 || audits[0].Id != a2.Id {
+		t.Fatal("should have filtered by start time")
+	}
+
+	if result := <-store.Audit().Search(&model.AuditFilter{UserId: actorId, Until: a2.CreateAt - 1}, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if audits := result.Data.(model.Audits); len(audits) != 1 || audits[0].Id != a1.Id {
+		t.Fatal("should have filtered by end time")
+	}
+
+	if result := <-store.Audit().Search(&model.AuditFilter{UserId: actorId}, 0, 1001); result.Err == nil {
+		t.Fatal("should have failed with a limit over 1000")
+	}
+
+	Must(store.Audit().PermanentDeleteByUser(actorId))
+	Must(store.Audit().PermanentDeleteByUser(targetId))
+}
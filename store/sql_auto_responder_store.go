@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlAutoResponderStore struct {
+	*SqlStore
+}
+
+func NewSqlAutoResponderStore(sqlStore *SqlStore) AutoResponderStore {
+	s := &SqlAutoResponderStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.AutoResponder{}, "AutoResponders").SetKeys(false, "UserId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.AUTO_RESPONDER_MESSAGE_MAX_RUNES)
+
+		tableResponses := db.AddTableWithName(model.AutoResponse{}, "AutoResponses").SetKeys(false, "UserId", "SenderId")
+		tableResponses.ColMap("UserId").SetMaxSize(26)
+		tableResponses.ColMap("SenderId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlAutoResponderStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_autoresponders_active", "AutoResponders", "Active")
+}
+
+func (s SqlAutoResponderStore) SaveOrUpdate(responder *model.AutoResponder) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		responder.PreSave()
+		if result.Err = responder.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(responder); err != nil {
+			result.Err = model.NewAppError("SqlAutoResponderStore.SaveOrUpdate", "store.sql_auto_responder.save.app_error", nil, "user_id="+responder.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			if err := s.GetMaster().Insert(responder); err != nil {
+				result.Err = model.NewAppError("SqlAutoResponderStore.SaveOrUpdate", "store.sql_auto_responder.save.app_error", nil, "user_id="+responder.UserId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = responder
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlAutoResponderStore) Get(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var responder model.AutoResponder
+
+		if err := s.GetReplica().SelectOne(&responder, "SELECT * FROM AutoResponders WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlAutoResponderStore.Get", "store.sql_auto_responder.get.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlAutoResponderStore.Get", "store.sql_auto_responder.get.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &responder
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetActive returns the auto responders that are turned on, whether or not their date range has started.
+func (s SqlAutoResponderStore) GetActive(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var responders []*model.AutoResponder
+
+		if _, err := s.GetReplica().Select(&responders, "SELECT * FROM AutoResponders WHERE Active = :Active ORDER BY UserId LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Active": true, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlAutoResponderStore.GetActive", "store.sql_auto_responder.get_active.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = responders
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// MarkResponseSent records that a user's auto responder is replying to a sender unless it has already
+// replied to them since the given time. The result is true if the auto responder should reply. Two
+// messages from the same sender arriving at once only get one reply since only one of them can update
+// or insert the record.
+func (s SqlAutoResponderStore) MarkResponseSent(userId string, senderId string, sentAt int64, since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"UserId": userId, "SenderId": senderId, "SentAt": sentAt, "Since": since}
+
+		if res, err := s.GetMaster().Exec("UPDATE AutoResponses SET SentAt = :SentAt WHERE UserId = :UserId AND SenderId = :SenderId AND SentAt < :Since", props); err != nil {
+			result.Err = model.NewAppError("SqlAutoResponderStore.MarkResponseSent", "store.sql_auto_responder.mark_response_sent.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count > 0 {
+			result.Data = true
+		} else if err := s.GetMaster().Insert(&model.AutoResponse{UserId: userId, SenderId: senderId, SentAt: sentAt}); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"PRIMARY", "autoresponses_pkey"}) {
+				result.Data = false
+			} else {
+				result.Err = model.NewAppError("SqlAutoResponderStore.MarkResponseSent", "store.sql_auto_responder.mark_response_sent.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = true
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// DeleteResponses forgets who a user's auto responder has replied to, so that it replies to everyone
+// again the next time it's turned on.
+func (s SqlAutoResponderStore) DeleteResponses(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM AutoResponses WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlAutoResponderStore.DeleteResponses", "store.sql_auto_responder.delete_responses.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
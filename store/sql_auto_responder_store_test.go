@@ -0,0 +1,69 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestAutoResponderStore(t *testing.T) {
+	Setup()
+
+	responder := &model.AutoResponder{UserId: model.NewId(), Active: true, Message: "I'm away"}
+	if result := <-store.AutoResponder().SaveOrUpdate(responder); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	responder.Message = "I'm still away"
+	if result := <-store.AutoResponder().SaveOrUpdate(responder); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.AutoResponder().Get(responder.UserId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.AutoResponder); saved.Message != responder.Message || !saved.Active {
+		t.Fatal("should have updated the auto responder")
+	}
+
+	if result := <-store.AutoResponder().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found an auto responder")
+	}
+
+	if result := <-store.AutoResponder().GetActive(0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, active := range result.Data.([]*model.AutoResponder) {
+			if active.UserId == responder.UserId {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the active auto responder")
+		}
+	}
+
+	senderId := model.NewId()
+
+	if sent := Must(store.AutoResponder().MarkResponseSent(responder.UserId, senderId, 1000, 500)).(bool); !sent {
+		t.Fatal("should reply to the first message")
+	}
+
+	if sent := Must(store.AutoResponder().MarkResponseSent(responder.UserId, senderId, 1500, 500)).(bool); sent {
+		t.Fatal("shouldn't reply twice on the same day")
+	}
+
+	if sent := Must(store.AutoResponder().MarkResponseSent(responder.UserId, senderId, 3000, 2000)).(bool); !sent {
+		t.Fatal("should reply again on the next day")
+	}
+
+	Must(store.AutoResponder().DeleteResponses(responder.UserId))
+
+	if sent := Must(store.AutoResponder().MarkResponseSent(responder.UserId, senderId, 3500, 2000)).(bool); !sent {
+		t.Fatal("should reply again once the responses are deleted")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlBotStore struct {
+	*SqlStore
+}
+
+// The username and display name of a bot are those of its user, so they are read with a join
+// rather than stored in the Bots table.
+const botsQuery = `
+	SELECT
+		Bots.*, Users.Username, Users.FirstName AS DisplayName
+	FROM
+		Bots, Users
+	WHERE
+		Bots.UserId = Users.Id
+		BOTS_FILTER`
+
+func NewSqlBotStore(sqlStore *SqlStore) BotStore {
+	s := &SqlBotStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Bot{}, "Bots").SetKeys(false, "UserId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Username").SetTransient(true)
+		table.ColMap("DisplayName").SetTransient(true)
+		table.ColMap("Description").SetMaxSize(model.BOT_DESCRIPTION_MAX_RUNES)
+		table.ColMap("OwnerId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlBotStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_bots_owner_id", "Bots", "OwnerId")
+}
+
+func (s SqlBotStore) Save(bot *model.Bot) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		bot.PreSave()
+		if result.Err = bot.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(bot); err != nil {
+			result.Err = model.NewAppError("SqlBotStore.Save", "store.sql_bot.save.app_error", nil, "user_id="+bot.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) Update(bot *model.Bot) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		bot.PreUpdate()
+		if result.Err = bot.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(bot); err != nil {
+			result.Err = model.NewAppError("SqlBotStore.Update", "store.sql_bot.update.app_error", nil, "user_id="+bot.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlBotStore.Update", "store.sql_bot.update.app_error", nil, "user_id="+bot.UserId, http.StatusNotFound)
+		} else {
+			result.Data = bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) Get(userId string, includeDeleted bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		filter := "AND Bots.UserId = :UserId"
+		if !includeDeleted {
+			filter += " AND Bots.DeleteAt = 0"
+		}
+
+		var bot model.Bot
+		if err := s.GetReplica().SelectOne(&bot, strings.Replace(botsQuery, "BOTS_FILTER", filter, 1), map[string]interface{}{"UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlBotStore.Get", "store.sql_bot.get.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlBotStore.Get", "store.sql_bot.get.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns the active bots owned by the given user, or all active bots if ownerId is empty.
+func (s SqlBotStore) GetAll(ownerId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		filter := "AND Bots.DeleteAt = 0"
+		if len(ownerId) > 0 {
+			filter += " AND Bots.OwnerId = :OwnerId"
+		}
+
+		query := strings.Replace(botsQuery, "BOTS_FILTER", filter, 1) + " ORDER BY Users.Username ASC LIMIT :Limit OFFSET :Offset"
+
+		var bots []*model.Bot
+		if _, err := s.GetReplica().Select(&bots, query, map[string]interface{}{"OwnerId": ownerId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlBotStore.GetAll", "store.sql_bot.get_all.app_error", nil, "owner_id="+ownerId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = bots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) PermanentDelete(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Bots WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlBotStore.PermanentDelete", "store.sql_bot.permanent_delete.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
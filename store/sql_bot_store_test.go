@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestBotStoreSaveGet(t *testing.T) {
+	Setup()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	u1.Username = "bot" + model.NewId()
+	u1.FirstName = "Bot"
+	Must(store.User().Save(u1))
+
+	ownerId := model.NewId()
+	bot := &model.Bot{UserId: u1.Id, OwnerId: ownerId, Description: "a bot"}
+
+	if result := <-store.Bot().Save(bot); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Bot().Get(u1.Id, false); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rbot := result.Data.(*model.Bot); rbot.Username != u1.Username || rbot.DisplayName != u1.FirstName || rbot.Description != bot.Description {
+		t.Fatal("should have returned the bot with the names of its user")
+	}
+
+	if result := <-store.Bot().GetAll(ownerId, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if bots := result.Data.([]*model.Bot); len(bots) != 1 || bots[0].UserId != u1.Id {
+		t.Fatal("should have returned the bots of the owner")
+	}
+
+	if result := <-store.Bot().GetAll(model.NewId(), 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if bots := result.Data.([]*model.Bot); len(bots) != 0 {
+		t.Fatal("should not have returned the bots of another owner")
+	}
+
+	bot.DeleteAt = model.GetMillis()
+	if result := <-store.Bot().Update(bot); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Bot().Get(u1.Id, false); result.Err == nil {
+		t.Fatal("should not have returned a deleted bot")
+	}
+
+	if result := <-store.Bot().Get(u1.Id, true); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Bot().PermanentDelete(u1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Bot().Get(u1.Id, true); result.Err == nil {
+		t.Fatal("should have deleted the bot")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlBulkEmailStore struct {
+	*SqlStore
+}
+
+func NewSqlBulkEmailStore(sqlStore *SqlStore) BulkEmailStore {
+	s := &SqlBulkEmailStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.BulkEmail{}, "BulkEmails").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Segment").SetMaxSize(32)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("MinVersion").SetMaxSize(32)
+		table.ColMap("Subject").SetMaxSize(model.BULK_EMAIL_SUBJECT_MAX_RUNES)
+		table.ColMap("Message").SetMaxSize(model.BULK_EMAIL_MESSAGE_MAX_RUNES)
+		table.ColMap("SenderId").SetMaxSize(26)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("Error").SetMaxSize(model.BULK_EMAIL_ERROR_MAX_RUNES)
+
+		recipients := db.AddTableWithName(model.BulkEmailRecipient{}, "BulkEmailRecipients").SetKeys(false, "BulkEmailId", "UserId")
+		recipients.ColMap("BulkEmailId").SetMaxSize(26)
+		recipients.ColMap("UserId").SetMaxSize(26)
+		recipients.ColMap("Status").SetMaxSize(32)
+		recipients.ColMap("Error").SetMaxSize(model.BULK_EMAIL_ERROR_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlBulkEmailStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_bulk_emails_create_at", "BulkEmails", "CreateAt")
+	s.CreateIndexIfNotExists("idx_bulk_email_recipients_status", "BulkEmailRecipients", "Status")
+}
+
+func (s SqlBulkEmailStore) Save(bulkEmail *model.BulkEmail) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(bulkEmail.Id) > 0 {
+			result.Err = model.NewAppError("SqlBulkEmailStore.Save", "store.sql_bulk_email.save.existing.app_error", nil, "id="+bulkEmail.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		bulkEmail.PreSave()
+		if result.Err = bulkEmail.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(bulkEmail); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.Save", "store.sql_bulk_email.save.app_error", nil, "id="+bulkEmail.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = bulkEmail
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBulkEmailStore) Update(bulkEmail *model.BulkEmail) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		bulkEmail.PreUpdate()
+		if result.Err = bulkEmail.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(bulkEmail); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.Update", "store.sql_bulk_email.update.app_error", nil, "id="+bulkEmail.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlBulkEmailStore.Update", "store.sql_bulk_email.update.app_error", nil, "id="+bulkEmail.Id, http.StatusNotFound)
+		} else {
+			result.Data = bulkEmail
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBulkEmailStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var bulkEmail model.BulkEmail
+
+		// Read from the master so that the progress is up to date
+		if err := s.GetMaster().SelectOne(&bulkEmail, "SELECT * FROM BulkEmails WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlBulkEmailStore.Get", "store.sql_bulk_email.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlBulkEmailStore.Get", "store.sql_bulk_email.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &bulkEmail
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns a page of the bulk emails, most recently created first.
+func (s SqlBulkEmailStore) GetAll(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var bulkEmails []*model.BulkEmail
+
+		if _, err := s.GetMaster().Select(&bulkEmails, "SELECT * FROM BulkEmails ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.GetAll", "store.sql_bulk_email.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = bulkEmails
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveRecipients saves the users that a bulk email is sent to as pending recipients. Either all of
+// them are saved or none are.
+func (s SqlBulkEmailStore) SaveRecipients(bulkEmailId string, userIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.SaveRecipients", "store.sql_bulk_email.save_recipients.open_transaction.app_error", nil, "bulk_email_id="+bulkEmailId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		updateAt := model.GetMillis()
+
+		for _, userId := range userIds {
+			recipient := &model.BulkEmailRecipient{
+				BulkEmailId: bulkEmailId,
+				UserId:      userId,
+				Status:      model.BULK_EMAIL_RECIPIENT_STATUS_PENDING,
+				UpdateAt:    updateAt,
+			}
+
+			if err := transaction.Insert(recipient); err != nil {
+				transaction.Rollback()
+				result.Err = model.NewAppError("SqlBulkEmailStore.SaveRecipients", "store.sql_bulk_email.save_recipients.app_error", nil, "bulk_email_id="+bulkEmailId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.SaveRecipients", "store.sql_bulk_email.save_recipients.commit_transaction.app_error", nil, "bulk_email_id="+bulkEmailId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = len(userIds)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBulkEmailStore) UpdateRecipient(recipient *model.BulkEmailRecipient) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		recipient.PreUpdate()
+		if !model.IsValidBulkEmailRecipientStatus(recipient.Status) {
+			result.Err = model.NewAppError("SqlBulkEmailStore.UpdateRecipient", "store.sql_bulk_email.update_recipient.status.app_error", nil, "bulk_email_id="+recipient.BulkEmailId+", status="+recipient.Status, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(recipient); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.UpdateRecipient", "store.sql_bulk_email.update_recipient.app_error", nil, "bulk_email_id="+recipient.BulkEmailId+", user_id="+recipient.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlBulkEmailStore.UpdateRecipient", "store.sql_bulk_email.update_recipient.app_error", nil, "bulk_email_id="+recipient.BulkEmailId+", user_id="+recipient.UserId, http.StatusNotFound)
+		} else {
+			result.Data = recipient
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetRecipients returns a page of the recipients of a bulk email ordered by user id. If status isn't
+// empty, only the recipients with that status are returned.
+func (s SqlBulkEmailStore) GetRecipients(bulkEmailId string, status string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := "SELECT * FROM BulkEmailRecipients WHERE BulkEmailId = :BulkEmailId"
+		if len(status) > 0 {
+			query += " AND Status = :Status"
+		}
+		query += " ORDER BY UserId LIMIT :Limit OFFSET :Offset"
+
+		var recipients []*model.BulkEmailRecipient
+
+		if _, err := s.GetMaster().Select(&recipients, query, map[string]interface{}{"BulkEmailId": bulkEmailId, "Status": status, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlBulkEmailStore.GetRecipients", "store.sql_bulk_email.get_recipients.app_error", nil, "bulk_email_id="+bulkEmailId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = recipients
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestBulkEmailStore(t *testing.T) {
+	Setup()
+
+	e1 := &model.BulkEmail{Segment: model.BULK_EMAIL_SEGMENT_ALL, UserId: model.NewId(), Message: "message", SendDirectMessage: true}
+	if result := <-store.BulkEmail().Save(e1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.BulkEmail().Save(e1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing bulk email")
+	}
+
+	e2 := &model.BulkEmail{Segment: model.BULK_EMAIL_SEGMENT_TEAM, TeamId: model.NewId(), UserId: model.NewId(), Subject: "subject", Message: "message", SendEmail: true}
+	e2 = Must(store.BulkEmail().Save(e2)).(*model.BulkEmail)
+
+	e1.Status = model.BULK_EMAIL_STATUS_RUNNING
+	e1.SentCount = 5
+	if result := <-store.BulkEmail().Update(e1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.BulkEmail().Get(e1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.BulkEmail); saved.Status != model.BULK_EMAIL_STATUS_RUNNING || saved.SentCount != 5 {
+		t.Fatal("should have updated the bulk email")
+	}
+
+	if result := <-store.BulkEmail().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found a bulk email")
+	}
+
+	if result := <-store.BulkEmail().GetAll(0, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if bulkEmails := result.Data.([]*model.BulkEmail); len(bulkEmails) != 1 || bulkEmails[0].Id != e2.Id {
+		t.Fatal("should have returned the most recent bulk email first")
+	}
+}
+
+func TestBulkEmailStoreRecipients(t *testing.T) {
+	Setup()
+
+	e1 := &model.BulkEmail{Segment: model.BULK_EMAIL_SEGMENT_ALL, UserId: model.NewId(), Message: "message", SendDirectMessage: true}
+	e1 = Must(store.BulkEmail().Save(e1)).(*model.BulkEmail)
+
+	userIds := []string{model.NewId(), model.NewId(), model.NewId()}
+	if result := <-store.BulkEmail().SaveRecipients(e1.Id, userIds); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.BulkEmail().SaveRecipients(e1.Id, userIds[:1]); result.Err == nil {
+		t.Fatal("shouldn't be able to save a recipient twice")
+	}
+
+	recipients := Must(store.BulkEmail().GetRecipients(e1.Id, model.BULK_EMAIL_RECIPIENT_STATUS_PENDING, 0, 100)).([]*model.BulkEmailRecipient)
+	if len(recipients) != 3 {
+		t.Fatal("should have saved every recipient as pending", len(recipients))
+	}
+
+	recipients[0].Status = model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED
+	recipients[0].Error = model.BULK_EMAIL_SKIPPED_UNSUBSCRIBED
+	if result := <-store.BulkEmail().UpdateRecipient(recipients[0]); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	recipients[1].Status = "junk"
+	if result := <-store.BulkEmail().UpdateRecipient(recipients[1]); result.Err == nil {
+		t.Fatal("should have failed with an invalid status")
+	}
+
+	if pending := Must(store.BulkEmail().GetRecipients(e1.Id, model.BULK_EMAIL_RECIPIENT_STATUS_PENDING, 0, 100)).([]*model.BulkEmailRecipient); len(pending) != 2 {
+		t.Fatal("should no longer have returned the skipped recipient as pending", len(pending))
+	}
+
+	if skipped := Must(store.BulkEmail().GetRecipients(e1.Id, model.BULK_EMAIL_RECIPIENT_STATUS_SKIPPED, 0, 100)).([]*model.BulkEmailRecipient); len(skipped) != 1 || skipped[0].Error != model.BULK_EMAIL_SKIPPED_UNSUBSCRIBED {
+		t.Fatal("should have returned the skipped recipient with its reason")
+	}
+
+	if all := Must(store.BulkEmail().GetRecipients(e1.Id, "", 0, 100)).([]*model.BulkEmailRecipient); len(all) != 3 {
+		t.Fatal("should have returned every recipient", len(all))
+	}
+}
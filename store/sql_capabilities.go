@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// SqlCapabilities describes the features of the database server beyond the SQL that every supported
+// version understands, so that better queries can be used when they're available.
+type SqlCapabilities struct {
+	DriverName string
+	Version    string
+	IsMariaDB  bool
+
+	// Upsert is whether a row can be inserted or updated in a single statement, which MySQL has always
+	// supported and Postgres supports as of 9.5
+	Upsert bool
+
+	// Json is whether the server has a native JSON column type, which is available as of Postgres 9.4,
+	// MySQL 5.7.8 and MariaDB 10.2.7
+	Json bool
+}
+
+var sqlVersionRegexp = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// detectSqlCapabilities asks the database server for its version. Any error is logged and results in
+// no optional features being used.
+func detectSqlCapabilities(dbmap *gorp.DbMap, driverName string) SqlCapabilities {
+	var query string
+	if driverName == model.DATABASE_DRIVER_POSTGRES {
+		query = "SHOW server_version"
+	} else if driverName == model.DATABASE_DRIVER_MYSQL {
+		query = "SELECT VERSION()"
+	} else {
+		return SqlCapabilities{DriverName: driverName}
+	}
+
+	version, err := dbmap.SelectStr(query)
+	if err != nil {
+		l4g.Warn(utils.T("store.sql.capabilities.warn"), err.Error())
+		return SqlCapabilities{DriverName: driverName}
+	}
+
+	capabilities := newSqlCapabilities(driverName, version)
+	l4g.Info(utils.T("store.sql.capabilities.info"), capabilities.Version, capabilities.Upsert, capabilities.Json)
+
+	return capabilities
+}
+
+// newSqlCapabilities works out the features of a database server from the version it reports, such
+// as "9.6.3", "10.1 (Debian 10.1-1)", "5.7.18-log" or "10.2.7-MariaDB".
+func newSqlCapabilities(driverName string, version string) SqlCapabilities {
+	capabilities := SqlCapabilities{
+		DriverName: driverName,
+		Version:    version,
+	}
+
+	major, minor, patch, ok := parseSqlVersion(version)
+	if !ok {
+		return capabilities
+	}
+
+	if driverName == model.DATABASE_DRIVER_POSTGRES {
+		capabilities.Upsert = isSqlVersionAtLeast(major, minor, patch, 9, 5, 0)
+		capabilities.Json = isSqlVersionAtLeast(major, minor, patch, 9, 4, 0)
+	} else if driverName == model.DATABASE_DRIVER_MYSQL {
+		capabilities.IsMariaDB = strings.Contains(strings.ToLower(version), "mariadb")
+		capabilities.Upsert = true
+
+		if capabilities.IsMariaDB {
+			capabilities.Json = isSqlVersionAtLeast(major, minor, patch, 10, 2, 7)
+		} else {
+			capabilities.Json = isSqlVersionAtLeast(major, minor, patch, 5, 7, 8)
+		}
+	}
+
+	return capabilities
+}
+
+func parseSqlVersion(version string) (int, int, int, bool) {
+	matches := sqlVersionRegexp.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return 0, 0, 0, false
+	}
+
+	parts := [3]int{}
+	for i, match := range matches[1:] {
+		if len(match) > 0 {
+			parts[i], _ = strconv.Atoi(match)
+		}
+	}
+
+	return parts[0], parts[1], parts[2], true
+}
+
+func isSqlVersionAtLeast(major, minor, patch, wantMajor, wantMinor, wantPatch int) bool {
+	if major != wantMajor {
+		return major > wantMajor
+	}
+
+	if minor != wantMinor {
+		return minor > wantMinor
+	}
+
+	return patch >= wantPatch
+}
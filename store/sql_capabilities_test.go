@@ -0,0 +1,36 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestNewSqlCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		DriverName string
+		Version    string
+		IsMariaDB  bool
+		Upsert     bool
+		Json       bool
+	}{
+		{model.DATABASE_DRIVER_POSTGRES, "9.3.17", false, false, false},
+		{model.DATABASE_DRIVER_POSTGRES, "9.4.12", false, false, true},
+		{model.DATABASE_DRIVER_POSTGRES, "9.6.3", false, true, true},
+		{model.DATABASE_DRIVER_POSTGRES, "10.1 (Debian 10.1-1.pgdg90+1)", false, true, true},
+		{model.DATABASE_DRIVER_MYSQL, "5.6.36-log", false, true, false},
+		{model.DATABASE_DRIVER_MYSQL, "5.7.18", false, true, true},
+		{model.DATABASE_DRIVER_MYSQL, "10.1.23-MariaDB-9+deb9u1", true, true, false},
+		{model.DATABASE_DRIVER_MYSQL, "10.2.7-MariaDB", true, true, true},
+		{model.DATABASE_DRIVER_POSTGRES, "unknown", false, false, false},
+	} {
+		capabilities := newSqlCapabilities(tc.DriverName, tc.Version)
+
+		if capabilities.IsMariaDB != tc.IsMariaDB || capabilities.Upsert != tc.Upsert || capabilities.Json != tc.Json {
+			t.Fatal("wrong capabilities for "+tc.DriverName+" "+tc.Version, capabilities)
+		}
+	}
+}
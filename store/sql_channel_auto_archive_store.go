@@ -0,0 +1,262 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlChannelAutoArchiveStore struct {
+	*SqlStore
+}
+
+func NewSqlChannelAutoArchiveStore(sqlStore *SqlStore) ChannelAutoArchiveStore {
+	s := &SqlChannelAutoArchiveStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ChannelAutoArchive{}, "ChannelAutoArchives").SetKeys(false, "ChannelId")
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("ExemptBy").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlChannelAutoArchiveStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_channelautoarchives_archive_at", "ChannelAutoArchives", "ArchiveAt")
+}
+
+// Save creates or replaces the auto-archiving state of a channel.
+func (s SqlChannelAutoArchiveStore) Save(archive *model.ChannelAutoArchive) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		archive.PreSave()
+		if result.Err = archive.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if exists, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM ChannelAutoArchives WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": archive.ChannelId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Save", "store.sql_channel_auto_archive.save.app_error", nil, "channel_id="+archive.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+		} else if exists > 0 {
+			if _, err := s.GetMaster().Update(archive); err != nil {
+				result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Save", "store.sql_channel_auto_archive.save.app_error", nil, "channel_id="+archive.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else if err := s.GetMaster().Insert(archive); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Save", "store.sql_channel_auto_archive.save.app_error", nil, "channel_id="+archive.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		if result.Err == nil {
+			result.Data = archive
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelAutoArchiveStore) Get(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var archive model.ChannelAutoArchive
+		if err := s.GetReplica().SelectOne(&archive, "SELECT * FROM ChannelAutoArchives WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Get", "store.sql_channel_auto_archive.get.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Get", "store.sql_channel_auto_archive.get.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &archive
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelAutoArchiveStore) Delete(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ChannelAutoArchives WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.Delete", "store.sql_channel_auto_archive.delete.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetIdleChannels returns the public and private channels, other than the default channel of each team,
+// that haven't been posted in since the given time and that haven't already been warned about or
+// exempted, least recently posted in first.
+func (s SqlChannelAutoArchiveStore) GetIdleChannels(since int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var channels []*model.Channel
+		if _, err := s.GetReplica().Select(&channels,
+			`SELECT
+				Channels.*
+			FROM
+				Channels
+			WHERE
+				Channels.DeleteAt = 0
+				AND Channels.Type IN ('O', 'P')
+				AND Channels.Name != :DefaultChannel
+				AND Channels.CreateAt < :Since
+				AND Channels.LastPostAt < :Since
+				AND NOT EXISTS (SELECT 1 FROM ChannelAutoArchives WHERE ChannelAutoArchives.ChannelId = Channels.Id)
+			ORDER BY Channels.LastPostAt ASC, Channels.Id ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"DefaultChannel": model.DEFAULT_CHANNEL, "Since": since, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.GetIdleChannels", "store.sql_channel_auto_archive.get_idle_channels.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channels
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the warned channels whose grace period has ended by the given time.
+func (s SqlChannelAutoArchiveStore) GetDue(now int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var archives []*model.ChannelAutoArchive
+		if _, err := s.GetReplica().Select(&archives,
+			`SELECT
+				*
+			FROM
+				ChannelAutoArchives
+			WHERE
+				Exempt = :Exempt
+				AND ArchiveAt <= :Now
+			ORDER BY ArchiveAt ASC, ChannelId ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"Exempt": false, "Now": now, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.GetDue", "store.sql_channel_auto_archive.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = archives
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ResetActive forgets the warnings about the channels that have been posted in since they were warned
+// about, so that they're only warned about again once they've been idle for long enough. It returns
+// the number of channels that were reset.
+func (s SqlChannelAutoArchiveStore) ResetActive() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`DELETE FROM
+				ChannelAutoArchives
+			WHERE
+				Exempt = :Exempt
+				AND WarnedAt < (SELECT Channels.LastPostAt FROM Channels WHERE Channels.Id = ChannelAutoArchives.ChannelId)`,
+			map[string]interface{}{"Exempt": false}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.ResetActive", "store.sql_channel_auto_archive.reset_active.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.ResetActive", "store.sql_channel_auto_archive.reset_active.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// upcomingChannelArchivalsQuery selects the channels that have been warned about and haven't been
+// posted in since.
+const upcomingChannelArchivalsQuery = `
+	FROM
+		ChannelAutoArchives, Channels, Teams
+	WHERE
+		Channels.Id = ChannelAutoArchives.ChannelId
+		AND Teams.Id = Channels.TeamId
+		AND ChannelAutoArchives.Exempt = :Exempt
+		AND Channels.DeleteAt = 0
+		AND Channels.LastPostAt <= ChannelAutoArchives.WarnedAt`
+
+func (s SqlChannelAutoArchiveStore) GetUpcoming(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var archivals []*model.UpcomingChannelArchival
+		if _, err := s.GetReplica().Select(&archivals,
+			`SELECT
+				Channels.Id, Channels.TeamId, Teams.Name AS TeamName, Channels.Name, Channels.DisplayName,
+				Channels.Type, Channels.LastPostAt, ChannelAutoArchives.WarnedAt, ChannelAutoArchives.ArchiveAt`+upcomingChannelArchivalsQuery+`
+			ORDER BY ChannelAutoArchives.ArchiveAt ASC, Channels.Id ASC
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Exempt": false, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.GetUpcoming", "store.sql_channel_auto_archive.get_upcoming.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = archivals
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelAutoArchiveStore) GetUpcomingCount() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(Channels.Id)"+upcomingChannelArchivalsQuery, map[string]interface{}{"Exempt": false}); err != nil {
+			result.Err = model.NewAppError("SqlChannelAutoArchiveStore.GetUpcomingCount", "store.sql_channel_auto_archive.get_upcoming_count.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestChannelAutoArchiveStoreSave(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	if result := <-store.ChannelAutoArchive().Get(channelId); result.Err == nil {
+		t.Fatal("shouldn't have been warned about yet")
+	}
+
+	Must(store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: channelId, WarnedAt: 1000, ArchiveAt: 2000}))
+	Must(store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: channelId, Exempt: true, ExemptBy: model.NewId()}))
+
+	if result := <-store.ChannelAutoArchive().Get(channelId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if archive := result.Data.(*model.ChannelAutoArchive); !archive.Exempt || archive.ArchiveAt != 0 {
+		t.Fatal("the warning should have been replaced by the exemption")
+	}
+
+	if result := <-store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: channelId}); result.Err == nil {
+		t.Fatal("shouldn't save a channel that's neither warned about nor exempt")
+	}
+
+	Must(store.ChannelAutoArchive().Delete(channelId))
+
+	if result := <-store.ChannelAutoArchive().Get(channelId); result.Err == nil {
+		t.Fatal("should have been deleted")
+	}
+}
+
+func TestChannelAutoArchiveStoreIdleAndDue(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{
+		DisplayName: "Name",
+		Name:        model.NewId(),
+		Email:       model.NewId() + "@nowhere.com",
+		Type:        model.TEAM_OPEN,
+	})).(*model.Team)
+
+	idle := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	exempt := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_PRIVATE})).(*model.Channel)
+	active := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	town := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "Town Square", Name: model.DEFAULT_CHANNEL, Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	since := model.GetMillis() + 60*1000
+	Must(store.Post().Save(&model.Post{UserId: model.NewId(), ChannelId: active.Id, Message: "test", CreateAt: since + 1}))
+	Must(store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: exempt.Id, Exempt: true, ExemptBy: model.NewId()}))
+
+	isIdle := func(channelId string) bool {
+		for _, channel := range Must(store.ChannelAutoArchive().GetIdleChannels(since, 10000)).([]*model.Channel) {
+			if channel.Id == channelId {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !isIdle(idle.Id) {
+		t.Fatal("should have returned the idle channel")
+	} else if isIdle(exempt.Id) || isIdle(active.Id) || isIdle(town.Id) {
+		t.Fatal("should only have returned idle channels that aren't exempt or the default channel")
+	}
+
+	warnedAt := model.GetMillis()
+	Must(store.ChannelAutoArchive().Save(&model.ChannelAutoArchive{ChannelId: idle.Id, WarnedAt: warnedAt, ArchiveAt: warnedAt + 1000}))
+
+	if isIdle(idle.Id) {
+		t.Fatal("shouldn't return a channel that has already been warned about")
+	}
+
+	isDue := func(now int64) bool {
+		for _, archive := range Must(store.ChannelAutoArchive().GetDue(now, 10000)).([]*model.ChannelAutoArchive) {
+			if archive.ChannelId == exempt.Id {
+				t.Fatal("shouldn't return exempt channels")
+			} else if archive.ChannelId == idle.Id {
+				return true
+			}
+		}
+		return false
+	}
+
+	if isDue(warnedAt + 999) {
+		t.Fatal("shouldn't be due before the end of the grace period")
+	} else if !isDue(warnedAt + 1000) {
+		t.Fatal("should be due at the end of the grace period")
+	}
+
+	found := false
+	for _, archival := range Must(store.ChannelAutoArchive().GetUpcoming(0, 10000)).([]*model.UpcomingChannelArchival) {
+		if archival.Id == idle.Id {
+			found = true
+
+			if archival.TeamName != team.Name || archival.ArchiveAt != warnedAt+1000 {
+				t.Fatal("should have returned the team and the archive time of the channel")
+			}
+		} else if archival.Id == exempt.Id {
+			t.Fatal("shouldn't report exempt channels")
+		}
+	}
+
+	if !found {
+		t.Fatal("should have reported the upcoming archival")
+	}
+
+	if count := Must(store.ChannelAutoArchive().GetUpcomingCount()).(int64); count < 1 {
+		t.Fatal("should have counted the upcoming archival")
+	}
+
+	Must(store.Post().Save(&model.Post{UserId: model.NewId(), ChannelId: idle.Id, Message: "test", CreateAt: warnedAt + 1}))
+	Must(store.ChannelAutoArchive().ResetActive())
+
+	if result := <-store.ChannelAutoArchive().Get(idle.Id); result.Err == nil {
+		t.Fatal("the warning should have been reset after the channel was posted in")
+	}
+
+	if result := <-store.ChannelAutoArchive().Get(exempt.Id); result.Err != nil {
+		t.Fatal("the exemption shouldn't have been reset")
+	}
+}
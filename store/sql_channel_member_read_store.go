@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlChannelMemberReadStore struct {
+	*SqlStore
+}
+
+func NewSqlChannelMemberReadStore(sqlStore *SqlStore) ChannelMemberReadStore {
+	s := &SqlChannelMemberReadStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ChannelMemberRead{}, "ChannelMemberReads").SetKeys(false, "ChannelId", "UserId")
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("LastPostId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlChannelMemberReadStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_channelmemberreads_user_id", "ChannelMemberReads", "UserId")
+	s.CreateIndexIfNotExists("idx_channelmemberreads_last_read_at", "ChannelMemberReads", "LastReadAt")
+}
+
+// Save records the member's last read post in the channel, replacing the one recorded before.
+func (s SqlChannelMemberReadStore) Save(read *model.ChannelMemberRead) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		read.PreSave()
+		if result.Err = read.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		query := `UPDATE
+				ChannelMemberReads
+			SET
+				LastPostId = :LastPostId,
+				LastReadAt = :LastReadAt
+			WHERE
+				ChannelId = :ChannelId
+				AND UserId = :UserId`
+
+		params := map[string]interface{}{
+			"ChannelId":  read.ChannelId,
+			"UserId":     read.UserId,
+			"LastPostId": read.LastPostId,
+			"LastReadAt": read.LastReadAt,
+		}
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlChannelMemberReadStore.Save", "store.sql_channel_member_read.save.app_error", nil, "channel_id="+read.ChannelId+", user_id="+read.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			if err := s.GetMaster().Insert(read); err != nil {
+				// The member may have viewed the channel from another device at the same time
+				if _, err := s.GetMaster().Exec(query, params); err != nil {
+					result.Err = model.NewAppError("SqlChannelMemberReadStore.Save", "store.sql_channel_member_read.save.app_error", nil, "channel_id="+read.ChannelId+", user_id="+read.UserId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = read
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelMemberReadStore) Get(channelId, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var read model.ChannelMemberRead
+
+		if err := s.GetReplica().SelectOne(&read, "SELECT * FROM ChannelMemberReads WHERE ChannelId = :ChannelId AND UserId = :UserId", map[string]interface{}{"ChannelId": channelId, "UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlChannelMemberReadStore.Get", "store.sql_channel_member_read.get.app_error", nil, "channel_id="+channelId+", user_id="+userId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlChannelMemberReadStore.Get", "store.sql_channel_member_read.get.app_error", nil, "channel_id="+channelId+", user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &read
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetReadSince returns the reads of the members of the channel that viewed it at or after the given time.
+func (s SqlChannelMemberReadStore) GetReadSince(channelId string, since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var reads []*model.ChannelMemberRead
+
+		if _, err := s.GetReplica().Select(&reads, "SELECT * FROM ChannelMemberReads WHERE ChannelId = :ChannelId AND LastReadAt >= :Since ORDER BY LastReadAt", map[string]interface{}{"ChannelId": channelId, "Since": since}); err != nil {
+			result.Err = model.NewAppError("SqlChannelMemberReadStore.GetReadSince", "store.sql_channel_member_read.get_read_since.app_error", nil, "channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = reads
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelMemberReadStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ChannelMemberReads WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelMemberReadStore.PermanentDeleteByUser", "store.sql_channel_member_read.permanent_delete_by_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelMemberReadStore) PermanentDeleteByChannel(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ChannelMemberReads WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelMemberReadStore.PermanentDeleteByChannel", "store.sql_channel_member_read.permanent_delete_by_channel.app_error", nil, "channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestChannelMemberReadStore(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	read1 := &model.ChannelMemberRead{ChannelId: channelId, UserId: userId1, LastPostId: model.NewId(), LastReadAt: 1000}
+	Must(store.ChannelMemberRead().Save(read1))
+
+	read2 := &model.ChannelMemberRead{ChannelId: channelId, UserId: userId2, LastPostId: model.NewId(), LastReadAt: 2000}
+	Must(store.ChannelMemberRead().Save(read2))
+
+	update := &model.ChannelMemberRead{ChannelId: channelId, UserId: userId1, LastPostId: model.NewId(), LastReadAt: 3000}
+	Must(store.ChannelMemberRead().Save(update))
+
+	if result := <-store.ChannelMemberRead().Get(channelId, userId1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.ChannelMemberRead); saved.LastPostId != update.LastPostId || saved.LastReadAt != 3000 {
+		t.Fatal("should have replaced the existing read")
+	}
+
+	if result := <-store.ChannelMemberRead().GetReadSince(channelId, 2500); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if reads := result.Data.([]*model.ChannelMemberRead); len(reads) != 1 || reads[0].UserId != userId1 {
+		t.Fatal("should only have returned the reads since the time")
+	}
+
+	if result := <-store.ChannelMemberRead().GetReadSince(channelId, 0); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if reads := result.Data.([]*model.ChannelMemberRead); len(reads) != 2 || reads[0].UserId != userId2 {
+		t.Fatal("should have returned every read in order")
+	}
+
+	Must(store.ChannelMemberRead().PermanentDeleteByUser(userId1))
+	if result := <-store.ChannelMemberRead().Get(channelId, userId1); result.Err == nil {
+		t.Fatal("should have deleted the user's reads")
+	}
+
+	Must(store.ChannelMemberRead().PermanentDeleteByChannel(channelId))
+	if result := <-store.ChannelMemberRead().Get(channelId, userId2); result.Err == nil {
+		t.Fatal("should have deleted the channel's reads")
+	}
+}
@@ -32,17 +32,19 @@ const (
 	CHANNEL_MEMBERS_COUNTS_CACHE_SEC  = 1800 // 30 mins
 
 	CHANNEL_CACHE_SEC = 900 // 15 mins
+
+	CHANNEL_MEMBERS_BATCH_CHUNK_SIZE = 100
 )
 
 type SqlChannelStore struct {
 	*SqlStore
 }
 
-var channelMemberCountsCache = utils.NewLru(CHANNEL_MEMBERS_COUNTS_CACHE_SIZE)
-var allChannelMembersForUserCache = utils.NewLru(ALL_CHANNEL_MEMBERS_FOR_USER_CACHE_SIZE)
-var allChannelMembersNotifyPropsForChannelCache = utils.NewLru(ALL_CHANNEL_MEMBERS_NOTIFY_PROPS_FOR_CHANNEL_CACHE_SIZE)
-var channelCache = utils.NewLru(model.CHANNEL_CACHE_SIZE)
-var channelByNameCache = utils.NewLru(model.CHANNEL_CACHE_SIZE)
+var channelMemberCountsCache = utils.NewNamedLru("channel_member_counts", CHANNEL_MEMBERS_COUNTS_CACHE_SIZE)
+var allChannelMembersForUserCache = utils.NewNamedLru("all_channel_members_for_user", ALL_CHANNEL_MEMBERS_FOR_USER_CACHE_SIZE)
+var allChannelMembersNotifyPropsForChannelCache = utils.NewNamedLru("all_channel_members_notify_props", ALL_CHANNEL_MEMBERS_NOTIFY_PROPS_FOR_CHANNEL_CACHE_SIZE)
+var channelCache = utils.NewNamedLru("channel", model.CHANNEL_CACHE_SIZE)
+var channelByNameCache = utils.NewNamedLru("channel_by_name", model.CHANNEL_CACHE_SIZE)
 
 func ClearChannelCaches() {
 	channelMemberCountsCache.Purge()
@@ -66,12 +68,17 @@ func NewSqlChannelStore(sqlStore *SqlStore) ChannelStore {
 		table.ColMap("Header").SetMaxSize(1024)
 		table.ColMap("Purpose").SetMaxSize(250)
 		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("AllowedReactions").SetMaxSize(model.CHANNEL_ALLOWED_REACTIONS_MAX_LENGTH)
+		table.ColMap("ModerationSettings").SetMaxSize(512)
+		table.ColMap("AnnouncementPosters").SetMaxSize(model.CHANNEL_ANNOUNCEMENT_POSTERS_MAX_LENGTH)
 
 		tablem := db.AddTableWithName(model.ChannelMember{}, "ChannelMembers").SetKeys(false, "ChannelId", "UserId")
 		tablem.ColMap("ChannelId").SetMaxSize(26)
 		tablem.ColMap("UserId").SetMaxSize(26)
 		tablem.ColMap("Roles").SetMaxSize(64)
 		tablem.ColMap("NotifyProps").SetMaxSize(2000)
+
+		s.initSidebarCategories(db)
 	}
 
 	return s
@@ -83,11 +90,14 @@ func (s SqlChannelStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_channels_update_at", "Channels", "UpdateAt")
 	s.CreateIndexIfNotExists("idx_channels_create_at", "Channels", "CreateAt")
 	s.CreateIndexIfNotExists("idx_channels_delete_at", "Channels", "DeleteAt")
+	s.CreateIndexIfNotExists("idx_channels_last_post_at", "Channels", "LastPostAt")
 
 	s.CreateIndexIfNotExists("idx_channelmembers_channel_id", "ChannelMembers", "ChannelId")
 	s.CreateIndexIfNotExists("idx_channelmembers_user_id", "ChannelMembers", "UserId")
 
 	s.CreateFullTextIndexIfNotExists("idx_channels_txt", "Channels", "Name, DisplayName")
+
+	s.createSidebarCategoryIndexesIfNotExists()
 }
 
 func (s SqlChannelStore) Save(channel *model.Channel) StoreChannel {
@@ -98,16 +108,20 @@ func (s SqlChannelStore) Save(channel *model.Channel) StoreChannel {
 		if channel.Type == model.CHANNEL_DIRECT {
 			result.Err = model.NewLocAppError("SqlChannelStore.Save", "store.sql_channel.save.direct_channel.app_error", nil, "")
 		} else {
-			if transaction, err := s.GetMaster().Begin(); err != nil {
-				result.Err = model.NewLocAppError("SqlChannelStore.Save", "store.sql_channel.save.open_transaction.app_error", nil, err.Error())
-			} else {
-				result = s.saveChannelT(transaction, channel)
-				if result.Err != nil {
-					transaction.Rollback()
+			channelId := channel.Id
+			if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+				// saveChannelT gives the channel its id, so undo that from any previous attempt
+				channel.Id = channelId
+				if result = s.saveChannelT(transaction, channel); result.Err != nil {
+					return result.Err
+				}
+
+				return nil
+			}); err != nil {
+				if appErr, ok := err.(*model.AppError); ok {
+					result.Err = appErr
 				} else {
-					if err := transaction.Commit(); err != nil {
-						result.Err = model.NewLocAppError("SqlChannelStore.Save", "store.sql_channel.save.commit_transaction.app_error", nil, err.Error())
-					}
+					result.Err = model.NewLocAppError("SqlChannelStore.Save", "store.sql_channel.save.commit_transaction.app_error", nil, err.Error())
 				}
 			}
 		}
@@ -168,7 +182,12 @@ func (s SqlChannelStore) SaveDirectChannel(directchannel *model.Channel, member1
 					member2.ChannelId = newChannel.Id
 
 					member1Result := s.saveMemberT(transaction, member1, newChannel)
-					member2Result := s.saveMemberT(transaction, member2, newChannel)
+					member2Result := member1Result
+
+					// A user's direct channel with themselves has a single member
+					if member1.UserId != member2.UserId {
+						member2Result = s.saveMemberT(transaction, member2, newChannel)
+					}
 
 					if member1Result.Err != nil || member2Result.Err != nil {
 						transaction.Rollback()
@@ -344,6 +363,64 @@ func (s SqlChannelStore) GetChannelUnread(channelId, userId string) StoreChannel
 	return storeChannel
 }
 
+// GetChannelLastRead finds the last post the user read in the channel and counts the posts after it.
+func (s SqlChannelStore) GetChannelLastRead(channelId, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var lastRead model.ChannelLastRead
+		err := s.GetReplica().SelectOne(&lastRead,
+			`SELECT
+				Channels.TeamId TeamId,
+				ChannelMembers.ChannelId ChannelId,
+				ChannelMembers.UserId UserId,
+				ChannelMembers.LastViewedAt LastViewedAt,
+				ChannelMembers.MentionCount MentionCount,
+				COALESCE((SELECT
+						Posts.Id
+					FROM
+						Posts
+					WHERE
+						Posts.ChannelId = ChannelMembers.ChannelId
+						AND Posts.CreateAt <= ChannelMembers.LastViewedAt
+						AND Posts.DeleteAt = 0
+					ORDER BY Posts.CreateAt DESC
+					LIMIT 1), '') LastReadPostId,
+				(SELECT
+						COUNT(*)
+					FROM
+						Posts
+					WHERE
+						Posts.ChannelId = ChannelMembers.ChannelId
+						AND Posts.CreateAt > ChannelMembers.LastViewedAt
+						AND Posts.DeleteAt = 0) MsgCount
+			FROM
+				Channels, ChannelMembers
+			WHERE
+				Channels.Id = ChannelMembers.ChannelId
+				AND ChannelMembers.ChannelId = :ChannelId
+				AND ChannelMembers.UserId = :UserId
+				AND Channels.DeleteAt = 0`,
+			map[string]interface{}{"ChannelId": channelId, "UserId": userId})
+
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetChannelLastRead", "store.sql_channel.get_last_read.app_error", nil, "channelId="+channelId+" "+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+		} else {
+			result.Data = &lastRead
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlChannelStore) InvalidateChannel(id string) {
 	channelCache.Remove(id)
 }
@@ -730,6 +807,38 @@ func (s SqlChannelStore) GetTeamChannels(teamId string) StoreChannel {
 	return storeChannel
 }
 
+// GetAutoAddChannelsForTeam returns the announcement channels of a team that every member of the
+// team is added to.
+func (s SqlChannelStore) GetAutoAddChannelsForTeam(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var channels []*model.Channel
+		if _, err := s.GetReplica().Select(&channels,
+			`SELECT
+				*
+			FROM
+				Channels
+			WHERE
+				TeamId = :TeamId
+				AND IsAnnouncement = :IsAnnouncement
+				AND AutoAddTeamMembers = :AutoAddTeamMembers
+				AND DeleteAt = 0
+			ORDER BY DisplayName`, map[string]interface{}{"TeamId": teamId, "IsAnnouncement": true, "AutoAddTeamMembers": true}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetAutoAddChannelsForTeam", "store.sql_channel.get_auto_add_channels_for_team.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channels
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) GetByName(teamId string, name string, allowFromCache bool) StoreChannel {
 	return s.getByName(teamId, name, false, allowFromCache)
 }
@@ -850,6 +959,73 @@ func (s SqlChannelStore) SaveMember(member *model.ChannelMember) StoreChannel {
 	return storeChannel
 }
 
+// SaveMembers adds the members to the channel in transactions of CHANNEL_MEMBERS_BATCH_CHUNK_SIZE members
+// each. If a chunk fails, the chunks before it stay saved.
+func (s SqlChannelStore) SaveMembers(channelId string, members []*model.ChannelMember) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		var result StoreResult
+		saved := []*model.ChannelMember{}
+
+		if cr := <-s.GetFromMaster(channelId); cr.Err != nil {
+			result.Err = cr.Err
+		} else {
+			channel := cr.Data.(*model.Channel)
+
+			for start := 0; start < len(members); start += CHANNEL_MEMBERS_BATCH_CHUNK_SIZE {
+				end := start + CHANNEL_MEMBERS_BATCH_CHUNK_SIZE
+				if end > len(members) {
+					end = len(members)
+				}
+
+				transaction, err := s.GetMaster().Begin()
+				if err != nil {
+					result.Err = model.NewLocAppError("SqlChannelStore.SaveMembers", "store.sql_channel.save_member.open_transaction.app_error", nil, err.Error())
+					break
+				}
+
+				for _, member := range members[start:end] {
+					member.ChannelId = channelId
+					if result = s.saveMemberT(transaction, member, channel); result.Err != nil {
+						break
+					}
+				}
+
+				if result.Err != nil {
+					transaction.Rollback()
+					break
+				}
+
+				if err := transaction.Commit(); err != nil {
+					result.Err = model.NewLocAppError("SqlChannelStore.SaveMembers", "store.sql_channel.save_member.commit_transaction.app_error", nil, err.Error())
+					break
+				}
+
+				saved = append(saved, members[start:end]...)
+			}
+
+			if len(saved) > 0 {
+				// If sucessfull record members have changed in channel
+				if mu := <-s.extraUpdated(channel); mu.Err != nil && result.Err == nil {
+					result.Err = mu.Err
+				}
+			}
+		}
+
+		for _, member := range saved {
+			s.InvalidateAllChannelMembersForUser(member.UserId)
+		}
+
+		result.Data = saved
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) saveMemberT(transaction *gorp.Transaction, member *model.ChannelMember, channel *model.Channel) StoreResult {
 	result := StoreResult{}
 
@@ -1240,6 +1416,58 @@ func (s SqlChannelStore) RemoveMember(channelId string, userId string) StoreChan
 	return storeChannel
 }
 
+func (s SqlChannelStore) RemoveMembers(channelId string, userIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if cr := <-s.Get(channelId, true); cr.Err != nil {
+			result.Err = cr.Err
+		} else {
+			channel := cr.Data.(*model.Channel)
+
+			for start := 0; start < len(userIds); start += CHANNEL_MEMBERS_BATCH_CHUNK_SIZE {
+				end := start + CHANNEL_MEMBERS_BATCH_CHUNK_SIZE
+				if end > len(userIds) {
+					end = len(userIds)
+				}
+
+				props := map[string]interface{}{"ChannelId": channelId}
+				idQuery := ""
+
+				for index, userId := range userIds[start:end] {
+					if len(idQuery) > 0 {
+						idQuery += ", "
+					}
+
+					props["UserId"+strconv.Itoa(index)] = userId
+					idQuery += ":UserId" + strconv.Itoa(index)
+				}
+
+				if _, err := s.GetMaster().Exec("DELETE FROM ChannelMembers WHERE ChannelId = :ChannelId AND UserId IN ("+idQuery+")", props); err != nil {
+					result.Err = model.NewLocAppError("SqlChannelStore.RemoveMembers", "store.sql_channel.remove_member.app_error", nil, "channel_id="+channelId+", "+err.Error())
+					break
+				}
+			}
+
+			// If sucessfull record members have changed in channel
+			if mu := <-s.extraUpdated(channel); mu.Err != nil && result.Err == nil {
+				result.Err = mu.Err
+			}
+		}
+
+		for _, userId := range userIds {
+			s.InvalidateAllChannelMembersForUser(userId)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlChannelStore) PermanentDeleteMembersByUser(userId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -1641,3 +1869,273 @@ func (s SqlChannelStore) GetMembersByIds(channelId string, userIds []string) Sto
 
 	return storeChannel
 }
+
+// GetActiveSince returns up to limit channels that have had a post since the given time, most recently active first.
+func (s SqlChannelStore) GetActiveSince(since int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var data []*model.Channel
+		_, err := s.GetReplica().Select(&data, "SELECT * FROM Channels WHERE LastPostAt >= :Since AND DeleteAt = 0 ORDER BY LastPostAt DESC LIMIT :Limit", map[string]interface{}{"Since": since, "Limit": limit})
+
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetActiveSince", "store.sql_channel.get_active_since.app_error", nil, "since="+strconv.FormatInt(since, 10)+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = data
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateMembersRolesForUser replaces the roles of every channel membership of the user.
+func (s SqlChannelStore) UpdateMembersRolesForUser(userId string, roles string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE ChannelMembers SET Roles = :Roles WHERE UserId = :UserId", map[string]interface{}{"Roles": roles, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.UpdateMembersRolesForUser", "store.sql_channel.update_members_roles_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// inactiveChannelsQuery selects the public and private channels that haven't been posted in since a
+// time, using the last post time of the channel so that posts aren't scanned. Channels created after
+// that time are never reported.
+const inactiveChannelsQuery = `
+	FROM
+		Channels, Teams
+	WHERE
+		Teams.Id = Channels.TeamId
+		AND Channels.DeleteAt = 0
+		AND Channels.Type IN ('O', 'P')
+		AND Channels.CreateAt < :Since
+		AND Channels.LastPostAt < :Since`
+
+func (s SqlChannelStore) GetInactiveChannels(since int64, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var channels []*model.InactiveChannel
+		if _, err := s.GetReplica().Select(&channels,
+			`SELECT
+				Channels.Id, Channels.TeamId, Teams.Name AS TeamName, Channels.Name, Channels.DisplayName,
+				Channels.Type, Channels.CreateAt, Channels.LastPostAt, Channels.TotalMsgCount`+inactiveChannelsQuery+`
+			ORDER BY Channels.LastPostAt ASC, Channels.Name ASC
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Since": since, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetInactiveChannels", "store.sql_channel.get_inactive_channels.app_error", nil, err.Error())
+		} else {
+			result.Data = channels
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelStore) GetInactiveChannelsCount(since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(Channels.Id)"+inactiveChannelsQuery, map[string]interface{}{"Since": since}); err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetInactiveChannelsCount", "store.sql_channel.get_inactive_channels_count.app_error", nil, err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAdminCount returns the number of members of a channel that are channel admins and whose
+// accounts are active. It reads from the master so that it can be used right after a member leaves.
+func (s SqlChannelStore) GetAdminCount(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetMaster().SelectInt(`
+			SELECT
+				COUNT(ChannelMembers.UserId)
+			FROM
+				ChannelMembers, Users
+			WHERE
+				ChannelMembers.UserId = Users.Id
+				AND ChannelMembers.ChannelId = :ChannelId
+				AND ChannelMembers.Roles LIKE :AdminRole
+				AND Users.DeleteAt = 0`,
+			map[string]interface{}{"ChannelId": channelId, "AdminRole": "%" + model.ROLE_CHANNEL_ADMIN.Id + "%"}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetAdminCount", "store.sql_channel.get_admin_count.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAdminIds returns the ids of the members of a channel that are channel admins and whose accounts
+// are active, in the order that they joined the channel.
+func (s SqlChannelStore) GetAdminIds(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+		if _, err := s.GetReplica().Select(&userIds, `
+			SELECT
+				ChannelMembers.UserId
+			FROM
+				ChannelMembers, Users
+			WHERE
+				ChannelMembers.UserId = Users.Id
+				AND ChannelMembers.ChannelId = :ChannelId
+				AND ChannelMembers.Roles LIKE :AdminRole
+				AND Users.DeleteAt = 0
+			ORDER BY ChannelMembers.JoinAt ASC, ChannelMembers.UserId ASC`,
+			map[string]interface{}{"ChannelId": channelId, "AdminRole": "%" + model.ROLE_CHANNEL_ADMIN.Id + "%"}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetAdminIds", "store.sql_channel.get_admin_ids.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetLongestTenuredMember returns the member that has been in a channel the longest and could be made
+// a channel admin of it, which excludes guests, bots and deactivated users. Members that joined before
+// join times were recorded are treated as having joined first.
+func (s SqlChannelStore) GetLongestTenuredMember(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var member model.ChannelMember
+		if err := s.GetMaster().SelectOne(&member, `
+			SELECT
+				ChannelMembers.*
+			FROM
+				ChannelMembers, Users
+			WHERE
+				ChannelMembers.UserId = Users.Id
+				AND ChannelMembers.ChannelId = :ChannelId
+				AND ChannelMembers.Roles NOT LIKE :GuestRole
+				AND Users.DeleteAt = 0
+				AND NOT EXISTS (SELECT 1 FROM Bots WHERE Bots.UserId = Users.Id)
+			ORDER BY ChannelMembers.JoinAt ASC, ChannelMembers.UserId ASC
+			LIMIT 1`,
+			map[string]interface{}{"ChannelId": channelId, "GuestRole": "%" + model.ROLE_CHANNEL_GUEST.Id + "%"}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlChannelStore.GetLongestTenuredMember", "store.sql_channel.get_longest_tenured_member.missing.app_error", nil, "channel_id="+channelId, http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlChannelStore.GetLongestTenuredMember", "store.sql_channel.get_longest_tenured_member.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &member
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// orphanedChannelsQuery selects the public and private channels that don't have a channel admin whose
+// account is active.
+const orphanedChannelsQuery = `
+	FROM
+		Channels, Teams
+	WHERE
+		Teams.Id = Channels.TeamId
+		AND Channels.DeleteAt = 0
+		AND Channels.Type IN ('O', 'P')
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				ChannelMembers, Users
+			WHERE
+				ChannelMembers.UserId = Users.Id
+				AND ChannelMembers.ChannelId = Channels.Id
+				AND ChannelMembers.Roles LIKE :AdminRole
+				AND Users.DeleteAt = 0
+		)`
+
+func (s SqlChannelStore) GetOrphanedChannels(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var channels []*model.OrphanedChannel
+		if _, err := s.GetReplica().Select(&channels,
+			`SELECT
+				Channels.Id, Channels.TeamId, Teams.Name AS TeamName, Channels.Name, Channels.DisplayName,
+				Channels.Type, Channels.CreatorId, Channels.CreateAt,
+				(SELECT COUNT(*) FROM ChannelMembers WHERE ChannelMembers.ChannelId = Channels.Id) AS MemberCount`+orphanedChannelsQuery+`
+			ORDER BY Channels.CreateAt ASC, Channels.Id ASC
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"AdminRole": "%" + model.ROLE_CHANNEL_ADMIN.Id + "%", "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetOrphanedChannels", "store.sql_channel.get_orphaned_channels.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channels
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelStore) GetOrphanedChannelsCount() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(Channels.Id)"+orphanedChannelsQuery, map[string]interface{}{"AdminRole": "%" + model.ROLE_CHANNEL_ADMIN.Id + "%"}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetOrphanedChannelsCount", "store.sql_channel.get_orphaned_channels_count.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+)
+
+func (s SqlChannelStore) initSidebarCategories(db *gorp.DbMap) {
+	tablec := db.AddTableWithName(model.SidebarCategory{}, "SidebarCategories").SetKeys(false, "Id")
+	tablec.ColMap("Id").SetMaxSize(26)
+	tablec.ColMap("UserId").SetMaxSize(26)
+	tablec.ColMap("TeamId").SetMaxSize(26)
+	tablec.ColMap("DisplayName").SetMaxSize(model.SIDEBAR_CATEGORY_DISPLAY_NAME_MAX_RUNES * 4)
+
+	tablesc := db.AddTableWithName(model.SidebarChannel{}, "SidebarChannels").SetKeys(false, "CategoryId", "ChannelId")
+	tablesc.ColMap("CategoryId").SetMaxSize(26)
+	tablesc.ColMap("ChannelId").SetMaxSize(26)
+	tablesc.ColMap("UserId").SetMaxSize(26)
+}
+
+func (s SqlChannelStore) createSidebarCategoryIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_sidebarcategories_user_id", "SidebarCategories", "UserId")
+	s.CreateIndexIfNotExists("idx_sidebarchannels_user_id", "SidebarChannels", "UserId")
+	s.CreateIndexIfNotExists("idx_sidebarchannels_channel_id", "SidebarChannels", "ChannelId")
+}
+
+func (s SqlChannelStore) SaveSidebarCategory(category *model.SidebarCategoryWithChannels) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		category.PreSave()
+		if result.Err = category.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.SaveSidebarCategory", "store.sql_channel.save_sidebar_category.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		// New categories go below the user's existing ones
+		if maxSortOrder, err := transaction.SelectInt("SELECT COALESCE(MAX(SortOrder), 0) FROM SidebarCategories WHERE UserId = :UserId AND TeamId = :TeamId", map[string]interface{}{"UserId": category.UserId, "TeamId": category.TeamId}); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.SaveSidebarCategory", "store.sql_channel.save_sidebar_category.app_error", nil, "user_id="+category.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			category.SortOrder = maxSortOrder + 10
+
+			if err := transaction.Insert(&category.SidebarCategory); err != nil {
+				transaction.Rollback()
+				result.Err = model.NewAppError("SqlChannelStore.SaveSidebarCategory", "store.sql_channel.save_sidebar_category.app_error", nil, "user_id="+category.UserId+", "+err.Error(), http.StatusInternalServerError)
+			} else if err := s.saveSidebarChannelsT(transaction, category); err != nil {
+				transaction.Rollback()
+				result.Err = model.NewAppError("SqlChannelStore.SaveSidebarCategory", "store.sql_channel.save_sidebar_category.channels.app_error", nil, "user_id="+category.UserId+", "+err.Error(), http.StatusInternalServerError)
+			} else if err := transaction.Commit(); err != nil {
+				result.Err = model.NewAppError("SqlChannelStore.SaveSidebarCategory", "store.sql_channel.save_sidebar_category.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = category
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// saveSidebarChannelsT replaces the channels of the category. A channel can only be in one of a user's
+// categories for a team, so the channels are also taken out of the user's other categories.
+func (s SqlChannelStore) saveSidebarChannelsT(transaction *gorp.Transaction, category *model.SidebarCategoryWithChannels) error {
+	if _, err := transaction.Exec("DELETE FROM SidebarChannels WHERE CategoryId = :CategoryId", map[string]interface{}{"CategoryId": category.Id}); err != nil {
+		return err
+	}
+
+	if len(category.Channels) == 0 {
+		return nil
+	}
+
+	props := map[string]interface{}{"UserId": category.UserId, "TeamId": category.TeamId}
+	idQuery := ""
+	for index, channelId := range category.Channels {
+		if len(idQuery) > 0 {
+			idQuery += ", "
+		}
+
+		props["channelId"+strconv.Itoa(index)] = channelId
+		idQuery += ":channelId" + strconv.Itoa(index)
+	}
+
+	if _, err := transaction.Exec(
+		`DELETE FROM
+			SidebarChannels
+		WHERE
+			UserId = :UserId
+			AND ChannelId IN (`+idQuery+`)
+			AND CategoryId IN (SELECT Id FROM SidebarCategories WHERE UserId = :UserId AND TeamId = :TeamId)`, props); err != nil {
+		return err
+	}
+
+	for index, channelId := range category.Channels {
+		sidebarChannel := &model.SidebarChannel{
+			CategoryId: category.Id,
+			ChannelId:  channelId,
+			UserId:     category.UserId,
+			SortOrder:  int64(index) * 10,
+		}
+
+		if err := transaction.Insert(sidebarChannel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSidebarChannels returns the channels of the categories that match the where clause, leaving out
+// the channels that the user has since left.
+func (s SqlChannelStore) getSidebarChannels(where string, props map[string]interface{}) ([]*model.SidebarChannel, error) {
+	var sidebarChannels []*model.SidebarChannel
+
+	_, err := s.GetReplica().Select(&sidebarChannels,
+		`SELECT
+			SidebarChannels.*
+		FROM
+			SidebarChannels
+			INNER JOIN SidebarCategories ON SidebarCategories.Id = SidebarChannels.CategoryId
+			INNER JOIN ChannelMembers ON ChannelMembers.ChannelId = SidebarChannels.ChannelId AND ChannelMembers.UserId = SidebarChannels.UserId
+		WHERE
+			`+where+`
+		ORDER BY SidebarChannels.SortOrder`, props)
+
+	return sidebarChannels, err
+}
+
+func (s SqlChannelStore) GetSidebarCategory(categoryId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		category := &model.SidebarCategoryWithChannels{Channels: []string{}}
+
+		if err := s.GetReplica().SelectOne(&category.SidebarCategory, "SELECT * FROM SidebarCategories WHERE Id = :Id", map[string]interface{}{"Id": categoryId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlChannelStore.GetSidebarCategory", "store.sql_channel.get_sidebar_category.app_error", nil, "id="+categoryId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlChannelStore.GetSidebarCategory", "store.sql_channel.get_sidebar_category.app_error", nil, "id="+categoryId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else if sidebarChannels, err := s.getSidebarChannels("SidebarChannels.CategoryId = :CategoryId", map[string]interface{}{"CategoryId": categoryId}); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetSidebarCategory", "store.sql_channel.get_sidebar_category.app_error", nil, "id="+categoryId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			for _, sidebarChannel := range sidebarChannels {
+				category.Channels = append(category.Channels, sidebarChannel.ChannelId)
+			}
+
+			result.Data = category
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelStore) GetSidebarCategories(userId string, teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var categories []*model.SidebarCategory
+		props := map[string]interface{}{"UserId": userId, "TeamId": teamId}
+
+		if _, err := s.GetReplica().Select(&categories, "SELECT * FROM SidebarCategories WHERE UserId = :UserId AND TeamId = :TeamId ORDER BY SortOrder, CreateAt", props); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetSidebarCategories", "store.sql_channel.get_sidebar_categories.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if sidebarChannels, err := s.getSidebarChannels("SidebarCategories.UserId = :UserId AND SidebarCategories.TeamId = :TeamId", props); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetSidebarCategories", "store.sql_channel.get_sidebar_categories.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			list := make([]*model.SidebarCategoryWithChannels, 0, len(categories))
+			byId := make(map[string]*model.SidebarCategoryWithChannels)
+
+			for _, category := range categories {
+				withChannels := &model.SidebarCategoryWithChannels{SidebarCategory: *category, Channels: []string{}}
+				list = append(list, withChannels)
+				byId[category.Id] = withChannels
+			}
+
+			for _, sidebarChannel := range sidebarChannels {
+				if category, ok := byId[sidebarChannel.CategoryId]; ok {
+					category.Channels = append(category.Channels, sidebarChannel.ChannelId)
+				}
+			}
+
+			result.Data = list
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelStore) UpdateSidebarCategory(category *model.SidebarCategoryWithChannels) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		category.PreUpdate()
+		if result.Err = category.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategory", "store.sql_channel.update_sidebar_category.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := transaction.Update(&category.SidebarCategory); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategory", "store.sql_channel.update_sidebar_category.app_error", nil, "id="+category.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategory", "store.sql_channel.update_sidebar_category.app_error", nil, "id="+category.Id, http.StatusNotFound)
+		} else if err := s.saveSidebarChannelsT(transaction, category); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategory", "store.sql_channel.save_sidebar_category.channels.app_error", nil, "id="+category.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategory", "store.sql_channel.update_sidebar_category.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = category
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateSidebarCategoryOrder sorts the user's categories for the team in the order of the given ids.
+func (s SqlChannelStore) UpdateSidebarCategoryOrder(userId string, teamId string, categoryOrder []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategoryOrder", "store.sql_channel.update_sidebar_category_order.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		for index, categoryId := range categoryOrder {
+			if _, err := transaction.Exec(
+				"UPDATE SidebarCategories SET SortOrder = :SortOrder WHERE Id = :Id AND UserId = :UserId AND TeamId = :TeamId",
+				map[string]interface{}{"SortOrder": int64(index+1) * 10, "Id": categoryId, "UserId": userId, "TeamId": teamId}); err != nil {
+				transaction.Rollback()
+				result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategoryOrder", "store.sql_channel.update_sidebar_category_order.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.UpdateSidebarCategoryOrder", "store.sql_channel.update_sidebar_category_order.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlChannelStore) DeleteSidebarCategory(categoryId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.DeleteSidebarCategory", "store.sql_channel.delete_sidebar_category.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Exec("DELETE FROM SidebarChannels WHERE CategoryId = :CategoryId", map[string]interface{}{"CategoryId": categoryId}); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.DeleteSidebarCategory", "store.sql_channel.delete_sidebar_category.app_error", nil, "id="+categoryId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := transaction.Exec("DELETE FROM SidebarCategories WHERE Id = :Id", map[string]interface{}{"Id": categoryId}); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlChannelStore.DeleteSidebarCategory", "store.sql_channel.delete_sidebar_category.app_error", nil, "id="+categoryId+", "+err.Error(), http.StatusInternalServerError)
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.DeleteSidebarCategory", "store.sql_channel.delete_sidebar_category.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestChannelStoreSidebarCategories(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	channelIds := []string{}
+	for i := 0; i < 3; i++ {
+		channel := Must(store.Channel().Save(&model.Channel{
+			TeamId:      teamId,
+			DisplayName: "Name",
+			Name:        "a" + model.NewId() + "b",
+			Type:        model.CHANNEL_OPEN,
+		})).(*model.Channel)
+		Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: channel.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+		channelIds = append(channelIds, channel.Id)
+	}
+
+	c1 := &model.SidebarCategoryWithChannels{
+		SidebarCategory: model.SidebarCategory{UserId: userId, TeamId: teamId, DisplayName: "First"},
+		Channels:        []string{channelIds[1], channelIds[0]},
+	}
+	c1 = Must(store.Channel().SaveSidebarCategory(c1)).(*model.SidebarCategoryWithChannels)
+
+	c2 := &model.SidebarCategoryWithChannels{
+		SidebarCategory: model.SidebarCategory{UserId: userId, TeamId: teamId, DisplayName: "Second"},
+		Channels:        []string{channelIds[0], channelIds[2]},
+	}
+	c2 = Must(store.Channel().SaveSidebarCategory(c2)).(*model.SidebarCategoryWithChannels)
+
+	if c2.SortOrder <= c1.SortOrder {
+		t.Fatal("new categories should go last")
+	}
+
+	categories := Must(store.Channel().GetSidebarCategories(userId, teamId)).([]*model.SidebarCategoryWithChannels)
+	if len(categories) != 2 || categories[0].Id != c1.Id || categories[1].Id != c2.Id {
+		t.Fatal("should have returned both categories in order")
+	}
+
+	if len(categories[0].Channels) != 1 || categories[0].Channels[0] != channelIds[1] {
+		t.Fatal("the channel should have moved out of the first category")
+	}
+
+	if len(categories[1].Channels) != 2 || categories[1].Channels[0] != channelIds[0] || categories[1].Channels[1] != channelIds[2] {
+		t.Fatal("the second category should have its channels in order")
+	}
+
+	if other := Must(store.Channel().GetSidebarCategories(userId, model.NewId())).([]*model.SidebarCategoryWithChannels); len(other) != 0 {
+		t.Fatal("shouldn't have returned categories for another team")
+	}
+
+	c1.DisplayName = "Renamed"
+	c1.Collapsed = true
+	c1.Channels = []string{channelIds[2], channelIds[1]}
+	Must(store.Channel().UpdateSidebarCategory(c1))
+
+	if category := Must(store.Channel().GetSidebarCategory(c1.Id)).(*model.SidebarCategoryWithChannels); category.DisplayName != "Renamed" || !category.Collapsed {
+		t.Fatal("should have updated the category")
+	} else if len(category.Channels) != 2 || category.Channels[0] != channelIds[2] {
+		t.Fatal("should have updated the channels")
+	}
+
+	Must(store.Channel().UpdateSidebarCategoryOrder(userId, teamId, []string{c2.Id, c1.Id}))
+
+	categories = Must(store.Channel().GetSidebarCategories(userId, teamId)).([]*model.SidebarCategoryWithChannels)
+	if categories[0].Id != c2.Id || categories[1].Id != c1.Id {
+		t.Fatal("should have reordered the categories")
+	}
+
+	if len(categories[0].Channels) != 1 || categories[0].Channels[0] != channelIds[0] {
+		t.Fatal("the channel should have moved out of the second category")
+	}
+
+	Must(store.Channel().RemoveMember(channelIds[0], userId))
+
+	if category := Must(store.Channel().GetSidebarCategory(c2.Id)).(*model.SidebarCategoryWithChannels); len(category.Channels) != 0 {
+		t.Fatal("shouldn't return channels that the user left")
+	}
+
+	Must(store.Channel().DeleteSidebarCategory(c1.Id))
+
+	if result := <-store.Channel().GetSidebarCategory(c1.Id); result.Err == nil {
+		t.Fatal("should have deleted the category")
+	}
+
+	if categories = Must(store.Channel().GetSidebarCategories(userId, teamId)).([]*model.SidebarCategoryWithChannels); len(categories) != 1 {
+		t.Fatal("should only have one category left")
+	}
+}
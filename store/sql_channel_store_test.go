@@ -254,6 +254,63 @@ func TestGetChannelUnread(t *testing.T) {
 	}
 }
 
+func TestGetChannelLastRead(t *testing.T) {
+	Setup()
+
+	uid := model.NewId()
+	c1 := &model.Channel{TeamId: model.NewId(), Name: model.NewId(), DisplayName: "Downtown", Type: model.CHANNEL_OPEN}
+	Must(store.Channel().Save(c1))
+
+	lastViewedAt := model.GetMillis() - 10000
+	cm1 := &model.ChannelMember{ChannelId: c1.Id, UserId: uid, NotifyProps: model.GetDefaultChannelNotifyProps(), LastViewedAt: lastViewedAt, MentionCount: 1}
+	Must(store.Channel().SaveMember(cm1))
+
+	if resp := <-store.Channel().GetChannelLastRead(c1.Id, uid); resp.Err != nil {
+		t.Fatal(resp.Err)
+	} else if lastRead := resp.Data.(*model.ChannelLastRead); lastRead.LastReadPostId != "" || lastRead.MsgCount != 0 {
+		t.Fatal("should have no last read post in an empty channel")
+	}
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: uid, Message: "read", CreateAt: lastViewedAt - 2000})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: uid, Message: "last read", CreateAt: lastViewedAt})).(*model.Post)
+	Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: model.NewId(), Message: "unread", CreateAt: lastViewedAt + 1000}))
+	Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: model.NewId(), Message: "unread", CreateAt: lastViewedAt + 2000}))
+	Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "other channel", CreateAt: lastViewedAt + 2000}))
+
+	if resp := <-store.Channel().GetChannelLastRead(c1.Id, uid); resp.Err != nil {
+		t.Fatal(resp.Err)
+	} else {
+		lastRead := resp.Data.(*model.ChannelLastRead)
+		if lastRead.ChannelId != c1.Id || lastRead.TeamId != c1.TeamId || lastRead.UserId != uid {
+			t.Fatal("wrong channel")
+		}
+
+		if lastRead.LastReadPostId != o2.Id {
+			t.Fatal("wrong last read post")
+		}
+
+		if lastRead.MsgCount != 2 {
+			t.Fatal("wrong MsgCount", lastRead.MsgCount)
+		}
+
+		if lastRead.MentionCount != 1 {
+			t.Fatal("wrong MentionCount")
+		}
+	}
+
+	Must(store.Post().Delete(o2.Id, model.GetMillis()))
+
+	if resp := <-store.Channel().GetChannelLastRead(c1.Id, uid); resp.Err != nil {
+		t.Fatal(resp.Err)
+	} else if lastRead := resp.Data.(*model.ChannelLastRead); lastRead.LastReadPostId != o1.Id {
+		t.Fatal("should have skipped the deleted post")
+	}
+
+	if resp := <-store.Channel().GetChannelLastRead(c1.Id, model.NewId()); resp.Err == nil {
+		t.Fatal("should have failed for a user that isn't a member")
+	}
+}
+
 func TestChannelStoreGet(t *testing.T) {
 	Setup()
 
@@ -1715,6 +1772,44 @@ func TestChannelStoreGetMembersByIds(t *testing.T) {
 	}
 }
 
+func TestChannelStoreSaveAndRemoveMembers(t *testing.T) {
+	Setup()
+
+	o1 := model.Channel{}
+	o1.TeamId = model.NewId()
+	o1.DisplayName = "ChannelA"
+	o1.Name = "a" + model.NewId() + "b"
+	o1.Type = model.CHANNEL_OPEN
+	Must(store.Channel().Save(&o1))
+
+	members := []*model.ChannelMember{}
+	userIds := []string{}
+	for i := 0; i < CHANNEL_MEMBERS_BATCH_CHUNK_SIZE+5; i++ {
+		member := &model.ChannelMember{UserId: model.NewId(), NotifyProps: model.GetDefaultChannelNotifyProps()}
+		members = append(members, member)
+		userIds = append(userIds, member.UserId)
+	}
+
+	if saved := Must(store.Channel().SaveMembers(o1.Id, members)).([]*model.ChannelMember); len(saved) != len(members) {
+		t.Fatal("should have saved every member", len(saved))
+	}
+
+	if found := *Must(store.Channel().GetMembersByIds(o1.Id, userIds)).(*model.ChannelMembers); len(found) != len(members) {
+		t.Fatal("wrong number of members", len(found))
+	}
+
+	duplicate := &model.ChannelMember{UserId: members[0].UserId, NotifyProps: model.GetDefaultChannelNotifyProps()}
+	if r := <-store.Channel().SaveMembers(o1.Id, []*model.ChannelMember{duplicate}); r.Err == nil {
+		t.Fatal("shouldn't save an existing member")
+	}
+
+	Must(store.Channel().RemoveMembers(o1.Id, userIds[1:]))
+
+	if found := *Must(store.Channel().GetMembersByIds(o1.Id, userIds)).(*model.ChannelMembers); len(found) != 1 || found[0].UserId != userIds[0] {
+		t.Fatal("should have removed the members", len(found))
+	}
+}
+
 func TestChannelStoreAnalyticsDeletedTypeCount(t *testing.T) {
 	Setup()
 
@@ -1849,3 +1944,256 @@ func TestChannelStoreGetPinnedPosts(t *testing.T) {
 		t.Fatal("wasn't supposed to return posts")
 	}
 }
+
+func TestChannelStoreGetActiveSince(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	o2 := Must(store.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	since := model.GetMillis()
+	time.Sleep(10 * time.Millisecond)
+
+	Must(store.Post().Save(&model.Post{UserId: model.NewId(), ChannelId: o1.Id, Message: "test"}))
+	time.Sleep(10 * time.Millisecond)
+	Must(store.Post().Save(&model.Post{UserId: model.NewId(), ChannelId: o2.Id, Message: "test"}))
+
+	if r1 := <-store.Channel().GetActiveSince(since, 100); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if channels := r1.Data.([]*model.Channel); len(channels) < 2 || channels[0].Id != o2.Id || channels[1].Id != o1.Id {
+		t.Fatal("should have returned the active channels, most recent first")
+	}
+
+	if r2 := <-store.Channel().GetActiveSince(since, 1); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if channels := r2.Data.([]*model.Channel); len(channels) != 1 {
+		t.Fatal("should have limited the number of channels")
+	}
+}
+
+func TestChannelStoreGetAutoAddChannelsForTeam(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	posterId := model.NewId()
+
+	o1 := Must(store.Channel().Save(&model.Channel{
+		TeamId:              teamId,
+		DisplayName:         "Name",
+		Name:                "a" + model.NewId() + "b",
+		Type:                model.CHANNEL_OPEN,
+		IsAnnouncement:      true,
+		AnnouncementPosters: []string{posterId},
+		AutoAddTeamMembers:  true,
+	})).(*model.Channel)
+
+	Must(store.Channel().Save(&model.Channel{
+		TeamId:         teamId,
+		DisplayName:    "Name",
+		Name:           "a" + model.NewId() + "b",
+		Type:           model.CHANNEL_OPEN,
+		IsAnnouncement: true,
+	}))
+
+	Must(store.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}))
+
+	if r1 := <-store.Channel().GetAutoAddChannelsForTeam(teamId); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if channels := r1.Data.([]*model.Channel); len(channels) != 1 || channels[0].Id != o1.Id {
+		t.Fatal("should only have returned the channel that team members are added to")
+	} else if !channels[0].IsAnnouncementPoster(posterId) {
+		t.Fatal("should have saved the posters")
+	}
+
+	Must(store.Channel().Delete(o1.Id, model.GetMillis()))
+
+	if r2 := <-store.Channel().GetAutoAddChannelsForTeam(teamId); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if channels := r2.Data.([]*model.Channel); len(channels) != 0 {
+		t.Fatal("shouldn't return deleted channels")
+	}
+}
+
+func TestChannelStoreGetInactiveChannels(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{
+		DisplayName: "Name",
+		Name:        model.NewId(),
+		Email:       model.NewId() + "@nowhere.com",
+		Type:        model.TEAM_OPEN,
+	})).(*model.Team)
+
+	o1 := Must(store.Channel().Save(&model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	o2 := Must(store.Channel().Save(&model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_PRIVATE,
+	})).(*model.Channel)
+
+	since := model.GetMillis() + 60*1000
+	Must(store.Post().Save(&model.Post{UserId: model.NewId(), ChannelId: o2.Id, Message: "test", CreateAt: since + 1}))
+
+	if result := <-store.Channel().GetInactiveChannels(since, 0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, channel := range result.Data.([]*model.InactiveChannel) {
+			if channel.Id == o1.Id {
+				found = true
+
+				if channel.TeamName != team.Name || channel.Name != o1.Name {
+					t.Fatal("should have returned the team of the channel")
+				}
+			} else if channel.Id == o2.Id {
+				t.Fatal("should only have returned inactive channels")
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the inactive channel")
+		}
+	}
+
+	if result := <-store.Channel().GetInactiveChannelsCount(since); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count < 1 {
+		t.Fatal("should have counted the inactive channel")
+	}
+
+	if result := <-store.Channel().GetInactiveChannels(since, 0, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if channels := result.Data.([]*model.InactiveChannel); len(channels) != 1 {
+		t.Fatal("should have limited the number of channels")
+	}
+}
+
+func TestChannelStoreAdminSuccession(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{
+		DisplayName: "Name",
+		Name:        model.NewId(),
+		Email:       model.NewId() + "@nowhere.com",
+		Type:        model.TEAM_OPEN,
+	})).(*model.Team)
+
+	channel := Must(store.Channel().Save(&model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	users := []*model.User{}
+	for i := 0; i < 4; i++ {
+		users = append(users, Must(store.User().Save(&model.User{Email: model.NewId(), Username: "n" + model.NewId()})).(*model.User))
+	}
+
+	// users[0] is a bot, users[1] is a guest, users[2] is the longest tenured regular member
+	Must(store.Bot().Save(&model.Bot{UserId: users[0].Id, OwnerId: model.NewId()}))
+
+	roles := []string{model.ROLE_CHANNEL_USER.Id, model.ROLE_CHANNEL_GUEST.Id, model.ROLE_CHANNEL_USER.Id, model.ROLE_CHANNEL_USER.Id}
+	for i, user := range users {
+		Must(store.Channel().SaveMember(&model.ChannelMember{
+			ChannelId:   channel.Id,
+			UserId:      user.Id,
+			Roles:       roles[i],
+			NotifyProps: model.GetDefaultChannelNotifyProps(),
+			JoinAt:      int64(i + 1),
+		}))
+	}
+
+	if result := <-store.Channel().GetAdminCount(channel.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count != 0 {
+		t.Fatal("shouldn't have any channel admins")
+	}
+
+	if result := <-store.Channel().GetOrphanedChannels(0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, orphaned := range result.Data.([]*model.OrphanedChannel) {
+			if orphaned.Id == channel.Id {
+				found = true
+
+				if orphaned.TeamName != team.Name || orphaned.MemberCount != 4 {
+					t.Fatal("should have returned the team and member count of the channel")
+				}
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the orphaned channel")
+		}
+	}
+
+	if result := <-store.Channel().GetOrphanedChannelsCount(); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count < 1 {
+		t.Fatal("should have counted the orphaned channel")
+	}
+
+	if result := <-store.Channel().GetLongestTenuredMember(channel.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if member := result.Data.(*model.ChannelMember); member.UserId != users[2].Id {
+		t.Fatal("should have skipped the bot and the guest")
+	}
+
+	member := Must(store.Channel().GetMember(channel.Id, users[3].Id)).(*model.ChannelMember)
+	member.Roles = model.ROLE_CHANNEL_USER.Id + " " + model.ROLE_CHANNEL_ADMIN.Id
+	Must(store.Channel().UpdateMember(member))
+
+	if result := <-store.Channel().GetAdminCount(channel.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count != 1 {
+		t.Fatal("should have counted the channel admin")
+	}
+
+	if result := <-store.Channel().GetAdminIds(channel.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if userIds := result.Data.([]string); len(userIds) != 1 || userIds[0] != users[3].Id {
+		t.Fatal("should have returned the channel admin")
+	}
+
+	if result := <-store.Channel().GetOrphanedChannels(0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		for _, orphaned := range result.Data.([]*model.OrphanedChannel) {
+			if orphaned.Id == channel.Id {
+				t.Fatal("shouldn't have returned a channel with a channel admin")
+			}
+		}
+	}
+
+	Must(store.Channel().RemoveMember(channel.Id, users[2].Id))
+	Must(store.Channel().RemoveMember(channel.Id, users[3].Id))
+
+	if result := <-store.Channel().GetLongestTenuredMember(channel.Id); result.Err == nil {
+		t.Fatal("shouldn't have found a member that can be a channel admin")
+	}
+}
@@ -26,6 +26,7 @@ func NewSqlComplianceStore(sqlStore *SqlStore) ComplianceStore {
 		table.ColMap("Type").SetMaxSize(64)
 		table.ColMap("Keywords").SetMaxSize(512)
 		table.ColMap("Emails").SetMaxSize(1024)
+		table.ColMap("Format").SetMaxSize(32)
 	}
 
 	return s
@@ -0,0 +1,180 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+)
+
+// gorp doesn't accept a context, so the context aware store methods run their queries through
+// database/sql directly. Named parameters are expanded and values converted with the DbMap's
+// dialect and type converter so the queries and results are the same as they would be with gorp.
+// Drivers that support contexts abandon the query on the server once the context is done, others
+// only check the context before the query starts.
+
+var sqlNamedParameter = regexp.MustCompile(`:[[:word:]]+`)
+
+// newContextAppError reports a query abandoned because the request it was made for was cancelled
+// or ran out of time.
+func newContextAppError(where string, ctx context.Context) *model.AppError {
+	return model.NewAppError(where, "store.sql.context_done.app_error", nil, ctx.Err().Error(), http.StatusServiceUnavailable)
+}
+
+func (ss *SqlStore) queryContext(ctx context.Context, db *gorp.DbMap, query string, params map[string]interface{}) (*sql.Rows, error) {
+	args := []interface{}{}
+	var convertErr error
+
+	query = sqlNamedParameter.ReplaceAllStringFunc(query, func(key string) string {
+		value, ok := params[key[1:]]
+		if !ok {
+			return key
+		}
+
+		if db.TypeConverter != nil {
+			var err error
+			if value, err = db.TypeConverter.ToDb(value); err != nil {
+				convertErr = err
+			}
+		}
+
+		args = append(args, value)
+		return db.Dialect.BindVar(len(args) - 1)
+	})
+
+	if convertErr != nil {
+		return nil, convertErr
+	}
+
+	start := time.Now()
+	rows, err := db.Db.QueryContext(ctx, query, args...)
+
+	connection := "replica"
+	if db == ss.master {
+		connection = "master"
+	}
+	recordSqlQuery(connection, query, time.Since(start))
+
+	return rows, err
+}
+
+// selectContext runs a query and appends a row to holder, a pointer to a slice of struct
+// pointers, for each result.
+func (ss *SqlStore) selectContext(ctx context.Context, db *gorp.DbMap, holder interface{}, query string, params map[string]interface{}) error {
+	rows, err := ss.queryContext(ctx, db, query, params)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	slice := reflect.ValueOf(holder).Elem()
+	elemType := slice.Type().Elem().Elem()
+
+	fields, err := sqlColumnFields(rows, elemType)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanSqlRow(db, rows, fields, elem.Elem()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+
+	return rows.Err()
+}
+
+// selectOneContext runs a query and scans the first result into holder, a pointer to a struct. It
+// returns sql.ErrNoRows if there were no results.
+func (ss *SqlStore) selectOneContext(ctx context.Context, db *gorp.DbMap, holder interface{}, query string, params map[string]interface{}) error {
+	rows, err := ss.queryContext(ctx, db, query, params)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	value := reflect.ValueOf(holder).Elem()
+
+	fields, err := sqlColumnFields(rows, value.Type())
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	return scanSqlRow(db, rows, fields, value)
+}
+
+// sqlColumnFields matches each column in the results to the index of the struct field with the
+// same name, ignoring case like gorp does, or -1 if there isn't one.
+func sqlColumnFields(rows *sql.Rows, structType reflect.Type) ([]int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		indexes[strings.ToLower(structType.Field(i).Name)] = i
+	}
+
+	fields := make([]int, len(columns))
+	for i, column := range columns {
+		if index, ok := indexes[strings.ToLower(column)]; ok {
+			fields[i] = index
+		} else {
+			fields[i] = -1
+		}
+	}
+
+	return fields, nil
+}
+
+func scanSqlRow(db *gorp.DbMap, rows *sql.Rows, fields []int, value reflect.Value) error {
+	dest := make([]interface{}, len(fields))
+	scanners := []gorp.CustomScanner{}
+
+	for i, index := range fields {
+		if index < 0 {
+			dest[i] = new(interface{})
+			continue
+		}
+
+		target := value.Field(index).Addr().Interface()
+		if db.TypeConverter != nil {
+			if scanner, ok := db.TypeConverter.FromDb(target); ok {
+				target = scanner.Holder
+				scanners = append(scanners, scanner)
+			}
+		}
+		dest[i] = target
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	for _, scanner := range scanners {
+		if err := scanner.Bind(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// contextTestDriver answers every query with the same rows and remembers the last query and arguments.
+type contextTestDriver struct {
+	columns []string
+	rows    [][]driver.Value
+	query   string
+	args    []driver.Value
+}
+
+type contextTestConn struct{ driver *contextTestDriver }
+type contextTestStmt struct {
+	conn  *contextTestConn
+	query string
+}
+type contextTestRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *contextTestDriver) Open(name string) (driver.Conn, error) { return &contextTestConn{d}, nil }
+
+func (c *contextTestConn) Prepare(query string) (driver.Stmt, error) {
+	return &contextTestStmt{c, query}, nil
+}
+func (c *contextTestConn) Close() error              { return nil }
+func (c *contextTestConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+func (s *contextTestStmt) Close() error  { return nil }
+func (s *contextTestStmt) NumInput() int { return -1 }
+func (s *contextTestStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (s *contextTestStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.query = s.query
+	s.conn.driver.args = args
+	return &contextTestRows{s.conn.driver.columns, s.conn.driver.rows}, nil
+}
+
+func (r *contextTestRows) Columns() []string { return r.columns }
+func (r *contextTestRows) Close() error      { return nil }
+func (r *contextTestRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSelectContext(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	testDriver := &contextTestDriver{
+		columns: []string{"id", "message", "props", "fileids", "unknown"},
+		rows: [][]driver.Value{
+			{"post1", "hello", `{"key":"value"}`, `["file1"]`, int64(1)},
+			{"post2", "world", `{}`, `[]`, int64(2)},
+		},
+	}
+	driverName := "context_test_" + model.NewId()
+	sql.Register(driverName, testDriver)
+
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ss := &SqlStore{master: &gorp.DbMap{Db: db, TypeConverter: mattermConverter{}, Dialect: gorp.PostgresDialect{}}}
+
+	var posts []*model.Post
+	if err := ss.selectContext(context.Background(), ss.master, &posts, "SELECT * FROM Posts WHERE ChannelId = :ChannelId AND Type != :Missing LIMIT :Limit", map[string]interface{}{"ChannelId": "channel1", "Limit": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if testDriver.query != "SELECT * FROM Posts WHERE ChannelId = $1 AND Type != :Missing LIMIT $2" {
+		t.Fatal("should have expanded the named parameters", testDriver.query)
+	} else if len(testDriver.args) != 2 || testDriver.args[0] != "channel1" || testDriver.args[1] != int64(2) {
+		t.Fatal("should have passed the parameters in order", testDriver.args)
+	}
+
+	if len(posts) != 2 {
+		t.Fatal("should have returned both posts")
+	} else if posts[0].Id != "post1" || posts[0].Message != "hello" || posts[1].Id != "post2" {
+		t.Fatal("should have scanned the columns into the matching fields")
+	} else if posts[0].Props["key"] != "value" || len(posts[0].FileIds) != 1 || posts[0].FileIds[0] != "file1" {
+		t.Fatal("should have converted the columns with the type converter")
+	}
+
+	var post model.Post
+	if err := ss.selectOneContext(context.Background(), ss.master, &post, "SELECT * FROM Posts", nil); err != nil {
+		t.Fatal(err)
+	} else if post.Id != "post1" {
+		t.Fatal("should have scanned the first post")
+	}
+
+	testDriver.rows = nil
+	if err := ss.selectOneContext(context.Background(), ss.master, &post, "SELECT * FROM Posts", nil); err != sql.ErrNoRows {
+		t.Fatal("should have returned no rows", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := ss.selectContext(ctx, ss.master, &posts, "SELECT * FROM Posts", nil); err == nil {
+		t.Fatal("should not run the query once the context is done")
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlDeviceStore struct {
+	*SqlStore
+}
+
+func NewSqlDeviceStore(sqlStore *SqlStore) DeviceStore {
+	s := &SqlDeviceStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Device{}, "Devices").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("SessionId").SetMaxSize(26)
+		table.ColMap("Platform").SetMaxSize(32)
+		table.ColMap("AppVersion").SetMaxSize(model.DEVICE_APP_VERSION_MAX_LENGTH)
+		table.ColMap("PushToken").SetMaxSize(model.DEVICE_PUSH_TOKEN_MAX_LENGTH)
+		table.ColMap("UserAgent").SetMaxSize(model.DEVICE_USER_AGENT_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlDeviceStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_devices_user_id", "Devices", "UserId")
+	s.CreateIndexIfNotExists("idx_devices_session_id", "Devices", "SessionId")
+	s.CreateIndexIfNotExists("idx_devices_last_seen_at", "Devices", "LastSeenAt")
+}
+
+func (s SqlDeviceStore) Save(device *model.Device) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		device.PreSave()
+		if result.Err = device.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(device); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.Save", "store.sql_device.save.app_error", nil, "id="+device.Id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = device
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) Update(device *model.Device) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		device.PreSave()
+		if result.Err = device.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := s.GetMaster().Update(device); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.Update", "store.sql_device.update.app_error", nil, "id="+device.Id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = device
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var device model.Device
+
+		if err := s.GetReplica().SelectOne(&device, "SELECT * FROM Devices WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlDeviceStore.Get", "store.sql_device.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlDeviceStore.Get", "store.sql_device.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &device
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) GetBySessionId(sessionId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var device model.Device
+
+		if err := s.GetMaster().SelectOne(&device, "SELECT * FROM Devices WHERE SessionId = :SessionId", map[string]interface{}{"SessionId": sessionId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlDeviceStore.GetBySessionId", "store.sql_device.get.app_error", nil, "session_id="+sessionId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlDeviceStore.GetBySessionId", "store.sql_device.get.app_error", nil, "session_id="+sessionId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &device
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForUser returns the devices that the user is still signed in on, most recently seen first.
+func (s SqlDeviceStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var devices []*model.Device
+
+		if _, err := s.GetReplica().Select(&devices,
+			`SELECT
+				Devices.*
+			FROM
+				Devices
+				INNER JOIN Sessions ON Sessions.Id = Devices.SessionId
+			WHERE
+				Devices.UserId = :UserId
+			ORDER BY
+				Devices.LastSeenAt DESC`, map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.GetForUser", "store.sql_device.get_for_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = devices
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) UpdateLastSeenAt(sessionId string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE Devices SET LastSeenAt = :LastSeenAt WHERE SessionId = :SessionId", map[string]interface{}{"LastSeenAt": time, "SessionId": sessionId}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.UpdateLastSeenAt", "store.sql_device.update_last_seen_at.app_error", nil, "session_id="+sessionId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetVersionCounts counts the devices that users are signed in on and that have been seen since
+// the given time by platform and app version.
+func (s SqlDeviceStore) GetVersionCounts(since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var counts []*model.DeviceVersionCount
+
+		if _, err := s.GetReplica().Select(&counts,
+			`SELECT
+				Devices.Platform AS Platform,
+				Devices.AppVersion AS AppVersion,
+				COUNT(*) AS Count
+			FROM
+				Devices
+				INNER JOIN Sessions ON Sessions.Id = Devices.SessionId
+			WHERE
+				Devices.LastSeenAt >= :Since
+			GROUP BY
+				Devices.Platform,
+				Devices.AppVersion
+			ORDER BY
+				Count DESC,
+				Platform,
+				AppVersion`, map[string]interface{}{"Since": since}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.GetVersionCounts", "store.sql_device.get_version_counts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = counts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetUserIdsByAppVersion returns the ids of the users that are signed in on a version of an app and
+// that have used it since the given time.
+func (s SqlDeviceStore) GetUserIdsByAppVersion(platform string, appVersion string, since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+
+		if _, err := s.GetReplica().Select(&userIds,
+			`SELECT DISTINCT
+				Devices.UserId
+			FROM
+				Devices
+				INNER JOIN Sessions ON Sessions.Id = Devices.SessionId
+			WHERE
+				Devices.Platform = :Platform
+				AND Devices.AppVersion = :AppVersion
+				AND Devices.LastSeenAt >= :Since`, map[string]interface{}{"Platform": platform, "AppVersion": appVersion, "Since": since}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.GetUserIdsByAppVersion", "store.sql_device.get_user_ids_by_app_version.app_error", nil, "platform="+platform+", app_version="+appVersion+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) DeleteBySessionId(sessionId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Devices WHERE SessionId = :SessionId", map[string]interface{}{"SessionId": sessionId}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.DeleteBySessionId", "store.sql_device.delete.app_error", nil, "session_id="+sessionId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDeviceStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Devices WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlDeviceStore.PermanentDeleteByUser", "store.sql_device.permanent_delete_by_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestDeviceStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	session := Must(store.Session().Save(&model.Session{UserId: userId})).(*model.Session)
+	otherSession := Must(store.Session().Save(&model.Session{UserId: userId})).(*model.Session)
+
+	device := Must(store.Device().Save(&model.Device{UserId: userId, SessionId: session.Id, Platform: model.DEVICE_PLATFORM_IOS, AppVersion: "1.0.0"})).(*model.Device)
+	Must(store.Device().Save(&model.Device{UserId: userId, SessionId: otherSession.Id, Platform: model.DEVICE_PLATFORM_WEB}))
+
+	// Devices for sessions that no longer exist shouldn't be returned
+	Must(store.Device().Save(&model.Device{UserId: userId, SessionId: model.NewId(), Platform: model.DEVICE_PLATFORM_DESKTOP}))
+
+	if result := <-store.Device().Save(&model.Device{UserId: userId, SessionId: session.Id, Platform: "unknown"}); result.Err == nil {
+		t.Fatal("shouldn't save an invalid device")
+	}
+
+	if found := Must(store.Device().GetBySessionId(session.Id)).(*model.Device); found.Id != device.Id {
+		t.Fatal("found the wrong device")
+	}
+
+	device.PushToken = "apple:token"
+	device.AppVersion = "1.1.0"
+	Must(store.Device().Update(device))
+
+	if found := Must(store.Device().Get(device.Id)).(*model.Device); found.PushToken != device.PushToken || found.AppVersion != "1.1.0" {
+		t.Fatal("should have updated the device")
+	}
+
+	Must(store.Device().UpdateLastSeenAt(otherSession.Id, device.LastSeenAt+1000))
+
+	if devices := Must(store.Device().GetForUser(userId)).([]*model.Device); len(devices) != 2 {
+		t.Fatal("should have returned the devices with sessions", len(devices))
+	} else if devices[0].SessionId != otherSession.Id || devices[0].LastSeenAt != device.LastSeenAt+1000 {
+		t.Fatal("should have ordered the devices by when they were last seen")
+	}
+
+	found := false
+	for _, count := range Must(store.Device().GetVersionCounts(device.LastSeenAt)).([]*model.DeviceVersionCount) {
+		if count.Platform == model.DEVICE_PLATFORM_IOS && count.AppVersion == "1.1.0" && count.Count >= 1 {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have counted the device")
+	}
+
+	Must(store.Device().DeleteBySessionId(session.Id))
+
+	if result := <-store.Device().Get(device.Id); result.Err == nil {
+		t.Fatal("should have deleted the device")
+	}
+
+	Must(store.Device().PermanentDeleteByUser(userId))
+
+	if devices := Must(store.Device().GetForUser(userId)).([]*model.Device); len(devices) != 0 {
+		t.Fatal("should have deleted the user's devices")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlDraftStore struct {
+	*SqlStore
+}
+
+func NewSqlDraftStore(sqlStore *SqlStore) DraftStore {
+	s := &SqlDraftStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Draft{}, "Drafts").SetKeys(false, "UserId", "ChannelId", "RootId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_RUNES)
+		table.ColMap("Props").SetMaxSize(model.POST_PROPS_MAX_RUNES)
+		table.ColMap("FileIds").SetMaxSize(model.POST_FILEIDS_MAX_RUNES)
+	}
+
+	return s
+}
+
+// Save creates the draft or replaces the contents of the user's existing draft for the channel or thread.
+func (s SqlDraftStore) Save(draft *model.Draft) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		draft.PreSave()
+		if result.Err = draft.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		query := `UPDATE
+				Drafts
+			SET
+				Message = :Message,
+				Props = :Props,
+				FileIds = :FileIds,
+				UpdateAt = :UpdateAt
+			WHERE
+				UserId = :UserId
+				AND ChannelId = :ChannelId
+				AND RootId = :RootId`
+
+		params := map[string]interface{}{
+			"UserId":    draft.UserId,
+			"ChannelId": draft.ChannelId,
+			"RootId":    draft.RootId,
+			"Message":   draft.Message,
+			"Props":     model.StringInterfaceToJson(draft.Props),
+			"FileIds":   model.ArrayToJson(draft.FileIds),
+			"UpdateAt":  draft.UpdateAt,
+		}
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlDraftStore.Save", "store.sql_draft.save.app_error", nil, "user_id="+draft.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			if err := s.GetMaster().Insert(draft); err != nil {
+				// Another device may have saved the first draft at the same time
+				if _, err := s.GetMaster().Exec(query, params); err != nil {
+					result.Err = model.NewAppError("SqlDraftStore.Save", "store.sql_draft.save.app_error", nil, "user_id="+draft.UserId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = draft
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) Get(userId, channelId, rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var draft model.Draft
+
+		if err := s.GetReplica().SelectOne(&draft, "SELECT * FROM Drafts WHERE UserId = :UserId AND ChannelId = :ChannelId AND RootId = :RootId", map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlDraftStore.Get", "store.sql_draft.get.app_error", nil, "user_id="+userId+", channel_id="+channelId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlDraftStore.Get", "store.sql_draft.get.app_error", nil, "user_id="+userId+", channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &draft
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var drafts []*model.Draft
+
+		if _, err := s.GetReplica().Select(&drafts, "SELECT * FROM Drafts WHERE UserId = :UserId ORDER BY UpdateAt DESC", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlDraftStore.GetForUser", "store.sql_draft.get_for_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = drafts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) Delete(userId, channelId, rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM Drafts WHERE UserId = :UserId AND ChannelId = :ChannelId AND RootId = :RootId", map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId}); err != nil {
+			result.Err = model.NewAppError("SqlDraftStore.Delete", "store.sql_draft.delete.app_error", nil, "user_id="+userId+", channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlDraftStore.Delete", "store.sql_draft.delete.app_error", nil, "user_id="+userId+", channel_id="+channelId, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Drafts WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlDraftStore.PermanentDeleteByUser", "store.sql_draft.permanent_delete_by_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
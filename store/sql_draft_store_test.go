@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestDraftStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	channelId := model.NewId()
+	rootId := model.NewId()
+
+	draft := &model.Draft{UserId: userId, ChannelId: channelId, Message: "hello"}
+	if result := <-store.Draft().Save(draft); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	threadDraft := &model.Draft{UserId: userId, ChannelId: channelId, RootId: rootId, Message: "reply"}
+	if result := <-store.Draft().Save(threadDraft); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	update := &model.Draft{UserId: userId, ChannelId: channelId, Message: "hello again", FileIds: []string{model.NewId()}}
+	if result := <-store.Draft().Save(update); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Draft().Get(userId, channelId, ""); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.Draft); saved.Message != update.Message || len(saved.FileIds) != 1 {
+		t.Fatal("should have replaced the existing draft")
+	} else if saved.CreateAt != draft.CreateAt {
+		t.Fatal("shouldn't have changed the create time")
+	}
+
+	if result := <-store.Draft().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if drafts := result.Data.([]*model.Draft); len(drafts) != 2 {
+		t.Fatal("should have returned both drafts")
+	}
+
+	if result := <-store.Draft().Delete(userId, channelId, rootId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Draft().Delete(userId, channelId, rootId); result.Err == nil {
+		t.Fatal("shouldn't be able to delete a draft twice")
+	}
+
+	if result := <-store.Draft().Get(userId, channelId, rootId); result.Err == nil {
+		t.Fatal("should have deleted the draft")
+	}
+
+	Must(store.Draft().PermanentDeleteByUser(userId))
+
+	if result := <-store.Draft().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if drafts := result.Data.([]*model.Draft); len(drafts) != 0 {
+		t.Fatal("should have deleted the user's drafts")
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"context"
+	dbsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+)
+
+// sqlInstrumentedConnector opens connections that time every statement run on them, whether it goes
+// through gorp or not, and capture the plans of queries that are repeatedly slow. Every optional
+// driver interface is passed through so the wrapped driver behaves the same as it would unwrapped.
+type sqlInstrumentedConnector struct {
+	connection string
+	driver     driver.Driver
+	connector  driver.Connector
+	dataSource string
+
+	// db is the pool using the connector, which is used to capture query plans on another connection
+	db *dbsql.DB
+}
+
+func newSqlInstrumentedConnector(connection string, d driver.Driver, dataSource string) (*sqlInstrumentedConnector, error) {
+	c := &sqlInstrumentedConnector{
+		connection: connection,
+		driver:     d,
+		dataSource: dataSource,
+	}
+
+	if dc, ok := d.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dataSource)
+		if err != nil {
+			return nil, err
+		}
+
+		c.connector = connector
+	}
+
+	return c, nil
+}
+
+func (c *sqlInstrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if c.connector != nil {
+		conn, err = c.connector.Connect(ctx)
+	} else {
+		conn, err = c.driver.Open(c.dataSource)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlInstrumentedConn{conn: conn, connector: c}, nil
+}
+
+func (c *sqlInstrumentedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// observe records how long a statement took and starts capturing its plan if it has been slow
+// often enough.
+func (c *sqlInstrumentedConnector) observe(query string, args []driver.NamedValue, start time.Time, err error) {
+	// The statement wasn't run and will be retried another way
+	if err == driver.ErrSkip {
+		return
+	}
+
+	if recordSqlQuery(c.connection, query, time.Since(start)) && c.db != nil {
+		go c.explain(query, copySqlArgs(args))
+	}
+}
+
+// explain captures the plan of a query in the slow query log. Only SELECT statements are explained
+// since those are the only ones that are certain to have no side effects when explained.
+func (c *sqlInstrumentedConnector) explain(query string, args []interface{}) {
+	if !strings.HasPrefix(sqlQueryOperation(query), "SELECT") {
+		return
+	}
+
+	rows, err := c.db.Query("EXPLAIN "+query, args...)
+	if err != nil {
+		slowQueryLog.setPlan(c.connection, query, "error: "+err.Error())
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		slowQueryLog.setPlan(c.connection, query, "error: "+err.Error())
+		return
+	}
+
+	lines := []string{}
+	if len(columns) > 1 {
+		lines = append(lines, strings.Join(columns, " | "))
+	}
+
+	values := make([]dbsql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			slowQueryLog.setPlan(c.connection, query, "error: "+err.Error())
+			return
+		}
+
+		fields := make([]string, len(values))
+		for i, value := range values {
+			fields[i] = value.String
+		}
+
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+
+	slowQueryLog.setPlan(c.connection, query, redactSqlQuery(strings.Join(lines, "\n")))
+}
+
+// copySqlArgs copies the arguments of a statement so they can be used once it has returned.
+func copySqlArgs(args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if b, ok := arg.Value.([]byte); ok {
+			values[i] = append([]byte(nil), b...)
+		} else {
+			values[i] = arg.Value
+		}
+	}
+
+	return values
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if len(arg.Name) > 0 {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
+
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
+
+type sqlInstrumentedConn struct {
+	conn      driver.Conn
+	connector *sqlInstrumentedConnector
+}
+
+func (c *sqlInstrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlInstrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if cpc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = cpc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newSqlInstrumentedStmt(stmt, query, c.connector), nil
+}
+
+func (c *sqlInstrumentedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sqlInstrumentedConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+func (c *sqlInstrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cbt, ok := c.conn.(driver.ConnBeginTx); ok {
+		return cbt.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != driver.IsolationLevel(dbsql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.conn.Begin()
+}
+
+func (c *sqlInstrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var result driver.Result
+	var err error
+	if ec, ok := c.conn.(driver.ExecerContext); ok {
+		result, err = ec.ExecContext(ctx, query, args)
+	} else if e, ok := c.conn.(driver.Execer); ok {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err = e.Exec(query, values)
+	} else {
+		return nil, driver.ErrSkip
+	}
+
+	c.connector.observe(query, args, start, err)
+
+	return result, err
+}
+
+func (c *sqlInstrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if qc, ok := c.conn.(driver.QueryerContext); ok {
+		rows, err = qc.QueryContext(ctx, query, args)
+	} else if q, ok := c.conn.(driver.Queryer); ok {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rows, err = q.Query(query, values)
+	} else {
+		return nil, driver.ErrSkip
+	}
+
+	c.connector.observe(query, args, start, err)
+
+	return rows, err
+}
+
+func (c *sqlInstrumentedConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *sqlInstrumentedConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *sqlInstrumentedConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *sqlInstrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type sqlInstrumentedStmt struct {
+	stmt      driver.Stmt
+	query     string
+	connector *sqlInstrumentedConnector
+}
+
+// sqlInstrumentedConvertingStmt is used for statements that convert their own arguments, since
+// database/sql converts arguments differently depending on whether a statement can.
+type sqlInstrumentedConvertingStmt struct {
+	*sqlInstrumentedStmt
+}
+
+func newSqlInstrumentedStmt(stmt driver.Stmt, query string, connector *sqlInstrumentedConnector) driver.Stmt {
+	s := &sqlInstrumentedStmt{stmt: stmt, query: query, connector: connector}
+
+	if _, ok := stmt.(driver.ColumnConverter); ok {
+		return &sqlInstrumentedConvertingStmt{s}
+	}
+
+	return s
+}
+
+func (s *sqlInstrumentedConvertingStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.stmt.(driver.ColumnConverter).ColumnConverter(idx)
+}
+
+func (s *sqlInstrumentedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *sqlInstrumentedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *sqlInstrumentedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+func (s *sqlInstrumentedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+func (s *sqlInstrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var result driver.Result
+	var err error
+	if sec, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = sec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err = s.stmt.Exec(values)
+	}
+
+	s.connector.observe(s.query, args, start, err)
+
+	return result, err
+}
+
+func (s *sqlInstrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if sqc, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = sqc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rows, err = s.stmt.Query(values)
+	}
+
+	s.connector.observe(s.query, args, start, err)
+
+	return rows, err
+}
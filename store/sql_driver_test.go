@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	dbsql "database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/utils"
+)
+
+// slowTestDriver takes a little over a millisecond to run each query and returns no rows.
+type slowTestDriver struct{}
+
+type slowTestConn struct{}
+
+type slowTestRows struct{}
+
+func (slowTestDriver) Open(name string) (driver.Conn, error) {
+	return slowTestConn{}, nil
+}
+
+func (slowTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (slowTestConn) Close() error {
+	return nil
+}
+
+func (slowTestConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+func (slowTestConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	time.Sleep(2 * time.Millisecond)
+	return slowTestRows{}, nil
+}
+
+func (slowTestRows) Columns() []string {
+	return []string{"plan"}
+}
+
+func (slowTestRows) Close() error {
+	return nil
+}
+
+func (slowTestRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+func TestSqlInstrumentedConnector(t *testing.T) {
+	utils.TranslationsPreInit()
+	utils.LoadConfig("config.json")
+
+	slowQueryThreshold := *utils.Cfg.SqlSettings.SlowQueryThresholdMilliseconds
+	slowQueryExplainThreshold := *utils.Cfg.SqlSettings.SlowQueryExplainThreshold
+	defer func() {
+		*utils.Cfg.SqlSettings.SlowQueryThresholdMilliseconds = slowQueryThreshold
+		*utils.Cfg.SqlSettings.SlowQueryExplainThreshold = slowQueryExplainThreshold
+		slowQueryLog.clear()
+	}()
+
+	*utils.Cfg.SqlSettings.SlowQueryThresholdMilliseconds = 1
+	*utils.Cfg.SqlSettings.SlowQueryExplainThreshold = 0
+	slowQueryLog.clear()
+
+	connector, err := newSqlInstrumentedConnector("test", slowTestDriver{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := dbsql.OpenDB(connector)
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		rows, err := db.Query("SELECT * FROM Users WHERE Email = 'someone@example.com'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+
+	queries := slowQueryLog.list()
+	if len(queries) != 1 {
+		t.Fatal("should have logged the slow query once", len(queries))
+	}
+
+	if queries[0].Connection != "test" || queries[0].Operation != "SELECT Users" || queries[0].Count != 2 {
+		t.Fatal("should have grouped the runs of the query", queries[0])
+	}
+
+	if queries[0].Query != "SELECT * FROM Users WHERE Email = '?'" {
+		t.Fatal("should have redacted the query", queries[0].Query)
+	}
+}
+
+func TestSqlSlowQueryLog(t *testing.T) {
+	log := newSqlSlowQueryLog(2)
+
+	if log.record("master", "SELECT * FROM Posts WHERE Message = 'a'", 2*time.Second, 2) {
+		t.Fatal("should not explain a query the first time it's slow")
+	}
+
+	if !log.record("master", "SELECT * FROM Posts WHERE Message = 'b'", time.Second, 2) {
+		t.Fatal("should explain a query once it has been slow enough times")
+	}
+
+	if log.record("master", "SELECT * FROM Posts WHERE Message = 'c'", time.Second, 2) {
+		t.Fatal("should only explain a query once")
+	}
+
+	log.setPlan("master", "SELECT * FROM Posts WHERE Message = 'd'", "Seq Scan on posts")
+
+	queries := log.list()
+	if len(queries) != 1 || queries[0].Count != 3 || queries[0].MaxMillis != 2000 || queries[0].TotalMillis != 4000 || queries[0].Plan != "Seq Scan on posts" {
+		t.Fatal("should have grouped the runs of the query", queries)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	log.record("replica-0", "SELECT * FROM Users", time.Second, 0)
+	time.Sleep(2 * time.Millisecond)
+	log.record("master", "SELECT * FROM Users", time.Second, 0)
+
+	queries = log.list()
+	if len(queries) != 2 || queries[0].Connection != "master" || queries[1].Connection != "replica-0" {
+		t.Fatal("should have replaced the least recently slow query", queries)
+	}
+
+	log.clear()
+	if len(log.list()) != 0 {
+		t.Fatal("should have cleared the log")
+	}
+}
@@ -14,7 +14,7 @@ const (
 	EMOJI_CACHE_SEC  = 1800 // 30 mins
 )
 
-var emojiCache *utils.Cache = utils.NewLru(EMOJI_CACHE_SIZE)
+var emojiCache *utils.Cache = utils.NewNamedLru("emoji", EMOJI_CACHE_SIZE)
 
 type SqlEmojiStore struct {
 	*SqlStore
@@ -27,7 +27,9 @@ func NewSqlEmojiStore(sqlStore *SqlStore) EmojiStore {
 		table := db.AddTableWithName(model.Emoji{}, "Emoji").SetKeys(false, "Id")
 		table.ColMap("Id").SetMaxSize(26)
 		table.ColMap("CreatorId").SetMaxSize(26)
-		table.ColMap("Name").SetMaxSize(64)
+		table.ColMap("Name").SetMaxSize(model.EMOJI_NAME_MAX_LENGTH)
+		table.ColMap("Category").SetMaxSize(32)
+		table.ColMap("Aliases").SetMaxSize(model.EMOJI_ALIASES_MAX_LENGTH)
 
 		table.SetUniqueTogether("Name", "DeleteAt")
 	}
@@ -67,6 +69,35 @@ func (es SqlEmojiStore) Save(emoji *model.Emoji) StoreChannel {
 	return storeChannel
 }
 
+func (es SqlEmojiStore) Update(emoji *model.Emoji) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		emoji.PreUpdate()
+		if result.Err = emoji.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := es.GetMaster().Update(emoji); err != nil {
+			result.Err = model.NewLocAppError("SqlEmojiStore.Update", "store.sql_emoji.update.app_error", nil, "id="+emoji.Id+", "+err.Error())
+		} else if count != 1 {
+			result.Err = model.NewLocAppError("SqlEmojiStore.Update", "store.sql_emoji.update.app_error", nil, "id="+emoji.Id)
+		} else {
+			emojiCache.Remove(emoji.Id)
+			result.Data = emoji
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (es SqlEmojiStore) Get(id string, allowFromCache bool) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -175,6 +206,47 @@ func (es SqlEmojiStore) GetAll() StoreChannel {
 	return storeChannel
 }
 
+// Search returns the emoji with a name or alias that contains the term, or that starts with it if
+// prefixOnly is set, ordered by name.
+func (es SqlEmojiStore) Search(term string, prefixOnly bool, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		// Aliases are stored as a JSON array, so each alias starts after a quote
+		nameTerm := term + "%"
+		aliasTerm := "%\"" + term + "%"
+		if !prefixOnly {
+			nameTerm = "%" + term + "%"
+			aliasTerm = "%" + term + "%"
+		}
+
+		var emoji []*model.Emoji
+
+		if _, err := es.GetReplica().Select(&emoji,
+			`SELECT
+				*
+			FROM
+				Emoji
+			WHERE
+				(Name LIKE :Name OR Aliases LIKE :Alias)
+				AND DeleteAt = 0
+			ORDER BY
+				Name
+			LIMIT :Limit`, map[string]interface{}{"Name": nameTerm, "Alias": aliasTerm, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlEmojiStore.Search", "store.sql_emoji.search.app_error", nil, "term="+term+", "+err.Error())
+		} else {
+			result.Data = emoji
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (es SqlEmojiStore) Delete(id string, time int64) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
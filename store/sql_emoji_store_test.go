@@ -172,3 +172,65 @@ func TestEmojiGetAll(t *testing.T) {
 		}
 	}
 }
+
+func TestEmojiUpdate(t *testing.T) {
+	Setup()
+
+	emoji := Must(store.Emoji().Save(&model.Emoji{CreatorId: model.NewId(), Name: model.NewId()})).(*model.Emoji)
+	defer func() {
+		Must(store.Emoji().Delete(emoji.Id, time.Now().Unix()))
+	}()
+
+	// Load the emoji into the cache so that the update has to clear it
+	Must(store.Emoji().Get(emoji.Id, true))
+
+	emoji.Category = model.EMOJI_CATEGORY_PEOPLE
+	emoji.Aliases = model.StringArray{"alias_" + model.NewId()}
+	Must(store.Emoji().Update(emoji))
+
+	if updated := Must(store.Emoji().Get(emoji.Id, true)).(*model.Emoji); updated.Category != model.EMOJI_CATEGORY_PEOPLE {
+		t.Fatal("should have updated the category")
+	} else if len(updated.Aliases) != 1 || updated.Aliases[0] != emoji.Aliases[0] {
+		t.Fatal("should have updated the aliases")
+	}
+
+	emoji.Category = "unknown"
+	if result := <-store.Emoji().Update(emoji); result.Err == nil {
+		t.Fatal("shouldn't update to an invalid category")
+	}
+}
+
+func TestEmojiSearch(t *testing.T) {
+	Setup()
+
+	prefix := "search" + model.NewId()[:10]
+
+	emojis := []*model.Emoji{
+		{CreatorId: model.NewId(), Name: prefix + "_b"},
+		{CreatorId: model.NewId(), Name: "other" + model.NewId()[:10], Aliases: model.StringArray{prefix + "_alias"}},
+		{CreatorId: model.NewId(), Name: "middle_" + prefix},
+	}
+
+	for i, emoji := range emojis {
+		emojis[i] = Must(store.Emoji().Save(emoji)).(*model.Emoji)
+	}
+	defer func() {
+		for _, emoji := range emojis {
+			Must(store.Emoji().Delete(emoji.Id, time.Now().Unix()))
+		}
+	}()
+
+	if found := Must(store.Emoji().Search(prefix, true, 10)).([]*model.Emoji); len(found) != 2 {
+		t.Fatal("should have found the emoji starting with the term by name or alias", len(found))
+	} else if found[0].Id != emojis[1].Id || found[1].Id != emojis[0].Id {
+		t.Fatal("should be ordered by name")
+	}
+
+	if found := Must(store.Emoji().Search(prefix, false, 10)).([]*model.Emoji); len(found) != 3 {
+		t.Fatal("should have found all emoji containing the term", len(found))
+	}
+
+	if found := Must(store.Emoji().Search(prefix, false, 1)).([]*model.Emoji); len(found) != 1 {
+		t.Fatal("should have limited the results", len(found))
+	}
+}
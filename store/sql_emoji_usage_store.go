@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlEmojiUsageStore struct {
+	*SqlStore
+}
+
+func NewSqlEmojiUsageStore(sqlStore *SqlStore) EmojiUsageStore {
+	s := &SqlEmojiUsageStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.EmojiUsage{}, "EmojiUsage").SetKeys(false, "UserId", "EmojiName", "Day")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("EmojiName").SetMaxSize(model.EMOJI_USAGE_NAME_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlEmojiUsageStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_emojiusage_day", "EmojiUsage", "Day")
+}
+
+// Increment adds one use of each of the given emoji to the user's counts for the day.
+func (s SqlEmojiUsageStore) Increment(userId string, emojiNames []string, day int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := `UPDATE
+				EmojiUsage
+			SET
+				Count = Count + 1
+			WHERE
+				UserId = :UserId
+				AND EmojiName = :EmojiName
+				AND Day = :Day`
+
+		for _, emojiName := range emojiNames {
+			params := map[string]interface{}{"UserId": userId, "EmojiName": emojiName, "Day": day}
+
+			if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+				result.Err = model.NewAppError("SqlEmojiUsageStore.Increment", "store.sql_emoji_usage.increment.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				break
+			} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+				usage := &model.EmojiUsage{UserId: userId, EmojiName: emojiName, Day: day, Count: 1}
+
+				if err := s.GetMaster().Insert(usage); err != nil {
+					// Another request may have recorded the first use at the same time
+					if _, err := s.GetMaster().Exec(query, params); err != nil {
+						result.Err = model.NewAppError("SqlEmojiUsageStore.Increment", "store.sql_emoji_usage.increment.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+						break
+					}
+				}
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetFrequent returns the emoji the user used most since the given day, most used first.
+func (s SqlEmojiUsageStore) GetFrequent(userId string, since int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var counts []*model.EmojiUsageCount
+
+		query := `SELECT
+				EmojiName, SUM(Count) AS Count
+			FROM
+				EmojiUsage
+			WHERE
+				UserId = :UserId
+				AND Day >= :Since
+			GROUP BY EmojiName
+			ORDER BY Count DESC, EmojiName
+			LIMIT :Limit`
+
+		if _, err := s.GetReplica().Select(&counts, query, map[string]interface{}{"UserId": userId, "Since": since, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlEmojiUsageStore.GetFrequent", "store.sql_emoji_usage.get_frequent.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = counts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEmojiUsageStore) PermanentDeleteBefore(day int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM EmojiUsage WHERE Day < :Day", map[string]interface{}{"Day": day}); err != nil {
+			result.Err = model.NewAppError("SqlEmojiUsageStore.PermanentDeleteBefore", "store.sql_emoji_usage.permanent_delete_before.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
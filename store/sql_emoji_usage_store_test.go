@@ -0,0 +1,53 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestEmojiUsageStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	today := model.GetEmojiUsageDay(model.GetMillis())
+	yesterday := today - model.EMOJI_USAGE_DAY_MILLIS
+	lastYear := today - 365*model.EMOJI_USAGE_DAY_MILLIS
+
+	Must(store.EmojiUsage().Increment(userId, []string{"smile", "+1"}, today))
+	Must(store.EmojiUsage().Increment(userId, []string{"+1"}, today))
+	Must(store.EmojiUsage().Increment(userId, []string{"+1", "tada"}, yesterday))
+	Must(store.EmojiUsage().Increment(userId, []string{"tada", "tada"}, lastYear))
+	Must(store.EmojiUsage().Increment(model.NewId(), []string{"smile"}, today))
+
+	if result := <-store.EmojiUsage().GetFrequent(userId, yesterday, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if counts := result.Data.([]*model.EmojiUsageCount); len(counts) != 3 {
+		t.Fatal("should have returned the emoji used since yesterday")
+	} else if counts[0].EmojiName != "+1" || counts[0].Count != 3 {
+		t.Fatal("should have summed the daily counts", counts[0])
+	} else if counts[1].EmojiName != "smile" || counts[1].Count != 1 || counts[2].EmojiName != "tada" || counts[2].Count != 1 {
+		t.Fatal("should have ordered ties by name")
+	}
+
+	if result := <-store.EmojiUsage().GetFrequent(userId, yesterday, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if counts := result.Data.([]*model.EmojiUsageCount); len(counts) != 1 {
+		t.Fatal("should have limited the results")
+	}
+
+	Must(store.EmojiUsage().PermanentDeleteBefore(yesterday))
+
+	if result := <-store.EmojiUsage().GetFrequent(userId, 0, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		for _, count := range result.Data.([]*model.EmojiUsageCount) {
+			if count.EmojiName == "tada" && count.Count != 1 {
+				t.Fatal("should have deleted old usage")
+			}
+		}
+	}
+}
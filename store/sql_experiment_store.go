@@ -0,0 +1,230 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlExperimentStore struct {
+	*SqlStore
+}
+
+func NewSqlExperimentStore(sqlStore *SqlStore) ExperimentStore {
+	s := &SqlExperimentStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Experiment{}, "Experiments").SetKeys(false, "Name")
+		table.ColMap("Name").SetMaxSize(model.EXPERIMENT_NAME_MAX_LENGTH)
+		table.ColMap("Description").SetMaxSize(model.EXPERIMENT_DESCRIPTION_MAX_LENGTH)
+		table.ColMap("FlagName").SetMaxSize(model.FEATURE_FLAG_NAME_MAX_LENGTH)
+		table.ColMap("Variants").SetMaxSize(512)
+
+		tableExposure := db.AddTableWithName(model.ExperimentExposure{}, "ExperimentExposures").SetKeys(false, "ExperimentName", "UserId")
+		tableExposure.ColMap("ExperimentName").SetMaxSize(model.EXPERIMENT_NAME_MAX_LENGTH)
+		tableExposure.ColMap("UserId").SetMaxSize(26)
+		tableExposure.ColMap("Variant").SetMaxSize(model.EXPERIMENT_VARIANT_MAX_LENGTH)
+	}
+
+	return s
+}
+
+// Save inserts the experiment or replaces the stored experiment with the same name.
+func (s SqlExperimentStore) Save(experiment *model.Experiment) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var existing model.Experiment
+		if err := s.GetMaster().SelectOne(&existing, "SELECT * FROM Experiments WHERE Name = :Name", map[string]interface{}{"Name": experiment.Name}); err == nil {
+			experiment.CreateAt = existing.CreateAt
+			experiment.PreUpdate()
+			if result.Err = experiment.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			if _, err := s.GetMaster().Update(experiment); err != nil {
+				result.Err = model.NewAppError("SqlExperimentStore.Save", "store.sql_experiment.save.app_error", nil, "name="+experiment.Name+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = experiment
+			}
+		} else if err == sql.ErrNoRows {
+			experiment.PreSave()
+			if result.Err = experiment.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			if err := s.GetMaster().Insert(experiment); err != nil {
+				result.Err = model.NewAppError("SqlExperimentStore.Save", "store.sql_experiment.save.app_error", nil, "name="+experiment.Name+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = experiment
+			}
+		} else {
+			result.Err = model.NewAppError("SqlExperimentStore.Save", "store.sql_experiment.save.app_error", nil, "name="+experiment.Name+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlExperimentStore) Get(name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var experiment model.Experiment
+
+		if err := s.GetReplica().SelectOne(&experiment, "SELECT * FROM Experiments WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlExperimentStore.Get", "store.sql_experiment.get.app_error", nil, "name="+name+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlExperimentStore.Get", "store.sql_experiment.get.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &experiment
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlExperimentStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var experiments []*model.Experiment
+
+		if _, err := s.GetReplica().Select(&experiments, "SELECT * FROM Experiments ORDER BY Name"); err != nil {
+			result.Err = model.NewAppError("SqlExperimentStore.GetAll", "store.sql_experiment.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = experiments
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes the experiment along with its recorded exposures.
+func (s SqlExperimentStore) Delete(name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM Experiments WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
+			result.Err = model.NewAppError("SqlExperimentStore.Delete", "store.sql_experiment.delete.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			result.Err = model.NewAppError("SqlExperimentStore.Delete", "store.sql_experiment.delete.app_error", nil, "name="+name, http.StatusNotFound)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM ExperimentExposures WHERE ExperimentName = :Name", map[string]interface{}{"Name": name}); err != nil {
+			result.Err = model.NewAppError("SqlExperimentStore.Delete", "store.sql_experiment.delete.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = name
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveExposure records that the user saw the given variant. The first exposure assigns the user to
+// the variant and later ones increment the exposure count.
+func (s SqlExperimentStore) SaveExposure(exposure *model.ExperimentExposure) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		now := model.GetMillis()
+		params := map[string]interface{}{"ExperimentName": exposure.ExperimentName, "UserId": exposure.UserId, "Variant": exposure.Variant, "Now": now}
+		query := `UPDATE
+				ExperimentExposures
+			SET
+				Variant = :Variant,
+				LastExposureAt = :Now,
+				ExposureCount = ExposureCount + 1
+			WHERE
+				ExperimentName = :ExperimentName
+				AND UserId = :UserId`
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlExperimentStore.SaveExposure", "store.sql_experiment.save_exposure.app_error", nil, "name="+exposure.ExperimentName+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			exposure.CreateAt = now
+			exposure.LastExposureAt = now
+			exposure.ExposureCount = 1
+
+			if err := s.GetMaster().Insert(exposure); err != nil {
+				// Another request may have recorded the first exposure at the same time
+				if _, err := s.GetMaster().Exec(query, params); err != nil {
+					result.Err = model.NewAppError("SqlExperimentStore.SaveExposure", "store.sql_experiment.save_exposure.app_error", nil, "name="+exposure.ExperimentName+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = exposure
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetSummary counts the users assigned to each variant of the experiment and their exposures.
+func (s SqlExperimentStore) GetSummary(name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var variants []*model.ExperimentVariantSummary
+
+		if _, err := s.GetReplica().Select(&variants,
+			`SELECT
+				Variant,
+				COUNT(*) AS Users,
+				SUM(ExposureCount) AS Exposures
+			FROM
+				ExperimentExposures
+			WHERE
+				ExperimentName = :Name
+			GROUP BY
+				Variant
+			ORDER BY
+				Variant`, map[string]interface{}{"Name": name}); err != nil {
+			result.Err = model.NewAppError("SqlExperimentStore.GetSummary", "store.sql_experiment.get_summary.app_error", nil, "name="+name+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &model.ExperimentSummary{Name: name, Variants: variants}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestExperimentStore(t *testing.T) {
+	Setup()
+
+	experiment := &model.Experiment{Name: "exp_" + model.NewId(), Variants: model.StringArray{"control", "tour"}}
+
+	if result := <-store.Experiment().Save(experiment); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	createAt := experiment.CreateAt
+
+	experiment.Active = true
+	if result := <-store.Experiment().Save(experiment); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Experiment().Get(experiment.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.Experiment); !saved.Active || saved.CreateAt != createAt || len(saved.Variants) != 2 {
+		t.Fatal("should have replaced the experiment")
+	}
+
+	if result := <-store.Experiment().GetAll(); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, saved := range result.Data.([]*model.Experiment) {
+			if saved.Name == experiment.Name {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the experiment")
+		}
+	}
+
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	Must(store.Experiment().SaveExposure(&model.ExperimentExposure{ExperimentName: experiment.Name, UserId: userId1, Variant: "control"}))
+	Must(store.Experiment().SaveExposure(&model.ExperimentExposure{ExperimentName: experiment.Name, UserId: userId1, Variant: "control"}))
+	Must(store.Experiment().SaveExposure(&model.ExperimentExposure{ExperimentName: experiment.Name, UserId: userId2, Variant: "tour"}))
+
+	if result := <-store.Experiment().GetSummary(experiment.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		summary := result.Data.(*model.ExperimentSummary)
+		if len(summary.Variants) != 2 {
+			t.Fatal("should have both variants")
+		}
+
+		for _, v := range summary.Variants {
+			if v.Variant == "control" && (v.Users != 1 || v.Exposures != 2) {
+				t.Fatal("wrong control counts", v)
+			} else if v.Variant == "tour" && (v.Users != 1 || v.Exposures != 1) {
+				t.Fatal("wrong tour counts", v)
+			}
+		}
+	}
+
+	if result := <-store.Experiment().Delete(experiment.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Experiment().Get(experiment.Name); result.Err == nil {
+		t.Fatal("should have deleted the experiment")
+	}
+
+	if result := <-store.Experiment().GetSummary(experiment.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if len(result.Data.(*model.ExperimentSummary).Variants) != 0 {
+		t.Fatal("should have deleted the exposures")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlFeatureFlagStore struct {
+	*SqlStore
+}
+
+func NewSqlFeatureFlagStore(sqlStore *SqlStore) FeatureFlagStore {
+	s := &SqlFeatureFlagStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.FeatureFlag{}, "FeatureFlags").SetKeys(false, "Name")
+		table.ColMap("Name").SetMaxSize(model.FEATURE_FLAG_NAME_MAX_LENGTH)
+		table.ColMap("Description").SetMaxSize(model.FEATURE_FLAG_DESCRIPTION_MAX_LENGTH)
+		table.ColMap("UserIds").SetMaxSize(4000)
+		table.ColMap("TeamIds").SetMaxSize(4000)
+	}
+
+	return s
+}
+
+// Save inserts the flag or replaces the stored flag with the same name.
+func (s SqlFeatureFlagStore) Save(flag *model.FeatureFlag) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var existing model.FeatureFlag
+		if err := s.GetMaster().SelectOne(&existing, "SELECT * FROM FeatureFlags WHERE Name = :Name", map[string]interface{}{"Name": flag.Name}); err == nil {
+			flag.CreateAt = existing.CreateAt
+			flag.PreUpdate()
+			if result.Err = flag.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			if _, err := s.GetMaster().Update(flag); err != nil {
+				result.Err = model.NewAppError("SqlFeatureFlagStore.Save", "store.sql_feature_flag.save.app_error", nil, "name="+flag.Name+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = flag
+			}
+		} else if err == sql.ErrNoRows {
+			flag.PreSave()
+			if result.Err = flag.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			if err := s.GetMaster().Insert(flag); err != nil {
+				result.Err = model.NewAppError("SqlFeatureFlagStore.Save", "store.sql_feature_flag.save.app_error", nil, "name="+flag.Name+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = flag
+			}
+		} else {
+			result.Err = model.NewAppError("SqlFeatureFlagStore.Save", "store.sql_feature_flag.save.app_error", nil, "name="+flag.Name+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlFeatureFlagStore) Get(name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var flag model.FeatureFlag
+
+		if err := s.GetReplica().SelectOne(&flag, "SELECT * FROM FeatureFlags WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlFeatureFlagStore.Get", "store.sql_feature_flag.get.app_error", nil, "name="+name+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlFeatureFlagStore.Get", "store.sql_feature_flag.get.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &flag
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlFeatureFlagStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var flags []*model.FeatureFlag
+
+		if _, err := s.GetReplica().Select(&flags, "SELECT * FROM FeatureFlags ORDER BY Name"); err != nil {
+			result.Err = model.NewAppError("SqlFeatureFlagStore.GetAll", "store.sql_feature_flag.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = flags
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlFeatureFlagStore) Delete(name string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM FeatureFlags WHERE Name = :Name", map[string]interface{}{"Name": name}); err != nil {
+			result.Err = model.NewAppError("SqlFeatureFlagStore.Delete", "store.sql_feature_flag.delete.app_error", nil, "name="+name+", err="+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			result.Err = model.NewAppError("SqlFeatureFlagStore.Delete", "store.sql_feature_flag.delete.app_error", nil, "name="+name, http.StatusNotFound)
+		} else {
+			result.Data = name
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
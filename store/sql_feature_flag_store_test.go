@@ -0,0 +1,63 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestFeatureFlagStore(t *testing.T) {
+	Setup()
+
+	flag := &model.FeatureFlag{Name: "flag_" + model.NewId(), Enabled: true, RolloutPercentage: 20, TeamIds: model.StringArray{model.NewId()}}
+
+	if result := <-store.FeatureFlag().Save(flag); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	createAt := flag.CreateAt
+
+	flag.RolloutPercentage = 50
+	if result := <-store.FeatureFlag().Save(flag); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.FeatureFlag().Get(flag.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.FeatureFlag); saved.RolloutPercentage != 50 || saved.CreateAt != createAt || len(saved.TeamIds) != 1 {
+		t.Fatal("should have replaced the flag")
+	}
+
+	if result := <-store.FeatureFlag().GetAll(); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, saved := range result.Data.([]*model.FeatureFlag) {
+			if saved.Name == flag.Name {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the flag")
+		}
+	}
+
+	if result := <-store.FeatureFlag().Save(&model.FeatureFlag{Name: "Bad Name"}); result.Err == nil {
+		t.Fatal("should have failed on an invalid flag")
+	}
+
+	if result := <-store.FeatureFlag().Delete(flag.Name); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.FeatureFlag().Delete(flag.Name); result.Err == nil {
+		t.Fatal("should have failed to delete a missing flag")
+	}
+
+	if result := <-store.FeatureFlag().Get(flag.Name); result.Err == nil {
+		t.Fatal("should not return a deleted flag")
+	}
+}
@@ -5,7 +5,11 @@ package store
 import (
 	"database/sql"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
+	l4g "github.com/alecthomas/log4go"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -20,7 +24,7 @@ const (
 	FILE_INFO_CACHE_SEC  = 1800 // 30 minutes
 )
 
-var fileInfoCache *utils.Cache = utils.NewLru(FILE_INFO_CACHE_SIZE)
+var fileInfoCache *utils.Cache = utils.NewNamedLru("file_info", FILE_INFO_CACHE_SIZE)
 
 func ClearFileCaches() {
 	fileInfoCache.Purge()
@@ -40,6 +44,7 @@ func NewSqlFileInfoStore(sqlStore *SqlStore) FileInfoStore {
 		table.ColMap("Name").SetMaxSize(256)
 		table.ColMap("Extension").SetMaxSize(64)
 		table.ColMap("MimeType").SetMaxSize(256)
+		table.ColMap("Content").SetMaxSize(model.FILE_INFO_CONTENT_MAX_SIZE)
 	}
 
 	return s
@@ -50,6 +55,8 @@ func (fs SqlFileInfoStore) CreateIndexesIfNotExists() {
 	fs.CreateIndexIfNotExists("idx_fileinfo_create_at", "FileInfo", "CreateAt")
 	fs.CreateIndexIfNotExists("idx_fileinfo_delete_at", "FileInfo", "DeleteAt")
 	fs.CreateIndexIfNotExists("idx_fileinfo_postid_at", "FileInfo", "PostId")
+
+	fs.CreateFullTextIndexIfNotExists("idx_fileinfo_name_content_txt", "FileInfo", "Name, Content")
 }
 
 func (fs SqlFileInfoStore) Save(info *model.FileInfo) StoreChannel {
@@ -217,10 +224,11 @@ func (fs SqlFileInfoStore) AttachToPost(fileId, postId string) StoreChannel {
 			`UPDATE
 					FileInfo
 				SET
-					PostId = :PostId
+					PostId = :PostId,
+					UpdateAt = :UpdateAt
 				WHERE
 					Id = :Id
-					AND PostId = ''`, map[string]interface{}{"PostId": postId, "Id": fileId}); err != nil {
+					AND PostId = ''`, map[string]interface{}{"PostId": postId, "UpdateAt": model.GetMillis(), "Id": fileId}); err != nil {
 			result.Err = model.NewLocAppError("SqlFileInfoStore.AttachToPost",
 				"store.sql_file_info.attach_to_post.app_error", nil, "post_id="+postId+", file_id="+fileId+", err="+err.Error())
 		}
@@ -242,9 +250,10 @@ func (fs SqlFileInfoStore) DeleteForPost(postId string) StoreChannel {
 			`UPDATE
 				FileInfo
 			SET
-				DeleteAt = :DeleteAt
+				DeleteAt = :DeleteAt,
+				UpdateAt = :UpdateAt
 			WHERE
-				PostId = :PostId`, map[string]interface{}{"DeleteAt": model.GetMillis(), "PostId": postId}); err != nil {
+				PostId = :PostId`, map[string]interface{}{"DeleteAt": model.GetMillis(), "UpdateAt": model.GetMillis(), "PostId": postId}); err != nil {
 			result.Err = model.NewLocAppError("SqlFileInfoStore.DeleteForPost",
 				"store.sql_file_info.delete_for_post.app_error", nil, "post_id="+postId+", err="+err.Error())
 		} else {
@@ -257,3 +266,293 @@ func (fs SqlFileInfoStore) DeleteForPost(postId string) StoreChannel {
 
 	return storeChannel
 }
+
+// RestoreForPost brings back the files of a post that were deleted along with it.
+func (fs SqlFileInfoStore) RestoreForPost(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := fs.GetMaster().Exec(
+			`UPDATE
+				FileInfo
+			SET
+				DeleteAt = 0,
+				UpdateAt = :UpdateAt
+			WHERE
+				PostId = :PostId
+				AND DeleteAt != 0`, map[string]interface{}{"UpdateAt": model.GetMillis(), "PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.RestoreForPost", "store.sql_file_info.restore_for_post.app_error", nil, "post_id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = postId
+		}
+
+		fs.InvalidateFileInfosForPostCache(postId)
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForPosts returns the files attached to any of the posts, including the deleted ones.
+func (fs SqlFileInfoStore) GetForPosts(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var infos []*model.FileInfo
+
+		if len(postIds) == 0 {
+			result.Data = infos
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		if _, err := fs.GetReplica().Select(&infos, "SELECT * FROM FileInfo WHERE PostId IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.GetForPosts", "store.sql_file_info.get_for_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// PermanentDeleteBatch deletes the records of files. The files themselves are left in the file store.
+func (fs SqlFileInfoStore) PermanentDeleteBatch(fileIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(fileIds) == 0 {
+			result.Data = int64(0)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, fileId := range fileIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["fileId"+strconv.Itoa(index)] = fileId
+			idQuery += ":fileId" + strconv.Itoa(index)
+		}
+
+		if res, err := fs.GetMaster().Exec("DELETE FROM FileInfo WHERE Id IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.PermanentDeleteBatch", "store.sql_file_info.permanent_delete_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			count, _ := res.RowsAffected()
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetFilesBatchForIndexing returns the files attached to posts that were updated since startTime,
+// oldest first, along with the channel of their post.
+func (fs SqlFileInfoStore) GetFilesBatchForIndexing(startTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var infos []*model.FileForIndexing
+
+		if _, err := fs.GetReplica().Select(&infos,
+			`SELECT
+				FileInfo.*, Posts.ChannelId
+			FROM
+				FileInfo
+			INNER JOIN
+				Posts ON FileInfo.PostId = Posts.Id
+			WHERE
+				FileInfo.UpdateAt >= :StartTime
+			ORDER BY
+				FileInfo.UpdateAt ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"StartTime": startTime, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlFileInfoStore.GetFilesBatchForIndexing", "store.sql_file_info.get_files_batch_for_indexing.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Search returns the files attached to posts in the channels that the user belongs to whose name
+// or extracted content matches the search terms, newest first.
+func (fs SqlFileInfoStore) Search(teamId string, userId string, params *model.SearchParams) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		queryParams := map[string]interface{}{
+			"TeamId": teamId,
+			"UserId": userId,
+		}
+
+		terms := params.Terms
+
+		if terms == "" && len(params.InChannels) == 0 && len(params.FromUsers) == 0 {
+			result.Data = []*model.FileInfo{}
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		// these chars have special meaning and can be treated as spaces
+		for _, c := range specialSearchChar {
+			terms = strings.Replace(terms, c, " ", -1)
+		}
+
+		infos := []*model.FileInfo{}
+
+		searchQuery := `
+			SELECT
+				FileInfo.*
+			FROM
+				FileInfo
+			INNER JOIN
+				Posts ON FileInfo.PostId = Posts.Id
+			WHERE
+				FileInfo.DeleteAt = 0
+				AND Posts.DeleteAt = 0
+				FILE_FILTER
+				AND Posts.ChannelId IN (
+					SELECT
+						Id
+					FROM
+						Channels,
+						ChannelMembers
+					WHERE
+						Id = ChannelId
+							AND (TeamId = :TeamId OR TeamId = '')
+							AND UserId = :UserId
+							AND DeleteAt = 0
+							CHANNEL_FILTER)
+				SEARCH_CLAUSE
+				ORDER BY FileInfo.CreateAt DESC
+			LIMIT 100`
+
+		if len(params.InChannels) > 0 {
+			inClause := ":InChannel0"
+			queryParams["InChannel0"] = params.InChannels[0]
+
+			for i := 1; i < len(params.InChannels); i++ {
+				paramName := "InChannel" + strconv.FormatInt(int64(i), 10)
+				inClause += ", :" + paramName
+				queryParams[paramName] = params.InChannels[i]
+			}
+
+			searchQuery = strings.Replace(searchQuery, "CHANNEL_FILTER", "AND Name IN ("+inClause+")", 1)
+		} else {
+			searchQuery = strings.Replace(searchQuery, "CHANNEL_FILTER", "", 1)
+		}
+
+		if len(params.FromUsers) > 0 {
+			inClause := ":FromUser0"
+			queryParams["FromUser0"] = params.FromUsers[0]
+
+			for i := 1; i < len(params.FromUsers); i++ {
+				paramName := "FromUser" + strconv.FormatInt(int64(i), 10)
+				inClause += ", :" + paramName
+				queryParams[paramName] = params.FromUsers[i]
+			}
+
+			searchQuery = strings.Replace(searchQuery, "FILE_FILTER", `
+				AND FileInfo.CreatorId IN (
+					SELECT
+						Id
+					FROM
+						Users,
+						TeamMembers
+					WHERE
+						TeamMembers.TeamId = :TeamId
+						AND Users.Id = TeamMembers.UserId
+						AND Username IN (`+inClause+`))`, 1)
+		} else {
+			searchQuery = strings.Replace(searchQuery, "FILE_FILTER", "", 1)
+		}
+
+		if terms == "" {
+			// we've already confirmed that we have a channel or user to search for
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "", 1)
+		} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+			// Parse text for wildcards
+			if wildcard, err := regexp.Compile("\\*($| )"); err == nil {
+				terms = wildcard.ReplaceAllLiteralString(terms, ":* ")
+			}
+
+			if params.OrTerms {
+				terms = strings.Join(strings.Fields(terms), " | ")
+			} else {
+				terms = strings.Join(strings.Fields(terms), " & ")
+			}
+
+			// This has to match the expression used by the full text index for it to be used
+			searchClause := "AND to_tsvector('english', FileInfo.Name || ' ' || FileInfo.Content) @@ to_tsquery('english', :Terms)"
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", searchClause, 1)
+		} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+			searchClause := "AND MATCH (FileInfo.Name, FileInfo.Content) AGAINST (:Terms IN BOOLEAN MODE)"
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", searchClause, 1)
+
+			if !params.OrTerms {
+				splitTerms := strings.Fields(terms)
+				for i, t := range strings.Fields(terms) {
+					splitTerms[i] = "+" + t
+				}
+
+				terms = strings.Join(splitTerms, " ")
+			}
+		}
+
+		queryParams["Terms"] = terms
+
+		if _, err := fs.GetReplica().Select(&infos, searchQuery, queryParams); err != nil {
+			l4g.Warn(utils.T("store.sql_file_info.search.warn"), err.Error())
+			// Don't return the error to the caller as it is of no use to the user. Instead return an empty set of search results.
+		}
+
+		result.Data = infos
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -218,3 +218,69 @@ func TestFileInfoDeleteForPost(t *testing.T) {
 		t.Fatal("shouldn't have returned any file infos")
 	}
 }
+
+func TestFileInfoSearch(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	c1 := &model.Channel{}
+	c1.TeamId = teamId
+	c1.DisplayName = "Channel1"
+	c1.Name = "a" + model.NewId() + "b"
+	c1.Type = model.CHANNEL_OPEN
+	c1 = Must(store.Channel().Save(c1)).(*model.Channel)
+
+	m1 := model.ChannelMember{}
+	m1.ChannelId = c1.Id
+	m1.UserId = userId
+	m1.NotifyProps = model.GetDefaultChannelNotifyProps()
+	Must(store.Channel().SaveMember(&m1))
+
+	c2 := &model.Channel{}
+	c2.TeamId = teamId
+	c2.DisplayName = "Channel2"
+	c2.Name = "a" + model.NewId() + "b"
+	c2.Type = model.CHANNEL_OPEN
+	c2 = Must(store.Channel().Save(c2)).(*model.Channel)
+
+	p1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: userId, Message: "report"})).(*model.Post)
+	p2 := Must(store.Post().Save(&model.Post{ChannelId: c2.Id, UserId: userId, Message: "report"})).(*model.Post)
+
+	f1 := Must(store.FileInfo().Save(&model.FileInfo{
+		CreatorId: userId,
+		PostId:    p1.Id,
+		Path:      "file.pdf",
+		Name:      "file.pdf",
+		Content:   "quarterly revenue figures",
+	})).(*model.FileInfo)
+
+	Must(store.FileInfo().Save(&model.FileInfo{
+		CreatorId: userId,
+		PostId:    p1.Id,
+		Path:      "other.pdf",
+		Name:      "other.pdf",
+		Content:   "meeting notes",
+	}))
+
+	Must(store.FileInfo().Save(&model.FileInfo{
+		CreatorId: userId,
+		PostId:    p2.Id,
+		Path:      "hidden.pdf",
+		Name:      "hidden.pdf",
+		Content:   "quarterly revenue figures",
+	}))
+
+	if infos := Must(store.FileInfo().Search(teamId, userId, &model.SearchParams{Terms: "revenue"})).([]*model.FileInfo); len(infos) != 1 || infos[0].Id != f1.Id {
+		t.Fatal("should've only found the file in the user's channel", infos)
+	}
+
+	if infos := Must(store.FileInfo().Search(teamId, userId, &model.SearchParams{Terms: "revenue notes", OrTerms: true})).([]*model.FileInfo); len(infos) != 2 {
+		t.Fatal("should've found both files in the user's channel", infos)
+	}
+
+	if infos := Must(store.FileInfo().Search(teamId, userId, &model.SearchParams{Terms: "revenue notes"})).([]*model.FileInfo); len(infos) != 0 {
+		t.Fatal("shouldn't have found a file matching all terms", infos)
+	}
+}
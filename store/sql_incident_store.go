@@ -0,0 +1,175 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlIncidentStore struct {
+	*SqlStore
+}
+
+func NewSqlIncidentStore(sqlStore *SqlStore) IncidentStore {
+	s := &SqlIncidentStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Incident{}, "Incidents").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Title").SetMaxSize(model.INCIDENT_TITLE_MAX_RUNES * 4)
+		table.ColMap("Message").SetMaxSize(model.INCIDENT_MESSAGE_MAX_RUNES * 4)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("Severity").SetMaxSize(32)
+		table.ColMap("PostId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlIncidentStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_incidents_create_at", "Incidents", "CreateAt")
+	s.CreateIndexIfNotExists("idx_incidents_resolved_at", "Incidents", "ResolvedAt")
+}
+
+func (s SqlIncidentStore) Save(incident *model.Incident) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(incident.Id) > 0 {
+			result.Err = model.NewAppError("SqlIncidentStore.Save", "store.sql_incident.save.existing.app_error", nil, "id="+incident.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		incident.PreSave()
+		if result.Err = incident.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(incident); err != nil {
+			result.Err = model.NewAppError("SqlIncidentStore.Save", "store.sql_incident.save.app_error", nil, "id="+incident.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = incident
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlIncidentStore) Update(incident *model.Incident) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		incident.PreUpdate()
+		if result.Err = incident.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(incident); err != nil {
+			result.Err = model.NewAppError("SqlIncidentStore.Update", "store.sql_incident.update.app_error", nil, "id="+incident.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlIncidentStore.Update", "store.sql_incident.update.app_error", nil, "id="+incident.Id, http.StatusNotFound)
+		} else {
+			result.Data = incident
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlIncidentStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var incident model.Incident
+
+		if err := s.GetReplica().SelectOne(&incident, "SELECT * FROM Incidents WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlIncidentStore.Get", "store.sql_incident.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlIncidentStore.Get", "store.sql_incident.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &incident
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlIncidentStore) GetAll(offset, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var incidents []*model.Incident
+
+		if _, err := s.GetReplica().Select(&incidents, "SELECT * FROM Incidents ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlIncidentStore.GetAll", "store.sql_incident.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = incidents
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForFeed returns every open incident along with the incidents resolved since the given time.
+func (s SqlIncidentStore) GetForFeed(resolvedSince int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var incidents []*model.Incident
+
+		if _, err := s.GetReplica().Select(&incidents,
+			`SELECT
+				*
+			FROM
+				Incidents
+			WHERE
+				ResolvedAt = 0
+				OR ResolvedAt > :ResolvedSince
+			ORDER BY
+				CreateAt DESC`, map[string]interface{}{"ResolvedSince": resolvedSince}); err != nil {
+			result.Err = model.NewAppError("SqlIncidentStore.GetForFeed", "store.sql_incident.get_for_feed.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = incidents
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestIncidentStoreSaveGetUpdate(t *testing.T) {
+	Setup()
+
+	incident := &model.Incident{CreatorId: model.NewId(), Title: "Database unavailable"}
+
+	if result := <-store.Incident().Save(incident); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Incident().Save(incident); result.Err == nil {
+		t.Fatal("shouldn't be able to update from save")
+	}
+
+	if result := <-store.Incident().Get(incident.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.Incident); saved.Status != model.INCIDENT_STATUS_INVESTIGATING || saved.Severity != model.INCIDENT_SEVERITY_MINOR {
+		t.Fatal("should have set the default status and severity")
+	}
+
+	incident.Status = model.INCIDENT_STATUS_RESOLVED
+	if result := <-store.Incident().Update(incident); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.Incident).ResolvedAt == 0 {
+		t.Fatal("should have set the resolved time")
+	}
+
+	if result := <-store.Incident().Get(model.NewId()); result.Err == nil {
+		t.Fatal("should have failed on a missing incident")
+	}
+
+	missing := &model.Incident{Id: model.NewId(), CreateAt: 1, CreatorId: model.NewId(), Title: "missing", Status: model.INCIDENT_STATUS_INVESTIGATING, Severity: model.INCIDENT_SEVERITY_MINOR}
+	if result := <-store.Incident().Update(missing); result.Err == nil {
+		t.Fatal("should have failed to update a missing incident")
+	}
+}
+
+func TestIncidentStoreGetForFeed(t *testing.T) {
+	Setup()
+
+	open := &model.Incident{CreatorId: model.NewId(), Title: "Open"}
+	Must(store.Incident().Save(open))
+
+	resolved := &model.Incident{CreatorId: model.NewId(), Title: "Resolved", Status: model.INCIDENT_STATUS_RESOLVED}
+	Must(store.Incident().Save(resolved))
+
+	if result := <-store.Incident().GetAll(0, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if len(result.Data.([]*model.Incident)) < 2 {
+		t.Fatal("should have returned both incidents")
+	}
+
+	if result := <-store.Incident().GetForFeed(resolved.ResolvedAt); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		foundOpen := false
+		for _, incident := range result.Data.([]*model.Incident) {
+			if incident.Id == resolved.Id {
+				t.Fatal("should not include incidents resolved before the given time")
+			} else if incident.Id == open.Id {
+				foundOpen = true
+			}
+		}
+
+		if !foundOpen {
+			t.Fatal("should include open incidents")
+		}
+	}
+
+	if result := <-store.Incident().GetForFeed(resolved.ResolvedAt - 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, incident := range result.Data.([]*model.Incident) {
+			if incident.Id == resolved.Id {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should include recently resolved incidents")
+		}
+	}
+}
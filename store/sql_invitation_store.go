@@ -0,0 +1,219 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlInvitationStore struct {
+	*SqlStore
+}
+
+func NewSqlInvitationStore(sqlStore *SqlStore) InvitationStore {
+	s := &SqlInvitationStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Invitation{}, "Invitations").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Token").SetMaxSize(model.INVITATION_TOKEN_SIZE).SetUnique(true)
+		table.ColMap("Email").SetMaxSize(128)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("InviterId").SetMaxSize(26)
+		table.ColMap("ChannelIds").SetMaxSize(model.INVITATION_CHANNEL_IDS_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlInvitationStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_invitations_team_id", "Invitations", "TeamId")
+	s.CreateIndexIfNotExists("idx_invitations_expire_at", "Invitations", "ExpireAt")
+}
+
+func (s SqlInvitationStore) Save(invitation *model.Invitation) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(invitation.Id) > 0 {
+			result.Err = model.NewAppError("SqlInvitationStore.Save", "store.sql_invitation.save.existing.app_error", nil, "id="+invitation.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		invitation.PreSave()
+		if result.Err = invitation.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(invitation); err != nil {
+			result.Err = model.NewAppError("SqlInvitationStore.Save", "store.sql_invitation.save.app_error", nil, "id="+invitation.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = invitation
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlInvitationStore) Update(invitation *model.Invitation) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = invitation.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(invitation); err != nil {
+			result.Err = model.NewAppError("SqlInvitationStore.Update", "store.sql_invitation.update.app_error", nil, "id="+invitation.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlInvitationStore.Update", "store.sql_invitation.update.app_error", nil, "id="+invitation.Id, http.StatusNotFound)
+		} else {
+			result.Data = invitation
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlInvitationStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var invitation model.Invitation
+
+		if err := s.GetReplica().SelectOne(&invitation, "SELECT * FROM Invitations WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlInvitationStore.Get", "store.sql_invitation.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlInvitationStore.Get", "store.sql_invitation.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &invitation
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlInvitationStore) GetByToken(token string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var invitation model.Invitation
+
+		// Read from the master since the invitation may have just been resent
+		if err := s.GetMaster().SelectOne(&invitation, "SELECT * FROM Invitations WHERE Token = :Token", map[string]interface{}{"Token": token}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlInvitationStore.GetByToken", "store.sql_invitation.get_by_token.app_error", nil, err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlInvitationStore.GetByToken", "store.sql_invitation.get_by_token.app_error", nil, err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &invitation
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPendingForTeam returns the invitations to a team that haven't expired by the given time, most
+// recently sent first.
+func (s SqlInvitationStore) GetPendingForTeam(teamId string, now int64, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var invitations []*model.Invitation
+
+		if _, err := s.GetReplica().Select(&invitations,
+			`SELECT
+				*
+			FROM
+				Invitations
+			WHERE
+				TeamId = :TeamId
+				AND ExpireAt > :Now
+			ORDER BY UpdateAt DESC
+			LIMIT :Limit OFFSET :Offset`, map[string]interface{}{"TeamId": teamId, "Now": now, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlInvitationStore.GetPendingForTeam", "store.sql_invitation.get_pending_for_team.app_error", nil, "team_id="+teamId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = invitations
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlInvitationStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM Invitations WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlInvitationStore.Delete", "store.sql_invitation.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlInvitationStore.Delete", "store.sql_invitation.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// DeleteExpired removes the invitations that expired before the given time and returns how many there were.
+func (s SqlInvitationStore) DeleteExpired(before int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM Invitations WHERE ExpireAt <= :Before", map[string]interface{}{"Before": before}); err != nil {
+			result.Err = model.NewAppError("SqlInvitationStore.DeleteExpired", "store.sql_invitation.delete_expired.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			count, _ := res.RowsAffected()
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
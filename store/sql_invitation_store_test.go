@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestInvitationStore(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	i1 := &model.Invitation{Email: "test1@example.com", TeamId: teamId, InviterId: model.NewId()}
+	if result := <-store.Invitation().Save(i1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Invitation().Save(i1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing invitation")
+	}
+
+	i2 := &model.Invitation{Email: "test2@example.com", TeamId: teamId, Guest: true, ChannelIds: []string{model.NewId()}}
+	if result := <-store.Invitation().Save(i2); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Invitation().Get(i2.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.Invitation); saved.Email != i2.Email || !saved.Guest || len(saved.ChannelIds) != 1 || saved.ChannelIds[0] != i2.ChannelIds[0] {
+		t.Fatal("should have saved the invitation")
+	}
+
+	if result := <-store.Invitation().GetByToken(i1.Token); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.Invitation); saved.Id != i1.Id {
+		t.Fatal("should have found the invitation by its token")
+	}
+
+	if result := <-store.Invitation().GetPendingForTeam(teamId, model.GetMillis(), 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if pending := result.Data.([]*model.Invitation); len(pending) != 2 {
+		t.Fatal("should have returned the pending invitations of the team")
+	}
+
+	// Resending an invitation replaces its token
+	oldToken := i1.Token
+	i1.Renew(model.GetMillis() - model.INVITATION_EXPIRY_TIME - 1)
+	if result := <-store.Invitation().Update(i1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Invitation().GetByToken(oldToken); result.Err == nil {
+		t.Fatal("shouldn't have found the invitation by its old token")
+	}
+
+	if result := <-store.Invitation().GetPendingForTeam(teamId, model.GetMillis(), 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if pending := result.Data.([]*model.Invitation); len(pending) != 1 || pending[0].Id != i2.Id {
+		t.Fatal("shouldn't have returned the expired invitation")
+	}
+
+	if result := <-store.Invitation().DeleteExpired(model.GetMillis()); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count < 1 {
+		t.Fatal("should have deleted the expired invitation")
+	}
+
+	if result := <-store.Invitation().Get(i1.Id); result.Err == nil {
+		t.Fatal("should have deleted the expired invitation")
+	}
+
+	if result := <-store.Invitation().Delete(i2.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Invitation().Delete(i2.Id); result.Err == nil {
+		t.Fatal("shouldn't be able to delete an invitation twice")
+	}
+}
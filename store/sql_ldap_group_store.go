@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlLdapGroupStore struct {
+	*SqlStore
+}
+
+func NewSqlLdapGroupStore(sqlStore *SqlStore) LdapGroupStore {
+	s := &SqlLdapGroupStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LdapGroup{}, "LdapGroups").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("RemoteId").SetMaxSize(model.LDAP_GROUP_REMOTE_ID_MAX_RUNES)
+		table.ColMap("DisplayName").SetMaxSize(model.LDAP_GROUP_DISPLAY_NAME_MAX_RUNES)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.SetUniqueTogether("RemoteId", "ChannelId")
+	}
+
+	return s
+}
+
+func (s SqlLdapGroupStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_ldapgroups_channel_id", "LdapGroups", "ChannelId")
+}
+
+func (s SqlLdapGroupStore) Save(group *model.LdapGroup) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(group.Id) > 0 {
+			result.Err = model.NewAppError("SqlLdapGroupStore.Save", "store.sql_ldap_group.save.existing.app_error", nil, "id="+group.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		group.PreSave()
+		if result.Err = group.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(group); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"RemoteId", "ldapgroups_remoteid_channelid_key"}) {
+				result.Err = model.NewAppError("SqlLdapGroupStore.Save", "store.sql_ldap_group.save.linked.app_error", nil, "channel_id="+group.ChannelId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlLdapGroupStore.Save", "store.sql_ldap_group.save.app_error", nil, "id="+group.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLdapGroupStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var group model.LdapGroup
+
+		if err := s.GetReplica().SelectOne(&group, "SELECT * FROM LdapGroups WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlLdapGroupStore.Get", "store.sql_ldap_group.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlLdapGroupStore.Get", "store.sql_ldap_group.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &group
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLdapGroupStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var groups []*model.LdapGroup
+
+		if _, err := s.GetReplica().Select(&groups, "SELECT * FROM LdapGroups ORDER BY ChannelId, CreateAt"); err != nil {
+			result.Err = model.NewAppError("SqlLdapGroupStore.GetAll", "store.sql_ldap_group.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = groups
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLdapGroupStore) GetForChannel(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var groups []*model.LdapGroup
+
+		if _, err := s.GetReplica().Select(&groups, "SELECT * FROM LdapGroups WHERE ChannelId = :ChannelId ORDER BY CreateAt", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlLdapGroupStore.GetForChannel", "store.sql_ldap_group.get_for_channel.app_error", nil, "channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = groups
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLdapGroupStore) UpdateLastSyncAt(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE LdapGroups SET LastSyncAt = :LastSyncAt WHERE Id = :Id", map[string]interface{}{"LastSyncAt": time, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLdapGroupStore.UpdateLastSyncAt", "store.sql_ldap_group.update_last_sync_at.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLdapGroupStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM LdapGroups WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLdapGroupStore.Delete", "store.sql_ldap_group.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlLdapGroupStore.Delete", "store.sql_ldap_group.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLdapGroupStore(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	g1 := &model.LdapGroup{RemoteId: "cn=developers,ou=groups,dc=example,dc=com", ChannelId: channelId, CreatorId: model.NewId()}
+	if result := <-store.LdapGroup().Save(g1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.LdapGroup().Save(g1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing ldap group")
+	}
+
+	duplicate := &model.LdapGroup{RemoteId: g1.RemoteId, ChannelId: channelId, CreatorId: model.NewId()}
+	if result := <-store.LdapGroup().Save(duplicate); result.Err == nil {
+		t.Fatal("shouldn't be able to link a group to the same channel twice")
+	}
+
+	g2 := &model.LdapGroup{RemoteId: "cn=designers,ou=groups,dc=example,dc=com", ChannelId: channelId, CreatorId: model.NewId()}
+	if result := <-store.LdapGroup().Save(g2); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.LdapGroup().Get(g1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.LdapGroup); saved.RemoteId != g1.RemoteId || saved.ChannelId != channelId {
+		t.Fatal("should have saved the ldap group")
+	}
+
+	if result := <-store.LdapGroup().GetForChannel(channelId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.([]*model.LdapGroup); len(saved) != 2 {
+		t.Fatal("should have returned the groups linked to the channel")
+	}
+
+	if result := <-store.LdapGroup().GetAll(); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.([]*model.LdapGroup); len(saved) < 2 {
+		t.Fatal("should have returned every linked group")
+	}
+
+	if result := <-store.LdapGroup().UpdateLastSyncAt(g1.Id, 1234); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if saved := Must(store.LdapGroup().Get(g1.Id)).(*model.LdapGroup); saved.LastSyncAt != 1234 {
+		t.Fatal("should have updated the last sync time")
+	}
+
+	if result := <-store.LdapGroup().Delete(g1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.LdapGroup().Delete(g1.Id); result.Err == nil {
+		t.Fatal("shouldn't be able to delete an ldap group twice")
+	}
+
+	if result := <-store.LdapGroup().Get(g1.Id); result.Err == nil {
+		t.Fatal("should have deleted the ldap group")
+	}
+}
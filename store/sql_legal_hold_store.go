@@ -0,0 +1,325 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	LEGAL_HOLD_HELD_POST_QUERY = `(Posts.ChannelId IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'channel')
+				OR Posts.UserId IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'user'))`
+	LEGAL_HOLD_NOT_HELD_POST_QUERY = `Posts.ChannelId NOT IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'channel')
+				AND Posts.UserId NOT IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'user')`
+)
+
+type SqlLegalHoldStore struct {
+	*SqlStore
+}
+
+func NewSqlLegalHoldStore(sqlStore *SqlStore) LegalHoldStore {
+	s := &SqlLegalHoldStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LegalHold{}, "LegalHolds").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("TargetType").SetMaxSize(16)
+		table.ColMap("TargetId").SetMaxSize(26)
+		table.ColMap("Reason").SetMaxSize(model.LEGAL_HOLD_REASON_MAX_RUNES)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.SetUniqueTogether("TargetType", "TargetId")
+	}
+
+	return s
+}
+
+func (s SqlLegalHoldStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_legalholds_target_id", "LegalHolds", "TargetId")
+}
+
+func (s SqlLegalHoldStore) Save(hold *model.LegalHold) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(hold.Id) > 0 {
+			result.Err = model.NewAppError("SqlLegalHoldStore.Save", "store.sql_legal_hold.save.existing.app_error", nil, "id="+hold.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		hold.PreSave()
+		if result.Err = hold.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(hold); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"TargetType", "legalholds_targettype_targetid_key"}) {
+				result.Err = model.NewAppError("SqlLegalHoldStore.Save", "store.sql_legal_hold.save.exists.app_error", nil, "target_type="+hold.TargetType+", target_id="+hold.TargetId, http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlLegalHoldStore.Save", "store.sql_legal_hold.save.app_error", nil, "id="+hold.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = hold
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var hold model.LegalHold
+
+		if err := s.GetReplica().SelectOne(&hold, "SELECT * FROM LegalHolds WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlLegalHoldStore.Get", "store.sql_legal_hold.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlLegalHoldStore.Get", "store.sql_legal_hold.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &hold
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns a page of the legal holds, newest first.
+func (s SqlLegalHoldStore) GetAll(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var holds []*model.LegalHold
+
+		if _, err := s.GetReplica().Select(&holds,
+			`SELECT
+				*
+			FROM
+				LegalHolds
+			ORDER BY
+				CreateAt DESC, Id
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.GetAll", "store.sql_legal_hold.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = holds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) GetCount() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(*) FROM LegalHolds"); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.GetCount", "store.sql_legal_hold.get_count.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM LegalHolds WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.Delete", "store.sql_legal_hold.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlLegalHoldStore.Delete", "store.sql_legal_hold.get.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IsHeld returns true if either the user or the channel is held. Either id may be empty.
+func (s SqlLegalHoldStore) IsHeld(userId string, channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetMaster().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				LegalHolds
+			WHERE
+				(TargetType = 'user' AND TargetId = :UserId)
+				OR (TargetType = 'channel' AND TargetId = :ChannelId)`,
+			map[string]interface{}{"UserId": userId, "ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.IsHeld", "store.sql_legal_hold.is_held.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count > 0
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// HasHeldUserContent returns true if the user is held or has posted in a held channel.
+func (s SqlLegalHoldStore) HasHeldUserContent(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"UserId": userId}
+
+		if count, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM LegalHolds WHERE TargetType = 'user' AND TargetId = :UserId", props); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.HasHeldUserContent", "store.sql_legal_hold.is_held.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else if count > 0 {
+			result.Data = true
+		} else if count, err := s.GetMaster().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				Posts
+			WHERE
+				Posts.UserId = :UserId
+				AND Posts.ChannelId IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'channel')`, props); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.HasHeldUserContent", "store.sql_legal_hold.is_held.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count > 0
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// HasHeldChannelContent returns true if the channel is held or contains posts from held users.
+func (s SqlLegalHoldStore) HasHeldChannelContent(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"ChannelId": channelId}
+
+		if count, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM LegalHolds WHERE TargetType = 'channel' AND TargetId = :ChannelId", props); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.HasHeldChannelContent", "store.sql_legal_hold.is_held.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else if count > 0 {
+			result.Data = true
+		} else if count, err := s.GetMaster().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				Posts
+			WHERE
+				Posts.ChannelId = :ChannelId
+				AND Posts.UserId IN (SELECT TargetId FROM LegalHolds WHERE TargetType = 'user')`, props); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.HasHeldChannelContent", "store.sql_legal_hold.is_held.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count > 0
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetHeldPosts returns a page of the held posts, including deleted ones, that were created after
+// startTime and up to endTime, oldest first. Posts in direct and group channels have no team.
+func (s SqlLegalHoldStore) GetHeldPosts(startTime int64, endTime int64, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var cposts []*model.CompliancePost
+
+		if _, err := s.GetReplica().Select(&cposts,
+			`SELECT
+			    COALESCE(Teams.Name, '') AS TeamName,
+			    COALESCE(Teams.DisplayName, '') AS TeamDisplayName,
+			    Channels.Name AS ChannelName,
+			    Channels.DisplayName AS ChannelDisplayName,
+			    Users.Username AS UserUsername,
+			    Users.Email AS UserEmail,
+			    Users.Nickname AS UserNickname,
+			    Posts.Id AS PostId,
+			    Posts.CreateAt AS PostCreateAt,
+			    Posts.UpdateAt AS PostUpdateAt,
+			    Posts.DeleteAt AS PostDeleteAt,
+			    Posts.RootId AS PostRootId,
+			    Posts.ParentId AS PostParentId,
+			    Posts.OriginalId AS PostOriginalId,
+			    Posts.Message AS PostMessage,
+			    Posts.Type AS PostType,
+			    Posts.Props AS PostProps,
+			    Posts.Hashtags AS PostHashtags,
+			    Posts.FileIds AS PostFileIds
+			FROM
+			    Posts
+			INNER JOIN
+			    Channels ON Posts.ChannelId = Channels.Id
+			INNER JOIN
+			    Users ON Posts.UserId = Users.Id
+			LEFT JOIN
+			    Teams ON Channels.TeamId = Teams.Id
+			WHERE
+			    Posts.CreateAt > :StartTime
+			    AND Posts.CreateAt <= :EndTime
+			    AND `+LEGAL_HOLD_HELD_POST_QUERY+`
+			ORDER BY
+			    Posts.CreateAt, Posts.Id
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"StartTime": startTime, "EndTime": endTime, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlLegalHoldStore.GetHeldPosts", "store.sql_legal_hold.get_held_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = cposts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
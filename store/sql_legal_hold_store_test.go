@@ -0,0 +1,100 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLegalHoldStore(t *testing.T) {
+	Setup()
+
+	h1 := &model.LegalHold{TargetType: model.LEGAL_HOLD_TARGET_USER, TargetId: model.NewId(), CreatorId: model.NewId(), Reason: "litigation"}
+	if result := <-store.LegalHold().Save(h1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.LegalHold().Save(&model.LegalHold{TargetType: h1.TargetType, TargetId: h1.TargetId, CreatorId: h1.CreatorId}); result.Err == nil {
+		t.Fatal("shouldn't be able to hold a user twice")
+	}
+
+	if result := <-store.LegalHold().Get(h1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.LegalHold); saved.Reason != h1.Reason {
+		t.Fatal("should have saved the hold")
+	}
+
+	if held := Must(store.LegalHold().IsHeld(h1.TargetId, "")).(bool); !held {
+		t.Fatal("the user should be held")
+	}
+
+	if held := Must(store.LegalHold().IsHeld(model.NewId(), model.NewId())).(bool); held {
+		t.Fatal("the user and channel shouldn't be held")
+	}
+
+	if result := <-store.LegalHold().Delete(h1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.LegalHold().Get(h1.Id); result.Err == nil {
+		t.Fatal("should have deleted the hold")
+	}
+}
+
+func TestLegalHoldStoreHeldPosts(t *testing.T) {
+	Setup()
+
+	u1 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "u" + model.NewId()})).(*model.User)
+	u2 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "u" + model.NewId()})).(*model.User)
+
+	teamId := model.NewId()
+	c1 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel 1", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	c2 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel 2", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	channelHold := Must(store.LegalHold().Save(&model.LegalHold{TargetType: model.LEGAL_HOLD_TARGET_CHANNEL, TargetId: c1.Id, CreatorId: u1.Id})).(*model.LegalHold)
+	defer func() {
+		<-store.LegalHold().Delete(channelHold.Id)
+	}()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: u1.Id, Message: "held", CreateAt: 1000})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: c2.Id, UserId: u2.Id, Message: "not held", CreateAt: 1000})).(*model.Post)
+
+	if result := <-store.LegalHold().GetHeldPosts(0, 2000, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, cpost := range result.Data.([]*model.CompliancePost) {
+			if cpost.PostId == o1.Id {
+				found = true
+			} else if cpost.PostId == o2.Id {
+				t.Fatal("shouldn't have returned the post in the channel that isn't held")
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the post in the held channel")
+		}
+	}
+
+	if held := Must(store.LegalHold().HasHeldUserContent(u1.Id)).(bool); !held {
+		t.Fatal("the user has posted in a held channel")
+	}
+
+	if held := Must(store.LegalHold().HasHeldChannelContent(c2.Id)).(bool); held {
+		t.Fatal("the channel shouldn't have held content")
+	}
+
+	policy := Must(store.RetentionPolicy().Save(&model.RetentionPolicy{TeamId: teamId, MessageRetentionDays: 1})).(*model.RetentionPolicy)
+	defer func() {
+		<-store.RetentionPolicy().Delete(policy.Id)
+	}()
+
+	if result := <-store.RetentionPolicy().GetExpiredPostIds(policy, model.GetMillis(), 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if postIds := result.Data.([]string); len(postIds) != 1 || postIds[0] != o2.Id {
+		t.Fatal("retention shouldn't apply to held posts")
+	}
+}
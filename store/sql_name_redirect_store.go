@@ -0,0 +1,118 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlNameRedirectStore struct {
+	*SqlStore
+}
+
+func NewSqlNameRedirectStore(sqlStore *SqlStore) NameRedirectStore {
+	s := &SqlNameRedirectStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.NameRedirect{}, "NameRedirects").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Type").SetMaxSize(16)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("OldName").SetMaxSize(64)
+		table.ColMap("TargetId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlNameRedirectStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_nameredirects_old_name", "NameRedirects", "OldName")
+	s.CreateIndexIfNotExists("idx_nameredirects_target_id", "NameRedirects", "TargetId")
+}
+
+func (s SqlNameRedirectStore) Save(redirect *model.NameRedirect) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		redirect.PreSave()
+		if result.Err = redirect.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(redirect); err != nil {
+			result.Err = model.NewAppError("SqlNameRedirectStore.Save", "store.sql_name_redirect.save.app_error", nil, "id="+redirect.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = redirect
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Get returns the most recent redirect from the old name of a team, or of a channel in the given team,
+// that was made since the given time.
+func (s SqlNameRedirectStore) Get(redirectType string, teamId string, oldName string, since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var redirect model.NameRedirect
+
+		if err := s.GetReplica().SelectOne(&redirect,
+			`SELECT
+				*
+			FROM
+				NameRedirects
+			WHERE
+				Type = :Type
+				AND TeamId = :TeamId
+				AND OldName = :OldName
+				AND CreateAt >= :Since
+			ORDER BY CreateAt DESC
+			LIMIT 1`,
+			map[string]interface{}{"Type": redirectType, "TeamId": teamId, "OldName": oldName, "Since": since}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlNameRedirectStore.Get", "store.sql_name_redirect.get.app_error", nil, "old_name="+oldName+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlNameRedirectStore.Get", "store.sql_name_redirect.get.app_error", nil, "old_name="+oldName+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &redirect
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// PermanentDeleteByTarget removes the redirects to a team or a channel that's being deleted.
+func (s SqlNameRedirectStore) PermanentDeleteByTarget(targetId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM NameRedirects WHERE TargetId = :TargetId", map[string]interface{}{"TargetId": targetId}); err != nil {
+			result.Err = model.NewAppError("SqlNameRedirectStore.PermanentDeleteByTarget", "store.sql_name_redirect.permanent_delete_by_target.app_error", nil, "target_id="+targetId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestNameRedirectStore(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	oldName := "old-" + model.NewId()
+
+	r1 := &model.NameRedirect{Type: model.NAME_REDIRECT_TYPE_CHANNEL, TeamId: teamId, OldName: oldName, TargetId: model.NewId()}
+	Must(store.NameRedirect().Save(r1))
+
+	time.Sleep(10 * time.Millisecond)
+
+	r2 := &model.NameRedirect{Type: model.NAME_REDIRECT_TYPE_CHANNEL, TeamId: teamId, OldName: oldName, TargetId: model.NewId()}
+	Must(store.NameRedirect().Save(r2))
+
+	if result := <-store.NameRedirect().Get(model.NAME_REDIRECT_TYPE_CHANNEL, teamId, oldName, 0); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if redirect := result.Data.(*model.NameRedirect); redirect.TargetId != r2.TargetId {
+		t.Fatal("should have returned the most recent redirect")
+	}
+
+	if result := <-store.NameRedirect().Get(model.NAME_REDIRECT_TYPE_CHANNEL, model.NewId(), oldName, 0); result.Err == nil {
+		t.Fatal("shouldn't find a redirect for a channel in another team")
+	}
+
+	if result := <-store.NameRedirect().Get(model.NAME_REDIRECT_TYPE_TEAM, "", oldName, 0); result.Err == nil {
+		t.Fatal("shouldn't find a redirect for a team")
+	}
+
+	if result := <-store.NameRedirect().Get(model.NAME_REDIRECT_TYPE_CHANNEL, teamId, oldName, model.GetMillis()+1000); result.Err == nil {
+		t.Fatal("shouldn't find a redirect that's too old")
+	}
+
+	Must(store.NameRedirect().PermanentDeleteByTarget(r2.TargetId))
+
+	if result := <-store.NameRedirect().Get(model.NAME_REDIRECT_TYPE_CHANNEL, teamId, oldName, 0); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if redirect := result.Data.(*model.NameRedirect); redirect.TargetId != r1.TargetId {
+		t.Fatal("should have returned the remaining redirect")
+	}
+
+	if result := <-store.NameRedirect().Save(&model.NameRedirect{Type: model.NAME_REDIRECT_TYPE_TEAM, TeamId: teamId, OldName: oldName, TargetId: model.NewId()}); result.Err == nil {
+		t.Fatal("team redirects shouldn't have a team id")
+	}
+}
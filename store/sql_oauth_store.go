@@ -4,6 +4,7 @@
 package store
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/go-gorp/gorp"
@@ -27,6 +28,7 @@ func NewSqlOAuthStore(sqlStore *SqlStore) OAuthStore {
 		table.ColMap("CallbackUrls").SetMaxSize(1024)
 		table.ColMap("Homepage").SetMaxSize(256)
 		table.ColMap("IconURL").SetMaxSize(512)
+		table.ColMap("Scopes").SetMaxSize(model.OAUTH_SCOPES_MAX_LENGTH)
 
 		tableAuth := db.AddTableWithName(model.AuthData{}, "OAuthAuthData").SetKeys(false, "Code")
 		tableAuth.ColMap("UserId").SetMaxSize(26)
@@ -34,7 +36,10 @@ func NewSqlOAuthStore(sqlStore *SqlStore) OAuthStore {
 		tableAuth.ColMap("Code").SetMaxSize(128)
 		tableAuth.ColMap("RedirectUri").SetMaxSize(256)
 		tableAuth.ColMap("State").SetMaxSize(128)
-		tableAuth.ColMap("Scope").SetMaxSize(128)
+		tableAuth.ColMap("Scope").SetMaxSize(model.OAUTH_SCOPES_MAX_LENGTH)
+		tableAuth.ColMap("CodeChallenge").SetMaxSize(128)
+		tableAuth.ColMap("CodeChallengeMethod").SetMaxSize(16)
+		tableAuth.ColMap("Nonce").SetMaxSize(model.OPENID_NONCE_MAX_LENGTH)
 
 		tableAccess := db.AddTableWithName(model.AccessData{}, "OAuthAccessData").SetKeys(false, "Token")
 		tableAccess.ColMap("ClientId").SetMaxSize(26)
@@ -42,6 +47,7 @@ func NewSqlOAuthStore(sqlStore *SqlStore) OAuthStore {
 		tableAccess.ColMap("Token").SetMaxSize(26)
 		tableAccess.ColMap("RefreshToken").SetMaxSize(26)
 		tableAccess.ColMap("RedirectUri").SetMaxSize(256)
+		tableAccess.ColMap("Scopes").SetMaxSize(model.OAUTH_SCOPES_MAX_LENGTH)
 		tableAccess.SetUniqueTogether("ClientId", "UserId")
 	}
 
@@ -393,8 +399,8 @@ func (as SqlOAuthStore) UpdateAccessData(accessData *model.AccessData) StoreChan
 			return
 		}
 
-		if _, err := as.GetMaster().Exec("UPDATE OAuthAccessData SET Token = :Token, ExpiresAt = :ExpiresAt WHERE ClientId = :ClientId AND UserID = :UserId",
-			map[string]interface{}{"Token": accessData.Token, "ExpiresAt": accessData.ExpiresAt, "ClientId": accessData.ClientId, "UserId": accessData.UserId}); err != nil {
+		if _, err := as.GetMaster().Exec("UPDATE OAuthAccessData SET Token = :Token, ExpiresAt = :ExpiresAt, Scopes = :Scopes WHERE ClientId = :ClientId AND UserID = :UserId",
+			map[string]interface{}{"Token": accessData.Token, "ExpiresAt": accessData.ExpiresAt, "Scopes": accessData.Scopes, "ClientId": accessData.ClientId, "UserId": accessData.UserId}); err != nil {
 			result.Err = model.NewLocAppError("SqlOAuthStore.Update", "store.sql_oauth.update_access_data.app_error", nil,
 				"clientId="+accessData.ClientId+",userId="+accessData.UserId+", "+err.Error())
 		} else {
@@ -408,6 +414,38 @@ func (as SqlOAuthStore) UpdateAccessData(accessData *model.AccessData) StoreChan
 	return storeChannel
 }
 
+// RotateRefreshToken replaces the token, refresh token and expiry of an access grant, but only if
+// the grant still holds previousRefreshToken. Once rotated the previous refresh token can no longer be used.
+func (as SqlOAuthStore) RotateRefreshToken(accessData *model.AccessData, previousRefreshToken string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = accessData.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if sqlResult, err := as.GetMaster().Exec("UPDATE OAuthAccessData SET Token = :Token, RefreshToken = :RefreshToken, ExpiresAt = :ExpiresAt, Scopes = :Scopes WHERE ClientId = :ClientId AND UserId = :UserId AND RefreshToken = :PreviousRefreshToken",
+			map[string]interface{}{"Token": accessData.Token, "RefreshToken": accessData.RefreshToken, "ExpiresAt": accessData.ExpiresAt, "ClientId": accessData.ClientId, "UserId": accessData.UserId, "PreviousRefreshToken": previousRefreshToken}); err != nil {
+			result.Err = model.NewAppError("SqlOAuthStore.RotateRefreshToken", "store.sql_oauth.rotate_refresh_token.app_error", nil,
+				"clientId="+accessData.ClientId+",userId="+accessData.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			result.Err = model.NewAppError("SqlOAuthStore.RotateRefreshToken", "store.sql_oauth.rotate_refresh_token.missing.app_error", nil,
+				"clientId="+accessData.ClientId+",userId="+accessData.UserId, http.StatusBadRequest)
+		} else {
+			result.Data = accessData
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (as SqlOAuthStore) RemoveAccessData(token string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -425,6 +463,23 @@ func (as SqlOAuthStore) RemoveAccessData(token string) StoreChannel {
 	return storeChannel
 }
 
+func (as SqlOAuthStore) RemoveAccessDataByRefreshToken(token string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := as.GetMaster().Exec("DELETE FROM OAuthAccessData WHERE RefreshToken = :RefreshToken", map[string]interface{}{"RefreshToken": token}); err != nil {
+			result.Err = model.NewLocAppError("SqlOAuthStore.RemoveAccessDataByRefreshToken", "store.sql_oauth.remove_access_data.app_error", nil, "err="+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (as SqlOAuthStore) SaveAuthData(authData *model.AuthData) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
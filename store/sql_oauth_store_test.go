@@ -169,6 +169,7 @@ func TestOAuthUpdateAccessData(t *testing.T) {
 
 	// Should update fine
 	a1.RedirectUri = "http://example.com"
+	a1.Scopes = model.OAUTH_SCOPE_READ
 	if result := <-store.OAuth().UpdateAccessData(&a1); result.Err != nil {
 		t.Fatal(result.Err)
 	} else {
@@ -177,6 +178,12 @@ func TestOAuthUpdateAccessData(t *testing.T) {
 			t.Fatal("refresh tokens didn't match")
 		}
 	}
+
+	if result := <-store.OAuth().GetAccessData(a1.Token); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.AccessData).Scopes != model.OAUTH_SCOPE_READ {
+		t.Fatal("scopes should have been updated")
+	}
 }
 
 func TestOAuthStoreGetAccessData(t *testing.T) {
@@ -251,6 +258,60 @@ func TestOAuthStoreRemoveAccessData(t *testing.T) {
 	}
 }
 
+func TestOAuthStoreRotateRefreshToken(t *testing.T) {
+	Setup()
+
+	a1 := model.AccessData{}
+	a1.ClientId = model.NewId()
+	a1.UserId = model.NewId()
+	a1.Token = model.NewId()
+	a1.RefreshToken = model.NewId()
+	a1.RedirectUri = "http://example.com"
+	Must(store.OAuth().SaveAccessData(&a1))
+
+	previousRefreshToken := a1.RefreshToken
+	a1.Token = model.NewId()
+	a1.RefreshToken = model.NewId()
+	if err := (<-store.OAuth().RotateRefreshToken(&a1, previousRefreshToken)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (<-store.OAuth().GetAccessDataByRefreshToken(previousRefreshToken)).Err; err == nil {
+		t.Fatal("previous refresh token should no longer be valid")
+	}
+
+	if result := <-store.OAuth().GetAccessDataByRefreshToken(a1.RefreshToken); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(*model.AccessData).Token != a1.Token {
+		t.Fatal("token should have been updated")
+	}
+
+	a1.RefreshToken = model.NewId()
+	if err := (<-store.OAuth().RotateRefreshToken(&a1, previousRefreshToken)).Err; err == nil {
+		t.Fatal("should not rotate from a refresh token that was already rotated")
+	}
+}
+
+func TestOAuthStoreRemoveAccessDataByRefreshToken(t *testing.T) {
+	Setup()
+
+	a1 := model.AccessData{}
+	a1.ClientId = model.NewId()
+	a1.UserId = model.NewId()
+	a1.Token = model.NewId()
+	a1.RefreshToken = model.NewId()
+	a1.RedirectUri = "http://example.com"
+	Must(store.OAuth().SaveAccessData(&a1))
+
+	if err := (<-store.OAuth().RemoveAccessDataByRefreshToken(a1.RefreshToken)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (<-store.OAuth().GetAccessData(a1.Token)).Err; err == nil {
+		t.Fatal("did not delete access data")
+	}
+}
+
 func TestOAuthStoreSaveAuthData(t *testing.T) {
 	Setup()
 
@@ -0,0 +1,211 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPostEventHookStore struct {
+	*SqlStore
+}
+
+func NewSqlPostEventHookStore(sqlStore *SqlStore) PostEventHookStore {
+	s := &SqlPostEventHookStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostEventHook{}, "PostEventHooks").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Secret").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("Events").SetMaxSize(128)
+		table.ColMap("CallbackURL").SetMaxSize(model.POST_EVENT_HOOK_CALLBACK_URL_MAX_RUNES)
+		table.ColMap("DisplayName").SetMaxSize(model.POST_EVENT_HOOK_DISPLAY_NAME_MAX_RUNES)
+		table.ColMap("Description").SetMaxSize(model.POST_EVENT_HOOK_DESCRIPTION_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlPostEventHookStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_post_event_hooks_team_id", "PostEventHooks", "TeamId")
+	s.CreateIndexIfNotExists("idx_post_event_hooks_channel_id", "PostEventHooks", "ChannelId")
+}
+
+func (s SqlPostEventHookStore) Save(hook *model.PostEventHook) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(hook.Id) > 0 {
+			result.Err = model.NewAppError("SqlPostEventHookStore.Save", "store.sql_post_event_hook.save.existing.app_error", nil, "id="+hook.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		hook.PreSave()
+		if result.Err = hook.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(hook); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.Save", "store.sql_post_event_hook.save.app_error", nil, "id="+hook.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = hook
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostEventHookStore) Update(hook *model.PostEventHook) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		hook.PreUpdate()
+		if result.Err = hook.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(hook); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.Update", "store.sql_post_event_hook.update.app_error", nil, "id="+hook.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlPostEventHookStore.Update", "store.sql_post_event_hook.update.app_error", nil, "id="+hook.Id, http.StatusNotFound)
+		} else {
+			result.Data = hook
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostEventHookStore) Get(hookId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var hook model.PostEventHook
+
+		if err := s.GetReplica().SelectOne(&hook, "SELECT * FROM PostEventHooks WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": hookId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPostEventHookStore.Get", "store.sql_post_event_hook.get.app_error", nil, "id="+hookId+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlPostEventHookStore.Get", "store.sql_post_event_hook.get.app_error", nil, "id="+hookId+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &hook
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostEventHookStore) GetByTeam(teamId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var hooks []*model.PostEventHook
+
+		if _, err := s.GetReplica().Select(&hooks, "SELECT * FROM PostEventHooks WHERE TeamId = :TeamId AND DeleteAt = 0 ORDER BY CreateAt LIMIT :Limit OFFSET :Offset", map[string]interface{}{"TeamId": teamId, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.GetByTeam", "store.sql_post_event_hook.get_by_team.app_error", nil, "teamId="+teamId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = hooks
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForChannel returns the hooks that receive the events of a channel, which are the hooks on the
+// channel itself and the hooks on its whole team.
+func (s SqlPostEventHookStore) GetForChannel(teamId string, channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var hooks []*model.PostEventHook
+
+		if _, err := s.GetReplica().Select(&hooks,
+			`SELECT
+				*
+			FROM
+				PostEventHooks
+			WHERE
+				DeleteAt = 0
+				AND (ChannelId = :ChannelId OR (ChannelId = '' AND TeamId = :TeamId))`,
+			map[string]interface{}{"TeamId": teamId, "ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.GetForChannel", "store.sql_post_event_hook.get_for_channel.app_error", nil, "channelId="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = hooks
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostEventHookStore) Delete(hookId string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE PostEventHooks SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": hookId}); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.Delete", "store.sql_post_event_hook.delete.app_error", nil, "id="+hookId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostEventHookStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PostEventHooks WHERE CreatorId = :CreatorId", map[string]interface{}{"CreatorId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostEventHookStore.PermanentDeleteByUser", "store.sql_post_event_hook.permanent_delete_by_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
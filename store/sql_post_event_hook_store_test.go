@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostEventHookStore(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	channelId := model.NewId()
+
+	h1 := &model.PostEventHook{CreatorId: model.NewId(), TeamId: teamId, ChannelId: channelId, CallbackURL: "http://nowhere.com"}
+	if result := <-store.PostEventHook().Save(h1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.PostEventHook().Save(h1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing hook")
+	}
+
+	h2 := Must(store.PostEventHook().Save(&model.PostEventHook{CreatorId: model.NewId(), TeamId: teamId, CallbackURL: "http://nowhere.com"})).(*model.PostEventHook)
+	h3 := Must(store.PostEventHook().Save(&model.PostEventHook{CreatorId: model.NewId(), TeamId: teamId, ChannelId: model.NewId(), CallbackURL: "http://nowhere.com"})).(*model.PostEventHook)
+	Must(store.PostEventHook().Save(&model.PostEventHook{CreatorId: model.NewId(), TeamId: model.NewId(), CallbackURL: "http://nowhere.com"}))
+
+	if result := <-store.PostEventHook().GetForChannel(teamId, channelId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if hooks := result.Data.([]*model.PostEventHook); len(hooks) != 2 {
+		t.Fatal("should have returned the hooks on the channel and on its team")
+	}
+
+	if result := <-store.PostEventHook().GetByTeam(teamId, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if hooks := result.Data.([]*model.PostEventHook); len(hooks) != 3 {
+		t.Fatal("should have returned the hooks of the team")
+	}
+
+	h1.Events = model.StringArray{model.POST_EVENT_HOOK_EVENT_POST_DELETED}
+	if result := <-store.PostEventHook().Update(h1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.PostEventHook().Get(h1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if hook := result.Data.(*model.PostEventHook); len(hook.Events) != 1 || hook.Events[0] != model.POST_EVENT_HOOK_EVENT_POST_DELETED {
+		t.Fatal("should have updated the hook")
+	}
+
+	Must(store.PostEventHook().Delete(h2.Id, model.GetMillis()))
+
+	if result := <-store.PostEventHook().Get(h2.Id); result.Err == nil {
+		t.Fatal("shouldn't have returned a deleted hook")
+	}
+
+	if result := <-store.PostEventHook().GetForChannel(teamId, h3.ChannelId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if hooks := result.Data.([]*model.PostEventHook); len(hooks) != 1 || hooks[0].Id != h3.Id {
+		t.Fatal("shouldn't have returned a deleted hook")
+	}
+}
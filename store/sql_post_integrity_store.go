@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	POST_INTEGRITY_APPEND_MAX_ATTEMPTS = 5
+)
+
+type SqlPostIntegrityStore struct {
+	*SqlStore
+}
+
+func NewSqlPostIntegrityStore(sqlStore *SqlStore) PostIntegrityStore {
+	s := &SqlPostIntegrityStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostIntegrityRecord{}, "PostIntegrity").SetKeys(false, "PostId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("PreviousHash").SetMaxSize(64)
+		table.ColMap("Hash").SetMaxSize(64)
+	}
+
+	return s
+}
+
+func (s SqlPostIntegrityStore) CreateIndexesIfNotExists() {
+	s.CreateUniqueIndexIfNotExists("idx_post_integrity_channel_id_sequence", "PostIntegrity", "ChannelId, Sequence")
+}
+
+// Append adds a post to the end of the chain of its channel. Posts created at the same time in a
+// channel race for the next sequence number, so the loser retries after the winner is saved.
+func (s SqlPostIntegrityStore) Append(post *model.Post) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		for attempt := 1; ; attempt++ {
+			var previous *model.PostIntegrityRecord
+
+			var last model.PostIntegrityRecord
+			if err := s.GetMaster().SelectOne(&last, "SELECT * FROM PostIntegrity WHERE ChannelId = :ChannelId ORDER BY Sequence DESC LIMIT 1", map[string]interface{}{"ChannelId": post.ChannelId}); err == nil {
+				previous = &last
+			} else if err != sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPostIntegrityStore.Append", "store.sql_post_integrity.append.app_error", nil, "post_id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+				break
+			}
+
+			record := model.NewPostIntegrityRecord(post, previous)
+			record.PreSave()
+			if result.Err = record.IsValid(); result.Err != nil {
+				break
+			}
+
+			if err := s.GetMaster().Insert(record); err != nil {
+				if attempt < POST_INTEGRITY_APPEND_MAX_ATTEMPTS && IsUniqueConstraintError(err.Error(), []string{"Sequence", "idx_post_integrity_channel_id_sequence"}) {
+					continue
+				}
+
+				result.Err = model.NewAppError("SqlPostIntegrityStore.Append", "store.sql_post_integrity.append.app_error", nil, "post_id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = record
+			}
+
+			break
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForChannel returns the entries of a channel's chain that come after the given sequence number, in order.
+func (s SqlPostIntegrityStore) GetForChannel(channelId string, afterSequence int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var records []*model.PostIntegrityRecord
+		if _, err := s.GetReplica().Select(&records,
+			`SELECT
+				*
+			FROM
+				PostIntegrity
+			WHERE
+				ChannelId = :ChannelId
+				AND Sequence > :AfterSequence
+			ORDER BY Sequence ASC
+			LIMIT :Limit`, map[string]interface{}{"ChannelId": channelId, "AfterSequence": afterSequence, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostIntegrityStore.GetForChannel", "store.sql_post_integrity.get_for_channel.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = records
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetChannelIds returns the ids of the channels that have a chain.
+func (s SqlPostIntegrityStore) GetChannelIds(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var channelIds []string
+		if _, err := s.GetReplica().Select(&channelIds, "SELECT DISTINCT ChannelId FROM PostIntegrity ORDER BY ChannelId LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlPostIntegrityStore.GetChannelIds", "store.sql_post_integrity.get_channel_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = channelIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPostIntegrityStoreAppend(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	p1 := &model.Post{Id: model.NewId(), ChannelId: channelId, UserId: model.NewId(), Message: "first", CreateAt: model.GetMillis()}
+	p2 := &model.Post{Id: model.NewId(), ChannelId: channelId, UserId: model.NewId(), Message: "second", CreateAt: model.GetMillis()}
+
+	r1 := Must(store.PostIntegrity().Append(p1)).(*model.PostIntegrityRecord)
+	r2 := Must(store.PostIntegrity().Append(p2)).(*model.PostIntegrityRecord)
+
+	if r1.Sequence != 1 || r2.Sequence != 2 || r2.PreviousHash != r1.Hash {
+		t.Fatal("should have chained the records")
+	}
+
+	if result := <-store.PostIntegrity().GetForChannel(channelId, 0, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if records := result.Data.([]*model.PostIntegrityRecord); len(records) != 2 || records[0].PostId != p1.Id || records[1].PostId != p2.Id {
+		t.Fatal("should have returned the records in order")
+	}
+
+	if result := <-store.PostIntegrity().GetForChannel(channelId, 1, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if records := result.Data.([]*model.PostIntegrityRecord); len(records) != 1 || records[0].PostId != p2.Id {
+		t.Fatal("should have returned the records after the sequence number")
+	}
+
+	if result := <-store.PostIntegrity().GetChannelIds(0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, id := range result.Data.([]string) {
+			if id == channelId {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the channel")
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -11,6 +13,7 @@ import (
 	"strings"
 
 	l4g "github.com/alecthomas/log4go"
+	"github.com/go-gorp/gorp"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -28,8 +31,8 @@ const (
 	LAST_POSTS_CACHE_SEC  = 900 // 15 minutes
 )
 
-var lastPostTimeCache = utils.NewLru(LAST_POST_TIME_CACHE_SIZE)
-var lastPostsCache = utils.NewLru(LAST_POSTS_CACHE_SIZE)
+var lastPostTimeCache utils.ObjectCache = utils.NewLru(LAST_POST_TIME_CACHE_SIZE)
+var lastPostsCache utils.ObjectCache = utils.NewLru(LAST_POSTS_CACHE_SIZE)
 
 func ClearPostCaches() {
 	lastPostTimeCache.Purge()
@@ -39,6 +42,9 @@ func ClearPostCaches() {
 func NewSqlPostStore(sqlStore *SqlStore) PostStore {
 	s := &SqlPostStore{sqlStore}
 
+	lastPostTimeCache = utils.NewObjectCache("last_post_time", LAST_POST_TIME_CACHE_SIZE, int64(0))
+	lastPostsCache = utils.NewObjectCache("last_posts", LAST_POSTS_CACHE_SIZE, &model.PostList{})
+
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.Post{}, "Posts").SetKeys(false, "Id")
 		table.ColMap("Id").SetMaxSize(26)
@@ -52,6 +58,26 @@ func NewSqlPostStore(sqlStore *SqlStore) PostStore {
 		table.ColMap("Props").SetMaxSize(8000)
 		table.ColMap("Filenames").SetMaxSize(4000)
 		table.ColMap("FileIds").SetMaxSize(150)
+
+		tableHistory := db.AddTableWithName(model.PostHistory{}, "PostsHistory").SetKeys(false, "Id")
+		tableHistory.ColMap("Id").SetMaxSize(26)
+		tableHistory.ColMap("PostId").SetMaxSize(26)
+		tableHistory.ColMap("UserId").SetMaxSize(26)
+		tableHistory.ColMap("ChannelId").SetMaxSize(26)
+		tableHistory.ColMap("RootId").SetMaxSize(26)
+		tableHistory.ColMap("Message").SetMaxSize(4000)
+		tableHistory.ColMap("Props").SetMaxSize(8000)
+		tableHistory.ColMap("Hashtags").SetMaxSize(1000)
+		tableHistory.ColMap("FileIds").SetMaxSize(150)
+
+		tableThread := db.AddTableWithName(model.ThreadMembership{}, "ThreadMemberships").SetKeys(false, "PostId", "UserId")
+		tableThread.ColMap("PostId").SetMaxSize(26)
+		tableThread.ColMap("UserId").SetMaxSize(26)
+
+		tableCross := db.AddTableWithName(model.CrossPost{}, "CrossPosts").SetKeys(false, "CrossPostId", "PostId")
+		tableCross.ColMap("CrossPostId").SetMaxSize(26)
+		tableCross.ColMap("PostId").SetMaxSize(26)
+		tableCross.ColMap("ChannelId").SetMaxSize(26)
 	}
 
 	return s
@@ -65,6 +91,9 @@ func (s SqlPostStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_posts_root_id", "Posts", "RootId")
 	s.CreateIndexIfNotExists("idx_posts_user_id", "Posts", "UserId")
 	s.CreateIndexIfNotExists("idx_posts_is_pinned", "Posts", "IsPinned")
+	s.CreateIndexIfNotExists("idx_posts_history_post_id", "PostsHistory", "PostId")
+
+	s.CreateIndexIfNotExists("idx_thread_memberships_user_id", "ThreadMemberships", "UserId")
 
 	s.CreateFullTextIndexIfNotExists("idx_posts_message_txt", "Posts", "Message")
 	s.CreateFullTextIndexIfNotExists("idx_posts_hashtags_txt", "Posts", "Hashtags")
@@ -126,6 +155,11 @@ func (s SqlPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChan
 
 		newPost.UpdateAt = model.GetMillis()
 
+		var history *model.PostHistory
+		if newPost.Message != oldPost.Message {
+			history = model.NewPostHistory(oldPost, newPost.UpdateAt)
+		}
+
 		oldPost.DeleteAt = newPost.UpdateAt
 		oldPost.UpdateAt = newPost.UpdateAt
 		oldPost.OriginalId = oldPost.Id
@@ -150,6 +184,12 @@ func (s SqlPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChan
 			// mark the old post as deleted
 			s.GetMaster().Insert(oldPost)
 
+			if history != nil {
+				if err := s.GetMaster().Insert(history); err != nil {
+					l4g.Error(utils.T("store.sql_post.update.save_history.error"), newPost.Id, err.Error())
+				}
+			}
+
 			result.Data = newPost
 		}
 
@@ -160,6 +200,28 @@ func (s SqlPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChan
 	return storeChannel
 }
 
+// GetPostHistory returns the previous revisions of a post, most recent first.
+func (s SqlPostStore) GetPostHistory(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var history []*model.PostHistory
+
+		if _, err := s.GetReplica().Select(&history, "SELECT * FROM PostsHistory WHERE PostId = :PostId ORDER BY ReplacedAt DESC", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostHistory", "store.sql_post.get_post_history.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = history
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) Overwrite(post *model.Post) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -301,6 +363,11 @@ func (s SqlPostStore) GetFlaggedPostsForChannel(userId, channelId string, offset
 }
 
 func (s SqlPostStore) Get(id string) StoreChannel {
+	return s.GetContext(context.Background(), id)
+}
+
+// GetContext is Get with the queries cancelled along with ctx.
+func (s SqlPostStore) GetContext(ctx context.Context, id string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
@@ -315,8 +382,13 @@ func (s SqlPostStore) Get(id string) StoreChannel {
 		}
 
 		var post model.Post
-		err := s.GetReplica().SelectOne(&post, "SELECT * FROM Posts WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id})
-		if err != nil {
+		err := s.selectOneContext(ctx, s.GetReplica(), &post, "SELECT * FROM Posts WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id})
+		if ctx.Err() != nil {
+			result.Err = newContextAppError("SqlPostStore.GetPost", ctx)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.GetPost", "store.sql_post.get.app_error", nil, "id="+id+err.Error())
 			storeChannel <- result
 			close(storeChannel)
@@ -340,8 +412,13 @@ func (s SqlPostStore) Get(id string) StoreChannel {
 		}
 
 		var posts []*model.Post
-		_, err = s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = 0", map[string]interface{}{"Id": rootId, "RootId": rootId})
-		if err != nil {
+		err = s.selectContext(ctx, s.GetReplica(), &posts, "SELECT * FROM Posts WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = 0", map[string]interface{}{"Id": rootId, "RootId": rootId})
+		if ctx.Err() != nil {
+			result.Err = newContextAppError("SqlPostStore.GetPost", ctx)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.GetPost", "store.sql_post.get.app_error", nil, "root_id="+rootId+err.Error())
 			storeChannel <- result
 			close(storeChannel)
@@ -362,14 +439,21 @@ func (s SqlPostStore) Get(id string) StoreChannel {
 }
 
 func (s SqlPostStore) GetSingle(id string) StoreChannel {
+	return s.GetSingleContext(context.Background(), id)
+}
+
+// GetSingleContext is GetSingle with the query cancelled along with ctx.
+func (s SqlPostStore) GetSingleContext(ctx context.Context, id string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
 		var post model.Post
-		err := s.GetReplica().SelectOne(&post, "SELECT * FROM Posts WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id})
-		if err != nil {
+		err := s.selectOneContext(ctx, s.GetReplica(), &post, "SELECT * FROM Posts WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id})
+		if ctx.Err() != nil {
+			result.Err = newContextAppError("SqlPostStore.GetSingle", ctx)
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.GetSingle", "store.sql_post.get.app_error", nil, "id="+id+err.Error())
 		}
 
@@ -436,14 +520,26 @@ func (s SqlPostStore) GetEtag(channelId string, allowFromCache bool) StoreChanne
 	return storeChannel
 }
 
+// Delete marks a post and its replies as deleted along with their reactions. Everything is kept with
+// the same DeleteAt so that it can be brought back with Restore.
 func (s SqlPostStore) Delete(postId string, time int64) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
-		_, err := s.GetMaster().Exec("Update Posts SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id OR RootId = :RootId", map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": postId, "RootId": postId})
-		if err != nil {
+		params := map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": postId, "RootId": postId}
+
+		if _, err := s.GetMaster().Exec(
+			`UPDATE
+				Reactions
+			SET
+				DeleteAt = :DeleteAt
+			WHERE
+				DeleteAt = 0
+				AND PostId IN (SELECT Id FROM Posts WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = 0)`, params); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.Delete", "store.sql_post.delete.app_error", nil, "id="+postId+", err="+err.Error())
+		} else if _, err := s.GetMaster().Exec("Update Posts SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = 0", params); err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.Delete", "store.sql_post.delete.app_error", nil, "id="+postId+", err="+err.Error())
 		}
 
@@ -454,12 +550,79 @@ func (s SqlPostStore) Delete(postId string, time int64) StoreChannel {
 	return storeChannel
 }
 
+// Restore brings back a deleted post along with the replies and reactions that were deleted with it
+// and returns the posts that were restored. Replies and reactions that were deleted on their own stay
+// deleted.
+func (s SqlPostStore) Restore(postId string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+
+		if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+			posts = nil
+
+			deleteAt, err := transaction.SelectInt("SELECT DeleteAt FROM Posts WHERE Id = :Id", map[string]interface{}{"Id": postId})
+			if err != nil {
+				return err
+			} else if deleteAt == 0 {
+				return nil
+			}
+
+			params := map[string]interface{}{"Id": postId, "RootId": postId, "DeleteAt": deleteAt, "UpdateAt": time}
+
+			if _, err := transaction.Select(&posts, "SELECT * FROM Posts WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = :DeleteAt", params); err != nil {
+				return err
+			}
+
+			if _, err := transaction.Exec(
+				`UPDATE
+					Reactions
+				SET
+					DeleteAt = 0
+				WHERE
+					DeleteAt = :DeleteAt
+					AND PostId IN (SELECT Id FROM Posts WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = :DeleteAt)`, params); err != nil {
+				return err
+			}
+
+			_, err = transaction.Exec("UPDATE Posts SET DeleteAt = 0, UpdateAt = :UpdateAt WHERE (Id = :Id OR RootId = :RootId) AND DeleteAt = :DeleteAt", params)
+			return err
+		}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.Restore", "store.sql_post.restore.app_error", nil, "id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+		} else if len(posts) == 0 {
+			result.Err = model.NewAppError("SqlPostStore.Restore", "store.sql_post.restore.not_deleted.app_error", nil, "id="+postId, http.StatusBadRequest)
+		} else {
+			for _, post := range posts {
+				post.DeleteAt = 0
+				post.UpdateAt = time
+			}
+
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) permanentDelete(postId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
+		if _, err := s.GetMaster().Exec("DELETE FROM PostsHistory WHERE PostId = :Id OR RootId = :RootId", map[string]interface{}{"Id": postId, "RootId": postId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.Delete", "store.sql_post.permanent_delete.app_error", nil, "id="+postId+", err="+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
 		_, err := s.GetMaster().Exec("DELETE FROM Posts WHERE Id = :Id OR RootId = :RootId", map[string]interface{}{"Id": postId, "RootId": postId})
 		if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.Delete", "store.sql_post.permanent_delete.app_error", nil, "id="+postId+", err="+err.Error())
@@ -478,6 +641,13 @@ func (s SqlPostStore) permanentDeleteAllCommentByUser(userId string) StoreChanne
 	go func() {
 		result := StoreResult{}
 
+		if _, err := s.GetMaster().Exec("DELETE FROM PostsHistory WHERE UserId = :UserId AND RootId != ''", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.permanentDeleteAllCommentByUser", "store.sql_post.permanent_delete_all_comments_by_user.app_error", nil, "userId="+userId+", err="+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
 		_, err := s.GetMaster().Exec("DELETE FROM Posts WHERE UserId = :UserId AND RootId != ''", map[string]interface{}{"UserId": userId})
 		if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.permanentDeleteAllCommentByUser", "store.sql_post.permanent_delete_all_comments_by_user.app_error", nil, "userId="+userId+", err="+err.Error())
@@ -553,8 +723,53 @@ func (s SqlPostStore) PermanentDeleteByChannel(channelId string) StoreChannel {
 	go func() {
 		result := StoreResult{}
 
-		if _, err := s.GetMaster().Exec("DELETE FROM Posts WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+		if _, err := s.GetMaster().Exec("DELETE FROM PostsHistory WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.PermanentDeleteByChannel", "store.sql_post.permanent_delete_by_channel.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		} else if _, err := s.GetMaster().Exec("DELETE FROM Posts WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.PermanentDeleteByChannel", "store.sql_post.permanent_delete_by_channel.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// PermanentDeleteBatch deletes posts along with their edit history. Replies to the posts are left in
+// place so that they can be deleted when they expire themselves.
+func (s SqlPostStore) PermanentDeleteBatch(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) == 0 {
+			result.Data = int64(0)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PostsHistory WHERE PostId IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.PermanentDeleteBatch", "store.sql_post.permanent_delete_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else if res, err := s.GetMaster().Exec("DELETE FROM Posts WHERE Id IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.PermanentDeleteBatch", "store.sql_post.permanent_delete_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			count, _ := res.RowsAffected()
+			result.Data = count
 		}
 
 		storeChannel <- result
@@ -565,6 +780,11 @@ func (s SqlPostStore) PermanentDeleteByChannel(channelId string) StoreChannel {
 }
 
 func (s SqlPostStore) GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel {
+	return s.GetPostsContext(context.Background(), channelId, offset, limit, allowFromCache)
+}
+
+// GetPostsContext is GetPosts with the queries cancelled along with ctx.
+func (s SqlPostStore) GetPostsContext(ctx context.Context, channelId string, offset int, limit int, allowFromCache bool) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
@@ -599,8 +819,8 @@ func (s SqlPostStore) GetPosts(channelId string, offset int, limit int, allowFro
 			}
 		}
 
-		rpc := s.getRootPosts(channelId, offset, limit)
-		cpc := s.getParentsPosts(channelId, offset, limit)
+		rpc := s.getRootPosts(ctx, channelId, offset, limit)
+		cpc := s.getParentsPosts(ctx, channelId, offset, limit)
 
 		if rpr := <-rpc; rpr.Err != nil {
 			result.Err = rpr.Err
@@ -824,15 +1044,17 @@ func (s SqlPostStore) getPostsAround(channelId string, postId string, numPosts i
 	return storeChannel
 }
 
-func (s SqlPostStore) getRootPosts(channelId string, offset int, limit int) StoreChannel {
+func (s SqlPostStore) getRootPosts(ctx context.Context, channelId string, offset int, limit int) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
 		var posts []*model.Post
-		_, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE ChannelId = :ChannelId AND DeleteAt = 0 ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"ChannelId": channelId, "Offset": offset, "Limit": limit})
-		if err != nil {
+		err := s.selectContext(ctx, s.GetReplica(), &posts, "SELECT * FROM Posts WHERE ChannelId = :ChannelId AND DeleteAt = 0 ORDER BY CreateAt DESC LIMIT :Limit OFFSET :Offset", map[string]interface{}{"ChannelId": channelId, "Offset": offset, "Limit": limit})
+		if ctx.Err() != nil {
+			result.Err = newContextAppError("SqlPostStore.GetLinearPosts", ctx)
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.GetLinearPosts", "store.sql_post.get_root_posts.app_error", nil, "channelId="+channelId+err.Error())
 		} else {
 			result.Data = posts
@@ -845,14 +1067,14 @@ func (s SqlPostStore) getRootPosts(channelId string, offset int, limit int) Stor
 	return storeChannel
 }
 
-func (s SqlPostStore) getParentsPosts(channelId string, offset int, limit int) StoreChannel {
+func (s SqlPostStore) getParentsPosts(ctx context.Context, channelId string, offset int, limit int) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
 	go func() {
 		result := StoreResult{}
 
 		var posts []*model.Post
-		_, err := s.GetReplica().Select(&posts,
+		err := s.selectContext(ctx, s.GetReplica(), &posts,
 			`SELECT
 			    q2.*
 			FROM
@@ -877,7 +1099,9 @@ func (s SqlPostStore) getParentsPosts(channelId string, offset int, limit int) S
 			        AND DeleteAt = 0
 			ORDER BY CreateAt`,
 			map[string]interface{}{"ChannelId1": channelId, "Offset": offset, "Limit": limit, "ChannelId2": channelId})
-		if err != nil {
+		if ctx.Err() != nil {
+			result.Err = newContextAppError("SqlPostStore.GetLinearPosts", ctx)
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlPostStore.GetLinearPosts", "store.sql_post.get_parents_posts.app_error", nil, "channelId="+channelId+err.Error())
 		} else {
 			result.Data = posts
@@ -916,7 +1140,7 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 		termMap := map[string]bool{}
 		terms := params.Terms
 
-		if terms == "" && len(params.InChannels) == 0 && len(params.FromUsers) == 0 {
+		if terms == "" && !params.HasFilters() {
 			result.Data = []*model.Post{}
 			storeChannel <- result
 			return
@@ -946,6 +1170,8 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 				DeleteAt = 0
 				AND Type NOT LIKE '` + model.POST_SYSTEM_MESSAGE_PREFIX + `%'
 				POST_FILTER
+				DATE_FILTER
+				REACTION_FILTER
 				AND ChannelId IN (
 					SELECT
 						Id
@@ -1018,6 +1244,44 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 			searchQuery = strings.Replace(searchQuery, "POST_FILTER", "", 1)
 		}
 
+		dateFilter := ""
+		if afterDate := params.GetAfterDateMillis(); afterDate != 0 {
+			queryParams["AfterDate"] = afterDate
+			dateFilter += " AND CreateAt >= :AfterDate"
+		}
+		if beforeDate := params.GetBeforeDateMillis(); beforeDate != 0 {
+			queryParams["BeforeDate"] = beforeDate
+			dateFilter += " AND CreateAt < :BeforeDate"
+		}
+		searchQuery = strings.Replace(searchQuery, "DATE_FILTER", dateFilter, 1)
+
+		if len(params.ReactedBy) > 0 {
+			inClause := ":ReactedBy0"
+			queryParams["ReactedBy0"] = params.ReactedBy[0]
+
+			for i := 1; i < len(params.ReactedBy); i++ {
+				paramName := "ReactedBy" + strconv.FormatInt(int64(i), 10)
+				inClause += ", :" + paramName
+				queryParams[paramName] = params.ReactedBy[i]
+			}
+
+			searchQuery = strings.Replace(searchQuery, "REACTION_FILTER", `
+				AND Id IN (
+					SELECT
+						Reactions.PostId
+					FROM
+						Reactions
+					INNER JOIN
+						Users ON Reactions.UserId = Users.Id
+					WHERE
+						Users.Username IN (`+inClause+`)
+						AND Reactions.DeleteAt = 0)`, 1)
+		} else if params.HasReaction {
+			searchQuery = strings.Replace(searchQuery, "REACTION_FILTER", "AND HasReactions = true", 1)
+		} else {
+			searchQuery = strings.Replace(searchQuery, "REACTION_FILTER", "", 1)
+		}
+
 		if terms == "" {
 			// we've already confirmed that we have a channel or user to search for
 			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "", 1)
@@ -1275,3 +1539,643 @@ func (s SqlPostStore) GetPostsCreatedAt(channelId string, time int64) StoreChann
 
 	return storeChannel
 }
+
+// SaveThreadMembership creates the user's membership in the thread or replaces their existing one.
+func (s SqlPostStore) SaveThreadMembership(membership *model.ThreadMembership) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		membership.PreSave()
+		if result.Err = membership.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		query := `UPDATE
+				ThreadMemberships
+			SET
+				Following = :Following,
+				LastViewedAt = :LastViewedAt,
+				MentionCount = :MentionCount,
+				UpdateAt = :UpdateAt
+			WHERE
+				PostId = :PostId
+				AND UserId = :UserId`
+
+		params := map[string]interface{}{
+			"PostId":       membership.PostId,
+			"UserId":       membership.UserId,
+			"Following":    membership.Following,
+			"LastViewedAt": membership.LastViewedAt,
+			"MentionCount": membership.MentionCount,
+			"UpdateAt":     membership.UpdateAt,
+		}
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.SaveThreadMembership", "store.sql_post.save_thread_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			if err := s.GetMaster().Insert(membership); err != nil {
+				// The membership may have been created by a concurrent reply or mention
+				if _, err := s.GetMaster().Exec(query, params); err != nil {
+					result.Err = model.NewAppError("SqlPostStore.SaveThreadMembership", "store.sql_post.save_thread_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveThreadMembershipIfNotExists creates the membership unless the user already has one for the
+// thread, so that a user who has unfollowed a thread isn't made to follow it again.
+func (s SqlPostStore) SaveThreadMembershipIfNotExists(membership *model.ThreadMembership) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		membership.PreSave()
+		if result.Err = membership.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		params := map[string]interface{}{"PostId": membership.PostId, "UserId": membership.UserId}
+
+		if count, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId", params); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.SaveThreadMembershipIfNotExists", "store.sql_post.save_thread_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			if err := s.GetMaster().Insert(membership); err != nil {
+				if count, _ := s.GetMaster().SelectInt("SELECT COUNT(*) FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId", params); count == 0 {
+					result.Err = model.NewAppError("SqlPostStore.SaveThreadMembershipIfNotExists", "store.sql_post.save_thread_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) GetThreadMembership(postId, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var membership model.ThreadMembership
+
+		if err := s.GetReplica().SelectOne(&membership, "SELECT * FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlPostStore.GetThreadMembership", "store.sql_post.get_thread_membership.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlPostStore.GetThreadMembership", "store.sql_post.get_thread_membership.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetThreadFollowers returns the ids of the users following the thread started by the given post.
+func (s SqlPostStore) GetThreadFollowers(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+
+		if _, err := s.GetReplica().Select(&userIds, "SELECT UserId FROM ThreadMemberships WHERE PostId = :PostId AND Following = true", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetThreadFollowers", "store.sql_post.get_thread_followers.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IncrementThreadMentionCount counts a mention of the user in a reply to the thread. Users who
+// weren't yet members of the thread start following it.
+func (s SqlPostStore) IncrementThreadMentionCount(postId, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := `UPDATE
+				ThreadMemberships
+			SET
+				MentionCount = MentionCount + 1,
+				UpdateAt = :UpdateAt
+			WHERE
+				PostId = :PostId
+				AND UserId = :UserId`
+
+		params := map[string]interface{}{"PostId": postId, "UserId": userId, "UpdateAt": model.GetMillis()}
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.IncrementThreadMentionCount", "store.sql_post.increment_thread_mention_count.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			membership := &model.ThreadMembership{
+				PostId:       postId,
+				UserId:       userId,
+				Following:    true,
+				MentionCount: 1,
+			}
+			membership.PreSave()
+
+			if err := s.GetMaster().Insert(membership); err != nil {
+				if _, err := s.GetMaster().Exec(query, params); err != nil {
+					result.Err = model.NewAppError("SqlPostStore.IncrementThreadMentionCount", "store.sql_post.increment_thread_mention_count.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateThreadLastViewedAt marks the thread as read by the user and clears their mention count.
+func (s SqlPostStore) UpdateThreadLastViewedAt(postId, userId string, lastViewedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := `UPDATE
+				ThreadMemberships
+			SET
+				LastViewedAt = :LastViewedAt,
+				MentionCount = 0,
+				UpdateAt = :UpdateAt
+			WHERE
+				PostId = :PostId
+				AND UserId = :UserId`
+
+		params := map[string]interface{}{"PostId": postId, "UserId": userId, "LastViewedAt": lastViewedAt, "UpdateAt": model.GetMillis()}
+
+		if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.UpdateThreadLastViewedAt", "store.sql_post.update_thread_last_viewed_at.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+			result.Err = model.NewAppError("SqlPostStore.UpdateThreadLastViewedAt", "store.sql_post.update_thread_last_viewed_at.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetUnreadThreadsForUser returns the threads in the team, including direct and group messages,
+// that the user follows and has unread replies or mentions in.
+func (s SqlPostStore) GetUnreadThreadsForUser(userId, teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var threads []*model.ThreadUnread
+
+		if _, err := s.GetReplica().Select(&threads,
+			`SELECT
+				ThreadMemberships.PostId,
+				Posts.ChannelId,
+				ThreadMemberships.MentionCount,
+				(SELECT
+					COUNT(*)
+				FROM
+					Posts Replies
+				WHERE
+					Replies.RootId = ThreadMemberships.PostId
+					AND Replies.UserId != ThreadMemberships.UserId
+					AND Replies.CreateAt > ThreadMemberships.LastViewedAt
+					AND Replies.DeleteAt = 0) AS UnreadReplies
+			FROM
+				ThreadMemberships
+				INNER JOIN Posts ON Posts.Id = ThreadMemberships.PostId
+				INNER JOIN Channels ON Channels.Id = Posts.ChannelId
+			WHERE
+				ThreadMemberships.UserId = :UserId
+				AND ThreadMemberships.Following = true
+				AND Posts.DeleteAt = 0
+				AND (Channels.TeamId = :TeamId OR Channels.TeamId = '')
+			ORDER BY
+				ThreadMemberships.UpdateAt DESC`, map[string]interface{}{"UserId": userId, "TeamId": teamId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetUnreadThreadsForUser", "store.sql_post.get_unread_threads_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			unread := []*model.ThreadUnread{}
+			for _, thread := range threads {
+				if thread.UnreadReplies > 0 || thread.MentionCount > 0 {
+					unread = append(unread, thread)
+				}
+			}
+
+			result.Data = unread
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) GetPostsByIds(postIds []string) StoreChannel {
+	return s.getPostsByIds(postIds, false)
+}
+
+// GetPostsByIdsIncludeDeleted is GetPostsByIds including the posts that were deleted.
+func (s SqlPostStore) GetPostsByIdsIncludeDeleted(postIds []string) StoreChannel {
+	return s.getPostsByIds(postIds, true)
+}
+
+func (s SqlPostStore) getPostsByIds(postIds []string, includeDeleted bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		deleteFilter := "AND DeleteAt = 0"
+		if includeDeleted {
+			deleteFilter = ""
+		}
+
+		var posts []*model.Post
+
+		if len(postIds) == 0 {
+			result.Data = posts
+		} else if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE Id IN ("+idQuery+") "+deleteFilter+" ORDER BY CreateAt DESC", props); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostsByIds", "store.sql_post.get_posts_by_ids.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPostsBatchForIndexing returns the posts updated since startTime, oldest first, along with the
+// team of their channel. Deleted posts are included so they can be removed from the search index.
+func (s SqlPostStore) GetPostsBatchForIndexing(startTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.PostForIndexing
+
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				Posts.*, Channels.TeamId
+			FROM
+				Posts
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				Posts.UpdateAt >= :StartTime
+			ORDER BY
+				Posts.UpdateAt ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"StartTime": startTime, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostsBatchForIndexing", "store.sql_post.get_posts_batch_for_indexing.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPostsForArchive returns a page of the posts of a channel that haven't been deleted, oldest
+// first. The page starts after the post with the given create time and id so that paging through
+// a channel isn't affected by posts that are made or deleted in the meantime.
+func (s SqlPostStore) GetPostsForArchive(channelId string, afterCreateAt int64, afterId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND DeleteAt = 0
+				AND (CreateAt > :CreateAt OR (CreateAt = :CreateAt AND Id > :Id))
+			ORDER BY
+				CreateAt ASC, Id ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"ChannelId": channelId, "CreateAt": afterCreateAt, "Id": afterId, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostsForArchive", "store.sql_post.get_posts_for_archive.app_error", nil, "channelId="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SaveCrossPosts saves the copies of a cross-posted message and links them together through
+// their shared cross post id. Either all of the posts are saved or none of them are.
+func (s SqlPostStore) SaveCrossPosts(posts []*model.Post) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		links := make([]*model.CrossPost, 0, len(posts))
+		for _, post := range posts {
+			if len(post.Id) > 0 {
+				result.Err = model.NewLocAppError("SqlPostStore.SaveCrossPosts", "store.sql_post.save.existing.app_error", nil, "id="+post.Id)
+				break
+			}
+
+			post.PreSave()
+			if result.Err = post.IsValid(); result.Err != nil {
+				break
+			}
+
+			link := &model.CrossPost{CrossPostId: post.GetCrossPostId(), PostId: post.Id, ChannelId: post.ChannelId}
+			if result.Err = link.IsValid(); result.Err != nil {
+				break
+			}
+
+			links = append(links, link)
+		}
+
+		if result.Err == nil {
+			if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+				if err := s.saveCrossPostsT(transaction, posts, links); err != nil {
+					return err
+				}
+
+				return nil
+			}); err != nil {
+				if appErr, ok := err.(*model.AppError); ok {
+					result.Err = appErr
+				} else {
+					result.Err = model.NewAppError("SqlPostStore.SaveCrossPosts", "store.sql_post.save_cross_posts.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+				}
+			} else {
+				result.Data = posts
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) saveCrossPostsT(transaction *gorp.Transaction, posts []*model.Post, links []*model.CrossPost) *model.AppError {
+	for i, post := range posts {
+		if err := transaction.Insert(post, links[i]); err != nil {
+			return model.NewAppError("SqlPostStore.SaveCrossPosts", "store.sql_post.save.app_error", nil, "id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		if _, err := transaction.Exec("UPDATE Channels SET LastPostAt = :LastPostAt, TotalMsgCount = TotalMsgCount + 1 WHERE Id = :ChannelId", map[string]interface{}{"LastPostAt": post.UpdateAt, "ChannelId": post.ChannelId}); err != nil {
+			return model.NewAppError("SqlPostStore.SaveCrossPosts", "store.sql_post.save.app_error", nil, "id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		if len(post.RootId) > 0 {
+			if _, err := transaction.Exec("UPDATE Posts SET UpdateAt = :UpdateAt WHERE Id = :RootId", map[string]interface{}{"UpdateAt": post.UpdateAt, "RootId": post.RootId}); err != nil {
+				return model.NewAppError("SqlPostStore.SaveCrossPosts", "store.sql_post.save.app_error", nil, "id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetCrossPosts returns the undeleted posts that share the given cross post id.
+func (s SqlPostStore) GetCrossPosts(crossPostId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetMaster().Select(&posts,
+			`SELECT
+				Posts.*
+			FROM
+				Posts
+				INNER JOIN CrossPosts ON CrossPosts.PostId = Posts.Id
+			WHERE
+				CrossPosts.CrossPostId = :CrossPostId
+				AND Posts.DeleteAt = 0
+			ORDER BY Posts.CreateAt`, map[string]interface{}{"CrossPostId": crossPostId}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetCrossPosts", "store.sql_post.get_cross_posts.app_error", nil, "cross_post_id="+crossPostId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetChannelSnapshot returns the posts, reactions and files of a channel as they were at the given
+// time. All of them are read in a single repeatable read transaction so that the snapshot is
+// consistent even while the channel is being used. Reactions that users removed since then can't be
+// recovered since they aren't kept once deleted, unlike the ones removed along with their post.
+func (s SqlPostStore) GetChannelSnapshot(channelId string, asOf int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		snapshot := &model.ChannelSnapshot{AsOf: asOf}
+		params := map[string]interface{}{"ChannelId": channelId, "AsOf": asOf}
+
+		transaction, err := s.GetMaster().Begin()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.open_transaction.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		// MySQL already defaults to repeatable read, which makes every read in the transaction see the same snapshot
+		if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+			if _, err := transaction.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"); err != nil {
+				transaction.Rollback()
+				result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.open_transaction.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		// Editing a post keeps its previous version as a deleted copy that has the original post's id
+		// as its OriginalId, so the version of an edited post at the time of the snapshot is the one
+		// that was created by then and neither deleted nor edited until after.
+		if _, err := transaction.Select(&snapshot.Posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND CreateAt <= :AsOf
+				AND (DeleteAt = 0 OR DeleteAt > :AsOf)
+				AND EditAt <= :AsOf
+			ORDER BY CreateAt ASC`, params); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Select(&snapshot.Reactions,
+			`SELECT
+				Reactions.*
+			FROM
+				Reactions, Posts
+			WHERE
+				Reactions.PostId = Posts.Id
+				AND Posts.ChannelId = :ChannelId
+				AND Reactions.CreateAt <= :AsOf
+				AND (Reactions.DeleteAt = 0 OR Reactions.DeleteAt > :AsOf)
+			ORDER BY Reactions.CreateAt ASC`, params); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := transaction.Select(&snapshot.FileInfos,
+			`SELECT
+				FileInfo.*
+			FROM
+				FileInfo, Posts
+			WHERE
+				FileInfo.PostId = Posts.Id
+				AND Posts.ChannelId = :ChannelId
+				AND FileInfo.CreateAt <= :AsOf
+				AND (FileInfo.DeleteAt = 0 OR FileInfo.DeleteAt > :AsOf)
+			ORDER BY FileInfo.CreateAt ASC`, params); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := transaction.Commit(); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetChannelSnapshot", "store.sql_post.get_channel_snapshot.commit_transaction.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = snapshot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+type postIdWithChannelId struct {
+	Id        string
+	ChannelId string
+}
+
+// GetChannelIdsForPosts returns a map of the given post ids to the ids of their channels. Posts that
+// don't exist are left out.
+func (s SqlPostStore) GetChannelIdsForPosts(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		channelIds := make(map[string]string, len(postIds))
+
+		var posts []postIdWithChannelId
+
+		if len(postIds) == 0 {
+			result.Data = channelIds
+		} else if _, err := s.GetReplica().Select(&posts, "SELECT Id, ChannelId FROM Posts WHERE Id IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetChannelIdsForPosts", "store.sql_post.get_channel_ids_for_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			for _, post := range posts {
+				channelIds[post.Id] = post.ChannelId
+			}
+
+			result.Data = channelIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -4,7 +4,9 @@
 package store
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -90,6 +92,32 @@ func TestPostStoreGetSingle(t *testing.T) {
 	}
 }
 
+func TestPostStoreGetSingleContext(t *testing.T) {
+	Setup()
+
+	o1 := &model.Post{}
+	o1.ChannelId = model.NewId()
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+
+	o1 = (<-store.Post().Save(o1)).Data.(*model.Post)
+
+	if r1 := <-store.Post().GetSingleContext(context.Background(), o1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if r1.Data.(*model.Post).CreateAt != o1.CreateAt {
+		t.Fatal("invalid returned post")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := (<-store.Post().GetSingleContext(ctx, o1.Id)).Err; err == nil {
+		t.Fatal("cancelled context should have failed")
+	} else if err.StatusCode != http.StatusServiceUnavailable {
+		t.Fatal("should have returned service unavailable")
+	}
+}
+
 func TestGetEtagCache(t *testing.T) {
 	Setup()
 	o1 := &model.Post{}
@@ -219,6 +247,42 @@ func TestPostStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestPostStoreGetPostHistory(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+
+	if history := Must(store.Post().GetPostHistory(o1.Id)).([]*model.PostHistory); len(history) != 0 {
+		t.Fatal("shouldn't have any history")
+	}
+
+	ro1 := (<-store.Post().Get(o1.Id)).Data.(*model.PostList).Posts[o1.Id]
+	o1a := &model.Post{}
+	*o1a = *ro1
+	o1a.Message = ro1.Message + "BBBBBBBBBB"
+	Must(store.Post().Update(o1a, ro1))
+
+	time.Sleep(2 * time.Millisecond)
+
+	ro1a := (<-store.Post().Get(o1.Id)).Data.(*model.PostList).Posts[o1.Id]
+	o1b := &model.Post{}
+	*o1b = *ro1a
+	o1b.IsPinned = true
+	Must(store.Post().Update(o1b, ro1a))
+
+	if history := Must(store.Post().GetPostHistory(o1.Id)).([]*model.PostHistory); len(history) != 1 {
+		t.Fatal("should only have saved the revision with a different message")
+	} else if history[0].Message != o1.Message || history[0].ChannelId != o1.ChannelId {
+		t.Fatal("should have saved the original message")
+	}
+
+	Must(store.Post().PermanentDeleteByChannel(o1.ChannelId))
+
+	if history := Must(store.Post().GetPostHistory(o1.Id)).([]*model.PostHistory); len(history) != 0 {
+		t.Fatal("should have deleted the history with the channel")
+	}
+}
+
 func TestPostStoreDelete(t *testing.T) {
 	Setup()
 
@@ -257,6 +321,45 @@ func TestPostStoreDelete(t *testing.T) {
 	}
 }
 
+func TestPostStoreRestore(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: o1.ChannelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b", ParentId: o1.Id, RootId: o1.Id})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: o1.ChannelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b", ParentId: o1.Id, RootId: o1.Id})).(*model.Post)
+
+	reaction := Must(store.Reaction().Save(&model.Reaction{UserId: model.NewId(), PostId: o2.Id, EmojiName: "smile"})).(*model.Reaction)
+
+	if result := <-store.Post().Restore(o1.Id, model.GetMillis()); result.Err == nil {
+		t.Fatal("shouldn't be able to restore a post that isn't deleted")
+	}
+
+	Must(store.Post().Delete(o3.Id, model.GetMillis()-1000))
+	Must(store.Post().Delete(o1.Id, model.GetMillis()))
+
+	if reactions := Must(store.Reaction().GetForPost(o2.Id, false)).([]*model.Reaction); len(reactions) != 0 {
+		t.Fatal("should have removed the reactions with the post")
+	}
+
+	if result := <-store.Post().Restore(o1.Id, model.GetMillis()); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if posts := result.Data.([]*model.Post); len(posts) != 2 {
+		t.Fatal("should have restored the post and the reply that was deleted with it")
+	}
+
+	if result := <-store.Post().GetSingle(o2.Id); result.Err != nil {
+		t.Fatal("should have restored the reply")
+	}
+
+	if result := <-store.Post().GetSingle(o3.Id); result.Err == nil {
+		t.Fatal("the reply that was deleted on its own should stay deleted")
+	}
+
+	if reactions := Must(store.Reaction().GetForPost(o2.Id, false)).([]*model.Reaction); len(reactions) != 1 || reactions[0].EmojiName != reaction.EmojiName {
+		t.Fatal("should have restored the reactions with the post")
+	}
+}
+
 func TestPostStoreDelete1Level(t *testing.T) {
 	Setup()
 
@@ -911,6 +1014,61 @@ func TestPostStoreSearch(t *testing.T) {
 	}
 }
 
+func TestPostStoreSearchFilters(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	c1 := &model.Channel{}
+	c1.TeamId = teamId
+	c1.DisplayName = "Channel1"
+	c1.Name = "a" + model.NewId() + "b"
+	c1.Type = model.CHANNEL_OPEN
+	c1 = Must(store.Channel().Save(c1)).(*model.Channel)
+
+	m1 := model.ChannelMember{}
+	m1.ChannelId = c1.Id
+	m1.UserId = userId
+	m1.NotifyProps = model.GetDefaultChannelNotifyProps()
+	Must(store.Channel().SaveMember(&m1))
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	u1.Username = "a" + model.NewId()
+	u1 = Must(store.User().Save(u1)).(*model.User)
+
+	// 2017-05-01 and 2017-05-03 at noon UTC
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: userId, Message: "filtered search", CreateAt: 1493640000000})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: userId, Message: "filtered search", CreateAt: 1493812800000})).(*model.Post)
+
+	Must(store.Reaction().Save(&model.Reaction{PostId: o2.Id, UserId: u1.Id, EmojiName: "smile"}))
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{Terms: "filtered", AfterDate: "2017-05-01"})).(*model.PostList); len(r.Order) != 1 || r.Order[0] != o2.Id {
+		t.Fatal("should only have found the post after the date")
+	}
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{Terms: "filtered", BeforeDate: "2017-05-02"})).(*model.PostList); len(r.Order) != 1 || r.Order[0] != o1.Id {
+		t.Fatal("should only have found the post before the date")
+	}
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{AfterDate: "2017-04-30", BeforeDate: "2017-05-04"})).(*model.PostList); len(r.Order) != 2 {
+		t.Fatal("should have found both posts between the dates without any terms")
+	}
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{Terms: "filtered", HasReaction: true})).(*model.PostList); len(r.Order) != 1 || r.Order[0] != o2.Id {
+		t.Fatal("should only have found the post with a reaction")
+	}
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{Terms: "filtered", ReactedBy: []string{u1.Username}})).(*model.PostList); len(r.Order) != 1 || r.Order[0] != o2.Id {
+		t.Fatal("should only have found the post the user reacted to")
+	}
+
+	if r := Must(store.Post().Search(teamId, userId, &model.SearchParams{Terms: "filtered", ReactedBy: []string{"a" + model.NewId()}})).(*model.PostList); len(r.Order) != 0 {
+		t.Fatal("shouldn't have found any posts reacted to by another user")
+	}
+}
+
 func TestUserCountsWithPostsByDay(t *testing.T) {
 	Setup()
 
@@ -1550,3 +1708,289 @@ func TestPostStoreOverwrite(t *testing.T) {
 		t.Fatal("Failed to set FileIds")
 	}
 }
+
+func TestPostStoreThreadMemberships(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	channel := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	root := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId1, Message: "root"})).(*model.Post)
+
+	if result := <-store.Post().GetThreadMembership(root.Id, userId1); result.Err == nil || result.Err.StatusCode != 404 {
+		t.Fatal("should not have found membership")
+	}
+
+	Must(store.Post().SaveThreadMembership(&model.ThreadMembership{PostId: root.Id, UserId: userId1, Following: true, LastViewedAt: root.CreateAt}))
+	Must(store.Post().SaveThreadMembership(&model.ThreadMembership{PostId: root.Id, UserId: userId2, Following: false}))
+
+	// Shouldn't replace the existing membership
+	Must(store.Post().SaveThreadMembershipIfNotExists(&model.ThreadMembership{PostId: root.Id, UserId: userId2, Following: true}))
+
+	if followers := Must(store.Post().GetThreadFollowers(root.Id)).([]string); len(followers) != 1 || followers[0] != userId1 {
+		t.Fatal("should have returned only the following user")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId2, RootId: root.Id, Message: "reply"}))
+	Must(store.Post().IncrementThreadMentionCount(root.Id, userId1))
+
+	if threads := Must(store.Post().GetUnreadThreadsForUser(userId1, team.Id)).([]*model.ThreadUnread); len(threads) != 1 {
+		t.Fatal("should have returned the unread thread")
+	} else if threads[0].PostId != root.Id || threads[0].ChannelId != channel.Id || threads[0].UnreadReplies != 1 || threads[0].MentionCount != 1 {
+		t.Fatal("unread counts are wrong")
+	}
+
+	if threads := Must(store.Post().GetUnreadThreadsForUser(userId2, team.Id)).([]*model.ThreadUnread); len(threads) != 0 {
+		t.Fatal("shouldn't return threads that aren't followed")
+	}
+
+	Must(store.Post().UpdateThreadLastViewedAt(root.Id, userId1, model.GetMillis()))
+
+	if threads := Must(store.Post().GetUnreadThreadsForUser(userId1, team.Id)).([]*model.ThreadUnread); len(threads) != 0 {
+		t.Fatal("should have marked the thread as read")
+	}
+
+	if result := <-store.Post().UpdateThreadLastViewedAt(root.Id, model.NewId(), model.GetMillis()); result.Err == nil {
+		t.Fatal("should have failed for a user without a membership")
+	}
+
+	// Mentioning a user that isn't a member of the thread makes them follow it
+	userId3 := model.NewId()
+	Must(store.Post().IncrementThreadMentionCount(root.Id, userId3))
+
+	if membership := Must(store.Post().GetThreadMembership(root.Id, userId3)).(*model.ThreadMembership); !membership.Following || membership.MentionCount != 1 {
+		t.Fatal("should have created a following membership")
+	}
+}
+
+func TestPostStoreGetPostsBatchForIndexing(t *testing.T) {
+	Setup()
+
+	c1 := &model.Channel{}
+	c1.TeamId = model.NewId()
+	c1.DisplayName = "Channel1"
+	c1.Name = "zz" + model.NewId() + "b"
+	c1.Type = model.CHANNEL_OPEN
+	c1 = (<-store.Channel().Save(c1)).Data.(*model.Channel)
+
+	o1 := &model.Post{}
+	o1.ChannelId = c1.Id
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+	o1 = (<-store.Post().Save(o1)).Data.(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = c1.Id
+	o2.UserId = model.NewId()
+	o2.Message = "a" + model.NewId() + "b"
+	o2 = (<-store.Post().Save(o2)).Data.(*model.Post)
+
+	if r := <-store.Post().GetPostsBatchForIndexing(o1.UpdateAt, 100); r.Err != nil {
+		t.Fatal(r.Err)
+	} else {
+		found := 0
+		for _, post := range r.Data.([]*model.PostForIndexing) {
+			if post.Id == o1.Id || post.Id == o2.Id {
+				found++
+				if post.TeamId != c1.TeamId {
+					t.Fatal("should have returned the team of the post")
+				}
+			}
+		}
+
+		if found != 2 {
+			t.Fatal("should have returned both posts")
+		}
+	}
+
+	if r := <-store.Post().GetPostsByIds([]string{o1.Id, o2.Id}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]*model.Post)) != 2 {
+		t.Fatal("should have returned both posts")
+	}
+}
+
+func TestPostStoreGetPostsForArchive(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	o1 := &model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b"}
+	o1 = (<-store.Post().Save(o1)).Data.(*model.Post)
+
+	// Give the second post the same create time to check that ties are broken by id
+	o2 := &model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: o1.CreateAt}
+	o2 = (<-store.Post().Save(o2)).Data.(*model.Post)
+
+	o3 := &model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: o1.CreateAt + 1}
+	o3 = (<-store.Post().Save(o3)).Data.(*model.Post)
+	Must(store.Post().Delete(o3.Id, model.GetMillis()))
+
+	first, second := o1, o2
+	if o2.Id < o1.Id {
+		first, second = o2, o1
+	}
+
+	if r := <-store.Post().GetPostsForArchive(channelId, 0, "", 1); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if posts := r.Data.([]*model.Post); len(posts) != 1 || posts[0].Id != first.Id {
+		t.Fatal("should have returned the first post")
+	}
+
+	if r := <-store.Post().GetPostsForArchive(channelId, first.CreateAt, first.Id, 10); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if posts := r.Data.([]*model.Post); len(posts) != 1 || posts[0].Id != second.Id {
+		t.Fatal("should have returned the second post without the deleted one")
+	}
+}
+
+func TestPostStoreGetChannelIdsForPosts(t *testing.T) {
+	Setup()
+
+	o1 := &model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"}
+	o1 = (<-store.Post().Save(o1)).Data.(*model.Post)
+
+	o2 := &model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"}
+	o2 = (<-store.Post().Save(o2)).Data.(*model.Post)
+
+	if r := <-store.Post().GetChannelIdsForPosts([]string{o1.Id, o2.Id, model.NewId()}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if channelIds := r.Data.(map[string]string); len(channelIds) != 2 || channelIds[o1.Id] != o1.ChannelId || channelIds[o2.Id] != o2.ChannelId {
+		t.Fatal("should have returned the channels of the posts that exist", channelIds)
+	}
+}
+
+func TestPostStoreCrossPosts(t *testing.T) {
+	Setup()
+
+	crossPostId := model.NewId()
+	userId := model.NewId()
+
+	posts := []*model.Post{}
+	for i := 0; i < 2; i++ {
+		c := &model.Channel{TeamId: model.NewId(), DisplayName: "Channel", Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+		c = (<-store.Channel().Save(c)).Data.(*model.Channel)
+
+		post := &model.Post{ChannelId: c.Id, UserId: userId, Message: "a" + model.NewId() + "b"}
+		post.AddProp(model.POST_PROPS_CROSS_POST_ID, crossPostId)
+		posts = append(posts, post)
+	}
+
+	if r := <-store.Post().SaveCrossPosts(posts); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.Channel().Get(posts[1].ChannelId, false); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.Channel).TotalMsgCount != 1 {
+		t.Fatal("should have updated the channel")
+	}
+
+	if r := <-store.Post().GetCrossPosts(crossPostId); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if saved := r.Data.([]*model.Post); len(saved) != 2 {
+		t.Fatal("should have returned both posts")
+	}
+
+	Must(store.Post().Delete(posts[0].Id, model.GetMillis()))
+
+	if r := <-store.Post().GetCrossPosts(crossPostId); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if saved := r.Data.([]*model.Post); len(saved) != 1 || saved[0].Id != posts[1].Id {
+		t.Fatal("should not have returned the deleted post")
+	}
+
+	root := Must(store.Post().Save(&model.Post{ChannelId: posts[1].ChannelId, UserId: userId, Message: "root"})).(*model.Post)
+	reply := &model.Post{ChannelId: root.ChannelId, UserId: userId, RootId: root.Id, ParentId: root.Id, Message: "reply"}
+	reply.AddProp(model.POST_PROPS_CROSS_POST_ID, model.NewId())
+
+	if r := <-store.Post().SaveCrossPosts([]*model.Post{reply}); r.Err != nil {
+		t.Fatal(r.Err)
+	}
+
+	if r := <-store.Post().GetSingle(root.Id); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if r.Data.(*model.Post).UpdateAt != reply.UpdateAt {
+		t.Fatal("should have updated the root post")
+	}
+
+	invalid := []*model.Post{
+		{ChannelId: model.NewId(), UserId: userId, Message: "valid", Props: model.StringInterface{model.POST_PROPS_CROSS_POST_ID: model.NewId()}},
+		{ChannelId: "junk", UserId: userId, Message: "invalid"},
+	}
+	if r := <-store.Post().SaveCrossPosts(invalid); r.Err == nil {
+		t.Fatal("should have failed to save an invalid post")
+	}
+
+	if r := <-store.Post().GetCrossPosts(invalid[0].GetCrossPostId()); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]*model.Post)) != 0 {
+		t.Fatal("should not have saved any of the posts")
+	}
+}
+
+func TestPostStoreGetChannelSnapshot(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	o1 := &model.Post{}
+	o1.ChannelId = channelId
+	o1.UserId = model.NewId()
+	o1.Message = "a" + model.NewId() + "b"
+	o1 = (<-store.Post().Save(o1)).Data.(*model.Post)
+
+	o2 := &model.Post{}
+	o2.ChannelId = channelId
+	o2.UserId = model.NewId()
+	o2.Message = "a" + model.NewId() + "b"
+	o2 = (<-store.Post().Save(o2)).Data.(*model.Post)
+	time.Sleep(2 * time.Millisecond)
+
+	asOf := model.GetMillis()
+	time.Sleep(2 * time.Millisecond)
+
+	// Edit the first post and delete the second one after the snapshot time
+	ro1 := (<-store.Post().Get(o1.Id)).Data.(*model.PostList).Posts[o1.Id]
+	edited := &model.Post{}
+	*edited = *ro1
+	edited.Message = "edited"
+	edited.EditAt = model.GetMillis()
+	Must(store.Post().Update(edited, ro1))
+
+	Must(store.Post().Delete(o2.Id, model.GetMillis()))
+
+	o3 := &model.Post{}
+	o3.ChannelId = channelId
+	o3.UserId = model.NewId()
+	o3.Message = "a" + model.NewId() + "b"
+	o3 = (<-store.Post().Save(o3)).Data.(*model.Post)
+
+	snapshot := (<-store.Post().GetChannelSnapshot(channelId, asOf)).Data.(*model.ChannelSnapshot)
+
+	if len(snapshot.Posts) != 2 {
+		t.Fatal("should have returned the 2 posts created before the snapshot time", len(snapshot.Posts))
+	}
+
+	for _, post := range snapshot.Posts {
+		if post.Id == o3.Id {
+			t.Fatal("should not have returned a post created after the snapshot time")
+		} else if post.OriginalId == o1.Id {
+			if post.Message != o1.Message {
+				t.Fatal("should have returned the post as it was before being edited")
+			}
+		} else if post.Id != o2.Id {
+			t.Fatal("should have returned the post deleted after the snapshot time")
+		}
+	}
+
+	snapshot = (<-store.Post().GetChannelSnapshot(channelId, model.GetMillis())).Data.(*model.ChannelSnapshot)
+
+	if len(snapshot.Posts) != 2 {
+		t.Fatal("should have returned the edited post and the new post", len(snapshot.Posts))
+	}
+}
@@ -62,29 +62,22 @@ func (s SqlPreferenceStore) Save(preferences *model.Preferences) StoreChannel {
 		result := StoreResult{}
 
 		// wrap in a transaction so that if one fails, everything fails
-		transaction, err := s.GetMaster().Begin()
-		if err != nil {
-			result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_preference.save.open_transaction.app_error", nil, err.Error())
-		} else {
+		if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
 			for _, preference := range *preferences {
 				if upsertResult := s.save(transaction, &preference); upsertResult.Err != nil {
-					result = upsertResult
-					break
+					return upsertResult.Err
 				}
 			}
 
-			if result.Err == nil {
-				if err := transaction.Commit(); err != nil {
-					// don't need to rollback here since the transaction is already closed
-					result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_preference.save.commit_transaction.app_error", nil, err.Error())
-				} else {
-					result.Data = len(*preferences)
-				}
+			return nil
+		}); err != nil {
+			if appErr, ok := err.(*model.AppError); ok {
+				result.Err = appErr
 			} else {
-				if err := transaction.Rollback(); err != nil {
-					result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_preference.save.rollback_transaction.app_error", nil, err.Error())
-				}
+				result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_preference.save.commit_transaction.app_error", nil, err.Error())
 			}
+		} else {
+			result.Data = len(*preferences)
 		}
 
 		storeChannel <- result
@@ -121,6 +114,17 @@ func (s SqlPreferenceStore) save(transaction *gorp.Transaction, preference *mode
 				Value = :Value`, params); err != nil {
 			result.Err = model.NewLocAppError("SqlPreferenceStore.save", "store.sql_preference.save.updating.app_error", nil, err.Error())
 		}
+	} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES && s.Capabilities().Upsert {
+		if _, err := transaction.Exec(
+			`INSERT INTO
+				Preferences
+				(UserId, Category, Name, Value)
+			VALUES
+				(:UserId, :Category, :Name, :Value)
+			ON CONFLICT (UserId, Category, Name) DO UPDATE SET
+				Value = :Value`, params); err != nil {
+			result.Err = model.NewLocAppError("SqlPreferenceStore.save", "store.sql_preference.save.updating.app_error", nil, err.Error())
+		}
 	} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
 		// postgres has no way to upsert values until version 9.5 and trying inserting and then updating causes transactions to abort
 		count, err := transaction.SelectInt(
@@ -138,9 +142,9 @@ func (s SqlPreferenceStore) save(transaction *gorp.Transaction, preference *mode
 		}
 
 		if count == 1 {
-			s.update(transaction, preference)
+			result = s.update(transaction, preference)
 		} else {
-			s.insert(transaction, preference)
+			result = s.insert(transaction, preference)
 		}
 	} else {
 		result.Err = model.NewLocAppError("SqlPreferenceStore.save", "store.sql_preference.save.missing_driver.app_error", nil,
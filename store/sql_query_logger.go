@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/utils"
+)
+
+var sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// recordSqlQuery is called by the instrumented driver after every statement. Latencies are recorded
+// in the metrics interface by operation and table, and statements slower than
+// SqlSettings.SlowQueryThresholdMilliseconds are logged and added to the slow query log with their
+// parameters and any inline string literals redacted. Returns true if the statement's plan should be
+// captured.
+func recordSqlQuery(connection string, query string, elapsed time.Duration) bool {
+	if metrics := einterfaces.GetMetricsInterface(); metrics != nil {
+		metrics.ObserveSqlQueryDuration(sqlQueryOperation(query), elapsed.Seconds())
+	}
+
+	threshold := *utils.Cfg.SqlSettings.SlowQueryThresholdMilliseconds
+	if threshold <= 0 || elapsed < time.Duration(threshold)*time.Millisecond {
+		return false
+	}
+
+	l4g.Warn(utils.T("store.sql.slow_query.warn"), connection, int64(elapsed/time.Millisecond), redactSqlQuery(query))
+
+	return slowQueryLog.record(connection, query, elapsed, *utils.Cfg.SqlSettings.SlowQueryExplainThreshold)
+}
+
+// sqlQueryOperation names a query by its statement type and the first table it uses, such as
+// "UPDATE Posts", so latencies can be grouped without a label for every distinct query.
+func sqlQueryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+
+	operation := strings.ToUpper(strings.TrimRight(fields[0], ";"))
+
+	tableAfter := ""
+	switch operation {
+	case "SELECT", "DELETE":
+		tableAfter = "FROM"
+	case "INSERT":
+		tableAfter = "INTO"
+	case "UPDATE":
+		if len(fields) > 1 {
+			return operation + " " + sqlTableName(fields[1])
+		}
+		return operation
+	default:
+		return operation
+	}
+
+	for i, field := range fields[:len(fields)-1] {
+		if strings.ToUpper(field) == tableAfter {
+			if table := sqlTableName(fields[i+1]); len(table) > 0 && strings.ToUpper(table) != "SELECT" {
+				return operation + " " + table
+			}
+		}
+	}
+
+	return operation
+}
+
+func sqlTableName(field string) string {
+	return strings.Trim(field, "`\"(),;")
+}
+
+func redactSqlQuery(query string) string {
+	return sqlStringLiteral.ReplaceAllString(strings.Join(strings.Fields(query), " "), "'?'")
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+)
+
+func TestSqlQueryOperation(t *testing.T) {
+	for query, expected := range map[string]string{
+		"SELECT * FROM Posts WHERE Id = :Id":                       "SELECT Posts",
+		"SELECT COUNT(*) FROM (SELECT Id FROM Channels) AS c":      "SELECT Channels",
+		"UPDATE Posts SET HasReactions = true WHERE Id = :PostId":  "UPDATE Posts",
+		"insert into `Reactions` (`UserId`,`PostId`) values (?,?)": "INSERT Reactions",
+		"DELETE FROM \"Reactions\" WHERE PostId = $1":              "DELETE Reactions",
+		"begin;": "BEGIN",
+		"":       "UNKNOWN",
+		"\n\t\t\tSELECT\n\t\t\t\tUsers.*\n\t\t\tFROM\n\t\t\t\tUsers, Sessions": "SELECT Users",
+	} {
+		if operation := sqlQueryOperation(query); operation != expected {
+			t.Fatalf("expected %v for %v, got %v", expected, query, operation)
+		}
+	}
+}
+
+func TestRedactSqlQuery(t *testing.T) {
+	query := "SELECT *\n\tFROM Users WHERE Email = 'someone@example.com' AND Username = 'o''brien' AND Id = :Id"
+
+	if redacted := redactSqlQuery(query); redacted != "SELECT * FROM Users WHERE Email = '?' AND Username = '?' AND Id = :Id" {
+		t.Fatal("should have redacted the string literals", redacted)
+	}
+}
@@ -4,6 +4,11 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
@@ -15,9 +20,17 @@ import (
 const (
 	REACTION_CACHE_SIZE = 20000
 	REACTION_CACHE_SEC  = 1800 // 30 minutes
+
+	TOP_REACTIONS_CACHE_SIZE = 1000
+	TOP_REACTIONS_CACHE_SEC  = 300 // 5 minutes
+
+	REACTION_ANALYTICS_CACHE_SIZE = 1000
+	REACTION_ANALYTICS_CACHE_SEC  = 300 // 5 minutes
 )
 
-var reactionCache *utils.Cache = utils.NewLru(REACTION_CACHE_SIZE)
+var reactionCache utils.ObjectCache = utils.NewLru(REACTION_CACHE_SIZE)
+var topReactionsCache utils.ObjectCache = utils.NewLru(TOP_REACTIONS_CACHE_SIZE)
+var reactionAnalyticsCache utils.ObjectCache = utils.NewLru(REACTION_ANALYTICS_CACHE_SIZE)
 
 type SqlReactionStore struct {
 	*SqlStore
@@ -26,11 +39,18 @@ type SqlReactionStore struct {
 func NewSqlReactionStore(sqlStore *SqlStore) ReactionStore {
 	s := &SqlReactionStore{sqlStore}
 
+	reactionCache = utils.NewObjectCache("reactions", REACTION_CACHE_SIZE, []*model.Reaction{})
+	topReactionsCache = utils.NewObjectCache("top_reactions", TOP_REACTIONS_CACHE_SIZE, []*model.ReactionCount{})
+	reactionAnalyticsCache = utils.NewObjectCache("reaction_analytics", REACTION_ANALYTICS_CACHE_SIZE, model.AnalyticsRows{})
+
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.Reaction{}, "Reactions").SetKeys(false, "UserId", "PostId", "EmojiName")
 		table.ColMap("UserId").SetMaxSize(26)
 		table.ColMap("PostId").SetMaxSize(26)
 		table.ColMap("EmojiName").SetMaxSize(64)
+
+		countsTable := db.AddTableWithName(model.EmojiMessageCount{}, "EmojiMessageCounts").SetKeys(false, "EmojiName")
+		countsTable.ColMap("EmojiName").SetMaxSize(64)
 	}
 
 	return s
@@ -40,6 +60,7 @@ func (s SqlReactionStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_reactions_post_id", "Reactions", "PostId")
 	s.CreateIndexIfNotExists("idx_reactions_user_id", "Reactions", "UserId")
 	s.CreateIndexIfNotExists("idx_reactions_emoji_name", "Reactions", "EmojiName")
+	s.CreateIndexIfNotExists("idx_reactions_create_at", "Reactions", "CreateAt")
 }
 
 func (s SqlReactionStore) Save(reaction *model.Reaction) StoreChannel {
@@ -55,28 +76,70 @@ func (s SqlReactionStore) Save(reaction *model.Reaction) StoreChannel {
 			return
 		}
 
-		if transaction, err := s.GetMaster().Begin(); err != nil {
-			result.Err = model.NewLocAppError("SqlReactionStore.Save", "store.sql_reaction.save.begin.app_error", nil, err.Error())
+		if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+			saved, err := saveReactionAndUpdatePost(transaction, reaction, s.Capabilities())
+			if err == nil && !saved {
+				// Saving a reaction that already exists isn't an error, the existing reaction is returned instead
+				err = selectReaction(transaction, reaction)
+			}
+
+			return err
+		}); err != nil {
+			result.Err = model.NewLocAppError("SqlReactionStore.Save", "store.sql_reaction.save.save.app_error", nil, err.Error())
 		} else {
-			err := saveReactionAndUpdatePost(transaction, reaction)
+			result.Data = reaction
+		}
 
-			if err != nil {
-				transaction.Rollback()
+		storeChannel <- result
+		close(storeChannel)
+	}()
 
-				// We don't consider duplicated save calls as an error
-				if !IsUniqueConstraintError(err.Error(), []string{"reactions_pkey", "PRIMARY"}) {
-					result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_reaction.save.save.app_error", nil, err.Error())
-				}
-			} else {
-				if err := transaction.Commit(); err != nil {
-					// don't need to rollback here since the transaction is already closed
-					result.Err = model.NewLocAppError("SqlPreferenceStore.Save", "store.sql_reaction.save.commit.app_error", nil, err.Error())
+	return storeChannel
+}
+
+// SaveMultiple saves a batch of reactions in a single transaction, skipping the ones that already
+// exist, and returns the number of reactions that were inserted. It's meant for importers.
+func (s SqlReactionStore) SaveMultiple(reactions []*model.Reaction) StoreChannel {
+	storeChannel := make(StoreChannel)
+
+	go func() {
+		result := StoreResult{}
+
+		for _, reaction := range reactions {
+			reaction.PreSave()
+			if result.Err = reaction.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		inserted := 0
+		if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+			// The transaction may be retried, so only count the reactions inserted by the last attempt
+			inserted = 0
+			postIds := make(map[string]bool)
+
+			for _, reaction := range reactions {
+				if saved, err := insertReaction(transaction, reaction, s.Capabilities()); err != nil {
+					return err
+				} else if saved {
+					inserted++
+					postIds[reaction.PostId] = true
 				}
 			}
 
-			if result.Err == nil {
-				result.Data = reaction
+			for postId := range postIds {
+				if err := updatePostForReactions(transaction, postId); err != nil {
+					return err
+				}
 			}
+
+			return nil
+		}); err != nil {
+			result.Err = model.NewLocAppError("SqlReactionStore.SaveMultiple", "store.sql_reaction.save_multiple.app_error", nil, err.Error())
+		} else {
+			result.Data = inserted
 		}
 
 		storeChannel <- result
@@ -92,21 +155,12 @@ func (s SqlReactionStore) Delete(reaction *model.Reaction) StoreChannel {
 	go func() {
 		result := StoreResult{}
 
-		if transaction, err := s.GetMaster().Begin(); err != nil {
-			result.Err = model.NewLocAppError("SqlReactionStore.Delete", "store.sql_reaction.delete.begin.app_error", nil, err.Error())
+		if err := s.RunInTransaction(func(transaction *gorp.Transaction) error {
+			return deleteReactionAndUpdatePost(transaction, reaction)
+		}); err != nil {
+			result.Err = model.NewLocAppError("SqlReactionStore.Delete", "store.sql_reaction.delete.app_error", nil, err.Error())
 		} else {
-			err := deleteReactionAndUpdatePost(transaction, reaction)
-
-			if err != nil {
-				transaction.Rollback()
-
-				result.Err = model.NewLocAppError("SqlPreferenceStore.Delete", "store.sql_reaction.delete.app_error", nil, err.Error())
-			} else if err := transaction.Commit(); err != nil {
-				// don't need to rollback here since the transaction is already closed
-				result.Err = model.NewLocAppError("SqlPreferenceStore.Delete", "store.sql_reaction.delete.commit.app_error", nil, err.Error())
-			} else {
-				result.Data = reaction
-			}
+			result.Data = reaction
 		}
 
 		storeChannel <- result
@@ -116,12 +170,99 @@ func (s SqlReactionStore) Delete(reaction *model.Reaction) StoreChannel {
 	return storeChannel
 }
 
-func saveReactionAndUpdatePost(transaction *gorp.Transaction, reaction *model.Reaction) error {
-	if err := transaction.Insert(reaction); err != nil {
-		return err
+// saveReactionAndUpdatePost inserts a reaction unless the user has already reacted to the post with
+// the same emoji, in which case nothing is changed. It returns whether the reaction was inserted.
+func saveReactionAndUpdatePost(transaction *gorp.Transaction, reaction *model.Reaction, capabilities SqlCapabilities) (bool, error) {
+	if saved, err := insertReaction(transaction, reaction, capabilities); err != nil || !saved {
+		// The post's HasReactions is already set if the reaction already exists
+		return false, err
 	}
 
-	return updatePostForReactions(transaction, reaction.PostId)
+	return true, updatePostForReactions(transaction, reaction.PostId)
+}
+
+// insertReaction inserts a reaction, without updating its post, unless it already exists. It returns
+// whether the reaction was inserted.
+func insertReaction(transaction *gorp.Transaction, reaction *model.Reaction, capabilities SqlCapabilities) (bool, error) {
+	params := map[string]interface{}{"UserId": reaction.UserId, "PostId": reaction.PostId, "EmojiName": reaction.EmojiName, "CreateAt": reaction.CreateAt}
+
+	var query string
+	if capabilities.DriverName == model.DATABASE_DRIVER_MYSQL {
+		query = `INSERT IGNORE INTO
+				Reactions
+				(UserId, PostId, EmojiName, CreateAt, DeleteAt)
+			VALUES
+				(:UserId, :PostId, :EmojiName, :CreateAt, 0)`
+	} else if capabilities.Upsert {
+		query = `INSERT INTO
+				Reactions
+				(UserId, PostId, EmojiName, CreateAt, DeleteAt)
+			VALUES
+				(:UserId, :PostId, :EmojiName, :CreateAt, 0)
+			ON CONFLICT DO NOTHING`
+	} else {
+		// Postgres before 9.5 can't ignore conflicts, so only insert the reaction if it doesn't exist yet
+		query = `INSERT INTO
+				Reactions
+				(UserId, PostId, EmojiName, CreateAt, DeleteAt)
+			SELECT
+				:UserId, :PostId, :EmojiName, :CreateAt, 0
+			WHERE NOT EXISTS (
+				SELECT
+					1
+				FROM
+					Reactions
+				WHERE
+					UserId = :UserId
+					AND PostId = :PostId
+					AND EmojiName = :EmojiName
+			)`
+	}
+
+	sqlResult, err := transaction.Exec(query, params)
+	if err != nil {
+		return false, err
+	}
+
+	if rows, err := sqlResult.RowsAffected(); err != nil {
+		return false, err
+	} else if rows > 0 {
+		return true, nil
+	}
+
+	// The reaction may have been removed along with its post or emoji, in which case it's brought back
+	sqlResult, err = transaction.Exec(
+		`UPDATE
+			Reactions
+		SET
+			CreateAt = :CreateAt,
+			DeleteAt = 0
+		WHERE
+			UserId = :UserId
+			AND PostId = :PostId
+			AND EmojiName = :EmojiName
+			AND DeleteAt != 0`, params)
+	if err != nil {
+		return false, err
+	}
+
+	if rows, err := sqlResult.RowsAffected(); err != nil {
+		return false, err
+	} else {
+		return rows > 0, nil
+	}
+}
+
+func selectReaction(transaction *gorp.Transaction, reaction *model.Reaction) error {
+	return transaction.SelectOne(reaction,
+		`SELECT
+			*
+		FROM
+			Reactions
+		WHERE
+			UserId = :UserId
+			AND PostId = :PostId
+			AND EmojiName = :EmojiName`, map[string]interface{}{"UserId": reaction.UserId, "PostId": reaction.PostId, "EmojiName": reaction.EmojiName})
 }
 
 func deleteReactionAndUpdatePost(transaction *gorp.Transaction, reaction *model.Reaction) error {
@@ -145,10 +286,10 @@ const (
 			Posts
 		SET
 			UpdateAt = (CASE
-				WHEN HasReactions != (SELECT count(0) > 0 FROM Reactions WHERE PostId = :PostId) THEN :UpdateAt
+				WHEN HasReactions != (SELECT count(0) > 0 FROM Reactions WHERE PostId = :PostId AND DeleteAt = 0) THEN :UpdateAt
 				ELSE UpdateAt
 			END),
-			HasReactions = (SELECT count(0) > 0 FROM Reactions WHERE PostId = :PostId)
+			HasReactions = (SELECT count(0) > 0 FROM Reactions WHERE PostId = :PostId AND DeleteAt = 0)
 		WHERE
 			Id = :PostId`
 )
@@ -165,9 +306,16 @@ func (s SqlReactionStore) InvalidateCacheForPost(postId string) {
 
 func (s SqlReactionStore) InvalidateCache() {
 	reactionCache.Purge()
+	topReactionsCache.Purge()
+	reactionAnalyticsCache.Purge()
 }
 
 func (s SqlReactionStore) GetForPost(postId string, allowFromCache bool) StoreChannel {
+	return s.GetForPostContext(context.Background(), postId, allowFromCache)
+}
+
+// GetForPostContext is GetForPost with the query cancelled along with ctx.
+func (s SqlReactionStore) GetForPostContext(ctx context.Context, postId string, allowFromCache bool) StoreChannel {
 	storeChannel := make(StoreChannel)
 
 	go func() {
@@ -196,15 +344,18 @@ func (s SqlReactionStore) GetForPost(postId string, allowFromCache bool) StoreCh
 
 		var reactions []*model.Reaction
 
-		if _, err := s.GetReplica().Select(&reactions,
+		if err := s.selectContext(ctx, s.GetReplica(), &reactions,
 			`SELECT
 				*
 			FROM
 				Reactions
 			WHERE
 				PostId = :PostId
+				AND DeleteAt = 0
 			ORDER BY
-				CreateAt`, map[string]interface{}{"PostId": postId}); err != nil {
+				CreateAt`, map[string]interface{}{"PostId": postId}); ctx.Err() != nil {
+			result.Err = newContextAppError("SqlReactionStore.GetForPost", ctx)
+		} else if err != nil {
 			result.Err = model.NewLocAppError("SqlReactionStore.GetForPost", "store.sql_reaction.get_for_post.app_error", nil, "")
 		} else {
 			result.Data = reactions
@@ -219,6 +370,421 @@ func (s SqlReactionStore) GetForPost(postId string, allowFromCache bool) StoreCh
 	return storeChannel
 }
 
+// GetForPosts returns the reactions for each of the given posts keyed by post id. Posts found in the
+// cache are not queried and the cache is populated for every post that had to be loaded.
+func (s SqlReactionStore) GetForPosts(postIds []string) StoreChannel {
+	return s.GetForPostsContext(context.Background(), postIds)
+}
+
+// GetForPostsContext is GetForPosts with the query cancelled along with ctx.
+func (s SqlReactionStore) GetForPostsContext(ctx context.Context, postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+		metrics := einterfaces.GetMetricsInterface()
+
+		reactionsByPost := make(map[string][]*model.Reaction, len(postIds))
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for _, postId := range postIds {
+			if _, ok := reactionsByPost[postId]; ok {
+				continue
+			}
+
+			if cacheItem, ok := reactionCache.Get(postId); ok {
+				if metrics != nil {
+					metrics.IncrementMemCacheHitCounter("Reactions")
+				}
+				reactionsByPost[postId] = cacheItem.([]*model.Reaction)
+				continue
+			}
+
+			if metrics != nil {
+				metrics.IncrementMemCacheMissCounter("Reactions")
+			}
+
+			reactionsByPost[postId] = []*model.Reaction{}
+
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			key := "postId" + strconv.Itoa(len(props))
+			props[key] = postId
+			idQuery += ":" + key
+		}
+
+		if len(props) > 0 {
+			var reactions []*model.Reaction
+
+			if err := s.selectContext(ctx, s.GetReplica(), &reactions,
+				`SELECT
+					*
+				FROM
+					Reactions
+				WHERE
+					PostId IN (`+idQuery+`)
+					AND DeleteAt = 0
+				ORDER BY
+					CreateAt`, props); err != nil {
+				if ctx.Err() != nil {
+					result.Err = newContextAppError("SqlReactionStore.GetForPosts", ctx)
+				} else {
+					result.Err = model.NewLocAppError("SqlReactionStore.GetForPosts", "store.sql_reaction.get_for_posts.app_error", nil, err.Error())
+				}
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			for _, reaction := range reactions {
+				reactionsByPost[reaction.PostId] = append(reactionsByPost[reaction.PostId], reaction)
+			}
+
+			for _, postId := range props {
+				reactionCache.AddWithExpiresInSecs(postId.(string), reactionsByPost[postId.(string)], REACTION_CACHE_SEC)
+			}
+		}
+
+		result.Data = reactionsByPost
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetTopReactionsForTeam returns the emoji most used as reactions to posts in the team's channels
+// since the given time. Results are cached for a few minutes since they are only used for analytics.
+func (s SqlReactionStore) GetTopReactionsForTeam(teamId string, since int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+		metrics := einterfaces.GetMetricsInterface()
+
+		key := fmt.Sprintf("%v:%v:%v", teamId, since, limit)
+
+		if cacheItem, ok := topReactionsCache.Get(key); ok {
+			if metrics != nil {
+				metrics.IncrementMemCacheHitCounter("Top Reactions")
+			}
+			result.Data = cacheItem.([]*model.ReactionCount)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if metrics != nil {
+			metrics.IncrementMemCacheMissCounter("Top Reactions")
+		}
+
+		var counts []*model.ReactionCount
+
+		if _, err := s.GetReplica().Select(&counts,
+			`SELECT
+				Reactions.EmojiName AS EmojiName,
+				COUNT(*) AS Count
+			FROM
+				Reactions, Posts, Channels
+			WHERE
+				Reactions.PostId = Posts.Id
+				AND Posts.ChannelId = Channels.Id
+				AND Channels.TeamId = :TeamId
+				AND Posts.DeleteAt = 0
+				AND Reactions.DeleteAt = 0
+				AND Reactions.CreateAt >= :Since
+			GROUP BY
+				Reactions.EmojiName
+			ORDER BY
+				Count DESC, EmojiName
+			LIMIT :Limit`, map[string]interface{}{"TeamId": teamId, "Since": since, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlReactionStore.GetTopReactionsForTeam", "store.sql_reaction.get_top_reactions_for_team.app_error", nil, "team_id="+teamId+", err="+err.Error())
+		} else {
+			if counts == nil {
+				counts = []*model.ReactionCount{}
+			}
+
+			result.Data = counts
+
+			topReactionsCache.AddWithExpiresInSecs(key, counts, TOP_REACTIONS_CACHE_SEC)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// AnalyticsReactionCountsByDay returns the number of reactions made on each of the last 30 days before
+// today, most recent first. Results are cached for a few minutes like the top reactions.
+func (s SqlReactionStore) AnalyticsReactionCountsByDay(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		key := "counts_by_day:" + teamId
+		if rows, ok := getCachedReactionAnalytics(key); ok {
+			result.Data = rows
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		day := "DATE(FROM_UNIXTIME(Reactions.CreateAt / 1000))"
+		if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+			day = "TO_CHAR(DATE(TO_TIMESTAMP(Reactions.CreateAt / 1000)), 'YYYY-MM-DD')"
+		}
+
+		query := `SELECT ` + day + ` AS Name, COUNT(*) AS Value FROM Reactions`
+
+		if len(teamId) > 0 {
+			query += `
+				INNER JOIN Posts ON Reactions.PostId = Posts.Id
+				INNER JOIN Channels ON Posts.ChannelId = Channels.Id AND Channels.TeamId = :TeamId`
+		}
+
+		query += `
+			WHERE
+				Reactions.DeleteAt = 0
+				AND Reactions.CreateAt <= :EndTime
+				AND Reactions.CreateAt >= :StartTime
+			GROUP BY ` + day + `
+			ORDER BY Name DESC
+			LIMIT 30`
+
+		end := utils.MillisFromTime(utils.EndOfDay(utils.Yesterday()))
+		start := utils.MillisFromTime(utils.StartOfDay(utils.Yesterday().AddDate(0, 0, -31)))
+
+		var rows model.AnalyticsRows
+		if _, err := s.GetReplica().Select(&rows, query, map[string]interface{}{"TeamId": teamId, "StartTime": start, "EndTime": end}); err != nil {
+			result.Err = model.NewAppError("SqlReactionStore.AnalyticsReactionCountsByDay", "store.sql_reaction.analytics_reaction_counts_by_day.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			if rows == nil {
+				rows = model.AnalyticsRows{}
+			}
+
+			result.Data = rows
+			reactionAnalyticsCache.AddWithExpiresInSecs(key, rows, REACTION_ANALYTICS_CACHE_SEC)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// AnalyticsReactionCounts returns the number of reactions that haven't been removed as reaction_count
+// and the number of posts that have reactions as post_with_reactions_count. Results are cached for a
+// few minutes like the top reactions.
+func (s SqlReactionStore) AnalyticsReactionCounts(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		key := "counts:" + teamId
+		if rows, ok := getCachedReactionAnalytics(key); ok {
+			result.Data = rows
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		reactionQuery := "SELECT COUNT(*) FROM Reactions"
+		postQuery := "SELECT COUNT(Posts.Id) FROM Posts"
+
+		if len(teamId) > 0 {
+			reactionQuery += `
+				INNER JOIN Posts ON Reactions.PostId = Posts.Id
+				INNER JOIN Channels ON Posts.ChannelId = Channels.Id AND Channels.TeamId = :TeamId`
+			postQuery += " INNER JOIN Channels ON Posts.ChannelId = Channels.Id AND Channels.TeamId = :TeamId"
+		}
+
+		reactionQuery += " WHERE Reactions.DeleteAt = 0"
+		postQuery += " WHERE Posts.HasReactions = :HasReactions"
+
+		params := map[string]interface{}{"TeamId": teamId, "HasReactions": true}
+
+		if reactionCount, err := s.GetReplica().SelectInt(reactionQuery, params); err != nil {
+			result.Err = model.NewAppError("SqlReactionStore.AnalyticsReactionCounts", "store.sql_reaction.analytics_reaction_counts.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else if postCount, err := s.GetReplica().SelectInt(postQuery, params); err != nil {
+			result.Err = model.NewAppError("SqlReactionStore.AnalyticsReactionCounts", "store.sql_reaction.analytics_reaction_counts.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			rows := model.AnalyticsRows{
+				{Name: "reaction_count", Value: float64(reactionCount)},
+				{Name: "post_with_reactions_count", Value: float64(postCount)},
+			}
+
+			result.Data = rows
+			reactionAnalyticsCache.AddWithExpiresInSecs(key, rows, REACTION_ANALYTICS_CACHE_SEC)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func getCachedReactionAnalytics(key string) (model.AnalyticsRows, bool) {
+	metrics := einterfaces.GetMetricsInterface()
+
+	if cacheItem, ok := reactionAnalyticsCache.Get(key); ok {
+		if metrics != nil {
+			metrics.IncrementMemCacheHitCounter("Reaction Analytics")
+		}
+		return cacheItem.(model.AnalyticsRows), true
+	}
+
+	if metrics != nil {
+		metrics.IncrementMemCacheMissCounter("Reaction Analytics")
+	}
+
+	return nil, false
+}
+
+// IncrementEmojiMessageCounts records that a message used each of the given emoji in its text.
+func (s SqlReactionStore) IncrementEmojiMessageCounts(emojiNames []string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		query := `UPDATE
+				EmojiMessageCounts
+			SET
+				Count = Count + 1,
+				LastUsedAt = :LastUsedAt
+			WHERE
+				EmojiName = :EmojiName`
+
+		for _, emojiName := range emojiNames {
+			params := map[string]interface{}{"EmojiName": emojiName, "LastUsedAt": time}
+
+			if sqlResult, err := s.GetMaster().Exec(query, params); err != nil {
+				result.Err = model.NewAppError("SqlReactionStore.IncrementEmojiMessageCounts", "store.sql_reaction.increment_emoji_message_counts.app_error", nil, "emoji_name="+emojiName+", "+err.Error(), http.StatusInternalServerError)
+				break
+			} else if rows, _ := sqlResult.RowsAffected(); rows == 0 {
+				count := &model.EmojiMessageCount{EmojiName: emojiName, Count: 1, LastUsedAt: time}
+
+				if err := s.GetMaster().Insert(count); err != nil {
+					// Another message may have used the emoji for the first time at the same time
+					if _, err := s.GetMaster().Exec(query, params); err != nil {
+						result.Err = model.NewAppError("SqlReactionStore.IncrementEmojiMessageCounts", "store.sql_reaction.increment_emoji_message_counts.app_error", nil, "emoji_name="+emojiName+", "+err.Error(), http.StatusInternalServerError)
+						break
+					}
+				}
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetEmojiStats returns how often each of the given emoji has been used in reactions to posts that haven't been
+// deleted and in message text. The stats are returned in the same order as the names, including for unused emoji.
+func (s SqlReactionStore) GetEmojiStats(emojiNames []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		stats := []*model.EmojiStats{}
+		if len(emojiNames) == 0 {
+			result.Data = stats
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, emojiName := range emojiNames {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["EmojiName"+strconv.Itoa(index)] = emojiName
+			idQuery += ":EmojiName" + strconv.Itoa(index)
+		}
+
+		var reactionStats []*model.EmojiStats
+		if _, err := s.GetReplica().Select(&reactionStats,
+			`SELECT
+				Reactions.EmojiName AS EmojiName,
+				COUNT(*) AS ReactionCount,
+				COUNT(DISTINCT Reactions.UserId) AS ReactionUserCount,
+				MAX(Reactions.CreateAt) AS LastUsedAt
+			FROM
+				Reactions
+				INNER JOIN Posts ON Posts.Id = Reactions.PostId
+			WHERE
+				Reactions.EmojiName IN (`+idQuery+`)
+				AND Reactions.DeleteAt = 0
+				AND Posts.DeleteAt = 0
+			GROUP BY
+				Reactions.EmojiName`, props); err != nil {
+			result.Err = model.NewAppError("SqlReactionStore.GetEmojiStats", "store.sql_reaction.get_emoji_stats.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		var messageCounts []*model.EmojiMessageCount
+		if _, err := s.GetReplica().Select(&messageCounts, "SELECT * FROM EmojiMessageCounts WHERE EmojiName IN ("+idQuery+")", props); err != nil {
+			result.Err = model.NewAppError("SqlReactionStore.GetEmojiStats", "store.sql_reaction.get_emoji_stats.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		statsByName := make(map[string]*model.EmojiStats)
+		for _, emojiName := range emojiNames {
+			if _, ok := statsByName[emojiName]; !ok {
+				statsByName[emojiName] = &model.EmojiStats{EmojiName: emojiName}
+				stats = append(stats, statsByName[emojiName])
+			}
+		}
+
+		for _, reactionStat := range reactionStats {
+			if stat, ok := statsByName[reactionStat.EmojiName]; ok {
+				stat.ReactionCount = reactionStat.ReactionCount
+				stat.ReactionUserCount = reactionStat.ReactionUserCount
+				stat.LastUsedAt = reactionStat.LastUsedAt
+			}
+		}
+
+		for _, messageCount := range messageCounts {
+			if stat, ok := statsByName[messageCount.EmojiName]; ok {
+				stat.MessageCount = messageCount.Count
+				if messageCount.LastUsedAt > stat.LastUsedAt {
+					stat.LastUsedAt = messageCount.LastUsedAt
+				}
+			}
+		}
+
+		result.Data = stats
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// DeleteAllWithEmojiName removes every reaction made with an emoji. The reactions are kept with their
+// DeleteAt set so that they can be brought back.
 func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel {
 	storeChannel := make(StoreChannel)
 
@@ -234,7 +800,8 @@ func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel
 			FROM
 				Reactions
 			WHERE
-				EmojiName = :EmojiName`, map[string]interface{}{"EmojiName": emojiName}); err != nil {
+				EmojiName = :EmojiName
+				AND DeleteAt = 0`, map[string]interface{}{"EmojiName": emojiName}); err != nil {
 			result.Err = model.NewLocAppError("SqlReactionStore.DeleteAllWithEmojiName",
 				"store.sql_reaction.delete_all_with_emoji_name.get_reactions.app_error", nil,
 				"emoji_name="+emojiName+", error="+err.Error())
@@ -244,10 +811,13 @@ func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel
 		}
 
 		if _, err := s.GetMaster().Exec(
-			`DELETE FROM
+			`UPDATE
 				Reactions
+			SET
+				DeleteAt = :DeleteAt
 			WHERE
-				EmojiName = :EmojiName`, map[string]interface{}{"EmojiName": emojiName}); err != nil {
+				EmojiName = :EmojiName
+				AND DeleteAt = 0`, map[string]interface{}{"EmojiName": emojiName, "DeleteAt": model.GetMillis()}); err != nil {
 			result.Err = model.NewLocAppError("SqlReactionStore.DeleteAllWithEmojiName",
 				"store.sql_reaction.delete_all_with_emoji_name.delete_reactions.app_error", nil,
 				"emoji_name="+emojiName+", error="+err.Error())
@@ -256,6 +826,10 @@ func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel
 			return
 		}
 
+		if _, err := s.GetMaster().Exec("DELETE FROM EmojiMessageCounts WHERE EmojiName = :EmojiName", map[string]interface{}{"EmojiName": emojiName}); err != nil {
+			l4g.Warn(utils.T("store.sql_reaction.delete_all_with_emoji_name.delete_message_counts.warn"), emojiName, err.Error())
+		}
+
 		for _, reaction := range reactions {
 			if _, err := s.GetMaster().Exec(UPDATE_POST_HAS_REACTIONS_QUERY,
 				map[string]interface{}{"PostId": reaction.PostId, "UpdateAt": model.GetMillis()}); err != nil {
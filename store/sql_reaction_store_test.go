@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 func TestReactionSave(t *testing.T) {
@@ -39,9 +40,21 @@ func TestReactionSave(t *testing.T) {
 		secondUpdateAt = postList.Posts[post.Id].UpdateAt
 	}
 
-	if result := <-store.Reaction().Save(reaction1); result.Err != nil {
+	duplicate := &model.Reaction{
+		UserId:    reaction1.UserId,
+		PostId:    reaction1.PostId,
+		EmojiName: reaction1.EmojiName,
+		CreateAt:  reaction1.CreateAt + 1000,
+	}
+	if result := <-store.Reaction().Save(duplicate); result.Err != nil {
 		t.Log(result.Err)
 		t.Fatal("should've allowed saving a duplicate reaction")
+	} else if saved := result.Data.(*model.Reaction); saved.CreateAt != reaction1.CreateAt {
+		t.Fatal("should've returned the existing reaction")
+	}
+
+	if postList := Must(store.Post().Get(reaction1.PostId)).(*model.PostList); postList.Posts[post.Id].UpdateAt != secondUpdateAt {
+		t.Fatal("shouldn't mark as updated when saving a duplicate reaction")
 	}
 
 	// different user
@@ -88,6 +101,77 @@ func TestReactionSave(t *testing.T) {
 	}
 }
 
+func TestReactionSaveMultiple(t *testing.T) {
+	Setup()
+
+	post1 := Must(store.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+	})).(*model.Post)
+	post2 := Must(store.Post().Save(&model.Post{
+		ChannelId: post1.ChannelId,
+		UserId:    model.NewId(),
+	})).(*model.Post)
+
+	existing := Must(store.Reaction().Save(&model.Reaction{
+		UserId:    model.NewId(),
+		PostId:    post1.Id,
+		EmojiName: model.NewId(),
+	})).(*model.Reaction)
+
+	reactions := []*model.Reaction{
+		{
+			UserId:    existing.UserId,
+			PostId:    existing.PostId,
+			EmojiName: existing.EmojiName,
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    post1.Id,
+			EmojiName: existing.EmojiName,
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    post2.Id,
+			EmojiName: model.NewId(),
+			CreateAt:  1000,
+		},
+	}
+
+	if result := <-store.Reaction().SaveMultiple(reactions); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if inserted := result.Data.(int); inserted != 2 {
+		t.Fatal("should've skipped the existing reaction", inserted)
+	}
+
+	if saved := Must(store.Reaction().GetForPost(post1.Id, false)).([]*model.Reaction); len(saved) != 2 {
+		t.Fatal("should've saved the reaction to the first post", saved)
+	}
+
+	if saved := Must(store.Reaction().GetForPost(post2.Id, false)).([]*model.Reaction); len(saved) != 1 || saved[0].CreateAt != 1000 {
+		t.Fatal("should've saved the reaction to the second post", saved)
+	} else if postList := Must(store.Post().Get(post2.Id)).(*model.PostList); !postList.Posts[post2.Id].HasReactions {
+		t.Fatal("should've set HasReactions = true on post")
+	}
+
+	invalid := []*model.Reaction{
+		{
+			UserId:    model.NewId(),
+			PostId:    post2.Id,
+			EmojiName: model.NewId(),
+		},
+		{
+			UserId: model.NewId(),
+			PostId: post2.Id,
+		},
+	}
+	if result := <-store.Reaction().SaveMultiple(invalid); result.Err == nil {
+		t.Fatal("should've failed to save an invalid reaction")
+	} else if saved := Must(store.Reaction().GetForPost(post2.Id, false)).([]*model.Reaction); len(saved) != 1 {
+		t.Fatal("shouldn't have saved any of the reactions", saved)
+	}
+}
+
 func TestReactionDelete(t *testing.T) {
 	Setup()
 
@@ -206,6 +290,160 @@ func TestReactionGetForPost(t *testing.T) {
 	}
 }
 
+func TestReactionGetForPosts(t *testing.T) {
+	Setup()
+
+	postId1 := model.NewId()
+	postId2 := model.NewId()
+	postId3 := model.NewId()
+
+	reactions := []*model.Reaction{
+		{
+			UserId:    model.NewId(),
+			PostId:    postId1,
+			EmojiName: "smile",
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    postId1,
+			EmojiName: "smile",
+		},
+		{
+			UserId:    model.NewId(),
+			PostId:    postId2,
+			EmojiName: "sad",
+		},
+	}
+
+	for _, reaction := range reactions {
+		Must(store.Reaction().Save(reaction))
+	}
+
+	// populate the cache for one of the posts to make sure cached and loaded posts are combined
+	Must(store.Reaction().GetForPost(postId1, true))
+
+	if result := <-store.Reaction().GetForPosts([]string{postId1, postId2, postId3, postId2}); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if returned := result.Data.(map[string][]*model.Reaction); len(returned) != 3 {
+		t.Fatal("should've returned an entry for every post")
+	} else if len(returned[postId1]) != 2 || len(returned[postId2]) != 1 || len(returned[postId3]) != 0 {
+		t.Fatal("should've returned the reactions for each post")
+	}
+
+	if cached, ok := reactionCache.Get(postId3); !ok || len(cached.([]*model.Reaction)) != 0 {
+		t.Fatal("should've cached the post without reactions")
+	}
+
+	store.Reaction().InvalidateCacheForPost(postId1)
+	store.Reaction().InvalidateCacheForPost(postId2)
+	store.Reaction().InvalidateCacheForPost(postId3)
+}
+
+func TestReactionGetTopReactionsForTeam(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	channel := Must(store.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Name",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	post1 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId()})).(*model.Post)
+	post2 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId()})).(*model.Post)
+	otherPost := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId()})).(*model.Post)
+
+	reactions := []*model.Reaction{
+		{UserId: model.NewId(), PostId: post1.Id, EmojiName: "smile"},
+		{UserId: model.NewId(), PostId: post1.Id, EmojiName: "smile"},
+		{UserId: model.NewId(), PostId: post2.Id, EmojiName: "smile"},
+		{UserId: model.NewId(), PostId: post2.Id, EmojiName: "sad", CreateAt: 1000},
+		{UserId: model.NewId(), PostId: otherPost.Id, EmojiName: "sad"},
+	}
+
+	for _, reaction := range reactions {
+		Must(store.Reaction().Save(reaction))
+	}
+
+	if result := <-store.Reaction().GetTopReactionsForTeam(teamId, 0, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if counts := result.Data.([]*model.ReactionCount); len(counts) != 2 {
+		t.Fatal("should've returned the emoji used in the team")
+	} else if counts[0].EmojiName != "smile" || counts[0].Count != 3 || counts[1].EmojiName != "sad" || counts[1].Count != 1 {
+		t.Fatal("should've counted the reactions in the team's channels")
+	}
+
+	if result := <-store.Reaction().GetTopReactionsForTeam(teamId, 2000, 10); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if counts := result.Data.([]*model.ReactionCount); len(counts) != 1 || counts[0].EmojiName != "smile" {
+		t.Fatal("shouldn't have counted older reactions")
+	}
+
+	if result := <-store.Reaction().GetTopReactionsForTeam(teamId, 0, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if counts := result.Data.([]*model.ReactionCount); len(counts) != 1 {
+		t.Fatal("should've applied the limit")
+	}
+}
+
+func TestReactionAnalytics(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	channel := Must(store.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Name",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	post1 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId()})).(*model.Post)
+	post2 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId()})).(*model.Post)
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId()}))
+
+	yesterday := utils.MillisFromTime(utils.Yesterday())
+	twoDaysAgo := utils.MillisFromTime(utils.Yesterday().AddDate(0, 0, -1))
+
+	reactions := []*model.Reaction{
+		{UserId: model.NewId(), PostId: post1.Id, EmojiName: "smile", CreateAt: yesterday},
+		{UserId: model.NewId(), PostId: post1.Id, EmojiName: "sad", CreateAt: yesterday},
+		{UserId: model.NewId(), PostId: post2.Id, EmojiName: "smile", CreateAt: twoDaysAgo},
+	}
+
+	for _, reaction := range reactions {
+		Must(store.Reaction().Save(reaction))
+	}
+
+	if result := <-store.Reaction().AnalyticsReactionCountsByDay(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rows := result.Data.(model.AnalyticsRows); len(rows) != 2 {
+		t.Fatal("should've returned a row for each day with reactions", rows)
+	} else if rows[0].Value != 2 || rows[1].Value != 1 {
+		t.Fatal("should've counted the reactions on each day, most recent first")
+	}
+
+	if result := <-store.Reaction().AnalyticsReactionCounts(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rows := result.Data.(model.AnalyticsRows); rows[0].Value != 3 || rows[1].Value != 2 {
+		t.Fatal("should've counted the reactions and the posts with reactions", rows[0], rows[1])
+	}
+
+	Must(store.Reaction().Save(&model.Reaction{UserId: model.NewId(), PostId: post2.Id, EmojiName: "smile", CreateAt: yesterday}))
+
+	if rows := Must(store.Reaction().AnalyticsReactionCounts(teamId)).(model.AnalyticsRows); rows[0].Value != 3 {
+		t.Fatal("should've returned the cached counts")
+	}
+
+	store.Reaction().InvalidateCache()
+
+	if rows := Must(store.Reaction().AnalyticsReactionCounts(teamId)).(model.AnalyticsRows); rows[0].Value != 4 {
+		t.Fatal("should've counted the new reaction once the cache was cleared")
+	}
+}
+
 func TestReactionDeleteAllWithEmojiName(t *testing.T) {
 	Setup()
 
@@ -293,4 +531,58 @@ func TestReactionDeleteAllWithEmojiName(t *testing.T) {
 	if postList := Must(store.Post().Get(post3.Id)).(*model.PostList); postList.Posts[post3.Id].HasReactions {
 		t.Fatal("post shouldn't have reactions any more")
 	}
+
+	// reacting again with the emoji brings back the removed reaction
+	Must(store.Reaction().Save(&model.Reaction{UserId: userId, PostId: post3.Id, EmojiName: emojiToDelete}))
+
+	if returned := Must(store.Reaction().GetForPost(post3.Id, false)).([]*model.Reaction); len(returned) != 1 {
+		t.Fatal("should've brought back the reaction")
+	}
+
+	if postList := Must(store.Post().Get(post3.Id)).(*model.PostList); !postList.Posts[post3.Id].HasReactions {
+		t.Fatal("post should have reactions again")
+	}
+}
+
+func TestReactionGetEmojiStats(t *testing.T) {
+	Setup()
+
+	emojiName := model.NewId()
+	unusedName := model.NewId()
+
+	post := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId()})).(*model.Post)
+	userId := model.NewId()
+
+	Must(store.Reaction().Save(&model.Reaction{UserId: userId, PostId: post.Id, EmojiName: emojiName}))
+	Must(store.Reaction().Save(&model.Reaction{UserId: model.NewId(), PostId: post.Id, EmojiName: emojiName}))
+
+	otherPost := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId()})).(*model.Post)
+	Must(store.Reaction().Save(&model.Reaction{UserId: userId, PostId: otherPost.Id, EmojiName: emojiName}))
+
+	// Reactions to deleted posts aren't counted
+	deletedPost := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId()})).(*model.Post)
+	Must(store.Reaction().Save(&model.Reaction{UserId: userId, PostId: deletedPost.Id, EmojiName: emojiName}))
+	Must(store.Post().Delete(deletedPost.Id, model.GetMillis()))
+
+	Must(store.Reaction().IncrementEmojiMessageCounts([]string{emojiName}, 1000))
+	Must(store.Reaction().IncrementEmojiMessageCounts([]string{emojiName}, 2000))
+
+	stats := Must(store.Reaction().GetEmojiStats([]string{unusedName, emojiName})).([]*model.EmojiStats)
+	if len(stats) != 2 {
+		t.Fatal("should have returned stats for each emoji", len(stats))
+	}
+
+	if stats[0].EmojiName != unusedName || stats[0].ReactionCount != 0 || stats[0].MessageCount != 0 {
+		t.Fatal("unused emoji should have no usage", stats[0])
+	}
+
+	if stats[1].EmojiName != emojiName || stats[1].ReactionCount != 3 || stats[1].ReactionUserCount != 2 || stats[1].MessageCount != 2 {
+		t.Fatal("should have counted the emoji's usage", stats[1])
+	}
+
+	Must(store.Reaction().DeleteAllWithEmojiName(emojiName))
+
+	if stats := Must(store.Reaction().GetEmojiStats([]string{emojiName})).([]*model.EmojiStats); stats[0].ReactionCount != 0 || stats[0].MessageCount != 0 {
+		t.Fatal("should have removed the emoji's usage", stats[0])
+	}
 }
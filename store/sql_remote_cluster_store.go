@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlRemoteClusterStore struct {
+	*SqlStore
+}
+
+func NewSqlRemoteClusterStore(sqlStore *SqlStore) RemoteClusterStore {
+	s := &SqlRemoteClusterStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.RemoteCluster{}, "RemoteClusters").SetKeys(false, "RemoteId")
+		table.ColMap("RemoteId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(64)
+		table.ColMap("DisplayName").SetMaxSize(256)
+		table.ColMap("SiteURL").SetMaxSize(512)
+		table.ColMap("Token").SetMaxSize(26)
+		table.ColMap("RemoteRecordId").SetMaxSize(26)
+		table.ColMap("RemoteToken").SetMaxSize(26)
+		table.ColMap("DefaultTeamId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlRemoteClusterStore) CreateIndexesIfNotExists() {
+	s.CreateUniqueIndexIfNotExists("idx_remote_clusters_name", "RemoteClusters", "Name")
+}
+
+func (s SqlRemoteClusterStore) Save(rc *model.RemoteCluster) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		rc.PreSave()
+		if result.Err = rc.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(rc); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"Name", "idx_remote_clusters_name"}) {
+				result.Err = model.NewAppError("SqlRemoteClusterStore.Save", "store.sql_remote_cluster.save.name_exists.app_error", nil, "name="+rc.Name+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlRemoteClusterStore.Save", "store.sql_remote_cluster.save.app_error", nil, "remote_id="+rc.RemoteId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = rc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRemoteClusterStore) Update(rc *model.RemoteCluster) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = rc.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if _, err := s.GetMaster().Update(rc); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.Update", "store.sql_remote_cluster.update.app_error", nil, "remote_id="+rc.RemoteId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRemoteClusterStore) Get(remoteId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var rc model.RemoteCluster
+		if err := s.GetReplica().SelectOne(&rc, "SELECT * FROM RemoteClusters WHERE RemoteId = :RemoteId", map[string]interface{}{"RemoteId": remoteId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlRemoteClusterStore.Get", "store.sql_remote_cluster.get.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlRemoteClusterStore.Get", "store.sql_remote_cluster.get.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &rc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRemoteClusterStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var remotes []*model.RemoteCluster
+		if _, err := s.GetReplica().Select(&remotes, "SELECT * FROM RemoteClusters ORDER BY Name"); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.GetAll", "store.sql_remote_cluster.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = remotes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateLastPingAt records that the remote was heard from.
+func (s SqlRemoteClusterStore) UpdateLastPingAt(remoteId string, pingAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE RemoteClusters SET LastPingAt = :LastPingAt WHERE RemoteId = :RemoteId", map[string]interface{}{"LastPingAt": pingAt, "RemoteId": remoteId}); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.UpdateLastPingAt", "store.sql_remote_cluster.update.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes a remote along with every channel that is shared with it.
+func (s SqlRemoteClusterStore) Delete(remoteId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM SharedChannelRemotes WHERE RemoteId = :RemoteId", map[string]interface{}{"RemoteId": remoteId}); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.Delete", "store.sql_remote_cluster.delete.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM SharedChannels WHERE RemoteId = :RemoteId", map[string]interface{}{"RemoteId": remoteId}); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.Delete", "store.sql_remote_cluster.delete.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM RemoteClusters WHERE RemoteId = :RemoteId", map[string]interface{}{"RemoteId": remoteId}); err != nil {
+			result.Err = model.NewAppError("SqlRemoteClusterStore.Delete", "store.sql_remote_cluster.delete.app_error", nil, "remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestRemoteClusterStore(t *testing.T) {
+	Setup()
+
+	rc := &model.RemoteCluster{Name: "r" + model.NewId()[:10], CreatorId: model.NewId()}
+	rc = Must(store.RemoteCluster().Save(rc)).(*model.RemoteCluster)
+
+	if result := <-store.RemoteCluster().Save(&model.RemoteCluster{Name: rc.Name, CreatorId: model.NewId()}); result.Err == nil {
+		t.Fatal("shouldn't save a remote with the same name")
+	}
+
+	rc.SiteURL = "https://example.com"
+	rc.RemoteRecordId = model.NewId()
+	rc.RemoteToken = model.NewId()
+	Must(store.RemoteCluster().Update(rc))
+
+	if result := <-store.RemoteCluster().Get(rc.RemoteId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if rrc := result.Data.(*model.RemoteCluster); !rrc.IsConfirmed() || rrc.Token != rc.Token {
+		t.Fatal("the remote wasn't updated")
+	}
+
+	Must(store.RemoteCluster().UpdateLastPingAt(rc.RemoteId, 1234))
+
+	if rrc := Must(store.RemoteCluster().Get(rc.RemoteId)).(*model.RemoteCluster); rrc.LastPingAt != 1234 {
+		t.Fatal("the ping time wasn't updated")
+	}
+
+	found := false
+	for _, rrc := range Must(store.RemoteCluster().GetAll()).([]*model.RemoteCluster) {
+		if rrc.RemoteId == rc.RemoteId {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("the remote should have been listed")
+	}
+
+	Must(store.RemoteCluster().Delete(rc.RemoteId))
+
+	if result := <-store.RemoteCluster().Get(rc.RemoteId); result.Err == nil {
+		t.Fatal("the remote should have been deleted")
+	}
+}
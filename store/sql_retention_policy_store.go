@@ -0,0 +1,355 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlRetentionPolicyStore struct {
+	*SqlStore
+}
+
+func NewSqlRetentionPolicyStore(sqlStore *SqlStore) RetentionPolicyStore {
+	s := &SqlRetentionPolicyStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.RetentionPolicy{}, "RetentionPolicies").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.SetUniqueTogether("TeamId", "ChannelId")
+	}
+
+	return s
+}
+
+func (s SqlRetentionPolicyStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_retentionpolicies_team_id", "RetentionPolicies", "TeamId")
+	s.CreateIndexIfNotExists("idx_retentionpolicies_channel_id", "RetentionPolicies", "ChannelId")
+}
+
+func (s SqlRetentionPolicyStore) Save(policy *model.RetentionPolicy) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(policy.Id) > 0 {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.Save", "store.sql_retention_policy.save.existing.app_error", nil, "id="+policy.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		policy.PreSave()
+		if result.Err = policy.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(policy); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"TeamId", "retentionpolicies_teamid_channelid_key"}) {
+				result.Err = model.NewAppError("SqlRetentionPolicyStore.Save", "store.sql_retention_policy.save.exists.app_error", nil, "team_id="+policy.TeamId+", channel_id="+policy.ChannelId, http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlRetentionPolicyStore.Save", "store.sql_retention_policy.save.app_error", nil, "id="+policy.Id+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = policy
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) Update(policy *model.RetentionPolicy) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		policy.PreUpdate()
+		if result.Err = policy.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(policy); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.Update", "store.sql_retention_policy.update.app_error", nil, "id="+policy.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count == 0 {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.Update", "store.sql_retention_policy.get.app_error", nil, "id="+policy.Id, http.StatusNotFound)
+		} else {
+			result.Data = policy
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var policy model.RetentionPolicy
+
+		if err := s.GetReplica().SelectOne(&policy, "SELECT * FROM RetentionPolicies WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlRetentionPolicyStore.Get", "store.sql_retention_policy.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlRetentionPolicyStore.Get", "store.sql_retention_policy.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &policy
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns a page of the retention policies, with the team policies first.
+func (s SqlRetentionPolicyStore) GetAll(offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var policies []*model.RetentionPolicy
+
+		if _, err := s.GetReplica().Select(&policies,
+			`SELECT
+				*
+			FROM
+				RetentionPolicies
+			ORDER BY
+				ChannelId, TeamId, Id
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetAll", "store.sql_retention_policy.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = policies
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) GetCount() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(*) FROM RetentionPolicies"); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetCount", "store.sql_retention_policy.get_count.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM RetentionPolicies WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.Delete", "store.sql_retention_policy.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.Delete", "store.sql_retention_policy.get.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// retentionScopeQuery returns the conditions on Posts and Channels for the channels that a policy
+// applies to. A channel policy applies to its channel, a team policy applies to the channels of its
+// team that don't have a policy of their own, and the global settings, when policy is nil, apply to
+// the channels that aren't covered by any policy. Posts that are under a legal hold are never in scope.
+func retentionScopeQuery(policy *model.RetentionPolicy, props map[string]interface{}) string {
+	channelPolicies := "Posts.ChannelId NOT IN (SELECT ChannelId FROM RetentionPolicies WHERE ChannelId != '')"
+
+	if policy == nil {
+		return channelPolicies + " AND Channels.TeamId NOT IN (SELECT TeamId FROM RetentionPolicies WHERE TeamId != '') AND " + LEGAL_HOLD_NOT_HELD_POST_QUERY
+	}
+
+	if len(policy.ChannelId) > 0 {
+		props["ChannelId"] = policy.ChannelId
+		return "Posts.ChannelId = :ChannelId AND " + LEGAL_HOLD_NOT_HELD_POST_QUERY
+	}
+
+	props["TeamId"] = policy.TeamId
+	return "Channels.TeamId = :TeamId AND " + channelPolicies + " AND " + LEGAL_HOLD_NOT_HELD_POST_QUERY
+}
+
+// GetExpiredPostIds returns the ids of up to limit posts created before endTime in the channels that
+// a policy applies to, or that the global settings apply to if policy is nil, oldest first.
+func (s SqlRetentionPolicyStore) GetExpiredPostIds(policy *model.RetentionPolicy, endTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime, "Limit": limit}
+		scope := retentionScopeQuery(policy, props)
+
+		var postIds []string
+
+		if _, err := s.GetMaster().Select(&postIds,
+			`SELECT
+				Posts.Id
+			FROM
+				Posts
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				Posts.CreateAt < :EndTime
+				AND `+scope+`
+			ORDER BY
+				Posts.CreateAt
+			LIMIT :Limit`, props); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetExpiredPostIds", "store.sql_retention_policy.get_expired_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = postIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) GetExpiredPostCount(policy *model.RetentionPolicy, endTime int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime}
+		scope := retentionScopeQuery(policy, props)
+
+		if count, err := s.GetReplica().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				Posts
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				Posts.CreateAt < :EndTime
+				AND `+scope, props); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetExpiredPostCount", "store.sql_retention_policy.get_expired_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetExpiredFileInfos returns up to limit files created before endTime that are attached to posts in
+// the channels that a policy applies to, or that the global settings apply to if policy is nil,
+// oldest first.
+func (s SqlRetentionPolicyStore) GetExpiredFileInfos(policy *model.RetentionPolicy, endTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime, "Limit": limit}
+		scope := retentionScopeQuery(policy, props)
+
+		var infos []*model.FileInfo
+
+		if _, err := s.GetMaster().Select(&infos,
+			`SELECT
+				FileInfo.*
+			FROM
+				FileInfo
+			INNER JOIN
+				Posts ON FileInfo.PostId = Posts.Id
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				FileInfo.CreateAt < :EndTime
+				AND `+scope+`
+			ORDER BY
+				FileInfo.CreateAt
+			LIMIT :Limit`, props); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetExpiredFileInfos", "store.sql_retention_policy.get_expired_files.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlRetentionPolicyStore) GetExpiredFileCount(policy *model.RetentionPolicy, endTime int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime}
+		scope := retentionScopeQuery(policy, props)
+
+		if count, err := s.GetReplica().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				FileInfo
+			INNER JOIN
+				Posts ON FileInfo.PostId = Posts.Id
+			INNER JOIN
+				Channels ON Posts.ChannelId = Channels.Id
+			WHERE
+				FileInfo.CreateAt < :EndTime
+				AND `+scope, props); err != nil {
+			result.Err = model.NewAppError("SqlRetentionPolicyStore.GetExpiredFileCount", "store.sql_retention_policy.get_expired_files.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
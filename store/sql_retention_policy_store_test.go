@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestRetentionPolicyStore(t *testing.T) {
+	Setup()
+
+	p1 := &model.RetentionPolicy{TeamId: model.NewId(), MessageRetentionDays: 30}
+	if result := <-store.RetentionPolicy().Save(p1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.RetentionPolicy().Save(&model.RetentionPolicy{TeamId: p1.TeamId}); result.Err == nil {
+		t.Fatal("shouldn't be able to save a second policy for a team")
+	}
+
+	p1.FileRetentionDays = 7
+	if result := <-store.RetentionPolicy().Update(p1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.RetentionPolicy().Get(p1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.RetentionPolicy); saved.FileRetentionDays != 7 {
+		t.Fatal("should have updated the policy")
+	}
+
+	if result := <-store.RetentionPolicy().Delete(p1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.RetentionPolicy().Get(p1.Id); result.Err == nil {
+		t.Fatal("should have deleted the policy")
+	}
+}
+
+func TestRetentionPolicyStoreExpired(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	c1 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel 1", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	c2 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel 2", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	teamPolicy := Must(store.RetentionPolicy().Save(&model.RetentionPolicy{TeamId: teamId, MessageRetentionDays: 1})).(*model.RetentionPolicy)
+	channelPolicy := Must(store.RetentionPolicy().Save(&model.RetentionPolicy{ChannelId: c2.Id, MessageRetentionDays: 1})).(*model.RetentionPolicy)
+	defer func() {
+		<-store.RetentionPolicy().Delete(teamPolicy.Id)
+		<-store.RetentionPolicy().Delete(channelPolicy.Id)
+	}()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: model.NewId(), Message: "old", CreateAt: 1000})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: c2.Id, UserId: model.NewId(), Message: "old", CreateAt: 1000})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: model.NewId(), Message: "new"})).(*model.Post)
+
+	info := Must(store.FileInfo().Save(&model.FileInfo{CreatorId: o2.UserId, PostId: o2.Id, Path: "file.txt", CreateAt: 1000})).(*model.FileInfo)
+
+	endTime := model.GetMillis() - 60000
+
+	if result := <-store.RetentionPolicy().GetExpiredPostIds(teamPolicy, endTime, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if postIds := result.Data.([]string); len(postIds) != 1 || postIds[0] != o1.Id {
+		t.Fatal("the team policy should only apply to the old post in the channel without a policy")
+	}
+
+	if result := <-store.RetentionPolicy().GetExpiredPostCount(channelPolicy, endTime); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(int64) != 1 {
+		t.Fatal("the channel policy should apply to the old post in its channel")
+	}
+
+	if result := <-store.RetentionPolicy().GetExpiredPostIds(nil, endTime, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		for _, postId := range result.Data.([]string) {
+			if postId == o1.Id || postId == o2.Id || postId == o3.Id {
+				t.Fatal("the global settings shouldn't apply to channels with a policy")
+			}
+		}
+	}
+
+	if result := <-store.RetentionPolicy().GetExpiredFileInfos(channelPolicy, endTime, 100); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if infos := result.Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Id != info.Id {
+		t.Fatal("should have returned the old file in the channel")
+	}
+
+	if result := <-store.FileInfo().GetForPosts([]string{o2.Id, o3.Id}); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if infos := result.Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Id != info.Id {
+		t.Fatal("should have returned the files of the posts")
+	}
+
+	if result := <-store.FileInfo().PermanentDeleteBatch([]string{info.Id}); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(int64) != 1 {
+		t.Fatal("should have deleted the file")
+	}
+
+	if result := <-store.Post().PermanentDeleteBatch([]string{o1.Id, o2.Id}); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(int64) != 2 {
+		t.Fatal("should have deleted the posts")
+	}
+
+	if result := <-store.Post().Get(o3.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted the other post")
+	}
+}
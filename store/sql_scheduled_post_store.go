@@ -0,0 +1,222 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlScheduledPostStore struct {
+	*SqlStore
+}
+
+func NewSqlScheduledPostStore(sqlStore *SqlStore) ScheduledPostStore {
+	s := &SqlScheduledPostStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ScheduledPost{}, "ScheduledPosts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_RUNES)
+		table.ColMap("Props").SetMaxSize(model.POST_PROPS_MAX_RUNES)
+		table.ColMap("FileIds").SetMaxSize(model.POST_FILEIDS_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlScheduledPostStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_scheduledposts_user_id", "ScheduledPosts", "UserId")
+	s.CreateIndexIfNotExists("idx_scheduledposts_scheduled_at", "ScheduledPosts", "ScheduledAt")
+}
+
+func (s SqlScheduledPostStore) Save(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(scheduledPost.Id) > 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.existing.app_error", nil, "id="+scheduledPost.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		scheduledPost.PreSave()
+		if result.Err = scheduledPost.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPost model.ScheduledPost
+
+		if err := s.GetReplica().SelectOne(&scheduledPost, "SELECT * FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+
+		if _, err := s.GetReplica().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE UserId = :UserId ORDER BY ScheduledAt", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetForUser", "store.sql_scheduled_post.get_for_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the scheduled posts that should have been published by the given time, oldest first.
+// Posts that were claimed at or after claimedBefore are still being published and are left out.
+func (s SqlScheduledPostStore) GetDue(before int64, claimedBefore int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var scheduledPosts []*model.ScheduledPost
+
+		if _, err := s.GetMaster().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE ScheduledAt <= :Before AND ClaimedAt < :ClaimedBefore ORDER BY ScheduledAt LIMIT :Limit", map[string]interface{}{"Before": before, "ClaimedBefore": claimedBefore, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.GetDue", "store.sql_scheduled_post.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPosts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim marks a scheduled post as being published, unless another claim made at or after
+// claimedBefore is still held. Only one server can claim a post, so it fails if the post was already
+// claimed or deleted.
+func (s SqlScheduledPostStore) Claim(id string, claimedAt int64, claimedBefore int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("UPDATE ScheduledPosts SET ClaimedAt = :ClaimedAt, Attempts = Attempts + 1 WHERE Id = :Id AND ClaimedAt < :ClaimedBefore",
+			map[string]interface{}{"Id": id, "ClaimedAt": claimedAt, "ClaimedBefore": claimedBefore}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// DeleteClaimed deletes a scheduled post only if it still holds the claim made at claimedAt, so that a
+// server whose claim expired or was taken over can't delete it from under the server that holds it.
+func (s SqlScheduledPostStore) DeleteClaimed(id string, claimedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id AND ClaimedAt = :ClaimedAt", map[string]interface{}{"Id": id, "ClaimedAt": claimedAt}); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.DeleteClaimed", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlScheduledPostStore.DeleteClaimed", "store.sql_scheduled_post.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Restore puts back a scheduled post that was deleted, keeping its id, claim and attempts.
+func (s SqlScheduledPostStore) Restore(scheduledPost *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if err := s.GetMaster().Insert(scheduledPost); err != nil {
+			result.Err = model.NewAppError("SqlScheduledPostStore.Restore", "store.sql_scheduled_post.restore.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = scheduledPost
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
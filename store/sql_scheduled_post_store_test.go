@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduledPostStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	sp1 := &model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "later", ScheduledAt: now + 60000}
+	if result := <-store.ScheduledPost().Save(sp1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Save(sp1); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing scheduled post")
+	}
+
+	sp2 := &model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "sooner", ScheduledAt: now - 60000}
+	sp2.Props = model.StringInterface{"from_schedule": "true"}
+	if result := <-store.ScheduledPost().Save(sp2); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Get(sp2.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.ScheduledPost); saved.Message != sp2.Message || saved.Props["from_schedule"] != "true" {
+		t.Fatal("should have saved the scheduled post")
+	}
+
+	if result := <-store.ScheduledPost().GetForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.([]*model.ScheduledPost); len(saved) != 2 || saved[0].Id != sp2.Id || saved[1].Id != sp1.Id {
+		t.Fatal("should have returned the user's scheduled posts ordered by time")
+	}
+
+	isDue := func(claimedBefore int64, id string) bool {
+		result := <-store.ScheduledPost().GetDue(now, claimedBefore, 1000)
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+
+		for _, due := range result.Data.([]*model.ScheduledPost) {
+			if due.Id == id {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if isDue(now, sp1.Id) {
+		t.Fatal("shouldn't have returned a post scheduled in the future")
+	}
+
+	if !isDue(now, sp2.Id) {
+		t.Fatal("should have returned the due post")
+	}
+
+	if result := <-store.ScheduledPost().Claim(sp2.Id, now, now); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Claim(sp2.Id, now, now); result.Err == nil {
+		t.Fatal("shouldn't be able to claim a post that is already claimed")
+	}
+
+	if isDue(now, sp2.Id) {
+		t.Fatal("shouldn't have returned a claimed post")
+	}
+
+	if !isDue(now+1, sp2.Id) {
+		t.Fatal("should have returned a post whose claim expired")
+	}
+
+	if result := <-store.ScheduledPost().Claim(sp2.Id, now+1, now+1); result.Err != nil {
+		t.Fatal("should be able to claim a post whose claim expired", result.Err)
+	} else if claimed := (<-store.ScheduledPost().Get(sp2.Id)).Data.(*model.ScheduledPost); claimed.ClaimedAt != now+1 || claimed.Attempts != 2 {
+		t.Fatal("should have counted both claims", claimed.ClaimedAt, claimed.Attempts)
+	}
+
+	if result := <-store.ScheduledPost().DeleteClaimed(sp2.Id, now); result.Err == nil {
+		t.Fatal("shouldn't be able to delete a post with a claim that was taken over")
+	}
+
+	claimed := (<-store.ScheduledPost().Get(sp2.Id)).Data.(*model.ScheduledPost)
+	if result := <-store.ScheduledPost().DeleteClaimed(sp2.Id, now+1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().DeleteClaimed(sp2.Id, now+1); result.Err == nil {
+		t.Fatal("shouldn't be able to delete a claimed post twice")
+	}
+
+	if result := <-store.ScheduledPost().Restore(claimed); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if restored := (<-store.ScheduledPost().Get(sp2.Id)).Data.(*model.ScheduledPost); restored.ClaimedAt != now+1 || restored.Attempts != 2 {
+		t.Fatal("should have restored the post with its claim", restored.ClaimedAt, restored.Attempts)
+	}
+
+	if result := <-store.ScheduledPost().Delete(sp2.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Delete(sp2.Id); result.Err == nil {
+		t.Fatal("shouldn't be able to delete a scheduled post twice")
+	}
+
+	if result := <-store.ScheduledPost().Get(sp2.Id); result.Err == nil {
+		t.Fatal("should have deleted the scheduled post")
+	}
+
+	<-store.ScheduledPost().Delete(sp1.Id)
+}
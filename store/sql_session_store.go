@@ -318,6 +318,24 @@ func (me SqlSessionStore) UpdateDeviceId(id string, deviceId string, expiresAt i
 	return storeChannel
 }
 
+func (me SqlSessionStore) UpdateProps(session *model.Session) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+		if _, err := me.GetMaster().Exec("UPDATE Sessions SET Props = :Props WHERE Id = :Id", map[string]interface{}{"Props": model.MapToJson(session.Props), "Id": session.Id}); err != nil {
+			result.Err = model.NewLocAppError("SqlSessionStore.UpdateProps", "store.sql_session.update_props.app_error", nil, "sessionId="+session.Id+", "+err.Error())
+		} else {
+			result.Data = session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (me SqlSessionStore) AnalyticsSessionCount() StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -217,6 +217,29 @@ func TestSessionUpdateDeviceId2(t *testing.T) {
 	}
 }
 
+func TestSessionStoreUpdateProps(t *testing.T) {
+	Setup()
+
+	s1 := model.Session{}
+	s1.UserId = model.NewId()
+	s1.AddProp(model.SESSION_PROP_BROWSER, "Chrome/58.0")
+	Must(store.Session().Save(&s1))
+
+	s1.AddProp(model.SESSION_PROP_LABEL, "Work laptop")
+	if err := (<-store.Session().UpdateProps(&s1)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if rs1 := (<-store.Session().Get(s1.Id)); rs1.Err != nil {
+		t.Fatal(rs1.Err)
+	} else {
+		session := rs1.Data.(*model.Session)
+		if session.Props[model.SESSION_PROP_LABEL] != "Work laptop" || session.Props[model.SESSION_PROP_BROWSER] != "Chrome/58.0" {
+			t.Fatal("props should have been updated", session.Props)
+		}
+	}
+}
+
 func TestSessionStoreUpdateLastActivityAt(t *testing.T) {
 	Setup()
 
@@ -0,0 +1,409 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlSharedChannelStore struct {
+	*SqlStore
+}
+
+func NewSqlSharedChannelStore(sqlStore *SqlStore) SharedChannelStore {
+	s := &SqlSharedChannelStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.SharedChannel{}, "SharedChannels").SetKeys(false, "ChannelId")
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("SharedId").SetMaxSize(26)
+		table.ColMap("RemoteId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+
+		tabler := db.AddTableWithName(model.SharedChannelRemote{}, "SharedChannelRemotes").SetKeys(false, "Id")
+		tabler.ColMap("Id").SetMaxSize(26)
+		tabler.ColMap("ChannelId").SetMaxSize(26)
+		tabler.ColMap("RemoteId").SetMaxSize(26)
+		tabler.ColMap("CreatorId").SetMaxSize(26)
+		tabler.SetUniqueTogether("ChannelId", "RemoteId")
+	}
+
+	return s
+}
+
+func (s SqlSharedChannelStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_shared_channels_shared_id", "SharedChannels", "SharedId")
+	s.CreateIndexIfNotExists("idx_shared_channel_remotes_remote_id", "SharedChannelRemotes", "RemoteId")
+}
+
+func (s SqlSharedChannelStore) Save(sc *model.SharedChannel) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		sc.PreSave()
+		if result.Err = sc.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(sc); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.Save", "store.sql_shared_channel.save.app_error", nil, "channel_id="+sc.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = sc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlSharedChannelStore) Get(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var sc model.SharedChannel
+		if err := s.GetReplica().SelectOne(&sc, "SELECT * FROM SharedChannels WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlSharedChannelStore.Get", "store.sql_shared_channel.get.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlSharedChannelStore.Get", "store.sql_shared_channel.get.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &sc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForRemote returns this server's copy of a shared channel that a remote is syncing, which is
+// either the channel that came from the remote or a channel at home that is shared with it.
+func (s SqlSharedChannelStore) GetForRemote(sharedId string, remoteId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var sc model.SharedChannel
+		if err := s.GetReplica().SelectOne(&sc,
+			`SELECT
+				SharedChannels.*
+			FROM
+				SharedChannels
+			WHERE
+				(SharedId = :SharedId AND RemoteId = :RemoteId)
+				OR (ChannelId = :SharedId AND Home = :Home AND EXISTS (
+					SELECT 1 FROM SharedChannelRemotes WHERE SharedChannelRemotes.ChannelId = SharedChannels.ChannelId AND SharedChannelRemotes.RemoteId = :RemoteId
+				))`, map[string]interface{}{"SharedId": sharedId, "RemoteId": remoteId, "Home": true}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlSharedChannelStore.GetForRemote", "store.sql_shared_channel.get.app_error", nil, "shared_id="+sharedId+", remote_id="+remoteId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlSharedChannelStore.GetForRemote", "store.sql_shared_channel.get.app_error", nil, "shared_id="+sharedId+", remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &sc
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete stops sharing a channel with every remote. The channel and its posts are left alone.
+func (s SqlSharedChannelStore) Delete(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM SharedChannelRemotes WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.Delete", "store.sql_shared_channel.delete.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else if _, err := s.GetMaster().Exec("DELETE FROM SharedChannels WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.Delete", "store.sql_shared_channel.delete.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlSharedChannelStore) SaveRemote(remote *model.SharedChannelRemote) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		remote.PreSave()
+		if result.Err = remote.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(remote); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"ChannelId", "sharedchannelremotes_channelid_remoteid_key"}) {
+				result.Err = model.NewAppError("SqlSharedChannelStore.SaveRemote", "store.sql_shared_channel.save_remote.exists.app_error", nil, "channel_id="+remote.ChannelId+", remote_id="+remote.RemoteId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlSharedChannelStore.SaveRemote", "store.sql_shared_channel.save_remote.app_error", nil, "channel_id="+remote.ChannelId+", remote_id="+remote.RemoteId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = remote
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlSharedChannelStore) GetRemotes(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var remotes []*model.SharedChannelRemote
+		if _, err := s.GetReplica().Select(&remotes, "SELECT * FROM SharedChannelRemotes WHERE ChannelId = :ChannelId ORDER BY CreateAt", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.GetRemotes", "store.sql_shared_channel.get_remotes.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = remotes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlSharedChannelStore) DeleteRemote(channelId string, remoteId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM SharedChannelRemotes WHERE ChannelId = :ChannelId AND RemoteId = :RemoteId", map[string]interface{}{"ChannelId": channelId, "RemoteId": remoteId}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.DeleteRemote", "store.sql_shared_channel.delete_remote.app_error", nil, "channel_id="+channelId+", remote_id="+remoteId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := sqlResult.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlSharedChannelStore.DeleteRemote", "store.sql_shared_channel.delete_remote.app_error", nil, "channel_id="+channelId+", remote_id="+remoteId, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlSharedChannelStore) UpdateRemoteLastSyncAt(id string, lastSyncAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE SharedChannelRemotes SET LastSyncAt = :LastSyncAt WHERE Id = :Id", map[string]interface{}{"LastSyncAt": lastSyncAt, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpdateRemoteLastSyncAt", "store.sql_shared_channel.update_remote.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPostsForSync returns the posts in a channel that changed after the given time, oldest change
+// first. Deleted posts are included, but the copies kept of edited posts aren't.
+func (s SqlSharedChannelStore) GetPostsForSync(channelId string, since int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND UpdateAt > :Since
+				AND OriginalId = ''
+			ORDER BY UpdateAt, Id
+			LIMIT :Limit`, map[string]interface{}{"ChannelId": channelId, "Since": since, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.GetPostsForSync", "store.sql_shared_channel.get_posts_for_sync.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// TouchPost marks a post as changed so that it's synced again, which is used when its reactions
+// change.
+func (s SqlSharedChannelStore) TouchPost(postId string, updateAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE Posts SET UpdateAt = :UpdateAt WHERE Id = :Id AND UpdateAt < :UpdateAt", map[string]interface{}{"UpdateAt": updateAt, "Id": postId}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.TouchPost", "store.sql_shared_channel.touch_post.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpsertPost saves a post synced from a remote, keeping the id that it has on the remote. A post
+// that's already here is only replaced by a newer version of it, so the most recent change wins
+// when both servers changed a post, and the current version is kept when they changed it at the
+// same time. The result is whether the post was saved.
+func (s SqlSharedChannelStore) UpsertPost(post *model.Post) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = post.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		var existing model.Post
+		if err := s.GetMaster().SelectOne(&existing, "SELECT * FROM Posts WHERE Id = :Id", map[string]interface{}{"Id": post.Id}); err == sql.ErrNoRows {
+			if err := s.GetMaster().Insert(post); err != nil {
+				result.Err = model.NewAppError("SqlSharedChannelStore.UpsertPost", "store.sql_shared_channel.upsert_post.app_error", nil, "post_id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				if post.DeleteAt == 0 {
+					s.GetMaster().Exec("UPDATE Channels SET LastPostAt = :LastPostAt, TotalMsgCount = TotalMsgCount + 1 WHERE Id = :ChannelId AND LastPostAt < :LastPostAt", map[string]interface{}{"LastPostAt": post.CreateAt, "ChannelId": post.ChannelId})
+				}
+				result.Data = true
+			}
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpsertPost", "store.sql_shared_channel.upsert_post.app_error", nil, "post_id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if existing.ChannelId != post.ChannelId || existing.UserId != post.UserId {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpsertPost", "store.sql_shared_channel.upsert_post.mismatch.app_error", nil, "post_id="+post.Id, http.StatusBadRequest)
+		} else if post.UpdateAt <= existing.UpdateAt {
+			result.Data = false
+		} else if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				Posts
+			SET
+				UpdateAt = :UpdateAt,
+				EditAt = :EditAt,
+				DeleteAt = :DeleteAt,
+				IsPinned = :IsPinned,
+				Message = :Message,
+				Props = :Props,
+				Hashtags = :Hashtags,
+				FileIds = :FileIds
+			WHERE
+				Id = :Id
+				AND UpdateAt < :UpdateAt`, map[string]interface{}{
+				"Id":       post.Id,
+				"UpdateAt": post.UpdateAt,
+				"EditAt":   post.EditAt,
+				"DeleteAt": post.DeleteAt,
+				"IsPinned": post.IsPinned,
+				"Message":  post.Message,
+				"Props":    model.StringInterfaceToJson(post.Props),
+				"Hashtags": post.Hashtags,
+				"FileIds":  model.ArrayToJson(post.FileIds),
+			}); err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpsertPost", "store.sql_shared_channel.upsert_post.app_error", nil, "post_id="+post.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			count, _ := sqlResult.RowsAffected()
+			result.Data = count > 0
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpsertRemoteUser saves the user standing in for a user of a remote, keeping the id that it has on
+// the remote. Only the names of a user that's already here are updated, and only if it stands in
+// for a user of the same remote.
+func (s SqlSharedChannelStore) UpsertRemoteUser(user *model.User) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		remoteId := user.Props[model.USER_PROP_REMOTE_ID]
+
+		var existing model.User
+		if err := s.GetMaster().SelectOne(&existing, "SELECT * FROM Users WHERE Id = :Id", map[string]interface{}{"Id": user.Id}); err == sql.ErrNoRows {
+			user.PreSave()
+			if result.Err = user.IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			if err := s.GetMaster().Insert(user); err != nil {
+				if IsUniqueConstraintError(err.Error(), []string{"Username", "users_username_key", "idx_users_username_unique"}) {
+					result.Err = model.NewAppError("SqlSharedChannelStore.UpsertRemoteUser", "store.sql_user.save.username_exists.app_error", nil, "user_id="+user.Id+", "+err.Error(), http.StatusBadRequest)
+				} else {
+					result.Err = model.NewAppError("SqlSharedChannelStore.UpsertRemoteUser", "store.sql_shared_channel.upsert_remote_user.app_error", nil, "user_id="+user.Id+", "+err.Error(), http.StatusInternalServerError)
+				}
+			} else {
+				result.Data = user
+			}
+		} else if err != nil {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpsertRemoteUser", "store.sql_shared_channel.upsert_remote_user.app_error", nil, "user_id="+user.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if len(remoteId) == 0 || existing.Props[model.USER_PROP_REMOTE_ID] != remoteId {
+			result.Err = model.NewAppError("SqlSharedChannelStore.UpsertRemoteUser", "store.sql_shared_channel.upsert_remote_user.not_remote.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+		} else if existing.FirstName == user.FirstName && existing.LastName == user.LastName && existing.Nickname == user.Nickname {
+			result.Data = &existing
+		} else {
+			existing.FirstName = user.FirstName
+			existing.LastName = user.LastName
+			existing.Nickname = user.Nickname
+			existing.UpdateAt = model.GetMillis()
+
+			if _, err := s.GetMaster().Update(&existing); err != nil {
+				result.Err = model.NewAppError("SqlSharedChannelStore.UpsertRemoteUser", "store.sql_shared_channel.upsert_remote_user.app_error", nil, "user_id="+user.Id+", "+err.Error(), http.StatusInternalServerError)
+			} else {
+				result.Data = &existing
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
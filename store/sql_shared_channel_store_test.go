@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestSharedChannelStoreGetForRemote(t *testing.T) {
+	Setup()
+
+	remoteId := model.NewId()
+
+	home := &model.SharedChannel{ChannelId: model.NewId(), TeamId: model.NewId(), Home: true, CreatorId: model.NewId()}
+	home.SharedId = home.ChannelId
+	Must(store.SharedChannel().Save(home))
+
+	if result := <-store.SharedChannel().GetForRemote(home.SharedId, remoteId); result.Err == nil {
+		t.Fatal("the channel isn't shared with the remote yet")
+	}
+
+	remote := Must(store.SharedChannel().SaveRemote(&model.SharedChannelRemote{ChannelId: home.ChannelId, RemoteId: remoteId, CreatorId: model.NewId()})).(*model.SharedChannelRemote)
+
+	if result := <-store.SharedChannel().SaveRemote(&model.SharedChannelRemote{ChannelId: home.ChannelId, RemoteId: remoteId, CreatorId: model.NewId()}); result.Err == nil {
+		t.Fatal("shouldn't share the channel with the same remote twice")
+	}
+
+	if sc := Must(store.SharedChannel().GetForRemote(home.SharedId, remoteId)).(*model.SharedChannel); sc.ChannelId != home.ChannelId {
+		t.Fatal("should have found the channel at home")
+	}
+
+	remoteCopy := &model.SharedChannel{ChannelId: model.NewId(), TeamId: model.NewId(), SharedId: model.NewId(), RemoteId: remoteId, CreatorId: model.NewId()}
+	Must(store.SharedChannel().Save(remoteCopy))
+
+	if sc := Must(store.SharedChannel().GetForRemote(remoteCopy.SharedId, remoteId)).(*model.SharedChannel); sc.ChannelId != remoteCopy.ChannelId {
+		t.Fatal("should have found the copy of the remote's channel")
+	}
+
+	if result := <-store.SharedChannel().GetForRemote(remoteCopy.SharedId, model.NewId()); result.Err == nil {
+		t.Fatal("the copy shouldn't be found for another remote")
+	}
+
+	Must(store.SharedChannel().UpdateRemoteLastSyncAt(remote.Id, 1234))
+
+	if remotes := Must(store.SharedChannel().GetRemotes(home.ChannelId)).([]*model.SharedChannelRemote); len(remotes) != 1 || remotes[0].LastSyncAt != 1234 {
+		t.Fatal("the sync time wasn't updated")
+	}
+
+	Must(store.SharedChannel().DeleteRemote(home.ChannelId, remoteId))
+
+	if result := <-store.SharedChannel().DeleteRemote(home.ChannelId, remoteId); result.Err == nil {
+		t.Fatal("the remote should have been removed already")
+	}
+}
+
+func TestSharedChannelStoreUpsertPost(t *testing.T) {
+	Setup()
+
+	post := &model.Post{Id: model.NewId(), ChannelId: model.NewId(), UserId: model.NewId(), Message: "first"}
+	post.PreSave()
+
+	if inserted := Must(store.SharedChannel().UpsertPost(post)).(bool); !inserted {
+		t.Fatal("the post should have been inserted")
+	}
+
+	older := *post
+	older.Message = "older"
+	older.UpdateAt = post.UpdateAt - 1
+
+	if updated := Must(store.SharedChannel().UpsertPost(&older)).(bool); updated {
+		t.Fatal("an older version shouldn't replace the post")
+	}
+
+	newer := *post
+	newer.Message = "newer"
+	newer.UpdateAt = post.UpdateAt + 1
+
+	if updated := Must(store.SharedChannel().UpsertPost(&newer)).(bool); !updated {
+		t.Fatal("a newer version should replace the post")
+	}
+
+	if rpost := Must(store.Post().GetSingle(post.Id)).(*model.Post); rpost.Message != "newer" {
+		t.Fatal("the post wasn't updated")
+	}
+
+	other := newer
+	other.UserId = model.NewId()
+	other.UpdateAt = newer.UpdateAt + 1
+
+	if result := <-store.SharedChannel().UpsertPost(&other); result.Err == nil {
+		t.Fatal("shouldn't change who made the post")
+	}
+
+	Must(store.SharedChannel().TouchPost(post.Id, newer.UpdateAt+10))
+
+	if posts := Must(store.SharedChannel().GetPostsForSync(post.ChannelId, newer.UpdateAt, 10)).([]*model.Post); len(posts) != 1 || posts[0].Id != post.Id {
+		t.Fatal("the touched post should be synced")
+	}
+}
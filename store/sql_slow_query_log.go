@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	SQL_SLOW_QUERY_LOG_SIZE = 100
+)
+
+var slowQueryLog = newSqlSlowQueryLog(SQL_SLOW_QUERY_LOG_SIZE)
+
+// sqlSlowQueryLog keeps the slow queries of this server in memory, grouped by their redacted text.
+// Once full, the query that was least recently slow is dropped to make room for a new one.
+type sqlSlowQueryLog struct {
+	mutex   sync.Mutex
+	size    int
+	queries map[string]*model.SlowQuery
+}
+
+func newSqlSlowQueryLog(size int) *sqlSlowQueryLog {
+	return &sqlSlowQueryLog{
+		size:    size,
+		queries: make(map[string]*model.SlowQuery),
+	}
+}
+
+// record adds a slow run of a query to the log and returns true if the query's plan should be
+// captured now that it has been slow explainThreshold times.
+func (l *sqlSlowQueryLog) record(connection string, query string, elapsed time.Duration, explainThreshold int) bool {
+	redacted := redactSqlQuery(query)
+	key := connection + ":" + redacted
+	millis := int64(elapsed / time.Millisecond)
+	now := model.GetMillis()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	slowQuery, ok := l.queries[key]
+	if !ok {
+		if len(l.queries) >= l.size {
+			l.removeLeastRecentLocked()
+		}
+
+		slowQuery = &model.SlowQuery{
+			Connection:  connection,
+			Operation:   sqlQueryOperation(query),
+			Query:       redacted,
+			FirstSeenAt: now,
+		}
+		l.queries[key] = slowQuery
+	}
+
+	slowQuery.Count++
+	slowQuery.TotalMillis += millis
+	slowQuery.LastSeenAt = now
+	if millis > slowQuery.MaxMillis {
+		slowQuery.MaxMillis = millis
+	}
+
+	return explainThreshold > 0 && slowQuery.Count == int64(explainThreshold)
+}
+
+func (l *sqlSlowQueryLog) removeLeastRecentLocked() {
+	oldestKey := ""
+	var oldest int64
+	for key, slowQuery := range l.queries {
+		if oldestKey == "" || slowQuery.LastSeenAt < oldest {
+			oldestKey = key
+			oldest = slowQuery.LastSeenAt
+		}
+	}
+
+	delete(l.queries, oldestKey)
+}
+
+func (l *sqlSlowQueryLog) setPlan(connection string, query string, plan string) {
+	key := connection + ":" + redactSqlQuery(query)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if slowQuery, ok := l.queries[key]; ok {
+		slowQuery.Plan = plan
+	}
+}
+
+// list returns copies of the slow queries, the most recently slow first.
+func (l *sqlSlowQueryLog) list() []*model.SlowQuery {
+	l.mutex.Lock()
+	queries := make([]*model.SlowQuery, 0, len(l.queries))
+	for _, slowQuery := range l.queries {
+		copied := *slowQuery
+		queries = append(queries, &copied)
+	}
+	l.mutex.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].LastSeenAt > queries[j].LastSeenAt
+	})
+
+	return queries
+}
+
+func (l *sqlSlowQueryLog) clear() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.queries = make(map[string]*model.SlowQuery)
+}
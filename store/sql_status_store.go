@@ -5,6 +5,7 @@ package store
 
 import (
 	"database/sql"
+	"net/http"
 	"strconv"
 
 	"github.com/mattermost/platform/model"
@@ -26,6 +27,8 @@ func NewSqlStatusStore(sqlStore *SqlStore) StatusStore {
 		table.ColMap("UserId").SetMaxSize(26)
 		table.ColMap("Status").SetMaxSize(32)
 		table.ColMap("ActiveChannel").SetMaxSize(26)
+		table.ColMap("CustomStatusEmoji").SetMaxSize(64)
+		table.ColMap("CustomStatusText").SetMaxSize(128)
 	}
 
 	return s
@@ -34,6 +37,7 @@ func NewSqlStatusStore(sqlStore *SqlStore) StatusStore {
 func (s SqlStatusStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_status_user_id", "Status", "UserId")
 	s.CreateIndexIfNotExists("idx_status_status", "Status", "Status")
+	s.CreateIndexIfNotExists("idx_status_last_activity_at", "Status", "LastActivityAt")
 }
 
 func (s SqlStatusStore) SaveOrUpdate(status *model.Status) StoreChannel {
@@ -238,3 +242,32 @@ func (s SqlStatusStore) UpdateLastActivityAt(userId string, lastActivityAt int64
 
 	return storeChannel
 }
+
+// GetExpired returns the statuses whose do not disturb period or custom status has ended by the given time.
+func (s SqlStatusStore) GetExpired(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var statuses []*model.Status
+		if _, err := s.GetReplica().Select(&statuses,
+			`SELECT
+				*
+			FROM
+				Status
+			WHERE
+				(DNDEndTime > 0 AND DNDEndTime <= :Time)
+				OR (CustomStatusExpiresAt > 0 AND CustomStatusExpiresAt <= :Time)
+			LIMIT :Limit`, map[string]interface{}{"Time": time, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlStatusStore.GetExpired", "store.sql_status.get_expired.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = statuses
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -102,3 +102,35 @@ func TestActiveUserCount(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusStoreGetExpired(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	dnd := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: now - 1000}
+	Must(store.Status().SaveOrUpdate(dnd))
+
+	customStatus := &model.Status{UserId: model.NewId(), Status: model.STATUS_ONLINE, CustomStatusText: "lunch", CustomStatusExpiresAt: now - 1000}
+	Must(store.Status().SaveOrUpdate(customStatus))
+
+	notExpired := &model.Status{UserId: model.NewId(), Status: model.STATUS_DND, Manual: true, DNDEndTime: now + 100000, CustomStatusText: "lunch"}
+	Must(store.Status().SaveOrUpdate(notExpired))
+
+	if result := <-store.Status().GetExpired(now, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := map[string]bool{}
+		for _, status := range result.Data.([]*model.Status) {
+			found[status.UserId] = true
+		}
+
+		if !found[dnd.UserId] || !found[customStatus.UserId] {
+			t.Fatal("should have returned the expired statuses")
+		}
+
+		if found[notExpired.UserId] {
+			t.Fatal("should not have returned a status that hasn't expired")
+		}
+	}
+}
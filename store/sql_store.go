@@ -65,28 +65,56 @@ const (
 )
 
 type SqlStore struct {
-	master        *gorp.DbMap
-	replicas      []*gorp.DbMap
-	team          TeamStore
-	channel       ChannelStore
-	post          PostStore
-	user          UserStore
-	audit         AuditStore
-	compliance    ComplianceStore
-	session       SessionStore
-	oauth         OAuthStore
-	system        SystemStore
-	webhook       WebhookStore
-	command       CommandStore
-	preference    PreferenceStore
-	license       LicenseStore
-	recovery      PasswordRecoveryStore
-	emoji         EmojiStore
-	status        StatusStore
-	fileInfo      FileInfoStore
-	reaction      ReactionStore
-	SchemaVersion string
-	rrCounter     int64
+	master             *gorp.DbMap
+	replicas           []*gorp.DbMap
+	team               TeamStore
+	channel            ChannelStore
+	post               PostStore
+	user               UserStore
+	audit              AuditStore
+	compliance         ComplianceStore
+	session            SessionStore
+	oauth              OAuthStore
+	system             SystemStore
+	webhook            WebhookStore
+	command            CommandStore
+	preference         PreferenceStore
+	license            LicenseStore
+	recovery           PasswordRecoveryStore
+	emoji              EmojiStore
+	status             StatusStore
+	fileInfo           FileInfoStore
+	reaction           ReactionStore
+	alertmanager       AlertmanagerStore
+	incident           IncidentStore
+	featureFlag        FeatureFlagStore
+	experiment         ExperimentStore
+	teamTemplate       TeamTemplateStore
+	scheduledPost      ScheduledPostStore
+	emojiUsage         EmojiUsageStore
+	draft              DraftStore
+	device             DeviceStore
+	channelMemberRead  ChannelMemberReadStore
+	bot                BotStore
+	userAccessToken    UserAccessTokenStore
+	userCredential     UserCredentialStore
+	postIntegrity      PostIntegrityStore
+	ldapGroup          LdapGroupStore
+	invitation         InvitationStore
+	archiveExport      ArchiveExportStore
+	postEventHook      PostEventHookStore
+	bulkEmail          BulkEmailStore
+	retentionPolicy    RetentionPolicyStore
+	autoResponder      AutoResponderStore
+	legalHold          LegalHoldStore
+	nameRedirect       NameRedirectStore
+	teamQuota          TeamQuotaStore
+	remoteCluster      RemoteClusterStore
+	sharedChannel      SharedChannelStore
+	channelAutoArchive ChannelAutoArchiveStore
+	SchemaVersion      string
+	capabilities       SqlCapabilities
+	rrCounter          int64
 }
 
 func initConnection() *SqlStore {
@@ -110,6 +138,7 @@ func initConnection() *SqlStore {
 		}
 	}
 
+	sqlStore.capabilities = detectSqlCapabilities(sqlStore.master, utils.Cfg.SqlSettings.DriverName)
 	sqlStore.SchemaVersion = sqlStore.GetCurrentSchemaVersion()
 	return sqlStore
 }
@@ -136,6 +165,33 @@ func NewSqlStore() Store {
 	sqlStore.status = NewSqlStatusStore(sqlStore)
 	sqlStore.fileInfo = NewSqlFileInfoStore(sqlStore)
 	sqlStore.reaction = NewSqlReactionStore(sqlStore)
+	sqlStore.alertmanager = NewSqlAlertmanagerStore(sqlStore)
+	sqlStore.incident = NewSqlIncidentStore(sqlStore)
+	sqlStore.featureFlag = NewSqlFeatureFlagStore(sqlStore)
+	sqlStore.experiment = NewSqlExperimentStore(sqlStore)
+	sqlStore.teamTemplate = NewSqlTeamTemplateStore(sqlStore)
+	sqlStore.scheduledPost = NewSqlScheduledPostStore(sqlStore)
+	sqlStore.emojiUsage = NewSqlEmojiUsageStore(sqlStore)
+	sqlStore.draft = NewSqlDraftStore(sqlStore)
+	sqlStore.device = NewSqlDeviceStore(sqlStore)
+	sqlStore.channelMemberRead = NewSqlChannelMemberReadStore(sqlStore)
+	sqlStore.bot = NewSqlBotStore(sqlStore)
+	sqlStore.userAccessToken = NewSqlUserAccessTokenStore(sqlStore)
+	sqlStore.userCredential = NewSqlUserCredentialStore(sqlStore)
+	sqlStore.postIntegrity = NewSqlPostIntegrityStore(sqlStore)
+	sqlStore.ldapGroup = NewSqlLdapGroupStore(sqlStore)
+	sqlStore.invitation = NewSqlInvitationStore(sqlStore)
+	sqlStore.archiveExport = NewSqlArchiveExportStore(sqlStore)
+	sqlStore.postEventHook = NewSqlPostEventHookStore(sqlStore)
+	sqlStore.bulkEmail = NewSqlBulkEmailStore(sqlStore)
+	sqlStore.retentionPolicy = NewSqlRetentionPolicyStore(sqlStore)
+	sqlStore.autoResponder = NewSqlAutoResponderStore(sqlStore)
+	sqlStore.legalHold = NewSqlLegalHoldStore(sqlStore)
+	sqlStore.nameRedirect = NewSqlNameRedirectStore(sqlStore)
+	sqlStore.teamQuota = NewSqlTeamQuotaStore(sqlStore)
+	sqlStore.remoteCluster = NewSqlRemoteClusterStore(sqlStore)
+	sqlStore.sharedChannel = NewSqlSharedChannelStore(sqlStore)
+	sqlStore.channelAutoArchive = NewSqlChannelAutoArchiveStore(sqlStore)
 
 	err := sqlStore.master.CreateTablesIfNotExists()
 	if err != nil {
@@ -164,6 +220,28 @@ func NewSqlStore() Store {
 	sqlStore.status.(*SqlStatusStore).CreateIndexesIfNotExists()
 	sqlStore.fileInfo.(*SqlFileInfoStore).CreateIndexesIfNotExists()
 	sqlStore.reaction.(*SqlReactionStore).CreateIndexesIfNotExists()
+	sqlStore.alertmanager.(*SqlAlertmanagerStore).CreateIndexesIfNotExists()
+	sqlStore.incident.(*SqlIncidentStore).CreateIndexesIfNotExists()
+	sqlStore.scheduledPost.(*SqlScheduledPostStore).CreateIndexesIfNotExists()
+	sqlStore.emojiUsage.(*SqlEmojiUsageStore).CreateIndexesIfNotExists()
+	sqlStore.device.(*SqlDeviceStore).CreateIndexesIfNotExists()
+	sqlStore.channelMemberRead.(*SqlChannelMemberReadStore).CreateIndexesIfNotExists()
+	sqlStore.bot.(*SqlBotStore).CreateIndexesIfNotExists()
+	sqlStore.userAccessToken.(*SqlUserAccessTokenStore).CreateIndexesIfNotExists()
+	sqlStore.userCredential.(*SqlUserCredentialStore).CreateIndexesIfNotExists()
+	sqlStore.postIntegrity.(*SqlPostIntegrityStore).CreateIndexesIfNotExists()
+	sqlStore.ldapGroup.(*SqlLdapGroupStore).CreateIndexesIfNotExists()
+	sqlStore.invitation.(*SqlInvitationStore).CreateIndexesIfNotExists()
+	sqlStore.archiveExport.(*SqlArchiveExportStore).CreateIndexesIfNotExists()
+	sqlStore.postEventHook.(*SqlPostEventHookStore).CreateIndexesIfNotExists()
+	sqlStore.bulkEmail.(*SqlBulkEmailStore).CreateIndexesIfNotExists()
+	sqlStore.retentionPolicy.(*SqlRetentionPolicyStore).CreateIndexesIfNotExists()
+	sqlStore.autoResponder.(*SqlAutoResponderStore).CreateIndexesIfNotExists()
+	sqlStore.legalHold.(*SqlLegalHoldStore).CreateIndexesIfNotExists()
+	sqlStore.nameRedirect.(*SqlNameRedirectStore).CreateIndexesIfNotExists()
+	sqlStore.remoteCluster.(*SqlRemoteClusterStore).CreateIndexesIfNotExists()
+	sqlStore.sharedChannel.(*SqlSharedChannelStore).CreateIndexesIfNotExists()
+	sqlStore.channelAutoArchive.(*SqlChannelAutoArchiveStore).CreateIndexesIfNotExists()
 
 	sqlStore.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 
@@ -172,13 +250,25 @@ func NewSqlStore() Store {
 
 func setupConnection(con_type string, driver string, dataSource string, maxIdle int, maxOpen int, trace bool) *gorp.DbMap {
 
-	db, err := dbsql.Open(driver, dataSource)
+	// The registered driver is only opened to get it so that it can be wrapped by the instrumented one
+	unwrapped, err := dbsql.Open(driver, dataSource)
 	if err != nil {
 		l4g.Critical(utils.T("store.sql.open_conn.critical"), err)
 		time.Sleep(time.Second)
 		os.Exit(EXIT_DB_OPEN)
 	}
 
+	connector, err := newSqlInstrumentedConnector(con_type, unwrapped.Driver(), dataSource)
+	unwrapped.Close()
+	if err != nil {
+		l4g.Critical(utils.T("store.sql.open_conn.critical"), err)
+		time.Sleep(time.Second)
+		os.Exit(EXIT_DB_OPEN)
+	}
+
+	db := dbsql.OpenDB(connector)
+	connector.db = db
+
 	l4g.Info(utils.T("store.sql.pinging.info"), con_type)
 	err = db.Ping()
 	if err != nil {
@@ -212,6 +302,15 @@ func setupConnection(con_type string, driver string, dataSource string, maxIdle
 	return dbmap
 }
 
+// GetSlowQueries returns the queries that were slow on this server, the most recently slow first.
+func (ss *SqlStore) GetSlowQueries() []*model.SlowQuery {
+	return slowQueryLog.list()
+}
+
+func (ss *SqlStore) ClearSlowQueries() {
+	slowQueryLog.clear()
+}
+
 func (ss *SqlStore) TotalMasterDbConnections() int {
 	return ss.GetMaster().Db.Stats().OpenConnections
 }
@@ -230,6 +329,11 @@ func (ss *SqlStore) TotalReadDbConnections() int {
 	return count
 }
 
+// Capabilities returns the optional features of the master database server.
+func (ss *SqlStore) Capabilities() SqlCapabilities {
+	return ss.capabilities
+}
+
 func (ss *SqlStore) GetCurrentSchemaVersion() string {
 	version, _ := ss.GetMaster().SelectStr("SELECT Value FROM Systems WHERE Name='Version'")
 	return version
@@ -380,6 +484,42 @@ func (ss *SqlStore) CreateColumnIfNotExists(tableName string, columnName string,
 	}
 }
 
+// CreateColumnIfNotExistsNoDefault adds a column without a default value, which is needed for
+// types such as MySQL's text that can't have one. Returns true if the column was created.
+func (ss *SqlStore) CreateColumnIfNotExistsNoDefault(tableName string, columnName string, mySqlColType string, postgresColType string) bool {
+
+	if ss.DoesColumnExist(tableName, columnName) {
+		return false
+	}
+
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+		_, err := ss.GetMaster().Exec("ALTER TABLE " + tableName + " ADD " + columnName + " " + postgresColType)
+		if err != nil {
+			l4g.Critical(utils.T("store.sql.create_column.critical"), err)
+			time.Sleep(time.Second)
+			os.Exit(EXIT_CREATE_COLUMN_POSTGRES)
+		}
+
+		return true
+
+	} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := ss.GetMaster().Exec("ALTER TABLE " + tableName + " ADD " + columnName + " " + mySqlColType)
+		if err != nil {
+			l4g.Critical(utils.T("store.sql.create_column.critical"), err)
+			time.Sleep(time.Second)
+			os.Exit(EXIT_CREATE_COLUMN_MYSQL)
+		}
+
+		return true
+
+	} else {
+		l4g.Critical(utils.T("store.sql.create_column_missing_driver.critical"))
+		time.Sleep(time.Second)
+		os.Exit(EXIT_CREATE_COLUMN_MISSING)
+		return false
+	}
+}
+
 func (ss *SqlStore) RemoveColumnIfExists(tableName string, columnName string) bool {
 
 	if !ss.DoesColumnExist(tableName, columnName) {
@@ -687,6 +827,114 @@ func (ss *SqlStore) Reaction() ReactionStore {
 	return ss.reaction
 }
 
+func (ss *SqlStore) Alertmanager() AlertmanagerStore {
+	return ss.alertmanager
+}
+
+func (ss *SqlStore) Incident() IncidentStore {
+	return ss.incident
+}
+
+func (ss *SqlStore) FeatureFlag() FeatureFlagStore {
+	return ss.featureFlag
+}
+
+func (ss *SqlStore) Experiment() ExperimentStore {
+	return ss.experiment
+}
+
+func (ss *SqlStore) TeamTemplate() TeamTemplateStore {
+	return ss.teamTemplate
+}
+
+func (ss *SqlStore) ScheduledPost() ScheduledPostStore {
+	return ss.scheduledPost
+}
+
+func (ss *SqlStore) EmojiUsage() EmojiUsageStore {
+	return ss.emojiUsage
+}
+
+func (ss *SqlStore) Draft() DraftStore {
+	return ss.draft
+}
+
+func (ss *SqlStore) Device() DeviceStore {
+	return ss.device
+}
+
+func (ss *SqlStore) ChannelMemberRead() ChannelMemberReadStore {
+	return ss.channelMemberRead
+}
+
+func (ss *SqlStore) Bot() BotStore {
+	return ss.bot
+}
+
+func (ss *SqlStore) UserAccessToken() UserAccessTokenStore {
+	return ss.userAccessToken
+}
+
+func (ss *SqlStore) UserCredential() UserCredentialStore {
+	return ss.userCredential
+}
+
+func (ss *SqlStore) PostIntegrity() PostIntegrityStore {
+	return ss.postIntegrity
+}
+
+func (ss *SqlStore) LdapGroup() LdapGroupStore {
+	return ss.ldapGroup
+}
+
+func (ss *SqlStore) Invitation() InvitationStore {
+	return ss.invitation
+}
+
+func (ss *SqlStore) ArchiveExport() ArchiveExportStore {
+	return ss.archiveExport
+}
+
+func (ss *SqlStore) PostEventHook() PostEventHookStore {
+	return ss.postEventHook
+}
+
+func (ss *SqlStore) BulkEmail() BulkEmailStore {
+	return ss.bulkEmail
+}
+
+func (ss *SqlStore) RetentionPolicy() RetentionPolicyStore {
+	return ss.retentionPolicy
+}
+
+func (ss *SqlStore) AutoResponder() AutoResponderStore {
+	return ss.autoResponder
+}
+
+func (ss *SqlStore) LegalHold() LegalHoldStore {
+	return ss.legalHold
+}
+
+func (ss *SqlStore) NameRedirect() NameRedirectStore {
+	return ss.nameRedirect
+}
+
+func (ss *SqlStore) TeamQuota() TeamQuotaStore {
+	return ss.teamQuota
+}
+
+func (ss *SqlStore) RemoteCluster() RemoteClusterStore {
+	return ss.remoteCluster
+}
+
+func (ss *SqlStore) SharedChannel() SharedChannelStore {
+	return ss.sharedChannel
+}
+
+func (ss *SqlStore) ChannelAutoArchive() ChannelAutoArchiveStore {
+	return ss.channelAutoArchive
+}
+
 func (ss *SqlStore) DropAllTables() {
 	ss.master.TruncateTables()
 }
@@ -704,6 +952,10 @@ func (me mattermConverter) ToDb(val interface{}) (interface{}, error) {
 		return encrypt([]byte(utils.Cfg.SqlSettings.AtRestEncryptKey), model.MapToJson(t))
 	case model.StringInterface:
 		return model.StringInterfaceToJson(t), nil
+	case model.TeamTemplateContent:
+		return t.ToJson(), nil
+	case model.ChannelModerationSettings:
+		return t.ToJson(), nil
 	}
 
 	return val, nil
@@ -757,6 +1009,26 @@ func (me mattermConverter) FromDb(target interface{}) (gorp.CustomScanner, bool)
 			return json.Unmarshal(b, target)
 		}
 		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
+	case *model.TeamTemplateContent:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*string)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_team_template_content"))
+			}
+			b := []byte(*s)
+			return json.Unmarshal(b, target)
+		}
+		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
+	case *model.ChannelModerationSettings:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*string)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_channel_moderation_settings"))
+			}
+			b := []byte(*s)
+			return json.Unmarshal(b, target)
+		}
+		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
 	}
 
 	return gorp.CustomScanner{}, false
@@ -17,7 +17,7 @@ func NewSqlSystemStore(sqlStore *SqlStore) SystemStore {
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.System{}, "Systems").SetKeys(false, "Name")
 		table.ColMap("Name").SetMaxSize(64)
-		table.ColMap("Value").SetMaxSize(1024)
+		table.ColMap("Value").SetMaxSize(4096)
 	}
 
 	return s
@@ -0,0 +1,202 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlTeamQuotaStore struct {
+	*SqlStore
+}
+
+func NewSqlTeamQuotaStore(sqlStore *SqlStore) TeamQuotaStore {
+	s := &SqlTeamQuotaStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		tableq := db.AddTableWithName(model.TeamQuota{}, "TeamQuotas").SetKeys(false, "TeamId")
+		tableq.ColMap("TeamId").SetMaxSize(26)
+
+		tableu := db.AddTableWithName(model.TeamUsage{}, "TeamUsage").SetKeys(false, "TeamId")
+		tableu.ColMap("TeamId").SetMaxSize(26)
+		tableu.ColMap("Month").SetMaxSize(7)
+	}
+
+	return s
+}
+
+// SaveQuota creates or replaces the quota of a team.
+func (s SqlTeamQuotaStore) SaveQuota(quota *model.TeamQuota) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		quota.PreSave()
+		if result.Err = quota.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if exists, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM TeamQuotas WHERE TeamId = :TeamId", map[string]interface{}{"TeamId": quota.TeamId}); err != nil {
+			result.Err = model.NewAppError("SqlTeamQuotaStore.SaveQuota", "store.sql_team_quota.save_quota.app_error", nil, "team_id="+quota.TeamId+", "+err.Error(), http.StatusInternalServerError)
+		} else if exists > 0 {
+			if _, err := s.GetMaster().Update(quota); err != nil {
+				result.Err = model.NewAppError("SqlTeamQuotaStore.SaveQuota", "store.sql_team_quota.save_quota.app_error", nil, "team_id="+quota.TeamId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else if err := s.GetMaster().Insert(quota); err != nil {
+			result.Err = model.NewAppError("SqlTeamQuotaStore.SaveQuota", "store.sql_team_quota.save_quota.app_error", nil, "team_id="+quota.TeamId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		if result.Err == nil {
+			result.Data = quota
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamQuotaStore) GetQuota(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var quota model.TeamQuota
+		if err := s.GetReplica().SelectOne(&quota, "SELECT * FROM TeamQuotas WHERE TeamId = :TeamId", map[string]interface{}{"TeamId": teamId}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlTeamQuotaStore.GetQuota", "store.sql_team_quota.get_quota.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlTeamQuotaStore.GetQuota", "store.sql_team_quota.get_quota.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &quota
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetUsage returns what the team has used so far, which is nothing for a team that hasn't posted or
+// uploaded anything yet.
+func (s SqlTeamQuotaStore) GetUsage(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		usage := model.TeamUsage{TeamId: teamId}
+		if err := s.GetMaster().SelectOne(&usage, "SELECT * FROM TeamUsage WHERE TeamId = :TeamId", map[string]interface{}{"TeamId": teamId}); err != nil && err != sql.ErrNoRows {
+			result.Err = model.NewAppError("SqlTeamQuotaStore.GetUsage", "store.sql_team_quota.get_usage.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = &usage
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// CountIntegrations returns the number of webhooks and slash commands that the team has.
+func (s SqlTeamQuotaStore) CountIntegrations(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt(
+			`SELECT
+				(SELECT COUNT(*) FROM IncomingWebhooks WHERE TeamId = :TeamId AND DeleteAt = 0)
+				+ (SELECT COUNT(*) FROM OutgoingWebhooks WHERE TeamId = :TeamId AND DeleteAt = 0)
+				+ (SELECT COUNT(*) FROM Commands WHERE TeamId = :TeamId AND DeleteAt = 0)`,
+			map[string]interface{}{"TeamId": teamId}); err != nil {
+			result.Err = model.NewAppError("SqlTeamQuotaStore.CountIntegrations", "store.sql_team_quota.count_integrations.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IncrementPostCount counts a post made by the team in the given month. The count starts over when
+// the month changes.
+func (s SqlTeamQuotaStore) IncrementPostCount(teamId string, month string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		result.Err = s.incrementUsage(
+			`UPDATE
+				TeamUsage
+			SET
+				PostCount = CASE WHEN Month = :Month THEN PostCount + 1 ELSE 1 END,
+				Month = :Month,
+				UpdateAt = :UpdateAt
+			WHERE
+				TeamId = :TeamId`,
+			map[string]interface{}{"TeamId": teamId, "Month": month, "UpdateAt": model.GetMillis()},
+			&model.TeamUsage{TeamId: teamId, Month: month, PostCount: 1, UpdateAt: model.GetMillis()})
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// IncrementStorageBytes adds the size of files that the team uploaded to its usage.
+func (s SqlTeamQuotaStore) IncrementStorageBytes(teamId string, bytes int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		result.Err = s.incrementUsage(
+			"UPDATE TeamUsage SET StorageBytes = StorageBytes + :Bytes, UpdateAt = :UpdateAt WHERE TeamId = :TeamId",
+			map[string]interface{}{"TeamId": teamId, "Bytes": bytes, "UpdateAt": model.GetMillis()},
+			&model.TeamUsage{TeamId: teamId, Month: model.CurrentUsageMonth(), StorageBytes: bytes, UpdateAt: model.GetMillis()})
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// incrementUsage runs the update, or inserts the initial usage when the team doesn't have any yet. If
+// another server inserted it first, the update is run again.
+func (s SqlTeamQuotaStore) incrementUsage(query string, props map[string]interface{}, initial *model.TeamUsage) *model.AppError {
+	for attempt := 0; attempt < 2; attempt++ {
+		if sqlResult, err := s.GetMaster().Exec(query, props); err != nil {
+			return model.NewAppError("SqlTeamQuotaStore.incrementUsage", "store.sql_team_quota.increment_usage.app_error", nil, "team_id="+initial.TeamId+", "+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := sqlResult.RowsAffected(); count > 0 {
+			return nil
+		}
+
+		if err := s.GetMaster().Insert(initial); err == nil {
+			return nil
+		} else if !IsUniqueConstraintError(err.Error(), []string{"PRIMARY", "teamusage_pkey"}) {
+			return model.NewAppError("SqlTeamQuotaStore.incrementUsage", "store.sql_team_quota.increment_usage.app_error", nil, "team_id="+initial.TeamId+", "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	return model.NewAppError("SqlTeamQuotaStore.incrementUsage", "store.sql_team_quota.increment_usage.app_error", nil, "team_id="+initial.TeamId, http.StatusInternalServerError)
+}
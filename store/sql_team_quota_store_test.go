@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestTeamQuotaStoreQuota(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	if result := <-store.TeamQuota().GetQuota(teamId); result.Err == nil {
+		t.Fatal("shouldn't have a quota yet")
+	}
+
+	Must(store.TeamQuota().SaveQuota(&model.TeamQuota{TeamId: teamId, MaxPostsPerMonth: 100}))
+	Must(store.TeamQuota().SaveQuota(&model.TeamQuota{TeamId: teamId, MaxStorageBytes: 1024}))
+
+	if result := <-store.TeamQuota().GetQuota(teamId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if quota := result.Data.(*model.TeamQuota); quota.MaxPostsPerMonth != 0 || quota.MaxStorageBytes != 1024 {
+		t.Fatal("the quota should have been replaced")
+	}
+
+	if result := <-store.TeamQuota().SaveQuota(&model.TeamQuota{TeamId: teamId, MaxIntegrations: -1}); result.Err == nil {
+		t.Fatal("shouldn't save a negative limit")
+	}
+}
+
+func TestTeamQuotaStoreUsage(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	if usage := (<-store.TeamQuota().GetUsage(teamId)).Data.(*model.TeamUsage); usage.PostCount != 0 || usage.StorageBytes != 0 {
+		t.Fatal("a new team shouldn't have used anything")
+	}
+
+	Must(store.TeamQuota().IncrementPostCount(teamId, "2017-09"))
+	Must(store.TeamQuota().IncrementPostCount(teamId, "2017-09"))
+	Must(store.TeamQuota().IncrementStorageBytes(teamId, 100))
+	Must(store.TeamQuota().IncrementStorageBytes(teamId, 50))
+
+	if usage := (<-store.TeamQuota().GetUsage(teamId)).Data.(*model.TeamUsage); usage.Month != "2017-09" || usage.PostCount != 2 || usage.StorageBytes != 150 {
+		t.Fatal("usage wasn't counted correctly", usage)
+	}
+
+	Must(store.TeamQuota().IncrementPostCount(teamId, "2017-10"))
+
+	if usage := (<-store.TeamQuota().GetUsage(teamId)).Data.(*model.TeamUsage); usage.Month != "2017-10" || usage.PostCount != 1 || usage.StorageBytes != 150 {
+		t.Fatal("the post count should have started over for the new month", usage)
+	}
+
+	Must(store.Webhook().SaveIncoming(&model.IncomingWebhook{ChannelId: model.NewId(), UserId: model.NewId(), TeamId: teamId}))
+	Must(store.Webhook().SaveOutgoing(&model.OutgoingWebhook{ChannelId: model.NewId(), CreatorId: model.NewId(), TeamId: teamId, CallbackURLs: []string{"http://nowhere.com/"}}))
+
+	if count := (<-store.TeamQuota().CountIntegrations(teamId)).Data.(int64); count != 2 {
+		t.Fatal("should have counted both webhooks", count)
+	}
+}
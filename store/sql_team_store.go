@@ -38,6 +38,16 @@ func NewSqlTeamStore(sqlStore *SqlStore) TeamStore {
 		tablem.ColMap("TeamId").SetMaxSize(26)
 		tablem.ColMap("UserId").SetMaxSize(26)
 		tablem.ColMap("Roles").SetMaxSize(64)
+
+		tablej := db.AddTableWithName(model.TeamMembersBatchJob{}, "TeamMembersBatchJobs").SetKeys(false, "Id")
+		tablej.ColMap("Id").SetMaxSize(26)
+		tablej.ColMap("CreatorId").SetMaxSize(26)
+		tablej.ColMap("TeamId").SetMaxSize(26)
+		tablej.ColMap("Action").SetMaxSize(32)
+		tablej.ColMap("Roles").SetMaxSize(64)
+		tablej.ColMap("Status").SetMaxSize(32)
+		tablej.ColMap("Failures").SetMaxSize(model.TEAM_MEMBERS_BATCH_JOB_FAILURES_MAX_SIZE)
+		tablej.ColMap("Error").SetMaxSize(model.TEAM_MEMBERS_BATCH_JOB_ERROR_MAX_LENGTH)
 	}
 
 	return s
@@ -156,6 +166,32 @@ func (s SqlTeamStore) UpdateDisplayName(name string, teamId string) StoreChannel
 	return storeChannel
 }
 
+func (s SqlTeamStore) UpdateName(name string, teamId string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE Teams SET Name = :Name, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"Name": name, "UpdateAt": model.GetMillis(), "Id": teamId}); err != nil {
+			if IsUniqueConstraintError(err.Error(), []string{"Name", "teams_name_key"}) {
+				result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.save.domain_exists.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusBadRequest)
+			} else {
+				result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.update_name.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+			}
+		} else if count, _ := sqlResult.RowsAffected(); count != 1 {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateName", "store.sql_team.update_name.app_error", nil, "team_id="+teamId, http.StatusNotFound)
+		} else {
+			result.Data = teamId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlTeamStore) Get(id string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -822,3 +858,100 @@ func (s SqlTeamStore) RemoveAllMembersByUser(userId string) StoreChannel {
 
 	return storeChannel
 }
+
+func (s SqlTeamStore) SaveMembersBatchJob(job *model.TeamMembersBatchJob) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreSave()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(job); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.SaveMembersBatchJob", "store.sql_team.save_members_batch_job.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamStore) UpdateMembersBatchJob(job *model.TeamMembersBatchJob) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreUpdate()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(job); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateMembersBatchJob", "store.sql_team.update_members_batch_job.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateMembersBatchJob", "store.sql_team.update_members_batch_job.app_error", nil, "id="+job.Id, http.StatusNotFound)
+		} else {
+			result.Data = job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamStore) GetMembersBatchJob(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var job model.TeamMembersBatchJob
+
+		if err := s.GetReplica().SelectOne(&job, "SELECT * FROM TeamMembersBatchJobs WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlTeamStore.GetMembersBatchJob", "store.sql_team.get_members_batch_job.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlTeamStore.GetMembersBatchJob", "store.sql_team.get_members_batch_job.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateMembersRolesForUser replaces the roles of every team membership of the user.
+func (s SqlTeamStore) UpdateMembersRolesForUser(userId string, roles string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE TeamMembers SET Roles = :Roles WHERE UserId = :UserId", map[string]interface{}{"Roles": roles, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.UpdateMembersRolesForUser", "store.sql_team.update_members_roles_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -84,6 +84,43 @@ func TestTeamStoreUpdateDisplayName(t *testing.T) {
 	}
 }
 
+func TestTeamStoreUpdateName(t *testing.T) {
+	Setup()
+
+	o1 := &model.Team{}
+	o1.DisplayName = "Display Name"
+	o1.Name = "z-z-z" + model.NewId() + "b"
+	o1.Email = model.NewId() + "@nowhere.com"
+	o1.Type = model.TEAM_OPEN
+	o1 = (<-store.Team().Save(o1)).Data.(*model.Team)
+
+	o2 := &model.Team{}
+	o2.DisplayName = "Display Name"
+	o2.Name = "z-z-z" + model.NewId() + "b"
+	o2.Email = model.NewId() + "@nowhere.com"
+	o2.Type = model.TEAM_OPEN
+	o2 = (<-store.Team().Save(o2)).Data.(*model.Team)
+
+	newName := "z-z-z" + model.NewId() + "b"
+
+	if err := (<-store.Team().UpdateName(newName, o1.Id)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	ro1 := (<-store.Team().Get(o1.Id)).Data.(*model.Team)
+	if ro1.Name != newName {
+		t.Fatal("Name not updated")
+	}
+
+	if err := (<-store.Team().UpdateName(o2.Name, o1.Id)).Err; err == nil {
+		t.Fatal("shouldn't be able to take the name of another team")
+	}
+
+	if err := (<-store.Team().UpdateName(newName, model.NewId())).Err; err == nil {
+		t.Fatal("shouldn't be able to rename a missing team")
+	}
+}
+
 func TestTeamStoreGet(t *testing.T) {
 	Setup()
 
@@ -779,3 +816,34 @@ func TestGetChannelUnreadsForTeam(t *testing.T) {
 		}
 	}
 }
+
+func TestTeamStoreMembersBatchJob(t *testing.T) {
+	Setup()
+
+	job := &model.TeamMembersBatchJob{CreatorId: model.NewId(), TeamId: model.NewId(), Action: model.TEAM_MEMBERS_BATCH_ACTION_REMOVE, Total: 2}
+	if result := <-store.Team().SaveMembersBatchJob(job); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if job.Status != model.TEAM_MEMBERS_BATCH_JOB_STATUS_CREATED {
+		t.Fatal("should have defaulted the status")
+	}
+
+	userId := model.NewId()
+	job.Status = model.TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED
+	job.Succeeded = 1
+	job.AddFailure(userId, "store.sql_team.get_member.missing.app_error")
+	if result := <-store.Team().UpdateMembersBatchJob(job); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.Team().GetMembersBatchJob(job.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.TeamMembersBatchJob); saved.Status != model.TEAM_MEMBERS_BATCH_JOB_STATUS_FINISHED || saved.Succeeded != 1 || saved.FailedCount != 1 {
+		t.Fatal("should have updated the job")
+	} else if saved.Failures[userId] != "store.sql_team.get_member.missing.app_error" {
+		t.Fatal("should have saved the failures")
+	}
+
+	if result := <-store.Team().GetMembersBatchJob(model.NewId()); result.Err == nil {
+		t.Fatal("should have failed to get a missing job")
+	}
+}
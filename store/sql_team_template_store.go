@@ -0,0 +1,217 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlTeamTemplateStore struct {
+	*SqlStore
+}
+
+func NewSqlTeamTemplateStore(sqlStore *SqlStore) TeamTemplateStore {
+	s := &SqlTeamTemplateStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.TeamTemplate{}, "TeamTemplates").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(model.TEAM_TEMPLATE_NAME_MAX_RUNES * 4)
+		table.ColMap("Description").SetMaxSize(model.TEAM_TEMPLATE_DESCRIPTION_MAX_RUNES * 4)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("SourceTeamId").SetMaxSize(26)
+		table.ColMap("Content").SetMaxSize(model.TEAM_TEMPLATE_CONTENT_MAX_SIZE)
+
+		tablej := db.AddTableWithName(model.TeamCloneJob{}, "TeamCloneJobs").SetKeys(false, "Id")
+		tablej.ColMap("Id").SetMaxSize(26)
+		tablej.ColMap("CreatorId").SetMaxSize(26)
+		tablej.ColMap("SourceTeamId").SetMaxSize(26)
+		tablej.ColMap("TemplateId").SetMaxSize(26)
+		tablej.ColMap("TeamId").SetMaxSize(26)
+		tablej.ColMap("Status").SetMaxSize(32)
+		tablej.ColMap("Error").SetMaxSize(model.TEAM_CLONE_JOB_ERROR_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlTeamTemplateStore) Save(template *model.TeamTemplate) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(template.Id) > 0 {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.Save", "store.sql_team_template.save.existing.app_error", nil, "id="+template.Id, http.StatusBadRequest)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		template.PreSave()
+		if result.Err = template.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(template); err != nil {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.Save", "store.sql_team_template.save.app_error", nil, "id="+template.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = template
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var template model.TeamTemplate
+
+		if err := s.GetReplica().SelectOne(&template, "SELECT * FROM TeamTemplates WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlTeamTemplateStore.Get", "store.sql_team_template.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlTeamTemplateStore.Get", "store.sql_team_template.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &template
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) GetAll(offset, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var templates []*model.TeamTemplate
+
+		if _, err := s.GetReplica().Select(&templates, "SELECT * FROM TeamTemplates ORDER BY Name LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.GetAll", "store.sql_team_template.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = templates
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM TeamTemplates WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.Delete", "store.sql_team_template.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.Delete", "store.sql_team_template.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) SaveCloneJob(job *model.TeamCloneJob) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreSave()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(job); err != nil {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.SaveCloneJob", "store.sql_team_template.save_clone_job.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) UpdateCloneJob(job *model.TeamCloneJob) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		job.PreUpdate()
+		if result.Err = job.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(job); err != nil {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.UpdateCloneJob", "store.sql_team_template.update_clone_job.app_error", nil, "id="+job.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else if count != 1 {
+			result.Err = model.NewAppError("SqlTeamTemplateStore.UpdateCloneJob", "store.sql_team_template.update_clone_job.app_error", nil, "id="+job.Id, http.StatusNotFound)
+		} else {
+			result.Data = job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlTeamTemplateStore) GetCloneJob(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var job model.TeamCloneJob
+
+		if err := s.GetReplica().SelectOne(&job, "SELECT * FROM TeamCloneJobs WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlTeamTemplateStore.GetCloneJob", "store.sql_team_template.get_clone_job.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlTeamTemplateStore.GetCloneJob", "store.sql_team_template.get_clone_job.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &job
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
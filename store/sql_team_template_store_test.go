@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestTeamTemplateStore(t *testing.T) {
+	Setup()
+
+	template := &model.TeamTemplate{
+		Name:         "Support",
+		CreatorId:    model.NewId(),
+		SourceTeamId: model.NewId(),
+		Content: model.TeamTemplateContent{
+			Type:     model.TEAM_OPEN,
+			Channels: []*model.TeamTemplateChannel{{Name: "triage", DisplayName: "Triage", Type: model.CHANNEL_OPEN}},
+			Commands: []*model.TeamTemplateCommand{{Trigger: "ticket", Method: model.COMMAND_METHOD_POST, URL: "http://example.com/ticket"}},
+		},
+	}
+
+	if result := <-store.TeamTemplate().Save(template); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.TeamTemplate().Save(template); result.Err == nil {
+		t.Fatal("shouldn't be able to save an existing template")
+	}
+
+	if result := <-store.TeamTemplate().Get(template.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.TeamTemplate); saved.Name != template.Name || len(saved.Content.Channels) != 1 || saved.Content.Commands[0].Trigger != "ticket" {
+		t.Fatal("should have saved the template content")
+	}
+
+	if result := <-store.TeamTemplate().GetAll(0, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, saved := range result.Data.([]*model.TeamTemplate) {
+			if saved.Id == template.Id {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the template")
+		}
+	}
+
+	job := &model.TeamCloneJob{CreatorId: model.NewId(), TemplateId: template.Id, TeamId: model.NewId()}
+	if result := <-store.TeamTemplate().SaveCloneJob(job); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if job.Status != model.TEAM_CLONE_JOB_STATUS_CREATED {
+		t.Fatal("should have defaulted the status")
+	}
+
+	job.Status = model.TEAM_CLONE_JOB_STATUS_FAILED
+	job.Error = "failed"
+	if result := <-store.TeamTemplate().UpdateCloneJob(job); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.TeamTemplate().GetCloneJob(job.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.TeamCloneJob); saved.Status != model.TEAM_CLONE_JOB_STATUS_FAILED || saved.Error != "failed" {
+		t.Fatal("should have updated the job")
+	}
+
+	if result := <-store.TeamTemplate().Delete(template.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.TeamTemplate().Get(template.Id); result.Err == nil {
+		t.Fatal("should have deleted the template")
+	}
+
+	if result := <-store.TeamTemplate().Delete(template.Id); result.Err == nil {
+		t.Fatal("should have failed to delete a missing template")
+	}
+}
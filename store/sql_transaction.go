@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/go-gorp/gorp"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+const (
+	TRANSACTION_MAX_ATTEMPTS = 3
+	TRANSACTION_RETRY_DELAY  = 10 * time.Millisecond
+	TRANSACTION_RETRY_MAX    = 200 * time.Millisecond
+
+	MYSQL_ERROR_LOCK_WAIT_TIMEOUT = 1205
+	MYSQL_ERROR_DEADLOCK          = 1213
+
+	POSTGRES_ERROR_SERIALIZATION_FAILURE = "40001"
+	POSTGRES_ERROR_DEADLOCK_DETECTED     = "40P01"
+)
+
+// RunInTransaction runs fn in a transaction on the master database, committing it if fn succeeds and
+// rolling it back otherwise. If the transaction fails because it deadlocked or conflicted with another
+// one, the whole transaction is run again after a short delay, so fn must be safe to run more than once
+// and shouldn't keep any state from a previous attempt. The error of the last attempt is returned.
+func (ss *SqlStore) RunInTransaction(fn func(transaction *gorp.Transaction) error) error {
+	var err error
+
+	for attempt := 1; attempt <= TRANSACTION_MAX_ATTEMPTS; attempt++ {
+		if err = ss.runTransaction(fn); err == nil || !IsRetryableTransactionError(err) {
+			return err
+		}
+
+		if attempt < TRANSACTION_MAX_ATTEMPTS {
+			delay := transactionRetryDelay(attempt)
+			l4g.Debug("Retrying transaction in %v after attempt %v failed: %v", delay, attempt, err.Error())
+			time.Sleep(delay)
+		}
+	}
+
+	return err
+}
+
+func (ss *SqlStore) runTransaction(fn func(transaction *gorp.Transaction) error) error {
+	transaction, err := ss.GetMaster().Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(transaction); err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	// don't need to rollback if this fails since the transaction is already closed
+	return transaction.Commit()
+}
+
+// transactionRetryDelay returns how long to wait before retrying a transaction that failed on the
+// given attempt. The delay doubles with each attempt and is jittered so that the transactions that
+// conflicted with each other don't retry at the same time.
+func transactionRetryDelay(attempt int) time.Duration {
+	delay := TRANSACTION_RETRY_DELAY << uint(attempt-1)
+	if delay > TRANSACTION_RETRY_MAX {
+		delay = TRANSACTION_RETRY_MAX
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// IsRetryableTransactionError returns whether an error means that a transaction was aborted because
+// of a deadlock or a conflict with another transaction, in which case it can succeed if run again.
+// Errors that have already been wrapped in an AppError are recognized by their message.
+func IsRetryableTransactionError(err error) bool {
+	switch err := err.(type) {
+	case *mysql.MySQLError:
+		return err.Number == MYSQL_ERROR_DEADLOCK || err.Number == MYSQL_ERROR_LOCK_WAIT_TIMEOUT
+	case *pq.Error:
+		return err.Code == POSTGRES_ERROR_SERIALIZATION_FAILURE || err.Code == POSTGRES_ERROR_DEADLOCK_DETECTED
+	case *model.AppError:
+		return isRetryableTransactionErrorMessage(err.DetailedError)
+	case nil:
+		return false
+	default:
+		return isRetryableTransactionErrorMessage(err.Error())
+	}
+}
+
+func isRetryableTransactionErrorMessage(message string) bool {
+	for _, part := range []string{
+		"Error 1205:",
+		"Error 1213:",
+		"pq: deadlock detected",
+		"pq: could not serialize access",
+	} {
+		if strings.Contains(message, part) {
+			return true
+		}
+	}
+
+	return false
+}
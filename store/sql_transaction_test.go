@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+
+	"github.com/go-gorp/gorp"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestIsRetryableTransactionError(t *testing.T) {
+	for _, err := range []error{
+		&mysql.MySQLError{Number: MYSQL_ERROR_DEADLOCK},
+		&mysql.MySQLError{Number: MYSQL_ERROR_LOCK_WAIT_TIMEOUT},
+		&pq.Error{Code: POSTGRES_ERROR_SERIALIZATION_FAILURE},
+		&pq.Error{Code: POSTGRES_ERROR_DEADLOCK_DETECTED},
+		model.NewLocAppError("TestIsRetryableTransactionError", "id", nil, "Error 1213: Deadlock found when trying to get lock; try restarting transaction"),
+		model.NewLocAppError("TestIsRetryableTransactionError", "id", nil, "pq: could not serialize access due to concurrent update"),
+		errors.New("pq: deadlock detected"),
+	} {
+		if !IsRetryableTransactionError(err) {
+			t.Fatalf("%v should be retryable", err)
+		}
+	}
+
+	for _, err := range []error{
+		nil,
+		&mysql.MySQLError{Number: 1062},
+		&pq.Error{Code: "23505"},
+		model.NewLocAppError("TestIsRetryableTransactionError", "id", nil, "pq: duplicate key value violates unique constraint"),
+		errors.New("sql: no rows in result set"),
+	} {
+		if IsRetryableTransactionError(err) {
+			t.Fatalf("%v shouldn't be retryable", err)
+		}
+	}
+}
+
+func TestTransactionRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := transactionRetryDelay(attempt)
+
+		max := TRANSACTION_RETRY_DELAY << uint(attempt-1)
+		if max > TRANSACTION_RETRY_MAX {
+			max = TRANSACTION_RETRY_MAX
+		}
+
+		if delay < max/2 || delay > max {
+			t.Fatalf("delay %v for attempt %v should be between %v and %v", delay, attempt, max/2, max)
+		}
+	}
+}
+
+func TestRunInTransaction(t *testing.T) {
+	Setup()
+
+	ss := store.(*SqlStore)
+
+	attempts := 0
+	if err := ss.RunInTransaction(func(transaction *gorp.Transaction) error {
+		attempts++
+		if attempts == 1 {
+			return &mysql.MySQLError{Number: MYSQL_ERROR_DEADLOCK}
+		}
+
+		_, err := transaction.SelectInt("SELECT 1")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	} else if attempts != 2 {
+		t.Fatal("should have retried the transaction once")
+	}
+
+	attempts = 0
+	if err := ss.RunInTransaction(func(transaction *gorp.Transaction) error {
+		attempts++
+		return &pq.Error{Code: POSTGRES_ERROR_DEADLOCK_DETECTED}
+	}); err == nil {
+		t.Fatal("should have failed")
+	} else if attempts != TRANSACTION_MAX_ATTEMPTS {
+		t.Fatal("should have stopped retrying after the maximum number of attempts")
+	}
+
+	attempts = 0
+	if err := ss.RunInTransaction(func(transaction *gorp.Transaction) error {
+		attempts++
+		return errors.New("failed")
+	}); err == nil {
+		t.Fatal("should have failed")
+	} else if attempts != 1 {
+		t.Fatal("shouldn't have retried an error that isn't retryable")
+	}
+}
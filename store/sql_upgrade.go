@@ -258,6 +258,58 @@ func UpgradeDatabaseToVersion39(sqlStore *SqlStore) {
 	// TODO: Uncomment following condition when version 3.9.0 is released
 	//if shouldPerformUpgrade(sqlStore, VERSION_3_8_0, VERSION_3_9_0) {
 
+	sqlStore.CreateColumnIfNotExists("OAuthAuthData", "CodeChallenge", "varchar(128)", "varchar(128)", "")
+	sqlStore.CreateColumnIfNotExists("OAuthAuthData", "CodeChallengeMethod", "varchar(16)", "varchar(16)", "")
+
+	sqlStore.AlterColumnTypeIfExists("OAuthAuthData", "Scope", "varchar(1024)", "varchar(1024)")
+	sqlStore.CreateColumnIfNotExists("OAuthApps", "Scopes", "varchar(1024)", "varchar(1024)", "")
+	sqlStore.CreateColumnIfNotExists("OAuthAccessData", "Scopes", "varchar(1024)", "varchar(1024)", "")
+
+	sqlStore.CreateColumnIfNotExists("Channels", "AllowedReactions", "varchar(1024)", "varchar(1024)", "[]")
+	sqlStore.CreateColumnIfNotExists("Channels", "ForbidIntegrationOverrides", "tinyint(1)", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("Channels", "ModerationSettings", "varchar(512)", "varchar(512)", "{}")
+	sqlStore.CreateColumnIfNotExists("Channels", "IsAnnouncement", "tinyint(1)", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("Channels", "AnnouncementPosters", "varchar(3000)", "varchar(3000)", "[]")
+	sqlStore.CreateColumnIfNotExists("Channels", "AutoAddTeamMembers", "tinyint(1)", "boolean", "0")
+	sqlStore.CreateColumnIfNotExists("Reactions", "DeleteAt", "bigint", "bigint", "0")
+
+	// Existing hooks keep posting with any username and icon
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "UsernamePattern", "varchar(128)", "varchar(128)", "")
+	sqlStore.CreateColumnIfNotExists("IncomingWebhooks", "FixedIconURL", "varchar(1024)", "varchar(1024)", "")
+
+	sqlStore.CreateColumnIfNotExists("Emoji", "Category", "varchar(32)", "varchar(32)", "")
+	sqlStore.CreateColumnIfNotExists("Emoji", "Aliases", "varchar(1024)", "varchar(1024)", "[]")
+
+	sqlStore.CreateColumnIfNotExists("Status", "DNDEndTime", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("Users", "MfaBackupCodes", "varchar(1024)", "varchar(1024)", "")
+	sqlStore.CreateColumnIfNotExists("Status", "CustomStatusEmoji", "varchar(64)", "varchar(64)", "")
+	sqlStore.CreateColumnIfNotExists("Status", "CustomStatusText", "varchar(128)", "varchar(128)", "")
+	sqlStore.CreateColumnIfNotExists("Status", "CustomStatusExpiresAt", "bigint", "bigint", "0")
+
+	// Existing files are left with empty content since extracting it would mean reading every file
+	if sqlStore.CreateColumnIfNotExistsNoDefault("FileInfo", "Content", "text", "varchar(65535)") {
+		sqlStore.GetMaster().Exec("UPDATE FileInfo SET Content = '' WHERE Content IS NULL")
+	}
+
+	sqlStore.CreateColumnIfNotExists("UserAccessTokens", "IsActive", "tinyint(1)", "boolean", "1")
+	sqlStore.CreateColumnIfNotExists("UserAccessTokens", "LastUsedAt", "bigint", "bigint", "0")
+
+	sqlStore.CreateColumnIfNotExists("OAuthAuthData", "Nonce", "varchar(128)", "varchar(128)", "")
+	sqlStore.AlterColumnTypeIfExists("Systems", "Value", "varchar(4096)", "varchar(4096)")
+
+	sqlStore.CreateColumnIfNotExists("Compliances", "Format", "varchar(32)", "varchar(32)", "csv")
+
+	sqlStore.CreateColumnIfNotExists("Audits", "EventName", "varchar(64)", "varchar(64)", "")
+	sqlStore.CreateColumnIfNotExists("Audits", "ActorId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("Audits", "TargetType", "varchar(32)", "varchar(32)", "")
+	sqlStore.CreateColumnIfNotExists("Audits", "TargetId", "varchar(26)", "varchar(26)", "")
+	sqlStore.CreateColumnIfNotExists("Audits", "Metadata", "varchar(4096)", "varchar(4096)", "{}")
+
+	sqlStore.CreateColumnIfNotExists("ChannelMembers", "JoinAt", "bigint", "bigint", "0")
+
+	sqlStore.CreateColumnIfNotExists("ScheduledPosts", "ClaimedAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("ScheduledPosts", "Attempts", "int", "integer", "0")
+
 	//	saveSchemaVersion(sqlStore, VERSION_3_9_0)
 	//}
 }
@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestUserAccessTokenStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	t1 := &model.UserAccessToken{Token: model.NewId(), UserId: userId, Description: "first"}
+	t2 := &model.UserAccessToken{Token: model.NewId(), UserId: userId, Description: "second"}
+	Must(store.UserAccessToken().Save(t1))
+	Must(store.UserAccessToken().Save(t2))
+
+	if result := <-store.UserAccessToken().Get(t1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if token := result.Data.(*model.UserAccessToken); token.Token != t1.Token {
+		t.Fatal("should have returned the token")
+	}
+
+	if result := <-store.UserAccessToken().GetByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if tokens := result.Data.([]*model.UserAccessToken); len(tokens) != 2 {
+		t.Fatal("should have returned the tokens of the user")
+	}
+
+	if result := <-store.UserAccessToken().GetByToken(t2.Token); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if token := result.Data.(*model.UserAccessToken); token.Id != t2.Id || !token.IsActive {
+		t.Fatal("should have returned the active token")
+	}
+
+	Must(store.UserAccessToken().UpdateTokenDisable(t2.Id))
+	Must(store.UserAccessToken().UpdateLastUsedAt(t2.Id, 1234))
+
+	if token := Must(store.UserAccessToken().Get(t2.Id)).(*model.UserAccessToken); token.IsActive || token.LastUsedAt != 1234 {
+		t.Fatal("should have disabled the token and updated when it was last used")
+	}
+
+	Must(store.UserAccessToken().UpdateTokenEnable(t2.Id))
+
+	if token := Must(store.UserAccessToken().Get(t2.Id)).(*model.UserAccessToken); !token.IsActive {
+		t.Fatal("should have enabled the token")
+	}
+
+	if result := <-store.UserAccessToken().Delete(t1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UserAccessToken().Get(t1.Id); result.Err == nil {
+		t.Fatal("should have deleted the token")
+	}
+
+	if result := <-store.UserAccessToken().DeleteAllForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UserAccessToken().GetByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if tokens := result.Data.([]*model.UserAccessToken); len(tokens) != 0 {
+		t.Fatal("should have deleted all the tokens of the user")
+	}
+}
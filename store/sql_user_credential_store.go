@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlUserCredentialStore struct {
+	*SqlStore
+}
+
+func NewSqlUserCredentialStore(sqlStore *SqlStore) UserCredentialStore {
+	s := &SqlUserCredentialStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.UserCredential{}, "UserCredentials").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(model.USER_CREDENTIAL_NAME_MAX_RUNES)
+		table.ColMap("CredentialId").SetMaxSize(model.USER_CREDENTIAL_CREDENTIAL_ID_SIZE)
+		table.ColMap("PublicKey").SetMaxSize(model.USER_CREDENTIAL_PUBLIC_KEY_MAX_SIZE)
+	}
+
+	return s
+}
+
+func (s SqlUserCredentialStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_user_credentials_user_id", "UserCredentials", "UserId")
+}
+
+func (s SqlUserCredentialStore) Save(credential *model.UserCredential) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		credential.PreSave()
+		if result.Err = credential.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(credential); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.Save", "store.sql_user_credential.save.app_error", nil, "user_id="+credential.UserId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = credential
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUserCredentialStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var credential model.UserCredential
+		if err := s.GetReplica().SelectOne(&credential, "SELECT * FROM UserCredentials WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			if err == sql.ErrNoRows {
+				result.Err = model.NewAppError("SqlUserCredentialStore.Get", "store.sql_user_credential.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusNotFound)
+			} else {
+				result.Err = model.NewAppError("SqlUserCredentialStore.Get", "store.sql_user_credential.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			}
+		} else {
+			result.Data = &credential
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetByUser returns a user's security keys. It reads from the master since the keys are checked
+// when logging in.
+func (s SqlUserCredentialStore) GetByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var credentials []*model.UserCredential
+		if _, err := s.GetMaster().Select(&credentials, "SELECT * FROM UserCredentials WHERE UserId = :UserId ORDER BY CreateAt", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.GetByUser", "store.sql_user_credential.get_by_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = credentials
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateSignCount records that a security key was used to log in. The sign count is only updated if
+// it's still lower so that the same signature can't be accepted twice by concurrent logins. The
+// result is whether it was updated.
+func (s SqlUserCredentialStore) UpdateSignCount(id string, signCount int64, lastUsedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("UPDATE UserCredentials SET SignCount = :SignCount, LastUsedAt = :LastUsedAt WHERE Id = :Id AND (SignCount < :SignCount OR SignCount = 0)", map[string]interface{}{"SignCount": signCount, "LastUsedAt": lastUsedAt, "Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.UpdateSignCount", "store.sql_user_credential.update_sign_count.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.UpdateSignCount", "store.sql_user_credential.update_sign_count.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUserCredentialStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if res, err := s.GetMaster().Exec("DELETE FROM UserCredentials WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.Delete", "store.sql_user_credential.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		} else if count, _ := res.RowsAffected(); count == 0 {
+			result.Err = model.NewAppError("SqlUserCredentialStore.Delete", "store.sql_user_credential.delete.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUserCredentialStore) DeleteAllForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM UserCredentials WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlUserCredentialStore.DeleteAllForUser", "store.sql_user_credential.delete_all_for_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
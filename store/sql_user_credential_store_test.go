@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestUserCredentialStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	c1 := &model.UserCredential{UserId: userId, Name: "first", CredentialId: model.NewId(), PublicKey: model.NewId()}
+	c2 := &model.UserCredential{UserId: userId, Name: "second", CredentialId: model.NewId(), PublicKey: model.NewId(), SignCount: 5}
+	Must(store.UserCredential().Save(c1))
+	Must(store.UserCredential().Save(c2))
+
+	if result := <-store.UserCredential().Save(&model.UserCredential{UserId: userId}); result.Err == nil {
+		t.Fatal("should have failed to save an invalid credential")
+	}
+
+	if result := <-store.UserCredential().Get(c1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if credential := result.Data.(*model.UserCredential); credential.CredentialId != c1.CredentialId || credential.PublicKey != c1.PublicKey {
+		t.Fatal("should have returned the credential")
+	}
+
+	if result := <-store.UserCredential().GetByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if credentials := result.Data.([]*model.UserCredential); len(credentials) != 2 {
+		t.Fatal("should have returned the credentials of the user")
+	}
+
+	if updated := Must(store.UserCredential().UpdateSignCount(c2.Id, 6, 1234)).(bool); !updated {
+		t.Fatal("should have updated the sign count")
+	}
+
+	if updated := Must(store.UserCredential().UpdateSignCount(c2.Id, 6, 5678)).(bool); updated {
+		t.Fatal("shouldn't have updated the sign count without it going up")
+	}
+
+	if credential := Must(store.UserCredential().Get(c2.Id)).(*model.UserCredential); credential.SignCount != 6 || credential.LastUsedAt != 1234 {
+		t.Fatal("should have updated the sign count and when it was last used")
+	}
+
+	if result := <-store.UserCredential().Delete(c1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UserCredential().Delete(c1.Id); result.Err == nil {
+		t.Fatal("should have failed to delete a missing credential")
+	}
+
+	if result := <-store.UserCredential().DeleteAllForUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UserCredential().GetByUser(userId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if credentials := result.Data.([]*model.UserCredential); len(credentials) != 0 {
+		t.Fatal("should have deleted all the credentials of the user")
+	}
+}
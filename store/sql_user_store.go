@@ -36,8 +36,8 @@ type SqlUserStore struct {
 	*SqlStore
 }
 
-var profilesInChannelCache *utils.Cache = utils.NewLru(PROFILES_IN_CHANNEL_CACHE_SIZE)
-var profileByIdsCache *utils.Cache = utils.NewLru(PROFILE_BY_IDS_CACHE_SIZE)
+var profilesInChannelCache utils.ObjectCache = utils.NewLru(PROFILES_IN_CHANNEL_CACHE_SIZE)
+var profileByIdsCache utils.ObjectCache = utils.NewLru(PROFILE_BY_IDS_CACHE_SIZE)
 
 func ClearUserCaches() {
 	profilesInChannelCache.Purge()
@@ -51,6 +51,9 @@ func (us SqlUserStore) InvalidatProfileCacheForUser(userId string) {
 func NewSqlUserStore(sqlStore *SqlStore) UserStore {
 	us := &SqlUserStore{sqlStore}
 
+	profilesInChannelCache = utils.NewObjectCache("profiles_in_channel", PROFILES_IN_CHANNEL_CACHE_SIZE, map[string]*model.User{})
+	profileByIdsCache = utils.NewObjectCache("profile_by_ids", PROFILE_BY_IDS_CACHE_SIZE, &model.User{})
+
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.User{}, "Users").SetKeys(false, "Id")
 		table.ColMap("Id").SetMaxSize(26)
@@ -67,6 +70,7 @@ func NewSqlUserStore(sqlStore *SqlStore) UserStore {
 		table.ColMap("NotifyProps").SetMaxSize(2000)
 		table.ColMap("Locale").SetMaxSize(5)
 		table.ColMap("MfaSecret").SetMaxSize(128)
+		table.ColMap("MfaBackupCodes").SetMaxSize(1024)
 		table.ColMap("Position").SetMaxSize(64)
 	}
 
@@ -155,6 +159,7 @@ func (us SqlUserStore) Update(user *model.User, trustedUpdateData bool) StoreCha
 			user.FailedAttempts = oldUser.FailedAttempts
 			user.MfaSecret = oldUser.MfaSecret
 			user.MfaActive = oldUser.MfaActive
+			user.MfaBackupCodes = oldUser.MfaBackupCodes
 
 			if !trustedUpdateData {
 				user.Roles = oldUser.Roles
@@ -313,7 +318,7 @@ func (us SqlUserStore) UpdateAuthData(userId string, service string, authData *s
 		}
 
 		if resetMfa {
-			query += ", MfaActive = false, MfaSecret = ''"
+			query += ", MfaActive = false, MfaSecret = '', MfaBackupCodes = ''"
 		}
 
 		query += " WHERE Id = :UserId"
@@ -379,6 +384,32 @@ func (us SqlUserStore) UpdateMfaActive(userId string, active bool) StoreChannel
 	return storeChannel
 }
 
+// UpdateMfaBackupCodes replaces a user's backup codes as long as they're still previousCodes so that
+// the same code can't be used twice by concurrent logins. The result is whether they were replaced.
+func (us SqlUserStore) UpdateMfaBackupCodes(userId, codes, previousCodes string) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		updateAt := model.GetMillis()
+
+		if sqlResult, err := us.GetMaster().Exec("UPDATE Users SET MfaBackupCodes = :Codes, UpdateAt = :UpdateAt WHERE Id = :UserId AND MfaBackupCodes = :PreviousCodes", map[string]interface{}{"Codes": codes, "UpdateAt": updateAt, "UserId": userId, "PreviousCodes": previousCodes}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.UpdateMfaBackupCodes", "store.sql_user.update_mfa_backup_codes.app_error", nil, "id="+userId+", "+err.Error())
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.UpdateMfaBackupCodes", "store.sql_user.update_mfa_backup_codes.app_error", nil, "id="+userId+", "+err.Error())
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlUserStore) Get(id string) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
@@ -571,6 +602,66 @@ func (us SqlUserStore) GetProfilesInChannel(channelId string, offset int, limit
 	return storeChannel
 }
 
+// GetProfilesSharingChannels returns the users who are members of at least one of the channels that
+// the given user is a member of, optionally only counting the channels of a team.
+func (us SqlUserStore) GetProfilesSharingChannels(userId string, teamId string, offset int, limit int) StoreChannel {
+
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var users []*model.User
+
+		query := `
+			SELECT
+				Users.*
+			FROM
+				Users
+			WHERE
+				Users.DeleteAt = 0
+				AND Users.Id IN (
+					SELECT
+						Members.UserId
+					FROM
+						ChannelMembers AS Members,
+						ChannelMembers AS UserMembers,
+						Channels
+					WHERE
+						UserMembers.UserId = :UserId
+						AND Members.ChannelId = UserMembers.ChannelId
+						AND Channels.Id = UserMembers.ChannelId
+						AND Channels.DeleteAt = 0
+						TEAM_FILTER)
+			ORDER BY Users.Username ASC
+			LIMIT :Limit OFFSET :Offset`
+
+		if len(teamId) > 0 {
+			query = strings.Replace(query, "TEAM_FILTER", "AND Channels.TeamId = :TeamId", 1)
+		} else {
+			query = strings.Replace(query, "TEAM_FILTER", "", 1)
+		}
+
+		if _, err := us.GetReplica().Select(&users, query, map[string]interface{}{"UserId": userId, "TeamId": teamId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlUserStore.GetProfilesSharingChannels", "store.sql_user.get_profiles.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+
+			for _, u := range users {
+				u.Password = ""
+				u.AuthData = new(string)
+				*u.AuthData = ""
+			}
+
+			result.Data = users
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlUserStore) GetAllProfilesInChannel(channelId string, allowFromCache bool) StoreChannel {
 
 	storeChannel := make(StoreChannel)
@@ -1384,6 +1475,39 @@ func (us SqlUserStore) SearchInChannel(channelId string, term string, options ma
 	return storeChannel
 }
 
+// SearchSharingChannels searches the users who are members of at least one of the channels that the given user is a member of.
+func (us SqlUserStore) SearchSharingChannels(userId string, term string, options map[string]bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		searchQuery := `
+			SELECT
+				Users.*
+			FROM
+				Users
+			WHERE
+				Users.Id IN (
+					SELECT
+						Members.UserId
+					FROM
+						ChannelMembers AS Members,
+						ChannelMembers AS UserMembers
+					WHERE
+						UserMembers.UserId = :UserId
+						AND Members.ChannelId = UserMembers.ChannelId)
+				SEARCH_CLAUSE
+				INACTIVE_CLAUSE
+				ORDER BY Users.Username ASC
+			LIMIT 100`
+
+		storeChannel <- us.performSearch(searchQuery, term, options, map[string]interface{}{"UserId": userId})
+		close(storeChannel)
+
+	}()
+
+	return storeChannel
+}
+
 var specialUserSearchChar = []string{
 	"<",
 	">",
@@ -1608,3 +1732,119 @@ func (us SqlUserStore) GetEtagForProfilesNotInTeam(teamId string) StoreChannel {
 
 	return storeChannel
 }
+
+// GetUsersBatchForIndexing returns the users updated since startTime, oldest first, along with the
+// teams they belong to.
+func (us SqlUserStore) GetUsersBatchForIndexing(startTime int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var users []*model.User
+		if _, err := us.GetReplica().Select(&users, "SELECT * FROM Users WHERE UpdateAt >= :StartTime ORDER BY UpdateAt ASC LIMIT :Limit", map[string]interface{}{"StartTime": startTime, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlUserStore.GetUsersBatchForIndexing", "store.sql_user.get_users_batch_for_indexing.app_error", nil, err.Error(), http.StatusInternalServerError)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, user := range users {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["userId"+strconv.Itoa(index)] = user.Id
+			idQuery += ":userId" + strconv.Itoa(index)
+		}
+
+		var members []*model.TeamMember
+		if len(users) > 0 {
+			if _, err := us.GetReplica().Select(&members, "SELECT * FROM TeamMembers WHERE UserId IN ("+idQuery+") AND DeleteAt = 0", props); err != nil {
+				result.Err = model.NewAppError("SqlUserStore.GetUsersBatchForIndexing", "store.sql_user.get_users_batch_for_indexing.app_error", nil, err.Error(), http.StatusInternalServerError)
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		teamIds := make(map[string][]string)
+		for _, member := range members {
+			teamIds[member.UserId] = append(teamIds[member.UserId], member.TeamId)
+		}
+
+		batch := make([]*model.UserForIndexing, 0, len(users))
+		for _, user := range users {
+			user.Sanitize(map[string]bool{})
+			batch = append(batch, &model.UserForIndexing{User: *user, TeamIds: teamIds[user.Id]})
+		}
+
+		result.Data = batch
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// inactiveUsersQuery selects the users who haven't been active since a time, using the last activity
+// of their status so that posts aren't scanned. Users created after that time and bot accounts are
+// never reported.
+const inactiveUsersQuery = `
+	FROM
+		Users
+		LEFT JOIN Status ON Status.UserId = Users.Id
+	WHERE
+		Users.DeleteAt = 0
+		AND Users.CreateAt < :Since
+		AND (Status.LastActivityAt IS NULL OR Status.LastActivityAt < :Since)
+		AND NOT EXISTS (SELECT 1 FROM Bots WHERE Bots.UserId = Users.Id)`
+
+func (us SqlUserStore) GetInactiveUsers(since int64, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var users []*model.InactiveUser
+		if _, err := us.GetReplica().Select(&users,
+			`SELECT
+				Users.Id, Users.Username, Users.Email, Users.FirstName, Users.LastName, Users.CreateAt,
+				COALESCE(Status.LastActivityAt, 0) AS LastActivityAt`+inactiveUsersQuery+`
+			ORDER BY LastActivityAt ASC, Users.Username ASC
+			LIMIT :Limit OFFSET :Offset`,
+			map[string]interface{}{"Since": since, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.GetInactiveUsers", "store.sql_user.get_inactive_users.app_error", nil, err.Error())
+		} else {
+			result.Data = users
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (us SqlUserStore) GetInactiveUsersCount(since int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := us.GetReplica().SelectInt("SELECT COUNT(Users.Id)"+inactiveUsersQuery, map[string]interface{}{"Since": since}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.GetInactiveUsersCount", "store.sql_user.get_inactive_users_count.app_error", nil, err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -366,6 +366,67 @@ func TestUserStoreGetProfiles(t *testing.T) {
 	}
 }
 
+func TestUserStoreGetProfilesSharingChannels(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(u1))
+
+	u2 := &model.User{}
+	u2.Email = model.NewId()
+	Must(store.User().Save(u2))
+
+	u3 := &model.User{}
+	u3.Email = model.NewId()
+	Must(store.User().Save(u3))
+
+	c1 := model.Channel{}
+	c1.TeamId = teamId
+	c1.DisplayName = "Profiles sharing channels"
+	c1.Name = "profiles-" + model.NewId()
+	c1.Type = model.CHANNEL_OPEN
+	Must(store.Channel().Save(&c1))
+
+	c2 := model.Channel{}
+	c2.TeamId = model.NewId()
+	c2.DisplayName = "Profiles sharing channels"
+	c2.Name = "profiles-" + model.NewId()
+	c2.Type = model.CHANNEL_OPEN
+	Must(store.Channel().Save(&c2))
+
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c1.Id, UserId: u1.Id, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c1.Id, UserId: u2.Id, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c2.Id, UserId: u1.Id, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c2.Id, UserId: u3.Id, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	if r1 := <-store.User().GetProfilesSharingChannels(u2.Id, "", 0, 100); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if users := r1.Data.([]*model.User); len(users) != 2 {
+		t.Fatal("should have returned the users sharing a channel")
+	} else {
+		for _, user := range users {
+			if user.Id == u3.Id {
+				t.Fatal("should not have returned a user not sharing a channel")
+			}
+		}
+	}
+
+	if r1 := <-store.User().GetProfilesSharingChannels(u1.Id, teamId, 0, 100); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if users := r1.Data.([]*model.User); len(users) != 2 {
+		t.Fatal("should have only returned the users sharing a channel in the team")
+	}
+
+	if r1 := <-store.User().GetProfilesSharingChannels(u1.Id, "", 0, 100); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if users := r1.Data.([]*model.User); len(users) != 3 {
+		t.Fatal("should have returned the users sharing a channel in any team")
+	}
+}
+
 func TestUserStoreGetProfilesInChannel(t *testing.T) {
 	Setup()
 
@@ -1239,6 +1300,36 @@ func TestUserStoreUpdateMfaActive(t *testing.T) {
 	}
 }
 
+func TestUserStoreUpdateMfaBackupCodes(t *testing.T) {
+	Setup()
+
+	u1 := model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(&u1))
+
+	if result := <-store.User().UpdateMfaBackupCodes(u1.Id, "hash1 hash2", ""); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !result.Data.(bool) {
+		t.Fatal("should have updated the codes")
+	}
+
+	if result := <-store.User().UpdateMfaBackupCodes(u1.Id, "hash2", ""); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(bool) {
+		t.Fatal("should not update codes that have changed since")
+	}
+
+	if result := <-store.User().UpdateMfaBackupCodes(u1.Id, "hash2", "hash1 hash2"); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !result.Data.(bool) {
+		t.Fatal("should have updated the codes")
+	}
+
+	if user := Must(store.User().Get(u1.Id)).(*model.User); user.MfaBackupCodes != "hash2" {
+		t.Fatal("codes should have been saved", user.MfaBackupCodes)
+	}
+}
+
 func TestUserStoreGetRecentlyActiveUsersForTeam(t *testing.T) {
 	Setup()
 
@@ -1922,3 +2013,60 @@ func TestUserStoreGetProfilesNotInTeam(t *testing.T) {
 		}
 	}
 }
+
+func TestUserStoreGetInactiveUsers(t *testing.T) {
+	Setup()
+
+	since := model.GetMillis() + 60*1000
+
+	var countBefore int64
+	if result := <-store.User().GetInactiveUsersCount(since); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		countBefore = result.Data.(int64)
+	}
+
+	u1 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "a" + model.NewId()})).(*model.User)
+	Must(store.Status().SaveOrUpdate(&model.Status{UserId: u1.Id, Status: model.STATUS_OFFLINE, LastActivityAt: since - 1}))
+
+	u2 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "a" + model.NewId()})).(*model.User)
+	Must(store.Status().SaveOrUpdate(&model.Status{UserId: u2.Id, Status: model.STATUS_ONLINE, LastActivityAt: since + 1}))
+
+	u3 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "a" + model.NewId()})).(*model.User)
+	Must(store.Bot().Save(&model.Bot{UserId: u3.Id, Username: u3.Username, OwnerId: u1.Id}))
+
+	u4 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: "a" + model.NewId(), DeleteAt: model.GetMillis()})).(*model.User)
+
+	if result := <-store.User().GetInactiveUsersCount(since); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if count := result.Data.(int64); count != countBefore+1 {
+		t.Fatal("should have counted only the inactive user", countBefore, count)
+	}
+
+	if result := <-store.User().GetInactiveUsers(since, 0, 10000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else {
+		found := false
+		for _, user := range result.Data.([]*model.InactiveUser) {
+			if user.Id == u1.Id {
+				found = true
+
+				if user.LastActivityAt != since-1 || user.Username != u1.Username {
+					t.Fatal("should have returned the last activity of the user")
+				}
+			} else if user.Id == u2.Id || user.Id == u3.Id || user.Id == u4.Id {
+				t.Fatal("should only have returned inactive users")
+			}
+		}
+
+		if !found {
+			t.Fatal("should have returned the inactive user")
+		}
+	}
+
+	if result := <-store.User().GetInactiveUsers(since, 0, 1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if users := result.Data.([]*model.InactiveUser); len(users) != 1 {
+		t.Fatal("should have limited the number of users")
+	}
+}
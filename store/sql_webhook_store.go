@@ -22,7 +22,7 @@ const (
 	WEBHOOK_CACHE_SEC  = 900 // 15 minutes
 )
 
-var webhookCache = utils.NewLru(WEBHOOK_CACHE_SIZE)
+var webhookCache = utils.NewNamedLru("webhook", WEBHOOK_CACHE_SIZE)
 
 func ClearWebhookCaches() {
 	webhookCache.Purge()
@@ -39,6 +39,8 @@ func NewSqlWebhookStore(sqlStore *SqlStore) WebhookStore {
 		table.ColMap("TeamId").SetMaxSize(26)
 		table.ColMap("DisplayName").SetMaxSize(64)
 		table.ColMap("Description").SetMaxSize(128)
+		table.ColMap("UsernamePattern").SetMaxSize(model.INCOMING_WEBHOOK_USERNAME_PATTERN_MAX_LENGTH)
+		table.ColMap("FixedIconURL").SetMaxSize(model.INCOMING_WEBHOOK_FIXED_ICON_URL_MAX_LENGTH)
 
 		tableo := db.AddTableWithName(model.OutgoingWebhook{}, "OutgoingWebhooks").SetKeys(false, "Id")
 		tableo.ColMap("Id").SetMaxSize(26)
@@ -4,6 +4,7 @@
 package store
 
 import (
+	"context"
 	"time"
 
 	l4g "github.com/alecthomas/log4go"
@@ -47,9 +48,38 @@ type Store interface {
 	Status() StatusStore
 	FileInfo() FileInfoStore
 	Reaction() ReactionStore
+	Alertmanager() AlertmanagerStore
+	Incident() IncidentStore
+	FeatureFlag() FeatureFlagStore
+	Experiment() ExperimentStore
+	TeamTemplate() TeamTemplateStore
+	ScheduledPost() ScheduledPostStore
+	EmojiUsage() EmojiUsageStore
+	Draft() DraftStore
+	Device() DeviceStore
+	ChannelMemberRead() ChannelMemberReadStore
+	Bot() BotStore
+	UserAccessToken() UserAccessTokenStore
+	UserCredential() UserCredentialStore
+	PostIntegrity() PostIntegrityStore
+	LdapGroup() LdapGroupStore
+	Invitation() InvitationStore
+	ArchiveExport() ArchiveExportStore
+	PostEventHook() PostEventHookStore
+	BulkEmail() BulkEmailStore
+	RetentionPolicy() RetentionPolicyStore
+	AutoResponder() AutoResponderStore
+	LegalHold() LegalHoldStore
+	NameRedirect() NameRedirectStore
+	TeamQuota() TeamQuotaStore
+	RemoteCluster() RemoteClusterStore
+	SharedChannel() SharedChannelStore
+	ChannelAutoArchive() ChannelAutoArchiveStore
 	MarkSystemRanUnitTests()
 	Close()
 	DropAllTables()
+	GetSlowQueries() []*model.SlowQuery
+	ClearSlowQueries()
 	TotalMasterDbConnections() int
 	TotalReadDbConnections() int
 }
@@ -58,6 +88,7 @@ type TeamStore interface {
 	Save(team *model.Team) StoreChannel
 	Update(team *model.Team) StoreChannel
 	UpdateDisplayName(name string, teamId string) StoreChannel
+	UpdateName(name string, teamId string) StoreChannel
 	Get(id string) StoreChannel
 	GetByName(name string) StoreChannel
 	SearchByName(name string) StoreChannel
@@ -84,6 +115,10 @@ type TeamStore interface {
 	RemoveMember(teamId string, userId string) StoreChannel
 	RemoveAllMembersByTeam(teamId string) StoreChannel
 	RemoveAllMembersByUser(userId string) StoreChannel
+	UpdateMembersRolesForUser(userId string, roles string) StoreChannel
+	SaveMembersBatchJob(job *model.TeamMembersBatchJob) StoreChannel
+	UpdateMembersBatchJob(job *model.TeamMembersBatchJob) StoreChannel
+	GetMembersBatchJob(id string) StoreChannel
 }
 
 type ChannelStore interface {
@@ -108,9 +143,17 @@ type ChannelStore interface {
 	GetPublicChannelsByIdsForTeam(teamId string, channelIds []string) StoreChannel
 	GetChannelCounts(teamId string, userId string) StoreChannel
 	GetTeamChannels(teamId string) StoreChannel
+	GetAutoAddChannelsForTeam(teamId string) StoreChannel
+	SaveSidebarCategory(category *model.SidebarCategoryWithChannels) StoreChannel
+	GetSidebarCategory(categoryId string) StoreChannel
+	GetSidebarCategories(userId string, teamId string) StoreChannel
+	UpdateSidebarCategory(category *model.SidebarCategoryWithChannels) StoreChannel
+	UpdateSidebarCategoryOrder(userId string, teamId string, categoryOrder []string) StoreChannel
+	DeleteSidebarCategory(categoryId string) StoreChannel
 	GetAll(teamId string) StoreChannel
 	GetForPost(postId string) StoreChannel
 	SaveMember(member *model.ChannelMember) StoreChannel
+	SaveMembers(channelId string, members []*model.ChannelMember) StoreChannel
 	UpdateMember(member *model.ChannelMember) StoreChannel
 	GetMembers(channelId string, offset, limit int) StoreChannel
 	GetMember(channelId string, userId string) StoreChannel
@@ -125,6 +168,7 @@ type ChannelStore interface {
 	GetMemberCount(channelId string, allowFromCache bool) StoreChannel
 	GetPinnedPosts(channelId string) StoreChannel
 	RemoveMember(channelId string, userId string) StoreChannel
+	RemoveMembers(channelId string, userIds []string) StoreChannel
 	PermanentDeleteMembersByUser(userId string) StoreChannel
 	PermanentDeleteMembersByChannel(channelId string) StoreChannel
 	UpdateLastViewedAt(channelIds []string, userId string) StoreChannel
@@ -137,17 +181,32 @@ type ChannelStore interface {
 	GetMembersByIds(channelId string, userIds []string) StoreChannel
 	AnalyticsDeletedTypeCount(teamId string, channelType string) StoreChannel
 	GetChannelUnread(channelId, userId string) StoreChannel
+	GetChannelLastRead(channelId, userId string) StoreChannel
+	GetActiveSince(since int64, limit int) StoreChannel
+	UpdateMembersRolesForUser(userId string, roles string) StoreChannel
+	GetInactiveChannels(since int64, offset int, limit int) StoreChannel
+	GetInactiveChannelsCount(since int64) StoreChannel
+	GetAdminCount(channelId string) StoreChannel
+	GetAdminIds(channelId string) StoreChannel
+	GetLongestTenuredMember(channelId string) StoreChannel
+	GetOrphanedChannels(offset int, limit int) StoreChannel
+	GetOrphanedChannelsCount() StoreChannel
 }
 
 type PostStore interface {
 	Save(post *model.Post) StoreChannel
 	Update(newPost *model.Post, oldPost *model.Post) StoreChannel
 	Get(id string) StoreChannel
+	GetContext(ctx context.Context, id string) StoreChannel
 	GetSingle(id string) StoreChannel
+	GetSingleContext(ctx context.Context, id string) StoreChannel
 	Delete(postId string, time int64) StoreChannel
+	Restore(postId string, time int64) StoreChannel
 	PermanentDeleteByUser(userId string) StoreChannel
 	PermanentDeleteByChannel(channelId string) StoreChannel
+	PermanentDeleteBatch(postIds []string) StoreChannel
 	GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel
+	GetPostsContext(ctx context.Context, channelId string, offset int, limit int, allowFromCache bool) StoreChannel
 	GetFlaggedPosts(userId string, offset int, limit int) StoreChannel
 	GetFlaggedPostsForTeam(userId, teamId string, offset int, limit int) StoreChannel
 	GetFlaggedPostsForChannel(userId, channelId string, offset int, limit int) StoreChannel
@@ -162,6 +221,22 @@ type PostStore interface {
 	InvalidateLastPostTimeCache(channelId string)
 	GetPostsCreatedAt(channelId string, time int64) StoreChannel
 	Overwrite(post *model.Post) StoreChannel
+	GetPostHistory(postId string) StoreChannel
+	SaveThreadMembership(membership *model.ThreadMembership) StoreChannel
+	SaveThreadMembershipIfNotExists(membership *model.ThreadMembership) StoreChannel
+	GetThreadMembership(postId, userId string) StoreChannel
+	GetThreadFollowers(postId string) StoreChannel
+	IncrementThreadMentionCount(postId, userId string) StoreChannel
+	UpdateThreadLastViewedAt(postId, userId string, lastViewedAt int64) StoreChannel
+	GetUnreadThreadsForUser(userId, teamId string) StoreChannel
+	GetPostsByIds(postIds []string) StoreChannel
+	GetPostsBatchForIndexing(startTime int64, limit int) StoreChannel
+	SaveCrossPosts(posts []*model.Post) StoreChannel
+	GetCrossPosts(crossPostId string) StoreChannel
+	GetChannelSnapshot(channelId string, asOf int64) StoreChannel
+	GetPostsByIdsIncludeDeleted(postIds []string) StoreChannel
+	GetPostsForArchive(channelId string, afterCreateAt int64, afterId string, limit int) StoreChannel
+	GetChannelIdsForPosts(postIds []string) StoreChannel
 }
 
 type UserStore interface {
@@ -173,12 +248,14 @@ type UserStore interface {
 	UpdateAuthData(userId string, service string, authData *string, email string, resetMfa bool) StoreChannel
 	UpdateMfaSecret(userId, secret string) StoreChannel
 	UpdateMfaActive(userId string, active bool) StoreChannel
+	UpdateMfaBackupCodes(userId, codes, previousCodes string) StoreChannel
 	Get(id string) StoreChannel
 	GetAll() StoreChannel
 	InvalidateProfilesInChannelCacheByUser(userId string)
 	InvalidateProfilesInChannelCache(channelId string)
 	GetProfilesInChannel(channelId string, offset int, limit int) StoreChannel
 	GetAllProfilesInChannel(channelId string, allowFromCache bool) StoreChannel
+	GetProfilesSharingChannels(userId string, teamId string, offset int, limit int) StoreChannel
 	GetProfilesNotInChannel(teamId string, channelId string, offset int, limit int) StoreChannel
 	GetProfilesWithoutTeam(offset int, limit int) StoreChannel
 	GetProfilesByUsernames(usernames []string, teamId string) StoreChannel
@@ -206,12 +283,16 @@ type UserStore interface {
 	Search(teamId string, term string, options map[string]bool) StoreChannel
 	SearchNotInTeam(notInTeamId string, term string, options map[string]bool) StoreChannel
 	SearchInChannel(channelId string, term string, options map[string]bool) StoreChannel
+	SearchSharingChannels(userId string, term string, options map[string]bool) StoreChannel
 	SearchNotInChannel(teamId string, channelId string, term string, options map[string]bool) StoreChannel
 	SearchWithoutTeam(term string, options map[string]bool) StoreChannel
 	AnalyticsGetInactiveUsersCount() StoreChannel
 	AnalyticsGetSystemAdminCount() StoreChannel
 	GetProfilesNotInTeam(teamId string, offset int, limit int) StoreChannel
 	GetEtagForProfilesNotInTeam(teamId string) StoreChannel
+	GetUsersBatchForIndexing(startTime int64, limit int) StoreChannel
+	GetInactiveUsers(since int64, offset int, limit int) StoreChannel
+	GetInactiveUsersCount(since int64) StoreChannel
 }
 
 type SessionStore interface {
@@ -225,12 +306,14 @@ type SessionStore interface {
 	UpdateLastActivityAt(sessionId string, time int64) StoreChannel
 	UpdateRoles(userId string, roles string) StoreChannel
 	UpdateDeviceId(id string, deviceId string, expiresAt int64) StoreChannel
+	UpdateProps(session *model.Session) StoreChannel
 	AnalyticsSessionCount() StoreChannel
 }
 
 type AuditStore interface {
 	Save(audit *model.Audit) StoreChannel
 	Get(user_id string, offset int, limit int) StoreChannel
+	Search(filter *model.AuditFilter, offset int, limit int) StoreChannel
 	PermanentDeleteByUser(userId string) StoreChannel
 }
 
@@ -260,7 +343,9 @@ type OAuthStore interface {
 	GetAccessDataByUserForApp(userId, clientId string) StoreChannel
 	GetAccessDataByRefreshToken(token string) StoreChannel
 	GetPreviousAccessData(userId, clientId string) StoreChannel
+	RotateRefreshToken(accessData *model.AccessData, previousRefreshToken string) StoreChannel
 	RemoveAccessData(token string) StoreChannel
+	RemoveAccessDataByRefreshToken(token string) StoreChannel
 }
 
 type SystemStore interface {
@@ -331,9 +416,11 @@ type PasswordRecoveryStore interface {
 
 type EmojiStore interface {
 	Save(emoji *model.Emoji) StoreChannel
+	Update(emoji *model.Emoji) StoreChannel
 	Get(id string, allowFromCache bool) StoreChannel
 	GetByName(name string) StoreChannel
 	GetAll() StoreChannel
+	Search(term string, prefixOnly bool, limit int) StoreChannel
 	Delete(id string, time int64) StoreChannel
 }
 
@@ -347,6 +434,7 @@ type StatusStore interface {
 	ResetAll() StoreChannel
 	GetTotalActiveUsersCount() StoreChannel
 	UpdateLastActivityAt(userId string, lastActivityAt int64) StoreChannel
+	GetExpired(time int64, limit int) StoreChannel
 }
 
 type FileInfoStore interface {
@@ -357,13 +445,281 @@ type FileInfoStore interface {
 	InvalidateFileInfosForPostCache(postId string)
 	AttachToPost(fileId string, postId string) StoreChannel
 	DeleteForPost(postId string) StoreChannel
+	RestoreForPost(postId string) StoreChannel
+	GetForPosts(postIds []string) StoreChannel
+	PermanentDeleteBatch(fileIds []string) StoreChannel
+	GetFilesBatchForIndexing(startTime int64, limit int) StoreChannel
+	Search(teamId string, userId string, params *model.SearchParams) StoreChannel
 }
 
 type ReactionStore interface {
 	Save(reaction *model.Reaction) StoreChannel
+	SaveMultiple(reactions []*model.Reaction) StoreChannel
 	Delete(reaction *model.Reaction) StoreChannel
 	InvalidateCacheForPost(postId string)
 	InvalidateCache()
 	GetForPost(postId string, allowFromCache bool) StoreChannel
+	GetForPostContext(ctx context.Context, postId string, allowFromCache bool) StoreChannel
+	GetForPosts(postIds []string) StoreChannel
+	GetForPostsContext(ctx context.Context, postIds []string) StoreChannel
+	GetTopReactionsForTeam(teamId string, since int64, limit int) StoreChannel
+	AnalyticsReactionCountsByDay(teamId string) StoreChannel
+	AnalyticsReactionCounts(teamId string) StoreChannel
 	DeleteAllWithEmojiName(emojiName string) StoreChannel
+	IncrementEmojiMessageCounts(emojiNames []string, time int64) StoreChannel
+	GetEmojiStats(emojiNames []string) StoreChannel
+}
+
+type AlertmanagerStore interface {
+	SaveReceiver(receiver *model.AlertmanagerReceiver) StoreChannel
+	UpdateReceiver(receiver *model.AlertmanagerReceiver) StoreChannel
+	GetReceiver(id string) StoreChannel
+	GetReceiversByTeam(teamId string, offset, limit int) StoreChannel
+	DeleteReceiver(id string, time int64) StoreChannel
+	SavePost(alertPost *model.AlertmanagerPost) StoreChannel
+	GetPost(receiverId string, groupKey string) StoreChannel
+}
+
+type IncidentStore interface {
+	Save(incident *model.Incident) StoreChannel
+	Update(incident *model.Incident) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset, limit int) StoreChannel
+	GetForFeed(resolvedSince int64) StoreChannel
+}
+
+type FeatureFlagStore interface {
+	Save(flag *model.FeatureFlag) StoreChannel
+	Get(name string) StoreChannel
+	GetAll() StoreChannel
+	Delete(name string) StoreChannel
+}
+
+type ExperimentStore interface {
+	Save(experiment *model.Experiment) StoreChannel
+	Get(name string) StoreChannel
+	GetAll() StoreChannel
+	Delete(name string) StoreChannel
+	SaveExposure(exposure *model.ExperimentExposure) StoreChannel
+	GetSummary(name string) StoreChannel
+}
+
+type TeamTemplateStore interface {
+	Save(template *model.TeamTemplate) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset, limit int) StoreChannel
+	Delete(id string) StoreChannel
+	SaveCloneJob(job *model.TeamCloneJob) StoreChannel
+	UpdateCloneJob(job *model.TeamCloneJob) StoreChannel
+	GetCloneJob(id string) StoreChannel
+}
+
+type ScheduledPostStore interface {
+	Save(scheduledPost *model.ScheduledPost) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	GetDue(before int64, claimedBefore int64, limit int) StoreChannel
+	Claim(id string, claimedAt int64, claimedBefore int64) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteClaimed(id string, claimedAt int64) StoreChannel
+	Restore(scheduledPost *model.ScheduledPost) StoreChannel
+}
+
+type EmojiUsageStore interface {
+	Increment(userId string, emojiNames []string, day int64) StoreChannel
+	GetFrequent(userId string, since int64, limit int) StoreChannel
+	PermanentDeleteBefore(day int64) StoreChannel
+}
+
+type DraftStore interface {
+	Save(draft *model.Draft) StoreChannel
+	Get(userId, channelId, rootId string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	Delete(userId, channelId, rootId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type DeviceStore interface {
+	Save(device *model.Device) StoreChannel
+	Update(device *model.Device) StoreChannel
+	Get(id string) StoreChannel
+	GetBySessionId(sessionId string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	UpdateLastSeenAt(sessionId string, time int64) StoreChannel
+	GetVersionCounts(since int64) StoreChannel
+	GetUserIdsByAppVersion(platform string, appVersion string, since int64) StoreChannel
+	DeleteBySessionId(sessionId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type ChannelMemberReadStore interface {
+	Save(read *model.ChannelMemberRead) StoreChannel
+	Get(channelId, userId string) StoreChannel
+	GetReadSince(channelId string, since int64) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+	PermanentDeleteByChannel(channelId string) StoreChannel
+}
+
+type BotStore interface {
+	Save(bot *model.Bot) StoreChannel
+	Update(bot *model.Bot) StoreChannel
+	Get(userId string, includeDeleted bool) StoreChannel
+	GetAll(ownerId string, offset int, limit int) StoreChannel
+	PermanentDelete(userId string) StoreChannel
+}
+
+type UserAccessTokenStore interface {
+	Save(token *model.UserAccessToken) StoreChannel
+	Get(id string) StoreChannel
+	GetByUser(userId string) StoreChannel
+	GetByToken(token string) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteAllForUser(userId string) StoreChannel
+	UpdateTokenEnable(id string) StoreChannel
+	UpdateTokenDisable(id string) StoreChannel
+	UpdateLastUsedAt(id string, time int64) StoreChannel
+}
+
+type UserCredentialStore interface {
+	Save(credential *model.UserCredential) StoreChannel
+	Get(id string) StoreChannel
+	GetByUser(userId string) StoreChannel
+	UpdateSignCount(id string, signCount int64, lastUsedAt int64) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteAllForUser(userId string) StoreChannel
+}
+
+type PostIntegrityStore interface {
+	Append(post *model.Post) StoreChannel
+	GetForChannel(channelId string, afterSequence int64, limit int) StoreChannel
+	GetChannelIds(offset int, limit int) StoreChannel
+}
+
+type LdapGroupStore interface {
+	Save(group *model.LdapGroup) StoreChannel
+	Get(id string) StoreChannel
+	GetAll() StoreChannel
+	GetForChannel(channelId string) StoreChannel
+	UpdateLastSyncAt(id string, time int64) StoreChannel
+	Delete(id string) StoreChannel
+}
+
+type InvitationStore interface {
+	Save(invitation *model.Invitation) StoreChannel
+	Update(invitation *model.Invitation) StoreChannel
+	Get(id string) StoreChannel
+	GetByToken(token string) StoreChannel
+	GetPendingForTeam(teamId string, now int64, offset int, limit int) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteExpired(before int64) StoreChannel
+}
+
+type ArchiveExportStore interface {
+	Save(export *model.ArchiveExport) StoreChannel
+	Update(export *model.ArchiveExport) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset int, limit int) StoreChannel
+}
+
+type PostEventHookStore interface {
+	Save(hook *model.PostEventHook) StoreChannel
+	Update(hook *model.PostEventHook) StoreChannel
+	Get(hookId string) StoreChannel
+	GetByTeam(teamId string, offset int, limit int) StoreChannel
+	GetForChannel(teamId string, channelId string) StoreChannel
+	Delete(hookId string, time int64) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type BulkEmailStore interface {
+	Save(bulkEmail *model.BulkEmail) StoreChannel
+	Update(bulkEmail *model.BulkEmail) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset int, limit int) StoreChannel
+	SaveRecipients(bulkEmailId string, userIds []string) StoreChannel
+	UpdateRecipient(recipient *model.BulkEmailRecipient) StoreChannel
+	GetRecipients(bulkEmailId string, status string, offset int, limit int) StoreChannel
+}
+
+type RetentionPolicyStore interface {
+	Save(policy *model.RetentionPolicy) StoreChannel
+	Update(policy *model.RetentionPolicy) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset int, limit int) StoreChannel
+	GetCount() StoreChannel
+	Delete(id string) StoreChannel
+	GetExpiredPostIds(policy *model.RetentionPolicy, endTime int64, limit int) StoreChannel
+	GetExpiredPostCount(policy *model.RetentionPolicy, endTime int64) StoreChannel
+	GetExpiredFileInfos(policy *model.RetentionPolicy, endTime int64, limit int) StoreChannel
+	GetExpiredFileCount(policy *model.RetentionPolicy, endTime int64) StoreChannel
+}
+
+type AutoResponderStore interface {
+	SaveOrUpdate(responder *model.AutoResponder) StoreChannel
+	Get(userId string) StoreChannel
+	GetActive(offset int, limit int) StoreChannel
+	MarkResponseSent(userId string, senderId string, sentAt int64, since int64) StoreChannel
+	DeleteResponses(userId string) StoreChannel
+}
+
+type LegalHoldStore interface {
+	Save(hold *model.LegalHold) StoreChannel
+	Get(id string) StoreChannel
+	GetAll(offset int, limit int) StoreChannel
+	GetCount() StoreChannel
+	Delete(id string) StoreChannel
+	IsHeld(userId string, channelId string) StoreChannel
+	HasHeldUserContent(userId string) StoreChannel
+	HasHeldChannelContent(channelId string) StoreChannel
+	GetHeldPosts(startTime int64, endTime int64, offset int, limit int) StoreChannel
+}
+
+type NameRedirectStore interface {
+	Save(redirect *model.NameRedirect) StoreChannel
+	Get(redirectType string, teamId string, oldName string, since int64) StoreChannel
+	PermanentDeleteByTarget(targetId string) StoreChannel
+}
+
+type TeamQuotaStore interface {
+	SaveQuota(quota *model.TeamQuota) StoreChannel
+	GetQuota(teamId string) StoreChannel
+	GetUsage(teamId string) StoreChannel
+	CountIntegrations(teamId string) StoreChannel
+	IncrementPostCount(teamId string, month string) StoreChannel
+	IncrementStorageBytes(teamId string, bytes int64) StoreChannel
+}
+
+type RemoteClusterStore interface {
+	Save(rc *model.RemoteCluster) StoreChannel
+	Update(rc *model.RemoteCluster) StoreChannel
+	Get(remoteId string) StoreChannel
+	GetAll() StoreChannel
+	UpdateLastPingAt(remoteId string, pingAt int64) StoreChannel
+	Delete(remoteId string) StoreChannel
+}
+
+type SharedChannelStore interface {
+	Save(sc *model.SharedChannel) StoreChannel
+	Get(channelId string) StoreChannel
+	GetForRemote(sharedId string, remoteId string) StoreChannel
+	Delete(channelId string) StoreChannel
+	SaveRemote(remote *model.SharedChannelRemote) StoreChannel
+	GetRemotes(channelId string) StoreChannel
+	DeleteRemote(channelId string, remoteId string) StoreChannel
+	UpdateRemoteLastSyncAt(id string, lastSyncAt int64) StoreChannel
+	GetPostsForSync(channelId string, since int64, limit int) StoreChannel
+	TouchPost(postId string, updateAt int64) StoreChannel
+	UpsertPost(post *model.Post) StoreChannel
+	UpsertRemoteUser(user *model.User) StoreChannel
+}
+
+type ChannelAutoArchiveStore interface {
+	Save(archive *model.ChannelAutoArchive) StoreChannel
+	Get(channelId string) StoreChannel
+	Delete(channelId string) StoreChannel
+	GetIdleChannels(since int64, limit int) StoreChannel
+	GetDue(now int64, limit int) StoreChannel
+	ResetActive() StoreChannel
+	GetUpcoming(offset int, limit int) StoreChannel
+	GetUpcomingCount() StoreChannel
 }
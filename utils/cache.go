@@ -0,0 +1,33 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// ObjectCache is implemented by the caches the stores keep in front of the database. The LRU
+// holds values in the memory of a single node while the Redis cache shares them between every
+// node in a cluster, so neither serves stale data while waiting for a cluster invalidation.
+type ObjectCache interface {
+	Add(key, value interface{}) bool
+	AddWithExpiresInSecs(key, value interface{}, expireAtSecs int64) bool
+	Get(key interface{}) (value interface{}, ok bool)
+	Remove(key interface{})
+	Purge()
+	Keys() []interface{}
+	Len() int
+}
+
+// NewObjectCache creates the cache selected by CacheSettings. The name keeps the cache's keys
+// apart from other caches in a shared backend and identifies the in memory LRU in its statistics,
+// the size bounds the in memory LRU and valueType is an example of the values stored so shared
+// backends know what to decode them as.
+func NewObjectCache(name string, size int, valueType interface{}) ObjectCache {
+	if Cfg != nil && Cfg.CacheSettings.CacheType != nil && *Cfg.CacheSettings.CacheType == model.CACHE_TYPE_REDIS {
+		return NewRedisCache(GetRedisClient(), name, valueType)
+	}
+
+	return NewNamedLru(name, size)
+}
@@ -39,6 +39,7 @@ var CfgFileName string = ""
 var ClientCfg map[string]string = map[string]string{}
 var originalDisableDebugLvl l4g.Level = l4g.DEBUG
 var siteURL = ""
+var cfgListeners = map[string]func(oldCfg *model.Config, newCfg *model.Config){}
 
 func GetSiteURL() string {
 	return siteURL
@@ -232,6 +233,27 @@ func DisableConfigWatch() {
 	}
 }
 
+// AddConfigListener registers a function that is called with the old and the new config every time
+// the config is loaded, such as when it is saved or the config file watcher sees a change. Listeners
+// are called while the config is locked so they must not load or save the config themselves.
+// The returned id can be given to RemoveConfigListener.
+func AddConfigListener(listener func(oldCfg *model.Config, newCfg *model.Config)) string {
+	cfgMutex.Lock()
+	defer cfgMutex.Unlock()
+
+	id := model.NewId()
+	cfgListeners[id] = listener
+
+	return id
+}
+
+func RemoveConfigListener(id string) {
+	cfgMutex.Lock()
+	defer cfgMutex.Unlock()
+
+	delete(cfgListeners, id)
+}
+
 // LoadConfig will try to search around for the corresponding config file.
 // It will search /tmp/fileName then attempt ./config/fileName,
 // then ../config/fileName and last it will look at fileName
@@ -277,7 +299,8 @@ func LoadConfig(fileName string) {
 	CfgFileName = viper.ConfigFileUsed()
 
 	needSave := len(config.SqlSettings.AtRestEncryptKey) == 0 || len(*config.FileSettings.PublicLinkSalt) == 0 ||
-		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0
+		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0 ||
+		config.ComplianceSettings.ExportSigningKey == nil || len(*config.ComplianceSettings.ExportSigningKey) == 0
 
 	config.SetDefaults()
 
@@ -310,6 +333,7 @@ func LoadConfig(fileName string) {
 		}
 	}
 
+	oldCfg := Cfg
 	Cfg = &config
 	CfgHash = fmt.Sprintf("%x", md5.Sum([]byte(Cfg.ToJson())))
 	ClientCfg = getClientConfig(Cfg)
@@ -326,6 +350,36 @@ func LoadConfig(fileName string) {
 
 	SetDefaultRolesBasedOnConfig()
 	SetSiteURL(*Cfg.ServiceSettings.SiteURL)
+	configureIdSeed(Cfg)
+	ResizeNamedCaches(&Cfg.CacheSettings)
+
+	for _, listener := range cfgListeners {
+		listener(oldCfg, Cfg)
+	}
+}
+
+var idSeed int64
+
+// configureIdSeed makes ids deterministic when a seed is configured on a developer or testing server.
+// The seed is only applied when it changes so that reloading the config doesn't repeat ids.
+func configureIdSeed(c *model.Config) {
+	seed := *c.ServiceSettings.IdSeed
+	if !c.ServiceSettings.EnableTesting && !*c.ServiceSettings.EnableDeveloper {
+		seed = 0
+	}
+
+	if seed == idSeed {
+		return
+	}
+
+	if seed == 0 {
+		model.ClearIdSeed()
+	} else {
+		l4g.Warn(T("utils.config.id_seed.warn"), seed)
+		model.SetIdSeed(seed)
+	}
+
+	idSeed = seed
 }
 
 func RegenerateClientConfig() {
@@ -348,6 +402,8 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableUserCreation"] = strconv.FormatBool(c.TeamSettings.EnableUserCreation)
 	props["EnableOpenServer"] = strconv.FormatBool(*c.TeamSettings.EnableOpenServer)
 	props["RestrictDirectMessage"] = *c.TeamSettings.RestrictDirectMessage
+	props["EnableReadReceipts"] = strconv.FormatBool(*c.TeamSettings.EnableReadReceipts)
+	props["EnableGuestAccounts"] = strconv.FormatBool(*c.TeamSettings.EnableGuestAccounts)
 	props["RestrictTeamInvite"] = *c.TeamSettings.RestrictTeamInvite
 	props["RestrictPublicChannelCreation"] = *c.TeamSettings.RestrictPublicChannelCreation
 	props["RestrictPrivateChannelCreation"] = *c.TeamSettings.RestrictPrivateChannelCreation
@@ -363,6 +419,9 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableOutgoingWebhooks"] = strconv.FormatBool(c.ServiceSettings.EnableOutgoingWebhooks)
 	props["EnableCommands"] = strconv.FormatBool(*c.ServiceSettings.EnableCommands)
 	props["EnableOnlyAdminIntegrations"] = strconv.FormatBool(*c.ServiceSettings.EnableOnlyAdminIntegrations)
+	props["EnableBotAccountCreation"] = strconv.FormatBool(*c.ServiceSettings.EnableBotAccountCreation)
+	props["EnableUserAccessTokens"] = strconv.FormatBool(*c.ServiceSettings.EnableUserAccessTokens)
+	props["EnableGraphQL"] = strconv.FormatBool(*c.ServiceSettings.EnableGraphQL)
 	props["EnablePostUsernameOverride"] = strconv.FormatBool(c.ServiceSettings.EnablePostUsernameOverride)
 	props["EnablePostIconOverride"] = strconv.FormatBool(c.ServiceSettings.EnablePostIconOverride)
 	props["EnableLinkPreviews"] = strconv.FormatBool(*c.ServiceSettings.EnableLinkPreviews)
@@ -382,6 +441,8 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableEmailBatching"] = strconv.FormatBool(*c.EmailSettings.EnableEmailBatching)
 
 	props["EnableSignUpWithGitLab"] = strconv.FormatBool(c.GitLabSettings.Enable)
+	props["EnableSignUpWithOpenId"] = strconv.FormatBool(*c.OpenIdSettings.Enable)
+	props["OpenIdButtonText"] = *c.OpenIdSettings.ButtonText
 
 	props["ShowEmailAddress"] = strconv.FormatBool(c.PrivacySettings.ShowEmailAddress)
 
@@ -405,8 +466,15 @@ func getClientConfig(c *model.Config) map[string]string {
 
 	props["EnableCustomEmoji"] = strconv.FormatBool(*c.ServiceSettings.EnableCustomEmoji)
 	props["RestrictCustomEmojiCreation"] = *c.ServiceSettings.RestrictCustomEmojiCreation
+	props["MaxEmojiFrames"] = strconv.Itoa(*c.ServiceSettings.MaxEmojiFrames)
+	props["MaxEmojiUploadWidth"] = strconv.Itoa(*c.ServiceSettings.MaxEmojiUploadWidth)
+	props["MaxEmojiUploadHeight"] = strconv.Itoa(*c.ServiceSettings.MaxEmojiUploadHeight)
 	props["MaxFileSize"] = strconv.FormatInt(*c.FileSettings.MaxFileSize, 10)
 
+	props["DesktopMinVersion"] = *c.ClientRequirementsSettings.DesktopMinVersion
+	props["MobileMinVersion"] = *c.ClientRequirementsSettings.MobileMinVersion
+	props["WebMinVersion"] = *c.ClientRequirementsSettings.WebMinVersion
+
 	props["AppDownloadLink"] = *c.NativeAppSettings.AppDownloadLink
 	props["AndroidAppDownloadLink"] = *c.NativeAppSettings.AndroidAppDownloadLink
 	props["IosAppDownloadLink"] = *c.NativeAppSettings.IosAppDownloadLink
@@ -414,6 +482,7 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableWebrtc"] = strconv.FormatBool(*c.WebrtcSettings.Enable)
 
 	props["MaxNotificationsPerChannel"] = strconv.FormatInt(*c.TeamSettings.MaxNotificationsPerChannel, 10)
+	props["LargeChannelThreshold"] = strconv.FormatInt(*c.TeamSettings.LargeChannelThreshold, 10)
 	props["TimeBetweenUserTypingUpdatesMilliseconds"] = strconv.FormatInt(*c.ServiceSettings.TimeBetweenUserTypingUpdatesMilliseconds, 10)
 	props["EnableUserTypingMessages"] = strconv.FormatBool(*c.ServiceSettings.EnableUserTypingMessages)
 
@@ -438,6 +507,8 @@ func getClientConfig(c *model.Config) map[string]string {
 		if *License.Features.MFA {
 			props["EnableMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnableMultifactorAuthentication)
 			props["EnforceMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthentication)
+			props["EnforceMultifactorAuthenticationAfter"] = strconv.FormatInt(*c.ServiceSettings.EnforceMultifactorAuthenticationAfter, 10)
+			props["EnableWebAuthn"] = strconv.FormatBool(*c.ServiceSettings.EnableWebAuthn)
 		}
 
 		if *License.Features.Compliance {
@@ -535,10 +606,25 @@ func Desanitize(cfg *model.Config) {
 		cfg.EmailSettings.SMTPPassword = Cfg.EmailSettings.SMTPPassword
 	}
 
+	if *cfg.ComplianceSettings.ExportSigningKey == model.FAKE_SETTING {
+		*cfg.ComplianceSettings.ExportSigningKey = *Cfg.ComplianceSettings.ExportSigningKey
+	}
+	if *cfg.ComplianceSettings.SftpPassword == model.FAKE_SETTING {
+		*cfg.ComplianceSettings.SftpPassword = *Cfg.ComplianceSettings.SftpPassword
+	}
+
 	if cfg.GitLabSettings.Secret == model.FAKE_SETTING {
 		cfg.GitLabSettings.Secret = Cfg.GitLabSettings.Secret
 	}
 
+	if *cfg.OpenIdSettings.Secret == model.FAKE_SETTING {
+		*cfg.OpenIdSettings.Secret = *Cfg.OpenIdSettings.Secret
+	}
+
+	if *cfg.AuditSettings.WebhookSecret == model.FAKE_SETTING {
+		*cfg.AuditSettings.WebhookSecret = *Cfg.AuditSettings.WebhookSecret
+	}
+
 	if cfg.SqlSettings.DataSource == model.FAKE_SETTING {
 		cfg.SqlSettings.DataSource = Cfg.SqlSettings.DataSource
 	}
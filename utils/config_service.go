@@ -0,0 +1,51 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/mattermost/platform/model"
+)
+
+// ConfigService provides the server configuration to code that shouldn't depend on the global
+// Cfg directly. The config it returns must not be changed. Changes are made to a copy that replaces
+// it wholesale, such as when the config file is reloaded, so holding on to the returned config
+// gives a consistent snapshot, like the one request handlers take for each request.
+type ConfigService interface {
+	Config() *model.Config
+}
+
+type globalConfigService struct{}
+
+// Config returns the global Cfg, so that it keeps working with code that loads or changes it directly.
+func (globalConfigService) Config() *model.Config {
+	return Cfg
+}
+
+// GlobalConfigService is the compatibility shim that serves the loaded config file.
+var GlobalConfigService ConfigService = globalConfigService{}
+
+// StaticConfigService serves a config that isn't shared with the rest of the server, so that
+// tests can change settings without affecting each other.
+type StaticConfigService struct {
+	cfg *model.Config
+}
+
+func NewStaticConfigService(cfg *model.Config) *StaticConfigService {
+	return &StaticConfigService{cfg: cfg}
+}
+
+func (s *StaticConfigService) Config() *model.Config {
+	return s.cfg
+}
+
+// CloneConfig returns a deep copy of cfg that can be changed without affecting the original.
+func CloneConfig(cfg *model.Config) *model.Config {
+	if cfg == nil {
+		return nil
+	}
+
+	return model.ConfigFromJson(strings.NewReader(cfg.ToJson()))
+}
@@ -6,6 +6,8 @@ package utils
 import (
 	"os"
 	"testing"
+
+	"github.com/mattermost/platform/model"
 )
 
 func TestConfig(t *testing.T) {
@@ -59,3 +61,53 @@ func TestConfigFromEnviroVars(t *testing.T) {
 	}
 
 }
+
+func TestConfigService(t *testing.T) {
+	TranslationsPreInit()
+	LoadConfig("config.json")
+
+	if GlobalConfigService.Config() != Cfg {
+		t.Fatal("global config service should return the loaded config")
+	}
+
+	cfg := CloneConfig(Cfg)
+	cfg.TeamSettings.SiteName = "Cloned " + Cfg.TeamSettings.SiteName
+	*cfg.ServiceSettings.EnableDeveloper = !*Cfg.ServiceSettings.EnableDeveloper
+
+	if Cfg.TeamSettings.SiteName == cfg.TeamSettings.SiteName || *Cfg.ServiceSettings.EnableDeveloper == *cfg.ServiceSettings.EnableDeveloper {
+		t.Fatal("changing a cloned config shouldn't change the original")
+	}
+
+	service := NewStaticConfigService(cfg)
+	if service.Config() != cfg {
+		t.Fatal("static config service should return its own config")
+	}
+
+	if CloneConfig(nil) != nil {
+		t.Fatal("cloning a missing config should return nil")
+	}
+}
+
+func TestConfigListener(t *testing.T) {
+	TranslationsPreInit()
+	LoadConfig("config.json")
+
+	var oldCfg, newCfg *model.Config
+	id := AddConfigListener(func(o *model.Config, n *model.Config) {
+		oldCfg, newCfg = o, n
+	})
+
+	loaded := Cfg
+	LoadConfig("config.json")
+	if oldCfg != loaded || newCfg != Cfg {
+		t.Fatal("should have been called with the old and the new config")
+	}
+
+	RemoveConfigListener(id)
+
+	oldCfg, newCfg = nil, nil
+	LoadConfig("config.json")
+	if oldCfg != nil || newCfg != nil {
+		t.Fatal("shouldn't be called once removed")
+	}
+}
@@ -5,6 +5,8 @@ package utils
 
 import (
 	"bytes"
+	"encoding/binary"
+	"hash/crc32"
 	"image"
 	"image/color"
 	"image/gif"
@@ -60,3 +62,69 @@ func CreateTestPng(t *testing.T, width int, height int) []byte {
 
 	return buffer.Bytes()
 }
+
+// CreateTestAnimatedPng creates an animated png where each frame reuses the image data of the first.
+func CreateTestAnimatedPng(t *testing.T, width int, height int, frames int) []byte {
+	var still bytes.Buffer
+
+	if err := png.Encode(&still, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("failed to create png: %v", err.Error())
+	}
+
+	// Split the still image into its header and image data
+	data := still.Bytes()
+	var header, imageData []byte
+	for offset := 8; offset < len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		chunk := data[offset : offset+length+12]
+
+		switch string(chunk[4:8]) {
+		case "IHDR":
+			header = chunk
+		case "IDAT":
+			imageData = append(imageData, chunk[8:8+length]...)
+		}
+
+		offset += length + 12
+	}
+
+	var buffer bytes.Buffer
+	writeChunk := func(chunkType string, chunkData []byte) {
+		binary.Write(&buffer, binary.BigEndian, uint32(len(chunkData)))
+		buffer.WriteString(chunkType)
+		buffer.Write(chunkData)
+		binary.Write(&buffer, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(chunkType), chunkData...)))
+	}
+
+	buffer.Write(data[:8])
+	buffer.Write(header)
+
+	animationControl := make([]byte, 8)
+	binary.BigEndian.PutUint32(animationControl, uint32(frames))
+	writeChunk("acTL", animationControl)
+
+	sequence := uint32(0)
+	for i := 0; i < frames; i++ {
+		frameControl := make([]byte, 26)
+		binary.BigEndian.PutUint32(frameControl[0:], sequence)
+		binary.BigEndian.PutUint32(frameControl[4:], uint32(width))
+		binary.BigEndian.PutUint32(frameControl[8:], uint32(height))
+		binary.BigEndian.PutUint16(frameControl[20:], 1)
+		binary.BigEndian.PutUint16(frameControl[22:], 10)
+		writeChunk("fcTL", frameControl)
+		sequence++
+
+		if i == 0 {
+			writeChunk("IDAT", imageData)
+		} else {
+			frameData := make([]byte, 4)
+			binary.BigEndian.PutUint32(frameData, sequence)
+			writeChunk("fdAT", append(frameData, imageData...))
+			sequence++
+		}
+	}
+
+	writeChunk("IEND", nil)
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	// Limits how much is decompressed from a single document so that a malicious file can't use up
+	// all of the server's memory
+	MAX_EXTRACTED_DOCUMENT_SIZE = 10 * 1024 * 1024
+)
+
+var plainTextExtensions = map[string]bool{
+	".txt":      true,
+	".text":     true,
+	".md":       true,
+	".markdown": true,
+	".csv":      true,
+	".tsv":      true,
+	".log":      true,
+	".json":     true,
+	".xml":      true,
+	".html":     true,
+	".htm":      true,
+	".yml":      true,
+	".yaml":     true,
+}
+
+// ExtractFileContent returns the searchable text contained in a plain text, docx or PDF file,
+// truncated so that it fits in a FileInfo. Files of any other type have no content.
+func ExtractFileContent(name string, data []byte) (string, error) {
+	var content string
+	var err error
+
+	switch extension := strings.ToLower(filepath.Ext(name)); {
+	case plainTextExtensions[extension]:
+		content = extractPlainText(data)
+	case extension == ".docx":
+		content, err = extractDocxText(data)
+	case extension == ".pdf":
+		content, err = extractPdfText(data)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return truncateFileContent(strings.Join(strings.Fields(content), " ")), nil
+}
+
+func extractPlainText(data []byte) string {
+	if !utf8.Valid(data) {
+		return ""
+	}
+
+	return string(data)
+}
+
+// extractDocxText returns the text of the paragraphs in the main document of a Word file.
+func extractDocxText(data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range reader.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		document, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		defer document.Close()
+
+		return extractDocxDocumentText(io.LimitReader(document, MAX_EXTRACTED_DOCUMENT_SIZE))
+	}
+
+	return "", nil
+}
+
+func extractDocxDocumentText(document io.Reader) (string, error) {
+	var text bytes.Buffer
+	inText := false
+
+	decoder := xml.NewDecoder(document)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "t" {
+				inText = true
+			} else if t.Name.Local == "tab" || t.Name.Local == "br" {
+				text.WriteString(" ")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "t" {
+				inText = false
+			} else if t.Name.Local == "p" {
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+
+		if text.Len() > model.FILE_INFO_CONTENT_MAX_SIZE {
+			break
+		}
+	}
+
+	return text.String(), nil
+}
+
+// extractPdfText returns the strings drawn by the text operators in the content streams of a PDF.
+// It doesn't understand font encodings, so it only finds text stored as plain strings, which is
+// what most PDFs with Latin text contain.
+func extractPdfText(data []byte) (string, error) {
+	var text bytes.Buffer
+
+	for remaining := data; text.Len() <= model.FILE_INFO_CONTENT_MAX_SIZE; {
+		start := bytes.Index(remaining, []byte("stream"))
+		if start == -1 {
+			break
+		}
+
+		dictionary := remaining[:start]
+		remaining = remaining[start+len("stream"):]
+
+		// The stream keyword is followed by an end of line before the data itself
+		if bytes.HasPrefix(remaining, []byte("\r\n")) {
+			remaining = remaining[2:]
+		} else if bytes.HasPrefix(remaining, []byte("\n")) {
+			remaining = remaining[1:]
+		} else {
+			continue
+		}
+
+		end := bytes.Index(remaining, []byte("endstream"))
+		if end == -1 {
+			break
+		}
+
+		stream := remaining[:end]
+		remaining = remaining[end+len("endstream"):]
+
+		if dictionaryStart := bytes.LastIndex(dictionary, []byte("<<")); dictionaryStart != -1 {
+			dictionary = dictionary[dictionaryStart:]
+		}
+
+		if bytes.Contains(dictionary, []byte("/FlateDecode")) {
+			if reader, err := zlib.NewReader(bytes.NewReader(stream)); err != nil {
+				continue
+			} else {
+				// Some PDF writers leave garbage after the compressed data, so keep whatever was decoded
+				stream, _ = ioutil.ReadAll(io.LimitReader(reader, MAX_EXTRACTED_DOCUMENT_SIZE))
+				reader.Close()
+			}
+		} else if bytes.Contains(dictionary, []byte("/Filter")) {
+			// Images and fonts use other filters, and neither contain any text
+			continue
+		}
+
+		extractPdfContentStreamText(stream, &text)
+	}
+
+	return text.String(), nil
+}
+
+func extractPdfContentStreamText(stream []byte, text *bytes.Buffer) {
+	// Strings are collected until the operator that uses them is found
+	operands := []string{}
+
+	for i := 0; i < len(stream); {
+		c := stream[i]
+
+		switch {
+		case c == '(':
+			var literal string
+			literal, i = readPdfLiteralString(stream, i)
+			operands = append(operands, literal)
+		case c == '<' && i+1 < len(stream) && stream[i+1] == '<':
+			// Skip the start of a dictionary so that it isn't read as a hex string
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(stream[i:], '>')
+			if end == -1 {
+				return
+			}
+
+			operands = append(operands, decodePdfHexString(stream[i+1:i+end]))
+			i += end + 1
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case isPdfOperatorChar(c):
+			start := i
+			for i < len(stream) && isPdfOperatorChar(stream[i]) {
+				i++
+			}
+
+			switch string(stream[start:i]) {
+			case "Tj", "TJ", "'", "\"":
+				for _, operand := range operands {
+					text.WriteString(operand)
+				}
+				operands = operands[:0]
+			case "Td", "TD", "T*", "Tm", "ET":
+				text.WriteString(" ")
+				operands = operands[:0]
+			default:
+				operands = operands[:0]
+			}
+		default:
+			i++
+		}
+	}
+}
+
+func isPdfOperatorChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '*' || c == '\'' || c == '"'
+}
+
+// readPdfLiteralString reads the string in parentheses starting at start and returns it along
+// with the index of the first byte after it.
+func readPdfLiteralString(stream []byte, start int) (string, int) {
+	var literal []rune
+	depth := 0
+
+	i := start
+	for ; i < len(stream); i++ {
+		c := stream[i]
+
+		if c == '\\' && i+1 < len(stream) {
+			i++
+
+			switch stream[i] {
+			case 'n', 'r', 't':
+				literal = append(literal, ' ')
+			case 'b', 'f', '\r', '\n':
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				// Octal character codes have up to three digits
+				code := 0
+				for j := 0; j < 3 && i < len(stream) && stream[i] >= '0' && stream[i] <= '7'; j++ {
+					code = code*8 + int(stream[i]-'0')
+					i++
+				}
+				i--
+
+				literal = appendPdfRune(literal, rune(code&0xff))
+			default:
+				literal = append(literal, rune(stream[i]))
+			}
+
+			continue
+		}
+
+		if c == '(' {
+			depth++
+			if depth == 1 {
+				continue
+			}
+		} else if c == ')' {
+			depth--
+			if depth == 0 {
+				return string(literal), i + 1
+			}
+		}
+
+		literal = appendPdfRune(literal, rune(c))
+	}
+
+	return string(literal), i
+}
+
+func decodePdfHexString(data []byte) string {
+	digits := bytes.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, data)
+
+	// A missing final digit is treated as a zero
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	decoded := make([]byte, hex.DecodedLen(len(digits)))
+	if _, err := hex.Decode(decoded, digits); err != nil {
+		return ""
+	}
+
+	var literal []rune
+	for _, b := range decoded {
+		literal = appendPdfRune(literal, rune(b))
+	}
+
+	return string(literal)
+}
+
+// appendPdfRune adds a character to a PDF string, treating its bytes as Latin-1 and skipping any
+// control characters since those are most likely glyph ids from a font that we can't decode.
+func appendPdfRune(literal []rune, r rune) []rune {
+	if unicode.IsPrint(r) {
+		return append(literal, r)
+	}
+
+	return literal
+}
+
+func truncateFileContent(content string) string {
+	if len(content) <= model.FILE_INFO_CONTENT_MAX_SIZE {
+		return content
+	}
+
+	// Don't cut a multi-byte character in half
+	end := model.FILE_INFO_CONTENT_MAX_SIZE
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+
+	return content[:end]
+}
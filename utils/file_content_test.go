@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestExtractFileContentPlainText(t *testing.T) {
+	if content, err := ExtractFileContent("notes.TXT", []byte("some\nplain   text")); err != nil {
+		t.Fatal(err)
+	} else if content != "some plain text" {
+		t.Fatal("extracted the wrong text", content)
+	}
+
+	if content, err := ExtractFileContent("notes.txt", []byte{0xff, 0xfe, 0x00}); err != nil || content != "" {
+		t.Fatal("shouldn't extract text that isn't utf-8", content)
+	}
+
+	if content, err := ExtractFileContent("image.png", []byte("not really an image")); err != nil || content != "" {
+		t.Fatal("shouldn't extract text from other types of files", content)
+	}
+}
+
+func TestExtractFileContentDocx(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+	if file, err := writer.Create("word/document.xml"); err != nil {
+		t.Fatal(err)
+	} else {
+		file.Write([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+	<w:body>
+		<w:p><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t xml:space="preserve"> report</w:t></w:r></w:p>
+		<w:p><w:r><w:t>Revenue &amp; costs</w:t></w:r></w:p>
+	</w:body>
+</w:document>`))
+	}
+	writer.Close()
+
+	if content, err := ExtractFileContent("report.docx", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if content != "Quarterly report Revenue & costs" {
+		t.Fatal("extracted the wrong text", content)
+	}
+
+	if _, err := ExtractFileContent("report.docx", []byte("not a zip file")); err == nil {
+		t.Fatal("should have failed to read an invalid docx file")
+	}
+}
+
+func TestExtractFileContentPdf(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("BT /F1 12 Tf 72 712 Td [(Hello) -250 (\\(PDF\\))] TJ T* <576f726c64> Tj ET"))
+	writer.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n4 0 obj\n<< /Length 44 >>\nstream\nBT (Plain) Tj ET\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Filter /FlateDecode >>\nstream\n")
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("6 0 obj\n<< /Filter /DCTDecode >>\nstream\n(Image) Tj\nendstream\nendobj\n%%EOF")
+
+	if content, err := ExtractFileContent("document.pdf", pdf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if content != "Plain Hello(PDF) World" {
+		t.Fatal("extracted the wrong text", content)
+	}
+}
+
+func TestExtractFileContentTruncation(t *testing.T) {
+	data := []byte(strings.Repeat("é", model.FILE_INFO_CONTENT_MAX_SIZE))
+
+	if content, err := ExtractFileContent("long.txt", data); err != nil {
+		t.Fatal(err)
+	} else if len(content) > model.FILE_INFO_CONTENT_MAX_SIZE || len(content) < model.FILE_INFO_CONTENT_MAX_SIZE-1 {
+		t.Fatal("should have truncated the content", len(content))
+	} else if strings.Trim(content, "é") != "" {
+		t.Fatal("shouldn't have cut a character in half")
+	}
+}
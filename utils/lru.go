@@ -16,11 +16,16 @@ import (
 
 // Cache is a thread-safe fixed size LRU cache.
 type Cache struct {
-	size      int
-	evictList *list.List
-	items     map[interface{}]*list.Element
-	lock      sync.RWMutex
-	onEvicted func(key interface{}, value interface{})
+	name        string
+	size        int
+	defaultSize int
+	evictList   *list.List
+	items       map[interface{}]*list.Element
+	lock        sync.RWMutex
+	onEvicted   func(key interface{}, value interface{})
+	hits        int64
+	misses      int64
+	evictions   int64
 }
 
 // entry is used to hold a value in the evictList
@@ -41,10 +46,11 @@ func NewLruWithEvict(size int, onEvicted func(key interface{}, value interface{}
 		return nil, errors.New(T("utils.iru.with_evict"))
 	}
 	c := &Cache{
-		size:      size,
-		evictList: list.New(),
-		items:     make(map[interface{}]*list.Element, size),
-		onEvicted: onEvicted,
+		size:        size,
+		defaultSize: size,
+		evictList:   list.New(),
+		items:       make(map[interface{}]*list.Element, size),
+		onEvicted:   onEvicted,
 	}
 	return c, nil
 }
@@ -94,6 +100,7 @@ func (c *Cache) AddWithExpiresInSecs(key, value interface{}, expireAtSecs int64)
 	// Verify size not exceeded
 	if evict {
 		c.removeOldest()
+		c.evictions++
 	}
 	return evict
 }
@@ -108,13 +115,16 @@ func (c *Cache) Get(key interface{}) (value interface{}, ok bool) {
 		if ent.Value.(*entry).expireAtSecs > 0 {
 			if (time.Now().UnixNano() / int64(time.Second)) > ent.Value.(*entry).expireAtSecs {
 				c.removeElement(ent)
+				c.misses++
 				return nil, false
 			}
 		}
 
 		c.evictList.MoveToFront(ent)
+		c.hits++
 		return ent.Value.(*entry).value, true
 	}
+	c.misses++
 	return
 }
 
@@ -152,6 +162,28 @@ func (c *Cache) Keys() []interface{} {
 	return keys
 }
 
+// Resize changes the number of items the cache can hold, removing the oldest ones if it no longer
+// fits them. Returns the number of items removed.
+func (c *Cache) Resize(size int) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if size <= 0 {
+		return 0
+	}
+
+	c.size = size
+
+	evicted := 0
+	for c.evictList.Len() > c.size {
+		c.removeOldest()
+		c.evictions++
+		evicted++
+	}
+
+	return evicted
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache) Len() int {
 	c.lock.RLock()
@@ -0,0 +1,79 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"fmt"
+
+	l4g "github.com/alecthomas/log4go"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	CACHE_MIN_SIZE            = 100
+	CACHE_DEFAULT_ENTRY_BYTES = 1024
+)
+
+// ResizeNamedCaches applies CacheSettings to every named cache. Each cache gets the size set for
+// it in CacheSizes or else the size it was created with. When MemoryBudgetMB is set, those sizes
+// are then scaled together so that the caches are expected to fit in the budget given the size
+// of the entries they currently hold.
+func ResizeNamedCaches(settings *model.CacheSettings) {
+	namedCachesLock.RLock()
+	caches := make([]*Cache, 0, len(namedCaches))
+	for _, cache := range namedCaches {
+		caches = append(caches, cache)
+	}
+	namedCachesLock.RUnlock()
+
+	for cache, size := range getNamedCacheSizes(settings, caches) {
+		if evicted := cache.Resize(size); evicted > 0 {
+			l4g.Debug(fmt.Sprintf("Resized the %v cache to %v entries and evicted %v of them", cache.name, size, evicted))
+		}
+	}
+}
+
+func getNamedCacheSizes(settings *model.CacheSettings, caches []*Cache) map[*Cache]int {
+	sizes := make(map[*Cache]int, len(caches))
+
+	for _, cache := range caches {
+		sizes[cache] = cache.defaultSize
+
+		if configuredSize, ok := settings.CacheSizes[cache.name]; ok && configuredSize > 0 {
+			sizes[cache] = configuredSize
+		}
+	}
+
+	if settings.MemoryBudgetMB == nil || *settings.MemoryBudgetMB <= 0 {
+		return sizes
+	}
+
+	entryBytes := make(map[*Cache]int64, len(caches))
+	expectedBytes := int64(0)
+	for _, cache := range caches {
+		entryBytes[cache] = CACHE_DEFAULT_ENTRY_BYTES
+
+		if stats := cache.Stats(); stats.Len > 0 && stats.ApproximateBytes > 0 {
+			entryBytes[cache] = stats.ApproximateBytes / int64(stats.Len)
+		}
+
+		expectedBytes += entryBytes[cache] * int64(sizes[cache])
+	}
+
+	if expectedBytes == 0 {
+		return sizes
+	}
+
+	scale := float64(*settings.MemoryBudgetMB) * 1024 * 1024 / float64(expectedBytes)
+	for _, cache := range caches {
+		sizes[cache] = int(float64(sizes[cache]) * scale)
+
+		if sizes[cache] < CACHE_MIN_SIZE {
+			sizes[cache] = CACHE_MIN_SIZE
+		}
+	}
+
+	return sizes
+}
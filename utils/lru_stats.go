@@ -0,0 +1,153 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	CACHE_STATS_SAMPLE_SIZE = 100
+	CACHE_STATS_MAX_DEPTH   = 8
+)
+
+var namedCaches = map[string]*Cache{}
+var namedCachesLock sync.RWMutex
+
+// NewNamedLru creates an LRU of the given size whose statistics are reported under the given name
+// and which can be purged and resized by name. The size is only a default that CacheSettings can
+// override. A cache created later with the same name replaces it.
+func NewNamedLru(name string, size int) *Cache {
+	cache := NewLru(size)
+	cache.name = name
+
+	if Cfg != nil {
+		if configuredSize, ok := Cfg.CacheSettings.CacheSizes[name]; ok && configuredSize > 0 {
+			cache.Resize(configuredSize)
+		}
+	}
+
+	namedCachesLock.Lock()
+	namedCaches[name] = cache
+	namedCachesLock.Unlock()
+
+	return cache
+}
+
+// GetNamedCacheStats returns the statistics of every named cache, sorted by name.
+func GetNamedCacheStats() []*model.CacheStats {
+	namedCachesLock.RLock()
+	caches := make([]*Cache, 0, len(namedCaches))
+	for _, cache := range namedCaches {
+		caches = append(caches, cache)
+	}
+	namedCachesLock.RUnlock()
+
+	stats := make([]*model.CacheStats, 0, len(caches))
+	for _, cache := range caches {
+		stats = append(stats, cache.Stats())
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+// PurgeNamedCache purges the named cache and returns false if there isn't one with that name.
+func PurgeNamedCache(name string) bool {
+	namedCachesLock.RLock()
+	cache, ok := namedCaches[name]
+	namedCachesLock.RUnlock()
+
+	if ok {
+		cache.Purge()
+	}
+
+	return ok
+}
+
+// Stats returns the usage of the cache since it was created. The memory used by the cache is
+// extrapolated from the size of its CACHE_STATS_SAMPLE_SIZE most recently used entries.
+func (c *Cache) Stats() *model.CacheStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := &model.CacheStats{
+		Name:      c.name,
+		Size:      c.size,
+		Len:       c.evictList.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+
+	if c.hits+c.misses > 0 {
+		stats.HitRatio = float64(c.hits) / float64(c.hits+c.misses)
+	}
+
+	sampled := 0
+	sampleBytes := int64(0)
+	for ent := c.evictList.Front(); ent != nil && sampled < CACHE_STATS_SAMPLE_SIZE; ent = ent.Next() {
+		kv := ent.Value.(*entry)
+		sampleBytes += approximateSize(reflect.ValueOf(kv.key), 0) + approximateSize(reflect.ValueOf(kv.value), 0)
+		sampled++
+	}
+
+	if sampled > 0 {
+		stats.ApproximateBytes = sampleBytes / int64(sampled) * int64(stats.Len)
+	}
+
+	return stats
+}
+
+// approximateSize returns the number of bytes used by a value and the values it refers to. Shared
+// references are counted each time they're found and anything past CACHE_STATS_MAX_DEPTH is ignored.
+func approximateSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+
+	return int64(v.Type().Size()) + approximateIndirectSize(v, depth)
+}
+
+func approximateIndirectSize(v reflect.Value, depth int) int64 {
+	if depth > CACHE_STATS_MAX_DEPTH {
+		return 0
+	}
+
+	size := int64(0)
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			size += approximateSize(v.Elem(), depth+1)
+		}
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			size += approximateSize(v.Index(i), depth+1)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			size += approximateIndirectSize(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			size += approximateSize(key, depth+1) + approximateSize(v.MapIndex(key), depth+1)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			size += approximateIndirectSize(v.Field(i), depth+1)
+		}
+	}
+
+	return size
+}
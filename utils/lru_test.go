@@ -10,6 +10,8 @@ package utils
 import "testing"
 import "time"
 
+import "github.com/mattermost/platform/model"
+
 func TestLRU(t *testing.T) {
 	evictCounter := 0
 	onEvicted := func(k interface{}, v interface{}) {
@@ -110,3 +112,106 @@ func TestLRUExpire(t *testing.T) {
 		t.Fatal("should exist")
 	}
 }
+
+func TestLRUStats(t *testing.T) {
+	l := NewNamedLru("test_lru_stats", 2)
+
+	l.Add("a", "value a")
+	l.Add("b", "value b")
+	l.Add("c", "value c")
+
+	l.Get("b")
+	l.Get("c")
+	l.Get("a")
+
+	stats := l.Stats()
+	if stats.Name != "test_lru_stats" || stats.Size != 2 || stats.Len != 2 {
+		t.Fatal("should have returned the size of the cache", stats)
+	}
+
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Fatal("should have counted the hits, misses and evictions", stats)
+	}
+
+	if stats.ApproximateBytes <= 0 {
+		t.Fatal("should have estimated the memory used by the entries")
+	}
+
+	found := false
+	for _, s := range GetNamedCacheStats() {
+		if s.Name == "test_lru_stats" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should have registered the cache")
+	}
+
+	if !PurgeNamedCache("test_lru_stats") || l.Len() != 0 {
+		t.Fatal("should have purged the cache")
+	}
+
+	if PurgeNamedCache("junk") {
+		t.Fatal("should not purge an unknown cache")
+	}
+}
+
+func TestLRUResize(t *testing.T) {
+	l := NewLru(4)
+
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+
+	if evicted := l.Resize(2); evicted != 2 || l.Len() != 2 {
+		t.Fatal("should have evicted the oldest entries")
+	}
+
+	if _, ok := l.Get(0); ok {
+		t.Fatal("should have evicted the oldest entry")
+	}
+
+	if _, ok := l.Get(3); !ok {
+		t.Fatal("should have kept the newest entry")
+	}
+
+	if evicted := l.Resize(8); evicted != 0 {
+		t.Fatal("should not evict when growing")
+	}
+
+	for i := 0; i < 8; i++ {
+		l.Add(i, i)
+	}
+
+	if l.Len() != 8 {
+		t.Fatal("should hold the new number of entries")
+	}
+}
+
+func TestNamedCacheSizes(t *testing.T) {
+	small := NewLru(1000)
+	small.name = "small"
+	large := NewLru(4000)
+	large.name = "large"
+	caches := []*Cache{small, large}
+
+	settings := &model.CacheSettings{MemoryBudgetMB: new(int), CacheSizes: map[string]int{"small": 2000}}
+
+	sizes := getNamedCacheSizes(settings, caches)
+	if sizes[small] != 2000 || sizes[large] != 4000 {
+		t.Fatal("should have used the configured and default sizes", sizes)
+	}
+
+	// The caches are expected to use 6000 entries of CACHE_DEFAULT_ENTRY_BYTES before scaling
+	*settings.MemoryBudgetMB = 3
+	sizes = getNamedCacheSizes(settings, caches)
+	if sizes[small] < 1000 || sizes[small] > 1030 || sizes[large] < 2000 || sizes[large] > 2060 {
+		t.Fatal("should have scaled the sizes together", sizes)
+	}
+
+	budget := int64(*settings.MemoryBudgetMB) * 1024 * 1024
+	if used := int64(sizes[small]+sizes[large]) * CACHE_DEFAULT_ENTRY_BYTES; used > budget {
+		t.Fatal("should have fit the caches in the budget", used, budget)
+	}
+}
@@ -122,6 +122,22 @@ func SendMailUsingConfig(to, subject, body string, config *model.Config) *model.
 	}
 	message += "\r\n<html><body>" + body + "</body></html>"
 
+	return sendMessage(fromMail.Address, toMail.Address, []byte(message), config)
+}
+
+// SendRawMailUsingConfig sends a message that already has its headers to an address, using the
+// SMTP server of the config even when email notifications are disabled.
+func SendRawMailUsingConfig(to string, message []byte, config *model.Config) *model.AppError {
+	if len(config.EmailSettings.SMTPServer) == 0 {
+		return model.NewLocAppError("SendRawMail", "utils.mail.send_raw_mail.no_server.app_error", nil, "")
+	}
+
+	l4g.Debug(T("utils.mail.send_raw_mail.sending.debug"), to)
+
+	return sendMessage(config.EmailSettings.FeedbackEmail, to, message, config)
+}
+
+func sendMessage(from, to string, message []byte, config *model.Config) *model.AppError {
 	conn, err1 := connectToSMTPServer(config)
 	if err1 != nil {
 		return err1
@@ -135,11 +151,11 @@ func SendMailUsingConfig(to, subject, body string, config *model.Config) *model.
 	defer c.Quit()
 	defer c.Close()
 
-	if err := c.Mail(fromMail.Address); err != nil {
+	if err := c.Mail(from); err != nil {
 		return model.NewLocAppError("SendMail", "utils.mail.send_mail.from_address.app_error", nil, err.Error())
 	}
 
-	if err := c.Rcpt(toMail.Address); err != nil {
+	if err := c.Rcpt(to); err != nil {
 		return model.NewLocAppError("SendMail", "utils.mail.send_mail.to_address.app_error", nil, err.Error())
 	}
 
@@ -148,7 +164,7 @@ func SendMailUsingConfig(to, subject, body string, config *model.Config) *model.
 		return model.NewLocAppError("SendMail", "utils.mail.send_mail.msg_data.app_error", nil, err.Error())
 	}
 
-	_, err = w.Write([]byte(message))
+	_, err = w.Write(message)
 	if err != nil {
 		return model.NewLocAppError("SendMail", "utils.mail.send_mail.msg.app_error", nil, err.Error())
 	}
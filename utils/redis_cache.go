@@ -0,0 +1,352 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+)
+
+const (
+	REDIS_MAX_IDLE_CONNECTIONS = 16
+	REDIS_TIMEOUT              = 5 * time.Second
+	REDIS_SCAN_COUNT           = 1000
+	REDIS_KEY_PREFIX           = "mattermost:cache:"
+)
+
+var sharedRedisClient *RedisClient
+var sharedRedisClientLock sync.Mutex
+
+// GetRedisClient returns a client for the Redis server in CacheSettings, replacing the previous
+// one if the settings have changed.
+func GetRedisClient() *RedisClient {
+	sharedRedisClientLock.Lock()
+	defer sharedRedisClientLock.Unlock()
+
+	address := *Cfg.CacheSettings.RedisAddress
+	password := *Cfg.CacheSettings.RedisPassword
+	database := *Cfg.CacheSettings.RedisDatabase
+
+	if sharedRedisClient == nil || sharedRedisClient.address != address || sharedRedisClient.password != password || sharedRedisClient.database != database {
+		if sharedRedisClient != nil {
+			sharedRedisClient.Close()
+		}
+		sharedRedisClient = NewRedisClient(address, password, database)
+	}
+
+	return sharedRedisClient
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// RedisClient is a small client for the parts of the Redis protocol used by the cache. It keeps a
+// pool of idle connections and dials new ones as needed.
+type RedisClient struct {
+	address  string
+	password string
+	database int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisClient(address, password string, database int) *RedisClient {
+	return &RedisClient{
+		address:  address,
+		password: password,
+		database: database,
+		idle:     make(chan *redisConn, REDIS_MAX_IDLE_CONNECTIONS),
+	}
+}
+
+// Do sends a command to Redis and returns its reply, which is nil, a string, an int64 or a
+// slice of replies.
+func (c *RedisClient) Do(args ...string) (interface{}, error) {
+	conn, err := c.getConn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		conn.Close()
+		return nil, err
+	}
+
+	c.putConn(conn)
+	return reply, err
+}
+
+// Close closes the idle connections. Connections in use are closed when they're returned.
+func (c *RedisClient) Close() {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *RedisClient) getConn() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	netConn, err := net.DialTimeout("tcp", c.address, REDIS_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if len(c.password) > 0 {
+		if _, err := conn.do("AUTH", c.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if c.database != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(c.database)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *RedisClient) putConn(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (conn *redisConn) do(args ...string) (interface{}, error) {
+	conn.SetDeadline(time.Now().Add(REDIS_TIMEOUT))
+
+	command := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		command += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(conn, command); err != nil {
+		return nil, err
+	}
+
+	return readRedisReply(conn.reader)
+}
+
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, errors.New("redis: malformed reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		} else if length < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		} else if length < 0 {
+			return nil, nil
+		}
+
+		replies := make([]interface{}, length)
+		for i := range replies {
+			if replies[i], err = readRedisReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return replies, nil
+	}
+
+	return nil, errors.New("redis: unknown reply type " + line[:1])
+}
+
+// RedisCache stores JSON encoded values in Redis under keys named after the cache. Values are
+// decoded back into the type the cache was created with, so callers can treat it like the LRU.
+// Redis is only a cache, so when it can't be reached lookups miss and writes are dropped.
+type RedisCache struct {
+	client    *RedisClient
+	name      string
+	prefix    string
+	valueType reflect.Type
+}
+
+func NewRedisCache(client *RedisClient, name string, valueType interface{}) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		name:      name,
+		prefix:    REDIS_KEY_PREFIX + name + ":",
+		valueType: reflect.TypeOf(valueType),
+	}
+}
+
+func (c *RedisCache) Add(key, value interface{}) bool {
+	return c.AddWithExpiresInSecs(key, value, 0)
+}
+
+// AddWithExpiresInSecs adds a value to the cache. Redis evicts keys by itself, so this never
+// reports an eviction.
+func (c *RedisCache) AddWithExpiresInSecs(key, value interface{}, expireAtSecs int64) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		l4g.Error(T("utils.redis_cache.encode.error"), c.name, err.Error())
+		return false
+	}
+
+	args := []string{"SET", c.key(key), string(data)}
+	if expireAtSecs > 0 {
+		args = append(args, "EX", strconv.FormatInt(expireAtSecs, 10))
+	}
+
+	if _, err := c.client.Do(args...); err != nil {
+		l4g.Error(T("utils.redis_cache.request.error"), c.name, err.Error())
+	}
+
+	return false
+}
+
+func (c *RedisCache) Get(key interface{}) (value interface{}, ok bool) {
+	reply, err := c.client.Do("GET", c.key(key))
+	if err != nil {
+		l4g.Error(T("utils.redis_cache.request.error"), c.name, err.Error())
+		return nil, false
+	}
+
+	data, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+
+	ptr := reflect.New(c.valueType)
+	if err := json.Unmarshal([]byte(data), ptr.Interface()); err != nil {
+		l4g.Error(T("utils.redis_cache.decode.error"), c.name, err.Error())
+		return nil, false
+	}
+
+	return ptr.Elem().Interface(), true
+}
+
+func (c *RedisCache) Remove(key interface{}) {
+	if _, err := c.client.Do("DEL", c.key(key)); err != nil {
+		l4g.Error(T("utils.redis_cache.request.error"), c.name, err.Error())
+	}
+}
+
+// Purge removes every key belonging to this cache.
+func (c *RedisCache) Purge() {
+	keys := c.scan()
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > REDIS_SCAN_COUNT {
+			batch = batch[:REDIS_SCAN_COUNT]
+		}
+		keys = keys[len(batch):]
+
+		if _, err := c.client.Do(append([]string{"DEL"}, batch...)...); err != nil {
+			l4g.Error(T("utils.redis_cache.request.error"), c.name, err.Error())
+			return
+		}
+	}
+}
+
+// Keys returns the keys currently in this cache. Redis doesn't keep them in any order.
+func (c *RedisCache) Keys() []interface{} {
+	keys := c.scan()
+
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		result[i] = strings.TrimPrefix(key, c.prefix)
+	}
+
+	return result
+}
+
+func (c *RedisCache) Len() int {
+	return len(c.scan())
+}
+
+func (c *RedisCache) key(key interface{}) string {
+	return c.prefix + fmt.Sprint(key)
+}
+
+// scan returns the full Redis names of the keys in this cache.
+func (c *RedisCache) scan() []string {
+	keys := []string{}
+	cursor := "0"
+
+	for {
+		reply, err := c.client.Do("SCAN", cursor, "MATCH", c.prefix+"*", "COUNT", strconv.Itoa(REDIS_SCAN_COUNT))
+		if err != nil {
+			l4g.Error(T("utils.redis_cache.request.error"), c.name, err.Error())
+			return keys
+		}
+
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return keys
+		}
+
+		matches, _ := parts[1].([]interface{})
+		for _, match := range matches {
+			if key, ok := match.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+
+		if cursor, _ = parts[0].(string); cursor == "0" || cursor == "" {
+			return keys
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+// startFakeRedis serves the handful of commands used by RedisCache from a map.
+func startFakeRedis(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]string{}
+	var lock sync.Mutex
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+
+				for {
+					reply, err := readRedisReply(reader)
+					if err != nil {
+						return
+					}
+
+					args := []string{}
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, arg.(string))
+					}
+
+					lock.Lock()
+					switch strings.ToUpper(args[0]) {
+					case "SET":
+						data[args[1]] = args[2]
+						fmt.Fprint(conn, "+OK\r\n")
+					case "GET":
+						if value, ok := data[args[1]]; ok {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+						} else {
+							fmt.Fprint(conn, "$-1\r\n")
+						}
+					case "DEL":
+						count := 0
+						for _, key := range args[1:] {
+							if _, ok := data[key]; ok {
+								delete(data, key)
+								count++
+							}
+						}
+						fmt.Fprintf(conn, ":%d\r\n", count)
+					case "SCAN":
+						prefix := strings.TrimSuffix(args[3], "*")
+						keys := []string{}
+						for key := range data {
+							if strings.HasPrefix(key, prefix) {
+								keys = append(keys, key)
+							}
+						}
+						fmt.Fprintf(conn, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+						for _, key := range keys {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(key), key)
+						}
+					default:
+						fmt.Fprint(conn, "-ERR unknown command\r\n")
+					}
+					lock.Unlock()
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func TestRedisCache(t *testing.T) {
+	listener := startFakeRedis(t)
+	defer listener.Close()
+
+	client := NewRedisClient(listener.Addr().String(), "", 0)
+	defer client.Close()
+
+	users := NewRedisCache(client, "users", &model.User{})
+	counts := NewRedisCache(client, "counts", int64(0))
+
+	if _, ok := users.Get("missing"); ok {
+		t.Fatal("should have missed")
+	}
+
+	user := &model.User{Id: model.NewId(), Username: "redis"}
+	users.AddWithExpiresInSecs(user.Id, user, 60)
+	counts.Add("a", int64(5))
+	counts.Add("b", int64(7))
+
+	if value, ok := users.Get(user.Id); !ok {
+		t.Fatal("should have found the user")
+	} else if cached := value.(*model.User); cached == user || cached.Username != user.Username {
+		t.Fatal("should have decoded a copy of the user")
+	}
+
+	if value, ok := counts.Get("b"); !ok || value.(int64) != 7 {
+		t.Fatal("should have found the count")
+	}
+
+	if counts.Len() != 2 || users.Len() != 1 {
+		t.Fatal("each cache should only see its own keys")
+	}
+
+	if keys := users.Keys(); len(keys) != 1 || keys[0] != user.Id {
+		t.Fatal("should have returned the key without the prefix")
+	}
+
+	counts.Remove("a")
+	if _, ok := counts.Get("a"); ok {
+		t.Fatal("should have removed the count")
+	}
+
+	counts.Purge()
+	if counts.Len() != 0 || users.Len() != 1 {
+		t.Fatal("should only have purged the counts")
+	}
+
+	if _, err := client.Do("BOGUS"); err == nil {
+		t.Fatal("should have returned the error reply")
+	} else if _, ok := users.Get(user.Id); !ok {
+		t.Fatal("error replies should not break the connection")
+	}
+}
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	TranslationsPreInit()
+
+	listener := startFakeRedis(t)
+	address := listener.Addr().String()
+	listener.Close()
+
+	cache := NewRedisCache(NewRedisClient(address, "", 0), "users", &model.User{})
+	cache.Add("a", &model.User{})
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("should miss when redis is unavailable")
+	}
+
+	if cache.Len() != 0 {
+		t.Fatal("should be empty when redis is unavailable")
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"golang.org/x/crypto/ssh"
+)
+
+// Only the parts of version 3 of the SFTP protocol that are needed to upload a file are implemented.
+const (
+	SFTP_VERSION = 3
+
+	SFTP_PACKET_INIT    = 1
+	SFTP_PACKET_VERSION = 2
+	SFTP_PACKET_OPEN    = 3
+	SFTP_PACKET_CLOSE   = 4
+	SFTP_PACKET_WRITE   = 6
+	SFTP_PACKET_STATUS  = 101
+	SFTP_PACKET_HANDLE  = 102
+
+	SFTP_OPEN_WRITE    = 0x02
+	SFTP_OPEN_CREATE   = 0x08
+	SFTP_OPEN_TRUNCATE = 0x10
+
+	SFTP_STATUS_OK = 0
+
+	SFTP_MAX_PACKET_SIZE = 256 * 1024
+	SFTP_WRITE_SIZE      = 32 * 1024
+	SFTP_DIAL_TIMEOUT    = 30 * time.Second
+)
+
+// UploadFileWithSftp uploads a file to an SFTP server that's authenticated with its public key,
+// which is given in the authorized_keys format, replacing the file if it already exists.
+func UploadFileWithSftp(server, username, password, hostKey, path string, data []byte) *model.AppError {
+	expectedKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.host_key.app_error", nil, err.Error())
+	}
+
+	config := &ssh.ClientConfig{
+		User: username,
+		Auth: []ssh.AuthMethod{ssh.Password(password)},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if !bytes.Equal(key.Marshal(), expectedKey.Marshal()) {
+				return errors.New("host key mismatch")
+			}
+
+			return nil
+		},
+		Timeout: SFTP_DIAL_TIMEOUT,
+	}
+
+	client, err := ssh.Dial("tcp", server, config)
+	if err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.connect.app_error", map[string]interface{}{"Server": server}, err.Error())
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.connect.app_error", map[string]interface{}{"Server": server}, err.Error())
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.connect.app_error", map[string]interface{}{"Server": server}, err.Error())
+	}
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.connect.app_error", map[string]interface{}{"Server": server}, err.Error())
+	}
+
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.connect.app_error", map[string]interface{}{"Server": server}, err.Error())
+	}
+
+	if err := newSftpClient(r, w).writeFile(path, data); err != nil {
+		return model.NewLocAppError("UploadFileWithSftp", "utils.sftp.write.app_error", map[string]interface{}{"Path": path}, err.Error())
+	}
+
+	return nil
+}
+
+type sftpClient struct {
+	r      io.Reader
+	w      io.Writer
+	nextId uint32
+}
+
+func newSftpClient(r io.Reader, w io.Writer) *sftpClient {
+	return &sftpClient{r: r, w: w}
+}
+
+// writeFile starts an SFTP session and writes the file, waiting for the server to acknowledge every
+// request before sending the next one.
+func (c *sftpClient) writeFile(path string, data []byte) error {
+	if err := c.init(); err != nil {
+		return err
+	}
+
+	handle, err := c.open(path)
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; offset < len(data); offset += SFTP_WRITE_SIZE {
+		end := offset + SFTP_WRITE_SIZE
+		if end > len(data) {
+			end = len(data)
+		}
+
+		payload := appendSftpString(nil, handle)
+		payload = appendSftpUint64(payload, uint64(offset))
+		payload = appendSftpString(payload, string(data[offset:end]))
+
+		if err := c.request(SFTP_PACKET_WRITE, payload); err != nil {
+			c.request(SFTP_PACKET_CLOSE, appendSftpString(nil, handle))
+			return err
+		}
+	}
+
+	return c.request(SFTP_PACKET_CLOSE, appendSftpString(nil, handle))
+}
+
+func (c *sftpClient) init() error {
+	if err := c.writePacket(SFTP_PACKET_INIT, appendSftpUint32(nil, SFTP_VERSION)); err != nil {
+		return err
+	}
+
+	packetType, payload, err := c.readPacket()
+	if err != nil {
+		return err
+	} else if packetType != SFTP_PACKET_VERSION || len(payload) < 4 {
+		return fmt.Errorf("unexpected sftp packet %v", packetType)
+	} else if version := binary.BigEndian.Uint32(payload); version < SFTP_VERSION {
+		return fmt.Errorf("unsupported sftp version %v", version)
+	}
+
+	return nil
+}
+
+func (c *sftpClient) open(path string) (string, error) {
+	id := c.nextRequestId()
+
+	payload := appendSftpUint32(nil, id)
+	payload = appendSftpString(payload, path)
+	payload = appendSftpUint32(payload, SFTP_OPEN_WRITE|SFTP_OPEN_CREATE|SFTP_OPEN_TRUNCATE)
+	payload = appendSftpUint32(payload, 0) // No attributes
+
+	if err := c.writePacket(SFTP_PACKET_OPEN, payload); err != nil {
+		return "", err
+	}
+
+	packetType, response, err := c.readResponse(id)
+	if err != nil {
+		return "", err
+	}
+
+	switch packetType {
+	case SFTP_PACKET_HANDLE:
+		if handle, _, ok := readSftpString(response); ok {
+			return handle, nil
+		}
+
+		return "", errors.New("invalid sftp handle")
+	case SFTP_PACKET_STATUS:
+		return "", sftpStatusError(response)
+	default:
+		return "", fmt.Errorf("unexpected sftp packet %v", packetType)
+	}
+}
+
+// request sends a request whose payload follows its id and waits for the server to answer it with
+// a successful status.
+func (c *sftpClient) request(packetType byte, payload []byte) error {
+	id := c.nextRequestId()
+
+	if err := c.writePacket(packetType, append(appendSftpUint32(nil, id), payload...)); err != nil {
+		return err
+	}
+
+	responseType, response, err := c.readResponse(id)
+	if err != nil {
+		return err
+	} else if responseType != SFTP_PACKET_STATUS {
+		return fmt.Errorf("unexpected sftp packet %v", responseType)
+	}
+
+	return sftpStatusError(response)
+}
+
+func (c *sftpClient) nextRequestId() uint32 {
+	c.nextId++
+	return c.nextId
+}
+
+// readResponse reads the response to a request and returns it without its id.
+func (c *sftpClient) readResponse(id uint32) (byte, []byte, error) {
+	packetType, payload, err := c.readPacket()
+	if err != nil {
+		return 0, nil, err
+	} else if len(payload) < 4 || binary.BigEndian.Uint32(payload) != id {
+		return 0, nil, errors.New("unexpected sftp response")
+	}
+
+	return packetType, payload[4:], nil
+}
+
+func (c *sftpClient) writePacket(packetType byte, payload []byte) error {
+	packet := appendSftpUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, packetType)
+	packet = append(packet, payload...)
+
+	_, err := c.w.Write(packet)
+	return err
+}
+
+func (c *sftpClient) readPacket() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	if length == 0 || length > SFTP_MAX_PACKET_SIZE {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %v", length)
+	}
+
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[4], payload, nil
+}
+
+// sftpStatusError returns the error of a status response, or nil if the request succeeded.
+func sftpStatusError(response []byte) error {
+	if len(response) < 4 {
+		return errors.New("invalid sftp status")
+	}
+
+	code := binary.BigEndian.Uint32(response)
+	if code == SFTP_STATUS_OK {
+		return nil
+	}
+
+	message, _, _ := readSftpString(response[4:])
+	return fmt.Errorf("sftp error %v: %v", code, message)
+}
+
+func appendSftpUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendSftpUint64(b []byte, v uint64) []byte {
+	return appendSftpUint32(appendSftpUint32(b, uint32(v>>32)), uint32(v))
+}
+
+func appendSftpString(b []byte, s string) []byte {
+	return append(appendSftpUint32(b, uint32(len(s))), s...)
+}
+
+func readSftpString(b []byte) (string, []byte, bool) {
+	if len(b) < 4 {
+		return "", nil, false
+	}
+
+	length := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < length {
+		return "", nil, false
+	}
+
+	return string(b[4 : 4+length]), b[4+length:], true
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeSftpServer answers the requests of an sftpClient and keeps the files that are written to it.
+type fakeSftpServer struct {
+	client    *sftpClient
+	files     map[string][]byte
+	denyOpens bool
+}
+
+func newFakeSftpServer() (*fakeSftpServer, *sftpClient) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server := &fakeSftpServer{
+		client: newSftpClient(serverReader, serverWriter),
+		files:  make(map[string][]byte),
+	}
+
+	go server.serve(serverWriter)
+
+	return server, newSftpClient(clientReader, clientWriter)
+}
+
+func (s *fakeSftpServer) serve(w *io.PipeWriter) {
+	defer w.Close()
+
+	handles := make(map[string]string)
+
+	for {
+		packetType, payload, err := s.client.readPacket()
+		if err != nil {
+			return
+		}
+
+		if packetType == SFTP_PACKET_INIT {
+			s.client.writePacket(SFTP_PACKET_VERSION, appendSftpUint32(nil, SFTP_VERSION))
+			continue
+		}
+
+		id := binary.BigEndian.Uint32(payload)
+		handleOrPath, rest, _ := readSftpString(payload[4:])
+		status := uint32(SFTP_STATUS_OK)
+
+		switch packetType {
+		case SFTP_PACKET_OPEN:
+			if s.denyOpens {
+				status = 3
+				break
+			}
+
+			handle := "handle" + handleOrPath
+			handles[handle] = handleOrPath
+			s.files[handleOrPath] = nil
+			s.client.writePacket(SFTP_PACKET_HANDLE, appendSftpString(appendSftpUint32(nil, id), handle))
+			continue
+		case SFTP_PACKET_WRITE:
+			offset := binary.BigEndian.Uint64(rest)
+			data, _, _ := readSftpString(rest[8:])
+
+			path := handles[handleOrPath]
+			if offset != uint64(len(s.files[path])) {
+				status = 4
+				break
+			}
+
+			s.files[path] = append(s.files[path], data...)
+		case SFTP_PACKET_CLOSE:
+			delete(handles, handleOrPath)
+		}
+
+		response := appendSftpUint32(appendSftpUint32(nil, id), status)
+		response = appendSftpString(response, "failed")
+		response = appendSftpString(response, "en")
+		s.client.writePacket(SFTP_PACKET_STATUS, response)
+	}
+}
+
+func TestSftpClientWriteFile(t *testing.T) {
+	server, client := newFakeSftpServer()
+
+	data := bytes.Repeat([]byte("0123456789"), SFTP_WRITE_SIZE/4)
+	if err := client.writeFile("/exports/export.xml", data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(server.files["/exports/export.xml"], data) {
+		t.Fatal("should have written the whole file", len(server.files["/exports/export.xml"]))
+	}
+
+	server, client = newFakeSftpServer()
+	server.denyOpens = true
+
+	if err := client.writeFile("/exports/export.xml", data); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatal("should have failed to open the file", err)
+	}
+}
+
+func TestUploadFileWithSftp(t *testing.T) {
+	if err := UploadFileWithSftp("localhost:22", "user", "password", "junk", "export.xml", []byte("data")); err == nil || err.Id != "utils.sftp.host_key.app_error" {
+		t.Fatal("should have failed to parse the host key", err)
+	}
+}
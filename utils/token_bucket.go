@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucketLimiter rate limits requests separately for each key. Every key has a bucket that
+// holds up to maxBurst tokens and refills at perMinute tokens a minute, so a client can make a
+// burst of requests after being idle without being allowed a higher sustained rate.
+type TokenBucketLimiter struct {
+	perMinute int
+	maxBurst  int
+	buckets   *Cache
+	lock      sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewTokenBucketLimiter creates a limiter that tracks at most size keys, forgetting the least
+// recently used ones first.
+func NewTokenBucketLimiter(perMinute int, maxBurst int, size int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		perMinute: perMinute,
+		maxBurst:  maxBurst,
+		buckets:   NewLru(size),
+	}
+}
+
+// Allow takes a token from the key's bucket. When the bucket is empty it returns false along with
+// how long to wait until a token will be available.
+func (l *TokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	return l.allowAt(key, time.Now())
+}
+
+func (l *TokenBucketLimiter) allowAt(key string, now time.Time) (bool, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	perNanosecond := float64(l.perMinute) / float64(time.Minute)
+
+	var bucket *tokenBucket
+	if value, ok := l.buckets.Get(key); ok {
+		bucket = value.(*tokenBucket)
+
+		if elapsed := now.Sub(bucket.lastRefill); elapsed > 0 {
+			bucket.tokens = math.Min(float64(l.maxBurst), bucket.tokens+float64(elapsed)*perNanosecond)
+			bucket.lastRefill = now
+		}
+	} else {
+		bucket = &tokenBucket{tokens: float64(l.maxBurst), lastRefill: now}
+		l.buckets.Add(key, bucket)
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration(math.Ceil((1 - bucket.tokens) / perNanosecond))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	limiter := NewTokenBucketLimiter(60, 3, 10)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.allowAt("a", now); !allowed {
+			t.Fatal("should allow a burst up to the maximum")
+		}
+	}
+
+	if allowed, retryAfter := limiter.allowAt("a", now); allowed {
+		t.Fatal("should have run out of tokens")
+	} else if retryAfter != time.Second {
+		t.Fatal("should retry once a token has been refilled", retryAfter)
+	}
+
+	if allowed, _ := limiter.allowAt("b", now); !allowed {
+		t.Fatal("each key should have its own bucket")
+	}
+
+	if allowed, _ := limiter.allowAt("a", now.Add(time.Second)); !allowed {
+		t.Fatal("should have refilled a token")
+	}
+
+	if allowed, _ := limiter.allowAt("a", now.Add(time.Second)); allowed {
+		t.Fatal("should only have refilled one token")
+	}
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.allowAt("a", now.Add(time.Hour)); !allowed {
+			t.Fatal("should have refilled the bucket")
+		}
+	}
+
+	if allowed, _ := limiter.allowAt("a", now.Add(time.Hour)); allowed {
+		t.Fatal("shouldn't refill past the maximum burst")
+	}
+}
@@ -244,7 +244,9 @@ export function allowOAuth2(params, success, error) {
             if (error) {
                 error(err);
             }
-        }
+        },
+        params.code_challenge,
+        params.code_challenge_method
     );
 }
 
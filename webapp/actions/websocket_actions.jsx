@@ -69,6 +69,8 @@ export function initialize() {
         reconnect(false);
     });
     WebSocketClient.setCloseCallback(handleClose);
+    WebSocketClient.setEventSourceUrl(getSiteURL() + '/api/v4/events');
+    WebSocketClient.setPollUrl(getSiteURL() + '/api/v4/events/poll');
     WebSocketClient.initialize(connUrl);
 }
 
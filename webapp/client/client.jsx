@@ -2014,7 +2014,13 @@ export default class Client {
         this.trackEvent('api', 'api_apps_register');
     }
 
-    allowOAuth2(responseType, clientId, redirectUri, state, scope, success, error) {
+    allowOAuth2(responseType, clientId, redirectUri, state, scope, success, error, codeChallenge, codeChallengeMethod) {
+        const pkce = {};
+        if (codeChallenge) {
+            pkce.code_challenge = codeChallenge;
+            pkce.code_challenge_method = codeChallengeMethod;
+        }
+
         request.
             get(`${this.getOAuthRoute()}/allow`).
             set(this.defaultHeaders).
@@ -2025,6 +2031,7 @@ export default class Client {
             query({redirect_uri: redirectUri}).
             query({scope}).
             query({state}).
+            query(pkce).
             end(this.handleResponse.bind(this, 'allowOAuth2', success, error));
     }
 
@@ -5,10 +5,23 @@ const MAX_WEBSOCKET_FAILS = 7;
 const MIN_WEBSOCKET_RETRY_TIME = 3000; // 3 sec
 const MAX_WEBSOCKET_RETRY_TIME = 300000; // 5 mins
 
+// Number of failed attempts to open a first websocket before falling back to server-sent events,
+// and then to open a first server-sent events stream before falling back to long polling
+const EVENT_SOURCE_FALLBACK_FAILS = 2;
+const POLLING_FALLBACK_FAILS = 2;
+
 export default class WebSocketClient {
     constructor() {
         this.conn = null;
         this.connectionUrl = null;
+        this.eventSourceUrl = null;
+        this.usingEventSource = false;
+        this.websocketOpened = false;
+        this.eventSourceOpened = false;
+        this.eventSourceFailCount = 0;
+        this.pollUrl = null;
+        this.usingPolling = false;
+        this.pollSince = -1;
         this.sequence = 1;
         this.eventSequence = 0;
         this.connectFailCount = 0;
@@ -35,11 +48,20 @@ export default class WebSocketClient {
             console.log('websocket connecting to ' + connectionUrl); //eslint-disable-line no-console
         }
 
+        if (this.usingPolling) {
+            this.initializePolling();
+            return;
+        } else if (this.usingEventSource) {
+            this.initializeEventSource();
+            return;
+        }
+
         this.conn = new WebSocket(connectionUrl);
         this.connectionUrl = connectionUrl;
 
         this.conn.onopen = () => {
             this.eventSequence = 0;
+            this.websocketOpened = true;
 
             if (token) {
                 this.sendMessage('authentication_challenge', {token});
@@ -71,6 +93,15 @@ export default class WebSocketClient {
                 this.closeCallback(this.connectFailCount);
             }
 
+            // Some proxies break websockets entirely, in which case the events are received as
+            // server-sent events instead
+            if (!this.websocketOpened && this.eventSourceUrl && window.EventSource && this.connectFailCount >= EVENT_SOURCE_FALLBACK_FAILS) {
+                console.log('websocket unavailable, falling back to server-sent events'); //eslint-disable-line no-console
+                this.usingEventSource = true;
+                this.initializeEventSource();
+                return;
+            }
+
             let retryTime = MIN_WEBSOCKET_RETRY_TIME;
 
             // If we've failed a bunch of connections then start backing off
@@ -100,26 +131,178 @@ export default class WebSocketClient {
             }
         };
 
-        this.conn.onmessage = (evt) => {
-            const msg = JSON.parse(evt.data);
-            if (msg.seq_reply) {
-                if (msg.error) {
-                    console.log(msg); //eslint-disable-line no-console
+        this.conn.onmessage = (evt) => this.handleMessage(JSON.parse(evt.data));
+    }
+
+    // initializeEventSource receives the events as server-sent events. The browser reconnects by
+    // itself and sends the id of the last event it received, so the server replays the events that
+    // were missed. If it can't, the connection starts a new sequence and the missed event callback
+    // is called.
+    initializeEventSource() {
+        if (this.conn) {
+            return;
+        }
+
+        this.conn = new EventSource(this.eventSourceUrl, {withCredentials: true});
+
+        let opened = false;
+        this.conn.onopen = () => {
+            if (!opened) {
+                opened = true;
+                this.eventSourceOpened = true;
+                this.eventSequence = 0;
+                this.handleConnected('server-sent events');
+            }
+
+            this.connectFailCount = 0;
+        };
+
+        this.conn.onerror = (evt) => {
+            if (this.errorCallback) {
+                this.errorCallback(evt);
+            }
+
+            // The browser only gives up reconnecting if the server refused the connection
+            if (this.conn && this.conn.readyState === EventSource.CLOSED) {
+                this.conn = null;
+                this.connectFailCount++;
+                this.eventSourceFailCount++;
+
+                if (this.closeCallback) {
+                    this.closeCallback(this.connectFailCount);
                 }
 
-                if (this.responseCallbacks[msg.seq_reply]) {
-                    this.responseCallbacks[msg.seq_reply](msg);
-                    Reflect.deleteProperty(this.responseCallbacks, msg.seq_reply);
+                // Some networks block streamed responses too, in which case the events are polled
+                if (!this.eventSourceOpened && this.pollUrl && this.eventSourceFailCount >= POLLING_FALLBACK_FAILS) {
+                    console.log('server-sent events unavailable, falling back to long polling'); //eslint-disable-line no-console
+                    this.usingPolling = true;
+                    this.initializePolling();
+                    return;
                 }
-            } else if (this.eventCallback) {
-                if (msg.seq !== this.eventSequence && this.missedEventCallback) {
-                    console.log('missed websocket event, act_seq=' + msg.seq + ' exp_seq=' + this.eventSequence); //eslint-disable-line no-console
-                    this.missedEventCallback();
+
+                setTimeout(
+                    () => {
+                        this.initializeEventSource();
+                    },
+                    MIN_WEBSOCKET_RETRY_TIME
+                );
+            }
+        };
+
+        this.conn.onmessage = (evt) => this.handleMessage(JSON.parse(evt.data));
+    }
+
+    // initializePolling long-polls the server for the events that come after the last one received.
+    // The server keeps the events of the session in a queue between polls. The events that were
+    // queued before the first poll are skipped since the client fetched its data when it started.
+    // If the queue expired between polls, the events of a new queue are returned, which starts a new
+    // sequence, and the missed event callback is called.
+    initializePolling() {
+        if (this.conn) {
+            return;
+        }
+
+        const xhr = new XMLHttpRequest();
+        this.conn = xhr;
+
+        const failed = () => {
+            if (this.conn !== xhr) {
+                return;
+            }
+
+            this.conn = null;
+            this.connectFailCount++;
+
+            if (this.closeCallback) {
+                this.closeCallback(this.connectFailCount);
+            }
+
+            setTimeout(
+                () => {
+                    this.initializePolling();
+                },
+                MIN_WEBSOCKET_RETRY_TIME
+            );
+        };
+
+        xhr.onload = () => {
+            if (this.conn !== xhr) {
+                return;
+            }
+
+            if (xhr.status !== 200) {
+                failed();
+                return;
+            }
+
+            this.conn = null;
+
+            const events = JSON.parse(xhr.responseText);
+            const firstPoll = this.pollSince === -1;
+
+            if (firstPoll && events.length > 0) {
+                this.eventSequence = events[events.length - 1].seq + 1;
+            }
+
+            if (firstPoll || this.connectFailCount > 0) {
+                this.handleConnected('long polling');
+            }
+
+            this.connectFailCount = 0;
+
+            for (const msg of events) {
+                if (!firstPoll) {
+                    this.handleMessage(msg);
                 }
-                this.eventSequence = msg.seq + 1;
-                this.eventCallback(msg);
+
+                this.pollSince = msg.seq;
             }
+
+            this.initializePolling();
         };
+
+        xhr.onerror = failed;
+
+        xhr.open('GET', this.pollUrl + '?since=' + this.pollSince);
+        xhr.setRequestHeader('X-Requested-With', 'XMLHttpRequest');
+        xhr.send();
+    }
+
+    handleConnected(transport) {
+        if (this.connectFailCount > 0 && this.reconnectCallback) {
+            console.log(transport + ' established connection'); //eslint-disable-line no-console
+            this.reconnectCallback();
+        } else if (this.firstConnectCallback) {
+            this.firstConnectCallback();
+        }
+    }
+
+    handleMessage(msg) {
+        if (msg.seq_reply) {
+            if (msg.error) {
+                console.log(msg); //eslint-disable-line no-console
+            }
+
+            if (this.responseCallbacks[msg.seq_reply]) {
+                this.responseCallbacks[msg.seq_reply](msg);
+                Reflect.deleteProperty(this.responseCallbacks, msg.seq_reply);
+            }
+        } else if (this.eventCallback) {
+            if (msg.seq !== this.eventSequence && this.missedEventCallback) {
+                console.log('missed websocket event, act_seq=' + msg.seq + ' exp_seq=' + this.eventSequence); //eslint-disable-line no-console
+                this.missedEventCallback();
+            }
+            this.eventSequence = msg.seq + 1;
+            this.eventCallback(msg);
+        }
+    }
+
+    setEventSourceUrl(eventSourceUrl) {
+        this.eventSourceUrl = eventSourceUrl;
+    }
+
+    setPollUrl(pollUrl) {
+        this.pollUrl = pollUrl;
     }
 
     setEventCallback(callback) {
@@ -149,7 +332,16 @@ export default class WebSocketClient {
     close() {
         this.connectFailCount = 0;
         this.sequence = 1;
-        if (this.conn && this.conn.readyState === WebSocket.OPEN) {
+        if (this.usingPolling && this.conn) {
+            const xhr = this.conn;
+            this.conn = null;
+            xhr.abort();
+            console.log('long polling stopped'); //eslint-disable-line no-console
+        } else if (this.usingEventSource && this.conn) {
+            this.conn.close();
+            this.conn = null;
+            console.log('server-sent events closed'); //eslint-disable-line no-console
+        } else if (this.conn && this.conn.readyState === WebSocket.OPEN) {
             this.conn.onclose = () => {}; //eslint-disable-line no-empty-function
             this.conn.close();
             this.conn = null;
@@ -158,6 +350,14 @@ export default class WebSocketClient {
     }
 
     sendMessage(action, data, responseCallback) {
+        // Server-sent events and polls only go from the server to the client
+        if (this.usingEventSource || this.usingPolling) {
+            if (!this.conn) {
+                this.initialize();
+            }
+            return;
+        }
+
         const msg = {
             action,
             seq: this.sequence++,
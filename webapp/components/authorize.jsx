@@ -3,6 +3,8 @@
 
 import FormError from 'components/form_error.jsx';
 
+import ChannelStore from 'stores/channel_store.jsx';
+
 import {FormattedMessage, FormattedHTMLMessage} from 'react-intl';
 import React from 'react';
 
@@ -59,6 +61,71 @@ export default class Authorize extends React.Component {
         );
     }
 
+    getScopes() {
+        let scope = this.props.location.query.scope;
+        if (!scope) {
+            scope = this.state.app.scopes || 'user';
+        }
+
+        return scope.split(' ').filter((item) => item.length > 0);
+    }
+
+    renderScopes() {
+        const scopes = this.getScopes();
+
+        const items = [];
+        if (scopes.indexOf('user') === -1 && scopes.indexOf('read') === -1) {
+            items.push(
+                <li key='openid'>
+                    <FormattedMessage
+                        id='authorize.scope.openid'
+                        defaultMessage='Confirm your identity and read your profile'
+                    />
+                </li>
+            );
+        } else if (scopes.indexOf('user') === -1) {
+            items.push(
+                <li key='read'>
+                    <FormattedMessage
+                        id='authorize.scope.read'
+                        defaultMessage='Read your information, teams, channels and messages without making any changes'
+                    />
+                </li>
+            );
+        } else {
+            items.push(
+                <li key='user'>
+                    <FormattedMessage
+                        id='authorize.scope.user'
+                        defaultMessage='Read and modify your information, teams, channels and messages'
+                    />
+                </li>
+            );
+        }
+
+        const channelNames = scopes.filter((item) => item.indexOf('channel:') === 0).map((item) => {
+            const channelId = item.substring('channel:'.length);
+            const channel = ChannelStore.get(channelId);
+            return channel ? channel.display_name : channelId;
+        });
+
+        if (channelNames.length > 0) {
+            items.push(
+                <li key='channels'>
+                    <FormattedMessage
+                        id='authorize.scope.channels'
+                        defaultMessage='Only access the following channels: {channels}'
+                        values={{
+                            channels: channelNames.join(', ')
+                        }}
+                    />
+                </li>
+            );
+        }
+
+        return <ul className='prompt__scopes'>{items}</ul>;
+    }
+
     handleDeny() {
         window.location.replace(this.props.location.query.redirect_uri + '?error=access_denied');
     }
@@ -110,12 +177,13 @@ export default class Authorize extends React.Component {
                     <p>
                         <FormattedHTMLMessage
                             id='authorize.app'
-                            defaultMessage='The app <strong>{appName}</strong> would like the ability to access and modify your basic information.'
+                            defaultMessage='The app <strong>{appName}</strong> would like the ability to:'
                             values={{
                                 appName: app.name
                             }}
                         />
                     </p>
+                    {this.renderScopes()}
                     <h2 className='prompt__allow'>
                         <FormattedHTMLMessage
                             id='authorize.access'
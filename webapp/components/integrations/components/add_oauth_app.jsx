@@ -29,6 +29,7 @@ export default class AddOAuthApp extends React.Component {
         this.updateHomepage = this.updateHomepage.bind(this);
         this.updateIconUrl = this.updateIconUrl.bind(this);
         this.updateCallbackUrls = this.updateCallbackUrls.bind(this);
+        this.updateScopes = this.updateScopes.bind(this);
 
         this.imageLoaded = this.imageLoaded.bind(this);
         this.image = new Image();
@@ -40,6 +41,7 @@ export default class AddOAuthApp extends React.Component {
             homepage: '',
             icon_url: '',
             callbackUrls: '',
+            scopes: '',
             is_trusted: false,
             has_icon: false,
             saving: false,
@@ -139,7 +141,8 @@ export default class AddOAuthApp extends React.Component {
             homepage: this.state.homepage,
             description: this.state.description,
             is_trusted: this.state.is_trusted,
-            icon_url: this.state.icon_url
+            icon_url: this.state.icon_url,
+            scopes: this.state.scopes.trim()
         };
 
         OAuthActions.registerOAuthApp(
@@ -194,6 +197,12 @@ export default class AddOAuthApp extends React.Component {
         });
     }
 
+    updateScopes(e) {
+        this.setState({
+            scopes: e.target.value
+        });
+    }
+
     render() {
         let icon;
         if (this.state.has_icon) {
@@ -401,6 +410,34 @@ export default class AddOAuthApp extends React.Component {
                                 </div>
                             </div>
                         </div>
+                        <div className='form-group'>
+                            <label
+                                className='control-label col-sm-4'
+                                htmlFor='scopes'
+                            >
+                                <FormattedMessage
+                                    id='installed_oauth_apps.scopes'
+                                    defaultMessage='Scopes'
+                                />
+                            </label>
+                            <div className='col-md-5 col-sm-8'>
+                                <input
+                                    id='scopes'
+                                    type='text'
+                                    maxLength='1024'
+                                    className='form-control'
+                                    placeholder='user'
+                                    value={this.state.scopes}
+                                    onChange={this.updateScopes}
+                                />
+                                <div className='form__help'>
+                                    <FormattedMessage
+                                        id='add_oauth_app.scopes.help'
+                                        defaultMessage='The space separated permissions users can grant to the application. Use "user" for full access or "read" for read-only access, and add "channel:<channel_id>" entries to restrict the application to specific channels. Leave blank for full access.'
+                                    />
+                                </div>
+                            </div>
+                        </div>
                         <div className='backstage-form__footer'>
                             <FormError
                                 type='backstage'
@@ -16,6 +16,7 @@ func InitApi() {
 	InitSystem()
 	InitStatus()
 	InitWebrtc()
+	InitSubscription()
 
 	app.HubStart()
 }
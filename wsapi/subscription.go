@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package wsapi
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitSubscription() {
+	l4g.Debug(utils.T("wsapi.subscription.init.debug"))
+
+	app.Srv.WebSocketRouter.Handle("subscribe", ApiWebConnHandler(subscribe))
+	app.Srv.WebSocketRouter.Handle("unsubscribe", ApiWebConnHandler(unsubscribe))
+}
+
+func subscribe(conn *app.WebConn, req *model.WebSocketRequest) (map[string]interface{}, *model.AppError) {
+	channelIds, events, err := getSubscriptionParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.Subscribe(channelIds, events)
+
+	return nil, nil
+}
+
+func unsubscribe(conn *app.WebConn, req *model.WebSocketRequest) (map[string]interface{}, *model.AppError) {
+	channelIds, events, err := getSubscriptionParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Unsubscribe(channelIds, events); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func getSubscriptionParams(req *model.WebSocketRequest) ([]string, []string, *model.AppError) {
+	channelIds := model.ArrayFromInterface(req.Data["channel_ids"])
+	events := model.ArrayFromInterface(req.Data["events"])
+
+	if len(channelIds) == 0 && len(events) == 0 {
+		return nil, nil, NewInvalidWebSocketParamError(req.Action, "channel_ids")
+	}
+
+	for _, channelId := range channelIds {
+		if len(channelId) != 26 {
+			return nil, nil, NewInvalidWebSocketParamError(req.Action, "channel_ids")
+		}
+	}
+
+	for _, event := range events {
+		if len(event) == 0 || event == model.WEBSOCKET_EVENT_HELLO {
+			return nil, nil, NewInvalidWebSocketParamError(req.Action, "events")
+		}
+	}
+
+	return channelIds, events, nil
+}
@@ -28,6 +28,16 @@ func userTyping(req *model.WebSocketRequest) (map[string]interface{}, *model.App
 		parentId = ""
 	}
 
+	// Typing counts as activity so that the user isn't shown as away
+	go app.SetStatusOnline(req.Session.UserId, req.Session.Id, false)
+
+	// Typing events aren't sent to large channels since they'd be broadcast to every member
+	if isLarge, err := app.IsLargeChannel(channelId); err != nil {
+		return nil, err
+	} else if isLarge {
+		return nil, nil
+	}
+
 	omitUsers := make(map[string]bool, 1)
 	omitUsers[req.Session.UserId] = true
 